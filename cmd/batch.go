@@ -12,14 +12,17 @@ import (
 
 	"github.com/iflytek/agentbridge/core"
 	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/cache"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	workerCount   int
-	overwriteMode bool
+	workerCount        int
+	overwriteMode      bool
+	outputNameTemplate string
 )
 
 // BatchJob represents a single conversion task
@@ -34,6 +37,7 @@ type BatchJob struct {
 type BatchResult struct {
 	Job      BatchJob
 	Success  bool
+	Cached   bool // The conversion result came from the cache
 	Error    error
 	Duration time.Duration
 }
@@ -44,6 +48,9 @@ type ConcurrentBatchProcessor struct {
 	jobQueue        chan BatchJob
 	resultQueue     chan BatchResult
 	conversionSvc   *services.ConversionService
+	cache           *cache.Cache      // Nil without a cache directory
+	outputPaths     map[string]string // Output path of each input file
+	fingerprint     [][]byte          // Cache key part of the shared conversion options
 	ctx             context.Context
 	cancel          context.CancelFunc
 	progressTracker *ProgressTracker
@@ -73,18 +80,24 @@ Supports directory-based batch conversion with configurable worker count and pro
   agentbridge batch --from iflytek --to dify --input-dir ./workflows --output-dir ./converted --workers 8
 
   # Batch convert with pattern matching
-  agentbridge batch --from iflytek --to dify --input-dir ./workflows --pattern "*.yml" --output-dir ./converted`,
+  agentbridge batch --from iflytek --to dify --input-dir ./workflows --pattern "*.yml" --output-dir ./converted
+
+  # Name the outputs after the workflows they hold
+  agentbridge batch --from coze --to dify --input-dir ./exports --output-dir ./converted --name-template "{{.Name}}-{{.TargetPlatform}}{{.Ext}}"`,
 		RunE: runBatch,
 	}
 
 	// Configure batch command flags
 	batchCmd.Flags().StringVar(&inputDir, "input-dir", "", "Input directory containing workflow files (required)")
 	batchCmd.Flags().StringVar(&outputDir, "output-dir", "", "Output directory for converted files (required)")
-	batchCmd.Flags().StringVar(&sourceType, "from", "", "Source platform (iflytek|dify|coze) (required)")
-	batchCmd.Flags().StringVar(&targetType, "to", "", "Target platform (iflytek|dify|coze) (required)")
+	batchCmd.Flags().StringVar(&sourceType, "from", "", "Source platform (iflytek|dify|coze|unified) (required)")
+	batchCmd.Flags().StringVar(&targetType, "to", "", "Target platform (iflytek|dify|coze|unified) (required)")
+	addGenerationFlags(batchCmd)
 	batchCmd.Flags().StringVar(&pattern, "pattern", "*.yml", "File pattern to match (default: *.yml)")
 	batchCmd.Flags().IntVar(&workerCount, "workers", 0, "Number of concurrent workers (default: auto-detect based on CPU cores)")
 	batchCmd.Flags().BoolVar(&overwriteMode, "overwrite", false, "Automatically overwrite existing output files without prompting")
+	batchCmd.Flags().StringVar(&outputNameTemplate, "name-template", "", "Go template naming the output files (fields: .Name .Stem .Author .Version .SourcePlatform .TargetPlatform .Ext .Index; default: the input file name)")
+	batchCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Reuse the results of unchanged conversions from this directory (env: "+cache.EnvCacheDir+")")
 
 	// Mark required flags
 	batchCmd.MarkFlagRequired("input-dir")
@@ -107,6 +120,25 @@ func runBatch(cmd *cobra.Command, args []string) error {
 		printHeader("Concurrent Batch Conversion")
 	}
 
+	if err := validateProvenanceMode(); err != nil {
+		return err
+	}
+
+	if err := loadHookScript(); err != nil {
+		return err
+	}
+
+	if err := loadPolicy(); err != nil {
+		return err
+	}
+	if err := loadValidationRules(); err != nil {
+		return err
+	}
+
+	if err := loadSigningKey(); err != nil {
+		return err
+	}
+
 	if err := setupBatchDirectories(); err != nil {
 		return err
 	}
@@ -118,28 +150,39 @@ func runBatch(cmd *cobra.Command, args []string) error {
 
 	logFilesFound(files)
 
-	// Check for output file conflicts before processing
-	if err := checkOutputFileConflicts(files); err != nil {
-		return err
-	}
-
 	// Initialize conversion service once (reused by all workers)
 	conversionSvc, err := core.InitializeArchitecture()
 	if err != nil {
 		return fmt.Errorf("failed to initialize conversion service: %w", err)
 	}
 
+	outputPaths, err := planOutputPaths(files, conversionSvc)
+	if err != nil {
+		return err
+	}
+
+	// Check for output file conflicts before processing
+	if err := checkOutputFileConflicts(files, outputPaths); err != nil {
+		return err
+	}
+
 	// Create and configure concurrent processor
 	processor := NewConcurrentBatchProcessor(conversionSvc, len(files))
 	defer processor.Close()
+	processor.outputPaths = outputPaths
+	if processor.cache = openConversionCache(); processor.cache != nil {
+		if processor.fingerprint, err = conversionFingerprint(buildConversionOptions()); err != nil {
+			return err
+		}
+	}
 
 	// Process files concurrently
-	successCount, errorCount, err := processor.ProcessFiles(files)
+	successCount, errorCount, cachedCount, err := processor.ProcessFiles(files)
 	if err != nil {
 		return fmt.Errorf("batch processing failed: %w", err)
 	}
 
-	printBatchSummary(files, successCount, errorCount)
+	printBatchSummary(files, successCount, errorCount, cachedCount)
 
 	if errorCount > 0 {
 		return fmt.Errorf("batch conversion completed with %d errors", errorCount)
@@ -175,8 +218,9 @@ func NewConcurrentBatchProcessor(conversionSvc *services.ConversionService, tota
 	}
 }
 
-// ProcessFiles processes all files concurrently
-func (p *ConcurrentBatchProcessor) ProcessFiles(files []string) (int, int, error) {
+// ProcessFiles processes all files concurrently and returns the numbers of successful, failed
+// and cached conversions
+func (p *ConcurrentBatchProcessor) ProcessFiles(files []string) (int, int, int, error) {
 	if verbose {
 		fmt.Printf("🚀 Starting concurrent processing with %d workers\n", p.workerCount)
 	}
@@ -192,12 +236,16 @@ func (p *ConcurrentBatchProcessor) ProcessFiles(files []string) (int, int, error
 	resultDone := make(chan struct{})
 	successCount := 0
 	errorCount := 0
+	cachedCount := 0
 	go func() {
 		defer close(resultDone)
 		for result := range p.resultQueue {
 			p.updateProgress(result)
 			if result.Success {
 				successCount++
+				if result.Cached {
+					cachedCount++
+				}
 			} else {
 				errorCount++
 				fmt.Printf("❌ Failed to process %s: %v\n", filepath.Base(result.Job.FilePath), result.Error)
@@ -209,8 +257,10 @@ func (p *ConcurrentBatchProcessor) ProcessFiles(files []string) (int, int, error
 	go func() {
 		defer close(p.jobQueue)
 		for i, file := range files {
-			filename := filepath.Base(file)
-			outputFile := filepath.Join(outputDir, filename)
+			outputFile, ok := p.outputPaths[file]
+			if !ok {
+				outputFile = filepath.Join(outputDir, filepath.Base(file))
+			}
 
 			select {
 			case p.jobQueue <- BatchJob{
@@ -232,7 +282,7 @@ func (p *ConcurrentBatchProcessor) ProcessFiles(files []string) (int, int, error
 	// Wait for result collector to finish
 	<-resultDone
 
-	return successCount, errorCount, nil
+	return successCount, errorCount, cachedCount, nil
 }
 
 // worker processes jobs from the job queue
@@ -247,12 +297,13 @@ func (p *ConcurrentBatchProcessor) worker(workerID int, wg *sync.WaitGroup) {
 			}
 
 			startTime := time.Now()
-			err := p.processJob(job)
+			cached, err := p.processJob(job)
 			duration := time.Since(startTime)
 
 			result := BatchResult{
 				Job:      job,
 				Success:  err == nil,
+				Cached:   cached,
 				Error:    err,
 				Duration: duration,
 			}
@@ -269,45 +320,52 @@ func (p *ConcurrentBatchProcessor) worker(workerID int, wg *sync.WaitGroup) {
 	}
 }
 
-// processJob processes a single conversion job with enhanced error handling
-func (p *ConcurrentBatchProcessor) processJob(job BatchJob) error {
+// processJob processes a single conversion job with enhanced error handling and reports whether
+// the conversion result came from the cache
+func (p *ConcurrentBatchProcessor) processJob(job BatchJob) (bool, error) {
 	filename := filepath.Base(job.FilePath)
 
 	// Validate file existence and readability
 	if err := p.validateInputFile(job.FilePath); err != nil {
-		return fmt.Errorf("input validation failed for '%s': %w", filename, err)
+		return false, fmt.Errorf("input validation failed for '%s': %w", filename, err)
 	}
 
 	// Read input file
 	inputData, err := os.ReadFile(job.FilePath)
 	if err != nil {
 		if os.IsPermission(err) {
-			return fmt.Errorf("permission denied accessing '%s' - check file permissions", filename)
+			return false, fmt.Errorf("permission denied accessing '%s' - check file permissions", filename)
 		}
-		return fmt.Errorf("unable to read '%s': %w", filename, err)
+		return false, fmt.Errorf("unable to read '%s': %w", filename, err)
 	}
 
 	// Validate file size (prevent processing extremely large files)
 	if len(inputData) == 0 {
-		return fmt.Errorf("file '%s' is empty - skipping conversion", filename)
+		return false, fmt.Errorf("file '%s' is empty - skipping conversion", filename)
 	}
 	if len(inputData) > 50*1024*1024 { // 50MB limit for CLI tool
-		return fmt.Errorf("file '%s' is too large (%.1fMB) - maximum supported size is 50MB",
+		return false, fmt.Errorf("file '%s' is too large (%.1fMB) - maximum supported size is 50MB",
 			filename, float64(len(inputData))/1024/1024)
 	}
 
 	// Convert using shared service (thread-safe)
-	outputData, err := p.convertFileData(inputData)
+	result, cached, err := p.convertFileData(inputData, job.FilePath)
 	if err != nil {
-		return fmt.Errorf("conversion failed for '%s': %w", filename, err)
+		return false, fmt.Errorf("conversion failed for '%s': %w", filename, err)
+	}
+
+	// Sidecars, provenance and signatures are written for cached results too
+	outputData, err := finalizeConversionOutput(job.FilePath, job.OutputPath, inputData, result)
+	if err != nil {
+		return cached, fmt.Errorf("output finalization failed for '%s': %w", filename, err)
 	}
 
 	// Validate output directory and write file
 	if err := p.writeOutputFile(job.OutputPath, outputData); err != nil {
-		return fmt.Errorf("output write failed for '%s': %w", filename, err)
+		return cached, fmt.Errorf("output write failed for '%s': %w", filename, err)
 	}
 
-	return nil
+	return cached, nil
 }
 
 // validateInputFile performs comprehensive input file validation
@@ -370,8 +428,9 @@ func (p *ConcurrentBatchProcessor) checkWritePermission(dir string) error {
 	return nil
 }
 
-// convertFileData converts data using the shared conversion service with enhanced error handling
-func (p *ConcurrentBatchProcessor) convertFileData(inputData []byte) ([]byte, error) {
+// convertFileData converts data using the shared conversion service with enhanced error handling,
+// or returns the cached result of an identical earlier conversion
+func (p *ConcurrentBatchProcessor) convertFileData(inputData []byte, inputPath string) (*services.ConversionResult, bool, error) {
 	var fromPlatform, toPlatform models.PlatformType
 
 	// Validate and convert source platform
@@ -382,8 +441,10 @@ func (p *ConcurrentBatchProcessor) convertFileData(inputData []byte) ([]byte, er
 		fromPlatform = models.PlatformDify
 	case "coze":
 		fromPlatform = models.PlatformCoze
+	case "unified":
+		fromPlatform = models.PlatformUnified
 	default:
-		return nil, fmt.Errorf("unsupported source platform '%s' - supported platforms: iflytek, dify, coze", sourceType)
+		return nil, false, fmt.Errorf("unsupported source platform '%s' - supported platforms: iflytek, dify, coze, unified", sourceType)
 	}
 
 	// Validate and convert target platform
@@ -394,22 +455,41 @@ func (p *ConcurrentBatchProcessor) convertFileData(inputData []byte) ([]byte, er
 		toPlatform = models.PlatformDify
 	case "coze":
 		toPlatform = models.PlatformCoze
+	case "unified":
+		toPlatform = models.PlatformUnified
 	default:
-		return nil, fmt.Errorf("unsupported target platform '%s' - supported platforms: iflytek, dify, coze", targetType)
+		return nil, false, fmt.Errorf("unsupported target platform '%s' - supported platforms: iflytek, dify, coze, unified", targetType)
 	}
 
-	// Validate that source and target are different
-	if fromPlatform == toPlatform {
-		return nil, fmt.Errorf("source and target platforms are the same ('%s') - no conversion needed", sourceType)
+	var cacheKey string
+	if p.cache != nil {
+		cacheKey = cache.Key(inputData, fromPlatform, toPlatform, getVersion(), p.fingerprint...)
+		if result, ok := p.cache.Get(cacheKey); ok {
+			summarizeConversion(result, nil)
+			return result, true, nil
+		}
 	}
 
 	// Perform conversion with enhanced error context
-	result, err := p.conversionSvc.Convert(inputData, fromPlatform, toPlatform)
+	ctx, cancel := conversionContext(p.ctx)
+	defer cancel()
+	conversionStart := time.Now()
+	options := buildConversionOptions()
+	options.DebugDir = debugArtifactDir(inputDir, inputPath)
+	result, err := p.conversionSvc.ConvertWithResult(ctx, inputData, fromPlatform, toPlatform, options)
+	recordConversion("batch", fromPlatform, toPlatform, result, err, time.Since(conversionStart))
 	if err != nil {
-		return nil, p.enhanceConversionError(err, fromPlatform, toPlatform)
+		return nil, false, p.enhanceConversionError(timeoutError(err), fromPlatform, toPlatform)
+	}
+
+	// A cache that cannot be written costs speed, not the conversion
+	if p.cache != nil {
+		if err := p.cache.Put(cacheKey, result); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
 	}
 
-	return result, nil
+	return result, false, nil
 }
 
 // enhanceConversionError provides more user-friendly conversion error messages
@@ -472,6 +552,9 @@ func (p *ConcurrentBatchProcessor) updateProgress(result BatchResult) {
 		// Verbose mode: show detailed progress with visual elements
 		if result.Success {
 			fileSize := p.humanizeFileSize(result.Job.FilePath)
+			if result.Cached {
+				fileSize += ", cached"
+			}
 			fmt.Printf("✅ [%d/%d] %s %s (%s) - %.1fms | %.1f/s | ETA: %v\n",
 				completed, total,
 				p.createProgressBar(progress, 20),
@@ -604,15 +687,86 @@ func logFilesFound(files []string) {
 	}
 }
 
+// planOutputPaths names the output of each input file: the input file name, or --name-template
+// rendered from the workflow the file holds. Inputs that would be written to the same file fail
+// the batch before anything is converted.
+func planOutputPaths(files []string, conversionSvc *services.ConversionService) (map[string]string, error) {
+	var nameTemplate *common.OutputNameTemplate
+	if outputNameTemplate != "" {
+		var err error
+		if nameTemplate, err = common.ParseOutputNameTemplate(outputNameTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	// Parsers print their summaries again when the files are converted
+	if nameTemplate != nil {
+		restore := discardStdout()
+		defer restore()
+	}
+
+	outputPaths := make(map[string]string, len(files))
+	inputs := make(map[string]string, len(files)) // output path -> input path
+	for i, inputFile := range files {
+		name := filepath.Base(inputFile)
+		if nameTemplate != nil {
+			var err error
+			if name, err = nameTemplate.Render(outputNameFields(conversionSvc, inputFile, i+1)); err != nil {
+				return nil, fmt.Errorf("cannot name the output of %s: %w", filepath.Base(inputFile), err)
+			}
+		}
+		outputFile := filepath.Join(outputDir, name)
+		if other, taken := inputs[outputFile]; taken {
+			return nil, fmt.Errorf("%s and %s would both be written to %s - add {{.Stem}} or {{.Index}} to --name-template",
+				filepath.Base(other), filepath.Base(inputFile), outputFile)
+		}
+		inputs[outputFile] = inputFile
+		outputPaths[inputFile] = outputFile
+	}
+	return outputPaths, nil
+}
+
+// outputNameFields reads the template fields of an input file. Files that do not parse are named
+// after the file, and fail later in their conversion.
+func outputNameFields(conversionSvc *services.ConversionService, inputFile string, index int) common.OutputNameFields {
+	stem := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
+	fields := common.OutputNameFields{
+		Name:           stem,
+		Stem:           stem,
+		SourcePlatform: models.PlatformType(sourceType),
+		TargetPlatform: models.PlatformType(targetType),
+		Ext:            ".yml",
+		Index:          index,
+	}
+	if outputFormat == models.OutputFormatJSON {
+		fields.Ext = ".json"
+	}
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fields
+	}
+	unifiedDSL, err := conversionSvc.Parse(data, fields.SourcePlatform)
+	if err != nil {
+		return fields
+	}
+	if common.Slugify(unifiedDSL.Metadata.Name) != "" {
+		fields.Name = unifiedDSL.Metadata.Name
+	}
+	fields.Author = unifiedDSL.Metadata.Author
+	fields.Version = unifiedDSL.Metadata.Version
+	return fields
+}
+
 // checkOutputFileConflicts checks for existing output files and handles conflicts
-func checkOutputFileConflicts(files []string) error {
+func checkOutputFileConflicts(files []string, outputPaths map[string]string) error {
 	if overwriteMode {
 		return nil // Skip conflict check in overwrite mode
 	}
 
 	conflicts := make(map[string]string) // output path -> input path
 	for _, inputFile := range files {
-		outputFile := filepath.Join(outputDir, filepath.Base(inputFile))
+		outputFile := outputPaths[inputFile]
 		if _, err := os.Stat(outputFile); err == nil {
 			conflicts[outputFile] = inputFile
 		}
@@ -703,12 +857,15 @@ func removeConflictingFiles(files []string, conflicts map[string]string) error {
 }
 
 // printBatchSummary prints summary of batch conversion results
-func printBatchSummary(files []string, successCount, errorCount int) {
+func printBatchSummary(files []string, successCount, errorCount, cachedCount int) {
 	if !quiet {
 		fmt.Printf("\n📊 Concurrent Batch Conversion Summary:\n")
 		fmt.Printf("   Workers used: %d\n", workerCount)
 		fmt.Printf("   Total files: %d\n", len(files))
 		fmt.Printf("   Successful: %d\n", successCount)
+		if cachedCount > 0 {
+			fmt.Printf("   Cached: %d (unchanged since an earlier run)\n", cachedCount)
+		}
 		fmt.Printf("   Failed: %d\n", errorCount)
 		fmt.Printf("   Success rate: %.1f%%\n", float64(successCount)/float64(len(files))*100)
 	}
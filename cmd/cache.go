@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/iflytek/agentbridge/internal/cache"
+	"github.com/iflytek/agentbridge/internal/models"
+	iflytekGenerator "github.com/iflytek/agentbridge/platforms/iflytek/generator"
+)
+
+// cacheDir is the directory of the conversion cache of batch runs
+var cacheDir string
+
+// openConversionCache returns the cache of --cache-dir (or cache_dir of the profile) or
+// AGENTBRIDGE_CACHE_DIR, nil when neither is set. --debug-dir needs every conversion to run, and
+// --touch stamps every output with the time of the run, so both disable the cache.
+func openConversionCache() *cache.Cache {
+	dir := cacheDir
+	if dir == "" {
+		dir = os.Getenv(cache.EnvCacheDir)
+	}
+	if dir == "" || debugDir != "" || touch {
+		return nil
+	}
+	return cache.New(dir)
+}
+
+// conversionFingerprint returns what shapes conversions besides the source and the platforms:
+// the options, the hook script, policy and rules files they were loaded from, and the Spark
+// identity generators fall back to
+func conversionFingerprint(options *models.ConversionOptions) ([][]byte, error) {
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode conversion options: %w", err)
+	}
+	fingerprint := [][]byte{encoded}
+
+	for _, path := range []string{hookScriptFile, policyFile, rulesFile} {
+		var data []byte
+		if path != "" {
+			if data, err = os.ReadFile(path); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+		}
+		fingerprint = append(fingerprint, data)
+	}
+	return append(fingerprint,
+		[]byte(os.Getenv(iflytekGenerator.EnvSparkAppID)),
+		[]byte(os.Getenv(iflytekGenerator.EnvSparkUID)),
+	), nil
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+
+	"github.com/spf13/cobra"
+)
+
+// NewCheckCmd creates the check command
+func NewCheckCmd() *cobra.Command {
+	var checkCmd = &cobra.Command{
+		Use:   "check",
+		Short: "Report how a workflow will convert without converting it",
+		Long: `Analyze a source workflow against the capability matrix of the target platform.
+
+Every node, including iteration sub-workflow nodes, is reported as native (converts cleanly),
+degraded (replaced by a different node, possibly a code placeholder) or unsupported (fails).
+Code nodes are analyzed for imports, network, file and process access, and variables that are
+not inputs of the node, which the restricted code sandbox of the target may reject; these
+findings do not fail the check. A dry run conversion in memory catches failures the matrix cannot predict. No file is written;
+the command exits with an error when the conversion would fail.`,
+		Example: `  # Plan a Dify to iFlytek migration
+  agentbridge check --input dify.yml --to iflytek
+
+  # Check with the strategies the conversion will use
+  agentbridge check --input agent.yml --from iflytek --to dify --audio-strategy http --placeholder-strategy fail
+
+  # Fail on unknown node types, malformed references and missing required fields, e.g. in CI
+  agentbridge check --input dify.yml --to iflytek --parse-mode strict`,
+		RunE: runCheck,
+	}
+
+	// Configure check command flags
+	checkCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input DSL file path (required)")
+	checkCmd.Flags().StringVar(&sourceType, "from", "", "Source platform (iflytek|dify|coze|unified, auto-detect if not specified)")
+	checkCmd.Flags().StringVar(&targetType, "to", "", "Target platform (iflytek|dify|coze|unified) (required)")
+	checkCmd.Flags().StringVar(&targetVersion, "target-version", "", "Target platform version to stay compatible with (dify: 0.6.x|0.15.x|1.x, iflytek: v1|v2)")
+	checkCmd.Flags().StringVar(&placeholderStrategy, "placeholder-strategy", models.PlaceholderStrategyPlaceholder, "Unsupported node handling (placeholder|fail)")
+	checkCmd.Flags().StringVar(&policyFile, "policy", "", "YAML policy whose block rules fail the check")
+	checkCmd.Flags().StringVar(&rulesFile, "rules", "", "YAML file of custom validation rules whose errors fail the check")
+	checkCmd.Flags().StringVar(&audioStrategy, "audio-strategy", models.AudioStrategyPlaceholder, "Speech synthesis/recognition node handling on Dify and Coze (placeholder|http)")
+	checkCmd.Flags().StringVar(&triggerStrategy, "trigger-strategy", models.TriggerStrategyComment, "Schedule and event trigger handling on iFlytek and Dify (comment|placeholder)")
+	checkCmd.Flags().StringVar(&parseMode, "parse-mode", models.ParseModePermissive, "Source problem handling: permissive converts best-effort with warnings, strict fails, lenient also repairs damaged exports (permissive|strict|lenient)")
+
+	// Mark required flags
+	checkCmd.MarkFlagRequired("input")
+	checkCmd.MarkFlagRequired("to")
+
+	return checkCmd
+}
+
+// runCheck executes the check command
+func runCheck(cmd *cobra.Command, args []string) error {
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+	if quiet {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	if !quiet {
+		printHeader("Conversion Pre-flight Check")
+	}
+
+	report, err := executeCheck()
+	if err != nil {
+		return err
+	}
+
+	// A failing report is not a usage error
+	cmd.SilenceUsage = true
+	return outputCheckReport(report)
+}
+
+// executeCheck reads the input file and analyzes it against the target platform
+func executeCheck() (*services.CheckReport, error) {
+	if err := validateInputFile(inputFile); err != nil {
+		return nil, fmt.Errorf("input file validation failed: %w", err)
+	}
+	if err := loadPolicy(); err != nil {
+		return nil, err
+	}
+	if err := loadValidationRules(); err != nil {
+		return nil, err
+	}
+
+	inputData, err := os.ReadFile(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	if sourceType == "" {
+		detected, err := detectSourceType(inputData)
+		if err != nil {
+			return nil, err
+		}
+		sourceType = detected
+	}
+	if err := validateFormatTypes(sourceType, targetType); err != nil {
+		return nil, err
+	}
+
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+
+	report, err := conversionService.Check(inputData,
+		models.PlatformType(sourceType), models.PlatformType(targetType), buildConversionOptions())
+	if err != nil {
+		return nil, fmt.Errorf("check failed: %w", err)
+	}
+	return report, nil
+}
+
+// checkLevelIcons marks support levels in the node list
+var checkLevelIcons = map[common.SupportLevel]string{
+	common.SupportNative:      "✅",
+	common.SupportDegraded:    "⚠️ ",
+	common.SupportUnsupported: "❌",
+}
+
+// outputCheckReport prints the node verdicts and the summary
+func outputCheckReport(report *services.CheckReport) error {
+	fmt.Printf("   File: %s\n", inputFile)
+	fmt.Printf("   Path: %s → %s\n\n", report.SourcePlatform, report.TargetPlatform)
+
+	printCheckNodes(report)
+	printCheckSummary(report)
+
+	for _, issue := range report.ParseIssues {
+		fmt.Printf("⚠️  %s\n", issue)
+	}
+
+	if report.DryRunError != nil {
+		fmt.Printf("❌ Dry run conversion failed: %v\n", report.DryRunError)
+	} else {
+		fmt.Printf("✅ Dry run conversion succeeded\n")
+	}
+
+	if !report.Passed() {
+		return fmt.Errorf("conversion to %s would fail", report.TargetPlatform)
+	}
+	return nil
+}
+
+// printCheckNodes lists the verdict of every node, iteration sub-workflow nodes indented
+func printCheckNodes(report *services.CheckReport) {
+	for _, node := range report.Nodes {
+		indent := "   "
+		if node.IterationID != "" {
+			indent = "      "
+		}
+		fmt.Printf("%s%s %s [%s] %s", indent, checkLevelIcons[node.Level], node.Title, node.Type, node.Level)
+		if node.Note != "" {
+			fmt.Printf(": %s", node.Note)
+		}
+		fmt.Println()
+		for _, finding := range node.Code {
+			fmt.Printf("%s   %s %s\n", indent, codeFindingIcons[finding.Severity], finding)
+		}
+	}
+}
+
+// codeFindingIcons mark code findings by severity
+var codeFindingIcons = map[models.ErrorSeverity]string{
+	models.SeverityError:   "❌",
+	models.SeverityWarning: "⚠️ ",
+	models.SeverityInfo:    "ℹ️ ",
+}
+
+// printCheckSummary prints the node counts of a report
+func printCheckSummary(report *services.CheckReport) {
+	fmt.Printf("\n📊 %d nodes: %d native, %d degraded, %d unsupported\n",
+		len(report.Nodes), report.Count(common.SupportNative), report.Count(common.SupportDegraded),
+		report.Count(common.SupportUnsupported))
+	if placeholders := report.Placeholders(); placeholders > 0 {
+		fmt.Printf("   %d nodes become code placeholders that have to be implemented by hand\n", placeholders)
+	}
+	errors, warnings := report.CodeFindings(models.SeverityError), report.CodeFindings(models.SeverityWarning)
+	if errors+warnings > 0 {
+		fmt.Printf("   Code analysis: %d of %d code findings are errors the target sandbox will fail on\n", errors, errors+warnings)
+	}
+}
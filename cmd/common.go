@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -24,6 +28,28 @@ var (
 	showTypes    bool
 	showAll      bool
 	showDetailed bool
+
+	// Generation options shared by convert and batch
+	targetVersion string
+	keepTitles    bool
+	anonymize     bool
+	titlePrefix   string
+	titleSuffix   string
+	titleTemplate string
+	iflytekAppID  string
+	iflytekUID    string
+
+	placeholderStrategy string
+	audioStrategy       string
+	triggerStrategy     string
+	defaultIntent       string
+	parseMode           string
+	outputFormat        string
+	minify              bool
+	bumpVersion         string
+	touch               bool
+	conversionTimeout   time.Duration
+	debugDir            string
 )
 
 // printHeader prints a formatted header
@@ -33,6 +59,92 @@ func printHeader(title string) {
 	fmt.Println(strings.Repeat("=", 60))
 }
 
+// buildConversionOptions collects generation options from command flags
+func buildConversionOptions() *models.ConversionOptions {
+	options := models.NewConversionOptions()
+	options.TargetVersion = targetVersion
+	options.KeepTitles = keepTitles
+	options.TitlePrefix = titlePrefix
+	options.TitleSuffix = titleSuffix
+	options.TitleTemplate = titleTemplate
+	options.IFlytekAppID = iflytekAppID
+	options.IFlytekUID = iflytekUID
+	options.ModelMap = modelMap
+	options.Icons = iconSet
+	options.PlaceholderStrategy = placeholderStrategy
+	options.AudioStrategy = audioStrategy
+	options.TriggerStrategy = triggerStrategy
+	options.DefaultIntent = defaultIntent
+	options.ParseMode = parseMode
+	options.OutputFormat = outputFormat
+	options.Minify = minify
+	options.Anonymize = anonymize
+	options.BumpVersion = bumpVersion
+	options.Touch = touch
+	options.NodeHooks = nodeHooks
+	options.Policy = conversionPolicy
+	options.Validators = validationRules
+	return options
+}
+
+// debugArtifactDir returns the --debug-dir subdirectory of one input file, named after its path
+// relative to baseDir without extension; empty without --debug-dir
+func debugArtifactDir(baseDir, inputPath string) string {
+	if debugDir == "" {
+		return ""
+	}
+	name, err := filepath.Rel(baseDir, inputPath)
+	if err != nil || strings.HasPrefix(name, "..") {
+		name = filepath.Base(inputPath)
+	}
+	return filepath.Join(debugDir, strings.TrimSuffix(name, filepath.Ext(name)))
+}
+
+// conversionContext limits a conversion to --timeout
+func conversionContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if conversionTimeout > 0 {
+		return context.WithTimeout(parent, conversionTimeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// timeoutError explains conversions stopped by --timeout and returns other errors unchanged
+func timeoutError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s (raise --timeout): %w", conversionTimeout, err)
+	}
+	return err
+}
+
+// addGenerationFlags registers generation option flags shared by convert and batch
+func addGenerationFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&targetVersion, "target-version", "", "Target platform version to stay compatible with (dify: 0.6.x|0.15.x|1.x, iflytek: v1|v2)")
+	cmd.Flags().BoolVar(&keepTitles, "keep-titles", false, "Keep original node titles, including titles of unsupported node placeholders")
+	cmd.Flags().StringVar(&titlePrefix, "title-prefix", "", "Prefix added to every generated node title")
+	cmd.Flags().StringVar(&titleSuffix, "title-suffix", "", "Suffix added to every generated node title")
+	cmd.Flags().StringVar(&iflytekAppID, "iflytek-app-id", "", "Spark appId written into generated iFlytek nodes (env: AGENTBRIDGE_IFLYTEK_APP_ID)")
+	cmd.Flags().StringVar(&iflytekUID, "iflytek-uid", "", "Spark uid written into generated iFlytek nodes (env: AGENTBRIDGE_IFLYTEK_UID)")
+	cmd.Flags().StringVar(&titleTemplate, "title-template", "", "Node title template, supports {{title}}, {{id}} and {{type}} (e.g. \"{{title}} (migrated)\")")
+	cmd.Flags().StringVar(&provenanceMode, "provenance", "", "Attach conversion provenance (embed|sidecar)")
+	cmd.Flags().BoolVar(&emitChecksum, "checksum", false, "Write the SHA-256 checksum of the output to <output>.sha256 (sha256sum format)")
+	cmd.Flags().StringVar(&signKeyFile, "sign", "", "PEM private key (RSA, ECDSA or Ed25519) that signs the output into <output>.sig")
+	cmd.Flags().BoolVar(&emitMapping, "emit-mapping", false, "Write the source-to-target ID mapping (nodes, outputs, branches, intents) to <output>.mapping.json")
+	cmd.Flags().StringVar(&placeholderStrategy, "placeholder-strategy", models.PlaceholderStrategyPlaceholder, "Unsupported node handling (placeholder|fail)")
+	cmd.Flags().BoolVar(&anonymize, "anonymize", false, "Replace titles, prompts and literal values with deterministic pseudonyms, for sharing workflows in bug reports")
+	cmd.Flags().StringVar(&hookScriptFile, "hook-script", "", "YAML hook script that edits nodes before and after conversion (e.g. prompt prefixes, model names)")
+	cmd.Flags().StringVar(&policyFile, "policy", "", "YAML policy whose rules warn about, rewrite or block nodes (e.g. max temperature, approved providers)")
+	cmd.Flags().StringVar(&rulesFile, "rules", "", "YAML file of custom validation rules (e.g. LLM nodes need a system prompt)")
+	cmd.Flags().StringVar(&audioStrategy, "audio-strategy", models.AudioStrategyPlaceholder, "Speech synthesis/recognition node handling on Dify and Coze (placeholder|http)")
+	cmd.Flags().StringVar(&triggerStrategy, "trigger-strategy", models.TriggerStrategyComment, "Schedule and event trigger handling on iFlytek and Dify (comment|placeholder)")
+	cmd.Flags().StringVar(&defaultIntent, "default-intent", models.DefaultIntentLastClass, "Target of the default intent iFlytek classifiers need, converting from Dify (last-class|end|none)")
+	cmd.Flags().BoolVar(&minify, "minify", false, "Shrink the output: drop default-valued fields, write repeated strings once as YAML anchors where the target allows, compact JSON")
+	cmd.Flags().StringVar(&bumpVersion, "bump-version", "", "Increment the workflow version of the export, starting from 0.0.0 when it has none (major|minor|patch)")
+	cmd.Flags().BoolVar(&touch, "touch", false, "Set the updated time of the export to now instead of keeping the source's")
+	cmd.Flags().StringVar(&debugDir, "debug-dir", "", "Save intermediate results (extracted ZIP JSON and manifest, unified DSL) per input file below this directory")
+	cmd.Flags().DurationVar(&conversionTimeout, "timeout", 0, "Abort a conversion that takes longer, e.g. 30s (0: no limit)")
+	cmd.Flags().StringVar(&parseMode, "parse-mode", models.ParseModePermissive, "Source problem handling: permissive converts best-effort with warnings, strict fails, lenient also repairs damaged exports (permissive|strict|lenient)")
+}
+
 // validateInputFile validates that the input file exists and has correct format
 func validateInputFile(filename string) error {
 	if filename == "" {
@@ -40,53 +152,30 @@ func validateInputFile(filename string) error {
 	}
 
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		return fmt.Errorf("input file does not exist: %s", filename)
+		return withExitCode(ExitIO, fmt.Errorf("input file does not exist: %s", filename))
 	}
 
 	ext := strings.ToLower(filepath.Ext(filename))
-	if ext != ".yml" && ext != ".yaml" && ext != ".zip" {
-		return fmt.Errorf("input file must be in YAML format (.yml or .yaml) or ZIP format (.zip)")
+	if ext != ".yml" && ext != ".yaml" && ext != ".zip" && ext != ".json" {
+		return fmt.Errorf("input file must be in YAML format (.yml or .yaml), ZIP format (.zip), or JSON (.json) for unified DSL and Coze workflow JSON")
 	}
 
 	return nil
 }
 
-// detectSourceType auto-detects the source platform type from file content
-func detectSourceType(data []byte) string {
-	// Detect ZIP signature first; treat any ZIP as Coze export package
-	if isZipData(data) {
-		return "coze"
-	}
-
-	// Use case-insensitive heuristic checks
-	content := string(data)
-	lower := strings.ToLower(content)
-
-	// iFlytek Spark Agent characteristics
-	if strings.Contains(content, "flowMeta") && strings.Contains(content, "flowData") {
-		return "iflytek"
-	}
-
-	// Dify characteristics
-	if strings.Contains(content, "workflow") && strings.Contains(content, "app") && strings.Contains(content, "kind") {
-		return "dify"
+// detectSourceType auto-detects the source platform type from file content and reports the
+// confidence of the detection
+func detectSourceType(data []byte) (string, error) {
+	detection, err := common.DetectPlatform(data)
+	if err != nil {
+		return "", withExitCode(ExitParse, fmt.Errorf("%w; specify the source platform with --from", err))
 	}
 
-	// Coze characteristics (be tolerant to different field spellings)
-	// - workflow_id (official) or workflowid (observed in fixtures)
-	// - export_format hint
-	// - schema section with nodes/edges
-	// - trigger_parameters key appears in start/end
-	if strings.Contains(lower, "workflow_id") ||
-		strings.Contains(lower, "workflowid") ||
-		strings.Contains(lower, "export_format") ||
-		(strings.Contains(lower, "schema:") && (strings.Contains(lower, "nodes:") || strings.Contains(lower, "edges:"))) ||
-		strings.Contains(lower, "trigger_parameters") {
-		return "coze"
+	fmt.Printf("🔍 Auto-detected source platform: %s (confidence %.0f%%)\n", detection.Platform, detection.Confidence*100)
+	if verbose {
+		fmt.Printf("   Matched: %s\n", strings.Join(detection.Signals, ", "))
 	}
-
-	// Default to iFlytek format
-	return "iflytek"
+	return string(detection.Platform), nil
 }
 
 // isZipData returns true if data starts with a ZIP file signature ("PK")
@@ -99,7 +188,7 @@ func isZipData(data []byte) bool {
 
 // validateFormatTypes validates source and target platform types
 func validateFormatTypes(source, target string) error {
-	validTypes := []string{"iflytek", "dify", "coze"}
+	validTypes := []string{"iflytek", "dify", "coze", "unified"}
 
 	isValidSource := false
 	isValidTarget := false
@@ -121,11 +210,8 @@ func validateFormatTypes(source, target string) error {
 		return fmt.Errorf("unsupported target platform: %s, supported platforms: %v", target, validTypes)
 	}
 
-	if source == target {
-		return fmt.Errorf("source and target platforms cannot be the same")
-	}
-
-	// Validate supported conversion paths (star architecture with iFlytek as hub)
+	// Validate supported conversion paths (star architecture with iFlytek as hub; the unified DSL
+	// is the intermediate format of every path, so it can be exported from and imported to any platform)
 	if (source == "dify" && target == "coze") || (source == "coze" && target == "dify") {
 		return fmt.Errorf("direct conversion between %s and %s is not supported. Please use iFlytek as intermediate hub:\n  1. Convert %s → iflytek\n  2. Convert iflytek → %s", source, target, source, target)
 	}
@@ -234,6 +320,17 @@ func wrapUserFriendlyError(err error) error {
 
 	errStr := err.Error()
 
+	// Timeouts name the limit and the stage that overran it, keep them
+	if errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	// Parse failures carry the parser's reason, such as the YAML document at fault, keep it
+	var parseErr *models.ParseError
+	if errors.As(err, &parseErr) && parseErr.Code == "PARSE_FAILED" {
+		return parseErr
+	}
+
 	// Try to match error patterns using the mapping table
 	for _, mapping := range errorCodeMappings {
 		if strings.Contains(errStr, mapping.Pattern) {
@@ -250,6 +347,21 @@ func wrapUserFriendlyError(err error) error {
 		}
 	}
 
+	// Policy violations name the offending nodes, keep them
+	var convErr *models.ConversionError
+	if errors.As(err, &convErr) && convErr.Code == "POLICY_VIOLATION" {
+		return convErr
+	}
+
+	// So do failures of custom validation rules, typed by their stage
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		switch models.ValidationStage(validationErr.Type) {
+		case models.ValidationStageStructure, models.ValidationStageSemantic, models.ValidationStagePlatform:
+			return validationErr
+		}
+	}
+
 	// Handle special case for conversion failed errors
 	if strings.Contains(errStr, "conversion failed") {
 		return &models.ConversionError{
@@ -284,6 +396,11 @@ func redirectStdoutIfQuiet() func() {
 	if !quiet {
 		return func() {}
 	}
+	return discardStdout()
+}
+
+// discardStdout sends stdout to the null device until the returned function restores it
+func discardStdout() func() {
 	old := os.Stdout
 	nullDevice := "/dev/null"
 	if runtime.GOOS == "windows" {
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// flagValueCompletions lists the fixed values of enum-like flags shared by several commands
+var flagValueCompletions = map[string][]string{
+	"output-format":        {models.OutputFormatYAML, models.OutputFormatJSON},
+	"placeholder-strategy": {models.PlaceholderStrategyPlaceholder, models.PlaceholderStrategyFail},
+	"audio-strategy":       {models.AudioStrategyPlaceholder, models.AudioStrategyHTTP},
+	"trigger-strategy":     {models.TriggerStrategyComment, models.TriggerStrategyPlaceholder},
+	"default-intent":       {models.DefaultIntentLastClass, models.DefaultIntentEnd, models.DefaultIntentNone},
+	"parse-mode":           {models.ParseModePermissive, models.ParseModeStrict, models.ParseModeLenient},
+	"provenance":           {models.ProvenanceModeEmbed, models.ProvenanceModeSidecar},
+	"bump-version":         {models.VersionBumpMajor, models.VersionBumpMinor, models.VersionBumpPatch},
+}
+
+// registerFlagCompletions wires shell completion for the flags of cmd and its subcommands.
+// Flags a command does not define are skipped, so every command can go through it.
+func registerFlagCompletions(cmd *cobra.Command) {
+	if cmd.Flags().Lookup("from") != nil {
+		cmd.RegisterFlagCompletionFunc("from", completePlatforms)
+	}
+	if cmd.Flags().Lookup("to") != nil {
+		cmd.RegisterFlagCompletionFunc("to", completePlatforms)
+	}
+	if cmd.Flags().Lookup("platform") != nil {
+		cmd.RegisterFlagCompletionFunc("platform", completePlatforms)
+	}
+	for flag, values := range flagValueCompletions {
+		if cmd.Flags().Lookup(flag) != nil {
+			cmd.RegisterFlagCompletionFunc(flag, cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp))
+		}
+	}
+	if cmd.Flags().Lookup("input") != nil {
+		cmd.MarkFlagFilename("input", "yml", "yaml", "zip", "json")
+	}
+	for _, flag := range []string{"input-dir", "output-dir", "dir"} {
+		if cmd.Flags().Lookup(flag) != nil {
+			cmd.MarkFlagDirname(flag)
+		}
+	}
+
+	for _, child := range cmd.Commands() {
+		registerFlagCompletions(child)
+	}
+}
+
+// completePlatforms completes the registered platforms; a platform given to both --from and --to
+// makes an identity conversion
+func completePlatforms(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	service, err := core.InitializeArchitecture()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var platforms []string
+	for _, platform := range service.SupportedPlatforms() {
+		name := string(platform)
+		if strings.HasPrefix(name, toComplete) {
+			platforms = append(platforms, name)
+		}
+	}
+	return platforms, cobra.ShellCompDirectiveNoFileComp
+}
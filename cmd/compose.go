@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// Options of the compose command
+var (
+	composeInputs  []string
+	composeMapping map[string]string
+)
+
+// NewComposeCmd creates the compose command
+func NewComposeCmd() *cobra.Command {
+	var composeCmd = &cobra.Command{
+		Use:   "compose",
+		Short: "Chain workflows into one",
+		Long: `Append workflows one after another and generate the result for any platform, to build
+pipelines from converted building blocks without editing graphs by hand.
+
+The end node of a workflow and the start node of the next one are dropped; the next workflow
+reads its start variables from the end outputs of the previous one, matched by name or with
+--map variable=output. Variables without a matching output become start variables of the
+composed workflow. The inputs may come from different platforms.`,
+		Example: `  # Run a Dify summarizer after an iFlytek retrieval agent, as one iFlytek agent
+  agentbridge compose --input retrieve.yml --input summarize.yml --to iflytek --output pipeline.yml
+
+  # Feed the "answer" output of the first workflow into the "text" input of the second
+  agentbridge compose -i a.yml -i b.yml --map text=answer --to dify --output ab.yml`,
+		RunE: runCompose,
+	}
+
+	composeCmd.Flags().StringSliceVarP(&composeInputs, "input", "i", nil, "Workflow files in execution order, at least two (required)")
+	composeCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output DSL file path (required)")
+	composeCmd.Flags().StringVar(&targetType, "to", "", "Target platform (iflytek|dify|coze|unified) (required)")
+	composeCmd.Flags().StringToStringVar(&composeMapping, "map", nil, "Start variable of a workflow fed by an end output of the previous one, e.g. text=answer")
+	composeCmd.Flags().StringVar(&debugDir, "debug-dir", "", "Save intermediate results below this directory: the unified DSL of each input in a numbered subdirectory, the composed one at the top")
+	composeCmd.Flags().StringVar(&outputFormat, "output-format", models.OutputFormatYAML, "Output serialization (yaml|json); json is supported for dify, coze and unified targets")
+
+	composeCmd.MarkFlagRequired("input")
+	composeCmd.MarkFlagRequired("output")
+	composeCmd.MarkFlagRequired("to")
+
+	return composeCmd
+}
+
+// runCompose executes the compose command
+func runCompose(cmd *cobra.Command, args []string) error {
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+	if quiet {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	} else {
+		printHeader("Workflow Composition")
+	}
+	startTime := time.Now()
+
+	if len(composeInputs) < 2 {
+		return fmt.Errorf("compose needs at least two --input files, got %d", len(composeInputs))
+	}
+	switch models.PlatformType(targetType) {
+	case models.PlatformIFlytek, models.PlatformDify, models.PlatformCoze, models.PlatformUnified:
+	default:
+		return fmt.Errorf("unsupported target platform: %s, supported platforms: [iflytek dify coze unified]", targetType)
+	}
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+
+	sources, err := readComposeSources()
+	if err != nil {
+		return err
+	}
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+
+	ctx, cancel := conversionContext(context.Background())
+	defer cancel()
+	target := models.PlatformType(targetType)
+	options := buildConversionOptions()
+	options.DebugDir = debugDir
+	result, err := conversionService.ComposeWithResult(ctx, sources, target, composeMapping, options)
+	recordConversion("compose", sources[0].Platform, target, result, err, time.Since(startTime))
+	if err != nil {
+		return fmt.Errorf("composition failed: %w", timeoutError(err))
+	}
+	cmd.SilenceUsage = true
+	for _, warning := range result.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+
+	if err := createOutputDirectory(); err != nil {
+		return err
+	}
+	if err := writeOutputFile(result.Output); err != nil {
+		return err
+	}
+	if !quiet {
+		fmt.Printf("✅ Composed %d workflows into %s (%s, %d bytes) in %v\n",
+			len(sources), outputFile, target, len(result.Output), time.Since(startTime))
+	}
+	return nil
+}
+
+// readComposeSources reads the input files and detects their platforms
+func readComposeSources() ([]services.ComposeSource, error) {
+	sources := make([]services.ComposeSource, 0, len(composeInputs))
+	for _, path := range composeInputs {
+		if err := validateInputFile(path); err != nil {
+			return nil, fmt.Errorf("input file validation failed: %w", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input file: %w", err)
+		}
+		platform, err := detectSourceType(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		sources = append(sources, services.ComposeSource{
+			Data:     data,
+			Platform: models.PlatformType(platform),
+			Name:     strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		})
+	}
+	return sources, nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/iflytek/agentbridge/internal/cache"
+	"github.com/iflytek/agentbridge/internal/config"
+	"github.com/iflytek/agentbridge/internal/models"
+	iflytekGenerator "github.com/iflytek/agentbridge/platforms/iflytek/generator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	configFile  string
+	profileName string
+
+	// Values only available through the config file
+	modelMap   map[string]string
+	iconSet    *models.IconSet
+	accounts   map[string]config.AccountProfile
+	lintConfig models.LintConfig
+)
+
+// loadConfigProfile loads the config file and applies the selected profile to flags
+// that were not set on the command line
+func loadConfigProfile(cmd *cobra.Command, args []string) error {
+	path := configFile
+	explicit := path != ""
+	if !explicit {
+		path = config.DefaultPath()
+	}
+
+	file, err := config.Load(path, explicit)
+	if err != nil {
+		return err
+	}
+
+	profile, err := file.Resolve(profileName)
+	if err != nil {
+		return err
+	}
+
+	applyConfigProfile(cmd, profile)
+	return nil
+}
+
+// applyConfigProfile copies profile values into flag variables; explicit flags win
+func applyConfigProfile(cmd *cobra.Command, profile *config.Profile) {
+	setBool(cmd, "verbose", &verbose, profile.Verbose)
+	setBool(cmd, "quiet", &quiet, profile.Quiet)
+	setBool(cmd, "overwrite", &overwriteMode, profile.Overwrite)
+	setBool(cmd, "keep-titles", &keepTitles, profile.KeepTitles)
+
+	if profile.Workers > 0 && !flagChanged(cmd, "workers") {
+		workerCount = profile.Workers
+	}
+
+	setString(cmd, "target-version", &targetVersion, profile.TargetVersion)
+	setString(cmd, "title-prefix", &titlePrefix, profile.TitlePrefix)
+	setString(cmd, "title-suffix", &titleSuffix, profile.TitleSuffix)
+	setString(cmd, "title-template", &titleTemplate, profile.TitleTemplate)
+	setString(cmd, "placeholder-strategy", &placeholderStrategy, profile.PlaceholderStrategy)
+	setString(cmd, "audio-strategy", &audioStrategy, profile.AudioStrategy)
+	setString(cmd, "trigger-strategy", &triggerStrategy, profile.TriggerStrategy)
+	setString(cmd, "default-intent", &defaultIntent, profile.DefaultIntent)
+	setString(cmd, "parse-mode", &parseMode, profile.ParseMode)
+
+	// Environment variables take precedence over the config file for the Spark identity
+	if os.Getenv(iflytekGenerator.EnvSparkAppID) == "" {
+		setString(cmd, "iflytek-app-id", &iflytekAppID, profile.IFlytek.AppID)
+	}
+	if os.Getenv(iflytekGenerator.EnvSparkUID) == "" {
+		setString(cmd, "iflytek-uid", &iflytekUID, profile.IFlytek.UID)
+	}
+	if os.Getenv(cache.EnvCacheDir) == "" {
+		setString(cmd, "cache-dir", &cacheDir, profile.CacheDir)
+	}
+
+	modelMap = profile.ModelMap
+	statsFile = profile.StatsFile
+	iconSet = &profile.Icons
+	accounts = profile.Accounts
+	lintConfig = profile.Lint
+}
+
+// flagChanged reports whether a local or inherited flag was set on the command line
+func flagChanged(cmd *cobra.Command, name string) bool {
+	flag := cmd.Flag(name)
+	return flag != nil && flag.Changed
+}
+
+func setBool(cmd *cobra.Command, name string, target *bool, value *bool) {
+	if value != nil && !flagChanged(cmd, name) {
+		*target = *value
+	}
+}
+
+func setString(cmd *cobra.Command, name string, target *string, value string) {
+	if value != "" && !flagChanged(cmd, name) {
+		*target = value
+	}
+}
@@ -1,17 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/core/services"
 	"github.com/iflytek/agentbridge/internal/models"
 
 	"github.com/spf13/cobra"
 )
 
+// Subgraph selection of the convert command
+var (
+	includeNodes []string
+	subgraphFrom string
+)
+
 // NewConvertCmd creates the convert command
 func NewConvertCmd() *cobra.Command {
 	var convertCmd = &cobra.Command{
@@ -24,6 +32,7 @@ func NewConvertCmd() *cobra.Command {
   • iFlytek Spark ↔ Coze Platform    ✅ Full Bidirectional
   • Support for Coze ZIP format      ✅ Auto-detection
   • Dify ↔ Coze                      ❌ Not Supported (use iFlytek as hub)
+  • Any platform ↔ Unified DSL       ✅ Export, edit and generate from the intermediate format
 
 📋 Technical Features:
   • Unified DSL intermediate representation
@@ -44,6 +53,46 @@ func NewConvertCmd() *cobra.Command {
   # Auto-detect source platform
   agentbridge convert --to coze --input agent.yml --output coze.yml
 
+  # Export the unified DSL, edit it, then generate any platform from it
+  agentbridge convert --from dify --to unified --input dify.yml --output workflow.unified.yml
+  agentbridge convert --from unified --to coze --input workflow.unified.yml --output coze.yml
+
+  # Write JSON for post-processing with jq
+  agentbridge convert --from iflytek --to dify --input agent.yml --output dify.json --output-format json
+
+  # Shrink the generated DSL and report the savings
+  agentbridge convert --from dify --to iflytek --input dify.yml --output agent.yml --minify
+
+  # Emit DSL for an older Dify release
+  agentbridge convert --from iflytek --to dify --input agent.yml --output dify.yml --target-version 0.15.x
+
+  # Mark migrated nodes in their titles
+  agentbridge convert --from dify --to iflytek --input dify.yml --output agent.yml --title-template "{{title}} (migrated)"
+
+  # Record provenance next to the output (dify.yml.provenance.json)
+  agentbridge convert --from iflytek --to dify --input agent.yml --output dify.yml --provenance sidecar
+
+  # Sign the output for a regulated import (agent.yml.sha256, agent.yml.sig)
+  agentbridge convert --from dify --to iflytek --input dify.yml --output agent.yml --checksum --sign key.pem
+
+  # Export node/output/branch/intent ID mapping (coze.yml.mapping.json)
+  agentbridge convert --from iflytek --to coze --input agent.yml --output coze.yml --emit-mapping
+
+  # Re-convert an updated workflow, keeping the target IDs of unchanged nodes
+  agentbridge convert --from iflytek --to coze --input agent.yml --output coze.yml --previous-mapping coze.yml.mapping.json --emit-mapping
+
+  # Prefix every LLM system prompt through a hook script
+  agentbridge convert --from dify --to iflytek --input dify.yml --output agent.yml --hook-script hooks.yml
+
+  # Enforce enterprise conversion rules
+  agentbridge convert --from dify --to iflytek --input dify.yml --output agent.yml --policy policy.yml
+
+  # Convert only node 1718000000001 and what follows it, e.g. to reuse that part in another agent
+  agentbridge convert --from dify --to iflytek --input dify.yml --output part.yml --subgraph-from 1718000000001
+
+  # Strip proprietary content before attaching a workflow to a bug report
+  agentbridge convert --from iflytek --to dify --input agent.yml --output shared.yml --anonymize
+
   # Detailed conversion process
   agentbridge convert --from iflytek --to coze --input agent.yml --output coze.yml --verbose`,
 		RunE: runConvert,
@@ -52,8 +101,13 @@ func NewConvertCmd() *cobra.Command {
 	// Configure convert command flags
 	convertCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input DSL file path (required)")
 	convertCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output DSL file path (required)")
-	convertCmd.Flags().StringVar(&sourceType, "from", "", "Source platform (iflytek|dify|coze, auto-detect if not specified)")
-	convertCmd.Flags().StringVar(&targetType, "to", "", "Target platform (iflytek|dify|coze) (required)")
+	convertCmd.Flags().StringVar(&sourceType, "from", "", "Source platform (iflytek|dify|coze|unified, auto-detect if not specified)")
+	convertCmd.Flags().StringVar(&targetType, "to", "", "Target platform (iflytek|dify|coze|unified) (required)")
+	convertCmd.Flags().StringVar(&outputFormat, "output-format", models.OutputFormatYAML, "Output serialization (yaml|json); json is supported for dify, coze and unified targets")
+	convertCmd.Flags().StringVar(&previousMappingFile, "previous-mapping", "", "ID mapping file from an earlier conversion; unchanged nodes keep their target IDs")
+	convertCmd.Flags().StringSliceVar(&includeNodes, "include-nodes", nil, "Convert only these top-level nodes, comma-separated IDs; start and end nodes are added as needed")
+	convertCmd.Flags().StringVar(&subgraphFrom, "subgraph-from", "", "Convert only this node and the nodes downstream of it")
+	addGenerationFlags(convertCmd)
 
 	// Mark required flags
 	convertCmd.MarkFlagRequired("input")
@@ -121,6 +175,21 @@ func initializeAndValidateInput() ([]byte, error) {
 		return nil, fmt.Errorf("input file validation failed: %w", err)
 	}
 
+	if err := loadHookScript(); err != nil {
+		return nil, err
+	}
+
+	if err := loadPolicy(); err != nil {
+		return nil, err
+	}
+	if err := loadValidationRules(); err != nil {
+		return nil, err
+	}
+
+	if err := loadSigningKey(); err != nil {
+		return nil, err
+	}
+
 	// Read input file
 	if verbose {
 		fmt.Printf("📖 Reading input file: %s\n", inputFile)
@@ -142,14 +211,41 @@ func initializeAndValidateInput() ([]byte, error) {
 func detectAndValidateSourceFormat(inputData []byte) error {
 	// Auto-detect source format (if not specified)
 	if sourceType == "" {
-		sourceType = detectSourceType(inputData)
-		if verbose {
-			fmt.Printf("🔍 Auto-detected source platform: %s\n", sourceType)
+		detected, err := detectSourceType(inputData)
+		if err != nil {
+			return err
 		}
+		sourceType = detected
 	}
 
 	// Validate format types
-	return validateFormatTypes(sourceType, targetType)
+	if err := validateFormatTypes(sourceType, targetType); err != nil {
+		return err
+	}
+
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+	return validateProvenanceMode()
+}
+
+// validateOutputFormat checks the --output-format flag against the target and the provenance mode
+func validateOutputFormat() error {
+	switch outputFormat {
+	case models.OutputFormatYAML:
+		return nil
+	case models.OutputFormatJSON:
+	default:
+		return fmt.Errorf("invalid output format '%s' (expected %s|%s)", outputFormat, models.OutputFormatYAML, models.OutputFormatJSON)
+	}
+
+	if targetType == string(models.PlatformIFlytek) {
+		return fmt.Errorf("iFlytek Spark imports YAML DSL only, use --output-format %s", models.OutputFormatYAML)
+	}
+	if provenanceMode == models.ProvenanceModeEmbed {
+		return fmt.Errorf("--provenance embed writes a YAML comment, use --provenance sidecar with --output-format json")
+	}
+	return nil
 }
 
 // executeConversion performs the actual DSL conversion
@@ -158,18 +254,24 @@ func executeConversion(inputData []byte) ([]byte, error) {
 		fmt.Printf("🔄 Starting conversion: %s → %s\n", sourceType, targetType)
 	}
 
-	var outputData []byte
+	var result *services.ConversionResult
 	var err error
 
 	switch {
 	case sourceType == "iflytek" && targetType == "dify":
-		outputData, err = convertBetweenPlatforms(inputData, models.PlatformIFlytek, models.PlatformDify)
+		result, err = convertBetweenPlatforms(inputData, models.PlatformIFlytek, models.PlatformDify)
 	case sourceType == "dify" && targetType == "iflytek":
-		outputData, err = convertBetweenPlatforms(inputData, models.PlatformDify, models.PlatformIFlytek)
+		result, err = convertBetweenPlatforms(inputData, models.PlatformDify, models.PlatformIFlytek)
 	case sourceType == "iflytek" && targetType == "coze":
-		outputData, err = convertBetweenPlatforms(inputData, models.PlatformIFlytek, models.PlatformCoze)
+		result, err = convertBetweenPlatforms(inputData, models.PlatformIFlytek, models.PlatformCoze)
 	case sourceType == "coze" && targetType == "iflytek":
-		outputData, err = convertBetweenPlatforms(inputData, models.PlatformCoze, models.PlatformIFlytek)
+		result, err = convertBetweenPlatforms(inputData, models.PlatformCoze, models.PlatformIFlytek)
+	case sourceType == targetType:
+		// Identity conversion, checked against the source
+		result, err = convertBetweenPlatforms(inputData, models.PlatformType(sourceType), models.PlatformType(targetType))
+	case sourceType == "unified" || targetType == "unified":
+		// Exports and imports of the intermediate format
+		result, err = convertBetweenPlatforms(inputData, models.PlatformType(sourceType), models.PlatformType(targetType))
 	default:
 		return nil, fmt.Errorf("unsupported conversion path: %s → %s", sourceType, targetType)
 	}
@@ -178,7 +280,7 @@ func executeConversion(inputData []byte) ([]byte, error) {
 		return nil, fmt.Errorf("conversion failed: %w", err)
 	}
 
-	return outputData, nil
+	return finalizeConversionOutput(inputFile, outputFile, inputData, result)
 }
 
 // writeOutputAndReport writes output file and reports conversion results
@@ -231,22 +333,63 @@ func reportConversionResults(inputData, outputData []byte, startTime time.Time)
 }
 
 // convertBetweenPlatforms performs conversion between platforms
-func convertBetweenPlatforms(inputData []byte, fromPlatform, toPlatform models.PlatformType) ([]byte, error) {
+func convertBetweenPlatforms(inputData []byte, fromPlatform, toPlatform models.PlatformType) (*services.ConversionResult, error) {
 	// Initialize conversion service
 	conversionService, err := core.InitializeArchitecture()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize architecture: %w", err)
 	}
 
+	options := buildConversionOptions()
+	options.DebugDir = debugArtifactDir(filepath.Dir(inputFile), inputFile)
+	options.IncludeNodes = includeNodes
+	options.SubgraphFrom = subgraphFrom
+	if verbose {
+		options.Progress = models.ProgressFunc(printProgress())
+	}
+	if err := loadPreviousMapping(options); err != nil {
+		return nil, err
+	}
+
 	// Execute conversion
-	outputData, err := conversionService.Convert(inputData, fromPlatform, toPlatform)
+	ctx, cancel := conversionContext(context.Background())
+	defer cancel()
+	conversionStart := time.Now()
+	result, err := conversionService.ConvertWithResult(ctx, inputData, fromPlatform, toPlatform, options)
+	recordConversion("convert", fromPlatform, toPlatform, result, err, time.Since(conversionStart))
 	if err != nil {
-		return nil, fmt.Errorf("conversion failed: %w", err)
+		return nil, fmt.Errorf("conversion failed: %w", timeoutError(err))
 	}
 
 	if verbose {
 		fmt.Printf("   Conversion completed\n")
 	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	if report := result.MinifyReport; report != nil {
+		fmt.Printf("📦 Minified: %d → %d bytes (%.1f%% smaller, %d default fields removed, %d strings interned)\n",
+			report.OriginalBytes, report.MinifiedBytes, report.SavedPercent(), report.RemovedFields, report.InternedStrings)
+	}
 
-	return outputData, nil
+	return result, nil
+}
+
+// printProgress returns a progress callback printing each stage and the nodes it works on
+func printProgress() func(models.ProgressEvent) {
+	var stage models.ProgressStage
+	return func(event models.ProgressEvent) {
+		if event.Stage != stage {
+			stage = event.Stage
+			fmt.Printf("   [%3d%%] %s\n", event.Percent, event.Stage)
+		}
+		if event.NodeID == "" {
+			return
+		}
+		name := event.NodeID
+		if event.NodeTitle != "" {
+			name = fmt.Sprintf("%s (%s)", event.NodeTitle, event.NodeID)
+		}
+		fmt.Printf("   [%3d%%]   %s\n", event.Percent, name)
+	}
 }
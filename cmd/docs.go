@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// Docs command flags
+var (
+	docsMan      bool
+	docsMarkdown bool
+	docsDir      string
+)
+
+// NewDocsCmd creates the docs command
+func NewDocsCmd() *cobra.Command {
+	var docsCmd = &cobra.Command{
+		Use:   "docs",
+		Short: "Generate man pages or Markdown reference for every command",
+		Long: `Generate the command reference from the CLI definition, for distribution packages and docs sites.
+
+--man writes one roff page per command (agentbridge.1, agentbridge-convert.1, ...), ready to be
+installed under share/man/man1. --markdown writes one Markdown file per command. Man pages are
+dated with SOURCE_DATE_EPOCH when it is set, for reproducible package builds.
+
+Shell completion scripts are generated by the completion command.`,
+		Example: `  # Man pages for a package build
+  agentbridge docs --man --dir ./man
+
+  # Markdown reference
+  agentbridge docs --markdown --dir ./docs/cli
+
+  # Completion scripts
+  agentbridge completion bash > /usr/share/bash-completion/completions/agentbridge
+  agentbridge completion zsh > /usr/share/zsh/site-functions/_agentbridge
+  agentbridge completion fish > /usr/share/fish/vendor_completions.d/agentbridge.fish`,
+		RunE: runDocs,
+	}
+
+	docsCmd.Flags().BoolVar(&docsMan, "man", false, "Generate man pages (section 1)")
+	docsCmd.Flags().BoolVar(&docsMarkdown, "markdown", false, "Generate Markdown files")
+	docsCmd.Flags().StringVar(&docsDir, "dir", "", "Output directory (required)")
+
+	docsCmd.MarkFlagRequired("dir")
+	docsCmd.MarkFlagsOneRequired("man", "markdown")
+
+	return docsCmd
+}
+
+// runDocs executes the docs command
+func runDocs(cmd *cobra.Command, args []string) error {
+	if err := os.MkdirAll(docsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	root := cmd.Root()
+	root.DisableAutoGenTag = true
+
+	if docsMan {
+		header := &doc.GenManHeader{
+			Title:   "AGENTBRIDGE",
+			Section: "1",
+			Source:  fmt.Sprintf("%s %s", appName, getVersion()),
+			Manual:  "AgentBridge Manual",
+		}
+		if err := doc.GenManTree(root, header, docsDir); err != nil {
+			return fmt.Errorf("failed to generate man pages: %w", err)
+		}
+	}
+
+	if docsMarkdown {
+		if err := doc.GenMarkdownTree(root, docsDir); err != nil {
+			return fmt.Errorf("failed to generate Markdown docs: %w", err)
+		}
+	}
+
+	if !quiet {
+		fmt.Printf("✅ Documentation written to %s\n", docsDir)
+	}
+	return nil
+}
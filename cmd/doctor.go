@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/config"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+
+	"github.com/spf13/cobra"
+)
+
+// NewDoctorCmd creates the doctor command
+func NewDoctorCmd() *cobra.Command {
+	var doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the environment and an input file, with fixes",
+		Long: `Check the installation and, with --input, an exported DSL file for the problems behind most
+failed conversions, and print a fix for each one found.
+
+Environment: version, config file and profile, and the python3 and node interpreters that
+simulate --run-code uses. Input: encoding (UTF-8, byte order marks, UTF-16), line endings, YAML
+syntax and tab indentation, Coze ZIP exports (damaged archives, base64 encoded ones, missing
+workflow or manifest), platform detection confidence, and whether the file parses.
+
+The command exits with an error when a problem blocks conversion. A broken config file is
+reported rather than stopping the command.`,
+		Example: `  # Check the installation
+  agentbridge doctor
+
+  # Triage an export that fails to convert
+  agentbridge doctor --input export.yml`,
+		Args: cobra.NoArgs,
+		// The config file is checked, not applied; profiling still starts
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return startProfiling() },
+		RunE:              runDoctor,
+	}
+
+	doctorCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Exported DSL file to diagnose")
+	doctorCmd.Flags().StringVar(&sourceType, "from", "", "Source platform to parse the file as (default: detected)")
+
+	return doctorCmd
+}
+
+// runDoctor executes the doctor command
+func runDoctor(cmd *cobra.Command, args []string) error {
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+	cmd.SilenceUsage = true
+	if !quiet {
+		printHeader("Doctor")
+	}
+
+	fmt.Println("Environment")
+	problems := printDiagnoses(diagnoseEnvironment())
+
+	if inputFile != "" {
+		fmt.Printf("\nInput %s\n", inputFile)
+		data, err := os.ReadFile(inputFile)
+		if err != nil {
+			return withExitCode(ExitIO, fmt.Errorf("failed to read input file: %w", err))
+		}
+		diagnoses := common.DiagnoseInput(data)
+		diagnoses = append(diagnoses, diagnoseParse(data, diagnoses)...)
+		problems += printDiagnoses(diagnoses)
+	}
+
+	if problems > 0 {
+		return withExitCode(ExitValidation, fmt.Errorf("doctor found %d problem(s) blocking conversion", problems))
+	}
+	fmt.Println("\n✅ No blocking problems found")
+	return nil
+}
+
+// diagnoseEnvironment checks the installation
+func diagnoseEnvironment() []common.Diagnosis {
+	diagnoses := []common.Diagnosis{{
+		Check:    "version",
+		Severity: models.SeverityInfo,
+		Message:  fmt.Sprintf("agentbridge %s, %s, %s/%s", getVersion(), runtime.Version(), runtime.GOOS, runtime.GOARCH),
+	}}
+
+	path, explicit := configFile, configFile != ""
+	if !explicit {
+		path = config.DefaultPath()
+	}
+	file, err := config.Load(path, explicit)
+	if err == nil {
+		_, err = file.Resolve(profileName)
+	}
+	switch {
+	case err != nil:
+		diagnoses = append(diagnoses, common.Diagnosis{Check: "config", Severity: models.SeverityError, Message: err.Error(),
+			Fix: "fix the config file, or pass --config to use another one"})
+	case !fileExists(path):
+		diagnoses = append(diagnoses, common.Diagnosis{Check: "config", Severity: models.SeverityInfo, Message: "no config file (optional): " + path})
+	default:
+		diagnoses = append(diagnoses, common.Diagnosis{Check: "config", Severity: models.SeverityInfo, Message: path})
+	}
+
+	for _, interpreter := range []string{"python3", "node"} {
+		if location, err := exec.LookPath(interpreter); err == nil {
+			diagnoses = append(diagnoses, common.Diagnosis{Check: interpreter, Severity: models.SeverityInfo, Message: location})
+		} else {
+			diagnoses = append(diagnoses, common.Diagnosis{Check: interpreter, Severity: models.SeverityInfo, Message: "not found",
+				Fix: "install it to run code nodes with simulate --run-code; conversion does not need it"})
+		}
+	}
+	return diagnoses
+}
+
+// diagnoseParse parses the input once the file checks pass, as --from or the detected platform
+func diagnoseParse(data []byte, diagnoses []common.Diagnosis) []common.Diagnosis {
+	for _, diagnosis := range diagnoses {
+		if diagnosis.Severity == models.SeverityError {
+			return nil
+		}
+	}
+	platform := models.PlatformType(sourceType)
+	if platform == "" {
+		detection, err := common.DetectPlatform(data)
+		if err != nil {
+			return nil
+		}
+		platform = detection.Platform
+	}
+
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return []common.Diagnosis{{Check: "parse", Severity: models.SeverityError, Message: err.Error()}}
+	}
+	restore := discardStdout()
+	unifiedDSL, err := conversionService.Parse(data, platform)
+	restore()
+	if err != nil {
+		return []common.Diagnosis{{Check: "parse", Severity: models.SeverityError, Message: fmt.Sprintf("as %s: %v", platform, err),
+			Fix: fmt.Sprintf("run agentbridge validate --from %s --input %s for the full report", platform, inputFile)}}
+	}
+	return []common.Diagnosis{{Check: "parse", Severity: models.SeverityInfo,
+		Message: fmt.Sprintf("parses as %s: %d nodes, %d edges", platform, len(unifiedDSL.Workflow.Nodes), len(unifiedDSL.Workflow.Edges))}}
+}
+
+// printDiagnoses prints each diagnosis with its fix and returns the number of errors
+func printDiagnoses(diagnoses []common.Diagnosis) int {
+	errors := 0
+	for _, diagnosis := range diagnoses {
+		icon := "ℹ️ "
+		switch {
+		case diagnosis.Passed():
+			icon = "✅"
+		case diagnosis.Severity == models.SeverityError:
+			icon = "❌"
+			errors++
+		case diagnosis.Severity == models.SeverityWarning:
+			icon = "⚠️ "
+		}
+		fmt.Printf("  %s %s: %s\n", icon, diagnosis.Check, diagnosis.Message)
+		if diagnosis.Fix != "" {
+			fmt.Printf("     → %s\n", diagnosis.Fix)
+		}
+	}
+	return errors
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Exit codes, one per failure category, so scripts and orchestrators can branch on the outcome
+const (
+	ExitOK         = 0
+	ExitFailure    = 1 // Usage errors and failures of no other category
+	ExitParse      = 2 // The source could not be read as a workflow of its platform
+	ExitValidation = 3 // Validation, rules or the policy rejected the workflow
+	ExitGeneration = 4 // The target DSL could not be generated
+	ExitIO         = 5 // Files could not be read or written
+)
+
+// EnvJSONSummary enables the JSON summary line like --json-summary, for container entrypoints
+const EnvJSONSummary = "AGENTBRIDGE_JSON_SUMMARY"
+
+// exitCategories names the exit codes in the JSON summary
+var exitCategories = map[int]string{
+	ExitOK:         "ok",
+	ExitFailure:    "error",
+	ExitParse:      "parse",
+	ExitValidation: "validation",
+	ExitGeneration: "generation",
+	ExitIO:         "io",
+}
+
+// jsonSummary is set by --json-summary
+var jsonSummary bool
+
+// exitError gives an error an exit code its type does not tell
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode makes the command exit with code when it fails with err
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// ExitCodeFor classifies the error a command failed with into its exit code
+func ExitCodeFor(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var coded *exitError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) || errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+		return ExitIO
+	}
+	var parseErr *models.ParseError
+	if errors.As(err, &parseErr) {
+		return ExitParse
+	}
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		return ExitValidation
+	}
+	var conversionErr *models.ConversionError
+	if errors.As(err, &conversionErr) {
+		switch conversionErr.Code {
+		case "PARSE_FAILED", "PARSER_NOT_FOUND":
+			return ExitParse
+		case "POLICY_VIOLATION":
+			return ExitValidation
+		case "GENERATION_FAILED", "GENERATOR_NOT_FOUND", "MINIFY_FAILED", "COMPOSITION_FAILED",
+			"UNSUPPORTED_NODES", "UNSUPPORTED_CONDITION", "UNSUPPORTED_OPERATOR", "IDENTITY_CHANGED":
+			return ExitGeneration
+		}
+	}
+	return ExitFailure
+}
+
+// runSummary accumulates the conversions of a command for the JSON summary; batch workers add
+// to it concurrently
+var runSummary struct {
+	mu           sync.Mutex
+	converted    int
+	failed       int
+	nodes        int
+	placeholders int
+	warnings     int
+	failureCodes map[int]int // Exit code -> failed conversions
+}
+
+// summarizeConversion adds a conversion to the run summary
+func summarizeConversion(result *services.ConversionResult, err error) {
+	runSummary.mu.Lock()
+	defer runSummary.mu.Unlock()
+	if err != nil {
+		runSummary.failed++
+		if runSummary.failureCodes == nil {
+			runSummary.failureCodes = make(map[int]int)
+		}
+		runSummary.failureCodes[ExitCodeFor(err)]++
+		return
+	}
+	runSummary.converted++
+	if result == nil {
+		return
+	}
+	for _, count := range result.NodeTypes {
+		runSummary.nodes += count
+	}
+	runSummary.placeholders += result.Placeholders
+	runSummary.warnings += len(result.Warnings)
+}
+
+// commandExitCode is the exit code of a failed command. Commands failing because some of their
+// conversions failed, like batch, take the category of those failures when they share one.
+func commandExitCode(err error) int {
+	code := ExitCodeFor(err)
+	if code == ExitFailure && len(runSummary.failureCodes) == 1 {
+		for failureCode := range runSummary.failureCodes {
+			return failureCode
+		}
+	}
+	return code
+}
+
+// jsonSummaryEnabled reports whether the JSON summary line is requested
+func jsonSummaryEnabled() bool {
+	if jsonSummary {
+		return true
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv(EnvJSONSummary))
+	return enabled
+}
+
+// writeJSONSummary prints the final summary line of a command on stderr. Fidelity is the share of
+// source nodes converted to native target nodes rather than placeholders.
+func writeJSONSummary(command string, err error, code int, elapsed time.Duration) {
+	summary := struct {
+		Command      string   `json:"command"`
+		Success      bool     `json:"success"`
+		ExitCode     int      `json:"exit_code"`
+		Category     string   `json:"category"`
+		Error        string   `json:"error,omitempty"`
+		Converted    int      `json:"files_converted"`
+		Failed       int      `json:"files_failed"`
+		Nodes        int      `json:"nodes"`
+		Placeholders int      `json:"placeholders"`
+		Warnings     int      `json:"warnings"`
+		Fidelity     *float64 `json:"fidelity,omitempty"`
+		DurationMS   int64    `json:"duration_ms"`
+	}{
+		Command:      command,
+		Success:      err == nil,
+		ExitCode:     code,
+		Category:     exitCategories[code],
+		Converted:    runSummary.converted,
+		Failed:       runSummary.failed,
+		Nodes:        runSummary.nodes,
+		Placeholders: runSummary.placeholders,
+		Warnings:     runSummary.warnings,
+		DurationMS:   elapsed.Milliseconds(),
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	if runSummary.nodes > 0 {
+		fidelity := float64(runSummary.nodes-runSummary.placeholders) / float64(runSummary.nodes)
+		summary.Fidelity = &fidelity
+	}
+
+	data, marshalErr := json.Marshal(summary)
+	if marshalErr != nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, string(data))
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// hookScriptFile is a YAML hook script that edits unified nodes during conversion
+var hookScriptFile string
+
+// nodeHooks holds the loaded hook script, shared by every conversion of a command
+var nodeHooks []models.NodeHook
+
+// loadHookScript reads the --hook-script file
+func loadHookScript() error {
+	if hookScriptFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(hookScriptFile)
+	if err != nil {
+		return fmt.Errorf("failed to read hook script: %w", err)
+	}
+
+	script, err := common.ParseHookScript(data)
+	if err != nil {
+		return fmt.Errorf("failed to load hook script %s: %w", hookScriptFile, err)
+	}
+
+	nodeHooks = []models.NodeHook{script}
+	return nil
+}
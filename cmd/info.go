@@ -2,8 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+
 	"github.com/spf13/cobra"
 )
 
@@ -14,10 +20,17 @@ func NewInfoCmd() *cobra.Command {
 		Short: "Display tool information",
 		Long: `Display detailed information about the tool, including supported node types, data type mappings, and more.
 
-This command provides comprehensive information about the converter's capabilities.`,
+This command provides comprehensive information about the converter's capabilities.
+
+With --input it prints the statistics of a workflow instead: nodes per type, the longest path,
+iteration sub-workflows, branches and variable reference fan-in/out, to estimate the effort of a
+migration and spot excessively large workflows before converting them.`,
 		Example: `  # Show supported node types
   agentbridge info --nodes
 
+  # Show the statistics of a workflow
+  agentbridge info --input agent.yml
+
   # Show data type mappings
   agentbridge info --types
 
@@ -30,6 +43,8 @@ This command provides comprehensive information about the converter's capabiliti
 	infoCmd.Flags().BoolVar(&showNodes, "nodes", false, "Show supported node types")
 	infoCmd.Flags().BoolVar(&showTypes, "types", false, "Show data type mappings")
 	infoCmd.Flags().BoolVar(&showAll, "all", false, "Show all information")
+	infoCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Show the statistics of this workflow file")
+	infoCmd.Flags().StringVar(&sourceType, "from", "", "Platform of the file (iflytek|dify|coze|unified, auto-detect if not specified)")
 
 	return infoCmd
 }
@@ -48,6 +63,10 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		printDataTypeMapping()
 	}
 
+	if inputFile != "" {
+		return printWorkflowStats()
+	}
+
 	if !showNodes && !showTypes && !showAll {
 		printGeneralInfo()
 	}
@@ -55,6 +74,63 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printWorkflowStats parses the input file and prints its statistics
+func printWorkflowStats() error {
+	if err := validateInputFile(inputFile); err != nil {
+		return fmt.Errorf("input file validation failed: %w", err)
+	}
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+	if sourceType == "" {
+		detected, err := detectSourceType(data)
+		if err != nil {
+			return err
+		}
+		sourceType = detected
+	}
+	platform := models.PlatformType(sourceType)
+
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+	unifiedDSL, err := conversionService.Parse(data, platform)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s DSL: %w", platform, err)
+	}
+	stats := common.ComputeWorkflowStats(unifiedDSL)
+
+	fmt.Printf("\n📈 Workflow Statistics: %s (%s)\n", inputFile, platform)
+	fmt.Printf("   Nodes: %d (%d top-level), edges: %d\n", stats.Nodes, len(unifiedDSL.Workflow.Nodes), stats.Edges)
+	fmt.Printf("   Max depth: %d nodes on the longest path\n", stats.MaxDepth)
+	fmt.Printf("   Iteration sub-workflows: %d (max nesting %d)\n", stats.Iterations, stats.MaxNesting)
+	fmt.Printf("   Branching nodes: %d with %d branches (max %d on one node)\n", stats.BranchNodes, stats.Branches, stats.MaxBranches)
+	fmt.Printf("   Variable references: %d\n", stats.References)
+	if stats.MaxFanIn.Count > 0 {
+		fmt.Printf("   Max fan-in: %s (%s) reads %d nodes\n", stats.MaxFanIn.Title, stats.MaxFanIn.NodeID, stats.MaxFanIn.Count)
+		fmt.Printf("   Max fan-out: %s (%s) is read by %d nodes\n", stats.MaxFanOut.Title, stats.MaxFanOut.NodeID, stats.MaxFanOut.Count)
+	}
+
+	types := make([]string, 0, len(stats.NodeTypes))
+	for nodeType := range stats.NodeTypes {
+		types = append(types, string(nodeType))
+	}
+	sort.Slice(types, func(i, j int) bool {
+		countI, countJ := stats.NodeTypes[models.NodeType(types[i])], stats.NodeTypes[models.NodeType(types[j])]
+		if countI != countJ {
+			return countI > countJ
+		}
+		return types[i] < types[j]
+	})
+	fmt.Println("\n   Nodes by type:")
+	for _, nodeType := range types {
+		fmt.Printf("   %-20s %d\n", nodeType, stats.NodeTypes[models.NodeType(nodeType)])
+	}
+	return nil
+}
+
 // printSupportedNodes prints supported node types information
 func printSupportedNodes() {
 	fmt.Println("\n📋 Supported Node Types:")
@@ -86,26 +162,24 @@ func printSupportedNodes() {
 func printDataTypeMapping() {
 	fmt.Println("\n🔄 Data Type Mappings:")
 
-	dataTypes := []struct {
-		unified string
-		iflytek string
-		dify    string
-		desc    string
-	}{
-		{"string", "string", "string", "String type"},
-		{"number", "integer/number", "number", "Numeric type"},
-		{"boolean", "boolean", "boolean", "Boolean type"},
-		{"array[string]", "array-string", "array[string]", "String array"},
-		{"array[object]", "array-object", "array[object]", "Object array"},
-		{"object", "object", "object", "Object type"},
-	}
+	mapping := models.GetDefaultDataTypeMapping()
+	platforms := []models.PlatformType{models.PlatformIFlytek, models.PlatformDify, models.PlatformCoze}
 
-	fmt.Printf("%-15s %-15s %-15s %s\n", "Unified", "iFlytek Spark", "Dify", "Description")
+	fmt.Printf("%-15s %-15s %-15s %s\n", "Unified", "iFlytek Spark", "Dify", "Coze")
 	fmt.Println(strings.Repeat("-", 70))
 
-	for _, dt := range dataTypes {
-		fmt.Printf("%-15s %-15s %-15s %s\n", dt.unified, dt.iflytek, dt.dify, dt.desc)
+	for _, unifiedType := range mapping.UnifiedTypes {
+		columns := make([]string, 0, len(platforms))
+		for _, platform := range platforms {
+			column := mapping.ToPlatformType(platform, unifiedType)
+			if _, lossy := mapping.LossyConversion(platform, unifiedType); lossy {
+				column += " *"
+			}
+			columns = append(columns, column)
+		}
+		fmt.Printf("%-15s %-15s %-15s %s\n", unifiedType, columns[0], columns[1], columns[2])
 	}
+	fmt.Println("\n* Lossy: the platform has no matching type, conversions report a warning")
 }
 
 // printGeneralInfo prints general tool information
@@ -115,7 +189,7 @@ func printGeneralInfo() {
 	fmt.Printf("🔄 Conversion Paths: iFlytek↔Dify, iFlytek↔Coze (Star Architecture)\n")
 	fmt.Printf("📋 Supported Nodes: 7 fundamental node types\n")
 	fmt.Printf("🔗 Supported Connections: Default connections, conditional connections\n")
-	fmt.Printf("📊 Data Types: 6 unified data types\n")
+	fmt.Printf("📊 Data Types: %d unified data types\n", len(models.GetAllUnifiedTypes()))
 	fmt.Printf("⚡ Features: Bidirectional conversion, unsupported node placeholder conversion, ZIP format support\n")
 
 	fmt.Println("\n💡 Usage Tips:")
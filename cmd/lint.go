@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+
+	"github.com/spf13/cobra"
+)
+
+// Options of the lint command
+var (
+	lintDisable   []string
+	lintSeverity  map[string]string
+	lintFormat    string
+	lintStrict    bool
+	lintListRules bool
+)
+
+// NewLintCmd creates the lint command
+func NewLintCmd() *cobra.Command {
+	var lintCmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Check a workflow against best practices",
+		Long: `Check a workflow of any supported platform against best-practice rules, on the unified model
+so every platform gets the same checks: outputs and start variables nothing reads, LLM nodes
+without a temperature, iterations without an output selector and branches without a target.
+
+Rules are disabled with --disable and graded with --severity rule=error|warning|info|off, or in
+the lint section of the config file. The command exits with an error when a finding has error
+severity, or any warning with --strict.`,
+		Example: `  # Lint a Dify workflow
+  agentbridge lint --input dify.yml
+
+  # Skip the temperature rule and fail on unused outputs, e.g. in CI
+  agentbridge lint --input agent.yml --disable llm-temperature --severity unused-output=error
+
+  # List the rules
+  agentbridge lint --list-rules`,
+		RunE: runLint,
+	}
+
+	lintCmd.Flags().StringVarP(&inputFile, "input", "i", "", "DSL file to lint (required)")
+	lintCmd.Flags().StringVar(&sourceType, "from", "", "Platform of the file (iflytek|dify|coze|unified, auto-detect if not specified)")
+	lintCmd.Flags().StringSliceVar(&lintDisable, "disable", nil, "Rules not to run, comma-separated")
+	lintCmd.Flags().StringToStringVar(&lintSeverity, "severity", nil, "Severity of a rule, e.g. unused-output=error (error|warning|info|off)")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format (text|json)")
+	lintCmd.Flags().BoolVar(&lintStrict, "strict", false, "Exit with an error on warnings too")
+	lintCmd.Flags().BoolVar(&lintListRules, "list-rules", false, "List the rules with their default severity and exit")
+	lintCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	return lintCmd
+}
+
+// runLint executes the lint command
+func runLint(cmd *cobra.Command, args []string) error {
+	if lintListRules {
+		printLintRules()
+		return nil
+	}
+	if inputFile == "" {
+		return fmt.Errorf("required flag \"input\" not set")
+	}
+	if lintFormat != "text" && lintFormat != "json" {
+		return fmt.Errorf("invalid format %q, expected text or json", lintFormat)
+	}
+
+	if quiet {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	// JSON on stdout is the product of the command, even in quiet mode
+	reportOut := os.Stdout
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+	restoreParse := func() {}
+	if lintFormat == "json" {
+		restoreParse = discardStdout()
+	} else if !quiet {
+		printHeader("Workflow Lint")
+	}
+	platform, findings, err := executeLint()
+	restoreParse()
+	if err != nil {
+		return err
+	}
+
+	// Findings are not usage errors
+	cmd.SilenceUsage = true
+	if lintFormat == "json" {
+		if findings == nil {
+			findings = []common.LintFinding{}
+		}
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode findings: %w", err)
+		}
+		fmt.Fprintln(reportOut, string(data))
+	} else {
+		printLintFindings(platform, findings)
+	}
+	return lintResult(findings)
+}
+
+// executeLint parses the input file and lints it with the profile and flag configuration
+func executeLint() (models.PlatformType, []common.LintFinding, error) {
+	if err := validateInputFile(inputFile); err != nil {
+		return "", nil, fmt.Errorf("input file validation failed: %w", err)
+	}
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+	if sourceType == "" {
+		detected, err := detectSourceType(data)
+		if err != nil {
+			return "", nil, err
+		}
+		sourceType = detected
+	}
+	platform := models.PlatformType(sourceType)
+
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+	unifiedDSL, err := conversionService.Parse(data, platform)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse %s DSL: %w", platform, err)
+	}
+
+	findings, err := common.Lint(unifiedDSL, lintOptions())
+	if err != nil {
+		return "", nil, err
+	}
+	return platform, findings, nil
+}
+
+// lintOptions adds the flags to the lint configuration of the profile
+func lintOptions() models.LintConfig {
+	config := models.LintConfig{
+		Disable:  append(append([]string(nil), lintConfig.Disable...), lintDisable...),
+		Severity: make(map[string]models.ErrorSeverity, len(lintConfig.Severity)+len(lintSeverity)),
+	}
+	for rule, severity := range lintConfig.Severity {
+		config.Severity[rule] = severity
+	}
+	for rule, severity := range lintSeverity {
+		config.Severity[rule] = models.ErrorSeverity(severity)
+	}
+	return config
+}
+
+// lintSeverityIcons marks severities in the findings list
+var lintSeverityIcons = map[models.ErrorSeverity]string{
+	models.SeverityError:   "❌",
+	models.SeverityWarning: "⚠️ ",
+	models.SeverityInfo:    "ℹ️ ",
+}
+
+// printLintFindings lists the findings with a summary by severity
+func printLintFindings(platform models.PlatformType, findings []common.LintFinding) {
+	fmt.Printf("   File: %s\n", inputFile)
+	fmt.Printf("   Platform: %s\n\n", platform)
+	if len(findings) == 0 {
+		fmt.Println("✅ No findings")
+		return
+	}
+
+	counts := make(map[models.ErrorSeverity]int)
+	for _, finding := range findings {
+		counts[finding.Severity]++
+		location := ""
+		if finding.NodeID != "" {
+			location = fmt.Sprintf("%s (%s): ", finding.NodeTitle, finding.NodeID)
+		}
+		fmt.Printf("%s [%s] %s%s\n", lintSeverityIcons[finding.Severity], finding.Rule, location, finding.Message)
+	}
+	fmt.Printf("\n📊 %d errors, %d warnings, %d info\n",
+		counts[models.SeverityError], counts[models.SeverityWarning], counts[models.SeverityInfo])
+}
+
+// lintResult fails on error findings, and on warnings with --strict
+func lintResult(findings []common.LintFinding) error {
+	errors, warnings := 0, 0
+	for _, finding := range findings {
+		switch finding.Severity {
+		case models.SeverityError:
+			errors++
+		case models.SeverityWarning:
+			warnings++
+		}
+	}
+	if errors > 0 {
+		return fmt.Errorf("lint found %d errors", errors)
+	}
+	if lintStrict && warnings > 0 {
+		return fmt.Errorf("lint found %d warnings (--strict)", warnings)
+	}
+	return nil
+}
+
+// printLintRules lists the rules with their default severity
+func printLintRules() {
+	for _, rule := range common.LintRules() {
+		fmt.Printf("%-27s %-8s %s\n", rule.Name, rule.Severity, rule.Description)
+	}
+}
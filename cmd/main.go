@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"runtime/debug"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -79,28 +80,76 @@ Cross-platform AI agent workflow DSL converter with iFlytek Spark as the central
   # Validate DSL file
   agentbridge validate --input agent.yml
 
+  # Use the "prod" profile from ~/.agentbridge.yaml
+  agentbridge convert --profile prod --to dify --input agent.yml --output dify.yml
+
   # Show supported node types
   agentbridge info --nodes`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := startProfiling(); err != nil {
+			return err
+		}
+		return loadConfigProfile(cmd, args)
+	},
 }
 
 func init() {
 	// Configure global flags
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Quiet mode, only show errors")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file path (default: ~/.agentbridge.yaml, env: AGENTBRIDGE_CONFIG)")
+	rootCmd.PersistentFlags().BoolVar(&jsonSummary, "json-summary", false, "Print a JSON summary line on stderr when the command ends (env: "+EnvJSONSummary+")")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Config profile to use (default: default_profile from config file)")
+	addProfilingFlags(rootCmd)
 
 	// Add subcommands
 	rootCmd.AddCommand(NewConvertCmd())
 	rootCmd.AddCommand(NewValidateCmd())
+	rootCmd.AddCommand(NewCheckCmd())
 	rootCmd.AddCommand(NewInfoCmd())
 	rootCmd.AddCommand(NewPlatformsCmd())
 	rootCmd.AddCommand(NewBatchCmd())
+	rootCmd.AddCommand(NewSchemaCmd())
+	rootCmd.AddCommand(NewVerifyCmd())
+	rootCmd.AddCommand(NewWizardCmd())
+	rootCmd.AddCommand(NewDocsCmd())
+	rootCmd.AddCommand(NewStatsCmd())
+	rootCmd.AddCommand(NewTestCmd())
+	rootCmd.AddCommand(NewTodoCmd())
+	rootCmd.AddCommand(NewPullCmd())
+	rootCmd.AddCommand(NewPushCmd())
+	rootCmd.AddCommand(NewSyncCmd())
+	rootCmd.AddCommand(NewLintCmd())
+	rootCmd.AddCommand(NewComposeCmd())
+	rootCmd.AddCommand(NewPreviewCmd())
+	rootCmd.AddCommand(NewSimulateCmd())
+	rootCmd.AddCommand(NewServeCmd())
+	rootCmd.AddCommand(NewMCPCmd())
+	rootCmd.AddCommand(NewUpdateCmd())
+	rootCmd.AddCommand(NewDoctorCmd())
+
+	registerFlagCompletions(rootCmd)
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	start := time.Now()
+	command, err := rootCmd.ExecuteC()
+	stopProfiling()
+	code := ExitOK
+	if err != nil {
+		code = commandExitCode(err)
 		if !quiet {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", wrapUserFriendlyError(err))
 		}
-		os.Exit(1)
+	}
+	if jsonSummaryEnabled() {
+		name := rootCmd.Name()
+		if command != nil {
+			name = command.Name()
+		}
+		writeJSONSummary(name, err, code, time.Since(start))
+	}
+	if code != ExitOK {
+		os.Exit(code)
 	}
 }
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// mappingSidecarSuffix is appended to the output path for the exported ID mapping
+const mappingSidecarSuffix = ".mapping.json"
+
+// emitMapping writes the source-to-target ID mapping next to the generated DSL
+var emitMapping bool
+
+// previousMappingFile is an ID mapping emitted by an earlier conversion whose target IDs are reused
+var previousMappingFile string
+
+// mappingSidecarPath returns the ID mapping file path for an output file
+func mappingSidecarPath(outputPath string) string {
+	return outputPath + mappingSidecarSuffix
+}
+
+// writeMappingSidecar writes the ID mapping to <output>.mapping.json
+func writeMappingSidecar(outputPath string, mapping *models.IDMapping) error {
+	if mapping == nil {
+		return fmt.Errorf("target platform does not report an ID mapping")
+	}
+
+	data, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ID mapping: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := os.WriteFile(mappingSidecarPath(outputPath), data, 0644); err != nil {
+		return fmt.Errorf("failed to write ID mapping file: %w", err)
+	}
+
+	return nil
+}
+
+// loadPreviousMapping reads the --previous-mapping file into the conversion options
+func loadPreviousMapping(options *models.ConversionOptions) error {
+	if previousMappingFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(previousMappingFile)
+	if err != nil {
+		return fmt.Errorf("failed to read previous mapping file: %w", err)
+	}
+
+	mapping, err := models.ParseIDMapping(data)
+	if err != nil {
+		return fmt.Errorf("failed to load previous mapping file %s: %w", previousMappingFile, err)
+	}
+
+	options.PreviousMapping = mapping
+	return nil
+}
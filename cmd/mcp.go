@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/mcp"
+	"github.com/iflytek/agentbridge/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// NewMCPCmd creates the mcp command
+func NewMCPCmd() *cobra.Command {
+	var mcpCmd = &cobra.Command{
+		Use:   "mcp",
+		Short: "Run as an MCP server for AI assistants and IDE agents",
+		Long: `Run a Model Context Protocol server on stdin and stdout.
+
+MCP clients such as desktop assistants and IDE agents start this command and call its tools:
+
+  convert_workflow   convert a workflow to another platform or the unified DSL
+  validate_workflow  validate a workflow, optionally checking how it converts to a target
+  inspect_workflow   list the nodes, edges and statistics of a workflow
+
+Workflows are passed as local paths, as text, or base64 for Coze ZIP exports. The command
+flags set the defaults of every conversion. Logs go to stderr.`,
+		Example: `  # Register with an MCP client, e.g. in its JSON configuration:
+  #   "agentbridge": {"command": "agentbridge", "args": ["mcp"]}
+
+  # Convert with a policy and Dify 0.15.x as the default target version
+  agentbridge mcp --policy policy.yml --target-version 0.15.x`,
+		Args: cobra.NoArgs,
+		RunE: runMCP,
+	}
+
+	mcpCmd.Flags().StringVar(&targetVersion, "target-version", "", "Default target platform version (dify: 0.6.x|0.15.x|1.x, iflytek: v1|v2)")
+	mcpCmd.Flags().StringVar(&iflytekAppID, "iflytek-app-id", "", "Spark appId written into generated iFlytek nodes (env: AGENTBRIDGE_IFLYTEK_APP_ID)")
+	mcpCmd.Flags().StringVar(&iflytekUID, "iflytek-uid", "", "Spark uid written into generated iFlytek nodes (env: AGENTBRIDGE_IFLYTEK_UID)")
+	mcpCmd.Flags().StringVar(&placeholderStrategy, "placeholder-strategy", models.PlaceholderStrategyPlaceholder, "Unsupported node handling (placeholder|fail)")
+	mcpCmd.Flags().StringVar(&audioStrategy, "audio-strategy", models.AudioStrategyPlaceholder, "Speech synthesis/recognition node handling on Dify and Coze (placeholder|http)")
+	mcpCmd.Flags().StringVar(&triggerStrategy, "trigger-strategy", models.TriggerStrategyComment, "Schedule and event trigger handling on iFlytek and Dify (comment|placeholder)")
+	mcpCmd.Flags().StringVar(&defaultIntent, "default-intent", models.DefaultIntentLastClass, "Target of the default intent iFlytek classifiers need, converting from Dify (last-class|end|none)")
+	mcpCmd.Flags().StringVar(&parseMode, "parse-mode", models.ParseModePermissive, "Source problem handling (permissive|strict|lenient)")
+	mcpCmd.Flags().StringVar(&hookScriptFile, "hook-script", "", "YAML hook script applied to every conversion")
+	mcpCmd.Flags().StringVar(&policyFile, "policy", "", "YAML policy enforced on every conversion")
+	mcpCmd.Flags().StringVar(&rulesFile, "rules", "", "YAML file of custom validation rules")
+
+	return mcpCmd
+}
+
+// runMCP executes the mcp command
+func runMCP(cmd *cobra.Command, args []string) error {
+	// stdout carries the protocol; parser summaries and other output go to stderr
+	protocol := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = protocol }()
+
+	if err := loadHookScript(); err != nil {
+		return err
+	}
+	if err := loadPolicy(); err != nil {
+		return err
+	}
+	if err := loadValidationRules(); err != nil {
+		return err
+	}
+
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+	server := mcp.New(conversionService, mcp.Config{Version: getVersion(), Options: buildConversionOptions()})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := server.Serve(ctx, os.Stdin, protocol); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("MCP server failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// policyFile is a YAML policy whose rules warn about, rewrite or block converted nodes
+var policyFile string
+
+// conversionPolicy holds the loaded policy, shared by every conversion of a command
+var conversionPolicy *models.Policy
+
+// loadPolicy reads the --policy file
+func loadPolicy() error {
+	if policyFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(policyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	policy, err := models.ParsePolicy(data)
+	if err != nil {
+		return fmt.Errorf("failed to load policy file %s: %w", policyFile, err)
+	}
+
+	conversionPolicy = policy
+	return nil
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+
+	"github.com/spf13/cobra"
+)
+
+// Options of the preview command
+var (
+	previewValuesFile string
+	previewSet        []string
+	previewFormat     string
+)
+
+// NewPreviewCmd creates the preview command
+func NewPreviewCmd() *cobra.Command {
+	var previewCmd = &cobra.Command{
+		Use:   "preview",
+		Short: "Show which branches a workflow takes for sample values",
+		Long: `Walk a workflow from its start node and evaluate its condition and classifier nodes with
+sample values, without running anything. Previewing the source and the converted file with the
+same values shows whether the branch mapping survived the conversion.
+
+Values are a JSON object keyed by start variable name or by "nodeID.output". Start variables
+without a value take their default. A classifier takes the class given under its node ID, or the
+only class whose name appears in the query; otherwise the walk stops at the classifier.`,
+		Example: `  # Preview a Dify workflow with values from a file
+  agentbridge preview --input dify.yml --values samples.json
+
+  # Compare the branches after conversion
+  agentbridge preview --input iflytek.yml --set gender=woman --set birth_year=1990
+
+  # Pick the class of a classifier node
+  agentbridge preview --input agent.yml --set input_01=hello --set 1754270706489=技能实践类`,
+		RunE: runPreview,
+	}
+
+	previewCmd.Flags().StringVarP(&inputFile, "input", "i", "", "DSL file to preview (required)")
+	previewCmd.Flags().StringVar(&sourceType, "from", "", "Platform of the file (iflytek|dify|coze|unified, auto-detect if not specified)")
+	previewCmd.Flags().StringVar(&previewValuesFile, "values", "", "JSON file of sample values")
+	previewCmd.Flags().StringArrayVar(&previewSet, "set", nil, "Sample value as name=value, JSON values are decoded (repeatable)")
+	previewCmd.Flags().StringVar(&previewFormat, "format", "text", "Output format (text|json)")
+	previewCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	previewCmd.MarkFlagRequired("input")
+
+	return previewCmd
+}
+
+// runPreview executes the preview command
+func runPreview(cmd *cobra.Command, args []string) error {
+	if previewFormat != "text" && previewFormat != "json" {
+		return fmt.Errorf("invalid format %q, expected text or json", previewFormat)
+	}
+	if quiet {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	// JSON on stdout is the product of the command, even in quiet mode
+	reportOut := os.Stdout
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+	restoreParse := func() {}
+	if previewFormat == "json" {
+		restoreParse = discardStdout()
+	} else if !quiet {
+		printHeader("Branch Preview")
+	}
+
+	values, err := readSampleValues(previewValuesFile, previewSet)
+	if err != nil {
+		restoreParse()
+		return err
+	}
+	cmd.SilenceUsage = true
+	unifiedDSL, platform, err := parseInputToUnified()
+	restoreParse()
+	if err != nil {
+		return err
+	}
+	preview, err := common.PreviewBranches(unifiedDSL, values)
+	if err != nil {
+		return fmt.Errorf("failed to preview branches: %w", err)
+	}
+
+	if previewFormat == "json" {
+		data, err := json.MarshalIndent(preview, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode preview: %w", err)
+		}
+		fmt.Fprintln(reportOut, string(data))
+		return nil
+	}
+	printBranchPreview(platform, unifiedDSL, preview)
+	return nil
+}
+
+// readSampleValues reads a JSON object of values and applies name=value assignments on top of it
+func readSampleValues(path string, assignments []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file: %w", err)
+		}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("invalid values file %s: %w", path, err)
+		}
+	}
+	for _, assignment := range assignments {
+		name, raw, ok := strings.Cut(assignment, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --set %q, expected name=value", assignment)
+		}
+		var value interface{}
+		if json.Unmarshal([]byte(raw), &value) != nil {
+			value = raw
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// parseInputToUnified parses the input file of any platform to the unified model
+func parseInputToUnified() (*models.UnifiedDSL, models.PlatformType, error) {
+	if err := validateInputFile(inputFile); err != nil {
+		return nil, "", fmt.Errorf("input file validation failed: %w", err)
+	}
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read input file: %w", err)
+	}
+	if sourceType == "" {
+		detected, err := detectSourceType(data)
+		if err != nil {
+			return nil, "", err
+		}
+		sourceType = detected
+	}
+	platform := models.PlatformType(sourceType)
+
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+	unifiedDSL, err := conversionService.Parse(data, platform)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s DSL: %w", platform, err)
+	}
+	return unifiedDSL, platform, nil
+}
+
+// printBranchPreview lists the decisions in walk order with the nodes each branch leads to
+func printBranchPreview(platform models.PlatformType, unifiedDSL *models.UnifiedDSL, preview *common.BranchPreview) {
+	titles := make(map[string]string)
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		titles[node.ID] = node.Title
+	}
+	fmt.Printf("   File: %s\n", inputFile)
+	fmt.Printf("   Platform: %s\n\n", platform)
+
+	if len(preview.Decisions) == 0 {
+		fmt.Println("ℹ️  No condition or classifier node on the path")
+	}
+	for _, decision := range preview.Decisions {
+		if decision.Branch == "" {
+			fmt.Printf("❓ %s (%s): undecided, %s\n", decision.NodeTitle, decision.NodeID, decision.Reason)
+			continue
+		}
+		fmt.Printf("🔀 %s (%s): %s, %s\n", decision.NodeTitle, decision.NodeID, decision.Branch, decision.Reason)
+		for _, next := range decision.Next {
+			fmt.Printf("   → %s (%s)\n", titles[next], next)
+		}
+	}
+	for _, missing := range preview.Missing {
+		fmt.Printf("⚠️  No value for %s, treated as empty\n", missing)
+	}
+	for _, warning := range preview.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	fmt.Printf("\n📊 Reached %d of %d nodes\n", len(preview.Reached), len(unifiedDSL.Workflow.Nodes))
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+
+	"github.com/spf13/cobra"
+)
+
+// Profiling output files, empty to skip the profile
+var (
+	cpuProfileFile string
+	memProfileFile string
+	traceFile      string
+)
+
+// stopProfiling finishes the profiles started by startProfiling; Execute calls it after the
+// command returned, also when it failed
+var stopProfiling = func() {}
+
+// addProfilingFlags registers the profiling flags of the root command
+func addProfilingFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&cpuProfileFile, "cpuprofile", "", "Write a CPU profile to this file (inspect with go tool pprof)")
+	cmd.PersistentFlags().StringVar(&memProfileFile, "memprofile", "", "Write a heap profile to this file when the command ends")
+	cmd.PersistentFlags().StringVar(&traceFile, "trace", "", "Write an execution trace to this file (inspect with go tool trace)")
+}
+
+// startProfiling starts the CPU profile and the execution trace of the flags
+func startProfiling() error {
+	var stops []func()
+	stop := func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
+	if cpuProfileFile != "" {
+		file, err := os.Create(cpuProfileFile)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			closeProfile(file)
+		})
+	}
+
+	if traceFile != "" {
+		file, err := os.Create(traceFile)
+		if err != nil {
+			stop()
+			return fmt.Errorf("failed to create trace: %w", err)
+		}
+		if err := trace.Start(file); err != nil {
+			file.Close()
+			stop()
+			return fmt.Errorf("failed to start trace: %w", err)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			closeProfile(file)
+		})
+	}
+
+	if memProfileFile != "" {
+		stops = append(stops, writeHeapProfile)
+	}
+
+	stopProfiling = func() {
+		stop()
+		stopProfiling = func() {}
+	}
+	return nil
+}
+
+// writeHeapProfile writes the heap profile after a garbage collection, so it shows live memory
+func writeHeapProfile() {
+	file, err := os.Create(memProfileFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  failed to create heap profile: %v\n", err)
+		return
+	}
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  failed to write heap profile: %v\n", err)
+	}
+	closeProfile(file)
+}
+
+func closeProfile(file *os.File) {
+	if err := file.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  failed to write %s: %v\n", file.Name(), err)
+	}
+}
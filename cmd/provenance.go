@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// provenanceCommentPrefix marks the embedded provenance line in generated YAML
+const provenanceCommentPrefix = "# agentbridge-provenance: "
+
+// provenanceMode selects how provenance is attached: "", "embed" or "sidecar"
+var provenanceMode string
+
+// validateProvenanceMode checks the --provenance flag value
+func validateProvenanceMode() error {
+	switch provenanceMode {
+	case "", models.ProvenanceModeEmbed, models.ProvenanceModeSidecar:
+		return nil
+	default:
+		return fmt.Errorf("invalid provenance mode '%s' (expected %s|%s)",
+			provenanceMode, models.ProvenanceModeEmbed, models.ProvenanceModeSidecar)
+	}
+}
+
+// finalizeConversionOutput attaches optional artifacts to a conversion result and returns the data to write
+func finalizeConversionOutput(inputPath, outputPath string, inputData []byte, result *services.ConversionResult) ([]byte, error) {
+	if emitMapping {
+		if err := writeMappingSidecar(outputPath, result.IDMapping); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := attachProvenance(inputPath, outputPath, inputData, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Checksums and signatures cover the bytes as written, provenance header included
+	if err := writeIntegrityFiles(outputPath, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// attachProvenance embeds provenance into the output or writes it next to it, as --provenance selects
+func attachProvenance(inputPath, outputPath string, inputData []byte, result *services.ConversionResult) ([]byte, error) {
+	if provenanceMode == "" {
+		return result.Output, nil
+	}
+
+	provenance := models.NewProvenance(getVersion(), result.SourcePlatform, result.TargetPlatform,
+		filepath.Base(inputPath), inputData, result.NodeMapping)
+
+	switch provenanceMode {
+	case models.ProvenanceModeEmbed:
+		return embedProvenance(result.Output, provenance)
+	case models.ProvenanceModeSidecar:
+		if err := writeProvenanceSidecar(outputPath, provenance); err != nil {
+			return nil, err
+		}
+	}
+
+	return result.Output, nil
+}
+
+// embedProvenance prepends provenance as a single-line YAML comment, which target platforms ignore on import
+func embedProvenance(output []byte, provenance *models.Provenance) ([]byte, error) {
+	data, err := json.Marshal(provenance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal provenance: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(provenanceCommentPrefix)
+	buf.Write(data)
+	buf.WriteByte('\n')
+	buf.Write(output)
+	return buf.Bytes(), nil
+}
+
+// writeProvenanceSidecar writes provenance to <output>.provenance.json
+func writeProvenanceSidecar(outputPath string, provenance *models.Provenance) error {
+	data, err := json.MarshalIndent(provenance, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal provenance: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	sidecarPath := outputPath + ".provenance.json"
+	if err := os.WriteFile(sidecarPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write provenance file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iflytek/agentbridge/integrations"
+	"github.com/iflytek/agentbridge/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// Options shared by the pull and push commands
+var (
+	accountPlatform string
+	accountEndpoint string
+	accountAPIKey   string
+	accountAppID    string
+	accountTimeout  time.Duration
+)
+
+// Options of the pull command
+var pullIncludeSecrets bool
+
+// NewPullCmd creates the pull command
+func NewPullCmd() *cobra.Command {
+	var pullCmd = &cobra.Command{
+		Use:   "pull",
+		Short: "Export the DSL of an app from a platform account",
+		Long: `Export the DSL of an app with the management API of a platform account, to stdout or to
+--output, ready for convert.
+
+Credentials come from the flags, then from the environment (AGENTBRIDGE_<PLATFORM>_ENDPOINT,
+AGENTBRIDGE_<PLATFORM>_API_KEY), then from the accounts section of the config profile.
+Dify: --api-key is a console access token. Coze and iFlytek Spark have no workflow export API;
+export the workflow in their editors instead.`,
+		Example: `  # Export a Dify app and convert it to iFlytek Spark
+  agentbridge pull --platform dify --endpoint https://dify.example.com --app-id 5f0e... -o app.yml
+  agentbridge convert --from dify --to iflytek --input app.yml --output iflytek.yml`,
+		RunE: runPull,
+	}
+
+	addAccountFlags(pullCmd)
+	pullCmd.Flags().BoolVar(&pullIncludeSecrets, "include-secrets", false, "Export secret environment variables too")
+	pullCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write the DSL to this file instead of stdout")
+
+	pullCmd.MarkFlagRequired("platform")
+	pullCmd.MarkFlagRequired("app-id")
+
+	return pullCmd
+}
+
+// addAccountFlags adds the flags that reach a platform account
+func addAccountFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&accountPlatform, "platform", "", "Platform of the account (dify|coze|iflytek) (required)")
+	cmd.Flags().StringVar(&accountEndpoint, "endpoint", "", "Base URL of the instance (required for dify)")
+	cmd.Flags().StringVar(&accountAPIKey, "api-key", "", "API key of the account (env: AGENTBRIDGE_<PLATFORM>_API_KEY)")
+	cmd.Flags().StringVar(&accountAppID, "app-id", "", "ID of the app")
+	cmd.Flags().DurationVar(&accountTimeout, "timeout", time.Minute, "Time limit of the API calls")
+	cmd.RegisterFlagCompletionFunc("platform", cobra.FixedCompletions([]string{"dify", "coze", "iflytek"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// resolveAccount returns the account of the flags, completed by the environment and the config profile
+func resolveAccount(space string) (integrations.Account, error) {
+	platform := models.PlatformType(accountPlatform)
+	if !models.IsValidPlatformType(platform) {
+		return integrations.Account{}, fmt.Errorf("unsupported platform: %s (supported: iflytek, dify, coze)", accountPlatform)
+	}
+	configured := accounts[accountPlatform]
+	account := integrations.Account{
+		Platform: platform,
+		Endpoint: accountEndpoint,
+		APIKey:   accountAPIKey,
+		Space:    space,
+	}.WithEnvironment(integrations.Account{
+		Endpoint: configured.Endpoint,
+		APIKey:   configured.APIKey,
+		Space:    configured.Space,
+	})
+	return account, nil
+}
+
+// runPull executes the pull command
+func runPull(cmd *cobra.Command, args []string) error {
+	account, err := resolveAccount("")
+	if err != nil {
+		return err
+	}
+	adapter, err := integrations.NewAdapter(account)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), accountTimeout)
+	defer cancel()
+	data, err := adapter.Pull(ctx, accountAppID, pullIncludeSecrets)
+	if err != nil {
+		// A failing platform is not a usage error
+		cmd.SilenceUsage = true
+		return fmt.Errorf("pull from %s failed: %w", account.Platform, err)
+	}
+
+	if outputFile == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := createOutputDirectory(); err != nil {
+		return err
+	}
+	if err := writeOutputFile(data); err != nil {
+		return err
+	}
+	if !quiet {
+		fmt.Printf("✅ Pulled %s app %s into %s\n", account.Platform, accountAppID, outputFile)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/iflytek/agentbridge/integrations"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+
+	"github.com/spf13/cobra"
+)
+
+// pushSpace is the workspace the push command imports into
+var pushSpace string
+
+// NewPushCmd creates the push command
+func NewPushCmd() *cobra.Command {
+	var pushCmd = &cobra.Command{
+		Use:   "push",
+		Short: "Import a DSL file into a platform account",
+		Long: `Import a converted DSL file with the management API of a platform account, as a new app or
+over the app given with --app-id.
+
+Credentials come from the flags, then from the environment (AGENTBRIDGE_<PLATFORM>_ENDPOINT,
+AGENTBRIDGE_<PLATFORM>_API_KEY, AGENTBRIDGE_<PLATFORM>_SPACE), then from the accounts section of
+the config profile. The file must already be in the DSL of --platform; convert it first.
+Dify: --space is the workspace (tenant) ID. Coze and iFlytek Spark have no workflow import API;
+import the file in their editors instead.`,
+		Example: `  # Convert an iFlytek Spark workflow and import it into a Dify workspace
+  agentbridge convert --from iflytek --to dify --input agent.yml --output dify.yml
+  agentbridge push --platform dify --endpoint https://dify.example.com --space 8c1e... --input dify.yml
+
+  # Overwrite an existing Dify app
+  agentbridge push --platform dify --input dify.yml --app-id 5f0e...`,
+		RunE: runPush,
+	}
+
+	addAccountFlags(pushCmd)
+	pushCmd.Flags().StringVarP(&inputFile, "input", "i", "", "DSL file to import (required)")
+	pushCmd.Flags().StringVar(&pushSpace, "space", "", "Workspace to import into (default the current workspace)")
+
+	pushCmd.MarkFlagRequired("platform")
+	pushCmd.MarkFlagRequired("input")
+
+	return pushCmd
+}
+
+// runPush executes the push command
+func runPush(cmd *cobra.Command, args []string) error {
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+	if !quiet {
+		printHeader("Platform Push")
+	}
+
+	account, err := resolveAccount(pushSpace)
+	if err != nil {
+		return err
+	}
+	if err := validateInputFile(inputFile); err != nil {
+		return fmt.Errorf("input file validation failed: %w", err)
+	}
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+	if err := checkPushPlatform(data, account.Platform); err != nil {
+		return err
+	}
+	adapter, err := integrations.NewAdapter(account)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("   File: %s\n", inputFile)
+	fmt.Printf("   Platform: %s\n", account.Platform)
+	if account.Space != "" {
+		fmt.Printf("   Space: %s\n", account.Space)
+	}
+	fmt.Println()
+
+	ctx, cancel := context.WithTimeout(context.Background(), accountTimeout)
+	defer cancel()
+	result, err := adapter.Push(ctx, data, integrations.PushOptions{AppID: accountAppID})
+	if err != nil {
+		// A failing platform is not a usage error
+		cmd.SilenceUsage = true
+		return fmt.Errorf("push to %s failed: %w", account.Platform, err)
+	}
+
+	if result.Updated {
+		fmt.Printf("✅ Updated app %s\n", result.AppID)
+	} else {
+		fmt.Printf("✅ Imported as app %s\n", result.AppID)
+	}
+	if result.Warning != "" {
+		fmt.Printf("⚠️  %s\n", result.Warning)
+	}
+	return nil
+}
+
+// checkPushPlatform rejects files that are not in the DSL of platform, which the platform would
+// reject with a less helpful message
+func checkPushPlatform(data []byte, platform models.PlatformType) error {
+	detection, err := common.DetectPlatform(data)
+	if err != nil {
+		// Undetected files are left to the platform to judge
+		return nil
+	}
+	if detection.Platform != platform {
+		return fmt.Errorf("%s is a %s workflow, convert it first: agentbridge convert --from %s --to %s --input %s",
+			inputFile, detection.Platform, detection.Platform, platform, inputFile)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// rulesFile is a YAML file of custom validation rules
+var rulesFile string
+
+// validationRules holds the validators of the rules file, shared by every conversion of a command
+var validationRules []models.Validator
+
+// loadValidationRules reads the --rules file
+func loadValidationRules() error {
+	if rulesFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(rulesFile)
+	if err != nil {
+		return fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	rules, err := models.ParseValidationRules(data)
+	if err != nil {
+		return fmt.Errorf("failed to load rules file %s: %w", rulesFile, err)
+	}
+
+	validationRules = common.RuleValidators(rules)
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iflytek/agentbridge/platforms/unified/schema"
+
+	"github.com/spf13/cobra"
+)
+
+// NewSchemaCmd creates the schema command
+func NewSchemaCmd() *cobra.Command {
+	var schemaCmd = &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the unified DSL",
+		Long: `Print the JSON Schema of the unified DSL, the intermediate format written by --to unified
+and read by --from unified.
+
+The schema is built into the binary and matches the unified DSL of this version. Use it to check
+hand-edited or generated unified DSL files, or to get completion in editors.`,
+		Example: `  # Print the schema
+  agentbridge schema
+
+  # Save the schema for an editor
+  agentbridge schema --output unified-dsl.schema.json`,
+		RunE: runSchema,
+	}
+
+	schemaCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write the schema to a file instead of stdout")
+
+	return schemaCmd
+}
+
+// runSchema executes the schema command
+func runSchema(cmd *cobra.Command, args []string) error {
+	data, err := schema.JSON()
+	if err != nil {
+		return fmt.Errorf("failed to build schema: %w", err)
+	}
+
+	if outputFile == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	if !quiet {
+		fmt.Printf("✅ Unified DSL schema written to %s\n", outputFile)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/internal/server"
+	"github.com/iflytek/agentbridge/web"
+
+	"github.com/spf13/cobra"
+)
+
+// Options of the serve command
+var (
+	serveAddr     string
+	serveGRPCAddr string
+	serveUI       bool
+)
+
+// NewServeCmd creates the serve command
+func NewServeCmd() *cobra.Command {
+	var serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve conversions over HTTP, with an optional web playground",
+		Long: `Run an HTTP server converting uploaded DSL files.
+
+POST /api/parse returns the nodes and edges of an uploaded file, POST /api/convert converts it.
+Both take a multipart form with the file in the "file" field, the platforms in "from" (detected
+when empty) and "to", and option fields such as target_version, keep_titles, minify, anonymize,
+placeholder_strategy, default_intent, output_format and bump_version. Conversions requested with
+"Accept: text/event-stream" stream their progress as server-sent events.
+
+With --grpc-addr the command also serves the gRPC ConvertService of proto/agentbridge/v1,
+returning parsed workflows as typed protobuf messages.
+
+With --ui the server also hosts a playground at / to drop a file, preview its graph, pick the
+target and options and download the result. The command flags set the defaults of every
+conversion.`,
+		Example: `  # Open the playground at http://localhost:8080
+  agentbridge serve --ui
+
+  # Serve the API only, with a policy and a per-conversion timeout
+  agentbridge serve --addr 127.0.0.1:9000 --policy policy.yml --timeout 30s
+
+  # Serve gRPC next to the HTTP API
+  agentbridge serve --grpc-addr localhost:9090`,
+		Args: cobra.NoArgs,
+		RunE: runServe,
+	}
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveGRPCAddr, "grpc-addr", "", "Also serve the gRPC ConvertService on this address")
+	serveCmd.Flags().BoolVar(&serveUI, "ui", false, "Serve the web playground at /")
+	serveCmd.Flags().StringVar(&targetVersion, "target-version", "", "Default target platform version (dify: 0.6.x|0.15.x|1.x, iflytek: v1|v2)")
+	serveCmd.Flags().StringVar(&iflytekAppID, "iflytek-app-id", "", "Spark appId written into generated iFlytek nodes (env: AGENTBRIDGE_IFLYTEK_APP_ID)")
+	serveCmd.Flags().StringVar(&iflytekUID, "iflytek-uid", "", "Spark uid written into generated iFlytek nodes (env: AGENTBRIDGE_IFLYTEK_UID)")
+	serveCmd.Flags().StringVar(&placeholderStrategy, "placeholder-strategy", models.PlaceholderStrategyPlaceholder, "Unsupported node handling (placeholder|fail)")
+	serveCmd.Flags().StringVar(&audioStrategy, "audio-strategy", models.AudioStrategyPlaceholder, "Speech synthesis/recognition node handling on Dify and Coze (placeholder|http)")
+	serveCmd.Flags().StringVar(&triggerStrategy, "trigger-strategy", models.TriggerStrategyComment, "Schedule and event trigger handling on iFlytek and Dify (comment|placeholder)")
+	serveCmd.Flags().StringVar(&defaultIntent, "default-intent", models.DefaultIntentLastClass, "Target of the default intent iFlytek classifiers need, converting from Dify (last-class|end|none)")
+	serveCmd.Flags().StringVar(&parseMode, "parse-mode", models.ParseModePermissive, "Source problem handling (permissive|strict|lenient)")
+	serveCmd.Flags().StringVar(&hookScriptFile, "hook-script", "", "YAML hook script applied to every conversion")
+	serveCmd.Flags().StringVar(&policyFile, "policy", "", "YAML policy enforced on every conversion")
+	serveCmd.Flags().StringVar(&rulesFile, "rules", "", "YAML file of custom validation rules")
+	serveCmd.Flags().DurationVar(&conversionTimeout, "timeout", time.Minute, "Abort a conversion that takes longer (0: no limit)")
+
+	return serveCmd
+}
+
+// runServe executes the serve command
+func runServe(cmd *cobra.Command, args []string) error {
+	// Parsers print conversion summaries to stdout
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+
+	if err := loadHookScript(); err != nil {
+		return err
+	}
+	if err := loadPolicy(); err != nil {
+		return err
+	}
+	if err := loadValidationRules(); err != nil {
+		return err
+	}
+
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+	config := server.Config{Options: buildConversionOptions(), Timeout: conversionTimeout}
+	if serveUI {
+		config.UI = web.UI()
+	}
+	httpServer := &http.Server{
+		Addr:              serveAddr,
+		Handler:           server.New(conversionService, config),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serveErr := make(chan error, 2)
+
+	if serveGRPCAddr != "" {
+		listener, err := net.Listen("tcp", serveGRPCAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", serveGRPCAddr, err)
+		}
+		grpcServer := server.NewGRPC(conversionService, config)
+		defer grpcServer.GracefulStop()
+		go func() {
+			serveErr <- grpcServer.Serve(listener)
+		}()
+	}
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	if !quiet {
+		if serveUI {
+			fmt.Printf("🌐 Playground at http://%s/\n", serveAddr)
+		}
+		if serveGRPCAddr != "" {
+			fmt.Printf("📡 gRPC ConvertService at %s\n", serveGRPCAddr)
+		}
+		fmt.Printf("🔌 API at http://%s/api/ (press Ctrl+C to stop)\n", serveAddr)
+	}
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to stop server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iflytek/agentbridge/internal/signing"
+)
+
+// emitChecksum writes the SHA-256 checksum of every generated file to <output>.sha256
+var emitChecksum bool
+
+// signKeyFile is a PEM private key that signs every generated file into <output>.sig
+var signKeyFile string
+
+// signingKey holds the loaded --sign key, shared by every conversion of a command
+var signingKey crypto.Signer
+
+// loadSigningKey reads the --sign key file
+func loadSigningKey() error {
+	if signKeyFile == "" {
+		return nil
+	}
+
+	key, err := signing.LoadPrivateKey(signKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load signing key: %w", err)
+	}
+
+	signingKey = key
+	return nil
+}
+
+// writeIntegrityFiles writes the checksum and signature of the data written to outputPath
+func writeIntegrityFiles(outputPath string, data []byte) error {
+	if !emitChecksum && signingKey == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if emitChecksum {
+		checksum := signing.FormatChecksumFile(data, filepath.Base(outputPath))
+		if err := os.WriteFile(outputPath+signing.ChecksumSuffix, checksum, 0644); err != nil {
+			return fmt.Errorf("failed to write checksum file: %w", err)
+		}
+	}
+
+	if signingKey != nil {
+		signature, err := signing.Sign(signingKey, data)
+		if err != nil {
+			return fmt.Errorf("failed to sign output: %w", err)
+		}
+		if err := os.WriteFile(outputPath+signing.SignatureSuffix, signature, 0644); err != nil {
+			return fmt.Errorf("failed to write signature file: %w", err)
+		}
+	}
+
+	return nil
+}
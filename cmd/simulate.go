@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/internal/simulator"
+
+	"github.com/spf13/cobra"
+)
+
+// Options of the simulate command
+var (
+	simulateValuesFile    string
+	simulateSet           []string
+	simulateResponsesFile string
+	simulateRunCode       bool
+	simulateCodeTimeout   time.Duration
+	simulateFormat        string
+)
+
+// simulateValueWidth bounds the values printed per step in text output
+const simulateValueWidth = 120
+
+// NewSimulateCmd creates the simulate command
+func NewSimulateCmd() *cobra.Command {
+	var simulateCmd = &cobra.Command{
+		Use:   "simulate",
+		Short: "Dry-run a workflow and trace its data flow",
+		Long: `Run a workflow of any platform locally on the unified model, without a platform or a model,
+and trace the inputs and outputs of every node through edges, branches and iterations.
+
+LLM nodes echo their rendered prompt, or return the canned response given for their node ID in
+--responses. Conditions are evaluated; classifiers take the class given in --responses or the
+only class named by the query. Code nodes return the zero values of their outputs, or run with
+the local python3 or node interpreter with --run-code: the code runs in a temporary directory
+with a time limit but is not isolated from the machine, so only run code you trust. Other nodes
+are stubbed with zero values unless a response is given.
+
+Simulating the source and the converted file with the same values shows whether the conversion
+kept the data flow.`,
+		Example: `  # Trace a Dify workflow with a start variable
+  agentbridge simulate --input dify.yml --set gender=woman
+
+  # Canned model answers and classes, keyed by node ID, and real code execution
+  agentbridge simulate --input agent.yml --values inputs.json --responses responses.json --run-code
+
+  # Machine-readable trace
+  agentbridge simulate --input dify.yml --set input_01=python --format json`,
+		RunE: runSimulate,
+	}
+
+	simulateCmd.Flags().StringVarP(&inputFile, "input", "i", "", "DSL file to simulate (required)")
+	simulateCmd.Flags().StringVar(&sourceType, "from", "", "Platform of the file (iflytek|dify|coze|unified, auto-detect if not specified)")
+	simulateCmd.Flags().StringVar(&simulateValuesFile, "values", "", "JSON file of start variable values")
+	simulateCmd.Flags().StringArrayVar(&simulateSet, "set", nil, "Start variable value as name=value, JSON values are decoded (repeatable)")
+	simulateCmd.Flags().StringVar(&simulateResponsesFile, "responses", "", "JSON file of canned node results by node ID")
+	simulateCmd.Flags().BoolVar(&simulateRunCode, "run-code", false, "Run code nodes with the local python3 and node interpreters")
+	simulateCmd.Flags().DurationVar(&simulateCodeTimeout, "code-timeout", simulator.DefaultCodeTimeout, "Time limit of one code node run")
+	simulateCmd.Flags().StringVar(&simulateFormat, "format", "text", "Output format (text|json)")
+	simulateCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	simulateCmd.MarkFlagRequired("input")
+
+	return simulateCmd
+}
+
+// runSimulate executes the simulate command
+func runSimulate(cmd *cobra.Command, args []string) error {
+	if simulateFormat != "text" && simulateFormat != "json" {
+		return fmt.Errorf("invalid format %q, expected text or json", simulateFormat)
+	}
+	if quiet {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	// JSON on stdout is the product of the command, even in quiet mode
+	reportOut := os.Stdout
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+	restoreParse := func() {}
+	if simulateFormat == "json" {
+		restoreParse = discardStdout()
+	} else if !quiet {
+		printHeader("Workflow Simulation")
+	}
+
+	inputs, err := readSampleValues(simulateValuesFile, simulateSet)
+	if err != nil {
+		restoreParse()
+		return err
+	}
+	responses, err := readSampleValues(simulateResponsesFile, nil)
+	if err != nil {
+		restoreParse()
+		return fmt.Errorf("responses: %w", err)
+	}
+	cmd.SilenceUsage = true
+	unifiedDSL, platform, err := parseInputToUnified()
+	restoreParse()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := conversionContext(context.Background())
+	defer cancel()
+	trace, runErr := simulator.Run(ctx, unifiedDSL, simulator.Options{
+		Inputs:      inputs,
+		Responses:   responses,
+		RunCode:     simulateRunCode,
+		CodeTimeout: simulateCodeTimeout,
+	})
+	if trace == nil {
+		return fmt.Errorf("simulation failed: %w", runErr)
+	}
+
+	if simulateFormat == "json" {
+		data, err := json.MarshalIndent(trace, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode trace: %w", err)
+		}
+		fmt.Fprintln(reportOut, string(data))
+	} else {
+		printSimulationTrace(platform, unifiedDSL, trace)
+	}
+	if runErr != nil {
+		return fmt.Errorf("simulation stopped: %w", timeoutError(runErr))
+	}
+	return nil
+}
+
+// simulateModeIcons mark how each step produced its outputs
+var simulateModeIcons = map[string]string{
+	simulator.ModeEvaluated: "▶️ ",
+	simulator.ModeCanned:    "📼",
+	simulator.ModeEcho:      "🔁",
+	simulator.ModeExecuted:  "⚙️ ",
+	simulator.ModeStubbed:   "⬜",
+	simulator.ModeFailed:    "❌",
+}
+
+// printSimulationTrace lists the steps with their values, then the workflow outputs
+func printSimulationTrace(platform models.PlatformType, unifiedDSL *models.UnifiedDSL, trace *simulator.Trace) {
+	fmt.Printf("   File: %s\n", inputFile)
+	fmt.Printf("   Platform: %s\n\n", platform)
+
+	for _, step := range trace.Steps {
+		indent := ""
+		if step.Iteration != "" {
+			indent = fmt.Sprintf("   ↻ %d ", step.Item)
+		}
+		fmt.Printf("%s%s %s (%s) [%s]\n", indent, simulateModeIcons[step.Mode], step.NodeTitle, step.NodeID, step.Mode)
+		if len(step.Inputs) > 0 {
+			fmt.Printf("      in:  %s\n", compactValue(step.Inputs))
+		}
+		if len(step.Outputs) > 0 {
+			fmt.Printf("      out: %s\n", compactValue(step.Outputs))
+		}
+		if step.Branch != "" {
+			fmt.Printf("      branch: %s (%s)\n", step.Branch, step.Note)
+		} else if step.Note != "" {
+			fmt.Printf("      note: %s\n", step.Note)
+		}
+		if step.Error != "" {
+			fmt.Printf("      error: %s\n", step.Error)
+		}
+	}
+
+	fmt.Println()
+	names := make([]string, 0, len(trace.Outputs))
+	for name := range trace.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("📤 %s = %s\n", name, compactValue(trace.Outputs[name]))
+	}
+	if answer := strings.TrimSpace(trace.Answer); answer != "" {
+		fmt.Printf("💬 %s\n", answer)
+	}
+	if len(trace.Skipped) > 0 {
+		titles := make(map[string]string)
+		for _, node := range unifiedDSL.Workflow.Nodes {
+			titles[node.ID] = node.Title
+		}
+		for _, id := range trace.Skipped {
+			fmt.Printf("⏭️  Not reached: %s (%s)\n", titles[id], id)
+		}
+	}
+	for _, warning := range trace.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+}
+
+// compactValue prints a value as one line of JSON, shortened to simulateValueWidth characters
+func compactValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	text := string(data)
+	if utf8.RuneCountInString(text) > simulateValueWidth {
+		text = string([]rune(text)[:simulateValueWidth-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/internal/stats"
+
+	"github.com/spf13/cobra"
+)
+
+// statsFile is the stats_file config value; AGENTBRIDGE_STATS_FILE takes precedence
+var statsFile string
+
+// Stats command flags
+var (
+	statsSince  string
+	statsFormat string
+)
+
+// statsFilePath returns the statistics file, empty when statistics are disabled
+func statsFilePath() string {
+	path := os.Getenv(stats.EnvStatsFile)
+	if path == "" {
+		path = statsFile
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// recordConversion adds a conversion to the run summary and appends it to the statistics file
+// when statistics are enabled. Failing to record never fails the conversion.
+func recordConversion(command string, from, to models.PlatformType, result *services.ConversionResult, err error, elapsed time.Duration) {
+	summarizeConversion(result, err)
+
+	path := statsFilePath()
+	if path == "" {
+		return
+	}
+
+	record := stats.Record{
+		Time:       time.Now().UTC(),
+		Command:    command,
+		Source:     string(from),
+		Target:     string(to),
+		Success:    err == nil,
+		DurationMS: elapsed.Milliseconds(),
+	}
+	if err != nil {
+		var conversionErr *models.ConversionError
+		if errors.As(err, &conversionErr) {
+			record.ErrorCode = conversionErr.Code
+		}
+	} else if result != nil {
+		record.Nodes = make(map[string]int, len(result.NodeTypes))
+		for nodeType, count := range result.NodeTypes {
+			record.Nodes[string(nodeType)] = count
+		}
+		record.Placeholders = result.Placeholders
+		record.Warnings = len(result.Warnings)
+	}
+
+	if appendErr := stats.Append(path, record); appendErr != nil && verbose {
+		fmt.Fprintf(os.Stderr, "⚠️  Statistics not recorded: %v\n", appendErr)
+	}
+}
+
+// NewStatsCmd creates the stats command
+func NewStatsCmd() *cobra.Command {
+	var statsCmd = &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize local conversion statistics",
+		Long: `Summarize the usage statistics recorded on this machine, for migration program reporting.
+
+Statistics are off by default. Set stats_file in the config file or AGENTBRIDGE_STATS_FILE to
+a path, and convert, batch and wizard append one line per conversion to it: platforms, outcome,
+node counts per type, placeholders, warnings and duration. No file names, titles or workflow
+content are recorded and nothing is sent over the network.`,
+		Example: `  # Enable statistics for this shell
+  export AGENTBRIDGE_STATS_FILE=~/.agentbridge/stats.jsonl
+
+  # Summary of all recorded conversions
+  agentbridge stats
+
+  # This quarter, as JSON for a report
+  agentbridge stats --since 2026-10-01 --format json`,
+		RunE: runStats,
+	}
+
+	statsCmd.Flags().StringVar(&statsSince, "since", "", "Only count conversions on or after this date (YYYY-MM-DD)")
+	statsCmd.Flags().StringVar(&statsFormat, "format", "text", "Output format (text|json)")
+	statsCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	return statsCmd
+}
+
+// runStats executes the stats command
+func runStats(cmd *cobra.Command, args []string) error {
+	if statsFormat != "text" && statsFormat != "json" {
+		return fmt.Errorf("invalid format %q, expected text or json", statsFormat)
+	}
+
+	var since time.Time
+	if statsSince != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", statsSince, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --since date %q, expected YYYY-MM-DD", statsSince)
+		}
+		since = parsed
+	}
+
+	path := statsFilePath()
+	if path == "" {
+		return fmt.Errorf("statistics are disabled: set stats_file in the config file or %s", stats.EnvStatsFile)
+	}
+	records, err := stats.Load(path)
+	if err != nil {
+		return err
+	}
+	summary := stats.Summarize(records, since)
+
+	if statsFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+
+	printStatsSummary(path, summary)
+	return nil
+}
+
+// printStatsSummary prints the summary for humans
+func printStatsSummary(path string, summary *stats.Summary) {
+	printHeader("Conversion Statistics")
+	fmt.Printf("📁 %s\n", path)
+	if summary.Conversions == 0 {
+		fmt.Printf("   No conversions recorded\n")
+		return
+	}
+
+	fmt.Printf("   Period: %s – %s\n", summary.First.Local().Format("2006-01-02 15:04"), summary.Last.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("   Conversions: %d (%d succeeded, %d failed, %.1f%% success)\n",
+		summary.Conversions, summary.Succeeded, summary.Failed, summary.SuccessRate())
+	fmt.Printf("   Nodes converted: %d, placeholders: %d (%.1f%%), in %d conversions\n",
+		summary.Nodes, summary.Placeholders, summary.PlaceholderRate(), summary.WithPlaceholder)
+
+	printStatsCounts("Conversion paths", summary.Paths)
+	printStatsCounts("Node types", summary.NodeTypes)
+	printStatsCounts("Failures", summary.Errors)
+}
+
+func printStatsCounts(title string, counts []stats.Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", title)
+	for _, count := range counts {
+		fmt.Printf("   %-24s %d\n", count.Name, count.Count)
+	}
+}
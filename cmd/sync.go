@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/integrations"
+	"github.com/iflytek/agentbridge/internal/gitops"
+	"github.com/iflytek/agentbridge/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// Options of the sync command
+var (
+	syncConfigFile  string
+	syncDryRun      bool
+	syncFailOnDrift bool
+	syncReportFile  string
+	syncTimeout     time.Duration
+)
+
+// NewSyncCmd creates the sync command
+func NewSyncCmd() *cobra.Command {
+	var syncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Keep platform apps in sync with a directory of workflows",
+		Long: `Convert and push the workflows of a source directory to the platform accounts of a sync
+configuration, for cron and CI jobs that treat the directory as the source of truth.
+
+A state file next to the configuration records the app of every workflow and target. Workflows
+whose source, converter version or platform app changed since the last sync are converted and
+pushed again; the others are left alone, so runs without changes push nothing. Apps edited on
+the platform are reported as drift and overwritten with the source.
+
+Targets without an import API (Coze, iFlytek Spark) get their converted files in output_dir for
+a manual import. --config names the sync configuration here; the profile config file comes from
+AGENTBRIDGE_CONFIG or ~/.agentbridge.yaml.`,
+		Example: `  # Sync and write a drift report
+  agentbridge sync --config sync.yaml --report drift.json
+
+  # Report what would change, failing when apps were edited on the platform
+  agentbridge sync --config sync.yaml --dry-run --fail-on-drift`,
+		RunE: runSync,
+	}
+
+	syncCmd.Flags().StringVar(&syncConfigFile, "config", "", "Sync configuration file (required)")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Report drift and pending pushes without converting or pushing")
+	syncCmd.Flags().BoolVar(&syncFailOnDrift, "fail-on-drift", false, "Exit non-zero when apps were changed on their platform")
+	syncCmd.Flags().StringVar(&syncReportFile, "report", "", "Write the drift report as JSON to this file")
+	syncCmd.Flags().DurationVar(&syncTimeout, "timeout", 10*time.Minute, "Time limit of the whole sync")
+
+	syncCmd.MarkFlagRequired("config")
+
+	return syncCmd
+}
+
+// runSync executes the sync command
+func runSync(cmd *cobra.Command, args []string) error {
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+	if !quiet {
+		printHeader("Workflow Sync")
+	}
+
+	config, err := gitops.LoadConfig(syncConfigFile)
+	if err != nil {
+		return err
+	}
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+
+	configured := make(map[models.PlatformType]integrations.Account, len(accounts))
+	for platform, account := range accounts {
+		configured[models.PlatformType(platform)] = integrations.Account{
+			Endpoint: account.Endpoint,
+			APIKey:   account.APIKey,
+			Space:    account.Space,
+		}
+	}
+	syncer := &gitops.Syncer{
+		Config:   config,
+		Convert:  syncConverter(conversionService),
+		Accounts: configured,
+		Version:  getVersion(),
+		DryRun:   syncDryRun,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+	report, err := syncer.Run(ctx)
+	if report != nil {
+		printSyncReport(report)
+		if reportErr := writeSyncReport(report); reportErr != nil && err == nil {
+			err = reportErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Failures of single workflows and drift are findings, not usage errors
+	cmd.SilenceUsage = true
+	if failed := report.Count(gitops.StatusFailed); failed > 0 {
+		return fmt.Errorf("sync failed for %d of %d workflows", failed, len(report.Items))
+	}
+	if syncFailOnDrift && report.Drifted() > 0 {
+		return fmt.Errorf("%d apps changed on their platform since the last sync", report.Drifted())
+	}
+	return nil
+}
+
+// syncConverter converts with the options of the config profile
+func syncConverter(conversionService *services.ConversionService) gitops.Converter {
+	return func(ctx context.Context, source []byte, from, to models.PlatformType, previous *models.IDMapping) (*services.ConversionResult, error) {
+		options := buildConversionOptions()
+		options.PreviousMapping = previous
+
+		ctx, cancel := conversionContext(ctx)
+		defer cancel()
+		conversionStart := time.Now()
+		result, err := conversionService.ConvertWithResult(ctx, source, from, to, options)
+		recordConversion("sync", from, to, result, err, time.Since(conversionStart))
+		if err != nil {
+			return nil, timeoutError(err)
+		}
+		return result, nil
+	}
+}
+
+// printSyncReport lists the workflows per target with their status and the reasons of changes
+func printSyncReport(report *gitops.Report) {
+	for _, item := range report.Items {
+		line := fmt.Sprintf("%s %s → %s: %s", syncStatusIcon(item.Status), item.File, item.Target, item.Status)
+		if item.AppID != "" {
+			line += " (app " + item.AppID + ")"
+		}
+		fmt.Println(line)
+		if len(item.Reasons) > 0 {
+			fmt.Printf("   %s\n", strings.Join(item.Reasons, "; "))
+		}
+		if item.Error != "" {
+			fmt.Printf("   %s\n", item.Error)
+		}
+		if verbose {
+			for _, warning := range item.Warnings {
+				fmt.Printf("   ⚠️  %s\n", warning)
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("📊 %d in sync, %d created, %d updated, %d manual, %d pending, %d failed; %d drifted\n",
+		report.Count(gitops.StatusInSync), report.Count(gitops.StatusCreated), report.Count(gitops.StatusUpdated),
+		report.Count(gitops.StatusManual), report.Count(gitops.StatusPending), report.Count(gitops.StatusFailed), report.Drifted())
+}
+
+func syncStatusIcon(status gitops.Status) string {
+	switch status {
+	case gitops.StatusInSync:
+		return "✅"
+	case gitops.StatusCreated, gitops.StatusUpdated:
+		return "🔄"
+	case gitops.StatusFailed:
+		return "❌"
+	default:
+		return "⚠️ "
+	}
+}
+
+// writeSyncReport writes the report to --report
+func writeSyncReport(report *gitops.Report) error {
+	if syncReportFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sync report: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(syncReportFile), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	if err := os.WriteFile(syncReportFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write sync report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/internal/smoketest"
+
+	"github.com/spf13/cobra"
+)
+
+// EnvTestAPIKey supplies the test API key without putting it on the command line
+const EnvTestAPIKey = "AGENTBRIDGE_API_KEY"
+
+// Options of the test command
+var (
+	testPlatform   string
+	testEndpoint   string
+	testAPIKey     string
+	testWorkflowID string
+	testQuery      string
+	testInputs     []string
+	testKeep       bool
+	testTimeout    time.Duration
+)
+
+// NewTestCmd creates the test command
+func NewTestCmd() *cobra.Command {
+	var testCmd = &cobra.Command{
+		Use:   "test",
+		Short: "Smoke test a generated DSL file on a live platform",
+		Long: `Run a generated DSL file once on a live Dify, Coze or iFlytek Spark instance and report
+import or runtime errors.
+
+Every start variable gets a sample value: its default, its first option or a placeholder of its
+type; --set overrides them. File variables are left out.
+
+Dify: the file is imported with the console API (--api-key is a console access token), run with
+a new app API key, and the app is deleted afterwards unless --keep is given.
+Coze and iFlytek Spark have no workflow import API: import and publish the file by hand, then
+pass its ID with --workflow-id. --api-key is a Coze personal access token, or "APIKey:APISecret"
+of the Spark agent.`,
+		Example: `  # Import and run a Dify workflow
+  agentbridge test --platform dify --endpoint https://dify.example.com --api-key $TOKEN --input dify.yml
+
+  # Run a chatflow with a question and fixed inputs
+  agentbridge test --platform dify --endpoint http://localhost --input chat.yml --query "What is RAG?" --set topic=search
+
+  # Run a workflow imported into Coze by hand
+  agentbridge test --platform coze --api-key $PAT --workflow-id 7412345678901234567 --input coze.yml`,
+		RunE: runTest,
+	}
+
+	testCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Generated DSL file to test (required)")
+	testCmd.Flags().StringVar(&testPlatform, "platform", "", "Platform to run on (dify|coze|iflytek) (required)")
+	testCmd.Flags().StringVar(&testEndpoint, "endpoint", "", "Base URL of the instance (required for dify, default the public cloud)")
+	testCmd.Flags().StringVar(&testAPIKey, "api-key", "", "API key of the instance (env: "+EnvTestAPIKey+")")
+	testCmd.Flags().StringVar(&testWorkflowID, "workflow-id", "", "ID of the workflow imported by hand (coze, iflytek)")
+	testCmd.Flags().StringVar(&testQuery, "query", "hello", "User message of chatflows")
+	testCmd.Flags().StringArrayVar(&testInputs, "set", nil, "Input value as name=value, JSON values are decoded (repeatable)")
+	testCmd.Flags().BoolVar(&testKeep, "keep", false, "Keep the imported app instead of deleting it")
+	testCmd.Flags().DurationVar(&testTimeout, "timeout", 2*time.Minute, "Time limit of the import and run")
+
+	testCmd.MarkFlagRequired("input")
+	testCmd.MarkFlagRequired("platform")
+
+	return testCmd
+}
+
+// runTest executes the test command
+func runTest(cmd *cobra.Command, args []string) error {
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+	if quiet {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	if !quiet {
+		printHeader("Live Platform Smoke Test")
+	}
+
+	if testAPIKey == "" {
+		testAPIKey = os.Getenv(EnvTestAPIKey)
+	}
+	platform := models.PlatformType(testPlatform)
+	target := smoketest.Target{
+		Platform:   platform,
+		Endpoint:   testEndpoint,
+		APIKey:     testAPIKey,
+		WorkflowID: testWorkflowID,
+		Query:      testQuery,
+		Keep:       testKeep,
+	}
+	if err := target.Validate(); err != nil {
+		return err
+	}
+	if err := validateInputFile(inputFile); err != nil {
+		return fmt.Errorf("input file validation failed: %w", err)
+	}
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+	inputs, err := buildTestInputs(data, platform)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("   File: %s\n", inputFile)
+	fmt.Printf("   Platform: %s\n", platform)
+	printTestInputs(inputs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	result, err := smoketest.Run(ctx, target, data, inputs)
+	if err != nil {
+		// A failing platform is not a usage error
+		cmd.SilenceUsage = true
+		if errors.Is(err, smoketest.ErrImport) || errors.Is(err, smoketest.ErrRun) {
+			fmt.Printf("❌ %v\n", err)
+		}
+		return fmt.Errorf("smoke test on %s failed: %w", platform, err)
+	}
+
+	printTestResult(result)
+	return nil
+}
+
+// buildTestInputs returns sample values of the start variables of data, overridden by --set
+func buildTestInputs(data []byte, platform models.PlatformType) (map[string]interface{}, error) {
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+	unifiedDSL, err := conversionService.Parse(data, platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s DSL: %w", platform, err)
+	}
+
+	inputs := smoketest.SampleInputs(unifiedDSL)
+	for _, assignment := range testInputs {
+		name, raw, ok := strings.Cut(assignment, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --set %q, expected name=value", assignment)
+		}
+		var value interface{}
+		if json.Unmarshal([]byte(raw), &value) != nil {
+			value = raw
+		}
+		inputs[name] = value
+	}
+	return inputs, nil
+}
+
+// printTestInputs lists the inputs sent to the workflow, sorted by name
+func printTestInputs(inputs map[string]interface{}) {
+	names := make([]string, 0, len(inputs))
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("   Input %s = %v\n", name, inputs[name])
+	}
+	fmt.Println()
+}
+
+// printTestResult reports a successful run
+func printTestResult(result *smoketest.Result) {
+	if result.Imported {
+		fmt.Printf("✅ Imported as app %s\n", result.AppID)
+	}
+	fmt.Printf("✅ Run succeeded in %s\n", result.Elapsed.Round(time.Millisecond))
+	if result.Answer != "" {
+		fmt.Printf("   Answer: %s\n", result.Answer)
+	}
+	if len(result.Outputs) > 0 {
+		outputs, _ := json.MarshalIndent(result.Outputs, "   ", "  ")
+		fmt.Printf("   Outputs: %s\n", outputs)
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+
+	"github.com/spf13/cobra"
+)
+
+// todoFormat is the output format of the todo command
+var todoFormat string
+
+// NewTodoCmd creates the todo command
+func NewTodoCmd() *cobra.Command {
+	var todoCmd = &cobra.Command{
+		Use:   "todo",
+		Short: "List the placeholder nodes of a converted workflow",
+		Long: `List the code placeholders this tool generated in a converted workflow of any platform, with
+their original node types and the manual step each one needs.
+
+Placeholders are found by the marker line at the top of their code, so renamed placeholders are
+found too; placeholders written by versions without markers are recognized by their title.
+--format markdown writes a checklist for the migration team, to stdout or to --output.`,
+		Example: `  # Review the placeholders left by a conversion
+  agentbridge todo --input iflytek.yml
+
+  # Export a Markdown checklist
+  agentbridge todo --input dify.yml --format markdown --output TODO.md`,
+		RunE: runTodo,
+	}
+
+	todoCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Converted DSL file to audit (required)")
+	todoCmd.Flags().StringVar(&sourceType, "from", "", "Platform of the file (iflytek|dify|coze|unified, auto-detect if not specified)")
+	todoCmd.Flags().StringVar(&todoFormat, "format", "text", "Output format (text|markdown)")
+	todoCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write the list to this file instead of stdout")
+	todoCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "markdown"}, cobra.ShellCompDirectiveNoFileComp))
+
+	todoCmd.MarkFlagRequired("input")
+
+	return todoCmd
+}
+
+// runTodo executes the todo command
+func runTodo(cmd *cobra.Command, args []string) error {
+	if todoFormat != "text" && todoFormat != "markdown" {
+		return fmt.Errorf("invalid format %q, expected text or markdown", todoFormat)
+	}
+	// A checklist on stdout is the product of the command, even in quiet mode
+	checklistOut := os.Stdout
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+
+	// Detection and parser messages would end up in a checklist on stdout
+	restoreParse := func() {}
+	if todoFormat == "markdown" && outputFile == "" {
+		restoreParse = discardStdout()
+	} else if !quiet {
+		printHeader("Placeholder Audit")
+	}
+	platform, placeholders, err := findFilePlaceholders()
+	restoreParse()
+	if err != nil {
+		return err
+	}
+
+	var list strings.Builder
+	if todoFormat == "markdown" {
+		writePlaceholderChecklist(&list, filepath.Base(inputFile), platform, placeholders)
+	} else {
+		writePlaceholderList(&list, platform, placeholders)
+	}
+
+	if outputFile == "" {
+		target := os.Stdout
+		if todoFormat == "markdown" {
+			target = checklistOut
+		}
+		_, err := io.WriteString(target, list.String())
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputFile, []byte(list.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+	if !quiet {
+		fmt.Printf("✅ %d placeholders written to %s\n", len(placeholders), outputFile)
+	}
+	return nil
+}
+
+// findFilePlaceholders parses the input file and returns its platform and placeholders
+func findFilePlaceholders() (models.PlatformType, []common.PlaceholderNode, error) {
+	if err := validateInputFile(inputFile); err != nil {
+		return "", nil, fmt.Errorf("input file validation failed: %w", err)
+	}
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	if sourceType == "" {
+		detected, err := detectSourceType(data)
+		if err != nil {
+			return "", nil, err
+		}
+		sourceType = detected
+	}
+	platform := models.PlatformType(sourceType)
+
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+	unifiedDSL, err := conversionService.Parse(data, platform)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse %s DSL: %w", platform, err)
+	}
+	return platform, common.FindPlaceholders(unifiedDSL), nil
+}
+
+// describePlaceholderOrigin describes the node a placeholder replaces
+func describePlaceholderOrigin(placeholder common.PlaceholderNode) string {
+	if placeholder.TitleOnly {
+		return "unknown (no marker, recognized by title)"
+	}
+	marker := placeholder.Marker
+	origin := "`" + marker.OriginalType + "`"
+	if marker.SourcePlatform != "" {
+		origin += " node from " + string(marker.SourcePlatform)
+	} else {
+		origin += " node, replaced during generation"
+	}
+	if marker.OriginalID != "" {
+		origin += fmt.Sprintf(" (ID `%s`)", marker.OriginalID)
+	}
+	return origin
+}
+
+// writePlaceholderList writes the placeholders for the terminal
+func writePlaceholderList(w *strings.Builder, platform models.PlatformType, placeholders []common.PlaceholderNode) {
+	fmt.Fprintf(w, "   File: %s\n", inputFile)
+	fmt.Fprintf(w, "   Platform: %s\n\n", platform)
+	if len(placeholders) == 0 {
+		fmt.Fprintf(w, "✅ No placeholder nodes\n")
+		return
+	}
+
+	for _, placeholder := range placeholders {
+		indent := "   "
+		if placeholder.IterationID != "" {
+			indent = "      "
+		}
+		fmt.Fprintf(w, "%s📝 %s [%s]\n", indent, placeholder.Title, placeholder.ID)
+		fmt.Fprintf(w, "%s   Original: %s\n", indent, strings.ReplaceAll(describePlaceholderOrigin(placeholder), "`", ""))
+		if placeholder.Marker.Action != "" {
+			fmt.Fprintf(w, "%s   Action: %s\n", indent, placeholder.Marker.Action)
+		}
+	}
+	fmt.Fprintf(w, "\n📊 Placeholders to implement by hand: %d\n", len(placeholders))
+}
+
+// writePlaceholderChecklist writes the placeholders as a Markdown checklist
+func writePlaceholderChecklist(w *strings.Builder, name string, platform models.PlatformType, placeholders []common.PlaceholderNode) {
+	fmt.Fprintf(w, "# Placeholder checklist: %s\n\n", name)
+	if len(placeholders) == 0 {
+		fmt.Fprintf(w, "Platform: %s. No placeholder nodes.\n", platform)
+		return
+	}
+	fmt.Fprintf(w, "Platform: %s. Placeholders to implement by hand: %d.\n\n", platform, len(placeholders))
+
+	for _, placeholder := range placeholders {
+		fmt.Fprintf(w, "- [ ] **%s** (`%s`", placeholder.Title, placeholder.ID)
+		if placeholder.IterationID != "" {
+			fmt.Fprintf(w, ", in iteration `%s`", placeholder.IterationID)
+		}
+		fmt.Fprintf(w, ")\n")
+		fmt.Fprintf(w, "  - Original: %s\n", describePlaceholderOrigin(placeholder))
+		if placeholder.Marker.Action != "" {
+			fmt.Fprintf(w, "  - Action: %s\n", placeholder.Marker.Action)
+		}
+	}
+}
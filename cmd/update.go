@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/iflytek/agentbridge/internal/signing"
+	"github.com/iflytek/agentbridge/internal/update"
+
+	"github.com/spf13/cobra"
+)
+
+// Environment variables of the update command
+const (
+	EnvUpdateAPI = "AGENTBRIDGE_UPDATE_API"
+	EnvUpdateKey = "AGENTBRIDGE_UPDATE_KEY"
+)
+
+// Options of the update command
+var (
+	updateCheck         bool
+	updateTag           string
+	updateKeyFile       string
+	updateAllowUnsigned bool
+	updateForce         bool
+	updateTimeout       time.Duration
+)
+
+// NewUpdateCmd creates the update command
+func NewUpdateCmd() *cobra.Command {
+	var updateCmd = &cobra.Command{
+		Use:   "update",
+		Short: "Replace agentbridge with the latest GitHub release",
+		Long: `Download the latest release of agentbridge, or the one of --tag, and replace the running
+binary with it, for hosts without a package manager.
+
+The binary is checked against the checksums.txt of the release before it is installed, and
+checksums.txt against its detached signature checksums.txt.sig with the release public key:
+the one of --key, else of ` + EnvUpdateKey + `, else the one release builds embed. Without a key
+the update is refused; --allow-unsigned installs a release checked against checksums.txt alone.
+The new binary is written next to the old one and renamed over it, so a failed update leaves the
+old binary working. The GitHub API is read from ` + EnvUpdateAPI + ` when set (e.g.
+a GitHub Enterprise mirror), with GITHUB_TOKEN when set.`,
+		Example: `  # Only report whether a newer release exists
+  agentbridge update --check
+
+  # Update, checking the release signature with the embedded key
+  agentbridge update
+
+  # Update a development build, checking the release signature with a key file
+  agentbridge update --key agentbridge-release.pem
+
+  # Install a given release, also an older one
+  agentbridge update --tag v1.4.0 --force`,
+		Args: cobra.NoArgs,
+		RunE: runUpdate,
+	}
+
+	updateCmd.Flags().BoolVar(&updateCheck, "check", false, "Only report whether a newer release exists")
+	updateCmd.Flags().StringVar(&updateTag, "tag", "", "Install this release instead of the latest, e.g. v1.4.0")
+	updateCmd.Flags().StringVar(&updateKeyFile, "key", "", "PEM public key that checks the release signature (env: "+EnvUpdateKey+"; default: the key embedded in release builds)")
+	updateCmd.Flags().BoolVar(&updateAllowUnsigned, "allow-unsigned", false, "Install a release without checking its signature when no release public key is available")
+	updateCmd.Flags().BoolVar(&updateForce, "force", false, "Install the release even when it is not newer than this binary")
+	updateCmd.Flags().DurationVar(&updateTimeout, "timeout", 5*time.Minute, "Time limit of the update")
+
+	return updateCmd
+}
+
+// runUpdate executes the update command
+func runUpdate(cmd *cobra.Command, args []string) error {
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+
+	updater := &update.Updater{API: os.Getenv(EnvUpdateAPI), Token: os.Getenv("GITHUB_TOKEN"), AllowUnsigned: updateAllowUnsigned}
+	keyFile := updateKeyFile
+	if keyFile == "" {
+		keyFile = os.Getenv(EnvUpdateKey)
+	}
+	var err error
+	if keyFile != "" {
+		updater.PublicKey, err = signing.LoadPublicKey(keyFile)
+	} else {
+		updater.PublicKey, err = update.EmbeddedKey()
+	}
+	// Key and network failures are not usage errors
+	cmd.SilenceUsage = true
+	if err != nil {
+		return err
+	}
+	if updater.PublicKey == nil && !updateAllowUnsigned && !updateCheck {
+		return withExitCode(ExitValidation, fmt.Errorf("%w: this build embeds no release key; pass --key (env: %s), or --allow-unsigned to trust %s alone",
+			update.ErrUnsigned, EnvUpdateKey, update.ChecksumsAsset))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+	defer cancel()
+
+	var release *update.Release
+	if updateTag != "" {
+		release, err = updater.Release(ctx, updateTag)
+	} else {
+		release, err = updater.Latest(ctx)
+	}
+	if err != nil {
+		return withExitCode(ExitIO, fmt.Errorf("failed to look up the release: %w", err))
+	}
+
+	current := getVersion()
+	newer := update.Newer(release.TagName, current)
+	if updateCheck {
+		if newer {
+			fmt.Printf("⬆️  %s is available (this is %s): %s\n", release.TagName, current, release.HTMLURL)
+		} else {
+			fmt.Printf("✅ %s is up to date\n", current)
+		}
+		return nil
+	}
+	if !newer && !updateForce {
+		fmt.Printf("✅ %s is up to date (latest release %s; --force installs it anyway)\n", current, release.TagName)
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running binary: %w", err)
+	}
+	fmt.Printf("📥 Downloading %s for %s...\n", release.TagName, update.AssetName(runtime.GOOS, runtime.GOARCH))
+	binary, err := updater.Download(ctx, release)
+	if err != nil {
+		code := ExitIO
+		if errors.Is(err, signing.ErrChecksumMismatch) || errors.Is(err, signing.ErrInvalidSignature) || errors.Is(err, update.ErrUnsigned) {
+			code = ExitValidation
+		}
+		return withExitCode(code, fmt.Errorf("update to %s failed: %w", release.TagName, err))
+	}
+	if updater.PublicKey == nil {
+		fmt.Println("⚠️  Checksum verified, signature not checked (--allow-unsigned)")
+	}
+	if err := update.Replace(executable, binary); err != nil {
+		return withExitCode(ExitIO, err)
+	}
+
+	fmt.Printf("✅ Updated %s to %s\n", current, release.TagName)
+	return nil
+}
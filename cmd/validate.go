@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/iflytek/agentbridge/core"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	"github.com/iflytek/agentbridge/platforms/unified/schema"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -25,6 +28,9 @@ Supports automatic platform detection and comprehensive validation rules.`,
   # Validate Dify DSL file
   agentbridge validate --input dify.yml --from dify
 
+  # Validate an edited unified DSL against its JSON Schema
+  agentbridge validate --input workflow.unified.yml --from unified
+
   # Auto-detect format and validate
   agentbridge validate --input workflow.yml`,
 		RunE: runValidate,
@@ -32,7 +38,8 @@ Supports automatic platform detection and comprehensive validation rules.`,
 
 	// Configure validate command flags
 	validateCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input DSL file path (required)")
-	validateCmd.Flags().StringVar(&sourceType, "from", "", "Source platform (iflytek|dify|coze, auto-detect if not specified)")
+	validateCmd.Flags().StringVar(&sourceType, "from", "", "Source platform (iflytek|dify|coze|unified, auto-detect if not specified)")
+	validateCmd.Flags().StringVar(&rulesFile, "rules", "", "YAML file of custom validation rules (structure and semantic stages)")
 
 	// Mark required flags
 	validateCmd.MarkFlagRequired("input")
@@ -63,6 +70,12 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if len(validationErrors) == 0 {
+		validationErrors, err = executeRuleValidation(ctx)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Output results
 	return outputValidationResults(ctx, validationErrors)
@@ -95,9 +108,9 @@ func prepareValidationContext() (*validationContext, error) {
 	// Auto-detect format if not specified
 	detectedType := sourceType
 	if detectedType == "" {
-		detectedType = detectSourceType(inputData)
-		if verbose {
-			fmt.Printf("🔍 Detected platform: %s\n", detectedType)
+		detectedType, err = detectSourceType(inputData)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -121,11 +134,36 @@ func executeValidation(ctx *validationContext) ([]string, error) {
 		return validateDifyDSL(ctx.inputData), nil
 	case "coze":
 		return validateCozeDSL(ctx.inputData), nil
+	case "unified":
+		return validateUnifiedDSL(ctx.inputData), nil
 	default:
 		return nil, fmt.Errorf("unsupported platform type: %s", ctx.sourceType)
 	}
 }
 
+// executeRuleValidation runs the --rules validators on the parsed workflow
+func executeRuleValidation(ctx *validationContext) ([]string, error) {
+	if err := loadValidationRules(); err != nil {
+		return nil, err
+	}
+	if len(validationRules) == 0 {
+		return nil, nil
+	}
+
+	conversionService, err := core.InitializeArchitecture()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+	warnings, err := conversionService.ValidateWithRules(ctx.inputData, models.PlatformType(ctx.sourceType), validationRules)
+	for _, warning := range warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+	return nil, nil
+}
+
 // outputValidationResults outputs validation results
 func outputValidationResults(ctx *validationContext, validationErrors []string) error {
 	if len(validationErrors) == 0 {
@@ -151,7 +189,7 @@ func outputValidationFailure(validationErrors []string) error {
 	for i, err := range validationErrors {
 		fmt.Printf("   %d. %s\n", i+1, err)
 	}
-	return fmt.Errorf("validation failed")
+	return withExitCode(ExitValidation, fmt.Errorf("validation failed"))
 }
 
 // validateIflytekDSL validates iFlytek DSL format
@@ -160,7 +198,7 @@ func validateIflytekDSL(data []byte) []string {
 
 	// Basic YAML format validation
 	var yamlData map[string]interface{}
-	if err := yaml.Unmarshal(data, &yamlData); err != nil {
+	if err := common.UnmarshalYAMLDocument(data, &yamlData, "iFlytek", "flowMeta", "flowData"); err != nil {
 		errors = append(errors, fmt.Sprintf("YAML format error: %v", err))
 		return errors
 	}
@@ -194,7 +232,7 @@ func validateDifyDSL(data []byte) []string {
 
 	// Basic YAML format validation
 	var yamlData map[string]interface{}
-	if err := yaml.Unmarshal(data, &yamlData); err != nil {
+	if err := common.UnmarshalYAMLDocument(data, &yamlData, "Dify", "app", "workflow"); err != nil {
 		errors = append(errors, fmt.Sprintf("YAML format error: %v", err))
 		return errors
 	}
@@ -210,6 +248,25 @@ func validateDifyDSL(data []byte) []string {
 	return errors
 }
 
+// validateUnifiedDSL validates a unified DSL against its JSON Schema, one error per violation
+func validateUnifiedDSL(data []byte) []string {
+	var document yaml.Node
+	if err := common.UnmarshalYAMLDocument(data, &document, "unified DSL", "version", "workflow"); err != nil {
+		return []string{fmt.Sprintf("YAML format error: %v", err)}
+	}
+
+	err := schema.Validate(&document)
+	var validationErr *schema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+	messages := make([]string, len(validationErr.Violations))
+	for i, violation := range validationErr.Violations {
+		messages[i] = violation.String()
+	}
+	return messages
+}
+
 // validateCozeDSL validates Coze DSL format
 func validateCozeDSL(data []byte) []string {
 	var errors []string
@@ -229,7 +286,7 @@ func validateCozeDSL(data []byte) []string {
 
 	// YAML format path: quick syntax check first
 	var yamlData map[string]interface{}
-	if err := yaml.Unmarshal(data, &yamlData); err != nil {
+	if err := common.UnmarshalYAMLDocument(data, &yamlData, "Coze", "name", "nodes"); err != nil {
 		errors = append(errors, fmt.Sprintf("YAML format error: %v", err))
 		return errors
 	}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iflytek/agentbridge/internal/signing"
+
+	"github.com/spf13/cobra"
+)
+
+// Files checked by the verify command; empty paths default to the sidecars next to the input
+var (
+	verifyKeyFile string
+	signatureFile string
+	checksumFile  string
+)
+
+// NewVerifyCmd creates the verify command
+func NewVerifyCmd() *cobra.Command {
+	var verifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the checksum and signature of a generated file",
+		Long: `Verify a generated DSL file against the checksum written by --checksum and the detached
+signature written by --sign, before importing it into a production platform.
+
+The checksum is read from <input>.sha256 when present. The signature is read from <input>.sig and
+checked when a public key is given; the key may be a PEM public key, a certificate or the signing
+key itself. Any mismatch exits with an error.`,
+		Example: `  # Check the checksum written by --checksum
+  agentbridge verify --input agent.yml
+
+  # Check the checksum and the signature written by --sign key.pem
+  agentbridge verify --input agent.yml --key public.pem
+
+  # Signature stored elsewhere
+  agentbridge verify --input agent.yml --key public.pem --signature release/agent.yml.sig`,
+		RunE: runVerify,
+	}
+
+	verifyCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Generated DSL file to verify (required)")
+	verifyCmd.Flags().StringVar(&verifyKeyFile, "key", "", "PEM public key or certificate that checks the signature")
+	verifyCmd.Flags().StringVar(&signatureFile, "signature", "", "Detached signature file (default <input>.sig)")
+	verifyCmd.Flags().StringVar(&checksumFile, "checksum", "", "Checksum file in sha256sum format (default <input>.sha256)")
+
+	verifyCmd.MarkFlagRequired("input")
+
+	return verifyCmd
+}
+
+// runVerify executes the verify command
+func runVerify(cmd *cobra.Command, args []string) error {
+	restore := redirectStdoutIfQuiet()
+	defer restore()
+	if quiet {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	if !quiet {
+		printHeader("Artifact Verification")
+	}
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	checked, err := verifyChecksumFile(data)
+	if err != nil {
+		return err
+	}
+
+	if signatureFile != "" && verifyKeyFile == "" {
+		return fmt.Errorf("--signature needs --key to be checked")
+	}
+	if verifyKeyFile != "" {
+		if err := verifySignatureFile(data); err != nil {
+			return err
+		}
+		checked = true
+	}
+
+	if !checked {
+		return fmt.Errorf("nothing to verify: %s not found and no --key given", inputFile+signing.ChecksumSuffix)
+	}
+
+	fmt.Printf("✅ %s verified\n", inputFile)
+	return nil
+}
+
+// verifyChecksumFile checks data against the checksum file and reports whether one was found
+func verifyChecksumFile(data []byte) (bool, error) {
+	path := checksumFile
+	if path == "" {
+		path = inputFile + signing.ChecksumSuffix
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && checksumFile == "" {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read checksum file: %w", err)
+	}
+
+	expected, err := signing.ParseChecksumFile(content, filepath.Base(inputFile))
+	if err != nil {
+		return false, fmt.Errorf("invalid checksum file %s: %w", path, err)
+	}
+	if err := signing.VerifyChecksum(data, expected); err != nil {
+		return false, fmt.Errorf("%s: %w", inputFile, err)
+	}
+
+	fmt.Printf("   Checksum: OK (%s)\n", path)
+	return true, nil
+}
+
+// verifySignatureFile checks the detached signature of data with the --key public key
+func verifySignatureFile(data []byte) error {
+	path := signatureFile
+	if path == "" {
+		path = inputFile + signing.SignatureSuffix
+	}
+
+	key, err := signing.LoadPublicKey(verifyKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load verification key: %w", err)
+	}
+	signature, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read signature file: %w", err)
+	}
+	if err := signing.Verify(key, data, signature); err != nil {
+		return fmt.Errorf("%s: %w (signature %s, key %s)", inputFile, err, path, verifyKeyFile)
+	}
+
+	fmt.Printf("   Signature: valid (%s)\n", path)
+	return nil
+}
@@ -0,0 +1,461 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+
+	"github.com/spf13/cobra"
+)
+
+// errWizardAborted is returned when standard input ends before the wizard is done
+var errWizardAborted = errors.New("wizard aborted: no more input")
+
+// wizardPlatforms lists the platforms offered by the wizard, in menu order
+var wizardPlatforms = []string{"iflytek", "dify", "coze", "unified"}
+
+// NewWizardCmd creates the wizard command
+func NewWizardCmd() *cobra.Command {
+	var wizardCmd = &cobra.Command{
+		Use:   "wizard",
+		Short: "Interactive migration wizard",
+		Long: `Walk through a migration step by step in the terminal.
+
+The wizard asks for the source file, detects its platform, lets you pick the target, shows how
+every node converts and lets you decide per node whether placeholders are kept or rejected, how
+speech nodes are replaced and which model each LLM or classifier node uses. It then runs the
+conversion with these choices. Press Enter to accept the default shown in brackets.`,
+		Example: `  # Start from scratch
+  agentbridge wizard
+
+  # Start with a source file
+  agentbridge wizard --input dify.yml`,
+		RunE: runWizard,
+	}
+
+	wizardCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Source DSL file (asked when omitted)")
+	wizardCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output DSL file (asked when omitted)")
+
+	return wizardCmd
+}
+
+// migrationWizard holds the answers given so far
+type migrationWizard struct {
+	in      *bufio.Reader
+	service *services.ConversionService
+
+	inputData  []byte
+	source     string
+	target     string
+	strategies map[string]string // Node ID -> placeholder or audio strategy
+	models     map[string]string // Node ID -> target model name
+}
+
+// runWizard executes the wizard command
+func runWizard(cmd *cobra.Command, args []string) error {
+	printHeader("Migration Wizard")
+
+	service, err := core.InitializeArchitecture()
+	if err != nil {
+		return fmt.Errorf("failed to initialize architecture: %w", err)
+	}
+
+	wizard := &migrationWizard{
+		in:         bufio.NewReader(os.Stdin),
+		service:    service,
+		strategies: make(map[string]string),
+		models:     make(map[string]string),
+	}
+
+	steps := []func() error{
+		wizard.selectSourceFile,
+		wizard.selectSourcePlatform,
+		wizard.selectTargetPlatform,
+		wizard.reviewNodes,
+		wizard.convert,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+	}
+	return nil
+}
+
+// selectSourceFile asks for the source file until a readable DSL file is given
+func (w *migrationWizard) selectSourceFile() error {
+	fmt.Printf("\n📂 Step 1/5 · Source file\n")
+	for {
+		if inputFile == "" {
+			answer, err := w.ask("   Path of the workflow to migrate", "")
+			if err != nil {
+				return err
+			}
+			inputFile = answer
+		}
+
+		err := validateInputFile(inputFile)
+		if err == nil {
+			w.inputData, err = os.ReadFile(inputFile)
+		}
+		if err == nil {
+			fmt.Printf("   %s (%d bytes)\n", inputFile, len(w.inputData))
+			return nil
+		}
+		fmt.Printf("   ❌ %v\n", err)
+		inputFile = ""
+	}
+}
+
+// selectSourcePlatform confirms the detected platform or asks for it
+func (w *migrationWizard) selectSourcePlatform() error {
+	fmt.Printf("\n🔍 Step 2/5 · Source platform\n")
+	detection, err := common.DetectPlatform(w.inputData)
+	if err != nil {
+		fmt.Printf("   Could not detect the platform: %v\n", err)
+	} else {
+		fmt.Printf("   Detected %s (confidence %.0f%%)\n", detection.Platform, detection.Confidence*100)
+		ok, err := w.confirm("   Is this right?", true)
+		if err != nil {
+			return err
+		}
+		if ok {
+			w.source = string(detection.Platform)
+			return nil
+		}
+	}
+
+	w.source, err = w.choose("   Source platform", wizardPlatforms, "")
+	return err
+}
+
+// selectTargetPlatform asks for one of the targets reachable from the source
+func (w *migrationWizard) selectTargetPlatform() error {
+	fmt.Printf("\n🎯 Step 3/5 · Target platform\n")
+	targets := wizardTargets(w.source)
+	var err error
+	w.target, err = w.choose("   Convert to", targets, targets[0])
+	return err
+}
+
+// wizardTargets returns the targets convert supports from a source platform; Dify and Coze
+// only reach each other through iFlytek
+func wizardTargets(source string) []string {
+	var targets []string
+	for _, target := range wizardPlatforms {
+		if target == source {
+			continue
+		}
+		if source == "iflytek" || target == "iflytek" || source == "unified" || target == "unified" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// reviewNodes shows the compatibility of every node and collects the per-node choices until the
+// dry run passes
+func (w *migrationWizard) reviewNodes() error {
+	fmt.Printf("\n🧩 Step 4/5 · Node compatibility\n")
+	report, err := w.check()
+	if err != nil {
+		return err
+	}
+	printCheckNodes(report)
+	printCheckSummary(report)
+
+	choices := w.choiceNodes(report)
+	review := false
+	if len(choices) > 0 {
+		question := fmt.Sprintf("   Review %d placeholder, speech and model choices node by node?", len(choices))
+		if review, err = w.confirm(question, !report.Passed()); err != nil {
+			return err
+		}
+	}
+
+	for {
+		if review {
+			if err := w.askNodeChoices(choices); err != nil {
+				return err
+			}
+			if report, err = w.check(); err != nil {
+				return err
+			}
+			choices = w.choiceNodes(report)
+			printCheckNodes(report)
+			printCheckSummary(report)
+		}
+
+		if report.Passed() {
+			fmt.Printf("✅ Dry run conversion succeeded\n")
+			return nil
+		}
+
+		if report.DryRunError != nil {
+			fmt.Printf("❌ Dry run conversion failed: %v\n", report.DryRunError)
+		} else {
+			fmt.Printf("❌ %d nodes cannot be converted\n", report.Count(common.SupportUnsupported))
+		}
+		if len(choices) == 0 {
+			return fmt.Errorf("conversion to %s would fail", w.target)
+		}
+		if review, err = w.confirm("   Change the node choices?", true); err != nil {
+			return err
+		}
+		if !review {
+			return fmt.Errorf("conversion to %s would fail", w.target)
+		}
+	}
+}
+
+// choiceNodes returns the nodes the user can decide on: placeholders, speech nodes that can call
+// an HTTP service instead, and nodes with a model
+func (w *migrationWizard) choiceNodes(report *services.CheckReport) []services.NodeCheck {
+	var nodes []services.NodeCheck
+	for _, node := range report.Nodes {
+		if node.Placeholder || w.speechChoice(node) || node.Model != "" {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// speechChoice reports whether a speech node can call an HTTP service on the target
+func (w *migrationWizard) speechChoice(node services.NodeCheck) bool {
+	isSpeech := node.Type == models.NodeTypeTextToSpeech || node.Type == models.NodeTypeSpeechToText
+	return isSpeech && (w.target == "dify" || w.target == "coze")
+}
+
+// askNodeChoices asks for the strategy and model of every choice node
+func (w *migrationWizard) askNodeChoices(nodes []services.NodeCheck) error {
+	options := w.options()
+	for _, node := range nodes {
+		fmt.Printf("\n   %s %s [%s]\n", checkLevelIcons[node.Level], node.Title, node.Type)
+		if node.Note != "" {
+			fmt.Printf("      %s\n", node.Note)
+		}
+
+		if node.Placeholder || w.speechChoice(node) {
+			choices := []string{models.PlaceholderStrategyPlaceholder, models.PlaceholderStrategyFail}
+			current := options.PlaceholderStrategyFor(node.ID)
+			if w.speechChoice(node) {
+				choices = []string{models.AudioStrategyPlaceholder, models.AudioStrategyHTTP, models.PlaceholderStrategyFail}
+				if current != models.PlaceholderStrategyFail {
+					current = options.AudioStrategyFor(node.ID)
+				}
+			}
+			if current == "" {
+				current = models.PlaceholderStrategyPlaceholder
+			}
+			strategy, err := w.choose("      Conversion", choices, current)
+			if err != nil {
+				return err
+			}
+			if strategy == models.PlaceholderStrategyPlaceholder {
+				// The default of every conversion
+				delete(w.strategies, node.ID)
+			} else {
+				w.strategies[node.ID] = strategy
+			}
+		}
+
+		if node.Model != "" {
+			current := node.Model
+			if mapped, ok := w.models[node.ID]; ok {
+				current = mapped
+			}
+			model, err := w.ask("      Target model", current)
+			if err != nil {
+				return err
+			}
+			if model == node.Model {
+				delete(w.models, node.ID)
+			} else {
+				w.models[node.ID] = model
+			}
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// convert asks for the output file and runs the conversion with the chosen options
+func (w *migrationWizard) convert() error {
+	fmt.Printf("\n🔄 Step 5/5 · Convert\n")
+	if outputFile == "" {
+		base := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
+		defaultPath := filepath.Join(filepath.Dir(inputFile), fmt.Sprintf("%s.%s.yml", base, w.target))
+		answer, err := w.ask("   Output file", defaultPath)
+		if err != nil {
+			return err
+		}
+		outputFile = answer
+	}
+	if _, err := os.Stat(outputFile); err == nil {
+		overwrite, err := w.confirm(fmt.Sprintf("   %s exists, overwrite it?", outputFile), false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			return fmt.Errorf("output file %s exists", outputFile)
+		}
+	}
+
+	fmt.Printf("   %s (%s) → %s (%s)", inputFile, w.source, outputFile, w.target)
+	if len(w.strategies)+len(w.models) > 0 {
+		fmt.Printf(", %d node strategies, %d model choices", len(w.strategies), len(w.models))
+	}
+	fmt.Println()
+	proceed, err := w.confirm("   Convert now?", true)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		return fmt.Errorf("conversion cancelled")
+	}
+
+	conversionStart := time.Now()
+	result, err := w.service.ConvertWithResult(context.Background(), w.inputData,
+		models.PlatformType(w.source), models.PlatformType(w.target), w.options())
+	recordConversion("wizard", models.PlatformType(w.source), models.PlatformType(w.target), result, err, time.Since(conversionStart))
+	if err != nil {
+		return fmt.Errorf("conversion failed: %w", err)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+
+	if err := createOutputDirectory(); err != nil {
+		return err
+	}
+	if err := writeOutputFile(result.Output); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Conversion completed successfully!\n")
+	fmt.Printf("   Output file: %s (%d bytes)\n", outputFile, len(result.Output))
+	return nil
+}
+
+// check runs the pre-flight check with the choices made so far
+func (w *migrationWizard) check() (*services.CheckReport, error) {
+	report, err := w.service.Check(w.inputData, models.PlatformType(w.source), models.PlatformType(w.target), w.options())
+	if err != nil {
+		return nil, fmt.Errorf("check failed: %w", err)
+	}
+	return report, nil
+}
+
+// options returns the conversion options of the choices made so far
+func (w *migrationWizard) options() *models.ConversionOptions {
+	options := models.NewConversionOptions()
+	options.NodeStrategies = w.strategies
+	if len(w.models) > 0 {
+		// After the model map, so the choice made for the node wins
+		options.NodeHooks = []models.NodeHook{models.NodeHookFuncs{
+			After: func(ctx models.NodeHookContext, node *models.Node) error {
+				if model, ok := w.models[node.ID]; ok {
+					common.SetNodeModelName(node, model)
+				}
+				return nil
+			},
+		}}
+	}
+	return options
+}
+
+// ask prints a question and returns the answer, or defaultValue for an empty answer
+func (w *migrationWizard) ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Printf("%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+
+	line, err := w.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Println()
+		return "", errWizardAborted
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return defaultValue, nil
+}
+
+// confirm asks a yes/no question
+func (w *migrationWizard) confirm(question string, defaultYes bool) (bool, error) {
+	hint := "y/N"
+	if defaultYes {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := w.ask(question+" ("+hint+")", "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultYes, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Printf("   Please answer y or n\n")
+	}
+}
+
+// choose asks for one of choices by number, name or unique name prefix
+func (w *migrationWizard) choose(question string, choices []string, defaultChoice string) (string, error) {
+	for i, choice := range choices {
+		fmt.Printf("      %d) %s\n", i+1, choice)
+	}
+	for {
+		answer, err := w.ask(question, defaultChoice)
+		if err != nil {
+			return "", err
+		}
+		if choice, ok := matchChoice(answer, choices); ok {
+			return choice, nil
+		}
+		fmt.Printf("   Please enter a number from 1 to %d or a name\n", len(choices))
+	}
+}
+
+// matchChoice resolves a menu answer
+func matchChoice(answer string, choices []string) (string, bool) {
+	if index, err := strconv.Atoi(answer); err == nil {
+		if index >= 1 && index <= len(choices) {
+			return choices[index-1], true
+		}
+		return "", false
+	}
+
+	answer = strings.ToLower(answer)
+	var matched []string
+	for _, choice := range choices {
+		if choice == answer {
+			return choice, true
+		}
+		if answer != "" && strings.HasPrefix(choice, answer) {
+			matched = append(matched, choice)
+		}
+	}
+	if len(matched) == 1 {
+		return matched[0], true
+	}
+	return "", false
+}
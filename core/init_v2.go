@@ -6,6 +6,7 @@ import (
 	cozeStrategies "github.com/iflytek/agentbridge/platforms/coze/strategies"
 	"github.com/iflytek/agentbridge/platforms/dify/strategies"
 	iflytekStrategies "github.com/iflytek/agentbridge/platforms/iflytek/strategies"
+	unifiedStrategies "github.com/iflytek/agentbridge/platforms/unified/strategies"
 	"github.com/iflytek/agentbridge/registry"
 )
 
@@ -18,10 +19,12 @@ func InitializeArchitecture() (*services.ConversionService, error) {
 	cozeStrategy := cozeStrategies.NewCozeStrategy()
 	difyStrategy := strategies.NewDifyStrategy()
 	iflytekStrategy := iflytekStrategies.NewIFlytekStrategy()
+	unifiedStrategy := unifiedStrategies.NewUnifiedStrategy()
 
 	strategyRegistry.RegisterStrategy(models.PlatformCoze, cozeStrategy)
 	strategyRegistry.RegisterStrategy(models.PlatformDify, difyStrategy)
 	strategyRegistry.RegisterStrategy(models.PlatformIFlytek, iflytekStrategy)
+	strategyRegistry.RegisterStrategy(models.PlatformUnified, unifiedStrategy)
 
 	// Create conversion service
 	conversionService := services.NewConversionService(strategyRegistry)
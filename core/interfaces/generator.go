@@ -1,6 +1,8 @@
 package interfaces
 
 import (
+	"context"
+
 	"github.com/iflytek/agentbridge/internal/models"
 )
 
@@ -39,3 +41,64 @@ type IDMapper interface {
 	// SetMapping sets the mapping table
 	SetMapping(mapping map[string]string)
 }
+
+// ConfigurableGenerator is implemented by generators that accept conversion options
+type ConfigurableGenerator interface {
+	// Configure applies conversion options before generation
+	Configure(options *models.ConversionOptions) error
+}
+
+// ConfigurableParser is implemented by parsers that support the parse modes
+type ConfigurableParser interface {
+	// SetParseMode selects models.ParseModeStrict, models.ParseModePermissive or models.ParseModeLenient
+	SetParseMode(mode string)
+	// Issues returns the source problems the last parse worked around
+	Issues() []string
+}
+
+// QuietParser is implemented by parsers that print a summary after each parse
+type QuietParser interface {
+	// SetQuiet leaves the summary out, for parses repeating one already reported
+	SetQuiet(quiet bool)
+}
+
+// ArchiveParser is implemented by parsers that read ZIP archives
+type ArchiveParser interface {
+	// SetArchiveLimits bounds the decompressed size, entry count and nesting of archives; zero values keep the defaults
+	SetArchiveLimits(limits models.ArchiveLimits)
+}
+
+// DebugParser is implemented by parsers with intermediate results worth inspecting, such as the
+// workflow JSON extracted from an archive
+type DebugParser interface {
+	// SetDebugDir saves the intermediate results of the next parses into dir; empty saves nothing
+	SetDebugDir(dir string)
+}
+
+// MappingProvider is implemented by generators that expose the source-to-target node ID mapping of the last generation
+type MappingProvider interface {
+	// GetNodeIDMapping returns a copy of the source node ID -> target node ID mapping
+	GetNodeIDMapping() map[string]string
+	// GetIDMapping returns the node, output, branch and intent ID mappings
+	GetIDMapping() *models.IDMapping
+}
+
+// MappingGenerator is implemented by generators that return the ID mapping together with the output,
+// which stays correct when one instance runs several generations concurrently
+type MappingGenerator interface {
+	// GenerateWithMapping creates target platform DSL and the ID mapping of this generation
+	GenerateWithMapping(unifiedDSL *models.UnifiedDSL) ([]byte, *models.IDMapping, error)
+}
+
+// ContextParser is implemented by parsers that stop with the context error once ctx is done,
+// for example between ZIP entries and nodes
+type ContextParser interface {
+	// ParseWithContext converts DSL file to unified format unless ctx is cancelled first
+	ParseWithContext(ctx context.Context, data []byte) (*models.UnifiedDSL, error)
+}
+
+// ContextGenerator is implemented by generators that check ctx between generation passes and nodes
+type ContextGenerator interface {
+	// GenerateWithContext creates target platform DSL and its ID mapping unless ctx is cancelled first
+	GenerateWithContext(ctx context.Context, unifiedDSL *models.UnifiedDSL) ([]byte, *models.IDMapping, error)
+}
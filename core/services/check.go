@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// NodeCheck is the pre-flight verdict for one source node.
+type NodeCheck struct {
+	ID          string
+	Title       string
+	Type        models.NodeType
+	IterationID string               // Enclosing iteration node, empty for top-level nodes
+	Model       string               // Model of LLM and classifier nodes
+	Code        []common.CodeFinding // Static analysis of code nodes against the target sandbox
+	common.NodeCapability
+}
+
+// CheckReport describes how a source workflow would convert to a target platform.
+type CheckReport struct {
+	SourcePlatform models.PlatformType
+	TargetPlatform models.PlatformType
+	Nodes          []NodeCheck
+	ParseIssues    []string // Source problems worked around in permissive parse mode
+	DryRunError    error    // Error of the in-memory conversion, nil when the target DSL could be generated
+}
+
+// Count returns the number of nodes with the given support level.
+func (r *CheckReport) Count(level common.SupportLevel) int {
+	count := 0
+	for _, node := range r.Nodes {
+		if node.Level == level {
+			count++
+		}
+	}
+	return count
+}
+
+// Placeholders returns the number of nodes that become code placeholders.
+func (r *CheckReport) Placeholders() int {
+	count := 0
+	for _, node := range r.Nodes {
+		if node.Placeholder {
+			count++
+		}
+	}
+	return count
+}
+
+// CodeFindings returns the number of code findings with the given severity.
+func (r *CheckReport) CodeFindings(severity models.ErrorSeverity) int {
+	count := 0
+	for _, node := range r.Nodes {
+		for _, finding := range node.Code {
+			if finding.Severity == severity {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Passed reports whether the workflow converts without failures.
+func (r *CheckReport) Passed() bool {
+	return r.DryRunError == nil && r.Count(common.SupportUnsupported) == 0
+}
+
+// Check analyzes the source DSL against the capability matrix of the target platform without
+// producing output. Every node, including iteration sub-workflow nodes, is classified and code is
+// analyzed for constructs the target sandbox may reject; a dry run conversion in memory catches
+// failures the matrix cannot predict.
+func (s *ConversionService) Check(
+	sourceData []byte,
+	sourcePlatform, targetPlatform models.PlatformType,
+	options *models.ConversionOptions,
+) (*CheckReport, error) {
+	unifiedDSL, parseIssues, err := s.parseSource(context.Background(), sourceData, sourcePlatform, targetPlatform, options)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CheckReport{
+		SourcePlatform: sourcePlatform,
+		TargetPlatform: targetPlatform,
+		Nodes:          checkNodes(unifiedDSL.Workflow.Nodes, "", targetPlatform, options, nil),
+		ParseIssues:    parseIssues,
+	}
+	_, report.DryRunError = s.convertUnified(context.Background(), unifiedDSL, sourcePlatform, targetPlatform, options)
+
+	return report, nil
+}
+
+// checkNodes classifies nodes and descends into iteration sub-workflows; sub-nodes kept beside
+// their iteration are attributed to it through their config.
+func checkNodes(nodes []models.Node, iterationID string, targetPlatform models.PlatformType, options *models.ConversionOptions, checks []NodeCheck) []NodeCheck {
+	for _, node := range nodes {
+		model, _ := common.NodeModelName(&node)
+		parent := iterationID
+		if parent == "" {
+			parent = common.IterationParent(&node)
+		}
+		checks = append(checks, NodeCheck{
+			ID:             node.ID,
+			Title:          node.Title,
+			Type:           node.Type,
+			IterationID:    parent,
+			Model:          model,
+			Code:           common.AnalyzeCode(&node, targetPlatform),
+			NodeCapability: common.NodeCapabilityFor(node, targetPlatform, options),
+		})
+		if iterConfig, ok := common.AsIterationConfig(node.Config); ok && iterConfig != nil {
+			checks = checkNodes(iterConfig.SubWorkflow.Nodes, node.ID, targetPlatform, options, checks)
+		}
+	}
+	return checks
+}
@@ -2,13 +2,20 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/iflytek/agentbridge/core/interfaces"
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
-// ConversionService orchestrates DSL conversion between platforms.
+// ConversionService orchestrates DSL conversion between platforms. It is safe for concurrent use:
+// every conversion gets its own parser and generator, and generators keep per-conversion state
+// in a generation context of their own.
 type ConversionService struct {
 	strategyRegistry StrategyRegistry
 }
@@ -34,50 +41,464 @@ func (s *ConversionService) ConvertWithContext(
 	sourceData []byte,
 	sourcePlatform, targetPlatform models.PlatformType,
 ) ([]byte, error) {
-	// Check platform support
-	if err := s.validatePlatformSupport(sourcePlatform, targetPlatform); err != nil {
+	return s.ConvertWithOptions(ctx, sourceData, sourcePlatform, targetPlatform, nil)
+}
+
+// ConversionResult holds the generated DSL together with conversion details.
+type ConversionResult struct {
+	Output         []byte
+	SourcePlatform models.PlatformType
+	TargetPlatform models.PlatformType
+	NodeMapping    map[string]string       // Source node ID -> target node ID
+	IDMapping      *models.IDMapping       // Node, output, branch and intent ID mappings
+	Warnings       []string                // Constructs the target DSL only approximates
+	MinifyReport   *common.MinifyReport    // Size savings, when the output was minified
+	NodeTypes      map[models.NodeType]int // Source nodes per type, iteration sub-workflows included
+	Placeholders   int                     // Nodes converted to unsupported node placeholders
+}
+
+// ConvertWithOptions performs DSL conversion using the provided generation options.
+func (s *ConversionService) ConvertWithOptions(
+	ctx context.Context,
+	sourceData []byte,
+	sourcePlatform, targetPlatform models.PlatformType,
+	options *models.ConversionOptions,
+) ([]byte, error) {
+	result, err := s.ConvertWithResult(ctx, sourceData, sourcePlatform, targetPlatform, options)
+	if err != nil {
+		return nil, err
+	}
+	return result.Output, nil
+}
+
+// ConvertWithResult performs DSL conversion and returns the output with its conversion details.
+func (s *ConversionService) ConvertWithResult(
+	ctx context.Context,
+	sourceData []byte,
+	sourcePlatform, targetPlatform models.PlatformType,
+	options *models.ConversionOptions,
+) (*ConversionResult, error) {
+	ctx = progressContext(ctx, options)
+	unifiedDSL, parseIssues, err := s.parseSource(ctx, sourceData, sourcePlatform, targetPlatform, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Identity conversions keep the source IDs unless an earlier mapping says otherwise, and are
+	// checked against the source unless the options edit the workflow on purpose
+	identity := sourcePlatform == targetPlatform && sourcePlatform != models.PlatformUnified
+	checked := identity && !editsWorkflow(options)
+	if identity && (options == nil || options.PreviousMapping == nil) {
+		identityOptions := models.NewConversionOptions()
+		if options != nil {
+			copied := *options
+			identityOptions = &copied
+		}
+		identityOptions.PreviousMapping = common.IdentityMapping(unifiedDSL, sourcePlatform)
+		options = identityOptions
+	}
+
+	result, err := s.convertUnified(ctx, unifiedDSL, sourcePlatform, targetPlatform, options)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(parseIssues, result.Warnings...)
+	if checked {
+		if err := s.checkIdentity(sourceData, result.Output, sourcePlatform, options); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// editsWorkflow reports whether the options change the workflow beyond its metadata, so that an
+// identity conversion no longer reproduces the source
+func editsWorkflow(options *models.ConversionOptions) bool {
+	if options == nil {
+		return false
+	}
+	return options.Anonymize || len(options.IncludeNodes) > 0 || options.SubgraphFrom != "" ||
+		len(options.ModelMap) > 0 || options.TitlePrefix != "" || options.TitleSuffix != "" ||
+		options.TitleTemplate != "" || len(options.NodeHooks) > 0 || options.Policy != nil ||
+		options.PreviousMapping != nil
+}
+
+// checkIdentity parses the source and the output of an identity conversion and fails listing the
+// functional differences between them
+func (s *ConversionService) checkIdentity(sourceData, output []byte, platform models.PlatformType, options *models.ConversionOptions) error {
+	parser, err := s.getParser(platform)
+	if err != nil {
+		return nil
+	}
+	if configurableParser, ok := parser.(interfaces.ConfigurableParser); ok && options != nil {
+		configurableParser.SetParseMode(options.ParseMode)
+	}
+	// The conversion already printed its parse summary
+	if quietParser, ok := parser.(interfaces.QuietParser); ok {
+		quietParser.SetQuiet(true)
+	}
+	differences, err := s.identityDifferences(parser, sourceData, output)
+	if err != nil {
+		differences = []string{err.Error()}
+	}
+	if len(differences) == 0 {
+		return nil
+	}
+	return &models.ConversionError{
+		Code:           "IDENTITY_CHANGED",
+		Message:        fmt.Sprintf("Identity conversion changed the workflow: %s", strings.Join(differences, "; ")),
+		SourcePlatform: string(platform),
+		TargetPlatform: string(platform),
+		ErrorType:      "identity_error",
+		Details:        strings.Join(differences, "\n"),
+		Severity:       models.SeverityError,
+		Suggestions: []string{
+			"Convert to the unified DSL (--to unified) to keep the workflow as parsed",
+		},
+	}
+}
+
+// identityDifferences parses the source and the output of an identity conversion again and
+// compares their workflows
+func (s *ConversionService) identityDifferences(parser interfaces.DSLParser, sourceData, output []byte) ([]string, error) {
+	source, err := parser.Parse(sourceData)
+	if err != nil {
+		return nil, fmt.Errorf("source cannot be parsed again: %w", err)
+	}
+	generated, err := parser.Parse(output)
+	if err != nil {
+		return nil, fmt.Errorf("output cannot be parsed: %w", err)
+	}
+	return common.CompareWorkflows(&source.Workflow, &generated.Workflow)
+}
+
+// ComposeSource is one workflow of a composition.
+type ComposeSource struct {
+	Data     []byte
+	Platform models.PlatformType
+	Name     string // Names the debug artifact directory of the source after its position
+}
+
+// ComposeWithResult parses the sources, appends each workflow after the one before it and generates
+// the result. mapping names, by start variable of a later workflow, the end output of the workflow
+// before it that feeds the variable; see common.AppendWorkflow. The result has the source platform
+// of the workflows when they share one, unified otherwise. With a debug directory each source saves
+// its parse artifacts in a subdirectory of its own, see composeSourceOptions.
+func (s *ConversionService) ComposeWithResult(
+	ctx context.Context,
+	sources []ComposeSource,
+	targetPlatform models.PlatformType,
+	mapping map[string]string,
+	options *models.ConversionOptions,
+) (*ConversionResult, error) {
+	if len(sources) < 2 {
+		return nil, fmt.Errorf("composition needs at least two workflows, got %d", len(sources))
+	}
+
+	ctx = progressContext(ctx, options)
+	var composed *models.UnifiedDSL
+	var warnings []string
+	sourcePlatform := sources[0].Platform
+	for i, source := range sources {
+		unifiedDSL, parseIssues, err := s.parseSource(ctx, source.Data, source.Platform, targetPlatform, composeSourceOptions(options, i, source.Name))
+		if err != nil {
+			return nil, fmt.Errorf("workflow %d: %w", i+1, err)
+		}
+		warnings = append(warnings, parseIssues...)
+		if source.Platform != sourcePlatform {
+			sourcePlatform = models.PlatformUnified
+		}
+		if composed == nil {
+			composed = unifiedDSL
+			continue
+		}
+
+		appendWarnings, err := common.AppendWorkflow(composed, unifiedDSL, mapping)
+		if err != nil {
+			return nil, &models.ConversionError{
+				Code:           "COMPOSITION_FAILED",
+				Message:        fmt.Sprintf("Cannot append workflow %d: %v", i+1, err),
+				SourcePlatform: string(source.Platform),
+				TargetPlatform: string(targetPlatform),
+				ErrorType:      "composition_error",
+				Details:        err.Error(),
+				Severity:       models.SeverityError,
+				Suggestions: []string{
+					"Map start variables to end outputs of the previous workflow explicitly",
+				},
+			}
+		}
+		warnings = append(warnings, appendWarnings...)
+	}
+
+	result, err := s.convertUnified(ctx, composed, sourcePlatform, targetPlatform, options)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(warnings, result.Warnings...)
+	return result, nil
+}
+
+// composeSourceOptions returns the options parsing the source at index uses: the debug directory
+// becomes its subdirectory <position>-<name>, or <position> without a name
+func composeSourceOptions(options *models.ConversionOptions, index int, name string) *models.ConversionOptions {
+	if options == nil || options.DebugDir == "" {
+		return options
+	}
+	dir := fmt.Sprint(index + 1)
+	if name != "" {
+		dir += "-" + name
+	}
+	copied := *options
+	copied.DebugDir = filepath.Join(options.DebugDir, dir)
+	return &copied
+}
+
+// convertUnified anonymizes, runs node hooks on, lowers, maps and names the nodes of a parsed DSL in place and
+// generates the target DSL. Once ctx is done it stops between stages, or inside parsers and generators that
+// implement the context interfaces, with an error wrapping ctx.Err().
+func (s *ConversionService) convertUnified(
+	ctx context.Context,
+	unifiedDSL *models.UnifiedDSL,
+	sourcePlatform, targetPlatform models.PlatformType,
+	options *models.ConversionOptions,
+) (*ConversionResult, error) {
+	if err := checkCancelled(ctx, "preprocessing"); err != nil {
+		return nil, err
+	}
+	models.ReportStage(ctx, models.ProgressStageTransform)
+
+	// Keep only the selected part of the workflow
+	var subgraphWarnings []string
+	if options != nil {
+		var err error
+		subgraphWarnings, err = common.ExtractSubgraph(unifiedDSL, options.IncludeNodes, options.SubgraphFrom)
+		if err != nil {
+			return nil, &models.ConversionError{
+				Code:           "INVALID_OPTIONS",
+				Message:        fmt.Sprintf("Invalid subgraph selection: %v", err),
+				SourcePlatform: string(sourcePlatform),
+				TargetPlatform: string(targetPlatform),
+				ErrorType:      "options_error",
+				Details:        err.Error(),
+				Severity:       models.SeverityError,
+				Suggestions: []string{
+					"Select nodes by the IDs they have in the source file",
+				},
+			}
+		}
+	}
+	nodeTypes := countNodeTypes(unifiedDSL.Workflow.Nodes, make(map[models.NodeType]int))
+
+	// Strip proprietary content before anything else sees it
+	if options != nil && options.Anonymize {
+		common.AnonymizeDSL(unifiedDSL)
+	}
+
+	// Let integrators edit the parsed nodes
+	if err := s.applyNodeHooks(unifiedDSL, common.HookPhaseBefore, sourcePlatform, targetPlatform, options); err != nil {
+		return nil, err
+	}
+
+	// Document triggers on targets without them, before placeholders are counted
+	triggerWarnings := common.LowerTriggers(unifiedDSL, targetPlatform, options)
+
+	// Replace nodes without a native target representation by their closest supported equivalent
+	common.LowerNodes(unifiedDSL, targetPlatform, options)
+	// Placeholders of the parser and of lowering, recognized by their marker
+	placeholders := len(common.CollectUnsupportedNodes(unifiedDSL))
+
+	// Reject placeholders when the caller asked for strict node support
+	if options != nil {
+		if unsupported := common.CollectRejectedPlaceholders(unifiedDSL, options); len(unsupported) > 0 {
+			return nil, &models.ConversionError{
+				Code:           "UNSUPPORTED_NODES",
+				Message:        fmt.Sprintf("%d unsupported nodes found", len(unsupported)),
+				SourcePlatform: string(sourcePlatform),
+				TargetPlatform: string(targetPlatform),
+				ErrorType:      "unsupported_nodes",
+				Details:        strings.Join(unsupported, ", "),
+				Severity:       models.SeverityError,
+				Suggestions: []string{
+					"Use placeholder_strategy: placeholder to convert unsupported nodes to code node placeholders",
+				},
+			}
+		}
+	}
+
+	// Route references into iteration bodies through the iteration outputs
+	iterationWarnings, err := common.RewriteCrossIterationReferences(unifiedDSL, targetPlatform)
+	if err != nil {
 		return nil, &models.ConversionError{
-			Code:           "PLATFORM_NOT_SUPPORTED",
-			Message:        "Platform validation failed",
+			Code:           "CROSS_ITERATION_REFERENCE",
+			Message:        fmt.Sprintf("Cannot convert to %s: %v", targetPlatform, err),
 			SourcePlatform: string(sourcePlatform),
 			TargetPlatform: string(targetPlatform),
-			ErrorType:      "platform_support",
+			ErrorType:      "invalid_reference",
 			Details:        err.Error(),
-			Severity:       models.SeverityCritical,
+			Severity:       models.SeverityError,
+			Suggestions: []string{
+				"Read values of an iteration body inside the iteration, or make them the iteration output",
+			},
 		}
 	}
 
-	// Get source platform parser
-	parser, err := s.getParser(sourcePlatform)
+	// Split condition cases with nested and/or groups into the flat cases platforms have
+	expressionWarnings, err := common.LowerConditionExpressions(unifiedDSL, targetPlatform)
 	if err != nil {
 		return nil, &models.ConversionError{
-			Code:           "PARSER_NOT_FOUND",
-			Message:        fmt.Sprintf("Failed to get parser for %s", sourcePlatform),
+			Code:           "UNSUPPORTED_CONDITION",
+			Message:        fmt.Sprintf("Cannot convert to %s: %v", targetPlatform, err),
 			SourcePlatform: string(sourcePlatform),
 			TargetPlatform: string(targetPlatform),
-			ErrorType:      "parser_error",
+			ErrorType:      "unsupported_condition",
 			Details:        err.Error(),
-			Severity:       models.SeverityCritical,
+			Severity:       models.SeverityError,
+			Suggestions: []string{
+				"Split the condition into several cases or nodes",
+			},
 		}
 	}
 
-	// Parse source DSL to unified format
-	unifiedDSL, err := parser.Parse(sourceData)
+	// Reject condition operators the target platform cannot express
+	warnings, err := common.CheckConditionOperators(unifiedDSL, targetPlatform)
 	if err != nil {
-		return nil, &models.ParseError{
-			Code:    "PARSE_FAILED",
-			Message: "Failed to parse source DSL",
+		return nil, &models.ConversionError{
+			Code:           "UNSUPPORTED_OPERATOR",
+			Message:        fmt.Sprintf("Cannot convert to %s: %v", targetPlatform, err),
+			SourcePlatform: string(sourcePlatform),
+			TargetPlatform: string(targetPlatform),
+			ErrorType:      "unsupported_operator",
+			Details:        err.Error(),
+			Severity:       models.SeverityError,
 			Suggestions: []string{
-				"Check DSL format and syntax",
-				"Verify all required fields are present",
-				"Ensure file encoding is correct",
+				"Rewrite the condition with an operator the target platform supports",
 			},
 		}
 	}
 
-	// Basic validation using the common validator
-	if err := s.performValidation(unifiedDSL); err != nil {
-		return nil, err // Already a typed error
+	warnings = append(append(append(append(subgraphWarnings, triggerWarnings...), iterationWarnings...), expressionWarnings...), warnings...)
+
+	// Report suggested questions the target has no room for
+	warnings = append(warnings, common.CheckSuggestedQuestions(unifiedDSL, targetPlatform)...)
+
+	// Report iteration settings the target runs differently
+	warnings = append(warnings, common.CheckIterationExecution(unifiedDSL, targetPlatform)...)
+
+	// Report node error handling the target drops
+	warnings = append(warnings, common.CheckErrorHandling(unifiedDSL, targetPlatform)...)
+
+	// Report conversation history the target drops or shortens
+	warnings = append(warnings, common.CheckMemory(unifiedDSL, targetPlatform)...)
+
+	// Report start input defaults the target drops
+	warnings = append(warnings, common.CheckStartDefaults(unifiedDSL, targetPlatform)...)
+
+	// Report outputs whose type the target lacks
+	warnings = append(warnings, common.CheckDataTypes(unifiedDSL, targetPlatform)...)
+
+	// Report where the default intents the target requires are connected
+	var defaultIntent string
+	if options != nil {
+		defaultIntent = options.DefaultIntent
+	}
+	warnings = append(warnings, common.CheckDefaultIntents(unifiedDSL, targetPlatform, defaultIntent)...)
+
+	// Map model names
+	if options != nil {
+		common.ApplyModelMap(unifiedDSL, options.ModelMap)
+	}
+
+	// Report image inputs the target models cannot see, after model names are final
+	warnings = append(warnings, common.CheckVision(unifiedDSL, targetPlatform)...)
+
+	// Fit sampling parameters into the target ranges
+	warnings = append(warnings, common.NormalizeSampling(unifiedDSL, targetPlatform)...)
+
+	// Translate the workflow icon to a form the target accepts
+	var iconSet *models.IconSet
+	if options != nil {
+		iconSet = options.Icons
+	}
+	warnings = append(warnings, common.TranslateWorkflowIcon(unifiedDSL, targetPlatform, common.IconTranslatorFor(iconSet))...)
+
+	// Release the export under the next workflow version
+	if options != nil && options.BumpVersion != "" {
+		version, err := common.BumpVersion(unifiedDSL.Metadata.Version, options.BumpVersion)
+		if err != nil {
+			return nil, &models.ConversionError{
+				Code:           "INVALID_OPTIONS",
+				Message:        "Cannot bump the workflow version",
+				SourcePlatform: string(sourcePlatform),
+				TargetPlatform: string(targetPlatform),
+				ErrorType:      "options_error",
+				Details:        err.Error(),
+				Severity:       models.SeverityError,
+				Suggestions: []string{
+					"Give the workflow a semantic version such as 1.2.0, or drop --bump-version",
+				},
+			}
+		}
+		unifiedDSL.Metadata.Version = version
+	}
+	if options != nil && options.Touch {
+		unifiedDSL.UpdateTimestamp()
+	}
+
+	// Apply node naming options
+	if err := common.ApplyTitleOptions(unifiedDSL, options); err != nil {
+		return nil, &models.ConversionError{
+			Code:           "INVALID_OPTIONS",
+			Message:        "Invalid node naming options",
+			SourcePlatform: string(sourcePlatform),
+			TargetPlatform: string(targetPlatform),
+			ErrorType:      "options_error",
+			Details:        err.Error(),
+			Severity:       models.SeverityError,
+			Suggestions: []string{
+				"Use either --keep-titles or --title-prefix/--title-suffix/--title-template",
+			},
+		}
+	}
+
+	// Let integrators edit the nodes as they will be generated
+	if err := s.applyNodeHooks(unifiedDSL, common.HookPhaseAfter, sourcePlatform, targetPlatform, options); err != nil {
+		return nil, err
+	}
+
+	// Enforce governance rules on the nodes as they will be generated
+	if options != nil {
+		policyWarnings, err := common.EnforcePolicy(unifiedDSL, options.Policy)
+		warnings = append(warnings, policyWarnings...)
+		if err != nil {
+			return nil, &models.ConversionError{
+				Code:           "POLICY_VIOLATION",
+				Message:        fmt.Sprintf("Conversion blocked by policy: %v", err),
+				SourcePlatform: string(sourcePlatform),
+				TargetPlatform: string(targetPlatform),
+				ErrorType:      "policy_violation",
+				Details:        err.Error(),
+				Severity:       models.SeverityError,
+				Suggestions: []string{
+					"Fix the listed nodes in the source workflow",
+					"Change the rule action to warn or rewrite if the violation is acceptable",
+				},
+			}
+		}
+	}
+
+	// Custom validators see the nodes as they will be generated
+	ruleWarnings, err := runCustomValidators(unifiedDSL, models.ValidationStagePlatform, sourcePlatform, targetPlatform, options)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, ruleWarnings...)
+
+	if err := checkCancelled(ctx, "preprocessing"); err != nil {
+		return nil, err
+	}
+	if err := debugArtifacts(options).SaveYAML(common.ArtifactUnifiedFinal, unifiedDSL); err != nil {
+		warnings = append(warnings, err.Error())
 	}
 
 	// Get target platform generator
@@ -94,8 +515,29 @@ func (s *ConversionService) ConvertWithContext(
 		}
 	}
 
+	// Apply conversion options to generators that support them
+	if err := s.configureGenerator(generator, options); err != nil {
+		return nil, &models.ConversionError{
+			Code:           "INVALID_OPTIONS",
+			Message:        "Invalid conversion options",
+			SourcePlatform: string(sourcePlatform),
+			TargetPlatform: string(targetPlatform),
+			ErrorType:      "options_error",
+			Details:        err.Error(),
+			Severity:       models.SeverityError,
+			Suggestions: []string{
+				"Check the --target-version value against the versions supported by the target platform",
+				"Use --output-format yaml for targets that only import YAML",
+			},
+		}
+	}
+
 	// Generate target platform DSL
-	targetData, err := generator.Generate(unifiedDSL)
+	models.ReportStage(ctx, models.ProgressStageGenerate)
+	targetData, idMapping, err := s.generate(ctx, generator, unifiedDSL)
+	if cancelled := checkCancelled(ctx, "generation"); cancelled != nil {
+		return nil, cancelled
+	}
 	if err != nil {
 		return nil, &models.ConversionError{
 			Code:           "GENERATION_FAILED",
@@ -113,7 +555,288 @@ func (s *ConversionService) ConvertWithContext(
 		}
 	}
 
-	return targetData, nil
+	// Shrink the output after generation so ID mappings describe the same document
+	var minifyReport *common.MinifyReport
+	if options != nil && options.Minify {
+		targetData, minifyReport, err = common.MinifyOutput(targetData, targetPlatform, options.OutputFormat)
+		if err != nil {
+			return nil, &models.ConversionError{
+				Code:           "MINIFY_FAILED",
+				Message:        "Failed to minify target DSL",
+				SourcePlatform: string(sourcePlatform),
+				TargetPlatform: string(targetPlatform),
+				ErrorType:      "generation_error",
+				Details:        err.Error(),
+				Severity:       models.SeverityError,
+				Suggestions: []string{
+					"Convert without --minify",
+				},
+			}
+		}
+	}
+
+	result := &ConversionResult{
+		Output:         targetData,
+		MinifyReport:   minifyReport,
+		SourcePlatform: sourcePlatform,
+		TargetPlatform: targetPlatform,
+		Warnings:       warnings,
+		NodeTypes:      nodeTypes,
+		Placeholders:   placeholders,
+	}
+	if idMapping != nil {
+		result.NodeMapping = common.CopyIDMapping(idMapping.Nodes)
+		result.IDMapping = idMapping
+		result.IDMapping.SourcePlatform = sourcePlatform
+		result.IDMapping.TargetPlatform = targetPlatform
+	}
+
+	models.ReportStage(ctx, models.ProgressStageDone)
+	return result, nil
+}
+
+// countNodeTypes adds the nodes, including those of iteration sub-workflows, to counts by type.
+func countNodeTypes(nodes []models.Node, counts map[models.NodeType]int) map[models.NodeType]int {
+	for _, node := range nodes {
+		counts[node.Type]++
+		if iterConfig, ok := common.AsIterationConfig(node.Config); ok && iterConfig != nil {
+			countNodeTypes(iterConfig.SubWorkflow.Nodes, counts)
+		}
+	}
+	return counts
+}
+
+// applyNodeHooks runs the node hooks of the conversion options for a phase.
+func (s *ConversionService) applyNodeHooks(
+	unifiedDSL *models.UnifiedDSL,
+	phase string,
+	sourcePlatform, targetPlatform models.PlatformType,
+	options *models.ConversionOptions,
+) error {
+	if options == nil {
+		return nil
+	}
+	ctx := models.NodeHookContext{SourcePlatform: sourcePlatform, TargetPlatform: targetPlatform}
+	if err := common.ApplyNodeHooks(unifiedDSL, options.NodeHooks, phase, ctx); err != nil {
+		return &models.ConversionError{
+			Code:           "NODE_HOOK_FAILED",
+			Message:        fmt.Sprintf("Node hook failed in %s phase", phase),
+			SourcePlatform: string(sourcePlatform),
+			TargetPlatform: string(targetPlatform),
+			ErrorType:      "hook_error",
+			Details:        err.Error(),
+			Severity:       models.SeverityError,
+		}
+	}
+	return nil
+}
+
+// parseSource checks platform support and options, then parses and validates the source DSL. It
+// returns the source problems the parser worked around in permissive parse mode.
+func (s *ConversionService) parseSource(
+	ctx context.Context,
+	sourceData []byte,
+	sourcePlatform, targetPlatform models.PlatformType,
+	options *models.ConversionOptions,
+) (*models.UnifiedDSL, []string, error) {
+	return s.parseSourceWith(ctx, sourceData, sourcePlatform, targetPlatform, options, nil)
+}
+
+// parseSourceWith is parseSource running prepare, when given, on the parsed DSL before it is
+// validated. The warnings of prepare are returned with the parse issues.
+func (s *ConversionService) parseSourceWith(
+	ctx context.Context,
+	sourceData []byte,
+	sourcePlatform, targetPlatform models.PlatformType,
+	options *models.ConversionOptions,
+	prepare func(*models.UnifiedDSL) ([]string, error),
+) (*models.UnifiedDSL, []string, error) {
+	// Check platform support
+	if err := s.validatePlatformSupport(sourcePlatform, targetPlatform); err != nil {
+		return nil, nil, &models.ConversionError{
+			Code:           "PLATFORM_NOT_SUPPORTED",
+			Message:        "Platform validation failed",
+			SourcePlatform: string(sourcePlatform),
+			TargetPlatform: string(targetPlatform),
+			ErrorType:      "platform_support",
+			Details:        err.Error(),
+			Severity:       models.SeverityCritical,
+		}
+	}
+
+	// Reject invalid options before doing any work
+	if options != nil {
+		err := options.Validate()
+		if err == nil && options.PreviousMapping != nil {
+			err = options.PreviousMapping.CheckPlatforms(sourcePlatform, targetPlatform)
+		}
+		if err != nil {
+			return nil, nil, &models.ConversionError{
+				Code:           "INVALID_OPTIONS",
+				Message:        "Invalid conversion options",
+				SourcePlatform: string(sourcePlatform),
+				TargetPlatform: string(targetPlatform),
+				ErrorType:      "options_error",
+				Details:        err.Error(),
+				Severity:       models.SeverityError,
+			}
+		}
+	}
+
+	// Get source platform parser
+	parser, err := s.getParser(sourcePlatform)
+	if err != nil {
+		return nil, nil, &models.ConversionError{
+			Code:           "PARSER_NOT_FOUND",
+			Message:        fmt.Sprintf("Failed to get parser for %s", sourcePlatform),
+			SourcePlatform: string(sourcePlatform),
+			TargetPlatform: string(targetPlatform),
+			ErrorType:      "parser_error",
+			Details:        err.Error(),
+			Severity:       models.SeverityCritical,
+		}
+	}
+
+	configurableParser, configurable := parser.(interfaces.ConfigurableParser)
+	if configurable && options != nil {
+		configurableParser.SetParseMode(options.ParseMode)
+	}
+	if archiveParser, ok := parser.(interfaces.ArchiveParser); ok && options != nil && options.ArchiveLimits != nil {
+		archiveParser.SetArchiveLimits(*options.ArchiveLimits)
+	}
+	debug := debugArtifacts(options)
+	if debug.Enabled() {
+		if err := os.MkdirAll(debug.Dir, 0o755); err != nil {
+			return nil, nil, &models.ConversionError{
+				Code:           "INVALID_OPTIONS",
+				Message:        "Cannot create the debug directory",
+				SourcePlatform: string(sourcePlatform),
+				TargetPlatform: string(targetPlatform),
+				ErrorType:      "options_error",
+				Details:        err.Error(),
+				Severity:       models.SeverityError,
+			}
+		}
+		if debugParser, ok := parser.(interfaces.DebugParser); ok {
+			debugParser.SetDebugDir(debug.Dir)
+		}
+	}
+
+	// Parse source DSL to unified format
+	models.ReportStage(ctx, models.ProgressStageParse)
+	var unifiedDSL *models.UnifiedDSL
+	if contextParser, ok := parser.(interfaces.ContextParser); ok {
+		unifiedDSL, err = contextParser.ParseWithContext(ctx, sourceData)
+	} else {
+		unifiedDSL, err = parser.Parse(sourceData)
+	}
+	if cancelled := checkCancelled(ctx, "parsing"); cancelled != nil {
+		return nil, nil, cancelled
+	}
+	if err != nil {
+		suggestions := []string{
+			"Check DSL format and syntax",
+			"Verify all required fields are present",
+			"Ensure file encoding is correct",
+		}
+		if options != nil && options.ParseMode == models.ParseModeStrict {
+			suggestions = append(suggestions, "Use permissive parse mode to convert best-effort with warnings")
+		}
+		if errors.Is(err, common.ErrDamagedExport) && (options == nil || options.ParseMode != models.ParseModeLenient) {
+			suggestions = append(suggestions, "Use lenient parse mode to salvage what the damaged export still holds")
+		}
+		return nil, nil, &models.ParseError{
+			Code:        "PARSE_FAILED",
+			Message:     fmt.Sprintf("Failed to parse source DSL: %v", err),
+			Suggestions: suggestions,
+		}
+	}
+
+	var prepared []string
+	if prepare != nil {
+		if prepared, err = prepare(unifiedDSL); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Basic validation using the common validator
+	if err := s.performValidation(unifiedDSL); err != nil {
+		return nil, nil, err // Already a typed error
+	}
+
+	var issues []string
+	if configurable {
+		issues = configurableParser.Issues()
+	}
+	issues = append(issues, prepared...)
+	for _, stage := range []models.ValidationStage{models.ValidationStageStructure, models.ValidationStageSemantic} {
+		ruleWarnings, err := runCustomValidators(unifiedDSL, stage, sourcePlatform, targetPlatform, options)
+		if err != nil {
+			return nil, nil, err
+		}
+		issues = append(issues, ruleWarnings...)
+	}
+	if err := debug.SaveYAML(common.ArtifactUnifiedParsed, unifiedDSL); err != nil {
+		issues = append(issues, err.Error())
+	}
+	return unifiedDSL, issues, nil
+}
+
+// generate runs the generator and returns the ID mapping of this generation when the generator reports one.
+func (s *ConversionService) generate(ctx context.Context, generator interfaces.DSLGenerator, unifiedDSL *models.UnifiedDSL) ([]byte, *models.IDMapping, error) {
+	if contextGenerator, ok := generator.(interfaces.ContextGenerator); ok {
+		return contextGenerator.GenerateWithContext(ctx, unifiedDSL)
+	}
+	if mappingGenerator, ok := generator.(interfaces.MappingGenerator); ok {
+		return mappingGenerator.GenerateWithMapping(unifiedDSL)
+	}
+
+	data, err := generator.Generate(unifiedDSL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mappingProvider, ok := generator.(interfaces.MappingProvider); ok {
+		return data, mappingProvider.GetIDMapping(), nil
+	}
+	return data, nil, nil
+}
+
+// progressContext passes the progress reporter of the conversion options to the stages of the conversion.
+func progressContext(ctx context.Context, options *models.ConversionOptions) context.Context {
+	if options == nil {
+		return ctx
+	}
+	return models.WithProgressReporter(ctx, options.Progress)
+}
+
+// debugArtifacts returns where the conversion saves intermediate results, if anywhere.
+func debugArtifacts(options *models.ConversionOptions) common.DebugArtifacts {
+	if options == nil {
+		return common.DebugArtifacts{}
+	}
+	return common.DebugArtifacts{Dir: options.DebugDir}
+}
+
+// checkCancelled returns ctx.Err(), naming the stage the conversion stopped in, once ctx is done.
+func checkCancelled(ctx context.Context, stage string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("conversion cancelled during %s: %w", stage, err)
+	}
+	return nil
+}
+
+// configureGenerator applies conversion options when the generator supports them.
+func (s *ConversionService) configureGenerator(generator interfaces.DSLGenerator, options *models.ConversionOptions) error {
+	if options == nil {
+		return nil
+	}
+
+	configurable, ok := generator.(interfaces.ConfigurableGenerator)
+	if !ok {
+		return nil
+	}
+
+	return configurable.Configure(options)
 }
 
 // validatePlatformSupport checks if the source and target platforms are supported.
@@ -174,6 +897,30 @@ func (s *ConversionService) performValidation(unifiedDSL *models.UnifiedDSL) err
 	return nil
 }
 
+// runCustomValidators runs the validators of the conversion options for a stage.
+func runCustomValidators(
+	unifiedDSL *models.UnifiedDSL,
+	stage models.ValidationStage,
+	sourcePlatform, targetPlatform models.PlatformType,
+	options *models.ConversionOptions,
+) ([]string, error) {
+	if options == nil {
+		return nil, nil
+	}
+	ctx := models.ValidationContext{Stage: stage, SourcePlatform: sourcePlatform, TargetPlatform: targetPlatform}
+	warnings, err := common.RunValidators(unifiedDSL, options.Validators, ctx)
+	if err != nil {
+		return warnings, &models.ValidationError{
+			Type:           string(stage),
+			Severity:       "error",
+			Message:        fmt.Sprintf("Custom validation failed: %v", err),
+			AffectedItems:  []string{err.Error()},
+			FixSuggestions: []string{"Fix the listed nodes in the source workflow", "Set the rule severity to warning if the issue is acceptable"},
+		}
+	}
+	return warnings, nil
+}
+
 // ValidateSourceData validates input data against source platform requirements.
 func (s *ConversionService) ValidateSourceData(
 	data []byte,
@@ -187,6 +934,33 @@ func (s *ConversionService) ValidateSourceData(
 	return parser.Validate(data)
 }
 
+// ValidateWithRules parses data and runs the structure and semantic stages of the validation
+// pipeline: the built-in checks, then validators. It returns the warnings of parsing and of the
+// validators; platform stage validators need a target and only run in conversions.
+func (s *ConversionService) ValidateWithRules(
+	data []byte,
+	platform models.PlatformType,
+	validators []models.Validator,
+) ([]string, error) {
+	options := models.NewConversionOptions()
+	options.Validators = validators
+	_, warnings, err := s.parseSource(context.Background(), data, platform, platform, options)
+	return warnings, err
+}
+
+// Parse reads a DSL of the given platform into the unified DSL without converting it.
+func (s *ConversionService) Parse(
+	data []byte,
+	platform models.PlatformType,
+) (*models.UnifiedDSL, error) {
+	parser, err := s.getParser(platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parser: %w", err)
+	}
+
+	return parser.Parse(data)
+}
+
 // ValidateTargetCompatibility verifies unified DSL compatibility with target platform.
 func (s *ConversionService) ValidateTargetCompatibility(
 	unifiedDSL *models.UnifiedDSL,
@@ -200,6 +974,13 @@ func (s *ConversionService) ValidateTargetCompatibility(
 	return generator.Validate(unifiedDSL)
 }
 
+// SupportedPlatforms returns the platforms with a registered strategy, sorted by name.
+func (s *ConversionService) SupportedPlatforms() []models.PlatformType {
+	platforms := s.strategyRegistry.GetSupportedPlatforms()
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i] < platforms[j] })
+	return platforms
+}
+
 func (s *ConversionService) getParser(platform models.PlatformType) (interfaces.DSLParser, error) {
 	strategy, err := s.strategyRegistry.GetStrategy(platform)
 	if err != nil {
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// NodeConversionResult holds one converted node with its conversion details.
+type NodeConversionResult struct {
+	Output         []byte // The node as the target platform keeps it in its exports
+	SourceNodeID   string
+	TargetNodeID   string
+	SourcePlatform models.PlatformType
+	TargetPlatform models.PlatformType
+	Warnings       []string // Start variables replacing the outputs the node reads, and conversion warnings
+}
+
+// ConvertNode converts a single node, given as YAML or JSON in the form the source platform keeps
+// nodes in its exports, without the workflow around it. The node is converted as the only node
+// between a start and an end node: outputs of other nodes it reads become start variables, each
+// reported in the warnings; strict parse mode rejects such references as dangling. Iterations
+// convert without the body nodes kept beside them. Subgraph options are ignored, and the output
+// is YAML unless options ask for JSON.
+func (s *ConversionService) ConvertNode(
+	ctx context.Context,
+	nodeData []byte,
+	sourcePlatform, targetPlatform models.PlatformType,
+	options *models.ConversionOptions,
+) (*NodeConversionResult, error) {
+	sourceData, sourceNodeID, err := common.WrapNode(nodeData, sourcePlatform)
+	if err != nil {
+		return nil, &models.ParseError{
+			Code:        "PARSE_FAILED",
+			Message:     fmt.Sprintf("Failed to read source node: %v", err),
+			Suggestions: []string{"Paste a single node as the source platform exports it, with its id"},
+		}
+	}
+	if options != nil && (len(options.IncludeNodes) > 0 || options.SubgraphFrom != "") {
+		copied := *options
+		copied.IncludeNodes, copied.SubgraphFrom = nil, ""
+		options = &copied
+	}
+
+	ctx = progressContext(ctx, options)
+	unifiedDSL, warnings, err := s.parseSourceWith(ctx, sourceData, sourcePlatform, targetPlatform, options,
+		func(unifiedDSL *models.UnifiedDSL) ([]string, error) {
+			isolated, err := common.IsolateNode(unifiedDSL, sourceNodeID)
+			if err != nil {
+				return nil, &models.ConversionError{
+					Code:           "NODE_NOT_FOUND",
+					Message:        fmt.Sprintf("Failed to convert node %s", sourceNodeID),
+					SourcePlatform: string(sourcePlatform),
+					TargetPlatform: string(targetPlatform),
+					ErrorType:      "parser_error",
+					Details:        err.Error(),
+					Severity:       models.SeverityError,
+				}
+			}
+			return isolated, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.convertUnified(ctx, unifiedDSL, sourcePlatform, targetPlatform, options)
+	if err != nil {
+		return nil, err
+	}
+
+	targetNodeID := sourceNodeID
+	if mapped, ok := result.NodeMapping[sourceNodeID]; ok && mapped != "" {
+		targetNodeID = mapped
+	}
+	format := models.OutputFormatYAML
+	if options != nil && options.OutputFormat != "" {
+		format = options.OutputFormat
+	}
+	output, err := common.ExtractNode(result.Output, targetNodeID, format)
+	if err != nil {
+		return nil, &models.ConversionError{
+			Code:           "GENERATION_FAILED",
+			Message:        "Failed to extract the converted node",
+			SourcePlatform: string(sourcePlatform),
+			TargetPlatform: string(targetPlatform),
+			ErrorType:      "generation_error",
+			Details:        err.Error(),
+			Severity:       models.SeverityError,
+		}
+	}
+
+	return &NodeConversionResult{
+		Output:         output,
+		SourceNodeID:   sourceNodeID,
+		TargetNodeID:   targetNodeID,
+		SourcePlatform: sourcePlatform,
+		TargetPlatform: targetPlatform,
+		Warnings:       append(warnings, result.Warnings...),
+	}, nil
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// SSEProgressReporter writes progress events as server-sent events, one "progress" event with a
+// JSON ProgressEvent per report, so an HTTP handler can stream a conversion to a browser. Writers
+// with a Flush method, such as http.ResponseWriter, are flushed after every event.
+type SSEProgressReporter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+// NewSSEProgressReporter creates a reporter streaming to w.
+func NewSSEProgressReporter(w io.Writer) *SSEProgressReporter {
+	return &SSEProgressReporter{w: w}
+}
+
+// ReportProgress writes the event. After a failed write, for example once the client went away,
+// further events are dropped; Err returns the failure.
+func (r *SSEProgressReporter) ReportProgress(event models.ProgressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		r.err = fmt.Errorf("failed to encode progress event: %w", err)
+		return
+	}
+	if _, err := fmt.Fprintf(r.w, "event: progress\ndata: %s\n\n", data); err != nil {
+		r.err = fmt.Errorf("failed to write progress event: %w", err)
+		return
+	}
+	if flusher, ok := r.w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+}
+
+// Err returns the first error writing events, if any.
+func (r *SSEProgressReporter) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
@@ -0,0 +1,86 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Client calls the JSON API of a platform below a base URL.
+type Client struct {
+	http          *http.Client
+	baseURL       string
+	authorization string
+}
+
+// NewClient returns a client sending authorization with every request; a nil httpClient uses
+// http.DefaultClient.
+func NewClient(httpClient *http.Client, baseURL, authorization string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{http: httpClient, baseURL: strings.TrimSuffix(baseURL, "/"), authorization: authorization}
+}
+
+// Do sends body as JSON and decodes the response into out. Responses outside 2xx are returned
+// as errors carrying the message of the platform.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	request.Header.Set("Authorization", c.authorization)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response of %s: %w", path, err)
+	}
+
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s: %s: %s", method, path, response.Status, responseMessage(data))
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("unexpected response of %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// responseMessage extracts the error message of an error response body
+func responseMessage(data []byte) string {
+	var body map[string]interface{}
+	if json.Unmarshal(data, &body) == nil {
+		for _, key := range []string{"message", "msg", "error"} {
+			if message, ok := body[key].(string); ok && message != "" {
+				return message
+			}
+		}
+	}
+	message := strings.TrimSpace(string(data))
+	if len(message) > 200 {
+		message = message[:200] + "..."
+	}
+	return message
+}
@@ -0,0 +1,26 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+)
+
+// cozeAdapter stands for Coze accounts. The Coze OpenAPI runs published workflows but cannot
+// export or import them.
+type cozeAdapter struct {
+	account Account
+}
+
+// Pull reports the manual export of Coze workflows.
+func (a *cozeAdapter) Pull(ctx context.Context, appID string, includeSecrets bool) ([]byte, error) {
+	return nil, fmt.Errorf("%w: Coze has no workflow export API, export workflow %s as ZIP in the Coze editor", ErrUnsupported, appID)
+}
+
+// Push reports the manual import of Coze workflows.
+func (a *cozeAdapter) Push(ctx context.Context, dsl []byte, options PushOptions) (*PushResult, error) {
+	space := a.account.Space
+	if space == "" {
+		space = "the target space"
+	}
+	return nil, fmt.Errorf("%w: Coze has no workflow import API, import the file into %s in the Coze editor", ErrUnsupported, space)
+}
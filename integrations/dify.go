@@ -0,0 +1,95 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Dify import statuses; pending imports wait for a DSL version confirmation
+const (
+	difyImportPending      = "pending"
+	difyImportFailed       = "failed"
+	difyImportWithWarnings = "completed-with-warnings"
+)
+
+type difyImport struct {
+	ID                 string `json:"id"`
+	Status             string `json:"status"`
+	AppID              string `json:"app_id"`
+	Error              string `json:"error"`
+	CurrentDSLVersion  string `json:"current_dsl_version"`
+	ImportedDSLVersion string `json:"imported_dsl_version"`
+}
+
+// difyAdapter exports and imports apps with the console API of a Dify instance.
+type difyAdapter struct {
+	account Account
+}
+
+func (a *difyAdapter) console() *Client {
+	return NewClient(a.account.Client, a.account.Endpoint+"/console/api", "Bearer "+a.account.APIKey)
+}
+
+// Pull exports the DSL of an app.
+func (a *difyAdapter) Pull(ctx context.Context, appID string, includeSecrets bool) ([]byte, error) {
+	if appID == "" {
+		return nil, fmt.Errorf("the ID of the Dify app to pull is required")
+	}
+	var exported struct {
+		Data string `json:"data"`
+	}
+	path := fmt.Sprintf("/apps/%s/export?include_secret=%t", url.PathEscape(appID), includeSecrets)
+	if err := a.console().Do(ctx, http.MethodGet, path, nil, &exported); err != nil {
+		return nil, fmt.Errorf("failed to export Dify app %s: %w", appID, err)
+	}
+	if exported.Data == "" {
+		return nil, fmt.Errorf("Dify returned no DSL for app %s", appID)
+	}
+	return []byte(exported.Data), nil
+}
+
+// Push imports dsl as a new app, or over options.AppID, in the account workspace. Imports of
+// other DSL versions are confirmed, as the Dify console asks the user to.
+func (a *difyAdapter) Push(ctx context.Context, dsl []byte, options PushOptions) (*PushResult, error) {
+	console := a.console()
+	if a.account.Space != "" {
+		err := console.Do(ctx, http.MethodPost, "/workspaces/switch", map[string]interface{}{"tenant_id": a.account.Space}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to switch to Dify workspace %s: %w", a.account.Space, err)
+		}
+	}
+
+	request := map[string]interface{}{
+		"mode":         "yaml-content",
+		"yaml_content": string(dsl),
+	}
+	if options.AppID != "" {
+		request["app_id"] = options.AppID
+	}
+	var imported difyImport
+	err := console.Do(ctx, http.MethodPost, "/apps/imports", request, &imported)
+	var warning string
+	if err == nil && imported.Status == difyImportPending {
+		warning = fmt.Sprintf("DSL version %s imported into Dify %s", imported.ImportedDSLVersion, imported.CurrentDSLVersion)
+		err = console.Do(ctx, http.MethodPost, "/apps/imports/"+url.PathEscape(imported.ID)+"/confirm", nil, &imported)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to import into Dify: %w", err)
+	}
+	if imported.Status == difyImportFailed || imported.AppID == "" {
+		return nil, fmt.Errorf("Dify rejected the import: %s", imported.Error)
+	}
+	if imported.Status == difyImportWithWarnings && warning == "" {
+		warning = imported.Error
+	}
+
+	return &PushResult{AppID: imported.AppID, Updated: options.AppID != "", Warning: warning}, nil
+}
+
+// DeleteDifyApp deletes a Dify app, such as one pushed for a test.
+func DeleteDifyApp(ctx context.Context, account Account, appID string) error {
+	adapter := &difyAdapter{account: account}
+	return adapter.console().Do(ctx, http.MethodDelete, "/apps/"+url.PathEscape(appID), nil, nil)
+}
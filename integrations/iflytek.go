@@ -0,0 +1,21 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+)
+
+// iflytekAdapter stands for iFlytek Spark accounts, whose API serves published agents only.
+type iflytekAdapter struct {
+	account Account
+}
+
+// Pull reports the manual export of Spark agents.
+func (a *iflytekAdapter) Pull(ctx context.Context, appID string, includeSecrets bool) ([]byte, error) {
+	return nil, fmt.Errorf("%w: iFlytek Spark has no workflow export API, export agent %s in the agent console", ErrUnsupported, appID)
+}
+
+// Push reports the manual import of Spark agents.
+func (a *iflytekAdapter) Push(ctx context.Context, dsl []byte, options PushOptions) (*PushResult, error) {
+	return nil, fmt.Errorf("%w: iFlytek Spark has no workflow import API, import the file in the agent console", ErrUnsupported)
+}
@@ -0,0 +1,108 @@
+// Package integrations fetches and publishes workflow DSL through the management APIs of
+// platform accounts, so that workflows move between accounts without manual export and import.
+//
+// Every platform has an adapter. Dify exports and imports apps with its console API. Coze and
+// iFlytek Spark publish no API to export or import workflows; their adapters return
+// ErrUnsupported with the manual step instead.
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// ErrUnsupported is wrapped by errors of operations the platform API does not offer
+var ErrUnsupported = errors.New("not supported by the platform API")
+
+// Account is a platform account reached through its management API.
+type Account struct {
+	Platform models.PlatformType
+	Endpoint string // Base URL of the instance, required for Dify
+	APIKey   string // Dify console access token, Coze personal access token
+	Space    string // Workspace pushed workflows go to; empty keeps the current workspace
+	Client   *http.Client
+}
+
+// PushOptions controls how a workflow is published.
+type PushOptions struct {
+	AppID string // App to overwrite; empty creates a new app
+}
+
+// PushResult describes a published workflow.
+type PushResult struct {
+	AppID   string
+	Updated bool   // An existing app was overwritten
+	Warning string // Import notes of the platform, such as a DSL version mismatch
+}
+
+// Adapter fetches and publishes the workflows of one platform account.
+type Adapter interface {
+	// Pull returns the DSL of an app, without secrets unless includeSecrets is set
+	Pull(ctx context.Context, appID string, includeSecrets bool) ([]byte, error)
+	// Push imports dsl into the account
+	Push(ctx context.Context, dsl []byte, options PushOptions) (*PushResult, error)
+}
+
+// NewAdapter returns the adapter of the account platform.
+func NewAdapter(account Account) (Adapter, error) {
+	switch account.Platform {
+	case models.PlatformDify:
+		if account.Endpoint == "" {
+			return nil, fmt.Errorf("Dify needs the endpoint of its instance (flag --endpoint or env %s)", EnvEndpoint(account.Platform))
+		}
+		if account.APIKey == "" {
+			return nil, fmt.Errorf("a console access token is required to reach Dify (flag --api-key or env %s)", EnvAPIKey(account.Platform))
+		}
+		return &difyAdapter{account: account}, nil
+	case models.PlatformCoze:
+		return &cozeAdapter{account: account}, nil
+	case models.PlatformIFlytek:
+		return &iflytekAdapter{account: account}, nil
+	default:
+		return nil, fmt.Errorf("platform accounts are not supported on %s", account.Platform)
+	}
+}
+
+// EnvAPIKey returns the environment variable of the API key of platform accounts,
+// e.g. AGENTBRIDGE_DIFY_API_KEY.
+func EnvAPIKey(platform models.PlatformType) string {
+	return envName(platform, "API_KEY")
+}
+
+// EnvEndpoint returns the environment variable of the endpoint of platform accounts.
+func EnvEndpoint(platform models.PlatformType) string {
+	return envName(platform, "ENDPOINT")
+}
+
+// EnvSpace returns the environment variable of the workspace of platform accounts.
+func EnvSpace(platform models.PlatformType) string {
+	return envName(platform, "SPACE")
+}
+
+func envName(platform models.PlatformType, field string) string {
+	return "AGENTBRIDGE_" + strings.ToUpper(string(platform)) + "_" + field
+}
+
+// WithEnvironment fills the unset fields of account from the environment, then from configured,
+// the account of the config file.
+func (a Account) WithEnvironment(configured Account) Account {
+	a.Endpoint = firstNonEmpty(a.Endpoint, os.Getenv(EnvEndpoint(a.Platform)), configured.Endpoint)
+	a.APIKey = firstNonEmpty(a.APIKey, os.Getenv(EnvAPIKey(a.Platform)), configured.APIKey)
+	a.Space = firstNonEmpty(a.Space, os.Getenv(EnvSpace(a.Platform)), configured.Space)
+	return a
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
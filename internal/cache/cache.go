@@ -0,0 +1,131 @@
+// Package cache stores conversion results in a local directory, so repeated batch runs skip
+// files that have not changed since they were last converted.
+//
+// Entries are keyed by a hash of everything that shapes the output: the source, the platforms,
+// the tool version and a fingerprint of the conversion options. A changed input of any kind is a
+// different key, so entries never go stale; they only pile up until the directory is removed.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// EnvCacheDir enables the conversion cache and names its directory
+const EnvCacheDir = "AGENTBRIDGE_CACHE_DIR"
+
+// entryFormatVersion is part of every key, so a new entry layout misses old entries
+const entryFormatVersion = "1"
+
+// Cache is a directory of conversion results.
+type Cache struct {
+	Dir string
+}
+
+// New returns the cache in dir.
+func New(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+// entry is the stored form of a conversion result
+type entry struct {
+	Output         []byte                  `json:"output"`
+	SourcePlatform models.PlatformType     `json:"source_platform"`
+	TargetPlatform models.PlatformType     `json:"target_platform"`
+	NodeMapping    map[string]string       `json:"node_mapping,omitempty"`
+	IDMapping      *models.IDMapping       `json:"id_mapping,omitempty"`
+	Warnings       []string                `json:"warnings,omitempty"`
+	NodeTypes      map[models.NodeType]int `json:"node_types,omitempty"`
+	Placeholders   int                     `json:"placeholders,omitempty"`
+}
+
+// Key returns the key of a conversion. fingerprint holds everything besides the source that
+// shapes the output, such as encoded options and the contents of rule files.
+func Key(source []byte, from, to models.PlatformType, version string, fingerprint ...[]byte) string {
+	hash := sha256.New()
+	parts := append([][]byte{[]byte(entryFormatVersion), source, []byte(from), []byte(to), []byte(version)}, fingerprint...)
+	for _, part := range parts {
+		// Length prefixes keep the parts apart, so "ab"+"c" and "a"+"bc" differ
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(part)))
+		hash.Write(length[:])
+		hash.Write(part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// path spreads entries over subdirectories named after the first key characters
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key+".json")
+}
+
+// Get returns the cached result of key. Missing and unreadable entries are misses.
+func (c *Cache) Get(key string) (*services.ConversionResult, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var cached entry
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, false
+	}
+	return &services.ConversionResult{
+		Output:         cached.Output,
+		SourcePlatform: cached.SourcePlatform,
+		TargetPlatform: cached.TargetPlatform,
+		NodeMapping:    cached.NodeMapping,
+		IDMapping:      cached.IDMapping,
+		Warnings:       cached.Warnings,
+		NodeTypes:      cached.NodeTypes,
+		Placeholders:   cached.Placeholders,
+	}, true
+}
+
+// Put stores the result of key. The entry is written to a temporary file first, so concurrent
+// workers and interrupted runs never leave a partial entry behind.
+func (c *Cache) Put(key string, result *services.ConversionResult) error {
+	data, err := json.Marshal(entry{
+		Output:         result.Output,
+		SourcePlatform: result.SourcePlatform,
+		TargetPlatform: result.TargetPlatform,
+		NodeMapping:    result.NodeMapping,
+		IDMapping:      result.IDMapping,
+		Warnings:       result.Warnings,
+		NodeTypes:      result.NodeTypes,
+		Placeholders:   result.Placeholders,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+// Package config loads AgentBridge defaults from a YAML configuration file with named profiles.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigPath overrides the default configuration file location
+const EnvConfigPath = "AGENTBRIDGE_CONFIG"
+
+// DefaultFileName is the configuration file name looked up in the home directory
+const DefaultFileName = ".agentbridge.yaml"
+
+// File represents the configuration file layout.
+//
+//	default_profile: dev
+//	defaults:
+//	  verbose: true
+//	profiles:
+//	  prod:
+//	    iflytek:
+//	      app_id: 12a0a7e2
+//	    accounts:
+//	      dify:
+//	        endpoint: https://dify.example.com
+type File struct {
+	DefaultProfile string             `yaml:"default_profile"`
+	Defaults       Profile            `yaml:"defaults"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// Profile holds default values for command flags. Unset fields keep the built-in defaults.
+type Profile struct {
+	Verbose   *bool `yaml:"verbose,omitempty"`
+	Quiet     *bool `yaml:"quiet,omitempty"`
+	Workers   int   `yaml:"workers,omitempty"`
+	Overwrite *bool `yaml:"overwrite,omitempty"`
+
+	TargetVersion string `yaml:"target_version,omitempty"`
+
+	KeepTitles    *bool  `yaml:"keep_titles,omitempty"`
+	TitlePrefix   string `yaml:"title_prefix,omitempty"`
+	TitleSuffix   string `yaml:"title_suffix,omitempty"`
+	TitleTemplate string `yaml:"title_template,omitempty"`
+
+	// ModelMap renames source model names on conversion, e.g. "gpt-4o: xdeepseekv3"
+	ModelMap map[string]string `yaml:"model_map,omitempty"`
+	// PlaceholderStrategy controls unsupported nodes: "placeholder" (default) or "fail"
+	PlaceholderStrategy string `yaml:"placeholder_strategy,omitempty"`
+	// AudioStrategy controls speech nodes on Dify and Coze: "placeholder" (default) or "http"
+	AudioStrategy string `yaml:"audio_strategy,omitempty"`
+	// TriggerStrategy documents schedule and event triggers on iFlytek and Dify: "comment" (default) or "placeholder"
+	TriggerStrategy string `yaml:"trigger_strategy,omitempty"`
+	// DefaultIntent connects the default intent of classifiers converted from Dify to iFlytek:
+	// "last-class" (default), "end" or "none"
+	DefaultIntent string `yaml:"default_intent,omitempty"`
+	// ParseMode controls source problems: "permissive" (default) warns, "strict" fails, "lenient" also repairs damaged exports
+	ParseMode string `yaml:"parse_mode,omitempty"`
+	// StatsFile enables local usage statistics, appended to this file (e.g. ~/.agentbridge/stats.jsonl)
+	StatsFile string `yaml:"stats_file,omitempty"`
+	// CacheDir enables the batch conversion cache in this directory
+	CacheDir string `yaml:"cache_dir,omitempty"`
+	// Icons replaces workflow icons a target cannot show, e.g. "emoji: {📚: https://.../book.png}"
+	Icons models.IconSet `yaml:"icons,omitempty"`
+
+	IFlytek IFlytekProfile `yaml:"iflytek,omitempty"`
+
+	// Accounts are the platform accounts of pull and push, keyed by platform, e.g. "dify: {endpoint: ...}"
+	Accounts map[string]AccountProfile `yaml:"accounts,omitempty"`
+
+	// Lint disables rules of the lint command or changes their severity
+	Lint models.LintConfig `yaml:"lint,omitempty"`
+}
+
+// IFlytekProfile holds iFlytek Spark specific defaults.
+type IFlytekProfile struct {
+	AppID string `yaml:"app_id,omitempty"`
+	UID   string `yaml:"uid,omitempty"`
+}
+
+// AccountProfile holds the management API access of a platform account.
+type AccountProfile struct {
+	Endpoint string `yaml:"endpoint,omitempty"`
+	APIKey   string `yaml:"api_key,omitempty"`
+	Space    string `yaml:"space,omitempty"`
+}
+
+// DefaultPath returns the configuration file path, honoring AGENTBRIDGE_CONFIG.
+func DefaultPath() string {
+	if path := os.Getenv(EnvConfigPath); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return DefaultFileName
+	}
+	return filepath.Join(home, DefaultFileName)
+}
+
+// Load reads a configuration file. A missing file yields an empty configuration
+// unless the path was given explicitly.
+func Load(path string, explicit bool) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return &File{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	if err := validateProfile("defaults", file.Defaults); err != nil {
+		return nil, err
+	}
+	for name, profile := range file.Profiles {
+		if err := validateProfile(name, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	return &file, nil
+}
+
+// Resolve merges the defaults section with the named profile. An empty name selects
+// default_profile; requesting an unknown profile is an error.
+func (f *File) Resolve(name string) (*Profile, error) {
+	resolved := f.Defaults
+	if name == "" {
+		name = f.DefaultProfile
+	}
+	if name == "" {
+		return &resolved, nil
+	}
+
+	profile, ok := f.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("config profile %q not found (available: %s)", name, strings.Join(f.ProfileNames(), ", "))
+	}
+
+	resolved.merge(profile)
+	return &resolved, nil
+}
+
+// ProfileNames returns the sorted names of all profiles.
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge overlays the set fields of other onto p
+func (p *Profile) merge(other Profile) {
+	if other.Verbose != nil {
+		p.Verbose = other.Verbose
+	}
+	if other.Quiet != nil {
+		p.Quiet = other.Quiet
+	}
+	if other.Workers != 0 {
+		p.Workers = other.Workers
+	}
+	if other.Overwrite != nil {
+		p.Overwrite = other.Overwrite
+	}
+	if other.TargetVersion != "" {
+		p.TargetVersion = other.TargetVersion
+	}
+	if other.KeepTitles != nil {
+		p.KeepTitles = other.KeepTitles
+	}
+	if other.TitlePrefix != "" {
+		p.TitlePrefix = other.TitlePrefix
+	}
+	if other.TitleSuffix != "" {
+		p.TitleSuffix = other.TitleSuffix
+	}
+	if other.TitleTemplate != "" {
+		p.TitleTemplate = other.TitleTemplate
+	}
+	if len(other.ModelMap) > 0 {
+		merged := make(map[string]string, len(p.ModelMap)+len(other.ModelMap))
+		for k, v := range p.ModelMap {
+			merged[k] = v
+		}
+		for k, v := range other.ModelMap {
+			merged[k] = v
+		}
+		p.ModelMap = merged
+	}
+	if other.PlaceholderStrategy != "" {
+		p.PlaceholderStrategy = other.PlaceholderStrategy
+	}
+	if other.AudioStrategy != "" {
+		p.AudioStrategy = other.AudioStrategy
+	}
+	if other.TriggerStrategy != "" {
+		p.TriggerStrategy = other.TriggerStrategy
+	}
+	if other.DefaultIntent != "" {
+		p.DefaultIntent = other.DefaultIntent
+	}
+	if other.ParseMode != "" {
+		p.ParseMode = other.ParseMode
+	}
+	if other.StatsFile != "" {
+		p.StatsFile = other.StatsFile
+	}
+	if other.CacheDir != "" {
+		p.CacheDir = other.CacheDir
+	}
+	mergeIcons(&p.Icons, other.Icons)
+	if other.IFlytek.AppID != "" {
+		p.IFlytek.AppID = other.IFlytek.AppID
+	}
+	if other.IFlytek.UID != "" {
+		p.IFlytek.UID = other.IFlytek.UID
+	}
+	mergeAccounts(&p.Accounts, other.Accounts)
+	mergeLint(&p.Lint, other.Lint)
+}
+
+// mergeAccounts overlays the set fields of the accounts of other onto accounts
+func mergeAccounts(accounts *map[string]AccountProfile, other map[string]AccountProfile) {
+	if len(other) == 0 {
+		return
+	}
+	merged := make(map[string]AccountProfile, len(*accounts)+len(other))
+	for platform, account := range *accounts {
+		merged[platform] = account
+	}
+	for platform, account := range other {
+		current := merged[platform]
+		if account.Endpoint != "" {
+			current.Endpoint = account.Endpoint
+		}
+		if account.APIKey != "" {
+			current.APIKey = account.APIKey
+		}
+		if account.Space != "" {
+			current.Space = account.Space
+		}
+		merged[platform] = current
+	}
+	*accounts = merged
+}
+
+// mergeLint adds the disabled rules of other and overrides severities rule by rule
+func mergeLint(lint *models.LintConfig, other models.LintConfig) {
+	lint.Disable = append(lint.Disable, other.Disable...)
+	if len(other.Severity) == 0 {
+		return
+	}
+	severity := make(map[string]models.ErrorSeverity, len(lint.Severity)+len(other.Severity))
+	for rule, level := range lint.Severity {
+		severity[rule] = level
+	}
+	for rule, level := range other.Severity {
+		severity[rule] = level
+	}
+	lint.Severity = severity
+}
+
+// mergeIcons overlays the set fields of other onto icons
+func mergeIcons(icons *models.IconSet, other models.IconSet) {
+	if other.IFlytekDefault != "" {
+		icons.IFlytekDefault = other.IFlytekDefault
+	}
+	if other.IFlytekColor != "" {
+		icons.IFlytekColor = other.IFlytekColor
+	}
+	if other.DifyDefault != "" {
+		icons.DifyDefault = other.DifyDefault
+	}
+	if other.DifyBackground != "" {
+		icons.DifyBackground = other.DifyBackground
+	}
+	if len(other.Emoji) > 0 {
+		merged := make(map[string]string, len(icons.Emoji)+len(other.Emoji))
+		for k, v := range icons.Emoji {
+			merged[k] = v
+		}
+		for k, v := range other.Emoji {
+			merged[k] = v
+		}
+		icons.Emoji = merged
+	}
+}
+
+// validateIcons checks that icons have the form of their platform
+func validateIcons(name string, icons models.IconSet) error {
+	urls := map[string]string{"icons.iflytek_default": icons.IFlytekDefault}
+	for emoji, url := range icons.Emoji {
+		urls["icons.emoji."+emoji] = url
+	}
+	for key, url := range urls {
+		if url != "" && !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+			return fmt.Errorf("config profile %q: %s must be an image URL, got %q", name, key, url)
+		}
+	}
+	for key, color := range map[string]string{"icons.iflytek_color": icons.IFlytekColor, "icons.dify_background": icons.DifyBackground} {
+		if color != "" && !strings.HasPrefix(color, "#") {
+			return fmt.Errorf("config profile %q: %s must be a hex color like #E8F5E8, got %q", name, key, color)
+		}
+	}
+	return nil
+}
+
+// validateProfile checks enumerated values
+func validateProfile(name string, profile Profile) error {
+	switch profile.PlaceholderStrategy {
+	case "", "placeholder", "fail":
+	default:
+		return fmt.Errorf("config profile %q: invalid placeholder_strategy %q (expected placeholder|fail)", name, profile.PlaceholderStrategy)
+	}
+	switch profile.AudioStrategy {
+	case "", "placeholder", "http":
+	default:
+		return fmt.Errorf("config profile %q: invalid audio_strategy %q (expected placeholder|http)", name, profile.AudioStrategy)
+	}
+	switch profile.TriggerStrategy {
+	case "", "comment", "placeholder":
+	default:
+		return fmt.Errorf("config profile %q: invalid trigger_strategy %q (expected comment|placeholder)", name, profile.TriggerStrategy)
+	}
+	switch profile.DefaultIntent {
+	case "", "last-class", "end", "none":
+	default:
+		return fmt.Errorf("config profile %q: invalid default_intent %q (expected last-class|end|none)", name, profile.DefaultIntent)
+	}
+	switch profile.ParseMode {
+	case "", "permissive", "strict", "lenient":
+	default:
+		return fmt.Errorf("config profile %q: invalid parse_mode %q (expected permissive|strict|lenient)", name, profile.ParseMode)
+	}
+	if profile.Workers < 0 {
+		return fmt.Errorf("config profile %q: workers must not be negative", name)
+	}
+	for platform := range profile.Accounts {
+		if !models.IsValidPlatformType(models.PlatformType(platform)) {
+			return fmt.Errorf("config profile %q: unknown account platform %q (expected iflytek|dify|coze)", name, platform)
+		}
+	}
+	return validateIcons(name, profile.Icons)
+}
@@ -0,0 +1,164 @@
+// Package gitops keeps the apps of platform accounts in sync with a directory of source-of-truth
+// workflows under version control. Workflows changed since the last sync are converted and
+// pushed; apps edited on the platform since then are reported as drift and overwritten.
+//
+// A state file records, per target and workflow, the app ID and the hashes of the last sync, so
+// repeated runs without changes push nothing and leave the state file untouched.
+package gitops
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultStateFile is the state file name, next to the sync configuration
+const DefaultStateFile = ".agentbridge-sync.json"
+
+// defaultPatterns select workflow files in the source directory
+var defaultPatterns = []string{"*.yml", "*.yaml", "*.zip"}
+
+// Config is the layout of a sync configuration file.
+//
+//	source_dir: workflows
+//	from: iflytek
+//	output_dir: build
+//	targets:
+//	  - name: dify-prod
+//	    platform: dify
+//	    endpoint: https://dify.example.com
+//	    space: 8c1e...
+//	    api_key_env: DIFY_PROD_TOKEN
+type Config struct {
+	SourceDir string   `yaml:"source_dir"`
+	From      string   `yaml:"from,omitempty"`     // Source platform; detected per file when empty
+	Patterns  []string `yaml:"patterns,omitempty"` // Workflow file name patterns, default *.yml, *.yaml, *.zip
+	StateFile string   `yaml:"state_file,omitempty"`
+	OutputDir string   `yaml:"output_dir,omitempty"` // Converted files, required by targets without an import API
+	Targets   []Target `yaml:"targets"`
+
+	dir string // Directory of the configuration file, base of relative paths
+}
+
+// Target is a platform account the workflows are pushed to.
+type Target struct {
+	Name      string `yaml:"name"`
+	Platform  string `yaml:"platform"`
+	Endpoint  string `yaml:"endpoint,omitempty"`
+	Space     string `yaml:"space,omitempty"`
+	APIKeyEnv string `yaml:"api_key_env,omitempty"` // Environment variable of the API key of this target
+	// Apps adopts existing apps on the first sync, keyed by workflow file
+	Apps map[string]string `yaml:"apps,omitempty"`
+}
+
+// LoadConfig reads and validates a sync configuration file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync config %s: %w", path, err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid sync config %s: %w", path, err)
+	}
+	config.dir = filepath.Dir(path)
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid sync config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+func (c *Config) validate() error {
+	if c.SourceDir == "" {
+		return fmt.Errorf("source_dir is required")
+	}
+	if c.From != "" && !models.IsValidPlatformType(models.PlatformType(c.From)) {
+		return fmt.Errorf("unknown from platform %q (expected iflytek|dify|coze)", c.From)
+	}
+	if len(c.Targets) == 0 {
+		return fmt.Errorf("at least one target is required")
+	}
+
+	names := make(map[string]bool, len(c.Targets))
+	for i := range c.Targets {
+		target := &c.Targets[i]
+		if !models.IsValidPlatformType(models.PlatformType(target.Platform)) {
+			return fmt.Errorf("target %d: unknown platform %q (expected iflytek|dify|coze)", i+1, target.Platform)
+		}
+		if target.Name == "" {
+			target.Name = target.Platform
+		}
+		if strings.ContainsAny(target.Name, `/\`) {
+			return fmt.Errorf("target %q: names cannot contain path separators", target.Name)
+		}
+		if names[target.Name] {
+			return fmt.Errorf("target %q is defined twice; name the targets of one platform apart", target.Name)
+		}
+		names[target.Name] = true
+	}
+	return nil
+}
+
+// resolve returns path relative to the configuration file
+func (c *Config) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.dir, path)
+}
+
+// StatePath returns the path of the state file.
+func (c *Config) StatePath() string {
+	if c.StateFile == "" {
+		return filepath.Join(c.dir, DefaultStateFile)
+	}
+	return c.resolve(c.StateFile)
+}
+
+// SourceFiles returns the workflow files of the source directory, relative to it with forward
+// slashes and sorted.
+func (c *Config) SourceFiles() ([]string, error) {
+	root := c.resolve(c.SourceDir)
+	patterns := c.Patterns
+	if len(patterns) == 0 {
+		patterns = defaultPatterns
+	}
+	outputDir := c.resolve(c.OutputDir)
+
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if outputDir != "" && path == outputDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		for _, pattern := range patterns {
+			if matched, _ := filepath.Match(pattern, entry.Name()); matched {
+				rel, err := filepath.Rel(root, path)
+				if err != nil {
+					return err
+				}
+				files = append(files, filepath.ToSlash(rel))
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source workflows: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
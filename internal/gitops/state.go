@@ -0,0 +1,84 @@
+package gitops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// StateFormatVersion is the version of the state file layout
+const StateFormatVersion = "1"
+
+// State records the last sync of every workflow on every target.
+type State struct {
+	FormatVersion string               `json:"format_version"`
+	Apps          map[string]*AppState `json:"apps"` // Keyed by target name and workflow file, e.g. "dify-prod/support.yml"
+}
+
+// AppState is the last sync of one workflow on one target.
+type AppState struct {
+	AppID            string            `json:"app_id,omitempty"`
+	SourceHash       string            `json:"source_hash"`
+	ConverterVersion string            `json:"converter_version"`
+	RemoteHash       string            `json:"remote_hash,omitempty"` // Hash of the app export right after the push
+	Mapping          *models.IDMapping `json:"mapping,omitempty"`     // Reused so updates keep node IDs
+	SyncedAt         time.Time         `json:"synced_at"`
+}
+
+// LoadState reads a state file; a missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	state := &State{FormatVersion: StateFormatVersion, Apps: make(map[string]*AppState)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid sync state %s: %w", path, err)
+	}
+	if state.FormatVersion != StateFormatVersion {
+		return nil, fmt.Errorf("unsupported sync state format version %q (expected %s)", state.FormatVersion, StateFormatVersion)
+	}
+	if state.Apps == nil {
+		state.Apps = make(map[string]*AppState)
+	}
+	return state, nil
+}
+
+// Save writes the state file through a temporary file, so an interrupted job never leaves a
+// truncated state that would create the apps again.
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sync state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create sync state directory: %w", err)
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return nil
+}
+
+func stateKey(target, file string) string {
+	return target + "/" + file
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,277 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/integrations"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// Status is the outcome of the sync of one workflow on one target
+type Status string
+
+const (
+	StatusInSync  Status = "in-sync"
+	StatusCreated Status = "created"
+	StatusUpdated Status = "updated"
+	StatusPending Status = "pending" // Out of sync, left alone by a dry run
+	StatusManual  Status = "manual"  // Converted into the output directory for a manual import
+	StatusFailed  Status = "failed"
+)
+
+// Item reports the sync of one workflow on one target.
+type Item struct {
+	Target   string              `json:"target"`
+	Platform models.PlatformType `json:"platform"`
+	File     string              `json:"file"`
+	AppID    string              `json:"app_id,omitempty"`
+	Status   Status              `json:"status"`
+	Drift    bool                `json:"drift"`             // The app changed on the platform since the last sync
+	Reasons  []string            `json:"reasons,omitempty"` // Why the workflow was out of sync
+	Output   string              `json:"output,omitempty"`  // Converted file, when an output directory is set
+	Warnings []string            `json:"warnings,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// Report is the drift report of a sync run.
+type Report struct {
+	DryRun bool   `json:"dry_run"`
+	Items  []Item `json:"items"`
+}
+
+// Count returns the number of items with status.
+func (r *Report) Count(status Status) int {
+	count := 0
+	for _, item := range r.Items {
+		if item.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Drifted returns the number of apps changed on their platform since the last sync.
+func (r *Report) Drifted() int {
+	count := 0
+	for _, item := range r.Items {
+		if item.Drift {
+			count++
+		}
+	}
+	return count
+}
+
+// Converter converts a source workflow, reusing the target IDs of previous when set
+type Converter func(ctx context.Context, source []byte, from, to models.PlatformType, previous *models.IDMapping) (*services.ConversionResult, error)
+
+// Syncer syncs the workflows of a configuration with its targets.
+type Syncer struct {
+	Config  *Config
+	Convert Converter
+	// NewAdapter reaches a target account; integrations.NewAdapter when nil
+	NewAdapter func(account integrations.Account) (integrations.Adapter, error)
+	// Accounts are the configured accounts per platform, completing the targets
+	Accounts map[models.PlatformType]integrations.Account
+	// Version of the converter; a new version converts and pushes every workflow again
+	Version string
+	DryRun  bool
+}
+
+// Run syncs every workflow with every target. The state is saved after each push, so a job
+// stopped halfway resumes without creating apps twice.
+func (s *Syncer) Run(ctx context.Context) (*Report, error) {
+	files, err := s.Config.SourceFiles()
+	if err != nil {
+		return nil, err
+	}
+	statePath := s.Config.StatePath()
+	state, err := LoadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{DryRun: s.DryRun}
+	for _, target := range s.Config.Targets {
+		adapter, adapterErr := s.adapter(target)
+		for _, file := range files {
+			item := Item{Target: target.Name, Platform: models.PlatformType(target.Platform), File: file}
+			if adapterErr != nil {
+				item.Status = StatusFailed
+				item.Error = adapterErr.Error()
+			} else if changed := s.syncWorkflow(ctx, target, adapter, file, state, &item); changed {
+				if err := state.Save(statePath); err != nil {
+					return report, err
+				}
+			}
+			report.Items = append(report.Items, item)
+		}
+	}
+	return report, nil
+}
+
+// adapter returns the adapter of the account of target
+func (s *Syncer) adapter(target Target) (integrations.Adapter, error) {
+	platform := models.PlatformType(target.Platform)
+	account := integrations.Account{
+		Platform: platform,
+		Endpoint: target.Endpoint,
+		Space:    target.Space,
+	}
+	if target.APIKeyEnv != "" {
+		account.APIKey = os.Getenv(target.APIKeyEnv)
+	}
+	account = account.WithEnvironment(s.Accounts[platform])
+
+	newAdapter := s.NewAdapter
+	if newAdapter == nil {
+		newAdapter = integrations.NewAdapter
+	}
+	adapter, err := newAdapter(account)
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %w", target.Name, err)
+	}
+	return adapter, nil
+}
+
+// syncWorkflow syncs one workflow and reports whether the state changed
+func (s *Syncer) syncWorkflow(ctx context.Context, target Target, adapter integrations.Adapter, file string, state *State, item *Item) bool {
+	fail := func(err error) bool {
+		item.Status = StatusFailed
+		item.Error = err.Error()
+		return false
+	}
+
+	source, err := os.ReadFile(filepath.Join(s.Config.resolve(s.Config.SourceDir), filepath.FromSlash(file)))
+	if err != nil {
+		return fail(fmt.Errorf("failed to read workflow: %w", err))
+	}
+	sourceHash := hash(source)
+
+	key := stateKey(target.Name, file)
+	entry := state.Apps[key]
+	switch {
+	case entry == nil:
+		entry = &AppState{AppID: target.Apps[file]}
+		if entry.AppID != "" {
+			item.Reasons = append(item.Reasons, "adopting app "+entry.AppID)
+		} else {
+			item.Reasons = append(item.Reasons, "new workflow")
+		}
+	case entry.SourceHash != sourceHash:
+		item.Reasons = append(item.Reasons, "source changed")
+	}
+	if entry.ConverterVersion != "" && entry.ConverterVersion != s.Version {
+		item.Reasons = append(item.Reasons, fmt.Sprintf("converter changed from %s to %s", entry.ConverterVersion, s.Version))
+	}
+	item.AppID = entry.AppID
+
+	// Drift can only be told from apps the last sync recorded the export of
+	if entry.AppID != "" && entry.RemoteHash != "" {
+		remote, err := adapter.Pull(ctx, entry.AppID, false)
+		if err != nil {
+			return fail(fmt.Errorf("failed to pull app %s to check drift (drop it from the state file to create it again): %w", entry.AppID, err))
+		}
+		if hash(remote) != entry.RemoteHash {
+			item.Drift = true
+			item.Reasons = append(item.Reasons, "app changed on the platform")
+		}
+	}
+
+	if len(item.Reasons) == 0 {
+		item.Status = StatusInSync
+		return false
+	}
+	if s.DryRun {
+		item.Status = StatusPending
+		return false
+	}
+
+	from := models.PlatformType(s.Config.From)
+	if from == "" {
+		detection, err := common.DetectPlatform(source)
+		if err != nil {
+			return fail(fmt.Errorf("%w; set from in the sync config", err))
+		}
+		from = detection.Platform
+	}
+	to := models.PlatformType(target.Platform)
+	previous := entry.Mapping
+	if previous != nil && previous.CheckPlatforms(from, to) != nil {
+		previous = nil
+	}
+	result, err := s.Convert(ctx, source, from, to, previous)
+	if err != nil {
+		return fail(fmt.Errorf("conversion failed: %w", err))
+	}
+	item.Warnings = append(item.Warnings, result.Warnings...)
+
+	if s.Config.OutputDir != "" {
+		item.Output, err = s.writeOutput(target, file, result.Output)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	pushed, err := adapter.Push(ctx, result.Output, integrations.PushOptions{AppID: entry.AppID})
+	switch {
+	case errors.Is(err, integrations.ErrUnsupported):
+		if item.Output == "" {
+			return fail(fmt.Errorf("%w; set output_dir in the sync config to get the file to import", err))
+		}
+		item.Status = StatusManual
+		item.Warnings = append(item.Warnings, err.Error())
+	case err != nil:
+		return fail(fmt.Errorf("push failed: %w", err))
+	default:
+		item.Status = StatusCreated
+		if pushed.Updated {
+			item.Status = StatusUpdated
+		}
+		item.AppID = pushed.AppID
+		if pushed.Warning != "" {
+			item.Warnings = append(item.Warnings, pushed.Warning)
+		}
+		entry.AppID = pushed.AppID
+		entry.RemoteHash = ""
+		if remote, err := adapter.Pull(ctx, pushed.AppID, false); err == nil {
+			entry.RemoteHash = hash(remote)
+		} else if !errors.Is(err, integrations.ErrUnsupported) {
+			item.Warnings = append(item.Warnings, fmt.Sprintf("drift of app %s goes unnoticed until its next push: %v", pushed.AppID, err))
+		}
+	}
+
+	entry.SourceHash = sourceHash
+	entry.ConverterVersion = s.Version
+	entry.Mapping = result.IDMapping
+	entry.SyncedAt = time.Now().UTC()
+	state.Apps[key] = entry
+	return true
+}
+
+// writeOutput writes a converted workflow to <output_dir>/<target>/<file>
+func (s *Syncer) writeOutput(target Target, file string, data []byte) (string, error) {
+	extension := ".yml"
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		extension = ".zip"
+	}
+	name := strings.TrimSuffix(file, path.Ext(file)) + extension
+	output := filepath.Join(s.Config.resolve(s.Config.OutputDir), target.Name, filepath.FromSlash(name))
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write converted workflow: %w", err)
+	}
+	return output, nil
+}
@@ -0,0 +1,163 @@
+// Package mcp serves the conversion service as a Model Context Protocol server, so assistants
+// and IDE agents can convert, validate and inspect workflows as tools.
+//
+// The server speaks JSON-RPC 2.0 over the stdio transport: one message per line.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// ProtocolVersion is the MCP revision the server implements
+const ProtocolVersion = "2024-11-05"
+
+// maxMessageBytes bounds a single JSON-RPC message, workflow content included
+const maxMessageBytes = 64 << 20
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Config configures the MCP server.
+type Config struct {
+	// Version is reported as the server version in the initialize handshake
+	Version string
+	// Options are the conversion defaults; tool arguments override them
+	Options *models.ConversionOptions
+}
+
+// Server answers MCP requests with the convert_workflow, validate_workflow and inspect_workflow
+// tools.
+type Server struct {
+	service *services.ConversionService
+	config  Config
+	tools   map[string]tool
+}
+
+// New creates an MCP server converting with service.
+func New(service *services.ConversionService, config Config) *Server {
+	s := &Server{service: service, config: config, tools: make(map[string]tool)}
+	for _, t := range s.toolList() {
+		s.tools[t.Name] = t
+	}
+	return s
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve reads requests from r and writes responses to w until r ends or ctx is cancelled.
+// Requests are handled one at a time, in order.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageBytes)
+	write := func(message response) error {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		message, ok := s.handle(ctx, line)
+		if !ok {
+			continue
+		}
+		if err := write(message); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+// handle answers one message; notifications get no response
+func (s *Server) handle(ctx context.Context, line []byte) (response, bool) {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), codeParseError, fmt.Sprintf("invalid JSON: %v", err)), true
+	}
+	if len(req.ID) == 0 {
+		return response{}, false
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "not a JSON-RPC 2.0 request"), true
+	}
+
+	var result interface{}
+	var err *rpcError
+	switch req.Method {
+	case "initialize":
+		result = s.initialize()
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = map[string]interface{}{"tools": s.toolList()}
+	case "tools/call":
+		result, err = s.callTool(ctx, req.Params)
+	default:
+		err = &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+	if err != nil {
+		return errorResponse(req.ID, err.Code, err.Message), true
+	}
+	return response{JSONRPC: "2.0", ID: req.ID, Result: result}, true
+}
+
+// initialize answers the handshake; clients on other revisions decide whether to go on
+func (s *Server) initialize() interface{} {
+	version := s.config.Version
+	if version == "" {
+		version = "dev"
+	}
+	return map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+		"serverInfo":      map[string]string{"name": "agentbridge", "version": version},
+		"instructions": "Converts AI agent workflow DSL files between iFlytek Spark, Dify and Coze. " +
+			"Pass a workflow as a local path, as text content, or base64 for Coze ZIP exports.",
+	}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) response {
+	return response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
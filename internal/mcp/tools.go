@@ -0,0 +1,454 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// tool is an MCP tool and the function running it. A run error is returned to the client as
+// a failed tool result rather than a protocol error, so the model can read it.
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+
+	run func(ctx context.Context, arguments json.RawMessage) ([]content, error)
+}
+
+// content is a block of a tool result
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type toolResult struct {
+	Content []content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+// workflowArguments name the workflow a tool works on
+type workflowArguments struct {
+	Path          string `json:"path"`
+	Content       string `json:"content"`
+	ContentBase64 string `json:"content_base64"`
+	From          string `json:"from"`
+}
+
+// workflowProperties are the input schema properties of workflowArguments
+func workflowProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"path":           stringProperty("Local path of the workflow file"),
+		"content":        stringProperty("Workflow YAML or JSON, instead of path"),
+		"content_base64": stringProperty("Base64 workflow file, for Coze ZIP exports, instead of path"),
+		"from":           enumProperty("Source platform, detected when omitted", "iflytek", "dify", "coze", "unified"),
+	}
+}
+
+// read returns the workflow data and its platform
+func (a workflowArguments) read() ([]byte, models.PlatformType, error) {
+	var data []byte
+	switch {
+	case a.Path != "":
+		file, err := os.ReadFile(a.Path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read workflow: %w", err)
+		}
+		data = file
+	case a.Content != "":
+		data = []byte(a.Content)
+	case a.ContentBase64 != "":
+		decoded, err := base64.StdEncoding.DecodeString(a.ContentBase64)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid content_base64: %w", err)
+		}
+		data = decoded
+	default:
+		return nil, "", errors.New("pass the workflow as path, content or content_base64")
+	}
+
+	platform := models.PlatformType(a.From)
+	if platform == "" {
+		detection, err := common.DetectPlatform(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w; pass the source platform as from", err)
+		}
+		platform = detection.Platform
+	}
+	if !isPlatform(platform) {
+		return nil, "", fmt.Errorf("unsupported source platform %q", platform)
+	}
+	return data, platform, nil
+}
+
+func (s *Server) toolList() []tool {
+	convertProperties := workflowProperties()
+	convertProperties["to"] = enumProperty("Target platform", "iflytek", "dify", "coze", "unified")
+	convertProperties["output_path"] = stringProperty("Write the converted workflow to this local path instead of returning it")
+	convertProperties["target_version"] = stringProperty("Target platform version (dify: 0.6.x|0.15.x|1.x, iflytek: v1|v2)")
+	convertProperties["keep_titles"] = boolProperty("Keep source node titles, placeholder titles included")
+	convertProperties["minify"] = boolProperty("Drop default-valued fields and intern repeated strings in the output")
+	convertProperties["touch"] = boolProperty("Set the updated time of the export to the conversion time")
+	convertProperties["anonymize"] = boolProperty("Replace titles, prompts and literal values with deterministic pseudonyms")
+	convertProperties["placeholder_strategy"] = enumProperty("Unsupported node handling", models.PlaceholderStrategyPlaceholder, models.PlaceholderStrategyFail)
+	convertProperties["trigger_strategy"] = enumProperty("Schedule and event trigger handling on iFlytek and Dify", models.TriggerStrategyComment, models.TriggerStrategyPlaceholder)
+	convertProperties["default_intent"] = enumProperty("Target of the default intent of iFlytek classifiers converted from Dify",
+		models.DefaultIntentLastClass, models.DefaultIntentEnd, models.DefaultIntentNone)
+	convertProperties["output_format"] = enumProperty("Output format", models.OutputFormatYAML, models.OutputFormatJSON)
+	convertProperties["bump_version"] = enumProperty("Increment the workflow version of the export",
+		models.VersionBumpMajor, models.VersionBumpMinor, models.VersionBumpPatch)
+
+	validateProperties := workflowProperties()
+	validateProperties["to"] = enumProperty("Also check how each node would convert to this platform", "iflytek", "dify", "coze")
+
+	return []tool{
+		{
+			Name: "convert_workflow",
+			Description: "Convert an agent workflow DSL between iFlytek Spark, Dify and Coze, or to and from the unified DSL. " +
+				"Returns the converted workflow, then a JSON summary with warnings, placeholder nodes and the node ID mapping.",
+			InputSchema: objectSchema(convertProperties, "to"),
+			run:         s.convertWorkflow,
+		},
+		{
+			Name: "validate_workflow",
+			Description: "Validate a workflow DSL file: syntax, structure and the configured rules. " +
+				"With to, also report the nodes the target platform only approximates or cannot convert.",
+			InputSchema: objectSchema(validateProperties),
+			run:         s.validateWorkflow,
+		},
+		{
+			Name:        "inspect_workflow",
+			Description: "Describe a workflow DSL file: its platform, name, statistics, nodes (iteration bodies included) and edges.",
+			InputSchema: objectSchema(workflowProperties()),
+			run:         s.inspectWorkflow,
+		},
+	}
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (interface{}, *rpcError) {
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &call); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	t, ok := s.tools[call.Name]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool %q", call.Name)}
+	}
+	if len(call.Arguments) == 0 {
+		call.Arguments = json.RawMessage("{}")
+	}
+
+	blocks, err := t.run(ctx, call.Arguments)
+	if err != nil {
+		return toolResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return toolResult{Content: blocks}, nil
+}
+
+func (s *Server) convertWorkflow(ctx context.Context, arguments json.RawMessage) ([]content, error) {
+	var args struct {
+		workflowArguments
+		To                  string `json:"to"`
+		OutputPath          string `json:"output_path"`
+		TargetVersion       string `json:"target_version"`
+		KeepTitles          *bool  `json:"keep_titles"`
+		Minify              *bool  `json:"minify"`
+		Touch               *bool  `json:"touch"`
+		Anonymize           *bool  `json:"anonymize"`
+		PlaceholderStrategy string `json:"placeholder_strategy"`
+		TriggerStrategy     string `json:"trigger_strategy"`
+		DefaultIntent       string `json:"default_intent"`
+		OutputFormat        string `json:"output_format"`
+		BumpVersion         string `json:"bump_version"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	data, platform, err := args.read()
+	if err != nil {
+		return nil, err
+	}
+	target := models.PlatformType(args.To)
+	if err := checkPath(platform, target); err != nil {
+		return nil, err
+	}
+
+	options := s.options()
+	for _, field := range []struct {
+		value  string
+		target *string
+	}{
+		{args.TargetVersion, &options.TargetVersion},
+		{args.PlaceholderStrategy, &options.PlaceholderStrategy},
+		{args.TriggerStrategy, &options.TriggerStrategy},
+		{args.DefaultIntent, &options.DefaultIntent},
+		{args.OutputFormat, &options.OutputFormat},
+		{args.BumpVersion, &options.BumpVersion},
+	} {
+		if field.value != "" {
+			*field.target = field.value
+		}
+	}
+	for _, field := range []struct {
+		value  *bool
+		target *bool
+	}{
+		{args.KeepTitles, &options.KeepTitles},
+		{args.Minify, &options.Minify},
+		{args.Anonymize, &options.Anonymize},
+		{args.Touch, &options.Touch},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+
+	result, err := s.service.ConvertWithResult(ctx, data, platform, target, options)
+	if err != nil {
+		return nil, err
+	}
+	summary := struct {
+		Source       models.PlatformType `json:"source"`
+		Target       models.PlatformType `json:"target"`
+		OutputPath   string              `json:"output_path,omitempty"`
+		Warnings     []string            `json:"warnings"`
+		Placeholders int                 `json:"placeholders"`
+		NodeMapping  map[string]string   `json:"node_mapping,omitempty"`
+	}{platform, target, args.OutputPath, append([]string{}, result.Warnings...), result.Placeholders, result.NodeMapping}
+
+	var blocks []content
+	if args.OutputPath != "" {
+		if err := os.WriteFile(args.OutputPath, result.Output, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write output: %w", err)
+		}
+	} else {
+		blocks = append(blocks, content{Type: "text", Text: string(result.Output)})
+	}
+	summaryBlock, err := jsonContent(summary)
+	if err != nil {
+		return nil, err
+	}
+	return append(blocks, summaryBlock), nil
+}
+
+func (s *Server) validateWorkflow(ctx context.Context, arguments json.RawMessage) ([]content, error) {
+	var args struct {
+		workflowArguments
+		To string `json:"to"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	data, platform, err := args.read()
+	if err != nil {
+		return nil, err
+	}
+
+	type nodeIssue struct {
+		ID          string               `json:"id"`
+		Title       string               `json:"title"`
+		Type        models.NodeType      `json:"type"`
+		Level       common.SupportLevel  `json:"level"`
+		Placeholder bool                 `json:"placeholder,omitempty"`
+		Note        string               `json:"note,omitempty"`
+		Code        []common.CodeFinding `json:"code,omitempty"`
+	}
+	report := struct {
+		Platform models.PlatformType `json:"platform"`
+		Valid    bool                `json:"valid"`
+		Errors   []string            `json:"errors"`
+		Warnings []string            `json:"warnings"`
+		Target   models.PlatformType `json:"target,omitempty"`
+		Nodes    []nodeIssue         `json:"nodes,omitempty"`
+	}{Platform: platform, Errors: []string{}, Warnings: []string{}}
+
+	if err := s.service.ValidateSourceData(data, platform); err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	} else {
+		warnings, err := s.service.ValidateWithRules(data, platform, s.options().Validators)
+		report.Warnings = append(report.Warnings, warnings...)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+	report.Valid = len(report.Errors) == 0
+
+	if report.Valid && args.To != "" {
+		target := models.PlatformType(args.To)
+		if err := checkPath(platform, target); err != nil {
+			return nil, err
+		}
+		check, err := s.service.Check(data, platform, target, s.options())
+		if err != nil {
+			return nil, err
+		}
+		report.Target = target
+		for _, node := range check.Nodes {
+			if node.Level == common.SupportNative && len(node.Code) == 0 {
+				continue
+			}
+			report.Nodes = append(report.Nodes, nodeIssue{
+				ID:          node.ID,
+				Title:       node.Title,
+				Type:        node.Type,
+				Level:       node.Level,
+				Placeholder: node.Placeholder,
+				Note:        node.Note,
+				Code:        node.Code,
+			})
+		}
+		if check.DryRunError != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("conversion to %s fails: %v", target, check.DryRunError))
+			report.Valid = false
+		}
+	}
+
+	block, err := jsonContent(report)
+	if err != nil {
+		return nil, err
+	}
+	return []content{block}, nil
+}
+
+func (s *Server) inspectWorkflow(ctx context.Context, arguments json.RawMessage) ([]content, error) {
+	var args workflowArguments
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	data, platform, err := args.read()
+	if err != nil {
+		return nil, err
+	}
+	unifiedDSL, err := s.service.Parse(data, platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s DSL: %w", platform, err)
+	}
+
+	type node struct {
+		ID        string          `json:"id"`
+		Title     string          `json:"title"`
+		Type      models.NodeType `json:"type"`
+		Iteration string          `json:"iteration,omitempty"` // Iteration whose body holds the node
+	}
+	type edge struct {
+		Source string `json:"source"`
+		Target string `json:"target"`
+		Handle string `json:"handle,omitempty"`
+	}
+	stats := common.ComputeWorkflowStats(unifiedDSL)
+	description := struct {
+		Platform    models.PlatformType     `json:"platform"`
+		Name        string                  `json:"name"`
+		Description string                  `json:"description,omitempty"`
+		NodeCount   int                     `json:"node_count"`
+		EdgeCount   int                     `json:"edge_count"`
+		NodeTypes   map[models.NodeType]int `json:"node_types"`
+		MaxDepth    int                     `json:"max_depth"`
+		Iterations  int                     `json:"iterations"`
+		BranchNodes int                     `json:"branch_nodes"`
+		Nodes       []node                  `json:"nodes"`
+		Edges       []edge                  `json:"edges"`
+	}{
+		Platform:    platform,
+		Name:        unifiedDSL.Metadata.Name,
+		Description: unifiedDSL.Metadata.Description,
+		NodeCount:   stats.Nodes,
+		EdgeCount:   stats.Edges,
+		NodeTypes:   stats.NodeTypes,
+		MaxDepth:    stats.MaxDepth,
+		Iterations:  stats.Iterations,
+		BranchNodes: stats.BranchNodes,
+		Nodes:       []node{},
+		Edges:       []edge{},
+	}
+
+	var walk func(nodes []*models.Node, edges []models.Edge, iteration string)
+	walk = func(nodes []*models.Node, edges []models.Edge, iteration string) {
+		for _, e := range edges {
+			description.Edges = append(description.Edges, edge{Source: e.Source, Target: e.Target, Handle: e.SourceHandle})
+		}
+		for _, n := range nodes {
+			description.Nodes = append(description.Nodes, node{ID: n.ID, Title: n.Title, Type: n.Type, Iteration: iteration})
+			if n.Type == models.NodeTypeIteration {
+				body, bodyEdges := common.IterationBody(unifiedDSL, n)
+				walk(body, bodyEdges, n.ID)
+			}
+		}
+	}
+	nodes, edges := common.TopLevelNodes(unifiedDSL)
+	walk(nodes, edges, "")
+
+	block, err := jsonContent(description)
+	if err != nil {
+		return nil, err
+	}
+	return []content{block}, nil
+}
+
+// options returns a copy of the configured conversion defaults
+func (s *Server) options() *models.ConversionOptions {
+	if s.config.Options == nil {
+		return models.NewConversionOptions()
+	}
+	copied := *s.config.Options
+	return &copied
+}
+
+func jsonContent(v interface{}) (content, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return content{}, err
+	}
+	return content{Type: "text", Text: string(data)}, nil
+}
+
+func isPlatform(platform models.PlatformType) bool {
+	switch platform {
+	case models.PlatformIFlytek, models.PlatformDify, models.PlatformCoze, models.PlatformUnified:
+		return true
+	}
+	return false
+}
+
+// checkPath rejects the conversions the convert command rejects: iFlytek is the hub between Dify
+// and Coze
+func checkPath(source, target models.PlatformType) error {
+	switch {
+	case !isPlatform(target):
+		return fmt.Errorf("unsupported target platform %q", target)
+	case source == target:
+		return fmt.Errorf("the workflow already is a %s workflow", target)
+	case (source == models.PlatformDify && target == models.PlatformCoze) || (source == models.PlatformCoze && target == models.PlatformDify):
+		return fmt.Errorf("direct conversion between %s and %s is not supported; convert to iflytek first, then to %s", source, target, target)
+	}
+	return nil
+}
+
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func boolProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "boolean", "description": description}
+}
+
+func enumProperty(description string, values ...string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description, "enum": values}
+}
@@ -0,0 +1,70 @@
+package models
+
+import "strings"
+
+// Logical operators joining the conditions of a case
+const (
+	LogicalAnd = "and"
+	LogicalOr  = "or"
+)
+
+// ConditionExpression is a node of a logical expression over conditions. Leaves hold a
+// condition; the other nodes join their operands with LogicalOperator.
+type ConditionExpression struct {
+	LogicalOperator string                `yaml:"logical_operator,omitempty" json:"logical_operator,omitempty"` // and/or
+	Operands        []ConditionExpression `yaml:"operands,omitempty" json:"operands,omitempty"`
+	Condition       *Condition            `yaml:"condition,omitempty" json:"condition,omitempty"`
+}
+
+// NormalizeLogicalOperator returns LogicalOr for "or" in any case and "||", and LogicalAnd for
+// everything else, which is what platforms default to.
+func NormalizeLogicalOperator(operator string) string {
+	switch strings.ToLower(strings.TrimSpace(operator)) {
+	case LogicalOr, "||":
+		return LogicalOr
+	}
+	return LogicalAnd
+}
+
+// Tree returns the expression of the case; flat cases become one group of their conditions.
+func (c ConditionCase) Tree() ConditionExpression {
+	if c.Expression != nil {
+		return *c.Expression
+	}
+	group := ConditionExpression{
+		LogicalOperator: NormalizeLogicalOperator(c.LogicalOperator),
+		Operands:        make([]ConditionExpression, len(c.Conditions)),
+	}
+	for i := range c.Conditions {
+		condition := c.Conditions[i]
+		group.Operands[i] = ConditionExpression{Condition: &condition}
+	}
+	return group
+}
+
+// AllConditions returns every condition of the case, the leaves of Expression when it is set.
+func (c ConditionCase) AllConditions() []Condition {
+	if c.Expression == nil {
+		return c.Conditions
+	}
+	var conditions []Condition
+	c.Expression.Walk(func(condition *Condition) {
+		conditions = append(conditions, *condition)
+	})
+	return conditions
+}
+
+// HasConditions reports whether the case tests anything; cases without conditions are default branches.
+func (c ConditionCase) HasConditions() bool {
+	return len(c.AllConditions()) > 0
+}
+
+// Walk calls fn with every condition of the expression, left to right.
+func (e *ConditionExpression) Walk(fn func(condition *Condition)) {
+	if e.Condition != nil {
+		fn(e.Condition)
+	}
+	for i := range e.Operands {
+		e.Operands[i].Walk(fn)
+	}
+}
@@ -0,0 +1,290 @@
+// Package models contains conversion options for the AI Agents Transformer.
+package models
+
+import "fmt"
+
+// ConversionOptions carries user-supplied settings that tune target DSL generation.
+// A nil or zero value keeps the generators' default behavior.
+type ConversionOptions struct {
+	// TargetVersion selects the target platform release the generated DSL must be compatible with
+	TargetVersion string `json:"target_version,omitempty" yaml:"target_version,omitempty"`
+
+	// Node naming options
+	KeepTitles    bool   `json:"keep_titles,omitempty" yaml:"keep_titles,omitempty"`       // Keep source titles, including those of unsupported node placeholders
+	TitlePrefix   string `json:"title_prefix,omitempty" yaml:"title_prefix,omitempty"`     // Prepended to every node title
+	TitleSuffix   string `json:"title_suffix,omitempty" yaml:"title_suffix,omitempty"`     // Appended to every node title
+	TitleTemplate string `json:"title_template,omitempty" yaml:"title_template,omitempty"` // Title template, e.g. "{{title}} (migrated)"; supports {{title}}, {{id}}, {{type}}
+
+	// iFlytek Spark application identity; empty values fall back to environment variables, then defaults
+	IFlytekAppID string `json:"iflytek_app_id,omitempty" yaml:"iflytek_app_id,omitempty"`
+	IFlytekUID   string `json:"iflytek_uid,omitempty" yaml:"iflytek_uid,omitempty"`
+
+	// Anonymize replaces titles, prompts and literal values with deterministic pseudonyms
+	Anonymize bool `json:"anonymize,omitempty" yaml:"anonymize,omitempty"`
+
+	// ModelMap renames source model names to target model names
+	ModelMap map[string]string `json:"model_map,omitempty" yaml:"model_map,omitempty"`
+
+	// PlaceholderStrategy controls unsupported nodes: PlaceholderStrategyPlaceholder or PlaceholderStrategyFail
+	PlaceholderStrategy string `json:"placeholder_strategy,omitempty" yaml:"placeholder_strategy,omitempty"`
+
+	// ParseMode controls unknown node types, malformed references and missing required fields in
+	// the source: ParseModePermissive converts them best-effort with warnings, ParseModeStrict fails,
+	// ParseModeLenient also salvages what damaged exports still hold
+	ParseMode string `json:"parse_mode,omitempty" yaml:"parse_mode,omitempty"`
+
+	// ArchiveLimits bounds what ZIP sources may decompress to; nil keeps DefaultArchiveLimits
+	ArchiveLimits *ArchiveLimits `json:"archive_limits,omitempty" yaml:"archive_limits,omitempty"`
+
+	// AudioStrategy controls speech nodes on targets without them: AudioStrategyPlaceholder or AudioStrategyHTTP
+	AudioStrategy string `json:"audio_strategy,omitempty" yaml:"audio_strategy,omitempty"`
+
+	// NodeStrategies overrides PlaceholderStrategy and AudioStrategy for single nodes, keyed by
+	// node ID: PlaceholderStrategyPlaceholder, PlaceholderStrategyFail or, for speech nodes,
+	// AudioStrategyHTTP
+	NodeStrategies map[string]string `json:"node_strategies,omitempty" yaml:"node_strategies,omitempty"`
+
+	// TriggerStrategy controls schedule and event triggers on targets without them:
+	// TriggerStrategyComment (default) or TriggerStrategyPlaceholder
+	TriggerStrategy string `json:"trigger_strategy,omitempty" yaml:"trigger_strategy,omitempty"`
+
+	// DefaultIntent controls where the default intent iFlytek Spark classifiers require goes for
+	// Dify classifiers, which have none: DefaultIntentLastClass (default), DefaultIntentEnd or
+	// DefaultIntentNone
+	DefaultIntent string `json:"default_intent,omitempty" yaml:"default_intent,omitempty"`
+
+	// OutputFormat selects the serialization of the generated DSL: OutputFormatYAML (default) or
+	// OutputFormatJSON, for targets that import JSON
+	OutputFormat string `json:"output_format,omitempty" yaml:"output_format,omitempty"`
+
+	// Minify drops default-valued fields and interns repeated strings in the generated DSL
+	Minify bool `json:"minify,omitempty" yaml:"minify,omitempty"`
+
+	// PreviousMapping is the ID mapping of an earlier conversion; target IDs of unchanged nodes are reused
+	PreviousMapping *IDMapping `json:"-" yaml:"-"`
+
+	// NodeHooks mutate unified nodes during conversion, in order
+	NodeHooks []NodeHook `json:"-" yaml:"-"`
+
+	// Policy holds governance rules that warn about, rewrite or block nodes before generation
+	Policy *Policy `json:"-" yaml:"-"`
+
+	// Validators are custom validation rules, run in the validation stage each one selects
+	Validators []Validator `json:"-" yaml:"-"`
+
+	// Progress receives the stage and current node of the conversion as it runs
+	Progress ProgressReporter `json:"-" yaml:"-"`
+
+	// DebugDir receives intermediate results of the conversion, such as the workflow JSON extracted
+	// from a ZIP export and the unified DSL; empty writes nothing besides the output
+	DebugDir string `json:"debug_dir,omitempty" yaml:"debug_dir,omitempty"`
+
+	// IncludeNodes and SubgraphFrom convert only part of the workflow: the listed top-level nodes,
+	// and a node with everything downstream of it. Start and end nodes are added as needed.
+	IncludeNodes []string `json:"include_nodes,omitempty" yaml:"include_nodes,omitempty"`
+	SubgraphFrom string   `json:"subgraph_from,omitempty" yaml:"subgraph_from,omitempty"`
+
+	// Icons overrides the icons used when a workflow icon has no form on the target platform
+	Icons *IconSet `json:"icons,omitempty" yaml:"icons,omitempty"`
+
+	// BumpVersion increments the workflow version of the export: VersionBumpMajor, VersionBumpMinor
+	// or VersionBumpPatch
+	BumpVersion string `json:"bump_version,omitempty" yaml:"bump_version,omitempty"`
+
+	// Touch sets the updated time of the export to the conversion time instead of keeping the
+	// source's
+	Touch bool `json:"touch,omitempty" yaml:"touch,omitempty"`
+}
+
+// IconSet configures workflow icon translation. Empty fields keep the built-in icons.
+type IconSet struct {
+	IFlytekDefault string `json:"iflytek_default,omitempty" yaml:"iflytek_default,omitempty"` // Avatar image URL
+	IFlytekColor   string `json:"iflytek_color,omitempty" yaml:"iflytek_color,omitempty"`     // Avatar background color
+	DifyDefault    string `json:"dify_default,omitempty" yaml:"dify_default,omitempty"`       // App icon emoji
+	DifyBackground string `json:"dify_background,omitempty" yaml:"dify_background,omitempty"` // App icon background color
+
+	// Emoji maps Dify emoji icons to iFlytek avatar URLs; it is also read backwards
+	Emoji map[string]string `json:"emoji,omitempty" yaml:"emoji,omitempty"`
+}
+
+// ArchiveLimits protects parsers of ZIP sources from archives crafted to exhaust memory. Sizes are
+// counted while decompressing, since the sizes an archive declares can lie.
+type ArchiveLimits struct {
+	MaxDecompressedBytes int64 `json:"max_decompressed_bytes,omitempty" yaml:"max_decompressed_bytes,omitempty"` // All entries read, nested archives included
+	MaxEntries           int   `json:"max_entries,omitempty" yaml:"max_entries,omitempty"`                       // Entries of one archive
+	MaxNestingDepth      int   `json:"max_nesting_depth,omitempty" yaml:"max_nesting_depth,omitempty"`           // Archives inside the source archive, counted in levels
+}
+
+// DefaultArchiveLimits leaves ample room for real exports, which decompress to a few megabytes at most.
+func DefaultArchiveLimits() ArchiveLimits {
+	return ArchiveLimits{
+		MaxDecompressedBytes: 64 << 20,
+		MaxEntries:           1000,
+		MaxNestingDepth:      2,
+	}
+}
+
+// WithDefaults returns the limits with zero values replaced by the defaults.
+func (l ArchiveLimits) WithDefaults() ArchiveLimits {
+	defaults := DefaultArchiveLimits()
+	if l.MaxDecompressedBytes == 0 {
+		l.MaxDecompressedBytes = defaults.MaxDecompressedBytes
+	}
+	if l.MaxEntries == 0 {
+		l.MaxEntries = defaults.MaxEntries
+	}
+	if l.MaxNestingDepth == 0 {
+		l.MaxNestingDepth = defaults.MaxNestingDepth
+	}
+	return l
+}
+
+// Placeholder strategies for unsupported nodes
+const (
+	// PlaceholderStrategyPlaceholder converts unsupported nodes to code node placeholders
+	PlaceholderStrategyPlaceholder = "placeholder"
+	// PlaceholderStrategyFail aborts conversion when unsupported nodes are found
+	PlaceholderStrategyFail = "fail"
+)
+
+// Parse modes for source DSL problems
+const (
+	// ParseModePermissive works around source problems and reports them as warnings
+	ParseModePermissive = "permissive"
+	// ParseModeStrict fails parsing when the source has any problem, listing all of them
+	ParseModeStrict = "strict"
+	// ParseModeLenient is permissive and also repairs damaged exports, such as truncated Coze
+	// archives, reporting what was salvaged and what was lost
+	ParseModeLenient = "lenient"
+)
+
+// Degrade strategies for speech synthesis / recognition nodes on targets without them
+const (
+	// AudioStrategyPlaceholder converts speech nodes to code stubs that return empty values
+	AudioStrategyPlaceholder = "placeholder"
+	// AudioStrategyHTTP converts speech nodes to code nodes that call an HTTP speech service
+	AudioStrategyHTTP = "http"
+)
+
+// Degrade strategies for workflow triggers on targets without them
+const (
+	// TriggerStrategyComment documents the triggers in the description of the start node
+	TriggerStrategyComment = "comment"
+	// TriggerStrategyPlaceholder adds an unconnected code placeholder documenting each trigger
+	TriggerStrategyPlaceholder = "placeholder"
+)
+
+// Targets of the default intent synthesized for classifiers without a default class
+const (
+	// DefaultIntentLastClass connects the default intent to the target of the last class
+	DefaultIntentLastClass = "last-class"
+	// DefaultIntentEnd connects the default intent to the end node, or the end of the iteration body
+	DefaultIntentEnd = "end"
+	// DefaultIntentNone leaves the default intent unconnected
+	DefaultIntentNone = "none"
+)
+
+// Parts of the semantic workflow version BumpVersion increments
+const (
+	VersionBumpMajor = "major"
+	VersionBumpMinor = "minor"
+	VersionBumpPatch = "patch"
+)
+
+// Serializations of the generated DSL
+const (
+	OutputFormatYAML = "yaml"
+	OutputFormatJSON = "json"
+)
+
+// NewConversionOptions creates conversion options with default values.
+func NewConversionOptions() *ConversionOptions {
+	return &ConversionOptions{}
+}
+
+// HasTitleOptions reports whether any node naming option is set.
+func (o *ConversionOptions) HasTitleOptions() bool {
+	return o.KeepTitles || o.TitlePrefix != "" || o.TitleSuffix != "" || o.TitleTemplate != ""
+}
+
+// PlaceholderStrategyFor returns the placeholder strategy of a node.
+func (o *ConversionOptions) PlaceholderStrategyFor(nodeID string) string {
+	switch strategy := o.NodeStrategies[nodeID]; strategy {
+	case PlaceholderStrategyPlaceholder, PlaceholderStrategyFail:
+		return strategy
+	case AudioStrategyHTTP:
+		// An HTTP speech node is no placeholder to reject
+		return PlaceholderStrategyPlaceholder
+	}
+	return o.PlaceholderStrategy
+}
+
+// AudioStrategyFor returns the audio strategy of a speech node.
+func (o *ConversionOptions) AudioStrategyFor(nodeID string) string {
+	switch strategy := o.NodeStrategies[nodeID]; strategy {
+	case AudioStrategyPlaceholder, AudioStrategyHTTP:
+		return strategy
+	case PlaceholderStrategyFail:
+		// Stubbed, so that the placeholder check rejects it
+		return AudioStrategyPlaceholder
+	}
+	return o.AudioStrategy
+}
+
+// Validate checks enumerated option values.
+func (o *ConversionOptions) Validate() error {
+	switch o.PlaceholderStrategy {
+	case "", PlaceholderStrategyPlaceholder, PlaceholderStrategyFail:
+	default:
+		return fmt.Errorf("invalid placeholder strategy %q (expected %s|%s)",
+			o.PlaceholderStrategy, PlaceholderStrategyPlaceholder, PlaceholderStrategyFail)
+	}
+	switch o.ParseMode {
+	case "", ParseModePermissive, ParseModeStrict, ParseModeLenient:
+	default:
+		return fmt.Errorf("invalid parse mode %q (expected %s|%s|%s)", o.ParseMode, ParseModePermissive, ParseModeStrict, ParseModeLenient)
+	}
+	switch o.AudioStrategy {
+	case "", AudioStrategyPlaceholder, AudioStrategyHTTP:
+	default:
+		return fmt.Errorf("invalid audio strategy %q (expected %s|%s)",
+			o.AudioStrategy, AudioStrategyPlaceholder, AudioStrategyHTTP)
+	}
+	switch o.TriggerStrategy {
+	case "", TriggerStrategyComment, TriggerStrategyPlaceholder:
+	default:
+		return fmt.Errorf("invalid trigger strategy %q (expected %s|%s)",
+			o.TriggerStrategy, TriggerStrategyComment, TriggerStrategyPlaceholder)
+	}
+	switch o.DefaultIntent {
+	case "", DefaultIntentLastClass, DefaultIntentEnd, DefaultIntentNone:
+	default:
+		return fmt.Errorf("invalid default intent %q (expected %s|%s|%s)",
+			o.DefaultIntent, DefaultIntentLastClass, DefaultIntentEnd, DefaultIntentNone)
+	}
+	switch o.OutputFormat {
+	case "", OutputFormatYAML, OutputFormatJSON:
+	default:
+		return fmt.Errorf("invalid output format %q (expected %s|%s)", o.OutputFormat, OutputFormatYAML, OutputFormatJSON)
+	}
+	switch o.BumpVersion {
+	case "", VersionBumpMajor, VersionBumpMinor, VersionBumpPatch:
+	default:
+		return fmt.Errorf("invalid version bump %q (expected %s|%s|%s)", o.BumpVersion, VersionBumpMajor, VersionBumpMinor, VersionBumpPatch)
+	}
+	for nodeID, strategy := range o.NodeStrategies {
+		switch strategy {
+		case PlaceholderStrategyPlaceholder, PlaceholderStrategyFail, AudioStrategyHTTP:
+		default:
+			return fmt.Errorf("invalid strategy %q for node %s (expected %s|%s|%s)", strategy, nodeID,
+				PlaceholderStrategyPlaceholder, PlaceholderStrategyFail, AudioStrategyHTTP)
+		}
+	}
+	if limits := o.ArchiveLimits; limits != nil && (limits.MaxDecompressedBytes < 0 || limits.MaxEntries < 0 || limits.MaxNestingDepth < 0) {
+		return fmt.Errorf("invalid archive limits: values must not be negative")
+	}
+	if o.Policy != nil {
+		return o.Policy.Validate()
+	}
+	return nil
+}
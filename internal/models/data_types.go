@@ -23,6 +23,8 @@ const (
 	DataTypeArrayBoolean UnifiedDataType = "array[boolean]" // Array of booleans
 	DataTypeArrayObject  UnifiedDataType = "array[object]"  // Array of objects
 	DataTypeObject       UnifiedDataType = "object"         // Complex object/map structure
+	DataTypeFile         UnifiedDataType = "file"           // Uploaded or generated file
+	DataTypeArrayFile    UnifiedDataType = "array[file]"    // List of files
 )
 
 // DataTypeMapping defines cross-platform type mapping and alias resolution.
@@ -34,6 +36,9 @@ type DataTypeMapping struct {
 	// Alias mappings for backward compatibility and alternative type names
 	IFlytekAliases map[string]string `yaml:"iflytek_aliases" json:"iflytek_aliases"`
 	DifyAliases    map[string]string `yaml:"dify_aliases" json:"dify_aliases"`
+	// Lossy conversions per platform: unified types the platform has no type for, with what
+	// happens to their values
+	LossyConversions map[PlatformType]map[UnifiedDataType]string `yaml:"lossy_conversions" json:"lossy_conversions"`
 }
 
 // GetDefaultDataTypeMapping returns the standard cross-platform type mapping configuration.
@@ -42,12 +47,13 @@ func GetDefaultDataTypeMapping() *DataTypeMapping {
 		UnifiedTypes: []UnifiedDataType{
 			DataTypeString, DataTypeInteger, DataTypeFloat, DataTypeNumber, DataTypeBoolean,
 			DataTypeArrayString, DataTypeArrayInteger, DataTypeArrayFloat, DataTypeArrayNumber, DataTypeArrayBoolean, DataTypeArrayObject, DataTypeObject,
+			DataTypeFile, DataTypeArrayFile,
 		},
 		IFlytekMapping: map[UnifiedDataType]string{
 			DataTypeString:       "string",
 			DataTypeInteger:      "integer", // iFlytek integer maps to unified integer
 			DataTypeFloat:        "number",  // iFlytek number maps to unified float
-			DataTypeNumber:       "integer", // Backward compatibility: generic number -> integer
+			DataTypeNumber:       "number",  // Generic number may hold decimals
 			DataTypeBoolean:      "boolean",
 			DataTypeArrayString:  "array-string",
 			DataTypeArrayInteger: "array-integer", // iFlytek supports integer arrays
@@ -56,6 +62,8 @@ func GetDefaultDataTypeMapping() *DataTypeMapping {
 			DataTypeArrayBoolean: "array-boolean", // iFlytek supports boolean arrays
 			DataTypeArrayObject:  "array-object",
 			DataTypeObject:       "object",
+			DataTypeFile:         "string",       // Files are passed as URLs
+			DataTypeArrayFile:    "array-string", // File lists are passed as URL lists
 		},
 		DifyMapping: map[UnifiedDataType]string{
 			DataTypeString:       "string",
@@ -70,6 +78,8 @@ func GetDefaultDataTypeMapping() *DataTypeMapping {
 			DataTypeArrayBoolean: "array[boolean]", // Dify supports boolean arrays
 			DataTypeArrayObject:  "array[object]",
 			DataTypeObject:       "object",
+			DataTypeFile:         "file",
+			DataTypeArrayFile:    "array[file]",
 		},
 		// Coze platform type mapping
 		CozeMapping: map[UnifiedDataType]string{
@@ -81,10 +91,12 @@ func GetDefaultDataTypeMapping() *DataTypeMapping {
 			DataTypeArrayString:  "array[string]",
 			DataTypeArrayInteger: "array[integer]", // Coze supports precise integer arrays
 			DataTypeArrayFloat:   "array[float]",   // Coze supports precise float arrays
-			DataTypeArrayNumber:  "array[number]",  // Backward compatibility for generic number arrays
+			DataTypeArrayNumber:  "array[float]",   // Generic number arrays -> float arrays
 			DataTypeArrayBoolean: "array[boolean]", // Coze supports boolean arrays
 			DataTypeArrayObject:  "array[object]",
 			DataTypeObject:       "object",
+			DataTypeFile:         "string",        // Files are passed as URLs
+			DataTypeArrayFile:    "array[string]", // File lists are passed as URL lists
 		},
 		// iFlytek platform type aliases for backward compatibility
 		IFlytekAliases: map[string]string{
@@ -96,10 +108,64 @@ func GetDefaultDataTypeMapping() *DataTypeMapping {
 			"integer": "number", "int": "number", "bool": "boolean", "str": "string",
 			"text": "string", "list": "array[string]", "array": "array[string]",
 			"dict": "object", "map": "object", "float": "number", "double": "number",
+			"file-list": "array[file]",
+		},
+		LossyConversions: map[PlatformType]map[UnifiedDataType]string{
+			PlatformIFlytek: {
+				DataTypeFile:      "files become URL strings",
+				DataTypeArrayFile: "file lists become lists of URL strings",
+			},
+			PlatformCoze: {
+				DataTypeFile:      "files become URL strings",
+				DataTypeArrayFile: "file lists become lists of URL strings",
+			},
 		},
 	}
 }
 
+// Platform types that several unified types map to resolve to one preferred unified type, so
+// that parsing does not depend on map iteration order.
+var (
+	iflytekUnifiedTypes = map[string]UnifiedDataType{
+		"string":        DataTypeString,
+		"integer":       DataTypeInteger,
+		"number":        DataTypeFloat,
+		"boolean":       DataTypeBoolean,
+		"object":        DataTypeObject,
+		"array-string":  DataTypeArrayString,
+		"array-integer": DataTypeArrayInteger,
+		"array-number":  DataTypeArrayFloat,
+		"array-boolean": DataTypeArrayBoolean,
+		"array-object":  DataTypeArrayObject,
+	}
+	difyUnifiedTypes = map[string]UnifiedDataType{
+		"string":         DataTypeString,
+		"number":         DataTypeNumber,
+		"boolean":        DataTypeBoolean,
+		"object":         DataTypeObject,
+		"array[string]":  DataTypeArrayString,
+		"array[number]":  DataTypeArrayNumber,
+		"array[boolean]": DataTypeArrayBoolean,
+		"array[object]":  DataTypeArrayObject,
+		"file":           DataTypeFile,
+		"array[file]":    DataTypeArrayFile,
+	}
+	cozeUnifiedTypes = map[string]UnifiedDataType{
+		"string":         DataTypeString,
+		"integer":        DataTypeInteger,
+		"float":          DataTypeFloat,
+		"boolean":        DataTypeBoolean,
+		"object":         DataTypeObject,
+		"list":           DataTypeArrayString, // Element type is in the schema
+		"array":          DataTypeArrayString,
+		"array[string]":  DataTypeArrayString,
+		"array[integer]": DataTypeArrayInteger,
+		"array[float]":   DataTypeArrayFloat,
+		"array[boolean]": DataTypeArrayBoolean,
+		"array[object]":  DataTypeArrayObject,
+	}
+)
+
 // ToIFlytekType converts unified type to iFlytek platform-specific type.
 func (dtm *DataTypeMapping) ToIFlytekType(unifiedType UnifiedDataType) string {
 	if iflytekType, exists := dtm.IFlytekMapping[unifiedType]; exists {
@@ -124,29 +190,37 @@ func (dtm *DataTypeMapping) ToCozeType(unifiedType UnifiedDataType) string {
 	return string(unifiedType)
 }
 
+// ToPlatformType converts unified type to the type of platform; unified and unknown platforms
+// keep the unified type.
+func (dtm *DataTypeMapping) ToPlatformType(platform PlatformType, unifiedType UnifiedDataType) string {
+	switch platform {
+	case PlatformIFlytek:
+		return dtm.ToIFlytekType(unifiedType)
+	case PlatformDify:
+		return dtm.ToDifyType(unifiedType)
+	case PlatformCoze:
+		return dtm.ToCozeType(unifiedType)
+	default:
+		return string(unifiedType)
+	}
+}
+
+// LossyConversion returns what happens to values of unified type on platform, false when the
+// platform has a matching type.
+func (dtm *DataTypeMapping) LossyConversion(platform PlatformType, unifiedType UnifiedDataType) (string, bool) {
+	loss, lossy := dtm.LossyConversions[platform][unifiedType]
+	return loss, lossy
+}
+
 // FromIFlytekType converts iFlytek platform type to unified type with precise type recognition.
 func (dtm *DataTypeMapping) FromIFlytekType(iflytekType string) UnifiedDataType {
-	// Precise type mapping without aliases for core types
-	switch iflytekType {
-	case "integer":
-		return DataTypeInteger
-	case "number":
-		return DataTypeFloat
-	case "string":
-		return DataTypeString
-	case "boolean":
-		return DataTypeBoolean
+	if unified, exists := iflytekUnifiedTypes[iflytekType]; exists {
+		return unified
 	}
 
 	// Check for alias mappings for other type names
 	if canonical, exists := dtm.IFlytekAliases[iflytekType]; exists {
-		// Recursively resolve aliases
-		return dtm.FromIFlytekType(canonical)
-	}
-
-	// Perform reverse mapping from platform type to unified type for remaining types
-	for unified, iflytek := range dtm.IFlytekMapping {
-		if iflytek == iflytekType {
+		if unified, exists := iflytekUnifiedTypes[canonical]; exists {
 			return unified
 		}
 	}
@@ -160,15 +234,21 @@ func (dtm *DataTypeMapping) FromDifyType(difyType string) UnifiedDataType {
 		difyType = canonical
 	}
 
-	// Perform reverse mapping from platform type to unified type
-	for unified, dify := range dtm.DifyMapping {
-		if dify == difyType {
-			return unified
-		}
+	if unified, exists := difyUnifiedTypes[difyType]; exists {
+		return unified
 	}
 	return UnifiedDataType(difyType)
 }
 
+// FromCozeType converts Coze platform type to unified type. Coze lists carry their element type
+// in a schema, so a bare list resolves to a string list.
+func (dtm *DataTypeMapping) FromCozeType(cozeType string) UnifiedDataType {
+	if unified, exists := cozeUnifiedTypes[cozeType]; exists {
+		return unified
+	}
+	return UnifiedDataType(cozeType)
+}
+
 // MapToDifyTypeWithAliases maps any input type to Dify format with comprehensive alias support.
 // Attempts multiple resolution strategies before defaulting to string type.
 func (dtm *DataTypeMapping) MapToDifyTypeWithAliases(inputType string) string {
@@ -371,14 +451,38 @@ func IsStringType(dataType UnifiedDataType) bool { return dataType == DataTypeSt
 func IsArrayType(dataType UnifiedDataType) bool {
 	return dataType == DataTypeArrayString || dataType == DataTypeArrayInteger ||
 		dataType == DataTypeArrayFloat || dataType == DataTypeArrayNumber ||
-		dataType == DataTypeArrayBoolean || dataType == DataTypeArrayObject
+		dataType == DataTypeArrayBoolean || dataType == DataTypeArrayObject || dataType == DataTypeArrayFile
 }
 func IsObjectType(dataType UnifiedDataType) bool { return dataType == DataTypeObject }
+func IsFileType(dataType UnifiedDataType) bool {
+	return dataType == DataTypeFile || dataType == DataTypeArrayFile
+}
 func IsPrimitiveType(dataType UnifiedDataType) bool {
 	return dataType == DataTypeString || dataType == DataTypeNumber ||
 		dataType == DataTypeInteger || dataType == DataTypeFloat || dataType == DataTypeBoolean
 }
 
+// ArrayTypeOf returns the list type with elements of elementType, a string list for element types
+// without one.
+func ArrayTypeOf(elementType UnifiedDataType) UnifiedDataType {
+	switch elementType {
+	case DataTypeInteger:
+		return DataTypeArrayInteger
+	case DataTypeFloat:
+		return DataTypeArrayFloat
+	case DataTypeNumber:
+		return DataTypeArrayNumber
+	case DataTypeBoolean:
+		return DataTypeArrayBoolean
+	case DataTypeObject:
+		return DataTypeArrayObject
+	case DataTypeFile:
+		return DataTypeArrayFile
+	default:
+		return DataTypeArrayString
+	}
+}
+
 // GetTypeCategory returns the general category of a data type.
 func GetTypeCategory(dataType UnifiedDataType) string {
 	switch {
@@ -388,6 +492,8 @@ func GetTypeCategory(dataType UnifiedDataType) string {
 		return "array"
 	case IsObjectType(dataType):
 		return "object"
+	case IsFileType(dataType):
+		return "file"
 	default:
 		return "unknown"
 	}
@@ -0,0 +1,106 @@
+// Package models contains the source-to-target ID mapping of a conversion.
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// IDMappingFormatVersion is the version of the exported ID mapping file layout
+const IDMappingFormatVersion = "1"
+
+// IDMapping correlates source workflow identifiers with the identifiers of the generated workflow.
+type IDMapping struct {
+	FormatVersion  string       `json:"format_version"`
+	SourcePlatform PlatformType `json:"source_platform"`
+	TargetPlatform PlatformType `json:"target_platform"`
+
+	Nodes    map[string]string            `json:"nodes"`              // Source node ID -> target node ID
+	Outputs  map[string]map[string]string `json:"outputs,omitempty"`  // Source node ID -> output name -> target output ID
+	Branches map[string]map[string]string `json:"branches,omitempty"` // Source node ID -> source case ID -> target branch ID
+	Intents  map[string]map[string]string `json:"intents,omitempty"`  // Source node ID -> source class ID -> target intent ID
+}
+
+// NewIDMapping creates an empty ID mapping.
+func NewIDMapping() *IDMapping {
+	return &IDMapping{
+		FormatVersion: IDMappingFormatVersion,
+		Nodes:         make(map[string]string),
+		Outputs:       make(map[string]map[string]string),
+		Branches:      make(map[string]map[string]string),
+		Intents:       make(map[string]map[string]string),
+	}
+}
+
+// ParseIDMapping decodes a previously exported ID mapping file.
+func ParseIDMapping(data []byte) (*IDMapping, error) {
+	var mapping IDMapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("invalid ID mapping: %w", err)
+	}
+	if mapping.FormatVersion != IDMappingFormatVersion {
+		return nil, fmt.Errorf("unsupported ID mapping format version %q (expected %s)", mapping.FormatVersion, IDMappingFormatVersion)
+	}
+	if mapping.Nodes == nil {
+		mapping.Nodes = make(map[string]string)
+	}
+	return &mapping, nil
+}
+
+// CheckPlatforms verifies that the mapping was produced by a conversion between the same platforms.
+func (m *IDMapping) CheckPlatforms(sourcePlatform, targetPlatform PlatformType) error {
+	if m.SourcePlatform != sourcePlatform || m.TargetPlatform != targetPlatform {
+		return fmt.Errorf("ID mapping was produced for %s -> %s, not %s -> %s",
+			m.SourcePlatform, m.TargetPlatform, sourcePlatform, targetPlatform)
+	}
+	return nil
+}
+
+// AddOutput records the target ID of a node output.
+func (m *IDMapping) AddOutput(sourceNodeID, outputName, targetOutputID string) {
+	addNestedID(m.Outputs, sourceNodeID, outputName, targetOutputID)
+}
+
+// AddBranch records the target ID of a condition branch.
+func (m *IDMapping) AddBranch(sourceNodeID, sourceCaseID, targetBranchID string) {
+	addNestedID(m.Branches, sourceNodeID, sourceCaseID, targetBranchID)
+}
+
+// AddIntent records the target ID of a classifier intent.
+func (m *IDMapping) AddIntent(sourceNodeID, sourceClassID, targetIntentID string) {
+	addNestedID(m.Intents, sourceNodeID, sourceClassID, targetIntentID)
+}
+
+func addNestedID(table map[string]map[string]string, nodeID, key, value string) {
+	if nodeID == "" || key == "" || value == "" {
+		return
+	}
+	if table[nodeID] == nil {
+		table[nodeID] = make(map[string]string)
+	}
+	table[nodeID][key] = value
+}
+
+// Clone returns a deep copy of the mapping.
+func (m *IDMapping) Clone() *IDMapping {
+	clone := *m
+	clone.Nodes = make(map[string]string, len(m.Nodes))
+	for sourceID, targetID := range m.Nodes {
+		clone.Nodes[sourceID] = targetID
+	}
+	clone.Outputs = cloneNestedIDs(m.Outputs)
+	clone.Branches = cloneNestedIDs(m.Branches)
+	clone.Intents = cloneNestedIDs(m.Intents)
+	return &clone
+}
+
+func cloneNestedIDs(table map[string]map[string]string) map[string]map[string]string {
+	clone := make(map[string]map[string]string, len(table))
+	for nodeID, ids := range table {
+		clone[nodeID] = make(map[string]string, len(ids))
+		for key, value := range ids {
+			clone[nodeID][key] = value
+		}
+	}
+	return clone
+}
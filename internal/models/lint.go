@@ -0,0 +1,10 @@
+package models
+
+// LintSeverityOff disables a lint rule in LintConfig.Severity
+const LintSeverityOff ErrorSeverity = "off"
+
+// LintConfig selects and grades the best-practice rules of the lint command.
+type LintConfig struct {
+	Disable  []string                 `yaml:"disable,omitempty" json:"disable,omitempty"`   // Rules not run
+	Severity map[string]ErrorSeverity `yaml:"severity,omitempty" json:"severity,omitempty"` // Severity by rule: error, warning, info or off
+}
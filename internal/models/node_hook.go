@@ -0,0 +1,38 @@
+package models
+
+// NodeHookContext describes the conversion a node hook runs in.
+type NodeHookContext struct {
+	SourcePlatform PlatformType
+	TargetPlatform PlatformType
+}
+
+// NodeHook lets integrators mutate unified nodes during conversion. BeforeNodeConvert sees every
+// node as parsed from the source DSL; AfterNodeConvert sees every node right before target
+// generation, after lowering, model mapping and title options. Iteration sub-workflow nodes are
+// included. Returning an error aborts the conversion.
+type NodeHook interface {
+	BeforeNodeConvert(ctx NodeHookContext, node *Node) error
+	AfterNodeConvert(ctx NodeHookContext, node *Node) error
+}
+
+// NodeHookFuncs adapts functions to NodeHook; nil functions leave nodes unchanged.
+type NodeHookFuncs struct {
+	Before func(ctx NodeHookContext, node *Node) error
+	After  func(ctx NodeHookContext, node *Node) error
+}
+
+// BeforeNodeConvert calls Before when set.
+func (h NodeHookFuncs) BeforeNodeConvert(ctx NodeHookContext, node *Node) error {
+	if h.Before == nil {
+		return nil
+	}
+	return h.Before(ctx, node)
+}
+
+// AfterNodeConvert calls After when set.
+func (h NodeHookFuncs) AfterNodeConvert(ctx NodeHookContext, node *Node) error {
+	if h.After == nil {
+		return nil
+	}
+	return h.After(ctx, node)
+}
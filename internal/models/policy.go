@@ -0,0 +1,112 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy actions
+const (
+	// PolicyActionWarn reports violations as conversion warnings
+	PolicyActionWarn = "warn"
+	// PolicyActionRewrite fixes violations in the converted DSL and reports them as warnings
+	PolicyActionRewrite = "rewrite"
+	// PolicyActionBlock aborts conversion on violations
+	PolicyActionBlock = "block"
+)
+
+// Policy is a set of governance rules evaluated against the unified DSL before generation.
+type Policy struct {
+	Rules []PolicyRule `yaml:"rules" json:"rules"`
+}
+
+// PolicyRule is a single constraint with the action taken on violation. Each rule sets exactly
+// one constraint; NodeType narrows the nodes it applies to.
+type PolicyRule struct {
+	Name     string   `yaml:"name" json:"name"`
+	Action   string   `yaml:"action" json:"action"`                           // warn (default), rewrite or block
+	NodeType NodeType `yaml:"node_type,omitempty" json:"node_type,omitempty"` // Only nodes of this type, empty for all
+
+	// Constraints
+	MaxTemperature   *float64   `yaml:"max_temperature,omitempty" json:"max_temperature,omitempty"`     // LLM and classifier nodes; rewrite lowers the temperature
+	AllowedProviders []string   `yaml:"allowed_providers,omitempty" json:"allowed_providers,omitempty"` // LLM and classifier nodes, path.Match patterns
+	AllowedModels    []string   `yaml:"allowed_models,omitempty" json:"allowed_models,omitempty"`       // LLM and classifier nodes, path.Match patterns
+	ForbidImports    []string   `yaml:"forbid_imports,omitempty" json:"forbid_imports,omitempty"`       // Code nodes, module names including submodules
+	ForbidNodeTypes  []NodeType `yaml:"forbid_node_types,omitempty" json:"forbid_node_types,omitempty"`
+
+	// Replacement is the provider or model name a rewrite of AllowedProviders or AllowedModels sets
+	Replacement string `yaml:"replacement,omitempty" json:"replacement,omitempty"`
+}
+
+// ParsePolicy parses a YAML policy, rejecting unknown keys and invalid rules.
+func ParsePolicy(data []byte) (*Policy, error) {
+	policy := &Policy{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Validate checks that every rule has one constraint and an action it supports.
+func (p *Policy) Validate() error {
+	for i, rule := range p.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid policy rule %d (%s): %w", i+1, rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r PolicyRule) validate() error {
+	switch r.Action {
+	case "", PolicyActionWarn, PolicyActionRewrite, PolicyActionBlock:
+	default:
+		return fmt.Errorf("invalid action %q (expected %s|%s|%s)", r.Action, PolicyActionWarn, PolicyActionRewrite, PolicyActionBlock)
+	}
+
+	constraints := 0
+	for _, set := range []bool{
+		r.MaxTemperature != nil, len(r.AllowedProviders) > 0, len(r.AllowedModels) > 0,
+		len(r.ForbidImports) > 0, len(r.ForbidNodeTypes) > 0,
+	} {
+		if set {
+			constraints++
+		}
+	}
+	if constraints != 1 {
+		return fmt.Errorf("a rule needs exactly one constraint, found %d", constraints)
+	}
+
+	for _, pattern := range append(append([]string(nil), r.AllowedProviders...), r.AllowedModels...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	if r.Action != PolicyActionRewrite {
+		return nil
+	}
+	switch {
+	case len(r.ForbidImports) > 0 || len(r.ForbidNodeTypes) > 0:
+		return fmt.Errorf("forbidden imports and node types cannot be rewritten, use %s or %s", PolicyActionWarn, PolicyActionBlock)
+	case (len(r.AllowedProviders) > 0 || len(r.AllowedModels) > 0) && r.Replacement == "":
+		return fmt.Errorf("rewriting providers or models needs a replacement")
+	}
+	return nil
+}
+
+// EffectiveAction returns the action of the rule, defaulting to warn.
+func (r PolicyRule) EffectiveAction() string {
+	if r.Action == "" {
+		return PolicyActionWarn
+	}
+	return r.Action
+}
@@ -0,0 +1,77 @@
+package models
+
+import "context"
+
+// ProgressStage names a step of a conversion.
+type ProgressStage string
+
+const (
+	ProgressStageParse     ProgressStage = "parse"     // Reading the source DSL
+	ProgressStageTransform ProgressStage = "transform" // Lowering, checks, hooks and policies on the unified DSL
+	ProgressStageGenerate  ProgressStage = "generate"  // Writing the target DSL
+	ProgressStageDone      ProgressStage = "done"
+)
+
+// progressStageRanges are the parts of the overall percentage each stage covers
+var progressStageRanges = map[ProgressStage][2]int{
+	ProgressStageParse:     {0, 40},
+	ProgressStageTransform: {40, 50},
+	ProgressStageGenerate:  {50, 100},
+	ProgressStageDone:      {100, 100},
+}
+
+// ProgressEvent reports how far a conversion got. NodeID and NodeTitle name the node being parsed
+// or generated and are empty for events that start a stage. Compositions report the parse stage
+// once per workflow.
+type ProgressEvent struct {
+	Stage     ProgressStage `json:"stage"`
+	Percent   int           `json:"percent"`
+	NodeID    string        `json:"node_id,omitempty"`
+	NodeTitle string        `json:"node_title,omitempty"`
+}
+
+// ProgressReporter receives progress events of a conversion, in order, on the goroutine running it.
+// Reporters shared by concurrent conversions must synchronize themselves.
+type ProgressReporter interface {
+	ReportProgress(event ProgressEvent)
+}
+
+// ProgressFunc adapts a function to ProgressReporter.
+type ProgressFunc func(event ProgressEvent)
+
+// ReportProgress calls f.
+func (f ProgressFunc) ReportProgress(event ProgressEvent) {
+	f(event)
+}
+
+type progressKey struct{}
+
+// WithProgressReporter returns a context that carries reporter to the parsers and generators of a
+// conversion. A nil reporter returns ctx unchanged.
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	if reporter == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey{}, reporter)
+}
+
+// ReportStage reports the start of a stage to the reporter of ctx, if any.
+func ReportStage(ctx context.Context, stage ProgressStage) {
+	if reporter, ok := ctx.Value(progressKey{}).(ProgressReporter); ok {
+		reporter.ReportProgress(ProgressEvent{Stage: stage, Percent: progressStageRanges[stage][0]})
+	}
+}
+
+// ReportNodeProgress reports that the node at index of total nodes is being handled in stage.
+func ReportNodeProgress(ctx context.Context, stage ProgressStage, index, total int, nodeID, nodeTitle string) {
+	reporter, ok := ctx.Value(progressKey{}).(ProgressReporter)
+	if !ok {
+		return
+	}
+	bounds := progressStageRanges[stage]
+	percent := bounds[0]
+	if total > 0 && index > 0 {
+		percent += (bounds[1] - bounds[0]) * min(index, total) / total
+	}
+	reporter.ReportProgress(ProgressEvent{Stage: stage, Percent: percent, NodeID: nodeID, NodeTitle: nodeTitle})
+}
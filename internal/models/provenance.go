@@ -0,0 +1,46 @@
+// Package models contains conversion provenance for the AI Agents Transformer.
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// ProvenanceToolName identifies the converter in provenance records
+const ProvenanceToolName = "agentbridge"
+
+// Provenance modes for generated artifacts
+const (
+	// ProvenanceModeEmbed writes provenance as a comment header of the generated DSL
+	ProvenanceModeEmbed = "embed"
+	// ProvenanceModeSidecar writes provenance to a JSON file next to the generated DSL
+	ProvenanceModeSidecar = "sidecar"
+)
+
+// Provenance records which tool and inputs produced a generated DSL.
+type Provenance struct {
+	Tool           string            `json:"tool"`
+	ToolVersion    string            `json:"tool_version"`
+	SourcePlatform PlatformType      `json:"source_platform"`
+	TargetPlatform PlatformType      `json:"target_platform"`
+	SourceFile     string            `json:"source_file,omitempty"`
+	SourceSHA256   string            `json:"source_sha256"`
+	ConvertedAt    time.Time         `json:"converted_at"`
+	NodeMapping    map[string]string `json:"node_mapping,omitempty"` // Source node ID -> target node ID
+}
+
+// NewProvenance creates a provenance record for a conversion.
+func NewProvenance(toolVersion string, sourcePlatform, targetPlatform PlatformType, sourceFile string, sourceData []byte, nodeMapping map[string]string) *Provenance {
+	sum := sha256.Sum256(sourceData)
+	return &Provenance{
+		Tool:           ProvenanceToolName,
+		ToolVersion:    toolVersion,
+		SourcePlatform: sourcePlatform,
+		TargetPlatform: targetPlatform,
+		SourceFile:     sourceFile,
+		SourceSHA256:   hex.EncodeToString(sum[:]),
+		ConvertedAt:    time.Now().UTC(),
+		NodeMapping:    nodeMapping,
+	}
+}
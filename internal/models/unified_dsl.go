@@ -19,6 +19,9 @@ type Metadata struct {
 	CreatedAt   time.Time `yaml:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `yaml:"updated_at" json:"updated_at"`
 	UIConfig    *UIConfig `yaml:"ui_config,omitempty" json:"ui_config,omitempty"`
+	Tags        []string  `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Author      string    `yaml:"author,omitempty" json:"author,omitempty"`
+	Version     string    `yaml:"version,omitempty" json:"version,omitempty"` // Version of the workflow itself, e.g. 1.4.0
 }
 
 // UIConfig contains user interface configuration
@@ -52,6 +55,9 @@ type DifyMetadata struct {
 	UseIconAsAnswerIcon bool   `yaml:"use_icon_as_answer_icon" json:"use_icon_as_answer_icon"`
 	Kind                string `yaml:"kind" json:"kind"`
 	AppVersion          string `yaml:"app_version" json:"app_version"`
+
+	// Features is the workflow features block as exported, restored on Dify targets
+	Features map[string]interface{} `yaml:"features,omitempty" json:"features,omitempty"`
 }
 
 // CozeMetadata contains Coze platform specific metadata
@@ -73,8 +79,29 @@ type Workflow struct {
 	Edges     []Edge     `yaml:"edges" json:"edges"`
 	Variables []Variable `yaml:"variables" json:"variables"`
 	Features  *Features  `yaml:"features,omitempty" json:"features,omitempty"`
+	Triggers  []Trigger  `yaml:"triggers,omitempty" json:"triggers,omitempty"`
+}
+
+// Trigger starts the workflow without a user, on a schedule or an event. The start node stays the
+// entry point: a trigger runs it with the values of Inputs for its variables.
+type Trigger struct {
+	ID       string                 `yaml:"id" json:"id"`
+	Title    string                 `yaml:"title,omitempty" json:"title,omitempty"`
+	Type     TriggerType            `yaml:"type" json:"type"`
+	Cron     string                 `yaml:"cron,omitempty" json:"cron,omitempty"`         // Schedule triggers, five fields, e.g. "0 9 * * 1-5"
+	Timezone string                 `yaml:"timezone,omitempty" json:"timezone,omitempty"` // IANA name, e.g. Asia/Shanghai
+	Event    string                 `yaml:"event,omitempty" json:"event,omitempty"`       // Event triggers, the event name
+	Inputs   map[string]interface{} `yaml:"inputs,omitempty" json:"inputs,omitempty"`     // Start variable name -> value
 }
 
+// TriggerType represents trigger type enumeration
+type TriggerType string
+
+const (
+	TriggerTypeSchedule TriggerType = "schedule" // Runs on a cron schedule
+	TriggerTypeEvent    TriggerType = "event"    // Runs when a platform event fires
+)
+
 // Features contains feature configuration
 type Features struct {
 	FileUpload         *FileUploadConfig `yaml:"file_upload,omitempty" json:"file_upload,omitempty"`
@@ -114,7 +141,9 @@ type Variable struct {
 	Required    bool         `yaml:"required" json:"required"`
 	Default     interface{}  `yaml:"default,omitempty" json:"default,omitempty"`
 	Description string       `yaml:"description,omitempty" json:"description,omitempty"`
+	Placeholder string       `yaml:"placeholder,omitempty" json:"placeholder,omitempty"` // Text shown in the empty input
 	Constraints *Constraints `yaml:"constraints,omitempty" json:"constraints,omitempty"`
+	Control     string       `yaml:"control,omitempty" json:"control,omitempty"` // Input control of start variables, see VariableControlText
 
 	// Extended fields to support platform-specific information
 	ID                  string        `yaml:"id,omitempty" json:"id,omitempty"`
@@ -122,6 +151,35 @@ type Variable struct {
 	CustomParameterType string        `yaml:"custom_parameter_type,omitempty" json:"custom_parameter_type,omitempty"`
 	DeleteDisabled      bool          `yaml:"delete_disabled,omitempty" json:"delete_disabled,omitempty"`
 	NameErrMsg          string        `yaml:"name_err_msg,omitempty" json:"name_err_msg,omitempty"`
+
+	// File is set for file inputs; Type is then string, or array[string] for file lists
+	File *FileInput `yaml:"file,omitempty" json:"file,omitempty"`
+}
+
+// Start variable input controls; options and maximum length live in Constraints
+const (
+	VariableControlText      = "text"      // Single-line text
+	VariableControlParagraph = "paragraph" // Multi-line text
+	VariableControlSelect    = "select"    // One of Constraints.Options
+	VariableControlNumber    = "number"
+)
+
+// File input categories
+const (
+	FileTypeDocument = "document"
+	FileTypeImage    = "image"
+	FileTypeAudio    = "audio"
+	FileTypeVideo    = "video"
+	FileTypeCustom   = "custom" // Restricted to AllowedExtensions
+)
+
+// FileInput describes the files a start variable accepts
+type FileInput struct {
+	Multiple          bool     `yaml:"multiple,omitempty" json:"multiple,omitempty"`                     // File list instead of a single file
+	AllowedTypes      []string `yaml:"allowed_types,omitempty" json:"allowed_types,omitempty"`           // document/image/audio/video/custom
+	AllowedExtensions []string `yaml:"allowed_extensions,omitempty" json:"allowed_extensions,omitempty"` // e.g. .pdf, for custom files
+	UploadMethods     []string `yaml:"upload_methods,omitempty" json:"upload_methods,omitempty"`         // local_file/remote_url
+	MaxCount          int      `yaml:"max_count,omitempty" json:"max_count,omitempty"`                   // File list size limit
 }
 
 // Constraints defines variable constraints
@@ -136,13 +194,21 @@ type Constraints struct {
 type NodeType string
 
 const (
-	NodeTypeStart      NodeType = "start"      // Start node
-	NodeTypeEnd        NodeType = "end"        // End node
-	NodeTypeLLM        NodeType = "llm"        // Large language model node
-	NodeTypeCode       NodeType = "code"       // Code execution node
-	NodeTypeCondition  NodeType = "condition"  // Conditional branch node
-	NodeTypeClassifier NodeType = "classifier" // Classification decision node
-	NodeTypeIteration  NodeType = "iteration"  // Iteration node
+	NodeTypeStart             NodeType = "start"              // Start node
+	NodeTypeEnd               NodeType = "end"                // End node
+	NodeTypeLLM               NodeType = "llm"                // Large language model node
+	NodeTypeCode              NodeType = "code"               // Code execution node
+	NodeTypeCondition         NodeType = "condition"          // Conditional branch node
+	NodeTypeClassifier        NodeType = "classifier"         // Classification decision node
+	NodeTypeIteration         NodeType = "iteration"          // Iteration node
+	NodeTypeDataStore         NodeType = "data_store"         // Database / memory read-write node
+	NodeTypeAgent             NodeType = "agent"              // Tool-using agent node
+	NodeTypeHumanInput        NodeType = "human_input"        // Node that asks the user a question and waits for the answer
+	NodeTypeTextToSpeech      NodeType = "text_to_speech"     // Speech synthesis node
+	NodeTypeSpeechToText      NodeType = "speech_to_text"     // Speech recognition node
+	NodeTypeDocumentExtractor NodeType = "document_extractor" // Extracts text from uploaded documents
+	NodeTypeListOperation     NodeType = "list_operation"     // Filters, sorts, slices and maps a list
+	NodeTypeJSONProcess       NodeType = "json_process"       // Serializes to or deserializes from JSON text
 )
 
 // PlatformType represents platform type enumeration
@@ -152,6 +218,9 @@ const (
 	PlatformIFlytek PlatformType = "iflytek" // iFlytek platform
 	PlatformDify    PlatformType = "dify"    // Dify platform
 	PlatformCoze    PlatformType = "coze"    // Coze platform
+
+	// PlatformUnified is the unified DSL itself, exported and imported as an intermediate format
+	PlatformUnified PlatformType = "unified"
 )
 
 // Node represents unified node structure
@@ -165,9 +234,28 @@ type Node struct {
 	Inputs         []Input        `yaml:"inputs" json:"inputs"`
 	Outputs        []Output       `yaml:"outputs" json:"outputs"`
 	Config         NodeConfig     `yaml:"config" json:"config"`
+	ErrorHandling  *ErrorHandling `yaml:"error_handling,omitempty" json:"error_handling,omitempty"` // Nil fails the workflow on the first error
 	PlatformConfig PlatformConfig `yaml:"platform_config" json:"platform_config"`
 }
 
+// ErrorHandling defines what a node does when it fails, after its retries are spent.
+type ErrorHandling struct {
+	Strategy      string                 `yaml:"strategy" json:"strategy"`
+	DefaultValues map[string]interface{} `yaml:"default_values,omitempty" json:"default_values,omitempty"` // Outputs of the default-value strategy
+	MaxRetries    int                    `yaml:"max_retries,omitempty" json:"max_retries,omitempty"`
+	RetryInterval int                    `yaml:"retry_interval_ms,omitempty" json:"retry_interval_ms,omitempty"` // Milliseconds between retries
+}
+
+// Error strategies of nodes, named after Dify
+const (
+	ErrorStrategyFail         = "fail"          // The workflow fails
+	ErrorStrategyDefaultValue = "default-value" // The node outputs its default values
+	ErrorStrategyFailBranch   = "fail-branch"   // The workflow goes on along the edges of ErrorBranchHandle
+)
+
+// ErrorBranchHandle is the source handle of the edges taken when a node fails
+const ErrorBranchHandle = "fail-branch"
+
 // Position represents node position coordinates
 type Position struct {
 	X float64 `yaml:"x" json:"x"`
@@ -212,6 +300,29 @@ type PlatformConfig struct {
 	IFlytek map[string]interface{} `yaml:"iflytek,omitempty" json:"iflytek,omitempty"`
 	Dify    map[string]interface{} `yaml:"dify,omitempty" json:"dify,omitempty"`
 	Coze    map[string]interface{} `yaml:"coze,omitempty" json:"coze,omitempty"`
+
+	// Placeholder marks code nodes the converter generated in place of a node it cannot convert
+	Placeholder *PlaceholderMarker `yaml:"agentbridge_placeholder,omitempty" json:"agentbridge_placeholder,omitempty"`
+
+	// Naming records how the node naming options renamed the node, so that nodes generators add
+	// for it are named alike. It only lives for one conversion and is never written out.
+	Naming *NodeNaming `yaml:"-" json:"-"`
+}
+
+// NodeNaming is the title of a node before the node naming options renamed it, and the options.
+type NodeNaming struct {
+	OriginalTitle string
+	Prefix        string
+	Suffix        string
+	Template      string
+}
+
+// PlaceholderMarker describes the node a code placeholder replaces and the manual step it needs.
+type PlaceholderMarker struct {
+	SourcePlatform PlatformType `yaml:"source,omitempty" json:"source,omitempty"` // Empty for nodes replaced during generation
+	OriginalType   string       `yaml:"type" json:"type"`
+	OriginalID     string       `yaml:"id,omitempty" json:"id,omitempty"`
+	Action         string       `yaml:"action,omitempty" json:"action,omitempty"`
 }
 
 // VariableReference represents variable reference
@@ -242,9 +353,24 @@ type Edge struct {
 	TargetHandle   string         `yaml:"target_handle,omitempty" json:"target_handle,omitempty"`
 	Type           EdgeType       `yaml:"type" json:"type"`
 	Condition      string         `yaml:"condition,omitempty" json:"condition,omitempty"`
+	Style          *EdgeStyle     `yaml:"style,omitempty" json:"style,omitempty"`
 	PlatformConfig PlatformConfig `yaml:"platform_config" json:"platform_config"`
 }
 
+// EdgeStyle describes how an edge is drawn. Empty fields use the target's default look.
+type EdgeStyle struct {
+	Path   string `yaml:"path,omitempty" json:"path,omitempty"`     // Line shape, see EdgePathCurve
+	Color  string `yaml:"color,omitempty" json:"color,omitempty"`   // Line and arrow color
+	Marker string `yaml:"marker,omitempty" json:"marker,omitempty"` // Arrow at the target end, e.g. "arrow"
+	Label  string `yaml:"label,omitempty" json:"label,omitempty"`
+}
+
+// Edge line shapes
+const (
+	EdgePathCurve    = "curve"
+	EdgePathPolyline = "polyline"
+)
+
 // EdgeType represents edge type enumeration
 type EdgeType string
 
@@ -264,9 +390,15 @@ func (c StartConfig) GetNodeType() NodeType {
 	return NodeTypeStart
 }
 
+// End node output modes
+const (
+	EndOutputModeTemplate  = "template"  // The workflow answers with the rendered template (Coze useAnswerContent)
+	EndOutputModeVariables = "variables" // The workflow returns the input variables (Coze returnVariables, Dify)
+)
+
 // EndConfig defines end node configuration
 type EndConfig struct {
-	OutputMode   string      `yaml:"output_mode" json:"output_mode"` // template/variables
+	OutputMode   string      `yaml:"output_mode" json:"output_mode"` // EndOutputModeTemplate or EndOutputModeVariables
 	Template     string      `yaml:"template,omitempty" json:"template,omitempty"`
 	StreamOutput bool        `yaml:"stream_output" json:"stream_output"`
 	Outputs      []EndOutput `yaml:"outputs,omitempty" json:"outputs,omitempty"`
@@ -286,13 +418,15 @@ type EndOutput struct {
 
 // LLMConfig defines large language model node configuration
 type LLMConfig struct {
-	Model         ModelConfig     `yaml:"model" json:"model"`
-	Parameters    ModelParameters `yaml:"parameters" json:"parameters"`
-	Prompt        PromptConfig    `yaml:"prompt" json:"prompt"`
-	Context       *ContextConfig  `yaml:"context,omitempty" json:"context,omitempty"`
-	Vision        *VisionConfig   `yaml:"vision,omitempty" json:"vision,omitempty"`
-	IsInIteration bool            `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
-	IterationID   string          `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+	Model         ModelConfig            `yaml:"model" json:"model"`
+	Parameters    ModelParameters        `yaml:"parameters" json:"parameters"`
+	Prompt        PromptConfig           `yaml:"prompt" json:"prompt"`
+	Context       *ContextConfig         `yaml:"context,omitempty" json:"context,omitempty"`
+	Vision        *VisionConfig          `yaml:"vision,omitempty" json:"vision,omitempty"`
+	Memory        *MemoryConfig          `yaml:"memory,omitempty" json:"memory,omitempty"`
+	OutputSchema  map[string]interface{} `yaml:"output_schema,omitempty" json:"output_schema,omitempty"` // JSON schema of JSON responses
+	IsInIteration bool                   `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
+	IterationID   string                 `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
 }
 
 func (c LLMConfig) GetNodeType() NodeType {
@@ -313,8 +447,20 @@ type ModelParameters struct {
 	TopK           int     `yaml:"top_k,omitempty" json:"top_k,omitempty"`
 	TopP           float64 `yaml:"top_p,omitempty" json:"top_p,omitempty"`
 	ResponseFormat int     `yaml:"response_format,omitempty" json:"response_format,omitempty"` // 0=text, 1=markdown, 2=json
+
+	PresencePenalty  float64  `yaml:"presence_penalty,omitempty" json:"presence_penalty,omitempty"`
+	FrequencyPenalty float64  `yaml:"frequency_penalty,omitempty" json:"frequency_penalty,omitempty"`
+	Stop             []string `yaml:"stop,omitempty" json:"stop,omitempty"` // Stop sequences
+	Seed             int      `yaml:"seed,omitempty" json:"seed,omitempty"`
 }
 
+// Response formats of LLM nodes
+const (
+	ResponseFormatText     = 0
+	ResponseFormatMarkdown = 1
+	ResponseFormatJSON     = 2
+)
+
 // PromptConfig defines prompt configuration
 type PromptConfig struct {
 	SystemTemplate string    `yaml:"system_template,omitempty" json:"system_template,omitempty"`
@@ -336,13 +482,29 @@ type ContextConfig struct {
 
 // VisionConfig defines vision configuration
 type VisionConfig struct {
-	Enabled bool `yaml:"enabled" json:"enabled"`
+	Enabled          bool     `yaml:"enabled" json:"enabled"`
+	VariableSelector []string `yaml:"variable_selector,omitempty" json:"variable_selector,omitempty"` // Images the model sees: node ID and output name
+	Detail           string   `yaml:"detail,omitempty" json:"detail,omitempty"`                       // VisionDetailHigh or VisionDetailLow
 }
 
-// MemoryConfig defines memory configuration
+// Image resolutions of vision models
+const (
+	VisionDetailHigh = "high"
+	VisionDetailLow  = "low"
+)
+
+// MemoryConfig defines the conversation history a model sees
 type MemoryConfig struct {
-	Enabled bool `yaml:"enabled" json:"enabled"`
-	Window  int  `yaml:"window,omitempty" json:"window,omitempty"` // Memory window size
+	Enabled     bool              `yaml:"enabled" json:"enabled"`
+	Window      int               `yaml:"window,omitempty" json:"window,omitempty"`             // Rounds of history, 0 for the whole conversation
+	RolePrefix  *MemoryRolePrefix `yaml:"role_prefix,omitempty" json:"role_prefix,omitempty"`   // Turn prefixes of completion models
+	QueryPrompt string            `yaml:"query_prompt,omitempty" json:"query_prompt,omitempty"` // Template of the current user turn
+}
+
+// MemoryRolePrefix names the speakers of history turns in completion prompts
+type MemoryRolePrefix struct {
+	User      string `yaml:"user" json:"user"`
+	Assistant string `yaml:"assistant" json:"assistant"`
 }
 
 // CodeConfig defines code node configuration
@@ -376,6 +538,10 @@ type ConditionCase struct {
 	Conditions      []Condition `yaml:"conditions" json:"conditions"`
 	LogicalOperator string      `yaml:"logical_operator" json:"logical_operator"` // and/or
 	Level           int         `yaml:"level,omitempty" json:"level,omitempty"`   // Branch level, 999 for default branch
+
+	// Expression nests and/or groups of conditions, e.g. a and (b or c); nil for the flat form of
+	// Conditions joined by LogicalOperator. It replaces Conditions when set.
+	Expression *ConditionExpression `yaml:"expression,omitempty" json:"expression,omitempty"`
 }
 
 // Condition defines condition specification
@@ -388,25 +554,37 @@ type Condition struct {
 
 // ClassifierConfig defines classifier decision node configuration
 type ClassifierConfig struct {
-	Model         ModelConfig       `yaml:"model" json:"model"`
-	Parameters    ModelParameters   `yaml:"parameters" json:"parameters"` // Use unified model parameter structure
-	Classes       []ClassifierClass `yaml:"classes" json:"classes"`
-	QueryVariable string            `yaml:"query_variable" json:"query_variable"`
-	Instructions  string            `yaml:"instructions,omitempty" json:"instructions,omitempty"`
-	IsInIteration bool              `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
-	IterationID   string            `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+	Model         ModelConfig         `yaml:"model" json:"model"`
+	Parameters    ModelParameters     `yaml:"parameters" json:"parameters"` // Use unified model parameter structure
+	Classes       []ClassifierClass   `yaml:"classes" json:"classes"`
+	QueryVariable string              `yaml:"query_variable" json:"query_variable"`
+	Instructions  string              `yaml:"instructions,omitempty" json:"instructions,omitempty"`
+	Examples      []ClassifierExample `yaml:"examples,omitempty" json:"examples,omitempty"` // Few-shot queries, written into the instructions on every platform
+	Mode          string              `yaml:"mode,omitempty" json:"mode,omitempty"`         // Empty for full LLM classification
+	Memory        *MemoryConfig       `yaml:"memory,omitempty" json:"memory,omitempty"`
+	IsInIteration bool                `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
+	IterationID   string              `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
 }
 
 func (c ClassifierConfig) GetNodeType() NodeType {
 	return NodeTypeClassifier
 }
 
+// ClassifierModeFast classifies by class names only, trading accuracy for latency
+const ClassifierModeFast = "fast"
+
 // ClassifierClass represents classification category
 type ClassifierClass struct {
 	ID          string `yaml:"id" json:"id"`
 	Name        string `yaml:"name" json:"name"`
-	Description string `yaml:"description,omitempty" json:"description,omitempty"`
-	IsDefault   bool   `yaml:"is_default,omitempty" json:"is_default,omitempty"` // Indicates if this is the default intent
+	Description string `yaml:"description,omitempty" json:"description,omitempty"` // What the model matches the query against
+	IsDefault   bool   `yaml:"is_default,omitempty" json:"is_default,omitempty"`   // Indicates if this is the default intent
+}
+
+// ClassifierExample is a sample query and the class it belongs to
+type ClassifierExample struct {
+	Query string `yaml:"query" json:"query"`
+	Class string `yaml:"class" json:"class"` // Class ID
 }
 
 // IterationConfig defines iteration node configuration
@@ -432,8 +610,31 @@ type IteratorConfig struct {
 // ExecutionConfig defines execution configuration
 type ExecutionConfig struct {
 	IsParallel      bool   `yaml:"is_parallel" json:"is_parallel"`
-	ParallelNums    int    `yaml:"parallel_nums" json:"parallel_nums"`
-	ErrorHandleMode string `yaml:"error_handle_mode" json:"error_handle_mode"`
+	ParallelNums    int    `yaml:"parallel_nums" json:"parallel_nums"`         // Items run at once when parallel
+	ErrorHandleMode string `yaml:"error_handle_mode" json:"error_handle_mode"` // See ErrorHandleModeTerminated
+}
+
+// Error handling of failing iteration items, named after Dify
+const (
+	ErrorHandleModeTerminated = "terminated"             // Stop the iteration at the first failing item
+	ErrorHandleModeContinue   = "continue-on-error"      // Go on; the failing item outputs null
+	ErrorHandleModeRemove     = "remove-abnormal-output" // Go on and leave failing items out of the output
+)
+
+// DefaultParallelNums is the concurrency of parallel iterations that do not set one
+const DefaultParallelNums = 10
+
+// NormalizeErrorHandleMode returns the error handling mode of mode, which may use older names;
+// empty and unknown modes terminate.
+func NormalizeErrorHandleMode(mode string) string {
+	switch mode {
+	case ErrorHandleModeContinue, "continue":
+		return ErrorHandleModeContinue
+	case ErrorHandleModeRemove:
+		return ErrorHandleModeRemove
+	default:
+		return ErrorHandleModeTerminated
+	}
 }
 
 // SubWorkflowConfig defines sub-workflow configuration
@@ -450,6 +651,216 @@ type OutputSelectorConfig struct {
 	OutputName string `yaml:"output_name" json:"output_name"`
 }
 
+// Agent strategies
+const (
+	AgentStrategyFunctionCalling = "function_calling"
+	AgentStrategyReAct           = "react"
+)
+
+// Agent tool kinds
+const (
+	AgentToolBuiltin   = "builtin"
+	AgentToolPlugin    = "plugin"
+	AgentToolWorkflow  = "workflow"
+	AgentToolKnowledge = "knowledge"
+)
+
+// AgentConfig defines agent node configuration: a model that may call tools in a loop
+type AgentConfig struct {
+	Model         ModelConfig     `yaml:"model" json:"model"`
+	Parameters    ModelParameters `yaml:"parameters" json:"parameters"`
+	Prompt        PromptConfig    `yaml:"prompt" json:"prompt"`
+	Strategy      string          `yaml:"strategy,omitempty" json:"strategy,omitempty"` // function_calling/react
+	Tools         []AgentTool     `yaml:"tools,omitempty" json:"tools,omitempty"`
+	MaxIterations int             `yaml:"max_iterations,omitempty" json:"max_iterations,omitempty"`
+	IsInIteration bool            `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
+	IterationID   string          `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+}
+
+func (c AgentConfig) GetNodeType() NodeType {
+	return NodeTypeAgent
+}
+
+// AgentTool describes a tool available to an agent
+type AgentTool struct {
+	Type        string `yaml:"type" json:"type"` // builtin/plugin/workflow/knowledge
+	Provider    string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// Data store kinds
+const (
+	DataStoreDatabase = "database" // Table based storage
+	DataStoreMemory   = "memory"   // Conversation / user variable storage
+)
+
+// Data store operations
+const (
+	DataStoreOperationQuery  = "query"
+	DataStoreOperationInsert = "insert"
+	DataStoreOperationUpdate = "update"
+	DataStoreOperationDelete = "delete"
+	DataStoreOperationSQL    = "sql"   // Custom SQL statement
+	DataStoreOperationRead   = "read"  // Memory read
+	DataStoreOperationWrite  = "write" // Memory write
+)
+
+// DataStoreConfig defines database / memory node configuration
+type DataStoreConfig struct {
+	Store         string               `yaml:"store" json:"store"`         // database/memory
+	Operation     string               `yaml:"operation" json:"operation"` // query/insert/update/delete/sql/read/write
+	Table         string               `yaml:"table,omitempty" json:"table,omitempty"`
+	SQL           string               `yaml:"sql,omitempty" json:"sql,omitempty"`
+	Fields        []DataStoreField     `yaml:"fields,omitempty" json:"fields,omitempty"` // Selected (query), assigned (insert/update/write) or read fields
+	Conditions    []DataStoreCondition `yaml:"conditions,omitempty" json:"conditions,omitempty"`
+	Logic         string               `yaml:"logic,omitempty" json:"logic,omitempty"` // and/or
+	OrderBy       []DataStoreOrder     `yaml:"order_by,omitempty" json:"order_by,omitempty"`
+	Limit         int                  `yaml:"limit,omitempty" json:"limit,omitempty"`
+	IsInIteration bool                 `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
+	IterationID   string               `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+}
+
+func (c DataStoreConfig) GetNodeType() NodeType {
+	return NodeTypeDataStore
+}
+
+// DataStoreField maps a table field or memory variable to its value
+type DataStoreField struct {
+	Name  string             `yaml:"name" json:"name"`
+	Value *VariableReference `yaml:"value,omitempty" json:"value,omitempty"`
+}
+
+// DataStoreCondition filters the rows a data store operation applies to
+type DataStoreCondition struct {
+	Field    string             `yaml:"field" json:"field"`
+	Operator string             `yaml:"operator" json:"operator"`
+	Value    *VariableReference `yaml:"value,omitempty" json:"value,omitempty"`
+}
+
+// DataStoreOrder defines query result ordering
+type DataStoreOrder struct {
+	Field     string `yaml:"field" json:"field"`
+	Ascending bool   `yaml:"ascending" json:"ascending"`
+}
+
+// Human input answer types
+const (
+	HumanInputAnswerText   = "text"   // Free text answer
+	HumanInputAnswerOption = "option" // Answer picked from options, each option is a branch
+)
+
+// HumanInputConfig defines human input node configuration: a question asked mid-flow
+type HumanInputConfig struct {
+	Question      string             `yaml:"question" json:"question"`
+	AnswerType    string             `yaml:"answer_type" json:"answer_type"` // text/option
+	Options       []HumanInputOption `yaml:"options,omitempty" json:"options,omitempty"`
+	DefaultOption string             `yaml:"default_option,omitempty" json:"default_option,omitempty"` // Branch taken when no option matches
+	MaxRetries    int                `yaml:"max_retries,omitempty" json:"max_retries,omitempty"`
+	IsInIteration bool               `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
+	IterationID   string             `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+}
+
+func (c HumanInputConfig) GetNodeType() NodeType {
+	return NodeTypeHumanInput
+}
+
+// HumanInputOption is an answer option; its ID is the source handle of the option branch
+type HumanInputOption struct {
+	ID   string `yaml:"id" json:"id"`
+	Name string `yaml:"name" json:"name"`
+}
+
+// TextToSpeechConfig defines speech synthesis node configuration
+type TextToSpeechConfig struct {
+	Voice         string `yaml:"voice" json:"voice"`                       // Speaker, e.g. x4_yezi
+	Speed         int    `yaml:"speed,omitempty" json:"speed,omitempty"`   // 0-100, 50 is normal speed
+	Volume        int    `yaml:"volume,omitempty" json:"volume,omitempty"` // 0-100
+	Pitch         int    `yaml:"pitch,omitempty" json:"pitch,omitempty"`   // 0-100
+	Format        string `yaml:"format,omitempty" json:"format,omitempty"` // Audio encoding, e.g. mp3
+	IsInIteration bool   `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
+	IterationID   string `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+}
+
+func (c TextToSpeechConfig) GetNodeType() NodeType {
+	return NodeTypeTextToSpeech
+}
+
+// SpeechToTextConfig defines speech recognition node configuration
+type SpeechToTextConfig struct {
+	Language      string `yaml:"language,omitempty" json:"language,omitempty"` // e.g. zh_cn, en_us
+	Accent        string `yaml:"accent,omitempty" json:"accent,omitempty"`     // e.g. mandarin
+	Format        string `yaml:"format,omitempty" json:"format,omitempty"`     // Audio format, e.g. mp3
+	IsInIteration bool   `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
+	IterationID   string `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+}
+
+func (c SpeechToTextConfig) GetNodeType() NodeType {
+	return NodeTypeSpeechToText
+}
+
+// DocumentExtractorConfig defines document extractor node configuration. The file to read is
+// the first node input; the extracted text is the "text" output.
+type DocumentExtractorConfig struct {
+	IsArrayFile   bool   `yaml:"is_array_file,omitempty" json:"is_array_file,omitempty"` // Input is a file list, output a list of texts
+	IsInIteration bool   `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
+	IterationID   string `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+}
+
+func (c DocumentExtractorConfig) GetNodeType() NodeType {
+	return NodeTypeDocumentExtractor
+}
+
+// ListOperationConfig defines list operation node configuration. The list is the first node input;
+// the operations apply in the order filter, extract, sort, limit, map and the node outputs
+// "result", "first_record" and "last_record".
+type ListOperationConfig struct {
+	ItemType      UnifiedDataType       `yaml:"item_type" json:"item_type"`                             // Element type of the input list
+	Filters       []ListFilterCondition `yaml:"filters,omitempty" json:"filters,omitempty"`             // Items must match every condition
+	ExtractIndex  int                   `yaml:"extract_index,omitempty" json:"extract_index,omitempty"` // 1-based item kept as a one-item list, 0 keeps all
+	SortBy        *ListSort             `yaml:"sort_by,omitempty" json:"sort_by,omitempty"`
+	Limit         int                   `yaml:"limit,omitempty" json:"limit,omitempty"`         // Keeps the first N items, 0 keeps all
+	MapField      string                `yaml:"map_field,omitempty" json:"map_field,omitempty"` // Replaces object items with this field
+	IsInIteration bool                  `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
+	IterationID   string                `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+}
+
+// ListFilterCondition compares an item, or one field of object items, with a value.
+// Operators use the condition node vocabulary plus "in" / "not_in".
+type ListFilterCondition struct {
+	Key      string      `yaml:"key,omitempty" json:"key,omitempty"` // Empty compares the item itself
+	Operator string      `yaml:"operator" json:"operator"`
+	Value    interface{} `yaml:"value,omitempty" json:"value,omitempty"`
+}
+
+// ListSort orders items by themselves or by one field of object items
+type ListSort struct {
+	Key       string `yaml:"key,omitempty" json:"key,omitempty"`
+	Ascending bool   `yaml:"ascending" json:"ascending"`
+}
+
+func (c ListOperationConfig) GetNodeType() NodeType {
+	return NodeTypeListOperation
+}
+
+// JSON process operations
+const (
+	JSONOperationSerialize   = "serialize"   // Value input -> JSON text output
+	JSONOperationDeserialize = "deserialize" // JSON text input -> typed value output
+)
+
+// JSONProcessConfig defines JSON serialization node configuration. The value is the first node
+// input and the result the first node output.
+type JSONProcessConfig struct {
+	Operation     string `yaml:"operation" json:"operation"`
+	IsInIteration bool   `yaml:"is_in_iteration,omitempty" json:"is_in_iteration,omitempty"`
+	IterationID   string `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+}
+
+func (c JSONProcessConfig) GetNodeType() NodeType {
+	return NodeTypeJSONProcess
+}
+
 func NewUnifiedDSL() *UnifiedDSL {
 	return &UnifiedDSL{
 		Version: "1.0.0",
@@ -495,9 +906,9 @@ func NewEdge(id, source, target string) *Edge {
 	}
 }
 
-// IsValidNodeType checks if the node type is valid
-func IsValidNodeType(nodeType NodeType) bool {
-	validTypes := []NodeType{
+// NodeTypes returns every unified node type
+func NodeTypes() []NodeType {
+	return []NodeType{
 		NodeTypeStart,
 		NodeTypeEnd,
 		NodeTypeLLM,
@@ -505,9 +916,20 @@ func IsValidNodeType(nodeType NodeType) bool {
 		NodeTypeCondition,
 		NodeTypeClassifier,
 		NodeTypeIteration,
+		NodeTypeDataStore,
+		NodeTypeAgent,
+		NodeTypeHumanInput,
+		NodeTypeTextToSpeech,
+		NodeTypeSpeechToText,
+		NodeTypeDocumentExtractor,
+		NodeTypeListOperation,
+		NodeTypeJSONProcess,
 	}
+}
 
-	for _, validType := range validTypes {
+// IsValidNodeType checks if the node type is valid
+func IsValidNodeType(nodeType NodeType) bool {
+	for _, validType := range NodeTypes() {
 		if nodeType == validType {
 			return true
 		}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// nodeConfigPrototypes holds an empty config of every node type, stored the way parsers store
+// it: generators type-assert iteration, classifier and condition configs as pointers and the
+// others as values, so decoded configs must keep that form.
+var nodeConfigPrototypes = map[NodeType]NodeConfig{
+	NodeTypeStart:             StartConfig{},
+	NodeTypeEnd:               EndConfig{},
+	NodeTypeLLM:               LLMConfig{},
+	NodeTypeCode:              CodeConfig{},
+	NodeTypeCondition:         &ConditionConfig{},
+	NodeTypeClassifier:        &ClassifierConfig{},
+	NodeTypeIteration:         &IterationConfig{},
+	NodeTypeDataStore:         DataStoreConfig{},
+	NodeTypeAgent:             AgentConfig{},
+	NodeTypeHumanInput:        HumanInputConfig{},
+	NodeTypeTextToSpeech:      TextToSpeechConfig{},
+	NodeTypeSpeechToText:      SpeechToTextConfig{},
+	NodeTypeDocumentExtractor: DocumentExtractorConfig{},
+	NodeTypeListOperation:     ListOperationConfig{},
+	NodeTypeJSONProcess:       JSONProcessConfig{},
+}
+
+// NodeConfigType returns the struct type of the config of a node type.
+func NodeConfigType(nodeType NodeType) (reflect.Type, bool) {
+	prototype, ok := nodeConfigPrototypes[nodeType]
+	if !ok {
+		return nil, false
+	}
+	configType := reflect.TypeOf(prototype)
+	if configType.Kind() == reflect.Ptr {
+		configType = configType.Elem()
+	}
+	return configType, true
+}
+
+// UnmarshalYAML decodes a node, choosing the config type from the node type, so that a unified
+// DSL exported as YAML can be read back.
+func (n *Node) UnmarshalYAML(value *yaml.Node) error {
+	// plainNode has the fields of Node without this method
+	type plainNode Node
+
+	fields := *value
+	var configValue *yaml.Node
+	if value.Kind == yaml.MappingNode {
+		fields.Content = nil
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if value.Content[i].Value == "config" {
+				configValue = value.Content[i+1]
+				continue
+			}
+			fields.Content = append(fields.Content, value.Content[i], value.Content[i+1])
+		}
+	}
+
+	var node plainNode
+	if err := fields.Decode(&node); err != nil {
+		return err
+	}
+	*n = Node(node)
+
+	if configValue == nil || configValue.Tag == "!!null" {
+		return nil
+	}
+	prototype, ok := nodeConfigPrototypes[n.Type]
+	if !ok {
+		return fmt.Errorf("node %s: unknown node type %q", n.ID, n.Type)
+	}
+	config := reflect.New(reflect.TypeOf(prototype))
+	if configType := reflect.TypeOf(prototype); configType.Kind() == reflect.Ptr {
+		config.Elem().Set(reflect.New(configType.Elem()))
+		if err := configValue.Decode(config.Elem().Interface()); err != nil {
+			return fmt.Errorf("node %s: config: %w", n.ID, err)
+		}
+	} else if err := configValue.Decode(config.Interface()); err != nil {
+		return fmt.Errorf("node %s: config: %w", n.ID, err)
+	}
+	n.Config = config.Elem().Interface().(NodeConfig)
+	return nil
+}
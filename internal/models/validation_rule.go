@@ -0,0 +1,158 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationStage is a stage of the validation pipeline of a conversion.
+type ValidationStage string
+
+const (
+	// ValidationStageStructure validates the workflow as parsed: metadata, nodes and edges
+	ValidationStageStructure ValidationStage = "structure"
+	// ValidationStageSemantic validates the meaning of the parsed workflow, such as start and end
+	// nodes and references, once its structure passed
+	ValidationStageSemantic ValidationStage = "semantic"
+	// ValidationStagePlatform validates the nodes as they will be generated for the target platform
+	ValidationStagePlatform ValidationStage = "platform"
+)
+
+// ValidationContext describes the conversion a validator runs in.
+type ValidationContext struct {
+	Stage          ValidationStage
+	SourcePlatform PlatformType
+	TargetPlatform PlatformType
+}
+
+// ValidationIssue is a problem a validator found in a workflow.
+type ValidationIssue struct {
+	Severity ErrorSeverity // SeverityError fails the conversion, SeverityWarning becomes a warning
+	NodeID   string        // Offending node, empty for workflow-wide issues
+	Message  string
+}
+
+// Validator is a custom validation rule. Validators of a stage run after the built-in checks of
+// that stage passed; iteration sub-workflows are left to the validator.
+type Validator interface {
+	// Name identifies the rule in reports
+	Name() string
+	// Stage selects the stage the validator runs in
+	Stage() ValidationStage
+	// Validate returns the problems of the workflow, none when it passes
+	Validate(ctx ValidationContext, unifiedDSL *UnifiedDSL) []ValidationIssue
+}
+
+// ValidatorFunc adapts a function to Validator.
+type ValidatorFunc struct {
+	RuleName  string
+	RuleStage ValidationStage // Empty runs in ValidationStageSemantic
+	Check     func(ctx ValidationContext, unifiedDSL *UnifiedDSL) []ValidationIssue
+}
+
+// Name returns RuleName.
+func (v ValidatorFunc) Name() string {
+	return v.RuleName
+}
+
+// Stage returns RuleStage, defaulting to ValidationStageSemantic.
+func (v ValidatorFunc) Stage() ValidationStage {
+	if v.RuleStage == "" {
+		return ValidationStageSemantic
+	}
+	return v.RuleStage
+}
+
+// Validate calls Check.
+func (v ValidatorFunc) Validate(ctx ValidationContext, unifiedDSL *UnifiedDSL) []ValidationIssue {
+	return v.Check(ctx, unifiedDSL)
+}
+
+// ValidationRules is a rules file of declarative validators, for teams that extend validation
+// without writing Go.
+type ValidationRules struct {
+	Rules []ValidationRule `yaml:"rules" json:"rules"`
+}
+
+// ValidationRule is one declarative validator. Each rule sets exactly one constraint; NodeType
+// narrows the nodes node constraints apply to.
+type ValidationRule struct {
+	Name     string          `yaml:"name" json:"name"`
+	Stage    ValidationStage `yaml:"stage,omitempty" json:"stage,omitempty"`       // Default semantic
+	Severity ErrorSeverity   `yaml:"severity,omitempty" json:"severity,omitempty"` // error (default) or warning
+	NodeType NodeType        `yaml:"node_type,omitempty" json:"node_type,omitempty"`
+
+	// Constraints
+	RequireSystemPrompt bool   `yaml:"require_system_prompt,omitempty" json:"require_system_prompt,omitempty"` // LLM nodes have a system prompt
+	RequireEndOutput    string `yaml:"require_end_output,omitempty" json:"require_end_output,omitempty"`       // Some end node returns an output of this name
+	RequireDescription  bool   `yaml:"require_description,omitempty" json:"require_description,omitempty"`     // Nodes have a description
+	TitlePattern        string `yaml:"title_pattern,omitempty" json:"title_pattern,omitempty"`                 // Node titles match this regular expression
+	MaxNodes            int    `yaml:"max_nodes,omitempty" json:"max_nodes,omitempty"`                         // Top-level node count limit
+}
+
+// ParseValidationRules parses a YAML rules file, rejecting unknown keys and invalid rules.
+func ParseValidationRules(data []byte) (*ValidationRules, error) {
+	rules := &ValidationRules{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(rules); err != nil {
+		return nil, fmt.Errorf("invalid validation rules: %w", err)
+	}
+	for i, rule := range rules.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("invalid validation rule %d (%s): %w", i+1, rule.Name, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r ValidationRule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("a rule needs a name")
+	}
+	switch r.Stage {
+	case "", ValidationStageStructure, ValidationStageSemantic, ValidationStagePlatform:
+	default:
+		return fmt.Errorf("invalid stage %q (expected %s|%s|%s)", r.Stage, ValidationStageStructure, ValidationStageSemantic, ValidationStagePlatform)
+	}
+	switch r.Severity {
+	case "", SeverityError, SeverityWarning:
+	default:
+		return fmt.Errorf("invalid severity %q (expected %s|%s)", r.Severity, SeverityError, SeverityWarning)
+	}
+
+	constraints := 0
+	for _, set := range []bool{r.RequireSystemPrompt, r.RequireEndOutput != "", r.RequireDescription, r.TitlePattern != "", r.MaxNodes > 0} {
+		if set {
+			constraints++
+		}
+	}
+	if constraints != 1 {
+		return fmt.Errorf("a rule needs exactly one constraint, found %d", constraints)
+	}
+	if r.TitlePattern != "" {
+		if _, err := regexp.Compile(r.TitlePattern); err != nil {
+			return fmt.Errorf("invalid title pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// EffectiveStage returns the stage of the rule, defaulting to semantic.
+func (r ValidationRule) EffectiveStage() ValidationStage {
+	if r.Stage == "" {
+		return ValidationStageSemantic
+	}
+	return r.Stage
+}
+
+// EffectiveSeverity returns the severity of the rule, defaulting to error.
+func (r ValidationRule) EffectiveSeverity() ErrorSeverity {
+	if r.Severity == "" {
+		return SeverityError
+	}
+	return r.Severity
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	agentbridgev1 "github.com/iflytek/agentbridge/proto/agentbridge/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConvertService implements the gRPC ConvertService of proto/agentbridge/v1.
+type ConvertService struct {
+	agentbridgev1.UnimplementedConvertServiceServer
+
+	service *services.ConversionService
+	config  Config
+}
+
+// NewGRPC creates a gRPC server exposing ConvertService. UI and MaxUploadBytes of config are
+// not used; message sizes are bounded by the gRPC server options.
+func NewGRPC(service *services.ConversionService, config Config, opts ...grpc.ServerOption) *grpc.Server {
+	grpcServer := grpc.NewServer(opts...)
+	agentbridgev1.RegisterConvertServiceServer(grpcServer, &ConvertService{service: service, config: config})
+	return grpcServer
+}
+
+// Convert implements agentbridgev1.ConvertServiceServer.
+func (s *ConvertService) Convert(ctx context.Context, request *agentbridgev1.ConvertRequest) (*agentbridgev1.ConvertResponse, error) {
+	return s.convert(ctx, request, nil)
+}
+
+// ConvertStream implements agentbridgev1.ConvertServiceServer.
+func (s *ConvertService) ConvertStream(request *agentbridgev1.ConvertRequest, stream agentbridgev1.ConvertService_ConvertStreamServer) error {
+	// Progress is reported from the converting goroutine, so sends never overlap
+	var sendErr error
+	progress := models.ProgressFunc(func(event models.ProgressEvent) {
+		if sendErr != nil {
+			return
+		}
+		sendErr = stream.Send(&agentbridgev1.ConvertEvent{Event: &agentbridgev1.ConvertEvent_Progress{Progress: &agentbridgev1.Progress{
+			Stage:     string(event.Stage),
+			Percent:   int32(event.Percent),
+			NodeId:    event.NodeID,
+			NodeTitle: event.NodeTitle,
+		}}})
+	})
+
+	response, err := s.convert(stream.Context(), request, progress)
+	if err != nil {
+		return err
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	return stream.Send(&agentbridgev1.ConvertEvent{Event: &agentbridgev1.ConvertEvent_Result{Result: response}})
+}
+
+// Parse implements agentbridgev1.ConvertServiceServer.
+func (s *ConvertService) Parse(ctx context.Context, request *agentbridgev1.ParseRequest) (*agentbridgev1.ParseResponse, error) {
+	platform, err := sourcePlatform(request.GetSource(), request.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+	unifiedDSL, err := s.service.Parse(request.GetSource(), platform)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse %s DSL: %v", platform, err)
+	}
+	dsl, err := UnifiedDSLToProto(unifiedDSL)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &agentbridgev1.ParseResponse{Platform: string(platform), Dsl: dsl}, nil
+}
+
+func (s *ConvertService) convert(ctx context.Context, request *agentbridgev1.ConvertRequest, progress models.ProgressReporter) (*agentbridgev1.ConvertResponse, error) {
+	platform, err := sourcePlatform(request.GetSource(), request.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+	target := models.PlatformType(request.GetTo())
+	if !isPlatform(target) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported target platform %q", target)
+	}
+	options := s.requestOptions(request.GetOptions())
+	options.Progress = progress
+
+	if s.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancel()
+	}
+	result, err := s.service.ConvertWithResult(ctx, request.GetSource(), platform, target, options)
+	if err != nil {
+		return nil, conversionStatus(err)
+	}
+	return &agentbridgev1.ConvertResponse{
+		Output:       result.Output,
+		Source:       string(platform),
+		Target:       string(target),
+		Warnings:     result.Warnings,
+		Placeholders: int32(result.Placeholders),
+		NodeMapping:  result.NodeMapping,
+	}, nil
+}
+
+// requestOptions returns the configured options with the fields set in the request applied
+func (s *ConvertService) requestOptions(requested *agentbridgev1.ConversionOptions) *models.ConversionOptions {
+	options := models.NewConversionOptions()
+	if s.config.Options != nil {
+		copied := *s.config.Options
+		options = &copied
+	}
+	if requested == nil {
+		return options
+	}
+
+	for _, field := range []struct {
+		value  string
+		target *string
+	}{
+		{requested.TargetVersion, &options.TargetVersion},
+		{requested.TitlePrefix, &options.TitlePrefix},
+		{requested.TitleSuffix, &options.TitleSuffix},
+		{requested.PlaceholderStrategy, &options.PlaceholderStrategy},
+		{requested.AudioStrategy, &options.AudioStrategy},
+		{requested.TriggerStrategy, &options.TriggerStrategy},
+		{requested.DefaultIntent, &options.DefaultIntent},
+		{requested.OutputFormat, &options.OutputFormat},
+		{requested.BumpVersion, &options.BumpVersion},
+	} {
+		if field.value != "" {
+			*field.target = field.value
+		}
+	}
+	if requested.KeepTitles != nil {
+		options.KeepTitles = requested.GetKeepTitles()
+	}
+	if requested.Anonymize != nil {
+		options.Anonymize = requested.GetAnonymize()
+	}
+	if requested.Minify != nil {
+		options.Minify = requested.GetMinify()
+	}
+	if requested.Touch != nil {
+		options.Touch = requested.GetTouch()
+	}
+	return options
+}
+
+// sourcePlatform returns the named platform, or the detected one when from is empty
+func sourcePlatform(data []byte, from string) (models.PlatformType, error) {
+	platform := models.PlatformType(from)
+	if platform == "" {
+		detection, err := common.DetectPlatform(data)
+		if err != nil {
+			return "", status.Error(codes.InvalidArgument, err.Error())
+		}
+		platform = detection.Platform
+	}
+	if !isPlatform(platform) {
+		return "", status.Errorf(codes.InvalidArgument, "unsupported source platform %q", platform)
+	}
+	return platform, nil
+}
+
+// conversionStatus maps conversion errors to gRPC status codes
+func conversionStatus(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, fmt.Sprintf("conversion failed: %v", err))
+}
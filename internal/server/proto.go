@@ -0,0 +1,217 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	agentbridgev1 "github.com/iflytek/agentbridge/proto/agentbridge/v1"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UnifiedDSLToProto returns the protobuf form of a unified DSL. Node configs, error handling,
+// features and platform data become Structs holding their JSON form.
+func UnifiedDSLToProto(unifiedDSL *models.UnifiedDSL) (*agentbridgev1.UnifiedDSL, error) {
+	platformMetadata, err := toStruct(unifiedDSL.PlatformMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("platform metadata: %w", err)
+	}
+	features, err := toStruct(unifiedDSL.Workflow.Features)
+	if err != nil {
+		return nil, fmt.Errorf("features: %w", err)
+	}
+
+	dsl := &agentbridgev1.UnifiedDSL{
+		Version:          unifiedDSL.Version,
+		Metadata:         metadataToProto(unifiedDSL.Metadata),
+		PlatformMetadata: platformMetadata,
+		Workflow:         &agentbridgev1.Workflow{Features: features},
+	}
+	for i := range unifiedDSL.Workflow.Nodes {
+		node, err := nodeToProto(&unifiedDSL.Workflow.Nodes[i])
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", unifiedDSL.Workflow.Nodes[i].ID, err)
+		}
+		dsl.Workflow.Nodes = append(dsl.Workflow.Nodes, node)
+	}
+	for _, edge := range unifiedDSL.Workflow.Edges {
+		dsl.Workflow.Edges = append(dsl.Workflow.Edges, &agentbridgev1.Edge{
+			Id:           edge.ID,
+			Source:       edge.Source,
+			Target:       edge.Target,
+			SourceHandle: edge.SourceHandle,
+			TargetHandle: edge.TargetHandle,
+			Type:         string(edge.Type),
+			Condition:    edge.Condition,
+		})
+	}
+	for _, trigger := range unifiedDSL.Workflow.Triggers {
+		inputs, err := toStruct(trigger.Inputs)
+		if err != nil {
+			return nil, fmt.Errorf("trigger %s: %w", trigger.ID, err)
+		}
+		dsl.Workflow.Triggers = append(dsl.Workflow.Triggers, &agentbridgev1.Trigger{
+			Id:       trigger.ID,
+			Title:    trigger.Title,
+			Type:     string(trigger.Type),
+			Cron:     trigger.Cron,
+			Timezone: trigger.Timezone,
+			Event:    trigger.Event,
+			Inputs:   inputs,
+		})
+	}
+	for _, variable := range unifiedDSL.Workflow.Variables {
+		defaultValue, err := toValue(variable.Default)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", variable.Name, err)
+		}
+		dsl.Workflow.Variables = append(dsl.Workflow.Variables, &agentbridgev1.Variable{
+			Name:        variable.Name,
+			Label:       variable.Label,
+			Type:        variable.Type,
+			Required:    variable.Required,
+			Default:     defaultValue,
+			Description: variable.Description,
+		})
+	}
+	return dsl, nil
+}
+
+func metadataToProto(metadata models.Metadata) *agentbridgev1.Metadata {
+	result := &agentbridgev1.Metadata{
+		Name:        metadata.Name,
+		Description: metadata.Description,
+		Tags:        metadata.Tags,
+		Author:      metadata.Author,
+		Version:     metadata.Version,
+	}
+	if !metadata.CreatedAt.IsZero() {
+		result.CreatedAt = timestamppb.New(metadata.CreatedAt)
+	}
+	if !metadata.UpdatedAt.IsZero() {
+		result.UpdatedAt = timestamppb.New(metadata.UpdatedAt)
+	}
+	if ui := metadata.UIConfig; ui != nil {
+		result.UiConfig = &agentbridgev1.UIConfig{
+			OpeningStatement:   ui.OpeningStatement,
+			SuggestedQuestions: ui.SuggestedQuestions,
+			Icon:               ui.Icon,
+			IconBackground:     ui.IconBackground,
+		}
+	}
+	return result
+}
+
+func nodeToProto(node *models.Node) (*agentbridgev1.Node, error) {
+	config, err := toStruct(node.Config)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	errorHandling, err := toStruct(node.ErrorHandling)
+	if err != nil {
+		return nil, fmt.Errorf("error handling: %w", err)
+	}
+	platformConfig, err := toStruct(node.PlatformConfig)
+	if err != nil {
+		return nil, fmt.Errorf("platform config: %w", err)
+	}
+
+	result := &agentbridgev1.Node{
+		Id:             node.ID,
+		Type:           string(node.Type),
+		Title:          node.Title,
+		Description:    node.Description,
+		Position:       &agentbridgev1.Position{X: node.Position.X, Y: node.Position.Y},
+		Size:           &agentbridgev1.Size{Width: node.Size.Width, Height: node.Size.Height},
+		Config:         config,
+		ErrorHandling:  errorHandling,
+		PlatformConfig: platformConfig,
+	}
+	for _, input := range node.Inputs {
+		defaultValue, err := toValue(input.Default)
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %w", input.Name, err)
+		}
+		reference, err := referenceToProto(input.Reference)
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %w", input.Name, err)
+		}
+		result.Inputs = append(result.Inputs, &agentbridgev1.Input{
+			Name:        input.Name,
+			Label:       input.Label,
+			Type:        string(input.Type),
+			Required:    input.Required,
+			Default:     defaultValue,
+			Description: input.Description,
+			Reference:   reference,
+		})
+	}
+	for _, output := range node.Outputs {
+		defaultValue, err := toValue(output.Default)
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %w", output.Name, err)
+		}
+		result.Outputs = append(result.Outputs, &agentbridgev1.Output{
+			Name:        output.Name,
+			Label:       output.Label,
+			Type:        string(output.Type),
+			Required:    output.Required,
+			Description: output.Description,
+			Default:     defaultValue,
+		})
+	}
+	return result, nil
+}
+
+func referenceToProto(reference *models.VariableReference) (*agentbridgev1.VariableReference, error) {
+	if reference == nil {
+		return nil, nil
+	}
+	value, err := toValue(reference.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &agentbridgev1.VariableReference{
+		Type:       string(reference.Type),
+		NodeId:     reference.NodeID,
+		OutputName: reference.OutputName,
+		DataType:   string(reference.DataType),
+		Value:      value,
+		Template:   reference.Template,
+	}, nil
+}
+
+// toStruct returns the JSON form of v as a Struct, or nil when v encodes to null
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	var fields map[string]interface{}
+	if err := jsonRoundTrip(v, &fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, nil
+	}
+	return structpb.NewStruct(fields)
+}
+
+// toValue returns the JSON form of v as a Value, or nil for nil
+func toValue(v interface{}) (*structpb.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var value interface{}
+	if err := jsonRoundTrip(v, &value); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(value)
+}
+
+// jsonRoundTrip decodes the JSON encoding of v into target, leaving only the types Structs hold
+func jsonRoundTrip(v interface{}, target interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
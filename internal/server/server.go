@@ -0,0 +1,337 @@
+// Package server exposes the conversion service over HTTP for the web playground and other
+// clients that cannot link the Go library.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// DefaultMaxUploadBytes bounds the DSL files the server accepts
+const DefaultMaxUploadBytes = 32 << 20
+
+// Config configures the HTTP server.
+type Config struct {
+	// Options are the conversion defaults; form fields of a request override them
+	Options *models.ConversionOptions
+	// UI holds the static files of the web playground; nil serves the API only
+	UI fs.FS
+	// Timeout bounds each conversion, zero leaves it unbounded
+	Timeout time.Duration
+	// MaxUploadBytes bounds uploaded files, zero keeps DefaultMaxUploadBytes
+	MaxUploadBytes int64
+}
+
+// Server handles the HTTP API:
+//
+//	POST /api/parse    parses the uploaded file and returns its graph
+//	POST /api/convert  converts the uploaded file; with Accept: text/event-stream the progress
+//	                   is streamed as server-sent events before a final "result" event
+//
+// Files are uploaded as the "file" field of a multipart form, with "from", "to" and option fields.
+type Server struct {
+	service *services.ConversionService
+	config  Config
+	mux     *http.ServeMux
+}
+
+// New creates a server converting with service.
+func New(service *services.ConversionService, config Config) *Server {
+	if config.MaxUploadBytes <= 0 {
+		config.MaxUploadBytes = DefaultMaxUploadBytes
+	}
+	s := &Server{service: service, config: config, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/api/parse", s.handleParse)
+	s.mux.HandleFunc("/api/convert", s.handleConvert)
+	if config.UI != nil {
+		s.mux.Handle("/", http.FileServer(http.FS(config.UI)))
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// GraphNode is a node of the parsed graph. Parent is the iteration holding the node, whose
+// position is relative to the iteration.
+type GraphNode struct {
+	ID     string  `json:"id"`
+	Title  string  `json:"title"`
+	Type   string  `json:"type"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Parent string  `json:"parent,omitempty"`
+}
+
+// GraphEdge is an edge of the parsed graph.
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Handle string `json:"handle,omitempty"`
+}
+
+// ParseResponse is the answer of /api/parse.
+type ParseResponse struct {
+	Platform models.PlatformType `json:"platform"`
+	Name     string              `json:"name"`
+	Nodes    []GraphNode         `json:"nodes"`
+	Edges    []GraphEdge         `json:"edges"`
+}
+
+// ConvertResponse is the answer of /api/convert.
+type ConvertResponse struct {
+	Filename     string              `json:"filename"`
+	Output       string              `json:"output"`
+	Source       models.PlatformType `json:"source"`
+	Target       models.PlatformType `json:"target"`
+	Warnings     []string            `json:"warnings"`
+	Placeholders int                 `json:"placeholders"`
+}
+
+// errorResponse is the body of failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleParse(w http.ResponseWriter, r *http.Request) {
+	data, platform, ok := s.readUpload(w, r)
+	if !ok {
+		return
+	}
+	unifiedDSL, err := s.service.Parse(data, platform)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("failed to parse %s DSL: %w", platform, err))
+		return
+	}
+	writeJSON(w, http.StatusOK, buildGraph(platform, unifiedDSL))
+}
+
+func (s *Server) handleConvert(w http.ResponseWriter, r *http.Request) {
+	data, platform, ok := s.readUpload(w, r)
+	if !ok {
+		return
+	}
+	target := models.PlatformType(r.FormValue("to"))
+	if !isPlatform(target) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported target platform %q", target))
+		return
+	}
+	options, err := s.requestOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	if s.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancel()
+	}
+
+	var stream *services.SSEProgressReporter
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		stream = services.NewSSEProgressReporter(w)
+		options.Progress = stream
+	}
+
+	result, err := s.service.ConvertWithResult(ctx, data, platform, target, options)
+	if err != nil {
+		if stream != nil {
+			writeEvent(w, "error", errorResponse{Error: err.Error()})
+			return
+		}
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	response := ConvertResponse{
+		Filename:     outputFilename(r, target, options.OutputFormat),
+		Output:       string(result.Output),
+		Source:       platform,
+		Target:       target,
+		Warnings:     append([]string{}, result.Warnings...),
+		Placeholders: result.Placeholders,
+	}
+	if stream != nil {
+		writeEvent(w, "result", response)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// readUpload reads the uploaded file and its platform, detecting the platform when the request
+// names none. It answers the request itself when it fails.
+func (s *Server) readUpload(w http.ResponseWriter, r *http.Request) ([]byte, models.PlatformType, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return nil, "", false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxUploadBytes)
+	if err := r.ParseMultipartForm(s.config.MaxUploadBytes); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to read form: %w", err))
+		return nil, "", false
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing file: %w", err))
+		return nil, "", false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to read file: %w", err))
+		return nil, "", false
+	}
+
+	platform := models.PlatformType(r.FormValue("from"))
+	if platform == "" {
+		detection, err := common.DetectPlatform(data)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return nil, "", false
+		}
+		platform = detection.Platform
+	}
+	if !isPlatform(platform) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported source platform %q", platform))
+		return nil, "", false
+	}
+	return data, platform, true
+}
+
+// requestOptions returns the configured options with the option fields of the request applied
+func (s *Server) requestOptions(r *http.Request) (*models.ConversionOptions, error) {
+	options := models.NewConversionOptions()
+	if s.config.Options != nil {
+		copied := *s.config.Options
+		options = &copied
+	}
+
+	for field, target := range map[string]*string{
+		"target_version":       &options.TargetVersion,
+		"title_prefix":         &options.TitlePrefix,
+		"title_suffix":         &options.TitleSuffix,
+		"placeholder_strategy": &options.PlaceholderStrategy,
+		"audio_strategy":       &options.AudioStrategy,
+		"trigger_strategy":     &options.TriggerStrategy,
+		"default_intent":       &options.DefaultIntent,
+		"output_format":        &options.OutputFormat,
+		"bump_version":         &options.BumpVersion,
+	} {
+		if value := r.FormValue(field); value != "" {
+			*target = value
+		}
+	}
+	for field, target := range map[string]*bool{
+		"keep_titles": &options.KeepTitles,
+		"anonymize":   &options.Anonymize,
+		"minify":      &options.Minify,
+		"touch":       &options.Touch,
+	} {
+		value := r.FormValue(field)
+		if value == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", field, value, err)
+		}
+		*target = enabled
+	}
+	return options, nil
+}
+
+// buildGraph lists the nodes and edges of a workflow, iteration bodies included
+func buildGraph(platform models.PlatformType, unifiedDSL *models.UnifiedDSL) ParseResponse {
+	graph := ParseResponse{Platform: platform, Name: unifiedDSL.Metadata.Name, Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	addNodes := func(nodes []*models.Node, edges []models.Edge, parent string) {
+		for _, node := range nodes {
+			graph.Nodes = append(graph.Nodes, GraphNode{
+				ID:     node.ID,
+				Title:  node.Title,
+				Type:   string(node.Type),
+				X:      node.Position.X,
+				Y:      node.Position.Y,
+				Parent: parent,
+			})
+		}
+		for _, edge := range edges {
+			graph.Edges = append(graph.Edges, GraphEdge{Source: edge.Source, Target: edge.Target, Handle: edge.SourceHandle})
+		}
+	}
+
+	nodes, edges := common.TopLevelNodes(unifiedDSL)
+	addNodes(nodes, edges, "")
+	for _, node := range nodes {
+		if node.Type == models.NodeTypeIteration {
+			body, bodyEdges := common.IterationBody(unifiedDSL, node)
+			addNodes(body, bodyEdges, node.ID)
+		}
+	}
+	return graph
+}
+
+// outputFilename names the converted file after the uploaded one
+func outputFilename(r *http.Request, target models.PlatformType, outputFormat string) string {
+	name := "workflow"
+	if _, header, err := r.FormFile("file"); err == nil && header.Filename != "" {
+		name = header.Filename
+		if dot := strings.LastIndex(name, "."); dot > 0 {
+			name = name[:dot]
+		}
+	}
+	extension := ".yml"
+	if outputFormat == models.OutputFormatJSON {
+		extension = ".json"
+	}
+	return fmt.Sprintf("%s.%s%s", name, target, extension)
+}
+
+func isPlatform(platform models.PlatformType) bool {
+	switch platform {
+	case models.PlatformIFlytek, models.PlatformDify, models.PlatformCoze, models.PlatformUnified:
+		return true
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// writeEvent writes a server-sent event after the progress events of a streamed conversion
+func writeEvent(w http.ResponseWriter, event string, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse{Error: err.Error()})
+		event = "error"
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,217 @@
+// Package signing computes checksums and detached signatures of generated DSL files.
+//
+// Signatures are compatible with OpenSSL: RSA and ECDSA keys sign the SHA-256 digest of the file
+// (openssl dgst -sha256 -sign key.pem), Ed25519 keys sign the file itself
+// (openssl pkeyutl -sign -rawin).
+package signing
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// File suffixes of the artifacts written next to a generated file
+const (
+	ChecksumSuffix  = ".sha256"
+	SignatureSuffix = ".sig"
+)
+
+// ErrChecksumMismatch is returned when a file does not match its recorded checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ErrInvalidSignature is returned when a signature was not made over a file with the given key.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Checksum returns the hex encoded SHA-256 digest of data.
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// FormatChecksumFile returns a checksum file in the sha256sum format, so that
+// `sha256sum -c` can check it as well.
+func FormatChecksumFile(data []byte, fileName string) []byte {
+	return []byte(fmt.Sprintf("%s  %s\n", Checksum(data), fileName))
+}
+
+// ParseChecksumFile returns the checksum recorded for fileName. A file with a single entry
+// is accepted whatever name it records.
+func ParseChecksumFile(content []byte, fileName string) (string, error) {
+	var entries [][2]string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		sum, name, _ := strings.Cut(line, " ")
+		// sha256sum marks binary mode with '*'
+		name = strings.TrimPrefix(strings.TrimSpace(name), "*")
+		if len(sum) != sha256.Size*2 {
+			return "", fmt.Errorf("malformed checksum line %q", line)
+		}
+		entries = append(entries, [2]string{strings.ToLower(sum), name})
+	}
+
+	if len(entries) == 1 {
+		return entries[0][0], nil
+	}
+	for _, entry := range entries {
+		if entry[1] == fileName {
+			return entry[0], nil
+		}
+	}
+	return "", fmt.Errorf("no checksum recorded for %s", fileName)
+}
+
+// VerifyChecksum checks data against a hex encoded SHA-256 digest.
+func VerifyChecksum(data []byte, expected string) error {
+	if actual := Checksum(data); actual != strings.ToLower(expected) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expected, actual)
+	}
+	return nil
+}
+
+// LoadPrivateKey reads an unencrypted PEM private key: PKCS#8 (RSA, ECDSA, Ed25519),
+// PKCS#1 RSA or SEC 1 EC.
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	// Legacy OpenSSL encryption marks the block with a Proc-Type header
+	if block.Type == "ENCRYPTED PRIVATE KEY" || strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED") {
+		return nil, fmt.Errorf("%s: encrypted keys are not supported, decrypt it with openssl pkey", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: expected a private key, found %s", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to parse private key: %w", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok || !isSupportedKey(signer.Public()) {
+		return nil, fmt.Errorf("%s: unsupported key type %T (expected RSA, ECDSA or Ed25519)", path, key)
+	}
+	return signer, nil
+}
+
+// LoadPublicKey reads a PEM public key or certificate. A private key is accepted too and
+// verifies with its public half.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var certificate *x509.Certificate
+		certificate, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key = certificate.PublicKey
+		}
+	default:
+		signer, loadErr := LoadPrivateKey(path)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		return signer.Public(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to parse public key: %w", path, err)
+	}
+	if !isSupportedKey(key) {
+		return nil, fmt.Errorf("%s: unsupported key type %T (expected RSA, ECDSA or Ed25519)", path, key)
+	}
+	return key, nil
+}
+
+// ParsePublicKey parses a DER encoded PKIX public key, the content of a PEM "PUBLIC KEY" block.
+func ParsePublicKey(der []byte) (crypto.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	if !isSupportedKey(key) {
+		return nil, fmt.Errorf("unsupported key type %T (expected RSA, ECDSA or Ed25519)", key)
+	}
+	return key, nil
+}
+
+// Sign returns the detached signature of data.
+func Sign(signer crypto.Signer, data []byte) ([]byte, error) {
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return signer.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(data)
+	return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// Verify checks a detached signature of data.
+func Verify(key crypto.PublicKey, data, signature []byte) error {
+	digest := sha256.Sum256(data)
+	valid := false
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, data, signature)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest[:], signature)
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+func isSupportedKey(key crypto.PublicKey) bool {
+	switch key.(type) {
+	case ed25519.PublicKey, *rsa.PublicKey, *ecdsa.PublicKey:
+		return true
+	}
+	return false
+}
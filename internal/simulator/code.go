@@ -0,0 +1,130 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// DefaultCodeTimeout limits one run of a code node
+const DefaultCodeTimeout = 10 * time.Second
+
+// codeRuntime runs the code of one language: the interpreter, the script file and the harness
+// appended to the code. Harnesses read the inputs as a JSON object from stdin, call main with
+// them and print the returned object as JSON on the last line.
+type codeRuntime struct {
+	interpreter string
+	file        string
+	harness     string
+}
+
+// Functions taking a single args parameter follow Coze, which passes the inputs as args.params
+const pythonHarness = `
+
+if __name__ == "__main__":
+    import asyncio as _asyncio, inspect as _inspect, json as _json, sys as _sys
+
+    class _Args:
+        def __init__(self, params):
+            self.params = params
+
+    _inputs = _json.load(_sys.stdin)
+    if list(_inspect.signature(main).parameters) == ["args"]:
+        _result = main(_Args(_inputs))
+    else:
+        _result = main(**_inputs)
+    if _inspect.isawaitable(_result):
+        _result = _asyncio.run(_result)
+    print()
+    print(_json.dumps(_result, ensure_ascii=False, default=str))
+`
+
+const javascriptHarness = `
+
+const _inputs = JSON.parse(require("fs").readFileSync(0, "utf8"));
+Promise.resolve(main({ ..._inputs, params: _inputs })).then((result) => {
+  console.log();
+  console.log(JSON.stringify(result));
+});
+`
+
+func runtimeFor(language string) (codeRuntime, error) {
+	switch strings.ToLower(language) {
+	case "python3", "python", "":
+		return codeRuntime{interpreter: "python3", file: "main.py", harness: pythonHarness}, nil
+	case "javascript", "nodejs", "js":
+		return codeRuntime{interpreter: "node", file: "main.js", harness: javascriptHarness}, nil
+	}
+	return codeRuntime{}, fmt.Errorf("cannot run %s code", language)
+}
+
+// runCode runs a code node with the local interpreter of its language. The process runs in an
+// empty temporary directory with only PATH set and the code timeout; it is not isolated from the
+// file system or the network, so only run code you trust.
+func (s *simulation) runCode(node *models.Node, inputs map[string]interface{}) (map[string]interface{}, error) {
+	config, ok := common.AsCodeConfig(node.Config)
+	if !ok || config == nil {
+		return nil, fmt.Errorf("code node has no code")
+	}
+	runtime, err := runtimeFor(config.Language)
+	if err != nil {
+		return nil, err
+	}
+	interpreter, err := exec.LookPath(runtime.interpreter)
+	if err != nil {
+		return nil, fmt.Errorf("%s interpreter not found: %w", runtime.interpreter, err)
+	}
+
+	dir, err := os.MkdirTemp("", "agentbridge-simulate-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create code directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, runtime.file)
+	if err := os.WriteFile(script, []byte(config.Code+runtime.harness), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write code: %w", err)
+	}
+	if inputs == nil {
+		inputs = map[string]interface{}{}
+	}
+	stdin, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode code inputs: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.options.CodeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, interpreter, script)
+	cmd.Dir = dir
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "HOME=" + dir}
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("code timed out after %v", s.options.CodeTimeout)
+		}
+		return nil, fmt.Errorf("code failed: %w: %s", err, lastLine(stderr.String()))
+	}
+
+	var outputs map[string]interface{}
+	if err := json.Unmarshal([]byte(lastLine(stdout.String())), &outputs); err != nil {
+		return nil, fmt.Errorf("code did not return an object: %s", lastLine(stdout.String()))
+	}
+	return outputs, nil
+}
+
+// lastLine returns the last non-empty line of a process output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
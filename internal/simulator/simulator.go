@@ -0,0 +1,492 @@
+// Package simulator dry-runs unified DSL workflows without a platform.
+//
+// A simulation runs every node the workflow reaches in graph order and traces the values flowing
+// through references, branches and iterations. Conditions are evaluated, classifiers take the
+// class given in the responses or named by the query, and LLM nodes echo their rendered prompt
+// unless a canned response is given. Code nodes run in a local interpreter when enabled and
+// return the zero values of their outputs otherwise; all other nodes are stubbed the same way.
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// How a node produced its outputs
+const (
+	ModeEvaluated = "evaluated" // Computed from the inputs: start, end, branches and iterations
+	ModeCanned    = "canned"    // Taken from the responses of the options
+	ModeEcho      = "echo"      // LLM output echoing the rendered prompt
+	ModeExecuted  = "executed"  // Code run by a local interpreter
+	ModeStubbed   = "stubbed"   // Zero values of the declared outputs
+	ModeFailed    = "failed"    // The node failed; see the error handling of the node
+)
+
+// ErrUndecided is returned for classifiers neither the responses nor the query decide.
+var ErrUndecided = errors.New("classifier branch undecided")
+
+// Options configure a simulation.
+type Options struct {
+	Inputs      map[string]interface{} // Start variables by name; "sys.x" and "conversation.x" keys set those selectors
+	Responses   map[string]interface{} // Canned results by node ID: the text of LLM nodes, the class of classifiers, or an object of outputs
+	RunCode     bool                   // Run code nodes with the local python3 and node interpreters
+	CodeTimeout time.Duration          // Time limit of one code run, DefaultCodeTimeout when zero
+}
+
+// Step is one run of a node.
+type Step struct {
+	NodeID    string                 `json:"node_id"`
+	NodeTitle string                 `json:"node_title"`
+	NodeType  models.NodeType        `json:"node_type"`
+	Iteration string                 `json:"iteration,omitempty"` // Enclosing iteration node
+	Item      int                    `json:"item,omitempty"`      // 1-based position of the iteration item, 0 outside iterations
+	Mode      string                 `json:"mode"`
+	Inputs    map[string]interface{} `json:"inputs,omitempty"`
+	Outputs   map[string]interface{} `json:"outputs,omitempty"`
+	Branch    string                 `json:"branch,omitempty"` // Case ID or class name of branch nodes
+	Note      string                 `json:"note,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// Trace is the result of a simulation.
+type Trace struct {
+	Steps    []Step                 `json:"steps"`
+	Outputs  map[string]interface{} `json:"outputs"`           // Outputs of the end node
+	Answer   string                 `json:"answer,omitempty"`  // Rendered answer template of the end node
+	Skipped  []string               `json:"skipped,omitempty"` // Top-level nodes not reached, by ID
+	Warnings []string               `json:"warnings,omitempty"`
+}
+
+// Run simulates a workflow. The trace is returned with the error when a node fails without error
+// handling or a classifier is undecided, and lists the steps up to there. Parallel iterations run
+// their items one by one.
+func Run(ctx context.Context, unifiedDSL *models.UnifiedDSL, options Options) (*Trace, error) {
+	if unifiedDSL == nil {
+		return nil, fmt.Errorf("workflow is required")
+	}
+	if options.CodeTimeout <= 0 {
+		options.CodeTimeout = DefaultCodeTimeout
+	}
+
+	s := &simulation{
+		ctx:     ctx,
+		dsl:     unifiedDSL,
+		options: options,
+		outputs: make(map[string]map[string]interface{}),
+		missing: make(map[string]bool),
+		nodeIDs: make(map[string]bool),
+		trace:   &Trace{Outputs: make(map[string]interface{})},
+	}
+	collectNodeIDs(unifiedDSL.Workflow.Nodes, s.nodeIDs)
+	s.evaluator = &common.BranchEvaluator{Lookup: s.lookup, Classes: make(map[string]string)}
+	for nodeID, response := range options.Responses {
+		if class, ok := response.(string); ok {
+			s.evaluator.Classes[nodeID] = class
+		}
+	}
+
+	nodes, edges := common.TopLevelNodes(unifiedDSL)
+	err := s.runGraph(nodes, edges, nil)
+	s.trace.Warnings = append(s.trace.Warnings, s.evaluator.Warnings...)
+	return s.trace, err
+}
+
+type simulation struct {
+	ctx       context.Context
+	dsl       *models.UnifiedDSL
+	options   Options
+	outputs   map[string]map[string]interface{} // Outputs of the nodes run so far, by node ID
+	nodeIDs   map[string]bool                   // All nodes, iteration bodies included
+	missing   map[string]bool                   // Selectors already reported as unset
+	evaluator *common.BranchEvaluator
+	trace     *Trace
+}
+
+func collectNodeIDs(nodes []models.Node, ids map[string]bool) {
+	for _, node := range nodes {
+		ids[node.ID] = true
+		if iterConfig, ok := common.AsIterationConfig(node.Config); ok && iterConfig != nil {
+			collectNodeIDs(iterConfig.SubWorkflow.Nodes, ids)
+		}
+	}
+}
+
+// iterationScope is the iteration item a sub-workflow runs for
+type iterationScope struct {
+	iteration *models.Node
+	item      int // 1-based
+	last      *models.Node
+}
+
+// runGraph runs the nodes of the workflow or of an iteration body in graph order. A node runs
+// when an edge taken by a node before it leads to it; at the top level the walk starts at the
+// start node, in an iteration body at the nodes nothing else in the body leads to.
+func (s *simulation) runGraph(nodes []*models.Node, edges []models.Edge, scope *iterationScope) error {
+	inLevel := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		inLevel[node.ID] = true
+	}
+	outgoing := make(map[string][]models.Edge)
+	led := make(map[string]bool)
+	for _, edge := range edges {
+		outgoing[edge.Source] = append(outgoing[edge.Source], edge)
+		if inLevel[edge.Source] {
+			led[edge.Target] = true
+		}
+	}
+
+	active := make(map[string]bool)
+	for _, node := range nodes {
+		if scope == nil && node.Type == models.NodeTypeStart || scope != nil && !led[node.ID] {
+			active[node.ID] = true
+		}
+	}
+	for _, node := range graphOrder(nodes, edges) {
+		if !active[node.ID] {
+			if scope == nil {
+				s.trace.Skipped = append(s.trace.Skipped, node.ID)
+			}
+			continue
+		}
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+		targets, err := s.runNode(node, outgoing[node.ID], scope)
+		if err != nil {
+			return err
+		}
+		for _, target := range targets {
+			active[target] = true
+		}
+		if scope != nil {
+			scope.last = node
+		}
+	}
+	return nil
+}
+
+// graphOrder sorts nodes so that every node comes after the nodes leading to it, keeping the
+// workflow order otherwise. Nodes on cycles come last.
+func graphOrder(nodes []*models.Node, edges []models.Edge) []*models.Node {
+	position := make(map[string]int, len(nodes))
+	for i, node := range nodes {
+		position[node.ID] = i
+	}
+	incoming := make(map[string]int)
+	next := make(map[string][]string)
+	for _, edge := range edges {
+		if _, ok := position[edge.Source]; !ok {
+			continue
+		}
+		if _, ok := position[edge.Target]; ok {
+			incoming[edge.Target]++
+			next[edge.Source] = append(next[edge.Source], edge.Target)
+		}
+	}
+
+	var ready []int
+	for i, node := range nodes {
+		if incoming[node.ID] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	ordered := make([]*models.Node, 0, len(nodes))
+	done := make(map[string]bool)
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		node := nodes[ready[0]]
+		ready = ready[1:]
+		ordered = append(ordered, node)
+		done[node.ID] = true
+		for _, target := range next[node.ID] {
+			if incoming[target]--; incoming[target] == 0 {
+				ready = append(ready, position[target])
+			}
+		}
+	}
+	for _, node := range nodes {
+		if !done[node.ID] {
+			ordered = append(ordered, node)
+		}
+	}
+	return ordered
+}
+
+// runNode runs a node, records its step and returns the targets of the edges it takes
+func (s *simulation) runNode(node *models.Node, edges []models.Edge, scope *iterationScope) ([]string, error) {
+	step := Step{NodeID: node.ID, NodeTitle: node.Title, NodeType: node.Type, Inputs: s.resolveInputs(node)}
+	if scope != nil {
+		step.Iteration, step.Item = scope.iteration.ID, scope.item
+	}
+
+	var targets []string
+	choice, isBranch := s.evaluator.Decide(node)
+	outputs, err := s.execute(node, &step, scope)
+	switch {
+	case err != nil:
+		if targets, err = s.fail(node, &step, edges, err); err != nil {
+			return nil, err
+		}
+		outputs = step.Outputs
+	case isBranch:
+		step.Branch, step.Note = choice.Branch, choice.Reason
+		if choice.Branch == "" {
+			step.Mode = ModeFailed
+			step.Error = choice.Reason
+			s.trace.Steps = append(s.trace.Steps, step)
+			return nil, fmt.Errorf("%s (%s): %w: %s", node.Title, node.ID, ErrUndecided, choice.Reason)
+		}
+		for _, output := range node.Outputs {
+			outputs[output.Name] = choice.Branch
+		}
+		targets = common.BranchTargets(choice, edges)
+	default:
+		targets = successTargets(edges)
+	}
+
+	s.outputs[node.ID] = outputs
+	step.Outputs = outputs
+	s.trace.Steps = append(s.trace.Steps, step)
+	return targets, nil
+}
+
+// fail applies the error handling of a failed node: default values, the error branch, or the end
+// of the simulation
+func (s *simulation) fail(node *models.Node, step *Step, edges []models.Edge, err error) ([]string, error) {
+	step.Mode, step.Error = ModeFailed, err.Error()
+	strategy := models.ErrorStrategyFail
+	if node.ErrorHandling != nil {
+		strategy = node.ErrorHandling.Strategy
+	}
+
+	switch strategy {
+	case models.ErrorStrategyDefaultValue:
+		step.Outputs = make(map[string]interface{})
+		for name, value := range node.ErrorHandling.DefaultValues {
+			step.Outputs[name] = value
+		}
+		step.Note = "default values after the failure"
+		return successTargets(edges), nil
+	case models.ErrorStrategyFailBranch:
+		step.Outputs = map[string]interface{}{}
+		step.Note = "error branch after the failure"
+		var targets []string
+		for _, edge := range edges {
+			if edge.SourceHandle == models.ErrorBranchHandle {
+				targets = append(targets, edge.Target)
+			}
+		}
+		return targets, nil
+	default:
+		s.trace.Steps = append(s.trace.Steps, *step)
+		return nil, fmt.Errorf("%s (%s) failed: %w", node.Title, node.ID, err)
+	}
+}
+
+// successTargets returns the targets of the edges taken when a node succeeds
+func successTargets(edges []models.Edge) []string {
+	var targets []string
+	for _, edge := range edges {
+		if edge.SourceHandle != models.ErrorBranchHandle {
+			targets = append(targets, edge.Target)
+		}
+	}
+	return targets
+}
+
+// execute computes the outputs of a node and sets the mode of its step
+func (s *simulation) execute(node *models.Node, step *Step, scope *iterationScope) (map[string]interface{}, error) {
+	step.Mode = ModeEvaluated
+	switch node.Type {
+	case models.NodeTypeStart:
+		return s.startOutputs(node, scope), nil
+	case models.NodeTypeEnd:
+		return s.endOutputs(node, step, scope), nil
+	case models.NodeTypeCondition, models.NodeTypeClassifier:
+		return make(map[string]interface{}), nil
+	case models.NodeTypeIteration:
+		return s.runIteration(node, step)
+	}
+
+	if response, ok := s.options.Responses[node.ID]; ok {
+		step.Mode = ModeCanned
+		return cannedOutputs(node, response), nil
+	}
+	switch node.Type {
+	case models.NodeTypeLLM:
+		step.Mode = ModeEcho
+		outputs := zeroOutputs(node)
+		if name := textOutput(node); name != "" {
+			outputs[name] = s.echo(node, step.Inputs)
+		}
+		return outputs, nil
+	case models.NodeTypeCode:
+		if s.options.RunCode {
+			step.Mode = ModeExecuted
+			return s.runCode(node, step.Inputs)
+		}
+	}
+	step.Mode = ModeStubbed
+	return zeroOutputs(node), nil
+}
+
+// startOutputs returns the start variables; in an iteration body the start node passes the item
+func (s *simulation) startOutputs(node *models.Node, scope *iterationScope) map[string]interface{} {
+	outputs := make(map[string]interface{})
+	if scope != nil {
+		item := s.outputs[scope.iteration.ID]["item"]
+		for _, output := range node.Outputs {
+			outputs[output.Name] = item
+		}
+		return outputs
+	}
+
+	names := make([]string, 0, len(node.Outputs))
+	defaults := make(map[string]interface{})
+	required := make(map[string]bool)
+	for _, output := range node.Outputs {
+		names = append(names, output.Name)
+		defaults[output.Name], required[output.Name] = output.Default, output.Required
+	}
+	if config, ok := common.AsStartConfig(node.Config); ok && config != nil {
+		for _, variable := range config.Variables {
+			if _, known := defaults[variable.Name]; !known {
+				names = append(names, variable.Name)
+			}
+			if variable.Default != nil {
+				defaults[variable.Name] = variable.Default
+			}
+			required[variable.Name] = required[variable.Name] || variable.Required
+		}
+	}
+	for _, name := range names {
+		value, ok := s.options.Inputs[name]
+		if !ok {
+			value = defaults[name]
+			if value == nil && required[name] {
+				s.trace.Warnings = append(s.trace.Warnings, fmt.Sprintf("required start variable %q has no value", name))
+			}
+		}
+		outputs[name] = value
+	}
+	return outputs
+}
+
+// endOutputs returns the values the end node returns. The top-level end node sets the outputs of
+// the trace.
+func (s *simulation) endOutputs(node *models.Node, step *Step, scope *iterationScope) map[string]interface{} {
+	outputs := make(map[string]interface{}, len(step.Inputs))
+	for name, value := range step.Inputs {
+		outputs[name] = value
+	}
+	if scope != nil {
+		return outputs
+	}
+	for name, value := range outputs {
+		s.trace.Outputs[name] = value
+	}
+	if config, ok := common.AsEndConfig(node.Config); ok && config != nil && config.OutputMode == models.EndOutputModeTemplate {
+		s.trace.Answer = s.render(config.Template, step.Inputs)
+	}
+	return outputs
+}
+
+// runIteration runs the body of an iteration once per item and collects the result of each item
+func (s *simulation) runIteration(node *models.Node, step *Step) (map[string]interface{}, error) {
+	iterConfig, ok := common.AsIterationConfig(node.Config)
+	if !ok || iterConfig == nil {
+		return zeroOutputs(node), nil
+	}
+	var list interface{}
+	if iterConfig.Iterator.SourceNode != "" {
+		list, _ = s.lookup([]string{iterConfig.Iterator.SourceNode, iterConfig.Iterator.SourceOutput})
+	} else {
+		for _, input := range node.Inputs {
+			if input.Reference != nil {
+				list = step.Inputs[input.Name]
+				break
+			}
+		}
+	}
+	items, err := listItems(list)
+	if err != nil {
+		return nil, fmt.Errorf("iteration input: %w", err)
+	}
+
+	body, edges := common.IterationBody(s.dsl, node)
+	results := make([]interface{}, 0, len(items))
+	mode := models.NormalizeErrorHandleMode(iterConfig.Execution.ErrorHandleMode)
+	for i, item := range items {
+		// The body reads the item as an output of the iteration, named item or after the iteration input
+		current := map[string]interface{}{"item": item, "index": i}
+		for _, input := range node.Inputs {
+			current[input.Name] = item
+		}
+		s.outputs[node.ID] = current
+
+		scope := &iterationScope{iteration: node, item: i + 1}
+		if err := s.runGraph(body, edges, scope); err != nil {
+			if errors.Is(err, ErrUndecided) || mode == models.ErrorHandleModeTerminated || s.ctx.Err() != nil {
+				return nil, err
+			}
+			if mode == models.ErrorHandleModeContinue {
+				results = append(results, nil)
+			}
+			s.trace.Warnings = append(s.trace.Warnings, fmt.Sprintf("%s (%s): item %d failed, %s: %v", node.Title, node.ID, i+1, mode, err))
+			continue
+		}
+		results = append(results, s.itemResult(iterConfig, body, scope))
+	}
+
+	outputs := map[string]interface{}{"output": results}
+	if len(node.Outputs) > 0 {
+		outputs = map[string]interface{}{node.Outputs[0].Name: results}
+	}
+	step.Note = fmt.Sprintf("%d items", len(items))
+	return outputs, nil
+}
+
+// itemResult is the output selected by the iteration, the value returned by the end node of the
+// body, or the first output of the node that ran last
+func (s *simulation) itemResult(config *models.IterationConfig, body []*models.Node, scope *iterationScope) interface{} {
+	if selector := config.OutputSelector; selector.NodeID != "" {
+		value, _ := s.lookup(append([]string{selector.NodeID}, strings.Split(selector.OutputName, ".")...))
+		return value
+	}
+	for _, node := range body {
+		if node.Type == models.NodeTypeEnd && s.outputs[node.ID] != nil {
+			if len(node.Inputs) == 1 {
+				return s.outputs[node.ID][node.Inputs[0].Name]
+			}
+			return s.outputs[node.ID]
+		}
+	}
+	if scope.last != nil && len(scope.last.Outputs) > 0 {
+		return s.outputs[scope.last.ID][scope.last.Outputs[0].Name]
+	}
+	return nil
+}
+
+// listItems returns the elements of an iteration input
+func listItems(list interface{}) ([]interface{}, error) {
+	if list == nil {
+		return nil, nil
+	}
+	value := reflect.ValueOf(list)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%T is not a list", list)
+	}
+	items := make([]interface{}, value.Len())
+	for i := range items {
+		items[i] = value.Index(i).Interface()
+	}
+	return items, nil
+}
@@ -0,0 +1,193 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// specialSelectors are selector roots the options set directly instead of a node
+var specialSelectors = map[string]bool{
+	"sys":          true,
+	"env":          true,
+	"conversation": true,
+}
+
+// lookup returns the value of a selector: node ID, output name, then nested keys. Outputs of
+// nodes that did not run are reported once as warnings.
+func (s *simulation) lookup(selector []string) (interface{}, bool) {
+	if len(selector) < 2 {
+		return nil, false
+	}
+	key := selector[0] + "." + selector[1]
+	if specialSelectors[selector[0]] {
+		value, ok := s.options.Inputs[key]
+		if !ok {
+			s.warnMissing(key)
+			return nil, false
+		}
+		return common.SelectField(value, selector[2:])
+	}
+
+	// Outputs of nodes on branches not taken are empty, as on the platforms
+	value, ok := s.outputs[selector[0]][selector[1]]
+	if !ok {
+		if !s.nodeIDs[selector[0]] || s.outputs[selector[0]] != nil {
+			s.warnMissing(key)
+		}
+		return nil, false
+	}
+	return common.SelectField(value, selector[2:])
+}
+
+func (s *simulation) warnMissing(key string) {
+	if !s.missing[key] {
+		s.missing[key] = true
+		s.trace.Warnings = append(s.trace.Warnings, fmt.Sprintf("%s is read but has no value", key))
+	}
+}
+
+// resolve returns the value of a reference
+func (s *simulation) resolve(reference *models.VariableReference, inputs map[string]interface{}) (interface{}, bool) {
+	switch reference.Type {
+	case models.ReferenceTypeLiteral:
+		return reference.Value, true
+	case models.ReferenceTypeTemplate:
+		return s.render(reference.Template, inputs), true
+	}
+	if reference.NodeID == "" {
+		return reference.Value, reference.Value != nil
+	}
+	return s.lookup(append([]string{reference.NodeID}, strings.Split(reference.OutputName, ".")...))
+}
+
+// resolveInputs returns the values of the inputs of a node, defaults for unresolved references
+func (s *simulation) resolveInputs(node *models.Node) map[string]interface{} {
+	if len(node.Inputs) == 0 {
+		return nil
+	}
+	inputs := make(map[string]interface{}, len(node.Inputs))
+	for _, input := range node.Inputs {
+		value := input.Default
+		if input.Reference != nil {
+			if resolved, ok := s.resolve(input.Reference, inputs); ok {
+				value = resolved
+			}
+		}
+		inputs[input.Name] = value
+	}
+	return inputs
+}
+
+// render fills the placeholders of a template: inline references with the outputs they name,
+// named placeholders with the inputs of the node. Unknown placeholders are kept.
+func (s *simulation) render(template string, inputs map[string]interface{}) string {
+	return common.RewritePromptTemplate(template, func(p common.PromptPlaceholder) string {
+		if p.IsReference() {
+			value, ok := s.lookup(append([]string{p.NodeID}, strings.Split(p.Output, ".")...))
+			if !ok {
+				return p.Text
+			}
+			return formatValue(value)
+		}
+		if value, ok := inputs[p.Name]; ok {
+			return formatValue(value)
+		}
+		return p.Text
+	})
+}
+
+// formatValue writes texts as they are, nothing for missing values and everything else as JSON
+func formatValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// echo renders the user prompt of an LLM node, or its system prompt when it has none
+func (s *simulation) echo(node *models.Node, inputs map[string]interface{}) string {
+	config, ok := common.AsLLMConfig(node.Config)
+	if !ok || config == nil {
+		return ""
+	}
+	prompt := config.Prompt.UserTemplate
+	for _, message := range config.Prompt.Messages {
+		if prompt == "" && message.Role == "user" {
+			prompt = message.Content
+		}
+	}
+	if prompt == "" {
+		prompt = config.Prompt.SystemTemplate
+	}
+	return s.render(prompt, inputs)
+}
+
+// textOutput names the output an LLM answers in: the first text output
+func textOutput(node *models.Node) string {
+	for _, output := range node.Outputs {
+		if output.Type == models.DataTypeString || output.Type == "" {
+			return output.Name
+		}
+	}
+	return ""
+}
+
+// cannedOutputs maps a response to the outputs of a node: objects give the outputs by name, other
+// values the first output
+func cannedOutputs(node *models.Node, response interface{}) map[string]interface{} {
+	outputs := zeroOutputs(node)
+	if object, ok := response.(map[string]interface{}); ok {
+		for name, value := range object {
+			outputs[name] = value
+		}
+		return outputs
+	}
+	name := textOutput(node)
+	if name == "" && len(node.Outputs) > 0 {
+		name = node.Outputs[0].Name
+	}
+	if name != "" {
+		outputs[name] = response
+	}
+	return outputs
+}
+
+// zeroOutputs returns the zero value of each declared output, or its default
+func zeroOutputs(node *models.Node) map[string]interface{} {
+	outputs := make(map[string]interface{}, len(node.Outputs))
+	for _, output := range node.Outputs {
+		if output.Default != nil {
+			outputs[output.Name] = output.Default
+			continue
+		}
+		outputs[output.Name] = zeroValue(output.Type)
+	}
+	return outputs
+}
+
+func zeroValue(dataType models.UnifiedDataType) interface{} {
+	switch {
+	case models.IsArrayType(dataType):
+		return []interface{}{}
+	case models.IsNumericType(dataType):
+		return 0
+	case dataType == models.DataTypeBoolean:
+		return false
+	case models.IsObjectType(dataType):
+		return map[string]interface{}{}
+	case models.IsFileType(dataType):
+		return nil
+	}
+	return ""
+}
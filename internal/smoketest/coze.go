@@ -0,0 +1,46 @@
+package smoketest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// cozeEndpoint is the OpenAPI of the Coze cloud
+const cozeEndpoint = "https://api.coze.cn"
+
+// runCoze runs a published workflow with the OpenAPI; Coze has no API to import workflows.
+func runCoze(ctx context.Context, target Target, inputs map[string]interface{}) (*Result, error) {
+	endpoint := target.Endpoint
+	if endpoint == "" {
+		endpoint = cozeEndpoint
+	}
+
+	var run struct {
+		Code     int    `json:"code"`
+		Msg      string `json:"msg"`
+		Data     string `json:"data"` // JSON encoded outputs
+		DebugURL string `json:"debug_url"`
+	}
+	api := newClient(target, endpoint, "Bearer "+target.APIKey)
+	err := api.Do(ctx, http.MethodPost, "/v1/workflow/run", map[string]interface{}{
+		"workflow_id": target.WorkflowID,
+		"parameters":  inputs,
+	}, &run)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRun, err)
+	}
+	if run.Code != 0 {
+		if run.DebugURL != "" {
+			return nil, fmt.Errorf("%w: code %d: %s (trace: %s)", ErrRun, run.Code, run.Msg, run.DebugURL)
+		}
+		return nil, fmt.Errorf("%w: code %d: %s", ErrRun, run.Code, run.Msg)
+	}
+
+	result := &Result{AppID: target.WorkflowID}
+	if json.Unmarshal([]byte(run.Data), &result.Outputs) != nil {
+		result.Answer = run.Data
+	}
+	return result, nil
+}
@@ -0,0 +1,101 @@
+package smoketest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/iflytek/agentbridge/integrations"
+	"github.com/iflytek/agentbridge/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+type difyWorkflowRun struct {
+	WorkflowRunID string `json:"workflow_run_id"`
+	Data          struct {
+		Status  string                 `json:"status"`
+		Outputs map[string]interface{} `json:"outputs"`
+		Error   string                 `json:"error"`
+	} `json:"data"`
+}
+
+// runDify imports dsl with the console API, runs it with a fresh app API key and deletes the app
+// unless the target keeps it.
+func runDify(ctx context.Context, target Target, dsl []byte, inputs map[string]interface{}) (*Result, error) {
+	var app struct {
+		App struct {
+			Mode string `yaml:"mode"`
+		} `yaml:"app"`
+	}
+	if err := yaml.Unmarshal(dsl, &app); err != nil {
+		return nil, fmt.Errorf("%w: not a Dify DSL: %v", ErrImport, err)
+	}
+
+	account := integrations.Account{
+		Platform: models.PlatformDify,
+		Endpoint: target.Endpoint,
+		APIKey:   target.APIKey,
+		Client:   target.Client,
+	}
+	adapter, err := integrations.NewAdapter(account)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := adapter.Push(ctx, dsl, integrations.PushOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImport, err)
+	}
+
+	console := newClient(target, target.Endpoint+"/console/api", "Bearer "+target.APIKey)
+	result, err := runDifyApp(ctx, target, console, imported.AppID, app.App.Mode, inputs)
+	if !target.Keep {
+		if deleteErr := integrations.DeleteDifyApp(context.WithoutCancel(ctx), account, imported.AppID); deleteErr != nil && err == nil {
+			err = fmt.Errorf("failed to delete test app %s: %w", imported.AppID, deleteErr)
+		}
+	}
+	return result, err
+}
+
+func runDifyApp(ctx context.Context, target Target, console *integrations.Client, appID, mode string, inputs map[string]interface{}) (*Result, error) {
+	var key struct {
+		Token string `json:"token"`
+	}
+	if err := console.Do(ctx, http.MethodPost, "/apps/"+appID+"/api-keys", nil, &key); err != nil {
+		return nil, fmt.Errorf("failed to create an API key for app %s: %w", appID, err)
+	}
+
+	api := newClient(target, target.Endpoint+"/v1", "Bearer "+key.Token)
+	result := &Result{AppID: appID, Imported: true}
+	if mode == "advanced-chat" {
+		var reply struct {
+			Answer string `json:"answer"`
+		}
+		err := api.Do(ctx, http.MethodPost, "/chat-messages", map[string]interface{}{
+			"inputs":        inputs,
+			"query":         target.Query,
+			"response_mode": "blocking",
+			"user":          UserID,
+		}, &reply)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRun, err)
+		}
+		result.Answer = reply.Answer
+		return result, nil
+	}
+
+	var run difyWorkflowRun
+	err := api.Do(ctx, http.MethodPost, "/workflows/run", map[string]interface{}{
+		"inputs":        inputs,
+		"response_mode": "blocking",
+		"user":          UserID,
+	}, &run)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRun, err)
+	}
+	if run.Data.Status != "succeeded" {
+		return nil, fmt.Errorf("%w: workflow run %s %s: %s", ErrRun, run.WorkflowRunID, run.Data.Status, run.Data.Error)
+	}
+	result.Outputs = run.Data.Outputs
+	return result, nil
+}
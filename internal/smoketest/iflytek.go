@@ -0,0 +1,47 @@
+package smoketest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// iflytekEndpoint serves the workflow API of published iFlytek Spark agents
+const iflytekEndpoint = "https://xingchen-api.xf-yun.com"
+
+// runIFlytek runs a published agent workflow; Spark has no API to import workflows.
+func runIFlytek(ctx context.Context, target Target, inputs map[string]interface{}) (*Result, error) {
+	endpoint := target.Endpoint
+	if endpoint == "" {
+		endpoint = iflytekEndpoint
+	}
+
+	var run struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+	}
+	api := newClient(target, endpoint, "Bearer "+target.APIKey)
+	err := api.Do(ctx, http.MethodPost, "/workflow/v1/chat/completions", map[string]interface{}{
+		"flow_id":    target.WorkflowID,
+		"uid":        UserID,
+		"parameters": inputs,
+		"stream":     false,
+	}, &run)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRun, err)
+	}
+	if run.Code != 0 {
+		return nil, fmt.Errorf("%w: code %d: %s", ErrRun, run.Code, run.Message)
+	}
+
+	result := &Result{AppID: target.WorkflowID}
+	if len(run.Choices) > 0 {
+		result.Answer = run.Choices[0].Delta.Content
+	}
+	return result, nil
+}
@@ -0,0 +1,153 @@
+// Package smoketest runs generated DSL files on live platform instances.
+//
+// A smoke test imports the file where the platform has an import API (Dify), runs the workflow
+// once with sample inputs and reports whether the import or the run failed. Coze and iFlytek
+// Spark publish no workflow import API, so their workflows are imported by hand and run by ID.
+package smoketest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/integrations"
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Failures of a smoke test; errors returned by Run wrap one of them with the platform message.
+var (
+	ErrImport = errors.New("import failed")
+	ErrRun    = errors.New("run failed")
+)
+
+// UserID identifies smoke test runs in the platform logs
+const UserID = "agentbridge-smoke-test"
+
+// Target is a live platform instance.
+type Target struct {
+	Platform   models.PlatformType
+	Endpoint   string // Base URL; empty for the public cloud of Coze and iFlytek
+	APIKey     string // Dify console token, Coze personal access token, iFlytek "APIKey:APISecret"
+	WorkflowID string // Workflow imported by hand, required on Coze and iFlytek
+	Query      string // User message of chatflows
+	Keep       bool   // Keep the app imported for the test instead of deleting it
+	Client     *http.Client
+}
+
+// Result describes a successful smoke test.
+type Result struct {
+	AppID    string // Imported app, or the workflow ID given in the target
+	Imported bool
+	Outputs  map[string]interface{}
+	Answer   string // Reply of chatflows
+	Elapsed  time.Duration
+}
+
+// Validate checks that the target names everything its platform needs.
+func (t Target) Validate() error {
+	switch t.Platform {
+	case models.PlatformDify:
+		if t.Endpoint == "" {
+			return fmt.Errorf("Dify needs the endpoint of its instance")
+		}
+	case models.PlatformCoze:
+		if t.WorkflowID == "" {
+			return fmt.Errorf("Coze has no workflow import API: import the file in the Coze editor, publish it and pass its workflow ID")
+		}
+	case models.PlatformIFlytek:
+		if t.WorkflowID == "" {
+			return fmt.Errorf("iFlytek Spark has no workflow import API: import the file in the agent console, publish it and pass its flow ID")
+		}
+	default:
+		return fmt.Errorf("smoke tests are not supported on %s", t.Platform)
+	}
+	if t.APIKey == "" {
+		return fmt.Errorf("an API key is required to reach %s", t.Platform)
+	}
+	return nil
+}
+
+// Run imports dsl into the target when the platform allows it and runs the workflow with inputs.
+func Run(ctx context.Context, target Target, dsl []byte, inputs map[string]interface{}) (*Result, error) {
+	if err := target.Validate(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	var result *Result
+	var err error
+	switch target.Platform {
+	case models.PlatformDify:
+		result, err = runDify(ctx, target, dsl, inputs)
+	case models.PlatformCoze:
+		result, err = runCoze(ctx, target, inputs)
+	case models.PlatformIFlytek:
+		result, err = runIFlytek(ctx, target, inputs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Elapsed = time.Since(start)
+	return result, nil
+}
+
+// SampleInputs returns a value for every start variable of a workflow: its default, its first
+// option, or a placeholder of its type. File variables are left out.
+func SampleInputs(unifiedDSL *models.UnifiedDSL) map[string]interface{} {
+	inputs := make(map[string]interface{})
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if node.Type != models.NodeTypeStart {
+			continue
+		}
+		var variables []models.Variable
+		switch config := node.Config.(type) {
+		case models.StartConfig:
+			variables = config.Variables
+		case *models.StartConfig:
+			variables = config.Variables
+		}
+		for _, variable := range variables {
+			if value, ok := sampleValue(variable); ok {
+				inputs[variable.Name] = value
+			}
+		}
+	}
+	return inputs
+}
+
+func sampleValue(variable models.Variable) (interface{}, bool) {
+	if variable.Default != nil && variable.Default != "" {
+		return variable.Default, true
+	}
+	if variable.Constraints != nil && len(variable.Constraints.Options) > 0 {
+		return variable.Constraints.Options[0], true
+	}
+
+	switch dataType := models.UnifiedDataType(variable.Type); {
+	case dataType == models.DataTypeInteger || dataType == models.DataTypeNumber:
+		return 1, true
+	case dataType == models.DataTypeFloat:
+		return 1.5, true
+	case dataType == models.DataTypeBoolean:
+		return true, true
+	case dataType == models.DataTypeObject:
+		return map[string]interface{}{}, true
+	case strings.HasPrefix(variable.Type, "array[object]"):
+		return []interface{}{}, true
+	case strings.HasPrefix(variable.Type, "array["):
+		value, ok := sampleValue(models.Variable{Type: strings.TrimSuffix(strings.TrimPrefix(variable.Type, "array["), "]")})
+		return []interface{}{value}, ok
+	case strings.Contains(variable.Type, "file"):
+		return nil, false
+	default:
+		return "hello", true
+	}
+}
+
+// newClient returns a client of the target HTTP client
+func newClient(target Target, baseURL, authorization string) *integrations.Client {
+	return integrations.NewClient(target.Client, baseURL, authorization)
+}
@@ -0,0 +1,200 @@
+// Package stats records local usage statistics of conversions and summarizes them.
+//
+// Statistics are opt-in and never leave the machine: every conversion appends one JSON line
+// to a file chosen by the user. Records hold counts only, no file names, titles or content.
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EnvStatsFile enables statistics and names the file they are appended to
+const EnvStatsFile = "AGENTBRIDGE_STATS_FILE"
+
+// Record describes one conversion.
+type Record struct {
+	Time         time.Time      `json:"time"`
+	Command      string         `json:"command"`
+	Source       string         `json:"source"`
+	Target       string         `json:"target"`
+	Success      bool           `json:"success"`
+	ErrorCode    string         `json:"error_code,omitempty"`
+	Nodes        map[string]int `json:"nodes,omitempty"` // Source nodes per type
+	Placeholders int            `json:"placeholders,omitempty"`
+	Warnings     int            `json:"warnings,omitempty"`
+	DurationMS   int64          `json:"duration_ms"`
+}
+
+// NodeCount returns the total number of source nodes.
+func (r *Record) NodeCount() int {
+	total := 0
+	for _, count := range r.Nodes {
+		total += count
+	}
+	return total
+}
+
+// appendMutex serializes the appends of concurrent batch workers
+var appendMutex sync.Mutex
+
+// Append adds a record to the statistics file, creating it when needed.
+func Append(path string, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode statistics record: %w", err)
+	}
+	line = append(line, '\n')
+
+	appendMutex.Lock()
+	defer appendMutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create statistics directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open statistics file: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write statistics file: %w", err)
+	}
+	return file.Close()
+}
+
+// Load reads all records of a statistics file. A missing file has no records.
+func Load(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open statistics file: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid statistics record: %w", path, line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read statistics file: %w", err)
+	}
+	return records, nil
+}
+
+// Count is a named counter of a summary.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Summary aggregates records for migration reporting.
+type Summary struct {
+	First           *time.Time `json:"first,omitempty"`
+	Last            *time.Time `json:"last,omitempty"`
+	Conversions     int        `json:"conversions"`
+	Succeeded       int        `json:"succeeded"`
+	Failed          int        `json:"failed"`
+	Paths           []Count    `json:"paths"`
+	Errors          []Count    `json:"errors,omitempty"`
+	NodeTypes       []Count    `json:"node_types"`
+	Nodes           int        `json:"nodes"`        // Source nodes of successful conversions
+	Placeholders    int        `json:"placeholders"` // Placeholders of successful conversions
+	WithPlaceholder int        `json:"conversions_with_placeholders"`
+}
+
+// PlaceholderRate returns the share of converted nodes that became placeholders, in percent.
+func (s *Summary) PlaceholderRate() float64 {
+	if s.Nodes == 0 {
+		return 0
+	}
+	return float64(s.Placeholders) * 100 / float64(s.Nodes)
+}
+
+// SuccessRate returns the share of successful conversions, in percent.
+func (s *Summary) SuccessRate() float64 {
+	if s.Conversions == 0 {
+		return 0
+	}
+	return float64(s.Succeeded) * 100 / float64(s.Conversions)
+}
+
+// Summarize aggregates the records made at or after since; a zero since keeps all records.
+func Summarize(records []Record, since time.Time) *Summary {
+	summary := &Summary{}
+	paths := make(map[string]int)
+	errorCodes := make(map[string]int)
+	nodeTypes := make(map[string]int)
+
+	for _, record := range records {
+		if record.Time.Before(since) {
+			continue
+		}
+		recordTime := record.Time
+		if summary.First == nil || recordTime.Before(*summary.First) {
+			summary.First = &recordTime
+		}
+		if summary.Last == nil || recordTime.After(*summary.Last) {
+			summary.Last = &recordTime
+		}
+
+		summary.Conversions++
+		paths[record.Source+" → "+record.Target]++
+		if !record.Success {
+			summary.Failed++
+			code := record.ErrorCode
+			if code == "" {
+				code = "unknown"
+			}
+			errorCodes[code]++
+			continue
+		}
+
+		summary.Succeeded++
+		for nodeType, count := range record.Nodes {
+			nodeTypes[nodeType] += count
+		}
+		summary.Nodes += record.NodeCount()
+		summary.Placeholders += record.Placeholders
+		if record.Placeholders > 0 {
+			summary.WithPlaceholder++
+		}
+	}
+
+	summary.Paths = sortedCounts(paths)
+	summary.Errors = sortedCounts(errorCodes)
+	summary.NodeTypes = sortedCounts(nodeTypes)
+	return summary
+}
+
+// sortedCounts orders counters by decreasing count, then by name
+func sortedCounts(counts map[string]int) []Count {
+	sorted := make([]Count, 0, len(counts))
+	for name, count := range counts {
+		sorted = append(sorted, Count{Name: name, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
@@ -0,0 +1,312 @@
+// Package update replaces the running agentbridge binary with a release published on GitHub.
+//
+// A release carries one binary per platform, named by AssetName, a checksums.txt in the sha256sum
+// format covering them and checksums.txt.sig, a detached signature of checksums.txt; see package
+// signing for the signature formats. Binaries are only installed from signed releases unless
+// unsigned ones are explicitly allowed.
+package update
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/signing"
+)
+
+// Defaults of the Updater
+const (
+	DefaultAPI        = "https://api.github.com"
+	DefaultRepository = "iflytek/agentbridge"
+)
+
+// Assets every release publishes next to the binaries
+const (
+	ChecksumsAsset = "checksums.txt"
+	SignatureAsset = ChecksumsAsset + signing.SignatureSuffix
+)
+
+// MaxAssetBytes bounds downloaded assets
+const MaxAssetBytes = 256 << 20
+
+// ErrNoAsset is returned when a release has no binary for the platform.
+var ErrNoAsset = errors.New("release has no binary for this platform")
+
+// ErrUnsigned is returned when a release would be installed without a public key to check its
+// signature.
+var ErrUnsigned = errors.New("no release public key to check the signature")
+
+// ReleaseKey is the release public key embedded by release builds: the base64 encoded DER of the
+// PEM public key, set with -ldflags "-X github.com/iflytek/agentbridge/internal/update.ReleaseKey=...".
+// Development builds embed none.
+var ReleaseKey string
+
+// EmbeddedKey returns the release public key of the build, nil when it embeds none.
+func EmbeddedKey() (crypto.PublicKey, error) {
+	if ReleaseKey == "" {
+		return nil, nil
+	}
+	der, err := base64.StdEncoding.DecodeString(ReleaseKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded release key: %w", err)
+	}
+	key, err := signing.ParsePublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded release key: %w", err)
+	}
+	return key, nil
+}
+
+// Release is a published GitHub release.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Asset returns the asset called name.
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// AssetName names the release binary of a platform, e.g. agentbridge_linux_amd64 or
+// agentbridge_windows_amd64.exe.
+func AssetName(goos, goarch string) string {
+	name := fmt.Sprintf("agentbridge_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Updater looks up and downloads releases. Zero fields take the defaults.
+type Updater struct {
+	Client     *http.Client
+	API        string // GitHub API base URL, e.g. of a GitHub Enterprise server
+	Repository string // owner/name
+	Token      string // Optional GitHub token, raising the API rate limit
+	// PublicKey checks checksums.txt.sig
+	PublicKey crypto.PublicKey
+	// AllowUnsigned installs binaries checked against checksums.txt alone when PublicKey is nil,
+	// trusting it as downloaded over HTTPS
+	AllowUnsigned bool
+}
+
+// Latest returns the newest release that is neither a draft nor a pre-release.
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	return u.release(ctx, "/latest")
+}
+
+// Release returns the release of a tag such as v1.4.0.
+func (u *Updater) Release(ctx context.Context, tag string) (*Release, error) {
+	return u.release(ctx, "/tags/"+tag)
+}
+
+func (u *Updater) release(ctx context.Context, path string) (*Release, error) {
+	api := u.API
+	if api == "" {
+		api = DefaultAPI
+	}
+	repository := u.Repository
+	if repository == "" {
+		repository = DefaultRepository
+	}
+	data, err := u.get(ctx, strings.TrimSuffix(api, "/")+"/repos/"+repository+"/releases"+path, "application/vnd.github+json")
+	if err != nil {
+		return nil, err
+	}
+	var release Release
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("release has no tag")
+	}
+	return &release, nil
+}
+
+// Download returns the binary of the release for the running platform after checking it
+// against checksums.txt, and checksums.txt against its signature. Without a public key it fails
+// with ErrUnsigned unless unsigned releases are allowed.
+func (u *Updater) Download(ctx context.Context, release *Release) ([]byte, error) {
+	if u.PublicKey == nil && !u.AllowUnsigned {
+		return nil, fmt.Errorf("%w of %s", ErrUnsigned, release.TagName)
+	}
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	binaryAsset, ok := release.Asset(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoAsset, release.TagName, name)
+	}
+	checksumsAsset, ok := release.Asset(ChecksumsAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s, refusing an unverified binary", release.TagName, ChecksumsAsset)
+	}
+
+	checksums, err := u.get(ctx, checksumsAsset.URL, "application/octet-stream")
+	if err != nil {
+		return nil, err
+	}
+	if u.PublicKey != nil {
+		signatureAsset, ok := release.Asset(SignatureAsset)
+		if !ok {
+			return nil, fmt.Errorf("release %s is not signed: no %s", release.TagName, SignatureAsset)
+		}
+		signature, err := u.get(ctx, signatureAsset.URL, "application/octet-stream")
+		if err != nil {
+			return nil, err
+		}
+		if err := signing.Verify(u.PublicKey, checksums, signature); err != nil {
+			return nil, fmt.Errorf("%s of %s: %w", ChecksumsAsset, release.TagName, err)
+		}
+	}
+	expected, err := signing.ParseChecksumFile(checksums, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s of %s: %w", ChecksumsAsset, release.TagName, err)
+	}
+
+	binary, err := u.get(ctx, binaryAsset.URL, "application/octet-stream")
+	if err != nil {
+		return nil, err
+	}
+	if err := signing.VerifyChecksum(binary, expected); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return binary, nil
+}
+
+// get downloads url, bounded by MaxAssetBytes
+func (u *Updater) get(ctx context.Context, url, accept string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	request.Header.Set("Accept", accept)
+	if u.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+u.Token)
+	}
+
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", url, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, response.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(response.Body, MaxAssetBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if len(data) > MaxAssetBytes {
+		return nil, fmt.Errorf("%s exceeds %d bytes", url, MaxAssetBytes)
+	}
+	return data, nil
+}
+
+// Replace swaps the executable at path for binary. The new file is written next to it and
+// renamed over it, so an interrupted update leaves the old binary in place. Windows cannot
+// replace a running executable, so there the old one is moved to <path>.old first.
+func Replace(path string, binary []byte) error {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve executable: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat executable: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), ".agentbridge-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to write next to %s: %w", path, err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(binary); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := os.Chmod(temp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return fmt.Errorf("failed to make new binary executable: %w", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return fmt.Errorf("failed to move old binary aside: %w", err)
+		}
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// Newer reports whether release version candidate is newer than current. Versions are
+// compared as vMAJOR.MINOR.PATCH; a current version that does not parse, such as "dev", is
+// never considered up to date.
+func Newer(candidate, current string) bool {
+	c, ok := parseVersion(candidate)
+	if !ok {
+		return false
+	}
+	v, ok := parseVersion(current)
+	if !ok {
+		return true
+	}
+	for i := range c {
+		if c[i] != v[i] {
+			return c[i] > v[i]
+		}
+	}
+	return false
+}
+
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if end := strings.IndexAny(version, "-+"); end >= 0 {
+		version = version[:end]
+	}
+	fields := strings.Split(version, ".")
+	if len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		number, err := strconv.Atoi(field)
+		if err != nil || number < 0 {
+			return parts, false
+		}
+		parts[i] = number
+	}
+	return parts, true
+}
@@ -0,0 +1,93 @@
+// Package wasmbridge handles the requests of the js/wasm build: it converts and detects workflows
+// and answers with values syscall/js turns into JavaScript objects (maps, slices of interface{},
+// strings, numbers and booleans). The JavaScript glue stays in wasm/main.go.
+package wasmbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// Convert converts input from the source platform ("" detects it) to the target. optionsJSON
+// holds the JSON fields of models.ConversionOptions, empty for the defaults. The response has
+// output, source, target, warnings, placeholders and, when there is one, the ID mapping.
+func Convert(service *services.ConversionService, input []byte, from, to, optionsJSON string) (map[string]interface{}, error) {
+	source := models.PlatformType(from)
+	if source == "" {
+		detection, err := common.DetectPlatform(input)
+		if err != nil {
+			return nil, err
+		}
+		source = detection.Platform
+	}
+
+	options := models.NewConversionOptions()
+	if optionsJSON != "" {
+		if err := json.Unmarshal([]byte(optionsJSON), options); err != nil {
+			return nil, fmt.Errorf("invalid options: %w", err)
+		}
+	}
+	// There is no filesystem to write debug artifacts to
+	options.DebugDir = ""
+
+	result, err := service.ConvertWithResult(context.Background(), input, source, models.PlatformType(to), options)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"output":       string(result.Output),
+		"source":       string(result.SourcePlatform),
+		"target":       string(result.TargetPlatform),
+		"warnings":     stringsValue(result.Warnings),
+		"placeholders": result.Placeholders,
+	}
+	if result.IDMapping != nil {
+		mapping, err := jsonValue(result.IDMapping)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode ID mapping: %w", err)
+		}
+		response["mapping"] = mapping
+	}
+	return response, nil
+}
+
+// Detect answers with the platform, confidence and signals of input.
+func Detect(input []byte) (map[string]interface{}, error) {
+	detection, err := common.DetectPlatform(input)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"platform":   string(detection.Platform),
+		"confidence": detection.Confidence,
+		"signals":    stringsValue(detection.Signals),
+	}, nil
+}
+
+// stringsValue returns values as the []interface{} syscall/js converts, unlike []string
+func stringsValue(values []string) []interface{} {
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return list
+}
+
+// jsonValue returns value as decoded JSON: maps, slices, strings, numbers and booleans
+func jsonValue(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
@@ -0,0 +1,309 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// anonymizedPlatformKeys are the keys of raw platform data that hold user text
+var anonymizedPlatformKeys = map[string]bool{
+	"title":          true,
+	"label":          true,
+	"description":    true,
+	"desc":           true,
+	"template":       true,
+	"systemTemplate": true,
+	"prompt":         true,
+	"instruction":    true,
+	"instructions":   true,
+	"content":        true,
+	"question":       true,
+	"answer":         true,
+	"text":           true,
+	"default":        true,
+}
+
+// AnonymizeDSL replaces titles, descriptions, prompts and literal values with deterministic
+// pseudonyms, so a workflow can be shared without its proprietary content. Node IDs, types,
+// variable names, references, models, code and the graph are kept, and placeholders inside
+// prompts survive. Equal texts get equal pseudonyms, which keeps condition values matching.
+func AnonymizeDSL(unifiedDSL *models.UnifiedDSL) {
+	if unifiedDSL == nil {
+		return
+	}
+
+	metadata := &unifiedDSL.Metadata
+	metadata.Name = anonymizeText(metadata.Name)
+	metadata.Description = anonymizeText(metadata.Description)
+	if metadata.UIConfig != nil {
+		metadata.UIConfig.OpeningStatement = anonymizeText(metadata.UIConfig.OpeningStatement)
+		anonymizeTexts(metadata.UIConfig.SuggestedQuestions)
+	}
+	if iflytekMeta := unifiedDSL.PlatformMetadata.IFlytek; iflytekMeta != nil {
+		iflytekMeta.AdvancedConfig = anonymizeJSONText(iflytekMeta.AdvancedConfig)
+	}
+
+	workflow := &unifiedDSL.Workflow
+	anonymizeVariables(workflow.Variables)
+	if workflow.Features != nil {
+		workflow.Features.OpeningStatement = anonymizeText(workflow.Features.OpeningStatement)
+		anonymizeTexts(workflow.Features.SuggestedQuestions)
+	}
+	anonymizeNodes(workflow.Nodes)
+	anonymizeEdgeLabels(workflow.Edges)
+}
+
+func anonymizeNodes(nodes []models.Node) {
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Title != "" {
+			node.Title = pseudonym(string(node.Type), node.Title)
+		}
+		node.Description = anonymizeText(node.Description)
+		for j := range node.Inputs {
+			input := &node.Inputs[j]
+			input.Label = anonymizeText(input.Label)
+			input.Description = anonymizeText(input.Description)
+			input.Default = anonymizeValue(input.Default)
+			if input.Reference != nil && input.Reference.Type != models.ReferenceTypeNodeOutput {
+				input.Reference.Value = anonymizeValue(input.Reference.Value)
+				input.Reference.Template = anonymizeText(input.Reference.Template)
+			}
+		}
+		for j := range node.Outputs {
+			output := &node.Outputs[j]
+			output.Label = anonymizeText(output.Label)
+			output.Description = anonymizeText(output.Description)
+			output.Default = anonymizeValue(output.Default)
+		}
+		if handling := node.ErrorHandling; handling != nil && len(handling.DefaultValues) > 0 {
+			anonymized := *handling
+			anonymized.DefaultValues = make(map[string]interface{}, len(handling.DefaultValues))
+			for name, value := range handling.DefaultValues {
+				anonymized.DefaultValues[name] = anonymizeValue(value)
+			}
+			node.ErrorHandling = &anonymized
+		}
+		anonymizePlatformData(node.PlatformConfig.IFlytek)
+		anonymizePlatformData(node.PlatformConfig.Dify)
+		anonymizePlatformData(node.PlatformConfig.Coze)
+
+		anonymizeNodeConfig(node)
+	}
+}
+
+// anonymizeNodeConfig anonymizes the user text of a node configuration.
+func anonymizeNodeConfig(node *models.Node) {
+	if config, ok := AsStartConfig(node.Config); ok && config != nil {
+		anonymizeVariables(config.Variables)
+		storeNodeConfig(node, config)
+	} else if config, ok := AsEndConfig(node.Config); ok && config != nil {
+		config.Template = anonymizeText(config.Template)
+		storeNodeConfig(node, config)
+	} else if config, ok := AsLLMConfig(node.Config); ok && config != nil {
+		anonymizePrompt(&config.Prompt)
+		config.Memory = anonymizeMemory(config.Memory)
+		storeNodeConfig(node, config)
+	} else if config, ok := AsAgentConfig(node.Config); ok && config != nil {
+		anonymizePrompt(&config.Prompt)
+		for i := range config.Tools {
+			config.Tools[i].Description = anonymizeText(config.Tools[i].Description)
+		}
+		storeNodeConfig(node, config)
+	} else if config, ok := AsClassifierConfig(node.Config); ok && config != nil {
+		config.Instructions = anonymizeText(config.Instructions)
+		config.Memory = anonymizeMemory(config.Memory)
+		for i := range config.Classes {
+			config.Classes[i].Name = anonymizeText(config.Classes[i].Name)
+			config.Classes[i].Description = anonymizeText(config.Classes[i].Description)
+		}
+		for i := range config.Examples {
+			config.Examples[i].Query = anonymizeText(config.Examples[i].Query)
+		}
+		storeNodeConfig(node, config)
+	} else if config, ok := AsConditionConfig(node.Config); ok && config != nil {
+		for i := range config.Cases {
+			for j := range config.Cases[i].Conditions {
+				condition := &config.Cases[i].Conditions[j]
+				condition.Value = anonymizeValue(condition.Value)
+			}
+			if config.Cases[i].Expression != nil {
+				config.Cases[i].Expression.Walk(func(condition *models.Condition) {
+					condition.Value = anonymizeValue(condition.Value)
+				})
+			}
+		}
+		storeNodeConfig(node, config)
+	} else if config, ok := AsHumanInputConfig(node.Config); ok && config != nil {
+		config.Question = anonymizeText(config.Question)
+		for i := range config.Options {
+			config.Options[i].Name = anonymizeText(config.Options[i].Name)
+		}
+		storeNodeConfig(node, config)
+	} else if config, ok := AsIterationConfig(node.Config); ok && config != nil {
+		anonymizeNodes(config.SubWorkflow.Nodes)
+		anonymizeEdgeLabels(config.SubWorkflow.Edges)
+	}
+}
+
+func anonymizeEdgeLabels(edges []models.Edge) {
+	for i := range edges {
+		if style := edges[i].Style; style != nil && style.Label != "" {
+			labeled := *style
+			labeled.Label = anonymizeText(style.Label)
+			edges[i].Style = &labeled
+		}
+	}
+}
+
+// storeNodeConfig writes an edited config copy back into a node that stores its config by value.
+// Configs stored by pointer were edited in place.
+func storeNodeConfig[T models.NodeConfig](node *models.Node, config *T) {
+	if _, isValue := node.Config.(T); isValue {
+		node.Config = *config
+	}
+}
+
+func anonymizeVariables(variables []models.Variable) {
+	for i := range variables {
+		variables[i].Label = anonymizeText(variables[i].Label)
+		variables[i].Description = anonymizeText(variables[i].Description)
+		variables[i].Default = anonymizeValue(variables[i].Default)
+	}
+}
+
+// anonymizeMemory returns a copy of memory with an anonymized query prompt; memory is shared
+// with the source DSL.
+func anonymizeMemory(memory *models.MemoryConfig) *models.MemoryConfig {
+	if memory == nil || memory.QueryPrompt == "" {
+		return memory
+	}
+	anonymized := *memory
+	anonymized.QueryPrompt = anonymizeText(memory.QueryPrompt)
+	return &anonymized
+}
+
+func anonymizePrompt(prompt *models.PromptConfig) {
+	prompt.SystemTemplate = anonymizeText(prompt.SystemTemplate)
+	prompt.UserTemplate = anonymizeText(prompt.UserTemplate)
+	for i := range prompt.Messages {
+		prompt.Messages[i].Content = anonymizeText(prompt.Messages[i].Content)
+	}
+}
+
+// anonymizePlatformData anonymizes the user text kept in raw source platform data. Only strings
+// under text keys are replaced, so IDs and variable names in the same data are kept.
+func anonymizePlatformData(data map[string]interface{}) {
+	for key, value := range data {
+		switch v := value.(type) {
+		case string:
+			if anonymizedPlatformKeys[key] {
+				data[key] = anonymizeText(v)
+			}
+		case []interface{}:
+			for i, item := range v {
+				if text, ok := item.(string); ok && anonymizedPlatformKeys[key] {
+					v[i] = anonymizeText(text)
+				} else if intent, ok := item.(map[string]interface{}); ok && key == "intentChains" {
+					// Intent names are user text, unlike the names of inputs and outputs
+					if name, ok := intent["name"].(string); ok {
+						intent["name"] = anonymizeText(name)
+					}
+					anonymizePlatformData(intent)
+				} else if nested, ok := item.(map[string]interface{}); ok {
+					anonymizePlatformData(nested)
+				}
+			}
+		case map[string]interface{}:
+			anonymizePlatformData(v)
+		}
+	}
+}
+
+// anonymizeValue anonymizes the strings of a literal value; numbers and booleans are kept.
+func anonymizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return anonymizeText(v)
+	case []string:
+		anonymized := make([]string, len(v))
+		for i, item := range v {
+			anonymized[i] = anonymizeText(item)
+		}
+		return anonymized
+	case []interface{}:
+		anonymized := make([]interface{}, len(v))
+		for i, item := range v {
+			anonymized[i] = anonymizeValue(item)
+		}
+		return anonymized
+	case map[string]interface{}:
+		anonymized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			anonymized[key] = anonymizeValue(item)
+		}
+		return anonymized
+	default:
+		return value
+	}
+}
+
+func anonymizeTexts(texts []string) {
+	for i := range texts {
+		texts[i] = anonymizeText(texts[i])
+	}
+}
+
+// anonymizeText replaces the text around prompt placeholders, keeping the placeholders and the
+// surrounding whitespace.
+func anonymizeText(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+
+	var result strings.Builder
+	rest := text
+	for _, placeholder := range ParsePromptPlaceholders(text) {
+		index := strings.Index(rest, placeholder.Text)
+		result.WriteString(anonymizeSegment(rest[:index]))
+		result.WriteString(placeholder.Text)
+		rest = rest[index+len(placeholder.Text):]
+	}
+	result.WriteString(anonymizeSegment(rest))
+	return result.String()
+}
+
+func anonymizeSegment(segment string) string {
+	content := strings.TrimSpace(segment)
+	if content == "" {
+		return segment
+	}
+	start := strings.Index(segment, content)
+	return segment[:start] + pseudonym("text", content) + segment[start+len(content):]
+}
+
+// anonymizeJSONText anonymizes every string of a JSON document; invalid JSON is dropped.
+func anonymizeJSONText(text string) string {
+	if text == "" {
+		return text
+	}
+	var document interface{}
+	if err := json.Unmarshal([]byte(text), &document); err != nil {
+		return ""
+	}
+	data, err := json.Marshal(anonymizeValue(document))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// pseudonym derives a stable name from text.
+func pseudonym(kind, text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return kind + "_" + hex.EncodeToString(sum[:4])
+}
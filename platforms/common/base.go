@@ -1,6 +1,10 @@
 package common
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/iflytek/agentbridge/internal/models"
 )
 
@@ -20,9 +24,16 @@ func (g *BaseGenerator) GetPlatformType() models.PlatformType {
 	return g.platformType
 }
 
+// ErrDamagedExport marks sources that are cut off or corrupted; models.ParseModeLenient salvages
+// what such sources still hold.
+var ErrDamagedExport = errors.New("damaged export")
+
 // BaseParser provides base implementation for parsers
 type BaseParser struct {
 	platformType models.PlatformType
+	parseMode    string
+	issues       []string // Source problems worked around by the last parse
+	quiet        bool     // Leaves out the summary printed after each parse
 }
 
 func NewBaseParser(platformType models.PlatformType) *BaseParser {
@@ -35,3 +46,48 @@ func NewBaseParser(platformType models.PlatformType) *BaseParser {
 func (p *BaseParser) GetPlatformType() models.PlatformType {
 	return p.platformType
 }
+
+// SetParseMode selects models.ParseModeStrict, models.ParseModeLenient or models.ParseModePermissive, the default.
+func (p *BaseParser) SetParseMode(mode string) {
+	p.parseMode = mode
+}
+
+// SetQuiet leaves out the summary printed after each parse.
+func (p *BaseParser) SetQuiet(quiet bool) {
+	p.quiet = quiet
+}
+
+// Quiet reports whether the summary printed after each parse is left out.
+func (p *BaseParser) Quiet() bool {
+	return p.quiet
+}
+
+// Lenient reports whether damaged sources should be repaired rather than rejected.
+func (p *BaseParser) Lenient() bool {
+	return p.parseMode == models.ParseModeLenient
+}
+
+// ReportIssue records a source problem the parser worked around, such as an unknown node type.
+func (p *BaseParser) ReportIssue(format string, args ...interface{}) {
+	p.issues = append(p.issues, fmt.Sprintf(format, args...))
+}
+
+// Issues returns the source problems found by the last parse.
+func (p *BaseParser) Issues() []string {
+	return append([]string(nil), p.issues...)
+}
+
+// ResetIssues forgets the problems of an earlier parse; parsers call it when a parse starts.
+func (p *BaseParser) ResetIssues() {
+	p.issues = nil
+}
+
+// FinishParse adds the problems found in the parsed DSL to the reported ones. In strict mode it
+// fails when there are any, listing all of them.
+func (p *BaseParser) FinishParse(unifiedDSL *models.UnifiedDSL) error {
+	p.issues = append(p.issues, ParseIssues(unifiedDSL)...)
+	if p.parseMode != models.ParseModeStrict || len(p.issues) == 0 {
+		return nil
+	}
+	return fmt.Errorf("strict parse mode: %s", strings.Join(p.issues, "; "))
+}
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"context"
+	"io"
+)
+
+// contextReader fails reads once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// ContextReader returns a reader that stops with the context error when ctx is cancelled, so that
+// decompressing a large archive entry can be aborted midway.
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: r}
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
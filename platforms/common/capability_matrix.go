@@ -0,0 +1,147 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// SupportLevel describes how a node converts to a target platform.
+type SupportLevel string
+
+const (
+	SupportNative      SupportLevel = "native"      // Generated as the equivalent target node
+	SupportDegraded    SupportLevel = "degraded"    // Replaced by a different node that keeps the wiring
+	SupportUnsupported SupportLevel = "unsupported" // Conversion fails
+)
+
+// NodeCapability describes the conversion of a node type to a target platform.
+type NodeCapability struct {
+	Level       SupportLevel
+	Placeholder bool   // Replaced by a code placeholder that has to be implemented by hand
+	Note        string // How the node is degraded
+}
+
+var (
+	nativeCapability = NodeCapability{Level: SupportNative}
+	stubCapability   = NodeCapability{Level: SupportDegraded, Placeholder: true, Note: "code placeholder carrying the original configuration"}
+	pythonCapability = NodeCapability{Level: SupportDegraded, Note: "equivalent Python code node"}
+	agentCapability  = NodeCapability{Level: SupportDegraded, Note: "LLM node with the same model and prompts; tools must be configured again"}
+)
+
+// capabilityMatrix lists the conversion of every node type per target platform. It mirrors
+// nodeLowerings: native entries reach the target generator unchanged.
+var capabilityMatrix = map[models.NodeType]map[models.PlatformType]NodeCapability{
+	models.NodeTypeStart:      allTargets(nativeCapability),
+	models.NodeTypeEnd:        allTargets(nativeCapability),
+	models.NodeTypeLLM:        allTargets(nativeCapability),
+	models.NodeTypeCode:       allTargets(nativeCapability),
+	models.NodeTypeCondition:  allTargets(nativeCapability),
+	models.NodeTypeClassifier: allTargets(nativeCapability),
+	models.NodeTypeIteration:  allTargets(nativeCapability),
+	models.NodeTypeDataStore: {
+		models.PlatformIFlytek: stubCapability,
+		models.PlatformDify:    stubCapability,
+		models.PlatformCoze:    nativeCapability,
+	},
+	models.NodeTypeAgent: {
+		models.PlatformIFlytek: agentCapability,
+		models.PlatformDify:    nativeCapability,
+		models.PlatformCoze:    agentCapability,
+	},
+	models.NodeTypeHumanInput: {
+		models.PlatformIFlytek: nativeCapability,
+		models.PlatformDify:    {Level: SupportDegraded, Note: "answer node; the reply is read from a conversation variable in the next turn"},
+		models.PlatformCoze:    nativeCapability,
+	},
+	models.NodeTypeTextToSpeech: audioCapabilities(),
+	models.NodeTypeSpeechToText: audioCapabilities(),
+	models.NodeTypeDocumentExtractor: {
+		models.PlatformIFlytek: stubCapability,
+		models.PlatformDify:    nativeCapability,
+		models.PlatformCoze:    stubCapability,
+	},
+	models.NodeTypeListOperation: {
+		models.PlatformIFlytek: pythonCapability,
+		models.PlatformDify:    nativeCapability,
+		models.PlatformCoze:    pythonCapability,
+	},
+	models.NodeTypeJSONProcess: {
+		models.PlatformIFlytek: pythonCapability,
+		models.PlatformDify:    pythonCapability,
+		models.PlatformCoze:    nativeCapability,
+	},
+}
+
+func allTargets(capability NodeCapability) map[models.PlatformType]NodeCapability {
+	return map[models.PlatformType]NodeCapability{
+		models.PlatformIFlytek: capability,
+		models.PlatformDify:    capability,
+		models.PlatformCoze:    capability,
+	}
+}
+
+func audioCapabilities() map[models.PlatformType]NodeCapability {
+	return map[models.PlatformType]NodeCapability{
+		models.PlatformIFlytek: nativeCapability,
+		models.PlatformDify:    stubCapability,
+		models.PlatformCoze:    stubCapability,
+	}
+}
+
+// NodeCapabilityFor returns how node converts to targetPlatform with the given options, which may be nil.
+// Source placeholders for nodes the parser could not translate are reported as stubs; nodes whose
+// lowering depends on their configuration or on the options are refined from the matrix entry.
+func NodeCapabilityFor(node models.Node, targetPlatform models.PlatformType, options *models.ConversionOptions) NodeCapability {
+	if IsPlaceholderNode(node) {
+		return NodeCapability{Level: SupportDegraded, Placeholder: true, Note: "source node without a unified equivalent, kept as a code placeholder"}
+	}
+
+	// The unified DSL keeps every node as it is
+	if targetPlatform == models.PlatformUnified {
+		return nativeCapability
+	}
+
+	capability, exists := capabilityMatrix[node.Type][targetPlatform]
+	if !exists {
+		return NodeCapability{Level: SupportUnsupported, Note: "no generator for this node type"}
+	}
+	if options == nil {
+		options = models.NewConversionOptions()
+	}
+
+	switch node.Type {
+	case models.NodeTypeAgent:
+		if targetPlatform == models.PlatformCoze && hasCozeSkills(node) {
+			capability = nativeCapability
+		}
+	case models.NodeTypeCondition:
+		notes, err := ConditionOperatorIssues(node, targetPlatform)
+		if err != nil {
+			capability = NodeCapability{Level: SupportUnsupported, Note: err.Error()}
+		} else if len(notes) > 0 {
+			capability = NodeCapability{Level: SupportDegraded, Note: strings.Join(notes, "; ")}
+		}
+	case models.NodeTypeTextToSpeech, models.NodeTypeSpeechToText:
+		if capability.Placeholder && options.AudioStrategyFor(node.ID) == models.AudioStrategyHTTP {
+			capability = NodeCapability{Level: SupportDegraded, Note: "code node calling an HTTP speech service; SERVICE_URL must be replaced"}
+		}
+	case models.NodeTypeListOperation:
+		if config, ok := AsListOperationConfig(node.Config); ok && config != nil && config.MapField != "" && targetPlatform == models.PlatformDify {
+			capability = NodeCapability{Level: SupportDegraded, Note: "equivalent Python code node; Dify list operators cannot map fields"}
+		}
+		if capability.Level == SupportDegraded && len(node.Inputs) == 0 {
+			capability = NodeCapability{Level: SupportDegraded, Placeholder: true, Note: "code placeholder; the input list is not connected"}
+		}
+	case models.NodeTypeJSONProcess:
+		if capability.Level == SupportDegraded && len(node.Inputs) == 0 {
+			capability = NodeCapability{Level: SupportDegraded, Placeholder: true, Note: "code placeholder; the input is not connected"}
+		}
+	}
+
+	if capability.Placeholder && options.PlaceholderStrategyFor(node.ID) == models.PlaceholderStrategyFail {
+		capability.Level = SupportUnsupported
+		capability.Note += " (rejected by the fail placeholder strategy)"
+	}
+	return capability
+}
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// classifierExamplesHeading opens the examples block written after the classifier instructions
+const classifierExamplesHeading = "Examples:"
+
+// ClassifierInstructions returns the instructions of a classifier followed by its few-shot
+// examples, one `- "query" -> class name` line each. No platform has a field for examples, so
+// they reach the model through the instructions.
+func ClassifierInstructions(config *models.ClassifierConfig) string {
+	if config == nil {
+		return ""
+	}
+	if len(config.Examples) == 0 {
+		return config.Instructions
+	}
+
+	names := make(map[string]string, len(config.Classes))
+	for _, class := range config.Classes {
+		names[class.ID] = class.Name
+	}
+	var b strings.Builder
+	if config.Instructions != "" {
+		b.WriteString(strings.TrimRight(config.Instructions, "\n"))
+		b.WriteString("\n\n")
+	}
+	b.WriteString(classifierExamplesHeading)
+	for _, example := range config.Examples {
+		name, ok := names[example.Class]
+		if !ok {
+			name = example.Class
+		}
+		fmt.Fprintf(&b, "\n- %s -> %s", strconv.Quote(example.Query), name)
+	}
+	return b.String()
+}
+
+// SplitClassifierExamples takes the examples block ClassifierInstructions writes off the end of
+// instructions and returns the remaining instructions and the examples. Instructions are returned
+// unchanged when they end otherwise or an example names a class the classifier does not have.
+func SplitClassifierExamples(instructions string, classes []models.ClassifierClass) (string, []models.ClassifierExample) {
+	text := strings.TrimRight(instructions, "\n ")
+	start := strings.LastIndex(text, "\n"+classifierExamplesHeading+"\n")
+	prefix := ""
+	switch {
+	case start >= 0:
+		prefix = strings.TrimRight(text[:start], "\n")
+		start += len(classifierExamplesHeading) + 2
+	case strings.HasPrefix(text, classifierExamplesHeading+"\n"):
+		start = len(classifierExamplesHeading) + 1
+	default:
+		return instructions, nil
+	}
+
+	ids := make(map[string]string, len(classes))
+	for _, class := range classes {
+		if _, seen := ids[class.Name]; !seen {
+			ids[class.Name] = class.ID
+		}
+	}
+
+	var examples []models.ClassifierExample
+	for _, line := range strings.Split(text[start:], "\n") {
+		line = strings.TrimSpace(line)
+		body, ok := strings.CutPrefix(line, "- ")
+		sep := strings.LastIndex(body, " -> ")
+		if !ok || sep < 0 {
+			return instructions, nil
+		}
+		query, err := strconv.Unquote(body[:sep])
+		id, known := ids[strings.TrimSpace(body[sep+len(" -> "):])]
+		if err != nil || !known {
+			return instructions, nil
+		}
+		examples = append(examples, models.ClassifierExample{Query: query, Class: id})
+	}
+	return prefix, examples
+}
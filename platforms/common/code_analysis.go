@@ -0,0 +1,701 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Kinds of code findings
+const (
+	CodeFindingImport     = "import"     // A module the code imports
+	CodeFindingNetwork    = "network"    // Network access, blocked by most sandboxes
+	CodeFindingFile       = "file"       // File system access
+	CodeFindingProcess    = "process"    // Starting processes
+	CodeFindingUndeclared = "undeclared" // A variable or parameter that is not an input of the node
+)
+
+// CodeFinding is a statically detected construct of a code node that may fail in the restricted
+// sandbox of a platform.
+type CodeFinding struct {
+	Kind     string               `json:"kind"`
+	Severity models.ErrorSeverity `json:"severity"`
+	Line     int                  `json:"line,omitempty"` // 1-based line of the code, 0 for the whole node
+	Message  string               `json:"message"`
+}
+
+func (f CodeFinding) String() string {
+	if f.Line == 0 {
+		return f.Message
+	}
+	return fmt.Sprintf("line %d: %s", f.Line, f.Message)
+}
+
+// sandboxModules are the third-party modules the code sandboxes of the platforms provide
+var sandboxModules = map[models.PlatformType]map[string]bool{
+	models.PlatformDify: {"requests": true, "httpx": true, "jinja2": true},
+	models.PlatformCoze: {"requests_async": true, "numpy": true},
+}
+
+// sandboxGlobals are names the sandboxes define for the code besides the inputs
+var sandboxGlobals = map[models.PlatformType]map[string]bool{
+	models.PlatformCoze: {"Args": true, "Output": true},
+}
+
+// AnalyzeCode parses the Python or JavaScript code of a code node and reports its imports,
+// network, file and process access, and the variables it reads that are not inputs of the node.
+// Findings are sorted by line; targetPlatform selects the modules and globals its sandbox
+// provides and may be empty. The analysis is lexical: Python names bound anywhere in the code
+// count as declared, and JavaScript code is only checked for the parameters main reads.
+func AnalyzeCode(node *models.Node, targetPlatform models.PlatformType) []CodeFinding {
+	config, ok := AsCodeConfig(node.Config)
+	if !ok || config == nil || strings.TrimSpace(config.Code) == "" {
+		return nil
+	}
+	inputs := make(map[string]bool, len(node.Inputs))
+	for _, input := range node.Inputs {
+		inputs[input.Name] = true
+	}
+
+	analysis := &codeAnalysis{target: targetPlatform, inputs: inputs}
+	switch strings.ToLower(config.Language) {
+	case "javascript", "nodejs", "js":
+		analysis.tokens = tokenizeCode(config.Code, false)
+		analysis.javascript()
+	default:
+		analysis.tokens = tokenizeCode(config.Code, true)
+		analysis.python()
+	}
+	sort.SliceStable(analysis.findings, func(i, j int) bool {
+		return analysis.findings[i].Line < analysis.findings[j].Line
+	})
+	return analysis.findings
+}
+
+// Token kinds
+const (
+	tokenName     = 'n'
+	tokenNumber   = 'd'
+	tokenString   = 's'
+	tokenOperator = 'o'
+	tokenBreak    = 'b' // End of a statement: a line break or semicolon outside brackets
+)
+
+type codeToken struct {
+	kind  byte
+	text  string // Name, operator, or string contents without quotes
+	line  int
+	depth int // Bracket depth the token is at
+}
+
+var codeOperators = []string{
+	"**=", "//=", ">>=", "<<=", "===", "!==", "...",
+	"==", "!=", "<=", ">=", ":=", "->", "=>", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=",
+	"**", "//", "&&", "||", "<<", ">>", "?.", "??",
+}
+
+// tokenizeCode splits Python or JavaScript code into tokens, dropping comments. Python string
+// prefixes are part of the string; interpolations of f-strings and template literals are not
+// tokenized.
+func tokenizeCode(code string, python bool) []codeToken {
+	var tokens []codeToken
+	src := []rune(code)
+	line, depth := 1, 0
+	at := func(i int) rune {
+		if i < len(src) {
+			return src[i]
+		}
+		return 0
+	}
+	statementEnd := func() {
+		if depth == 0 && len(tokens) > 0 && tokens[len(tokens)-1].kind != tokenBreak {
+			tokens = append(tokens, codeToken{kind: tokenBreak, line: line})
+		}
+	}
+
+	for i := 0; i < len(src); {
+		r := src[i]
+		switch {
+		case r == '\n':
+			statementEnd()
+			line++
+			i++
+		case r == '\\' && at(i+1) == '\n':
+			line++
+			i += 2
+		case unicode.IsSpace(r):
+			i++
+		case python && r == '#', !python && r == '/' && at(i+1) == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case !python && r == '/' && at(i+1) == '*':
+			for i += 2; i < len(src) && !(src[i] == '*' && at(i+1) == '/'); i++ {
+				if src[i] == '\n' {
+					line++
+				}
+			}
+			i += 2
+		case r == '"' || r == '\'' || !python && r == '`':
+			start := line
+			var text string
+			i, line, text = scanString(src, i, line, python)
+			tokens = append(tokens, codeToken{kind: tokenString, text: text, line: start, depth: depth})
+		case r == '_' || r == '$' || unicode.IsLetter(r):
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] == '$' || unicode.IsLetter(src[j]) || unicode.IsDigit(src[j])) {
+				j++
+			}
+			name := string(src[i:j])
+			if python && (at(j) == '"' || at(j) == '\'') && isStringPrefix(name) {
+				i = j
+				continue
+			}
+			tokens = append(tokens, codeToken{kind: tokenName, text: name, line: line, depth: depth})
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(src) && (src[j] == '.' || src[j] == '_' || unicode.IsLetter(src[j]) || unicode.IsDigit(src[j])) {
+				j++
+			}
+			tokens = append(tokens, codeToken{kind: tokenNumber, text: string(src[i:j]), line: line, depth: depth})
+			i = j
+		default:
+			operator := string(r)
+			for _, candidate := range codeOperators {
+				if strings.HasPrefix(string(src[i:minInt(i+len(candidate), len(src))]), candidate) {
+					operator = candidate
+					break
+				}
+			}
+			switch operator {
+			case ")", "]", "}":
+				if depth > 0 {
+					depth--
+				}
+			case ";":
+				statementEnd()
+				i++
+				continue
+			}
+			tokens = append(tokens, codeToken{kind: tokenOperator, text: operator, line: line, depth: depth})
+			if operator == "(" || operator == "[" || operator == "{" {
+				depth++
+			}
+			i += len([]rune(operator))
+		}
+	}
+	statementEnd()
+	return tokens
+}
+
+// scanString reads the string starting at the quote src[i] and returns the position after it, the
+// line it ends on and its contents
+func scanString(src []rune, i, line int, python bool) (int, int, string) {
+	quote := string(src[i])
+	if python && i+2 < len(src) && src[i+1] == src[i] && src[i+2] == src[i] {
+		quote = strings.Repeat(quote, 3)
+	}
+	var text strings.Builder
+	for i += len(quote); i < len(src); i++ {
+		if src[i] == '\\' && i+1 < len(src) {
+			text.WriteRune(src[i+1])
+			i++
+			continue
+		}
+		if strings.HasPrefix(string(src[i:minInt(i+len(quote), len(src))]), quote) {
+			return i + len(quote), line, text.String()
+		}
+		if src[i] == '\n' {
+			line++
+			if len(quote) == 1 && quote != "`" {
+				return i, line - 1, text.String()
+			}
+		}
+		text.WriteRune(src[i])
+	}
+	return len(src), line, text.String()
+}
+
+func isStringPrefix(name string) bool {
+	switch strings.ToLower(name) {
+	case "r", "b", "u", "f", "rb", "br", "fr", "rf":
+		return true
+	}
+	return false
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// codeAnalysis collects the findings of one code node
+type codeAnalysis struct {
+	target   models.PlatformType
+	inputs   map[string]bool
+	tokens   []codeToken
+	findings []CodeFinding
+	reported map[string]bool
+}
+
+func (a *codeAnalysis) add(kind string, severity models.ErrorSeverity, line int, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if a.reported == nil {
+		a.reported = make(map[string]bool)
+	}
+	if a.reported[kind+message] {
+		return
+	}
+	a.reported[kind+message] = true
+	a.findings = append(a.findings, CodeFinding{Kind: kind, Severity: severity, Line: line, Message: message})
+}
+
+// sandboxName names the sandbox of the target in messages
+func (a *codeAnalysis) sandboxName() string {
+	if a.target == "" || a.target == models.PlatformUnified {
+		return "the target sandbox"
+	}
+	return fmt.Sprintf("the %s sandbox", a.target)
+}
+
+func (a *codeAnalysis) token(i int) codeToken {
+	if i >= 0 && i < len(a.tokens) {
+		return a.tokens[i]
+	}
+	return codeToken{}
+}
+
+func (a *codeAnalysis) is(i int, kind byte, text string) bool {
+	token := a.token(i)
+	return token.kind == kind && token.text == text
+}
+
+// Modules by what they give access to; the first path element of an import is matched
+var (
+	networkModules = map[string]bool{
+		"requests": true, "requests_async": true, "urllib": true, "urllib3": true, "http": true, "httpx": true,
+		"aiohttp": true, "socket": true, "ftplib": true, "smtplib": true, "websocket": true, "websockets": true,
+		"https": true, "net": true, "tls": true, "dgram": true, "dns": true, "axios": true, "node-fetch": true,
+		"ws": true, "got": true, "request": true, "undici": true,
+	}
+	fileModules    = map[string]bool{"pathlib": true, "shutil": true, "tempfile": true, "glob": true, "fs": true}
+	processModules = map[string]bool{"subprocess": true, "multiprocessing": true, "ctypes": true, "child_process": true, "cluster": true}
+)
+
+// importModule reports an imported module by what it accesses, or as a plain import
+func (a *codeAnalysis) importModule(module string, line int, standard map[string]bool) {
+	module = strings.TrimPrefix(module, "node:")
+	root := module
+	if parts := strings.FieldsFunc(module, func(r rune) bool { return r == '.' || r == '/' }); len(parts) > 0 && !strings.HasPrefix(module, "@") {
+		root = parts[0]
+	}
+	if root == "" {
+		return
+	}
+	switch {
+	case networkModules[root]:
+		a.add(CodeFindingNetwork, models.SeverityWarning, line, "imports %s for network access, which %s may block", module, a.sandboxName())
+	case fileModules[root]:
+		a.add(CodeFindingFile, models.SeverityWarning, line, "imports %s for file access, which %s may not allow", module, a.sandboxName())
+	case processModules[root]:
+		a.add(CodeFindingProcess, models.SeverityWarning, line, "imports %s to start processes, which sandboxes do not allow", module)
+	case standard[root] || sandboxModules[a.target][root]:
+		a.add(CodeFindingImport, models.SeverityInfo, line, "imports %s", module)
+	default:
+		a.add(CodeFindingImport, models.SeverityWarning, line, "imports %s, which is not a standard module and may be missing from %s", module, a.sandboxName())
+	}
+}
+
+// python analyzes Python code: imports, access calls, the parameters of main and unbound names
+func (a *codeAnalysis) python() {
+	bound := make(map[string]bool)
+	skip := make(map[int]bool) // Tokens that are not reads: import statements, annotations, keyword arguments
+	var mainParams []codeParam
+	hasMain := false
+
+	for start := 0; start < len(a.tokens); {
+		end := start
+		for end < len(a.tokens) && a.tokens[end].kind != tokenBreak {
+			end++
+		}
+		a.pythonStatement(start, end, bound, skip)
+		for i := start; i < end; i++ {
+			if a.is(i, tokenName, "def") && a.is(i+1, tokenName, "main") && a.is(i+2, tokenOperator, "(") {
+				hasMain = true
+				mainParams = a.params(i+2, end)
+			}
+		}
+		start = end + 1
+	}
+
+	for i, token := range a.tokens {
+		if token.kind != tokenName || skip[i] || a.is(i-1, tokenOperator, ".") {
+			continue
+		}
+		switch {
+		case token.text == "open" && a.is(i+1, tokenOperator, "("):
+			a.add(CodeFindingFile, models.SeverityWarning, token.line, "opens files, which %s may not allow", a.sandboxName())
+		case token.text == "os" && a.is(i+1, tokenOperator, "."):
+			call := a.token(i + 2).text
+			if osFileCalls[call] {
+				a.add(CodeFindingFile, models.SeverityWarning, token.line, "calls os.%s for file access, which %s may not allow", call, a.sandboxName())
+			} else if osProcessCalls[call] || strings.HasPrefix(call, "exec") || strings.HasPrefix(call, "spawn") {
+				a.add(CodeFindingProcess, models.SeverityWarning, token.line, "calls os.%s to start processes, which sandboxes do not allow", call)
+			}
+		}
+		if !bound[token.text] && !pythonKeywords[token.text] && !pythonBuiltins[token.text] && !sandboxGlobals[a.target][token.text] {
+			a.add(CodeFindingUndeclared, models.SeverityError, token.line, "reads %s, which is neither an input nor defined in the code", token.text)
+		}
+	}
+
+	if !hasMain {
+		a.add(CodeFindingUndeclared, models.SeverityError, 0, "the code defines no main function")
+		return
+	}
+	a.checkMainParams(mainParams, "args")
+}
+
+// pythonStatement marks the names a statement binds and the tokens that are not reads
+func (a *codeAnalysis) pythonStatement(start, end int, bound map[string]bool, skip map[int]bool) {
+	first := a.token(start)
+	switch {
+	case first.kind == tokenName && (first.text == "import" || first.text == "from"):
+		a.pythonImport(start, end, bound)
+		for i := start; i < end; i++ {
+			skip[i] = true
+		}
+		return
+	case first.kind == tokenName && (first.text == "global" || first.text == "nonlocal"):
+		for i := start + 1; i < end; i++ {
+			if a.tokens[i].kind == tokenName {
+				bound[a.tokens[i].text] = true
+			}
+		}
+		return
+	case first.kind == tokenName && !pythonKeywords[first.text] && a.is(start+1, tokenOperator, ":"):
+		// Annotated assignment; local annotations are not evaluated
+		bound[first.text] = true
+		for i := start + 1; i < end && !a.is(i, tokenOperator, "="); i++ {
+			skip[i] = true
+		}
+	}
+
+	lastColon, assignment := start-1, -1
+	for i := start; i < end; i++ {
+		token := a.tokens[i]
+		switch {
+		case token.kind == tokenName && (token.text == "def" || token.text == "class"):
+			bound[a.token(i+1).text] = true
+			skip[i+1] = true
+			if token.text == "def" && a.is(i+2, tokenOperator, "(") {
+				for _, param := range a.params(i+2, end) {
+					bound[param.name] = true
+				}
+			}
+		case token.kind == tokenName && token.text == "lambda":
+			for j := i + 1; j < end && !a.is(j, tokenOperator, ":"); j++ {
+				if a.tokens[j].kind == tokenName {
+					bound[a.tokens[j].text] = true
+				}
+			}
+		case token.kind == tokenName && token.text == "for":
+			for j := i + 1; j < end && !a.is(j, tokenName, "in"); j++ {
+				if a.tokens[j].kind == tokenName {
+					bound[a.tokens[j].text] = true
+				}
+			}
+		case token.kind == tokenName && token.text == "as":
+			bound[a.token(i+1).text] = true
+		case token.kind == tokenOperator && token.text == ":=":
+			bound[a.token(i-1).text] = true
+		case token.kind == tokenOperator && token.text == ":" && token.depth == first.depth:
+			lastColon = i
+		case token.kind == tokenOperator && token.text == "=":
+			if token.depth > first.depth && a.token(i-1).kind == tokenName {
+				skip[i-1] = true // Keyword argument or parameter default
+			} else if token.depth == first.depth {
+				assignment = i
+			}
+		}
+	}
+	if assignment < 0 {
+		return
+	}
+	for i := lastColon + 1; i < assignment; i++ {
+		if a.tokens[i].kind != tokenName || a.is(i-1, tokenOperator, ".") {
+			continue
+		}
+		if next := a.token(i + 1); next.kind == tokenOperator && (next.text == "." || next.text == "[" || next.text == "(") {
+			continue
+		}
+		bound[a.tokens[i].text] = true
+	}
+}
+
+// pythonImport reports the modules of an import statement and binds the names it imports
+func (a *codeAnalysis) pythonImport(start, end int, bound map[string]bool) {
+	line := a.tokens[start].line
+	dotted := func(i int) (string, int) {
+		var name strings.Builder
+		for ; i < end && (a.tokens[i].kind == tokenName || a.is(i, tokenOperator, ".") || a.is(i, tokenOperator, "...")); i++ {
+			if a.is(i, tokenName, "import") || a.is(i, tokenName, "as") {
+				break
+			}
+			name.WriteString(a.tokens[i].text)
+		}
+		return name.String(), i
+	}
+
+	if a.tokens[start].text == "from" {
+		module, i := dotted(start + 1)
+		if !strings.HasPrefix(module, ".") {
+			a.importModule(module, line, pythonStandardModules)
+		}
+		for i++; i < end; i++ {
+			if a.tokens[i].kind == tokenName && !a.is(i+1, tokenName, "as") && a.tokens[i].text != "as" {
+				bound[a.tokens[i].text] = true
+			}
+		}
+		return
+	}
+	for i := start + 1; i < end; i++ {
+		module, next := dotted(i)
+		if module == "" {
+			continue
+		}
+		a.importModule(module, line, pythonStandardModules)
+		if a.is(next, tokenName, "as") {
+			bound[a.token(next+1).text] = true
+			next += 2
+		} else {
+			bound[strings.Split(module, ".")[0]] = true
+		}
+		i = next
+	}
+}
+
+// codeParam is a parameter of main
+type codeParam struct {
+	name       string
+	optional   bool     // Has a default value
+	variadic   bool     // *args or **kwargs, or a rest element
+	destructed []string // Keys of a destructured JavaScript object parameter
+}
+
+// params reads the parameters of the function whose parameter list opens at tokens[open]
+func (a *codeAnalysis) params(open, end int) []codeParam {
+	var params []codeParam
+	depth := a.tokens[open].depth + 1
+	expectName := true
+	variadic := false
+	for i := open + 1; i < end; i++ {
+		token := a.tokens[i]
+		if token.depth < depth {
+			break
+		}
+		if token.depth > depth {
+			continue
+		}
+		switch {
+		case token.kind == tokenOperator && (token.text == "*" || token.text == "**" || token.text == "..."):
+			variadic = true
+		case token.kind == tokenOperator && token.text == "{" && expectName:
+			param := codeParam{name: "{}"}
+			for j := i + 1; j < end && a.tokens[j].depth > depth; j++ {
+				if a.tokens[j].kind == tokenName && a.tokens[j].depth == depth+1 && (a.is(j-1, tokenOperator, "{") || a.is(j-1, tokenOperator, ",")) {
+					param.destructed = append(param.destructed, a.tokens[j].text)
+				}
+			}
+			params = append(params, param)
+			expectName = false
+		case token.kind == tokenName && expectName:
+			params = append(params, codeParam{name: token.text, variadic: variadic})
+			expectName, variadic = false, false
+		case token.kind == tokenOperator && token.text == "=" && len(params) > 0:
+			params[len(params)-1].optional = true
+		case token.kind == tokenOperator && token.text == ",":
+			expectName = true
+		}
+	}
+	if len(params) > 0 && params[0].name == "self" {
+		params = params[1:]
+	}
+	return params
+}
+
+// checkMainParams compares the parameters of main with the inputs of the node. Code taking a
+// single wrapper parameter, as Coze does, is checked for the keys it reads from it instead.
+func (a *codeAnalysis) checkMainParams(params []codeParam, wrapper string) {
+	if len(params) == 1 && (params[0].name == wrapper || params[0].name == "params" || containsString(params[0].destructed, "params")) {
+		a.checkParamReads()
+		return
+	}
+
+	acceptsAll := false
+	declared := make(map[string]bool)
+	for _, param := range params {
+		names := param.destructed
+		if param.destructed == nil {
+			names = []string{param.name}
+		}
+		for _, name := range names {
+			declared[name] = true
+			if param.variadic {
+				acceptsAll = true
+				continue
+			}
+			if !a.inputs[name] && !param.optional {
+				a.add(CodeFindingUndeclared, models.SeverityError, 0, "main takes %s, which is not an input of the node", name)
+			}
+		}
+	}
+	if acceptsAll {
+		return
+	}
+	names := make([]string, 0, len(a.inputs))
+	for name := range a.inputs {
+		if !declared[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a.add(CodeFindingUndeclared, models.SeverityWarning, 0, "input %s is not a parameter of main; platforms passing inputs by name fail", name)
+	}
+}
+
+// checkParamReads reports keys read from params that are not inputs: params.get("x"), params["x"]
+// and params.x
+func (a *codeAnalysis) checkParamReads() {
+	for i, token := range a.tokens {
+		if token.kind != tokenName || token.text != "params" {
+			continue
+		}
+		var key string
+		switch {
+		case a.is(i+1, tokenOperator, ".") && a.is(i+2, tokenName, "get") && a.is(i+3, tokenOperator, "(") && a.token(i+4).kind == tokenString:
+			key = a.token(i + 4).text
+		case a.is(i+1, tokenOperator, "[") && a.token(i+2).kind == tokenString:
+			key = a.token(i + 2).text
+		case (a.is(i+1, tokenOperator, ".") || a.is(i+1, tokenOperator, "?.")) && a.token(i+2).kind == tokenName && !a.is(i+3, tokenOperator, "("):
+			key = a.token(i + 2).text
+		}
+		if key != "" && !a.inputs[key] {
+			a.add(CodeFindingUndeclared, models.SeverityError, token.line, "reads parameter %s, which is not an input of the node", key)
+		}
+	}
+}
+
+// javascript analyzes JavaScript code: imports, access through globals and the parameters of main
+func (a *codeAnalysis) javascript() {
+	var mainParams []codeParam
+	hasMain := false
+	for i, token := range a.tokens {
+		switch {
+		case token.kind == tokenName && (token.text == "require" || token.text == "import") && a.is(i+1, tokenOperator, "(") && a.token(i+2).kind == tokenString:
+			a.importModule(a.token(i+2).text, token.line, nodeStandardModules)
+		case token.kind == tokenName && token.text == "import" && token.depth == 0 && !a.is(i+1, tokenOperator, "("):
+			for j := i + 1; j < len(a.tokens) && a.tokens[j].kind != tokenBreak; j++ {
+				if a.tokens[j].kind == tokenString {
+					a.importModule(a.tokens[j].text, token.line, nodeStandardModules)
+					break
+				}
+			}
+		case token.kind == tokenName && javascriptNetworkGlobals[token.text] && !a.is(i-1, tokenOperator, "."):
+			a.add(CodeFindingNetwork, models.SeverityWarning, token.line, "uses %s for network access, which %s may block", token.text, a.sandboxName())
+		case token.kind == tokenName && token.text == "main" && !a.is(i-1, tokenOperator, "."):
+			open := i + 1
+			if a.is(open, tokenOperator, "=") {
+				open++
+				if a.is(open, tokenName, "async") {
+					open++
+				}
+				if a.is(open, tokenName, "function") {
+					open++
+				}
+			}
+			if a.is(open, tokenOperator, "(") && (a.is(i-1, tokenName, "function") || a.is(i+1, tokenOperator, "=")) {
+				hasMain = true
+				mainParams = a.params(open, len(a.tokens))
+			}
+		}
+	}
+	if !hasMain {
+		a.add(CodeFindingUndeclared, models.SeverityError, 0, "the code defines no main function")
+		return
+	}
+	a.checkMainParams(mainParams, "args")
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+var osFileCalls = map[string]bool{
+	"open": true, "remove": true, "unlink": true, "listdir": true, "scandir": true, "walk": true, "mkdir": true,
+	"makedirs": true, "rmdir": true, "removedirs": true, "rename": true, "replace": true, "chdir": true, "chmod": true,
+}
+
+var osProcessCalls = map[string]bool{"system": true, "popen": true, "fork": true, "kill": true}
+
+var javascriptNetworkGlobals = map[string]bool{"fetch": true, "XMLHttpRequest": true, "WebSocket": true}
+
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true, "await": true,
+	"break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true, "if": true, "import": true,
+	"in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true, "match": true, "case": true,
+}
+
+var pythonBuiltins = map[string]bool{
+	"abs": true, "all": true, "any": true, "ascii": true, "bin": true, "bool": true, "breakpoint": true,
+	"bytearray": true, "bytes": true, "callable": true, "chr": true, "classmethod": true, "compile": true,
+	"complex": true, "delattr": true, "dict": true, "dir": true, "divmod": true, "enumerate": true, "eval": true,
+	"exec": true, "filter": true, "float": true, "format": true, "frozenset": true, "getattr": true,
+	"globals": true, "hasattr": true, "hash": true, "help": true, "hex": true, "id": true, "input": true,
+	"int": true, "isinstance": true, "issubclass": true, "iter": true, "len": true, "list": true, "locals": true,
+	"map": true, "max": true, "memoryview": true, "min": true, "next": true, "object": true, "oct": true,
+	"open": true, "ord": true, "pow": true, "print": true, "property": true, "range": true, "repr": true,
+	"reversed": true, "round": true, "set": true, "setattr": true, "slice": true, "sorted": true,
+	"staticmethod": true, "str": true, "sum": true, "super": true, "tuple": true, "type": true, "vars": true,
+	"zip": true, "__name__": true, "__file__": true, "__doc__": true, "__import__": true, "self": true,
+	"Exception": true, "BaseException": true, "ValueError": true, "TypeError": true, "KeyError": true,
+	"IndexError": true, "AttributeError": true, "RuntimeError": true, "StopIteration": true,
+	"ZeroDivisionError": true, "NotImplementedError": true, "NotImplemented": true, "Ellipsis": true,
+	"ImportError": true, "ModuleNotFoundError": true, "OSError": true, "IOError": true, "FileNotFoundError": true,
+	"TimeoutError": true, "ConnectionError": true, "AssertionError": true, "LookupError": true,
+	"ArithmeticError": true, "OverflowError": true, "UnicodeDecodeError": true, "UnicodeEncodeError": true,
+	"RecursionError": true, "PermissionError": true, "StopAsyncIteration": true,
+}
+
+// pythonStandardModules are the standard library modules code nodes commonly import
+var pythonStandardModules = map[string]bool{
+	"abc": true, "argparse": true, "array": true, "ast": true, "asyncio": true, "base64": true, "binascii": true,
+	"bisect": true, "calendar": true, "cmath": true, "codecs": true, "collections": true, "contextlib": true,
+	"copy": true, "csv": true, "dataclasses": true, "datetime": true, "decimal": true, "difflib": true,
+	"enum": true, "fractions": true, "functools": true, "gzip": true, "hashlib": true, "heapq": true,
+	"hmac": true, "html": true, "inspect": true, "io": true, "itertools": true, "json": true, "logging": true,
+	"math": true, "numbers": true, "operator": true, "os": true, "pprint": true, "queue": true, "random": true,
+	"re": true, "secrets": true, "statistics": true, "string": true, "struct": true, "sys": true,
+	"textwrap": true, "threading": true, "time": true, "traceback": true, "types": true, "typing": true,
+	"unicodedata": true, "uuid": true, "warnings": true, "xml": true, "zlib": true, "zoneinfo": true,
+}
+
+// nodeStandardModules are the Node.js core modules
+var nodeStandardModules = map[string]bool{
+	"assert": true, "buffer": true, "crypto": true, "events": true, "os": true, "path": true, "process": true,
+	"querystring": true, "readline": true, "stream": true, "string_decoder": true, "timers": true, "url": true,
+	"util": true, "v8": true, "vm": true, "worker_threads": true, "zlib": true, "perf_hooks": true,
+}
@@ -0,0 +1,312 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Horizontal gap between the last node of a workflow and the first node appended after it
+const composeSpacing = 300
+
+// AppendWorkflow appends second after first in place, so the result runs first and then second.
+// The end node of first and the start node of second are dropped: the nodes leading to the end of
+// first continue with the nodes following the start of second, and second reads its start
+// variables from the end outputs of first.
+//
+// mapping names the end output of first that feeds a start variable of second; unlisted variables
+// take the output of the same name. Variables without such an output become start variables of
+// first, which are shared when both workflows have one of the same name. Node and edge IDs of
+// second that first already uses are renamed. The returned warnings name the added variables.
+func AppendWorkflow(first, second *models.UnifiedDSL, mapping map[string]string) ([]string, error) {
+	if first == nil || second == nil {
+		return nil, fmt.Errorf("both workflows are required")
+	}
+	start, err := topLevelNode(first, models.NodeTypeStart)
+	if err != nil {
+		return nil, fmt.Errorf("first workflow: %w", err)
+	}
+	end, err := topLevelNode(first, models.NodeTypeEnd)
+	if err != nil {
+		return nil, fmt.Errorf("first workflow: %w", err)
+	}
+	renameCollisions(first, second)
+	join, err := topLevelNode(second, models.NodeTypeStart)
+	if err != nil {
+		return nil, fmt.Errorf("second workflow: %w", err)
+	}
+
+	targets, warnings, err := joinVariables(start, end, join, mapping)
+	if err != nil {
+		return nil, err
+	}
+	endID, joinID := end.ID, join.ID
+	for i := range second.Workflow.Nodes {
+		rewriteNodeReferences(&second.Workflow.Nodes[i], func(nodeID, output string) (string, string, bool) {
+			target, ok := targets[output]
+			if nodeID != joinID || !ok {
+				return "", "", false
+			}
+			return target.NodeID, target.OutputName, true
+		})
+	}
+
+	shiftWorkflow(second, maxTopLevelX(first)+composeSpacing)
+	first.Workflow.Edges = joinEdges(first.Workflow.Edges, second.Workflow.Edges, endID, joinID)
+	nodes := make([]models.Node, 0, len(first.Workflow.Nodes)+len(second.Workflow.Nodes)-2)
+	for _, node := range first.Workflow.Nodes {
+		if node.ID != endID {
+			nodes = append(nodes, node)
+		}
+	}
+	for _, node := range second.Workflow.Nodes {
+		if node.ID != joinID {
+			nodes = append(nodes, node)
+		}
+	}
+	first.Workflow.Nodes = nodes
+
+	// Conversation variables of both workflows, first wins on equal names
+	for _, variable := range second.Workflow.Variables {
+		found := false
+		for _, existing := range first.Workflow.Variables {
+			found = found || existing.Name == variable.Name
+		}
+		if !found {
+			first.Workflow.Variables = append(first.Workflow.Variables, variable)
+		}
+	}
+	return warnings, nil
+}
+
+// topLevelNode returns the only top-level node of a type, iteration starts and ends aside
+func topLevelNode(unifiedDSL *models.UnifiedDSL, nodeType models.NodeType) (*models.Node, error) {
+	var found []*models.Node
+	for i := range unifiedDSL.Workflow.Nodes {
+		node := &unifiedDSL.Workflow.Nodes[i]
+		if node.Type == nodeType && IterationParent(node) == "" {
+			if config, ok := AsStartConfig(node.Config); ok && config != nil && config.IsInIteration {
+				continue
+			}
+			found = append(found, node)
+		}
+	}
+	if len(found) != 1 {
+		return nil, fmt.Errorf("needs exactly one %s node, has %d", nodeType, len(found))
+	}
+	return found[0], nil
+}
+
+// joinVariables returns the reference replacing each start variable of the joined workflow: the
+// reference of the matching end output, or a start variable of the first workflow
+func joinVariables(start, end, join *models.Node, mapping map[string]string) (map[string]models.VariableReference, []string, error) {
+	variables := make(map[string]bool)
+	for _, output := range join.Outputs {
+		variables[output.Name] = true
+	}
+	var unknown []string
+	for variable := range mapping {
+		if !variables[variable] {
+			unknown = append(unknown, variable)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, nil, fmt.Errorf("second workflow has no start variables %v", unknown)
+	}
+
+	endOutputs := make(map[string]*models.VariableReference)
+	for _, input := range end.Inputs {
+		if input.Reference != nil && input.Reference.NodeID != "" {
+			endOutputs[input.Name] = input.Reference
+		}
+	}
+
+	targets := make(map[string]models.VariableReference)
+	var warnings []string
+	for _, output := range join.Outputs {
+		name, mapped := mapping[output.Name]
+		if !mapped {
+			name = output.Name
+		}
+		if reference := endOutputs[name]; reference != nil {
+			targets[output.Name] = *reference
+			continue
+		}
+		if mapped {
+			return nil, nil, fmt.Errorf("first workflow has no end output %q for start variable %q", name, output.Name)
+		}
+
+		if !hasOutput(start, output.Name) {
+			addStartVariable(start, join, output)
+			warnings = append(warnings, fmt.Sprintf("start variable %q of the second workflow matches no end output of the first; it is added to the start node", output.Name))
+		}
+		targets[output.Name] = models.VariableReference{
+			Type:       models.ReferenceTypeNodeOutput,
+			NodeID:     start.ID,
+			OutputName: output.Name,
+			DataType:   output.Type,
+		}
+	}
+	return targets, warnings, nil
+}
+
+func hasOutput(node *models.Node, name string) bool {
+	for _, output := range node.Outputs {
+		if output.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addStartVariable copies a start variable of the joined workflow to the start node
+func addStartVariable(start, join *models.Node, output models.Output) {
+	start.Outputs = append(start.Outputs, output)
+	config, ok := AsStartConfig(start.Config)
+	if !ok || config == nil {
+		return
+	}
+	variable := models.Variable{Name: output.Name, Label: output.Label, Type: string(output.Type), Required: output.Required}
+	if joinConfig, ok := AsStartConfig(join.Config); ok && joinConfig != nil {
+		for _, candidate := range joinConfig.Variables {
+			if candidate.Name == output.Name {
+				variable = candidate
+			}
+		}
+	}
+	config.Variables = append(config.Variables, variable)
+	start.Config = *config
+}
+
+// joinEdges connects the nodes leading to the end of the first workflow to the nodes following
+// the start of the second, keeping the source handles of branches
+func joinEdges(first, second []models.Edge, endID, joinID string) []models.Edge {
+	var next []string
+	var edges []models.Edge
+	for _, edge := range second {
+		if edge.Source == joinID {
+			next = append(next, edge.Target)
+		}
+	}
+
+	for _, edge := range first {
+		if edge.Target != endID {
+			edges = append(edges, edge)
+			continue
+		}
+		for _, target := range next {
+			joined := edge
+			joined.ID = fmt.Sprintf("%s-%s-%s", edge.Source, edge.SourceHandle, target)
+			joined.Target = target
+			edges = append(edges, joined)
+		}
+	}
+	for _, edge := range second {
+		if edge.Source != joinID {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// renameCollisions gives the nodes and edges of second that share an ID with first a new ID
+func renameCollisions(first, second *models.UnifiedDSL) {
+	used := make(map[string]bool)
+	collectNodeIDs(first.Workflow.Nodes, used)
+	secondIDs := make(map[string]bool)
+	collectNodeIDs(second.Workflow.Nodes, secondIDs)
+
+	ids := make(map[string]string)
+	for _, id := range sortedKeys(secondIDs) {
+		if !used[id] {
+			continue
+		}
+		renamed := id
+		for n := 2; used[renamed] || secondIDs[renamed]; n++ {
+			renamed = fmt.Sprintf("%s_%d", id, n)
+		}
+		used[renamed] = true
+		ids[id] = renamed
+	}
+
+	edgeIDs := make(map[string]bool)
+	for _, edge := range first.Workflow.Edges {
+		edgeIDs[edge.ID] = true
+	}
+	for i := range second.Workflow.Edges {
+		edge := &second.Workflow.Edges[i]
+		for n := 2; edge.ID != "" && edgeIDs[edge.ID]; n++ {
+			edge.ID = fmt.Sprintf("%s_%d", edge.ID, n)
+		}
+		edgeIDs[edge.ID] = true
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	renameNodes(second.Workflow.Nodes, second.Workflow.Edges, ids)
+	for i := range second.Workflow.Nodes {
+		rewriteNodeReferences(&second.Workflow.Nodes[i], func(nodeID, output string) (string, string, bool) {
+			renamed, ok := ids[nodeID]
+			return renamed, output, ok
+		})
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// renameNodes renames nodes and the ends of edges, sub-workflows included
+func renameNodes(nodes []models.Node, edges []models.Edge, ids map[string]string) {
+	for i := range nodes {
+		if renamed, ok := ids[nodes[i].ID]; ok {
+			nodes[i].ID = renamed
+		}
+		if iterConfig, ok := AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+			renameNodes(iterConfig.SubWorkflow.Nodes, iterConfig.SubWorkflow.Edges, ids)
+		}
+	}
+	for i := range edges {
+		if renamed, ok := ids[edges[i].Source]; ok {
+			edges[i].Source = renamed
+		}
+		if renamed, ok := ids[edges[i].Target]; ok {
+			edges[i].Target = renamed
+		}
+	}
+}
+
+// maxTopLevelX returns the right-most position of the top-level nodes
+func maxTopLevelX(unifiedDSL *models.UnifiedDSL) float64 {
+	maxX := 0.0
+	for i, node := range unifiedDSL.Workflow.Nodes {
+		if IterationParent(&unifiedDSL.Workflow.Nodes[i]) == "" && node.Position.X > maxX {
+			maxX = node.Position.X
+		}
+	}
+	return maxX
+}
+
+// shiftWorkflow moves the top-level nodes so the left-most one is at x. Nodes inside iterations
+// are placed relative to their iteration and keep their position.
+func shiftWorkflow(unifiedDSL *models.UnifiedDSL, x float64) {
+	minX, first := 0.0, true
+	for i, node := range unifiedDSL.Workflow.Nodes {
+		if IterationParent(&unifiedDSL.Workflow.Nodes[i]) == "" && (first || node.Position.X < minX) {
+			minX, first = node.Position.X, false
+		}
+	}
+	for i := range unifiedDSL.Workflow.Nodes {
+		if IterationParent(&unifiedDSL.Workflow.Nodes[i]) == "" {
+			unifiedDSL.Workflow.Nodes[i].Position.X += x - minX
+		}
+	}
+}
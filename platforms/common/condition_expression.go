@@ -0,0 +1,255 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// maxConditionDisjuncts bounds the branches a nested case may be split into
+const maxConditionDisjuncts = 16
+
+// LowerConditionExpressions rewrites condition cases with nested and/or groups into the flat
+// cases every platform has, including iteration sub-workflows. A nested case becomes the
+// disjunctive normal form of its expression: one "and" case per alternative, in a row and with
+// copies of the edges of the case, so the first case that holds takes the same targets as the
+// expression. Expressions that flatten without splitting, such as a or (b or c), stay one case.
+// It returns a warning per split case and an error for expressions too large to split.
+func LowerConditionExpressions(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) ([]string, error) {
+	if unifiedDSL == nil || targetPlatform == models.PlatformUnified {
+		return nil, nil
+	}
+
+	lowering := &expressionLowering{splits: make(map[string]map[string][]string), done: make(map[string]bool)}
+	if err := lowering.lowerNodes(unifiedDSL.Workflow.Nodes); err != nil {
+		return nil, err
+	}
+	if len(lowering.splits) == 0 {
+		return lowering.warnings, nil
+	}
+	unifiedDSL.Workflow.Edges = lowering.splitEdges(unifiedDSL.Workflow.Edges)
+	lowering.splitSubWorkflowEdges(unifiedDSL.Workflow.Nodes)
+	return lowering.warnings, nil
+}
+
+// expressionLowering collects the cases split per node, so that the edges of every workflow level
+// can follow them
+type expressionLowering struct {
+	splits   map[string]map[string][]string // Node ID -> case ID -> IDs of the cases it was split into
+	done     map[string]bool                // Nodes lowered, which may be listed on several levels
+	warnings []string
+}
+
+func (l *expressionLowering) lowerNodes(nodes []models.Node) error {
+	for i := range nodes {
+		node := &nodes[i]
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			if err := l.lowerNodes(iterConfig.SubWorkflow.Nodes); err != nil {
+				return err
+			}
+			continue
+		}
+		config, ok := AsConditionConfig(node.Config)
+		if node.Type != models.NodeTypeCondition || !ok || config == nil {
+			continue
+		}
+		if err := l.lowerNode(node, config); err != nil {
+			return fmt.Errorf("condition node %q: %w", node.Title, err)
+		}
+	}
+	return nil
+}
+
+// lowerNode replaces the nested cases of a condition node by flat ones
+func (l *expressionLowering) lowerNode(node *models.Node, config *models.ConditionConfig) error {
+	nested := false
+	for _, conditionCase := range config.Cases {
+		nested = nested || conditionCase.Expression != nil
+	}
+	if !nested {
+		return nil
+	}
+
+	caseIDs := make(map[string]bool, len(config.Cases))
+	for _, conditionCase := range config.Cases {
+		caseIDs[conditionCase.CaseID] = true
+	}
+	ordered := append([]models.ConditionCase(nil), config.Cases...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Level < ordered[j].Level
+	})
+
+	split := false
+	cases := make([]models.ConditionCase, 0, len(ordered))
+	for _, conditionCase := range ordered {
+		if conditionCase.Expression == nil {
+			cases = append(cases, conditionCase)
+			continue
+		}
+		disjuncts, err := conditionDisjuncts(*conditionCase.Expression)
+		if err != nil {
+			return fmt.Errorf("case %s: %w", conditionCase.CaseID, err)
+		}
+		if len(disjuncts) == 0 {
+			return fmt.Errorf("case %s: the expression has no conditions", conditionCase.CaseID)
+		}
+
+		flat := conditionCase
+		flat.Expression = nil
+		if conditions, operator, ok := flattenDisjuncts(disjuncts); ok {
+			flat.Conditions, flat.LogicalOperator = conditions, operator
+			cases = append(cases, flat)
+			continue
+		}
+
+		split = true
+		ids := make([]string, 0, len(disjuncts)-1)
+		for i, disjunct := range disjuncts {
+			part := flat
+			part.Conditions, part.LogicalOperator = disjunct, models.LogicalAnd
+			if i > 0 {
+				part.CaseID = uniqueCaseID(fmt.Sprintf("%s_or_%d", conditionCase.CaseID, i+1), caseIDs)
+				ids = append(ids, part.CaseID)
+			}
+			cases = append(cases, part)
+		}
+		if l.splits[node.ID] == nil {
+			l.splits[node.ID] = make(map[string][]string)
+		}
+		l.splits[node.ID][conditionCase.CaseID] = ids
+		if !l.done[node.ID] {
+			l.warnings = append(l.warnings, fmt.Sprintf("condition node %q: case %s nests and/or groups the target cannot, split into %d branches with the same targets",
+				node.Title, conditionCase.CaseID, len(disjuncts)))
+		}
+	}
+	l.done[node.ID] = true
+
+	// Split cases sit between their neighbours, so explicit levels are numbered again
+	if split && hasCaseLevels(cases) {
+		level := 1
+		for i := range cases {
+			if cases[i].Level != defaultCaseLevel {
+				cases[i].Level = level
+				level++
+			}
+		}
+	}
+	config.Cases = cases
+	storeNodeConfig(node, config)
+	return nil
+}
+
+// conditionDisjuncts returns an expression as alternatives of conditions that all have to hold
+func conditionDisjuncts(expression models.ConditionExpression) ([][]models.Condition, error) {
+	if expression.Condition != nil {
+		return [][]models.Condition{{*expression.Condition}}, nil
+	}
+
+	if models.NormalizeLogicalOperator(expression.LogicalOperator) == models.LogicalOr {
+		var disjuncts [][]models.Condition
+		for _, operand := range expression.Operands {
+			operandDisjuncts, err := conditionDisjuncts(operand)
+			if err != nil {
+				return nil, err
+			}
+			disjuncts = append(disjuncts, operandDisjuncts...)
+			if len(disjuncts) > maxConditionDisjuncts {
+				return nil, tooManyDisjuncts()
+			}
+		}
+		return disjuncts, nil
+	}
+
+	var disjuncts [][]models.Condition
+	for _, operand := range expression.Operands {
+		operandDisjuncts, err := conditionDisjuncts(operand)
+		if err != nil {
+			return nil, err
+		}
+		if len(operandDisjuncts) == 0 {
+			continue
+		}
+		if disjuncts == nil {
+			disjuncts = operandDisjuncts
+			continue
+		}
+		if len(disjuncts)*len(operandDisjuncts) > maxConditionDisjuncts {
+			return nil, tooManyDisjuncts()
+		}
+		product := make([][]models.Condition, 0, len(disjuncts)*len(operandDisjuncts))
+		for _, left := range disjuncts {
+			for _, right := range operandDisjuncts {
+				product = append(product, append(append([]models.Condition(nil), left...), right...))
+			}
+		}
+		disjuncts = product
+	}
+	return disjuncts, nil
+}
+
+func tooManyDisjuncts() error {
+	return fmt.Errorf("the expression expands to more than %d alternatives; simplify it or split the node", maxConditionDisjuncts)
+}
+
+// flattenDisjuncts returns the conditions and operator of a single flat case equal to the
+// alternatives: one alternative is an "and" case, single conditions an "or" case
+func flattenDisjuncts(disjuncts [][]models.Condition) ([]models.Condition, string, bool) {
+	if len(disjuncts) == 1 {
+		return disjuncts[0], models.LogicalAnd, true
+	}
+	conditions := make([]models.Condition, 0, len(disjuncts))
+	for _, disjunct := range disjuncts {
+		if len(disjunct) != 1 {
+			return nil, "", false
+		}
+		conditions = append(conditions, disjunct[0])
+	}
+	return conditions, models.LogicalOr, true
+}
+
+// uniqueCaseID returns id, suffixed until no case of the node has it, and records it
+func uniqueCaseID(id string, caseIDs map[string]bool) string {
+	unique := id
+	for n := 2; caseIDs[unique]; n++ {
+		unique = fmt.Sprintf("%s_%d", id, n)
+	}
+	caseIDs[unique] = true
+	return unique
+}
+
+func hasCaseLevels(cases []models.ConditionCase) bool {
+	for _, conditionCase := range cases {
+		if conditionCase.Level != 0 && conditionCase.Level != defaultCaseLevel {
+			return true
+		}
+	}
+	return false
+}
+
+// splitEdges copies the edges of split cases to the cases they were split into
+func (l *expressionLowering) splitEdges(edges []models.Edge) []models.Edge {
+	result := make([]models.Edge, 0, len(edges))
+	for _, edge := range edges {
+		result = append(result, edge)
+		for _, caseID := range l.splits[edge.Source][edge.SourceHandle] {
+			copied := edge
+			copied.ID = edge.ID + "-" + caseID
+			copied.SourceHandle = caseID
+			result = append(result, copied)
+		}
+	}
+	return result
+}
+
+func (l *expressionLowering) splitSubWorkflowEdges(nodes []models.Node) {
+	for i := range nodes {
+		iterConfig, ok := AsIterationConfig(nodes[i].Config)
+		if !ok || iterConfig == nil {
+			continue
+		}
+		iterConfig.SubWorkflow.Edges = l.splitEdges(iterConfig.SubWorkflow.Edges)
+		l.splitSubWorkflowEdges(iterConfig.SubWorkflow.Nodes)
+		storeNodeConfig(&nodes[i], iterConfig)
+	}
+}
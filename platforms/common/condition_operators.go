@@ -0,0 +1,266 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Unified comparison operators of condition nodes. Parsers normalize platform operators to these
+// names; generators translate them with ConditionOperatorFor.
+const (
+	OperatorEquals      = "equals"
+	OperatorNotEquals   = "not_equals"
+	OperatorContains    = "contains"
+	OperatorNotContains = "not_contains"
+	OperatorStartsWith  = "starts_with"
+	OperatorEndsWith    = "ends_with"
+	OperatorIsEmpty     = "is_empty"
+	OperatorIsNotEmpty  = "is_not_empty"
+	OperatorIsNull      = "is_null"
+	OperatorIsNotNull   = "is_not_null"
+	OperatorGreater     = "gt"
+	OperatorGreaterOrEq = "gte"
+	OperatorLess        = "lt"
+	OperatorLessOrEq    = "lte"
+	OperatorIsTrue      = "is_true"
+	OperatorIsFalse     = "is_false"
+	OperatorLengthGT    = "length_gt"
+	OperatorLengthGTE   = "length_gte"
+	OperatorLengthLT    = "length_lt"
+	OperatorLengthLTE   = "length_lte"
+)
+
+// OperatorTranslation is the target representation of a unified operator.
+type OperatorTranslation struct {
+	Operator string // Target operator, empty when unsupported
+	Note     string // How an approximate translation differs, empty when exact
+}
+
+// operatorSpec lists the translations of one unified operator. Dify uses symbolic equality
+// operators for numbers; Coze identifies operators by their ConditionType number.
+type operatorSpec struct {
+	iflytek       OperatorTranslation
+	dify          OperatorTranslation
+	difyNumeric   OperatorTranslation // Overrides dify for numeric operands when set
+	cozeType      int                 // Coze ConditionType, 0 when unsupported
+	cozeNote      string
+	sourceAliases []string // Platform spellings parsed to this operator
+}
+
+// conditionOperators is the support matrix of condition operators per target platform
+var conditionOperators = map[string]operatorSpec{
+	OperatorEquals: {
+		iflytek: exact("is"), dify: exact("is"), difyNumeric: exact("="), cozeType: 1,
+		sourceAliases: []string{"is", "eq", "==", "="},
+	},
+	OperatorNotEquals: {
+		iflytek: exact("is_not"), dify: exact("is not"), difyNumeric: exact("≠"), cozeType: 2,
+		sourceAliases: []string{"is_not", "is not", "not equals", "ne", "!=", "≠"},
+	},
+	OperatorContains: {
+		iflytek: exact("contains"), dify: exact("contains"), cozeType: 7,
+	},
+	OperatorNotContains: {
+		iflytek: exact("not_contains"), dify: exact("not contains"), cozeType: 8,
+		sourceAliases: []string{"not contains"},
+	},
+	OperatorStartsWith: {
+		iflytek: exact("start_with"), dify: exact("start with"),
+		cozeType: 7, cozeNote: "Coze has no starts-with operator; the condition checks contains",
+		sourceAliases: []string{"start_with", "start with"},
+	},
+	OperatorEndsWith: {
+		iflytek: exact("end_with"), dify: exact("end with"),
+		cozeType: 7, cozeNote: "Coze has no ends-with operator; the condition checks contains",
+		sourceAliases: []string{"end_with", "end with"},
+	},
+	OperatorIsEmpty: {
+		iflytek: exact("empty"), dify: exact("empty"), cozeType: 9,
+		sourceAliases: []string{"empty"},
+	},
+	OperatorIsNotEmpty: {
+		iflytek: exact("not_empty"), dify: exact("not empty"), cozeType: 10,
+		sourceAliases: []string{"not_empty", "not empty"},
+	},
+	OperatorIsNull: {
+		iflytek: exact("null"), dify: exact("null"), cozeType: 9,
+		sourceAliases: []string{"null", "is null"},
+	},
+	OperatorIsNotNull: {
+		iflytek: exact("not_null"), dify: exact("not null"), cozeType: 10,
+		sourceAliases: []string{"not_null", "not null"},
+	},
+	OperatorGreater: {
+		iflytek: exact("gt"), dify: exact(">"), cozeType: 13,
+		sourceAliases: []string{">", "greater_than", "greater than"},
+	},
+	OperatorGreaterOrEq: {
+		iflytek: exact("ge"), dify: exact("≥"), cozeType: 14,
+		sourceAliases: []string{"ge", ">=", "≥", "greater_equal", "greater than or equal"},
+	},
+	OperatorLess: {
+		iflytek: exact("lt"), dify: exact("<"), cozeType: 15,
+		sourceAliases: []string{"<", "less_than", "less than"},
+	},
+	OperatorLessOrEq: {
+		iflytek: exact("le"), dify: exact("≤"), cozeType: 16,
+		sourceAliases: []string{"le", "<=", "≤", "less_equal", "less than or equal"},
+	},
+	OperatorIsTrue:    {cozeType: 11},
+	OperatorIsFalse:   {cozeType: 12},
+	OperatorLengthGT:  {cozeType: 3},
+	OperatorLengthGTE: {cozeType: 4},
+	OperatorLengthLT:  {cozeType: 5},
+	OperatorLengthLTE: {cozeType: 6},
+}
+
+// operatorAliases maps every known operator spelling to its unified operator
+var operatorAliases = buildOperatorAliases()
+
+// cozeConditionTypes maps Coze ConditionType numbers to unified operators
+var cozeConditionTypes = map[int]string{
+	1: OperatorEquals, 2: OperatorNotEquals,
+	3: OperatorLengthGT, 4: OperatorLengthGTE, 5: OperatorLengthLT, 6: OperatorLengthLTE,
+	7: OperatorContains, 8: OperatorNotContains, 9: OperatorIsEmpty, 10: OperatorIsNotEmpty,
+	11: OperatorIsTrue, 12: OperatorIsFalse,
+	13: OperatorGreater, 14: OperatorGreaterOrEq, 15: OperatorLess, 16: OperatorLessOrEq,
+}
+
+func exact(operator string) OperatorTranslation {
+	return OperatorTranslation{Operator: operator}
+}
+
+func buildOperatorAliases() map[string]string {
+	aliases := make(map[string]string)
+	for operator, spec := range conditionOperators {
+		aliases[operator] = operator
+		for _, alias := range spec.sourceAliases {
+			aliases[alias] = operator
+		}
+	}
+	return aliases
+}
+
+// NormalizeConditionOperator returns the unified operator of a platform operator spelling.
+// Unknown operators are returned unchanged so that generators can report them.
+func NormalizeConditionOperator(operator string) string {
+	if unified, known := operatorAliases[operator]; known {
+		return unified
+	}
+	return operator
+}
+
+// ConditionOperatorFromCoze returns the unified operator of a Coze ConditionType.
+func ConditionOperatorFromCoze(conditionType int) (string, bool) {
+	operator, known := cozeConditionTypes[conditionType]
+	return operator, known
+}
+
+// ConditionOperatorFor translates a unified operator, or any platform spelling of it, to the
+// iFlytek or Dify operator for an operand of varType. Approximate translations carry a note.
+func ConditionOperatorFor(operator string, varType models.UnifiedDataType, targetPlatform models.PlatformType) (OperatorTranslation, error) {
+	spec, err := lookupOperatorSpec(operator)
+	if err != nil {
+		return OperatorTranslation{}, err
+	}
+
+	var translation OperatorTranslation
+	switch targetPlatform {
+	case models.PlatformIFlytek:
+		translation = spec.iflytek
+	case models.PlatformDify:
+		translation = spec.dify
+		if spec.difyNumeric.Operator != "" && models.IsNumericType(varType) {
+			translation = spec.difyNumeric
+		}
+	case models.PlatformCoze:
+		if spec.cozeType == 0 {
+			break
+		}
+		translation = OperatorTranslation{Operator: fmt.Sprintf("%d", spec.cozeType), Note: spec.cozeNote}
+	}
+
+	if translation.Operator == "" {
+		return OperatorTranslation{}, fmt.Errorf("condition operator %q is not supported on %s", NormalizeConditionOperator(operator), targetPlatform)
+	}
+	return translation, nil
+}
+
+// CozeConditionType translates a unified operator to the Coze ConditionType number.
+func CozeConditionType(operator string) (int, error) {
+	spec, err := lookupOperatorSpec(operator)
+	if err != nil {
+		return 0, err
+	}
+	if spec.cozeType == 0 {
+		return 0, fmt.Errorf("condition operator %q is not supported on %s", NormalizeConditionOperator(operator), models.PlatformCoze)
+	}
+	return spec.cozeType, nil
+}
+
+func lookupOperatorSpec(operator string) (operatorSpec, error) {
+	spec, known := conditionOperators[NormalizeConditionOperator(operator)]
+	if !known {
+		return operatorSpec{}, fmt.Errorf("unknown condition operator %q", operator)
+	}
+	return spec, nil
+}
+
+// ConditionOperatorIssues checks the operators of a condition node against targetPlatform. It
+// returns the notes of approximate translations and an error for the first unsupported operator.
+func ConditionOperatorIssues(node models.Node, targetPlatform models.PlatformType) ([]string, error) {
+	config, ok := AsConditionConfig(node.Config)
+	if !ok || config == nil {
+		return nil, nil
+	}
+
+	var notes []string
+	seen := make(map[string]bool)
+	for _, caseItem := range config.Cases {
+		for _, condition := range caseItem.AllConditions() {
+			translation, err := ConditionOperatorFor(condition.ComparisonOperator, condition.VarType, targetPlatform)
+			if err != nil {
+				return notes, err
+			}
+			if translation.Note != "" && !seen[translation.Note] {
+				seen[translation.Note] = true
+				notes = append(notes, translation.Note)
+			}
+		}
+	}
+	return notes, nil
+}
+
+// CheckConditionOperators checks the condition nodes of a workflow, including iteration
+// sub-workflows, against targetPlatform. It returns a warning per approximate translation and an
+// error naming the node of the first unsupported operator.
+func CheckConditionOperators(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) ([]string, error) {
+	// The unified DSL expresses every operator
+	if unifiedDSL == nil || targetPlatform == models.PlatformUnified {
+		return nil, nil
+	}
+	return checkConditionOperators(unifiedDSL.Workflow.Nodes, targetPlatform, make(map[string]bool), nil)
+}
+
+func checkConditionOperators(nodes []models.Node, targetPlatform models.PlatformType, checked map[string]bool, warnings []string) ([]string, error) {
+	for _, node := range nodes {
+		if node.Type == models.NodeTypeCondition && !checked[node.ID] {
+			checked[node.ID] = true
+			notes, err := ConditionOperatorIssues(node, targetPlatform)
+			if err != nil {
+				return warnings, fmt.Errorf("condition node %q: %w", node.Title, err)
+			}
+			for _, note := range notes {
+				warnings = append(warnings, fmt.Sprintf("condition node %q: %s", node.Title, note))
+			}
+		}
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			var err error
+			if warnings, err = checkConditionOperators(iterConfig.SubWorkflow.Nodes, targetPlatform, checked, warnings); err != nil {
+				return warnings, err
+			}
+		}
+	}
+	return warnings, nil
+}
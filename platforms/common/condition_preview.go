@@ -0,0 +1,465 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// BranchPreview is the route a workflow takes for sample values.
+type BranchPreview struct {
+	Decisions []BranchDecision `json:"decisions"`
+	Reached   []string         `json:"reached"`           // Node IDs in visit order
+	Missing   []string         `json:"missing,omitempty"` // Values conditions read that were not given
+	Warnings  []string         `json:"warnings,omitempty"`
+}
+
+// BranchDecision is the branch a condition or classifier node takes.
+type BranchDecision struct {
+	NodeID    string          `json:"node_id"`
+	NodeTitle string          `json:"node_title"`
+	NodeType  models.NodeType `json:"node_type"`
+	Branch    string          `json:"branch,omitempty"` // Case ID or class name, empty when undecided
+	Reason    string          `json:"reason"`
+	Next      []string        `json:"next,omitempty"` // Node IDs the branch leads to
+}
+
+// defaultHandles are the handles parsers give default branches (Dify, Coze selector, Coze classifier)
+var defaultHandles = []string{"false", "__default__", "default"}
+
+// PreviewBranches walks a workflow from its start node and decides each condition and classifier
+// node it reaches with sample values, without running any other node.
+//
+// values are keyed by "nodeID.output", start variables also by their name; start variables
+// without a value take their default. Classifiers take the class named by values[nodeID], or the
+// only class whose name the query contains; otherwise the walk stops there. Error branches and
+// iteration sub-workflows are not walked.
+func PreviewBranches(unifiedDSL *models.UnifiedDSL, values map[string]interface{}) (*BranchPreview, error) {
+	if unifiedDSL == nil {
+		return nil, fmt.Errorf("workflow is required")
+	}
+	start, err := topLevelNode(unifiedDSL, models.NodeTypeStart)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &branchPreview{
+		values:   values,
+		start:    start,
+		nodes:    make(map[string]*models.Node),
+		edges:    make(map[string][]models.Edge),
+		missing:  make(map[string]bool),
+		preview:  &BranchPreview{},
+		visited:  map[string]bool{start.ID: true},
+		defaults: make(map[string]interface{}),
+	}
+	p.evaluator = &BranchEvaluator{Lookup: p.lookup, Classes: make(map[string]string)}
+	for key, value := range values {
+		p.evaluator.Classes[key] = fmt.Sprint(value)
+	}
+	for i := range unifiedDSL.Workflow.Nodes {
+		p.nodes[unifiedDSL.Workflow.Nodes[i].ID] = &unifiedDSL.Workflow.Nodes[i]
+	}
+	for _, edge := range unifiedDSL.Workflow.Edges {
+		p.edges[edge.Source] = append(p.edges[edge.Source], edge)
+	}
+	if config, ok := AsStartConfig(start.Config); ok && config != nil {
+		for _, variable := range config.Variables {
+			if variable.Default != nil {
+				p.defaults[variable.Name] = variable.Default
+			}
+		}
+	}
+
+	queue := []string{start.ID}
+	for len(queue) > 0 {
+		node := p.nodes[queue[0]]
+		queue = queue[1:]
+		p.preview.Reached = append(p.preview.Reached, node.ID)
+		for _, next := range p.step(node) {
+			if !p.visited[next] && p.nodes[next] != nil {
+				p.visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	p.preview.Missing = sortedKeys(p.missing)
+	p.preview.Warnings = p.evaluator.Warnings
+	return p.preview, nil
+}
+
+type branchPreview struct {
+	values    map[string]interface{}
+	defaults  map[string]interface{} // Start variable defaults
+	start     *models.Node
+	nodes     map[string]*models.Node
+	edges     map[string][]models.Edge // Outgoing edges by source node
+	missing   map[string]bool
+	visited   map[string]bool
+	evaluator *BranchEvaluator
+	preview   *BranchPreview
+}
+
+// step returns the nodes a node leads to, deciding branches on the way
+func (p *branchPreview) step(node *models.Node) []string {
+	choice, ok := p.evaluator.Decide(node)
+	if !ok {
+		var next []string
+		for _, edge := range p.edges[node.ID] {
+			if edge.SourceHandle != models.ErrorBranchHandle {
+				next = append(next, edge.Target)
+			}
+		}
+		return next
+	}
+
+	decision := BranchDecision{
+		NodeID:    node.ID,
+		NodeTitle: node.Title,
+		NodeType:  node.Type,
+		Branch:    choice.Branch,
+		Reason:    choice.Reason,
+		Next:      BranchTargets(choice, p.edges[node.ID]),
+	}
+	if choice.Branch != "" && len(decision.Next) == 0 {
+		p.evaluator.Warnings = append(p.evaluator.Warnings,
+			fmt.Sprintf("%s (%s): branch %s has no outgoing edge", node.Title, node.ID, choice.Branch))
+	}
+	p.preview.Decisions = append(p.preview.Decisions, decision)
+	return decision.Next
+}
+
+// lookup returns the sample value of a selector: node ID, output name, then nested keys
+func (p *branchPreview) lookup(selector []string) (interface{}, bool) {
+	if len(selector) < 2 {
+		return nil, false
+	}
+	key := selector[0] + "." + selector[1]
+	value, ok := p.values[key]
+	if !ok && selector[0] == p.start.ID {
+		if value, ok = p.values[selector[1]]; !ok {
+			value, ok = p.defaults[selector[1]]
+		}
+	}
+	if !ok {
+		p.missing[key] = true
+		return nil, false
+	}
+	return SelectField(value, selector[2:])
+}
+
+// SelectField returns the value at a path of object keys inside value.
+func SelectField(value interface{}, path []string) (interface{}, bool) {
+	for _, field := range path {
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return nil, false
+		}
+		value = object[field]
+	}
+	return value, true
+}
+
+// ValueLookup returns the value of a variable selector: node ID, output name, then nested keys.
+type ValueLookup func(selector []string) (interface{}, bool)
+
+// BranchChoice is the branch a condition or classifier node takes.
+type BranchChoice struct {
+	Branch  string   // Case ID or class name, empty when undecided
+	Handles []string // Source handles the edges of the branch may carry, in order of preference
+	Reason  string
+}
+
+// BranchTargets returns the targets of the edges of a branch among the outgoing edges of its node.
+// The first handle with edges wins, as parsers keep one spelling per branch.
+func BranchTargets(choice BranchChoice, edges []models.Edge) []string {
+	for _, handle := range choice.Handles {
+		var targets []string
+		for _, edge := range edges {
+			if edge.SourceHandle == handle && handle != "" {
+				targets = append(targets, edge.Target)
+			}
+		}
+		if len(targets) > 0 {
+			return targets
+		}
+	}
+	return nil
+}
+
+// BranchEvaluator decides condition and classifier nodes with the values of Lookup.
+type BranchEvaluator struct {
+	Lookup   ValueLookup
+	Classes  map[string]string // Class name or ID a classifier takes, by node ID
+	Warnings []string          // Operators that cannot be evaluated and unknown classes
+}
+
+// Decide returns the branch of a condition or classifier node; ok is false for other nodes.
+func (e *BranchEvaluator) Decide(node *models.Node) (BranchChoice, bool) {
+	if config, ok := AsConditionConfig(node.Config); ok && config != nil {
+		return e.decideCondition(node, config), true
+	}
+	if config, ok := AsClassifierConfig(node.Config); ok && config != nil {
+		return e.decideClassifier(node, config), true
+	}
+	return BranchChoice{}, false
+}
+
+// decideCondition takes the first case, by level, whose conditions hold, or the default case
+func (e *BranchEvaluator) decideCondition(node *models.Node, config *models.ConditionConfig) BranchChoice {
+	cases := make([]models.ConditionCase, 0, len(config.Cases))
+	defaultChoice := BranchChoice{Branch: "default", Handles: defaultHandles, Reason: "no case matched"}
+	for _, conditionCase := range config.Cases {
+		if conditionCase.CaseID == config.DefaultCase || conditionCase.Level == defaultCaseLevel || !conditionCase.HasConditions() {
+			defaultChoice.Branch = conditionCase.CaseID
+			defaultChoice.Handles = append([]string{conditionCase.CaseID}, defaultHandles...)
+			continue
+		}
+		cases = append(cases, conditionCase)
+	}
+	if config.DefaultCase != "" {
+		defaultChoice.Branch = config.DefaultCase
+		defaultChoice.Handles = append([]string{config.DefaultCase}, defaultHandles...)
+	}
+	sort.SliceStable(cases, func(i, j int) bool {
+		return cases[i].Level < cases[j].Level
+	})
+
+	for _, conditionCase := range cases {
+		if matched, reason := e.evaluateCase(node, conditionCase); matched {
+			return BranchChoice{Branch: conditionCase.CaseID, Handles: []string{conditionCase.CaseID}, Reason: reason}
+		}
+	}
+	return defaultChoice
+}
+
+// defaultCaseLevel is the level of iFlytek default branches
+const defaultCaseLevel = 999
+
+// evaluateCase reports whether the conditions of a case hold, and which ones decided it
+func (e *BranchEvaluator) evaluateCase(node *models.Node, conditionCase models.ConditionCase) (bool, string) {
+	matched, held := e.evaluateExpression(node, conditionCase.Tree())
+	if !matched {
+		return false, ""
+	}
+	return true, strings.Join(held, " and ")
+}
+
+// evaluateExpression evaluates and/or groups left to right, stopping once the result is known
+func (e *BranchEvaluator) evaluateExpression(node *models.Node, expression models.ConditionExpression) (bool, []string) {
+	if condition := expression.Condition; condition != nil {
+		ok, err := e.evaluateCondition(*condition)
+		if err != nil {
+			e.Warnings = append(e.Warnings, fmt.Sprintf("%s (%s): %v", node.Title, node.ID, err))
+		}
+		if !ok {
+			return false, nil
+		}
+		return true, []string{describeCondition(*condition)}
+	}
+
+	or := models.NormalizeLogicalOperator(expression.LogicalOperator) == models.LogicalOr
+	var held []string
+	for _, operand := range expression.Operands {
+		ok, decided := e.evaluateExpression(node, operand)
+		if ok {
+			if or {
+				return true, decided
+			}
+			held = append(held, decided...)
+		} else if !or {
+			return false, nil
+		}
+	}
+	if or || len(held) == 0 {
+		return false, nil
+	}
+	return true, held
+}
+
+func describeCondition(condition models.Condition) string {
+	name := strings.Join(condition.VariableSelector, ".")
+	operator := NormalizeConditionOperator(condition.ComparisonOperator)
+	switch operator {
+	case OperatorIsEmpty, OperatorIsNotEmpty, OperatorIsNull, OperatorIsNotNull, OperatorIsTrue, OperatorIsFalse:
+		return fmt.Sprintf("%s %s", name, operator)
+	}
+	return fmt.Sprintf("%s %s %v", name, operator, condition.Value)
+}
+
+// evaluateCondition compares the value of a condition with its operand
+func (e *BranchEvaluator) evaluateCondition(condition models.Condition) (bool, error) {
+	value, _ := e.Lookup(condition.VariableSelector)
+	operand := condition.Value
+	// Operands may reference another variable
+	if text, ok := operand.(string); ok {
+		if match := templateNodeReference.FindStringSubmatch(text); match != nil {
+			operand, _ = e.Lookup([]string{match[1], match[2]})
+		}
+	}
+
+	operator := NormalizeConditionOperator(condition.ComparisonOperator)
+	switch operator {
+	case OperatorEquals, OperatorNotEquals:
+		return sameValue(value, operand) == (operator == OperatorEquals), nil
+	case OperatorContains, OperatorNotContains:
+		return containsValue(value, operand) == (operator == OperatorContains), nil
+	case OperatorStartsWith:
+		return value != nil && strings.HasPrefix(fmt.Sprint(value), fmt.Sprint(operand)), nil
+	case OperatorEndsWith:
+		return value != nil && strings.HasSuffix(fmt.Sprint(value), fmt.Sprint(operand)), nil
+	case OperatorIsEmpty, OperatorIsNotEmpty:
+		return isEmptyValue(value) == (operator == OperatorIsEmpty), nil
+	case OperatorIsNull, OperatorIsNotNull:
+		return (value == nil) == (operator == OperatorIsNull), nil
+	case OperatorIsTrue, OperatorIsFalse:
+		return sameValue(value, operator == OperatorIsTrue), nil
+	case OperatorGreater, OperatorGreaterOrEq, OperatorLess, OperatorLessOrEq:
+		left, leftOK := toNumber(value)
+		right, rightOK := toNumber(operand)
+		return leftOK && rightOK && compareNumbers(left, right, operator), nil
+	case OperatorLengthGT, OperatorLengthGTE, OperatorLengthLT, OperatorLengthLTE:
+		length, lengthOK := valueLength(value)
+		right, rightOK := toNumber(operand)
+		return lengthOK && rightOK && compareNumbers(float64(length), right, strings.TrimPrefix(operator, "length_")), nil
+	}
+	return false, fmt.Errorf("cannot evaluate condition operator %q", condition.ComparisonOperator)
+}
+
+// decideClassifier takes the class given for the node, or the only class the query names
+func (e *BranchEvaluator) decideClassifier(node *models.Node, config *models.ClassifierConfig) BranchChoice {
+	if given, ok := e.Classes[node.ID]; ok {
+		for i, class := range config.Classes {
+			if given == class.Name || given == class.ID {
+				return classChoice(config, i, fmt.Sprintf("class %q given", given))
+			}
+		}
+		e.Warnings = append(e.Warnings, fmt.Sprintf("%s (%s): %s is not a class of the classifier", node.Title, node.ID, given))
+	}
+
+	query, found := e.classifierQuery(node, config)
+	if found {
+		text := strings.ToLower(fmt.Sprint(query))
+		matches := []int{}
+		for i, class := range config.Classes {
+			if !class.IsDefault && class.Name != "" && strings.Contains(text, strings.ToLower(class.Name)) {
+				matches = append(matches, i)
+			}
+		}
+		if len(matches) == 1 {
+			return classChoice(config, matches[0], fmt.Sprintf("the query names class %q", config.Classes[matches[0]].Name))
+		}
+	}
+	names := make([]string, 0, len(config.Classes))
+	for _, class := range config.Classes {
+		names = append(names, fmt.Sprintf("%q", class.Name))
+	}
+	return BranchChoice{Reason: fmt.Sprintf("the model picks the class; give %s one of %s", node.ID, strings.Join(names, ", "))}
+}
+
+// classifierQuery returns the value of the classified query
+func (e *BranchEvaluator) classifierQuery(node *models.Node, config *models.ClassifierConfig) (interface{}, bool) {
+	for _, input := range node.Inputs {
+		if input.Reference != nil && input.Reference.NodeID != "" {
+			return e.Lookup([]string{input.Reference.NodeID, input.Reference.OutputName})
+		}
+	}
+	if nodeID, output, ok := strings.Cut(config.QueryVariable, "."); ok {
+		return e.Lookup([]string{nodeID, output})
+	}
+	return nil, false
+}
+
+// classChoice returns the branch of a class. Parsers keep the class ID or its 1-based position as
+// the handle.
+func classChoice(config *models.ClassifierConfig, index int, reason string) BranchChoice {
+	class := config.Classes[index]
+	handles := []string{class.ID, strconv.Itoa(index + 1)}
+	if class.IsDefault {
+		handles = append(handles, defaultHandles...)
+	}
+	return BranchChoice{Branch: class.Name, Handles: handles, Reason: reason}
+}
+
+// sameValue compares numbers by value and everything else by its text
+func sameValue(value, operand interface{}) bool {
+	if value == nil || operand == nil {
+		return value == nil && operand == nil
+	}
+	left, leftOK := toNumber(value)
+	right, rightOK := toNumber(operand)
+	if leftOK && rightOK {
+		return left == right
+	}
+	return fmt.Sprint(value) == fmt.Sprint(operand)
+}
+
+// containsValue checks substrings of texts and elements of arrays
+func containsValue(value, operand interface{}) bool {
+	if value == nil {
+		return false
+	}
+	if items := reflect.ValueOf(value); items.Kind() == reflect.Slice {
+		for i := 0; i < items.Len(); i++ {
+			if sameValue(items.Index(i).Interface(), operand) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(fmt.Sprint(value), fmt.Sprint(operand))
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	length, ok := valueLength(value)
+	return ok && length == 0
+}
+
+// valueLength is the length of texts, arrays and objects in characters or elements
+func valueLength(value interface{}) (int, bool) {
+	if text, ok := value.(string); ok {
+		return utf8.RuneCountInString(text), true
+	}
+	switch items := reflect.ValueOf(value); items.Kind() {
+	case reflect.Slice, reflect.Map:
+		return items.Len(), true
+	}
+	return 0, false
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case int:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+func compareNumbers(left, right float64, operator string) bool {
+	switch operator {
+	case OperatorGreater:
+		return left > right
+	case OperatorGreaterOrEq:
+		return left >= right
+	case OperatorLess:
+		return left < right
+	case OperatorLessOrEq:
+		return left <= right
+	}
+	return false
+}
@@ -92,3 +92,107 @@ func AsIterationConfig(cfg interface{}) (*models.IterationConfig, bool) {
 		return nil, false
 	}
 }
+
+// AsDataStoreConfig returns a pointer to DataStoreConfig regardless of value or pointer storage.
+func AsDataStoreConfig(cfg interface{}) (*models.DataStoreConfig, bool) {
+	switch c := cfg.(type) {
+	case *models.DataStoreConfig:
+		return c, true
+	case models.DataStoreConfig:
+		cc := c
+		return &cc, true
+	default:
+		return nil, false
+	}
+}
+
+// AsAgentConfig returns a pointer to AgentConfig regardless of value or pointer storage.
+func AsAgentConfig(cfg interface{}) (*models.AgentConfig, bool) {
+	switch c := cfg.(type) {
+	case *models.AgentConfig:
+		return c, true
+	case models.AgentConfig:
+		cc := c
+		return &cc, true
+	default:
+		return nil, false
+	}
+}
+
+// AsHumanInputConfig returns a pointer to HumanInputConfig regardless of value or pointer storage.
+func AsHumanInputConfig(cfg interface{}) (*models.HumanInputConfig, bool) {
+	switch c := cfg.(type) {
+	case *models.HumanInputConfig:
+		return c, true
+	case models.HumanInputConfig:
+		cc := c
+		return &cc, true
+	default:
+		return nil, false
+	}
+}
+
+// AsTextToSpeechConfig returns a pointer to TextToSpeechConfig regardless of value or pointer storage.
+func AsTextToSpeechConfig(cfg interface{}) (*models.TextToSpeechConfig, bool) {
+	switch c := cfg.(type) {
+	case *models.TextToSpeechConfig:
+		return c, true
+	case models.TextToSpeechConfig:
+		cc := c
+		return &cc, true
+	default:
+		return nil, false
+	}
+}
+
+// AsSpeechToTextConfig returns a pointer to SpeechToTextConfig regardless of value or pointer storage.
+func AsSpeechToTextConfig(cfg interface{}) (*models.SpeechToTextConfig, bool) {
+	switch c := cfg.(type) {
+	case *models.SpeechToTextConfig:
+		return c, true
+	case models.SpeechToTextConfig:
+		cc := c
+		return &cc, true
+	default:
+		return nil, false
+	}
+}
+
+// AsDocumentExtractorConfig returns a pointer to DocumentExtractorConfig regardless of value or pointer storage.
+func AsDocumentExtractorConfig(cfg interface{}) (*models.DocumentExtractorConfig, bool) {
+	switch c := cfg.(type) {
+	case *models.DocumentExtractorConfig:
+		return c, true
+	case models.DocumentExtractorConfig:
+		cc := c
+		return &cc, true
+	default:
+		return nil, false
+	}
+}
+
+// AsListOperationConfig returns a pointer to ListOperationConfig regardless of value or pointer storage.
+func AsListOperationConfig(cfg interface{}) (*models.ListOperationConfig, bool) {
+	switch c := cfg.(type) {
+	case *models.ListOperationConfig:
+		return c, true
+	case models.ListOperationConfig:
+		cc := c
+		return &cc, true
+	default:
+		return nil, false
+	}
+}
+
+// AsJSONProcessConfig returns a pointer to JSONProcessConfig regardless of value or pointer storage.
+func AsJSONProcessConfig(cfg interface{}) (*models.JSONProcessConfig, bool) {
+	switch c := cfg.(type) {
+	case *models.JSONProcessConfig:
+		return c, true
+	case models.JSONProcessConfig:
+		cc := c
+		return &cc, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,167 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// ErrCrossIterationReference is returned for references into an iteration body that no iteration
+// output carries out of it.
+var ErrCrossIterationReference = errors.New("references into iteration bodies cannot be rewritten")
+
+// defaultIterationOutput names the output of iterations that declare none, as Dify does
+const defaultIterationOutput = "output"
+
+// iterationScopes knows which iteration body every node of a workflow runs in
+type iterationScopes struct {
+	scope      map[string]string // Node ID -> ID of the iteration whose body holds it, "" at the top level
+	iterations map[string]*models.Node
+	readers    []*models.Node
+}
+
+// RewriteCrossIterationReferences checks that every node reads only nodes of its own iteration
+// body, of the bodies around it, or outside all iterations. Nodes of a body only exist while the
+// iteration runs; a reference to one from outside its body is rewritten to read the output of the
+// iteration when that output is the value referenced, now collected for every item, and reported.
+// References no iteration output carries fail the conversion with one line each. Unified targets
+// keep the workflow as parsed.
+func RewriteCrossIterationReferences(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) ([]string, error) {
+	if unifiedDSL == nil || targetPlatform == models.PlatformUnified {
+		return nil, nil
+	}
+	scopes := newIterationScopes(unifiedDSL.Workflow.Nodes)
+	if len(scopes.iterations) == 0 {
+		return nil, nil
+	}
+
+	// Flat bodies may also be kept in the sub-workflow; both copies are rewritten, and reported once
+	var warnings, failures []string
+	reported := make(map[string]bool)
+	report := func(list *[]string, message string) {
+		if !reported[message] {
+			reported[message] = true
+			*list = append(*list, message)
+		}
+	}
+	for _, reader := range scopes.readers {
+		readerScope := scopes.scope[reader.ID]
+		if reader.Type == models.NodeTypeIteration {
+			// An iteration reads its body for its output
+			readerScope = reader.ID
+		}
+		scopes.rewriteOwnReferences(reader, func(nodeID, output string) (string, string, bool) {
+			if output == "" || scopes.visible(nodeID, readerScope) {
+				return "", "", false
+			}
+			newID, newOutput, err := scopes.exit(nodeID, output, readerScope)
+			if err != nil {
+				report(&failures, fmt.Sprintf("node %q (%s) reads %s.%s: %v", reader.Title, reader.ID, nodeID, output, err))
+				return "", "", false
+			}
+			report(&warnings, fmt.Sprintf("node %q (%s) read %s.%s inside iteration %q; it now reads the iteration output %s.%s, the value for every item",
+				reader.Title, reader.ID, nodeID, output, scopes.title(scopes.scope[nodeID]), newID, newOutput))
+			return newID, newOutput, true
+		})
+	}
+	if len(failures) > 0 {
+		return warnings, fmt.Errorf("%w: %s", ErrCrossIterationReference, strings.Join(failures, "; "))
+	}
+	return warnings, nil
+}
+
+// newIterationScopes indexes the nodes of a workflow by the iteration body they run in. Bodies
+// may be kept in the sub-workflow of their iteration, at the top level with the iteration ID in
+// their config, or both.
+func newIterationScopes(nodes []models.Node) *iterationScopes {
+	scopes := &iterationScopes{scope: make(map[string]string), iterations: make(map[string]*models.Node)}
+	var collect func(nodes []models.Node, parent string)
+	collect = func(nodes []models.Node, parent string) {
+		for i := range nodes {
+			node := &nodes[i]
+			scope := IterationParent(node)
+			if scope == "" {
+				scope = parent
+			}
+			scopes.scope[node.ID] = scope
+			scopes.readers = append(scopes.readers, node)
+			if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+				// Keep the config addressable, so that rewrites of the body reach the workflow
+				node.Config = iterConfig
+				scopes.iterations[node.ID] = node
+				collect(iterConfig.SubWorkflow.Nodes, node.ID)
+			}
+		}
+	}
+	collect(nodes, "")
+	return scopes
+}
+
+// visible reports whether a node in readerScope may read nodeID: nodes of unknown IDs, such as
+// system variables, and nodes of the same or an enclosing body are
+func (s *iterationScopes) visible(nodeID, readerScope string) bool {
+	target, known := s.scope[nodeID]
+	if !known {
+		return true
+	}
+	for scope, depth := readerScope, 0; depth <= len(s.iterations); depth++ {
+		if scope == target {
+			return true
+		}
+		if scope == "" {
+			return false
+		}
+		scope = s.scope[scope]
+	}
+	return false
+}
+
+// exit returns the iteration output that carries the output of nodeID out of the bodies
+// readerScope is not in, or why there is none
+func (s *iterationScopes) exit(nodeID, output, readerScope string) (string, string, error) {
+	for !s.visible(nodeID, readerScope) {
+		iteration := s.iterations[s.scope[nodeID]]
+		if iteration == nil {
+			return "", "", fmt.Errorf("its iteration %s is missing", s.scope[nodeID])
+		}
+		iterConfig, _ := AsIterationConfig(iteration.Config)
+		selector := iterConfig.OutputSelector
+		if selector.NodeID != nodeID || selector.OutputName != output {
+			carried := "nothing"
+			if selector.NodeID != "" {
+				carried = selector.NodeID + "." + selector.OutputName
+			}
+			return "", "", fmt.Errorf("%s.%s only exists inside iteration %q (%s), whose output carries %s; read it inside the iteration or make it the iteration output",
+				nodeID, output, iteration.Title, iteration.ID, carried)
+		}
+		nodeID, output = iteration.ID, defaultIterationOutput
+		if len(iteration.Outputs) > 0 && iteration.Outputs[0].Name != "" {
+			output = iteration.Outputs[0].Name
+		}
+	}
+	return nodeID, output, nil
+}
+
+// rewriteOwnReferences rewrites the references of a node; the body of an iteration is left to
+// its own nodes, which read from another scope
+func (s *iterationScopes) rewriteOwnReferences(node *models.Node, rewrite func(nodeID, output string) (string, string, bool)) {
+	iterConfig, ok := node.Config.(*models.IterationConfig)
+	if !ok || iterConfig == nil {
+		rewriteNodeReferences(node, rewrite)
+		return
+	}
+	body := iterConfig.SubWorkflow.Nodes
+	iterConfig.SubWorkflow.Nodes = nil
+	rewriteNodeReferences(node, rewrite)
+	iterConfig.SubWorkflow.Nodes = body
+}
+
+// title returns the title of an iteration, or its ID
+func (s *iterationScopes) title(iterationID string) string {
+	if iteration := s.iterations[iterationID]; iteration != nil && iteration.Title != "" {
+		return iteration.Title
+	}
+	return iterationID
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// CheckDataTypes returns a warning per node output, including iteration sub-workflow nodes, whose
+// type targetPlatform has no match for, so that the output changes its values on conversion.
+func CheckDataTypes(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) []string {
+	if unifiedDSL == nil {
+		return nil
+	}
+	return checkDataTypes(unifiedDSL.Workflow.Nodes, models.GetDefaultDataTypeMapping(), targetPlatform, nil)
+}
+
+func checkDataTypes(nodes []models.Node, mapping *models.DataTypeMapping, targetPlatform models.PlatformType, warnings []string) []string {
+	for _, node := range nodes {
+		for _, output := range node.Outputs {
+			loss, lossy := mapping.LossyConversion(targetPlatform, output.Type)
+			if !lossy {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("node %q output %q has type %s, which %s has no type for; %s (%s)",
+				node.Title, output.Name, output.Type, targetPlatform, loss, mapping.ToPlatformType(targetPlatform, output.Type)))
+		}
+
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			warnings = checkDataTypes(iterConfig.SubWorkflow.Nodes, mapping, targetPlatform, warnings)
+		}
+	}
+	return warnings
+}
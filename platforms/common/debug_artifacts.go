@@ -0,0 +1,74 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Debug artifact file names
+const (
+	ArtifactWorkflowJSON  = "workflow.json"      // Workflow JSON extracted from a Coze ZIP export
+	ArtifactManifest      = "manifest.json"      // MANIFEST.yml of a Coze ZIP export
+	ArtifactCozeDSL       = "coze_dsl.yml"       // Coze YAML DSL built from a ZIP export or raw workflow JSON
+	ArtifactUnifiedParsed = "unified_parsed.yml" // Unified DSL as parsed
+	ArtifactUnifiedFinal  = "unified_final.yml"  // Unified DSL as handed to the generator
+)
+
+// DebugArtifacts saves intermediate results of one conversion into Dir, to see which step of a
+// conversion goes wrong. With an empty Dir nothing is written.
+type DebugArtifacts struct {
+	Dir string
+}
+
+// Enabled reports whether artifacts are saved.
+func (d DebugArtifacts) Enabled() bool {
+	return d.Dir != ""
+}
+
+// Save writes data to the named artifact.
+func (d DebugArtifacts) Save(name string, data []byte) error {
+	if !d.Enabled() {
+		return nil
+	}
+	if err := os.MkdirAll(d.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create debug directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(d.Dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("failed to save debug artifact %s: %w", name, err)
+	}
+	return nil
+}
+
+// SaveJSON writes value as indented JSON to the named artifact.
+func (d DebugArtifacts) SaveJSON(name string, value interface{}) error {
+	if !d.Enabled() {
+		return nil
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode debug artifact %s: %w", name, err)
+	}
+	return d.Save(name, data)
+}
+
+// SaveYAML writes value as YAML to the named artifact.
+func (d DebugArtifacts) SaveYAML(name string, value interface{}) error {
+	if !d.Enabled() {
+		return nil
+	}
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode debug artifact %s: %w", name, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode debug artifact %s: %w", name, err)
+	}
+	return d.Save(name, buffer.Bytes())
+}
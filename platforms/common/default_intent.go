@@ -0,0 +1,86 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// SynthesizesDefaultIntents reports whether converting the workflow to targetPlatform adds a
+// default intent to its classifiers: iFlytek Spark classifiers need one, Dify classifiers have none.
+func SynthesizesDefaultIntents(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) bool {
+	if unifiedDSL == nil || targetPlatform != models.PlatformIFlytek {
+		return false
+	}
+	metadata := unifiedDSL.PlatformMetadata
+	return metadata.Coze == nil && metadata.Dify != nil
+}
+
+// DefaultIntentTarget returns the node a synthesized default intent of the classifier connects to
+// under strategy: the target of the last edge leaving the classifier, or the end node. Classifiers
+// in an iteration body get the iteration node for the end, since the end of the body is only
+// generated on the target. Empty leaves the intent unconnected.
+func DefaultIntentTarget(workflow *models.Workflow, classifier *models.Node, strategy string) string {
+	switch strategy {
+	case models.DefaultIntentNone:
+		return ""
+	case models.DefaultIntentEnd:
+		if config, ok := AsClassifierConfig(classifier.Config); ok && config != nil && config.IsInIteration {
+			return config.IterationID
+		}
+		for _, node := range workflow.Nodes {
+			if node.Type == models.NodeTypeEnd {
+				return node.ID
+			}
+		}
+		return ""
+	}
+
+	target := ""
+	for _, edge := range workflow.Edges {
+		if edge.Source == classifier.ID {
+			target = edge.Target
+		}
+	}
+	return target
+}
+
+// CheckDefaultIntents returns, for each classifier that gets a synthesized default intent on
+// targetPlatform, where the intent was connected, so the choice shows in the conversion report.
+func CheckDefaultIntents(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType, strategy string) []string {
+	if !SynthesizesDefaultIntents(unifiedDSL, targetPlatform) {
+		return nil
+	}
+
+	titles := make(map[string]string, len(unifiedDSL.Workflow.Nodes))
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		titles[node.ID] = node.Title
+	}
+
+	var warnings []string
+	for i := range unifiedDSL.Workflow.Nodes {
+		classifier := &unifiedDSL.Workflow.Nodes[i]
+		if classifier.Type != models.NodeTypeClassifier {
+			continue
+		}
+		target := DefaultIntentTarget(&unifiedDSL.Workflow, classifier, strategy)
+		switch {
+		case target == "":
+			warnings = append(warnings, fmt.Sprintf("classifier %q: the default intent %s requires is left unconnected; queries matching no class end the workflow there",
+				classifier.Title, targetPlatform))
+		case strategy == models.DefaultIntentEnd && isIterationOf(classifier, target):
+			warnings = append(warnings, fmt.Sprintf("classifier %q: the default intent %s requires connects to the end of iteration %q",
+				classifier.Title, targetPlatform, titles[target]))
+		default:
+			warnings = append(warnings, fmt.Sprintf("classifier %q: the default intent %s requires connects to %q",
+				classifier.Title, targetPlatform, titles[target]))
+		}
+	}
+	return warnings
+}
+
+// isIterationOf reports whether iterationID is the iteration whose body holds the classifier
+func isIterationOf(classifier *models.Node, iterationID string) bool {
+	config, ok := AsClassifierConfig(classifier.Config)
+	return ok && config != nil && config.IsInIteration && config.IterationID == iterationID
+}
@@ -0,0 +1,262 @@
+package common
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Diagnosis is the outcome of one check of DiagnoseInput. Info diagnoses without a fix are
+// passed checks.
+type Diagnosis struct {
+	Check    string
+	Severity models.ErrorSeverity
+	Message  string
+	Fix      string
+}
+
+// Passed reports whether the check found nothing to act on.
+func (d Diagnosis) Passed() bool {
+	return d.Severity == models.SeverityInfo && d.Fix == ""
+}
+
+// maxDiagnosedLines bounds the line numbers listed in one diagnosis
+const maxDiagnosedLines = 5
+
+var base64Text = regexp.MustCompile(`^[A-Za-z0-9+/\r\n]+={0,2}\s*$`)
+
+// DiagnoseInput checks an exported DSL file for the problems behind most failed conversions:
+// encoding, line endings, tab indentation, YAML syntax, damaged or base64 encoded Coze ZIP
+// exports and platform detection. Each diagnosis with a problem carries a fix.
+func DiagnoseInput(data []byte) []Diagnosis {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return []Diagnosis{{Check: "content", Severity: models.SeverityError, Message: "the file is empty",
+			Fix: "export the workflow again; an empty file usually comes from an interrupted download"}}
+	}
+
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		diagnoses := diagnoseArchive(data)
+		if diagnoses[0].Severity == models.SeverityError {
+			return diagnoses
+		}
+		return append(diagnoses, diagnoseDetection(data))
+	}
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("UEs")) && base64Text.Match(trimmed) {
+		decoded, err := base64.StdEncoding.DecodeString(strings.NewReplacer("\r", "", "\n", "").Replace(string(trimmed)))
+		if err != nil {
+			return []Diagnosis{{Check: "base64", Severity: models.SeverityError,
+				Message: fmt.Sprintf("the file looks like a base64 encoded ZIP export but does not decode: %v", err),
+				Fix:     "copy the export again in full, or download it as a .zip file instead"}}
+		}
+		diagnoses := []Diagnosis{{Check: "base64", Severity: models.SeverityInfo,
+			Message: fmt.Sprintf("base64 encoded ZIP export, %d bytes decoded", len(decoded))}}
+		return append(append(diagnoses, diagnoseArchive(decoded)...), Diagnosis{Check: "platform", Severity: models.SeverityWarning,
+			Message: "coze, but platform detection only recognizes ZIP archives, not their base64 text",
+			Fix:     "pass --from coze, or decode the file first: base64 -d export.txt > export.zip"})
+	}
+
+	text, diagnoses := diagnoseEncoding(data)
+	if text == nil {
+		return diagnoses
+	}
+	diagnoses = append(diagnoses, diagnoseLineEndings(text)...)
+
+	documents, err := decodeYAMLDocuments(text)
+	if err != nil {
+		// Tabs are fine inside quoted strings, so they are only blamed for broken files
+		diagnoses = append(diagnoses, diagnoseIndentation(text)...)
+		return append(diagnoses, Diagnosis{Check: "yaml", Severity: models.SeverityError, Message: err.Error(),
+			Fix: "fix the syntax at the reported line; files edited by hand often miss a quote or mix indentation"})
+	}
+	message := "valid YAML"
+	if len(documents) > 1 {
+		message = fmt.Sprintf("valid YAML, %d documents", len(documents))
+	}
+	diagnoses = append(diagnoses, Diagnosis{Check: "yaml", Severity: models.SeverityInfo, Message: message})
+	return append(diagnoses, diagnoseDetection(data))
+}
+
+// diagnoseEncoding returns the input as UTF-8 text, or nil when it cannot be read as text
+func diagnoseEncoding(data []byte) ([]byte, []Diagnosis) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xFF\xFE")), bytes.HasPrefix(data, []byte("\xFE\xFF")):
+		text, ok := decodeUTF16(data)
+		if !ok {
+			return nil, []Diagnosis{{Check: "encoding", Severity: models.SeverityError, Message: "UTF-16 text with an odd number of bytes",
+				Fix: "export the workflow again; the file was cut off"}}
+		}
+		return text, []Diagnosis{{Check: "encoding", Severity: models.SeverityInfo, Message: "UTF-16 with a byte order mark",
+			Fix: "AgentBridge reads it, but platform importers expect UTF-8: iconv -f UTF-16 -t UTF-8"}}
+	case bytes.IndexByte(data, 0) >= 0:
+		return nil, []Diagnosis{{Check: "encoding", Severity: models.SeverityError,
+			Message: fmt.Sprintf("NUL byte at line %d: this is not a text export", lineOf(data, bytes.IndexByte(data, 0))),
+			Fix:     "pass the exported .yml, .json or .zip file itself, not a document or archive holding it"}}
+	}
+
+	text := bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	if !utf8.Valid(text) {
+		offset := 0
+		for offset < len(text) {
+			r, size := utf8.DecodeRune(text[offset:])
+			if r == utf8.RuneError && size == 1 {
+				break
+			}
+			offset += size
+		}
+		return nil, []Diagnosis{{Check: "encoding", Severity: models.SeverityError,
+			Message: fmt.Sprintf("invalid UTF-8 at line %d", lineOf(text, offset)),
+			Fix:     "re-save the file as UTF-8, e.g. iconv -f GBK -t UTF-8 when an editor saved it as GBK"}}
+	}
+	if len(text) < len(data) {
+		return text, []Diagnosis{{Check: "encoding", Severity: models.SeverityInfo, Message: "UTF-8 with a byte order mark",
+			Fix: "AgentBridge ignores it; remove it if a platform importer rejects the file: sed -i '1s/^\\xEF\\xBB\\xBF//'"}}
+	}
+	return text, []Diagnosis{{Check: "encoding", Severity: models.SeverityInfo, Message: "UTF-8"}}
+}
+
+func decodeUTF16(data []byte) ([]byte, bool) {
+	if len(data)%2 != 0 {
+		return nil, false
+	}
+	bigEndian := data[0] == 0xFE
+	units := make([]uint16, 0, len(data)/2-1)
+	for i := 2; i < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+		}
+	}
+	return []byte(string(utf16.Decode(units))), true
+}
+
+func diagnoseLineEndings(text []byte) []Diagnosis {
+	crlf := bytes.Count(text, []byte("\r\n"))
+	lf := bytes.Count(text, []byte("\n")) - crlf
+	switch {
+	case crlf > 0 && lf > 0:
+		return []Diagnosis{{Check: "line endings", Severity: models.SeverityWarning,
+			Message: fmt.Sprintf("mixed line endings: %d CRLF, %d LF", crlf, lf),
+			Fix:     "normalize them, e.g. dos2unix; the file was probably edited on Windows and Unix"}}
+	case crlf > 0:
+		return []Diagnosis{{Check: "line endings", Severity: models.SeverityInfo, Message: "CRLF (Windows)",
+			Fix: "AgentBridge reads them; convert with dos2unix if a prompt or code block ends up with stray \\r"}}
+	}
+	return []Diagnosis{{Check: "line endings", Severity: models.SeverityInfo, Message: "LF"}}
+}
+
+// diagnoseIndentation finds lines indented with tabs, which YAML does not allow
+func diagnoseIndentation(text []byte) []Diagnosis {
+	var lines []string
+	count := 0
+	for i, line := range strings.Split(string(text), "\n") {
+		if !strings.HasPrefix(line, "\t") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		count++
+		if len(lines) < maxDiagnosedLines {
+			lines = append(lines, fmt.Sprint(i+1))
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("line %s is indented with a tab", lines[0])
+	if count > 1 {
+		if count > len(lines) {
+			lines = append(lines, "...")
+		}
+		message = fmt.Sprintf("%d lines are indented with tabs (lines %s)", count, strings.Join(lines, ", "))
+	}
+	return []Diagnosis{{Check: "indentation", Severity: models.SeverityError,
+		Message: message,
+		Fix:     "indent with spaces only; YAML does not allow tabs, e.g. expand -t 2"}}
+}
+
+// diagnoseArchive checks that a Coze ZIP export opens, that its entries decompress with valid
+// checksums and that it holds a workflow
+func diagnoseArchive(data []byte) []Diagnosis {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return []Diagnosis{{Check: "zip", Severity: models.SeverityError, Message: fmt.Sprintf("the ZIP archive cannot be opened: %v", err),
+			Fix: "download the export again; truncated downloads and mail gateways rewriting attachments damage archives. --parse-mode lenient salvages what it still holds"}}
+	}
+
+	budget := models.DefaultArchiveLimits().MaxDecompressedBytes
+	var workflow []byte
+	for _, file := range reader.File {
+		content, err := readArchiveEntry(file, budget)
+		if err != nil {
+			return []Diagnosis{{Check: "zip", Severity: models.SeverityError, Message: fmt.Sprintf("entry %s is damaged: %v", file.Name, err),
+				Fix: "download the export again; the archive was modified or cut off. --parse-mode lenient salvages what it still holds"}}
+		}
+		budget -= int64(len(content))
+		if workflow == nil && strings.Contains(file.Name, "Workflow-") && strings.HasSuffix(file.Name, ".zip") {
+			workflow = content
+		}
+	}
+	if workflow == nil {
+		return []Diagnosis{{Check: "zip", Severity: models.SeverityError,
+			Message: fmt.Sprintf("no Workflow-*.zip entry among %d entries: not a Coze workflow export", len(reader.File)),
+			Fix:     "export the workflow from the Coze editor (not a plugin, bot or knowledge base package)"}}
+	}
+	if bytes.HasPrefix(workflow, []byte("PK\x03\x04")) {
+		return diagnoseArchive(workflow)
+	}
+
+	diagnoses := []Diagnosis{{Check: "zip", Severity: models.SeverityInfo, Message: fmt.Sprintf("%d entries, checksums valid", len(reader.File))}}
+	if !bytes.Contains(workflow, []byte(`{"nodes"`)) && !bytes.Contains(workflow, []byte(`{"edges"`)) {
+		return append(diagnoses, Diagnosis{Check: "workflow", Severity: models.SeverityError, Message: "the workflow entry holds no workflow JSON",
+			Fix: "export the workflow again from the Coze editor"})
+	}
+	if !bytes.Contains(workflow, []byte("MANIFEST.yml")) {
+		diagnoses = append(diagnoses, Diagnosis{Check: "manifest", Severity: models.SeverityWarning, Message: "no MANIFEST.yml: name, description and version fall back to defaults",
+			Fix: "export the workflow again, or set the metadata after conversion"})
+	}
+	return diagnoses
+}
+
+func readArchiveEntry(file *zip.File, budget int64) ([]byte, error) {
+	if budget <= 0 {
+		return nil, errArchiveBudget
+	}
+	entry, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+	content, err := io.ReadAll(io.LimitReader(entry, budget+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > budget {
+		return nil, errArchiveBudget
+	}
+	return content, nil
+}
+
+// errArchiveBudget stops archives decompressing to more than the default archive limits
+var errArchiveBudget = fmt.Errorf("archive exceeds %d decompressed bytes", models.DefaultArchiveLimits().MaxDecompressedBytes)
+
+func diagnoseDetection(data []byte) Diagnosis {
+	detection, err := DetectPlatform(data)
+	if err != nil {
+		return Diagnosis{Check: "platform", Severity: models.SeverityError, Message: err.Error(),
+			Fix: "pass --from iflytek|dify|coze|unified if the file is an export with a modified structure"}
+	}
+	return Diagnosis{Check: "platform", Severity: models.SeverityInfo, Message: describeDetection(*detection)}
+}
+
+// lineOf returns the 1-based line of a byte offset
+func lineOf(data []byte, offset int) int {
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
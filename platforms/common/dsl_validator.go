@@ -173,6 +173,32 @@ func (v *UnifiedDSLValidator) validateNodeConfig(node *models.Node) error {
 		return v.validateClassifierConfig(node.Config)
 	case models.NodeTypeIteration:
 		return v.validateIterationConfig(node.Config)
+	case models.NodeTypeDataStore:
+		return v.validateDataStoreConfig(node.Config)
+	case models.NodeTypeAgent:
+		return v.validateAgentConfig(node.Config)
+	case models.NodeTypeHumanInput:
+		return v.validateHumanInputConfig(node.Config)
+	case models.NodeTypeTextToSpeech:
+		if cfg, ok := AsTextToSpeechConfig(node.Config); !ok || cfg == nil {
+			return fmt.Errorf("invalid text-to-speech config type")
+		}
+	case models.NodeTypeSpeechToText:
+		if cfg, ok := AsSpeechToTextConfig(node.Config); !ok || cfg == nil {
+			return fmt.Errorf("invalid speech-to-text config type")
+		}
+	case models.NodeTypeDocumentExtractor:
+		if cfg, ok := AsDocumentExtractorConfig(node.Config); !ok || cfg == nil {
+			return fmt.Errorf("invalid document extractor config type")
+		}
+	case models.NodeTypeListOperation:
+		if cfg, ok := AsListOperationConfig(node.Config); !ok || cfg == nil {
+			return fmt.Errorf("invalid list operation config type")
+		}
+	case models.NodeTypeJSONProcess:
+		if cfg, ok := AsJSONProcessConfig(node.Config); !ok || cfg == nil {
+			return fmt.Errorf("invalid JSON process config type")
+		}
 	}
 
 	return nil
@@ -255,6 +281,54 @@ func (v *UnifiedDSLValidator) validateIterationConfig(config interface{}) error
 	return v.ValidateWorkflow(subWorkflow)
 }
 
+// validateDataStoreConfig validates database / memory node configuration
+func (v *UnifiedDSLValidator) validateDataStoreConfig(config interface{}) error {
+	dataStoreConfig, ok := AsDataStoreConfig(config)
+	if !ok || dataStoreConfig == nil {
+		return fmt.Errorf("invalid data store config type")
+	}
+
+	if dataStoreConfig.Store == "" || dataStoreConfig.Operation == "" {
+		return fmt.Errorf("data store kind and operation are required")
+	}
+
+	return nil
+}
+
+// validateAgentConfig validates agent node configuration
+func (v *UnifiedDSLValidator) validateAgentConfig(config interface{}) error {
+	agentConfig, ok := AsAgentConfig(config)
+	if !ok || agentConfig == nil {
+		return fmt.Errorf("invalid agent config type")
+	}
+
+	if agentConfig.Model.Name == "" {
+		return fmt.Errorf("agent model name is required")
+	}
+
+	return nil
+}
+
+// validateHumanInputConfig validates human input node configuration
+func (v *UnifiedDSLValidator) validateHumanInputConfig(config interface{}) error {
+	humanInputConfig, ok := AsHumanInputConfig(config)
+	if !ok || humanInputConfig == nil {
+		return fmt.Errorf("invalid human input config type")
+	}
+
+	switch humanInputConfig.AnswerType {
+	case models.HumanInputAnswerText:
+	case models.HumanInputAnswerOption:
+		if len(humanInputConfig.Options) == 0 {
+			return fmt.Errorf("human input node with option answers must have at least one option")
+		}
+	default:
+		return fmt.Errorf("unsupported human input answer type: %s", humanInputConfig.AnswerType)
+	}
+
+	return nil
+}
+
 // isSupportedNodeType checks if node type is supported
 func (v *UnifiedDSLValidator) isSupportedNodeType(nodeType models.NodeType) bool {
 	supportedTypes := []models.NodeType{
@@ -265,6 +339,14 @@ func (v *UnifiedDSLValidator) isSupportedNodeType(nodeType models.NodeType) bool
 		models.NodeTypeCondition,
 		models.NodeTypeClassifier,
 		models.NodeTypeIteration,
+		models.NodeTypeDataStore,
+		models.NodeTypeAgent,
+		models.NodeTypeHumanInput,
+		models.NodeTypeTextToSpeech,
+		models.NodeTypeSpeechToText,
+		models.NodeTypeDocumentExtractor,
+		models.NodeTypeListOperation,
+		models.NodeTypeJSONProcess,
 	}
 
 	for _, supportedType := range supportedTypes {
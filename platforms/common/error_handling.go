@@ -0,0 +1,47 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// errorHandlingNodeTypes lists the node types whose retries and error strategy each target
+// keeps. iFlytek nodes always fail the workflow.
+var errorHandlingNodeTypes = map[models.PlatformType]map[models.NodeType]bool{
+	models.PlatformDify: {models.NodeTypeLLM: true, models.NodeTypeCode: true},
+	models.PlatformCoze: {models.NodeTypeLLM: true, models.NodeTypeCode: true},
+}
+
+// SupportsErrorHandling reports whether targetPlatform keeps the error handling of nodeType nodes.
+func SupportsErrorHandling(targetPlatform models.PlatformType, nodeType models.NodeType) bool {
+	return errorHandlingNodeTypes[targetPlatform][nodeType]
+}
+
+// CheckErrorHandling returns a warning per node, including iteration sub-workflow nodes, whose
+// error handling targetPlatform drops.
+func CheckErrorHandling(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) []string {
+	if unifiedDSL == nil || targetPlatform == models.PlatformUnified {
+		return nil
+	}
+	return checkErrorHandling(unifiedDSL.Workflow.Nodes, targetPlatform, make(map[string]bool), nil)
+}
+
+func checkErrorHandling(nodes []models.Node, targetPlatform models.PlatformType, checked map[string]bool, warnings []string) []string {
+	for _, node := range nodes {
+		handling := node.ErrorHandling
+		if handling != nil && !checked[node.ID] && !SupportsErrorHandling(targetPlatform, node.Type) {
+			checked[node.ID] = true
+			warning := fmt.Sprintf("node %q: %s %s nodes have no retries or error strategy and fail the workflow on errors",
+				node.Title, targetPlatform, node.Type)
+			if handling.Strategy == models.ErrorStrategyFailBranch {
+				warning += "; the nodes of its fail branch are connected as normal successors"
+			}
+			warnings = append(warnings, warning)
+		}
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			warnings = checkErrorHandling(iterConfig.SubWorkflow.Nodes, targetPlatform, checked, warnings)
+		}
+	}
+	return warnings
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
 	"math/rand"
+	"sort"
 	"strings"
 	"time"
 )
@@ -151,12 +152,63 @@ func UpdateVariableSelector(selector []string, nodeIDMapping map[string]string)
 	return selector
 }
 
-// ReplaceTemplateNodeReferences replaces node ID references in template strings
+// ReplaceTemplateNodeReferences replaces node ID references in template strings. The ID of each
+// {{#id.field#}} reference is looked up in the mapping, longest candidate first, so the cost does
+// not grow with the size of the mapping.
 func ReplaceTemplateNodeReferences(text string, nodeIDMapping map[string]string) string {
-	for oldID, newID := range nodeIDMapping {
-		oldPattern := fmt.Sprintf("{{#%s.", oldID)
-		newPattern := fmt.Sprintf("{{#%s.", newID)
-		text = strings.ReplaceAll(text, oldPattern, newPattern)
+	const open = "{{#"
+	if len(nodeIDMapping) == 0 || !strings.Contains(text, open) {
+		return text
 	}
-	return text
+
+	var result strings.Builder
+	for {
+		index := strings.Index(text, open)
+		if index < 0 {
+			break
+		}
+		result.WriteString(text[:index+len(open)])
+		text = text[index+len(open):]
+
+		// The ID ends at one of the dots before the closing #
+		end := strings.IndexByte(text, '#')
+		if end < 0 {
+			end = len(text)
+		}
+		for dot := strings.LastIndexByte(text[:end], '.'); dot > 0; dot = strings.LastIndexByte(text[:dot], '.') {
+			if newID, found := nodeIDMapping[text[:dot]]; found {
+				result.WriteString(newID)
+				text = text[dot:]
+				break
+			}
+		}
+	}
+	result.WriteString(text)
+	return result.String()
+}
+
+// CopyIDMapping returns a copy of an ID mapping table.
+func CopyIDMapping(mapping map[string]string) map[string]string {
+	result := make(map[string]string, len(mapping))
+	for k, v := range mapping {
+		result[k] = v
+	}
+	return result
+}
+
+// MappingKeysLongestFirst returns the keys of an ID mapping table, longest first and alphabetical
+// among equal lengths. Replacing in this order does not depend on map iteration and never rewrites
+// the prefix of a longer ID.
+func MappingKeysLongestFirst(mapping map[string]string) []string {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
 }
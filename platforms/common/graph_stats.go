@@ -0,0 +1,189 @@
+package common
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// WorkflowStats describes the size and shape of a workflow, to estimate the effort of a migration.
+type WorkflowStats struct {
+	Nodes     int // All nodes, iteration sub-workflow nodes included
+	Edges     int
+	NodeTypes map[models.NodeType]int
+
+	MaxDepth   int // Nodes on the longest top-level path
+	Iterations int // Iteration sub-workflows
+	MaxNesting int // Deepest iteration nesting, 0 without iterations
+
+	BranchNodes int // Condition and classifier nodes
+	Branches    int // Branches of those nodes, default branches included
+	MaxBranches int
+
+	References int     // Distinct node pairs connected by a variable reference
+	MaxFanIn   NodeFan // Node referencing the most other nodes
+	MaxFanOut  NodeFan // Node referenced by the most other nodes
+}
+
+// NodeFan is a node with its number of distinct referenced or referencing nodes.
+type NodeFan struct {
+	NodeID string
+	Title  string
+	Count  int
+}
+
+// ComputeWorkflowStats computes the statistics of a workflow. Edges closing a cycle are ignored
+// for the depth.
+func ComputeWorkflowStats(unifiedDSL *models.UnifiedDSL) *WorkflowStats {
+	stats := &WorkflowStats{NodeTypes: make(map[models.NodeType]int)}
+	if unifiedDSL == nil {
+		return stats
+	}
+
+	nodes := make(map[string]*models.Node)
+	stats.collect(unifiedDSL.Workflow.Nodes, len(unifiedDSL.Workflow.Edges), 0, nodes)
+	stats.MaxDepth = longestPath(unifiedDSL.Workflow.Nodes, unifiedDSL.Workflow.Edges)
+	stats.countReferences(nodes)
+	return stats
+}
+
+// collect counts the nodes, edges and branches of a workflow level and its sub-workflows
+func (s *WorkflowStats) collect(nodes []models.Node, edges, nesting int, all map[string]*models.Node) {
+	s.Edges += edges
+	if nesting > s.MaxNesting {
+		s.MaxNesting = nesting
+	}
+	for i := range nodes {
+		node := &nodes[i]
+		all[node.ID] = node
+		s.Nodes++
+		s.NodeTypes[node.Type]++
+
+		if branches := countBranches(node); branches > 0 {
+			s.BranchNodes++
+			s.Branches += branches
+			if branches > s.MaxBranches {
+				s.MaxBranches = branches
+			}
+		}
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			s.Iterations++
+			sub := iterConfig.SubWorkflow
+			s.collect(sub.Nodes, len(sub.Edges), nesting+1, all)
+		}
+	}
+}
+
+func countBranches(node *models.Node) int {
+	if config, ok := AsConditionConfig(node.Config); ok && config != nil {
+		cases := make(map[string]bool)
+		for _, conditionCase := range config.Cases {
+			cases[conditionCase.CaseID] = true
+		}
+		if config.DefaultCase != "" {
+			cases[config.DefaultCase] = true
+		}
+		return len(cases)
+	}
+	if config, ok := AsClassifierConfig(node.Config); ok && config != nil {
+		return len(config.Classes)
+	}
+	return 0
+}
+
+// countReferences counts the distinct nodes each node references and is referenced by
+func (s *WorkflowStats) countReferences(nodes map[string]*models.Node) {
+	fanIn := make(map[string]map[string]bool)
+	fanOut := make(map[string]int)
+	for id, node := range nodes {
+		for _, referenced := range referencedNodes(node) {
+			if referenced == id || nodes[referenced] == nil || fanIn[id][referenced] {
+				continue
+			}
+			if fanIn[id] == nil {
+				fanIn[id] = make(map[string]bool)
+			}
+			fanIn[id][referenced] = true
+			fanOut[referenced]++
+			s.References++
+		}
+	}
+
+	for id, node := range nodes {
+		s.MaxFanIn = maxFan(s.MaxFanIn, node, len(fanIn[id]))
+		s.MaxFanOut = maxFan(s.MaxFanOut, node, fanOut[id])
+	}
+}
+
+// maxFan keeps the larger fan, preferring the smaller node ID on ties so output is stable
+func maxFan(current NodeFan, node *models.Node, count int) NodeFan {
+	if count == 0 || count < current.Count || (count == current.Count && node.ID > current.NodeID) {
+		return current
+	}
+	return NodeFan{NodeID: node.ID, Title: node.Title, Count: count}
+}
+
+// referencedNodes returns the IDs of the nodes whose outputs the node reads
+func referencedNodes(node *models.Node) []string {
+	var ids []string
+	for _, input := range node.Inputs {
+		if input.Reference != nil && input.Reference.NodeID != "" {
+			ids = append(ids, input.Reference.NodeID)
+		}
+	}
+	if config, ok := AsEndConfig(node.Config); ok && config != nil {
+		for _, output := range config.Outputs {
+			if output.Reference != nil && output.Reference.NodeID != "" {
+				ids = append(ids, output.Reference.NodeID)
+			} else if len(output.ValueSelector) > 0 {
+				ids = append(ids, output.ValueSelector[0])
+			}
+		}
+	}
+	if config, ok := AsConditionConfig(node.Config); ok && config != nil {
+		for _, conditionCase := range config.Cases {
+			for _, condition := range conditionCase.AllConditions() {
+				if len(condition.VariableSelector) > 0 {
+					ids = append(ids, condition.VariableSelector[0])
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// longestPath returns the number of nodes on the longest path of a workflow level
+func longestPath(nodes []models.Node, edges []models.Edge) int {
+	successors := make(map[string][]string)
+	for _, edge := range edges {
+		successors[edge.Source] = append(successors[edge.Source], edge.Target)
+	}
+
+	depths := make(map[string]int)
+	visiting := make(map[string]bool)
+	var depth func(id string) int
+	depth = func(id string) int {
+		if d, ok := depths[id]; ok {
+			return d
+		}
+		if visiting[id] {
+			return 0
+		}
+		visiting[id] = true
+		longest := 0
+		for _, next := range successors[id] {
+			if d := depth(next); d > longest {
+				longest = d
+			}
+		}
+		visiting[id] = false
+		depths[id] = longest + 1
+		return longest + 1
+	}
+
+	maxDepth := 0
+	for _, node := range nodes {
+		if d := depth(node.ID); d > maxDepth {
+			maxDepth = d
+		}
+	}
+	return maxDepth
+}
@@ -0,0 +1,182 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// HookScript is a declarative node hook loaded from YAML. Each rule edits the nodes its match
+// selects; rules run in order, so later rules see the edits of earlier ones.
+//
+//	before:
+//	  - match: {type: llm}
+//	    set: {system_prompt_prefix: "[migrated] "}
+//	after:
+//	  - match: {type: llm, model: "gpt-*", target: iflytek}
+//	    set: {model: xdeepseekv3}
+type HookScript struct {
+	Before []HookRule `yaml:"before"`
+	After  []HookRule `yaml:"after"`
+}
+
+// HookRule applies Set to the nodes selected by Match.
+type HookRule struct {
+	Match HookMatch `yaml:"match"`
+	Set   HookEdit  `yaml:"set"`
+}
+
+// HookMatch selects nodes. Empty fields match everything; ID, Title and Model accept
+// path.Match patterns such as "gpt-*".
+type HookMatch struct {
+	Type   models.NodeType     `yaml:"type"`
+	ID     string              `yaml:"id"`
+	Title  string              `yaml:"title"`
+	Model  string              `yaml:"model"` // Model name of LLM and classifier nodes
+	Source models.PlatformType `yaml:"source"`
+	Target models.PlatformType `yaml:"target"`
+}
+
+// HookEdit lists the node edits of a rule. Empty fields leave nodes unchanged.
+type HookEdit struct {
+	Title              string `yaml:"title"`
+	TitlePrefix        string `yaml:"title_prefix"`
+	TitleSuffix        string `yaml:"title_suffix"`
+	Description        string `yaml:"description"`
+	Model              string `yaml:"model"` // Model name of LLM and classifier nodes
+	SystemPromptPrefix string `yaml:"system_prompt_prefix"`
+	SystemPromptSuffix string `yaml:"system_prompt_suffix"`
+	UserPromptPrefix   string `yaml:"user_prompt_prefix"`
+	UserPromptSuffix   string `yaml:"user_prompt_suffix"`
+}
+
+// ParseHookScript parses a hook script, rejecting unknown keys and invalid patterns.
+func ParseHookScript(data []byte) (*HookScript, error) {
+	script := &HookScript{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(script); err != nil {
+		return nil, fmt.Errorf("invalid hook script: %w", err)
+	}
+
+	for phase, rules := range map[string][]HookRule{HookPhaseBefore: script.Before, HookPhaseAfter: script.After} {
+		for i, rule := range rules {
+			if err := rule.Match.validate(); err != nil {
+				return nil, fmt.Errorf("invalid %s rule %d: %w", phase, i+1, err)
+			}
+		}
+	}
+	return script, nil
+}
+
+// BeforeNodeConvert applies the before rules.
+func (s *HookScript) BeforeNodeConvert(ctx models.NodeHookContext, node *models.Node) error {
+	applyHookRules(s.Before, ctx, node)
+	return nil
+}
+
+// AfterNodeConvert applies the after rules.
+func (s *HookScript) AfterNodeConvert(ctx models.NodeHookContext, node *models.Node) error {
+	applyHookRules(s.After, ctx, node)
+	return nil
+}
+
+func applyHookRules(rules []HookRule, ctx models.NodeHookContext, node *models.Node) {
+	for _, rule := range rules {
+		if rule.Match.matches(ctx, node) {
+			rule.Set.apply(node)
+		}
+	}
+}
+
+func (m HookMatch) validate() error {
+	for _, pattern := range []string{m.ID, m.Title, m.Model} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (m HookMatch) matches(ctx models.NodeHookContext, node *models.Node) bool {
+	if m.Type != "" && m.Type != node.Type {
+		return false
+	}
+	if m.Source != "" && m.Source != ctx.SourcePlatform {
+		return false
+	}
+	if m.Target != "" && m.Target != ctx.TargetPlatform {
+		return false
+	}
+	if m.Model != "" {
+		name, ok := NodeModelName(node)
+		if !ok || !matchHookPattern(m.Model, name) {
+			return false
+		}
+	}
+	return matchHookPattern(m.ID, node.ID) && matchHookPattern(m.Title, node.Title)
+}
+
+// matchHookPattern reports whether value matches pattern; an empty pattern matches everything.
+func matchHookPattern(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, _ := path.Match(pattern, value)
+	return matched
+}
+
+func (e HookEdit) apply(node *models.Node) {
+	if e.Title != "" {
+		node.Title = e.Title
+	}
+	node.Title = e.TitlePrefix + node.Title + e.TitleSuffix
+	if e.Description != "" {
+		node.Description = e.Description
+	}
+
+	if e.Model != "" {
+		SetNodeModelName(node, e.Model)
+	}
+
+	if e.SystemPromptPrefix == "" && e.SystemPromptSuffix == "" && e.UserPromptPrefix == "" && e.UserPromptSuffix == "" {
+		return
+	}
+	if config, ok := AsLLMConfig(node.Config); ok && config != nil {
+		config.Prompt.SystemTemplate = e.SystemPromptPrefix + config.Prompt.SystemTemplate + e.SystemPromptSuffix
+		config.Prompt.UserTemplate = e.UserPromptPrefix + config.Prompt.UserTemplate + e.UserPromptSuffix
+		if _, isValue := node.Config.(models.LLMConfig); isValue {
+			node.Config = *config
+		}
+	}
+}
+
+// NodeModelName returns the model name of LLM and classifier nodes.
+func NodeModelName(node *models.Node) (string, bool) {
+	if config, ok := AsLLMConfig(node.Config); ok && config != nil {
+		return config.Model.Name, true
+	}
+	if config, ok := AsClassifierConfig(node.Config); ok && config != nil {
+		return config.Model.Name, true
+	}
+	return "", false
+}
+
+// SetNodeModelName renames the model of LLM and classifier nodes, keeping value or pointer config
+// storage unchanged.
+func SetNodeModelName(node *models.Node, name string) {
+	if config, ok := AsLLMConfig(node.Config); ok && config != nil {
+		config.Model.Name = name
+		if _, isValue := node.Config.(models.LLMConfig); isValue {
+			node.Config = *config
+		}
+	} else if config, ok := AsClassifierConfig(node.Config); ok && config != nil {
+		config.Model.Name = name
+		if _, isValue := node.Config.(models.ClassifierConfig); isValue {
+			node.Config = *config
+		}
+	}
+}
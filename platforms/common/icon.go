@@ -0,0 +1,226 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Built-in workflow icons of each platform
+const (
+	DefaultIFlytekIcon    = "https://oss-beijing-m8.openstorage.cn/SparkBotProd/icon/common/emojiitem_00_10@2x.png"
+	DefaultIFlytekColor   = "#FFEAD5"
+	DefaultDifyIcon       = "🤖"
+	DefaultDifyBackground = "#E8F5E8"
+)
+
+// maxEmojiIconRunes bounds emoji icons; flags, skin tones and ZWJ sequences are several runes long
+const maxEmojiIconRunes = 8
+
+// IconKind classifies a workflow icon value.
+type IconKind string
+
+const (
+	IconKindNone    IconKind = "none"
+	IconKindEmoji   IconKind = "emoji"    // Dify emoji icons
+	IconKindURL     IconKind = "url"      // iFlytek avatar images
+	IconKindDataURI IconKind = "data_uri" // Inline base64 images
+	IconKindOther   IconKind = "other"    // Upload IDs and other platform-internal references
+)
+
+var hexColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// ClassifyIcon returns the kind of an icon value.
+func ClassifyIcon(icon string) IconKind {
+	icon = strings.TrimSpace(icon)
+	lower := strings.ToLower(icon)
+	switch {
+	case icon == "":
+		return IconKindNone
+	case strings.HasPrefix(lower, "data:image/") && strings.Contains(lower, ";base64,"):
+		return IconKindDataURI
+	case strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://"):
+		return IconKindURL
+	case isEmoji(icon):
+		return IconKindEmoji
+	default:
+		return IconKindOther
+	}
+}
+
+// isEmoji accepts short values made of symbols only, like "🤖" or "👩‍💻"
+func isEmoji(icon string) bool {
+	if utf8.RuneCountInString(icon) > maxEmojiIconRunes {
+		return false
+	}
+	for _, r := range icon {
+		if r < utf8.RuneSelf || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// TranslatedIcon is a workflow icon in the form of a target platform.
+type TranslatedIcon struct {
+	Icon       string
+	Background string
+	Fallback   bool // The source icon has no target form and was replaced by the default
+}
+
+type iconKey struct {
+	icon, background string
+	target           models.PlatformType
+}
+
+// IconTranslator converts workflow icons between platforms. Translations are cached, so a batch
+// sharing one translator classifies every distinct icon once. It is safe for concurrent use.
+type IconTranslator struct {
+	set    models.IconSet
+	emoji  map[string]string // Emoji to URL
+	images map[string]string // URL to emoji
+
+	mu    sync.Mutex
+	cache map[iconKey]TranslatedIcon
+}
+
+// NewIconTranslator creates a translator; set may be nil for the built-in icons.
+func NewIconTranslator(set *models.IconSet) *IconTranslator {
+	translator := &IconTranslator{
+		emoji:  make(map[string]string),
+		images: make(map[string]string),
+		cache:  make(map[iconKey]TranslatedIcon),
+	}
+	if set != nil {
+		translator.set = *set
+	}
+	defaultString(&translator.set.IFlytekDefault, DefaultIFlytekIcon)
+	defaultString(&translator.set.IFlytekColor, DefaultIFlytekColor)
+	defaultString(&translator.set.DifyDefault, DefaultDifyIcon)
+	defaultString(&translator.set.DifyBackground, DefaultDifyBackground)
+
+	for emoji, url := range translator.set.Emoji {
+		translator.emoji[emoji] = url
+		translator.images[url] = emoji
+	}
+	return translator
+}
+
+func defaultString(value *string, fallback string) {
+	if *value == "" {
+		*value = fallback
+	}
+}
+
+var (
+	iconTranslatorsMu sync.Mutex
+	iconTranslators   = make(map[*models.IconSet]*IconTranslator)
+)
+
+// IconTranslatorFor returns the shared translator of an icon set; a nil set uses the built-in icons.
+// Conversions passing the same set share its cache.
+func IconTranslatorFor(set *models.IconSet) *IconTranslator {
+	iconTranslatorsMu.Lock()
+	defer iconTranslatorsMu.Unlock()
+	translator, ok := iconTranslators[set]
+	if !ok {
+		translator = NewIconTranslator(set)
+		iconTranslators[set] = translator
+	}
+	return translator
+}
+
+// Translate converts an icon and its background color to targetPlatform. Targets without
+// workflow icons get an empty result.
+func (t *IconTranslator) Translate(icon, background string, targetPlatform models.PlatformType) TranslatedIcon {
+	key := iconKey{icon: strings.TrimSpace(icon), background: strings.TrimSpace(background), target: targetPlatform}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if translated, ok := t.cache[key]; ok {
+		return translated
+	}
+
+	var translated TranslatedIcon
+	switch targetPlatform {
+	case models.PlatformIFlytek:
+		translated = t.toIFlytek(key.icon, key.background)
+	case models.PlatformDify:
+		translated = t.toDify(key.icon, key.background)
+	}
+	t.cache[key] = translated
+	return translated
+}
+
+func (t *IconTranslator) toIFlytek(icon, background string) TranslatedIcon {
+	translated := TranslatedIcon{Icon: t.set.IFlytekDefault, Background: colorOr(background, t.set.IFlytekColor)}
+	switch ClassifyIcon(icon) {
+	case IconKindURL:
+		translated.Icon = icon
+	case IconKindEmoji:
+		if url, ok := t.emoji[icon]; ok {
+			translated.Icon = url
+		} else {
+			translated.Fallback = icon != DefaultDifyIcon
+		}
+	case IconKindDataURI, IconKindOther:
+		translated.Fallback = true
+	}
+	return translated
+}
+
+func (t *IconTranslator) toDify(icon, background string) TranslatedIcon {
+	translated := TranslatedIcon{Icon: t.set.DifyDefault, Background: colorOr(background, t.set.DifyBackground)}
+	switch ClassifyIcon(icon) {
+	case IconKindEmoji:
+		translated.Icon = icon
+	case IconKindURL:
+		if emoji, ok := t.images[icon]; ok {
+			translated.Icon = emoji
+		} else {
+			translated.Fallback = icon != DefaultIFlytekIcon
+		}
+	case IconKindDataURI, IconKindOther:
+		translated.Fallback = true
+	}
+	return translated
+}
+
+// colorOr returns color when it is a hex color, fallback otherwise
+func colorOr(color, fallback string) string {
+	if hexColorPattern.MatchString(color) {
+		return color
+	}
+	return fallback
+}
+
+// TranslateWorkflowIcon replaces the workflow icon of the UI config with its targetPlatform
+// form. It returns a warning when a custom icon had to be replaced by the default one.
+func TranslateWorkflowIcon(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType, translator *IconTranslator) []string {
+	uiConfig := unifiedDSL.Metadata.UIConfig
+	if uiConfig == nil || (uiConfig.Icon == "" && uiConfig.IconBackground == "") {
+		return nil
+	}
+
+	translated := translator.Translate(uiConfig.Icon, uiConfig.IconBackground, targetPlatform)
+	if translated.Icon == "" {
+		return nil
+	}
+	var warnings []string
+	if translated.Fallback {
+		source := fmt.Sprintf("%q", uiConfig.Icon)
+		if ClassifyIcon(uiConfig.Icon) == IconKindDataURI {
+			source = "(base64 image)"
+		}
+		warnings = append(warnings, fmt.Sprintf("workflow icon %s has no %s form, using the default icon; map it under icons in the config file",
+			source, targetPlatform))
+	}
+	uiConfig.Icon = translated.Icon
+	uiConfig.IconBackground = translated.Background
+	return warnings
+}
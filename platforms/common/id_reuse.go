@@ -0,0 +1,130 @@
+package common
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// ReusableIDs pairs freshly generated target IDs with the target IDs a previous conversion
+// assigned to the same source keys. An ID is reused only when it has the same kind (the part
+// before "::") as the new one and is not already taken by another key in the current output.
+// The result maps generated ID -> previous ID.
+func ReusableIDs(previous, current map[string]string) map[string]string {
+	replacements := make(map[string]string)
+	ReusableIDsInto(replacements, previous, current)
+	return replacements
+}
+
+// ReusableIDsInto adds the reusable IDs of one mapping table to an existing replacement table.
+func ReusableIDsInto(replacements map[string]string, previous, current map[string]string) {
+	if len(previous) == 0 || len(current) == 0 {
+		return
+	}
+
+	taken := make(map[string]bool, len(current))
+	for _, targetID := range current {
+		taken[targetID] = true
+	}
+	for _, previousID := range replacements {
+		taken[previousID] = true
+	}
+
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		generatedID := current[key]
+		previousID, exists := previous[key]
+		if !exists || previousID == "" || previousID == generatedID {
+			continue
+		}
+		if _, replaced := replacements[generatedID]; replaced {
+			continue
+		}
+		if taken[previousID] || idKind(previousID) != idKind(generatedID) {
+			continue
+		}
+		replacements[generatedID] = previousID
+		taken[previousID] = true
+	}
+}
+
+// ReusableNestedIDsInto adds the reusable IDs of a per-node mapping table (outputs, branches, intents).
+func ReusableNestedIDsInto(replacements map[string]string, previous, current map[string]map[string]string) {
+	nodeIDs := make([]string, 0, len(current))
+	for nodeID := range current {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+
+	for _, nodeID := range nodeIDs {
+		ReusableIDsInto(replacements, previous[nodeID], current[nodeID])
+	}
+}
+
+// ReplaceIDs rewrites every occurrence of the generated IDs in data, longest IDs first.
+func ReplaceIDs(data []byte, replacements map[string]string) []byte {
+	if len(replacements) == 0 {
+		return data
+	}
+
+	oldIDs := MappingKeysLongestFirst(replacements)
+	pairs := make([]string, 0, len(oldIDs)*2)
+	for _, oldID := range oldIDs {
+		pairs = append(pairs, oldID, replacements[oldID])
+	}
+
+	return []byte(strings.NewReplacer(pairs...).Replace(string(data)))
+}
+
+// CollectReusableIDs returns the node, output, branch and intent IDs of current that can take
+// back the IDs recorded in previous, as generated ID -> previous ID.
+func CollectReusableIDs(previous, current *models.IDMapping) map[string]string {
+	replacements := make(map[string]string)
+	if previous == nil || current == nil {
+		return replacements
+	}
+	ReusableIDsInto(replacements, previous.Nodes, current.Nodes)
+	ReusableNestedIDsInto(replacements, previous.Outputs, current.Outputs)
+	ReusableNestedIDsInto(replacements, previous.Branches, current.Branches)
+	ReusableNestedIDsInto(replacements, previous.Intents, current.Intents)
+	return replacements
+}
+
+// ApplyReusedIDs rewrites the target IDs of mapping through replacements.
+func ApplyReusedIDs(mapping *models.IDMapping, replacements map[string]string) {
+	if mapping == nil || len(replacements) == 0 {
+		return
+	}
+	mapping.Nodes = RemapIDValues(mapping.Nodes, replacements)
+	for _, table := range []map[string]map[string]string{mapping.Outputs, mapping.Branches, mapping.Intents} {
+		for nodeID, ids := range table {
+			table[nodeID] = RemapIDValues(ids, replacements)
+		}
+	}
+}
+
+// RemapIDValues returns a copy of mapping with its target IDs rewritten through replacements.
+func RemapIDValues(mapping map[string]string, replacements map[string]string) map[string]string {
+	result := make(map[string]string, len(mapping))
+	for key, targetID := range mapping {
+		if reusedID, exists := replacements[targetID]; exists {
+			targetID = reusedID
+		}
+		result[key] = targetID
+	}
+	return result
+}
+
+// idKind returns the type prefix of a "kind::uuid" style ID, or "" for plain IDs
+func idKind(id string) string {
+	if idx := strings.LastIndex(id, "::"); idx >= 0 {
+		return id[:idx]
+	}
+	return ""
+}
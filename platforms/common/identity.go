@@ -0,0 +1,306 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Identity conversion parses a workflow and generates it for its own platform again. The output
+// keeps the IDs of the source and describes the same workflow; only the layout of the file may
+// differ. CompareWorkflows checks the second part.
+
+// layoutKeys are the keys of the unified DSL and of kept platform fields that place and draw
+// nodes on the canvas without changing what the workflow does. The iFlytek editor also caches
+// the variables a node may pick from in references.
+var layoutKeys = map[string]bool{
+	"position": true, "positionAbsolute": true, "size": true, "width": true, "height": true,
+	"selected": true, "zIndex": true, "sourcePosition": true, "targetPosition": true,
+	"icon": true, "references": true,
+}
+
+// generatedIDPattern matches the random UUIDs generators give variables, inputs, conditions and
+// prompt messages. They only link parts of one file, and are not kept across a conversion.
+var generatedIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// IdentityMapping maps the node, trigger, branch and intent IDs of a parsed workflow, iteration
+// bodies included, to themselves. Passed as the previous mapping of a conversion to the source platform,
+// it makes generators keep the source IDs.
+func IdentityMapping(unifiedDSL *models.UnifiedDSL, platform models.PlatformType) *models.IDMapping {
+	mapping := models.NewIDMapping()
+	mapping.SourcePlatform = platform
+	mapping.TargetPlatform = platform
+	if unifiedDSL == nil {
+		return mapping
+	}
+
+	var collect func(nodes []models.Node)
+	collect = func(nodes []models.Node) {
+		for _, node := range nodes {
+			mapping.Nodes[node.ID] = node.ID
+			if conditionConfig, ok := AsConditionConfig(node.Config); ok && conditionConfig != nil {
+				for _, caseItem := range conditionConfig.Cases {
+					if caseItem.CaseID != "" {
+						mapping.AddBranch(node.ID, caseItem.CaseID, caseItem.CaseID)
+					}
+				}
+			}
+			if classifierConfig, ok := AsClassifierConfig(node.Config); ok && classifierConfig != nil {
+				for _, class := range classifierConfig.Classes {
+					if class.ID != "" {
+						mapping.AddIntent(node.ID, class.ID, class.ID)
+					}
+				}
+			}
+			if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+				collect(iterConfig.SubWorkflow.Nodes)
+			}
+		}
+	}
+	collect(unifiedDSL.Workflow.Nodes)
+	for _, trigger := range unifiedDSL.Workflow.Triggers {
+		mapping.Nodes[trigger.ID] = trigger.ID
+	}
+	return mapping
+}
+
+// CompareWorkflows lists the differences between two workflows that change what they do, as
+// "<where>: <expected> != <actual>" lines sorted by node. Positions and sizes are ignored, and
+// edges are compared by the nodes and handles they connect rather than by their IDs. Iteration
+// bodies are compared the same way, so the order of their nodes does not matter either.
+func CompareWorkflows(expected, actual *models.Workflow) ([]string, error) {
+	var differences []string
+	if err := compareGraphs("", expected.Nodes, actual.Nodes, expected.Edges, actual.Edges, &differences); err != nil {
+		return nil, err
+	}
+
+	for _, part := range []struct {
+		name             string
+		expected, actual interface{}
+	}{
+		{"variables", expected.Variables, actual.Variables},
+		{"features", expected.Features, actual.Features},
+		{"triggers", expected.Triggers, actual.Triggers},
+	} {
+		expectedValue, err := normalizedValue(part.expected)
+		if err != nil {
+			return nil, err
+		}
+		actualValue, err := normalizedValue(part.actual)
+		if err != nil {
+			return nil, err
+		}
+		diffValues(part.name, expectedValue, actualValue, &differences)
+	}
+	return differences, nil
+}
+
+// compareGraphs appends the differences between two sets of nodes and edges, naming them after
+// prefix
+func compareGraphs(prefix string, expected, actual []models.Node, expectedEdgeList, actualEdgeList []models.Edge, differences *[]string) error {
+	expectedNodes, expectedBodies, err := nodesByID(expected)
+	if err != nil {
+		return err
+	}
+	actualNodes, actualBodies, err := nodesByID(actual)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range unionKeys(expectedNodes, actualNodes) {
+		expectedNode, inExpected := expectedNodes[id]
+		actualNode, inActual := actualNodes[id]
+		switch {
+		case !inActual:
+			*differences = append(*differences, fmt.Sprintf("%snode %s: missing", prefix, id))
+		case !inExpected:
+			*differences = append(*differences, fmt.Sprintf("%snode %s: unexpected", prefix, id))
+		default:
+			diffValues(fmt.Sprintf("%snode %s", prefix, id), expectedNode, actualNode, differences)
+			expectedBody, actualBody := expectedBodies[id], actualBodies[id]
+			if expectedBody != nil || actualBody != nil {
+				if expectedBody == nil {
+					expectedBody = &models.SubWorkflowConfig{}
+				}
+				if actualBody == nil {
+					actualBody = &models.SubWorkflowConfig{}
+				}
+				bodyPrefix := fmt.Sprintf("%snode %s body: ", prefix, id)
+				if err := compareGraphs(bodyPrefix, expectedBody.Nodes, actualBody.Nodes, expectedBody.Edges, actualBody.Edges, differences); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	expectedEdges, actualEdges := edgeKeys(expectedEdgeList), edgeKeys(actualEdgeList)
+	for _, key := range unionKeys(expectedEdges, actualEdges) {
+		if !expectedEdges[key] {
+			*differences = append(*differences, fmt.Sprintf("%sedge %s: unexpected", prefix, key))
+		} else if !actualEdges[key] {
+			*differences = append(*differences, fmt.Sprintf("%sedge %s: missing", prefix, key))
+		}
+	}
+	return nil
+}
+
+// nodesByID returns the normalized nodes of a workflow by ID, and the bodies of its iterations,
+// which are left out of the normalized nodes
+func nodesByID(nodes []models.Node) (map[string]interface{}, map[string]*models.SubWorkflowConfig, error) {
+	result := make(map[string]interface{}, len(nodes))
+	bodies := make(map[string]*models.SubWorkflowConfig)
+	for _, node := range nodes {
+		value, err := normalizedValue(node)
+		if err != nil {
+			return nil, nil, fmt.Errorf("node %s: %w", node.ID, err)
+		}
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			bodies[node.ID] = &iterConfig.SubWorkflow
+			if config, ok := value.(map[string]interface{})["config"].(map[string]interface{}); ok {
+				if body, ok := config["sub_workflow"].(map[string]interface{}); ok {
+					delete(body, "nodes")
+					delete(body, "edges")
+				}
+			}
+		}
+		result[node.ID] = value
+	}
+	return result, bodies, nil
+}
+
+// edgeKeys returns the connections of edges as "source[handle] -> target[handle]" keys
+func edgeKeys(edges []models.Edge) map[string]bool {
+	keys := make(map[string]bool, len(edges))
+	for _, edge := range edges {
+		key := edge.Source
+		if edge.SourceHandle != "" && edge.SourceHandle != "source" {
+			key += "[" + edge.SourceHandle + "]"
+		}
+		key += " -> " + edge.Target
+		if edge.TargetHandle != "" && edge.TargetHandle != "target" {
+			key += "[" + edge.TargetHandle + "]"
+		}
+		keys[key] = true
+	}
+	return keys
+}
+
+// normalizedValue converts a value to its JSON form without layout keys and generated IDs, so
+// values of different Go types with the same content compare equal
+func normalizedValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return dropLayout(normalized), nil
+}
+
+// dropLayout removes layout keys, generated IDs and empty values at any depth, and the whitespace
+// around texts. Node IDs carry a type prefix and are never taken for generated ones.
+func dropLayout(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case string:
+		return strings.TrimSpace(typed)
+	case map[string]interface{}:
+		for key, item := range typed {
+			item = dropLayout(item)
+			if text, ok := item.(string); ok && generatedIDPattern.MatchString(text) {
+				delete(typed, key)
+				continue
+			}
+			if layoutKeys[key] || isBlankValue(item) {
+				delete(typed, key)
+				continue
+			}
+			typed[key] = item
+		}
+		return typed
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = dropLayout(item)
+		}
+		return typed
+	}
+	return value
+}
+
+// isBlankValue reports whether a JSON value carries nothing, so that a missing key and a zero
+// value compare equal
+func isBlankValue(value interface{}) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	case bool:
+		return !typed
+	case float64:
+		return typed == 0
+	case map[string]interface{}:
+		return len(typed) == 0
+	case []interface{}:
+		return len(typed) == 0
+	}
+	return false
+}
+
+// diffValues appends the differences between two normalized values below path
+func diffValues(path string, expected, actual interface{}, differences *[]string) {
+	expectedMap, expectedIsMap := expected.(map[string]interface{})
+	actualMap, actualIsMap := actual.(map[string]interface{})
+	if expectedIsMap && actualIsMap {
+		for _, key := range unionKeys(expectedMap, actualMap) {
+			diffValues(path+"."+key, expectedMap[key], actualMap[key], differences)
+		}
+		return
+	}
+
+	expectedList, expectedIsList := expected.([]interface{})
+	actualList, actualIsList := actual.([]interface{})
+	if expectedIsList && actualIsList && len(expectedList) == len(actualList) {
+		for i := range expectedList {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), expectedList[i], actualList[i], differences)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		*differences = append(*differences, fmt.Sprintf("%s: %s != %s", path, compactJSON(expected), compactJSON(actual)))
+	}
+}
+
+// compactJSON renders a normalized value for a difference line
+func compactJSON(value interface{}) string {
+	if value == nil {
+		return "(none)"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// unionKeys returns the keys of both maps, sorted
+func unionKeys[V any](first, second map[string]V) []string {
+	keys := make([]string, 0, len(first)+len(second))
+	for key := range first {
+		keys = append(keys, key)
+	}
+	for key := range second {
+		if _, exists := first[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
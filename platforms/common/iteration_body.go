@@ -0,0 +1,125 @@
+package common
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// TopLevelNodes returns the nodes of a workflow outside iterations, with the edges between them.
+func TopLevelNodes(unifiedDSL *models.UnifiedDSL) ([]*models.Node, []models.Edge) {
+	var nodes []*models.Node
+	ids := make(map[string]bool)
+	for i := range unifiedDSL.Workflow.Nodes {
+		node := &unifiedDSL.Workflow.Nodes[i]
+		if IterationParent(node) != "" {
+			continue
+		}
+		if config, ok := AsStartConfig(node.Config); ok && config != nil && config.IsInIteration {
+			continue
+		}
+		nodes = append(nodes, node)
+		ids[node.ID] = true
+	}
+
+	var edges []models.Edge
+	for _, edge := range unifiedDSL.Workflow.Edges {
+		if ids[edge.Source] && ids[edge.Target] {
+			edges = append(edges, edge)
+		}
+	}
+	return nodes, edges
+}
+
+// IterationBody returns the nodes an iteration runs for each item with the edges leading to them,
+// edges from the iteration node itself included. Parsers keep the nodes in the sub-workflow, at the
+// top level with the iteration ID in their config, or both; top-level copies win.
+func IterationBody(unifiedDSL *models.UnifiedDSL, iteration *models.Node) ([]*models.Node, []models.Edge) {
+	iterConfig, ok := AsIterationConfig(iteration.Config)
+	if !ok || iterConfig == nil {
+		return nil, nil
+	}
+
+	var nodes []*models.Node
+	ids := make(map[string]bool)
+	for i := range unifiedDSL.Workflow.Nodes {
+		if node := &unifiedDSL.Workflow.Nodes[i]; IterationParent(node) == iteration.ID {
+			nodes = append(nodes, node)
+			ids[node.ID] = true
+		}
+	}
+	for i := range iterConfig.SubWorkflow.Nodes {
+		if node := &iterConfig.SubWorkflow.Nodes[i]; !ids[node.ID] {
+			nodes = append(nodes, node)
+			ids[node.ID] = true
+		}
+	}
+
+	var edges []models.Edge
+	seen := make(map[[3]string]bool)
+	for _, edge := range append(append([]models.Edge(nil), iterConfig.SubWorkflow.Edges...), unifiedDSL.Workflow.Edges...) {
+		key := [3]string{edge.Source, edge.SourceHandle, edge.Target}
+		if seen[key] || !ids[edge.Target] || !(ids[edge.Source] || edge.Source == iteration.ID) {
+			continue
+		}
+		seen[key] = true
+		edges = append(edges, edge)
+	}
+	return nodes, edges
+}
+
+// NestIterationBodies returns a workflow whose iterations hold their bodies in the sub-workflow,
+// for generators that expect them there: nodes kept at the top level with the iteration ID in their
+// config, as the Dify parser does, move into the sub-workflow of their iteration with the edges
+// between them. Workflows without such nodes are returned as they are; the input is not modified.
+func NestIterationBodies(unifiedDSL *models.UnifiedDSL) *models.UnifiedDSL {
+	flat := false
+	for i := range unifiedDSL.Workflow.Nodes {
+		if IterationParent(&unifiedDSL.Workflow.Nodes[i]) != "" {
+			flat = true
+			break
+		}
+	}
+	if !flat {
+		return unifiedDSL
+	}
+
+	nested := *unifiedDSL
+	nested.Workflow.Nodes = make([]models.Node, 0, len(unifiedDSL.Workflow.Nodes))
+	moved := make(map[string]bool)
+	for i := range unifiedDSL.Workflow.Nodes {
+		node := unifiedDSL.Workflow.Nodes[i]
+		if node.Type == models.NodeTypeIteration {
+			if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+				body, bodyEdges := IterationBody(unifiedDSL, &unifiedDSL.Workflow.Nodes[i])
+				subWorkflow := models.SubWorkflowConfig{StartNodeID: iterConfig.SubWorkflow.StartNodeID, EndNodeID: iterConfig.SubWorkflow.EndNodeID}
+				for _, bodyNode := range body {
+					subWorkflow.Nodes = append(subWorkflow.Nodes, *bodyNode)
+					moved[bodyNode.ID] = true
+				}
+				for _, edge := range bodyEdges {
+					if edge.Source != node.ID {
+						subWorkflow.Edges = append(subWorkflow.Edges, edge)
+					}
+				}
+				config := *iterConfig
+				config.SubWorkflow = subWorkflow
+				node.Config = &config
+			}
+		}
+		nested.Workflow.Nodes = append(nested.Workflow.Nodes, node)
+	}
+
+	kept := nested.Workflow.Nodes[:0]
+	for _, node := range nested.Workflow.Nodes {
+		if !moved[node.ID] {
+			kept = append(kept, node)
+		}
+	}
+	nested.Workflow.Nodes = kept
+	nested.Workflow.Edges = nil
+	for _, edge := range unifiedDSL.Workflow.Edges {
+		if !moved[edge.Source] && !moved[edge.Target] {
+			nested.Workflow.Edges = append(nested.Workflow.Edges, edge)
+		}
+	}
+	return &nested
+}
@@ -0,0 +1,48 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// terminatingIterationPlatforms stop iterations at the first failing item
+var terminatingIterationPlatforms = map[models.PlatformType]bool{
+	models.PlatformIFlytek: true,
+	models.PlatformCoze:    true,
+}
+
+// sequentialIterationPlatforms run iteration items one by one. Parallel iterations become batch
+// nodes on Coze.
+var sequentialIterationPlatforms = map[models.PlatformType]bool{
+	models.PlatformIFlytek: true,
+}
+
+// CheckIterationExecution returns a warning per iteration whose parallelism or error handling
+// targetPlatform cannot express.
+func CheckIterationExecution(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) []string {
+	if unifiedDSL == nil || !terminatingIterationPlatforms[targetPlatform] {
+		return nil
+	}
+	return checkIterationExecution(unifiedDSL.Workflow.Nodes, targetPlatform, nil)
+}
+
+func checkIterationExecution(nodes []models.Node, targetPlatform models.PlatformType, warnings []string) []string {
+	for _, node := range nodes {
+		iterConfig, ok := AsIterationConfig(node.Config)
+		if !ok || iterConfig == nil {
+			continue
+		}
+		execution := iterConfig.Execution
+		if execution.IsParallel && sequentialIterationPlatforms[targetPlatform] {
+			warnings = append(warnings, fmt.Sprintf("iteration node %q runs its items one by one on %s instead of %d at a time",
+				node.Title, targetPlatform, execution.ParallelNums))
+		}
+		if mode := models.NormalizeErrorHandleMode(execution.ErrorHandleMode); mode != models.ErrorHandleModeTerminated {
+			warnings = append(warnings, fmt.Sprintf("iteration node %q stops at the first failing item on %s; %s is not supported",
+				node.Title, targetPlatform, mode))
+		}
+		warnings = checkIterationExecution(iterConfig.SubWorkflow.Nodes, targetPlatform, warnings)
+	}
+	return warnings
+}
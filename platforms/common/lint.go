@@ -0,0 +1,418 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// LintRule is a best-practice check of the lint command.
+type LintRule struct {
+	Name        string
+	Description string
+	Severity    models.ErrorSeverity // Default severity
+	check       func(workflow *lintWorkflow) []LintFinding
+}
+
+// LintFinding is a place where a workflow breaks a lint rule.
+type LintFinding struct {
+	Rule      string               `json:"rule"`
+	Severity  models.ErrorSeverity `json:"severity"`
+	NodeID    string               `json:"node_id,omitempty"`
+	NodeTitle string               `json:"node_title,omitempty"`
+	Message   string               `json:"message"`
+}
+
+// LintRules returns the lint rules in report order.
+func LintRules() []LintRule {
+	return []LintRule{
+		{Name: "unused-output", Severity: models.SeverityWarning, check: lintUnusedOutputs,
+			Description: "Node outputs no other node reads"},
+		{Name: "unused-start-variable", Severity: models.SeverityWarning, check: lintUnusedStartVariables,
+			Description: "Start variables no node reads"},
+		{Name: "llm-temperature", Severity: models.SeverityInfo, check: lintLLMTemperature,
+			Description: "LLM nodes without a temperature, left to the platform default"},
+		{Name: "iteration-output-selector", Severity: models.SeverityError, check: lintIterationOutputSelectors,
+			Description: "Iterations without an output selector, or with one pointing outside their sub-workflow"},
+		{Name: "branch-without-target", Severity: models.SeverityError, check: lintBranchTargets,
+			Description: "Condition and classifier branches not connected to any node"},
+		{Name: "code-sandbox", Severity: models.SeverityWarning, check: lintCodeSandbox,
+			Description: "Code with third-party imports, network, file or process access, or variables that are not inputs"},
+	}
+}
+
+// Lint checks a workflow against the lint rules selected by config. Findings are sorted by
+// rule, then node order.
+func Lint(unifiedDSL *models.UnifiedDSL, config models.LintConfig) ([]LintFinding, error) {
+	rules := LintRules()
+	known := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		known[rule.Name] = true
+	}
+	disabled := make(map[string]bool)
+	for _, name := range config.Disable {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+		disabled[name] = true
+	}
+	for name, severity := range config.Severity {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+		switch severity {
+		case models.SeverityError, models.SeverityWarning, models.SeverityInfo, models.LintSeverityOff:
+		default:
+			return nil, fmt.Errorf("invalid severity %q for lint rule %s (expected error|warning|info|off)", severity, name)
+		}
+	}
+
+	if unifiedDSL == nil {
+		return nil, nil
+	}
+	workflow := newLintWorkflow(unifiedDSL)
+	var findings []LintFinding
+	for _, rule := range rules {
+		severity := rule.Severity
+		if override, ok := config.Severity[rule.Name]; ok {
+			severity = override
+		}
+		if disabled[rule.Name] || severity == models.LintSeverityOff {
+			continue
+		}
+		for _, finding := range rule.check(workflow) {
+			finding.Rule = rule.Name
+			finding.Severity = severity
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// lintWorkflow is a workflow prepared for the lint rules
+type lintWorkflow struct {
+	nodes   []*lintNode                // All nodes once, in document order
+	handles map[string]map[string]bool // Source handles of the edges leaving a node
+	used    map[string]map[string]bool // Outputs read by some node, by node ID
+}
+
+type lintNode struct {
+	*models.Node
+	iterationID string // Enclosing iteration, empty at the top level
+}
+
+// children returns the nodes of an iteration. Parsers keep them in the sub-workflow, at the top
+// level with the iteration ID in their config, or both.
+func (w *lintWorkflow) children(iterationID string) []*lintNode {
+	var children []*lintNode
+	for _, node := range w.nodes {
+		if node.iterationID == iterationID {
+			children = append(children, node)
+		}
+	}
+	return children
+}
+
+func newLintWorkflow(unifiedDSL *models.UnifiedDSL) *lintWorkflow {
+	workflow := &lintWorkflow{
+		handles: make(map[string]map[string]bool),
+		used:    make(map[string]map[string]bool),
+	}
+	workflow.addLevel(unifiedDSL.Workflow.Nodes, unifiedDSL.Workflow.Edges, "", make(map[string]bool))
+	for _, node := range workflow.nodes {
+		workflow.collectReads(node.Node)
+	}
+	return workflow
+}
+
+func (w *lintWorkflow) addLevel(nodes []models.Node, edges []models.Edge, iterationID string, seen map[string]bool) {
+	for _, edge := range edges {
+		if w.handles[edge.Source] == nil {
+			w.handles[edge.Source] = make(map[string]bool)
+		}
+		w.handles[edge.Source][edge.SourceHandle] = true
+	}
+	for i := range nodes {
+		if seen[nodes[i].ID] {
+			continue
+		}
+		seen[nodes[i].ID] = true
+		parent := iterationID
+		if parent == "" {
+			parent = IterationParent(&nodes[i])
+		}
+		w.nodes = append(w.nodes, &lintNode{Node: &nodes[i], iterationID: parent})
+		if iterConfig, ok := AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+			w.addLevel(iterConfig.SubWorkflow.Nodes, iterConfig.SubWorkflow.Edges, nodes[i].ID, seen)
+		}
+	}
+}
+
+// IterationParent returns the iteration a top-level node belongs to according to its config, as
+// parsers keeping iteration bodies beside the iteration record it
+func IterationParent(node *models.Node) string {
+	value := reflect.ValueOf(node.Config)
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return ""
+	}
+	for _, name := range []string{"IterationID", "ParentID"} {
+		if field := value.FieldByName(name); field.IsValid() && field.Kind() == reflect.String && field.String() != "" {
+			return field.String()
+		}
+	}
+	return ""
+}
+
+func (w *lintWorkflow) markUsed(nodeID, output string) {
+	if nodeID == "" || output == "" {
+		return
+	}
+	if w.used[nodeID] == nil {
+		w.used[nodeID] = make(map[string]bool)
+	}
+	w.used[nodeID][output] = true
+}
+
+// templateNodeReference matches Dify ({{#node.output#}}) and unified ({{$nodes.node.output}})
+// references inside prompt and answer templates
+var templateNodeReference = regexp.MustCompile(`\{\{(?:#|\$nodes\.)([^#.{}]+)\.([^#.{}]+)`)
+
+// collectReads marks the outputs a node reads through references, selectors and templates
+func (w *lintWorkflow) collectReads(node *models.Node) {
+	w.collectValueReads(reflect.ValueOf(node.Inputs), "")
+	// Sub-workflow nodes are collected on their own
+	if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+		w.markUsed(iterConfig.Iterator.SourceNode, iterConfig.Iterator.SourceOutput)
+		w.markUsed(iterConfig.OutputSelector.NodeID, iterConfig.OutputSelector.OutputName)
+		return
+	}
+	if config, ok := AsClassifierConfig(node.Config); ok && config != nil {
+		if nodeID, output, found := strings.Cut(config.QueryVariable, "."); found {
+			w.markUsed(nodeID, output)
+		}
+	}
+	w.collectValueReads(reflect.ValueOf(node.Config), "")
+}
+
+func (w *lintWorkflow) collectValueReads(value reflect.Value, field string) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			w.collectValueReads(value.Elem(), field)
+		}
+	case reflect.Struct:
+		if reference, ok := value.Interface().(models.VariableReference); ok {
+			w.markUsed(reference.NodeID, reference.OutputName)
+			w.collectTemplateReads(reference.Template)
+			return
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).IsExported() {
+				w.collectValueReads(value.Field(i), value.Type().Field(i).Name)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		// Dify-style selectors: node ID, output name, then nested keys
+		if selector, ok := value.Interface().([]string); ok && strings.HasSuffix(field, "Selector") {
+			if len(selector) >= 2 {
+				w.markUsed(selector[0], selector[1])
+			}
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			w.collectValueReads(value.Index(i), field)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			w.collectValueReads(iter.Value(), field)
+		}
+	case reflect.String:
+		w.collectTemplateReads(value.String())
+	}
+}
+
+func (w *lintWorkflow) collectTemplateReads(text string) {
+	for _, match := range templateNodeReference.FindAllStringSubmatch(text, -1) {
+		w.markUsed(strings.TrimSpace(match[1]), strings.TrimSpace(match[2]))
+	}
+}
+
+func nodeFinding(node *lintNode, message string) LintFinding {
+	return LintFinding{NodeID: node.ID, NodeTitle: node.Title, Message: message}
+}
+
+// lintUnusedOutputs reports outputs of working nodes nothing reads. Start variables have their own
+// rule; end nodes and branches produce no data of their own, and placeholders keep the outputs of
+// the node they replace. Nodes of iterations whose output selector names no node are skipped,
+// since the iteration may return any of their outputs.
+func lintUnusedOutputs(workflow *lintWorkflow) []LintFinding {
+	unresolved := make(map[string]bool)
+	for _, node := range workflow.nodes {
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil && iterConfig.OutputSelector.NodeID == "" {
+			unresolved[node.ID] = true
+		}
+	}
+
+	var findings []LintFinding
+	for _, node := range workflow.nodes {
+		switch node.Type {
+		case models.NodeTypeStart, models.NodeTypeEnd, models.NodeTypeCondition, models.NodeTypeClassifier:
+			continue
+		}
+		if node.PlatformConfig.Placeholder != nil || unresolved[node.iterationID] {
+			continue
+		}
+		for _, output := range node.Outputs {
+			if !workflow.used[node.ID][output.Name] {
+				findings = append(findings, nodeFinding(node, fmt.Sprintf("output %q is never read", output.Name)))
+			}
+		}
+	}
+	return findings
+}
+
+// lintUnusedStartVariables reports workflow inputs nothing reads. The iFlytek user input cannot
+// be removed, so it is not reported.
+func lintUnusedStartVariables(workflow *lintWorkflow) []LintFinding {
+	var findings []LintFinding
+	for _, node := range workflow.nodes {
+		if node.Type != models.NodeTypeStart || node.iterationID != "" {
+			continue
+		}
+		for _, output := range node.Outputs {
+			if output.Name != "AGENT_USER_INPUT" && !workflow.used[node.ID][output.Name] {
+				findings = append(findings, nodeFinding(node, fmt.Sprintf("start variable %q is never read", output.Name)))
+			}
+		}
+	}
+	return findings
+}
+
+func lintLLMTemperature(workflow *lintWorkflow) []LintFinding {
+	var findings []LintFinding
+	for _, node := range workflow.nodes {
+		if config, ok := AsLLMConfig(node.Config); ok && config != nil && config.Parameters.Temperature == 0 {
+			findings = append(findings, nodeFinding(node, "temperature is not set; platforms apply different defaults"))
+		}
+	}
+	return findings
+}
+
+// lintIterationOutputSelectors reports iterations that return nothing. iFlytek iterations return
+// through an end node of their sub-workflow and Coze iterations declare their outputs on the
+// iteration node, so either counts as an output.
+func lintIterationOutputSelectors(workflow *lintWorkflow) []LintFinding {
+	var findings []LintFinding
+	for _, node := range workflow.nodes {
+		iterConfig, ok := AsIterationConfig(node.Config)
+		if !ok || iterConfig == nil {
+			continue
+		}
+		children := workflow.children(node.ID)
+
+		if selector := iterConfig.OutputSelector; selector.NodeID != "" {
+			found := false
+			for _, child := range children {
+				found = found || child.ID == selector.NodeID
+			}
+			if !found {
+				findings = append(findings, nodeFinding(node, fmt.Sprintf("output selector reads node %s outside the sub-workflow", selector.NodeID)))
+			}
+			continue
+		}
+		if len(node.Outputs) > 0 || hasEndNode(children) {
+			continue
+		}
+		findings = append(findings, nodeFinding(node, "iteration has no output selector, so it returns nothing"))
+	}
+	return findings
+}
+
+// hasEndNode reports whether the nodes include an end node returning something
+func hasEndNode(nodes []*lintNode) bool {
+	for _, node := range nodes {
+		if node.Type != models.NodeTypeEnd {
+			continue
+		}
+		if config, ok := AsEndConfig(node.Config); len(node.Inputs) > 0 || (ok && config != nil && len(config.Outputs) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintCodeSandbox reports the code findings that may fail in a sandbox; plain imports are left out
+func lintCodeSandbox(workflow *lintWorkflow) []LintFinding {
+	var findings []LintFinding
+	for _, node := range workflow.nodes {
+		for _, finding := range AnalyzeCode(node.Node, "") {
+			if finding.Severity != models.SeverityInfo {
+				findings = append(findings, nodeFinding(node, finding.String()))
+			}
+		}
+	}
+	return findings
+}
+
+// lintBranchTargets reports branches without an edge. Platforms name branch handles differently;
+// when the handles are the branch IDs, the unconnected branches are named, otherwise the edges
+// are counted.
+func lintBranchTargets(workflow *lintWorkflow) []LintFinding {
+	var findings []LintFinding
+	for _, node := range workflow.nodes {
+		branches := branchNames(node.Node)
+		if len(branches) == 0 {
+			continue
+		}
+		handles := workflow.handles[node.ID]
+
+		var ids []string
+		matched := 0
+		for id := range branches {
+			ids = append(ids, id)
+			if handles[id] {
+				matched++
+			}
+		}
+		sort.Strings(ids)
+		if matched > 0 && matched == len(handles) {
+			for _, id := range ids {
+				if !handles[id] {
+					findings = append(findings, nodeFinding(node, fmt.Sprintf("branch %s has no target", branches[id])))
+				}
+			}
+			continue
+		}
+		if len(handles) < len(branches) {
+			findings = append(findings, nodeFinding(node, fmt.Sprintf("%d of %d branches have no target", len(branches)-len(handles), len(branches))))
+		}
+	}
+	return findings
+}
+
+// branchNames returns the branches of a condition or classifier node by ID, with a readable name
+func branchNames(node *models.Node) map[string]string {
+	branches := make(map[string]string)
+	if config, ok := AsConditionConfig(node.Config); ok && config != nil {
+		for i, conditionCase := range config.Cases {
+			branches[conditionCase.CaseID] = fmt.Sprintf("%d (%s)", i+1, conditionCase.CaseID)
+		}
+		if config.DefaultCase != "" {
+			branches[config.DefaultCase] = fmt.Sprintf("default (%s)", config.DefaultCase)
+		}
+	}
+	if config, ok := AsClassifierConfig(node.Config); ok && config != nil {
+		for _, class := range config.Classes {
+			branches[class.ID] = fmt.Sprintf("%q", class.Name)
+		}
+	}
+	return branches
+}
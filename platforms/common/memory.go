@@ -0,0 +1,98 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// BoundedMemoryWindow is the number of history rounds iFlytek and Coze models see when the
+// source keeps the whole conversation, which they cannot.
+const BoundedMemoryWindow = 10
+
+// difyChatflowMode is the Dify app mode with conversations; workflow apps have no history.
+const difyChatflowMode = "advanced-chat"
+
+// MemoryOf returns the memory of LLM and classifier nodes, nil for other nodes.
+func MemoryOf(node models.Node) *models.MemoryConfig {
+	if llmConfig, ok := AsLLMConfig(node.Config); ok && llmConfig != nil {
+		return llmConfig.Memory
+	}
+	if classifierConfig, ok := AsClassifierConfig(node.Config); ok && classifierConfig != nil {
+		return classifierConfig.Memory
+	}
+	return nil
+}
+
+// ChatHistory returns the chat history switch and rounds of platforms that bound the history.
+// defaultRounds is kept when the history is off.
+func ChatHistory(memory *models.MemoryConfig, defaultRounds int) (bool, int) {
+	if memory == nil || !memory.Enabled {
+		return false, defaultRounds
+	}
+	if memory.Window > 0 {
+		return true, memory.Window
+	}
+	return true, BoundedMemoryWindow
+}
+
+// IsDifyChatflow reports whether the Dify DSL generated from unifiedDSL is a chatflow. Dify
+// sources keep their app mode; human input nodes need conversation variables.
+func IsDifyChatflow(unifiedDSL *models.UnifiedDSL) bool {
+	if difyMeta := unifiedDSL.PlatformMetadata.Dify; difyMeta != nil && difyMeta.Mode == difyChatflowMode {
+		return true
+	}
+	return hasNodeType(unifiedDSL.Workflow.Nodes, models.NodeTypeHumanInput)
+}
+
+func hasNodeType(nodes []models.Node, nodeType models.NodeType) bool {
+	for _, node := range nodes {
+		if node.Type == nodeType {
+			return true
+		}
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil && hasNodeType(iterConfig.SubWorkflow.Nodes, nodeType) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckMemory returns a warning per node, including iteration sub-workflow nodes, whose
+// conversation history targetPlatform drops or shortens.
+func CheckMemory(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) []string {
+	if unifiedDSL == nil {
+		return nil
+	}
+	switch targetPlatform {
+	case models.PlatformDify:
+		if IsDifyChatflow(unifiedDSL) {
+			return nil
+		}
+	case models.PlatformIFlytek, models.PlatformCoze:
+	default:
+		return nil
+	}
+	return checkMemory(unifiedDSL.Workflow.Nodes, targetPlatform, nil)
+}
+
+func checkMemory(nodes []models.Node, targetPlatform models.PlatformType, warnings []string) []string {
+	for _, node := range nodes {
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			warnings = checkMemory(iterConfig.SubWorkflow.Nodes, targetPlatform, warnings)
+			continue
+		}
+		memory := MemoryOf(node)
+		if memory == nil || !memory.Enabled {
+			continue
+		}
+
+		switch {
+		case targetPlatform == models.PlatformDify:
+			warnings = append(warnings, fmt.Sprintf("node %q reads the conversation history, which Dify workflow apps do not have; its memory is dropped", node.Title))
+		case memory.Window == 0:
+			warnings = append(warnings, fmt.Sprintf("node %q reads the whole conversation history, but %s keeps only the last %d rounds",
+				node.Title, targetPlatform, BoundedMemoryWindow))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,218 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// MinifyReport describes the size savings of a minified output.
+type MinifyReport struct {
+	OriginalBytes   int
+	MinifiedBytes   int
+	RemovedFields   int // Default-valued fields dropped
+	InternedStrings int // Repeated strings written once as a YAML anchor
+}
+
+// SavedPercent returns the size reduction in percent of the original size.
+func (r *MinifyReport) SavedPercent() float64 {
+	if r.OriginalBytes == 0 {
+		return 0
+	}
+	return float64(r.OriginalBytes-r.MinifiedBytes) * 100 / float64(r.OriginalBytes)
+}
+
+// defaultField is a key whose value the target platform assumes when the key is missing.
+// A key starting with "*" matches every key ending with the rest, "*" alone matches any key.
+type defaultField struct {
+	key   string
+	value interface{}
+}
+
+// minifyProfile lists what the importer of a platform tolerates in a smaller document.
+type minifyProfile struct {
+	internStrings bool // Importer resolves YAML anchors and aliases
+	defaults      []defaultField
+}
+
+// minifyProfiles holds the size optimizations per target platform. Only fields the importers
+// fill in themselves are dropped: editor state and empty validation messages on iFlytek, node
+// state on Dify and the nil pointers the Coze schema serializes.
+var minifyProfiles = map[models.PlatformType]minifyProfile{
+	models.PlatformIFlytek: {
+		internStrings: true,
+		defaults: []defaultField{
+			{"dragging", false},
+			{"selected", false},
+			{"status", ""},
+			{"*ErrMsg", ""},
+		},
+	},
+	models.PlatformDify: {
+		internStrings: true,
+		defaults: []defaultField{
+			{"selected", false},
+			{"isInIteration", false},
+			{"isInLoop", false},
+			{"zIndex", 0},
+			{"desc", ""},
+		},
+	},
+	models.PlatformCoze: {
+		defaults: []defaultField{
+			{"*", nil},
+		},
+	},
+	models.PlatformUnified: {
+		internStrings: true,
+		defaults: []defaultField{
+			{"*", nil},
+		},
+	},
+}
+
+// minInternLength keeps short values such as types and names inline
+const minInternLength = 24
+
+// MinifyOutput shrinks a generated YAML or JSON document for a target platform. It drops
+// default-valued fields, writes repeated long strings once as YAML anchors where the platform
+// importer resolves them, and writes JSON without indentation.
+func MinifyOutput(data []byte, platform models.PlatformType, format string) ([]byte, *MinifyReport, error) {
+	profile, ok := minifyProfiles[platform]
+	if !ok {
+		return nil, nil, fmt.Errorf("minify is not supported for %s", platform)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, nil, fmt.Errorf("failed to read generated DSL: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return data, &MinifyReport{OriginalBytes: len(data), MinifiedBytes: len(data)}, nil
+	}
+	root := document.Content[0]
+
+	report := &MinifyReport{OriginalBytes: len(data)}
+	report.RemovedFields = removeDefaultFields(root, profile.defaults)
+
+	var buffer bytes.Buffer
+	if format == models.OutputFormatJSON {
+		// JSON has no aliases, compact output is the saving left
+		if err := writeJSON(&buffer, root); err != nil {
+			return nil, nil, fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		buffer.WriteByte('\n')
+	} else {
+		if profile.internStrings {
+			report.InternedStrings = internStrings(root)
+		}
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&document); err != nil {
+			return nil, nil, fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, nil, fmt.Errorf("failed to encode YAML: %w", err)
+		}
+	}
+
+	report.MinifiedBytes = buffer.Len()
+	return buffer.Bytes(), report, nil
+}
+
+// removeDefaultFields drops the matching keys of all mappings below node and returns their count.
+func removeDefaultFields(node *yaml.Node, defaults []defaultField) int {
+	removed := 0
+	if node.Kind == yaml.MappingNode {
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if isDefaultField(key.Value, value, defaults) {
+				removed++
+				continue
+			}
+			content = append(content, key, value)
+		}
+		node.Content = content
+	}
+	for _, child := range node.Content {
+		removed += removeDefaultFields(child, defaults)
+	}
+	return removed
+}
+
+func isDefaultField(key string, value *yaml.Node, defaults []defaultField) bool {
+	if value.Kind != yaml.ScalarNode {
+		return false
+	}
+	for _, field := range defaults {
+		if !matchesFieldKey(field.key, key) {
+			continue
+		}
+		var decoded interface{}
+		if err := value.Decode(&decoded); err == nil && decoded == field.value {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesFieldKey(pattern, key string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(key, suffix)
+	}
+	return pattern == key
+}
+
+// internStrings anchors the first occurrence of every repeated long string value and replaces
+// the others by aliases when that makes the document smaller. It returns the interned count.
+func internStrings(root *yaml.Node) int {
+	var order []string
+	occurrences := make(map[string][]*yaml.Node)
+	collectStringValues(root, func(node *yaml.Node) {
+		if _, seen := occurrences[node.Value]; !seen {
+			order = append(order, node.Value)
+		}
+		occurrences[node.Value] = append(occurrences[node.Value], node)
+	})
+
+	interned := 0
+	for _, value := range order {
+		nodes := occurrences[value]
+		anchor := "s" + strconv.FormatInt(int64(interned), 36)
+		// "&anchor " once and "*anchor" for every repeat against the repeated text
+		cost := len(anchor) + 2 + (len(nodes)-1)*(len(anchor)+1)
+		if len(nodes) < 2 || (len(nodes)-1)*len(value) <= cost {
+			continue
+		}
+
+		nodes[0].Anchor = anchor
+		for _, node := range nodes[1:] {
+			*node = yaml.Node{Kind: yaml.AliasNode, Value: anchor, Alias: nodes[0]}
+		}
+		interned++
+	}
+	return interned
+}
+
+// collectStringValues visits the long single-line string scalars used as values, in document order.
+func collectStringValues(node *yaml.Node, visit func(*yaml.Node)) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			collectStringValues(node.Content[i], visit)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			collectStringValues(item, visit)
+		}
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" && len(node.Value) >= minInternLength && !strings.Contains(node.Value, "\n") {
+			visit(node)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package common
+
+import "github.com/iflytek/agentbridge/internal/models"
+
+// ApplyModelMap renames the models of LLM and classifier nodes, including iteration
+// sub-workflow nodes. Names missing from the map are kept.
+func ApplyModelMap(unifiedDSL *models.UnifiedDSL, modelMap map[string]string) {
+	if unifiedDSL == nil || len(modelMap) == 0 {
+		return
+	}
+	mapNodeModels(unifiedDSL.Workflow.Nodes, modelMap)
+}
+
+// mapNodeModels rewrites model names in place, keeping value or pointer config storage unchanged
+func mapNodeModels(nodes []models.Node, modelMap map[string]string) {
+	for i := range nodes {
+		switch cfg := nodes[i].Config.(type) {
+		case models.LLMConfig:
+			cfg.Model.Name = mapModelName(cfg.Model.Name, modelMap)
+			nodes[i].Config = cfg
+		case *models.LLMConfig:
+			cfg.Model.Name = mapModelName(cfg.Model.Name, modelMap)
+		case models.ClassifierConfig:
+			cfg.Model.Name = mapModelName(cfg.Model.Name, modelMap)
+			nodes[i].Config = cfg
+		case *models.ClassifierConfig:
+			cfg.Model.Name = mapModelName(cfg.Model.Name, modelMap)
+		case models.IterationConfig:
+			mapNodeModels(cfg.SubWorkflow.Nodes, modelMap)
+		case *models.IterationConfig:
+			mapNodeModels(cfg.SubWorkflow.Nodes, modelMap)
+		}
+	}
+}
+
+func mapModelName(name string, modelMap map[string]string) string {
+	if mapped, ok := modelMap[name]; ok && mapped != "" {
+		return mapped
+	}
+	return name
+}
+
+// CollectUnsupportedNodes returns the titles of unsupported node placeholders, including
+// those inside iteration sub-workflows.
+func CollectUnsupportedNodes(unifiedDSL *models.UnifiedDSL) []string {
+	if unifiedDSL == nil {
+		return nil
+	}
+	return collectUnsupportedNodes(unifiedDSL.Workflow.Nodes, func(models.Node) bool { return true }, nil)
+}
+
+// CollectRejectedPlaceholders returns the titles of the unsupported node placeholders whose
+// placeholder strategy is fail, per node or for the whole conversion.
+func CollectRejectedPlaceholders(unifiedDSL *models.UnifiedDSL, options *models.ConversionOptions) []string {
+	if unifiedDSL == nil || options == nil {
+		return nil
+	}
+	rejected := func(node models.Node) bool {
+		return options.PlaceholderStrategyFor(node.ID) == models.PlaceholderStrategyFail
+	}
+	return collectUnsupportedNodes(unifiedDSL.Workflow.Nodes, rejected, nil)
+}
+
+func collectUnsupportedNodes(nodes []models.Node, include func(models.Node) bool, titles []string) []string {
+	for _, node := range nodes {
+		if IsPlaceholderNode(node) && include(node) {
+			titles = append(titles, node.Title)
+		}
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			titles = collectUnsupportedNodes(iterConfig.SubWorkflow.Nodes, include, titles)
+		}
+	}
+	return titles
+}
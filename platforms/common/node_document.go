@@ -0,0 +1,191 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Node conversion converts one node without the workflow around it. WrapNode puts the node in the
+// smallest document the parser of its platform reads, IsolateNode gives the parsed node the start
+// and end nodes generators expect, and ExtractNode takes the generated node back out.
+
+// ErrNodeNotFound is returned when a document holds no node with the ID asked for.
+var ErrNodeNotFound = errors.New("node not found")
+
+// wrappedWorkflowName names the documents WrapNode builds
+const wrappedWorkflowName = "node"
+
+// WrapNode returns a workflow document of the platform holding only the node, given as YAML or
+// JSON in the form the platform keeps nodes in its exports, and the ID of the node. Coze nodes
+// given as JSON are taken for canvas nodes and wrapped in a workflow JSON.
+func WrapNode(data []byte, platform models.PlatformType) ([]byte, string, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF")))
+	var node map[string]interface{}
+	if err := yaml.Unmarshal(trimmed, &node); err != nil {
+		return nil, "", fmt.Errorf("invalid node: %w", err)
+	}
+	if node == nil {
+		return nil, "", fmt.Errorf("invalid node: expected a mapping")
+	}
+	id := strings.TrimSpace(fmt.Sprint(node["id"]))
+	if node["id"] == nil || id == "" {
+		return nil, "", fmt.Errorf("invalid node: missing id")
+	}
+
+	nodes := []interface{}{node}
+	edges := []interface{}{}
+	var document map[string]interface{}
+	switch platform {
+	case models.PlatformDify:
+		document = map[string]interface{}{
+			"app":      map[string]interface{}{"name": wrappedWorkflowName, "mode": "workflow"},
+			"kind":     "app",
+			"workflow": map[string]interface{}{"graph": map[string]interface{}{"nodes": nodes, "edges": edges}},
+		}
+	case models.PlatformIFlytek:
+		document = map[string]interface{}{
+			"flowMeta": map[string]interface{}{"name": wrappedWorkflowName},
+			"flowData": map[string]interface{}{"nodes": nodes, "edges": edges},
+		}
+	case models.PlatformCoze:
+		if bytes.HasPrefix(trimmed, []byte("{")) {
+			encoded, err := json.Marshal(map[string]interface{}{"nodes": nodes, "edges": edges})
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to wrap node: %w", err)
+			}
+			return encoded, id, nil
+		}
+		document = map[string]interface{}{"name": wrappedWorkflowName, "nodes": nodes, "edges": edges}
+	case models.PlatformUnified:
+		document = map[string]interface{}{
+			"version":  "1.0",
+			"metadata": map[string]interface{}{"name": wrappedWorkflowName},
+			"workflow": map[string]interface{}{"nodes": nodes, "edges": edges},
+		}
+	default:
+		return nil, "", fmt.Errorf("unsupported platform: %s", platform)
+	}
+
+	encoded, err := yaml.Marshal(document)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to wrap node: %w", err)
+	}
+	return encoded, id, nil
+}
+
+// IsolateNode reduces a parsed workflow to one top-level node between a start and an end node.
+// Outputs of other nodes the node reads become start variables, one warning each, typed after the
+// inputs reading them; the end node returns the outputs of the node.
+func IsolateNode(unifiedDSL *models.UnifiedDSL, nodeID string) ([]string, error) {
+	var node *models.Node
+	for i := range unifiedDSL.Workflow.Nodes {
+		if unifiedDSL.Workflow.Nodes[i].ID == nodeID && IterationParent(&unifiedDSL.Workflow.Nodes[i]) == "" {
+			node = &unifiedDSL.Workflow.Nodes[i]
+			break
+		}
+	}
+	if node == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, nodeID)
+	}
+
+	// ExtractSubgraph only rewires references to nodes of the workflow; stand-ins for the nodes
+	// read make them removed nodes
+	types := make(map[string]models.UnifiedDataType)
+	for _, input := range node.Inputs {
+		if reference := input.Reference; reference != nil && reference.OutputName != "" {
+			types[reference.NodeID+"."+reference.OutputName] = input.Type
+			if reference.DataType != "" {
+				types[reference.NodeID+"."+reference.OutputName] = reference.DataType
+			}
+		}
+	}
+	present := make(map[string]bool)
+	for _, existing := range unifiedDSL.Workflow.Nodes {
+		present[existing.ID] = true
+	}
+	stubs := make(map[string]*models.Node)
+	var order []string
+	rewriteNodeReferences(node, func(readID, output string) (string, string, bool) {
+		if present[readID] || promptSpecialSelectors[readID] || output == "" {
+			return "", "", false
+		}
+		stub := stubs[readID]
+		if stub == nil {
+			stub = &models.Node{ID: readID, Type: models.NodeTypeCode, Title: readID}
+			stubs[readID] = stub
+			order = append(order, readID)
+		}
+		for _, existing := range stub.Outputs {
+			if existing.Name == output {
+				return "", "", false
+			}
+		}
+		stub.Outputs = append(stub.Outputs, models.Output{Name: output, Type: types[readID+"."+output]})
+		return "", "", false
+	})
+	for _, id := range order {
+		unifiedDSL.Workflow.Nodes = append(unifiedDSL.Workflow.Nodes, *stubs[id])
+	}
+	return ExtractSubgraph(unifiedDSL, []string{nodeID}, "")
+}
+
+// ExtractNode returns the node with the ID from a generated document, YAML or JSON, in the format
+// of the document: the first node of that ID in a nodes list, nearest to the root first. Coze
+// documents thus yield the node as kept in the top-level nodes, not the canvas copy.
+func ExtractNode(data []byte, nodeID string, format string) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to read generated document: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, nodeID)
+	}
+	if err := expandYAMLAliases(&document); err != nil {
+		return nil, err
+	}
+
+	queue := []*yaml.Node{document.Content[0]}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current.Kind != yaml.MappingNode {
+			queue = append(queue, current.Content...)
+			continue
+		}
+		for i := 0; i+1 < len(current.Content); i += 2 {
+			key, value := current.Content[i], current.Content[i+1]
+			if key.Value == "nodes" && value.Kind == yaml.SequenceNode {
+				if found := nodeWithID(value, nodeID); found != nil {
+					encoded, err := yaml.Marshal(found)
+					if err != nil {
+						return nil, fmt.Errorf("failed to encode node: %w", err)
+					}
+					return EncodeOutput(encoded, format)
+				}
+			}
+			queue = append(queue, value)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, nodeID)
+}
+
+// nodeWithID returns the mapping of a nodes list whose id is nodeID
+func nodeWithID(nodes *yaml.Node, nodeID string) *yaml.Node {
+	for _, item := range nodes.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "id" && item.Content[i+1].Kind == yaml.ScalarNode && item.Content[i+1].Value == nodeID {
+				return item
+			}
+		}
+	}
+	return nil
+}
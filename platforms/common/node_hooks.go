@@ -0,0 +1,45 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Node hook phases
+const (
+	HookPhaseBefore = "before"
+	HookPhaseAfter  = "after"
+)
+
+// ApplyNodeHooks runs the hooks of a phase on every node, including iteration sub-workflow
+// nodes. Hooks run in order; the first error stops the conversion.
+func ApplyNodeHooks(unifiedDSL *models.UnifiedDSL, hooks []models.NodeHook, phase string, ctx models.NodeHookContext) error {
+	if unifiedDSL == nil || len(hooks) == 0 {
+		return nil
+	}
+	return applyNodeHooks(unifiedDSL.Workflow.Nodes, hooks, phase, ctx)
+}
+
+func applyNodeHooks(nodes []models.Node, hooks []models.NodeHook, phase string, ctx models.NodeHookContext) error {
+	for i := range nodes {
+		for _, hook := range hooks {
+			var err error
+			if phase == HookPhaseBefore {
+				err = hook.BeforeNodeConvert(ctx, &nodes[i])
+			} else {
+				err = hook.AfterNodeConvert(ctx, &nodes[i])
+			}
+			if err != nil {
+				return fmt.Errorf("%s hook failed on node %s (%s): %w", phase, nodes[i].ID, nodes[i].Title, err)
+			}
+		}
+
+		if iterConfig, ok := AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+			if err := applyNodeHooks(iterConfig.SubWorkflow.Nodes, hooks, phase, ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
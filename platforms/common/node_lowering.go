@@ -0,0 +1,515 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// agentToolsTitleSuffix marks lowered agents whose tools have to be configured again by hand
+const agentToolsTitleSuffix = "（原智能体工具需手动配置）"
+
+// nodeLowerings convert node types a target generator cannot express natively into nodes it supports
+var nodeLowerings = map[models.NodeType]func(models.Node, models.PlatformType, *models.ConversionOptions) models.Node{
+	models.NodeTypeDataStore:         lowerDataStoreNode,
+	models.NodeTypeAgent:             lowerAgentNode,
+	models.NodeTypeTextToSpeech:      lowerAudioNode,
+	models.NodeTypeSpeechToText:      lowerAudioNode,
+	models.NodeTypeDocumentExtractor: lowerDocumentExtractorNode,
+	models.NodeTypeListOperation:     lowerListOperationNode,
+	models.NodeTypeJSONProcess:       lowerJSONProcessNode,
+}
+
+// LowerNodes replaces nodes that the target generator cannot express natively with the closest
+// supported equivalent, including iteration sub-workflow nodes. Options may be nil. Exports of
+// the unified DSL keep every node, lowering happens when they are generated for a platform.
+func LowerNodes(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType, options *models.ConversionOptions) {
+	if unifiedDSL == nil || targetPlatform == models.PlatformUnified {
+		return
+	}
+	if options == nil {
+		options = models.NewConversionOptions()
+	}
+	lowerNodeList(unifiedDSL.Workflow.Nodes, targetPlatform, options)
+}
+
+// lowerNodeList lowers nodes in place and descends into iteration sub-workflows.
+func lowerNodeList(nodes []models.Node, targetPlatform models.PlatformType, options *models.ConversionOptions) {
+	for i := range nodes {
+		if lower, exists := nodeLowerings[nodes[i].Type]; exists {
+			nodes[i] = lower(nodes[i], targetPlatform, options)
+			continue
+		}
+
+		if nodes[i].Type != models.NodeTypeIteration {
+			continue
+		}
+		// Sub-workflow slices share their backing array, so in-place lowering works for value configs too
+		if iterConfig, ok := AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+			lowerNodeList(iterConfig.SubWorkflow.Nodes, targetPlatform, options)
+		}
+	}
+}
+
+// lowerAgentNode degrades an agent to an LLM node with the same model and prompts on iFlytek and
+// Coze, whose generators emit no agent nodes; Dify generates them natively, and so does Coze for
+// agents parsed from Coze, which keep their skills. The tools are listed in the description so
+// they can be wired up again by hand; iFlytek LLM nodes carry a fixed description, so there the
+// title flags the missing tools instead. The node keeps its outputs so downstream references stay
+// valid.
+func lowerAgentNode(node models.Node, targetPlatform models.PlatformType, _ *models.ConversionOptions) models.Node {
+	config, ok := AsAgentConfig(node.Config)
+	if !ok || config == nil || targetPlatform == models.PlatformDify || (targetPlatform == models.PlatformCoze && hasCozeSkills(node)) {
+		return node
+	}
+
+	if len(config.Tools) > 0 {
+		tools := make([]string, 0, len(config.Tools))
+		for _, tool := range config.Tools {
+			name := tool.Name
+			if tool.Provider != "" {
+				name = tool.Provider + "/" + tool.Name
+			}
+			tools = append(tools, tool.Type+":"+name)
+		}
+		summary := "原智能体工具（需手动配置）: " + strings.Join(tools, ", ")
+		if node.Description != "" {
+			summary = node.Description + "\n" + summary
+		}
+		node.Description = summary
+		if targetPlatform == models.PlatformIFlytek {
+			node.Title += agentToolsTitleSuffix
+		}
+	}
+
+	if len(node.Outputs) == 0 {
+		node.Outputs = []models.Output{{Name: "output", Type: models.DataTypeString}}
+	}
+
+	node.Type = models.NodeTypeLLM
+	node.Config = models.LLMConfig{
+		Model:         config.Model,
+		Parameters:    config.Parameters,
+		Prompt:        config.Prompt,
+		IsInIteration: config.IsInIteration,
+		IterationID:   config.IterationID,
+	}
+	return node
+}
+
+// hasCozeSkills reports whether an agent was parsed from a Coze LLM node whose skills it keeps
+func hasCozeSkills(node models.Node) bool {
+	params, _ := node.PlatformConfig.Coze[SourceParamsKey].(map[string]interface{})
+	return params["fcParam"] != nil
+}
+
+// lowerDataStoreNode turns a database / memory node into a Python stub that documents the
+// operation and returns empty values for every output, so downstream references stay valid.
+// Coze generates database and variable nodes natively.
+func lowerDataStoreNode(node models.Node, targetPlatform models.PlatformType, _ *models.ConversionOptions) models.Node {
+	config, ok := AsDataStoreConfig(node.Config)
+	if !ok || config == nil || targetPlatform == models.PlatformCoze {
+		return node
+	}
+
+	if len(node.Outputs) == 0 {
+		node.Outputs = []models.Output{{Name: "result", Type: models.DataTypeString}}
+	}
+
+	header := fmt.Sprintf("数据存储节点（%s / %s）：目标平台无对应节点，请接入实际存储后替换以下实现", config.Store, config.Operation)
+	return codeStubNode(node, header, config, config.IsInIteration, config.IterationID)
+}
+
+// lowerAudioNode degrades speech synthesis / recognition nodes on Dify and Coze, which have no
+// such nodes. The audio strategy picks a stub returning empty values or a code node calling an
+// HTTP speech service; iFlytek generates them natively.
+func lowerAudioNode(node models.Node, targetPlatform models.PlatformType, options *models.ConversionOptions) models.Node {
+	if targetPlatform == models.PlatformIFlytek {
+		return node
+	}
+
+	var (
+		label, serviceURL, defaultOutput string
+		config                           interface{}
+		settings                         map[string]interface{}
+		isInIteration                    bool
+		iterationID                      string
+	)
+	if ttsConfig, ok := AsTextToSpeechConfig(node.Config); ok && ttsConfig != nil {
+		label, serviceURL, defaultOutput = "语音合成", "https://your-speech-service.example.com/text-to-speech", "voice_url"
+		config, isInIteration, iterationID = ttsConfig, ttsConfig.IsInIteration, ttsConfig.IterationID
+		settings = map[string]interface{}{
+			"voice":  ttsConfig.Voice,
+			"speed":  ttsConfig.Speed,
+			"volume": ttsConfig.Volume,
+			"pitch":  ttsConfig.Pitch,
+			"format": ttsConfig.Format,
+		}
+	} else if asrConfig, ok := AsSpeechToTextConfig(node.Config); ok && asrConfig != nil {
+		label, serviceURL, defaultOutput = "语音识别", "https://your-speech-service.example.com/speech-to-text", "text"
+		config, isInIteration, iterationID = asrConfig, asrConfig.IsInIteration, asrConfig.IterationID
+		settings = map[string]interface{}{
+			"language": asrConfig.Language,
+			"accent":   asrConfig.Accent,
+			"format":   asrConfig.Format,
+		}
+	} else {
+		return node
+	}
+
+	if len(node.Outputs) == 0 {
+		node.Outputs = []models.Output{{Name: defaultOutput, Type: models.DataTypeString}}
+	}
+
+	if options.AudioStrategyFor(node.ID) == models.AudioStrategyHTTP {
+		header := fmt.Sprintf("%s节点：目标平台无对应节点，已改为调用 HTTP 语音服务，请将 SERVICE_URL 替换为实际服务地址", label)
+		return httpToolCodeNode(node, header, serviceURL, settings, isInIteration, iterationID)
+	}
+
+	header := fmt.Sprintf("%s节点：目标平台无对应节点，请接入语音服务后替换以下实现", label)
+	return codeStubNode(node, header, config, isInIteration, iterationID)
+}
+
+// lowerDocumentExtractorNode turns a document extractor into a Python stub on iFlytek and Coze,
+// which have no document parsing node; the file input stays wired to the stub. Dify generates it natively.
+func lowerDocumentExtractorNode(node models.Node, targetPlatform models.PlatformType, _ *models.ConversionOptions) models.Node {
+	config, ok := AsDocumentExtractorConfig(node.Config)
+	if !ok || config == nil || targetPlatform == models.PlatformDify {
+		return node
+	}
+
+	if len(node.Outputs) == 0 {
+		textType := models.DataTypeString
+		if config.IsArrayFile {
+			textType = models.DataTypeArrayString
+		}
+		node.Outputs = []models.Output{{Name: "text", Type: textType}}
+	}
+
+	header := "文档提取节点：目标平台无对应节点，请接入文档解析服务后替换以下实现"
+	return codeStubNode(node, header, config, config.IsInIteration, config.IterationID)
+}
+
+// lowerJSONProcessNode turns a JSON serialization / deserialization node into the equivalent Python
+// code node on iFlytek and Dify, which have no JSON nodes; Coze generates them natively.
+// Deserialized objects also fill outputs named after their top-level fields.
+func lowerJSONProcessNode(node models.Node, targetPlatform models.PlatformType, _ *models.ConversionOptions) models.Node {
+	config, ok := AsJSONProcessConfig(node.Config)
+	if !ok || config == nil || targetPlatform == models.PlatformCoze {
+		return node
+	}
+
+	if len(node.Inputs) == 0 {
+		return codeStubNode(node, "JSON 处理节点：未找到输入变量，请手动配置", config, config.IsInIteration, config.IterationID)
+	}
+	if len(node.Outputs) == 0 {
+		node.Outputs = []models.Output{{Name: "output", Type: models.DataTypeString}}
+	}
+	input := node.Inputs[0].Name
+
+	var code strings.Builder
+	if config.Operation == models.JSONOperationDeserialize {
+		code.WriteString("# JSON 反序列化节点：解析 JSON 文本，解析失败时输出空值\n")
+	} else {
+		code.WriteString("# JSON 序列化节点：将输入转换为 JSON 文本\n")
+	}
+	code.WriteString("import json\n\n\n")
+	code.WriteString(pythonMainSignature(node))
+
+	if config.Operation != models.JSONOperationDeserialize {
+		code.WriteString("    return {\n")
+		code.WriteString(fmt.Sprintf("        %q: json.dumps(%s, ensure_ascii=False),\n", node.Outputs[0].Name, input))
+		for _, output := range node.Outputs[1:] {
+			code.WriteString(fmt.Sprintf("        %q: %s,\n", output.Name, pythonZeroValue(output.Type)))
+		}
+		code.WriteString("    }")
+		return asPythonCodeNode(node, code.String(), config.IsInIteration, config.IterationID)
+	}
+
+	code.WriteString("    try:\n")
+	code.WriteString(fmt.Sprintf("        value = json.loads(%s) if %s else None\n", input, input))
+	code.WriteString("    except ValueError:\n")
+	code.WriteString("        value = None\n")
+	if len(node.Outputs) > 1 {
+		code.WriteString("    fields = value if isinstance(value, dict) else {}\n")
+	}
+	code.WriteString("    return {\n")
+	for i, output := range node.Outputs {
+		zero := pythonZeroValue(output.Type)
+		if i == 0 {
+			code.WriteString(fmt.Sprintf("        %q: value if value is not None else %s,\n", output.Name, zero))
+			continue
+		}
+		code.WriteString(fmt.Sprintf("        %q: fields.get(%q, %s),\n", output.Name, output.Name, zero))
+	}
+	code.WriteString("    }")
+
+	return asPythonCodeNode(node, code.String(), config.IsInIteration, config.IterationID)
+}
+
+// listOperationHelpers are the Python helpers of lowered list operations: field access and filter matching
+const listOperationHelpers = `def _field(item, key):
+    if key and isinstance(item, dict):
+        return item.get(key)
+    return item
+
+
+def _match(value, operator, expected):
+    if operator in ("is_empty", "is_not_empty"):
+        empty = value is None or value == "" or value == [] or value == {}
+        return empty if operator == "is_empty" else not empty
+    checks = {
+        "contains": lambda: expected in value,
+        "not_contains": lambda: expected not in value,
+        "starts_with": lambda: str(value).startswith(str(expected)),
+        "ends_with": lambda: str(value).endswith(str(expected)),
+        "equals": lambda: value == expected or str(value) == str(expected),
+        "not_equals": lambda: value != expected and str(value) != str(expected),
+        "in": lambda: value in expected,
+        "not_in": lambda: value not in expected,
+        "gt": lambda: float(value) > float(expected),
+        "gte": lambda: float(value) >= float(expected),
+        "lt": lambda: float(value) < float(expected),
+        "lte": lambda: float(value) <= float(expected),
+    }
+    check = checks.get(operator)
+    if check is None:
+        return True
+    try:
+        return check()
+    except (TypeError, ValueError):
+        return False
+
+
+`
+
+// lowerListOperationNode turns a list operation into an equivalent Python code node on iFlytek and
+// Coze, which have no list node, and on Dify when a field is mapped, which its list operator cannot do.
+// The node keeps its inputs and outputs, so references on both sides stay valid.
+func lowerListOperationNode(node models.Node, targetPlatform models.PlatformType, _ *models.ConversionOptions) models.Node {
+	config, ok := AsListOperationConfig(node.Config)
+	if !ok || config == nil || (targetPlatform == models.PlatformDify && config.MapField == "") {
+		return node
+	}
+
+	if len(node.Inputs) == 0 {
+		return codeStubNode(node, "列表操作节点：未找到输入列表，请手动配置", config, config.IsInIteration, config.IterationID)
+	}
+	if len(node.Outputs) == 0 {
+		node.Outputs = []models.Output{{Name: "result", Type: node.Inputs[0].Type}}
+	}
+
+	operation := map[string]interface{}{
+		"filters":       config.Filters,
+		"extract_index": config.ExtractIndex,
+		"sort_by":       config.SortBy,
+		"limit":         config.Limit,
+		"map_field":     config.MapField,
+	}
+	if config.Filters == nil {
+		operation["filters"] = []models.ListFilterCondition{}
+	}
+	operationJSON, _ := json.Marshal(operation)
+
+	var code strings.Builder
+	code.WriteString("# 列表操作节点：按原节点配置依次执行过滤、提取、排序、截取与字段映射\n")
+	code.WriteString("import json\n\n")
+	code.WriteString(fmt.Sprintf("OPERATION = json.loads(%q)\n\n\n", string(operationJSON)))
+	code.WriteString(listOperationHelpers)
+
+	code.WriteString(pythonMainSignature(node))
+	code.WriteString(fmt.Sprintf("    result = list(%s or [])\n", node.Inputs[0].Name))
+	code.WriteString("    for condition in OPERATION[\"filters\"]:\n")
+	code.WriteString("        result = [item for item in result\n")
+	code.WriteString("                  if _match(_field(item, condition.get(\"key\")), condition[\"operator\"], condition.get(\"value\"))]\n")
+	code.WriteString("    if OPERATION[\"extract_index\"] > 0:\n")
+	code.WriteString("        result = result[OPERATION[\"extract_index\"] - 1:OPERATION[\"extract_index\"]]\n")
+	code.WriteString("    if OPERATION[\"sort_by\"]:\n")
+	code.WriteString("        sort_by = OPERATION[\"sort_by\"]\n")
+	code.WriteString("        result = sorted(result, key=lambda item: _field(item, sort_by.get(\"key\")), reverse=not sort_by[\"ascending\"])\n")
+	code.WriteString("    if OPERATION[\"limit\"] > 0:\n")
+	code.WriteString("        result = result[:OPERATION[\"limit\"]]\n")
+	code.WriteString("    if OPERATION[\"map_field\"]:\n")
+	code.WriteString("        result = [_field(item, OPERATION[\"map_field\"]) for item in result]\n")
+	code.WriteString("    return {\n")
+	for _, output := range node.Outputs {
+		value := pythonZeroValue(output.Type)
+		switch output.Name {
+		case "result":
+			value = "result"
+		case "first_record":
+			value = fmt.Sprintf("result[0] if result else %s", value)
+		case "last_record":
+			value = fmt.Sprintf("result[-1] if result else %s", value)
+		}
+		code.WriteString(fmt.Sprintf("        %q: %s,\n", output.Name, value))
+	}
+	code.WriteString("    }")
+
+	return asPythonCodeNode(node, code.String(), config.IsInIteration, config.IterationID)
+}
+
+// codeStubNode replaces node with a placeholder Python code node whose comments carry the
+// original configuration and which returns empty values for every output.
+func codeStubNode(node models.Node, header string, config interface{}, isInIteration bool, iterationID string) models.Node {
+	var code strings.Builder
+	code.WriteString("# " + header + "\n")
+	writeConfigComment(&code, config)
+
+	code.WriteString(pythonMainSignature(node))
+	code.WriteString("    return {\n")
+	for _, output := range node.Outputs {
+		code.WriteString(fmt.Sprintf("        %q: %s,\n", output.Name, pythonZeroValue(output.Type)))
+	}
+	code.WriteString("    }")
+
+	marker := models.PlaceholderMarker{OriginalType: string(node.Type), OriginalID: node.ID, Action: header}
+	node = asPythonCodeNode(node, code.String(), isInIteration, iterationID)
+	node.Title = FormatUnsupportedNodeTitle(node.Title)
+	MarkPlaceholder(&node, marker)
+	return node
+}
+
+// httpToolCodeNode replaces node with a Python code node that posts its inputs and settings to
+// an HTTP service and returns the fields of the JSON response named like the node outputs.
+func httpToolCodeNode(node models.Node, header, serviceURL string, settings map[string]interface{}, isInIteration bool, iterationID string) models.Node {
+	var code strings.Builder
+	code.WriteString("# " + header + "\n")
+	code.WriteString("import json\nimport urllib.request\n\n")
+	code.WriteString(fmt.Sprintf("SERVICE_URL = %q\n", serviceURL))
+	settingsJSON, _ := json.Marshal(settings)
+	code.WriteString(fmt.Sprintf("SETTINGS = json.loads(%q)\n\n", string(settingsJSON)))
+
+	code.WriteString(pythonMainSignature(node))
+	code.WriteString("    payload = dict(SETTINGS)\n")
+	for _, input := range node.Inputs {
+		code.WriteString(fmt.Sprintf("    payload[%q] = %s\n", input.Name, input.Name))
+	}
+	code.WriteString("    request = urllib.request.Request(SERVICE_URL, data=json.dumps(payload).encode(\"utf-8\"),\n")
+	code.WriteString("                                     headers={\"Content-Type\": \"application/json\"}, method=\"POST\")\n")
+	code.WriteString("    with urllib.request.urlopen(request, timeout=60) as response:\n")
+	code.WriteString("        result = json.loads(response.read().decode(\"utf-8\"))\n")
+	code.WriteString("    return {\n")
+	for _, output := range node.Outputs {
+		code.WriteString(fmt.Sprintf("        %q: result.get(%q, %s),\n", output.Name, output.Name, pythonZeroValue(output.Type)))
+	}
+	code.WriteString("    }")
+
+	return asPythonCodeNode(node, code.String(), isInIteration, iterationID)
+}
+
+// writeConfigComment writes the original node configuration as indented JSON comments
+func writeConfigComment(code *strings.Builder, config interface{}) {
+	var configJSON bytes.Buffer
+	encoder := json.NewEncoder(&configJSON)
+	encoder.SetEscapeHTML(false) // Keep SQL comparison operators readable
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(config); err != nil {
+		return
+	}
+	code.WriteString("# 原始配置:\n")
+	for _, line := range strings.Split(strings.TrimSpace(configJSON.String()), "\n") {
+		code.WriteString("# " + line + "\n")
+	}
+}
+
+// pythonMainSignature returns the main function definition taking the node inputs
+func pythonMainSignature(node models.Node) string {
+	params := make([]string, 0, len(node.Inputs))
+	for _, input := range node.Inputs {
+		params = append(params, fmt.Sprintf("%s: %s", input.Name, pythonTypeName(input.Type)))
+	}
+	return fmt.Sprintf("def main(%s) -> dict:\n", strings.Join(params, ", "))
+}
+
+// asPythonCodeNode turns node into a Python code node running code
+func asPythonCodeNode(node models.Node, code string, isInIteration bool, iterationID string) models.Node {
+	node.Type = models.NodeTypeCode
+	node.Config = models.CodeConfig{
+		Language:      "python3",
+		Code:          code,
+		Dependencies:  []string{},
+		IsInIteration: isInIteration,
+		IterationID:   iterationID,
+	}
+	return node
+}
+
+// pythonTypeName returns the Python annotation of a unified data type
+func pythonTypeName(dataType models.UnifiedDataType) string {
+	switch dataType {
+	case models.DataTypeInteger:
+		return "int"
+	case models.DataTypeFloat, models.DataTypeNumber:
+		return "float"
+	case models.DataTypeBoolean:
+		return "bool"
+	case models.DataTypeObject:
+		return "dict"
+	}
+	if strings.HasPrefix(string(dataType), "array") {
+		return "list"
+	}
+	return "str"
+}
+
+// pythonZeroValue returns the Python empty value of a unified data type
+func pythonZeroValue(dataType models.UnifiedDataType) string {
+	switch pythonTypeName(dataType) {
+	case "int":
+		return "0"
+	case "float":
+		return "0.0"
+	case "bool":
+		return "False"
+	case "dict":
+		return "{}"
+	case "list":
+		return "[]"
+	}
+	return `""`
+}
+
+// EndTemplateRenderNode builds a Python code node rendering the answer template of a template mode
+// end node into its "output" output, for targets whose end nodes can only return variables. The
+// node takes the end node inputs, plus one per node output the template references. It reports
+// false when there is nothing to render: variables mode, or a template of a single placeholder.
+func EndTemplateRenderNode(end models.Node) (models.Node, bool) {
+	config, ok := AsEndConfig(end.Config)
+	if !ok || config == nil || config.OutputMode != models.EndOutputModeTemplate {
+		return models.Node{}, false
+	}
+	placeholders := ParsePromptPlaceholders(config.Template)
+	if len(placeholders) == 0 || (len(placeholders) == 1 && strings.TrimSpace(config.Template) == placeholders[0].Text) {
+		return models.Node{}, false
+	}
+
+	template, inputs := NamePromptReferences(config.Template, end.Inputs)
+	node := models.Node{
+		ID:       end.ID + "_answer",
+		Title:    DerivedNodeTitle(end, end.ID+"_answer", models.NodeTypeCode, "回复模板"),
+		Position: end.Position,
+		Inputs:   inputs,
+		Outputs:  []models.Output{{Name: "output", Type: models.DataTypeString}},
+	}
+
+	values := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		values = append(values, fmt.Sprintf("%q: %s", input.Name, input.Name))
+	}
+
+	var code strings.Builder
+	code.WriteString("# 结束节点回复模板：将输入变量填入模板，生成回复内容\n")
+	code.WriteString(fmt.Sprintf("TEMPLATE = %q\n\n\n", template))
+	code.WriteString(pythonMainSignature(node))
+	code.WriteString(fmt.Sprintf("    values = {%s}\n", strings.Join(values, ", ")))
+	code.WriteString("    text = TEMPLATE\n")
+	code.WriteString("    for name, value in values.items():\n")
+	code.WriteString("        text = text.replace(\"{{\" + name + \"}}\", \"\" if value is None else str(value))\n")
+	code.WriteString("    return {\"output\": text}")
+
+	return asPythonCodeNode(node, code.String(), false, ""), true
+}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Placeholder title markers used for nodes the converter cannot translate
+const (
+	UnsupportedNodeTitlePrefix = "暂不兼容的节点-"
+	UnsupportedNodeTitleSuffix = "（请根据需求手动实现）"
+)
+
+// Placeholders supported by title templates
+const (
+	TitlePlaceholderTitle = "{{title}}"
+	TitlePlaceholderID    = "{{id}}"
+	TitlePlaceholderType  = "{{type}}"
+)
+
+// FormatUnsupportedNodeTitle builds the title of a placeholder node replacing an unsupported node.
+func FormatUnsupportedNodeTitle(title string) string {
+	return UnsupportedNodeTitlePrefix + title + UnsupportedNodeTitleSuffix
+}
+
+// IsUnsupportedNodeTitle reports whether a title marks an unsupported node placeholder.
+func IsUnsupportedNodeTitle(title string) bool {
+	return strings.Contains(title, UnsupportedNodeTitlePrefix)
+}
+
+// OriginalUnsupportedNodeTitle extracts the source title from a placeholder title.
+func OriginalUnsupportedNodeTitle(title string) (string, bool) {
+	if !strings.HasPrefix(title, UnsupportedNodeTitlePrefix) {
+		return title, false
+	}
+	original := strings.TrimPrefix(title, UnsupportedNodeTitlePrefix)
+	return strings.TrimSuffix(original, UnsupportedNodeTitleSuffix), true
+}
+
+// ValidateTitleOptions checks that node naming options can be applied together.
+func ValidateTitleOptions(options *models.ConversionOptions) error {
+	if options == nil || !options.KeepTitles {
+		return nil
+	}
+	if options.TitlePrefix != "" || options.TitleSuffix != "" || options.TitleTemplate != "" {
+		return fmt.Errorf("keep-titles cannot be combined with title prefix, suffix or template")
+	}
+	return nil
+}
+
+// ApplyTitleOptions renames workflow nodes, including iteration sub-workflow nodes,
+// according to the node naming options.
+func ApplyTitleOptions(unifiedDSL *models.UnifiedDSL, options *models.ConversionOptions) error {
+	if unifiedDSL == nil || options == nil || !options.HasTitleOptions() {
+		return nil
+	}
+	if err := ValidateTitleOptions(options); err != nil {
+		return err
+	}
+
+	renameNodeTitles(unifiedDSL.Workflow.Nodes, options)
+	return nil
+}
+
+// renameNodeTitles renames nodes in place and descends into iteration sub-workflows.
+func renameNodeTitles(nodes []models.Node, options *models.ConversionOptions) {
+	for i := range nodes {
+		if !options.KeepTitles {
+			nodes[i].PlatformConfig.Naming = &models.NodeNaming{
+				OriginalTitle: nodes[i].Title,
+				Prefix:        options.TitlePrefix,
+				Suffix:        options.TitleSuffix,
+				Template:      options.TitleTemplate,
+			}
+		}
+		nodes[i].Title = RenderNodeTitle(nodes[i], options)
+
+		if nodes[i].Type != models.NodeTypeIteration {
+			continue
+		}
+		// Sub-workflow slices share their backing array, so in-place renaming works for value configs too
+		if iterConfig, ok := AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+			renameNodeTitles(iterConfig.SubWorkflow.Nodes, options)
+		}
+	}
+}
+
+// RenderNodeTitle computes the target title of a node under the node naming options.
+func RenderNodeTitle(node models.Node, options *models.ConversionOptions) string {
+	if options == nil {
+		return node.Title
+	}
+
+	if options.KeepTitles {
+		original, _ := OriginalUnsupportedNodeTitle(node.Title)
+		return original
+	}
+
+	title := node.Title
+	if options.TitleTemplate != "" {
+		title = strings.NewReplacer(
+			TitlePlaceholderTitle, node.Title,
+			TitlePlaceholderID, node.ID,
+			TitlePlaceholderType, string(node.Type),
+		).Replace(options.TitleTemplate)
+	}
+
+	return options.TitlePrefix + title + options.TitleSuffix
+}
+
+// DerivedNodeTitle names a node a generator adds for source, such as the node rendering the answer
+// template of an end node: the title source had before renaming, followed by suffix, renamed the
+// way source was. Without naming options it is the title of source followed by suffix.
+func DerivedNodeTitle(source models.Node, derivedID string, derivedType models.NodeType, suffix string) string {
+	naming := source.PlatformConfig.Naming
+	if naming == nil {
+		return source.Title + suffix
+	}
+	derived := models.Node{ID: derivedID, Type: derivedType, Title: naming.OriginalTitle + suffix}
+	return RenderNodeTitle(derived, &models.ConversionOptions{
+		TitlePrefix:   naming.Prefix,
+		TitleSuffix:   naming.Suffix,
+		TitleTemplate: naming.Template,
+	})
+}
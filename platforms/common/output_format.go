@@ -0,0 +1,98 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// EncodeOutput re-encodes the YAML a generator produced in the requested output format. JSON
+// keeps the key order and key names of the YAML, so both formats describe the same document.
+func EncodeOutput(yamlData []byte, format string) ([]byte, error) {
+	if format != models.OutputFormatJSON {
+		return yamlData, nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(yamlData, &document); err != nil {
+		return nil, fmt.Errorf("failed to read generated YAML: %w", err)
+	}
+	if err := expandYAMLAliases(&document); err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if len(document.Content) == 0 {
+		buffer.WriteString("null")
+	} else if err := writeJSON(&buffer, document.Content[0]); err != nil {
+		return nil, fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buffer.Bytes(), "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes(), nil
+}
+
+// writeJSON writes a YAML node as compact JSON.
+func writeJSON(buffer *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		buffer.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeJSONValue(buffer, node.Content[i].Value); err != nil {
+				return err
+			}
+			buffer.WriteByte(':')
+			if err := writeJSON(buffer, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+	case yaml.SequenceNode:
+		buffer.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeJSON(buffer, item); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	case yaml.ScalarNode:
+		var value interface{}
+		if node.ShortTag() == "!!timestamp" {
+			// Keep the written form instead of Go's time layout
+			value = node.Value
+		} else if err := node.Decode(&value); err != nil {
+			return err
+		}
+		if err := writeJSONValue(buffer, value); err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+	default:
+		return fmt.Errorf("line %d: unexpected YAML node", node.Line)
+	}
+	return nil
+}
+
+// writeJSONValue writes a scalar value, leaving <, > and & in code and prompts unescaped.
+func writeJSONValue(buffer *bytes.Buffer, value interface{}) error {
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	// Encode terminates every value with a newline
+	buffer.Truncate(buffer.Len() - 1)
+	return nil
+}
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// OutputNameFields are the values an output name template sees, e.g.
+// {{.Name}}-{{.TargetPlatform}}{{.Ext}}. Text fields are slugified before they are inserted.
+type OutputNameFields struct {
+	Name           string // Workflow name
+	Stem           string // Input file name without its extension
+	Author         string
+	Version        string
+	SourcePlatform models.PlatformType
+	TargetPlatform models.PlatformType
+	Ext            string // .yml or .json, after the output format
+	Index          int    // 1-based position of the input in the batch
+}
+
+// OutputNameTemplate names converted files after the workflows they hold.
+type OutputNameTemplate struct {
+	template *template.Template
+}
+
+// ParseOutputNameTemplate parses a text/template over OutputNameFields. Unknown fields are
+// reported here rather than on the first file.
+func ParseOutputNameTemplate(text string) (*OutputNameTemplate, error) {
+	parsed, err := template.New("output-name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output name template: %w", err)
+	}
+	if err := parsed.Execute(io.Discard, OutputNameFields{}); err != nil {
+		return nil, fmt.Errorf("invalid output name template: %w", err)
+	}
+	return &OutputNameTemplate{template: parsed}, nil
+}
+
+// Render returns the file name for fields. Names that would leave the output directory or come
+// out empty are rejected.
+func (t *OutputNameTemplate) Render(fields OutputNameFields) (string, error) {
+	fields.Name = Slugify(fields.Name)
+	fields.Stem = Slugify(fields.Stem)
+	fields.Author = Slugify(fields.Author)
+	fields.Version = Slugify(fields.Version)
+
+	var name strings.Builder
+	if err := t.template.Execute(&name, fields); err != nil {
+		return "", fmt.Errorf("invalid output name template: %w", err)
+	}
+	result := strings.TrimSpace(name.String())
+	if result == "" || result == "." || result == ".." || strings.ContainsAny(result, `/\`) {
+		return "", fmt.Errorf("output name template gives %q, which is not a file name", result)
+	}
+	return result, nil
+}
+
+// Slugify reduces text to letters, digits, '_' and '.', joined by single dashes, in lower case.
+// Letters of any script are kept, so Chinese workflow names stay readable.
+func Slugify(text string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return strings.Trim(slug.String(), ".-")
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// ParseIssues returns the problems of a parsed DSL that parsers convert best-effort: nodes
+// missing required fields, malformed or dangling variable references and edges between unknown
+// nodes. Iteration sub-workflows are included.
+func ParseIssues(unifiedDSL *models.UnifiedDSL) []string {
+	if unifiedDSL == nil {
+		return nil
+	}
+
+	nodeIDs := make(map[string]bool)
+	collectNodeIDs(unifiedDSL.Workflow.Nodes, nodeIDs)
+
+	validator := NewUnifiedDSLValidator()
+	var issues []string
+	var check func(nodes []models.Node, edges []models.Edge)
+	check = func(nodes []models.Node, edges []models.Edge) {
+		for i := range nodes {
+			node := &nodes[i]
+			// Parsers keep iteration bodies at different levels, so only their body nodes are checked
+			if node.Type != models.NodeTypeIteration {
+				if err := validator.validateNodeConfig(node); err != nil {
+					issues = append(issues, fmt.Sprintf("node %s (%s): %v", node.Title, node.ID, err))
+				}
+			}
+			for _, input := range node.Inputs {
+				if message := referenceIssue(input.Reference, nodeIDs); message != "" {
+					issues = append(issues, fmt.Sprintf("node %s (%s): input %s %s", node.Title, node.ID, input.Name, message))
+				}
+			}
+			if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+				check(iterConfig.SubWorkflow.Nodes, iterConfig.SubWorkflow.Edges)
+			}
+		}
+		for _, edge := range edges {
+			if !nodeIDs[edge.Source] || !nodeIDs[edge.Target] {
+				issues = append(issues, fmt.Sprintf("edge %s -> %s connects an unknown node", edge.Source, edge.Target))
+			}
+		}
+	}
+	check(unifiedDSL.Workflow.Nodes, unifiedDSL.Workflow.Edges)
+	return issues
+}
+
+// referenceIssue describes what is wrong with a node output reference, or returns "".
+func referenceIssue(reference *models.VariableReference, nodeIDs map[string]bool) string {
+	if reference == nil || reference.Type != models.ReferenceTypeNodeOutput {
+		return ""
+	}
+	switch {
+	case reference.NodeID == "" || reference.OutputName == "":
+		return fmt.Sprintf("has a malformed reference %q.%q", reference.NodeID, reference.OutputName)
+	case !nodeIDs[reference.NodeID]:
+		return fmt.Sprintf("references unknown node %s", reference.NodeID)
+	}
+	return ""
+}
+
+func collectNodeIDs(nodes []models.Node, nodeIDs map[string]bool) {
+	for _, node := range nodes {
+		nodeIDs[node.ID] = true
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			collectNodeIDs(iterConfig.SubWorkflow.Nodes, nodeIDs)
+		}
+	}
+}
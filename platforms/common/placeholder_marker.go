@@ -0,0 +1,121 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// PlaceholderMarkerPrefix starts the comment line that repeats the placeholder marker in the code.
+// Platform files drop the unified platform config, while the line travels with the code through
+// every conversion and, unlike the title, is not renamed by title options or users.
+const PlaceholderMarkerPrefix = "# agentbridge:placeholder "
+
+// UnsupportedNodeAction is the manual step of placeholders for node types the parsers do not support
+const UnsupportedNodeAction = "请根据业务需求手动补充实现逻辑"
+
+// PlaceholderMarkerLine returns marker as the Python comment line that starts placeholder code,
+// without line break.
+func PlaceholderMarkerLine(marker models.PlaceholderMarker) string {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(marker); err != nil {
+		return strings.TrimSpace(PlaceholderMarkerPrefix)
+	}
+	return PlaceholderMarkerPrefix + strings.TrimSpace(encoded.String())
+}
+
+// ParsePlaceholderMarker returns the marker line of placeholder code, false when code has none.
+func ParsePlaceholderMarker(code string) (models.PlaceholderMarker, bool) {
+	for _, line := range strings.Split(code, "\n") {
+		data, found := strings.CutPrefix(strings.TrimSpace(line), PlaceholderMarkerPrefix)
+		if !found {
+			continue
+		}
+		var marker models.PlaceholderMarker
+		if json.Unmarshal([]byte(data), &marker) != nil {
+			return models.PlaceholderMarker{}, false
+		}
+		return marker, true
+	}
+	return models.PlaceholderMarker{}, false
+}
+
+// MarkPlaceholder records marker on a placeholder node, in its platform config and as the first
+// line of its code.
+func MarkPlaceholder(node *models.Node, marker models.PlaceholderMarker) {
+	node.PlatformConfig.Placeholder = &marker
+	if config, ok := AsCodeConfig(node.Config); ok && config != nil {
+		if _, marked := ParsePlaceholderMarker(config.Code); !marked {
+			config.Code = PlaceholderMarkerLine(marker) + "\n" + config.Code
+		}
+		if _, isValue := node.Config.(models.CodeConfig); isValue {
+			node.Config = *config
+		}
+	}
+}
+
+// PlaceholderMarkerOf returns the marker of a placeholder node: the platform config of nodes
+// parsed or lowered by the converter, or the marker line of placeholder code read back from a
+// platform file.
+func PlaceholderMarkerOf(node models.Node) (models.PlaceholderMarker, bool) {
+	if node.PlatformConfig.Placeholder != nil {
+		return *node.PlatformConfig.Placeholder, true
+	}
+	if config, ok := AsCodeConfig(node.Config); ok && config != nil {
+		return ParsePlaceholderMarker(config.Code)
+	}
+	return models.PlaceholderMarker{}, false
+}
+
+// IsPlaceholderNode reports whether node is a code placeholder, by its marker or, for files of
+// versions without markers, by its title.
+func IsPlaceholderNode(node models.Node) bool {
+	if _, marked := PlaceholderMarkerOf(node); marked {
+		return true
+	}
+	return IsUnsupportedNodeTitle(node.Title)
+}
+
+// PlaceholderNode is a code placeholder found in a workflow.
+type PlaceholderNode struct {
+	ID          string
+	Title       string
+	IterationID string // Enclosing iteration node, empty for top-level nodes
+	Marker      models.PlaceholderMarker
+	TitleOnly   bool // Recognized by its title only, as written by versions without markers
+}
+
+// FindPlaceholders returns the code placeholders of a workflow, including iteration sub-workflow
+// nodes, in node order. Placeholders carry a marker line in their code; placeholders of older
+// versions are recognized by their title.
+func FindPlaceholders(unifiedDSL *models.UnifiedDSL) []PlaceholderNode {
+	if unifiedDSL == nil {
+		return nil
+	}
+	return findPlaceholders(unifiedDSL.Workflow.Nodes, "", nil)
+}
+
+func findPlaceholders(nodes []models.Node, iterationID string, found []PlaceholderNode) []PlaceholderNode {
+	for _, node := range nodes {
+		placeholder := PlaceholderNode{ID: node.ID, Title: node.Title, IterationID: iterationID}
+		if marker, marked := PlaceholderMarkerOf(node); marked {
+			placeholder.Marker = marker
+			found = append(found, placeholder)
+			continue
+		}
+		if IsUnsupportedNodeTitle(node.Title) {
+			placeholder.TitleOnly = true
+			found = append(found, placeholder)
+			continue
+		}
+
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			found = findPlaceholders(iterConfig.SubWorkflow.Nodes, node.ID, found)
+		}
+	}
+	return found
+}
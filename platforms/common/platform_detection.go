@@ -0,0 +1,238 @@
+package common
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Detection thresholds
+const (
+	// minDetectionConfidence is the confidence below which a source is not recognized
+	minDetectionConfidence = 0.5
+	// minDetectionMargin is the lead the best platform needs over the runner-up
+	minDetectionMargin = 0.25
+)
+
+// PlatformDetection is the platform a source DSL was recognized as.
+type PlatformDetection struct {
+	Platform   models.PlatformType
+	Confidence float64  // Weighted share of the platform's signatures found, from 0 to 1
+	Signals    []string // Signatures that matched
+}
+
+// platformSignature is a top-level structure that identifies a platform.
+type platformSignature struct {
+	name    string
+	weight  float64
+	matches func(root *yaml.Node) bool
+}
+
+// platformSignatures lists the signatures of each platform's export format
+var platformSignatures = map[models.PlatformType][]platformSignature{
+	models.PlatformDify: {
+		{"kind: app", 3, func(root *yaml.Node) bool { return yamlScalarValue(yamlMappingValue(root, "kind")) == "app" }},
+		{"app.mode", 3, func(root *yaml.Node) bool { return yamlMappingValue(yamlMappingValue(root, "app"), "mode") != nil }},
+		{"workflow.graph", 3, func(root *yaml.Node) bool {
+			return yamlMappingValue(yamlMappingValue(root, "workflow"), "graph") != nil
+		}},
+		{"version", 1, func(root *yaml.Node) bool { return yamlMappingValue(root, "version") != nil }},
+	},
+	models.PlatformIFlytek: {
+		{"flowMeta", 4, func(root *yaml.Node) bool { return yamlKind(yamlMappingValue(root, "flowMeta")) == yaml.MappingNode }},
+		{"flowData.nodes", 4, func(root *yaml.Node) bool {
+			return yamlMappingValue(yamlMappingValue(root, "flowData"), "nodes") != nil
+		}},
+		{"flowData.edges", 2, func(root *yaml.Node) bool {
+			return yamlMappingValue(yamlMappingValue(root, "flowData"), "edges") != nil
+		}},
+	},
+	models.PlatformCoze: {
+		{"schema.nodes", 3, func(root *yaml.Node) bool {
+			return yamlMappingValue(yamlMappingValue(root, "schema"), "nodes") != nil
+		}},
+		{"nodes with numeric types", 3, hasNumericNodeTypes},
+		{"workflow_id", 2, func(root *yaml.Node) bool {
+			return yamlMappingValue(root, "workflow_id") != nil || yamlMappingValue(root, "workflowid") != nil
+		}},
+		{"export_format", 2, func(root *yaml.Node) bool {
+			return yamlMappingValue(root, "export_format") != nil || yamlMappingValue(root, "exportformat") != nil
+		}},
+	},
+	models.PlatformUnified: {
+		{"platform_metadata", 3, func(root *yaml.Node) bool { return yamlMappingValue(root, "platform_metadata") != nil }},
+		{"workflow.nodes", 3, func(root *yaml.Node) bool {
+			return yamlMappingValue(yamlMappingValue(root, "workflow"), "nodes") != nil
+		}},
+		{"metadata", 2, func(root *yaml.Node) bool { return yamlKind(yamlMappingValue(root, "metadata")) == yaml.MappingNode }},
+		{"version", 1, func(root *yaml.Node) bool { return yamlMappingValue(root, "version") != nil }},
+	},
+}
+
+// DetectPlatform recognizes the platform of a source DSL from its top-level structure: a ZIP
+// archive or a raw workflow JSON is a Coze export, YAML documents are scored against the signatures of each
+// export format. It fails when no platform matches well or when two platforms match alike,
+// rather than guessing.
+func DetectPlatform(data []byte) (*PlatformDetection, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		if _, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+			return nil, fmt.Errorf("input looks like a ZIP archive but cannot be read: %w", err)
+		}
+		return &PlatformDetection{Platform: models.PlatformCoze, Confidence: 1, Signals: []string{"ZIP export package"}}, nil
+	}
+	if IsCozeWorkflowJSON(data) {
+		return &PlatformDetection{Platform: models.PlatformCoze, Confidence: 1, Signals: []string{"raw workflow JSON"}}, nil
+	}
+
+	documents, err := decodeYAMLDocuments(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot detect source platform: input is not valid YAML: %w", err)
+	}
+
+	// Score each platform by its best matching document
+	var detections []PlatformDetection
+	for platform, signatures := range platformSignatures {
+		best := PlatformDetection{Platform: platform}
+		for _, document := range documents {
+			if detection := scoreDocument(document, platform, signatures); detection.Confidence > best.Confidence {
+				best = detection
+			}
+		}
+		detections = append(detections, best)
+	}
+	sort.Slice(detections, func(i, j int) bool {
+		if detections[i].Confidence != detections[j].Confidence {
+			return detections[i].Confidence > detections[j].Confidence
+		}
+		return detections[i].Platform < detections[j].Platform
+	})
+
+	best, runnerUp := detections[0], detections[1]
+	switch {
+	case best.Confidence == 0:
+		return nil, fmt.Errorf("cannot detect source platform: no Dify, iFlytek or Coze workflow signature found")
+	case best.Confidence < minDetectionConfidence:
+		return nil, fmt.Errorf("cannot detect source platform: too few workflow signatures found, best match %s", describeDetection(best))
+	case best.Confidence-runnerUp.Confidence < minDetectionMargin:
+		return nil, fmt.Errorf("cannot detect source platform: input matches both %s and %s", describeDetection(best), describeDetection(runnerUp))
+	}
+	return &best, nil
+}
+
+// IsCozeWorkflowJSON reports whether data is the raw workflow JSON of the Coze canvas, the
+// document found inside ZIP exports: {"nodes": [...], "edges": [...]} with nodes carrying an id,
+// a type and data, and edges linking them by sourceNodeID.
+func IsCozeWorkflowJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF")))
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		return false
+	}
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &document); err != nil {
+		return false
+	}
+	// Coze YAML exports written as JSON carry the canvas under schema
+	if _, ok := document["schema"]; ok {
+		return false
+	}
+
+	var nodes, edges []map[string]json.RawMessage
+	if json.Unmarshal(document["nodes"], &nodes) != nil || json.Unmarshal(document["edges"], &edges) != nil {
+		return false
+	}
+	if len(nodes) == 0 {
+		return false
+	}
+	for _, node := range nodes {
+		if node["id"] == nil || node["type"] == nil || node["data"] == nil {
+			return false
+		}
+	}
+	for _, edge := range edges {
+		if edge["sourceNodeID"] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// scoreDocument matches the signatures of a platform against one YAML document.
+func scoreDocument(document *yaml.Node, platform models.PlatformType, signatures []platformSignature) PlatformDetection {
+	detection := PlatformDetection{Platform: platform}
+	root := document
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return detection
+	}
+
+	var total, matched float64
+	for _, signature := range signatures {
+		total += signature.weight
+		if signature.matches(root) {
+			matched += signature.weight
+			detection.Signals = append(detection.Signals, signature.name)
+		}
+	}
+	detection.Confidence = matched / total
+	return detection
+}
+
+// describeDetection formats a detection for messages, e.g. "dify (70%: kind: app, version)".
+func describeDetection(detection PlatformDetection) string {
+	description := fmt.Sprintf("%s (%.0f%%", detection.Platform, detection.Confidence*100)
+	if len(detection.Signals) > 0 {
+		description += ": " + strings.Join(detection.Signals, ", ")
+	}
+	return description + ")"
+}
+
+// hasNumericNodeTypes reports whether the top-level nodes carry Coze's numeric type codes.
+func hasNumericNodeTypes(root *yaml.Node) bool {
+	nodes := yamlMappingValue(root, "nodes")
+	if yamlKind(nodes) != yaml.SequenceNode || len(nodes.Content) == 0 {
+		return false
+	}
+	for _, node := range nodes.Content {
+		nodeType := yamlScalarValue(yamlMappingValue(node, "type"))
+		if nodeType == "" || strings.Trim(nodeType, "0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlMappingValue returns the value of a key of a mapping node, or nil.
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if yamlKind(node) != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func yamlScalarValue(node *yaml.Node) string {
+	if yamlKind(node) != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+func yamlKind(node *yaml.Node) yaml.Kind {
+	if node == nil {
+		return 0
+	}
+	return node.Kind
+}
@@ -0,0 +1,219 @@
+package common
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// codeImportPatterns capture the modules imported by Python and JavaScript code
+var codeImportPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^\s*import\s+([\w.]+(?:\s+as\s+\w+)?(?:\s*,\s*[\w.]+(?:\s+as\s+\w+)?)*)`),
+	regexp.MustCompile(`(?m)^\s*from\s+([\w.]+)\s+import\b`),
+	regexp.MustCompile(`__import__\(\s*['"]([\w.]+)['"]`),
+	regexp.MustCompile(`require\(\s*['"]([^'"]+)['"]\s*\)`),
+	regexp.MustCompile(`(?m)^\s*import\s+(?:[^'"]+\s+from\s+)?['"]([^'"]+)['"]`),
+}
+
+// policyViolation is a node breaking a policy rule.
+type policyViolation struct {
+	Rule      models.PolicyRule
+	NodeID    string
+	NodeTitle string
+	Message   string
+	Rewritten bool // The violation was fixed in the DSL
+}
+
+// String formats the violation for warnings and errors.
+func (v policyViolation) String() string {
+	message := fmt.Sprintf("policy %q: node %s (%s): %s", v.Rule.Name, v.NodeTitle, v.NodeID, v.Message)
+	if v.Rewritten {
+		message += " (rewritten)"
+	}
+	return message
+}
+
+// EnforcePolicy evaluates a policy against every node, including iteration sub-workflow nodes.
+// Rewrite rules fix their violations in place. Warn and rewrite violations are returned as
+// warnings; block violations are returned as an error listing all of them.
+func EnforcePolicy(unifiedDSL *models.UnifiedDSL, policy *models.Policy) ([]string, error) {
+	if unifiedDSL == nil || policy == nil || len(policy.Rules) == 0 {
+		return nil, nil
+	}
+
+	violations := enforcePolicy(unifiedDSL.Workflow.Nodes, policy.Rules, nil)
+
+	var warnings, blocked []string
+	for _, violation := range violations {
+		if violation.Rule.EffectiveAction() == models.PolicyActionBlock {
+			blocked = append(blocked, violation.String())
+		} else {
+			warnings = append(warnings, violation.String())
+		}
+	}
+	if len(blocked) > 0 {
+		return warnings, fmt.Errorf("policy violations (%d): %s", len(blocked), strings.Join(blocked, "; "))
+	}
+	return warnings, nil
+}
+
+func enforcePolicy(nodes []models.Node, rules []models.PolicyRule, violations []policyViolation) []policyViolation {
+	for i := range nodes {
+		for _, rule := range rules {
+			if rule.NodeType != "" && rule.NodeType != nodes[i].Type {
+				continue
+			}
+			if message, rewritten, violated := enforcePolicyRule(rule, &nodes[i]); violated {
+				violations = append(violations, policyViolation{
+					Rule:      rule,
+					NodeID:    nodes[i].ID,
+					NodeTitle: nodes[i].Title,
+					Message:   message,
+					Rewritten: rewritten,
+				})
+			}
+		}
+
+		if iterConfig, ok := AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+			violations = enforcePolicy(iterConfig.SubWorkflow.Nodes, rules, violations)
+		}
+	}
+	return violations
+}
+
+// enforcePolicyRule checks one node against the constraint of a rule and applies rewrites.
+func enforcePolicyRule(rule models.PolicyRule, node *models.Node) (message string, rewritten, violated bool) {
+	rewrite := rule.EffectiveAction() == models.PolicyActionRewrite
+
+	switch {
+	case len(rule.ForbidNodeTypes) > 0:
+		for _, nodeType := range rule.ForbidNodeTypes {
+			if node.Type == nodeType {
+				return fmt.Sprintf("%s nodes are not allowed", nodeType), false, true
+			}
+		}
+
+	case len(rule.ForbidImports) > 0:
+		config, ok := AsCodeConfig(node.Config)
+		if !ok || config == nil {
+			return "", false, false
+		}
+		if imports := forbiddenImports(config.Code, rule.ForbidImports); len(imports) > 0 {
+			return fmt.Sprintf("code imports %s", strings.Join(imports, ", ")), false, true
+		}
+
+	default:
+		return enforceModelRule(rule, node, rewrite)
+	}
+	return "", false, false
+}
+
+// enforceModelRule checks the model constraints of LLM and classifier nodes.
+func enforceModelRule(rule models.PolicyRule, node *models.Node, rewrite bool) (string, bool, bool) {
+	model, parameters, store := nodeModelSettings(node)
+	if model == nil {
+		return "", false, false
+	}
+
+	var message string
+	switch {
+	case rule.MaxTemperature != nil:
+		if parameters.Temperature <= *rule.MaxTemperature {
+			return "", false, false
+		}
+		message = fmt.Sprintf("temperature %g exceeds %g", parameters.Temperature, *rule.MaxTemperature)
+		if rewrite {
+			parameters.Temperature = *rule.MaxTemperature
+		}
+	case len(rule.AllowedProviders) > 0:
+		if matchAnyPattern(rule.AllowedProviders, model.Provider) {
+			return "", false, false
+		}
+		message = fmt.Sprintf("provider %q is not approved", model.Provider)
+		if rewrite {
+			model.Provider = rule.Replacement
+		}
+	case len(rule.AllowedModels) > 0:
+		if matchAnyPattern(rule.AllowedModels, model.Name) {
+			return "", false, false
+		}
+		message = fmt.Sprintf("model %q is not approved", model.Name)
+		if rewrite {
+			model.Name = rule.Replacement
+		}
+	default:
+		return "", false, false
+	}
+
+	if rewrite {
+		store()
+	}
+	return message, rewrite, true
+}
+
+// nodeModelSettings returns the model and parameters of LLM and classifier nodes and a function
+// storing edits back into the node, keeping value or pointer config storage unchanged.
+func nodeModelSettings(node *models.Node) (*models.ModelConfig, *models.ModelParameters, func()) {
+	if config, ok := AsLLMConfig(node.Config); ok && config != nil {
+		_, isValue := node.Config.(models.LLMConfig)
+		return &config.Model, &config.Parameters, func() {
+			if isValue {
+				node.Config = *config
+			}
+		}
+	}
+	if config, ok := AsClassifierConfig(node.Config); ok && config != nil {
+		_, isValue := node.Config.(models.ClassifierConfig)
+		return &config.Model, &config.Parameters, func() {
+			if isValue {
+				node.Config = *config
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+func matchAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// forbiddenImports returns the modules imported by code that are forbidden, directly or as
+// submodules of a forbidden module.
+func forbiddenImports(code string, forbidden []string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	for _, module := range codeImports(code) {
+		for _, name := range forbidden {
+			if (module == name || strings.HasPrefix(module, name+".") || strings.HasPrefix(module, name+"/")) && !seen[module] {
+				seen[module] = true
+				found = append(found, module)
+			}
+		}
+	}
+	return found
+}
+
+// codeImports returns the modules imported by Python or JavaScript code.
+func codeImports(code string) []string {
+	var modules []string
+	for _, pattern := range codeImportPatterns {
+		for _, match := range pattern.FindAllStringSubmatch(code, -1) {
+			for _, module := range strings.Split(match[1], ",") {
+				module = strings.TrimSpace(module)
+				if name, _, found := strings.Cut(module, " "); found {
+					module = name
+				}
+				modules = append(modules, strings.TrimPrefix(module, "node:"))
+			}
+		}
+	}
+	return modules
+}
@@ -0,0 +1,239 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Prompt templates reference variables in the style of their platform:
+//
+//	{{#nodeID.output#}}       Dify, references a node output inline
+//	{{$nodes.nodeID.output}}  unified DSL, references a node output inline
+//	{{name}}                  iFlytek and Coze, names a node input bound to a node output
+//
+// Parsers keep the style of the source platform; generators rewrite prompts to the style of the
+// target platform with NamePromptReferences or InlinePromptReferences.
+
+// maxPlaceholderNameLength bounds the name read from an unterminated placeholder
+const maxPlaceholderNameLength = 50
+
+// promptSpecialSelectors are Dify variable selectors that do not reference a workflow node
+var promptSpecialSelectors = map[string]bool{
+	"sys":          true,
+	"env":          true,
+	"conversation": true,
+	"context":      true,
+}
+
+// PromptPlaceholder is a variable placeholder of a prompt template.
+type PromptPlaceholder struct {
+	Text   string // Placeholder as written in the template
+	Name   string // Variable name of named placeholders, empty for inline references
+	NodeID string // Referenced node of inline references
+	Output string // Referenced output of inline references, may be a dotted path
+	Closed bool   // False for a named placeholder missing its closing braces
+}
+
+// IsReference reports whether the placeholder references a node output inline.
+func (p PromptPlaceholder) IsReference() bool {
+	return p.NodeID != ""
+}
+
+// IsNodeReference reports whether the placeholder references an output of a workflow node,
+// as opposed to system, environment or conversation variables.
+func (p PromptPlaceholder) IsNodeReference() bool {
+	return p.IsReference() && p.Output != "" && !promptSpecialSelectors[p.NodeID]
+}
+
+// variableName is the name the placeholder gets as a named placeholder.
+func (p PromptPlaceholder) variableName() string {
+	if !p.IsReference() {
+		return p.Name
+	}
+	if p.Output == "" {
+		return p.NodeID
+	}
+	if index := strings.LastIndex(p.Output, "."); index >= 0 {
+		return p.Output[index+1:]
+	}
+	return p.Output
+}
+
+// ParsePromptPlaceholders returns the placeholders of a template in order of appearance.
+func ParsePromptPlaceholders(template string) []PromptPlaceholder {
+	var placeholders []PromptPlaceholder
+	RewritePromptTemplate(template, func(p PromptPlaceholder) string {
+		placeholders = append(placeholders, p)
+		return p.Text
+	})
+	return placeholders
+}
+
+// RewritePromptTemplate replaces every placeholder of a template with the text returned by
+// rewrite. Returning the placeholder Text keeps it unchanged.
+func RewritePromptTemplate(template string, rewrite func(PromptPlaceholder) string) string {
+	var result strings.Builder
+	rest := template
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			result.WriteString(rest)
+			return result.String()
+		}
+		result.WriteString(rest[:start])
+		rest = rest[start:]
+
+		placeholder, ok := parsePromptPlaceholder(rest)
+		if !ok {
+			result.WriteString("{{")
+			rest = rest[2:]
+			continue
+		}
+		result.WriteString(rewrite(placeholder))
+		rest = rest[len(placeholder.Text):]
+	}
+}
+
+// parsePromptPlaceholder parses the placeholder at the start of text, which starts with "{{".
+func parsePromptPlaceholder(text string) (PromptPlaceholder, bool) {
+	end := strings.Index(text, "}}")
+	if end < 0 {
+		return parseUnterminatedPlaceholder(text)
+	}
+	body := text[2:end]
+	if strings.Contains(body, "{{") {
+		return PromptPlaceholder{}, false
+	}
+
+	placeholder := PromptPlaceholder{Text: text[:end+2], Closed: true}
+	switch {
+	case len(body) > 2 && strings.HasPrefix(body, "#") && strings.HasSuffix(body, "#"):
+		placeholder.NodeID, placeholder.Output, _ = strings.Cut(body[1:len(body)-1], ".")
+	case strings.HasPrefix(body, "$nodes."):
+		placeholder.NodeID, placeholder.Output, _ = strings.Cut(strings.TrimPrefix(body, "$nodes."), ".")
+	default:
+		placeholder.Name = strings.TrimSpace(body)
+	}
+	if placeholder.Name == "" && placeholder.NodeID == "" {
+		return PromptPlaceholder{}, false
+	}
+	return placeholder, true
+}
+
+// parseUnterminatedPlaceholder parses a named placeholder missing its closing braces, such as
+// "{{query" left over by hand edits. The name ends at the first character that cannot be part of it.
+func parseUnterminatedPlaceholder(text string) (PromptPlaceholder, bool) {
+	length := 0
+	for _, char := range text[2:] {
+		if !isPlaceholderNameChar(char) || length >= maxPlaceholderNameLength {
+			break
+		}
+		length++
+	}
+	if length == 0 {
+		return PromptPlaceholder{}, false
+	}
+	return PromptPlaceholder{Text: text[:2+length], Name: text[2 : 2+length]}, true
+}
+
+func isPlaceholderNameChar(char rune) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') ||
+		(char >= '0' && char <= '9') || char == '_'
+}
+
+// NamePromptReferences rewrites a template to named placeholders for iFlytek and Coze. Inline
+// references use the name of the input bound to the same output; references to node outputs
+// without such an input get a new input, which is returned with the given inputs. Other
+// references become their variable name.
+func NamePromptReferences(template string, inputs []models.Input) (string, []models.Input) {
+	result := RewritePromptTemplate(template, func(p PromptPlaceholder) string {
+		if !p.IsReference() {
+			return p.Text
+		}
+		if !p.IsNodeReference() {
+			return "{{" + p.variableName() + "}}"
+		}
+		for _, input := range inputs {
+			if input.Reference != nil && input.Reference.NodeID == p.NodeID && input.Reference.OutputName == p.Output {
+				return "{{" + input.Name + "}}"
+			}
+		}
+
+		name := uniqueInputName(inputs, p.variableName())
+		inputs = append(inputs, models.Input{
+			Name: name,
+			Type: models.DataTypeString,
+			Reference: &models.VariableReference{
+				Type:       models.ReferenceTypeNodeOutput,
+				NodeID:     p.NodeID,
+				OutputName: p.Output,
+				DataType:   models.DataTypeString,
+			},
+		})
+		return "{{" + name + "}}"
+	})
+	return result, inputs
+}
+
+// NamePromptTemplates rewrites the prompts of an LLM configuration with NamePromptReferences
+// and returns the inputs the prompts need.
+func NamePromptTemplates(config *models.LLMConfig, inputs []models.Input) []models.Input {
+	bound := append([]models.Input(nil), inputs...)
+	config.Prompt.SystemTemplate, bound = NamePromptReferences(config.Prompt.SystemTemplate, bound)
+	config.Prompt.UserTemplate, bound = NamePromptReferences(config.Prompt.UserTemplate, bound)
+	return bound
+}
+
+// uniqueInputName returns name, suffixed with a number when an input already uses it.
+func uniqueInputName(inputs []models.Input, name string) string {
+	used := make(map[string]bool, len(inputs))
+	for _, input := range inputs {
+		used[input.Name] = true
+	}
+	candidate := name
+	for suffix := 2; used[candidate]; suffix++ {
+		candidate = fmt.Sprintf("%s_%d", name, suffix)
+	}
+	return candidate
+}
+
+// InlinePromptReferences rewrites a template to inline references for Dify. A named placeholder
+// resolves to the output bound to the input of that name, then to an input referencing an output
+// of that name, then to fallback. Placeholders that cannot be resolved are kept.
+func InlinePromptReferences(template string, inputs []models.Input, fallback func(name string) (nodeID, output string)) string {
+	return RewritePromptTemplate(template, func(p PromptPlaceholder) string {
+		if p.IsReference() || strings.Contains(p.Name, ".") {
+			return p.Text
+		}
+		nodeID, output := resolvePromptName(p.Name, inputs)
+		if nodeID == "" && fallback != nil {
+			nodeID, output = fallback(p.Name)
+		}
+		if nodeID == "" || output == "" {
+			return p.Text
+		}
+		return InlinePromptReference(nodeID, output)
+	})
+}
+
+// resolvePromptName returns the node output bound to the input named name.
+func resolvePromptName(name string, inputs []models.Input) (string, string) {
+	for _, input := range inputs {
+		if input.Name == name && input.Reference != nil && input.Reference.NodeID != "" && input.Reference.OutputName != "" {
+			return input.Reference.NodeID, input.Reference.OutputName
+		}
+	}
+	for _, input := range inputs {
+		if input.Reference != nil && input.Reference.NodeID != "" && input.Reference.OutputName == name {
+			return input.Reference.NodeID, input.Reference.OutputName
+		}
+	}
+	return "", ""
+}
+
+// InlinePromptReference formats a Dify inline reference to a node output.
+func InlinePromptReference(nodeID, output string) string {
+	return "{{#" + nodeID + "." + output + "#}}"
+}
@@ -0,0 +1,125 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// samplingLimits are the model parameters a platform accepts; zero maxima are unbounded.
+type samplingLimits struct {
+	maxTemperature float64
+	maxTopK        int
+	maxTokens      int
+	penalties      bool // Presence and frequency penalties
+	stop           bool
+	seed           bool
+}
+
+var platformSamplingLimits = map[models.PlatformType]samplingLimits{
+	models.PlatformDify:    {maxTemperature: 2, penalties: true, stop: true, seed: true},
+	models.PlatformCoze:    {maxTemperature: 1, maxTokens: 32768, penalties: true},
+	models.PlatformIFlytek: {maxTemperature: 1, maxTopK: 6, maxTokens: 8192},
+}
+
+// Fallbacks of bounded platforms for parameters the source leaves unset
+const (
+	defaultSamplingTopK      = 4
+	defaultSamplingMaxTokens = 4096
+	maxSamplingPenalty       = 2.0
+)
+
+// NormalizeSampling clamps the model parameters of LLM, classifier and agent nodes, including
+// iteration sub-workflow nodes, to the targetPlatform ranges and drops the ones it has no
+// setting for. It returns a warning per node whose parameters changed.
+func NormalizeSampling(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) []string {
+	limits, ok := platformSamplingLimits[targetPlatform]
+	if unifiedDSL == nil || !ok {
+		return nil
+	}
+	return normalizeSampling(unifiedDSL.Workflow.Nodes, targetPlatform, limits, nil)
+}
+
+func normalizeSampling(nodes []models.Node, targetPlatform models.PlatformType, limits samplingLimits, warnings []string) []string {
+	for i := range nodes {
+		node := &nodes[i]
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			warnings = normalizeSampling(iterConfig.SubWorkflow.Nodes, targetPlatform, limits, warnings)
+			continue
+		}
+
+		var changes []string
+		if config, ok := AsLLMConfig(node.Config); ok && config != nil {
+			changes = limits.normalize(&config.Parameters)
+			storeNodeConfig(node, config)
+		} else if config, ok := AsClassifierConfig(node.Config); ok && config != nil {
+			changes = limits.normalize(&config.Parameters)
+			storeNodeConfig(node, config)
+		} else if config, ok := AsAgentConfig(node.Config); ok && config != nil {
+			changes = limits.normalize(&config.Parameters)
+			storeNodeConfig(node, config)
+		}
+		if len(changes) > 0 {
+			warnings = append(warnings, fmt.Sprintf("node %q: %s on %s", node.Title, strings.Join(changes, ", "), targetPlatform))
+		}
+	}
+	return warnings
+}
+
+// normalize fits params into the limits and describes what had to change.
+func (limits samplingLimits) normalize(params *models.ModelParameters) []string {
+	var changes []string
+	if params.Temperature > limits.maxTemperature {
+		changes = append(changes, fmt.Sprintf("temperature %g lowered to %g", params.Temperature, limits.maxTemperature))
+		params.Temperature = limits.maxTemperature
+	} else if params.Temperature < 0 {
+		params.Temperature = 0
+	}
+
+	if limits.maxTopK > 0 {
+		if params.TopK > limits.maxTopK {
+			changes = append(changes, fmt.Sprintf("top_k %d lowered to %d", params.TopK, limits.maxTopK))
+			params.TopK = limits.maxTopK
+		} else if params.TopK <= 0 {
+			params.TopK = min(defaultSamplingTopK, limits.maxTopK)
+		}
+	}
+
+	if limits.maxTokens > 0 {
+		if params.MaxTokens > limits.maxTokens {
+			changes = append(changes, fmt.Sprintf("max tokens %d lowered to %d", params.MaxTokens, limits.maxTokens))
+			params.MaxTokens = limits.maxTokens
+		} else if params.MaxTokens <= 0 {
+			params.MaxTokens = defaultSamplingMaxTokens
+		}
+	}
+
+	if params.PresencePenalty != 0 || params.FrequencyPenalty != 0 {
+		if !limits.penalties {
+			changes = append(changes, "presence and frequency penalties dropped")
+			params.PresencePenalty, params.FrequencyPenalty = 0, 0
+		} else {
+			changes = clampPenalty(changes, "presence penalty", &params.PresencePenalty)
+			changes = clampPenalty(changes, "frequency penalty", &params.FrequencyPenalty)
+		}
+	}
+	if len(params.Stop) > 0 && !limits.stop {
+		changes = append(changes, fmt.Sprintf("stop sequences %q dropped", params.Stop))
+		params.Stop = nil
+	}
+	if params.Seed != 0 && !limits.seed {
+		changes = append(changes, fmt.Sprintf("seed %d dropped", params.Seed))
+		params.Seed = 0
+	}
+	return changes
+}
+
+func clampPenalty(changes []string, name string, penalty *float64) []string {
+	clamped := max(-maxSamplingPenalty, min(*penalty, maxSamplingPenalty))
+	if clamped != *penalty {
+		changes = append(changes, fmt.Sprintf("%s %g limited to %g", name, *penalty, clamped))
+		*penalty = clamped
+	}
+	return changes
+}
@@ -0,0 +1,32 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// CheckStartDefaults returns a warning per start input with a default value targetPlatform drops.
+// iFlytek start inputs keep their description where other platforms keep the default, so they
+// have no default value.
+func CheckStartDefaults(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) []string {
+	if unifiedDSL == nil || targetPlatform != models.PlatformIFlytek {
+		return nil
+	}
+
+	var warnings []string
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		startConfig, ok := AsStartConfig(node.Config)
+		if node.Type != models.NodeTypeStart || !ok || startConfig == nil {
+			continue
+		}
+		for _, variable := range startConfig.Variables {
+			if variable.Default == nil {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("start input %q defaults to %v, but %s start inputs have no default value; the default is dropped",
+				variable.Name, variable.Default, targetPlatform))
+		}
+	}
+	return warnings
+}
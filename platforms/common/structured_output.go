@@ -0,0 +1,159 @@
+package common
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// reasoningOutputName is the thinking output of reasoning models, never a JSON field
+const reasoningOutputName = "reasoning_content"
+
+// jsonSchemaTypes maps unified scalar types to JSON schema types
+var jsonSchemaTypes = map[models.UnifiedDataType]string{
+	models.DataTypeString:  "string",
+	models.DataTypeInteger: "integer",
+	models.DataTypeFloat:   "number",
+	models.DataTypeNumber:  "number",
+	models.DataTypeBoolean: "boolean",
+	models.DataTypeObject:  "object",
+}
+
+// SchemaFromOutputs returns the JSON schema of a JSON response with one field per output.
+func SchemaFromOutputs(outputs []models.Output) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]interface{}, 0)
+	for _, output := range outputs {
+		if output.Name == reasoningOutputName {
+			continue
+		}
+		property := jsonSchemaOf(output.Type)
+		if output.Description != "" {
+			property["description"] = output.Description
+		}
+		properties[output.Name] = property
+		if output.Required {
+			required = append(required, output.Name)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+func jsonSchemaOf(dataType models.UnifiedDataType) map[string]interface{} {
+	if itemType, ok := strings.CutPrefix(string(dataType), "array["); ok {
+		return map[string]interface{}{
+			"type":  "array",
+			"items": jsonSchemaOf(models.UnifiedDataType(strings.TrimSuffix(itemType, "]"))),
+		}
+	}
+	if schemaType, ok := jsonSchemaTypes[dataType]; ok {
+		return map[string]interface{}{"type": schemaType}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// unifiedTypeOf returns the unified type of a JSON schema property
+func unifiedTypeOf(property map[string]interface{}) models.UnifiedDataType {
+	switch property["type"] {
+	case "integer":
+		return models.DataTypeInteger
+	case "number":
+		return models.DataTypeFloat
+	case "boolean":
+		return models.DataTypeBoolean
+	case "object":
+		return models.DataTypeObject
+	case "array":
+		items, _ := property["items"].(map[string]interface{})
+		switch unifiedTypeOf(items) {
+		case models.DataTypeInteger:
+			return models.DataTypeArrayInteger
+		case models.DataTypeFloat:
+			return models.DataTypeArrayFloat
+		case models.DataTypeBoolean:
+			return models.DataTypeArrayBoolean
+		case models.DataTypeObject:
+			return models.DataTypeArrayObject
+		default:
+			return models.DataTypeArrayString
+		}
+	default:
+		return models.DataTypeString
+	}
+}
+
+// StructuredOutputs returns the outputs of an LLM node: its own outputs, followed by the
+// top-level fields of its JSON schema it does not declare. Platforms without schemas expose
+// JSON fields as outputs.
+func StructuredOutputs(node models.Node) []models.Output {
+	llmConfig, ok := AsLLMConfig(node.Config)
+	if !ok || llmConfig == nil || llmConfig.Parameters.ResponseFormat != models.ResponseFormatJSON {
+		return node.Outputs
+	}
+	properties, _ := llmConfig.OutputSchema["properties"].(map[string]interface{})
+	if len(properties) == 0 {
+		return node.Outputs
+	}
+
+	declared := make(map[string]bool, len(node.Outputs))
+	for _, output := range node.Outputs {
+		declared[output.Name] = true
+	}
+	required := make(map[string]bool)
+	requiredNames, _ := llmConfig.OutputSchema["required"].([]interface{})
+	for _, name := range requiredNames {
+		if name, ok := name.(string); ok {
+			required[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		if !declared[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	outputs := append([]models.Output(nil), node.Outputs...)
+	for _, name := range names {
+		property, _ := properties[name].(map[string]interface{})
+		description, _ := property["description"].(string)
+		outputs = append(outputs, models.Output{
+			Name:        name,
+			Type:        unifiedTypeOf(property),
+			Description: description,
+			Required:    required[name],
+		})
+	}
+	return outputs
+}
+
+// ResponseFormat returns the response format of an LLM node generating outputs.
+func ResponseFormat(llmConfig *models.LLMConfig, outputs []models.Output) int {
+	if NeedsJSONFields(outputs) {
+		return models.ResponseFormatJSON
+	}
+	return llmConfig.Parameters.ResponseFormat
+}
+
+// NeedsJSONFields reports whether LLM outputs can only be filled from a JSON answer; text
+// answers fill a single text output.
+func NeedsJSONFields(outputs []models.Output) bool {
+	fields := 0
+	for _, output := range outputs {
+		if output.Name == reasoningOutputName {
+			continue
+		}
+		fields++
+		if output.Type != models.DataTypeString {
+			return true
+		}
+	}
+	return fields > 1
+}
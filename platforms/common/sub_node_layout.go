@@ -0,0 +1,91 @@
+package common
+
+import (
+	"math"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// SubNodeLayout places the nodes of an iteration body inside their container. Generators put
+// nodes at fixed spots by type or keep source positions that may coincide; a node overlapping one
+// placed before it moves below that node, so nodes of one type stack in a column instead of
+// covering each other exactly. The container is then sized to the area the nodes cover.
+type SubNodeLayout struct {
+	gap    float64
+	placed []layoutBox
+}
+
+// layoutBox is the area a placed node covers
+type layoutBox struct {
+	x, y, width, height float64
+}
+
+// minNodeExtent stands in for the size of nodes generated without one, so they still collide
+const minNodeExtent = 1
+
+// NewSubNodeLayout creates a layout keeping gap between stacked nodes
+func NewSubNodeLayout(gap float64) *SubNodeLayout {
+	if gap <= 0 {
+		gap = minNodeExtent
+	}
+	return &SubNodeLayout{gap: gap}
+}
+
+// Reserve marks the area of a node that keeps its position, such as the start node of a body
+func (l *SubNodeLayout) Reserve(position models.Position, width, height float64) {
+	l.placed = append(l.placed, newLayoutBox(position, width, height))
+}
+
+// Place returns the first position at or below position where a node of the given size overlaps
+// no node placed before, and reserves it
+func (l *SubNodeLayout) Place(position models.Position, width, height float64) models.Position {
+	box := newLayoutBox(position, width, height)
+	for {
+		blocker, overlapping := l.overlap(box)
+		if !overlapping {
+			break
+		}
+		box.y = blocker.y + blocker.height + l.gap
+	}
+	l.placed = append(l.placed, box)
+	return models.Position{X: box.x, Y: box.y}
+}
+
+// Extent returns the right and bottom edges of the nodes placed or reserved, relative to the
+// container like their positions
+func (l *SubNodeLayout) Extent() (right, bottom float64) {
+	for _, box := range l.placed {
+		right = math.Max(right, box.x+box.width)
+		bottom = math.Max(bottom, box.y+box.height)
+	}
+	return right, bottom
+}
+
+// ContainerSize returns the size of a container holding the laid out nodes with padding to its
+// right and bottom, in whole pixels and no smaller than minWidth by minHeight
+func (l *SubNodeLayout) ContainerSize(padding, minWidth, minHeight float64) (width, height float64) {
+	right, bottom := l.Extent()
+	return math.Max(minWidth, math.Ceil(right+padding)), math.Max(minHeight, math.Ceil(bottom+padding))
+}
+
+// overlap returns a placed box sharing area with box
+func (l *SubNodeLayout) overlap(box layoutBox) (layoutBox, bool) {
+	for _, placed := range l.placed {
+		if box.x < placed.x+placed.width && placed.x < box.x+box.width &&
+			box.y < placed.y+placed.height && placed.y < box.y+box.height {
+			return placed, true
+		}
+	}
+	return layoutBox{}, false
+}
+
+// newLayoutBox returns the box of a node, at least minNodeExtent in each direction
+func newLayoutBox(position models.Position, width, height float64) layoutBox {
+	if width < minNodeExtent {
+		width = minNodeExtent
+	}
+	if height < minNodeExtent {
+		height = minNodeExtent
+	}
+	return layoutBox{x: position.X, y: position.Y, width: width, height: height}
+}
@@ -0,0 +1,547 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Distance of added start and end nodes from the nearest kept node
+const subgraphNodeSpacing = 400
+
+// ExtractSubgraph reduces a workflow in place to a selection of top-level nodes, to convert a
+// reusable part of a large workflow. includeNodes names nodes to keep; from keeps a node and all
+// nodes downstream of it. Iterations keep their sub-workflows.
+//
+// References to removed nodes become variables of the start node, and edges cut by the selection
+// end at the end node. Both nodes are added when the selection has none. The returned warnings
+// name every rewired reference.
+func ExtractSubgraph(unifiedDSL *models.UnifiedDSL, includeNodes []string, from string) ([]string, error) {
+	if unifiedDSL == nil || (len(includeNodes) == 0 && from == "") {
+		return nil, nil
+	}
+	workflow := &unifiedDSL.Workflow
+
+	topLevel := make(map[string]*models.Node)
+	for i := range workflow.Nodes {
+		if IterationParent(&workflow.Nodes[i]) == "" {
+			topLevel[workflow.Nodes[i].ID] = &workflow.Nodes[i]
+		}
+	}
+	selected, err := selectSubgraph(workflow, topLevel, includeNodes, from)
+	if err != nil {
+		return nil, err
+	}
+
+	subgraph := &subgraph{
+		workflow:  workflow,
+		topLevel:  topLevel,
+		kept:      keptNodes(workflow.Nodes, selected),
+		variables: make(map[string]string),
+		taken:     make(map[string]bool),
+	}
+	subgraph.outputs = make(map[string]map[string]models.Output)
+	subgraph.indexOutputs(workflow.Nodes)
+	subgraph.removed = make(map[string]bool)
+	for id := range subgraph.outputs {
+		subgraph.removed[id] = true
+	}
+	subgraph.forgetKept(workflow.Nodes)
+
+	subgraph.filter()
+	subgraph.rewireReferences()
+	subgraph.connectStart()
+	subgraph.connectEnd()
+	return subgraph.warnings, nil
+}
+
+// selectSubgraph resolves the selection options to top-level node IDs
+func selectSubgraph(workflow *models.Workflow, topLevel map[string]*models.Node, includeNodes []string, from string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	var unknown []string
+	for _, id := range includeNodes {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if topLevel[id] == nil {
+			unknown = append(unknown, id)
+			continue
+		}
+		selected[id] = true
+	}
+
+	if from = strings.TrimSpace(from); from != "" {
+		if topLevel[from] == nil {
+			unknown = append(unknown, from)
+		} else {
+			successors := make(map[string][]string)
+			for _, edge := range workflow.Edges {
+				successors[edge.Source] = append(successors[edge.Source], edge.Target)
+			}
+			queue := []string{from}
+			selected[from] = true
+			for len(queue) > 0 {
+				id := queue[0]
+				queue = queue[1:]
+				for _, next := range successors[id] {
+					if topLevel[next] != nil && !selected[next] {
+						selected[next] = true
+						queue = append(queue, next)
+					}
+				}
+			}
+		}
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown nodes %s (select top-level node IDs; nodes inside iterations come with their iteration)",
+			strings.Join(unknown, ", "))
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no nodes selected")
+	}
+	return selected, nil
+}
+
+// keptNodes adds the iteration children parsers keep at the top level to the selection
+func keptNodes(nodes []models.Node, selected map[string]bool) map[string]bool {
+	kept := make(map[string]bool, len(selected))
+	for id := range selected {
+		kept[id] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for i := range nodes {
+			if parent := IterationParent(&nodes[i]); parent != "" && kept[parent] && !kept[nodes[i].ID] {
+				kept[nodes[i].ID] = true
+				changed = true
+			}
+		}
+	}
+	return kept
+}
+
+type subgraph struct {
+	workflow *models.Workflow
+	topLevel map[string]*models.Node
+	kept     map[string]bool                     // Top-level nodes, iteration children included
+	removed  map[string]bool                     // All removed nodes, sub-workflow nodes included
+	outputs  map[string]map[string]models.Output // Outputs of the original nodes by node and name
+	cutOut   []models.Edge                       // Edges from kept to removed nodes
+
+	start, end *models.Node
+	startID    string
+	variables  map[string]string // Start variable of each rewired "node.output"
+	added      []models.Output   // Start variables added for rewired references
+	taken      map[string]bool   // Start variable names in use
+	warnings   []string
+}
+
+func (s *subgraph) indexOutputs(nodes []models.Node) {
+	for i := range nodes {
+		node := &nodes[i]
+		if s.outputs[node.ID] == nil {
+			s.outputs[node.ID] = make(map[string]models.Output)
+		}
+		for _, output := range node.Outputs {
+			s.outputs[node.ID][output.Name] = output
+		}
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			s.indexOutputs(iterConfig.SubWorkflow.Nodes)
+		}
+	}
+}
+
+// forgetKept clears the removed mark of kept nodes and their sub-workflows
+func (s *subgraph) forgetKept(nodes []models.Node) {
+	for i := range nodes {
+		if !s.kept[nodes[i].ID] {
+			continue
+		}
+		delete(s.removed, nodes[i].ID)
+		if iterConfig, ok := AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+			s.forgetSubWorkflow(iterConfig.SubWorkflow.Nodes)
+		}
+	}
+}
+
+func (s *subgraph) forgetSubWorkflow(nodes []models.Node) {
+	for i := range nodes {
+		delete(s.removed, nodes[i].ID)
+		if iterConfig, ok := AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+			s.forgetSubWorkflow(iterConfig.SubWorkflow.Nodes)
+		}
+	}
+}
+
+// filter drops removed nodes and their edges and finds the start and end nodes of the selection
+func (s *subgraph) filter() {
+	nodes := make([]models.Node, 0, len(s.kept))
+	for _, node := range s.workflow.Nodes {
+		if s.kept[node.ID] {
+			nodes = append(nodes, node)
+		}
+	}
+	s.workflow.Nodes = nodes
+	s.topLevel = make(map[string]*models.Node)
+	for i := range s.workflow.Nodes {
+		node := &s.workflow.Nodes[i]
+		if IterationParent(node) != "" {
+			continue
+		}
+		s.topLevel[node.ID] = node
+		switch {
+		case node.Type == models.NodeTypeStart && s.start == nil:
+			s.start = node
+		case node.Type == models.NodeTypeEnd && s.end == nil:
+			s.end = node
+		}
+	}
+
+	edges := make([]models.Edge, 0, len(s.workflow.Edges))
+	for _, edge := range s.workflow.Edges {
+		source, target := s.kept[edge.Source], s.kept[edge.Target]
+		switch {
+		case source && target:
+			edges = append(edges, edge)
+		case source && !target && s.topLevel[edge.Source] != nil:
+			s.cutOut = append(s.cutOut, edge)
+		}
+	}
+	s.workflow.Edges = edges
+
+	if s.start != nil {
+		s.startID = s.start.ID
+		for _, output := range s.start.Outputs {
+			s.taken[output.Name] = true
+		}
+	} else {
+		s.startID = s.uniqueNodeID("subgraph_start")
+	}
+}
+
+// uniqueNodeID returns base, or base with a number when a node already has that ID
+func (s *subgraph) uniqueNodeID(base string) string {
+	id := base
+	for n := 2; s.outputs[id] != nil || s.topLevel[id] != nil; n++ {
+		id = fmt.Sprintf("%s_%d", base, n)
+	}
+	return id
+}
+
+// rewireReferences points references to removed nodes at start variables
+func (s *subgraph) rewireReferences() {
+	for i := range s.workflow.Nodes {
+		node := &s.workflow.Nodes[i]
+		rewriteNodeReferences(node, func(nodeID, output string) (string, string, bool) {
+			if !s.removed[nodeID] || output == "" {
+				return "", "", false
+			}
+			return s.startID, s.startVariable(node, nodeID, output), true
+		})
+	}
+}
+
+// startVariable returns the start variable replacing an output of a removed node, adding it on first use
+func (s *subgraph) startVariable(reader *models.Node, nodeID, output string) string {
+	key := nodeID + "." + output
+	if name, ok := s.variables[key]; ok {
+		return name
+	}
+
+	name := uniqueName(output, nodeID, s.taken)
+	s.variables[key] = name
+	variable, ok := s.outputs[nodeID][output]
+	if !ok || variable.Type == "" {
+		variable = models.Output{Type: models.DataTypeString}
+	}
+	s.added = append(s.added, models.Output{Name: name, Type: variable.Type, Required: true})
+	s.warnings = append(s.warnings, fmt.Sprintf("%s is not part of the subgraph; start variable %s replaces it (read by %s (%s))",
+		key, name, reader.Title, reader.ID))
+	return name
+}
+
+var nonIdentifierCharacters = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// uniqueName returns name, or name prefixed with the node ID and numbered as needed, and marks it taken
+func uniqueName(name, nodeID string, taken map[string]bool) string {
+	candidate := name
+	if taken[candidate] {
+		candidate = nonIdentifierCharacters.ReplaceAllString(nodeID, "_") + "_" + name
+	}
+	for n := 2; taken[candidate]; n++ {
+		candidate = fmt.Sprintf("%s_%s_%d", nonIdentifierCharacters.ReplaceAllString(nodeID, "_"), name, n)
+	}
+	taken[candidate] = true
+	return candidate
+}
+
+// connectStart adds the start variables and links the start node to the nodes the selection cut
+// off from their predecessors
+func (s *subgraph) connectStart() {
+	if s.start == nil {
+		s.workflow.Nodes = append(s.workflow.Nodes, models.Node{
+			ID:       s.startID,
+			Type:     models.NodeTypeStart,
+			Title:    "Start",
+			Position: s.edgePosition(-subgraphNodeSpacing),
+			Config:   models.StartConfig{Variables: []models.Variable{}},
+		})
+		s.start = &s.workflow.Nodes[len(s.workflow.Nodes)-1]
+		s.topLevel[s.startID] = s.start
+	}
+
+	if len(s.added) > 0 {
+		s.start.Outputs = append(s.start.Outputs, s.added...)
+		if config, ok := AsStartConfig(s.start.Config); ok && config != nil {
+			for _, output := range s.added {
+				config.Variables = append(config.Variables, models.Variable{
+					Name:     output.Name,
+					Label:    output.Name,
+					Type:     string(output.Type),
+					Required: true,
+				})
+			}
+			s.start.Config = *config
+		}
+	}
+
+	for _, node := range s.workflow.Nodes {
+		if s.topLevel[node.ID] == nil || node.ID == s.startID || s.hasEdge(node.ID, false) {
+			continue
+		}
+		s.addEdge(s.startID, "source", node.ID)
+	}
+}
+
+// connectEnd links the nodes the selection cut off from their successors to the end node, adding
+// an end node returning their outputs when the selection has none
+func (s *subgraph) connectEnd() {
+	synthetic := s.end == nil
+	endID := ""
+	if synthetic {
+		endID = s.uniqueNodeID("subgraph_end")
+	} else {
+		endID = s.end.ID
+	}
+
+	var exits []string
+	exited := make(map[string]bool)
+	exit := func(nodeID, handle string) {
+		if !exited[nodeID] {
+			exited[nodeID] = true
+			exits = append(exits, nodeID)
+		}
+		if !s.hasHandle(nodeID, handle, endID) {
+			s.addEdge(nodeID, handle, endID)
+		}
+	}
+	for _, edge := range s.cutOut {
+		exit(edge.Source, edge.SourceHandle)
+	}
+	for _, node := range s.workflow.Nodes {
+		if s.topLevel[node.ID] != nil && node.ID != endID && node.Type != models.NodeTypeEnd && !s.hasEdge(node.ID, true) {
+			exit(node.ID, "source")
+		}
+	}
+	if !synthetic {
+		return
+	}
+
+	end := models.Node{
+		ID:       endID,
+		Type:     models.NodeTypeEnd,
+		Title:    "End",
+		Position: s.edgePosition(subgraphNodeSpacing),
+		Config:   models.EndConfig{OutputMode: models.EndOutputModeVariables},
+	}
+	taken := make(map[string]bool)
+	for _, id := range exits {
+		node := s.topLevel[id]
+		if node == nil || node.Type == models.NodeTypeStart {
+			continue
+		}
+		for _, output := range node.Outputs {
+			end.Inputs = append(end.Inputs, models.Input{
+				Name: uniqueName(output.Name, id, taken),
+				Type: output.Type,
+				Reference: &models.VariableReference{
+					Type:       models.ReferenceTypeNodeOutput,
+					NodeID:     id,
+					OutputName: output.Name,
+					DataType:   output.Type,
+				},
+			})
+		}
+	}
+	s.workflow.Nodes = append(s.workflow.Nodes, end)
+}
+
+// hasEdge reports whether a kept edge leaves (outgoing) or enters a node
+func (s *subgraph) hasEdge(nodeID string, outgoing bool) bool {
+	for _, edge := range s.workflow.Edges {
+		if (outgoing && edge.Source == nodeID) || (!outgoing && edge.Target == nodeID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *subgraph) hasHandle(source, handle, target string) bool {
+	for _, edge := range s.workflow.Edges {
+		if edge.Source == source && edge.SourceHandle == handle && edge.Target == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *subgraph) addEdge(source, handle, target string) {
+	edgeType := models.EdgeTypeDefault
+	if node := s.topLevel[source]; node != nil && (node.Type == models.NodeTypeCondition || node.Type == models.NodeTypeClassifier) {
+		edgeType = models.EdgeTypeConditional
+	}
+	s.workflow.Edges = append(s.workflow.Edges, models.Edge{
+		ID:           fmt.Sprintf("%s-%s-%s", source, handle, target),
+		Source:       source,
+		Target:       target,
+		SourceHandle: handle,
+		TargetHandle: "target",
+		Type:         edgeType,
+	})
+}
+
+// edgePosition places an added node left (negative offset) or right of the kept top-level nodes
+func (s *subgraph) edgePosition(offset float64) models.Position {
+	var position models.Position
+	first := true
+	for _, node := range s.workflow.Nodes {
+		if s.topLevel[node.ID] == nil {
+			continue
+		}
+		if first || (offset < 0 && node.Position.X < position.X) || (offset > 0 && node.Position.X > position.X) {
+			position = node.Position
+			first = false
+		}
+	}
+	position.X += offset
+	return position
+}
+
+// rewriteNodeReferences rewrites the references of a node to other nodes, those of its sub-workflow
+// included. rewrite returns the new node and output of a reference, or false to keep it; node IDs
+// without an output, such as the iteration of a child node, are passed with an empty output.
+func rewriteNodeReferences(node *models.Node, rewrite func(nodeID, output string) (string, string, bool)) {
+	rewriteReferences(reflect.ValueOf(&node.Inputs).Elem(), "", rewrite)
+	if node.Config != nil {
+		config := reflect.New(reflect.TypeOf(node.Config)).Elem()
+		config.Set(reflect.ValueOf(node.Config))
+		rewriteReferences(config, "", rewrite)
+		node.Config = config.Interface().(models.NodeConfig)
+	}
+}
+
+// nodeIDFields are the config fields holding a bare node ID
+var nodeIDFields = map[string]bool{"IterationID": true, "ParentID": true, "StartNodeID": true, "EndNodeID": true}
+
+// rewriteReferences rewrites the node references of a settable value: variable references,
+// selectors, iteration sources, classifier query variables and template references
+func rewriteReferences(value reflect.Value, field string, rewrite func(nodeID, output string) (string, string, bool)) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			rewriteReferences(value.Elem(), field, rewrite)
+		}
+	case reflect.Interface:
+		if value.IsNil() || !value.CanSet() {
+			return
+		}
+		inner := reflect.New(value.Elem().Type()).Elem()
+		inner.Set(value.Elem())
+		rewriteReferences(inner, field, rewrite)
+		value.Set(inner)
+	case reflect.Struct:
+		if !value.CanAddr() {
+			return
+		}
+		switch reference := value.Addr().Interface().(type) {
+		case *models.VariableReference:
+			if nodeID, output, ok := rewrite(reference.NodeID, reference.OutputName); ok {
+				reference.NodeID, reference.OutputName = nodeID, output
+			}
+			reference.Template = rewriteTemplate(reference.Template, rewrite)
+			return
+		case *models.IteratorConfig:
+			if nodeID, output, ok := rewrite(reference.SourceNode, reference.SourceOutput); ok {
+				reference.SourceNode, reference.SourceOutput = nodeID, output
+			}
+		case *models.OutputSelectorConfig:
+			if nodeID, output, ok := rewrite(reference.NodeID, reference.OutputName); ok {
+				reference.NodeID, reference.OutputName = nodeID, output
+			}
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).IsExported() {
+				rewriteReferences(value.Field(i), value.Type().Field(i).Name, rewrite)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if selector, ok := value.Interface().([]string); ok && strings.HasSuffix(field, "Selector") {
+			if len(selector) >= 2 {
+				if nodeID, output, ok := rewrite(selector[0], selector[1]); ok {
+					selector[0], selector[1] = nodeID, output
+				}
+			}
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			rewriteReferences(value.Index(i), field, rewrite)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			element := reflect.New(iter.Value().Type()).Elem()
+			element.Set(iter.Value())
+			rewriteReferences(element, field, rewrite)
+			value.SetMapIndex(iter.Key(), element)
+		}
+	case reflect.String:
+		if !value.CanSet() {
+			return
+		}
+		text := value.String()
+		if nodeIDFields[field] {
+			if nodeID, _, ok := rewrite(text, ""); ok && text != "" {
+				value.SetString(nodeID)
+			}
+			return
+		}
+		if field == "QueryVariable" {
+			if nodeID, output, found := strings.Cut(text, "."); found {
+				if nodeID, output, ok := rewrite(nodeID, output); ok {
+					value.SetString(nodeID + "." + output)
+				}
+				return
+			}
+		}
+		value.SetString(rewriteTemplate(text, rewrite))
+	}
+}
+
+// rewriteTemplate rewrites the node references of a prompt or answer template
+func rewriteTemplate(text string, rewrite func(nodeID, output string) (string, string, bool)) string {
+	matches := templateNodeReference.FindAllStringSubmatchIndex(text, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		match := matches[i]
+		nodeID := strings.TrimSpace(text[match[2]:match[3]])
+		output := strings.TrimSpace(text[match[4]:match[5]])
+		if newID, newOutput, ok := rewrite(nodeID, output); ok {
+			text = text[:match[2]] + newID + "." + newOutput + text[match[5]:]
+		}
+	}
+	return text
+}
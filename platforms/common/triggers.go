@@ -0,0 +1,127 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// triggerNodeIDPrefix names the placeholder nodes of TriggerStrategyPlaceholder
+const triggerNodeIDPrefix = "trigger::"
+
+// platformHasTriggers reports whether a platform runs workflows on schedules and events itself
+func platformHasTriggers(platform models.PlatformType) bool {
+	return platform == models.PlatformCoze || platform == models.PlatformUnified
+}
+
+// LowerTriggers degrades the workflow triggers for targets without them, after
+// options.TriggerStrategy: TriggerStrategyComment documents them in the description of the start
+// node, TriggerStrategyPlaceholder adds an unconnected code placeholder per trigger, to be wired
+// to an external scheduler or deleted. Options may be nil. It returns a warning per trigger.
+func LowerTriggers(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType, options *models.ConversionOptions) []string {
+	if unifiedDSL == nil || len(unifiedDSL.Workflow.Triggers) == 0 || platformHasTriggers(targetPlatform) {
+		return nil
+	}
+	strategy := models.TriggerStrategyComment
+	if options != nil && options.TriggerStrategy != "" {
+		strategy = options.TriggerStrategy
+	}
+
+	start := -1
+	for i, node := range unifiedDSL.Workflow.Nodes {
+		if node.Type == models.NodeTypeStart {
+			start = i
+			break
+		}
+	}
+
+	var warnings []string
+	triggers := unifiedDSL.Workflow.Triggers
+	unifiedDSL.Workflow.Triggers = nil
+	if strategy == models.TriggerStrategyPlaceholder || start < 0 {
+		for i, trigger := range triggers {
+			node := triggerPlaceholderNode(trigger)
+			if start >= 0 {
+				position := unifiedDSL.Workflow.Nodes[start].Position
+				node.Position = models.Position{X: position.X, Y: position.Y - float64(200*(i+1))}
+			}
+			unifiedDSL.Workflow.Nodes = append(unifiedDSL.Workflow.Nodes, node)
+			warnings = append(warnings, fmt.Sprintf("%s has no triggers: %s became the unconnected placeholder node %s; call the workflow from a scheduler or event source instead",
+				targetPlatform, DescribeTrigger(trigger), node.ID))
+		}
+		return warnings
+	}
+
+	lines := make([]string, 0, len(triggers)+1)
+	lines = append(lines, "触发器（目标平台不支持，请在外部按以下配置调用工作流）:")
+	for _, trigger := range triggers {
+		lines = append(lines, "- "+DescribeTrigger(trigger))
+		warnings = append(warnings, fmt.Sprintf("%s has no triggers: %s is documented in the start node description; call the workflow from a scheduler or event source instead",
+			targetPlatform, DescribeTrigger(trigger)))
+	}
+	startNode := &unifiedDSL.Workflow.Nodes[start]
+	if startNode.Description != "" {
+		lines = append([]string{startNode.Description}, lines...)
+	}
+	startNode.Description = strings.Join(lines, "\n")
+	return warnings
+}
+
+// DescribeTrigger returns a one-line description of a trigger, e.g.
+// trigger "daily report" (schedule "0 9 * * *" Asia/Shanghai, inputs {"topic":"news"}).
+func DescribeTrigger(trigger models.Trigger) string {
+	var details []string
+	switch trigger.Type {
+	case models.TriggerTypeSchedule:
+		schedule := fmt.Sprintf("schedule %q", trigger.Cron)
+		if trigger.Timezone != "" {
+			schedule += " " + trigger.Timezone
+		}
+		details = append(details, schedule)
+	case models.TriggerTypeEvent:
+		details = append(details, fmt.Sprintf("event %q", trigger.Event))
+	default:
+		details = append(details, string(trigger.Type))
+	}
+	if len(trigger.Inputs) > 0 {
+		inputs, _ := json.Marshal(trigger.Inputs)
+		details = append(details, "inputs "+string(inputs))
+	}
+	name := trigger.Title
+	if name == "" {
+		name = trigger.ID
+	}
+	return fmt.Sprintf("trigger %q (%s)", name, strings.Join(details, ", "))
+}
+
+// triggerPlaceholderNode documents a trigger in a Python stub returning the start inputs the
+// trigger passes
+func triggerPlaceholderNode(trigger models.Trigger) models.Node {
+	title := trigger.Title
+	if title == "" {
+		title = "触发器"
+	}
+	header := fmt.Sprintf("触发器（%s）：目标平台不支持，请用外部调度按此配置调用工作流", trigger.Type)
+	inputs, _ := json.Marshal(trigger.Inputs)
+	if len(trigger.Inputs) == 0 {
+		inputs = []byte("{}")
+	}
+
+	var code strings.Builder
+	code.WriteString("# " + header + "\n")
+	writeConfigComment(&code, trigger)
+	code.WriteString("import json\n\n\n")
+	code.WriteString("def main() -> dict:\n")
+	code.WriteString(fmt.Sprintf("    return {\"inputs\": json.loads(%q)}", string(inputs)))
+
+	node := asPythonCodeNode(models.Node{
+		ID:          triggerNodeIDPrefix + trigger.ID,
+		Title:       FormatUnsupportedNodeTitle(title),
+		Description: DescribeTrigger(trigger),
+		Outputs:     []models.Output{{Name: "inputs", Type: models.DataTypeObject}},
+	}, code.String(), false, "")
+	MarkPlaceholder(&node, models.PlaceholderMarker{OriginalType: "trigger", OriginalID: trigger.ID, Action: header})
+	return node
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// suggestedQuestionLimits caps the suggested questions per target platform. Targets not listed
+// keep every question; Coze workflows have no opening questions and are not listed either.
+var suggestedQuestionLimits = map[models.PlatformType]int{
+	models.PlatformIFlytek: 3, // The three input examples of the agent prologue
+}
+
+// SuggestedQuestionLimit returns how many suggested questions targetPlatform keeps, 0 for no limit.
+func SuggestedQuestionLimit(targetPlatform models.PlatformType) int {
+	return suggestedQuestionLimits[targetPlatform]
+}
+
+// NonBlankQuestions returns the questions that are not blank, at most limit of them unless
+// limit is 0.
+func NonBlankQuestions(suggested []string, limit int) []string {
+	var questions []string
+	for _, question := range suggested {
+		if strings.TrimSpace(question) == "" {
+			continue
+		}
+		if limit > 0 && len(questions) == limit {
+			break
+		}
+		questions = append(questions, question)
+	}
+	return questions
+}
+
+// CheckSuggestedQuestions returns a warning when targetPlatform drops some of the suggested
+// questions of the workflow.
+func CheckSuggestedQuestions(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) []string {
+	limit := SuggestedQuestionLimit(targetPlatform)
+	if unifiedDSL == nil || limit == 0 {
+		return nil
+	}
+	uiConfig := unifiedDSL.Metadata.UIConfig
+	if uiConfig == nil {
+		return nil
+	}
+	total := len(NonBlankQuestions(uiConfig.SuggestedQuestions, 0))
+	if total <= limit {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s keeps %d of %d suggested questions; the rest are dropped", targetPlatform, limit, total)}
+}
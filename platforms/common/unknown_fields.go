@@ -0,0 +1,125 @@
+package common
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// UnknownFieldsKey is the PlatformConfig key under which parsers keep the node keys they do not
+// recognize, so that generating the same platform again emits them unchanged.
+const UnknownFieldsKey = "unknown_fields"
+
+// SourceParamsKey is the PlatformConfig key under which parsers keep source settings the unified
+// DSL has no field for, such as the model ID of a Coze LLM node, for generators of the same
+// platform to reuse.
+const SourceParamsKey = "source_params"
+
+// SourceNodeKey is the PlatformConfig key under which parsers keep the type, inputs and outputs of
+// a source node they replaced by a code placeholder, so that generating the same platform again
+// emits the original node.
+const SourceNodeKey = "source_node"
+
+// BatchNodeKey is the PlatformConfig key under which parsers keep the batch settings and outputs of
+// a source node run in batch mode, which they wrap in an iteration, so that generating the same
+// platform again emits the node in batch mode.
+const BatchNodeKey = "batch_node"
+
+// Keys of the recorded unknown fields
+const (
+	unknownFieldsNodeType = "node_type" // Source node type the fields belong to
+	unknownFieldsNode     = "node"      // Keys of the node itself
+	unknownFieldsData     = "data"      // Keys of the node data
+)
+
+// StoreUnknownFields records the unrecognized node and node data keys of a source node in its
+// platform data. nodeType identifies the kind of source node, so that the keys are only emitted
+// again on a node of the same kind.
+func StoreUnknownFields(platformData *map[string]interface{}, nodeType string, nodeFields, dataFields map[string]interface{}) {
+	if len(nodeFields) == 0 && len(dataFields) == 0 {
+		return
+	}
+	if *platformData == nil {
+		*platformData = make(map[string]interface{})
+	}
+
+	fields := map[string]interface{}{unknownFieldsNodeType: nodeType}
+	if len(nodeFields) > 0 {
+		fields[unknownFieldsNode] = nodeFields
+	}
+	if len(dataFields) > 0 {
+		fields[unknownFieldsData] = dataFields
+	}
+	(*platformData)[UnknownFieldsKey] = fields
+}
+
+// RestoreUnknownFields returns the unrecognized node and node data keys recorded for a node
+// generated as nodeType. Keys of the generated node structures, given as nodeStruct and
+// dataStruct, are left out because the generator owns them.
+func RestoreUnknownFields(platformData map[string]interface{}, nodeType string, nodeStruct, dataStruct interface{}) (nodeFields, dataFields map[string]interface{}) {
+	fields, ok := platformData[UnknownFieldsKey].(map[string]interface{})
+	if !ok || fields[unknownFieldsNodeType] != nodeType {
+		return nil, nil
+	}
+	nodeFields, _ = fields[unknownFieldsNode].(map[string]interface{})
+	dataFields, _ = fields[unknownFieldsData].(map[string]interface{})
+	return withoutStructFields(nodeFields, nodeStruct), withoutStructFields(dataFields, dataStruct)
+}
+
+// NodesByTargetID maps generated node IDs to the source nodes, including iteration sub-workflow
+// nodes, they were generated from according to a source-to-target node ID mapping.
+func NodesByTargetID(nodes []models.Node, idMapping map[string]string) map[string]*models.Node {
+	sourceNodes := make(map[string]*models.Node)
+	var walk func(nodes []models.Node)
+	walk = func(nodes []models.Node) {
+		for i := range nodes {
+			if targetID, ok := idMapping[nodes[i].ID]; ok {
+				sourceNodes[targetID] = &nodes[i]
+			}
+			if iterConfig, ok := AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+				walk(iterConfig.SubWorkflow.Nodes)
+			}
+		}
+	}
+	walk(nodes)
+	return sourceNodes
+}
+
+// withoutStructFields drops the fields serialized by the YAML tags of a struct.
+func withoutStructFields(fields map[string]interface{}, structValue interface{}) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	known := yamlFieldNames(reflect.TypeOf(structValue))
+	kept := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if !known[key] {
+			kept[key] = value
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// yamlFieldNames returns the keys the fields of a struct type are serialized under.
+func yamlFieldNames(structType reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" || strings.Contains(options, "inline") {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		names[name] = true
+	}
+	return names
+}
@@ -0,0 +1,163 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// RunValidators runs the validators of ctx.Stage on the workflow. Warning issues are returned as
+// warnings; error issues are returned as an error listing all of them.
+func RunValidators(unifiedDSL *models.UnifiedDSL, validators []models.Validator, ctx models.ValidationContext) ([]string, error) {
+	if unifiedDSL == nil {
+		return nil, nil
+	}
+
+	var warnings, failures []string
+	for _, validator := range validators {
+		if validator.Stage() != ctx.Stage {
+			continue
+		}
+		for _, issue := range validator.Validate(ctx, unifiedDSL) {
+			message := formatValidationIssue(validator.Name(), issue, unifiedDSL)
+			if issue.Severity == models.SeverityWarning {
+				warnings = append(warnings, message)
+			} else {
+				failures = append(failures, message)
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return warnings, fmt.Errorf("%s validation rules failed (%d): %s", ctx.Stage, len(failures), strings.Join(failures, "; "))
+	}
+	return warnings, nil
+}
+
+// formatValidationIssue names the rule and the node of an issue
+func formatValidationIssue(rule string, issue models.ValidationIssue, unifiedDSL *models.UnifiedDSL) string {
+	if issue.NodeID == "" {
+		return fmt.Sprintf("rule %q: %s", rule, issue.Message)
+	}
+	title := issue.NodeID
+	if node := findNode(unifiedDSL.Workflow.Nodes, issue.NodeID); node != nil {
+		title = node.Title
+	}
+	return fmt.Sprintf("rule %q: node %s (%s): %s", rule, title, issue.NodeID, issue.Message)
+}
+
+func findNode(nodes []models.Node, id string) *models.Node {
+	for i := range nodes {
+		if nodes[i].ID == id {
+			return &nodes[i]
+		}
+		if iterConfig, ok := AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+			if node := findNode(iterConfig.SubWorkflow.Nodes, id); node != nil {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+// RuleValidators returns a validator per rule of a rules file.
+func RuleValidators(rules *models.ValidationRules) []models.Validator {
+	if rules == nil {
+		return nil
+	}
+	validators := make([]models.Validator, 0, len(rules.Rules))
+	for _, rule := range rules.Rules {
+		rule := rule
+		validators = append(validators, models.ValidatorFunc{
+			RuleName:  rule.Name,
+			RuleStage: rule.EffectiveStage(),
+			Check: func(ctx models.ValidationContext, unifiedDSL *models.UnifiedDSL) []models.ValidationIssue {
+				return checkValidationRule(rule, unifiedDSL)
+			},
+		})
+	}
+	return validators
+}
+
+// checkValidationRule checks the workflow against the constraint of a rule
+func checkValidationRule(rule models.ValidationRule, unifiedDSL *models.UnifiedDSL) []models.ValidationIssue {
+	severity := rule.EffectiveSeverity()
+	nodes := unifiedDSL.Workflow.Nodes
+
+	switch {
+	case rule.RequireEndOutput != "":
+		for _, node := range nodes {
+			if config, ok := AsEndConfig(node.Config); ok && config != nil && hasEndOutput(config, rule.RequireEndOutput) {
+				return nil
+			}
+		}
+		return []models.ValidationIssue{{Severity: severity, Message: fmt.Sprintf("no end node returns %q", rule.RequireEndOutput)}}
+
+	case rule.MaxNodes > 0:
+		if len(nodes) > rule.MaxNodes {
+			return []models.ValidationIssue{{Severity: severity, Message: fmt.Sprintf("%d nodes exceed the limit of %d", len(nodes), rule.MaxNodes)}}
+		}
+		return nil
+
+	default:
+		return checkNodeRule(rule, severity, nodes, nil)
+	}
+}
+
+// checkNodeRule checks every node, including iteration sub-workflow nodes, against a node constraint
+func checkNodeRule(rule models.ValidationRule, severity models.ErrorSeverity, nodes []models.Node, issues []models.ValidationIssue) []models.ValidationIssue {
+	var titlePattern *regexp.Regexp
+	if rule.TitlePattern != "" {
+		titlePattern = regexp.MustCompile(rule.TitlePattern)
+	}
+
+	for _, node := range nodes {
+		if rule.NodeType == "" || rule.NodeType == node.Type {
+			var message string
+			switch {
+			case rule.RequireSystemPrompt:
+				if config, ok := AsLLMConfig(node.Config); ok && config != nil && !hasSystemPrompt(config) {
+					message = "LLM node has no system prompt"
+				}
+			case rule.RequireDescription:
+				if strings.TrimSpace(node.Description) == "" {
+					message = "node has no description"
+				}
+			case titlePattern != nil:
+				if !titlePattern.MatchString(node.Title) {
+					message = fmt.Sprintf("title does not match %s", rule.TitlePattern)
+				}
+			}
+			if message != "" {
+				issues = append(issues, models.ValidationIssue{Severity: severity, NodeID: node.ID, Message: message})
+			}
+		}
+
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			issues = checkNodeRule(rule, severity, iterConfig.SubWorkflow.Nodes, issues)
+		}
+	}
+	return issues
+}
+
+func hasSystemPrompt(config *models.LLMConfig) bool {
+	if strings.TrimSpace(config.Prompt.SystemTemplate) != "" {
+		return true
+	}
+	for _, message := range config.Prompt.Messages {
+		if message.Role == "system" && strings.TrimSpace(message.Content) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasEndOutput(config *models.EndConfig, name string) bool {
+	for _, output := range config.Outputs {
+		if output.Variable == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// BumpVersion increments the major, minor or patch part of a semantic workflow version and resets
+// the parts after it. A "v" prefix is kept, pre-release and build suffixes are dropped, and a
+// missing minor or patch counts as 0. An empty version bumps from 0.0.0.
+func BumpVersion(version, part string) (string, error) {
+	prefix := ""
+	core := strings.TrimSpace(version)
+	if rest, ok := strings.CutPrefix(core, "v"); ok {
+		prefix, core = "v", rest
+	}
+	if end := strings.IndexAny(core, "-+"); end >= 0 {
+		core = core[:end]
+	}
+
+	numbers := [3]int{}
+	if core != "" {
+		fields := strings.Split(core, ".")
+		if len(fields) > 3 {
+			return "", fmt.Errorf("version %q is not a semantic version", version)
+		}
+		for i, field := range fields {
+			number, err := strconv.Atoi(field)
+			if err != nil || number < 0 {
+				return "", fmt.Errorf("version %q is not a semantic version", version)
+			}
+			numbers[i] = number
+		}
+	}
+
+	switch part {
+	case models.VersionBumpMajor:
+		numbers = [3]int{numbers[0] + 1, 0, 0}
+	case models.VersionBumpMinor:
+		numbers = [3]int{numbers[0], numbers[1] + 1, 0}
+	case models.VersionBumpPatch:
+		numbers[2]++
+	default:
+		return "", fmt.Errorf("unknown version part %q", part)
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, numbers[0], numbers[1], numbers[2]), nil
+}
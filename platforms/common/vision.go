@@ -0,0 +1,57 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// visionModelKeywords appear in the names of models that accept images
+var visionModelKeywords = []string{"vision", "-vl", "vl-", "4v", "4o", "gemini", "claude-3", "claude-sonnet", "claude-opus", "image", "qvq", "omni"}
+
+// IsVisionModel reports whether a model name looks like a multimodal model.
+func IsVisionModel(modelName string) bool {
+	name := strings.ToLower(modelName)
+	for _, keyword := range visionModelKeywords {
+		if strings.Contains(name, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckVision returns a warning per LLM node, including iteration sub-workflow nodes, that reads
+// images the targetPlatform model cannot see.
+func CheckVision(unifiedDSL *models.UnifiedDSL, targetPlatform models.PlatformType) []string {
+	if unifiedDSL == nil || targetPlatform == models.PlatformUnified {
+		return nil
+	}
+	return checkVision(unifiedDSL.Workflow.Nodes, targetPlatform, nil)
+}
+
+func checkVision(nodes []models.Node, targetPlatform models.PlatformType, warnings []string) []string {
+	for _, node := range nodes {
+		if iterConfig, ok := AsIterationConfig(node.Config); ok && iterConfig != nil {
+			warnings = checkVision(iterConfig.SubWorkflow.Nodes, targetPlatform, warnings)
+			continue
+		}
+		llmConfig, ok := AsLLMConfig(node.Config)
+		if node.Type != models.NodeTypeLLM || !ok || llmConfig == nil || llmConfig.Vision == nil || !llmConfig.Vision.Enabled {
+			continue
+		}
+
+		switch targetPlatform {
+		case models.PlatformIFlytek:
+			warnings = append(warnings, fmt.Sprintf("LLM node %q reads images, but iFlytek LLM nodes are generated with a text model; switch it to a Spark image understanding model", node.Title))
+		case models.PlatformCoze:
+			warnings = append(warnings, fmt.Sprintf("LLM node %q reads images, which Coze LLM nodes are generated without; add the image input by hand", node.Title))
+		default:
+			if !IsVisionModel(llmConfig.Model.Name) {
+				warnings = append(warnings, fmt.Sprintf("LLM node %q reads images, but model %q may not accept them on %s; pick a vision model",
+					node.Title, llmConfig.Model.Name, targetPlatform))
+			}
+		}
+	}
+	return warnings
+}
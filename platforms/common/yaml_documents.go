@@ -0,0 +1,214 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bounds of the size of a YAML document after alias expansion. yaml.v3 rejects documents whose
+// aliases expand to a multiple of their size; exports that reuse large blocks through anchors
+// legitimately exceed its ratio, so aliases are expanded here within a budget of their own: a
+// fixed allowance plus a multiple of the parsed nodes, never more than an absolute cap. The
+// expanded size is counted before anything is copied.
+const (
+	yamlExpansionAllowance = 10_000
+	yamlExpansionRatio     = 100
+	yamlExpansionCap       = 500_000
+)
+
+// UnmarshalYAMLDocument decodes the workflow document of a possibly multi-document YAML stream
+// into out; a workflow document is a mapping with all the given top-level keys. Empty documents
+// and other documents, such as CI metadata, are skipped; a stream with several workflow documents
+// is rejected because one conversion handles one workflow. Anchors, aliases and merge keys are
+// expanded before decoding.
+func UnmarshalYAMLDocument(data []byte, out interface{}, platform string, keys ...string) error {
+	documents, err := decodeYAMLDocuments(data)
+	if err != nil {
+		return err
+	}
+
+	var selected []int
+	for i, document := range documents {
+		if yamlMappingHasKeys(document, keys) {
+			selected = append(selected, i)
+		}
+	}
+	switch {
+	case len(selected) == 0 && len(documents) == 1:
+		// A lone document is decoded as is, leaving field checks to the parser
+		selected = []int{0}
+	case len(selected) == 0:
+		return fmt.Errorf("none of the %d YAML documents is a %s workflow", len(documents), platform)
+	case len(selected) > 1:
+		positions := make([]string, len(selected))
+		for i, index := range selected {
+			positions[i] = fmt.Sprint(index + 1)
+		}
+		return fmt.Errorf("found %d %s workflows in YAML documents %s; convert them one at a time",
+			len(selected), platform, strings.Join(positions, ", "))
+	}
+
+	document := documents[selected[0]]
+	if err := expandYAMLAliases(document); err != nil {
+		return err
+	}
+	if err := document.Decode(out); err != nil {
+		if len(documents) > 1 {
+			return fmt.Errorf("YAML document %d: %w", selected[0]+1, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// yamlMappingHasKeys reports whether a document is a mapping containing all keys.
+func yamlMappingHasKeys(document *yaml.Node, keys []string) bool {
+	root := document
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return false
+	}
+	for _, key := range keys {
+		found := false
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeYAMLDocuments returns the non-empty documents of a YAML stream.
+func decodeYAMLDocuments(data []byte) ([]*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var documents []*yaml.Node
+	for {
+		document := &yaml.Node{}
+		err := decoder.Decode(document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("YAML document %d: %w", len(documents)+1, err)
+		}
+		if isEmptyYAMLDocument(document) {
+			continue
+		}
+		documents = append(documents, document)
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("no YAML document found")
+	}
+	return documents, nil
+}
+
+func isEmptyYAMLDocument(document *yaml.Node) bool {
+	if len(document.Content) == 0 {
+		return true
+	}
+	root := document.Content[0]
+	return root.Kind == yaml.ScalarNode && root.Tag == "!!null"
+}
+
+// expandYAMLAliases replaces every alias with a copy of its anchored node, after checking that
+// the copies stay within the expansion budget of the document.
+func expandYAMLAliases(document *yaml.Node) error {
+	if err := checkYAMLExpansion(document); err != nil {
+		return err
+	}
+	var expand func(node *yaml.Node)
+	expand = func(node *yaml.Node) {
+		for i, child := range node.Content {
+			if child.Kind == yaml.AliasNode {
+				copied := copyYAMLNode(child.Alias)
+				copied.Anchor = ""
+				node.Content[i] = copied
+				child = copied
+			}
+			expand(child)
+		}
+	}
+	expand(document)
+	return nil
+}
+
+// checkYAMLExpansion counts the nodes of a document with its aliases expanded, without expanding
+// them, and fails on aliases that refer to themselves or expand beyond the budget.
+func checkYAMLExpansion(document *yaml.Node) error {
+	parsed := 0
+	var count func(node *yaml.Node)
+	count = func(node *yaml.Node) {
+		parsed++
+		if node.Kind == yaml.AliasNode {
+			return
+		}
+		for _, child := range node.Content {
+			count(child)
+		}
+	}
+	count(document)
+	budget := yamlExpansionAllowance + yamlExpansionRatio*parsed
+	if budget > yamlExpansionCap {
+		budget = yamlExpansionCap
+	}
+
+	// Expanded sizes by node, each anchored node measured once
+	sizes := make(map[*yaml.Node]int)
+	active := make(map[*yaml.Node]bool)
+	var measure func(node *yaml.Node) (int, error)
+	measure = func(node *yaml.Node) (int, error) {
+		if node.Kind == yaml.AliasNode {
+			if active[node.Alias] {
+				return 0, fmt.Errorf("YAML alias *%s refers to itself", node.Value)
+			}
+			node = node.Alias
+		}
+		if size, ok := sizes[node]; ok {
+			return size, nil
+		}
+		active[node] = true
+		defer delete(active, node)
+		size := 1
+		for _, child := range node.Content {
+			childSize, err := measure(child)
+			if err != nil {
+				return 0, err
+			}
+			size += childSize
+			if size > budget {
+				return 0, fmt.Errorf("YAML aliases expand to more than %d nodes", budget)
+			}
+		}
+		sizes[node] = size
+		return size, nil
+	}
+	_, err := measure(document)
+	return err
+}
+
+// copyYAMLNode returns a deep copy of node. Aliases inside are kept and expanded by the caller.
+func copyYAMLNode(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		if child.Kind == yaml.AliasNode {
+			alias := *child
+			copied.Content[i] = &alias
+			continue
+		}
+		copied.Content[i] = copyYAMLNode(child)
+	}
+	return &copied
+}
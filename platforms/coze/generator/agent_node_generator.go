@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// AgentNodeGenerator generates agents parsed from Coze as the LLM nodes with skills they came from.
+// Other agents have no Coze skills and are lowered to LLM nodes before generation.
+type AgentNodeGenerator struct {
+	llm *LLMNodeGenerator
+}
+
+// NewAgentNodeGenerator creates an agent node generator
+func NewAgentNodeGenerator() *AgentNodeGenerator {
+	return &AgentNodeGenerator{llm: NewLLMNodeGenerator()}
+}
+
+// SetIDGenerator sets the shared ID generator
+func (g *AgentNodeGenerator) SetIDGenerator(idGenerator *CozeIDGenerator) {
+	g.llm.SetIDGenerator(idGenerator)
+}
+
+// GetNodeType returns the node type this generator handles
+func (g *AgentNodeGenerator) GetNodeType() models.NodeType {
+	return models.NodeTypeAgent
+}
+
+// ValidateNode validates the unified node before generation
+func (g *AgentNodeGenerator) ValidateNode(unifiedNode *models.Node) error {
+	if unifiedNode == nil {
+		return fmt.Errorf("unified node is nil")
+	}
+	if unifiedNode.Type != models.NodeTypeAgent {
+		return fmt.Errorf("invalid node type: expected %s, got %s", models.NodeTypeAgent, unifiedNode.Type)
+	}
+	if config, ok := common.AsAgentConfig(unifiedNode.Config); !ok || config == nil {
+		return fmt.Errorf("invalid config type: expected AgentConfig")
+	}
+	return nil
+}
+
+// GenerateNode generates a Coze LLM node with the skills of the agent
+func (g *AgentNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNode, error) {
+	if err := g.ValidateNode(unifiedNode); err != nil {
+		return nil, err
+	}
+	return g.llm.GenerateNode(g.asLLMNode(unifiedNode))
+}
+
+// GenerateSchemaNode generates a Coze schema LLM node with the skills of the agent
+func (g *AgentNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeSchemaNode, error) {
+	if err := g.ValidateNode(unifiedNode); err != nil {
+		return nil, err
+	}
+	return g.llm.GenerateSchemaNode(g.asLLMNode(unifiedNode))
+}
+
+// asLLMNode returns a copy of the agent as an LLM node; the skills stay in the Coze source params
+func (g *AgentNodeGenerator) asLLMNode(unifiedNode *models.Node) *models.Node {
+	config, _ := common.AsAgentConfig(unifiedNode.Config)
+	node := *unifiedNode
+	node.Type = models.NodeTypeLLM
+	node.Config = models.LLMConfig{
+		Model:         config.Model,
+		Parameters:    config.Parameters,
+		Prompt:        config.Prompt,
+		IsInIteration: config.IsInIteration,
+		IterationID:   config.IterationID,
+	}
+	return &node
+}
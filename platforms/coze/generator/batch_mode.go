@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	"gopkg.in/yaml.v3"
+)
+
+// batchModeNode returns the body of an iteration parsed from a Coze node run in batch mode, placed
+// where the iteration is, with the batch settings and outputs of the Coze node. Iterations of other
+// sources, or whose body was edited, give nil.
+func batchModeNode(unifiedNode *models.Node) (*models.Node, map[string]interface{}) {
+	batchNode, _ := unifiedNode.PlatformConfig.Coze[common.BatchNodeKey].(map[string]interface{})
+	iterationConfig, ok := common.AsIterationConfig(unifiedNode.Config)
+	if batchNode == nil || !ok || iterationConfig == nil || len(iterationConfig.SubWorkflow.Nodes) != 1 {
+		return nil, nil
+	}
+	body := iterationConfig.SubWorkflow.Nodes[0]
+	body.ID = unifiedNode.ID
+	body.Title = unifiedNode.Title
+	body.Description = unifiedNode.Description
+	body.Position = unifiedNode.Position
+	return &body, batchNode
+}
+
+// generateBatchModeNode generates the body of an iteration parsed from a batch-mode Coze node as
+// that node, with its batch settings
+func (g *IterationNodeGenerator) generateBatchModeNode(body *models.Node, batchNode map[string]interface{}) (*CozeNode, error) {
+	generator, err := g.bodyGenerator(body)
+	if err != nil {
+		return nil, err
+	}
+	cozeNode, err := generator.GenerateNode(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate batch node %s: %w", body.ID, err)
+	}
+	cozeNode.Data.Inputs = withBatchSettings(cozeNode.Data.Inputs, remapBlockIDs(g.idGenerator, batchNode["batch"]))
+	cozeNode.Data.Outputs = batchNode["outputs"]
+	return cozeNode, nil
+}
+
+// generateBatchModeSchemaNode generates the schema node of a batch-mode Coze node
+func (g *IterationNodeGenerator) generateBatchModeSchemaNode(body *models.Node, batchNode map[string]interface{}) (*CozeSchemaNode, error) {
+	generator, err := g.bodyGenerator(body)
+	if err != nil {
+		return nil, err
+	}
+	schemaNode, err := generator.GenerateSchemaNode(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate batch schema node %s: %w", body.ID, err)
+	}
+	schemaNode.Data.Inputs = withBatchSettings(schemaNode.Data.Inputs, remapBlockIDs(g.idGenerator, batchNode["batch"]))
+	schemaNode.Data.Outputs = batchNode["outputs"]
+	return schemaNode, nil
+}
+
+// bodyGenerator returns the generator of a batch-mode node
+func (g *IterationNodeGenerator) bodyGenerator(body *models.Node) (CozeNodeGenerator, error) {
+	generator, err := g.nodeFactory.GetNodeGenerator(body.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get generator for batch node %s: %w", body.ID, err)
+	}
+	generator.SetIDGenerator(g.idGenerator)
+	return generator, nil
+}
+
+// withBatchSettings returns node inputs with the batch settings added, as a map whatever structure
+// the node generator used
+func withBatchSettings(inputs interface{}, batch interface{}) map[string]interface{} {
+	fields, ok := inputs.(map[string]interface{})
+	if !ok {
+		fields = make(map[string]interface{})
+		if data, err := yaml.Marshal(inputs); err == nil {
+			_ = yaml.Unmarshal(data, &fields)
+		}
+	}
+	fields["batch"] = batch
+	return fields
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// classifierDefaultPort is the port of the branch Coze classifiers take when no intent matches
+const classifierDefaultPort = "default"
+
+// isDefaultClass reports whether a class is the default branch, which is not a Coze intent
+func isDefaultClass(class models.ClassifierClass) bool {
+	return class.IsDefault || strings.EqualFold(class.Name, "default")
+}
+
+// classifierPort returns the Coze classifier port of a branch handle: "branch_N" for the intent at
+// index N of the generated intents and "default" for the default class. Handles are class IDs
+// (iFlytek intent IDs, Dify class IDs), or 1-based intent positions as Coze sources number them.
+// Handles without a class get an empty port.
+func classifierPort(config *models.ClassifierConfig, handle string) string {
+	if handle == "" {
+		return ""
+	}
+	if handle == classifierDefaultPort {
+		return classifierDefaultPort
+	}
+
+	intents := 0
+	for _, class := range config.Classes {
+		if isDefaultClass(class) {
+			if class.ID == handle {
+				return classifierDefaultPort
+			}
+			continue
+		}
+		if class.ID == handle {
+			return fmt.Sprintf("branch_%d", intents)
+		}
+		intents++
+	}
+
+	if position, err := strconv.Atoi(handle); err == nil && position >= 1 && position <= intents {
+		return fmt.Sprintf("branch_%d", position-1)
+	}
+	return ""
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
-	"strings"
 )
 
 // ClassifierNodeGenerator generates Coze Intent Recognition nodes from unified DSL classifier configurations.
@@ -85,7 +84,7 @@ func (g *ClassifierNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeN
 		"inputParameters":    inputParams,
 		"intents":            intents,
 		"llmParam":           llmParam,
-		"mode":               "all", // Default mode for intent recognition
+		"mode":               g.intentMode(classifierConfig),
 		"settingOnError":     errorSettings,
 	}
 
@@ -105,7 +104,7 @@ func (g *ClassifierNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeN
 		Data: &CozeNodeData{
 			Meta: &CozeNodeMetaInfo{
 				Title:       unifiedNode.Title,
-				Description: "用于用户输入的意图识别，并将其与预设意图选项进行匹配。",
+				Description: g.getNodeDescription(unifiedNode),
 				Icon:        "https://lf3-static.bytednsdoc.com/obj/eden-cn/dvsmryvd_avi_dvsm/ljhwZthlaukjlkulzlp/icon/icon-Intent-v2.jpg",
 				SubTitle:    "意图识别",
 				MainColor:   "#00B2B2",
@@ -129,8 +128,8 @@ func (g *ClassifierNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (
 	}
 
 	// Parse classifier configuration with detailed error reporting
-	classifierConfig, ok := unifiedNode.Config.(*models.ClassifierConfig)
-	if !ok {
+	classifierConfig, ok := common.AsClassifierConfig(unifiedNode.Config)
+	if !ok || classifierConfig == nil {
 		return nil, fmt.Errorf("invalid classifier config type for schema node %s, got %T, expected *models.ClassifierConfig", unifiedNode.ID, unifiedNode.Config)
 	}
 
@@ -171,7 +170,7 @@ func (g *ClassifierNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (
 		"chatHistorySetting": schemaChatHistorySetting,
 		"intents":            schemaIntents,
 		"llmParam":           schemaLLMParam,
-		"mode":               "all",
+		"mode":               g.intentMode(classifierConfig),
 		"settingOnError":     schemaErrorSettings,
 	}
 
@@ -191,7 +190,7 @@ func (g *ClassifierNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (
 		Data: &CozeSchemaNodeData{
 			NodeMeta: &CozeNodeMetaInfo{
 				Title:       unifiedNode.Title,
-				Description: "用于用户输入的意图识别，并将其与预设意图选项进行匹配。",
+				Description: g.getNodeDescription(unifiedNode),
 				Icon:        "https://lf3-static.bytednsdoc.com/obj/eden-cn/dvsmryvd_avi_dvsm/ljhwZthlaukjlkulzlp/icon/icon-Intent-v2.jpg",
 				SubTitle:    "意图识别",
 				MainColor:   "#00B2B2",
@@ -230,25 +229,37 @@ func (g *ClassifierNodeGenerator) generateIntents(config *models.ClassifierConfi
 	intents := []map[string]interface{}{}
 
 	for _, class := range config.Classes {
-		// Skip default intent
-		if class.IsDefault || strings.EqualFold(class.Name, "default") {
+		// The default class is the default port, not an intent
+		if isDefaultClass(class) {
 			continue
 		}
 
 		intent := map[string]interface{}{
 			"name": class.Name,
 		}
+		if class.Description != "" && class.Description != class.Name {
+			intent["description"] = class.Description
+		}
 		intents = append(intents, intent)
 	}
 
 	return intents
 }
 
+// intentMode returns the Coze intent detection mode of a classifier.
+func (g *ClassifierNodeGenerator) intentMode(config *models.ClassifierConfig) string {
+	if config.Mode == models.ClassifierModeFast {
+		return "top_speed"
+	}
+	return "all"
+}
+
 // generateLLMParam converts classifier model configuration to Coze LLM parameters.
 func (g *ClassifierNodeGenerator) generateLLMParam(config *models.ClassifierConfig) map[string]interface{} {
+	enableChatHistory, chatHistoryRound := common.ChatHistory(config.Memory, 3)
 	return map[string]interface{}{
-		"chatHistoryRound":    3,         // Default value
-		"enableChatHistory":   false,     // Default value
+		"chatHistoryRound":    chatHistoryRound,
+		"enableChatHistory":   enableChatHistory,
 		"generationDiversity": "balance", // Default value
 		"maxTokens":           config.Parameters.MaxTokens,
 		"modelName":           config.Model.Name,
@@ -264,7 +275,7 @@ func (g *ClassifierNodeGenerator) generateLLMParam(config *models.ClassifierConf
 		"systemPrompt": map[string]interface{}{
 			"type": "string",
 			"value": map[string]interface{}{
-				"content": g.normalizeSystemPromptForCoze(config.Instructions),
+				"content": g.normalizeSystemPromptForCoze(common.ClassifierInstructions(config)),
 				"type":    "literal",
 			},
 		},
@@ -275,9 +286,10 @@ func (g *ClassifierNodeGenerator) generateLLMParam(config *models.ClassifierConf
 
 // generateChatHistorySetting creates chat history configuration for Coze classifier.
 func (g *ClassifierNodeGenerator) generateChatHistorySetting(config *models.ClassifierConfig) map[string]interface{} {
+	enableChatHistory, chatHistoryRound := common.ChatHistory(config.Memory, 3)
 	return map[string]interface{}{
-		"enableChatHistory": false, // Default value
-		"chatHistoryRound":  3,     // Default value
+		"enableChatHistory": enableChatHistory,
+		"chatHistoryRound":  chatHistoryRound,
 	}
 }
 
@@ -296,20 +308,15 @@ func (g *ClassifierNodeGenerator) generatePromptContent(config *models.Classifie
 	return "{{query}}"
 }
 
-// normalizeSystemPromptForCoze converts iFlytek systemPrompt format to Coze compatible format
-// Main conversion: {{Query}} to {{query}}
+// normalizeSystemPromptForCoze converts the instruction placeholders to {{query}}, the input of
+// Coze intent detection nodes: iFlytek {{Query}} as well as node references
 func (g *ClassifierNodeGenerator) normalizeSystemPromptForCoze(instructions string) string {
-	if instructions == "" {
-		return ""
-	}
-
-	// Convert iFlytek {{Query}} (uppercase) to Coze standard {{query}} (lowercase)
-	normalized := strings.ReplaceAll(instructions, "{{Query}}", "{{query}}")
-
-	// Handle other possible variants
-	normalized = strings.ReplaceAll(normalized, "{{QUERY}}", "{{query}}")
-
-	return normalized
+	return common.RewritePromptTemplate(instructions, func(p common.PromptPlaceholder) string {
+		if p.IsReference() || (p.Closed && (p.Name == "Query" || p.Name == "QUERY")) {
+			return "{{query}}"
+		}
+		return p.Text
+	})
 }
 
 // convertModelType maps unified model provider to Coze model type identifier.
@@ -434,3 +441,10 @@ func (g *ClassifierNodeGenerator) mapOutputFieldNameForCoze(nodeID, outputName s
 	// Default: return original name if no mapping needed
 	return outputName
 }
+
+func (g *ClassifierNodeGenerator) getNodeDescription(unifiedNode *models.Node) string {
+	if unifiedNode.Description != "" {
+		return unifiedNode.Description
+	}
+	return "用于用户输入的意图识别，并将其与预设意图选项进行匹配。"
+}
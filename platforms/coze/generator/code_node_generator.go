@@ -63,11 +63,15 @@ func (g *CodeNodeGenerator) ValidateNode(unifiedNode *models.Node) error {
 	return nil
 }
 
-// GenerateNode generates a Coze workflow code node
+// GenerateNode generates a Coze workflow code node. Placeholders of Coze nodes are generated as
+// the node they replaced.
 func (g *CodeNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNode, error) {
 	if err := g.ValidateNode(unifiedNode); err != nil {
 		return nil, err
 	}
+	if sourceNode := cozeSourceNode(unifiedNode); sourceNode != nil {
+		return generateSourceNode(g.idGenerator, unifiedNode, sourceNode), nil
+	}
 
 	cozeNodeID := g.idGenerator.MapToCozeNodeID(unifiedNode.ID)
 
@@ -87,7 +91,7 @@ func (g *CodeNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNode, e
 	inputParams := g.generateInputParameters(unifiedNode)
 
 	// Generate error handling settings
-	errorSettings := g.generateErrorSettings()
+	errorSettings := g.generateErrorSettings(unifiedNode)
 
 	// Map language to Coze language code
 	languageCode := g.mapLanguageToCozeCode(codeConfig.Language)
@@ -174,6 +178,9 @@ func (g *CodeNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeS
 	if err := g.ValidateNode(unifiedNode); err != nil {
 		return nil, err
 	}
+	if sourceNode := cozeSourceNode(unifiedNode); sourceNode != nil {
+		return generateSourceSchemaNode(g.idGenerator, unifiedNode, sourceNode), nil
+	}
 
 	cozeNodeID := g.idGenerator.MapToCozeNodeID(unifiedNode.ID)
 
@@ -181,7 +188,7 @@ func (g *CodeNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeS
 	schemaInputParams := g.generateSchemaInputParameters(unifiedNode)
 
 	// Generate error handling settings for schema
-	errorSettings := g.generateSchemaErrorSettings()
+	errorSettings := g.generateSchemaErrorSettings(unifiedNode)
 
 	// Extract code configuration
 	codeConfig, ok := common.AsCodeConfig(unifiedNode.Config)
@@ -406,28 +413,16 @@ func (g *CodeNodeGenerator) generateOutputs(unifiedNode *models.Node) []map[stri
 }
 
 // generateErrorSettings generates error handling settings
-func (g *CodeNodeGenerator) generateErrorSettings() map[string]interface{} {
-	if g.isInIteration {
-		// CRITICAL: For iteration context, use camelCase naming
-		return map[string]interface{}{
-			"dataonerr":   "",
-			"switch":      false,
-			"processType": 1,
-			"retryTimes":  0,
-			"timeoutMs":   60000, // 60 seconds timeout for code execution
-			"ext":         nil,
-		}
-	} else {
-		// For top-level context, use camelCase naming
-		return map[string]interface{}{
-			"dataonerr":   "",
-			"switch":      false,
-			"processType": 1,
-			"retryTimes":  0,
-			"timeoutMs":   60000, // 60 seconds timeout for code execution
-			"ext":         nil,
-		}
-	}
+func (g *CodeNodeGenerator) generateErrorSettings(unifiedNode *models.Node) map[string]interface{} {
+	// Iteration and top-level nodes share the camelCase format
+	return withErrorHandling(map[string]interface{}{
+		"dataonerr":   "",
+		"switch":      false,
+		"processType": 1,
+		"retryTimes":  0,
+		"timeoutMs":   60000, // 60 seconds timeout for code execution
+		"ext":         nil,
+	}, unifiedNode)
 }
 
 // mapOutputFieldNameForCoze maps output field names from unified DSL to Coze platform format
@@ -452,27 +447,28 @@ func (g *CodeNodeGenerator) generateNodeMeta(unifiedNode *models.Node) map[strin
 		title = "代码节点"
 	}
 
-	description := unifiedNode.Description
-	if description == "" {
-		description = "编写代码，处理输入变量来生成返回值"
-	}
-
 	return map[string]interface{}{
 		"title":       title,
-		"description": description,
+		"description": unifiedNode.Description,
 		"icon":        "https://lf3-static.bytednsdoc.com/obj/eden-cn/dvsmryvd_avi_dvsm/ljhwZthlaukjlkulzlp/icon/icon-Code-v2.jpg",
 		"subtitle":    "代码",
 		"maincolor":   "#00B2B2",
 	}
 }
 
-// mapLanguageToCozeCode maps unified language to Coze language code
+// mapLanguageToCozeCode maps unified language to Coze language code, the reverse of the parser
 func (g *CodeNodeGenerator) mapLanguageToCozeCode(language string) int {
 	switch language {
 	case "python3", "python":
 		return 3
 	case "javascript", "js":
 		return 1
+	case "python2":
+		return 2
+	case "java":
+		return 4
+	case "go":
+		return 5
 	default:
 		return 3 // Default to Python
 	}
@@ -490,7 +486,7 @@ func (g *CodeNodeGenerator) mapUnifiedTypeToCozeType(unifiedType models.UnifiedD
 	case models.DataTypeBoolean:
 		return "boolean"
 	case models.DataTypeArrayString, models.DataTypeArrayInteger, models.DataTypeArrayFloat,
-		models.DataTypeArrayNumber, models.DataTypeArrayBoolean, models.DataTypeArrayObject:
+		models.DataTypeArrayNumber, models.DataTypeArrayBoolean, models.DataTypeArrayObject, models.DataTypeArrayFile:
 		return "list" // Coze uses "list" for all array types
 	case models.DataTypeObject:
 		return "object"
@@ -509,6 +505,9 @@ func (g *CodeNodeGenerator) convertToCozeNodeOutputs(outputs []map[string]interf
 			Type:     output["type"].(string),
 			Required: false,
 		}
+		if schema, ok := output["schema"].(map[string]interface{}); ok {
+			cozeOutput.Schema = &CozeOutputSchema{Type: schema["type"].(string)}
+		}
 		cozeOutputs = append(cozeOutputs, cozeOutput)
 	}
 
@@ -527,7 +526,7 @@ func (g *CodeNodeGenerator) getCozeTypeCode(unifiedType models.UnifiedDataType)
 	case models.DataTypeFloat, models.DataTypeNumber:
 		return 4
 	case models.DataTypeArrayString, models.DataTypeArrayInteger, models.DataTypeArrayFloat,
-		models.DataTypeArrayNumber, models.DataTypeArrayBoolean, models.DataTypeArrayObject:
+		models.DataTypeArrayNumber, models.DataTypeArrayBoolean, models.DataTypeArrayObject, models.DataTypeArrayFile:
 		return 5 // All array types use code 5
 	case models.DataTypeObject:
 		return 6
@@ -574,12 +573,12 @@ func (g *CodeNodeGenerator) generateSchemaInputParameters(unifiedNode *models.No
 }
 
 // generateSchemaErrorSettings generates error handling settings for schema
-func (g *CodeNodeGenerator) generateSchemaErrorSettings() map[string]interface{} {
-	return map[string]interface{}{
+func (g *CodeNodeGenerator) generateSchemaErrorSettings(unifiedNode *models.Node) map[string]interface{} {
+	return withErrorHandling(map[string]interface{}{
 		"processType": 1,
 		"retryTimes":  0,
 		"timeoutMs":   60000,
-	}
+	}, unifiedNode)
 }
 
 // generateSchemaOutputs generates schema outputs
@@ -618,12 +617,9 @@ func (g *CodeNodeGenerator) getNodeTitle(unifiedNode *models.Node) string {
 	return "代码节点"
 }
 
-// getNodeDescription returns node description
+// getNodeDescription returns node description; Coze nodes may have none
 func (g *CodeNodeGenerator) getNodeDescription(unifiedNode *models.Node) string {
-	if unifiedNode.Description != "" {
-		return unifiedNode.Description
-	}
-	return "面向开发者提供代码开发能力，目前仅支持python语言，允许使用该节点已定义的变量作为参数传入，返回语句用于输出函数的结果"
+	return unifiedNode.Description
 }
 
 // getNodeIcon returns code node icon
@@ -694,9 +690,7 @@ func (g *CodeNodeGenerator) convertPythonCodeToCozeFormat(originalCode string, i
 
 // isArrayType checks if the unified type is an array type
 func (g *CodeNodeGenerator) isArrayType(unifiedType models.UnifiedDataType) bool {
-	return unifiedType == models.DataTypeArrayString || unifiedType == models.DataTypeArrayInteger ||
-		unifiedType == models.DataTypeArrayFloat || unifiedType == models.DataTypeArrayNumber ||
-		unifiedType == models.DataTypeArrayBoolean || unifiedType == models.DataTypeArrayObject
+	return models.IsArrayType(unifiedType)
 }
 
 // getArrayElementType returns the element type for array types
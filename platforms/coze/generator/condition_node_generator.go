@@ -43,6 +43,10 @@ func (g *ConditionNodeGenerator) ValidateNode(unifiedNode *models.Node) error {
 		return fmt.Errorf("condition node must have at least one case")
 	}
 
+	if _, err := common.ConditionOperatorIssues(*unifiedNode, models.PlatformCoze); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -60,7 +64,7 @@ func (g *ConditionNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNo
 	nodeData := &CozeNodeData{
 		Meta: &CozeNodeMetaInfo{
 			Title:       unifiedNode.Title,
-			Description: "连接多个下游分支，若设定的条件成立则仅运行对应的分支，若均不成立则只运行\"否则\"分支",
+			Description: g.getNodeDescription(unifiedNode),
 			Icon:        "https://lf3-static.bytednsdoc.com/obj/eden-cn/dvsmryvd_avi_dvsm/ljhwZthlaukjlkulzlp/icon/icon-Condition-v2.jpg",
 			Subtitle:    "选择器",
 			MainColor:   "#00B2B2",
@@ -110,7 +114,7 @@ func (g *ConditionNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*
 		Data: &CozeSchemaNodeData{
 			NodeMeta: &CozeNodeMetaInfo{
 				Title:       unifiedNode.Title,
-				Description: "连接多个下游分支，若设定的条件成立则仅运行对应的分支，若均不成立则只运行\"否则\"分支",
+				Description: g.getNodeDescription(unifiedNode),
 				Icon:        "https://lf3-static.bytednsdoc.com/obj/eden-cn/dvsmryvd_avi_dvsm/ljhwZthlaukjlkulzlp/icon/icon-Condition-v2.jpg",
 				SubTitle:    "选择器",
 				MainColor:   "#00B2B2",
@@ -132,14 +136,9 @@ func (g *ConditionNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*
 func (g *ConditionNodeGenerator) generateConditionInputs(unifiedNode *models.Node) map[string]interface{} {
 	conditionConfig, _ := common.AsConditionConfig(unifiedNode.Config)
 
-	// Generate selector branches, excluding empty condition branches (default cases with level=999)
+	// Generate selector branches in port order; the default case is the else branch
 	branches := make([]map[string]interface{}, 0)
-	for _, caseItem := range conditionConfig.Cases {
-		// Skip empty condition branches (typically default cases with level=999)
-		if len(caseItem.Conditions) == 0 {
-			continue
-		}
-
+	for _, caseItem := range selectorBranchCases(conditionConfig) {
 		branch := g.generateSelectorBranch(caseItem, unifiedNode)
 		branches = append(branches, branch)
 	}
@@ -178,13 +177,8 @@ func (g *ConditionNodeGenerator) generateBranches(unifiedNode *models.Node) ([]m
 	conditionConfig, _ := common.AsConditionConfig(unifiedNode.Config)
 	branches := make([]map[string]interface{}, 0)
 
-	// Add condition branches, excluding empty condition branches (default cases with level=999)
-	for _, caseItem := range conditionConfig.Cases {
-		// Skip empty condition branches (typically default cases with level=999)
-		if len(caseItem.Conditions) == 0 {
-			continue
-		}
-
+	// Add condition branches in port order; the default case is the else branch
+	for _, caseItem := range selectorBranchCases(conditionConfig) {
 		branch, err := g.GenerateSchemaBranch(caseItem, unifiedNode)
 		if err != nil {
 			return nil, err
@@ -292,55 +286,17 @@ func (g *ConditionNodeGenerator) generateSchemaCondition(condition models.Condit
 
 // mapLogicalOperator maps unified logical operator to Coze logic type
 func (g *ConditionNodeGenerator) mapLogicalOperator(logicalOperator string) int {
-	switch logicalOperator {
-	case "or":
+	if models.NormalizeLogicalOperator(logicalOperator) == models.LogicalOr {
 		return 1
-	case "and":
-		return 2
-	default:
-		return 2 // Default to AND
 	}
+	return 2
 }
 
-// mapComparisonOperator maps unified comparison operator to Coze operator type
-// Based on Coze source code ConditionType enum:
+// mapComparisonOperator maps unified comparison operators to Coze ConditionType numbers.
+// Unsupported operators are rejected by ValidateNode.
 func (g *ConditionNodeGenerator) mapComparisonOperator(compareOperator string) int {
-	switch compareOperator {
-	case "is", "equals":
-		return 1 // Equal
-	case "is_not", "not_equals":
-		return 2 // NotEqual
-	case "length_gt":
-		return 3 // LengthGt
-	case "length_gte":
-		return 4 // LengthGtEqual
-	case "length_lt":
-		return 5 // LengthLt
-	case "length_lte":
-		return 6 // LengthLtEqual
-	case "contains", "start_with":
-		return 7 // Contains (iFlytek start_with maps to Coze Contains)
-	case "not_contains":
-		return 8 // NotContains
-	case "empty", "is_empty":
-		return 9 // Null
-	case "not_empty", "is_not_empty":
-		return 10 // NotNull (fixed: unified DSL uses is_not_empty)
-	case "true":
-		return 11 // True
-	case "false":
-		return 12 // False
-	case "gt", ">", "greater_than":
-		return 13 // Gt
-	case "ge", ">=", "greater_equal":
-		return 14 // GtEqual
-	case "lt", "<", "less_than":
-		return 15 // Lt
-	case "le", "<=", "less_equal":
-		return 16 // LtEqual
-	default:
-		return 1 // Default to Equal
-	}
+	conditionType, _ := common.CozeConditionType(compareOperator)
+	return conditionType
 }
 
 // generateVariableReference generates variable reference for nodes section
@@ -487,3 +443,10 @@ func (g *ConditionNodeGenerator) mapUnifiedTypeToCozeRawMetaType(dataType models
 		return 1
 	}
 }
+
+func (g *ConditionNodeGenerator) getNodeDescription(unifiedNode *models.Node) string {
+	if unifiedNode.Description != "" {
+		return unifiedNode.Description
+	}
+	return "连接多个下游分支，若设定的条件成立则仅运行对应的分支，若均不成立则只运行\"否则\"分支"
+}
@@ -1,12 +1,14 @@
 package generator
 
 import (
+	"context"
 	"fmt"
 	"github.com/iflytek/agentbridge/core/interfaces"
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -14,10 +16,26 @@ import (
 
 // Interface compliance check at compile time
 var _ interfaces.DSLGenerator = (*CozeGenerator)(nil)
-
-// CozeGenerator implements DSL generation for ByteDance Coze workflow platform
+var _ interfaces.MappingProvider = (*CozeGenerator)(nil)
+var _ interfaces.ConfigurableGenerator = (*CozeGenerator)(nil)
+var _ interfaces.MappingGenerator = (*CozeGenerator)(nil)
+var _ interfaces.ContextGenerator = (*CozeGenerator)(nil)
+
+// CozeGenerator implements DSL generation for ByteDance Coze workflow platform. It holds the
+// configuration only; every generation runs on its own cozeGeneration, so once configured an
+// instance can be reused and shared by goroutines. Configure must not be called while generations are running.
 type CozeGenerator struct {
 	*common.BaseGenerator
+	previousIDs  map[string]string // unified ID -> Coze ID of an earlier conversion
+	outputFormat string            // models.OutputFormatYAML or models.OutputFormatJSON
+
+	mu          sync.Mutex
+	lastMapping *models.IDMapping // ID mapping of the last finished generation
+}
+
+// cozeGeneration carries the state of a single conversion
+type cozeGeneration struct {
+	ctx                  context.Context // Checked between passes and nodes
 	nodeGeneratorFactory *NodeGeneratorFactory
 	edgeGenerator        *EdgeGenerator
 	idGenerator          *CozeIDGenerator
@@ -25,24 +43,104 @@ type CozeGenerator struct {
 
 // NewCozeGenerator creates a Coze DSL generator
 func NewCozeGenerator() *CozeGenerator {
+	return &CozeGenerator{
+		BaseGenerator: common.NewBaseGenerator(models.PlatformCoze),
+		lastMapping:   models.NewIDMapping(),
+	}
+}
+
+func newCozeGeneration(ctx context.Context, previousIDs map[string]string) *cozeGeneration {
 	idGenerator := NewCozeIDGenerator()
+	idGenerator.SetPreviousIDs(previousIDs)
 	edgeGenerator := NewEdgeGenerator()
 	edgeGenerator.idGenerator = idGenerator // Share the same ID generator instance
 
-	return &CozeGenerator{
-		BaseGenerator:        common.NewBaseGenerator(models.PlatformCoze),
+	return &cozeGeneration{
+		ctx:                  ctx,
 		nodeGeneratorFactory: NewNodeGeneratorFactory(),
 		edgeGenerator:        edgeGenerator,
 		idGenerator:          idGenerator,
 	}
 }
 
+// Configure applies conversion options to the generator
+func (g *CozeGenerator) Configure(options *models.ConversionOptions) error {
+	if options == nil {
+		return nil
+	}
+
+	g.previousIDs = nil
+	if options.PreviousMapping != nil {
+		g.previousIDs = options.PreviousMapping.Nodes
+	}
+	g.outputFormat = options.OutputFormat
+	return nil
+}
+
+// GetNodeIDMapping returns the source node ID -> Coze node ID mapping of the last generation
+func (g *CozeGenerator) GetNodeIDMapping() map[string]string {
+	return g.GetIDMapping().Nodes
+}
+
+// GetIDMapping returns the node, branch and intent ID mappings of the last generation.
+// Concurrent callers should use GenerateWithMapping instead.
+func (g *CozeGenerator) GetIDMapping() *models.IDMapping {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lastMapping.Clone()
+}
+
 // Generate generates Coze DSL from unified DSL
 func (g *CozeGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, error) {
+	data, _, err := g.GenerateWithMapping(unifiedDSL)
+	return data, err
+}
+
+// GenerateWithMapping generates Coze DSL and returns the ID mapping of this generation
+func (g *CozeGenerator) GenerateWithMapping(unifiedDSL *models.UnifiedDSL) ([]byte, *models.IDMapping, error) {
+	return g.GenerateWithContext(context.Background(), unifiedDSL)
+}
+
+// GenerateWithContext generates Coze DSL with its ID mapping, giving up once ctx is done
+func (g *CozeGenerator) GenerateWithContext(ctx context.Context, unifiedDSL *models.UnifiedDSL) ([]byte, *models.IDMapping, error) {
 	// Validate input
 	if err := g.Validate(unifiedDSL); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	generation := newCozeGeneration(ctx, g.previousIDs)
+	data, err := generation.generate(unifiedDSL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if data, err = common.EncodeOutput(data, g.outputFormat); err != nil {
+		return nil, nil, err
+	}
+
+	mapping := generation.buildIDMapping()
+	g.mu.Lock()
+	g.lastMapping = mapping.Clone()
+	g.mu.Unlock()
+
+	return data, mapping, nil
+}
+
+// buildIDMapping returns the node, branch and intent ID mappings of the generation.
+// Coze references outputs by name, so no output IDs are reported.
+func (g *cozeGeneration) buildIDMapping() *models.IDMapping {
+	mapping := models.NewIDMapping()
+	mapping.Nodes = common.CopyIDMapping(g.idGenerator.nodeIDMapping)
+	if handles := g.edgeGenerator.handleIDMapping; handles != nil {
+		mapping.Branches = handles.Branches
+		mapping.Intents = handles.Intents
 	}
+	return mapping
+}
+
+// generate builds the Coze DSL of a validated unified DSL
+func (g *cozeGeneration) generate(unifiedDSL *models.UnifiedDSL) ([]byte, error) {
+	// Iteration bodies are blocks of their iteration node
+	unifiedDSL = common.NestIterationBodies(unifiedDSL)
 
 	// Set unified DSL reference for edge generator context
 	g.edgeGenerator.SetUnifiedDSL(unifiedDSL)
@@ -61,11 +159,17 @@ func (g *CozeGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, error)
 	}
 
 	// Generate nodes
+	if err := g.ctx.Err(); err != nil {
+		return nil, err
+	}
 	if err := g.generateNodes(unifiedDSL, cozeDSL); err != nil {
 		return nil, fmt.Errorf("failed to generate nodes: %w", err)
 	}
 
 	// Generate edges
+	if err := g.ctx.Err(); err != nil {
+		return nil, err
+	}
 	if err := g.generateEdges(unifiedDSL, cozeDSL); err != nil {
 		return nil, fmt.Errorf("failed to generate edges: %w", err)
 	}
@@ -78,7 +182,7 @@ func (g *CozeGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, error)
 }
 
 // generateWorkflowMetadata generates workflow-level metadata
-func (g *CozeGenerator) generateWorkflowMetadata(unifiedDSL *models.UnifiedDSL, cozeDSL *CozeRootStructure) error {
+func (g *cozeGeneration) generateWorkflowMetadata(unifiedDSL *models.UnifiedDSL, cozeDSL *CozeRootStructure) error {
 	// Generate workflow ID (use timestamp-based approach similar to example)
 	cozeDSL.WorkflowID = g.idGenerator.GenerateWorkflowID()
 
@@ -89,12 +193,14 @@ func (g *CozeGenerator) generateWorkflowMetadata(unifiedDSL *models.UnifiedDSL,
 	}
 
 	cozeDSL.Description = unifiedDSL.Metadata.Description
-	cozeDSL.Version = ""
+	cozeDSL.Version = unifiedDSL.Metadata.Version
+	cozeDSL.Tags = unifiedDSL.Metadata.Tags
+	cozeDSL.Author = unifiedDSL.Metadata.Author
 
-	// Set timestamps
-	now := time.Now().Unix()
-	cozeDSL.CreateTime = now
-	cozeDSL.UpdateTime = now
+	// Keep the source timestamps; sources without them are stamped with the conversion time
+	now := time.Now()
+	cozeDSL.CreateTime = unixOrNow(unifiedDSL.Metadata.CreatedAt, now)
+	cozeDSL.UpdateTime = unixOrNow(unifiedDSL.Metadata.UpdatedAt, now)
 
 	// Set export format
 	cozeDSL.ExportFormat = "yml"
@@ -104,7 +210,7 @@ func (g *CozeGenerator) generateWorkflowMetadata(unifiedDSL *models.UnifiedDSL,
 }
 
 // generateSchema generates the schema section
-func (g *CozeGenerator) generateSchema(unifiedDSL *models.UnifiedDSL, cozeDSL *CozeRootStructure) error {
+func (g *cozeGeneration) generateSchema(unifiedDSL *models.UnifiedDSL, cozeDSL *CozeRootStructure) error {
 	schema := &CozeSchema{
 		Edges: make([]CozeSchemaEdge, 0),
 		Nodes: make([]CozeSchemaNode, 0),
@@ -122,8 +228,8 @@ func (g *CozeGenerator) generateSchema(unifiedDSL *models.UnifiedDSL, cozeDSL *C
 		targetNode := g.findNodeByID(edge.Target, unifiedDSL)
 
 		if sourceNode != nil && sourceNode.Type == models.NodeTypeIteration {
-			// Iteration to other nodes must use loop-output port
-			cozeEdge.SourcePortID = "loop-output"
+			// Iteration to other nodes must use the loop-output or batch-output port
+			cozeEdge.SourcePortID = iterationOutputPort(sourceNode)
 			// Clear targetPortID as it should not be used
 			cozeEdge.TargetPortID = ""
 		}
@@ -137,7 +243,11 @@ func (g *CozeGenerator) generateSchema(unifiedDSL *models.UnifiedDSL, cozeDSL *C
 	}
 
 	// Generate schema nodes (simplified version)
-	for _, node := range unifiedDSL.Workflow.Nodes {
+	for i, node := range unifiedDSL.Workflow.Nodes {
+		if err := g.ctx.Err(); err != nil {
+			return err
+		}
+		models.ReportNodeProgress(g.ctx, models.ProgressStageGenerate, i, len(unifiedDSL.Workflow.Nodes), node.ID, node.Title)
 		generator, err := g.nodeGeneratorFactory.GetNodeGenerator(node.Type)
 		if err != nil {
 			// Skip unsupported node types, continue processing other nodes
@@ -168,7 +278,7 @@ func (g *CozeGenerator) generateSchema(unifiedDSL *models.UnifiedDSL, cozeDSL *C
 }
 
 // generateNodes generates workflow nodes
-func (g *CozeGenerator) generateNodes(unifiedDSL *models.UnifiedDSL, cozeDSL *CozeRootStructure) error {
+func (g *cozeGeneration) generateNodes(unifiedDSL *models.UnifiedDSL, cozeDSL *CozeRootStructure) error {
 	nodes := make([]CozeNode, 0)
 
 	for _, node := range unifiedDSL.Workflow.Nodes {
@@ -194,15 +304,26 @@ func (g *CozeGenerator) generateNodes(unifiedDSL *models.UnifiedDSL, cozeDSL *Co
 			return fmt.Errorf("failed to generate node %s (type: %s): %w", node.ID, node.Type, err)
 		}
 
+		restoreUnknownFields(cozeNode, &node)
 		nodes = append(nodes, *cozeNode)
 	}
+	nodes = append(nodes, g.generateTriggerNodes(unifiedDSL)...)
 
 	cozeDSL.Nodes = nodes
 	return nil
 }
 
+// restoreUnknownFields emits the source keys the parser did not model
+func restoreUnknownFields(cozeNode *CozeNode, node *models.Node) {
+	var dataFields map[string]interface{}
+	cozeNode.Unknown, dataFields = common.RestoreUnknownFields(node.PlatformConfig.Coze, cozeNode.Type, CozeNode{}, CozeNodeData{})
+	if cozeNode.Data != nil {
+		cozeNode.Data.Unknown = dataFields
+	}
+}
+
 // generateEdges generates workflow edges
-func (g *CozeGenerator) generateEdges(unifiedDSL *models.UnifiedDSL, cozeDSL *CozeRootStructure) error {
+func (g *cozeGeneration) generateEdges(unifiedDSL *models.UnifiedDSL, cozeDSL *CozeRootStructure) error {
 	edges := make([]CozeEdge, 0)
 
 	for _, edge := range unifiedDSL.Workflow.Edges {
@@ -216,13 +337,13 @@ func (g *CozeGenerator) generateEdges(unifiedDSL *models.UnifiedDSL, cozeDSL *Co
 }
 
 // addIterationPortsIfNeeded adds special loop ports for iteration nodes and fixes end node connections
-func (g *CozeGenerator) addIterationPortsIfNeeded(cozeEdge *CozeEdge, originalEdge *models.Edge, unifiedDSL *models.UnifiedDSL) {
+func (g *cozeGeneration) addIterationPortsIfNeeded(cozeEdge *CozeEdge, originalEdge *models.Edge, unifiedDSL *models.UnifiedDSL) {
 	sourceNode := g.findNodeByID(originalEdge.Source, unifiedDSL)
 	targetNode := g.findNodeByID(originalEdge.Target, unifiedDSL)
 
 	// Handle iteration node outputs
 	if sourceNode != nil && sourceNode.Type == models.NodeTypeIteration {
-		cozeEdge.FromPort = "loop-output"
+		cozeEdge.FromPort = iterationOutputPort(sourceNode)
 	}
 
 	// CRITICAL: All connections to end nodes must have empty target ports in Coze format
@@ -232,7 +353,7 @@ func (g *CozeGenerator) addIterationPortsIfNeeded(cozeEdge *CozeEdge, originalEd
 }
 
 // findNodeByID finds a node by its ID
-func (g *CozeGenerator) findNodeByID(nodeID string, unifiedDSL *models.UnifiedDSL) *models.Node {
+func (g *cozeGeneration) findNodeByID(nodeID string, unifiedDSL *models.UnifiedDSL) *models.Node {
 	for _, node := range unifiedDSL.Workflow.Nodes {
 		if node.ID == nodeID {
 			return &node
@@ -242,7 +363,7 @@ func (g *CozeGenerator) findNodeByID(nodeID string, unifiedDSL *models.UnifiedDS
 }
 
 // generateMetadataAndDependencies generates metadata and dependencies sections
-func (g *CozeGenerator) generateMetadataAndDependencies(unifiedDSL *models.UnifiedDSL, cozeDSL *CozeRootStructure) {
+func (g *cozeGeneration) generateMetadataAndDependencies(unifiedDSL *models.UnifiedDSL, cozeDSL *CozeRootStructure) {
 	// Generate metadata
 	cozeDSL.Metadata = &CozeMetadata{
 		ContentType: "0",
@@ -327,6 +448,8 @@ type CozeIDGenerator struct {
 	nodeIDCounter      int
 	nodeIDMapping      map[string]string // unified ID -> coze ID
 	currentIterationID string            // Current iteration node ID being processed
+	previousIDs        map[string]string // unified ID -> coze ID of an earlier conversion, reused when possible
+	reservedIDs        map[string]bool   // Coze IDs of the earlier conversion, skipped by the counter
 }
 
 // NewCozeIDGenerator creates a Coze ID generator
@@ -337,6 +460,40 @@ func NewCozeIDGenerator() *CozeIDGenerator {
 	}
 }
 
+// SetPreviousIDs makes the generator hand out the Coze IDs of an earlier conversion to the same unified nodes
+func (g *CozeIDGenerator) SetPreviousIDs(previousIDs map[string]string) {
+	g.previousIDs = previousIDs
+	g.reservedIDs = make(map[string]bool, len(previousIDs))
+	for _, cozeID := range previousIDs {
+		g.reservedIDs[cozeID] = true
+	}
+}
+
+// reusePreviousID returns the earlier Coze ID of a unified node when it is still free
+func (g *CozeIDGenerator) reusePreviousID(unifiedID string) (string, bool) {
+	cozeID, exists := g.previousIDs[unifiedID]
+	if !exists || cozeID == "" {
+		return "", false
+	}
+	for _, assignedID := range g.nodeIDMapping {
+		if assignedID == cozeID {
+			return "", false
+		}
+	}
+	g.nodeIDMapping[unifiedID] = cozeID
+	return cozeID, true
+}
+
+// nextNodeID returns the next incremental Coze node ID, skipping IDs reserved by an earlier conversion
+func (g *CozeIDGenerator) nextNodeID() string {
+	for g.reservedIDs[strconv.Itoa(g.nodeIDCounter)] {
+		g.nodeIDCounter++
+	}
+	cozeID := strconv.Itoa(g.nodeIDCounter)
+	g.nodeIDCounter++
+	return cozeID
+}
+
 // SetCurrentIterationNodeID sets the current iteration node ID for edge generation
 func (g *CozeIDGenerator) SetCurrentIterationNodeID(nodeID string) {
 	g.currentIterationID = nodeID
@@ -373,6 +530,10 @@ func (g *CozeIDGenerator) MapToCozeNodeID(unifiedID string) string {
 		return g.currentIterationID
 	}
 
+	if cozeID, reused := g.reusePreviousID(unifiedID); reused {
+		return cozeID
+	}
+
 	// Extract node type information from unified ID (if possible)
 	nodeType := g.extractNodeTypeFromID(unifiedID)
 
@@ -385,12 +546,10 @@ func (g *CozeIDGenerator) MapToCozeNodeID(unifiedID string) string {
 		cozeID = "900001" // End node uses fixed ID
 	case "llm":
 		// LLM nodes should get incremental IDs, starting from 197161 (based on example file)
-		cozeID = strconv.Itoa(g.nodeIDCounter)
-		g.nodeIDCounter++
+		cozeID = g.nextNodeID()
 	default:
 		// Other node types use incremental ID
-		cozeID = strconv.Itoa(g.nodeIDCounter)
-		g.nodeIDCounter++
+		cozeID = g.nextNodeID()
 	}
 
 	g.nodeIDMapping[unifiedID] = cozeID
@@ -432,6 +591,10 @@ func (g *CozeIDGenerator) MapToCozeNodeIDByType(unifiedID string, nodeType model
 		return cozeID
 	}
 
+	if cozeID, reused := g.reusePreviousID(unifiedID); reused {
+		return cozeID
+	}
+
 	var cozeID string
 	switch nodeType {
 	case models.NodeTypeStart:
@@ -439,25 +602,27 @@ func (g *CozeIDGenerator) MapToCozeNodeIDByType(unifiedID string, nodeType model
 	case models.NodeTypeEnd:
 		cozeID = "900001"
 	case models.NodeTypeLLM:
-		cozeID = strconv.Itoa(g.nodeIDCounter)
-		g.nodeIDCounter++
+		cozeID = g.nextNodeID()
 	case models.NodeTypeCode:
-		cozeID = strconv.Itoa(g.nodeIDCounter)
-		g.nodeIDCounter++
+		cozeID = g.nextNodeID()
 	case models.NodeTypeCondition:
-		cozeID = strconv.Itoa(g.nodeIDCounter)
-		g.nodeIDCounter++
+		cozeID = g.nextNodeID()
 	case models.NodeTypeClassifier:
-		cozeID = strconv.Itoa(g.nodeIDCounter)
-		g.nodeIDCounter++
+		cozeID = g.nextNodeID()
 	case models.NodeTypeIteration:
-		cozeID = strconv.Itoa(g.nodeIDCounter)
-		g.nodeIDCounter++
+		cozeID = g.nextNodeID()
 	default:
-		cozeID = strconv.Itoa(g.nodeIDCounter)
-		g.nodeIDCounter++
+		cozeID = g.nextNodeID()
 	}
 
 	g.nodeIDMapping[unifiedID] = cozeID
 	return cozeID
 }
+
+// unixOrNow returns the Unix seconds of t, or of now when t is unset
+func unixOrNow(t, now time.Time) int64 {
+	if t.IsZero() || t.Unix() <= 0 {
+		return now.Unix()
+	}
+	return t.Unix()
+}
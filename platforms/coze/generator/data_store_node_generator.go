@@ -0,0 +1,191 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// dataStoreNodeTypes maps data store operations to Coze database and variable node types
+var dataStoreNodeTypes = map[string]ioNodeMeta{
+	models.DataStoreOperationRead:   {Type: "11", Subtitle: "变量", MainColor: "#F2B600"},
+	models.DataStoreOperationWrite:  {Type: "11", Subtitle: "变量", MainColor: "#F2B600"},
+	models.DataStoreOperationSQL:    {Type: "12", Subtitle: "SQL 自定义", MainColor: "#F2B600"},
+	models.DataStoreOperationUpdate: {Type: "42", Subtitle: "更新数据", MainColor: "#F2B600"},
+	models.DataStoreOperationQuery:  {Type: "43", Subtitle: "查询数据", MainColor: "#F2B600"},
+	models.DataStoreOperationDelete: {Type: "44", Subtitle: "删除数据", MainColor: "#F2B600"},
+	models.DataStoreOperationInsert: {Type: "46", Subtitle: "新增数据", MainColor: "#F2B600"},
+}
+
+// DataStoreNodeGenerator generates Coze database and variable nodes
+type DataStoreNodeGenerator struct {
+	idGenerator *CozeIDGenerator
+}
+
+// NewDataStoreNodeGenerator creates a data store node generator
+func NewDataStoreNodeGenerator() *DataStoreNodeGenerator {
+	return &DataStoreNodeGenerator{}
+}
+
+// SetIDGenerator sets the shared ID generator
+func (g *DataStoreNodeGenerator) SetIDGenerator(idGenerator *CozeIDGenerator) {
+	g.idGenerator = idGenerator
+}
+
+// GetNodeType returns the node type this generator handles
+func (g *DataStoreNodeGenerator) GetNodeType() models.NodeType {
+	return models.NodeTypeDataStore
+}
+
+// ValidateNode validates the unified node before generation
+func (g *DataStoreNodeGenerator) ValidateNode(unifiedNode *models.Node) error {
+	if unifiedNode == nil {
+		return fmt.Errorf("unified node is nil")
+	}
+	if unifiedNode.Type != models.NodeTypeDataStore {
+		return fmt.Errorf("invalid node type: expected %s, got %s", models.NodeTypeDataStore, unifiedNode.Type)
+	}
+	config, ok := common.AsDataStoreConfig(unifiedNode.Config)
+	if !ok || config == nil {
+		return fmt.Errorf("invalid config type: expected DataStoreConfig")
+	}
+	if _, ok := dataStoreNodeTypes[config.Operation]; !ok {
+		return fmt.Errorf("unsupported data store operation: %s", config.Operation)
+	}
+	return nil
+}
+
+// GenerateNode generates a Coze database or variable node
+func (g *DataStoreNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNode, error) {
+	if err := g.ValidateNode(unifiedNode); err != nil {
+		return nil, err
+	}
+	config, _ := common.AsDataStoreConfig(unifiedNode.Config)
+	inputs := map[string]interface{}{}
+	if config.Store == models.DataStoreDatabase {
+		inputs["databasenode"] = g.generateDatabaseNode(config)
+	}
+	return generateIONode(g.idGenerator, unifiedNode, dataStoreNodeTypes[config.Operation], g.parameters(unifiedNode, config), inputs), nil
+}
+
+// GenerateSchemaNode generates a Coze schema database or variable node, whose settings sit
+// directly in the inputs
+func (g *DataStoreNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeSchemaNode, error) {
+	if err := g.ValidateNode(unifiedNode); err != nil {
+		return nil, err
+	}
+	config, _ := common.AsDataStoreConfig(unifiedNode.Config)
+	inputs := map[string]interface{}{}
+	if config.Store == models.DataStoreDatabase {
+		inputs = g.generateDatabaseNode(config)
+	}
+	return generateIOSchemaNode(g.idGenerator, unifiedNode, dataStoreNodeTypes[config.Operation], g.parameters(unifiedNode, config), inputs), nil
+}
+
+// parameters returns the input parameters of the node. Field values and condition values that
+// reference node outputs are also node inputs, but Coze keeps them in the database settings only.
+func (g *DataStoreNodeGenerator) parameters(unifiedNode *models.Node, config *models.DataStoreConfig) []models.Input {
+	if config.Store != models.DataStoreDatabase {
+		return unifiedNode.Inputs
+	}
+
+	settings := make(map[string]bool)
+	addSetting := func(field string, ref *models.VariableReference) {
+		if ref != nil && ref.Type == models.ReferenceTypeNodeOutput {
+			settings[field+"\x00"+ref.NodeID+"\x00"+ref.OutputName] = true
+		}
+	}
+	for _, field := range config.Fields {
+		addSetting(field.Name, field.Value)
+	}
+	for _, condition := range config.Conditions {
+		addSetting(condition.Field, condition.Value)
+	}
+
+	var parameters []models.Input
+	for _, input := range unifiedNode.Inputs {
+		if ref := input.Reference; ref != nil && settings[input.Label+"\x00"+ref.NodeID+"\x00"+ref.OutputName] {
+			continue
+		}
+		parameters = append(parameters, input)
+	}
+	return parameters
+}
+
+// generateDatabaseNode builds the database settings of the operation
+func (g *DataStoreNodeGenerator) generateDatabaseNode(config *models.DataStoreConfig) map[string]interface{} {
+	databaseNode := map[string]interface{}{
+		"databaseInfoList": []interface{}{map[string]interface{}{"databaseInfoID": config.Table}},
+	}
+
+	switch config.Operation {
+	case models.DataStoreOperationSQL:
+		databaseNode["sql"] = config.SQL
+	case models.DataStoreOperationQuery:
+		fieldList := make([]interface{}, 0, len(config.Fields))
+		for _, field := range config.Fields {
+			fieldList = append(fieldList, map[string]interface{}{"fieldID": field.Name, "isDistinct": false})
+		}
+		orderByList := make([]interface{}, 0, len(config.OrderBy))
+		for _, order := range config.OrderBy {
+			orderByList = append(orderByList, map[string]interface{}{"fieldID": order.Field, "isAsc": order.Ascending})
+		}
+		databaseNode["selectParam"] = map[string]interface{}{
+			"condition":   g.generateCondition(config),
+			"fieldList":   fieldList,
+			"orderByList": orderByList,
+			"limit":       config.Limit,
+		}
+	case models.DataStoreOperationInsert:
+		databaseNode["insertParam"] = map[string]interface{}{"fieldInfo": g.generateFieldInfo(config.Fields)}
+	case models.DataStoreOperationUpdate:
+		databaseNode["updateParam"] = map[string]interface{}{
+			"condition": g.generateCondition(config),
+			"fieldInfo": g.generateFieldInfo(config.Fields),
+		}
+	case models.DataStoreOperationDelete:
+		databaseNode["deleteParam"] = map[string]interface{}{"condition": g.generateCondition(config)}
+	}
+
+	return databaseNode
+}
+
+// generateCondition builds a Coze condition group from "left", "operation" and "right" params
+func (g *DataStoreNodeGenerator) generateCondition(config *models.DataStoreConfig) map[string]interface{} {
+	conditionList := make([]interface{}, 0, len(config.Conditions))
+	for _, condition := range config.Conditions {
+		conditionList = append(conditionList, []interface{}{
+			g.literalParam("left", condition.Field),
+			g.literalParam("operation", strings.ToUpper(condition.Operator)),
+			map[string]interface{}{"name": "right", "input": generateIOParamValue(g.idGenerator, condition.Value)},
+		})
+	}
+
+	logic := strings.ToUpper(config.Logic)
+	if logic == "" {
+		logic = "AND"
+	}
+	return map[string]interface{}{"conditionList": conditionList, "logic": logic}
+}
+
+// generateFieldInfo builds Coze field assignments from "fieldID" and "fieldValue" params
+func (g *DataStoreNodeGenerator) generateFieldInfo(fields []models.DataStoreField) []interface{} {
+	fieldInfo := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		fieldInfo = append(fieldInfo, []interface{}{
+			g.literalParam("fieldID", field.Name),
+			map[string]interface{}{"name": "fieldValue", "input": generateIOParamValue(g.idGenerator, field.Value)},
+		})
+	}
+	return fieldInfo
+}
+
+// literalParam builds a Coze param with a literal string value
+func (g *DataStoreNodeGenerator) literalParam(name, content string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":  name,
+		"input": map[string]interface{}{"type": "string", "value": map[string]interface{}{"type": "literal", "content": content}},
+	}
+}
@@ -1,15 +1,17 @@
 package generator
 
 import (
-	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 	"strings"
 )
 
 // EdgeGenerator handles Coze workflow edge generation and port mapping between platforms.
 type EdgeGenerator struct {
-	idGenerator *CozeIDGenerator
-	unifiedDSL  *models.UnifiedDSL // Unified DSL reference for context-aware mapping
+	idGenerator     *CozeIDGenerator
+	unifiedDSL      *models.UnifiedDSL      // Unified DSL reference for context-aware mapping
+	nodesByID       map[string]*models.Node // Top-level unified nodes by ID
+	handleIDMapping *models.IDMapping       // Source branch/intent handle -> Coze port ID
 }
 
 // NewEdgeGenerator creates an edge generator with platform-specific ID mapping.
@@ -22,11 +24,17 @@ func NewEdgeGenerator() *EdgeGenerator {
 // SetUnifiedDSL configures the unified DSL reference for context-aware port mapping.
 func (g *EdgeGenerator) SetUnifiedDSL(unifiedDSL *models.UnifiedDSL) {
 	g.unifiedDSL = unifiedDSL
+	g.handleIDMapping = models.NewIDMapping()
+	g.nodesByID = make(map[string]*models.Node, len(unifiedDSL.Workflow.Nodes))
+	for i := range unifiedDSL.Workflow.Nodes {
+		g.nodesByID[unifiedDSL.Workflow.Nodes[i].ID] = &unifiedDSL.Workflow.Nodes[i]
+	}
 }
 
 // GenerateEdge converts unified edge definitions to Coze edge format.
 func (g *EdgeGenerator) GenerateEdge(unifiedEdge *models.Edge) *CozeEdge {
-	fromPort := g.mapToCozePort(unifiedEdge.SourceHandle)
+	fromPort := g.mapToCozePort(unifiedEdge.Source, unifiedEdge.SourceHandle)
+	g.recordPort(unifiedEdge.Source, unifiedEdge.SourceHandle, fromPort)
 	// Coze format does not use target port for normal edges
 	toPort := ""
 
@@ -40,7 +48,8 @@ func (g *EdgeGenerator) GenerateEdge(unifiedEdge *models.Edge) *CozeEdge {
 
 // GenerateSchemaEdge converts unified edge definitions to Coze schema edge format.
 func (g *EdgeGenerator) GenerateSchemaEdge(unifiedEdge *models.Edge) *CozeSchemaEdge {
-	fromPort := g.mapToCozePort(unifiedEdge.SourceHandle)
+	fromPort := g.mapToCozePort(unifiedEdge.Source, unifiedEdge.SourceHandle)
+	g.recordPort(unifiedEdge.Source, unifiedEdge.SourceHandle, fromPort)
 	// Coze schema edges typically omit targetPortID
 	toPort := ""
 
@@ -57,8 +66,30 @@ func (g *EdgeGenerator) GenerateSchemaEdge(unifiedEdge *models.Edge) *CozeSchema
 	return edge
 }
 
-// mapToCozePort transforms unified port handles to Coze-specific port identifiers.
-func (g *EdgeGenerator) mapToCozePort(handle string) string {
+// recordPort remembers which Coze port a source branch or intent handle was mapped to
+func (g *EdgeGenerator) recordPort(sourceNodeID, handle, port string) {
+	if g.handleIDMapping == nil || port == "" {
+		return
+	}
+	switch {
+	case strings.HasPrefix(handle, "intent-one-of::"):
+		g.handleIDMapping.AddIntent(sourceNodeID, handle, port)
+	case strings.HasPrefix(handle, "branch_one_of::"):
+		g.handleIDMapping.AddBranch(sourceNodeID, handle, port)
+	default:
+		sourceNode := g.findNode(sourceNodeID)
+		switch {
+		case sourceNode == nil:
+		case sourceNode.Type == models.NodeTypeCondition:
+			g.handleIDMapping.AddBranch(sourceNodeID, handle, port)
+		case sourceNode.Type == models.NodeTypeClassifier:
+			g.handleIDMapping.AddIntent(sourceNodeID, handle, port)
+		}
+	}
+}
+
+// mapToCozePort transforms the source handle of an edge leaving sourceNodeID to a Coze port identifier.
+func (g *EdgeGenerator) mapToCozePort(sourceNodeID, handle string) string {
 	if handle == "" {
 		return ""
 	}
@@ -67,130 +98,40 @@ func (g *EdgeGenerator) mapToCozePort(handle string) string {
 		return ""
 	}
 
-	// Handle iFlytek branch format: branch_one_of::xxx (for condition nodes)
-	if strings.HasPrefix(handle, "branch_one_of::") {
-		return g.mapBranchToCozePort(handle)
+	// Condition branches of any source platform map to selector ports
+	if sourceNode := g.findNode(sourceNodeID); sourceNode != nil && sourceNode.Type == models.NodeTypeCondition {
+		if conditionConfig, ok := common.AsConditionConfig(sourceNode.Config); ok && conditionConfig != nil {
+			return selectorPort(conditionConfig, handle)
+		}
 	}
 
-	// Handle iFlytek classifier intent format: intent-one-of::xxx (for classifier nodes)
-	if strings.HasPrefix(handle, "intent-one-of::") {
-		return g.mapIntentToCozePort(handle)
+	// Classifier branches of any source platform map to intent ports
+	if sourceNode := g.findNode(sourceNodeID); sourceNode != nil && sourceNode.Type == models.NodeTypeClassifier {
+		if classifierConfig, ok := common.AsClassifierConfig(sourceNode.Config); ok && classifierConfig != nil {
+			return classifierPort(classifierConfig, handle)
+		}
 	}
 
-	// Do not pass through unknown handles
-	return ""
-}
-
-// mapBranchToCozePort converts iFlytek branch handles to Coze condition port format.
-func (g *EdgeGenerator) mapBranchToCozePort(branchHandle string) string {
-	// Extract UUID from branch_one_of::uuid format
-	parts := strings.Split(branchHandle, "::")
-	if len(parts) != 2 {
-		return ""
+	// Answer options of any source platform map to question ports
+	if sourceNode := g.findNode(sourceNodeID); sourceNode != nil && sourceNode.Type == models.NodeTypeHumanInput {
+		if questionConfig, ok := common.AsHumanInputConfig(sourceNode.Config); ok && questionConfig != nil {
+			return questionPort(questionConfig, handle)
+		}
 	}
 
-	branchID := parts[1]
-
-	// Look up the branch level in unified DSL if available
-	if g.unifiedDSL != nil {
-		for _, node := range g.unifiedDSL.Workflow.Nodes {
-			if node.Type == models.NodeTypeCondition {
-				conditionConfig, ok := node.Config.(*models.ConditionConfig)
-				if !ok {
-					continue
-				}
-
-				// Dynamic branch mapping based on Coze calcPortId rules
-				// Collect all non-default branches and sort by level
-				type BranchInfo struct {
-					CaseID string
-					Level  int
-				}
-
-				var nonDefaultBranches []BranchInfo
-				var isDefaultBranch bool
-
-				for _, caseItem := range conditionConfig.Cases {
-					// Check if current branchID is a default branch
-					if strings.Contains(caseItem.CaseID, branchID) && caseItem.Level == 999 {
-						isDefaultBranch = true
-						break
-					}
-
-					// Collect all non-default branches
-					if caseItem.Level != 999 {
-						nonDefaultBranches = append(nonDefaultBranches, BranchInfo{
-							CaseID: caseItem.CaseID,
-							Level:  caseItem.Level,
-						})
-					}
-				}
-
-				// Return false port for default branches
-				if isDefaultBranch {
-					return "false"
-				}
-
-				// Sort non-default branches by level (ascending)
-				for i := 0; i < len(nonDefaultBranches)-1; i++ {
-					for j := i + 1; j < len(nonDefaultBranches); j++ {
-						if nonDefaultBranches[i].Level > nonDefaultBranches[j].Level {
-							nonDefaultBranches[i], nonDefaultBranches[j] = nonDefaultBranches[j], nonDefaultBranches[i]
-						}
-					}
-				}
-
-				// Generate port ID based on Coze calcPortId rules
-				// index=0 -> "true", index=1 -> "true_1", index=N -> "true_N"
-				for index, branch := range nonDefaultBranches {
-					if strings.Contains(branch.CaseID, branchID) {
-						if index == 0 {
-							return "true"
-						} else {
-							return fmt.Sprintf("true_%d", index)
-						}
-					}
-				}
-			}
+	// Fail branches of nodes Coze keeps the error strategy of
+	if handle == models.ErrorBranchHandle {
+		if sourceNode := g.findNode(sourceNodeID); sourceNode != nil && sourceNode.ErrorHandling != nil &&
+			sourceNode.ErrorHandling.Strategy == models.ErrorStrategyFailBranch && common.SupportsErrorHandling(models.PlatformCoze, sourceNode.Type) {
+			return cozeErrorBranchPort
 		}
 	}
 
-	// Fallback mapping - return empty for unrecognized branches
+	// Do not pass through unknown handles
 	return ""
 }
 
-// mapIntentToCozePort converts iFlytek intent handles to Coze classifier port format.
-func (g *EdgeGenerator) mapIntentToCozePort(intentHandle string) string {
-	// intentHandle is already in format: intent-one-of::uuid
-	if intentHandle == "" {
-		return ""
-	}
-
-	// Look up the intent in unified DSL for dynamic mapping
-	if g.unifiedDSL != nil {
-		for _, node := range g.unifiedDSL.Workflow.Nodes {
-			if node.Type == models.NodeTypeClassifier {
-				classifierConfig, ok := node.Config.(*models.ClassifierConfig)
-				if !ok {
-					continue
-				}
-
-				// Find the intent by matching intentHandle with class IDs
-				// class.ID contains the full "intent-one-of::uuid" format
-				for i, class := range classifierConfig.Classes {
-					if class.ID == intentHandle {
-						// Handle default intent specially
-						if class.IsDefault {
-							return "default"
-						}
-						// Map non-default intents to branch_0, branch_1, etc.
-						return fmt.Sprintf("branch_%d", i)
-					}
-				}
-			}
-		}
-	}
-
-	// Fallback: if no match found in DSL, return empty
-	return ""
+// findNode returns the top-level unified node with the given ID
+func (g *EdgeGenerator) findNode(nodeID string) *models.Node {
+	return g.nodesByID[nodeID]
 }
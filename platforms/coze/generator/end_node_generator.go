@@ -3,9 +3,16 @@ package generator
 import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 	"strings"
 )
 
+// Coze end node terminate plans
+const (
+	terminatePlanAnswer    = "useAnswerContent" // Answer with the rendered content template
+	terminatePlanVariables = "returnVariables"  // Return the input variables
+)
+
 // EndNodeGenerator generates Coze end nodes
 type EndNodeGenerator struct {
 	idGenerator *CozeIDGenerator
@@ -28,6 +35,7 @@ func (g *EndNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNode, er
 	if err := g.ValidateNode(unifiedNode); err != nil {
 		return nil, err
 	}
+	unifiedNode = g.withAnswerTemplate(unifiedNode)
 
 	cozeNodeID := g.idGenerator.MapToCozeNodeID(unifiedNode.ID)
 
@@ -67,6 +75,7 @@ func (g *EndNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeSc
 	if err := g.ValidateNode(unifiedNode); err != nil {
 		return nil, err
 	}
+	unifiedNode = g.withAnswerTemplate(unifiedNode)
 
 	cozeNodeID := g.idGenerator.MapToCozeNodeID(unifiedNode.ID)
 
@@ -127,14 +136,18 @@ func (g *EndNodeGenerator) generateInputs(unifiedNode *models.Node) interface{}
 		}
 	}
 
-	// FIXED: Generate output template from inputs
-	outputTemplate := ""
-	if len(inputParameters) > 0 {
-		// Use the first input parameter name in template
-		if param, ok := inputParameters[0].(map[string]interface{}); ok {
-			if name, ok := param["name"].(string); ok {
-				outputTemplate = "{{" + name + "}}"
-			}
+	// useAnswerContent end nodes answer with the rendered template
+	var outputEmitter interface{}
+	if template, streaming, ok := g.answerContent(unifiedNode); ok {
+		outputEmitter = map[string]interface{}{
+			"content": map[string]interface{}{
+				"type": "string",
+				"value": map[string]interface{}{
+					"type":    "literal",
+					"content": template,
+				},
+			},
+			"streamingoutput": streaming,
 		}
 	}
 
@@ -144,16 +157,7 @@ func (g *EndNodeGenerator) generateInputs(unifiedNode *models.Node) interface{}
 		"settingonerror":  nil,
 		"nodebatchinfo":   nil,
 		"llmparam":        nil,
-		"outputemitter": map[string]interface{}{
-			"content": map[string]interface{}{
-				"type": "string",
-				"value": map[string]interface{}{
-					"type":    "literal",
-					"content": outputTemplate, // FIXED: Add output template
-				},
-			},
-			"streamingoutput": false,
-		},
+		"outputemitter":   outputEmitter,
 		"exit": map[string]interface{}{
 			"terminateplan": g.selectTerminatePlan(unifiedNode),
 		},
@@ -180,18 +184,48 @@ func (g *EndNodeGenerator) generateInputs(unifiedNode *models.Node) interface{}
 // selectTerminatePlan selects end node terminate plan by output mode
 func (g *EndNodeGenerator) selectTerminatePlan(unifiedNode *models.Node) string {
 	if unifiedNode == nil {
-		return "useAnswerContent"
+		return terminatePlanAnswer
 	}
-	if cfg, ok := unifiedNode.Config.(models.EndConfig); ok {
-		if strings.EqualFold(cfg.OutputMode, "variables") {
-			return "returnVariables"
+	if cfg, ok := common.AsEndConfig(unifiedNode.Config); ok && cfg != nil {
+		if strings.EqualFold(cfg.OutputMode, models.EndOutputModeVariables) {
+			return terminatePlanVariables
 		}
-	} else if cfgPtr, ok := unifiedNode.Config.(*models.EndConfig); ok && cfgPtr != nil {
-		if strings.EqualFold(cfgPtr.OutputMode, "variables") {
-			return "returnVariables"
+	}
+	return terminatePlanAnswer
+}
+
+// withAnswerTemplate returns a copy of the node whose answer template uses named placeholders,
+// with an input for every node output the template references. An empty template lists the inputs.
+func (g *EndNodeGenerator) withAnswerTemplate(unifiedNode *models.Node) *models.Node {
+	endConfig, ok := common.AsEndConfig(unifiedNode.Config)
+	if !ok || endConfig == nil || g.selectTerminatePlan(unifiedNode) != terminatePlanAnswer {
+		return unifiedNode
+	}
+
+	node := *unifiedNode
+	config := *endConfig
+	if config.Template == "" {
+		placeholders := make([]string, 0, len(node.Inputs))
+		for _, input := range node.Inputs {
+			placeholders = append(placeholders, "{{"+input.Name+"}}")
 		}
+		config.Template = strings.Join(placeholders, "\n")
 	}
-	return "useAnswerContent"
+	config.Template, node.Inputs = common.NamePromptReferences(config.Template, unifiedNode.Inputs)
+	node.Config = &config
+	return &node
+}
+
+// answerContent returns the answer template and streaming flag of useAnswerContent end nodes
+func (g *EndNodeGenerator) answerContent(unifiedNode *models.Node) (string, bool, bool) {
+	if g.selectTerminatePlan(unifiedNode) != terminatePlanAnswer {
+		return "", false, false
+	}
+	config, ok := common.AsEndConfig(unifiedNode.Config)
+	if !ok || config == nil {
+		return "", false, false
+	}
+	return config.Template, config.StreamOutput, true
 }
 
 // generateSchemaInputs generates inputs for the schema node format
@@ -222,9 +256,18 @@ func (g *EndNodeGenerator) generateSchemaInputs(unifiedNode *models.Node) *CozeN
 		}
 	}
 
-	return &CozeNodeInputs{
+	inputs := &CozeNodeInputs{
 		InputParameters: inputParameters,
+		TerminatePlan:   g.selectTerminatePlan(unifiedNode),
+	}
+	if template, streaming, ok := g.answerContent(unifiedNode); ok {
+		inputs.Content = &CozePromptValue{
+			Type:  "string",
+			Value: CozePromptContent{Content: template, Type: "literal"},
+		}
+		inputs.StreamingOutput = &streaming
 	}
+	return inputs
 }
 
 // mapUnifiedTypeToCoze maps unified data types to Coze types
@@ -241,7 +284,7 @@ func (g *EndNodeGenerator) mapUnifiedTypeToCoze(unifiedType models.UnifiedDataTy
 	case models.DataTypeBoolean:
 		return "boolean"
 	case models.DataTypeArrayString, models.DataTypeArrayInteger, models.DataTypeArrayFloat,
-		models.DataTypeArrayNumber, models.DataTypeArrayBoolean, models.DataTypeArrayObject:
+		models.DataTypeArrayNumber, models.DataTypeArrayBoolean, models.DataTypeArrayObject, models.DataTypeArrayFile:
 		return "list" // Coze uses "list" for all array types
 	case models.DataTypeObject:
 		return "object"
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"encoding/json"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Coze settingOnError process types
+const (
+	cozeProcessTypeThrow        = 1 // Fail the workflow
+	cozeProcessTypeDefaultValue = 2 // Output dataOnErr
+	cozeProcessTypeErrorBranch  = 3 // Continue along the branch_error port
+)
+
+// cozeErrorBranchPort is the port of the edges taken when a node fails
+const cozeErrorBranchPort = "branch_error"
+
+// withErrorHandling writes the retries and error strategy of node into its Coze error settings.
+func withErrorHandling(settings map[string]interface{}, node *models.Node) map[string]interface{} {
+	handling := node.ErrorHandling
+	if handling == nil {
+		return settings
+	}
+
+	settings["retryTimes"] = handling.MaxRetries
+	switch handling.Strategy {
+	case models.ErrorStrategyDefaultValue:
+		settings["processType"] = cozeProcessTypeDefaultValue
+		settings["switch"] = true
+		// dataOnErr is the JSON object of all outputs
+		values := make(map[string]interface{}, len(node.Outputs))
+		for _, output := range node.Outputs {
+			values[output.Name] = handling.DefaultValues[output.Name]
+		}
+		if data, err := json.MarshalIndent(values, "", "    "); err == nil {
+			delete(settings, "dataonerr")
+			settings["dataOnErr"] = string(data)
+		}
+	case models.ErrorStrategyFailBranch:
+		settings["processType"] = cozeProcessTypeErrorBranch
+		settings["switch"] = true
+	default:
+		settings["processType"] = cozeProcessTypeThrow
+	}
+	return settings
+}
@@ -75,6 +75,9 @@ func (g *IterationNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNo
 	if err := g.ValidateNode(unifiedNode); err != nil {
 		return nil, err
 	}
+	if body, batchNode := batchModeNode(unifiedNode); body != nil {
+		return g.generateBatchModeNode(body, batchNode)
+	}
 
 	cozeNodeID := g.idGenerator.MapToCozeNodeID(unifiedNode.ID)
 
@@ -87,8 +90,8 @@ func (g *IterationNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNo
 		return nil, fmt.Errorf("invalid iteration config type for node %s", unifiedNode.ID)
 	}
 
-	// Generate sub-blocks
-	blocks, err := g.generateSubBlocks(iterationConfig.SubWorkflow.Nodes)
+	// Generate sub-blocks, which the nodes section lists in the format of top-level nodes
+	blocks, err := g.generateNodeBlocks(iterationConfig.SubWorkflow.Nodes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate sub blocks: %v", err)
 	}
@@ -98,6 +101,7 @@ func (g *IterationNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNo
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate internal edges: %v", err)
 	}
+	edges = nodeSectionEdges(edges)
 
 	// Generate iteration inputs configuration - match Coze format exactly
 	inputParams := g.generateInputParameters(unifiedNode, iterationConfig)
@@ -136,10 +140,17 @@ func (g *IterationNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNo
 		"comment":            nil,
 		"inputReceiver":      nil,
 	}
+	kind := iterationKindOf(iterationConfig)
+	if kind == batchIteration {
+		delete(inputs, "loopType")
+		delete(inputs, "loopCount")
+		delete(inputs, "variableParameters")
+		inputs["batch"] = g.generateBatchSettings(iterationConfig)
+	}
 
 	return &CozeNode{
 		ID:   cozeNodeID,
-		Type: "21", // Coze iteration node type (correct type)
+		Type: kind.nodeType,
 		Meta: &CozeNodeMeta{
 			// FIXED: Add missing canvasPosition field matching Coze format
 			CanvasPosition: &CozePosition{
@@ -156,7 +167,7 @@ func (g *IterationNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNo
 				Title:       nodeMeta["title"].(string),
 				Description: nodeMeta["description"].(string),
 				Icon:        nodeMeta["icon"].(string),
-				SubTitle:    nodeMeta["subtitle"].(string), // FIXED: Use lowercase subtitle
+				SubTitle:    kind.subtitle,
 				MainColor:   nodeMeta["maincolor"].(string),
 			},
 			Outputs: g.generateIterationOutputs(unifiedNode),
@@ -174,6 +185,9 @@ func (g *IterationNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*
 	if err := g.ValidateNode(unifiedNode); err != nil {
 		return nil, err
 	}
+	if body, batchNode := batchModeNode(unifiedNode); body != nil {
+		return g.generateBatchModeSchemaNode(body, batchNode)
+	}
 
 	cozeNodeID := g.idGenerator.MapToCozeNodeID(unifiedNode.ID)
 
@@ -200,6 +214,15 @@ func (g *IterationNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*
 
 	// Generate schema iteration inputs
 	schemaInputs := g.generateSchemaIterationInputs(unifiedNode, iterationConfig)
+	kind := iterationKindOf(iterationConfig)
+	if kind == batchIteration {
+		delete(schemaInputs, "loopType")
+		delete(schemaInputs, "loopCount")
+		delete(schemaInputs, "variableParameters")
+		for key, value := range g.generateBatchSettings(iterationConfig) {
+			schemaInputs[key] = value
+		}
+	}
 
 	// Note: schema outputs are now generated using generateIterationOutputs method
 
@@ -209,14 +232,14 @@ func (g *IterationNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*
 				Title:       g.getNodeTitle(unifiedNode),
 				Description: g.getNodeDescription(unifiedNode),
 				Icon:        g.getNodeIcon(),
-				SubTitle:    "循环",      // Changed to match example
+				SubTitle:    kind.subtitle,
 				MainColor:   "#00B2B2", // Changed to match example
 			},
 			Inputs:  schemaInputs,
 			Outputs: g.generateIterationOutputs(unifiedNode),
 		},
 		ID:   cozeNodeID,
-		Type: "21", // Coze iteration node type
+		Type: kind.nodeType,
 		Meta: &CozeNodeMeta{
 			// Add canvasPosition for schema nodes
 			CanvasPosition: &CozePosition{
@@ -267,6 +290,54 @@ func (g *IterationNodeGenerator) generateSubBlocks(subNodes []models.Node) ([]in
 	return blocks, nil
 }
 
+// generateNodeBlocks generates the sub-blocks of the nodes section, which Coze exports in the same
+// format as top-level nodes
+func (g *IterationNodeGenerator) generateNodeBlocks(subNodes []models.Node) ([]interface{}, error) {
+	if g.nodeFactory == nil {
+		return nil, fmt.Errorf("node factory not set")
+	}
+
+	blocks := make([]interface{}, 0, len(subNodes))
+	for i := range subNodes {
+		subNode := &subNodes[i]
+		if subNode.Type == models.NodeTypeStart || subNode.Type == models.NodeTypeEnd {
+			continue
+		}
+		nodeGenerator, err := g.nodeFactory.GetNodeGenerator(subNode.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get generator for node type %s: %v", subNode.Type, err)
+		}
+		nodeGenerator.SetIDGenerator(g.idGenerator)
+
+		cozeNode, err := nodeGenerator.GenerateNode(subNode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate block node %s: %v", subNode.ID, err)
+		}
+		restoreUnknownFields(cozeNode, subNode)
+		blocks = append(blocks, cozeNode)
+	}
+	return blocks, nil
+}
+
+// nodeSectionEdges converts internal edges to the lowercase keys of the nodes section
+func nodeSectionEdges(edges []interface{}) []interface{} {
+	converted := make([]interface{}, 0, len(edges))
+	for _, edge := range edges {
+		edgeMap, ok := edge.(map[string]interface{})
+		if !ok {
+			converted = append(converted, edge)
+			continue
+		}
+		nodeEdge := map[string]interface{}{}
+		for _, key := range []string{"sourceNodeID", "targetNodeID", "sourcePortID", "targetPortID"} {
+			value, _ := edgeMap[key].(string)
+			nodeEdge[strings.ToLower(key)] = value
+		}
+		converted = append(converted, nodeEdge)
+	}
+	return converted
+}
+
 // generateBlockNode generates a CozeBlockNode with correct field ordering and structure
 func (g *IterationNodeGenerator) generateBlockNode(subNode *models.Node) (*CozeBlockNode, error) {
 
@@ -278,12 +349,15 @@ func (g *IterationNodeGenerator) generateBlockNode(subNode *models.Node) (*CozeB
 
 	nodeGenerator.SetIDGenerator(g.idGenerator)
 
-	// Set iteration context for code nodes and LLM nodes
+	// Set iteration context for code nodes and LLM nodes; the generators are shared with top-level
+	// nodes, so the context is reset afterwards
 	if codeGen, ok := nodeGenerator.(*CodeNodeGenerator); ok {
 		codeGen.SetIterationContext(true)
+		defer codeGen.SetIterationContext(false)
 	}
 	if llmGen, ok := nodeGenerator.(*LLMNodeGenerator); ok {
 		llmGen.SetIterationContext(true)
+		defer llmGen.SetIterationContext(false)
 	}
 
 	// Generate original node to get data
@@ -324,8 +398,8 @@ func (g *IterationNodeGenerator) generateConditionBlockNode(subNode *models.Node
 	// This way they can be correctly identified by coze platform and establish branch connections
 
 	// Get condition node configuration
-	conditionConfig, ok := subNode.Config.(*models.ConditionConfig)
-	if !ok {
+	conditionConfig, ok := common.AsConditionConfig(subNode.Config)
+	if !ok || conditionConfig == nil {
 		return nil, fmt.Errorf("invalid condition config type")
 	}
 
@@ -335,12 +409,7 @@ func (g *IterationNodeGenerator) generateConditionBlockNode(subNode *models.Node
 
 	// Generate schema format branches (simplified format, no extra fields)
 	branches := make([]map[string]interface{}, 0)
-	for _, caseItem := range conditionConfig.Cases {
-		// Skip empty condition branches (default case, level=999)
-		if len(caseItem.Conditions) == 0 {
-			continue
-		}
-
+	for _, caseItem := range selectorBranchCases(conditionConfig) {
 		// Use schema format to generate branch
 		branch, err := conditionGenerator.GenerateSchemaBranch(caseItem, subNode)
 		if err != nil {
@@ -374,22 +443,19 @@ func (g *IterationNodeGenerator) generateConditionBlockNode(subNode *models.Node
 func (g *IterationNodeGenerator) generateInternalEdges(subEdges []models.Edge, iterationConfig *models.IterationConfig) ([]interface{}, error) {
 	var edges []interface{}
 
-	if len(subEdges) == 0 {
-		return edges, nil
-	}
-
 	// Correct loop architecture based on Coze source code understanding:
 	// 1. Internal nodes connect directly to each other (no need for special entry/exit edges)
 	// 2. Loop node automatically manages loop flow through special ports
 	// 3. loop-function-inline-output/input ports are handled by the loop node itself
 
 	iterationNodeID := g.idGenerator.GetCurrentIterationNodeID()
+	internal := g.internalNodeIDs(subEdges, iterationConfig.SubWorkflow.Nodes)
 	handleMappings := g.buildHandleMappings(subEdges, iterationConfig)
 
 	// Generate direct connections between internal processing nodes
 	for _, edge := range subEdges {
 		// Skip edges involving internal start/end nodes
-		if g.isIterationInternalNode(edge.Source) || g.isIterationInternalNode(edge.Target) {
+		if internal[edge.Source] || internal[edge.Target] {
 			continue
 		}
 
@@ -401,20 +467,31 @@ func (g *IterationNodeGenerator) generateInternalEdges(subEdges []models.Edge, i
 	}
 
 	// Add loop special port connections (based on Coze official example)
-	g.addCozeLoopPortConnections(&edges, subEdges, iterationNodeID)
+	g.addCozeLoopPortConnections(&edges, subEdges, iterationConfig.SubWorkflow.Nodes, internal, iterationNodeID, iterationKindOf(iterationConfig))
 
 	return edges, nil
 }
 
-// buildHandleMappings builds handle mapping table based on edge appearance order, supporting dynamic multi-branch intent recognition
+// buildHandleMappings maps the branch handles of the sub-workflow edges to Coze ports, for conditions and
+// classifiers with any number of branches
 func (g *IterationNodeGenerator) buildHandleMappings(subEdges []models.Edge, iterationConfig *models.IterationConfig) map[string]string {
 	mappings := make(map[string]string)
 
-	// Get classifier node configuration for dynamic multi-branch mapping
-	classifierBranchMappings := g.buildDynamicClassifierBranchMappings(iterationConfig)
-
-	// Track indices for different types of handles separately
-	conditionBranchIndex := 0
+	// Condition and classifier branches of any source platform map to selector and intent ports
+	conditionConfigs := make(map[string]*models.ConditionConfig)
+	classifierConfigs := make(map[string]*models.ClassifierConfig)
+	for _, node := range iterationConfig.SubWorkflow.Nodes {
+		switch node.Type {
+		case models.NodeTypeCondition:
+			if config, ok := common.AsConditionConfig(node.Config); ok && config != nil {
+				conditionConfigs[node.ID] = config
+			}
+		case models.NodeTypeClassifier:
+			if config, ok := common.AsClassifierConfig(node.Config); ok && config != nil {
+				classifierConfigs[node.ID] = config
+			}
+		}
+	}
 
 	// CRITICAL: Process all edges in order, establish mapping from handles to Coze ports
 	for _, edge := range subEdges {
@@ -423,26 +500,18 @@ func (g *IterationNodeGenerator) buildHandleMappings(subEdges []models.Edge, ite
 			continue // Skip empty handles
 		}
 
-		// Handle intent recognition handles - use dynamic mapping, ensure each branch has unique mapping
-		if strings.HasPrefix(handle, "intent-one-of::") {
-			if branchName, exists := classifierBranchMappings[handle]; exists {
-				mappings[handle] = branchName
-			} else {
-				// CRITICAL: Avoid multiple branches mapping to same port
-				// Record unmapped handles for subsequent dynamic analysis
-				fmt.Printf("⚠️  Classifier branch mapping not found: %s\n", handle)
-				mappings[handle] = "default"
+		if conditionConfig, isCondition := conditionConfigs[edge.Source]; isCondition {
+			if port := selectorPort(conditionConfig, handle); port != "" {
+				mappings[handle] = port
 			}
+			continue
 		}
 
-		// Handle condition branch handles using branch mapping logic
-		if strings.HasPrefix(handle, "branch_one_of::") {
-			if _, exists := mappings[handle]; !exists {
-				// Use completely dynamic condition branch mapping logic, supporting any number of branches
-				mappedPort := g.mapConditionBranchToCozePort(handle, subEdges, iterationConfig, conditionBranchIndex)
-				mappings[handle] = mappedPort
-				conditionBranchIndex++
+		if classifierConfig, isClassifier := classifierConfigs[edge.Source]; isClassifier {
+			if port := classifierPort(classifierConfig, handle); port != "" {
+				mappings[handle] = port
 			}
+			continue
 		}
 
 		// Handle known fixed handle types
@@ -454,80 +523,6 @@ func (g *IterationNodeGenerator) buildHandleMappings(subEdges []models.Edge, ite
 	return mappings
 }
 
-// mapConditionBranchToCozePort dynamically maps condition branches to ports based on Coze source calcPortId rules
-// Rules: 1st branch="true", 2nd="true_1", Nth="true_{N-1}", default="false"
-func (g *IterationNodeGenerator) mapConditionBranchToCozePort(handle string, subEdges []models.Edge, iterationConfig *models.IterationConfig, branchIndex int) string {
-	// 1. Find condition node configuration
-	var conditionConfig *models.ConditionConfig
-	for _, node := range iterationConfig.SubWorkflow.Nodes {
-		if node.Type == models.NodeTypeCondition {
-			if config, ok := node.Config.(*models.ConditionConfig); ok {
-				conditionConfig = config
-				break
-			}
-		}
-	}
-
-	if conditionConfig == nil {
-		return "default"
-	}
-
-	// 2. Collect all non-default branches (level != 999) and sort by level
-	type BranchInfo struct {
-		CaseID string
-		Level  int
-	}
-
-	var nonDefaultBranches []BranchInfo
-	var isDefaultBranch bool
-
-	for _, caseItem := range conditionConfig.Cases {
-		if caseItem.CaseID == handle {
-			if caseItem.Level == 999 {
-				isDefaultBranch = true
-				break
-			}
-		}
-
-		// Collect all non-default branches
-		if caseItem.Level != 999 {
-			nonDefaultBranches = append(nonDefaultBranches, BranchInfo{
-				CaseID: caseItem.CaseID,
-				Level:  caseItem.Level,
-			})
-		}
-	}
-
-	// Return false port for default branches
-	if isDefaultBranch {
-		return "false"
-	}
-
-	// Sort non-default branches by level in ascending order
-	for i := 0; i < len(nonDefaultBranches)-1; i++ {
-		for j := i + 1; j < len(nonDefaultBranches); j++ {
-			if nonDefaultBranches[i].Level > nonDefaultBranches[j].Level {
-				nonDefaultBranches[i], nonDefaultBranches[j] = nonDefaultBranches[j], nonDefaultBranches[i]
-			}
-		}
-	}
-
-	// Generate port IDs based on Coze calcPortId rules
-	// index=0 -> "true", index=1 -> "true_1", index=N -> "true_N"
-	for index, branch := range nonDefaultBranches {
-		if branch.CaseID == handle {
-			if index == 0 {
-				return "true"
-			} else {
-				return fmt.Sprintf("true_%d", index)
-			}
-		}
-	}
-
-	// Fallback logic
-	return "default"
-}
-
 // generateCozeInternalEdgeWithMappings generates Coze format edge connections using mapping table
 func (g *IterationNodeGenerator) generateCozeInternalEdgeWithMappings(edge models.Edge, mappings map[string]string) map[string]interface{} {
 	sourceNodeID := g.idGenerator.MapToCozeNodeID(edge.Source)
@@ -557,51 +552,91 @@ func (g *IterationNodeGenerator) generateCozeInternalEdgeWithMappings(edge model
 }
 
 // addCozeLoopPortConnections adds loop port connections based on Coze official examples
-func (g *IterationNodeGenerator) addCozeLoopPortConnections(edges *[]interface{}, subEdges []models.Edge, iterationNodeID string) {
+func (g *IterationNodeGenerator) addCozeLoopPortConnections(edges *[]interface{}, subEdges []models.Edge, subNodes []models.Node, internal map[string]bool, iterationNodeID string, kind iterationKind) {
 	// Based on Coze official example loop_with_object_input.json connection pattern:
 	// 1. Loop node -> Internal first node (loop-function-inline-output)
 	// 2. Internal last node -> Loop node (loop-function-inline-input)
 
-	var firstNodeID, lastNodeID string
+	var firstNodeIDs, lastNodeIDs []string
+	hasIncoming, hasOutgoing := make(map[string]bool), make(map[string]bool)
 
 	// Identify first and last processing nodes
 	for _, edge := range subEdges {
 		// Find the first processing node connected from internal start node
-		if g.isIterationInternalNode(edge.Source) && !g.isIterationInternalNode(edge.Target) {
-			if firstNodeID == "" {
-				firstNodeID = g.idGenerator.MapToCozeNodeID(edge.Target)
+		if internal[edge.Source] && !internal[edge.Target] {
+			if len(firstNodeIDs) == 0 {
+				firstNodeIDs = append(firstNodeIDs, edge.Target)
 			}
 		}
 		// Find the last processing node connected to internal end node
-		if !g.isIterationInternalNode(edge.Source) && g.isIterationInternalNode(edge.Target) {
-			lastNodeID = g.idGenerator.MapToCozeNodeID(edge.Source)
+		if !internal[edge.Source] && internal[edge.Target] {
+			lastNodeIDs = []string{edge.Source}
+		}
+		if !internal[edge.Source] && !internal[edge.Target] {
+			hasIncoming[edge.Target] = true
+			hasOutgoing[edge.Source] = true
 		}
 	}
 
+	// Bodies without internal start or end nodes, as Coze sources have, begin at the nodes nothing
+	// leads to and end at the nodes leading nowhere
+	var entries, exits []string
+	for _, node := range subNodes {
+		if internal[node.ID] {
+			continue
+		}
+		if !hasIncoming[node.ID] {
+			entries = append(entries, node.ID)
+		}
+		if !hasOutgoing[node.ID] {
+			exits = append(exits, node.ID)
+		}
+	}
+	if len(firstNodeIDs) == 0 {
+		firstNodeIDs = entries
+	}
+	if len(lastNodeIDs) == 0 {
+		lastNodeIDs = exits
+	}
+
 	// Add loop entry connection (Coze official format)
-	if firstNodeID != "" {
+	for _, firstNodeID := range firstNodeIDs {
 		entryEdge := map[string]interface{}{
 			"sourceNodeID": iterationNodeID,
-			"targetNodeID": firstNodeID,
-			"sourcePortID": "loop-function-inline-output",
+			"targetNodeID": g.idGenerator.MapToCozeNodeID(firstNodeID),
+			"sourcePortID": kind.inlineOutputPort,
 		}
 		*edges = append(*edges, entryEdge)
 	}
 
 	// Add loop exit connection (Coze official format)
-	if lastNodeID != "" {
+	for _, lastNodeID := range lastNodeIDs {
 		exitEdge := map[string]interface{}{
-			"sourceNodeID": lastNodeID,
+			"sourceNodeID": g.idGenerator.MapToCozeNodeID(lastNodeID),
 			"targetNodeID": iterationNodeID,
-			"targetPortID": "loop-function-inline-input",
+			"targetPortID": kind.inlineInputPort,
 		}
 		*edges = append(*edges, exitEdge)
 	}
 }
 
-// isIterationInternalNode checks if a node ID represents an iteration internal node (start/end)
-func (g *IterationNodeGenerator) isIterationInternalNode(nodeID string) bool {
-	return strings.Contains(nodeID, "iteration-node-start") || strings.Contains(nodeID, "iteration-node-end")
+// internalNodeIDs returns the internal start and end nodes of an iteration body, which Coze bodies
+// do without
+func (g *IterationNodeGenerator) internalNodeIDs(subEdges []models.Edge, subNodes []models.Node) map[string]bool {
+	internal := make(map[string]bool)
+	for _, node := range subNodes {
+		if node.Type == models.NodeTypeStart || node.Type == models.NodeTypeEnd {
+			internal[node.ID] = true
+		}
+	}
+	for _, edge := range subEdges {
+		for _, nodeID := range []string{edge.Source, edge.Target} {
+			if strings.Contains(nodeID, "iteration-node-start") || strings.Contains(nodeID, "iteration-node-end") {
+				internal[nodeID] = true
+			}
+		}
+	}
+	return internal
 }
 
 // generateLoopCountConfig generates loop count configuration matching Coze format
@@ -735,9 +770,13 @@ func (g *IterationNodeGenerator) getLastProcessingNodeID(unifiedNode *models.Nod
 		}
 	}
 
-	// 2. Find nodes that do not output to other internal nodes (terminal nodes)
+	// 2. Find nodes that do not output to other internal nodes (terminal nodes), in node order
 	var terminalNodes []string
-	for nodeID := range internalNodeIDs {
+	for _, subNode := range iterationConfig.SubWorkflow.Nodes {
+		nodeID := subNode.ID
+		if !internalNodeIDs[nodeID] {
+			continue
+		}
 		hasInternalOutgoing := false
 		for _, edge := range outgoingEdges[nodeID] {
 			if internalNodeIDs[edge.Target] {
@@ -907,6 +946,10 @@ func (g *IterationNodeGenerator) inferOutputFromNodeMeta(node models.Node) strin
 
 // generateIterationOutputs generates complex reference outputs matching exact Coze format
 func (g *IterationNodeGenerator) generateIterationOutputs(unifiedNode *models.Node) []map[string]interface{} {
+	if outputs := g.sourceIterationOutputs(unifiedNode); outputs != nil {
+		return outputs
+	}
+
 	var outputs []map[string]interface{}
 
 	// FIXED: Reference the last actual processing node, not a virtual end node
@@ -950,6 +993,24 @@ func (g *IterationNodeGenerator) generateIterationOutputs(unifiedNode *models.No
 	return outputs
 }
 
+// sourceIterationOutputs returns the outputs of a Coze loop or batch node as parsed, each naming the
+// body output it collects. Iterations of other sources, or whose outputs were edited, give nil.
+func (g *IterationNodeGenerator) sourceIterationOutputs(unifiedNode *models.Node) []map[string]interface{} {
+	sourceOutputs, _ := cozeSourceParams(unifiedNode)["outputs"].([]interface{})
+	if len(sourceOutputs) == 0 || len(sourceOutputs) != len(unifiedNode.Outputs) {
+		return nil
+	}
+	outputs := make([]map[string]interface{}, 0, len(sourceOutputs))
+	for i, sourceOutput := range sourceOutputs {
+		output, _ := sourceOutput.(map[string]interface{})
+		if output == nil || output["input"] == nil || output["name"] != unifiedNode.Outputs[i].Name {
+			return nil
+		}
+		outputs = append(outputs, remapBlockIDs(g.idGenerator, output).(map[string]interface{}))
+	}
+	return outputs
+}
+
 // mapUnifiedTypeToCozeSchemaType maps unified data type to Coze schema type string
 func (g *IterationNodeGenerator) mapUnifiedTypeToCozeSchemaType(unifiedType models.UnifiedDataType) string {
 	switch unifiedType {
@@ -1087,68 +1148,51 @@ func (g *IterationNodeGenerator) mapOutputFieldNameForCoze(nodeID, outputName st
 	return outputName
 }
 
-// buildDynamicClassifierBranchMappings builds truly dynamic branch mappings based on original iFlytek DSL edge definitions
-func (g *IterationNodeGenerator) buildDynamicClassifierBranchMappings(iterationConfig *models.IterationConfig) map[string]string {
-	mappings := make(map[string]string)
+// iterationKind describes the Coze node an iteration is generated as: a loop running its items one
+// by one, or a batch node running several at a time
+type iterationKind struct {
+	nodeType         string
+	subtitle         string
+	outputPort       string // Port of the edges leaving the node
+	inlineOutputPort string // Port of the edges starting the body
+	inlineInputPort  string // Port of the edges ending the body
+}
 
-	// Step 1: Build mapping table from intent ID to intent information
-	intentIDToInfo := make(map[string]models.ClassifierClass)
-	classifierNodeIDs := make(map[string]bool)
+var (
+	loopIteration  = iterationKind{"21", "循环", "loop-output", "loop-function-inline-output", "loop-function-inline-input"}
+	batchIteration = iterationKind{"28", "批处理", "batch-output", "batch-function-inline-output", "batch-function-inline-input"}
+)
 
-	for _, node := range iterationConfig.SubWorkflow.Nodes {
-		if node.Type == models.NodeTypeClassifier {
-			classifierNodeIDs[node.ID] = true
-			if classifierConfig, ok := node.Config.(*models.ClassifierConfig); ok {
-				for _, class := range classifierConfig.Classes {
-					if class.ID != "" {
-						intentIDToInfo[class.ID] = class
-					}
-				}
-			}
-		}
+// iterationKindOf returns the Coze node kind of an iteration by its parallelism
+func iterationKindOf(iterationConfig *models.IterationConfig) iterationKind {
+	if iterationConfig != nil && iterationConfig.Execution.IsParallel {
+		return batchIteration
 	}
+	return loopIteration
+}
 
-	// Collect intent IDs from classifier output edges from edge definitions, maintaining original order in edge definitions
-	intentIDsInOrder := []string{}
-	for _, edge := range iterationConfig.SubWorkflow.Edges {
-		if classifierNodeIDs[edge.Source] && edge.SourceHandle != "" {
-			// Avoid recording the same intent ID repeatedly
-			found := false
-			for _, existingID := range intentIDsInOrder {
-				if existingID == edge.SourceHandle {
-					found = true
-					break
-				}
-			}
-			if !found {
-				intentIDsInOrder = append(intentIDsInOrder, edge.SourceHandle)
-			}
-		}
+// iterationOutputPort returns the port of the edges leaving an iteration node; batch-mode nodes
+// have none
+func iterationOutputPort(unifiedNode *models.Node) string {
+	if body, _ := batchModeNode(unifiedNode); body != nil {
+		return ""
 	}
+	iterationConfig, _ := common.AsIterationConfig(unifiedNode.Config)
+	return iterationKindOf(iterationConfig).outputPort
+}
 
-	// Step 3: Map based on actual intent information
-	branchIndex := 0
-	for _, intentID := range intentIDsInOrder {
-		if intentInfo, exists := intentIDToInfo[intentID]; exists {
-			// Judge whether it is default intent based on actual name and description
-			isDefault := (intentInfo.Name == "default" && strings.Contains(intentInfo.Description, "默认")) ||
-				strings.Contains(intentInfo.Description, "默认意图") ||
-				strings.Contains(strings.ToLower(intentInfo.Name), "default") ||
-				strings.Contains(strings.ToLower(intentInfo.Name), "其他") ||
-				strings.Contains(strings.ToLower(intentInfo.Name), "fallback")
-
-			if isDefault {
-				mappings[intentID] = "default"
-			} else {
-				mappings[intentID] = fmt.Sprintf("branch_%d", branchIndex)
-				branchIndex++
-			}
-		} else {
-			// If intent information not found, assign by order (insurance measure)
-			mappings[intentID] = fmt.Sprintf("branch_%d", branchIndex)
-			branchIndex++
-		}
+// generateBatchSettings generates the settings of batch nodes: the items run at a time and the
+// maximum number of items, which Coze defaults to 100
+func (g *IterationNodeGenerator) generateBatchSettings(iterationConfig *models.IterationConfig) map[string]interface{} {
+	literal := func(value int) map[string]interface{} {
+		return map[string]interface{}{"type": "integer", "value": map[string]interface{}{"type": "literal", "content": value}}
+	}
+	parallelNums := iterationConfig.Execution.ParallelNums
+	if parallelNums <= 0 {
+		parallelNums = models.DefaultParallelNums
+	}
+	return map[string]interface{}{
+		"batchSize":      literal(100),
+		"concurrentSize": literal(parallelNums),
 	}
-
-	return mappings
 }
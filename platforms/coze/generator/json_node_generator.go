@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// JSONNodeGenerator generates Coze JSON serialization and deserialization nodes
+type JSONNodeGenerator struct {
+	idGenerator *CozeIDGenerator
+}
+
+// NewJSONNodeGenerator creates a JSON node generator
+func NewJSONNodeGenerator() *JSONNodeGenerator {
+	return &JSONNodeGenerator{}
+}
+
+// SetIDGenerator sets the shared ID generator
+func (g *JSONNodeGenerator) SetIDGenerator(idGenerator *CozeIDGenerator) {
+	g.idGenerator = idGenerator
+}
+
+// GetNodeType returns the node type this generator handles
+func (g *JSONNodeGenerator) GetNodeType() models.NodeType {
+	return models.NodeTypeJSONProcess
+}
+
+// ValidateNode validates the unified node before generation
+func (g *JSONNodeGenerator) ValidateNode(unifiedNode *models.Node) error {
+	if unifiedNode == nil {
+		return fmt.Errorf("unified node is nil")
+	}
+	if unifiedNode.Type != models.NodeTypeJSONProcess {
+		return fmt.Errorf("invalid node type: expected %s, got %s", models.NodeTypeJSONProcess, unifiedNode.Type)
+	}
+	if config, ok := common.AsJSONProcessConfig(unifiedNode.Config); !ok || config == nil {
+		return fmt.Errorf("invalid config type: expected JSONProcessConfig")
+	}
+	return nil
+}
+
+// GenerateNode generates a Coze JSON node, which reads the "input" parameter and writes "output"
+func (g *JSONNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNode, error) {
+	if err := g.ValidateNode(unifiedNode); err != nil {
+		return nil, err
+	}
+	return generateIONode(g.idGenerator, unifiedNode, g.nodeMeta(unifiedNode), unifiedNode.Inputs, map[string]interface{}{}), nil
+}
+
+// GenerateSchemaNode generates a Coze schema JSON node
+func (g *JSONNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeSchemaNode, error) {
+	if err := g.ValidateNode(unifiedNode); err != nil {
+		return nil, err
+	}
+	return generateIOSchemaNode(g.idGenerator, unifiedNode, g.nodeMeta(unifiedNode), unifiedNode.Inputs, map[string]interface{}{}), nil
+}
+
+// nodeMeta returns the Coze node type of the operation
+func (g *JSONNodeGenerator) nodeMeta(unifiedNode *models.Node) ioNodeMeta {
+	config, _ := common.AsJSONProcessConfig(unifiedNode.Config)
+	if config.Operation == models.JSONOperationDeserialize {
+		return ioNodeMeta{Type: "59", Subtitle: "JSON 反序列化", MainColor: "#3071F2"}
+	}
+	return ioNodeMeta{Type: "58", Subtitle: "JSON 序列化", MainColor: "#3071F2"}
+}
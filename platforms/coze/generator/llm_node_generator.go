@@ -34,6 +34,7 @@ func (g *LLMNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNode, er
 	if err := g.ValidateNode(unifiedNode); err != nil {
 		return nil, err
 	}
+	unifiedNode = g.withNamedPromptReferences(unifiedNode)
 
 	cozeNodeID := g.idGenerator.MapToCozeNodeID(unifiedNode.ID)
 
@@ -55,6 +56,9 @@ func (g *LLMNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNode, er
 		"llmParam":        llmParams,     // ✅ Essential: LLM configuration parameters with correct camelCase
 		"settingOnError":  errorSettings, // ✅ Essential: error handling settings with correct camelCase
 	}
+	if fcParam, exists := cozeSourceParams(unifiedNode)["fcParam"]; exists {
+		llmInputs["fcParam"] = fcParam
+	}
 
 	return &CozeNode{
 		ID:   cozeNodeID,
@@ -87,6 +91,7 @@ func (g *LLMNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeSc
 	if err := g.ValidateNode(unifiedNode); err != nil {
 		return nil, err
 	}
+	unifiedNode = g.withNamedPromptReferences(unifiedNode)
 
 	cozeNodeID := g.idGenerator.MapToCozeNodeID(unifiedNode.ID)
 
@@ -110,7 +115,8 @@ func (g *LLMNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeSc
 			schemaInput := CozeInputParameter{
 				Name: input.Name,
 				Input: &CozeInputValue{
-					Type: cozeType,
+					Type:   cozeType,
+					Schema: g.generateInputSchema(input.Type),
 					Value: &CozeInputRef{
 						Type: "ref",
 						Content: &CozeRefContent{
@@ -133,6 +139,7 @@ func (g *LLMNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeSc
 		InputParameters: schemaInputParams,
 		LLMParam:        llmParams,     // Adds essential LLM parameter configuration for schema
 		SettingOnError:  errorSettings, // Adds error handling configuration for schema
+		FCParam:         cozeSourceParams(unifiedNode)["fcParam"],
 	}
 
 	return &CozeSchemaNode{
@@ -159,6 +166,21 @@ func (g *LLMNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeSc
 	}, nil
 }
 
+// withNamedPromptReferences returns a copy of the node whose prompts use named placeholders,
+// with an input for every node output the prompts reference
+func (g *LLMNodeGenerator) withNamedPromptReferences(unifiedNode *models.Node) *models.Node {
+	llmConfig, ok := common.AsLLMConfig(unifiedNode.Config)
+	if !ok || llmConfig == nil {
+		return unifiedNode
+	}
+
+	node := *unifiedNode
+	config := *llmConfig
+	node.Inputs = common.NamePromptTemplates(&config, unifiedNode.Inputs)
+	node.Config = &config
+	return &node
+}
+
 // generateInputParameters generates input parameters from unified node inputs
 // Creates properly formatted input parameters for nodes section with required fields
 func (g *LLMNodeGenerator) generateInputParameters(unifiedNode *models.Node) []interface{} {
@@ -189,6 +211,9 @@ func (g *LLMNodeGenerator) generateInputParameters(unifiedNode *models.Node) []i
 				"right":     nil,
 				"variables": []interface{}{},
 			}
+			if schema := g.generateInputSchema(input.Type); schema != nil {
+				inputParam["input"].(map[string]interface{})["schema"] = schema
+			}
 			inputParams = append(inputParams, inputParam)
 		} else {
 			// Handles literal value inputs when available
@@ -218,13 +243,23 @@ func (g *LLMNodeGenerator) generateLLMParameters(unifiedNode *models.Node) ([]ma
 
 	var llmParams []map[string]interface{}
 
-	// Model Type uses fixed Doubao model configuration
+	// Models default to Doubao; Coze sources keep theirs
+	var modelType, modelName, diversity interface{} = "61010", g.mapSparkDomainToCozeModel(*llmConfig), "balance"
+	source := cozeSourceParams(unifiedNode)
+	if source != nil && source["modleName"] == llmConfig.Model.Name {
+		modelType, modelName = source["modelType"], source["modleName"]
+		if value, exists := source["generationDiversity"]; exists {
+			diversity = value
+		}
+	}
+
+	// Model Type
 	llmParams = append(llmParams, map[string]interface{}{
 		"name": "modelType",
 		"input": map[string]interface{}{
 			"type": "integer",
 			"value": map[string]interface{}{
-				"content": "61010",
+				"content": modelType,
 				"rawMeta": map[string]interface{}{
 					"type": 2,
 				},
@@ -233,8 +268,7 @@ func (g *LLMNodeGenerator) generateLLMParameters(unifiedNode *models.Node) ([]ma
 		},
 	})
 
-	// Model Name maps Spark domain to Coze model names
-	modelName := g.mapSparkDomainToCozeModel(*llmConfig)
+	// Model Name
 	llmParams = append(llmParams, map[string]interface{}{
 		"name": "modleName",
 		"input": map[string]interface{}{
@@ -255,7 +289,7 @@ func (g *LLMNodeGenerator) generateLLMParameters(unifiedNode *models.Node) ([]ma
 		"input": map[string]interface{}{
 			"type": "string",
 			"value": map[string]interface{}{
-				"content": "balance",
+				"content": diversity,
 				"rawMeta": map[string]interface{}{
 					"type": 1,
 				},
@@ -321,13 +355,41 @@ func (g *LLMNodeGenerator) generateLLMParameters(unifiedNode *models.Node) ([]ma
 		},
 	})
 
-	// Response Format
+	// Penalties, left at the model defaults when unset
+	penalties := []struct {
+		name  string
+		value float64
+	}{
+		{"frequencyPenalty", llmConfig.Parameters.FrequencyPenalty},
+		{"presencePenalty", llmConfig.Parameters.PresencePenalty},
+	}
+	for _, penalty := range penalties {
+		if penalty.value == 0 {
+			continue
+		}
+		llmParams = append(llmParams, map[string]interface{}{
+			"name": penalty.name,
+			"input": map[string]interface{}{
+				"type": "float",
+				"value": map[string]interface{}{
+					"content": fmt.Sprintf("%g", penalty.value),
+					"rawMeta": map[string]interface{}{
+						"type": 4,
+					},
+					"type": "literal",
+				},
+			},
+		})
+	}
+
+	// Response format; outputs other than one text are fields of a JSON answer
+	responseFormat := common.ResponseFormat(llmConfig, common.StructuredOutputs(*unifiedNode))
 	llmParams = append(llmParams, map[string]interface{}{
 		"name": "responseFormat",
 		"input": map[string]interface{}{
 			"type": "integer",
 			"value": map[string]interface{}{
-				"content": "2",
+				"content": fmt.Sprintf("%d", responseFormat),
 				"rawMeta": map[string]interface{}{
 					"type": 2,
 				},
@@ -338,12 +400,15 @@ func (g *LLMNodeGenerator) generateLLMParameters(unifiedNode *models.Node) ([]ma
 
 	// Prompt corresponds to UserTemplate in iFlytek DSL
 	// Empty UserTemplate indicates iFlytek had placeholder value, map to corresponding Coze format
+	// Coze sources keep their prompts as written
 	prompt := llmConfig.Prompt.UserTemplate
-	if prompt == "" {
+	if prompt == "" && source == nil {
 		prompt = "无" // Map empty UserTemplate to placeholder value for Coze compatibility
 	}
-	// Converts English commas to Chinese commas for Coze format compatibility
-	prompt = g.convertCommasToChineseFormat(prompt)
+	if source == nil {
+		// Converts English commas to Chinese commas for Coze format compatibility
+		prompt = g.convertCommasToChineseFormat(prompt)
+	}
 
 	llmParams = append(llmParams, map[string]interface{}{
 		"name": "prompt",
@@ -359,13 +424,14 @@ func (g *LLMNodeGenerator) generateLLMParameters(unifiedNode *models.Node) ([]ma
 		},
 	})
 
-	// Enable Chat History
+	// Chat history the model sees
+	enableChatHistory, chatHistoryRound := common.ChatHistory(llmConfig.Memory, 3)
 	llmParams = append(llmParams, map[string]interface{}{
 		"name": "enableChatHistory",
 		"input": map[string]interface{}{
 			"type": "boolean",
 			"value": map[string]interface{}{
-				"content": false,
+				"content": enableChatHistory,
 				"rawMeta": map[string]interface{}{
 					"type": 3,
 				},
@@ -380,7 +446,7 @@ func (g *LLMNodeGenerator) generateLLMParameters(unifiedNode *models.Node) ([]ma
 		"input": map[string]interface{}{
 			"type": "integer",
 			"value": map[string]interface{}{
-				"content": "3",
+				"content": fmt.Sprintf("%d", chatHistoryRound),
 				"rawMeta": map[string]interface{}{
 					"type": 2,
 				},
@@ -391,7 +457,7 @@ func (g *LLMNodeGenerator) generateLLMParameters(unifiedNode *models.Node) ([]ma
 
 	// System Prompt gets template from SystemTemplate
 	systemPrompt := llmConfig.Prompt.SystemTemplate
-	if systemPrompt == "" {
+	if systemPrompt == "" && source == nil {
 		systemPrompt = "你是一个有用的AI助手"
 	}
 	llmParams = append(llmParams, map[string]interface{}{
@@ -426,20 +492,20 @@ func (g *LLMNodeGenerator) mapOutputFieldNameForCoze(nodeID, outputName string)
 
 // generateErrorSettings generates error handling settings
 func (g *LLMNodeGenerator) generateErrorSettings(unifiedNode *models.Node) map[string]interface{} {
-	return map[string]interface{}{
+	return withErrorHandling(map[string]interface{}{
 		"processType": 1,
 		"retryTimes":  0,
 		"timeoutMs":   180000,
-	}
+	}, unifiedNode)
 }
 
 // generateSchemaErrorSettings generates error handling settings for schema node
 func (g *LLMNodeGenerator) generateSchemaErrorSettings(unifiedNode *models.Node) map[string]interface{} {
-	return map[string]interface{}{
+	return withErrorHandling(map[string]interface{}{
 		"processType": 1,      // Schema section uses camelCase naming convention
 		"retryTimes":  0,      // Schema section uses camelCase naming convention
 		"timeoutMs":   180000, // Schema section uses camelCase naming convention
-	}
+	}, unifiedNode)
 }
 
 // generateOutputs generates outputs for LLM node
@@ -447,12 +513,16 @@ func (g *LLMNodeGenerator) generateOutputs(unifiedNode *models.Node) []CozeNodeO
 	var outputs []CozeNodeOutput
 
 	// Generate outputs completely based on unified DSL definition - NO hardcoded defaults
-	for _, output := range unifiedNode.Outputs {
-		outputs = append(outputs, CozeNodeOutput{
+	for _, output := range common.StructuredOutputs(*unifiedNode) {
+		cozeOutput := CozeNodeOutput{
 			Name:     output.Name,
 			Type:     g.mapDataTypeToCozeType(output.Type),
 			Required: output.Required,
-		})
+		}
+		if schema := g.generateInputSchema(output.Type); schema != nil {
+			cozeOutput.Schema = &CozeOutputSchema{Type: schema.Type}
+		}
+		outputs = append(outputs, cozeOutput)
 	}
 
 	return outputs
@@ -524,9 +594,22 @@ func (g *LLMNodeGenerator) mapDataTypeToCozeType(dataType models.UnifiedDataType
 		return "float" // Maps generic number to float in Coze
 	case models.DataTypeBoolean:
 		return "boolean"
-	default:
-		return "string"
+	case models.DataTypeObject:
+		return "object"
 	}
+	if models.IsArrayType(dataType) {
+		return "list"
+	}
+	return "string"
+}
+
+// generateInputSchema returns the element type of list inputs and outputs, nil for other types
+func (g *LLMNodeGenerator) generateInputSchema(dataType models.UnifiedDataType) *CozeInputSchema {
+	if !models.IsArrayType(dataType) {
+		return nil
+	}
+	fields := &CodeNodeGenerator{idGenerator: g.idGenerator}
+	return &CozeInputSchema{Type: fields.getArrayElementType(dataType)}
 }
 
 func (g *LLMNodeGenerator) mapDataTypeToRawMetaType(dataType models.UnifiedDataType) int {
@@ -539,9 +622,28 @@ func (g *LLMNodeGenerator) mapDataTypeToRawMetaType(dataType models.UnifiedDataT
 		return 3
 	case models.DataTypeFloat, models.DataTypeNumber:
 		return 4
-	default:
-		return 1
+	case models.DataTypeObject:
+		return 6
+	case models.DataTypeArrayInteger:
+		return 100
+	case models.DataTypeArrayBoolean:
+		return 101
+	case models.DataTypeArrayFloat, models.DataTypeArrayNumber:
+		return 102
+	case models.DataTypeArrayObject:
+		return 103
+	}
+	if models.IsArrayType(dataType) {
+		return 99 // Coze codes lists by element type, strings first
 	}
+	return 1
+}
+
+// cozeSourceParams returns the settings the Coze parser kept for a node, nil for nodes from other
+// platforms
+func cozeSourceParams(unifiedNode *models.Node) map[string]interface{} {
+	params, _ := unifiedNode.PlatformConfig.Coze[common.SourceParamsKey].(map[string]interface{})
+	return params
 }
 
 // mapSparkDomainToCozeModel maps Spark domain to Coze model name
@@ -36,6 +36,12 @@ func (f *NodeGeneratorFactory) registerGenerators() {
 	f.generators[models.NodeTypeCode] = NewCodeNodeGenerator()
 	f.generators[models.NodeTypeClassifier] = NewClassifierNodeGenerator()
 	f.generators[models.NodeTypeIteration] = NewIterationNodeGenerator()
+
+	// Nodes other targets lower: agents with Coze skills, questions, JSON and database nodes
+	f.generators[models.NodeTypeAgent] = NewAgentNodeGenerator()
+	f.generators[models.NodeTypeHumanInput] = NewQuestionNodeGenerator()
+	f.generators[models.NodeTypeJSONProcess] = NewJSONNodeGenerator()
+	f.generators[models.NodeTypeDataStore] = NewDataStoreNodeGenerator()
 }
 
 // GetNodeGenerator returns the appropriate node generator for the given node type
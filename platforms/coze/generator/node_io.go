@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// ioNodeMeta describes the Coze node type of nodes whose settings sit next to plain input
+// parameters and outputs, such as question, JSON and database nodes
+type ioNodeMeta struct {
+	Type      string
+	Subtitle  string
+	MainColor string
+}
+
+// generateIONode builds such a node for the nodes section; inputs holds the node specific
+// settings, the input parameters are added in the format of code nodes
+func generateIONode(idGenerator *CozeIDGenerator, unifiedNode *models.Node, meta ioNodeMeta, parameters []models.Input, inputs map[string]interface{}) *CozeNode {
+	fields := &CodeNodeGenerator{idGenerator: idGenerator}
+	inputs["inputparameters"] = fields.generateInputParameters(&models.Node{Inputs: parameters})
+
+	return &CozeNode{
+		ID:   idGenerator.MapToCozeNodeID(unifiedNode.ID),
+		Type: meta.Type,
+		Meta: &CozeNodeMeta{
+			Position: &CozePosition{X: unifiedNode.Position.X, Y: unifiedNode.Position.Y},
+		},
+		Data: &CozeNodeData{
+			Meta: &CozeNodeMetaInfo{
+				Title:       unifiedNode.Title,
+				Description: unifiedNode.Description,
+				Subtitle:    meta.Subtitle,
+				MainColor:   meta.MainColor,
+			},
+			Outputs: fields.convertToCozeNodeOutputs(fields.generateOutputs(unifiedNode)),
+			Inputs:  inputs,
+		},
+		Blocks:  []interface{}{},
+		Edges:   []interface{}{},
+		Version: "",
+	}
+}
+
+// generateIOSchemaNode builds such a node for the schema section
+func generateIOSchemaNode(idGenerator *CozeIDGenerator, unifiedNode *models.Node, meta ioNodeMeta, parameters []models.Input, inputs map[string]interface{}) *CozeSchemaNode {
+	fields := &CodeNodeGenerator{idGenerator: idGenerator}
+	inputs["inputParameters"] = fields.generateSchemaInputParameters(&models.Node{Inputs: parameters})
+
+	return &CozeSchemaNode{
+		Data: &CozeSchemaNodeData{
+			NodeMeta: &CozeNodeMetaInfo{
+				Title:       unifiedNode.Title,
+				Description: unifiedNode.Description,
+				SubTitle:    meta.Subtitle,
+				MainColor:   meta.MainColor,
+			},
+			Inputs:  inputs,
+			Outputs: fields.generateSchemaOutputs(unifiedNode),
+		},
+		ID:   idGenerator.MapToCozeNodeID(unifiedNode.ID),
+		Type: meta.Type,
+		Meta: &CozeNodeMeta{
+			Position: &CozePosition{X: unifiedNode.Position.X, Y: unifiedNode.Position.Y},
+		},
+	}
+}
+
+// generateIOParamValue builds the input of a Coze param from a variable reference: a node output
+// reference or a literal value
+func generateIOParamValue(idGenerator *CozeIDGenerator, ref *models.VariableReference) map[string]interface{} {
+	fields := &CodeNodeGenerator{idGenerator: idGenerator}
+	if ref == nil {
+		return map[string]interface{}{"type": "string", "value": map[string]interface{}{"type": "literal", "content": ""}}
+	}
+
+	value := map[string]interface{}{"type": "literal", "content": ref.Value}
+	if ref.Type == models.ReferenceTypeNodeOutput {
+		value = map[string]interface{}{
+			"type": "ref",
+			"content": map[string]interface{}{
+				"blockID": idGenerator.MapToCozeNodeID(ref.NodeID),
+				"name":    ref.OutputName,
+				"source":  "block-output",
+			},
+		}
+	}
+	return map[string]interface{}{"type": fields.mapUnifiedTypeToCozeType(ref.DataType), "value": value}
+}
@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// Coze question node settings
+const (
+	questionDefaultPort    = "default"
+	questionDynamicOptions = "dynamic_option" // Input the options of dynamic questions come from
+)
+
+// QuestionNodeGenerator generates Coze question nodes from human input nodes
+type QuestionNodeGenerator struct {
+	idGenerator *CozeIDGenerator
+}
+
+// NewQuestionNodeGenerator creates a question node generator
+func NewQuestionNodeGenerator() *QuestionNodeGenerator {
+	return &QuestionNodeGenerator{}
+}
+
+// SetIDGenerator sets the shared ID generator
+func (g *QuestionNodeGenerator) SetIDGenerator(idGenerator *CozeIDGenerator) {
+	g.idGenerator = idGenerator
+}
+
+// GetNodeType returns the node type this generator handles
+func (g *QuestionNodeGenerator) GetNodeType() models.NodeType {
+	return models.NodeTypeHumanInput
+}
+
+// ValidateNode validates the unified node before generation
+func (g *QuestionNodeGenerator) ValidateNode(unifiedNode *models.Node) error {
+	if unifiedNode == nil {
+		return fmt.Errorf("unified node is nil")
+	}
+	if unifiedNode.Type != models.NodeTypeHumanInput {
+		return fmt.Errorf("invalid node type: expected %s, got %s", models.NodeTypeHumanInput, unifiedNode.Type)
+	}
+	if config, ok := common.AsHumanInputConfig(unifiedNode.Config); !ok || config == nil {
+		return fmt.Errorf("invalid config type: expected HumanInputConfig")
+	}
+	return nil
+}
+
+// GenerateNode generates a Coze question node
+func (g *QuestionNodeGenerator) GenerateNode(unifiedNode *models.Node) (*CozeNode, error) {
+	if err := g.ValidateNode(unifiedNode); err != nil {
+		return nil, err
+	}
+	qa, parameters := g.generateQA(unifiedNode)
+	return generateIONode(g.idGenerator, unifiedNode, g.nodeMeta(), parameters, map[string]interface{}{"qa": qa}), nil
+}
+
+// GenerateSchemaNode generates a Coze schema question node, whose settings sit directly in the inputs
+func (g *QuestionNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*CozeSchemaNode, error) {
+	if err := g.ValidateNode(unifiedNode); err != nil {
+		return nil, err
+	}
+	qa, parameters := g.generateQA(unifiedNode)
+	return generateIOSchemaNode(g.idGenerator, unifiedNode, g.nodeMeta(), parameters, qa), nil
+}
+
+// generateQA builds the question settings and returns them with the input parameters; the
+// options of dynamic questions come from an input, which is not a parameter
+func (g *QuestionNodeGenerator) generateQA(unifiedNode *models.Node) (map[string]interface{}, []models.Input) {
+	config, _ := common.AsHumanInputConfig(unifiedNode.Config)
+	qa := map[string]interface{}{
+		"question":     config.Question,
+		"answer_type":  "text",
+		"limit":        config.MaxRetries,
+		"extra_output": false,
+	}
+	if config.AnswerType != models.HumanInputAnswerOption {
+		return qa, unifiedNode.Inputs
+	}
+
+	qa["answer_type"] = "option"
+	qa["option_type"] = "static"
+	var parameters []models.Input
+	for _, input := range unifiedNode.Inputs {
+		if input.Name == questionDynamicOptions && input.Reference != nil && isDynamicQuestion(config) {
+			qa["option_type"] = "dynamic"
+			qa["dynamic_option"] = generateIOParamValue(g.idGenerator, input.Reference)
+			continue
+		}
+		parameters = append(parameters, input)
+	}
+
+	options := make([]map[string]interface{}, 0, len(config.Options))
+	for _, option := range config.Options {
+		if option.ID == config.DefaultOption {
+			continue
+		}
+		options = append(options, map[string]interface{}{"name": option.Name})
+	}
+	qa["options"] = options
+	return qa, parameters
+}
+
+// nodeMeta returns the Coze node type of questions
+func (g *QuestionNodeGenerator) nodeMeta() ioNodeMeta {
+	return ioNodeMeta{Type: "18", Subtitle: "问答", MainColor: "#3071F2"}
+}
+
+// isDynamicQuestion reports whether the options of a question come from a variable at runtime,
+// which the Coze parser keeps as a single option naming the input
+func isDynamicQuestion(config *models.HumanInputConfig) bool {
+	return len(config.Options) == 1 && strings.TrimSpace(config.Options[0].Name) == "{{"+questionDynamicOptions+"}}"
+}
+
+// questionPort returns the Coze question port of a branch handle: "branch_N" for the option at
+// index N and "default" for the default branch. Handles are option IDs; handles without an option
+// get an empty port.
+func questionPort(config *models.HumanInputConfig, handle string) string {
+	if handle == "" {
+		return ""
+	}
+	if handle == questionDefaultPort || handle == config.DefaultOption {
+		return questionDefaultPort
+	}
+
+	index := 0
+	for _, option := range config.Options {
+		if option.ID == config.DefaultOption {
+			continue
+		}
+		if option.ID == handle {
+			return fmt.Sprintf("branch_%d", index)
+		}
+		index++
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// defaultBranchLevel is the level of iFlytek default branches
+const defaultBranchLevel = 999
+
+// selectorElsePort is the port of the "否则" branch every Coze selector has
+const selectorElsePort = "false"
+
+// defaultBranchHandles are the default branch handles of the source platforms (Dify "false", Coze "__default__")
+var defaultBranchHandles = map[string]bool{
+	"false":       true,
+	"__default__": true,
+	"default":     true,
+}
+
+// selectorBranchCases returns the cases generated as Coze selector branches, in port order.
+// The default case and cases without conditions are left out; the default case is taken
+// through the else port.
+func selectorBranchCases(config *models.ConditionConfig) []models.ConditionCase {
+	cases := make([]models.ConditionCase, 0, len(config.Cases))
+	for _, caseItem := range config.Cases {
+		if isDefaultCase(config, caseItem) || len(caseItem.Conditions) == 0 {
+			continue
+		}
+		cases = append(cases, caseItem)
+	}
+	sort.SliceStable(cases, func(i, j int) bool {
+		return cases[i].Level < cases[j].Level
+	})
+	return cases
+}
+
+// selectorPort returns the Coze selector port of a branch handle following Coze calcPortId:
+// "true" for the first branch, "true_N" for the branch at index N and "false" for the default
+// branch. Handles without a selector branch get an empty port.
+func selectorPort(config *models.ConditionConfig, handle string) string {
+	if handle == "" {
+		return ""
+	}
+	if defaultBranchHandles[handle] || handle == config.DefaultCase {
+		return selectorElsePort
+	}
+	for _, caseItem := range config.Cases {
+		if caseItem.CaseID == handle && isDefaultCase(config, caseItem) {
+			return selectorElsePort
+		}
+	}
+
+	for index, caseItem := range selectorBranchCases(config) {
+		if caseItem.CaseID != handle {
+			continue
+		}
+		if index == 0 {
+			return "true"
+		}
+		return fmt.Sprintf("true_%d", index)
+	}
+	return ""
+}
+
+// isDefaultCase reports whether caseItem is the default branch of the condition
+func isDefaultCase(config *models.ConditionConfig, caseItem models.ConditionCase) bool {
+	return caseItem.Level == defaultBranchLevel || (config.DefaultCase != "" && caseItem.CaseID == config.DefaultCase)
+}
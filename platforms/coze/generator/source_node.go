@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// cozeSourceNode returns the Coze node a placeholder was parsed from, nil for other nodes
+func cozeSourceNode(unifiedNode *models.Node) map[string]interface{} {
+	sourceNode, _ := unifiedNode.PlatformConfig.Coze[common.SourceNodeKey].(map[string]interface{})
+	if sourceNode == nil || !common.IsPlaceholderNode(*unifiedNode) {
+		return nil
+	}
+	if nodeType, _ := sourceNode["type"].(string); nodeType == "" {
+		return nil
+	}
+	return sourceNode
+}
+
+// generateSourceNode emits the Coze node a placeholder was parsed from with its original inputs
+// and outputs, which reference nodes by their generated IDs
+func generateSourceNode(idGenerator *CozeIDGenerator, unifiedNode *models.Node, sourceNode map[string]interface{}) *CozeNode {
+	return &CozeNode{
+		ID:   idGenerator.MapToCozeNodeID(unifiedNode.ID),
+		Type: sourceNode["type"].(string),
+		Meta: &CozeNodeMeta{
+			Position: &CozePosition{X: unifiedNode.Position.X, Y: unifiedNode.Position.Y},
+		},
+		Data: &CozeNodeData{
+			Meta:    sourceNodeMeta(unifiedNode, sourceNode),
+			Outputs: sourceNode["outputs"],
+			Inputs:  remapBlockIDs(idGenerator, sourceNode["inputs"]),
+		},
+		Blocks:  []interface{}{},
+		Edges:   []interface{}{},
+		Version: "",
+	}
+}
+
+// generateSourceSchemaNode emits the schema node of the Coze node a placeholder was parsed from
+func generateSourceSchemaNode(idGenerator *CozeIDGenerator, unifiedNode *models.Node, sourceNode map[string]interface{}) *CozeSchemaNode {
+	meta := sourceNodeMeta(unifiedNode, sourceNode)
+	meta.SubTitle, meta.Subtitle = meta.Subtitle, ""
+	return &CozeSchemaNode{
+		Data: &CozeSchemaNodeData{
+			NodeMeta: meta,
+			Outputs:  sourceNode["outputs"],
+			Inputs:   remapBlockIDs(idGenerator, sourceNode["inputs"]),
+		},
+		ID:   idGenerator.MapToCozeNodeID(unifiedNode.ID),
+		Type: sourceNode["type"].(string),
+		Meta: &CozeNodeMeta{
+			Position: &CozePosition{X: unifiedNode.Position.X, Y: unifiedNode.Position.Y},
+		},
+	}
+}
+
+// sourceNodeMeta restores the title the placeholder title was made from
+func sourceNodeMeta(unifiedNode *models.Node, sourceNode map[string]interface{}) *CozeNodeMetaInfo {
+	title, _ := common.OriginalUnsupportedNodeTitle(unifiedNode.Title)
+	subtitle, _ := sourceNode["subtitle"].(string)
+	icon, _ := sourceNode["icon"].(string)
+	return &CozeNodeMetaInfo{
+		Title:       title,
+		Description: unifiedNode.Description,
+		Icon:        icon,
+		Subtitle:    subtitle,
+	}
+}
+
+// remapBlockIDs returns a copy of source node inputs whose node references use generated IDs
+func remapBlockIDs(idGenerator *CozeIDGenerator, value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		remapped := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			if blockID, ok := item.(string); ok && key == "blockID" && blockID != "" {
+				remapped[key] = idGenerator.MapToCozeNodeID(blockID)
+				continue
+			}
+			remapped[key] = remapBlockIDs(idGenerator, item)
+		}
+		return remapped
+	case []interface{}:
+		remapped := make([]interface{}, len(typed))
+		for i, item := range typed {
+			remapped[i] = remapBlockIDs(idGenerator, item)
+		}
+		return remapped
+	default:
+		return value
+	}
+}
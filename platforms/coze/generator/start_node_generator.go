@@ -2,7 +2,9 @@ package generator
 
 import (
 	"fmt"
+
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 )
 
 // StartNodeGenerator generates Coze start nodes
@@ -97,6 +99,7 @@ func (g *StartNodeGenerator) GenerateSchemaNode(unifiedNode *models.Node) (*Coze
 // generateOutputs generates outputs from unified node
 func (g *StartNodeGenerator) generateOutputs(unifiedNode *models.Node) []CozeNodeOutput {
 	outputs := make([]CozeNodeOutput, 0)
+	variables := g.startVariables(unifiedNode)
 
 	// Process outputs from unified node
 	for _, output := range unifiedNode.Outputs {
@@ -105,6 +108,9 @@ func (g *StartNodeGenerator) generateOutputs(unifiedNode *models.Node) []CozeNod
 			Required: output.Required, // Read required field dynamically
 			Type:     g.mapUnifiedTypeToCoze(output.Type),
 		}
+		if variable, exists := variables[output.Name]; exists {
+			g.applyInputPresentation(&cozeOutput, variable)
+		}
 		outputs = append(outputs, cozeOutput)
 	}
 
@@ -121,6 +127,27 @@ func (g *StartNodeGenerator) generateOutputs(unifiedNode *models.Node) []CozeNod
 	return outputs
 }
 
+// startVariables returns the start variables of the node by name
+func (g *StartNodeGenerator) startVariables(unifiedNode *models.Node) map[string]models.Variable {
+	variables := make(map[string]models.Variable)
+	if startConfig, ok := common.AsStartConfig(unifiedNode.Config); ok && startConfig != nil {
+		for _, variable := range startConfig.Variables {
+			variables[variable.Name] = variable
+		}
+	}
+	return variables
+}
+
+// applyInputPresentation sets the default value and description of a start input; Coze has no
+// placeholder, so the placeholder stands in for a missing description
+func (g *StartNodeGenerator) applyInputPresentation(output *CozeNodeOutput, variable models.Variable) {
+	output.DefaultValue = variable.Default
+	output.Description = variable.Description
+	if output.Description == "" {
+		output.Description = variable.Placeholder
+	}
+}
+
 // mapUnifiedTypeToCoze maps unified data types to Coze types
 func (g *StartNodeGenerator) mapUnifiedTypeToCoze(unifiedType models.UnifiedDataType) string {
 	switch unifiedType {
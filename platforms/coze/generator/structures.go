@@ -6,6 +6,8 @@ type CozeRootStructure struct {
 	Name           string           `yaml:"name" json:"name"`
 	Description    string           `yaml:"description" json:"description"`
 	Version        string           `yaml:"version" json:"version"`
+	Tags           []string         `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Author         string           `yaml:"author,omitempty" json:"author,omitempty"`
 	CreateTime     int64            `yaml:"createtime" json:"createtime"`
 	UpdateTime     int64            `yaml:"updatetime" json:"updatetime"`
 	Schema         *CozeSchema      `yaml:"schema" json:"schema"`
@@ -62,6 +64,9 @@ type CozeNode struct {
 	Edges   []interface{} `yaml:"edges" json:"edges"`
 	Version string        `yaml:"version" json:"version"`
 	Size    interface{}   `yaml:"size" json:"size"`
+
+	// Source keys no field above models, emitted again on Coze round trips
+	Unknown map[string]interface{} `yaml:",inline" json:"-"`
 }
 
 // CozeBlockNode represents a node inside iteration blocks with correct field ordering
@@ -100,6 +105,9 @@ type CozeNodeData struct {
 	Size    interface{}       `yaml:"size" json:"size"`
 	// LLM node specific configuration
 	LLM *CozeLLMConfig `yaml:"llm,omitempty" json:"llm,omitempty"`
+
+	// Source keys no field above models, emitted again on Coze round trips
+	Unknown map[string]interface{} `yaml:",inline" json:"-"`
 }
 
 // CozeNodeMetaInfo represents node meta information
@@ -118,6 +126,10 @@ type CozeNodeOutput struct {
 	Required bool              `yaml:"required" json:"required"`
 	Type     string            `yaml:"type" json:"type"`
 	Schema   *CozeOutputSchema `yaml:"schema,omitempty" json:"schema,omitempty"` // Schema for array/complex types
+
+	// Start node inputs only
+	Description  string      `yaml:"description,omitempty" json:"description,omitempty"`
+	DefaultValue interface{} `yaml:"defaultValue,omitempty" json:"defaultValue,omitempty"`
 }
 
 // CozeOutputSchema represents output schema for array/complex types
@@ -128,6 +140,9 @@ type CozeOutputSchema struct {
 // CozeNodeInputs represents node inputs for end node
 type CozeNodeInputs struct {
 	InputParameters []CozeInputParameter `yaml:"inputParameters" json:"inputParameters"`
+	TerminatePlan   string               `yaml:"terminatePlan,omitempty" json:"terminatePlan,omitempty"`
+	Content         *CozePromptValue     `yaml:"content,omitempty" json:"content,omitempty"` // Answer template of useAnswerContent end nodes
+	StreamingOutput *bool                `yaml:"streamingOutput,omitempty" json:"streamingOutput,omitempty"`
 }
 
 // CozeSchemaNodeInputs represents comprehensive node inputs for schema section (includes LLM params)
@@ -135,6 +150,7 @@ type CozeSchemaNodeInputs struct {
 	InputParameters []CozeInputParameter     `yaml:"inputParameters,omitempty" json:"inputParameters,omitempty"`
 	LLMParam        []map[string]interface{} `yaml:"llmParam,omitempty" json:"llmParam,omitempty"`
 	SettingOnError  map[string]interface{}   `yaml:"settingOnError,omitempty" json:"settingOnError,omitempty"`
+	FCParam         interface{}              `yaml:"fcParam,omitempty" json:"fcParam,omitempty"` // Skills of agents
 }
 
 // CozeInputParameter represents input parameter for end node
@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// cozeNodeTypeTrigger is the Coze trigger node type, which runs the workflow on a schedule or an event
+const cozeNodeTypeTrigger = "34"
+
+// CozeTriggerInputs holds the settings of a trigger node
+type CozeTriggerInputs struct {
+	Trigger *CozeTriggerConfig `yaml:"trigger" json:"trigger"`
+}
+
+// CozeTriggerConfig configures a trigger node: a schedule or an event that runs the workflow
+type CozeTriggerConfig struct {
+	Type       string                 `yaml:"type" json:"type"` // "time" or "event"
+	Crontab    string                 `yaml:"crontab,omitempty" json:"crontab,omitempty"`
+	TimeZone   string                 `yaml:"timezone,omitempty" json:"timeZone,omitempty"`
+	Event      string                 `yaml:"event,omitempty" json:"event,omitempty"`
+	Parameters map[string]interface{} `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+}
+
+// generateTriggerNodes generates a trigger node per workflow trigger, unconnected and stacked
+// above the start node
+func (g *cozeGeneration) generateTriggerNodes(unifiedDSL *models.UnifiedDSL) []CozeNode {
+	var origin models.Position
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if node.Type == models.NodeTypeStart {
+			origin = node.Position
+			break
+		}
+	}
+
+	nodes := make([]CozeNode, 0, len(unifiedDSL.Workflow.Triggers))
+	for i, trigger := range unifiedDSL.Workflow.Triggers {
+		config := &CozeTriggerConfig{Type: "time", Crontab: trigger.Cron, TimeZone: trigger.Timezone, Parameters: trigger.Inputs}
+		if trigger.Type == models.TriggerTypeEvent {
+			config = &CozeTriggerConfig{Type: "event", Event: trigger.Event, Parameters: trigger.Inputs}
+		}
+		title := trigger.Title
+		if title == "" {
+			title = "触发器"
+		}
+		nodes = append(nodes, CozeNode{
+			ID:   g.idGenerator.MapToCozeNodeID(trigger.ID),
+			Type: cozeNodeTypeTrigger,
+			Meta: &CozeNodeMeta{
+				Position: &CozePosition{X: origin.X, Y: origin.Y - float64(200*(i+1))},
+			},
+			Data: &CozeNodeData{
+				Meta:    &CozeNodeMetaInfo{Title: title},
+				Outputs: []CozeNodeOutput{},
+				Inputs:  &CozeTriggerInputs{Trigger: config},
+			},
+			Blocks: []interface{}{},
+			Edges:  []interface{}{},
+		})
+	}
+	return nodes
+}
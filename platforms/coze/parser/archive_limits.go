@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// ErrArchiveLimit rejects ZIP exports that decompress to more than the archive limits allow
+var ErrArchiveLimit = errors.New("ZIP archive exceeds limits")
+
+// zipSignature starts every ZIP archive, including archives stored inside one
+var zipSignature = []byte("PK\x03\x04")
+
+// zipBudget counts what one ZIP export has decompressed so far, across nested archives
+type zipBudget struct {
+	limits models.ArchiveLimits
+	read   int64
+}
+
+// openArchive opens one archive level of the export, rejecting excessive nesting and entry counts
+func (b *zipBudget) openArchive(zipBytes []byte, depth int) (*zip.Reader, error) {
+	if depth > b.limits.MaxNestingDepth {
+		return nil, fmt.Errorf("%w: archives nested more than %d levels deep", ErrArchiveLimit, b.limits.MaxNestingDepth)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ZIP file: %w", err)
+	}
+	if len(zipReader.File) > b.limits.MaxEntries {
+		return nil, fmt.Errorf("%w: %d entries, at most %d allowed", ErrArchiveLimit, len(zipReader.File), b.limits.MaxEntries)
+	}
+	return zipReader, nil
+}
+
+// readEntry decompresses an entry within the remaining budget. The declared size only serves to
+// reject early; the bytes actually inflated are what count.
+func (b *zipBudget) readEntry(ctx context.Context, file *zip.File) ([]byte, error) {
+	remaining := b.limits.MaxDecompressedBytes - b.read
+	if file.UncompressedSize64 > uint64(remaining) {
+		return nil, b.exceeded(file.Name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(common.ContextReader(ctx, reader), remaining+1))
+	b.read += int64(len(content))
+	if int64(len(content)) > remaining {
+		return nil, b.exceeded(file.Name)
+	}
+	return content, err
+}
+
+func (b *zipBudget) exceeded(name string) error {
+	return fmt.Errorf("%w: %s decompresses beyond %d bytes", ErrArchiveLimit, name, b.limits.MaxDecompressedBytes)
+}
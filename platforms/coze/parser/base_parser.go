@@ -49,8 +49,8 @@ func (p *BaseNodeParser) ParseNode(cozeNode CozeNode) (*models.Node, error) {
 		node.Type = models.NodeTypeIteration
 	case "8":
 		node.Type = models.NodeTypeCondition // Selector nodes map to condition type
-	case "21":
-		node.Type = models.NodeTypeIteration // Loop nodes map to iteration type
+	case cozeNodeTypeLoop, cozeNodeTypeBatch:
+		node.Type = models.NodeTypeIteration // Loop and batch nodes map to iteration type
 	case "22":
 		node.Type = models.NodeTypeClassifier // Intent detection nodes map to classifier type
 	default:
@@ -183,18 +183,7 @@ func (p *BaseNodeParser) parseOutputs(cozeNode CozeNode) []models.Output {
 				}
 			}
 
-			switch schemaType {
-			case "string":
-				outputType = models.DataTypeArrayString
-			case "integer":
-				outputType = models.DataTypeArrayString // iFlytek doesn't distinguish array element types
-			case "float":
-				outputType = models.DataTypeArrayString
-			case "boolean":
-				outputType = models.DataTypeArrayString
-			default:
-				outputType = models.DataTypeArrayString
-			}
+			outputType = models.ArrayTypeOf(p.convertDataType(schemaType))
 		} else {
 			// For non-list types, use normal conversion
 			outputType = p.convertDataType(output.Type)
@@ -215,20 +204,10 @@ func (p *BaseNodeParser) parseOutputs(cozeNode CozeNode) []models.Output {
 
 // convertDataType converts Coze data types to unified data types.
 func (p *BaseNodeParser) convertDataType(cozeType string) models.UnifiedDataType {
-	switch cozeType {
-	case "string":
-		return models.DataTypeString
-	case "integer":
-		return models.DataTypeInteger
-	case "float":
-		return models.DataTypeFloat
-	case "boolean":
-		return models.DataTypeBoolean
-	case "array":
-		return models.DataTypeArrayString
-	case "object":
-		return models.DataTypeObject
-	default:
-		return models.DataTypeString // Default to string type
+	mapping := models.GetDefaultDataTypeMapping()
+	unifiedType := mapping.FromCozeType(cozeType)
+	if _, exists := mapping.CozeMapping[unifiedType]; exists {
+		return unifiedType
 	}
+	return models.DataTypeString // Default to string type
 }
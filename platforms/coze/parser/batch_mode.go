@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// batchItemSuffix is appended to the ID of a batch-mode node to name the node run per item
+const batchItemSuffix = "_batch_item"
+
+// cozeBatchMode returns the batch settings of a node running in batch mode, or nil. Coze runs such
+// nodes once per item of inputLists, concurrentSize items at a time, and collects their outputs in
+// outputList; the items are read as references to the node itself.
+func cozeBatchMode(cozeNode CozeNode) map[string]interface{} {
+	if isCozeIterationType(cozeNode.Type) || cozeNode.Data.Inputs == nil {
+		return nil
+	}
+	for _, settings := range []interface{}{cozeNode.Data.Inputs.Batch, cozeNode.Data.Inputs.NodeBatchInfo} {
+		batch, ok := settings.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if enabled, _ := mapValueFold(batch, "batchEnable").(bool); enabled {
+			return batch
+		}
+	}
+	return nil
+}
+
+// batchItemOutputs returns the outputs of one run of a batch-mode node: the fields of the objects
+// in its outputList
+func batchItemOutputs(outputs []CozeOutput) []CozeOutput {
+	if len(outputs) != 1 {
+		return outputs
+	}
+	schema, _ := outputs[0].Schema.(map[string]interface{})
+	fields, _ := mapValueFold(schema, "schema").([]interface{})
+	var itemOutputs []CozeOutput
+	for _, field := range fields {
+		fieldMap, ok := field.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := mapValueFold(fieldMap, "name").(string)
+		fieldType, _ := mapValueFold(fieldMap, "type").(string)
+		if name == "" {
+			continue
+		}
+		itemOutputs = append(itemOutputs, CozeOutput{Name: name, Type: fieldType, Schema: mapValueFold(fieldMap, "schema")})
+	}
+	if len(itemOutputs) == 0 {
+		return outputs
+	}
+	return itemOutputs
+}
+
+// wrapBatchNode turns a node parsed from a batch-mode Coze node into the body of a parallel
+// iteration. The iteration takes the ID of the Coze node, so both the references downstream and the
+// item references of the node itself resolve to it. The node moves into the body under a new ID;
+// the edge starting the body is returned with the iteration. The batch settings and outputs of the
+// Coze node are kept for Coze targets to emit the node in batch mode again.
+func (p *CozeParser) wrapBatchNode(cozeNode CozeNode, node *models.Node, batch map[string]interface{}, outputs []interface{}) (models.Node, models.Edge) {
+	iterationParser := NewIterationNodeParser(p.variableRefSystem)
+	iterationID := cozeNode.ID
+
+	itemName := "input"
+	lists, _ := mapValueFold(batch, "inputLists").([]interface{})
+	if first, ok := firstMap(lists); ok {
+		if name, _ := mapValueFold(first, "name").(string); name != "" {
+			itemName = name
+		}
+	}
+	if len(lists) > 1 {
+		p.ReportIssue("Batch node %q iterates over %d lists together; only %q is kept as the iteration list",
+			node.Title, len(lists), itemName)
+	}
+
+	input := models.Input{
+		Name:     itemName,
+		Label:    itemName,
+		Type:     models.DataTypeArrayString,
+		Required: true,
+	}
+	sourceNode, sourceOutput := iterationParser.batchListReference(batch)
+	if sourceNode != "" {
+		input.Reference = &models.VariableReference{
+			Type:       models.ReferenceTypeNodeOutput,
+			NodeID:     sourceNode,
+			OutputName: sourceOutput,
+			DataType:   input.Type,
+		}
+	}
+
+	inner := *node
+	inner.ID = iterationID + batchItemSuffix
+	inner.Position = models.Position{X: 0, Y: 100}
+	iterationParser.setIterationNodeConfig(&inner, iterationID)
+
+	selector := models.OutputSelectorConfig{NodeID: inner.ID, OutputName: "output"}
+	if len(inner.Outputs) > 0 {
+		selector.OutputName = inner.Outputs[0].Name
+	}
+	if len(inner.Outputs) > 1 {
+		p.ReportIssue("Batch node %q collects %d outputs per item; the iteration keeps only %q",
+			node.Title, len(inner.Outputs), selector.OutputName)
+	}
+
+	iteration := models.Node{
+		ID:          iterationID,
+		Type:        models.NodeTypeIteration,
+		Title:       node.Title,
+		Description: node.Description,
+		Position:    node.Position,
+		Size:        node.Size,
+		Inputs:      []models.Input{input},
+		Outputs: []models.Output{{
+			Name:     "output",
+			Type:     models.DataTypeArrayString,
+			Required: true,
+		}},
+		Config: models.IterationConfig{
+			Iterator: models.IteratorConfig{
+				InputType:    "array",
+				SourceNode:   sourceNode,
+				SourceOutput: sourceOutput,
+			},
+			Execution:      iterationParser.parseExecutionConfig(&CozeNodeInputs{Batch: batch}),
+			SubWorkflow:    models.SubWorkflowConfig{Nodes: []models.Node{inner}},
+			OutputSelector: selector,
+			OutputType:     "array",
+		},
+		PlatformConfig: models.PlatformConfig{
+			Coze: map[string]interface{}{
+				common.BatchNodeKey: map[string]interface{}{"batch": batch, "outputs": outputs},
+			},
+		},
+	}
+	// References to the outputs were resolved to the iteration output, whose name the outputs take
+	for _, output := range outputs {
+		if outputMap, ok := output.(map[string]interface{}); ok && p.variableRefSystem != nil {
+			name, _ := outputMap["name"].(string)
+			outputMap["name"] = p.variableRefSystem.ResolveOutputName(iterationID, name)
+		}
+	}
+	if unknownFields, ok := node.PlatformConfig.Coze[common.UnknownFieldsKey]; ok {
+		iteration.PlatformConfig.Coze[common.UnknownFieldsKey] = unknownFields
+	}
+
+	// The same start edge the internal edges of Coze loops get
+	edge := models.Edge{
+		ID:     fmt.Sprintf("edge-iteration-start-%s-%s", iterationID, inner.ID),
+		Source: iterationID,
+		Target: inner.ID,
+		Type:   models.EdgeTypeDefault,
+		PlatformConfig: models.PlatformConfig{
+			IFlytek: map[string]interface{}{
+				"isIterationStartEdge": true,
+				"originalFromPort":     "loop-function-inline-output",
+				"iterationNodeID":      iterationID,
+			},
+			Dify: make(map[string]interface{}),
+		},
+	}
+	return iteration, edge
+}
+
+// firstMap returns the first element of a list if it is a map
+func firstMap(list []interface{}) (map[string]interface{}, bool) {
+	if len(list) == 0 {
+		return nil, false
+	}
+	m, ok := list[0].(map[string]interface{})
+	return m, ok
+}
@@ -3,9 +3,14 @@ package parser
 
 import (
 	"fmt"
+
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 )
 
+// cozeTopSpeedMode is the intent detection mode of models.ClassifierModeFast
+const cozeTopSpeedMode = "top_speed"
+
 // ClassifierNodeParser handles parsing of classifier (intent detection) nodes
 type ClassifierNodeParser struct {
 	*BaseNodeParser
@@ -90,6 +95,7 @@ func (p *ClassifierNodeParser) parseClassifierConfig(cozeNode CozeNode) (models.
 		}
 	}
 
+	config.Instructions, config.Examples = common.SplitClassifierExamples(config.Instructions, config.Classes)
 	return config, nil
 }
 
@@ -232,9 +238,14 @@ func (p *ClassifierNodeParser) parseIntentDetectorConfig(config *models.Classifi
 			}
 
 			if intentName != "" {
+				// Intents without a description are matched by name
+				description, _ := intentItem["description"].(string)
+				if description == "" {
+					description = intentName
+				}
 				classifierClass := models.ClassifierClass{
 					Name:        intentName,
-					Description: intentName, // Use name as description
+					Description: description,
 					ID:          fmt.Sprintf("class_%d", i),
 				}
 				config.Classes = append(config.Classes, classifierClass)
@@ -242,13 +253,9 @@ func (p *ClassifierNodeParser) parseIntentDetectorConfig(config *models.Classifi
 		}
 	}
 
-	// Parse mode (if available) - mode information is preserved in the classifier config
-	// but not appended to instructions to keep original prompt text clean
-	if modeInterface, exists := intentMap["mode"]; exists {
-		if mode, ok := modeInterface.(string); ok {
-			// Mode information is available but not appended to preserve original prompt purity
-			_ = mode // Keep the mode parsing logic but don't use it for instructions
-		}
+	// Top speed intent detection matches intent names without the full prompt
+	if mode, _ := intentMap["mode"].(string); mode == cozeTopSpeedMode {
+		config.Mode = models.ClassifierModeFast
 	}
 
 	return nil
@@ -253,6 +253,10 @@ func (p *CodeNodeParser) replaceParameterAccess(code string, inputs []models.Inp
 			re := regexp.MustCompile(pattern)
 			convertedCode = re.ReplaceAllString(convertedCode, input.Name)
 		}
+
+		// The parameter extractions Coze code starts with become self-assignments
+		selfAssignment := regexp.MustCompile(fmt.Sprintf(`(?m)^[ \t]*%s = %s[ \t]*\n`, regexp.QuoteMeta(input.Name), regexp.QuoteMeta(input.Name)))
+		convertedCode = selfAssignment.ReplaceAllString(convertedCode, "")
 	}
 
 	return convertedCode
@@ -1,16 +1,15 @@
 package parser
 
 import (
-	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/iflytek/agentbridge/core/interfaces"
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
-	"io"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -19,28 +18,39 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// Compile-time interface check
-var _ interfaces.DSLParser = (*CozeParser)(nil)
+// Compile-time interface checks
+var (
+	_ interfaces.DSLParser     = (*CozeParser)(nil)
+	_ interfaces.ContextParser = (*CozeParser)(nil)
+	_ interfaces.ArchiveParser = (*CozeParser)(nil)
+	_ interfaces.DebugParser   = (*CozeParser)(nil)
+)
 
 // CozeParser parses Coze DSL to unified format.
 type CozeParser struct {
 	*common.BaseParser
 	factory           *ParserFactory
 	variableRefSystem *models.VariableReferenceSystem
-	skippedNodeIDs    map[string]bool // Track skipped node IDs
-	cozeDSL           *CozeDSL        // Reference to complete DSL for enhancement
-	verbose           bool            // Verbose mode flag
+	skippedNodeIDs    map[string]bool      // Track skipped node IDs
+	cozeDSL           *CozeDSL             // Reference to complete DSL for enhancement
+	verbose           bool                 // Verbose mode flag
+	archiveLimits     models.ArchiveLimits // Zero values fall back to models.DefaultArchiveLimits
+	debugArtifacts    common.DebugArtifacts
+	truncated         bool // The last parse salvaged a cut off workflow JSON
 }
 
 func NewCozeParser() *CozeParser {
 	variableRefSystem := models.NewVariableReferenceSystem()
 
-	return &CozeParser{
+	parser := &CozeParser{
 		BaseParser:        common.NewBaseParser(models.PlatformCoze),
 		factory:           NewParserFactory(),
 		variableRefSystem: variableRefSystem,
 		verbose:           false, // Default to non-verbose
 	}
+	parser.factory.SetIssueReporter(parser.ReportIssue)
+	parser.factory.SetPlaceholderConverter(parser.convertUnsupportedNodeToCodeNode)
+	return parser
 }
 
 // SetVerbose sets the verbose mode for debugging output
@@ -48,6 +58,29 @@ func (p *CozeParser) SetVerbose(verbose bool) {
 	p.verbose = verbose
 }
 
+// SetArchiveLimits bounds the decompressed size, entry count and nesting of ZIP exports
+func (p *CozeParser) SetArchiveLimits(limits models.ArchiveLimits) {
+	p.archiveLimits = limits
+}
+
+// SetDebugDir saves the extracted workflow JSON, manifest and Coze YAML of ZIP and raw JSON
+// sources into dir; empty saves nothing
+func (p *CozeParser) SetDebugDir(dir string) {
+	p.debugArtifacts = common.DebugArtifacts{Dir: dir}
+}
+
+// saveDebugArtifact saves an intermediate result when a debug directory is set
+func (p *CozeParser) saveDebugArtifact(name string, data []byte) {
+	p.reportDebugError(p.debugArtifacts.Save(name, data))
+}
+
+// reportDebugError notes in verbose mode why a debug artifact was not saved; debugging never fails a parse
+func (p *CozeParser) reportDebugError(err error) {
+	if err != nil {
+		p.debugPrintf("%v\n", err)
+	}
+}
+
 // debugPrintf prints debug messages only in verbose mode
 func (p *CozeParser) debugPrintf(format string, args ...interface{}) {
 	if p.verbose {
@@ -57,16 +90,47 @@ func (p *CozeParser) debugPrintf(format string, args ...interface{}) {
 
 // Parse parses Coze DSL to unified format.
 func (p *CozeParser) Parse(data []byte) (*models.UnifiedDSL, error) {
+	return p.ParseWithContext(context.Background(), data)
+}
+
+// ParseWithContext parses Coze DSL to unified format, stopping with the context error once ctx is done.
+func (p *CozeParser) ParseWithContext(ctx context.Context, data []byte) (*models.UnifiedDSL, error) {
+	p.ResetIssues()
+	p.truncated = false
+	return p.parse(ctx, data)
+}
+
+// parse converts ZIP and raw JSON sources to Coze YAML and parses that, keeping the issues found on the way
+func (p *CozeParser) parse(ctx context.Context, data []byte) (*models.UnifiedDSL, error) {
+	if p.Lenient() && !p.isZipFormat(data) {
+		if archive := unwrapZip(data); archive != nil {
+			p.ReportIssue("skipped %d wrapper bytes before the ZIP archive", len(data)-len(archive))
+			data = archive
+		}
+	}
+
 	// Detect format and convert ZIP to YAML if needed
 	if p.isZipFormat(data) {
 		p.debugPrintf("Detected ZIP format, converting to YAML\n")
 
-		yamlData, err := p.parseZipToYaml(data)
+		yamlData, err := p.parseZipToYaml(ctx, data)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert ZIP to YAML: %w", err)
 		}
 		// Recursively parse YAML using standard parsing logic
-		return p.Parse(yamlData)
+		return p.parse(ctx, yamlData)
+	}
+	if common.IsCozeWorkflowJSON(data) {
+		p.debugPrintf("Detected raw workflow JSON, converting to YAML\n")
+
+		yamlData, err := p.parseWorkflowJSONToYaml(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert workflow JSON to YAML: %w", err)
+		}
+		return p.parse(ctx, yamlData)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	// Parse YAML format using standard logic
@@ -77,7 +141,7 @@ func (p *CozeParser) Parse(data []byte) (*models.UnifiedDSL, error) {
 
 	// Parse YAML
 	var cozeDSL CozeDSL
-	if err := yaml.Unmarshal(data, &cozeDSL); err != nil {
+	if err := unmarshalCozeDSL(data, &cozeDSL); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
 	}
 
@@ -90,8 +154,11 @@ func (p *CozeParser) Parse(data []byte) (*models.UnifiedDSL, error) {
 		Metadata: models.Metadata{
 			Name:        cozeDSL.Name,
 			Description: cozeDSL.Description,
-			CreatedAt:   time.Unix(cozeDSL.CreateTime, 0),
-			UpdatedAt:   time.Unix(cozeDSL.UpdateTime, 0),
+			CreatedAt:   unixTime(cozeDSL.CreateTime),
+			UpdatedAt:   unixTime(cozeDSL.UpdateTime),
+			Tags:        cozeDSL.Tags,
+			Author:      cozeDSL.Author,
+			Version:     cozeDSL.Version,
 		},
 		PlatformMetadata: models.PlatformMetadata{
 			// TODO: Add Coze-specific metadata structure when needed
@@ -158,7 +225,7 @@ func (p *CozeParser) Parse(data []byte) (*models.UnifiedDSL, error) {
 		}
 	}
 
-	if err := p.parseNodes(allNodes, unifiedDSL); err != nil {
+	if err := p.parseNodes(ctx, allNodes, unifiedDSL); err != nil {
 		return nil, fmt.Errorf("failed to parse nodes: %w", err)
 	}
 
@@ -200,9 +267,16 @@ func (p *CozeParser) Parse(data []byte) (*models.UnifiedDSL, error) {
 		return nil, fmt.Errorf("failed to parse iteration internal edges: %w", err)
 	}
 
+	if p.truncated {
+		p.detachLostReferences(unifiedDSL)
+	}
+
 	// Print conversion summary after parsing is complete
 	p.printConversionSummary(unifiedDSL)
 
+	if err := p.FinishParse(unifiedDSL); err != nil {
+		return nil, err
+	}
 	return unifiedDSL, nil
 }
 
@@ -218,18 +292,24 @@ func (p *CozeParser) getStringFromMap(m map[string]interface{}, key string, defa
 
 // Validate validates Coze DSL format.
 func (p *CozeParser) Validate(data []byte) error {
-	// Support validating Coze ZIP by converting to YAML first
+	// Support validating Coze ZIP and raw workflow JSON by converting to YAML first
 	if p.isZipFormat(data) {
 		p.debugPrintf("Detected ZIP format in Validate, converting to YAML\n")
-		yamlData, err := p.parseZipToYaml(data)
+		yamlData, err := p.parseZipToYaml(context.Background(), data)
 		if err != nil {
 			return fmt.Errorf("invalid ZIP format: %w", err)
 		}
 		data = yamlData
+	} else if common.IsCozeWorkflowJSON(data) {
+		yamlData, err := p.parseWorkflowJSONToYaml(data)
+		if err != nil {
+			return fmt.Errorf("invalid workflow JSON: %w", err)
+		}
+		data = yamlData
 	}
 
 	var cozeDSL CozeDSL
-	if err := yaml.Unmarshal(data, &cozeDSL); err != nil {
+	if err := unmarshalCozeDSL(data, &cozeDSL); err != nil {
 		return fmt.Errorf("invalid YAML format: %w", err)
 	}
 
@@ -245,8 +325,14 @@ func (p *CozeParser) Validate(data []byte) error {
 	return nil
 }
 
+// unmarshalCozeDSL decodes the Coze workflow document of data, which may be one of several
+// concatenated YAML documents and may use anchors and aliases.
+func unmarshalCozeDSL(data []byte, cozeDSL *CozeDSL) error {
+	return common.UnmarshalYAMLDocument(data, cozeDSL, "Coze", "name", "nodes")
+}
+
 // parseNodes parses nodes.
-func (p *CozeParser) parseNodes(cozeNodes []CozeNode, unifiedDSL *models.UnifiedDSL) error {
+func (p *CozeParser) parseNodes(ctx context.Context, cozeNodes []CozeNode, unifiedDSL *models.UnifiedDSL) error {
 	// Track skipped node IDs for edge filtering
 	skippedNodeIDs := make(map[string]bool)
 	p.skippedNodeIDs = skippedNodeIDs // Ensure the parser instance has access to skipped node IDs
@@ -254,12 +340,32 @@ func (p *CozeParser) parseNodes(cozeNodes []CozeNode, unifiedDSL *models.Unified
 	// Pre-register output mappings from iteration nodes for reference resolution
 	p.preRegisterIterationOutputMappings(cozeNodes)
 
-	for _, cozeNode := range cozeNodes {
+	for i, cozeNode := range cozeNodes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		models.ReportNodeProgress(ctx, models.ProgressStageParse, i, len(cozeNodes), cozeNode.ID, cozeNode.Data.Meta.Title)
+
+		// Triggers are kept next to the nodes; their edges, if any, are dropped with them
+		if cozeNode.Type == cozeNodeTypeTrigger {
+			unifiedDSL.Workflow.Triggers = append(unifiedDSL.Workflow.Triggers, p.parseTrigger(cozeNode))
+			skippedNodeIDs[cozeNode.ID] = true
+			continue
+		}
+
 		// Enhance iteration nodes with complete data from schema
-		if cozeNode.Type == "21" {
+		if isCozeIterationType(cozeNode.Type) {
 			p.enhanceIterationNodeWithCompleteData(&cozeNode, p.cozeDSL)
 		}
 
+		// Batch-mode nodes are parsed as the body of an iteration, outputs per item
+		batch := cozeBatchMode(cozeNode)
+		var batchOutputs []interface{}
+		if batch != nil {
+			batchOutputs = cozeOutputMaps(cozeNode.Data.Outputs)
+			cozeNode.Data.Outputs = batchItemOutputs(cozeNode.Data.Outputs)
+		}
+
 		// Use fallback parsing to handle unsupported node types
 		node, supported, err := p.factory.ParseNodeWithFallback(cozeNode, p.variableRefSystem)
 		if err != nil {
@@ -268,7 +374,7 @@ func (p *CozeParser) parseNodes(cozeNodes []CozeNode, unifiedDSL *models.Unified
 
 		if !supported {
 			// Convert unsupported nodes to code node placeholders
-			fmt.Printf("⚠️  Converting unsupported node type '%s' (ID: %s) to code node placeholder\n",
+			p.ReportIssue("Converting unsupported node type '%s' (ID: %s) to code node placeholder",
 				cozeNode.Type, cozeNode.ID)
 
 			node, err = p.convertUnsupportedNodeToCodeNode(cozeNode)
@@ -288,6 +394,20 @@ func (p *CozeParser) parseNodes(cozeNodes []CozeNode, unifiedDSL *models.Unified
 			}
 		}
 
+		// Keep keys the parser does not model for Coze round trips
+		if supported {
+			common.StoreUnknownFields(&node.PlatformConfig.Coze, cozeNode.Type, cozeNode.Unknown, cozeNode.Data.Unknown)
+			if cozeNode.Data.Inputs != nil {
+				node.ErrorHandling = parseSettingOnError(cozeNode.Data.Inputs.SettingOnError)
+			}
+		}
+
+		if batch != nil {
+			iteration, edge := p.wrapBatchNode(cozeNode, node, batch, batchOutputs)
+			node = &iteration
+			unifiedDSL.Workflow.Edges = append(unifiedDSL.Workflow.Edges, edge)
+		}
+
 		unifiedDSL.Workflow.Nodes = append(unifiedDSL.Workflow.Nodes, *node)
 
 		// If this is an iteration node, also add its sub-nodes to the main node list
@@ -398,16 +518,12 @@ func (p *CozeParser) enhanceLLMBlockWithSchemaData(blockMap map[string]interface
 // This ensures mappings are available before other nodes that reference iteration outputs are parsed
 func (p *CozeParser) preRegisterIterationOutputMappings(cozeNodes []CozeNode) {
 	for _, cozeNode := range cozeNodes {
-		// Only process iteration nodes (type "21")
-		if cozeNode.Type == "21" {
-			// Pre-register the standard iteration output mapping: result_list -> output
-			if cozeNode.Data.Outputs != nil {
-				for _, originalOutput := range cozeNode.Data.Outputs {
-					// Register mapping from original Coze output name to standardized iFlytek name
-					if originalOutput.Name != "output" && p.variableRefSystem != nil {
-						p.variableRefSystem.RegisterOutputMapping(cozeNode.ID, originalOutput.Name, "output")
-					}
-				}
+		// Only process iteration nodes and nodes that become iterations
+		if isCozeIterationType(cozeNode.Type) || cozeBatchMode(cozeNode) != nil {
+			// Pre-register the standard iteration output mapping: result_list -> output. Further
+			// outputs keep their names.
+			if len(cozeNode.Data.Outputs) > 0 && cozeNode.Data.Outputs[0].Name != "output" && p.variableRefSystem != nil {
+				p.variableRefSystem.RegisterOutputMapping(cozeNode.ID, cozeNode.Data.Outputs[0].Name, "output")
 			}
 		}
 	}
@@ -458,6 +574,9 @@ func (p *CozeParser) convertCozeSourceHandle(fromPort string, sourceNodeID strin
 	if fromPort == "default" {
 		return "default"
 	}
+	if fromPort == cozeErrorBranchPort {
+		return models.ErrorBranchHandle
+	}
 
 	// Find the source node in unified DSL
 	var sourceNode *models.Node
@@ -479,7 +598,7 @@ func (p *CozeParser) convertCozeSourceHandle(fromPort string, sourceNodeID strin
 
 	// Handle classifier node branch formats
 	if sourceNode.Type == models.NodeTypeClassifier && strings.HasPrefix(fromPort, "branch_") {
-		return p.convertClassifierBranchHandle(fromPort, sourceNode)
+		return p.convertClassifierBranchHandle(fromPort)
 	}
 
 	// Return as-is if no conversion needed
@@ -514,33 +633,17 @@ func (p *CozeParser) convertSelectorBranchHandle(fromPort string, sourceNode *mo
 	return fromPort
 }
 
-// convertClassifierBranchHandle converts classifier node branch handles
-func (p *CozeParser) convertClassifierBranchHandle(fromPort string, sourceNode *models.Node) string {
-	classifierConfig, ok := sourceNode.Config.(models.ClassifierConfig)
-	if !ok {
-		return p.convertBranchToNumeric(fromPort)
-	}
-
-	// Extract branch index
-	branchIndexStr := strings.TrimPrefix(fromPort, "branch_")
-	branchIndex, err := strconv.Atoi(branchIndexStr)
-	if err != nil {
-		return fromPort
-	}
-
-	// Map to 1-based index for iFlytek format
-	if branchIndex >= 0 && branchIndex < len(classifierConfig.Classes) {
-		return fmt.Sprintf("%d", branchIndex+1)
-	}
-
+// convertClassifierBranchHandle converts classifier ports to 1-based intent positions: branch_N -> N+1,
+// for any number of intents
+func (p *CozeParser) convertClassifierBranchHandle(fromPort string) string {
 	return p.convertBranchToNumeric(fromPort)
 }
 
 // parseIterationInternalEdges parses edges inside iteration nodes
 func (p *CozeParser) parseIterationInternalEdges(cozeNodes []CozeNode, unifiedDSL *models.UnifiedDSL) error {
 	for _, cozeNode := range cozeNodes {
-		// Only process iteration nodes (type "21")
-		if cozeNode.Type == "21" && len(cozeNode.Edges) > 0 {
+		// Only process iteration nodes
+		if isCozeIterationType(cozeNode.Type) && len(cozeNode.Edges) > 0 {
 
 			for _, edgeInterface := range cozeNode.Edges {
 				if edgeMap, ok := edgeInterface.(map[string]interface{}); ok {
@@ -567,14 +670,14 @@ func (p *CozeParser) parseIterationInternalEdges(cozeNodes []CozeNode, unifiedDS
 						ToPort:   toPort,
 					}
 
-					// Skip loop-function-inline-input edges (back to iteration node)
-					if cozeEdge.ToPort == "loop-function-inline-input" {
+					// Skip the edges back to the iteration node, at loop-function-inline-input or batch-function-inline-input
+					if cozeEdge.ToPort == "loop-function-inline-input" || cozeEdge.ToPort == "batch-function-inline-input" {
 						continue
 					}
 
 					var edge models.Edge
-					// Convert loop-function-inline-output edges to iteration start edges
-					if cozeEdge.FromPort == "loop-function-inline-output" {
+					// Convert loop-function-inline-output and batch-function-inline-output edges to iteration start edges
+					if cozeEdge.FromPort == "loop-function-inline-output" || cozeEdge.FromPort == "batch-function-inline-output" {
 						// This edge represents connection from iteration start node to internal processing node
 						// We keep the iteration node ID as source, but mark it for special processing
 						// The iFlytek generator will map it to the correct iteration start node ID
@@ -624,28 +727,17 @@ func (p *CozeParser) parseIterationInternalEdges(cozeNodes []CozeNode, unifiedDS
 
 // getStringFromEdgeMap safely extracts string value from edge map
 func (p *CozeParser) getStringFromEdgeMap(edgeMap map[string]interface{}, key string) string {
-	if value, exists := edgeMap[key]; exists {
-		if strValue, ok := value.(string); ok {
-			return strValue
-		}
-	}
-	return ""
+	value, _ := mapValueFold(edgeMap, key).(string)
+	return value
 }
 
-// convertBranchToNumeric provides fallback numeric conversion for branch format
+// convertBranchToNumeric provides fallback numeric conversion for branch format: branch_0 -> 1, branch_N -> N+1
 func (p *CozeParser) convertBranchToNumeric(fromPort string) string {
-	switch fromPort {
-	case "branch_0":
-		return "1"
-	case "branch_1":
-		return "2"
-	case "branch_2":
-		return "3"
-	case "branch_3":
-		return "4"
-	default:
+	branchIndex, err := strconv.Atoi(strings.TrimPrefix(fromPort, "branch_"))
+	if err != nil || branchIndex < 0 || !strings.HasPrefix(fromPort, "branch_") {
 		return fromPort
 	}
+	return strconv.Itoa(branchIndex + 1)
 }
 
 // convertUnsupportedNodeToCodeNode converts unsupported nodes to code node placeholders
@@ -660,7 +752,7 @@ func (p *CozeParser) convertUnsupportedNodeToCodeNode(cozeNode CozeNode) (*model
 	modifiedNode := cozeNode
 	modifiedNode.Type = "5" // Coze code node type
 
-	modifiedNode.Data.Meta.Title = fmt.Sprintf("暂不兼容的节点-%s（请根据需求手动实现）", nodeTitle)
+	modifiedNode.Data.Meta.Title = common.FormatUnsupportedNodeTitle(nodeTitle)
 
 	// Set default code configuration
 	if modifiedNode.Data.Inputs == nil {
@@ -671,7 +763,7 @@ func (p *CozeParser) convertUnsupportedNodeToCodeNode(cozeNode CozeNode) (*model
 	codeRunnerConfig := make(map[string]interface{})
 	codeRunnerConfig["code"] = fmt.Sprintf(`# 抱歉！当前兼容性工具不支持转换此类节点: %s
 
-# 请根据业务需求手动补充实现逻辑`, nodeTitle)
+# %s`, nodeTitle, common.UnsupportedNodeAction)
 	codeRunnerConfig["language"] = "python3"
 	modifiedNode.Data.Inputs.CodeRunner = codeRunnerConfig
 
@@ -690,7 +782,56 @@ func (p *CozeParser) convertUnsupportedNodeToCodeNode(cozeNode CozeNode) (*model
 	}
 
 	// Parse using code node parser
-	return codeParser.ParseNode(modifiedNode)
+	node, err := codeParser.ParseNode(modifiedNode)
+	if err != nil {
+		return nil, err
+	}
+	common.MarkPlaceholder(node, models.PlaceholderMarker{
+		SourcePlatform: models.PlatformCoze,
+		OriginalType:   cozeNode.Type,
+		OriginalID:     cozeNode.ID,
+		Action:         common.UnsupportedNodeAction,
+	})
+	if node.PlatformConfig.Coze == nil {
+		node.PlatformConfig.Coze = make(map[string]interface{})
+	}
+	node.PlatformConfig.Coze[common.SourceNodeKey] = p.placeholderSourceNode(cozeNode)
+	return node, nil
+}
+
+// placeholderSourceNode keeps the type, inputs and outputs of an unsupported node for the Coze
+// generator, which emits it again in place of the placeholder
+func (p *CozeParser) placeholderSourceNode(cozeNode CozeNode) map[string]interface{} {
+	subtitle := cozeNode.Data.Meta.Subtitle
+	if subtitle == "" {
+		subtitle = cozeNode.Data.Meta.SubtitleAlt
+	}
+	sourceNode := map[string]interface{}{
+		"type":     cozeNode.Type,
+		"subtitle": subtitle,
+		"icon":     cozeNode.Data.Meta.Icon,
+		"outputs":  cozeOutputMaps(cozeNode.Data.Outputs),
+	}
+	if cozeNode.Data.Inputs != nil && cozeNode.Data.Inputs.Raw != nil {
+		sourceNode["inputs"] = cozeNode.Data.Inputs.Raw
+	}
+	return sourceNode
+}
+
+// cozeOutputMaps returns node outputs in the form of the source DSL, for generators to emit again
+func cozeOutputMaps(cozeOutputs []CozeOutput) []interface{} {
+	outputs := make([]interface{}, 0, len(cozeOutputs))
+	for _, output := range cozeOutputs {
+		entry := map[string]interface{}{"name": output.Name, "type": output.Type, "required": output.Required}
+		if output.Schema != nil {
+			entry["schema"] = output.Schema
+		}
+		if output.Input != nil {
+			entry["input"] = output.Input
+		}
+		outputs = append(outputs, entry)
+	}
+	return outputs
 }
 
 // extractNodeTitle extracts node title
@@ -711,13 +852,16 @@ func (p *CozeParser) nodeExistsInDSL(nodeID string, unifiedDSL *models.UnifiedDS
 	return false
 }
 func (p *CozeParser) printConversionSummary(unifiedDSL *models.UnifiedDSL) {
+	if p.Quiet() {
+		return
+	}
 	totalNodes := len(unifiedDSL.Workflow.Nodes)
 	fmt.Printf("✅ Conversion Summary: All %d nodes processed successfully\n", totalNodes)
 
-	// Count nodes converted to code placeholders by checking titles
+	// Count nodes converted to code placeholders by their marker
 	convertedCount := 0
 	for _, node := range unifiedDSL.Workflow.Nodes {
-		if strings.Contains(node.Title, "暂不兼容的节点-") {
+		if common.IsPlaceholderNode(node) {
 			convertedCount++
 		}
 	}
@@ -742,8 +886,7 @@ func (p *CozeParser) isZipFormat(data []byte) bool {
 
 	// Check Base64-encoded ZIP (usually starts with UEs)
 	if len(data) > 10 {
-		dataStr := string(data[:50]) // Check first 50 characters
-		if strings.HasPrefix(dataStr, "UEs") || p.isBase64Encoded(data) {
+		if bytes.HasPrefix(data, []byte("UEs")) || p.isBase64Encoded(data) {
 			return true
 		}
 	}
@@ -752,7 +895,7 @@ func (p *CozeParser) isZipFormat(data []byte) bool {
 }
 
 // parseZipToYaml converts ZIP format to YAML format following Coze source implementation
-func (p *CozeParser) parseZipToYaml(data []byte) ([]byte, error) {
+func (p *CozeParser) parseZipToYaml(ctx context.Context, data []byte) ([]byte, error) {
 	p.debugPrintf("Starting ZIP to YAML conversion\n")
 
 	// Step 1: Handle Base64 decoding following Coze source logic
@@ -762,11 +905,16 @@ func (p *CozeParser) parseZipToYaml(data []byte) ([]byte, error) {
 	}
 
 	// Step 2: Extract workflow JSON data following Coze source logic
-	jsonData, manifestData, err := p.extractWorkflowDataFromZip(zipBytes)
+	jsonData, manifestData, err := p.extractWorkflowDataFromZip(ctx, zipBytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract workflow data: %w", err)
 	}
 
+	if p.debugArtifacts.Enabled() {
+		p.reportDebugError(p.debugArtifacts.SaveJSON(common.ArtifactWorkflowJSON, jsonData))
+		p.reportDebugError(p.debugArtifacts.SaveJSON(common.ArtifactManifest, manifestData))
+	}
+
 	// Step 3: Convert to CozeDSL structure
 	cozeDSL, err := p.convertToCozeDSL(jsonData, manifestData)
 	if err != nil {
@@ -781,13 +929,35 @@ func (p *CozeParser) parseZipToYaml(data []byte) ([]byte, error) {
 
 	p.debugPrintf("ZIP to YAML conversion completed, YAML size: %d bytes\n", len(yamlBytes))
 
-	// Debug: Save intermediate results to file
-	if err := os.MkdirAll("../../test_output", 0755); err == nil {
-		if err := os.WriteFile("../../test_output/debug_coze_dsl.yml", yamlBytes, 0644); err != nil {
-		} else {
-		}
+	p.saveDebugArtifact(common.ArtifactCozeDSL, yamlBytes)
+	return yamlBytes, nil
+}
+
+// parseWorkflowJSONToYaml converts the raw workflow JSON some Coze tools export instead of a ZIP
+// package. It is the document a ZIP package wraps, without the MANIFEST.yml.
+func (p *CozeParser) parseWorkflowJSONToYaml(data []byte) ([]byte, error) {
+	var jsonData map[string]interface{}
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF")), &jsonData); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON data: %w", err)
 	}
 
+	manifestData := map[string]interface{}{
+		"main": map[string]interface{}{
+			"desc": "Workflow imported from JSON format",
+		},
+	}
+	cozeDSL, err := p.convertToCozeDSL(jsonData, manifestData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to CozeDSL: %w", err)
+	}
+
+	yamlBytes, err := yaml.Marshal(cozeDSL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	p.debugPrintf("Workflow JSON to YAML conversion completed, YAML size: %d bytes\n", len(yamlBytes))
+	p.saveDebugArtifact(common.ArtifactCozeDSL, yamlBytes)
 	return yamlBytes, nil
 }
 
@@ -850,57 +1020,68 @@ func (p *CozeParser) isBase64Encoded(data []byte) bool {
 }
 
 // extractWorkflowDataFromZip extracts workflow data from ZIP file following Coze source logic
-func (p *CozeParser) extractWorkflowDataFromZip(zipBytes []byte) (map[string]interface{}, map[string]interface{}, error) {
-	// Create ZIP reader following Coze source workflow implementation
-	// Optimization: Use readerAt for better performance
-	reader := bytes.NewReader(zipBytes)
-	zipReader, err := zip.NewReader(reader, int64(len(zipBytes)))
+func (p *CozeParser) extractWorkflowDataFromZip(ctx context.Context, zipBytes []byte) (map[string]interface{}, map[string]interface{}, error) {
+	budget := &zipBudget{limits: p.archiveLimits.WithDefaults()}
+	workflowContent, err := p.readWorkflowContent(ctx, zipBytes, 0, budget)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read ZIP file: %w", err)
+		return nil, nil, err
+	}
+	if len(workflowContent) == 0 {
+		return nil, nil, fmt.Errorf("no workflow content found in ZIP")
 	}
 
-	// Pre-allocate buffer to reduce memory allocations
-	var workflowContent strings.Builder
+	// Extract JSON and MANIFEST data following Coze source logic
+	return p.extractWorkflowDataFromContent(string(workflowContent))
+}
+
+// readWorkflowContent returns the workflow entry of a ZIP archive, or nothing when the archive has
+// none. A workflow entry that is a ZIP archive itself is opened in turn, and its first readable
+// entry taken, up to the nesting limit.
+func (p *CozeParser) readWorkflowContent(ctx context.Context, zipBytes []byte, depth int, budget *zipBudget) ([]byte, error) {
+	zipReader, err := budget.openArchive(zipBytes, depth)
+	if err != nil {
+		if errors.Is(err, ErrArchiveLimit) {
+			return nil, err
+		}
+		if p.Lenient() {
+			return p.salvageWorkflowContent(ctx, zipBytes, depth, budget, err)
+		}
+		return nil, fmt.Errorf("%w: %v", common.ErrDamagedExport, err)
+	}
 
 	// Traverse ZIP file contents following Coze source logic
 	for _, file := range zipReader.File {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		p.debugPrintf("Processing ZIP entry: %s, size: %d\n", file.Name, file.UncompressedSize64)
 
 		// Find workflow files first to avoid unnecessary reads
-		if !strings.Contains(file.Name, "Workflow-") || !strings.HasSuffix(file.Name, ".zip") {
+		if depth == 0 && (!strings.Contains(file.Name, "Workflow-") || !strings.HasSuffix(file.Name, ".zip")) {
 			continue
 		}
 
-		reader, err := file.Open()
-		if err != nil {
-			p.debugPrintf("Failed to open ZIP entry %s: %v\n", file.Name, err)
-			continue
+		// Large entries stop early on cancellation and at the decompression limit
+		content, err := budget.readEntry(ctx, file)
+		if errors.Is(err, ErrArchiveLimit) {
+			return nil, err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
 		}
-
-		// Optimization: Pre-allocate buffer based on file size
-		workflowContent.Grow(int(file.UncompressedSize64))
-
-		// Use efficient copying with pre-allocated buffer
-		content, err := io.ReadAll(reader)
-		reader.Close()
 		if err != nil {
 			p.debugPrintf("Failed to read ZIP entry %s: %v\n", file.Name, err)
 			continue
 		}
 
-		// Write content to builder for efficiency
-		workflowContent.Write(content)
-
+		if bytes.HasPrefix(content, zipSignature) {
+			p.debugPrintf("Opening nested archive: %s\n", file.Name)
+			return p.readWorkflowContent(ctx, content, depth+1, budget)
+		}
 		p.debugPrintf("Found workflow content in: %s\n", file.Name)
-		break
+		return content, nil
 	}
-
-	if workflowContent.Len() == 0 {
-		return nil, nil, fmt.Errorf("no workflow content found in ZIP")
-	}
-
-	// Extract JSON and MANIFEST data following Coze source logic
-	return p.extractWorkflowDataFromContent(workflowContent.String())
+	return nil, nil
 }
 
 // extractWorkflowDataFromContent extracts workflow data and MANIFEST from content following Coze source workflow
@@ -919,33 +1100,48 @@ func (p *CozeParser) extractWorkflowDataFromContent(content string) (map[string]
 	// Step 2: Extract content from JSON start position
 	contentFromJson := content[jsonStart:]
 
-	// Step 3: Simple bracket matching algorithm following Coze source workflow logic
-	braceCount := 0
-	jsonEnd := -1
-	for i, char := range contentFromJson {
-		if char == '{' {
-			braceCount++
-		} else if char == '}' {
-			braceCount--
-			if braceCount == 0 {
-				jsonEnd = i
-				break
-			}
+	// Step 3: Bracket matching, skipping brackets inside strings
+	jsonEnd := scanJSONObject(contentFromJson)
+	jsonMatch := contentFromJson
+	truncated := jsonEnd == -1
+	p.truncated = truncated
+	if truncated {
+		if !p.Lenient() {
+			return nil, nil, fmt.Errorf("%w: the workflow JSON is cut off after %d bytes", common.ErrDamagedExport, len(contentFromJson))
 		}
-	}
-
-	if jsonEnd == -1 {
-		return nil, nil, fmt.Errorf("no complete JSON found, unmatched braces")
+		repaired, dropped, ok := closeTruncatedJSON(p.cleanJsonString(contentFromJson))
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: the workflow JSON is cut off after %d bytes with nothing to salvage", common.ErrDamagedExport, len(contentFromJson))
+		}
+		jsonMatch = repaired
+		p.ReportIssue("the workflow JSON is cut off: dropped its last %d bytes after the last complete node or edge", dropped)
+	} else {
+		jsonMatch = contentFromJson[:jsonEnd+1]
 	}
 
 	// Step 4: JSON data cleaning following Coze source logic
-	jsonMatch := contentFromJson[:jsonEnd+1]
 	cleanJsonString := p.cleanJsonString(jsonMatch)
 
 	// Step 5: Parse JSON data
 	var jsonData map[string]interface{}
 	if err := json.Unmarshal([]byte(cleanJsonString), &jsonData); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse JSON data: %w", err)
+		repaired, removed := removeTrailingCommas(cleanJsonString)
+		if !p.Lenient() || removed == 0 || json.Unmarshal([]byte(repaired), &jsonData) != nil {
+			return nil, nil, fmt.Errorf("failed to parse JSON data: %w", err)
+		}
+		p.ReportIssue("removed %d trailing commas from the workflow JSON", removed)
+	}
+	if truncated {
+		if _, ok := jsonData["nodes"].([]interface{}); !ok {
+			return nil, nil, fmt.Errorf("%w: the workflow JSON is cut off before its nodes", common.ErrDamagedExport)
+		}
+		if _, ok := jsonData["edges"].([]interface{}); !ok {
+			jsonData["edges"] = []interface{}{}
+			p.ReportIssue("the workflow JSON is cut off before its edges: the nodes are left unconnected")
+		}
+		nodes := len(jsonData["nodes"].([]interface{}))
+		dangling := pruneDanglingEdges(jsonData)
+		p.ReportIssue("salvaged %d nodes and %d edges; dropped %d edges to lost nodes", nodes, len(jsonData["edges"].([]interface{})), dangling)
 	}
 
 	// Step 6: Parse MANIFEST.yml
@@ -953,6 +1149,9 @@ func (p *CozeParser) extractWorkflowDataFromContent(content string) (map[string]
 	if err != nil {
 		// MANIFEST parsing failure is not fatal, use default values
 		manifestData = make(map[string]interface{})
+		if truncated {
+			p.ReportIssue("MANIFEST.yml was lost: name, description and version fall back to defaults")
+		}
 	}
 
 	return jsonData, manifestData, nil
@@ -972,6 +1171,9 @@ func (p *CozeParser) cleanJsonString(jsonStr string) string {
 	return cleaned
 }
 
+// manifestMainVersion matches the version of the main entry of MANIFEST.yml, indented below it
+var manifestMainVersion = regexp.MustCompile(`^(?:\n[ \t]+[^\n]*)*?\n[ \t]+version:[ \t]*([^\n\r]*)`)
+
 // parseManifestFromContent parses MANIFEST.yml from content following Coze source workflow logic
 func (p *CozeParser) parseManifestFromContent(content string) (map[string]interface{}, error) {
 	// Use regex and parsing logic identical to Coze source
@@ -982,14 +1184,20 @@ func (p *CozeParser) parseManifestFromContent(content string) (map[string]interf
 		return nil, fmt.Errorf("MANIFEST.yml format not recognized")
 	}
 
+	main := map[string]interface{}{
+		"id":   strings.Trim(strings.TrimSpace(manifestMatch[3]), `"`),
+		"name": strings.Trim(strings.TrimSpace(manifestMatch[4]), `"`),
+		"desc": strings.Trim(strings.TrimSpace(manifestMatch[5]), `"`),
+	}
+	// The top-level version is the manifest format, main.version the one of the workflow
+	rest := content[strings.Index(content, manifestMatch[0])+len(manifestMatch[0]):]
+	if versionMatch := manifestMainVersion.FindStringSubmatch(rest); versionMatch != nil {
+		main["version"] = strings.Trim(strings.TrimSpace(versionMatch[1]), `"`)
+	}
 	manifestData := map[string]interface{}{
 		"type":    strings.TrimSpace(manifestMatch[1]),
 		"version": strings.Trim(strings.TrimSpace(manifestMatch[2]), `"`),
-		"main": map[string]interface{}{
-			"id":   strings.Trim(strings.TrimSpace(manifestMatch[3]), `"`),
-			"name": strings.Trim(strings.TrimSpace(manifestMatch[4]), `"`),
-			"desc": strings.Trim(strings.TrimSpace(manifestMatch[5]), `"`),
-		},
+		"main":    main,
 	}
 
 	return manifestData, nil
@@ -1088,6 +1296,9 @@ func (p *CozeParser) convertNodes(jsonData map[string]interface{}) ([]CozeNode,
 		if edges, ok := nodeWithoutBlocks["edges"].([]interface{}); ok {
 			convertedNode.Edges = edges
 		}
+		if blocks, ok := nodeMap["blocks"].([]interface{}); ok && isCozeIterationType(convertedNode.Type) {
+			convertedNode.Blocks = blocks
+		}
 
 		convertedNodes = append(convertedNodes, convertedNode)
 	}
@@ -1172,6 +1383,9 @@ func (p *CozeParser) buildSchema(jsonData map[string]interface{}) CozeSchema {
 				if nodeType, ok := nodeMap["type"].(string); ok {
 					schemaNode.Type = nodeType
 				}
+				if blocks, ok := nodeMap["blocks"].([]interface{}); ok {
+					schemaNode.Blocks = blocks
+				}
 				// Can add more field mappings as needed
 				schemaNodes = append(schemaNodes, schemaNode)
 			}
@@ -1202,11 +1416,14 @@ func (p *CozeParser) extractDescFromManifest(manifestData map[string]interface{}
 	return "Workflow imported from ZIP format"
 }
 
+// extractVersionFromManifest returns the version of the exported workflow, empty when unset
 func (p *CozeParser) extractVersionFromManifest(manifestData map[string]interface{}) string {
-	if version, ok := manifestData["version"].(string); ok && version != "" {
-		return version
+	if main, ok := manifestData["main"].(map[string]interface{}); ok {
+		if version, ok := main["version"].(string); ok {
+			return version
+		}
 	}
-	return "v1.0.0"
+	return ""
 }
 
 func (p *CozeParser) extractNodeDataFromMap(nodeMap map[string]interface{}) CozeNodeData {
@@ -1281,12 +1498,18 @@ func (p *CozeParser) extractNodeDataFromMap(nodeMap map[string]interface{}) Coze
 func (p *CozeParser) extractInputsData(inputs interface{}, nodeInputs *CozeNodeInputs) {
 	// Following Coze source logic: preserve original inputs data directly
 	if inputsMap, ok := inputs.(map[string]interface{}); ok {
+		nodeInputs.Raw = inputsMap
 
 		// Preserve key LLM parameters following Coze source logic
 		if llmParam, exists := inputsMap["llmParam"]; exists {
 			nodeInputs.LLMParam = llmParam
 		}
 
+		// Preserve LLM skills (function calling tools)
+		if fcParam, exists := inputsMap["fcParam"]; exists {
+			nodeInputs.FCParam = fcParam
+		}
+
 		// Preserve other important parameters
 		if settingOnError, exists := inputsMap["settingOnError"]; exists {
 			nodeInputs.SettingOnError = settingOnError
@@ -1300,6 +1523,9 @@ func (p *CozeParser) extractInputsData(inputs interface{}, nodeInputs *CozeNodeI
 				// If array format, convert to map format
 				intentsMap := make(map[string]interface{})
 				intentsMap["intents"] = intentsList
+				if mode, exists := inputsMap["mode"]; exists {
+					intentsMap["mode"] = mode
+				}
 				nodeInputs.IntentDetector = intentsMap
 			} else {
 				// If format not recognized, create default map structure
@@ -1395,6 +1621,56 @@ func (p *CozeParser) extractInputsData(inputs interface{}, nodeInputs *CozeNodeI
 			}
 		}
 
+		// Preserve database node configuration
+		if databaseNode := extractDatabaseNodeInputs(inputsMap); databaseNode != nil {
+			nodeInputs.DatabaseNode = databaseNode
+		}
+
+		// Preserve question node configuration
+		if qa := extractQuestionNodeInputs(inputsMap); qa != nil {
+			nodeInputs.QA = qa
+		}
+
+		// Preserve loop settings, kept at the top of the inputs of loop nodes
+		if loopType, exists := inputsMap["loopType"]; exists {
+			nodeInputs.Loop = map[string]interface{}{
+				"loopType":           loopType,
+				"loopCount":          inputsMap["loopCount"],
+				"variableParameters": inputsMap["variableParameters"],
+			}
+		}
+
+		// Preserve the branches of selector nodes
+		if branches, ok := inputsMap["branches"].([]interface{}); ok {
+			nodeInputs.Branches = branches
+		}
+
+		// Preserve trigger settings
+		if trigger, ok := inputsMap["triggerConfig"].(map[string]interface{}); ok {
+			nodeInputs.Trigger = decodeTriggerConfig(trigger)
+		}
+
+		// Preserve batch settings, which make batch nodes parallel iterations. Other nodes keep
+		// theirs under "batch" when they run in batch mode. Older batch nodes take their list
+		// from inputParameters instead of inputLists.
+		if batch, ok := inputsMap["batch"].(map[string]interface{}); ok {
+			nodeInputs.Batch = batch
+		} else if inputLists, exists := inputsMap["inputLists"]; exists || inputsMap["concurrentSize"] != nil {
+			nodeInputs.Batch = map[string]interface{}{
+				"batchSize":      inputsMap["batchSize"],
+				"concurrentSize": inputsMap["concurrentSize"],
+				"inputLists":     inputLists,
+			}
+		}
+
+		// Preserve the answer template of end nodes in the outputemitter layout of YAML exports
+		if content, exists := inputsMap["content"]; exists {
+			nodeInputs.OutputEmitter = map[string]interface{}{
+				"content":         content,
+				"streamingoutput": inputsMap["streamingOutput"],
+			}
+		}
+
 		// Preserve terminatePlan for end nodes
 		if terminatePlan, exists := inputsMap["terminatePlan"]; exists {
 			if nodeInputs.Exit == nil {
@@ -1446,6 +1722,7 @@ func (p *CozeParser) convertOutputs(outputs []interface{}) []CozeOutput {
 			if schema, exists := outputMap["schema"]; exists {
 				cozeOutput.Schema = schema
 			}
+			cozeOutput.Input = outputMap["input"]
 			convertedOutputs = append(convertedOutputs, cozeOutput)
 		}
 	}
@@ -1484,3 +1761,12 @@ func (p *CozeParser) buildDependencies(nodes []CozeNode) []CozeDep {
 
 	return dependencies
 }
+
+// unixTime converts Coze timestamps, which are Unix seconds. Exports without them read as the
+// parse time, as Dify and iFlytek exports do, instead of 1970.
+func unixTime(seconds int64) time.Time {
+	if seconds <= 0 {
+		return time.Now()
+	}
+	return time.Unix(seconds, 0)
+}
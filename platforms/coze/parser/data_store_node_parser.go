@@ -0,0 +1,348 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Coze database and variable (memory) node types
+const (
+	cozeNodeTypeVariable       = "11"
+	cozeNodeTypeDatabaseSQL    = "12"
+	cozeNodeTypeDatabaseUpdate = "42"
+	cozeNodeTypeDatabaseQuery  = "43"
+	cozeNodeTypeDatabaseDelete = "44"
+	cozeNodeTypeDatabaseInsert = "46"
+)
+
+// cozeDatabaseInputKeys are the canvas (ZIP/JSON) input keys that make up a database node configuration
+var cozeDatabaseInputKeys = []string{"databaseInfoList", "sql", "selectParam", "insertParam", "updateParam", "deleteParam"}
+
+// dataStoreInputNamePattern matches characters that cannot appear in generated input names
+var dataStoreInputNamePattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// DataStoreNodeParser handles Coze database and variable (memory) node parsing.
+type DataStoreNodeParser struct {
+	*BaseNodeParser
+}
+
+func NewDataStoreNodeParser(nodeType string, variableRefSystem *models.VariableReferenceSystem) NodeParser {
+	return &DataStoreNodeParser{
+		BaseNodeParser: NewBaseNodeParser(nodeType, variableRefSystem),
+	}
+}
+
+// ParseNode parses Coze database / variable node into a unified data store node.
+func (p *DataStoreNodeParser) ParseNode(cozeNode CozeNode) (*models.Node, error) {
+	if err := p.ValidateNode(cozeNode); err != nil {
+		return nil, fmt.Errorf("node validation failed: %w", err)
+	}
+
+	node := p.parseBasicNodeInfo(cozeNode)
+	node.Type = models.NodeTypeDataStore
+	node.Inputs = p.parseInputs(cozeNode)
+	node.Outputs = p.parseOutputs(cozeNode)
+
+	if cozeNode.Type == cozeNodeTypeVariable {
+		node.Config = p.parseMemoryConfig(node)
+		return node, nil
+	}
+
+	config, err := p.parseDatabaseConfig(cozeNode)
+	if err != nil {
+		return nil, err
+	}
+	node.Inputs = append(node.Inputs, p.referenceInputs(config, node.Inputs)...)
+	node.Config = config
+
+	return node, nil
+}
+
+// parseMemoryConfig maps a variable node: inputs are written, outputs are read
+func (p *DataStoreNodeParser) parseMemoryConfig(node *models.Node) models.DataStoreConfig {
+	config := models.DataStoreConfig{
+		Store:     models.DataStoreMemory,
+		Operation: models.DataStoreOperationRead,
+	}
+
+	if len(node.Inputs) > 0 {
+		config.Operation = models.DataStoreOperationWrite
+		for _, input := range node.Inputs {
+			config.Fields = append(config.Fields, models.DataStoreField{Name: input.Name, Value: input.Reference})
+		}
+		return config
+	}
+
+	for _, output := range node.Outputs {
+		config.Fields = append(config.Fields, models.DataStoreField{Name: output.Name})
+	}
+	return config
+}
+
+// parseDatabaseConfig maps the database node configuration of the given operation
+func (p *DataStoreNodeParser) parseDatabaseConfig(cozeNode CozeNode) (models.DataStoreConfig, error) {
+	config := models.DataStoreConfig{Store: models.DataStoreDatabase}
+
+	switch cozeNode.Type {
+	case cozeNodeTypeDatabaseSQL:
+		config.Operation = models.DataStoreOperationSQL
+	case cozeNodeTypeDatabaseQuery:
+		config.Operation = models.DataStoreOperationQuery
+	case cozeNodeTypeDatabaseInsert:
+		config.Operation = models.DataStoreOperationInsert
+	case cozeNodeTypeDatabaseUpdate:
+		config.Operation = models.DataStoreOperationUpdate
+	case cozeNodeTypeDatabaseDelete:
+		config.Operation = models.DataStoreOperationDelete
+	default:
+		return config, fmt.Errorf("unsupported data store node type: %s", cozeNode.Type)
+	}
+
+	var databaseNode map[string]interface{}
+	if cozeNode.Data.Inputs != nil {
+		databaseNode, _ = cozeNode.Data.Inputs.DatabaseNode.(map[string]interface{})
+	}
+	if databaseNode == nil {
+		return config, nil
+	}
+
+	if infoList, ok := lookupValue(databaseNode, "databaseInfoList").([]interface{}); ok && len(infoList) > 0 {
+		if info, ok := infoList[0].(map[string]interface{}); ok {
+			config.Table = formatScalar(lookupValue(info, "databaseInfoID"))
+		}
+	}
+	config.SQL = formatScalar(lookupValue(databaseNode, "sql"))
+
+	switch config.Operation {
+	case models.DataStoreOperationQuery:
+		if selectParam, ok := lookupValue(databaseNode, "selectParam").(map[string]interface{}); ok {
+			p.parseCondition(lookupValue(selectParam, "condition"), &config)
+			config.Fields = p.parseFieldList(lookupValue(selectParam, "fieldList"))
+			config.OrderBy = p.parseOrderBy(lookupValue(selectParam, "orderByList"))
+			if limit, err := strconv.Atoi(formatScalar(lookupValue(selectParam, "limit"))); err == nil {
+				config.Limit = limit
+			}
+		}
+	case models.DataStoreOperationInsert:
+		if insertParam, ok := lookupValue(databaseNode, "insertParam").(map[string]interface{}); ok {
+			config.Fields = p.parseFieldInfo(lookupValue(insertParam, "fieldInfo"))
+		}
+	case models.DataStoreOperationUpdate:
+		if updateParam, ok := lookupValue(databaseNode, "updateParam").(map[string]interface{}); ok {
+			p.parseCondition(lookupValue(updateParam, "condition"), &config)
+			config.Fields = p.parseFieldInfo(lookupValue(updateParam, "fieldInfo"))
+		}
+	case models.DataStoreOperationDelete:
+		if deleteParam, ok := lookupValue(databaseNode, "deleteParam").(map[string]interface{}); ok {
+			p.parseCondition(lookupValue(deleteParam, "condition"), &config)
+		}
+	}
+
+	return config, nil
+}
+
+// parseCondition maps a Coze condition group: each entry holds "left", "operation" and "right" params
+func (p *DataStoreNodeParser) parseCondition(condition interface{}, config *models.DataStoreConfig) {
+	conditionMap, ok := condition.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	config.Logic = strings.ToLower(formatScalar(lookupValue(conditionMap, "logic")))
+
+	entries, _ := lookupValue(conditionMap, "conditionList").([]interface{})
+	for _, entry := range entries {
+		params := p.paramsByName(entry)
+		left := p.parseParamValue(params["left"])
+		if left == nil {
+			continue
+		}
+
+		config.Conditions = append(config.Conditions, models.DataStoreCondition{
+			Field:    formatScalar(left.Value),
+			Operator: strings.ToLower(formatScalar(p.literalValue(params["operation"]))),
+			Value:    p.parseParamValue(params["right"]),
+		})
+	}
+}
+
+// parseFieldInfo maps Coze field assignments: each entry holds "fieldID" and "fieldValue" params
+func (p *DataStoreNodeParser) parseFieldInfo(fieldInfo interface{}) []models.DataStoreField {
+	entries, _ := fieldInfo.([]interface{})
+	fields := make([]models.DataStoreField, 0, len(entries))
+
+	for _, entry := range entries {
+		params := p.paramsByName(entry)
+		name := formatScalar(p.literalValue(params["fieldid"]))
+		if name == "" {
+			continue
+		}
+		fields = append(fields, models.DataStoreField{Name: name, Value: p.parseParamValue(params["fieldvalue"])})
+	}
+
+	return fields
+}
+
+// parseFieldList maps the selected fields of a query
+func (p *DataStoreNodeParser) parseFieldList(fieldList interface{}) []models.DataStoreField {
+	entries, _ := fieldList.([]interface{})
+	fields := make([]models.DataStoreField, 0, len(entries))
+
+	for _, entry := range entries {
+		if entryMap, ok := entry.(map[string]interface{}); ok {
+			if name := formatScalar(lookupValue(entryMap, "fieldID")); name != "" {
+				fields = append(fields, models.DataStoreField{Name: name})
+			}
+		}
+	}
+
+	return fields
+}
+
+// parseOrderBy maps query result ordering
+func (p *DataStoreNodeParser) parseOrderBy(orderByList interface{}) []models.DataStoreOrder {
+	entries, _ := orderByList.([]interface{})
+	orders := make([]models.DataStoreOrder, 0, len(entries))
+
+	for _, entry := range entries {
+		if entryMap, ok := entry.(map[string]interface{}); ok {
+			ascending, _ := lookupValue(entryMap, "isAsc").(bool)
+			orders = append(orders, models.DataStoreOrder{
+				Field:     formatScalar(lookupValue(entryMap, "fieldID")),
+				Ascending: ascending,
+			})
+		}
+	}
+
+	return orders
+}
+
+// paramsByName indexes a list of Coze params by lower-cased name
+func (p *DataStoreNodeParser) paramsByName(entry interface{}) map[string]map[string]interface{} {
+	params := make(map[string]map[string]interface{})
+	list, _ := entry.([]interface{})
+	for _, item := range list {
+		if param, ok := item.(map[string]interface{}); ok {
+			params[strings.ToLower(formatScalar(lookupValue(param, "name")))] = param
+		}
+	}
+	return params
+}
+
+// parseParamValue converts the input of a Coze param into a variable reference
+func (p *DataStoreNodeParser) parseParamValue(param map[string]interface{}) *models.VariableReference {
+	input, ok := lookupValue(param, "input").(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	value, ok := lookupValue(input, "value").(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	dataType := p.convertDataType(formatScalar(lookupValue(input, "type")))
+	content := lookupValue(value, "content")
+
+	if formatScalar(lookupValue(value, "type")) == "ref" {
+		contentMap, ok := content.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		blockID := formatScalar(lookupValue(contentMap, "blockID"))
+		outputName := formatScalar(lookupValue(contentMap, "name"))
+		if p.variableRefSystem != nil {
+			outputName = p.variableRefSystem.ResolveOutputName(blockID, outputName)
+		}
+		return &models.VariableReference{
+			Type:       models.ReferenceTypeNodeOutput,
+			NodeID:     blockID,
+			OutputName: outputName,
+			DataType:   dataType,
+		}
+	}
+
+	return &models.VariableReference{
+		Type:     models.ReferenceTypeLiteral,
+		Value:    content,
+		DataType: dataType,
+	}
+}
+
+// literalValue returns the literal content of a Coze param
+func (p *DataStoreNodeParser) literalValue(param map[string]interface{}) interface{} {
+	if ref := p.parseParamValue(param); ref != nil && ref.Type == models.ReferenceTypeLiteral {
+		return ref.Value
+	}
+	return nil
+}
+
+// referenceInputs exposes the node output references of field values and conditions as node inputs.
+// The inputs share the reference pointers with the configuration so later remapping updates both.
+func (p *DataStoreNodeParser) referenceInputs(config models.DataStoreConfig, existing []models.Input) []models.Input {
+	used := make(map[string]bool, len(existing))
+	for _, input := range existing {
+		used[input.Name] = true
+	}
+
+	var inputs []models.Input
+	addInput := func(field string, ref *models.VariableReference) {
+		if ref == nil || ref.Type != models.ReferenceTypeNodeOutput {
+			return
+		}
+		name := dataStoreInputName(field, used)
+		used[name] = true
+		inputs = append(inputs, models.Input{
+			Name:      name,
+			Label:     field,
+			Type:      ref.DataType,
+			Required:  true,
+			Reference: ref,
+		})
+	}
+
+	for _, field := range config.Fields {
+		addInput(field.Name, field.Value)
+	}
+	for _, condition := range config.Conditions {
+		addInput(condition.Field, condition.Value)
+	}
+
+	return inputs
+}
+
+// dataStoreInputName derives a unique identifier-safe input name from a field name
+func dataStoreInputName(field string, used map[string]bool) string {
+	base := dataStoreInputNamePattern.ReplaceAllString(field, "_")
+	if base == "" || (base[0] >= '0' && base[0] <= '9') {
+		base = "field_" + base
+	}
+
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	return name
+}
+
+// extractDatabaseNodeInputs collects the database configuration from raw node inputs,
+// which use the "databasenode" key in YAML exports and flat camelCase keys on the canvas.
+func extractDatabaseNodeInputs(inputs map[string]interface{}) interface{} {
+	if databaseNode, exists := inputs["databasenode"]; exists && databaseNode != nil {
+		return databaseNode
+	}
+
+	databaseNode := make(map[string]interface{})
+	for _, key := range cozeDatabaseInputKeys {
+		if value, exists := inputs[key]; exists {
+			databaseNode[key] = value
+		}
+	}
+	if len(databaseNode) == 0 {
+		return nil
+	}
+	return databaseNode
+}
@@ -42,8 +42,8 @@ func (p *EndNodeParser) ParseNode(cozeNode CozeNode) (*models.Node, error) {
 
 	// Parse end node specific configuration
 	config := models.EndConfig{
-		OutputMode:   "template", // Coze uses template mode by default
-		StreamOutput: true,       // Default to streaming output based on schema
+		OutputMode:   models.EndOutputModeTemplate, // Coze uses template mode by default
+		StreamOutput: true,                         // Default to streaming output based on schema
 	}
 
 	// Parse template from node inputs if available - check both formats
@@ -96,18 +96,22 @@ func (p *EndNodeParser) ParseNode(cozeNode CozeNode) (*models.Node, error) {
 		}
 	}
 
-	// Set template from exit configuration if available
+	// returnVariables end nodes return their inputs; useAnswerContent end nodes answer with a template
 	if cozeNode.Data.Inputs != nil && cozeNode.Data.Inputs.Exit != nil {
 		if cozeNode.Data.Inputs.Exit.TerminatePlan == "returnVariables" {
-			config.OutputMode = "variables"
+			config.OutputMode = models.EndOutputModeVariables
+		}
+	}
+	if config.OutputMode == models.EndOutputModeTemplate && cozeNode.Data.Inputs != nil {
+		if template, streaming, found := p.parseAnswerContent(cozeNode.Data.Inputs.OutputEmitter); found {
+			config.Template = template
+			config.StreamOutput = streaming
 		}
 	}
 
-	// Try to extract template from schema data if available
-	if len(node.Inputs) > 0 {
-		// Generate template from inputs
-		template := p.generateTemplateFromInputs(node.Inputs)
-		config.Template = template
+	// Without an answer template, the template lists the inputs
+	if config.Template == "" && len(node.Inputs) > 0 {
+		config.Template = p.generateTemplateFromInputs(node.Inputs)
 	}
 
 	node.Config = config
@@ -130,6 +134,22 @@ func (p *EndNodeParser) ValidateNode(cozeNode CozeNode) error {
 	return nil
 }
 
+// parseAnswerContent reads the answer template and streaming flag of an end node output emitter
+func (p *EndNodeParser) parseAnswerContent(outputEmitter interface{}) (string, bool, bool) {
+	emitter, ok := outputEmitter.(map[string]interface{})
+	if !ok {
+		return "", false, false
+	}
+	content, _ := emitter["content"].(map[string]interface{})
+	value, _ := content["value"].(map[string]interface{})
+	template, ok := value["content"].(string)
+	if !ok || template == "" {
+		return "", false, false
+	}
+	streaming, _ := emitter["streamingoutput"].(bool)
+	return template, streaming, true
+}
+
 // generateTemplateFromInputs generates a template string from inputs
 func (p *EndNodeParser) generateTemplateFromInputs(inputs []models.Input) string {
 	if len(inputs) == 0 {
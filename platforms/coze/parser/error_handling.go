@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Coze settingOnError process types
+const (
+	cozeProcessTypeThrow        = 1 // Fail the workflow
+	cozeProcessTypeDefaultValue = 2 // Output dataOnErr
+	cozeProcessTypeErrorBranch  = 3 // Continue along the branch_error port
+)
+
+// cozeErrorBranchPort is the port of the edges taken when a node fails
+const cozeErrorBranchPort = "branch_error"
+
+// parseSettingOnError reads the retries and error strategy of a node, nil when it has neither.
+func parseSettingOnError(settingOnError interface{}) *models.ErrorHandling {
+	settings, ok := settingOnError.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	handling := &models.ErrorHandling{
+		Strategy:   models.ErrorStrategyFail,
+		MaxRetries: cozeLiteralInt(mapValueFold(settings, "retryTimes")),
+	}
+	switch cozeLiteralInt(mapValueFold(settings, "processType")) {
+	case cozeProcessTypeDefaultValue:
+		handling.Strategy = models.ErrorStrategyDefaultValue
+		// dataOnErr is the JSON object of the outputs
+		if data, ok := mapValueFold(settings, "dataOnErr").(string); ok && strings.TrimSpace(data) != "" {
+			_ = json.Unmarshal([]byte(data), &handling.DefaultValues)
+		}
+	case cozeProcessTypeErrorBranch:
+		handling.Strategy = models.ErrorStrategyFailBranch
+	}
+
+	if handling.Strategy == models.ErrorStrategyFail && handling.MaxRetries <= 0 {
+		return nil
+	}
+	return handling
+}
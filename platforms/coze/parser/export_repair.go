@@ -0,0 +1,312 @@
+package parser
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// Repairs of damaged Coze exports, applied in lenient parse mode. Exports arrive cut off by
+// interrupted downloads or wrapped in bytes added by upload forms and chat tools; what they
+// still hold is salvaged and everything dropped is reported as an issue.
+
+// maxWrapperBytes bounds the bytes skipped before a ZIP archive that does not start the input
+const maxWrapperBytes = 4096
+
+// zipLocalHeaderSize is the fixed part of the local file header preceding every ZIP entry
+const zipLocalHeaderSize = 30
+
+// salvagedEntry is a ZIP entry read from its local file header
+type salvagedEntry struct {
+	name     string
+	content  []byte
+	complete bool
+}
+
+// unwrapZip returns the ZIP archive found after wrapper bytes near the start of data, or nil
+func unwrapZip(data []byte) []byte {
+	head := data
+	if len(head) > maxWrapperBytes {
+		head = head[:maxWrapperBytes]
+	}
+	if offset := bytes.Index(head, zipSignature); offset > 0 {
+		return data[offset:]
+	}
+	return nil
+}
+
+// salvageArchive reads the entries of an archive that cannot be opened from their local file
+// headers. Archives cut off lose the central directory at their end, but the entries before the
+// cut are intact; the entry the cut goes through yields what still inflates.
+func (b *zipBudget) salvageArchive(ctx context.Context, data []byte) ([]salvagedEntry, error) {
+	var entries []salvagedEntry
+	offset := bytes.Index(data, zipSignature)
+	for offset >= 0 && len(data)-offset >= zipLocalHeaderSize {
+		if len(entries) == b.limits.MaxEntries {
+			return nil, fmt.Errorf("%w: more than %d entries", ErrArchiveLimit, b.limits.MaxEntries)
+		}
+		header := data[offset : offset+zipLocalHeaderSize]
+		flags := binary.LittleEndian.Uint16(header[6:])
+		method := binary.LittleEndian.Uint16(header[8:])
+		size := int(binary.LittleEndian.Uint32(header[18:]))
+		nameEnd := offset + zipLocalHeaderSize + int(binary.LittleEndian.Uint16(header[26:]))
+		start := nameEnd + int(binary.LittleEndian.Uint16(header[28:]))
+		if start > len(data) {
+			break
+		}
+		entry := salvagedEntry{name: string(data[offset+zipLocalHeaderSize : nameEnd])}
+
+		// Sizes follow the data when flag bit 3 is set; the compressed stream then ends itself
+		sizeKnown := flags&0x8 == 0
+		compressed := data[start:]
+		if sizeKnown && size < len(compressed) {
+			compressed = compressed[:size]
+		}
+		remaining := b.limits.MaxDecompressedBytes - b.read
+		var consumed int
+		switch method {
+		case 0:
+			entry.content = compressed
+			entry.complete = sizeKnown && len(compressed) == size
+			consumed = len(compressed)
+		case 8:
+			source := bytes.NewReader(compressed)
+			inflater := flate.NewReader(source)
+			content, err := io.ReadAll(io.LimitReader(common.ContextReader(ctx, inflater), remaining+1))
+			inflater.Close()
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			entry.content = content
+			entry.complete = err == nil && (!sizeKnown || len(compressed) == size)
+			consumed = len(compressed) - source.Len()
+		default:
+			return entries, fmt.Errorf("entry %s uses unsupported compression method %d", entry.name, method)
+		}
+		b.read += int64(len(entry.content))
+		if int64(len(entry.content)) > remaining {
+			return nil, b.exceeded(entry.name)
+		}
+		entries = append(entries, entry)
+		if !entry.complete {
+			break
+		}
+
+		next := bytes.Index(data[start+consumed:], zipSignature)
+		if next < 0 {
+			break
+		}
+		offset = start + consumed + next
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entries found")
+	}
+	return entries, nil
+}
+
+// salvageWorkflowContent is readWorkflowContent for archives that cannot be opened
+func (p *CozeParser) salvageWorkflowContent(ctx context.Context, zipBytes []byte, depth int, budget *zipBudget, cause error) ([]byte, error) {
+	entries, err := budget.salvageArchive(ctx, zipBytes)
+	if err != nil {
+		if errors.Is(err, ErrArchiveLimit) || ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v, and no entries could be salvaged: %v", common.ErrDamagedExport, cause, err)
+	}
+
+	for _, entry := range entries {
+		if depth == 0 && (!strings.Contains(entry.name, "Workflow-") || !strings.HasSuffix(entry.name, ".zip")) {
+			continue
+		}
+		if entry.complete {
+			p.ReportIssue("damaged ZIP archive (%v): entry %s recovered intact from its local header", cause, entry.name)
+		} else {
+			p.ReportIssue("damaged ZIP archive (%v): entry %s is cut off, %d bytes recovered", cause, entry.name, len(entry.content))
+		}
+		if bytes.HasPrefix(entry.content, zipSignature) {
+			return p.readWorkflowContent(ctx, entry.content, depth+1, budget)
+		}
+		return entry.content, nil
+	}
+	return nil, nil
+}
+
+// scanJSONObject returns the end of the JSON object starting text, skipping braces inside
+// strings, or -1 when the object is cut off
+func scanJSONObject(text string) int {
+	depth := 0
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case inString:
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// closeTruncatedJSON completes a workflow JSON document that is cut off. It is cut back to the
+// last complete element of a top-level array, so a node or edge is either kept whole or dropped,
+// and the open arrays and the root object are closed. It returns the repaired document and the
+// number of bytes dropped, or false when the document holds nothing to keep.
+func closeTruncatedJSON(text string) (string, int, bool) {
+	var stack, kept []byte
+	keep := -1
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+			continue
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				return "", 0, false
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			continue
+		}
+		// The root object and its arrays are the levels at which whole elements end
+		if len(stack) > 0 && len(stack) <= 2 {
+			keep = i + 1
+			kept = append(kept[:0], stack...)
+		}
+	}
+	if keep < 0 {
+		return "", 0, false
+	}
+
+	var repaired strings.Builder
+	repaired.WriteString(text[:keep])
+	for i := len(kept) - 1; i >= 0; i-- {
+		repaired.WriteByte(kept[i])
+	}
+	return repaired.String(), len(text) - keep, true
+}
+
+// removeTrailingCommas drops the commas before closing brackets that hand-edited exports carry,
+// leaving strings alone. It returns the number removed.
+func removeTrailingCommas(text string) (string, int) {
+	var cleaned strings.Builder
+	removed := 0
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		} else if c == '"' {
+			inString = true
+		} else if c == ',' {
+			next := strings.TrimLeft(text[i+1:], " \t\r\n")
+			if strings.HasPrefix(next, "}") || strings.HasPrefix(next, "]") {
+				removed++
+				continue
+			}
+		}
+		cleaned.WriteByte(c)
+	}
+	return cleaned.String(), removed
+}
+
+// pruneDanglingEdges removes the top-level edges to nodes a truncated document lost. It returns
+// the number removed.
+func pruneDanglingEdges(jsonData map[string]interface{}) int {
+	nodeIDs := make(map[string]bool)
+	nodes, _ := jsonData["nodes"].([]interface{})
+	for _, node := range nodes {
+		if nodeMap, ok := node.(map[string]interface{}); ok {
+			nodeIDs[fmt.Sprint(nodeMap["id"])] = true
+		}
+	}
+
+	edges, _ := jsonData["edges"].([]interface{})
+	kept := make([]interface{}, 0, len(edges))
+	for _, edge := range edges {
+		edgeMap, ok := edge.(map[string]interface{})
+		if ok && (!nodeIDs[fmt.Sprint(edgeMap["sourceNodeID"])] || !nodeIDs[fmt.Sprint(edgeMap["targetNodeID"])]) {
+			continue
+		}
+		kept = append(kept, edge)
+	}
+	jsonData["edges"] = kept
+	return len(edges) - len(kept)
+}
+
+// detachLostReferences unsets the inputs that reference nodes a truncated document lost, which
+// would otherwise fail validation, and reports each one
+func (p *CozeParser) detachLostReferences(unifiedDSL *models.UnifiedDSL) {
+	nodeIDs := make(map[string]bool)
+	var collect func(nodes []models.Node)
+	collect = func(nodes []models.Node) {
+		for _, node := range nodes {
+			nodeIDs[node.ID] = true
+			if iterConfig, ok := common.AsIterationConfig(node.Config); ok && iterConfig != nil {
+				collect(iterConfig.SubWorkflow.Nodes)
+			}
+		}
+	}
+	collect(unifiedDSL.Workflow.Nodes)
+
+	var detach func(nodes []models.Node)
+	detach = func(nodes []models.Node) {
+		for i := range nodes {
+			node := &nodes[i]
+			for j := range node.Inputs {
+				reference := node.Inputs[j].Reference
+				if reference != nil && reference.NodeID != "" && !nodeIDs[reference.NodeID] {
+					p.ReportIssue("node %s (%s): input %s referenced lost node %s and is left unset", node.Title, node.ID, node.Inputs[j].Name, reference.NodeID)
+					node.Inputs[j].Reference = nil
+				}
+			}
+			if iterConfig, ok := common.AsIterationConfig(node.Config); ok && iterConfig != nil {
+				detach(iterConfig.SubWorkflow.Nodes)
+			}
+		}
+	}
+	detach(unifiedDSL.Workflow.Nodes)
+}
@@ -3,25 +3,39 @@ package parser
 import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
+// Coze node types that iterate over a list
+const (
+	cozeNodeTypeLoop  = "21"
+	cozeNodeTypeBatch = "28" // Runs its items in parallel
+)
+
+// isCozeIterationType reports whether nodes of the type hold an iteration sub-workflow
+func isCozeIterationType(nodeType string) bool {
+	return nodeType == cozeNodeTypeLoop || nodeType == cozeNodeTypeBatch
+}
+
 // IterationNodeParser parses Coze iteration nodes.
 type IterationNodeParser struct {
 	*BaseNodeParser
+	reportIssue func(format string, args ...interface{}) // Receives converted and skipped blocks, printed when nil
+	placeholder func(CozeNode) (*models.Node, error)     // Converts unsupported blocks, skipped when nil
 }
 
 func NewIterationNodeParser(variableRefSystem *models.VariableReferenceSystem) *IterationNodeParser {
 	return &IterationNodeParser{
-		BaseNodeParser: NewBaseNodeParser("21", variableRefSystem),
+		BaseNodeParser: NewBaseNodeParser(cozeNodeTypeLoop, variableRefSystem),
 	}
 }
 
 // GetSupportedType returns the supported node type.
 func (p *IterationNodeParser) GetSupportedType() string {
-	return "21"
+	return cozeNodeTypeLoop
 }
 
 // ParseNode parses a Coze iteration node into unified DSL.
@@ -46,6 +60,17 @@ func (p *IterationNodeParser) ParseNode(cozeNode CozeNode) (*models.Node, error)
 	// Parse outputs
 	node.Outputs = p.parseIterationOutputs(cozeNode)
 
+	// Keep the Coze outputs, which name the body outputs they collect, for Coze targets
+	if len(cozeNode.Data.Outputs) > 0 {
+		sourceOutputs := cozeOutputMaps(cozeNode.Data.Outputs)
+		for i, output := range sourceOutputs {
+			output.(map[string]interface{})["name"] = node.Outputs[i].Name
+		}
+		node.PlatformConfig.Coze = map[string]interface{}{
+			common.SourceParamsKey: map[string]interface{}{"outputs": sourceOutputs},
+		}
+	}
+
 	// Register output name mapping for variable reference resolution
 	// This ensures other nodes referencing this iteration node use the correct output names
 	p.registerIterationOutputMapping(cozeNode, node)
@@ -57,8 +82,8 @@ func (p *IterationNodeParser) ParseNode(cozeNode CozeNode) (*models.Node, error)
 func (p *IterationNodeParser) parseIterationConfig(cozeNode CozeNode) (models.IterationConfig, error) {
 	config := models.IterationConfig{}
 
-	// Extract loop configuration from inputs
-	if cozeNode.Data.Inputs != nil && cozeNode.Data.Inputs.Loop != nil {
+	// Extract loop configuration from inputs; batch nodes iterate like loops
+	if cozeNode.Data.Inputs != nil && (cozeNode.Data.Inputs.Loop != nil || cozeNode.Data.Inputs.Batch != nil) {
 		loopConfig := p.extractLoopParams(cozeNode.Data.Inputs.Loop)
 
 		// Set iterator configuration
@@ -66,24 +91,29 @@ func (p *IterationNodeParser) parseIterationConfig(cozeNode CozeNode) (models.It
 			InputType: p.getStringParam(loopConfig, "loopType", "array"),
 		}
 
-		// Find source node and output from inputParameters
-		if len(cozeNode.Data.Inputs.InputParameters) > 0 {
-			for _, param := range cozeNode.Data.Inputs.InputParameters {
-				if param.Input.Value.Type == "ref" {
-					config.Iterator.SourceNode = param.Input.Value.Content.BlockID
-					config.Iterator.SourceOutput = param.Input.Value.Content.Name
-					break
+		// Find source node and output from inputParameters, in either spelling
+		inputParams := cozeNode.Data.Inputs.InputParameters
+		if inputParams == nil {
+			inputParams = cozeNode.Data.Inputs.InputParametersAlt
+		}
+		for _, param := range inputParams {
+			if param.Input.Value.Type == "ref" {
+				config.Iterator.SourceNode = param.Input.Value.Content.BlockID
+				config.Iterator.SourceOutput = param.Input.Value.Content.Name
+				if p.variableRefSystem != nil {
+					config.Iterator.SourceOutput = p.variableRefSystem.ResolveOutputName(config.Iterator.SourceNode, config.Iterator.SourceOutput)
 				}
+				break
 			}
 		}
 
-		// Set execution configuration
-		config.Execution = models.ExecutionConfig{
-			IsParallel:      false, // Default for Coze iteration (sequential)
-			ParallelNums:    1,
-			ErrorHandleMode: "stop", // Default error handling
+		// Batch nodes take their list from inputLists
+		if config.Iterator.SourceNode == "" {
+			config.Iterator.SourceNode, config.Iterator.SourceOutput = p.batchListReference(cozeNode.Data.Inputs.Batch)
 		}
 
+		config.Execution = p.parseExecutionConfig(cozeNode.Data.Inputs)
+
 		// Set output type
 		config.OutputType = "array"
 	}
@@ -105,6 +135,86 @@ func (p *IterationNodeParser) parseIterationConfig(cozeNode CozeNode) (models.It
 	return config, nil
 }
 
+// parseExecutionConfig reads the parallelism of an iteration. Loop nodes run one item at a time,
+// batch nodes run concurrentSize items at once; both stop at the first failing item.
+func (p *IterationNodeParser) parseExecutionConfig(inputs *CozeNodeInputs) models.ExecutionConfig {
+	execution := models.ExecutionConfig{
+		ParallelNums:    1,
+		ErrorHandleMode: models.ErrorHandleModeTerminated,
+	}
+	batch, ok := inputs.Batch.(map[string]interface{})
+	if !ok {
+		return execution
+	}
+
+	execution.IsParallel = true
+	execution.ParallelNums = models.DefaultParallelNums
+	if size := cozeLiteralInt(mapValueFold(batch, "concurrentSize")); size > 0 {
+		execution.ParallelNums = size
+	}
+	return execution
+}
+
+// batchListReference returns the node and output of the first list reference of a batch node
+func (p *IterationNodeParser) batchListReference(batch interface{}) (string, string) {
+	batchMap, ok := batch.(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+	lists, _ := mapValueFold(batchMap, "inputLists").([]interface{})
+	for _, list := range lists {
+		listMap, ok := list.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		input, _ := mapValueFold(listMap, "input").(map[string]interface{})
+		value, _ := mapValueFold(input, "value").(map[string]interface{})
+		content, _ := mapValueFold(value, "content").(map[string]interface{})
+		blockID, _ := mapValueFold(content, "blockID").(string)
+		name, _ := mapValueFold(content, "name").(string)
+		if blockID != "" && name != "" {
+			if p.variableRefSystem != nil {
+				name = p.variableRefSystem.ResolveOutputName(blockID, name)
+			}
+			return blockID, name
+		}
+	}
+	return "", ""
+}
+
+// mapValueFold looks a key up ignoring case; Coze exports use both camelCase and lowercase keys
+func mapValueFold(m map[string]interface{}, key string) interface{} {
+	if value, ok := m[key]; ok {
+		return value
+	}
+	for k, value := range m {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+	return nil
+}
+
+// cozeLiteralInt reads an integer given directly or as a literal block input
+// ({type: integer, value: {type: literal, content: "10"}})
+func cozeLiteralInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
+	case map[string]interface{}:
+		if inner := mapValueFold(v, "value"); inner != nil {
+			return cozeLiteralInt(inner)
+		}
+		return cozeLiteralInt(mapValueFold(v, "content"))
+	}
+	return 0
+}
+
 // extractLoopParams converts Loop data to map for easier access
 func (p *IterationNodeParser) extractLoopParams(loop interface{}) map[string]interface{} {
 	params := make(map[string]interface{})
@@ -160,14 +270,30 @@ func (p *IterationNodeParser) parseSpecificBlockType(cozeNode CozeNode, iteratio
 		return p.parseSelectorBlock(cozeNode, iterationID)
 	case "22": // Classifier node - use main layer classifier parser
 		return p.parseClassifierBlock(cozeNode, iterationID)
+	case cozeNodeTypeVariable, cozeNodeTypeDatabaseSQL, cozeNodeTypeDatabaseQuery,
+		cozeNodeTypeDatabaseInsert, cozeNodeTypeDatabaseUpdate, cozeNodeTypeDatabaseDelete:
+		return p.parseDataStoreBlock(cozeNode, iterationID)
 	default:
-		// For unsupported types, skip the node instead of creating basic code node
-		fmt.Printf("⚠️  Skipping unsupported iteration block type '%s' (ID: %s, Title: %s)\n",
+		if p.placeholder != nil {
+			p.issue("Converting unsupported iteration block type '%s' (ID: %s) to code node placeholder",
+				cozeNode.Type, cozeNode.ID)
+			return p.placeholder(cozeNode)
+		}
+		p.issue("Skipping unsupported iteration block type '%s' (ID: %s, Title: %s)",
 			cozeNode.Type, cozeNode.ID, cozeNode.Data.Meta.Title)
 		return nil, nil // Return nil to indicate the node should be skipped
 	}
 }
 
+// issue reports a source problem the parser works around
+func (p *IterationNodeParser) issue(format string, args ...interface{}) {
+	if p.reportIssue == nil {
+		fmt.Printf("⚠️  "+format+"\n", args...)
+		return
+	}
+	p.reportIssue(format, args...)
+}
+
 // parseCodeBlockDetailed parses code blocks with full code extraction and conversion
 func (p *IterationNodeParser) parseCodeBlockDetailed(cozeNode CozeNode, iterationID string) (*models.Node, error) {
 	node := p.parseBasicNodeInfo(cozeNode)
@@ -417,6 +543,14 @@ func (p *IterationNodeParser) setIterationNodeConfig(node *models.Node, iteratio
 		config.IsInIteration = true
 		config.IterationID = iterationID
 		node.Config = config
+	case models.AgentConfig:
+		config.IsInIteration = true
+		config.IterationID = iterationID
+		node.Config = config
+	case models.DataStoreConfig:
+		config.IsInIteration = true
+		config.IterationID = iterationID
+		node.Config = config
 	}
 }
 
@@ -467,9 +601,12 @@ func (p *IterationNodeParser) parseCozeNodeDataFromBlock(data map[string]interfa
 	if outputs, ok := data["outputs"].([]interface{}); ok {
 		for _, output := range outputs {
 			if outputMap, ok := output.(map[string]interface{}); ok {
+				required, _ := outputMap["required"].(bool)
 				nodeData.Outputs = append(nodeData.Outputs, CozeOutput{
-					Name: p.getStringFromMap(outputMap, "name", ""),
-					Type: p.getStringFromMap(outputMap, "type", "string"),
+					Name:     p.getStringFromMap(outputMap, "name", ""),
+					Type:     p.getStringFromMap(outputMap, "type", "string"),
+					Required: required,
+					Schema:   outputMap["schema"],
 				})
 			}
 		}
@@ -477,14 +614,15 @@ func (p *IterationNodeParser) parseCozeNodeDataFromBlock(data map[string]interfa
 
 	// Parse inputs - store the actual inputs data for code extraction
 	if inputs, ok := data["inputs"].(map[string]interface{}); ok {
-		nodeData.Inputs = &CozeNodeInputs{}
+		nodeData.Inputs = &CozeNodeInputs{Raw: inputs}
 
 		// Initialize CodeRunner structure to store both code and inputParameters
-		nodeData.Inputs.CodeRunner = make(map[string]interface{})
+		codeRunner := make(map[string]interface{})
+		nodeData.Inputs.CodeRunner = codeRunner
 
 		// Store code field if it exists
 		if codeField, exists := inputs["code"]; exists {
-			nodeData.Inputs.CodeRunner.(map[string]interface{})["code"] = codeField
+			codeRunner["code"] = codeField
 		}
 
 		// Check if there's a coderunner field with code inside
@@ -492,31 +630,32 @@ func (p *IterationNodeParser) parseCozeNodeDataFromBlock(data map[string]interfa
 			if coderunnerMap, ok := coderunner.(map[string]interface{}); ok {
 				// Extract code from coderunner
 				if code, codeExists := coderunnerMap["code"]; codeExists {
-					nodeData.Inputs.CodeRunner.(map[string]interface{})["code"] = code
+					codeRunner["code"] = code
 				}
 				// Extract language from coderunner
 				if lang, langExists := coderunnerMap["language"]; langExists {
-					nodeData.Inputs.CodeRunner.(map[string]interface{})["language"] = lang
+					codeRunner["language"] = lang
 				}
 			}
 		}
 
 		// Store language field if it exists
 		if langField, exists := inputs["language"]; exists {
-			nodeData.Inputs.CodeRunner.(map[string]interface{})["language"] = langField
+			codeRunner["language"] = langField
 		}
 
 		// Store inputParameters for input parsing (check both cases)
 		if inputParams, exists := inputs["inputParameters"]; exists {
-			nodeData.Inputs.CodeRunner.(map[string]interface{})["inputParameters"] = inputParams
+			codeRunner["inputParameters"] = inputParams
 		} else if inputParams, exists := inputs["inputparameters"]; exists {
-			nodeData.Inputs.CodeRunner.(map[string]interface{})["inputParameters"] = inputParams
+			codeRunner["inputParameters"] = inputParams
 		}
 
 		// Store LLM parameters for LLM node parsing
 		if llmParam, exists := inputs["llmparam"]; exists {
 			nodeData.Inputs.LLMParam = llmParam
 		}
+		nodeData.Inputs.FCParam = lookupValue(inputs, "fcParam")
 
 		// Store all other relevant input fields for different node types
 		nodeData.Inputs.InputParameters = parseInputParametersFromMap(inputs)
@@ -531,6 +670,7 @@ func (p *IterationNodeParser) parseCozeNodeDataFromBlock(data map[string]interfa
 		// Store other fields as interface{}
 		nodeData.Inputs.IntentDetector = inputs["intentdetector"]
 		nodeData.Inputs.Selector = inputs["selector"]
+		nodeData.Inputs.DatabaseNode = extractDatabaseNodeInputs(inputs)
 	}
 
 	return nodeData
@@ -767,6 +907,10 @@ func (p *IterationNodeParser) replaceIterationParameterAccessForSingleInput(code
 		// Replace args.params['paramName'] with paramName
 		re = regexp.MustCompile(fmt.Sprintf(`(?:args\.)?params\[['"]%s['"]\]`, regexp.QuoteMeta(paramName)))
 		convertedCode = re.ReplaceAllString(convertedCode, paramName)
+
+		// The parameter extractions Coze code starts with become self-assignments
+		re = regexp.MustCompile(fmt.Sprintf(`(?m)^[ \t]*%s = %s[ \t]*\n`, regexp.QuoteMeta(paramName), regexp.QuoteMeta(paramName)))
+		convertedCode = re.ReplaceAllString(convertedCode, "")
 	}
 
 	// Add null handling code at the beginning of the function body
@@ -861,12 +1005,17 @@ func (p *IterationNodeParser) parseIterationInputs(cozeNode CozeNode) []models.I
 				Description: "",
 			}
 
-			// Parse reference if it exists
+			// Parse reference if it exists, with the output names of iterations it may point to
 			if param.Input.Value.Type == "ref" {
+				blockID := param.Input.Value.Content.BlockID
+				outputName := param.Input.Value.Content.Name
+				if p.variableRefSystem != nil {
+					outputName = p.variableRefSystem.ResolveOutputName(blockID, outputName)
+				}
 				input.Reference = &models.VariableReference{
 					Type:       models.ReferenceTypeNodeOutput,
-					NodeID:     param.Input.Value.Content.BlockID,
-					OutputName: param.Input.Value.Content.Name,
+					NodeID:     blockID,
+					OutputName: outputName,
 					DataType:   input.Type,
 				}
 			}
@@ -932,17 +1081,23 @@ func (p *IterationNodeParser) findIterationInputSource(cozeNode CozeNode) *model
 func (p *IterationNodeParser) parseIterationOutputs(cozeNode CozeNode) []models.Output {
 	var outputs []models.Output
 
-	if cozeNode.Data.Outputs != nil {
-		for range cozeNode.Data.Outputs {
-			// Force all iteration outputs to use standard "output" name for iFlytek compatibility
-			// This ensures the iteration main node can properly obtain iteration results
-			outputs = append(outputs, models.Output{
-				Name:        "output",                   // Always use "output" name to match iFlytek template
-				Type:        models.DataTypeArrayString, // iFlytek iteration outputs use array-string type
-				Description: "",
-				Required:    true,
-			})
+	for i, cozeOutput := range cozeNode.Data.Outputs {
+		// Force the first iteration output to use standard "output" name for iFlytek compatibility
+		// This ensures the iteration main node can properly obtain iteration results
+		name := "output"
+		if i > 0 {
+			name = cozeOutput.Name
 		}
+		outputs = append(outputs, models.Output{
+			Name:        name,
+			Type:        models.DataTypeArrayString, // iFlytek iteration outputs use array-string type
+			Description: "",
+			Required:    true,
+		})
+	}
+	if len(outputs) > 1 {
+		p.issue("Loop %q collects %d outputs; platforms other than Coze keep only the first",
+			cozeNode.Data.Meta.Title, len(outputs))
 	}
 
 	// Ensure at least one output exists with standard name to match iFlytek template
@@ -1023,14 +1178,10 @@ func (p *IterationNodeParser) isMainWorkflowStartNode(nodeID string) bool {
 // registerIterationOutputMapping registers output name mappings for iteration nodes
 // This ensures other nodes referencing iteration outputs use the correct standardized names
 func (p *IterationNodeParser) registerIterationOutputMapping(cozeNode CozeNode, parsedNode *models.Node) {
-	// If the original Coze node had different output names, register mappings
-	if cozeNode.Data.Outputs != nil {
-		for _, originalOutput := range cozeNode.Data.Outputs {
-			// Register mapping from original Coze output name to standardized iFlytek name
-			if originalOutput.Name != "output" {
-				p.registerNodeOutputMapping(cozeNode.ID, originalOutput.Name, "output")
-			}
-		}
+	// If the first Coze output had a different name, register its mapping; further outputs keep
+	// their names
+	if len(cozeNode.Data.Outputs) > 0 && cozeNode.Data.Outputs[0].Name != "output" {
+		p.registerNodeOutputMapping(cozeNode.ID, cozeNode.Data.Outputs[0].Name, "output")
 	}
 }
 
@@ -1100,6 +1251,24 @@ func (p *IterationNodeParser) parseClassifierBlock(cozeNode CozeNode, iterationI
 	return node, nil
 }
 
+// parseDataStoreBlock parses database and variable nodes within iteration using main layer data store parser
+func (p *IterationNodeParser) parseDataStoreBlock(cozeNode CozeNode, iterationID string) (*models.Node, error) {
+	dataStoreParser := NewDataStoreNodeParser(cozeNode.Type, p.variableRefSystem)
+
+	node, err := dataStoreParser.ParseNode(cozeNode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data store block using main parser: %w", err)
+	}
+
+	// Set iteration-specific configuration
+	p.setIterationNodeConfig(node, iterationID)
+
+	// Process iteration-specific variable references
+	p.processIterationVariableReferences(node, iterationID)
+
+	return node, nil
+}
+
 // processIterationVariableReferences processes variable references for iteration context
 func (p *IterationNodeParser) processIterationVariableReferences(node *models.Node, iterationID string) {
 	// Process input variable references
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Coze JSON serialization and deserialization node types
+const (
+	cozeNodeTypeJSONSerialize   = "58"
+	cozeNodeTypeJSONDeserialize = "59"
+)
+
+// JSONNodeParser handles Coze JSON serialization / deserialization node parsing.
+type JSONNodeParser struct {
+	*BaseNodeParser
+}
+
+func NewJSONNodeParser(nodeType string, variableRefSystem *models.VariableReferenceSystem) NodeParser {
+	return &JSONNodeParser{
+		BaseNodeParser: NewBaseNodeParser(nodeType, variableRefSystem),
+	}
+}
+
+// ParseNode parses Coze JSON node into a unified JSON process node. Both nodes read the
+// "input" parameter and write the "output" field.
+func (p *JSONNodeParser) ParseNode(cozeNode CozeNode) (*models.Node, error) {
+	if err := p.ValidateNode(cozeNode); err != nil {
+		return nil, fmt.Errorf("node validation failed: %w", err)
+	}
+
+	node := p.parseBasicNodeInfo(cozeNode)
+	node.Type = models.NodeTypeJSONProcess
+	node.Inputs = p.parseInputs(cozeNode)
+	node.Outputs = p.parseOutputs(cozeNode)
+
+	config := models.JSONProcessConfig{Operation: models.JSONOperationSerialize}
+	if cozeNode.Type == cozeNodeTypeJSONDeserialize {
+		config.Operation = models.JSONOperationDeserialize
+	}
+	node.Config = config
+
+	if len(node.Outputs) == 0 {
+		outputType := models.DataTypeString
+		if config.Operation == models.JSONOperationDeserialize {
+			outputType = models.DataTypeObject
+		}
+		node.Outputs = []models.Output{{Name: "output", Label: "output", Type: outputType}}
+	}
+
+	return node, nil
+}
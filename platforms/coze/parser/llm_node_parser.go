@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 	"strconv"
 	"strings"
 )
@@ -45,6 +46,7 @@ func (p *LLMNodeParser) ParseNode(cozeNode CozeNode) (*models.Node, error) {
 		return nil, fmt.Errorf("failed to parse LLM config: %w", err)
 	}
 	node.Config = config
+	node.PlatformConfig.Coze = map[string]interface{}{common.SourceParamsKey: p.sourceParams(cozeNode)}
 
 	// Parse inputs from inputParameters
 	node.Inputs = p.parseInputs(cozeNode)
@@ -52,9 +54,99 @@ func (p *LLMNodeParser) ParseNode(cozeNode CozeNode) (*models.Node, error) {
 	// Parse outputs (filtering out reasoning_content)
 	node.Outputs = p.parseNodeOutputs(cozeNode)
 
+	// JSON answers fill the outputs, which make up their schema
+	if config.Parameters.ResponseFormat == models.ResponseFormatJSON {
+		config.OutputSchema = common.SchemaFromOutputs(node.Outputs)
+		node.Config = config
+	}
+
+	// LLM nodes with skills call tools on their own, which makes them agents
+	if tools := p.parseSkills(cozeNode); len(tools) > 0 {
+		node.Type = models.NodeTypeAgent
+		node.Config = models.AgentConfig{
+			Model:      config.Model,
+			Parameters: config.Parameters,
+			Prompt:     config.Prompt,
+			Strategy:   models.AgentStrategyFunctionCalling,
+			Tools:      tools,
+		}
+	}
+
 	return node, nil
 }
 
+// cozeModelParams are the llmParam entries that select the model of a Coze LLM node
+var cozeModelParams = []string{"modelType", "modleName", "generationDiversity"}
+
+// sourceParams keeps the model selection and the skills of an LLM node, which the unified DSL
+// only names, for Coze round trips
+func (p *LLMNodeParser) sourceParams(cozeNode CozeNode) map[string]interface{} {
+	llmParams := p.extractLLMParams(cozeNode.Data.Inputs.LLMParam)
+	params := make(map[string]interface{})
+	for _, key := range cozeModelParams {
+		if value, exists := llmParams[key]; exists {
+			params[key] = value
+		}
+	}
+	if cozeNode.Data.Inputs.FCParam != nil {
+		params["fcParam"] = cozeNode.Data.Inputs.FCParam
+	}
+	return params
+}
+
+// parseSkills extracts the plugin, workflow and knowledge skills of an LLM node
+func (p *LLMNodeParser) parseSkills(cozeNode CozeNode) []models.AgentTool {
+	if cozeNode.Data.Inputs == nil {
+		return nil
+	}
+	fcParam, ok := cozeNode.Data.Inputs.FCParam.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var tools []models.AgentTool
+	for _, item := range p.skillList(fcParam, "pluginFCParam", "pluginList") {
+		tools = append(tools, models.AgentTool{
+			Type:     models.AgentToolPlugin,
+			Provider: formatScalar(lookupValue(item, "plugin_id")),
+			Name:     formatScalar(lookupValue(item, "api_name")),
+		})
+	}
+	for _, item := range p.skillList(fcParam, "workflowFCParam", "workflowList") {
+		tools = append(tools, models.AgentTool{
+			Type:     models.AgentToolWorkflow,
+			Provider: formatScalar(lookupValue(item, "plugin_id")),
+			Name:     formatScalar(lookupValue(item, "workflow_id")),
+		})
+	}
+	for _, item := range p.skillList(fcParam, "knowledgeFCParam", "knowledgeList") {
+		tools = append(tools, models.AgentTool{
+			Type:     models.AgentToolKnowledge,
+			Provider: formatScalar(lookupValue(item, "id")),
+			Name:     formatScalar(lookupValue(item, "name")),
+		})
+	}
+
+	return tools
+}
+
+// skillList returns the entries of one skill group of fcParam
+func (p *LLMNodeParser) skillList(fcParam map[string]interface{}, group, list string) []map[string]interface{} {
+	groupMap, ok := lookupValue(fcParam, group).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	entries, _ := lookupValue(groupMap, list).([]interface{})
+
+	items := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		if item, ok := entry.(map[string]interface{}); ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // parseLLMConfig extracts LLM configuration from Coze node
 func (p *LLMNodeParser) parseLLMConfig(cozeNode CozeNode) (models.LLMConfig, error) {
 	config := models.LLMConfig{}
@@ -78,6 +170,9 @@ func (p *LLMNodeParser) parseLLMConfig(cozeNode CozeNode) (models.LLMConfig, err
 		MaxTokens:      p.getIntParam(llmParams, "maxTokens", 4096),
 		TopP:           p.getFloatParam(llmParams, "topP", 0.7),
 		ResponseFormat: p.getIntParam(llmParams, "responseFormat", 0), // 0=text, 2=json
+
+		FrequencyPenalty: p.getFloatParam(llmParams, "frequencyPenalty", 0),
+		PresencePenalty:  p.getFloatParam(llmParams, "presencePenalty", 0),
 	}
 
 	// Parse prompt configuration
@@ -86,6 +181,14 @@ func (p *LLMNodeParser) parseLLMConfig(cozeNode CozeNode) (models.LLMConfig, err
 		UserTemplate:   p.getStringParam(llmParams, "prompt", ""),
 	}
 
+	// Parse chat history; rounds apply only while it is enabled
+	if p.getBoolParam(llmParams, "enableChatHistory") {
+		config.Memory = &models.MemoryConfig{
+			Enabled: true,
+			Window:  p.getIntParam(llmParams, "chatHistoryRound", 0),
+		}
+	}
+
 	return config, nil
 }
 
@@ -145,6 +248,17 @@ func (p *LLMNodeParser) getFloatParam(params map[string]interface{}, key string,
 	return defaultValue
 }
 
+func (p *LLMNodeParser) getBoolParam(params map[string]interface{}, key string) bool {
+	switch v := params[key].(type) {
+	case bool:
+		return v
+	case string:
+		enabled, _ := strconv.ParseBool(v)
+		return enabled
+	}
+	return false
+}
+
 func (p *LLMNodeParser) getIntParam(params map[string]interface{}, key string, defaultValue int) int {
 	if value, exists := params[key]; exists {
 		switch v := value.(type) {
@@ -7,7 +7,9 @@ import (
 
 // ParserFactory creates Coze node parsers.
 type ParserFactory struct {
-	parsers map[string]func(*models.VariableReferenceSystem) NodeParser
+	parsers     map[string]func(*models.VariableReferenceSystem) NodeParser
+	reportIssue func(format string, args ...interface{}) // Receives problems node parsers work around
+	placeholder func(CozeNode) (*models.Node, error)     // Converts unsupported iteration blocks
 }
 
 func NewParserFactory() *ParserFactory {
@@ -39,19 +41,57 @@ func NewParserFactory() *ParserFactory {
 		return NewClassifierNodeParser(vrs)
 	})
 
-	// Register Iteration node parser (Phase 7)
-	factory.Register("21", func(vrs *models.VariableReferenceSystem) NodeParser {
-		return NewIterationNodeParser(vrs)
-	})
+	// Register Iteration node parser (Phase 7); batch nodes are parallel iterations
+	for _, nodeType := range []string{cozeNodeTypeLoop, cozeNodeTypeBatch} {
+		factory.Register(nodeType, func(vrs *models.VariableReferenceSystem) NodeParser {
+			parser := NewIterationNodeParser(vrs)
+			parser.reportIssue = factory.reportIssue
+			parser.placeholder = factory.placeholder
+			return parser
+		})
+	}
 
 	// Register Selector node parser (Phase 4)
 	factory.Register("8", func(vrs *models.VariableReferenceSystem) NodeParser {
 		return NewSelectorNodeParser(vrs)
 	})
 
+	// Register question node parser
+	factory.Register(cozeNodeTypeQuestion, func(vrs *models.VariableReferenceSystem) NodeParser {
+		return NewQuestionNodeParser(vrs)
+	})
+
+	// Register JSON serialization and deserialization node parsers
+	for _, nodeType := range []string{cozeNodeTypeJSONSerialize, cozeNodeTypeJSONDeserialize} {
+		jsonType := nodeType
+		factory.Register(jsonType, func(vrs *models.VariableReferenceSystem) NodeParser {
+			return NewJSONNodeParser(jsonType, vrs)
+		})
+	}
+
+	// Register database and variable (memory) node parsers
+	for _, nodeType := range []string{cozeNodeTypeVariable, cozeNodeTypeDatabaseSQL, cozeNodeTypeDatabaseQuery,
+		cozeNodeTypeDatabaseInsert, cozeNodeTypeDatabaseUpdate, cozeNodeTypeDatabaseDelete} {
+		dataStoreType := nodeType
+		factory.Register(dataStoreType, func(vrs *models.VariableReferenceSystem) NodeParser {
+			return NewDataStoreNodeParser(dataStoreType, vrs)
+		})
+	}
+
 	return factory
 }
 
+// SetIssueReporter sets the function receiving source problems that node parsers work around.
+func (f *ParserFactory) SetIssueReporter(reportIssue func(format string, args ...interface{})) {
+	f.reportIssue = reportIssue
+}
+
+// SetPlaceholderConverter sets the function converting iteration blocks of unsupported types into
+// placeholders, as top-level nodes are.
+func (f *ParserFactory) SetPlaceholderConverter(placeholder func(CozeNode) (*models.Node, error)) {
+	f.placeholder = placeholder
+}
+
 // Register registers a parser.
 func (f *ParserFactory) Register(nodeType string, creator func(*models.VariableReferenceSystem) NodeParser) {
 	f.parsers[nodeType] = creator
@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// cozeNodeTypeQuestion is the Coze question (ask the user) node type
+const cozeNodeTypeQuestion = "18"
+
+// Coze question node answer and option types
+const (
+	cozeQuestionAnswerOption = "option"
+	cozeQuestionOptionStatic = "static"
+	cozeQuestionDefaultPort  = "default"
+)
+
+// cozeQuestionInputKeys are the canvas (ZIP/JSON) input keys that make up a question node configuration
+var cozeQuestionInputKeys = []string{"question", "answer_type", "option_type", "options", "dynamic_option", "limit", "extra_output"}
+
+// QuestionNodeParser handles Coze question node parsing.
+type QuestionNodeParser struct {
+	*BaseNodeParser
+}
+
+func NewQuestionNodeParser(variableRefSystem *models.VariableReferenceSystem) NodeParser {
+	return &QuestionNodeParser{
+		BaseNodeParser: NewBaseNodeParser(cozeNodeTypeQuestion, variableRefSystem),
+	}
+}
+
+// ParseNode parses Coze question node into a unified human input node.
+func (p *QuestionNodeParser) ParseNode(cozeNode CozeNode) (*models.Node, error) {
+	if err := p.ValidateNode(cozeNode); err != nil {
+		return nil, fmt.Errorf("node validation failed: %w", err)
+	}
+
+	node := p.parseBasicNodeInfo(cozeNode)
+	node.Type = models.NodeTypeHumanInput
+	node.Inputs = p.parseInputs(cozeNode)
+
+	var qa map[string]interface{}
+	if cozeNode.Data.Inputs != nil {
+		qa, _ = cozeNode.Data.Inputs.QA.(map[string]interface{})
+	}
+
+	config := models.HumanInputConfig{
+		Question:   formatScalar(lookupValue(qa, "question")),
+		AnswerType: models.HumanInputAnswerText,
+	}
+	if limit, err := strconv.Atoi(formatScalar(lookupValue(qa, "limit"))); err == nil {
+		config.MaxRetries = limit
+	}
+
+	if formatScalar(lookupValue(qa, "answer_type")) == cozeQuestionAnswerOption {
+		config.AnswerType = models.HumanInputAnswerOption
+		config.Options = p.parseOptions(qa, node)
+		config.DefaultOption = cozeQuestionDefaultPort
+	}
+	node.Config = config
+
+	node.Outputs = p.parseOutputs(cozeNode)
+	if len(node.Outputs) == 0 {
+		node.Outputs = p.defaultOutputs(config.AnswerType)
+	}
+
+	return node, nil
+}
+
+// parseOptions maps answer options to the "branch_N" ports their edges leave from.
+// Dynamic options come from a variable at runtime, so they share a single branch.
+func (p *QuestionNodeParser) parseOptions(qa map[string]interface{}, node *models.Node) []models.HumanInputOption {
+	optionType := formatScalar(lookupValue(qa, "option_type"))
+	if optionType != "" && optionType != cozeQuestionOptionStatic {
+		if reference := p.parseDynamicOption(lookupValue(qa, "dynamic_option")); reference != nil {
+			node.Inputs = append(node.Inputs, models.Input{
+				Name:      "dynamic_option",
+				Type:      models.DataTypeArrayString,
+				Reference: reference,
+			})
+		}
+		return []models.HumanInputOption{{ID: "branch_0", Name: "{{dynamic_option}}"}}
+	}
+
+	entries, _ := lookupValue(qa, "options").([]interface{})
+	options := make([]models.HumanInputOption, 0, len(entries))
+	for i, entry := range entries {
+		option, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		options = append(options, models.HumanInputOption{
+			ID:   fmt.Sprintf("branch_%d", i),
+			Name: formatScalar(lookupValue(option, "name")),
+		})
+	}
+	return options
+}
+
+// parseDynamicOption reads the variable reference the dynamic options come from
+func (p *QuestionNodeParser) parseDynamicOption(dynamicOption interface{}) *models.VariableReference {
+	optionMap, ok := dynamicOption.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	value, _ := lookupValue(optionMap, "value").(map[string]interface{})
+	content, _ := lookupValue(value, "content").(map[string]interface{})
+	blockID := formatScalar(lookupValue(content, "blockID"))
+	if blockID == "" {
+		return nil
+	}
+
+	return &models.VariableReference{
+		Type:       models.ReferenceTypeNodeOutput,
+		NodeID:     blockID,
+		OutputName: formatScalar(lookupValue(content, "name")),
+		DataType:   models.DataTypeArrayString,
+	}
+}
+
+// defaultOutputs returns the outputs Coze question nodes produce for an answer type
+func (p *QuestionNodeParser) defaultOutputs(answerType string) []models.Output {
+	if answerType == models.HumanInputAnswerOption {
+		return []models.Output{
+			{Name: "optionId", Label: "optionId", Type: models.DataTypeString},
+			{Name: "optionContent", Label: "optionContent", Type: models.DataTypeString},
+		}
+	}
+	return []models.Output{{Name: "USER_RESPONSE", Label: "USER_RESPONSE", Type: models.DataTypeString}}
+}
+
+// extractQuestionNodeInputs collects the question configuration from raw node inputs,
+// which use the "qa" key in YAML exports and flat keys on the canvas.
+func extractQuestionNodeInputs(inputs map[string]interface{}) interface{} {
+	if qa, exists := inputs["qa"]; exists && qa != nil {
+		return qa
+	}
+
+	qa := make(map[string]interface{})
+	for _, key := range cozeQuestionInputKeys {
+		if value, exists := inputs[key]; exists {
+			qa[key] = value
+		}
+	}
+	if len(qa) == 0 {
+		return nil
+	}
+	return qa
+}
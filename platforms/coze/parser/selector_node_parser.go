@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 	"strconv"
 )
 
@@ -137,15 +138,21 @@ func (p *SelectorNodeParser) parseConditionCase(branch map[string]interface{}, i
 	return conditionCase, nil
 }
 
-// parseLogicalOperator parses logical operator from condition data.
+// parseLogicalOperator parses logical operator from condition data: the logic number, or its
+// name, which some exports write instead.
 func (p *SelectorNodeParser) parseLogicalOperator(conditionData map[string]interface{}) string {
-	if logic, ok := conditionData["logic"].(float64); ok {
+	switch logic := conditionData["logic"].(type) {
+	case float64:
 		return p.mapCozeLogicToOperator(int(logic))
-	}
-	if logic, ok := conditionData["logic"].(int); ok {
+	case int:
 		return p.mapCozeLogicToOperator(logic)
+	case string:
+		if number, err := strconv.Atoi(logic); err == nil {
+			return p.mapCozeLogicToOperator(number)
+		}
+		return models.NormalizeLogicalOperator(logic)
 	}
-	return "and" // Default
+	return models.LogicalAnd
 }
 
 // mapCozeLogicToOperator maps Coze logic values to operator strings.
@@ -271,46 +278,11 @@ func (p *SelectorNodeParser) convertLiteralContent(content interface{}) (interfa
 	}
 }
 
-// mapCozeOperatorToUnified maps Coze operators to unified DSL operators.
+// mapCozeOperatorToUnified maps Coze ConditionType numbers to unified operators. Unknown types are
+// kept recognizable so that generators report them instead of converting a different condition.
 func (p *SelectorNodeParser) mapCozeOperatorToUnified(operator int) string {
-	switch operator {
-	case 1: // Equal
-		return "equals"
-	case 2: // Not equal
-		return "not equals"
-	case 3: // Contains
-		return "contains"
-	case 4: // Not contains
-		return "not contains"
-	case 5: // Empty
-		return "is_empty"
-	case 6: // Greater than or equal
-		return "gte"
-	case 7: // Greater than
-		return "gt"
-	case 8: // Less than
-		return "lt"
-	case 9: // Less than or equal
-		return "lte"
-	case 10: // Not empty
-		return "is_not_empty"
-	case 11: // Is true
-		return "is_true"
-	case 12: // Is false
-		return "is_false"
-	case 13: // Length greater than
-		return "length_gt"
-	case 14: // Greater than or equal (used for numeric/date comparisons)
-		return "gte"
-	case 15: // Length less than
-		return "length_lt"
-	case 16: // Length less than or equal
-		return "length_lte"
-	case 17: // Contain key
-		return "contain_key"
-	case 18: // Not contain key
-		return "not_contain_key"
-	default:
-		return "equals" // Default fallback
+	if unified, known := common.ConditionOperatorFromCoze(operator); known {
+		return unified
 	}
+	return fmt.Sprintf("coze_condition_type_%d", operator)
 }
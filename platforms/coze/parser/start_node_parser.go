@@ -60,25 +60,14 @@ func (p *StartNodeParser) parseStartConfiguration(cozeNode CozeNode) models.Star
 // convertCozeOutputToStartVariable converts a Coze output to start variable with validation
 func (p *StartNodeParser) convertCozeOutputToStartVariable(cozeOutput CozeOutput) models.Variable {
 	startVar := models.Variable{
-		Name:     cozeOutput.Name,
-		Label:    cozeOutput.Name,
-		Type:     string(p.convertDataType(cozeOutput.Type)),
-		Required: cozeOutput.Required,
+		Name:        cozeOutput.Name,
+		Label:       cozeOutput.Name,
+		Type:        string(p.convertDataType(cozeOutput.Type)),
+		Required:    cozeOutput.Required,
+		Description: cozeOutput.Description,
 	}
-
-	// Set reasonable default values for non-required fields
-	if !cozeOutput.Required {
-		// Set reasonable default values for non-required fields
-		switch p.convertDataType(cozeOutput.Type) {
-		case models.DataTypeString:
-			startVar.Default = ""
-		case models.DataTypeInteger:
-			startVar.Default = 0
-		case models.DataTypeFloat:
-			startVar.Default = 0.0
-		case models.DataTypeBoolean:
-			startVar.Default = false
-		}
+	if cozeOutput.DefaultValue != nil && cozeOutput.DefaultValue != "" {
+		startVar.Default = cozeOutput.DefaultValue
 	}
 
 	return startVar
@@ -1,11 +1,19 @@
 package parser
 
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
 // CozeDSL represents the root structure of Coze DSL
 type CozeDSL struct {
 	WorkflowID     string         `yaml:"workflowid" json:"workflowid"`
 	Name           string         `yaml:"name" json:"name"`
 	Description    string         `yaml:"description" json:"description"`
 	Version        string         `yaml:"version" json:"version"`
+	Tags           []string       `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Author         string         `yaml:"author,omitempty" json:"author,omitempty"`
 	CreateTime     int64          `yaml:"createtime" json:"createtime"`
 	UpdateTime     int64          `yaml:"updatetime" json:"updatetime"`
 	Schema         CozeSchema     `yaml:"schema" json:"schema"`
@@ -109,6 +117,9 @@ type CozeNode struct {
 	Blocks  []interface{} `yaml:"blocks" json:"blocks"`
 	Edges   []interface{} `yaml:"edges" json:"edges"`
 	Version string        `yaml:"version" json:"version"`
+	Size    interface{}   `yaml:"size" json:"size"`
+
+	Unknown map[string]interface{} `yaml:",inline" json:"-"` // Keys not modeled above, kept for round trips
 }
 
 // CozeNodeMeta contains node positioning metadata
@@ -128,6 +139,8 @@ type CozeNodeData struct {
 	Outputs []CozeOutput    `yaml:"outputs,omitempty" json:"outputs,omitempty"`
 	Inputs  *CozeNodeInputs `yaml:"inputs,omitempty" json:"inputs,omitempty"`
 	Size    interface{}     `yaml:"size" json:"size"`
+
+	Unknown map[string]interface{} `yaml:",inline" json:"-"` // Keys not modeled above, kept for round trips
 }
 
 // CozeDataMeta contains data metadata
@@ -136,6 +149,7 @@ type CozeDataMeta struct {
 	Description string `yaml:"description" json:"description"`
 	Icon        string `yaml:"icon" json:"icon"`
 	Subtitle    string `yaml:"subTitle" json:"subTitle"`
+	SubtitleAlt string `yaml:"subtitle" json:"subtitle"` // Lowercase version of YAML exports
 	MainColor   string `yaml:"maincolor" json:"maincolor"`
 }
 
@@ -147,6 +161,7 @@ type CozeNodeInputs struct {
 	SettingOnError     interface{}          `yaml:"settingonerror" json:"settingonerror"`
 	NodeBatchInfo      interface{}          `yaml:"nodebatchinfo" json:"nodebatchinfo"`
 	LLMParam           interface{}          `yaml:"llmparam" json:"llmparam"`
+	FCParam            interface{}          `yaml:"fcparam" json:"fcparam"` // LLM skills: plugins, workflows, knowledge
 	OutputEmitter      interface{}          `yaml:"outputemitter" json:"outputemitter"`
 	Exit               *CozeExit            `yaml:"exit,omitempty" json:"exit,omitempty"`
 	LLM                interface{}          `yaml:"llm" json:"llm"`
@@ -166,6 +181,70 @@ type CozeNodeInputs struct {
 	Batch              interface{}          `yaml:"batch" json:"batch"`
 	Comment            interface{}          `yaml:"comment" json:"comment"`
 	InputReceiver      interface{}          `yaml:"inputreceiver" json:"inputreceiver"`
+	Trigger            *CozeTriggerConfig   `yaml:"trigger,omitempty" json:"trigger,omitempty"` // For trigger nodes
+
+	Raw map[string]interface{} `yaml:"-" json:"-"` // Inputs as written, for nodes generated again unchanged
+}
+
+// UnmarshalYAML decodes the inputs of the nodes section, whose keys Coze exports write in lower
+// case, also when written in the camelCase of the schema section
+func (in *CozeNodeInputs) UnmarshalYAML(value *yaml.Node) error {
+	type plainInputs CozeNodeInputs
+	names := make(map[string]string)
+	if value.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			key := value.Content[i].Value
+			if key != "inputParameters" && strings.ToLower(key) != key {
+				names[key] = strings.ToLower(key)
+			}
+		}
+	}
+
+	var inputs plainInputs
+	if err := renameKeys(value, names).Decode(&inputs); err != nil {
+		return err
+	}
+
+	// Code, classifier and loop nodes in the JSON layout keep their settings at the top of the
+	// inputs
+	var flat struct {
+		Code               *string       `yaml:"code"`
+		Language           interface{}   `yaml:"language"`
+		Intents            []interface{} `yaml:"intents"`
+		Mode               interface{}   `yaml:"mode"`
+		LoopType           interface{}   `yaml:"loopType"`
+		LoopCount          interface{}   `yaml:"loopCount"`
+		VariableParameters interface{}   `yaml:"variableParameters"`
+	}
+	if err := value.Decode(&flat); err == nil {
+		if inputs.CodeRunner == nil && flat.Code != nil {
+			inputs.CodeRunner = map[string]interface{}{"code": *flat.Code, "language": flat.Language}
+		}
+		if inputs.IntentDetector == nil && flat.Intents != nil {
+			inputs.IntentDetector = map[string]interface{}{"intents": flat.Intents, "mode": flat.Mode}
+		}
+		if inputs.Loop == nil && flat.LoopType != nil {
+			inputs.Loop = map[string]interface{}{
+				"loopType":           flat.LoopType,
+				"loopCount":          flat.LoopCount,
+				"variableParameters": flat.VariableParameters,
+			}
+		}
+	}
+	if err := value.Decode(&inputs.Raw); err != nil {
+		return err
+	}
+	*in = CozeNodeInputs(inputs)
+	return nil
+}
+
+// CozeTriggerConfig configures a trigger node: a schedule or an event that runs the workflow
+type CozeTriggerConfig struct {
+	Type       string                 `yaml:"type" json:"type"` // "time" or "event"
+	Crontab    string                 `yaml:"crontab,omitempty" json:"crontab,omitempty"`
+	TimeZone   string                 `yaml:"timezone,omitempty" json:"timeZone,omitempty"`
+	Event      string                 `yaml:"event,omitempty" json:"event,omitempty"`
+	Parameters map[string]interface{} `yaml:"parameters,omitempty" json:"parameters,omitempty"` // Start node inputs the trigger passes
 }
 
 // CozeNodeInputParam represents node input parameter
@@ -183,6 +262,18 @@ type CozeNodeInput struct {
 	Value CozeNodeInputValue `yaml:"Value" json:"Value"`
 }
 
+// UnmarshalYAML decodes an input written with the "Type" and "Value" keys of Coze YAML exports or
+// the "type" and "value" keys of the JSON layout.
+func (i *CozeNodeInput) UnmarshalYAML(value *yaml.Node) error {
+	type plainInput CozeNodeInput
+	var input plainInput
+	if err := renameKeys(value, map[string]string{"type": "Type", "value": "Value"}).Decode(&input); err != nil {
+		return err
+	}
+	*i = CozeNodeInput(input)
+	return nil
+}
+
 // CozeNodeInputValue represents node input value
 type CozeNodeInputValue struct {
 	Type    string               `yaml:"type" json:"type"`
@@ -190,6 +281,40 @@ type CozeNodeInputValue struct {
 	RawMeta CozeNodeInputRawMeta `yaml:"rawmeta" json:"rawmeta"`
 }
 
+// UnmarshalYAML decodes an input value, reading "rawMeta" like "rawmeta"
+func (v *CozeNodeInputValue) UnmarshalYAML(value *yaml.Node) error {
+	type plainValue CozeNodeInputValue
+	var inputValue plainValue
+	if err := renameKeys(value, map[string]string{"rawMeta": "rawmeta"}).Decode(&inputValue); err != nil {
+		return err
+	}
+	*v = CozeNodeInputValue(inputValue)
+	return nil
+}
+
+// renameKeys returns a copy of a YAML mapping with keys renamed, unless the new key is present
+func renameKeys(value *yaml.Node, names map[string]string) *yaml.Node {
+	if value.Kind != yaml.MappingNode {
+		return value
+	}
+	present := make(map[string]bool, len(value.Content)/2)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		present[value.Content[i].Value] = true
+	}
+
+	renamed := *value
+	renamed.Content = make([]*yaml.Node, len(value.Content))
+	copy(renamed.Content, value.Content)
+	for i := 0; i+1 < len(renamed.Content); i += 2 {
+		if name, ok := names[renamed.Content[i].Value]; ok && !present[name] {
+			key := *renamed.Content[i]
+			key.Value = name
+			renamed.Content[i] = &key
+		}
+	}
+	return &renamed
+}
+
 // CozeNodeInputContent represents node input content
 type CozeNodeInputContent struct {
 	BlockID string `yaml:"blockID" json:"blockID"`
@@ -213,6 +338,13 @@ type CozeOutput struct {
 	Required bool        `yaml:"required" json:"required"`
 	Type     string      `yaml:"type" json:"type"`
 	Schema   interface{} `yaml:"schema,omitempty" json:"schema,omitempty"` // Flexible schema support for arrays, objects, etc.
+
+	// Loop and batch nodes only: the body output collected per item
+	Input interface{} `yaml:"input,omitempty" json:"input,omitempty"`
+
+	// Start node inputs only
+	Description  string      `yaml:"description,omitempty" json:"description,omitempty"`
+	DefaultValue interface{} `yaml:"defaultValue,omitempty" json:"defaultValue,omitempty"`
 }
 
 // CozeOutputSchema represents output schema information - support flexible schema formats
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"encoding/json"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// cozeNodeTypeTrigger is the Coze trigger node type, which runs the workflow on a schedule or an event
+const cozeNodeTypeTrigger = "34"
+
+// Coze trigger types
+const (
+	cozeTriggerTime  = "time"
+	cozeTriggerEvent = "event"
+)
+
+// decodeTriggerConfig reads the triggerConfig of a canvas (ZIP/JSON) trigger node
+func decodeTriggerConfig(raw map[string]interface{}) *CozeTriggerConfig {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var config CozeTriggerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	return &config
+}
+
+// parseTrigger converts a Coze trigger node into a unified trigger
+func (p *CozeParser) parseTrigger(cozeNode CozeNode) models.Trigger {
+	trigger := models.Trigger{ID: cozeNode.ID, Title: cozeNode.Data.Meta.Title}
+	var config *CozeTriggerConfig
+	if cozeNode.Data.Inputs != nil {
+		config = cozeNode.Data.Inputs.Trigger
+	}
+	if config == nil {
+		p.ReportIssue("trigger node %s (%s) has no trigger settings", trigger.Title, trigger.ID)
+		trigger.Type = models.TriggerTypeSchedule
+		return trigger
+	}
+
+	switch config.Type {
+	case cozeTriggerEvent:
+		trigger.Type = models.TriggerTypeEvent
+	case cozeTriggerTime:
+		trigger.Type = models.TriggerTypeSchedule
+	default:
+		p.ReportIssue("trigger node %s (%s) has unknown trigger type %q, kept as a schedule", trigger.Title, trigger.ID, config.Type)
+		trigger.Type = models.TriggerTypeSchedule
+	}
+	trigger.Cron = config.Crontab
+	trigger.Timezone = config.TimeZone
+	trigger.Event = config.Event
+	trigger.Inputs = config.Parameters
+	return trigger
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lookupValue reads a map key ignoring case and underscores, since YAML exports lower-case
+// the canvas keys ("databaseInfoList" -> "databaseinfolist", "plugin_id" -> "pluginid").
+func lookupValue(m map[string]interface{}, key string) interface{} {
+	if m == nil {
+		return nil
+	}
+	if value, exists := m[key]; exists {
+		return value
+	}
+	normalizedKey := normalizeKey(key)
+	for k, value := range m {
+		if normalizeKey(k) == normalizedKey {
+			return value
+		}
+	}
+	return nil
+}
+
+// normalizeKey folds a map key for lookupValue
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", ""))
+}
+
+// formatScalar renders a scalar YAML/JSON value as a string, keeping large integer IDs intact
+func formatScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,220 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// Dify agent strategy plugin generated agents run with
+const (
+	difyAgentStrategyProvider = "langgenius/agent/agent"
+	difyAgentDefaultProvider  = "langgenius/openai_api_compatible/openai_api_compatible"
+)
+
+// difyAgentStrategyLabels are the labels Dify shows for agent strategies
+var difyAgentStrategyLabels = map[string]string{
+	models.AgentStrategyFunctionCalling: "FunctionCalling",
+	models.AgentStrategyReAct:           "ReAct",
+}
+
+// AgentNodeGenerator generates Dify agent nodes. Prompts are generated like LLM prompts: the
+// system prompt becomes the instruction, the user prompt the query.
+type AgentNodeGenerator struct {
+	*BaseNodeGenerator
+	llmGenerator *LLMNodeGenerator
+}
+
+func NewAgentNodeGenerator() *AgentNodeGenerator {
+	return &AgentNodeGenerator{
+		BaseNodeGenerator: NewBaseNodeGenerator(models.NodeTypeAgent),
+		llmGenerator:      NewLLMNodeGenerator(),
+	}
+}
+
+// SetNodeMapping sets node mapping for prompt reference resolution
+func (g *AgentNodeGenerator) SetNodeMapping(nodes []models.Node) {
+	g.llmGenerator.SetNodeMapping(nodes)
+}
+
+// GenerateNode generates an agent node. Parameters of a Dify source the unified DSL does not
+// model, such as tool settings and disabled tools, are kept.
+func (g *AgentNodeGenerator) GenerateNode(node models.Node) (DifyNode, error) {
+	if node.Type != models.NodeTypeAgent {
+		return DifyNode{}, fmt.Errorf("unsupported node type: %s, expected: %s", node.Type, models.NodeTypeAgent)
+	}
+
+	config, ok := common.AsAgentConfig(node.Config)
+	if !ok || config == nil {
+		return DifyNode{}, fmt.Errorf("agent node must have AgentConfig")
+	}
+
+	strategy := config.Strategy
+	if strategy == "" {
+		strategy = models.AgentStrategyFunctionCalling
+	}
+
+	difyNode := g.generateBaseNode(node)
+	difyNode.Data.AgentStrategyProviderName = difyAgentStrategyProvider
+	difyNode.Data.AgentStrategyName = strategy
+	difyNode.Data.AgentStrategyLabel = difyAgentStrategyLabels[strategy]
+	difyNode.Data.AgentParameters = g.generateParameters(node, *config)
+	if provider, ok := node.PlatformConfig.Dify["agent_strategy_provider_name"].(string); ok && provider != "" {
+		difyNode.Data.AgentStrategyProviderName = provider
+	}
+
+	return difyNode, nil
+}
+
+// generateParameters generates the constant agent parameters over the source ones
+func (g *AgentNodeGenerator) generateParameters(node models.Node, config models.AgentConfig) map[string]interface{} {
+	source, _ := node.PlatformConfig.Dify["agent_parameters"].(map[string]interface{})
+	parameters := make(map[string]interface{}, len(source)+5)
+	for name, value := range source {
+		parameters[name] = value
+	}
+
+	parameters["model"] = constantParameter(g.generateModel(config, sourceParameterValue(source, "model")))
+	if instruction := g.promptText(node, config.Prompt.SystemTemplate, "system"); instruction != "" {
+		parameters["instruction"] = constantParameter(instruction)
+	}
+	if query := g.promptText(node, config.Prompt.UserTemplate, "user"); query != "" {
+		parameters["query"] = constantParameter(query)
+	}
+	parameters["tools"] = constantParameter(g.generateTools(config.Tools, sourceParameterValue(source, "tools")))
+	if config.MaxIterations > 0 {
+		parameters["maximum_iterations"] = constantParameter(config.MaxIterations)
+	}
+	return parameters
+}
+
+// generateModel generates the model selector value over the source one
+func (g *AgentNodeGenerator) generateModel(config models.AgentConfig, source interface{}) map[string]interface{} {
+	model := map[string]interface{}{
+		"provider":   difyAgentDefaultProvider,
+		"model":      config.Model.Name,
+		"mode":       "chat",
+		"model_type": "llm",
+		"type":       "model-selector",
+	}
+	completionParams := map[string]interface{}{}
+	setCompletionParams(completionParams, config.Parameters)
+	if sourceModel, ok := source.(map[string]interface{}); ok {
+		for key, value := range sourceModel {
+			model[key] = value
+		}
+		// The parser fills unset params with defaults; only the params the source sets are updated
+		if params, ok := sourceModel["completion_params"].(map[string]interface{}); ok {
+			merged := make(map[string]interface{}, len(params))
+			for key, value := range params {
+				merged[key] = value
+				if generated, exists := completionParams[key]; exists {
+					merged[key] = generated
+				}
+			}
+			completionParams = merged
+		}
+	}
+
+	if config.Model.Name != "" {
+		model["model"] = config.Model.Name
+	}
+	switch provider := config.Model.Provider; {
+	case provider == "openai_compatible":
+		model["provider"] = difyAgentDefaultProvider
+	case provider != "" && !strings.HasPrefix(provider, "iflytek"):
+		model["provider"] = provider
+	}
+	if config.Model.Mode != "" {
+		model["mode"] = config.Model.Mode
+	}
+	model["completion_params"] = completionParams
+	return model
+}
+
+// generateTools generates the tools list, keeping the source entry of each tool and the disabled
+// source tools in their order
+func (g *AgentNodeGenerator) generateTools(tools []models.AgentTool, source interface{}) []interface{} {
+	sourceTools, _ := source.([]interface{})
+	generated := make([]interface{}, 0, len(tools)+len(sourceTools))
+	used := make([]bool, len(tools))
+	for _, entry := range sourceTools {
+		toolMap, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if enabled, ok := toolMap["enabled"].(bool); ok && !enabled {
+			generated = append(generated, entry)
+			continue
+		}
+		for i, tool := range tools {
+			if !used[i] && toolMap["provider_name"] == tool.Provider && toolMap["tool_name"] == tool.Name {
+				used[i] = true
+				generated = append(generated, toolEntry(tool, toolMap))
+				break
+			}
+		}
+	}
+	for i, tool := range tools {
+		if !used[i] {
+			generated = append(generated, toolEntry(tool, nil))
+		}
+	}
+	return generated
+}
+
+// promptText returns the template, or else the first prompt message of the role, with references
+// rewritten for Dify
+func (g *AgentNodeGenerator) promptText(node models.Node, template, role string) string {
+	if template == "" {
+		config, _ := common.AsAgentConfig(node.Config)
+		for _, message := range config.Prompt.Messages {
+			if message.Role == role && message.Content != "" {
+				template = message.Content
+				break
+			}
+		}
+	}
+	if template == "" {
+		return ""
+	}
+	return g.llmGenerator.fixVariableReferences(template, node)
+}
+
+// toolEntry returns the agent tool entry of a tool over its source entry
+func toolEntry(tool models.AgentTool, source map[string]interface{}) map[string]interface{} {
+	entry := make(map[string]interface{}, len(source)+4)
+	for key, value := range source {
+		entry[key] = value
+	}
+	entry["enabled"] = true
+	entry["provider_name"] = tool.Provider
+	entry["tool_name"] = tool.Name
+	entry["type"] = tool.Type
+	if tool.Description != "" {
+		extra, _ := entry["extra"].(map[string]interface{})
+		copied := make(map[string]interface{}, len(extra)+1)
+		for key, value := range extra {
+			copied[key] = value
+		}
+		copied["description"] = tool.Description
+		entry["extra"] = copied
+	}
+	return entry
+}
+
+// constantParameter wraps a value as a constant agent parameter
+func constantParameter(value interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "constant", "value": value}
+}
+
+// sourceParameterValue returns the value of a source agent parameter
+func sourceParameterValue(parameters map[string]interface{}, name string) interface{} {
+	parameter, ok := parameters[name].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return parameter["value"]
+}
@@ -61,28 +61,17 @@ func (g *ClassifierNodeGenerator) setClassifierDataFields(data *DifyNodeData, no
 	// Set classification classes
 	data.Classes = g.generateClassesFromConfig(*classifierConfig)
 
-	// Need to convert variable reference format: {{Query}} -> {{#nodeId.variableName#}}
-	instruction := classifierConfig.Instructions
-	if instruction != "" && len(node.Inputs) > 0 {
-		// Get variable reference information from first input
-		firstInput := node.Inputs[0]
-		if firstInput.Reference != nil && firstInput.Reference.NodeID != "" {
-			// Construct Dify format variable reference (using temporary nodeID, will be updated later by updateVariableSelectorsWithNewIDs)
-			difyReference := fmt.Sprintf("{{#%s.%s#}}", firstInput.Reference.NodeID, firstInput.Reference.OutputName)
-			// Find all {{variableName}} format references and replace them
-			if strings.Contains(instruction, "{{") {
-				// Use more generic replacement logic, find query variable name
-				queryVarName := classifierConfig.QueryVariable
-				if queryVarName != "" {
-					oldPattern := fmt.Sprintf("{{%s}}", queryVarName)
-					instruction = strings.ReplaceAll(instruction, oldPattern, difyReference)
-				}
-				// Compatibility handling: if specific variable name not found, try common patterns
-				if strings.Contains(instruction, "{{Query}}") {
-					instruction = strings.ReplaceAll(instruction, "{{Query}}", difyReference)
-				}
+	// Convert the query placeholder of the instruction, {{Query}} on iFlytek, to a Dify reference
+	// to the first input (using temporary nodeID, updated later by updateVariableSelectorsWithNewIDs)
+	instruction := common.ClassifierInstructions(classifierConfig)
+	if len(node.Inputs) > 0 && node.Inputs[0].Reference != nil && node.Inputs[0].Reference.NodeID != "" {
+		queryRef := node.Inputs[0].Reference
+		instruction = common.RewritePromptTemplate(instruction, func(p common.PromptPlaceholder) string {
+			if p.Closed && (p.Name == "Query" || (p.Name != "" && p.Name == classifierConfig.QueryVariable)) {
+				return common.InlinePromptReference(queryRef.NodeID, queryRef.OutputName)
 			}
-		}
+			return p.Text
+		})
 	}
 
 	// Use correct field name
@@ -118,6 +107,11 @@ func (g *ClassifierNodeGenerator) generateClassesFromConfig(config models.Classi
 			"id":   classID,
 			"name": className,
 		}
+		// Dify models read the topic text, so a distinct description becomes it under a label
+		if !class.IsDefault && class.Description != "" && class.Description != class.Name {
+			difyClass["name"] = class.Description
+			difyClass["label"] = className
+		}
 		classes = append(classes, difyClass)
 	}
 
@@ -196,15 +190,7 @@ func (g *ClassifierNodeGenerator) generateModelFromClassifierConfig(classifierCo
 	params := map[string]interface{}{}
 
 	// Map iFlytek SparkAgent model parameters to Dify format
-	if classifierConfig.Parameters.Temperature > 0 {
-		params["temperature"] = classifierConfig.Parameters.Temperature
-	}
-	if classifierConfig.Parameters.MaxTokens > 0 {
-		params["max_tokens"] = classifierConfig.Parameters.MaxTokens
-	}
-	if classifierConfig.Parameters.TopK > 0 {
-		params["top_k"] = classifierConfig.Parameters.TopK
-	}
+	setCompletionParams(params, classifierConfig.Parameters)
 
 	model := map[string]interface{}{
 		"completion_params": params,
@@ -29,6 +29,9 @@ func (g *ConditionNodeGenerator) GenerateNode(node models.Node) (DifyNode, error
 	if node.Type != models.NodeTypeCondition {
 		return DifyNode{}, fmt.Errorf("unsupported node type: %s, expected: %s", node.Type, models.NodeTypeCondition)
 	}
+	if _, err := common.ConditionOperatorIssues(node, models.PlatformDify); err != nil {
+		return DifyNode{}, err
+	}
 
 	// Generate basic node structure
 	difyNode := g.generateBaseNode(node)
@@ -47,6 +50,12 @@ func (g *ConditionNodeGenerator) GenerateNode(node models.Node) (DifyNode, error
 	return difyNode, nil
 }
 
+// GetCaseID returns the Dify case_id generated for an original case_id
+func (g *ConditionNodeGenerator) GetCaseID(originalCaseID string) (string, bool) {
+	caseID, exists := g.caseIDCache[originalCaseID]
+	return caseID, exists
+}
+
 // SetNodeMapping sets node mapping for variable selector converter
 func (g *ConditionNodeGenerator) SetNodeMapping(nodes []models.Node) {
 	g.variableSelectorConverter.SetNodeMapping(nodes)
@@ -93,7 +102,7 @@ func (g *ConditionNodeGenerator) generateCases(node models.Node) []map[string]in
 			difyCase := map[string]interface{}{
 				"case_id":          caseID,
 				"id":               caseID,
-				"logical_operator": g.mapLogicalOperator(caseItem.LogicalOperator),
+				"logical_operator": models.NormalizeLogicalOperator(caseItem.LogicalOperator),
 				"conditions":       g.convertConditions(caseItem.Conditions, node),
 			}
 
@@ -112,18 +121,6 @@ func (g *ConditionNodeGenerator) generateConditionID() string {
 	return "condition-" + generateRandomUUID()
 }
 
-// mapLogicalOperator maps logical operators.
-func (g *ConditionNodeGenerator) mapLogicalOperator(operator string) string {
-	switch operator {
-	case "and", "AND":
-		return "and"
-	case "or", "OR":
-		return "or"
-	default:
-		return "and" // Default to and
-	}
-}
-
 // convertConditions converts condition list.
 func (g *ConditionNodeGenerator) convertConditions(conditions []models.Condition, node models.Node) []map[string]interface{} {
 	difyConditions := make([]map[string]interface{}, 0, len(conditions))
@@ -131,7 +128,7 @@ func (g *ConditionNodeGenerator) convertConditions(conditions []models.Condition
 	for _, condition := range conditions {
 		// Handle condition values - for empty value check operators, keep original values
 		conditionValue := condition.Value
-		mappedOperator := g.mapComparisonOperator(condition.ComparisonOperator)
+		mappedOperator := g.mapComparisonOperator(condition.ComparisonOperator, condition.VarType)
 		// Note: For empty/not empty operators, keep original values unchanged
 
 		difyCondition := map[string]interface{}{
@@ -197,7 +194,7 @@ func (g *ConditionNodeGenerator) generateSemanticCaseID(caseItem models.Conditio
 
 	// Multiple conditions case - use logical operator and primary condition
 	if len(caseItem.Conditions) > 1 {
-		logicalOp := strings.ToLower(caseItem.LogicalOperator)
+		logicalOp := models.NormalizeLogicalOperator(caseItem.LogicalOperator)
 		primaryCondition := caseItem.Conditions[0] // Use first condition as primary identifier
 		primaryID := g.buildSingleConditionID(primaryCondition)
 
@@ -233,30 +230,21 @@ func (g *ConditionNodeGenerator) extractConditionValue(condition models.Conditio
 // getOperatorTemplate returns the template for a given operator
 func (g *ConditionNodeGenerator) getOperatorTemplate(operator string) string {
 	templates := map[string]string{
-		"contains":      "contains_%s",
-		"not_contains":  "not_contains_%s",
-		"not contains":  "not_contains_%s",
-		"is":            "equals_%s",
-		"equals":        "equals_%s",
-		"is_not":        "not_equals_%s",
-		"is not":        "not_equals_%s",
-		"start_with":    "starts_%s",
-		"start with":    "starts_%s",
-		"end_with":      "ends_%s",
-		"end with":      "ends_%s",
-		"empty":         "is_empty",
-		"not_empty":     "not_empty",
-		"not empty":     "not_empty",
-		"greater_than":  "gt_%s",
-		">":             "gt_%s",
-		"less_than":     "lt_%s",
-		"<":             "lt_%s",
-		"greater_equal": "gte_%s",
-		">=":            "gte_%s",
-		"less_equal":    "lte_%s",
-		"<=":            "lte_%s",
-	}
-
+		common.OperatorContains:    "contains_%s",
+		common.OperatorNotContains: "not_contains_%s",
+		common.OperatorEquals:      "equals_%s",
+		common.OperatorNotEquals:   "not_equals_%s",
+		common.OperatorStartsWith:  "starts_%s",
+		common.OperatorEndsWith:    "ends_%s",
+		common.OperatorIsEmpty:     "is_empty",
+		common.OperatorIsNotEmpty:  "not_empty",
+		common.OperatorGreater:     "gt_%s",
+		common.OperatorLess:        "lt_%s",
+		common.OperatorGreaterOrEq: "gte_%s",
+		common.OperatorLessOrEq:    "lte_%s",
+	}
+
+	operator = common.NormalizeConditionOperator(operator)
 	if template, exists := templates[operator]; exists {
 		return template
 	}
@@ -373,45 +361,11 @@ func (g *ConditionNodeGenerator) saveCaseIDMapping(originalNode models.Node, dif
 	}
 }
 
-// mapComparisonOperator maps comparison operators.
-func (g *ConditionNodeGenerator) mapComparisonOperator(operator string) string {
-	operatorMap := g.getComparisonOperatorMap()
-
-	if mappedOp, exists := operatorMap[operator]; exists {
-		return mappedOp
-	}
-
-	return "contains" // Default to contains
-}
-
-// getComparisonOperatorMap returns the mapping table for comparison operators
-func (g *ConditionNodeGenerator) getComparisonOperatorMap() map[string]string {
-	return map[string]string{
-		"contains":      "contains",
-		"not_contains":  "not contains",
-		"is":            "is",
-		"equals":        "is",
-		"==":            "is",
-		"is_not":        "is not",
-		"not_equals":    "is not",
-		"!=":            "is not",
-		"start_with":    "start with",
-		"starts_with":   "start with",
-		"end_with":      "end with",
-		"ends_with":     "end with",
-		"empty":         "empty",
-		"is_empty":      "empty",
-		"not_empty":     "not empty",
-		"is_not_empty":  "not empty",
-		"greater_than":  ">",
-		">":             ">",
-		"less_than":     "<",
-		"<":             "<",
-		"greater_equal": ">=",
-		">=":            ">=",
-		"less_equal":    "<=",
-		"<=":            "<=",
-	}
+// mapComparisonOperator maps unified comparison operators to Dify operators, which are symbolic
+// for numeric operands. Unsupported operators are rejected by GenerateNode.
+func (g *ConditionNodeGenerator) mapComparisonOperator(operator string, varType models.UnifiedDataType) string {
+	translation, _ := common.ConditionOperatorFor(operator, varType, models.PlatformDify)
+	return translation.Operator
 }
 
 // mapVarType maps variable types.
@@ -1,45 +1,148 @@
 package generator
 
 import (
+	"context"
 	"fmt"
 	"github.com/iflytek/agentbridge/core/interfaces"
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
 	"regexp"
 	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Interface compliance check at compile time
 var _ interfaces.DSLGenerator = (*DifyGenerator)(nil)
-
-// DifyGenerator Dify DSL generator
+var _ interfaces.ConfigurableGenerator = (*DifyGenerator)(nil)
+var _ interfaces.MappingProvider = (*DifyGenerator)(nil)
+var _ interfaces.MappingGenerator = (*DifyGenerator)(nil)
+var _ interfaces.ContextGenerator = (*DifyGenerator)(nil)
+
+// DifyGenerator Dify DSL generator. It holds the configuration only; every generation runs on its
+// own difyGeneration, so once configured an instance can be reused and shared by goroutines.
+// Configure and SetTargetVersion must not be called while generations are running.
 type DifyGenerator struct {
 	*common.BaseGenerator
+	settings difySettings
+
+	mu          sync.Mutex
+	lastMapping *models.IDMapping // ID mapping of the last finished generation
+}
+
+// difySettings holds the configuration copied into every generation
+type difySettings struct {
+	versionProfile  *DifyVersionProfile // Target Dify release line
+	previousMapping *models.IDMapping   // ID mapping of an earlier conversion whose node IDs are reused
+	outputFormat    string              // models.OutputFormatYAML or models.OutputFormatJSON
+}
+
+// difyGeneration carries the state of a single conversion
+type difyGeneration struct {
+	difySettings
+	ctx                       context.Context // Checked between passes and nodes
 	nodeGeneratorFactory      *NodeGeneratorFactory
 	edgeGenerator             *EdgeGenerator
 	variableSelectorConverter *VariableSelectorConverter
 	conditionCaseIDMapping    map[string]map[string]string // nodeID -> (original case_id -> Dify case_id)
+	nodeIDMapping             map[string]string            // Source node ID -> Dify node ID
+	handleIDMapping           *models.IDMapping            // Branch and intent IDs
 }
 
 func NewDifyGenerator() *DifyGenerator {
+	profile, _ := ResolveDifyVersionProfile(DefaultDifyTargetVersion)
 	return &DifyGenerator{
-		BaseGenerator:             common.NewBaseGenerator(models.PlatformDify),
+		BaseGenerator: common.NewBaseGenerator(models.PlatformDify),
+		settings:      difySettings{versionProfile: profile},
+		lastMapping:   models.NewIDMapping(),
+	}
+}
+
+func newDifyGeneration(ctx context.Context, settings difySettings) *difyGeneration {
+	return &difyGeneration{
+		difySettings:              settings,
+		ctx:                       ctx,
 		nodeGeneratorFactory:      NewNodeGeneratorFactory(),
 		edgeGenerator:             NewEdgeGenerator(),
 		variableSelectorConverter: NewVariableSelectorConverter(),
 		conditionCaseIDMapping:    make(map[string]map[string]string),
+		handleIDMapping:           models.NewIDMapping(),
+	}
+}
+
+// Configure applies conversion options to the generator
+func (g *DifyGenerator) Configure(options *models.ConversionOptions) error {
+	if options == nil {
+		return nil
+	}
+	g.settings.previousMapping = options.PreviousMapping
+	g.settings.outputFormat = options.OutputFormat
+	return g.SetTargetVersion(options.TargetVersion)
+}
+
+// SetTargetVersion selects the Dify release line the generated DSL targets
+func (g *DifyGenerator) SetTargetVersion(version string) error {
+	profile, err := ResolveDifyVersionProfile(version)
+	if err != nil {
+		return err
 	}
+	g.settings.versionProfile = profile
+	return nil
+}
+
+// GetNodeIDMapping returns the source node ID -> Dify node ID mapping of the last generation
+func (g *DifyGenerator) GetNodeIDMapping() map[string]string {
+	return g.GetIDMapping().Nodes
+}
+
+// GetIDMapping returns the node, branch and intent ID mappings of the last generation.
+// Concurrent callers should use GenerateWithMapping instead.
+func (g *DifyGenerator) GetIDMapping() *models.IDMapping {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lastMapping.Clone()
+}
+
+// GetTargetVersion returns the name of the targeted Dify release line
+func (g *DifyGenerator) GetTargetVersion() string {
+	return g.settings.versionProfile.Name
 }
 
 // Generate generates Dify DSL from unified DSL
 func (g *DifyGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, error) {
+	data, _, err := g.GenerateWithMapping(unifiedDSL)
+	return data, err
+}
+
+// GenerateWithMapping generates Dify DSL and returns the ID mapping of this generation
+func (g *DifyGenerator) GenerateWithMapping(unifiedDSL *models.UnifiedDSL) ([]byte, *models.IDMapping, error) {
+	return g.GenerateWithContext(context.Background(), unifiedDSL)
+}
+
+// GenerateWithContext is GenerateWithMapping that stops with the context error once ctx is done
+func (g *DifyGenerator) GenerateWithContext(ctx context.Context, unifiedDSL *models.UnifiedDSL) ([]byte, *models.IDMapping, error) {
 	// Validate input
 	if err := g.Validate(unifiedDSL); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	generation := newDifyGeneration(ctx, g.settings)
+	data, err := generation.generate(unifiedDSL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mapping := generation.buildIDMapping()
+	g.mu.Lock()
+	g.lastMapping = mapping.Clone()
+	g.mu.Unlock()
+
+	return data, mapping, nil
+}
+
+// generate builds the Dify DSL of a validated unified DSL
+func (g *difyGeneration) generate(unifiedDSL *models.UnifiedDSL) ([]byte, error) {
 	// Build Dify DSL structure
 	difyDSL := &DifyRootStructure{}
 
@@ -48,15 +151,42 @@ func (g *DifyGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, error)
 		return nil, fmt.Errorf("failed to generate app metadata: %w", err)
 	}
 
+	// Iteration bodies are generated from the sub-workflow, also for flat Dify parses
+	unifiedDSL = common.NestIterationBodies(unifiedDSL)
+
+	// Human input replies live in conversation variables, which only chatflows support
+	expandedDSL, conversationVariables := g.expandHumanInputNodes(unifiedDSL)
+	if len(conversationVariables) > 0 {
+		difyDSL.App.Mode = "advanced-chat"
+	}
+
+	// Answer templates of end nodes are rendered by code nodes
+	expandedDSL = g.expandEndTemplates(expandedDSL)
+
 	// Generate workflow structure framework and get node ID mapping
-	nodeIDMapping, err := g.generateWorkflowFramework(unifiedDSL, difyDSL)
+	nodeIDMapping, err := g.generateWorkflowFramework(expandedDSL, difyDSL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate workflow framework: %w", err)
 	}
+	difyDSL.Workflow.ConversationVariables = conversationVariables
+	if err := g.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	g.nodeIDMapping = sourceNodeIDMapping(unifiedDSL.Workflow.Nodes, nodeIDMapping)
+	g.restoreUnknownFields(unifiedDSL, difyDSL)
+	g.applyErrorHandling(unifiedDSL, difyDSL)
+	g.applyMemory(unifiedDSL, difyDSL)
 
 	// Apply a final pass to update all node references using the complete ID mapping
+	if err := g.ctx.Err(); err != nil {
+		return nil, err
+	}
 	g.finalizeNodeReferences(difyDSL, nodeIDMapping)
 
+	// Adjust version-specific fields for the target Dify release
+	applyVersionCompatibility(difyDSL, g.versionProfile)
+
 	// Serialize to YAML
 	yamlData, err := yaml.Marshal(difyDSL)
 	if err != nil {
@@ -69,6 +199,9 @@ func (g *DifyGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, error)
 	idMapper.SetMapping(nodeIDMapping)
 
 	// Use structured YAML processing instead of string replacement
+	if err := g.ctx.Err(); err != nil {
+		return nil, err
+	}
 	yamlString = g.applyNodeIDMappingsToYAML(yamlString, idMapper)
 
 	// Add required empty fields for classifier nodes (if missing)
@@ -76,13 +209,14 @@ func (g *DifyGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, error)
 
 	yamlString = g.fixIterationStartNodeIDFormat(yamlString)
 
-	return []byte(yamlString), nil
+	// Keep node IDs stable across repeated conversions
+	return common.EncodeOutput(g.reusePreviousIDs([]byte(yamlString)), g.outputFormat)
 }
 
 // finalizeNodeReferences updates selectors and references using the node ID mapping.
 // It updates iteration selectors, start node id, iteration child parent/iteration ids,
 // variable selectors in code/condition/classifier/end nodes, and template references.
-func (g *DifyGenerator) finalizeNodeReferences(difyDSL *DifyRootStructure, nodeIDMapping map[string]string) {
+func (g *difyGeneration) finalizeNodeReferences(difyDSL *DifyRootStructure, nodeIDMapping map[string]string) {
 	if difyDSL == nil {
 		return
 	}
@@ -96,6 +230,8 @@ func (g *DifyGenerator) finalizeNodeReferences(difyDSL *DifyRootStructure, nodeI
 		// - code variables value_selector
 		// - end node outputs value_selector
 		// - classifier query_variable_selector and instruction references
+		// - document extractor variable_selector
+		// - list operator variable
 		// - iteration iterator_selector/output_selector/start_node_id
 		// - iteration child node parentId / iteration_id
 		_ = g.updateVariableSelectorsWithNewIDs(&difyDSL.Workflow.Graph.Nodes[i], models.Node{}, nodeIDMapping)
@@ -140,14 +276,24 @@ func (g *DifyGenerator) Validate(unifiedDSL *models.UnifiedDSL) error {
 }
 
 // generateAppMetadata generates app metadata
-func (g *DifyGenerator) generateAppMetadata(unifiedDSL *models.UnifiedDSL, difyDSL *DifyRootStructure) error {
+func (g *difyGeneration) generateAppMetadata(unifiedDSL *models.UnifiedDSL, difyDSL *DifyRootStructure) error {
 	app := DifyApp{
 		Name:                unifiedDSL.Metadata.Name,
 		Description:         unifiedDSL.Metadata.Description,
 		Mode:                "workflow", // Default workflow mode
-		Icon:                "🤖",        // Default icon
-		IconBackground:      "#E8F5E8",  // Default background color
+		Icon:                common.DefaultDifyIcon,
+		IconBackground:      common.DefaultDifyBackground,
 		UseIconAsAnswerIcon: false,
+		Tags:                unifiedDSL.Metadata.Tags,
+		Author:              unifiedDSL.Metadata.Author,
+		Version:             unifiedDSL.Metadata.Version,
+	}
+
+	// Carry the icon of other platforms over when Dify can show it
+	if uiConfig := unifiedDSL.Metadata.UIConfig; uiConfig != nil && uiConfig.Icon != "" {
+		icon := common.IconTranslatorFor(nil).Translate(uiConfig.Icon, uiConfig.IconBackground, models.PlatformDify)
+		app.Icon = icon.Icon
+		app.IconBackground = icon.Background
 	}
 
 	// Restore Dify-specific fields from platform-specific metadata
@@ -166,27 +312,14 @@ func (g *DifyGenerator) generateAppMetadata(unifiedDSL *models.UnifiedDSL, difyD
 
 	difyDSL.App = app
 
-	// Set basic information
+	// Set basic information; version and dependencies are set by the compatibility layer
 	difyDSL.Kind = "app"
-	difyDSL.Version = "0.3.1" // Dify DSL specification version
-
-	// Set dependencies: use marketplace dependencies to support langgenius/openai_api_compatible
-	// Use stable versions currently supported by Dify platform
-	difyDSL.Dependencies = []DifyDependency{
-		{
-			CurrentIdentifier: nil,
-			Type:              "marketplace",
-			Value: DifyDepValue{
-				MarketplacePluginUniqueIdentifier: "langgenius/openai_api_compatible:0.0.19@219552f62b54919d6fd317c737956d3b2cc97719b85f0179bb995e5a512b7ebb",
-			},
-		},
-	}
 
 	return nil
 }
 
 // generateWorkflowFramework generates basic workflow structure framework
-func (g *DifyGenerator) generateWorkflowFramework(unifiedDSL *models.UnifiedDSL, difyDSL *DifyRootStructure) (map[string]string, error) {
+func (g *difyGeneration) generateWorkflowFramework(unifiedDSL *models.UnifiedDSL, difyDSL *DifyRootStructure) (map[string]string, error) {
 	graph, nodeIDMapping, err := g.generateGraphFramework(unifiedDSL)
 	if err != nil {
 		return nil, err
@@ -203,8 +336,28 @@ func (g *DifyGenerator) generateWorkflowFramework(unifiedDSL *models.UnifiedDSL,
 	return nodeIDMapping, nil
 }
 
-// generateFeatures generates features configuration from unified DSL
-func (g *DifyGenerator) generateFeatures(unifiedDSL *models.UnifiedDSL) DifyFeatures {
+// generateFeatures generates features configuration from unified DSL. The features block of a
+// Dify source is restored as exported; the opening statement and suggested questions always come
+// from the UI config, which anonymization and other stages may have changed since parsing.
+func (g *difyGeneration) generateFeatures(unifiedDSL *models.UnifiedDSL) interface{} {
+	openingStatement, suggestedQuestions := "", []string{}
+	if uiConfig := unifiedDSL.Metadata.UIConfig; uiConfig != nil {
+		openingStatement = uiConfig.OpeningStatement
+		if len(uiConfig.SuggestedQuestions) > 0 {
+			suggestedQuestions = uiConfig.SuggestedQuestions
+		}
+	}
+
+	if difyMeta := unifiedDSL.PlatformMetadata.Dify; difyMeta != nil && len(difyMeta.Features) > 0 {
+		features := make(map[string]interface{}, len(difyMeta.Features)+2)
+		for key, value := range difyMeta.Features {
+			features[key] = value
+		}
+		features["opening_statement"] = openingStatement
+		features["suggested_questions"] = suggestedQuestions
+		return features
+	}
+
 	features := DifyFeatures{
 		FileUpload: DifyFileUpload{
 			Enabled:                  false,
@@ -226,6 +379,7 @@ func (g *DifyGenerator) generateFeatures(unifiedDSL *models.UnifiedDSL) DifyFeat
 			},
 			NumberLimits: 3,
 		},
+		OpeningStatement: openingStatement,
 		RetrieverResource: DifyRetrieverResource{
 			Enabled: true,
 		},
@@ -235,6 +389,7 @@ func (g *DifyGenerator) generateFeatures(unifiedDSL *models.UnifiedDSL) DifyFeat
 		SpeechToText: DifySpeechToText{
 			Enabled: false,
 		},
+		SuggestedQuestions: suggestedQuestions,
 		SuggestedQuestionsAfterAnswer: DifySuggestedQuestionsAfterAnswer{
 			Enabled: false,
 		},
@@ -244,35 +399,61 @@ func (g *DifyGenerator) generateFeatures(unifiedDSL *models.UnifiedDSL) DifyFeat
 			Voice:    "",
 		},
 	}
+	applyUnifiedFeatures(&features, unifiedDSL.Workflow.Features)
+	return features
+}
 
-	// Get configuration from unified DSL's UIConfig
-	if unifiedDSL.Metadata.UIConfig != nil {
-		uiConfig := unifiedDSL.Metadata.UIConfig
-
-		// Set opening statement
-		if uiConfig.OpeningStatement != "" {
-			features.OpeningStatement = uiConfig.OpeningStatement
+// applyUnifiedFeatures sets the features of a unified workflow, such as one exported from Dify
+// and edited, over the defaults
+func applyUnifiedFeatures(features *DifyFeatures, unified *models.Features) {
+	if unified == nil {
+		return
+	}
+	if features.OpeningStatement == "" {
+		features.OpeningStatement = unified.OpeningStatement
+	}
+	if len(features.SuggestedQuestions) == 0 && len(unified.SuggestedQuestions) > 0 {
+		features.SuggestedQuestions = unified.SuggestedQuestions
+	}
+	if upload := unified.FileUpload; upload != nil {
+		features.FileUpload.Enabled = upload.Enabled
+		if len(upload.AllowedFileTypes) > 0 {
+			features.FileUpload.AllowedFileTypes = upload.AllowedFileTypes
 		}
-
-		// Set suggested questions
-		if len(uiConfig.SuggestedQuestions) > 0 {
-			features.SuggestedQuestions = uiConfig.SuggestedQuestions
+		if len(upload.AllowedFileExtensions) > 0 {
+			features.FileUpload.AllowedFileExtensions = upload.AllowedFileExtensions
+		}
+		if len(upload.AllowedUploadMethods) > 0 {
+			features.FileUpload.AllowedFileUploadMethods = upload.AllowedUploadMethods
+		}
+		limits := &features.FileUpload.FileUploadConfig
+		for _, limit := range []struct {
+			value  int
+			target *int
+		}{
+			{upload.FileSizeLimit, &limits.FileSizeLimit},
+			{upload.ImageFileSizeLimit, &limits.ImageFileSizeLimit},
+			{upload.AudioFileSizeLimit, &limits.AudioFileSizeLimit},
+			{upload.VideoFileSizeLimit, &limits.VideoFileSizeLimit},
+			{upload.WorkflowFileUploadLimit, &limits.WorkflowFileUploadLimit},
+			{upload.BatchCountLimit, &limits.BatchCountLimit},
+			{upload.NumberLimits, &features.FileUpload.NumberLimits},
+		} {
+			if limit.value > 0 {
+				*limit.target = limit.value
+			}
 		}
 	}
-
-	// If not configured, set to empty (don't use hardcoded default values)
-	if features.OpeningStatement == "" {
-		features.OpeningStatement = ""
+	if unified.SpeechToText != nil {
+		features.SpeechToText.Enabled = unified.SpeechToText.Enabled
 	}
-	if len(features.SuggestedQuestions) == 0 {
-		features.SuggestedQuestions = []string{}
+	if tts := unified.TextToSpeech; tts != nil {
+		features.TextToSpeech = DifyTextToSpeech{Enabled: tts.Enabled, Language: tts.Language, Voice: tts.Voice}
 	}
-
-	return features
 }
 
 // generateGraphFramework generates graph structure framework
-func (g *DifyGenerator) generateGraphFramework(unifiedDSL *models.UnifiedDSL) (DifyGraph, map[string]string, error) {
+func (g *difyGeneration) generateGraphFramework(unifiedDSL *models.UnifiedDSL) (DifyGraph, map[string]string, error) {
 	// Initialize graph context and mappings
 	graph, nodeIDMapping := g.initializeGraphContext(unifiedDSL)
 
@@ -290,7 +471,7 @@ func (g *DifyGenerator) generateGraphFramework(unifiedDSL *models.UnifiedDSL) (D
 }
 
 // initializeGraphContext initializes the graph generation context
-func (g *DifyGenerator) initializeGraphContext(unifiedDSL *models.UnifiedDSL) (DifyGraph, map[string]string) {
+func (g *difyGeneration) initializeGraphContext(unifiedDSL *models.UnifiedDSL) (DifyGraph, map[string]string) {
 	graph := DifyGraph{
 		Edges: make([]DifyEdge, 0, len(unifiedDSL.Workflow.Edges)),
 		Nodes: make([]DifyNode, 0, len(unifiedDSL.Workflow.Nodes)),
@@ -308,8 +489,12 @@ func (g *DifyGenerator) initializeGraphContext(unifiedDSL *models.UnifiedDSL) (D
 }
 
 // generateNodesForWorkflow generates all nodes for the workflow
-func (g *DifyGenerator) generateNodesForWorkflow(unifiedDSL *models.UnifiedDSL, graph *DifyGraph, nodeIDMapping map[string]string) error {
+func (g *difyGeneration) generateNodesForWorkflow(unifiedDSL *models.UnifiedDSL, graph *DifyGraph, nodeIDMapping map[string]string) error {
 	for i, node := range unifiedDSL.Workflow.Nodes {
+		if err := g.ctx.Err(); err != nil {
+			return err
+		}
+		models.ReportNodeProgress(g.ctx, models.ProgressStageGenerate, i, len(unifiedDSL.Workflow.Nodes), node.ID, node.Title)
 		if err := g.generateSingleNode(node, i, unifiedDSL, graph, nodeIDMapping); err != nil {
 			return fmt.Errorf("failed to generate node %s: %w", node.ID, err)
 		}
@@ -322,7 +507,7 @@ func (g *DifyGenerator) generateNodesForWorkflow(unifiedDSL *models.UnifiedDSL,
 }
 
 // generateSingleNode generates a single node and adds it to the graph
-func (g *DifyGenerator) generateSingleNode(node models.Node, index int, unifiedDSL *models.UnifiedDSL, graph *DifyGraph, nodeIDMapping map[string]string) error {
+func (g *difyGeneration) generateSingleNode(node models.Node, index int, unifiedDSL *models.UnifiedDSL, graph *DifyGraph, nodeIDMapping map[string]string) error {
 	difyNodes, err := g.generateNodesByType(node, unifiedDSL)
 	if err != nil {
 		return err
@@ -332,7 +517,7 @@ func (g *DifyGenerator) generateSingleNode(node models.Node, index int, unifiedD
 }
 
 // generateNodesByType generates nodes based on node type
-func (g *DifyGenerator) generateNodesByType(node models.Node, unifiedDSL *models.UnifiedDSL) ([]DifyNode, error) {
+func (g *difyGeneration) generateNodesByType(node models.Node, unifiedDSL *models.UnifiedDSL) ([]DifyNode, error) {
 	switch node.Type {
 	case models.NodeTypeIteration:
 		return g.generateIterationNodes(node)
@@ -344,7 +529,7 @@ func (g *DifyGenerator) generateNodesByType(node models.Node, unifiedDSL *models
 }
 
 // generateIterationNodes generates iteration nodes
-func (g *DifyGenerator) generateIterationNodes(node models.Node) ([]DifyNode, error) {
+func (g *difyGeneration) generateIterationNodes(node models.Node) ([]DifyNode, error) {
 	if iterGenerator, ok := g.nodeGeneratorFactory.generators[models.NodeTypeIteration].(*IterationNodeGenerator); ok {
 		return iterGenerator.GenerateIterationNodes(node)
 	}
@@ -357,7 +542,7 @@ func (g *DifyGenerator) generateIterationNodes(node models.Node) ([]DifyNode, er
 }
 
 // generateEndNodes generates end nodes with workflow context
-func (g *DifyGenerator) generateEndNodes(node models.Node, unifiedDSL *models.UnifiedDSL) ([]DifyNode, error) {
+func (g *difyGeneration) generateEndNodes(node models.Node, unifiedDSL *models.UnifiedDSL) ([]DifyNode, error) {
 	if endGenerator, ok := g.nodeGeneratorFactory.generators[models.NodeTypeEnd].(*EndNodeGenerator); ok {
 		singleNode, err := endGenerator.GenerateNodeWithWorkflowContext(node, &unifiedDSL.Workflow)
 		if err != nil {
@@ -369,7 +554,7 @@ func (g *DifyGenerator) generateEndNodes(node models.Node, unifiedDSL *models.Un
 }
 
 // generateRegularNode generates regular nodes
-func (g *DifyGenerator) generateRegularNode(node models.Node) ([]DifyNode, error) {
+func (g *difyGeneration) generateRegularNode(node models.Node) ([]DifyNode, error) {
 	singleNode, err := g.nodeGeneratorFactory.GenerateNode(node)
 	if err != nil {
 		return nil, err
@@ -378,7 +563,7 @@ func (g *DifyGenerator) generateRegularNode(node models.Node) ([]DifyNode, error
 }
 
 // processGeneratedNodes processes generated nodes and adds them to the graph
-func (g *DifyGenerator) processGeneratedNodes(difyNodes []DifyNode, originalNode models.Node, index int, nodeIDMapping map[string]string, graph *DifyGraph) error {
+func (g *difyGeneration) processGeneratedNodes(difyNodes []DifyNode, originalNode models.Node, index int, nodeIDMapping map[string]string, graph *DifyGraph) error {
 	for j, difyNode := range difyNodes {
 		simpleID := g.generateNodeID(originalNode, index, j, nodeIDMapping)
 		difyNode.ID = simpleID
@@ -391,6 +576,10 @@ func (g *DifyGenerator) processGeneratedNodes(difyNodes []DifyNode, originalNode
 			return fmt.Errorf("failed to update variable selectors for node %s: %w", originalNode.ID, err)
 		}
 
+		if j == 0 {
+			g.recordHandleIDs(originalNode, difyNode)
+		}
+
 		g.setDefaultPositionIfNeeded(&difyNode, index)
 		g.updateIterationStartNode(&difyNode, originalNode, graph)
 
@@ -400,7 +589,7 @@ func (g *DifyGenerator) processGeneratedNodes(difyNodes []DifyNode, originalNode
 }
 
 // generateNodeID generates appropriate node ID based on node position
-func (g *DifyGenerator) generateNodeID(originalNode models.Node, index, nodeIndex int, nodeIDMapping map[string]string) string {
+func (g *difyGeneration) generateNodeID(originalNode models.Node, index, nodeIndex int, nodeIDMapping map[string]string) string {
 	if nodeIndex == 0 {
 		// Main node uses original ID generation logic
 		simpleID := common.GenerateSimpleNodeID(originalNode, index)
@@ -424,7 +613,7 @@ func (g *DifyGenerator) generateNodeID(originalNode models.Node, index, nodeInde
 }
 
 // updateNodeMappings updates node ID mappings for iteration nodes
-func (g *DifyGenerator) updateNodeMappings(originalNode models.Node, difyNode DifyNode, simpleID string, nodeIndex int, nodeIDMapping map[string]string) error {
+func (g *difyGeneration) updateNodeMappings(originalNode models.Node, difyNode DifyNode, simpleID string, nodeIndex int, nodeIDMapping map[string]string) error {
 	if originalNode.Type == models.NodeTypeIteration && nodeIndex > 0 {
 		if iterConfig, ok := originalNode.Config.(*models.IterationConfig); ok {
 			g.updateIterationNodeMappings(iterConfig, difyNode, simpleID, nodeIndex, nodeIDMapping)
@@ -440,7 +629,7 @@ func (g *DifyGenerator) updateNodeMappings(originalNode models.Node, difyNode Di
 }
 
 // updateIterationNodeMappings updates mappings for iteration internal nodes
-func (g *DifyGenerator) updateIterationNodeMappings(iterConfig *models.IterationConfig, difyNode DifyNode, simpleID string, nodeIndex int, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateIterationNodeMappings(iterConfig *models.IterationConfig, difyNode DifyNode, simpleID string, nodeIndex int, nodeIDMapping map[string]string) {
 	if difyNode.Data.Type == "iteration-start" {
 		if iterConfig.SubWorkflow.StartNodeID != "" {
 			nodeIDMapping[iterConfig.SubWorkflow.StartNodeID] = simpleID
@@ -463,7 +652,7 @@ func (g *DifyGenerator) updateIterationNodeMappings(iterConfig *models.Iteration
 }
 
 // setDefaultPositionIfNeeded sets default position if node has no position
-func (g *DifyGenerator) setDefaultPositionIfNeeded(difyNode *DifyNode, index int) {
+func (g *difyGeneration) setDefaultPositionIfNeeded(difyNode *DifyNode, index int) {
 	if difyNode.Position.X == 0 && difyNode.Position.Y == 0 {
 		difyNode.Position = DifyPosition{X: float64(index * 300), Y: 100}
 		difyNode.PositionAbsolute = DifyPosition{X: float64(index * 300), Y: 100}
@@ -471,7 +660,7 @@ func (g *DifyGenerator) setDefaultPositionIfNeeded(difyNode *DifyNode, index int
 }
 
 // updateIterationStartNode updates iteration start node reference
-func (g *DifyGenerator) updateIterationStartNode(difyNode *DifyNode, originalNode models.Node, graph *DifyGraph) {
+func (g *difyGeneration) updateIterationStartNode(difyNode *DifyNode, originalNode models.Node, graph *DifyGraph) {
 	if originalNode.Type == models.NodeTypeIteration && difyNode.Data.Type == "iteration-start" {
 		// Find corresponding iteration main node and update its start_node_id
 		for k := range graph.Nodes {
@@ -486,7 +675,7 @@ func (g *DifyGenerator) updateIterationStartNode(difyNode *DifyNode, originalNod
 }
 
 // generateEdgesForWorkflow generates all edges for the workflow
-func (g *DifyGenerator) generateEdgesForWorkflow(unifiedDSL *models.UnifiedDSL, graph *DifyGraph, nodeIDMapping map[string]string) error {
+func (g *difyGeneration) generateEdgesForWorkflow(unifiedDSL *models.UnifiedDSL, graph *DifyGraph, nodeIDMapping map[string]string) error {
 	// Collect iteration internal node IDs for filtering
 	iterationInternalNodeIDs := common.CollectIterationInternalNodeIDs(unifiedDSL.Workflow.Nodes)
 
@@ -507,7 +696,7 @@ func (g *DifyGenerator) generateEdgesForWorkflow(unifiedDSL *models.UnifiedDSL,
 }
 
 // setSubWorkflowNodeMappings sets node mapping for iteration sub-workflow nodes
-func (g *DifyGenerator) setSubWorkflowNodeMappings(nodes []models.Node) {
+func (g *difyGeneration) setSubWorkflowNodeMappings(nodes []models.Node) {
 	for _, node := range nodes {
 		if node.Type == models.NodeTypeIteration {
 			if iterConfig, ok := node.Config.(*models.IterationConfig); ok {
@@ -518,13 +707,17 @@ func (g *DifyGenerator) setSubWorkflowNodeMappings(nodes []models.Node) {
 }
 
 // updateVariableSelectorsWithNewIDs updates node IDs in variable selectors
-func (g *DifyGenerator) updateVariableSelectorsWithNewIDs(difyNode *DifyNode, originalNode models.Node, nodeIDMapping map[string]string) error {
+func (g *difyGeneration) updateVariableSelectorsWithNewIDs(difyNode *DifyNode, originalNode models.Node, nodeIDMapping map[string]string) error {
 	g.updateContextVariableSelector(difyNode, nodeIDMapping)
+	g.updateVisionVariableSelector(difyNode, nodeIDMapping)
 	g.updatePromptTemplateReferences(difyNode, nodeIDMapping)
+	g.updateMemoryQueryPrompt(difyNode, nodeIDMapping)
 	g.updateCaseConditionSelectors(difyNode, nodeIDMapping)
 	g.updateCodeVariableSelectors(difyNode, nodeIDMapping)
 	g.updateOutputValueSelectors(difyNode, nodeIDMapping)
 	g.updateClassifierQuerySelector(difyNode, nodeIDMapping)
+	g.updateDocumentExtractorSelector(difyNode, nodeIDMapping)
+	g.updateListOperatorSelector(difyNode, nodeIDMapping)
 	g.updateIterationNodeSelectors(difyNode, nodeIDMapping)
 	g.updateIterationChildNodeReferences(difyNode, nodeIDMapping)
 
@@ -532,7 +725,7 @@ func (g *DifyGenerator) updateVariableSelectorsWithNewIDs(difyNode *DifyNode, or
 }
 
 // updateContextVariableSelector updates LLM node's context.variable_selector
-func (g *DifyGenerator) updateContextVariableSelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateContextVariableSelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	if difyNode.Data.Context == nil {
 		return
 	}
@@ -548,8 +741,25 @@ func (g *DifyGenerator) updateContextVariableSelector(difyNode *DifyNode, nodeID
 	}
 }
 
+// updateVisionVariableSelector updates LLM node's vision.configs.variable_selector
+func (g *difyGeneration) updateVisionVariableSelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
+	configs, ok := difyNode.Data.Vision["configs"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	variableSelector, exists := configs["variable_selector"].([]string)
+	if !exists || len(variableSelector) < 2 {
+		return
+	}
+
+	if newNodeID, found := nodeIDMapping[variableSelector[0]]; found {
+		configs["variable_selector"] = []string{newNodeID, variableSelector[1]}
+	}
+}
+
 // updatePromptTemplateReferences updates variable references in prompt_template
-func (g *DifyGenerator) updatePromptTemplateReferences(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updatePromptTemplateReferences(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	if difyNode.Data.PromptTemplate == nil {
 		return
 	}
@@ -566,12 +776,20 @@ func (g *DifyGenerator) updatePromptTemplateReferences(difyNode *DifyNode, nodeI
 }
 
 // replaceTemplateNodeReferences replaces node ID references in template text
-func (g *DifyGenerator) replaceTemplateNodeReferences(text string, nodeIDMapping map[string]string) string {
+func (g *difyGeneration) replaceTemplateNodeReferences(text string, nodeIDMapping map[string]string) string {
 	return common.ReplaceTemplateNodeReferences(text, nodeIDMapping)
 }
 
+// updateMemoryQueryPrompt updates variable references in memory.query_prompt_template
+func (g *difyGeneration) updateMemoryQueryPrompt(difyNode *DifyNode, nodeIDMapping map[string]string) {
+	if difyNode.Data.Memory == nil {
+		return
+	}
+	difyNode.Data.Memory.QueryPromptTemplate = g.replaceTemplateNodeReferences(difyNode.Data.Memory.QueryPromptTemplate, nodeIDMapping)
+}
+
 // updateCaseConditionSelectors updates variable_selector in if-else node cases
-func (g *DifyGenerator) updateCaseConditionSelectors(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateCaseConditionSelectors(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	if difyNode.Data.Cases == nil {
 		return
 	}
@@ -589,7 +807,7 @@ func (g *DifyGenerator) updateCaseConditionSelectors(difyNode *DifyNode, nodeIDM
 }
 
 // updateConditionVariableSelector updates a single condition's variable selector
-func (g *DifyGenerator) updateConditionVariableSelector(condition map[string]interface{}, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateConditionVariableSelector(condition map[string]interface{}, nodeIDMapping map[string]string) {
 	variableSelector, exists := condition["variable_selector"].([]string)
 	if !exists {
 		return
@@ -600,7 +818,7 @@ func (g *DifyGenerator) updateConditionVariableSelector(condition map[string]int
 }
 
 // updateCodeVariableSelectors updates value_selector in code node variables
-func (g *DifyGenerator) updateCodeVariableSelectors(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateCodeVariableSelectors(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	variables, ok := difyNode.Data.Variables.([]map[string]interface{})
 	if !ok {
 		return
@@ -612,7 +830,7 @@ func (g *DifyGenerator) updateCodeVariableSelectors(difyNode *DifyNode, nodeIDMa
 }
 
 // updateVariableValueSelector updates a single variable's value selector
-func (g *DifyGenerator) updateVariableValueSelector(variable map[string]interface{}, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateVariableValueSelector(variable map[string]interface{}, nodeIDMapping map[string]string) {
 	valueSelector, exists := variable["value_selector"].([]string)
 	if !exists {
 		return
@@ -623,7 +841,7 @@ func (g *DifyGenerator) updateVariableValueSelector(variable map[string]interfac
 }
 
 // updateOutputValueSelectors updates value_selector in end node outputs
-func (g *DifyGenerator) updateOutputValueSelectors(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateOutputValueSelectors(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	if outputs, ok := difyNode.Data.Outputs.([]DifyOutput); ok {
 		g.updateDifyOutputSelectors(outputs, nodeIDMapping)
 		difyNode.Data.Outputs = outputs
@@ -633,7 +851,7 @@ func (g *DifyGenerator) updateOutputValueSelectors(difyNode *DifyNode, nodeIDMap
 }
 
 // updateDifyOutputSelectors updates value selectors in DifyOutput array
-func (g *DifyGenerator) updateDifyOutputSelectors(outputs []DifyOutput, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateDifyOutputSelectors(outputs []DifyOutput, nodeIDMapping map[string]string) {
 	for i := range outputs {
 		if len(outputs[i].ValueSelector) >= 2 {
 			oldNodeID := outputs[i].ValueSelector[0]
@@ -645,7 +863,7 @@ func (g *DifyGenerator) updateDifyOutputSelectors(outputs []DifyOutput, nodeIDMa
 }
 
 // updateClassifierQuerySelector updates query_variable_selector and instruction fields in classifier nodes
-func (g *DifyGenerator) updateClassifierQuerySelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateClassifierQuerySelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	if len(difyNode.Data.QueryVariableSelector) < 2 {
 		return
 	}
@@ -660,19 +878,44 @@ func (g *DifyGenerator) updateClassifierQuerySelector(difyNode *DifyNode, nodeID
 	g.updateInstructionNodeReferences(difyNode, oldNodeID, newNodeID)
 }
 
+// updateDocumentExtractorSelector updates variable_selector in document extractor nodes
+func (g *difyGeneration) updateDocumentExtractorSelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
+	if len(difyNode.Data.VariableSelector) < 2 {
+		return
+	}
+
+	if newNodeID, found := nodeIDMapping[difyNode.Data.VariableSelector[0]]; found {
+		difyNode.Data.VariableSelector[0] = newNodeID
+	}
+}
+
+// updateListOperatorSelector updates the list selector (variable) in list operator nodes
+func (g *difyGeneration) updateListOperatorSelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
+	if len(difyNode.Data.Variable) < 2 {
+		return
+	}
+
+	if newNodeID, found := nodeIDMapping[difyNode.Data.Variable[0]]; found {
+		difyNode.Data.Variable[0] = newNodeID
+	}
+}
+
 // updateInstructionNodeReferences updates variable references in instruction field
-func (g *DifyGenerator) updateInstructionNodeReferences(difyNode *DifyNode, oldNodeID, newNodeID string) {
+func (g *difyGeneration) updateInstructionNodeReferences(difyNode *DifyNode, oldNodeID, newNodeID string) {
 	if difyNode.Data.Instruction == "" {
 		return
 	}
 
-	oldPattern := fmt.Sprintf("{{#%s.", oldNodeID)
-	newPattern := fmt.Sprintf("{{#%s.", newNodeID)
-	difyNode.Data.Instruction = strings.ReplaceAll(difyNode.Data.Instruction, oldPattern, newPattern)
+	difyNode.Data.Instruction = common.RewritePromptTemplate(difyNode.Data.Instruction, func(p common.PromptPlaceholder) string {
+		if p.NodeID != oldNodeID || p.Output == "" {
+			return p.Text
+		}
+		return common.InlinePromptReference(newNodeID, p.Output)
+	})
 }
 
 // updateValueSelectorsInOutputs updates value_selector in outputs (generic method)
-func (g *DifyGenerator) updateValueSelectorsInOutputs(outputs *interface{}, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateValueSelectorsInOutputs(outputs *interface{}, nodeIDMapping map[string]string) {
 	if outputs == nil {
 		return
 	}
@@ -683,7 +926,7 @@ func (g *DifyGenerator) updateValueSelectorsInOutputs(outputs *interface{}, node
 }
 
 // processInterfaceSliceOutputs processes []interface{} type outputs
-func (g *DifyGenerator) processInterfaceSliceOutputs(outputs *interface{}, nodeIDMapping map[string]string) {
+func (g *difyGeneration) processInterfaceSliceOutputs(outputs *interface{}, nodeIDMapping map[string]string) {
 	outputsSlice, ok := (*outputs).([]interface{})
 	if !ok {
 		return
@@ -695,7 +938,7 @@ func (g *DifyGenerator) processInterfaceSliceOutputs(outputs *interface{}, nodeI
 }
 
 // processInterfaceOutput processes a single interface{} output
-func (g *DifyGenerator) processInterfaceOutput(outputInterface interface{}, nodeIDMapping map[string]string) {
+func (g *difyGeneration) processInterfaceOutput(outputInterface interface{}, nodeIDMapping map[string]string) {
 	outputMap, ok := outputInterface.(map[string]interface{})
 	if !ok {
 		return
@@ -705,7 +948,7 @@ func (g *DifyGenerator) processInterfaceOutput(outputInterface interface{}, node
 }
 
 // updateInterfaceValueSelector updates value_selector in map[string]interface{}
-func (g *DifyGenerator) updateInterfaceValueSelector(outputMap map[string]interface{}, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateInterfaceValueSelector(outputMap map[string]interface{}, nodeIDMapping map[string]string) {
 	valueSelector, exists := outputMap["value_selector"].([]interface{})
 	if !exists || len(valueSelector) < 2 {
 		return
@@ -722,7 +965,7 @@ func (g *DifyGenerator) updateInterfaceValueSelector(outputMap map[string]interf
 }
 
 // processMapSliceOutputs processes []map[string]interface{} type outputs
-func (g *DifyGenerator) processMapSliceOutputs(outputs *interface{}, nodeIDMapping map[string]string) {
+func (g *difyGeneration) processMapSliceOutputs(outputs *interface{}, nodeIDMapping map[string]string) {
 	outputsSlice, ok := (*outputs).([]map[string]interface{})
 	if !ok {
 		return
@@ -734,7 +977,7 @@ func (g *DifyGenerator) processMapSliceOutputs(outputs *interface{}, nodeIDMappi
 }
 
 // processMapOutput processes a single map[string]interface{} output
-func (g *DifyGenerator) processMapOutput(outputMap map[string]interface{}, nodeIDMapping map[string]string) {
+func (g *difyGeneration) processMapOutput(outputMap map[string]interface{}, nodeIDMapping map[string]string) {
 	// Try interface{} value selector first
 	if g.tryUpdateInterfaceValueSelector(outputMap, nodeIDMapping) {
 		return
@@ -745,7 +988,7 @@ func (g *DifyGenerator) processMapOutput(outputMap map[string]interface{}, nodeI
 }
 
 // tryUpdateInterfaceValueSelector tries to update []interface{} value selector
-func (g *DifyGenerator) tryUpdateInterfaceValueSelector(outputMap map[string]interface{}, nodeIDMapping map[string]string) bool {
+func (g *difyGeneration) tryUpdateInterfaceValueSelector(outputMap map[string]interface{}, nodeIDMapping map[string]string) bool {
 	valueSelector, exists := outputMap["value_selector"].([]interface{})
 	if !exists || len(valueSelector) < 2 {
 		return false
@@ -763,7 +1006,7 @@ func (g *DifyGenerator) tryUpdateInterfaceValueSelector(outputMap map[string]int
 }
 
 // tryUpdateStringValueSelector tries to update []string value selector
-func (g *DifyGenerator) tryUpdateStringValueSelector(outputMap map[string]interface{}, nodeIDMapping map[string]string) {
+func (g *difyGeneration) tryUpdateStringValueSelector(outputMap map[string]interface{}, nodeIDMapping map[string]string) {
 	valueSelector, exists := outputMap["value_selector"].([]string)
 	if !exists || len(valueSelector) < 2 {
 		return
@@ -776,7 +1019,7 @@ func (g *DifyGenerator) tryUpdateStringValueSelector(outputMap map[string]interf
 }
 
 // fixIterationStartNodeIDFormat fixes YAML format issues for iteration start node IDs
-func (g *DifyGenerator) fixIterationStartNodeIDFormat(yamlString string) string {
+func (g *difyGeneration) fixIterationStartNodeIDFormat(yamlString string) string {
 	// Use regex to find and fix issues with 'numeric'start format
 	// Pattern: 'numeric'start -> 'numericstart'
 	re := regexp.MustCompile(`'(\d+)'start`)
@@ -786,7 +1029,7 @@ func (g *DifyGenerator) fixIterationStartNodeIDFormat(yamlString string) string
 }
 
 // ensureClassifierRequiredFields ensures classifier nodes contain required empty fields
-func (g *DifyGenerator) ensureClassifierRequiredFields(yamlString string) string {
+func (g *difyGeneration) ensureClassifierRequiredFields(yamlString string) string {
 	if !g.isClassifierNode(yamlString) {
 		return yamlString
 	}
@@ -798,12 +1041,12 @@ func (g *DifyGenerator) ensureClassifierRequiredFields(yamlString string) string
 }
 
 // isClassifierNode checks if the YAML contains classifier nodes
-func (g *DifyGenerator) isClassifierNode(yamlString string) bool {
+func (g *difyGeneration) isClassifierNode(yamlString string) bool {
 	return strings.Contains(yamlString, "type: question-classifier")
 }
 
 // ensureInstructionsField adds missing instructions field to classifier nodes
-func (g *DifyGenerator) ensureInstructionsField(yamlString string) string {
+func (g *difyGeneration) ensureInstructionsField(yamlString string) string {
 	if strings.Contains(yamlString, "instructions:") {
 		return yamlString
 	}
@@ -814,7 +1057,7 @@ func (g *DifyGenerator) ensureInstructionsField(yamlString string) string {
 }
 
 // ensureTopicsField adds missing topics field to classifier nodes
-func (g *DifyGenerator) ensureTopicsField(yamlString string) string {
+func (g *difyGeneration) ensureTopicsField(yamlString string) string {
 	if strings.Contains(yamlString, "topics:") {
 		return yamlString
 	}
@@ -823,7 +1066,7 @@ func (g *DifyGenerator) ensureTopicsField(yamlString string) string {
 }
 
 // addTopicsFieldToClassifier adds topics field after query_variable_selector
-func (g *DifyGenerator) addTopicsFieldToClassifier(yamlString string) string {
+func (g *difyGeneration) addTopicsFieldToClassifier(yamlString string) string {
 	lines := strings.Split(yamlString, "\n")
 
 	for i, line := range lines {
@@ -836,7 +1079,7 @@ func (g *DifyGenerator) addTopicsFieldToClassifier(yamlString string) string {
 }
 
 // isQueryVariableSelectorLine checks if current line is query_variable_selector in classifier
-func (g *DifyGenerator) isQueryVariableSelectorLine(line string, lines []string, index int) bool {
+func (g *difyGeneration) isQueryVariableSelectorLine(line string, lines []string, index int) bool {
 	if !strings.Contains(line, "query_variable_selector:") || index == 0 {
 		return false
 	}
@@ -845,7 +1088,7 @@ func (g *DifyGenerator) isQueryVariableSelectorLine(line string, lines []string,
 }
 
 // isInClassifierContext checks if we're in classifier node context
-func (g *DifyGenerator) isInClassifierContext(lines []string, currentIndex int) bool {
+func (g *difyGeneration) isInClassifierContext(lines []string, currentIndex int) bool {
 	start := currentIndex - 10
 	if start < 0 {
 		start = 0
@@ -860,7 +1103,7 @@ func (g *DifyGenerator) isInClassifierContext(lines []string, currentIndex int)
 }
 
 // insertTopicsField inserts topics field at appropriate position
-func (g *DifyGenerator) insertTopicsField(lines []string, selectorIndex int) string {
+func (g *difyGeneration) insertTopicsField(lines []string, selectorIndex int) string {
 	insertPos := selectorIndex + 3
 	if insertPos >= len(lines) {
 		return strings.Join(lines, "\n")
@@ -870,55 +1113,46 @@ func (g *DifyGenerator) insertTopicsField(lines []string, selectorIndex int) str
 	return strings.Join(newLines, "\n")
 }
 
-// applyNodeIDMappingsToYAML applies node ID mappings using structured processing
-func (g *DifyGenerator) applyNodeIDMappingsToYAML(yamlString string, idMapper *common.UnifiedIDMapper) string {
-	// Process YAML using regex patterns to safely replace node IDs
-	// This approach is safer than direct string replacement as it targets specific patterns
-
-	for oldID, newID := range idMapper.GetMapping() {
-		// Replace node IDs in edges (source/target fields)
-		yamlString = g.replaceNodeIDInField(yamlString, "source:", oldID, newID)
-		yamlString = g.replaceNodeIDInField(yamlString, "target:", oldID, newID)
-
-		// Replace node IDs in variable selectors and references
-		yamlString = g.replaceNodeIDInVariableSelectors(yamlString, oldID, newID)
+// Patterns of node ID references in the generated YAML; the ID is the second group
+var (
+	nodeIDFieldPattern    = regexp.MustCompile(`((?:source|target):\s+)(\S+)(\s|$)`)
+	nodeIDSelectorPattern = regexp.MustCompile(`(\[\s*)([^\s\[\],]+)(\s*,)`)
+	nodeIDTemplatePattern = regexp.MustCompile(`(\{\{#)([^\s#{}]+?)(\.[\w]+#\}\})`)
+)
 
-		// Replace node IDs in template references
-		yamlString = g.replaceNodeIDInTemplates(yamlString, oldID, newID)
+// applyNodeIDMappingsToYAML applies node ID mappings using structured processing. Each reference
+// pattern is matched once over the document and looked up in the mapping, so the cost stays
+// linear in the document size instead of growing with every mapped node.
+func (g *difyGeneration) applyNodeIDMappingsToYAML(yamlString string, idMapper *common.UnifiedIDMapper) string {
+	mapping := idMapper.GetMapping()
+	if len(mapping) == 0 {
+		return yamlString
 	}
 
-	return yamlString
-}
+	// Replace node IDs in edges (source/target fields)
+	yamlString = replaceNodeIDs(yamlString, nodeIDFieldPattern, mapping)
 
-// replaceNodeIDInField safely replaces node IDs in specific YAML fields
-func (g *DifyGenerator) replaceNodeIDInField(yamlString, fieldName, oldID, newID string) string {
-	// Pattern to match field: "oldID" with proper YAML formatting
-	pattern := fmt.Sprintf(`(%s\s+)(%s)(\s|$)`, regexp.QuoteMeta(fieldName), regexp.QuoteMeta(oldID))
-	re := regexp.MustCompile(pattern)
+	// Replace node IDs in variable selectors and references
+	yamlString = replaceNodeIDs(yamlString, nodeIDSelectorPattern, mapping)
 
-	return re.ReplaceAllString(yamlString, fmt.Sprintf("${1}%s${3}", newID))
+	// Replace node IDs in template references
+	return replaceNodeIDs(yamlString, nodeIDTemplatePattern, mapping)
 }
 
-// replaceNodeIDInVariableSelectors replaces node IDs in variable selector arrays
-func (g *DifyGenerator) replaceNodeIDInVariableSelectors(yamlString, oldID, newID string) string {
-	// Pattern to match variable selectors: [oldID, fieldName]
-	pattern := fmt.Sprintf(`(\[\s*)(%s)(\s*,)`, regexp.QuoteMeta(oldID))
-	re := regexp.MustCompile(pattern)
-
-	return re.ReplaceAllString(yamlString, fmt.Sprintf("${1}%s${3}", newID))
-}
-
-// replaceNodeIDInTemplates replaces node IDs in template expressions
-func (g *DifyGenerator) replaceNodeIDInTemplates(yamlString, oldID, newID string) string {
-	// Pattern to match template references: {{#oldID.field#}}
-	pattern := fmt.Sprintf(`(\{\{#)(%s)(\.[\w]+#\}\})`, regexp.QuoteMeta(oldID))
-	re := regexp.MustCompile(pattern)
-
-	return re.ReplaceAllString(yamlString, fmt.Sprintf("${1}%s${3}", newID))
+// replaceNodeIDs replaces the mapped node IDs matched by the second group of the pattern
+func replaceNodeIDs(yamlString string, pattern *regexp.Regexp, mapping map[string]string) string {
+	return pattern.ReplaceAllStringFunc(yamlString, func(match string) string {
+		groups := pattern.FindStringSubmatch(match)
+		newID, ok := mapping[groups[2]]
+		if !ok {
+			return match
+		}
+		return groups[1] + newID + groups[3]
+	})
 }
 
 // updateIterationNodeSelectors updates iteration node specific selectors and references
-func (g *DifyGenerator) updateIterationNodeSelectors(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateIterationNodeSelectors(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	if difyNode.Data.Type != "iteration" {
 		return
 	}
@@ -934,7 +1168,7 @@ func (g *DifyGenerator) updateIterationNodeSelectors(difyNode *DifyNode, nodeIDM
 }
 
 // updateIteratorSelector updates iterator_selector field in iteration nodes
-func (g *DifyGenerator) updateIteratorSelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateIteratorSelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	if len(difyNode.Data.IteratorSelector) < 2 {
 		return
 	}
@@ -946,7 +1180,7 @@ func (g *DifyGenerator) updateIteratorSelector(difyNode *DifyNode, nodeIDMapping
 }
 
 // updateIterationOutputSelector updates output_selector field in iteration nodes
-func (g *DifyGenerator) updateIterationOutputSelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateIterationOutputSelector(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	if len(difyNode.Data.OutputSelector) < 2 {
 		return
 	}
@@ -958,13 +1192,14 @@ func (g *DifyGenerator) updateIterationOutputSelector(difyNode *DifyNode, nodeID
 }
 
 // updateStartNodeID updates start_node_id field in iteration nodes
-func (g *DifyGenerator) updateStartNodeID(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateStartNodeID(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	if difyNode.Data.StartNodeID == "" {
 		return
 	}
 
 	// Handle cases like "originalNodeIDstart" -> "newNodeIDstart"
-	for oldNodeID, newNodeID := range nodeIDMapping {
+	for _, oldNodeID := range common.MappingKeysLongestFirst(nodeIDMapping) {
+		newNodeID := nodeIDMapping[oldNodeID]
 		if strings.HasPrefix(difyNode.Data.StartNodeID, oldNodeID) {
 			suffix := strings.TrimPrefix(difyNode.Data.StartNodeID, oldNodeID)
 			difyNode.Data.StartNodeID = newNodeID + suffix
@@ -974,7 +1209,7 @@ func (g *DifyGenerator) updateStartNodeID(difyNode *DifyNode, nodeIDMapping map[
 }
 
 // updateIterationChildNodeReferences updates parentId and iteration_id in iteration child nodes
-func (g *DifyGenerator) updateIterationChildNodeReferences(difyNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) updateIterationChildNodeReferences(difyNode *DifyNode, nodeIDMapping map[string]string) {
 	// Update ParentID if it exists in the mapping
 	if difyNode.ParentID != "" {
 		if newParentID, found := nodeIDMapping[difyNode.ParentID]; found {
@@ -991,7 +1226,7 @@ func (g *DifyGenerator) updateIterationChildNodeReferences(difyNode *DifyNode, n
 }
 
 // postProcessIterationNodes post-processes iteration nodes to fix selectors after all mappings are established
-func (g *DifyGenerator) postProcessIterationNodes(graph *DifyGraph, nodeIDMapping map[string]string) {
+func (g *difyGeneration) postProcessIterationNodes(graph *DifyGraph, nodeIDMapping map[string]string) {
 	for i := range graph.Nodes {
 		node := &graph.Nodes[i]
 		if node.Data.Type == "iteration" {
@@ -1001,7 +1236,7 @@ func (g *DifyGenerator) postProcessIterationNodes(graph *DifyGraph, nodeIDMappin
 }
 
 // fixIterationOutputSelector fixes output_selector for iteration nodes using complete node mapping
-func (g *DifyGenerator) fixIterationOutputSelector(iterationNode *DifyNode, nodeIDMapping map[string]string) {
+func (g *difyGeneration) fixIterationOutputSelector(iterationNode *DifyNode, nodeIDMapping map[string]string) {
 	if len(iterationNode.Data.OutputSelector) < 2 {
 		return
 	}
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// DocumentExtractorNodeGenerator generates Dify document extractor nodes
+type DocumentExtractorNodeGenerator struct {
+	*BaseNodeGenerator
+	variableSelectorConverter *VariableSelectorConverter
+}
+
+func NewDocumentExtractorNodeGenerator() *DocumentExtractorNodeGenerator {
+	return &DocumentExtractorNodeGenerator{
+		BaseNodeGenerator:         NewBaseNodeGenerator(models.NodeTypeDocumentExtractor),
+		variableSelectorConverter: NewVariableSelectorConverter(),
+	}
+}
+
+// SetNodeMapping sets node mapping for variable selector converter
+func (g *DocumentExtractorNodeGenerator) SetNodeMapping(nodes []models.Node) {
+	g.variableSelectorConverter.SetNodeMapping(nodes)
+}
+
+// GenerateNode generates a document extractor node reading the file of the first input
+func (g *DocumentExtractorNodeGenerator) GenerateNode(node models.Node) (DifyNode, error) {
+	if node.Type != models.NodeTypeDocumentExtractor {
+		return DifyNode{}, fmt.Errorf("unsupported node type: %s, expected: %s", node.Type, models.NodeTypeDocumentExtractor)
+	}
+
+	config, ok := common.AsDocumentExtractorConfig(node.Config)
+	if !ok || config == nil {
+		return DifyNode{}, fmt.Errorf("document extractor node must have DocumentExtractorConfig")
+	}
+
+	difyNode := g.generateBaseNode(node)
+	difyNode.Data.IsArrayFile = config.IsArrayFile
+	difyNode.Data.VariableSelector = g.generateFileSelector(node)
+
+	return difyNode, nil
+}
+
+// generateFileSelector generates the variable selector of the file to extract
+func (g *DocumentExtractorNodeGenerator) generateFileSelector(node models.Node) []string {
+	if len(node.Inputs) == 0 || node.Inputs[0].Reference == nil {
+		return []string{}
+	}
+
+	reference := node.Inputs[0].Reference
+	valueSelector, err := g.variableSelectorConverter.ConvertVariableReference(reference)
+	if err != nil {
+		return []string{reference.NodeID, reference.OutputName}
+	}
+	return valueSelector
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -95,6 +96,10 @@ func (g *EdgeGenerator) generateEdgeWithIDMapping(edge models.Edge, nodes []mode
 		sourceHandle = g.mapConditionHandle(edge.SourceHandle, nodes, edge.Source)
 	case "question-classifier":
 		sourceHandle = g.mapClassifierHandle(edge.SourceHandle, nodes, edge.Source)
+	default:
+		if edge.SourceHandle == models.ErrorBranchHandle && g.hasFailBranch(edge.Source, nodes) {
+			sourceHandle = models.ErrorBranchHandle
+		}
 	}
 
 	// Generate standard Dify edge ID
@@ -112,6 +117,7 @@ func (g *EdgeGenerator) generateEdgeWithIDMapping(edge models.Edge, nodes []mode
 			IsInLoop:   false,
 			SourceType: sourceType,
 			TargetType: g.getNodeTypeByID(edge.Target, nodes),
+			EdgeStyle:  edge.Style,
 		},
 	}
 
@@ -151,6 +157,17 @@ func (g *EdgeGenerator) getNodeTypeByID(nodeID string, nodes []models.Node) stri
 	return "unknown"
 }
 
+// hasFailBranch checks if a node takes its fail branch on errors in Dify
+func (g *EdgeGenerator) hasFailBranch(nodeID string, nodes []models.Node) bool {
+	for _, node := range nodes {
+		if node.ID == nodeID {
+			return node.ErrorHandling != nil && node.ErrorHandling.Strategy == models.ErrorStrategyFailBranch &&
+				common.SupportsErrorHandling(models.PlatformDify, node.Type)
+		}
+	}
+	return false
+}
+
 // isIterationEdge checks if it's an iteration connection
 func (g *EdgeGenerator) isIterationEdge(edge models.Edge, nodes []models.Node) bool {
 	// Only consider an internal iteration connection if both source and target are within a sub-workflow of an iteration
@@ -350,12 +367,12 @@ func (g *EdgeGenerator) extractMappingFromNode(node models.Node, sourceHandle st
 		return ""
 	}
 
-	mapping, exists := node.PlatformConfig.Dify["case_id_mapping"].(map[string]string)
-	if !exists {
-		return ""
-	}
-
-	if difyCaseID, found := mapping[sourceHandle]; found {
+	switch mapping := node.PlatformConfig.Dify["case_id_mapping"].(type) {
+	case map[string]string:
+		return mapping[sourceHandle]
+	case map[string]interface{}:
+		// Generic map of a unified DSL read back from YAML
+		difyCaseID, _ := mapping[sourceHandle].(string)
 		return difyCaseID
 	}
 	return ""
@@ -363,26 +380,49 @@ func (g *EdgeGenerator) extractMappingFromNode(node models.Node, sourceHandle st
 
 // mapClassifierHandle maps classifier handles to Dify standard format
 func (g *EdgeGenerator) mapClassifierHandle(sourceHandle string, nodes []models.Node, sourceNodeID string) string {
-	// Early return for simple numeric formats
-	if g.isSimpleNumericFormat(sourceHandle) {
-		return sourceHandle
-	}
-
 	// Find the classifier node and attempt different mapping strategies
 	classifierNode := g.findClassifierNode(nodes, sourceNodeID)
 	if classifierNode != nil {
 		if mappedHandle := g.tryConfigBasedMapping(sourceHandle, classifierNode); mappedHandle != "" {
 			return mappedHandle
 		}
+		if mappedHandle := g.tryPositionalMapping(sourceHandle, classifierNode); mappedHandle != "" {
+			return mappedHandle
+		}
+	}
+
+	// Numeric handles without a class are kept
+	if g.isSimpleNumericFormat(sourceHandle) {
+		return sourceHandle
 	}
 
 	// Apply smart mapping rules as fallback
 	return g.applySmartMappingRules(sourceHandle, sourceNodeID)
 }
 
-// isSimpleNumericFormat checks if the handle is already in simple numeric format
+// isSimpleNumericFormat checks if the handle is a 1-based class position
 func (g *EdgeGenerator) isSimpleNumericFormat(sourceHandle string) bool {
-	return sourceHandle == "1" || sourceHandle == "2" || sourceHandle == "3" || sourceHandle == "4" || sourceHandle == "5"
+	position, err := strconv.Atoi(sourceHandle)
+	return err == nil && position >= 1
+}
+
+// tryPositionalMapping maps the 1-based intent positions Coze sources use as handles to the class
+// at that position among the non-default classes
+func (g *EdgeGenerator) tryPositionalMapping(sourceHandle string, node *models.Node) string {
+	config, ok := common.AsClassifierConfig(node.Config)
+	if !ok || config == nil || !g.isSimpleNumericFormat(sourceHandle) {
+		return ""
+	}
+	position, _ := strconv.Atoi(sourceHandle)
+	for i, class := range config.Classes {
+		if class.IsDefault {
+			continue
+		}
+		if position--; position == 0 {
+			return g.generateSemanticClassID(class, i+1)
+		}
+	}
+	return ""
 }
 
 // findClassifierNode finds the classifier node by ID
@@ -527,7 +567,7 @@ func (g *EdgeGenerator) tryIntentOneOfMapping(sourceHandle string) string {
 	}
 
 	suffix := strings.TrimPrefix(sourceHandle, "intent-one-of::")
-	if len(suffix) <= 2 && suffix >= "1" && suffix <= "9" {
+	if g.isSimpleNumericFormat(suffix) {
 		return suffix
 	}
 	return ""
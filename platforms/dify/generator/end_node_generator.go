@@ -101,6 +101,23 @@ func (g *EndNodeGenerator) generateOutputsFromInputs(inputs []models.Input) []Di
 	return difyOutputs
 }
 
+// inferValueType sets the type of an end output from the node output it references
+func (g *EndNodeGenerator) inferValueType(out *DifyOutput, sourceNode *models.Node, reference *models.VariableReference) {
+	if sourceNode != nil {
+		if inferredType := g.getReferencedOutputType(sourceNode, reference.OutputName); inferredType != "" {
+			out.ValueType = inferredType
+			out.Type = inferredType
+		}
+		return
+	}
+
+	// Fallback for iteration pattern by NodeID prefix
+	if strings.HasPrefix(reference.NodeID, "iteration::") {
+		out.ValueType = "array[string]"
+		out.Type = "array[string]"
+	}
+}
+
 // generateSmartOutputsFromWorkflow builds end node outputs with workflow context
 func (g *EndNodeGenerator) generateSmartOutputsFromWorkflow(endNode models.Node, workflow *models.Workflow) []DifyOutput {
 	// Handle explicit inputs and use workflow to infer types and mapped output names
@@ -122,19 +139,10 @@ func (g *EndNodeGenerator) generateSmartOutputsFromWorkflow(endNode models.Node,
 				mappedOutputName := g.mapOutputNameByNodeID(input.Reference.NodeID, input.Reference.OutputName)
 				out.ValueSelector = []string{input.Reference.NodeID, mappedOutputName}
 
-				// If source node is found, prefer its output type for value_type
-				if sourceNode != nil {
-					inferredType := g.getNodeOutputType(sourceNode)
-					if inferredType != "" {
-						out.ValueType = inferredType
-						out.Type = inferredType
-					}
-				} else {
-					// Fallback for iteration pattern by NodeID prefix
-					if strings.HasPrefix(input.Reference.NodeID, "iteration::") {
-						out.ValueType = "array[string]"
-						out.Type = "array[string]"
-					}
+				// If source node is found, prefer its output type for value_type; Dify sources
+				// declare it themselves
+				if len(endNode.PlatformConfig.Dify) == 0 || input.Type == "" {
+					g.inferValueType(&out, sourceNode, input.Reference)
 				}
 			}
 
@@ -354,6 +362,17 @@ func (g *EndNodeGenerator) getNodeOutputType(node *models.Node) string {
 	}
 }
 
+// getReferencedOutputType returns the declared type of the referenced output, falling back to
+// the node output type
+func (g *EndNodeGenerator) getReferencedOutputType(node *models.Node, outputName string) string {
+	for _, output := range node.Outputs {
+		if output.Name == outputName && output.Type != "" {
+			return g.mapUnifiedTypeToString(output.Type)
+		}
+	}
+	return g.getNodeOutputType(node)
+}
+
 // mapUnifiedTypeToString maps unified DSL types to strings
 func (g *EndNodeGenerator) mapUnifiedTypeToString(dataType models.UnifiedDataType) string {
 	// Use unified mapping system
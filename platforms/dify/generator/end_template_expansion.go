@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// endTemplateOffsetX is the horizontal distance between a template render node and its end node
+const endTemplateOffsetX = 300
+
+// expandEndTemplates prepares template mode end nodes for generation. Dify end nodes can only return
+// variables, so a code node rendering the answer template is inserted before each end node, which
+// then returns the rendered answer as its single output variable.
+// The returned DSL is a copy; the given DSL is left unchanged.
+func (g *difyGeneration) expandEndTemplates(unifiedDSL *models.UnifiedDSL) *models.UnifiedDSL {
+	var expanded *models.UnifiedDSL
+	for i, node := range unifiedDSL.Workflow.Nodes {
+		if node.Type != models.NodeTypeEnd {
+			continue
+		}
+		renderNode, ok := common.EndTemplateRenderNode(node)
+		if !ok {
+			continue
+		}
+
+		if expanded == nil {
+			copied := *unifiedDSL
+			copied.Workflow.Nodes = append([]models.Node(nil), unifiedDSL.Workflow.Nodes...)
+			copied.Workflow.Edges = append([]models.Edge(nil), unifiedDSL.Workflow.Edges...)
+			expanded = &copied
+		}
+		g.insertEndTemplateNode(expanded, i, renderNode)
+	}
+
+	if expanded == nil {
+		return unifiedDSL
+	}
+	return expanded
+}
+
+// insertEndTemplateNode puts renderNode between the end node at endIndex and its predecessors and
+// points the end node output at the rendered answer.
+func (g *difyGeneration) insertEndTemplateNode(unifiedDSL *models.UnifiedDSL, endIndex int, renderNode models.Node) {
+	end := unifiedDSL.Workflow.Nodes[endIndex]
+
+	for i, edge := range unifiedDSL.Workflow.Edges {
+		if edge.Target == end.ID {
+			edge.Target = renderNode.ID
+			unifiedDSL.Workflow.Edges[i] = edge
+		}
+	}
+	unifiedDSL.Workflow.Edges = append(unifiedDSL.Workflow.Edges, models.Edge{
+		ID:     renderNode.ID + "_edge",
+		Source: renderNode.ID,
+		Target: end.ID,
+		Type:   models.EdgeTypeDefault,
+	})
+
+	end.Position.X += endTemplateOffsetX
+	end.Inputs = []models.Input{{
+		Name: "output",
+		Type: models.DataTypeString,
+		Reference: &models.VariableReference{
+			Type:       models.ReferenceTypeNodeOutput,
+			NodeID:     renderNode.ID,
+			OutputName: "output",
+			DataType:   models.DataTypeString,
+		},
+	}}
+	if config, ok := common.AsEndConfig(end.Config); ok && config != nil {
+		endConfig := *config
+		endConfig.OutputMode = models.EndOutputModeVariables
+		endConfig.Template = ""
+		end.Config = endConfig
+	}
+
+	unifiedDSL.Workflow.Nodes[endIndex] = end
+	unifiedDSL.Workflow.Nodes = append(unifiedDSL.Workflow.Nodes, renderNode)
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// applyErrorHandling emits the retries and error strategy of the source nodes that Dify
+// supports them on.
+func (g *difyGeneration) applyErrorHandling(unifiedDSL *models.UnifiedDSL, difyDSL *DifyRootStructure) {
+	sourceNodes := common.NodesByTargetID(unifiedDSL.Workflow.Nodes, g.nodeIDMapping)
+
+	nodes := difyDSL.Workflow.Graph.Nodes
+	for i := range nodes {
+		sourceNode := sourceNodes[nodes[i].ID]
+		if sourceNode == nil || sourceNode.ErrorHandling == nil || !common.SupportsErrorHandling(models.PlatformDify, sourceNode.Type) {
+			continue
+		}
+		setErrorHandling(&nodes[i].Data, sourceNode)
+	}
+}
+
+func setErrorHandling(data *DifyNodeData, sourceNode *models.Node) {
+	handling := sourceNode.ErrorHandling
+	if handling.MaxRetries > 0 {
+		data.RetryConfig = &DifyRetryConfig{
+			Enabled:       true,
+			MaxRetries:    handling.MaxRetries,
+			RetryInterval: handling.RetryInterval,
+		}
+	}
+
+	switch handling.Strategy {
+	case models.ErrorStrategyFailBranch:
+		data.ErrorStrategy = models.ErrorStrategyFailBranch
+	case models.ErrorStrategyDefaultValue:
+		data.ErrorStrategy = models.ErrorStrategyDefaultValue
+		// Dify lists a default for every output, typed like the output; LLM nodes output text
+		outputs := sourceNode.Outputs
+		if sourceNode.Type == models.NodeTypeLLM && len(outputs) > 1 {
+			outputs = outputs[:1]
+		}
+		mapping := models.GetDefaultDataTypeMapping()
+		for _, output := range outputs {
+			key := output.Name
+			if sourceNode.Type == models.NodeTypeLLM {
+				key = "text"
+			}
+			data.DefaultValue = append(data.DefaultValue, DifyDefaultValue{
+				Key:   key,
+				Type:  mapping.MapToDifyTypeWithAliases(string(output.Type)),
+				Value: handling.DefaultValues[output.Name],
+			})
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// humanInputReplyVariableFormat names the conversation variable holding the reply to a human input node
+const humanInputReplyVariableFormat = "human_input_%d_reply"
+
+// conversationSelectorPrefix is the selector prefix of Dify conversation variables
+const conversationSelectorPrefix = "conversation"
+
+// expandHumanInputNodes prepares human input nodes for generation. Every node gets a conversation
+// variable for the user's reply, references to the node outputs read that variable instead, and
+// option answers branch through an if-else node comparing the reply with each option.
+// The returned DSL is a copy; the given DSL is left unchanged.
+func (g *difyGeneration) expandHumanInputNodes(unifiedDSL *models.UnifiedDSL) (*models.UnifiedDSL, []interface{}) {
+	replyVariables := make(map[string]string) // Human input node ID -> conversation variable name
+	conversationVariables := make([]interface{}, 0)
+
+	var collect func(nodes []models.Node)
+	collect = func(nodes []models.Node) {
+		for _, node := range nodes {
+			if node.Type == models.NodeTypeHumanInput {
+				name := fmt.Sprintf(humanInputReplyVariableFormat, len(replyVariables)+1)
+				replyVariables[node.ID] = name
+				conversationVariables = append(conversationVariables, g.buildReplyVariable(name, node))
+			}
+			if iterConfig, ok := common.AsIterationConfig(node.Config); ok && iterConfig != nil {
+				collect(iterConfig.SubWorkflow.Nodes)
+			}
+		}
+	}
+	collect(unifiedDSL.Workflow.Nodes)
+
+	if len(replyVariables) == 0 {
+		return unifiedDSL, conversationVariables
+	}
+
+	expanded := *unifiedDSL
+	expanded.Workflow.Nodes = g.rebindHumanInputReferences(unifiedDSL.Workflow.Nodes, replyVariables)
+	expanded.Workflow.Edges = append([]models.Edge(nil), unifiedDSL.Workflow.Edges...)
+
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if config, ok := common.AsHumanInputConfig(node.Config); ok && config != nil &&
+			config.AnswerType == models.HumanInputAnswerOption && len(config.Options) > 0 {
+			g.addOptionBranchNode(&expanded, node, *config, replyVariables[node.ID])
+		}
+	}
+
+	return &expanded, conversationVariables
+}
+
+// buildReplyVariable builds the conversation variable declaration holding a reply
+func (g *difyGeneration) buildReplyVariable(name string, node models.Node) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          generateRandomUUID(),
+		"name":        name,
+		"value_type":  "string",
+		"value":       "",
+		"description": fmt.Sprintf("用户对「%s」的回复", node.Title),
+		"selector":    []string{conversationSelectorPrefix, name},
+	}
+}
+
+// rebindHumanInputReferences points input references and condition selectors that read
+// human input node outputs at the reply conversation variables
+func (g *difyGeneration) rebindHumanInputReferences(nodes []models.Node, replyVariables map[string]string) []models.Node {
+	rebound := make([]models.Node, len(nodes))
+
+	for i, node := range nodes {
+		inputs := make([]models.Input, len(node.Inputs))
+		for j, input := range node.Inputs {
+			if input.Reference != nil && input.Reference.Type == models.ReferenceTypeNodeOutput {
+				if name, exists := replyVariables[input.Reference.NodeID]; exists {
+					reference := *input.Reference
+					reference.NodeID = conversationSelectorPrefix
+					reference.OutputName = name
+					input.Reference = &reference
+				}
+			}
+			inputs[j] = input
+		}
+		node.Inputs = inputs
+
+		if conditionConfig, ok := common.AsConditionConfig(node.Config); ok && conditionConfig != nil {
+			node.Config = g.rebindConditionSelectors(*conditionConfig, replyVariables)
+		}
+
+		if iterConfig, ok := common.AsIterationConfig(node.Config); ok && iterConfig != nil {
+			subConfig := *iterConfig
+			subConfig.SubWorkflow.Nodes = g.rebindHumanInputReferences(iterConfig.SubWorkflow.Nodes, replyVariables)
+			node.Config = &subConfig
+		}
+
+		rebound[i] = node
+	}
+
+	return rebound
+}
+
+// rebindConditionSelectors rewrites condition selectors that read human input node outputs
+func (g *difyGeneration) rebindConditionSelectors(config models.ConditionConfig, replyVariables map[string]string) models.ConditionConfig {
+	cases := make([]models.ConditionCase, len(config.Cases))
+	for i, caseItem := range config.Cases {
+		conditions := make([]models.Condition, len(caseItem.Conditions))
+		for j, condition := range caseItem.Conditions {
+			if len(condition.VariableSelector) >= 2 {
+				if name, exists := replyVariables[condition.VariableSelector[0]]; exists {
+					condition.VariableSelector = []string{conversationSelectorPrefix, name}
+				}
+			}
+			conditions[j] = condition
+		}
+		caseItem.Conditions = conditions
+		cases[i] = caseItem
+	}
+	config.Cases = cases
+	return config
+}
+
+// addOptionBranchNode inserts an if-else node after an option answer node. Each option becomes a case
+// comparing the reply with the option text; the option branches leave from the if-else node instead.
+func (g *difyGeneration) addOptionBranchNode(unifiedDSL *models.UnifiedDSL, node models.Node, config models.HumanInputConfig, replyVariable string) {
+	branchNode := models.Node{
+		ID:          node.ID + "_options",
+		Type:        models.NodeTypeCondition,
+		Title:       common.DerivedNodeTitle(node, node.ID+"_options", models.NodeTypeCondition, "选项分支"),
+		Description: "根据用户回复选择分支",
+		Position:    models.Position{X: node.Position.X + 300, Y: node.Position.Y},
+		PlatformConfig: models.PlatformConfig{
+			Dify: make(map[string]interface{}),
+		},
+	}
+
+	caseIDs := make(map[string]string, len(config.Options)) // Option ID -> Dify case_id
+	cases := make([]models.ConditionCase, 0, len(config.Options))
+	for i, option := range config.Options {
+		caseID := generateRandomUUID()
+		caseIDs[option.ID] = caseID
+		cases = append(cases, models.ConditionCase{
+			CaseID:          caseID,
+			LogicalOperator: "and",
+			Level:           i + 1,
+			Conditions: []models.Condition{{
+				VariableSelector:   []string{conversationSelectorPrefix, replyVariable},
+				ComparisonOperator: common.OperatorEquals,
+				Value:              option.Name,
+				VarType:            models.DataTypeString,
+			}},
+		})
+	}
+	branchNode.Config = models.ConditionConfig{Cases: cases}
+
+	for i, edge := range unifiedDSL.Workflow.Edges {
+		if edge.Source != node.ID {
+			continue
+		}
+		edge.Source = branchNode.ID
+		if caseID, exists := caseIDs[edge.SourceHandle]; exists {
+			edge.SourceHandle = caseID
+		} else {
+			edge.SourceHandle = "false" // Default option and unmatched replies take the else branch
+		}
+		unifiedDSL.Workflow.Edges[i] = edge
+	}
+
+	unifiedDSL.Workflow.Nodes = append(unifiedDSL.Workflow.Nodes, branchNode)
+	unifiedDSL.Workflow.Edges = append(unifiedDSL.Workflow.Edges, models.Edge{
+		ID:     branchNode.ID + "_edge",
+		Source: node.ID,
+		Target: branchNode.ID,
+		Type:   models.EdgeTypeDefault,
+	})
+}
@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// HumanInputNodeGenerator generates answer nodes for human input nodes.
+// Dify has no node that pauses for a reply: the question is sent with an answer node
+// and the reply is read from a conversation variable on the next turn.
+type HumanInputNodeGenerator struct {
+	*BaseNodeGenerator
+}
+
+func NewHumanInputNodeGenerator() *HumanInputNodeGenerator {
+	return &HumanInputNodeGenerator{
+		BaseNodeGenerator: NewBaseNodeGenerator(models.NodeTypeHumanInput),
+	}
+}
+
+// GenerateNode generates an answer node
+func (g *HumanInputNodeGenerator) GenerateNode(node models.Node) (DifyNode, error) {
+	if node.Type != models.NodeTypeHumanInput {
+		return DifyNode{}, fmt.Errorf("unsupported node type: %s, expected: %s", node.Type, models.NodeTypeHumanInput)
+	}
+
+	config, ok := common.AsHumanInputConfig(node.Config)
+	if !ok || config == nil {
+		return DifyNode{}, fmt.Errorf("human input node must have HumanInputConfig")
+	}
+
+	difyNode := g.generateBaseNode(node)
+	difyNode.Data.Answer = g.buildAnswerText(node, *config)
+	difyNode.Data.Variables = []interface{}{}
+	if difyNode.Data.Desc == "" {
+		difyNode.Data.Desc = "原问答节点：用户回复需写入会话变量后由下游节点读取"
+	}
+
+	return difyNode, nil
+}
+
+// buildAnswerText renders the question with Dify variable references, followed by the options to choose from
+func (g *HumanInputNodeGenerator) buildAnswerText(node models.Node, config models.HumanInputConfig) string {
+	answer := g.convertTemplateReferences(config.Question, node.Inputs)
+
+	if config.AnswerType != models.HumanInputAnswerOption || len(config.Options) == 0 {
+		return answer
+	}
+
+	lines := make([]string, 0, len(config.Options)+1)
+	lines = append(lines, answer)
+	for i, option := range config.Options {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, g.convertTemplateReferences(option.Name, node.Inputs)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// convertTemplateReferences replaces {{input}} placeholders with the {{#node.output#}} references they point to
+func (g *HumanInputNodeGenerator) convertTemplateReferences(text string, inputs []models.Input) string {
+	for _, input := range inputs {
+		if input.Reference == nil || input.Reference.NodeID == "" {
+			continue
+		}
+		placeholder := fmt.Sprintf("{{%s}}", input.Name)
+		reference := fmt.Sprintf("{{#%s.%s#}}", input.Reference.NodeID, input.Reference.OutputName)
+		text = strings.ReplaceAll(text, placeholder, reference)
+	}
+	return text
+}
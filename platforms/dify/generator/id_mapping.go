@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// buildIDMapping returns the node, branch and intent ID mappings of the generation.
+// Dify references outputs by variable name, so no output IDs are reported.
+func (g *difyGeneration) buildIDMapping() *models.IDMapping {
+	mapping := models.NewIDMapping()
+	mapping.Nodes = common.CopyIDMapping(g.nodeIDMapping)
+	if g.handleIDMapping != nil {
+		mapping.Branches = g.handleIDMapping.Branches
+		mapping.Intents = g.handleIDMapping.Intents
+	}
+	return mapping
+}
+
+// reusePreviousIDs rewrites generated node IDs to the IDs a previous conversion assigned to the
+// same source nodes. Branch and class IDs are derived from node content and are left as generated.
+func (g *difyGeneration) reusePreviousIDs(data []byte) []byte {
+	if g.previousMapping == nil {
+		return data
+	}
+
+	replacements := common.ReusableIDs(g.previousMapping.Nodes, g.nodeIDMapping)
+	g.nodeIDMapping = common.RemapIDValues(g.nodeIDMapping, replacements)
+	return common.ReplaceIDs(data, replacements)
+}
+
+// sourceNodeIDMapping keeps the entries of nodeIDMapping that belong to source nodes,
+// dropping the generated-ID aliases used while rewriting references
+func sourceNodeIDMapping(nodes []models.Node, nodeIDMapping map[string]string) map[string]string {
+	result := make(map[string]string)
+	var collect func(nodes []models.Node)
+	collect = func(nodes []models.Node) {
+		for _, node := range nodes {
+			if difyID, exists := nodeIDMapping[node.ID]; exists {
+				result[node.ID] = difyID
+			}
+			if iterConfig, ok := common.AsIterationConfig(node.Config); ok && iterConfig != nil {
+				collect(iterConfig.SubWorkflow.Nodes)
+			}
+		}
+	}
+	collect(nodes)
+	return result
+}
+
+// recordHandleIDs captures the Dify case_id and class id generated for each source branch and intent
+func (g *difyGeneration) recordHandleIDs(originalNode models.Node, difyNode DifyNode) {
+	if g.handleIDMapping == nil {
+		return
+	}
+
+	switch originalNode.Type {
+	case models.NodeTypeCondition:
+		g.recordConditionCaseIDs(originalNode)
+	case models.NodeTypeClassifier:
+		g.recordClassifierClassIDs(originalNode, difyNode)
+	}
+}
+
+// recordConditionCaseIDs maps source case IDs to the generated Dify case_id values
+func (g *difyGeneration) recordConditionCaseIDs(originalNode models.Node) {
+	conditionConfig, ok := common.AsConditionConfig(originalNode.Config)
+	if !ok || conditionConfig == nil {
+		return
+	}
+	conditionGen, ok := g.nodeGeneratorFactory.generators[models.NodeTypeCondition].(*ConditionNodeGenerator)
+	if !ok {
+		return
+	}
+
+	for _, caseItem := range conditionConfig.Cases {
+		if caseID, exists := conditionGen.GetCaseID(caseItem.CaseID); exists {
+			g.handleIDMapping.AddBranch(originalNode.ID, caseItem.CaseID, caseID)
+		}
+	}
+}
+
+// recordClassifierClassIDs maps source class IDs to the generated Dify class ids, which keep the source order
+func (g *difyGeneration) recordClassifierClassIDs(originalNode models.Node, difyNode DifyNode) {
+	classifierConfig, ok := common.AsClassifierConfig(originalNode.Config)
+	if !ok || classifierConfig == nil {
+		return
+	}
+
+	for i, class := range classifierConfig.Classes {
+		if i >= len(difyNode.Data.Classes) {
+			break
+		}
+		if classID, ok := difyNode.Data.Classes[i]["id"].(string); ok {
+			g.handleIDMapping.AddIntent(originalNode.ID, class.ID, classID)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package generator
 import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -34,9 +36,9 @@ func (g *IterationNodeGenerator) GenerateNode(node models.Node) (DifyNode, error
 	// Set iteration node specific data - directly set to data field, not wrapped in config
 	g.setIterationDataFields(&difyNode.Data, node)
 
-	// According to Dify standards, iteration nodes use specific dimensions
-	difyNode.Height = 250
-	difyNode.Width = 508
+	// Empty iterations get the size of a new one; GenerateIterationNodes sizes them to their body
+	difyNode.Height = iterationMinHeight + iterationTitleHeight
+	difyNode.Width = iterationMinWidth
 	difyNode.ZIndex = 1 // zIndex for iteration main node
 
 	// Restore Dify-specific fields from platform-specific configuration
@@ -77,11 +79,16 @@ func (g *IterationNodeGenerator) GenerateIterationNodes(node models.Node) ([]Dif
 	mainNode.Data.StartNodeID = startNode.ID
 
 	// Generate internal nodes
-	internalNodes, err := g.generateInternalNodes(node, mainNode)
+	internalNodes, layout, err := g.generateInternalNodes(node, mainNode, startNode)
 	if err != nil {
 		return nil, err
 	}
 
+	// Size the iteration to its internal nodes
+	mainNode.Data.Width, mainNode.Data.Height = layout.ContainerSize(iterationPadding, iterationMinWidth, iterationMinHeight)
+	mainNode.Width = mainNode.Data.Width
+	mainNode.Height = mainNode.Data.Height + iterationTitleHeight
+
 	// Configure output selector
 	g.configureIterationOutputSelector(node, &mainNode, internalNodes)
 
@@ -102,15 +109,20 @@ func (g *IterationNodeGenerator) createIterationStartNode(node models.Node, main
 	return startNode
 }
 
-// generateInternalNodes generates all internal processing nodes
-func (g *IterationNodeGenerator) generateInternalNodes(node models.Node, mainNode DifyNode) ([]DifyNode, error) {
+// generateInternalNodes generates all internal processing nodes, and returns them with the layout
+// they were placed in
+func (g *IterationNodeGenerator) generateInternalNodes(node models.Node, mainNode, startNode DifyNode) ([]DifyNode, *common.SubNodeLayout, error) {
+	layout := common.NewSubNodeLayout(internalNodeGap)
+	layout.Reserve(models.Position{X: startNode.Position.X, Y: startNode.Position.Y}, startNode.Width, startNode.Height)
+
 	iterConfig, ok := node.Config.(*models.IterationConfig)
 	if !ok || len(iterConfig.SubWorkflow.Nodes) == 0 {
-		return []DifyNode{}, nil
+		return []DifyNode{}, layout, nil
 	}
 
 	g.setupVariableContext(iterConfig, mainNode.ID)
-	return g.processSubWorkflowNodes(iterConfig, mainNode)
+	internalNodes, err := g.processSubWorkflowNodes(iterConfig, mainNode, layout)
+	return internalNodes, layout, err
 }
 
 // setupVariableContext sets up variable mapping context
@@ -120,7 +132,7 @@ func (g *IterationNodeGenerator) setupVariableContext(iterConfig *models.Iterati
 }
 
 // processSubWorkflowNodes processes all sub-workflow nodes
-func (g *IterationNodeGenerator) processSubWorkflowNodes(iterConfig *models.IterationConfig, mainNode DifyNode) ([]DifyNode, error) {
+func (g *IterationNodeGenerator) processSubWorkflowNodes(iterConfig *models.IterationConfig, mainNode DifyNode, layout *common.SubNodeLayout) ([]DifyNode, error) {
 	var internalNodes []DifyNode
 
 	for _, subNode := range iterConfig.SubWorkflow.Nodes {
@@ -128,7 +140,7 @@ func (g *IterationNodeGenerator) processSubWorkflowNodes(iterConfig *models.Iter
 			continue
 		}
 
-		internalNode, err := g.createPositionedInternalNode(subNode, mainNode, iterConfig.SubWorkflow.Nodes)
+		internalNode, err := g.createPositionedInternalNode(subNode, mainNode, iterConfig.SubWorkflow.Nodes, layout)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate internal node %s: %w", subNode.ID, err)
 		}
@@ -144,12 +156,15 @@ func (g *IterationNodeGenerator) shouldSkipNode(subNode models.Node) bool {
 }
 
 // createPositionedInternalNode creates and positions internal node
-func (g *IterationNodeGenerator) createPositionedInternalNode(subNode models.Node, mainNode DifyNode, allNodes []models.Node) (DifyNode, error) {
+func (g *IterationNodeGenerator) createPositionedInternalNode(subNode models.Node, mainNode DifyNode, allNodes []models.Node, layout *common.SubNodeLayout) (DifyNode, error) {
 	internalNode, err := g.generateInternalNode(subNode, mainNode.ID, allNodes)
 	if err != nil {
 		return DifyNode{}, err
 	}
 
+	position := layout.Place(models.Position{X: internalNode.Position.X, Y: internalNode.Position.Y}, internalNode.Width, internalNode.Height)
+	internalNode.Position = DifyPosition{X: position.X, Y: position.Y}
+
 	internalNode.PositionAbsolute = DifyPosition{
 		X: mainNode.PositionAbsolute.X + internalNode.Position.X,
 		Y: mainNode.PositionAbsolute.Y + internalNode.Position.Y,
@@ -187,13 +202,24 @@ func (g *IterationNodeGenerator) generateIterationStartNode(parentNode models.No
 	draggable := false
 	selectable := false
 
+	// Keep the description of a start node the body brings along
+	desc := "Iterator start node"
+	if iterConfig, ok := common.AsIterationConfig(parentNode.Config); ok && iterConfig != nil {
+		for _, subNode := range iterConfig.SubWorkflow.Nodes {
+			if subNode.Type == models.NodeTypeStart && subNode.Description != "" {
+				desc = subNode.Description
+				break
+			}
+		}
+	}
+
 	return DifyNode{
 		ID:   startNodeID,
 		Type: "custom-iteration-start",
 		Data: DifyNodeData{
 			Type:          "iteration-start",
 			Title:         "",
-			Desc:          "Iterator start node",
+			Desc:          desc,
 			Selected:      false,
 			IsInIteration: true,
 			// Note: don't set IsParallel field, as iteration start node doesn't need this field
@@ -272,6 +298,18 @@ func (g *IterationNodeGenerator) setInternalNodeLayout(baseNode *DifyNode) {
 	baseNode.PositionAbsolute = DifyPosition{X: position.X, Y: position.Y}
 }
 
+// Layout of iteration containers: internal nodes stacked below one another keep internalNodeGap
+// apart, and the container keeps iterationPadding to the right and below them, but is no smaller
+// than the size of a new iteration in the Dify editor. The node height of an iteration includes
+// its title bar, which the height in its data does not.
+const (
+	internalNodeGap      = 20.0
+	iterationPadding     = 40.0
+	iterationMinWidth    = 508.0
+	iterationMinHeight   = 202.0
+	iterationTitleHeight = 48.0
+)
+
 // getNodeLayoutConfig returns position and dimensions for different node types
 func (g *IterationNodeGenerator) getNodeLayoutConfig(nodeType string) (DifyPosition, struct{ Width, Height int }) {
 	baseX, baseY := 204.0, 60.0
@@ -516,7 +554,6 @@ func (g *IterationNodeGenerator) configureIterationClassifierNode(difyNode *Dify
 func (g *IterationNodeGenerator) configureIterationConditionNode(difyNode *DifyNode, originalNode models.Node, parentID string) {
 	g.processConditionInputVariables(difyNode, originalNode, parentID)
 	g.fixConditionCaseReferences(difyNode, parentID)
-	g.fixConditionLogicalOperator(difyNode, parentID)
 }
 
 // processConditionInputVariables processes input variables for condition nodes
@@ -602,13 +639,6 @@ func (g *IterationNodeGenerator) fixConditionVariableSelector(condition map[stri
 	variableSelector[1] = outputFieldName
 }
 
-// fixConditionLogicalOperator fixes variable references in logical operators
-func (g *IterationNodeGenerator) fixConditionLogicalOperator(difyNode *DifyNode, parentID string) {
-	if logicalOperator, ok := difyNode.Data.Config["logical_operator"].(string); ok {
-		difyNode.Data.Config["logical_operator"] = g.fixIterationVariableReferences(logicalOperator, parentID)
-	}
-}
-
 // configureIterationBasicNode configures other types of iteration internal nodes
 func (g *IterationNodeGenerator) configureIterationBasicNode(difyNode *DifyNode, originalNode models.Node, parentID string) {
 	// Use unified variable mapping system to process all input variables
@@ -665,10 +695,10 @@ func (g *IterationNodeGenerator) setIterationDataFields(data *DifyNodeData, node
 
 // setIterationBasicFields sets basic configuration for iteration nodes
 func (g *IterationNodeGenerator) setIterationBasicFields(data *DifyNodeData) {
-	data.ErrorHandleMode = "terminated"
+	data.ErrorHandleMode = models.ErrorHandleModeTerminated
 	isParallel := false
 	data.IsParallel = &isParallel
-	data.ParallelNums = 10
+	data.ParallelNums = models.DefaultParallelNums
 	data.IteratorInputType = "array[string]"
 	data.OutputType = "array[string]"
 	data.Selected = false
@@ -676,19 +706,19 @@ func (g *IterationNodeGenerator) setIterationBasicFields(data *DifyNodeData) {
 
 // setIterationDimensions sets height and width according to Dify standards
 func (g *IterationNodeGenerator) setIterationDimensions(data *DifyNodeData) {
-	data.Height = 202
-	data.Width = 508
+	data.Height = iterationMinHeight
+	data.Width = iterationMinWidth
 }
 
 // processIterationConfig processes iteration configuration from node config
 func (g *IterationNodeGenerator) processIterationConfig(data *DifyNodeData, node models.Node) {
-	iterConfig, ok := node.Config.(*models.IterationConfig)
-	if !ok {
+	iterConfig, ok := common.AsIterationConfig(node.Config)
+	if !ok || iterConfig == nil {
 		return
 	}
 
 	g.setIterationInputType(data, iterConfig)
-	g.setIterationExecutionConfig(data)
+	g.setIterationExecutionConfig(data, iterConfig.Execution)
 	g.setIterationSelector(data, iterConfig)
 }
 
@@ -698,22 +728,23 @@ func (g *IterationNodeGenerator) setIterationInputType(data *DifyNodeData, iterC
 		return
 	}
 
-	switch iterConfig.Iterator.InputType {
-	case "array", "array[string]":
-		data.IteratorInputType = "array[string]"
-	case "array[object]":
-		data.IteratorInputType = "array[object]"
-	default:
-		data.IteratorInputType = "array[string]"
+	inputType := models.GetDefaultDataTypeMapping().MapToDifyTypeWithAliases(iterConfig.Iterator.InputType)
+	if !strings.HasPrefix(inputType, "array[") {
+		inputType = "array[string]"
 	}
+	data.IteratorInputType = inputType
 }
 
-// setIterationExecutionConfig sets execution configuration using Dify standard values
-func (g *IterationNodeGenerator) setIterationExecutionConfig(data *DifyNodeData) {
-	isParallel := false
+// setIterationExecutionConfig sets parallelism and error handling; Dify keeps parallel_nums
+// even for sequential iterations
+func (g *IterationNodeGenerator) setIterationExecutionConfig(data *DifyNodeData, execution models.ExecutionConfig) {
+	isParallel := execution.IsParallel
 	data.IsParallel = &isParallel
-	data.ParallelNums = 10
-	data.ErrorHandleMode = "terminated"
+	data.ParallelNums = models.DefaultParallelNums
+	if execution.IsParallel && execution.ParallelNums > 0 {
+		data.ParallelNums = execution.ParallelNums
+	}
+	data.ErrorHandleMode = models.NormalizeErrorHandleMode(execution.ErrorHandleMode)
 }
 
 // setIterationSelector sets iterator selector from configuration
@@ -895,10 +926,8 @@ func (g *IterationNodeGenerator) buildNodeMapping(originalNodes []models.Node, g
 // inferGeneratedNodeOutputName infers output name of generated node
 func (g *IterationNodeGenerator) inferGeneratedNodeOutputName(node DifyNode) string {
 	// Get output name from node's outputs configuration
-	if outputs, ok := node.Data.Outputs.(map[string]interface{}); ok {
-		for outputName := range outputs {
-			return outputName // Return first output name
-		}
+	if outputName := firstOutputName(node.Data.Outputs); outputName != "" {
+		return outputName
 	}
 
 	// Infer default output name based on node type
@@ -1069,10 +1098,8 @@ func (g *IterationNodeGenerator) inferNodeOutputName(node models.Node) string {
 // getNodeOutputName dynamically gets node's output name
 func (g *IterationNodeGenerator) getNodeOutputName(difyNode *DifyNode, originalNode models.Node) string {
 	// First check Dify node's outputs configuration (this is most accurate)
-	if outputs, ok := difyNode.Data.Outputs.(map[string]interface{}); ok {
-		for outputName := range outputs {
-			return outputName // Return first output name
-		}
+	if outputName := firstOutputName(difyNode.Data.Outputs); outputName != "" {
+		return outputName
 	}
 
 	// Second try to get from original node's outputs
@@ -1093,26 +1120,7 @@ func (g *IterationNodeGenerator) getNodeOutputName(difyNode *DifyNode, originalN
 
 // mapToValueType maps unified DSL types to Dify's value_type
 func (g *IterationNodeGenerator) mapToValueType(unifiedType string) string {
-	switch unifiedType {
-	case "string":
-		return "string"
-	case "integer": // Support integer type
-		return "number"
-	case "float": // Support float type
-		return "number"
-	case "number": // Maintain backward compatibility
-		return "number"
-	case "boolean":
-		return "boolean"
-	case "array[string]":
-		return "array[string]"
-	case "array[number]":
-		return "array[number]"
-	case "object", "array[object]":
-		return "object"
-	default:
-		return "string"
-	}
+	return models.GetDefaultDataTypeMapping().MapToDifyTypeWithAliases(unifiedType)
 }
 
 // inferNodeIDAndOutputFieldName infers correct node ID and output field name for references
@@ -1261,10 +1269,8 @@ func (g *IterationNodeGenerator) mapNodeOutputNameToDify(originalNodeID, origina
 		// Match through node ID (may need more intelligent mapping logic)
 		if strings.Contains(genNode.ID, extractNodeTypeFromID(originalNodeID)) {
 			// Get output name from actually generated node
-			if outputs, ok := genNode.Data.Outputs.(map[string]interface{}); ok {
-				for outputName := range outputs {
-					return outputName // Return first actual output name
-				}
+			if outputName := firstOutputName(genNode.Data.Outputs); outputName != "" {
+				return outputName
 			}
 
 			// Map output name based on node type
@@ -1299,81 +1305,55 @@ func extractNodeTypeFromID(nodeID string) string {
 	return "unknown"
 }
 
-// fixIterationVariableReferences fixes variable references in iteration internal nodes
-func (g *IterationNodeGenerator) fixIterationVariableReferences(text string, parentID string) string {
+// malformedReferencePattern matches references like {{class_name" that cannot be resolved without context
+var malformedReferencePattern = regexp.MustCompile(`\{\{[^}#]*[^}#]"`)
 
-	text = strings.ReplaceAll(text, "{{#"+parentID+"start.input#}}", "{{#"+parentID+".item#}}")
-	text = strings.ReplaceAll(text, "{{#"+parentID+"start.steps#}}", "{{#"+parentID+".item#}}")
-
-	inputRe := regexp.MustCompile(`\{\{#\d+\.input#\}\}`)
-	text = inputRe.ReplaceAllString(text, "{{#"+parentID+".item#}}")
-
-	stepsRe := regexp.MustCompile(`\{\{#\d+\.steps#\}\}`)
-	text = stepsRe.ReplaceAllString(text, "{{#"+parentID+".item#}}")
-
-	startInputPattern := regexp.MustCompile(`\{\{#` + regexp.QuoteMeta(parentID) + `start\.input#\}\}`)
-	text = startInputPattern.ReplaceAllString(text, "{{#"+parentID+".item#}}")
-
-	startStepsPattern := regexp.MustCompile(`\{\{#` + regexp.QuoteMeta(parentID) + `start\.steps#\}\}`)
-	text = startStepsPattern.ReplaceAllString(text, "{{#"+parentID+".item#}}")
+// fixIterationVariableReferences points references to the iteration start node, whose input and
+// steps outputs have no Dify counterpart, at the item of the iteration.
+func (g *IterationNodeGenerator) fixIterationVariableReferences(text string, parentID string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
 
-	// For example: {{#1234567890123456start.input#}} -> {{#1234567890123456.item#}}
-	anyStartInputPattern := regexp.MustCompile(`\{\{#(\d+)start\.input#\}\}`)
-	text = anyStartInputPattern.ReplaceAllStringFunc(text, func(match string) string {
-		// Extract iteration ID (remove start suffix)
-		matches := anyStartInputPattern.FindStringSubmatch(match)
-		if len(matches) >= 2 {
-			iterationID := matches[1]
-			return "{{#" + iterationID + ".item#}}"
+	item := common.InlinePromptReference(parentID, "item")
+	text = common.RewritePromptTemplate(text, func(p common.PromptPlaceholder) string {
+		if !p.IsReference() || (p.Output != "input" && p.Output != "steps") {
+			return p.Text
 		}
-		return match
-	})
-
-	anyStartStepsPattern := regexp.MustCompile(`\{\{#(\d+)start\.steps#\}\}`)
-	text = anyStartStepsPattern.ReplaceAllStringFunc(text, func(match string) string {
-		// Extract iteration ID (remove start suffix)
-		matches := anyStartStepsPattern.FindStringSubmatch(match)
-		if len(matches) >= 2 {
-			iterationID := matches[1]
-			return "{{#" + iterationID + ".item#}}"
+		// For example: {{#1234567890123456start.input#}} -> {{#1234567890123456.item#}}
+		if iterationID, ok := strings.CutSuffix(p.NodeID, "start"); ok && iterationID != parentID && isDigits(iterationID) {
+			return common.InlinePromptReference(iterationID, "item")
 		}
-		return match
+		return item
 	})
 
-	// For example: {{#iteration-node-start::uuid.input#}} -> {{#parentID.item#}}
-	uuidStartInputPattern := regexp.MustCompile(`\{\{#iteration-node-start::[^#]*\.input#\}\}`)
-	text = uuidStartInputPattern.ReplaceAllString(text, "{{#"+parentID+".item#}}")
-
-	uuidStartStepsPattern := regexp.MustCompile(`\{\{#iteration-node-start::[^#]*\.steps#\}\}`)
-	text = uuidStartStepsPattern.ReplaceAllString(text, "{{#"+parentID+".item#}}")
-
-	genericStartInputPattern := regexp.MustCompile(`\{\{#[^}]*start[^}]*\.input#\}\}`)
-	text = genericStartInputPattern.ReplaceAllString(text, "{{#"+parentID+".item#}}")
-
-	genericStartStepsPattern := regexp.MustCompile(`\{\{#[^}]*start[^}]*\.steps#\}\}`)
-	text = genericStartStepsPattern.ReplaceAllString(text, "{{#"+parentID+".item#}}")
-
-	inputPattern := regexp.MustCompile(`\{\{#[^}]*\.input#\}\}`)
-	text = inputPattern.ReplaceAllString(text, "{{#"+parentID+".item#}}")
-
-	stepsPattern := regexp.MustCompile(`\{\{#[^}]*\.steps#\}\}`)
-	text = stepsPattern.ReplaceAllString(text, "{{#"+parentID+".item#}}")
-
-	malformedPattern := regexp.MustCompile(`\{\{[^}#]*[^}#]"`)
-	text = malformedPattern.ReplaceAllStringFunc(text, func(match string) string {
-		// Extract the variable name from malformed pattern like {{class_name" or {{variableName"
-		varName := strings.TrimPrefix(match, "{{")
-		varName = strings.TrimSuffix(varName, "\"")
+	return malformedReferencePattern.ReplaceAllLiteralString(text, item)
+}
 
-		// For specific known cases like class_name, we can try to be more intelligent
-		if varName == "class_name" {
-			// This should reference a classifier node's output, but without context
-			return "{{#" + parentID + ".item#}}"
+// isDigits reports whether s is a non-empty string of decimal digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, char := range s {
+		if char < '0' || char > '9' {
+			return false
 		}
+	}
+	return true
+}
 
-		// Default fallback for other malformed patterns
-		return "{{#" + parentID + ".item#}}"
-	})
-
-	return text
+// firstOutputName returns the alphabetically first key of a Dify outputs map, so that the pick
+// does not depend on map iteration order
+func firstOutputName(outputs interface{}) string {
+	outputMap, ok := outputs.(map[string]interface{})
+	if !ok || len(outputMap) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(outputMap))
+	for name := range outputMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names[0]
 }
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// ListOperatorNodeGenerator generates Dify list operator nodes
+type ListOperatorNodeGenerator struct {
+	*BaseNodeGenerator
+	variableSelectorConverter *VariableSelectorConverter
+}
+
+func NewListOperatorNodeGenerator() *ListOperatorNodeGenerator {
+	return &ListOperatorNodeGenerator{
+		BaseNodeGenerator:         NewBaseNodeGenerator(models.NodeTypeListOperation),
+		variableSelectorConverter: NewVariableSelectorConverter(),
+	}
+}
+
+// SetNodeMapping sets node mapping for variable selector converter
+func (g *ListOperatorNodeGenerator) SetNodeMapping(nodes []models.Node) {
+	g.variableSelectorConverter.SetNodeMapping(nodes)
+}
+
+// GenerateNode generates a list operator node processing the list of the first input.
+// Map field operations have no Dify equivalent and are lowered to code nodes beforehand.
+func (g *ListOperatorNodeGenerator) GenerateNode(node models.Node) (DifyNode, error) {
+	if node.Type != models.NodeTypeListOperation {
+		return DifyNode{}, fmt.Errorf("unsupported node type: %s, expected: %s", node.Type, models.NodeTypeListOperation)
+	}
+
+	config, ok := common.AsListOperationConfig(node.Config)
+	if !ok || config == nil {
+		return DifyNode{}, fmt.Errorf("list operation node must have ListOperationConfig")
+	}
+
+	mapping := models.GetDefaultDataTypeMapping()
+	itemType := mapping.ToDifyType(config.ItemType)
+	listType := "array[" + itemType + "]"
+	if len(node.Inputs) > 0 && node.Inputs[0].Type != "" {
+		listType = mapping.ToDifyType(node.Inputs[0].Type)
+	}
+
+	difyNode := g.generateBaseNode(node)
+	difyNode.Data.Variable = g.generateListSelector(node)
+	difyNode.Data.VarType = listType
+	difyNode.Data.ItemVarType = itemType
+	difyNode.Data.FilterBy = g.generateFilterBy(config.Filters, config.ItemType)
+	difyNode.Data.ExtractBy = map[string]interface{}{"enabled": false, "serial": "1"}
+	if config.ExtractIndex > 0 {
+		difyNode.Data.ExtractBy = map[string]interface{}{"enabled": true, "serial": strconv.Itoa(config.ExtractIndex)}
+	}
+	difyNode.Data.OrderBy = map[string]interface{}{"enabled": false, "key": "", "value": "asc"}
+	if config.SortBy != nil {
+		order := "asc"
+		if !config.SortBy.Ascending {
+			order = "desc"
+		}
+		difyNode.Data.OrderBy = map[string]interface{}{"enabled": true, "key": config.SortBy.Key, "value": order}
+	}
+	difyNode.Data.Limit = map[string]interface{}{"enabled": false, "size": 10}
+	if config.Limit > 0 {
+		difyNode.Data.Limit = map[string]interface{}{"enabled": true, "size": config.Limit}
+	}
+
+	return difyNode, nil
+}
+
+// generateListSelector generates the variable selector of the list to process
+func (g *ListOperatorNodeGenerator) generateListSelector(node models.Node) []string {
+	if len(node.Inputs) == 0 || node.Inputs[0].Reference == nil {
+		return []string{}
+	}
+
+	reference := node.Inputs[0].Reference
+	valueSelector, err := g.variableSelectorConverter.ConvertVariableReference(reference)
+	if err != nil {
+		return []string{reference.NodeID, reference.OutputName}
+	}
+	return valueSelector
+}
+
+// generateFilterBy generates the filter conditions
+func (g *ListOperatorNodeGenerator) generateFilterBy(filters []models.ListFilterCondition, itemType models.UnifiedDataType) map[string]interface{} {
+	conditions := make([]interface{}, 0, len(filters))
+	for _, filter := range filters {
+		conditions = append(conditions, map[string]interface{}{
+			"key":                 filter.Key,
+			"comparison_operator": g.mapOperator(filter.Operator, itemType),
+			"value":               filter.Value,
+		})
+	}
+
+	return map[string]interface{}{
+		"enabled":    len(conditions) > 0,
+		"conditions": conditions,
+	}
+}
+
+// mapOperator maps condition node operators to Dify list filter operators. Ordering comparisons
+// are symbolic; equality is symbolic for numeric items only.
+func (g *ListOperatorNodeGenerator) mapOperator(operator string, itemType models.UnifiedDataType) string {
+	if models.IsNumericType(itemType) {
+		switch operator {
+		case "equals":
+			return "="
+		case "not_equals":
+			return "≠"
+		}
+	}
+
+	operators := map[string]string{
+		"contains":     "contains",
+		"not_contains": "not contains",
+		"starts_with":  "start with",
+		"ends_with":    "end with",
+		"equals":       "is",
+		"not_equals":   "is not",
+		"is_empty":     "empty",
+		"is_not_empty": "not empty",
+		"in":           "in",
+		"not_in":       "not in",
+		"gt":           ">",
+		"lt":           "<",
+		"gte":          "≥",
+		"lte":          "≤",
+	}
+	if mapped, exists := operators[operator]; exists {
+		return mapped
+	}
+	return operator
+}
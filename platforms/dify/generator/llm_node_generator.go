@@ -3,6 +3,7 @@ package generator
 import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 	"strings"
 )
 
@@ -65,19 +66,11 @@ func (g *LLMNodeGenerator) generateModelConfig(node models.Node) map[string]inte
 	if llmConfig, ok := node.Config.(models.LLMConfig); ok {
 		// Get model parameters - support iFlytek SparkAgent core parameters: Temperature, MaxTokens, TopK
 		if params, ok := modelConfig["completion_params"].(map[string]interface{}); ok {
-			// Temperature parameter
-			if llmConfig.Parameters.Temperature > 0 {
-				params["temperature"] = llmConfig.Parameters.Temperature
-			}
-
-			// MaxTokens parameter - iFlytek SparkAgent's maxTokens maps to Dify's max_tokens
-			if llmConfig.Parameters.MaxTokens > 0 {
-				params["max_tokens"] = llmConfig.Parameters.MaxTokens
-			}
+			setCompletionParams(params, llmConfig.Parameters)
 
-			// TopK parameter - iFlytek SparkAgent's topK maps to Dify's top_k
-			if llmConfig.Parameters.TopK > 0 {
-				params["top_k"] = llmConfig.Parameters.TopK
+			// JSON answers without a structured output schema use the JSON mode of the model
+			if llmConfig.Parameters.ResponseFormat == models.ResponseFormatJSON && g.generateStructuredOutput(node) == nil {
+				params["response_format"] = "json_object"
 			}
 		}
 
@@ -113,9 +106,24 @@ func (g *LLMNodeGenerator) generateVisionConfig(node models.Node) map[string]int
 	}
 
 	// Extract vision configuration from unified DSL settings
-	if llmConfig, ok := node.Config.(models.LLMConfig); ok {
-		if llmConfig.Vision != nil {
-			visionConfig["enabled"] = llmConfig.Vision.Enabled
+	llmConfig, ok := common.AsLLMConfig(node.Config)
+	if !ok || llmConfig == nil || llmConfig.Vision == nil {
+		return visionConfig
+	}
+	vision := llmConfig.Vision
+	visionConfig["enabled"] = vision.Enabled
+	if vision.Enabled {
+		// Images default to the files uploaded with the chat message
+		detail, selector := vision.Detail, vision.VariableSelector
+		if detail == "" {
+			detail = models.VisionDetailHigh
+		}
+		if len(selector) < 2 {
+			selector = []string{"sys", "files"}
+		}
+		visionConfig["configs"] = map[string]interface{}{
+			"detail":            detail,
+			"variable_selector": selector,
 		}
 	}
 
@@ -145,12 +153,41 @@ func (g *LLMNodeGenerator) setLLMDataFields(data *DifyNodeData, node models.Node
 	data.PromptTemplate = promptTemplate
 	data.Variables = []interface{}{} // Empty interface{} array, consistent with official example
 	data.Vision = visionConfig
+
+	// JSON answers follow a structured output schema when their fields are known
+	if structuredOutput := g.generateStructuredOutput(node); structuredOutput != nil {
+		data.StructuredOutputEnabled = true
+		data.StructuredOutput = structuredOutput
+	}
+}
+
+// generateStructuredOutput returns the schema of JSON answers: the source schema, or else one
+// field per output when the outputs cannot be filled from text.
+func (g *LLMNodeGenerator) generateStructuredOutput(node models.Node) *DifyStructuredOutput {
+	llmConfig, ok := common.AsLLMConfig(node.Config)
+	if !ok || llmConfig == nil || llmConfig.Parameters.ResponseFormat != models.ResponseFormatJSON {
+		return nil
+	}
+	if len(llmConfig.OutputSchema) > 0 {
+		return &DifyStructuredOutput{Schema: llmConfig.OutputSchema}
+	}
+	if common.NeedsJSONFields(node.Outputs) {
+		return &DifyStructuredOutput{Schema: common.SchemaFromOutputs(node.Outputs)}
+	}
+	return nil
 }
 
 // generateContextConfig generates context configuration
 func (g *LLMNodeGenerator) generateContextConfig(node models.Node) map[string]interface{} {
-	// LLM nodes in Dify should have disabled context with empty variable_selector
-	// Variable references should only exist in prompt template, not in context configuration
+	// Variable references of other platforms live in the prompt template; only Dify sources bind a
+	// context variable, which is kept
+	if llmConfig, ok := common.AsLLMConfig(node.Config); ok && llmConfig != nil && llmConfig.Context != nil &&
+		llmConfig.Context.Enabled && len(llmConfig.Context.VariableSelector) >= 2 {
+		return map[string]interface{}{
+			"enabled":           true,
+			"variable_selector": llmConfig.Context.VariableSelector,
+		}
+	}
 	context := map[string]interface{}{
 		"enabled":           false,
 		"variable_selector": []interface{}{}, // Empty array, consistent with correct Dify examples
@@ -171,7 +208,14 @@ func (g *LLMNodeGenerator) generatePromptTemplate(node models.Node) []map[string
 		}
 	}
 
-	// Use node description if no platform configuration available
+	// Otherwise the messages of the unified prompt, in their order and roles
+	if systemTemplate == "You are a helpful assistant." {
+		if messages := g.promptMessages(node); len(messages) > 0 {
+			return messages
+		}
+	}
+
+	// Use node description if no prompt is available
 	if systemTemplate == "You are a helpful assistant." && node.Description != "" {
 		systemTemplate = node.Description
 	}
@@ -190,170 +234,43 @@ func (g *LLMNodeGenerator) generatePromptTemplate(node models.Node) []map[string
 	return template
 }
 
-// fixVariableReferences corrects variable reference format from iFlytek to Dify
-func (g *LLMNodeGenerator) fixVariableReferences(text string, node models.Node) string {
-	replacements := g.buildVariableReplacements(text, node)
-	return g.applyReplacements(text, replacements)
-}
-
-// buildVariableReplacements builds complete variable replacement mapping
-func (g *LLMNodeGenerator) buildVariableReplacements(text string, node models.Node) map[string]string {
-	replacements := make(map[string]string)
-
-	// First priority: build from direct input references
-	g.addDirectInputReplacements(node, replacements)
-
-	// Second priority: extract from template if no direct references
-	if len(replacements) == 0 {
-		replacements = g.extractVariablesFromTemplate(text, node)
+// promptMessages returns the prompt template of the unified prompt: its messages, or else its
+// system and user templates
+func (g *LLMNodeGenerator) promptMessages(node models.Node) []map[string]interface{} {
+	llmConfig, ok := common.AsLLMConfig(node.Config)
+	if !ok || llmConfig == nil {
+		return nil
 	}
-
-	// Third priority: handle template variables with source node inference
-	g.addTemplateVariableReplacements(text, node, replacements)
-
-	return replacements
-}
-
-// addDirectInputReplacements adds replacements from direct input references
-func (g *LLMNodeGenerator) addDirectInputReplacements(node models.Node, replacements map[string]string) {
-	for _, input := range node.Inputs {
-		if g.hasValidReference(input.Reference) {
-			starPattern := fmt.Sprintf("{{%s}}", input.Reference.OutputName)
-			difyPattern := fmt.Sprintf("{{#%s.%s#}}", input.Reference.NodeID, input.Reference.OutputName)
-			replacements[starPattern] = difyPattern
+	messages := llmConfig.Prompt.Messages
+	if len(messages) == 0 {
+		if llmConfig.Prompt.SystemTemplate != "" {
+			messages = append(messages, models.Message{Role: "system", Content: llmConfig.Prompt.SystemTemplate})
 		}
-	}
-}
-
-// addTemplateVariableReplacements adds replacements for template variables with source node inference
-func (g *LLMNodeGenerator) addTemplateVariableReplacements(text string, node models.Node, replacements map[string]string) {
-	if len(node.Inputs) == 0 {
-		return
-	}
-
-	sourceNodeID := g.findSourceNodeID(node)
-	if sourceNodeID == "" {
-		return
-	}
-
-	templateVars := g.extractVariableNamesFromTemplate(text)
-	for _, varName := range templateVars {
-		starPattern := fmt.Sprintf("{{%s}}", varName)
-		if _, exists := replacements[starPattern]; !exists {
-			outputFieldName := g.inferOutputFieldName(sourceNodeID, varName)
-			difyPattern := fmt.Sprintf("{{#%s.%s#}}", sourceNodeID, outputFieldName)
-			replacements[starPattern] = difyPattern
+		if llmConfig.Prompt.UserTemplate != "" {
+			messages = append(messages, models.Message{Role: "user", Content: llmConfig.Prompt.UserTemplate})
 		}
 	}
-}
 
-// hasValidReference checks if input reference is valid
-func (g *LLMNodeGenerator) hasValidReference(ref *models.VariableReference) bool {
-	return ref != nil && ref.NodeID != "" && ref.OutputName != ""
-}
-
-// findSourceNodeID finds the first available source node ID from inputs
-func (g *LLMNodeGenerator) findSourceNodeID(node models.Node) string {
-	for _, input := range node.Inputs {
-		if input.Reference != nil && input.Reference.NodeID != "" {
-			return input.Reference.NodeID
+	template := make([]map[string]interface{}, 0, len(messages))
+	for _, message := range messages {
+		if message.Content == "" {
+			continue
 		}
+		template = append(template, map[string]interface{}{
+			"id":   generateRandomUUID(),
+			"role": message.Role,
+			"text": g.fixVariableReferences(message.Content, node),
+		})
 	}
-	return ""
-}
-
-// applyReplacements applies all replacements to text
-func (g *LLMNodeGenerator) applyReplacements(text string, replacements map[string]string) string {
-	result := text
-	for old, new := range replacements {
-		result = strings.ReplaceAll(result, old, new)
-	}
-
-	// Post-process to fix common template syntax issues
-	result = g.fixCommonTemplateSyntaxIssues(result)
-
-	return result
-}
-
-// fixCommonTemplateSyntaxIssues fixes common template syntax problems
-func (g *LLMNodeGenerator) fixCommonTemplateSyntaxIssues(text string) string {
-	// This function is kept for potential future use
-	// Main fixing is now handled in the replacement logic itself
-	return text
+	return template
 }
 
-// extractVariablesFromTemplate extracts variables from template and attempts mapping to node references
-func (g *LLMNodeGenerator) extractVariablesFromTemplate(text string, node models.Node) map[string]string {
-	replacements := make(map[string]string)
-
-	// Find all variable references in template {{variableName}}
-	start := 0
-	for {
-		startIdx := strings.Index(text[start:], "{{")
-		if startIdx == -1 {
-			break
-		}
-		startIdx += start
-
-		endIdx := strings.Index(text[startIdx:], "}}")
-		if endIdx == -1 {
-			// Handle incomplete template variables - look for likely end patterns
-			restText := text[startIdx+2:]
-
-			// Find variable name by looking for the next delimiter (space, quote, newline, etc.)
-			var varName string
-
-			// Look for common delimiters that might end a variable name
-			delimiters := []string{" ", "\"", "\n", "\r", "\t", "|", ":", "，", "。"}
-			minIdx := len(restText)
-
-			for _, delim := range delimiters {
-				if idx := strings.Index(restText, delim); idx != -1 && idx < minIdx {
-					minIdx = idx
-				}
-			}
-
-			if minIdx < len(restText) && minIdx > 0 {
-				varName = strings.TrimSpace(restText[:minIdx])
-			} else if len(restText) > 0 {
-				varName = strings.TrimSpace(restText)
-			}
-
-			if varName != "" {
-				sourceNodeID, outputFieldName := g.findSourceNodeAndOutputForVariable(varName, node)
-				if sourceNodeID != "" && outputFieldName != "" {
-					// Handle both quoted and unquoted patterns
-					// Pattern 1: "{{varName"  -> "{{#nodeId.field#}}"
-					quotedIncompletePattern := fmt.Sprintf(`"{{%s"`, varName)
-					quotedDifyPattern := fmt.Sprintf(`"{{#%s.%s#}}"`, sourceNodeID, outputFieldName)
-					replacements[quotedIncompletePattern] = quotedDifyPattern
-
-					// Pattern 2: {{varName  -> {{#nodeId.field#}}
-					incompletePattern := fmt.Sprintf("{{%s", varName)
-					difyPattern := fmt.Sprintf("{{#%s.%s#}}", sourceNodeID, outputFieldName)
-					replacements[incompletePattern] = difyPattern
-				}
-			}
-			break
-		}
-		endIdx += startIdx
-
-		// Extract variable name
-		varName := strings.TrimSpace(text[startIdx+2 : endIdx])
-		if varName != "" {
-			// Try to find corresponding input reference
-			sourceNodeID, outputFieldName := g.findSourceNodeAndOutputForVariable(varName, node)
-			if sourceNodeID != "" && outputFieldName != "" {
-				starPattern := fmt.Sprintf("{{%s}}", varName)
-				difyPattern := fmt.Sprintf("{{#%s.%s#}}", sourceNodeID, outputFieldName)
-				replacements[starPattern] = difyPattern
-			}
-		}
-
-		start = endIdx + 2
-	}
-
-	return replacements
+// fixVariableReferences rewrites named placeholders of iFlytek and Coze prompts to Dify references.
+// Names no input is bound to are resolved by inferring the output of the first referenced node.
+func (g *LLMNodeGenerator) fixVariableReferences(text string, node models.Node) string {
+	return common.InlinePromptReferences(text, node.Inputs, func(name string) (string, string) {
+		return g.findSourceNodeAndOutputForVariable(name, node)
+	})
 }
 
 // findSourceNodeAndOutputForVariable finds source node ID and correct output field name for variable
@@ -428,172 +345,6 @@ func (g *LLMNodeGenerator) mapOutputFieldByNodeType(nodeType, varName string) st
 	}
 }
 
-// findSourceNodeForVariable finds source node ID for variable (backward compatibility)
-func (g *LLMNodeGenerator) findSourceNodeForVariable(varName string, node models.Node) string {
-	sourceNodeID, _ := g.findSourceNodeAndOutputForVariable(varName, node)
-	return sourceNodeID
-}
-
-// extractVariableNamesFromTemplate extracts all variable names from template
-func (g *LLMNodeGenerator) extractVariableNamesFromTemplate(text string) []string {
-	var varNames []string
-	seen := make(map[string]bool)
-	start := 0
-
-	for {
-		varInfo := g.findNextVariablePattern(text, start)
-		if varInfo == nil {
-			break
-		}
-
-		varName := g.processVariableExtraction(text, varInfo)
-		if varName != "" && !seen[varName] {
-			varNames = append(varNames, varName)
-			seen[varName] = true
-		}
-
-		start = varInfo.nextStart
-	}
-
-	return varNames
-}
-
-// variablePatternInfo contains information about found variable pattern
-type variablePatternInfo struct {
-	startIdx   int
-	endIdx     int
-	nextStart  int
-	hasClosing bool
-}
-
-// findNextVariablePattern finds next variable pattern in text
-func (g *LLMNodeGenerator) findNextVariablePattern(text string, start int) *variablePatternInfo {
-	startIdx := strings.Index(text[start:], "{{")
-	if startIdx == -1 {
-		return nil
-	}
-	startIdx += start
-
-	endIdx := strings.Index(text[startIdx:], "}}")
-	if endIdx == -1 {
-		return &variablePatternInfo{
-			startIdx:   startIdx,
-			endIdx:     -1,
-			nextStart:  startIdx + 2,
-			hasClosing: false,
-		}
-	}
-
-	endIdx += startIdx
-	return &variablePatternInfo{
-		startIdx:   startIdx,
-		endIdx:     endIdx,
-		nextStart:  endIdx + 2,
-		hasClosing: true,
-	}
-}
-
-// processVariableExtraction processes variable extraction from pattern info
-func (g *LLMNodeGenerator) processVariableExtraction(text string, varInfo *variablePatternInfo) string {
-	if varInfo.hasClosing {
-		return g.extractValidVariableName(text, varInfo.startIdx, varInfo.endIdx)
-	}
-	return g.extractIncompleteVariableName(text, varInfo.startIdx)
-}
-
-// extractValidVariableName extracts variable name from complete pattern
-func (g *LLMNodeGenerator) extractValidVariableName(text string, startIdx, endIdx int) string {
-	varName := strings.TrimSpace(text[startIdx+2 : endIdx])
-	if g.isSimpleVariableName(varName) {
-		return varName
-	}
-	return ""
-}
-
-// extractIncompleteVariableName extracts variable name from incomplete pattern
-func (g *LLMNodeGenerator) extractIncompleteVariableName(text string, startIdx int) string {
-	remainingText := text[startIdx+2:]
-	varEndIdx := g.findVariableEndBoundary(remainingText)
-	if varEndIdx <= 0 {
-		return ""
-	}
-
-	varName := strings.TrimSpace(remainingText[:varEndIdx])
-	if g.isValidVariableName(varName) {
-		return varName
-	}
-	return ""
-}
-
-// isSimpleVariableName checks if variable name is simple (not already converted)
-func (g *LLMNodeGenerator) isSimpleVariableName(varName string) bool {
-	return varName != "" && !strings.Contains(varName, "#") && !strings.Contains(varName, ".")
-}
-
-// findVariableEndBoundary finds possible ending boundary for variable name
-func (g *LLMNodeGenerator) findVariableEndBoundary(text string) int {
-	// Common variable name ending markers: Chinese characters, spaces, punctuation
-	boundaryChars := []rune("这是为了 。，！？：；（）【】")
-
-	for i, char := range text {
-		// Check if Chinese characters encountered
-		if char >= 0x4e00 && char <= 0x9fff {
-			return i
-		}
-
-		// Check if common boundary characters encountered
-		for _, boundary := range boundaryChars {
-			if char == boundary {
-				return i
-			}
-		}
-
-		// Spaces may also be boundaries
-		if char == ' ' || char == '\t' || char == '\n' {
-			return i
-		}
-
-		// Force truncation if exceeding reasonable variable name length (30 characters)
-		if i >= 30 {
-			return i
-		}
-	}
-
-	// Return length of remaining text if no clear boundary found
-	return len(text)
-}
-
-// isValidVariableName validates whether variable name is legal
-func (g *LLMNodeGenerator) isValidVariableName(varName string) bool {
-	if !g.isValidVariableLength(varName) {
-		return false
-	}
-	return g.hasOnlyValidChars(varName)
-}
-
-// isValidVariableLength checks if variable name length is valid
-func (g *LLMNodeGenerator) isValidVariableLength(varName string) bool {
-	return len(varName) > 0 && len(varName) <= 50
-}
-
-// hasOnlyValidChars checks if variable name contains only valid characters
-func (g *LLMNodeGenerator) hasOnlyValidChars(varName string) bool {
-	for _, char := range varName {
-		if !g.isValidVariableChar(char) {
-			return false
-		}
-	}
-	return true
-}
-
-// isValidVariableChar checks if a character is valid for variable names
-func (g *LLMNodeGenerator) isValidVariableChar(char rune) bool {
-	return (char >= 'a' && char <= 'z') ||
-		(char >= 'A' && char <= 'Z') ||
-		(char >= '0' && char <= '9') ||
-		char == '_'
-}
-
 // restoreDifyPlatformConfig restores Dify platform-specific configuration
 func (g *LLMNodeGenerator) restoreDifyPlatformConfig(config map[string]interface{}, node *DifyNode) {
 	// Restore model configuration
@@ -628,3 +379,35 @@ func (g *LLMNodeGenerator) restoreDifyPlatformConfig(config map[string]interface
 		node.Data.Title = title
 	}
 }
+
+// setCompletionParams writes the model parameters LLM and classifier nodes share into Dify
+// completion_params, leaving out the unset ones
+func setCompletionParams(params map[string]interface{}, parameters models.ModelParameters) {
+	if parameters.Temperature > 0 {
+		params["temperature"] = parameters.Temperature
+	}
+	// iFlytek SparkAgent's maxTokens and topK map to max_tokens and top_k
+	if parameters.MaxTokens > 0 {
+		params["max_tokens"] = parameters.MaxTokens
+	}
+	if parameters.TopK > 0 {
+		params["top_k"] = parameters.TopK
+	}
+
+	// Sampling parameters other platforms may not have
+	if parameters.TopP > 0 {
+		params["top_p"] = parameters.TopP
+	}
+	if parameters.PresencePenalty != 0 {
+		params["presence_penalty"] = parameters.PresencePenalty
+	}
+	if parameters.FrequencyPenalty != 0 {
+		params["frequency_penalty"] = parameters.FrequencyPenalty
+	}
+	if len(parameters.Stop) > 0 {
+		params["stop"] = parameters.Stop
+	}
+	if parameters.Seed != 0 {
+		params["seed"] = parameters.Seed
+	}
+}
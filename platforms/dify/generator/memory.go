@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// Memory defaults of the Dify editor
+const (
+	defaultQueryPromptTemplate = "{{#sys.query#}}"
+	defaultMemoryWindowSize    = 50
+)
+
+// applyMemory emits the conversation history settings of LLM and classifier nodes. Only
+// chatflows have a conversation, so workflow apps get none.
+func (g *difyGeneration) applyMemory(unifiedDSL *models.UnifiedDSL, difyDSL *DifyRootStructure) {
+	if difyDSL.App.Mode != "advanced-chat" {
+		return
+	}
+	sourceNodes := common.NodesByTargetID(unifiedDSL.Workflow.Nodes, g.nodeIDMapping)
+
+	nodes := difyDSL.Workflow.Graph.Nodes
+	for i := range nodes {
+		sourceNode := sourceNodes[nodes[i].ID]
+		if sourceNode == nil {
+			continue
+		}
+		if memory := common.MemoryOf(*sourceNode); memory != nil && memory.Enabled {
+			nodes[i].Data.Memory = generateMemory(memory)
+		}
+	}
+}
+
+func generateMemory(memory *models.MemoryConfig) *DifyMemory {
+	difyMemory := &DifyMemory{
+		QueryPromptTemplate: memory.QueryPrompt,
+		Window:              DifyMemoryWindow{Enabled: memory.Window > 0, Size: memory.Window},
+	}
+	if difyMemory.QueryPromptTemplate == "" {
+		difyMemory.QueryPromptTemplate = defaultQueryPromptTemplate
+	}
+	if !difyMemory.Window.Enabled {
+		difyMemory.Window.Size = defaultMemoryWindowSize
+	}
+	if memory.RolePrefix != nil {
+		difyMemory.RolePrefix = &DifyRolePrefix{User: memory.RolePrefix.User, Assistant: memory.RolePrefix.Assistant}
+	}
+	return difyMemory
+}
@@ -88,6 +88,14 @@ func mapNodeTypeToDify(nodeType models.NodeType) string {
 		return "question-classifier"
 	case models.NodeTypeIteration:
 		return "iteration"
+	case models.NodeTypeHumanInput:
+		return "answer"
+	case models.NodeTypeDocumentExtractor:
+		return "document-extractor"
+	case models.NodeTypeListOperation:
+		return "list-operator"
+	case models.NodeTypeAgent:
+		return "agent"
 	default:
 		return string(nodeType) // Fallback to original type
 	}
@@ -30,6 +30,10 @@ func (f *NodeGeneratorFactory) registerGenerators() {
 	f.generators[models.NodeTypeCondition] = NewConditionNodeGenerator()
 	f.generators[models.NodeTypeClassifier] = NewClassifierNodeGenerator()
 	f.generators[models.NodeTypeIteration] = NewIterationNodeGenerator()
+	f.generators[models.NodeTypeHumanInput] = NewHumanInputNodeGenerator()
+	f.generators[models.NodeTypeDocumentExtractor] = NewDocumentExtractorNodeGenerator()
+	f.generators[models.NodeTypeListOperation] = NewListOperatorNodeGenerator()
+	f.generators[models.NodeTypeAgent] = NewAgentNodeGenerator()
 }
 
 // GetGenerator returns the node generator for the specified type
@@ -77,6 +81,21 @@ func (f *NodeGeneratorFactory) SetNodeMapping(nodes []models.Node) {
 		iterationGen.SetNodeMapping(nodes)
 	}
 
+	// Set node mapping for Document extractor node generator
+	if extractorGen, ok := f.generators[models.NodeTypeDocumentExtractor].(*DocumentExtractorNodeGenerator); ok {
+		extractorGen.SetNodeMapping(nodes)
+	}
+
+	// Set node mapping for List operator node generator
+	if listGen, ok := f.generators[models.NodeTypeListOperation].(*ListOperatorNodeGenerator); ok {
+		listGen.SetNodeMapping(nodes)
+	}
+
+	// Set node mapping for Agent node generator
+	if agentGen, ok := f.generators[models.NodeTypeAgent].(*AgentNodeGenerator); ok {
+		agentGen.SetNodeMapping(nodes)
+	}
+
 	// Future: Add similar settings for other generators that need node mapping
 }
 
@@ -80,6 +80,11 @@ func (g *StartNodeGenerator) generateVariablesFromConfig(variables []models.Vari
 	difyVariables := make([]DifyVariable, 0, len(variables))
 
 	for _, variable := range variables {
+		if variable.File != nil {
+			difyVariables = append(difyVariables, g.generateFileVariable(variable))
+			continue
+		}
+
 		// Check if type is supported by Dify start node
 		varType := models.UnifiedDataType(variable.Type)
 		if !g.isDifyStartNodeSupportedType(varType) {
@@ -88,15 +93,18 @@ func (g *StartNodeGenerator) generateVariablesFromConfig(variables []models.Vari
 		}
 
 		difyVar := DifyVariable{
-			Label:    variable.Label,
-			Variable: variable.Name,
-			Type:     g.mapOutputTypeToDify(varType),
-			Required: variable.Required,
-			Options:  []string{},
+			Label:       variable.Label,
+			Variable:    variable.Name,
+			Type:        g.variableControlType(variable),
+			Required:    variable.Required,
+			Options:     []string{},
+			Default:     variable.Default,
+			Placeholder: variable.Placeholder,
+			Hint:        variable.Description,
 		}
 
-		// Apply common variable settings
-		g.applyCommonVariableSettings(&difyVar, variable.Default, variable.Constraints)
+		// Apply common variable settings; the label of variables is final, their default is a value
+		g.applyCommonVariableSettings(&difyVar, nil, variable.Constraints)
 
 		difyVariables = append(difyVariables, difyVar)
 	}
@@ -104,11 +112,46 @@ func (g *StartNodeGenerator) generateVariablesFromConfig(variables []models.Vari
 	return difyVariables
 }
 
-// applyCommonVariableSettings applies common settings for variables
-func (g *StartNodeGenerator) applyCommonVariableSettings(variable *DifyVariable, defaultValue interface{}, constraints *models.Constraints) {
+// generateFileVariable generates a file or file-list variable
+func (g *StartNodeGenerator) generateFileVariable(variable models.Variable) DifyVariable {
+	difyVar := DifyVariable{
+		Label:                    variable.Label,
+		Variable:                 variable.Name,
+		Type:                     "file",
+		Required:                 variable.Required,
+		Options:                  []string{},
+		AllowedFileTypes:         variable.File.AllowedTypes,
+		AllowedFileExtensions:    variable.File.AllowedExtensions,
+		AllowedFileUploadMethods: variable.File.UploadMethods,
+	}
+
+	if len(difyVar.AllowedFileTypes) == 0 {
+		difyVar.AllowedFileTypes = []string{models.FileTypeDocument}
+	}
+	if len(difyVar.AllowedFileUploadMethods) == 0 {
+		difyVar.AllowedFileUploadMethods = []string{"local_file", "remote_url"}
+	}
+	if difyVar.Label == "" {
+		difyVar.Label = variable.Name
+	}
+
+	if variable.File.Multiple {
+		difyVar.Type = "file-list"
+		difyVar.MaxLength = variable.File.MaxCount
+		if difyVar.MaxLength <= 0 {
+			difyVar.MaxLength = 5
+		}
+	}
+
+	return difyVar
+}
+
+// applyCommonVariableSettings applies common settings for variables; a string labelHint, as iFlytek
+// outputs keep their description in the default, replaces the label
+func (g *StartNodeGenerator) applyCommonVariableSettings(variable *DifyVariable, labelHint interface{}, constraints *models.Constraints) {
 	// Apply all variable settings in sequence
 	g.fixVariableSpellingErrors(variable)
-	g.setVariableDisplayName(variable, defaultValue)
+	g.setVariableDisplayName(variable, labelHint)
 	g.setVariableLengthLimits(variable, constraints)
 	g.setVariableOptions(variable, constraints)
 }
@@ -264,6 +307,27 @@ func (g *StartNodeGenerator) isDifyStartNodeSupportedType(dataType models.Unifie
 	}
 }
 
+// variableControlType returns the Dify component of a start variable, from its input control
+// when the source recorded one
+func (g *StartNodeGenerator) variableControlType(variable models.Variable) string {
+	switch variable.Control {
+	case models.VariableControlText:
+		return "text-input"
+	case models.VariableControlParagraph:
+		return "paragraph"
+	case models.VariableControlSelect:
+		return "select"
+	case models.VariableControlNumber:
+		return "number"
+	}
+
+	// Sources without controls still carry the choices of a select
+	if g.hasConstraintOptions(variable.Constraints) && models.UnifiedDataType(variable.Type) == models.DataTypeString {
+		return "select"
+	}
+	return g.mapOutputTypeToDify(models.UnifiedDataType(variable.Type))
+}
+
 // mapOutputTypeToDify maps output types to Dify UI component types
 // Note: This function maps to UI component types, not data types, so special handling is needed
 func (g *StartNodeGenerator) mapOutputTypeToDify(dataType models.UnifiedDataType) string {
@@ -1,9 +1,11 @@
 package generator
 
+import "github.com/iflytek/agentbridge/internal/models"
+
 // DifyRootStructure represents the Dify root structure
 type DifyRootStructure struct {
 	App          DifyApp          `yaml:"app"`
-	Dependencies []DifyDependency `yaml:"dependencies"`
+	Dependencies []DifyDependency `yaml:"dependencies,omitempty"`
 	Kind         string           `yaml:"kind"`
 	Version      string           `yaml:"version"`
 	Workflow     DifyWorkflow     `yaml:"workflow"`
@@ -17,6 +19,11 @@ type DifyApp struct {
 	IconBackground      string `yaml:"icon_background"`
 	Mode                string `yaml:"mode"`
 	UseIconAsAnswerIcon bool   `yaml:"use_icon_as_answer_icon"`
+
+	// Catalog metadata of the app, written only when set
+	Tags    []string `yaml:"tags,omitempty"`
+	Author  string   `yaml:"author,omitempty"`
+	Version string   `yaml:"version,omitempty"`
 }
 
 // DifyDependency represents a Dify dependency
@@ -35,7 +42,7 @@ type DifyDepValue struct {
 type DifyWorkflow struct {
 	ConversationVariables []interface{} `yaml:"conversation_variables"`
 	EnvironmentVariables  []interface{} `yaml:"environment_variables"`
-	Features              DifyFeatures  `yaml:"features"`
+	Features              interface{}   `yaml:"features"` // DifyFeatures, or the features block of a Dify source
 	Graph                 DifyGraph     `yaml:"graph"`
 }
 
@@ -132,6 +139,9 @@ type DifyEdgeData struct {
 	IterationID   string `yaml:"iteration_id,omitempty"`
 	SourceType    string `yaml:"sourceType"`
 	TargetType    string `yaml:"targetType"`
+
+	// EdgeStyle keeps the look of edges from other platforms for the way back
+	EdgeStyle *models.EdgeStyle `yaml:"edgeStyle,omitempty"`
 }
 
 // DifyNode represents a Dify node - field order consistent with official example
@@ -152,6 +162,9 @@ type DifyNode struct {
 	Draggable  *bool  `yaml:"draggable,omitempty"`  // Use pointer type, only shown when explicitly set
 	Selectable *bool  `yaml:"selectable,omitempty"` // Use pointer type, only shown when explicitly set
 	ZIndex     int    `yaml:"zIndex,omitempty"`
+
+	// Source keys no field above models, emitted again on Dify round trips
+	Unknown map[string]interface{} `yaml:",inline"`
 }
 
 // DifyPosition represents Dify position information
@@ -200,6 +213,38 @@ type DifyNodeData struct {
 	Model          map[string]interface{}   `yaml:"model,omitempty"`
 	PromptTemplate []map[string]interface{} `yaml:"prompt_template,omitempty"`
 	Vision         map[string]interface{}   `yaml:"vision,omitempty"`
+	Memory         *DifyMemory              `yaml:"memory,omitempty"` // Chatflow LLM and classifier nodes
+
+	// Structured output of LLM nodes
+	StructuredOutputEnabled bool                  `yaml:"structured_output_enabled,omitempty"`
+	StructuredOutput        *DifyStructuredOutput `yaml:"structured_output,omitempty"`
+
+	// Error handling of LLM and code nodes
+	ErrorStrategy string             `yaml:"error_strategy,omitempty"`
+	DefaultValue  []DifyDefaultValue `yaml:"default_value,omitempty"`
+	RetryConfig   *DifyRetryConfig   `yaml:"retry_config,omitempty"`
+
+	// Answer node specific fields
+	Answer string `yaml:"answer,omitempty"`
+
+	// Agent node specific fields
+	AgentParameters           map[string]interface{} `yaml:"agent_parameters,omitempty"`
+	AgentStrategyLabel        string                 `yaml:"agent_strategy_label,omitempty"`
+	AgentStrategyName         string                 `yaml:"agent_strategy_name,omitempty"`
+	AgentStrategyProviderName string                 `yaml:"agent_strategy_provider_name,omitempty"`
+
+	// Document extractor node specific fields
+	VariableSelector []string `yaml:"variable_selector,omitempty"`
+	IsArrayFile      bool     `yaml:"is_array_file,omitempty"`
+
+	// List operator node specific fields
+	Variable    []string               `yaml:"variable,omitempty"`
+	VarType     string                 `yaml:"var_type,omitempty"`
+	ItemVarType string                 `yaml:"item_var_type,omitempty"`
+	FilterBy    map[string]interface{} `yaml:"filter_by,omitempty"`
+	ExtractBy   map[string]interface{} `yaml:"extract_by,omitempty"`
+	OrderBy     map[string]interface{} `yaml:"order_by,omitempty"`
+	Limit       map[string]interface{} `yaml:"limit,omitempty"`
 
 	// Other fields
 	Dependencies string                 `yaml:"dependencies,omitempty"`
@@ -214,6 +259,9 @@ type DifyNodeData struct {
 	Instructions          string                   `yaml:"instructions,omitempty"` // Keep empty string, consistent with Dify instance
 	QueryVariableSelector []string                 `yaml:"query_variable_selector,omitempty"`
 	Topics                []string                 `yaml:"topics,omitempty"`
+
+	// Source keys no field above models, emitted again on Dify round trips
+	Unknown map[string]interface{} `yaml:",inline"`
 }
 
 // DifyVariable represents Dify variable definition - field order consistent with official example
@@ -224,6 +272,15 @@ type DifyVariable struct {
 	Required  bool     `yaml:"required"`
 	Type      string   `yaml:"type"`
 	Variable  string   `yaml:"variable"`
+
+	Default     interface{} `yaml:"default,omitempty"`
+	Placeholder string      `yaml:"placeholder,omitempty"`
+	Hint        string      `yaml:"hint,omitempty"`
+
+	// File input specific fields
+	AllowedFileTypes         []string `yaml:"allowed_file_types,omitempty"`
+	AllowedFileExtensions    []string `yaml:"allowed_file_extensions,omitempty"`
+	AllowedFileUploadMethods []string `yaml:"allowed_file_upload_methods,omitempty"`
 }
 
 // DifyOutput represents Dify output definition
@@ -233,3 +290,41 @@ type DifyOutput struct {
 	ValueType     string   `yaml:"value_type"`
 	Type          string   `yaml:"type,omitempty"`
 }
+
+// DifyDefaultValue is an output of a node using the default-value error strategy.
+type DifyDefaultValue struct {
+	Key   string      `yaml:"key"`
+	Type  string      `yaml:"type"`
+	Value interface{} `yaml:"value"`
+}
+
+// DifyStructuredOutput defines the JSON schema LLM answers follow.
+type DifyStructuredOutput struct {
+	Schema map[string]interface{} `yaml:"schema"`
+}
+
+// DifyMemory defines the conversation history a chatflow model sees.
+type DifyMemory struct {
+	QueryPromptTemplate string           `yaml:"query_prompt_template"`
+	RolePrefix          *DifyRolePrefix  `yaml:"role_prefix,omitempty"`
+	Window              DifyMemoryWindow `yaml:"window"`
+}
+
+// DifyRolePrefix defines the turn prefixes of completion models.
+type DifyRolePrefix struct {
+	User      string `yaml:"user"`
+	Assistant string `yaml:"assistant"`
+}
+
+// DifyMemoryWindow limits the history to the last size rounds when enabled.
+type DifyMemoryWindow struct {
+	Enabled bool `yaml:"enabled"`
+	Size    int  `yaml:"size"`
+}
+
+// DifyRetryConfig defines the retries of a failing node.
+type DifyRetryConfig struct {
+	Enabled       bool `yaml:"enabled"`
+	MaxRetries    int  `yaml:"max_retries"`
+	RetryInterval int  `yaml:"retry_interval"` // Milliseconds
+}
@@ -0,0 +1,22 @@
+package generator
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// restoreUnknownFields emits the node keys the Dify parser kept without modeling them on the
+// nodes generated from those source nodes, so Dify round trips keep them.
+func (g *difyGeneration) restoreUnknownFields(unifiedDSL *models.UnifiedDSL, difyDSL *DifyRootStructure) {
+	sourceNodes := common.NodesByTargetID(unifiedDSL.Workflow.Nodes, g.nodeIDMapping)
+
+	nodes := difyDSL.Workflow.Graph.Nodes
+	for i := range nodes {
+		sourceNode := sourceNodes[nodes[i].ID]
+		if sourceNode == nil {
+			continue
+		}
+		nodes[i].Unknown, nodes[i].Data.Unknown = common.RestoreUnknownFields(
+			sourceNode.PlatformConfig.Dify, nodes[i].Data.Type, DifyNode{}, DifyNodeData{})
+	}
+}
@@ -3,6 +3,7 @@ package generator
 import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 	"strings"
 )
 
@@ -281,27 +282,11 @@ func (c *VariableSelectorConverter) convertInstructionVariableReferences(instruc
 	nodeID := selector[0]
 	variableName := selector[1]
 
-	// Use generic variable reference replacement logic
-	// Find and replace variable references in format {{#nodeId.variableName#}} with mapped node IDs
-	updatedInstruction := instruction
-
-	// Simple replacement: find reference patterns containing variable name
-	if strings.Contains(instruction, fmt.Sprintf(".%s#}}", variableName)) {
-		// Find patterns like {{#anyNodeId.variableName#}}
-		parts := strings.Split(instruction, "{{#")
-		if len(parts) > 1 {
-			for i := 1; i < len(parts); i++ {
-				if strings.Contains(parts[i], fmt.Sprintf(".%s#}}", variableName)) {
-					// Found a matching variable reference, replace the entire reference
-					endIndex := strings.Index(parts[i], "#}}")
-					if endIndex != -1 {
-						parts[i] = fmt.Sprintf("%s.%s#}}", nodeID, variableName) + parts[i][endIndex+3:]
-					}
-				}
-			}
-			updatedInstruction = strings.Join(parts, "{{#")
+	// Point references to the variable at the mapped node
+	return common.RewritePromptTemplate(instruction, func(p common.PromptPlaceholder) string {
+		if !p.IsReference() || p.Output != variableName {
+			return p.Text
 		}
-	}
-
-	return updatedInstruction
+		return common.InlinePromptReference(nodeID, variableName)
+	})
 }
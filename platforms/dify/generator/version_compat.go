@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultDifyTargetVersion is the Dify release line targeted when no version is specified
+const DefaultDifyTargetVersion = "1.x"
+
+// openAICompatibleDependency is the marketplace plugin required by plugin-based Dify releases
+const openAICompatibleDependency = "langgenius/openai_api_compatible:0.0.19@219552f62b54919d6fd317c737956d3b2cc97719b85f0179bb995e5a512b7ebb"
+
+// DifyVersionProfile describes DSL differences of a Dify release line.
+// All per-version field adjustments are driven by these profiles.
+type DifyVersionProfile struct {
+	Name             string // Release line name, e.g. "0.15.x"
+	MinMajor         int    // Lowest Dify major version covered by this profile
+	MinMinor         int    // Lowest Dify minor version covered by this profile
+	DSLVersion       string // Value of the root "version" field
+	PluginProviders  bool   // Model providers use plugin identifiers (author/plugin/provider)
+	PluginDependency bool   // Root "dependencies" lists marketplace plugins
+	LoopFlags        bool   // Nodes carry the isInLoop flag
+}
+
+// difyVersionProfiles lists supported release lines in ascending order.
+var difyVersionProfiles = []DifyVersionProfile{
+	{
+		Name:       "0.6.x",
+		MinMajor:   0,
+		MinMinor:   6,
+		DSLVersion: "0.1.0",
+	},
+	{
+		Name:       "0.15.x",
+		MinMajor:   0,
+		MinMinor:   15,
+		DSLVersion: "0.1.5",
+	},
+	{
+		Name:             "1.x",
+		MinMajor:         1,
+		MinMinor:         0,
+		DSLVersion:       "0.3.1",
+		PluginProviders:  true,
+		PluginDependency: true,
+		LoopFlags:        true,
+	},
+}
+
+// SupportedDifyTargetVersions returns the names of supported Dify release lines.
+func SupportedDifyTargetVersions() []string {
+	names := make([]string, 0, len(difyVersionProfiles))
+	for _, profile := range difyVersionProfiles {
+		names = append(names, profile.Name)
+	}
+	return names
+}
+
+// ResolveDifyVersionProfile finds the profile matching a Dify version such as "0.6", "0.15.3" or "1.x".
+// An empty version or "latest" resolves to the newest profile.
+func ResolveDifyVersionProfile(version string) (*DifyVersionProfile, error) {
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+	if version == "" || version == "latest" {
+		profile := difyVersionProfiles[len(difyVersionProfiles)-1]
+		return &profile, nil
+	}
+
+	major, minor, err := parseDifyVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(difyVersionProfiles) - 1; i >= 0; i-- {
+		profile := difyVersionProfiles[i]
+		if major > profile.MinMajor || (major == profile.MinMajor && minor >= profile.MinMinor) {
+			return &profile, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported Dify target version %q (supported: %s)",
+		version, strings.Join(SupportedDifyTargetVersions(), ", "))
+}
+
+// parseDifyVersion extracts major and minor numbers; a missing or wildcard minor counts as 0.
+func parseDifyVersion(version string) (int, int, error) {
+	parts := strings.Split(version, ".")
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Dify target version %q", version)
+	}
+
+	minor := 0
+	if len(parts) > 1 && parts[1] != "x" && parts[1] != "*" {
+		if minor, err = strconv.Atoi(parts[1]); err != nil {
+			return 0, 0, fmt.Errorf("invalid Dify target version %q", version)
+		}
+	}
+
+	return major, minor, nil
+}
+
+// applyVersionCompatibility adjusts the generated DSL to the given release line.
+func applyVersionCompatibility(difyDSL *DifyRootStructure, profile *DifyVersionProfile) {
+	difyDSL.Version = profile.DSLVersion
+
+	if profile.PluginDependency {
+		difyDSL.Dependencies = []DifyDependency{
+			{
+				CurrentIdentifier: nil,
+				Type:              "marketplace",
+				Value: DifyDepValue{
+					MarketplacePluginUniqueIdentifier: openAICompatibleDependency,
+				},
+			},
+		}
+	} else {
+		difyDSL.Dependencies = nil
+	}
+
+	for i := range difyDSL.Workflow.Graph.Nodes {
+		data := &difyDSL.Workflow.Graph.Nodes[i].Data
+		if !profile.PluginProviders {
+			data.Model = downgradePluginProvider(data.Model)
+		}
+		if !profile.LoopFlags {
+			data.IsInLoop = nil
+		}
+	}
+}
+
+// downgradePluginProvider converts "author/plugin/provider" identifiers to the bare provider name.
+func downgradePluginProvider(model map[string]interface{}) map[string]interface{} {
+	if model == nil {
+		return nil
+	}
+
+	if provider, ok := model["provider"].(string); ok && strings.Count(provider, "/") == 2 {
+		model["provider"] = provider[strings.LastIndex(provider, "/")+1:]
+	}
+
+	return model
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// AgentNodeParser parses Dify agent nodes.
+type AgentNodeParser struct {
+	*BaseNodeParser
+	llmParser *LLMNodeParser
+}
+
+func NewAgentNodeParser(vrs *models.VariableReferenceSystem) NodeParser {
+	return &AgentNodeParser{
+		BaseNodeParser: NewBaseNodeParser("agent", vrs),
+		llmParser:      NewLLMNodeParser(vrs).(*LLMNodeParser),
+	}
+}
+
+// GetSupportedType returns supported node type.
+func (p *AgentNodeParser) GetSupportedType() string {
+	return "agent"
+}
+
+// ParseNode parses Dify agent node. The model, instruction and query are parsed as an LLM node
+// would parse them, so agents degrade to LLM nodes with the same prompts and inputs.
+func (p *AgentNodeParser) ParseNode(difyNode DifyNode) (*models.Node, error) {
+	if err := p.ValidateNode(difyNode); err != nil {
+		return nil, err
+	}
+
+	llmNode, err := p.llmParser.ParseNode(p.asLLMNode(difyNode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse agent model and prompts: %w", err)
+	}
+	llmConfig := llmNode.Config.(models.LLMConfig)
+
+	node := p.parseBasicNodeInfo(difyNode)
+	node.Type = models.NodeTypeAgent
+	node.Inputs = llmNode.Inputs
+	node.Outputs = llmNode.Outputs // Agent "text" output is referenced like the LLM one
+	node.Config = models.AgentConfig{
+		Model:         llmConfig.Model,
+		Parameters:    llmConfig.Parameters,
+		Prompt:        llmConfig.Prompt,
+		Strategy:      p.parseStrategy(difyNode.Data.AgentStrategyName),
+		Tools:         p.parseTools(difyNode.Data.AgentParameters),
+		MaxIterations: p.getIntParameter(difyNode.Data.AgentParameters, "maximum_iterations"),
+	}
+
+	// Keep the parameters not modeled, such as tool settings and disabled tools, for Dify round trips
+	if len(difyNode.Data.AgentParameters) > 0 {
+		node.PlatformConfig.Dify["agent_parameters"] = difyNode.Data.AgentParameters
+	}
+	if difyNode.Data.AgentStrategyProviderName != "" {
+		node.PlatformConfig.Dify["agent_strategy_provider_name"] = difyNode.Data.AgentStrategyProviderName
+	}
+
+	return node, nil
+}
+
+// asLLMNode builds the LLM node equivalent of an agent: instruction as system prompt, query as user prompt
+func (p *AgentNodeParser) asLLMNode(difyNode DifyNode) DifyNode {
+	llmNode := difyNode
+	llmNode.Data.Type = "llm"
+	llmNode.Data.Model = p.parseModel(difyNode.Data.AgentParameters)
+	llmNode.Data.PromptTemplate = nil
+
+	if instruction := p.getStringParameter(difyNode.Data.AgentParameters, "instruction"); instruction != "" {
+		llmNode.Data.PromptTemplate = append(llmNode.Data.PromptTemplate, DifyPrompt{Role: "system", Text: instruction})
+	}
+	if query := p.getStringParameter(difyNode.Data.AgentParameters, "query"); query != "" {
+		llmNode.Data.PromptTemplate = append(llmNode.Data.PromptTemplate, DifyPrompt{Role: "user", Text: query})
+	}
+
+	return llmNode
+}
+
+// parseModel reads the model selector parameter
+func (p *AgentNodeParser) parseModel(parameters map[string]interface{}) *DifyModel {
+	model := &DifyModel{Mode: "chat"}
+
+	value, ok := p.parameterValue(parameters, "model").(map[string]interface{})
+	if !ok {
+		return model
+	}
+
+	model.Provider, _ = value["provider"].(string)
+	model.Name, _ = value["model"].(string)
+	if mode, ok := value["mode"].(string); ok && mode != "" {
+		model.Mode = mode
+	}
+	model.CompletionParams, _ = value["completion_params"].(map[string]interface{})
+	return model
+}
+
+// parseTools reads the enabled tools of the tools parameter
+func (p *AgentNodeParser) parseTools(parameters map[string]interface{}) []models.AgentTool {
+	entries, _ := p.parameterValue(parameters, "tools").([]interface{})
+
+	tools := make([]models.AgentTool, 0, len(entries))
+	for _, entry := range entries {
+		toolMap, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if enabled, ok := toolMap["enabled"].(bool); ok && !enabled {
+			continue
+		}
+
+		tool := models.AgentTool{Type: models.AgentToolBuiltin}
+		if toolType, ok := toolMap["type"].(string); ok && toolType != "" {
+			tool.Type = toolType
+		}
+		tool.Provider, _ = toolMap["provider_name"].(string)
+		tool.Name, _ = toolMap["tool_name"].(string)
+		if extra, ok := toolMap["extra"].(map[string]interface{}); ok {
+			tool.Description, _ = extra["description"].(string)
+		}
+		tools = append(tools, tool)
+	}
+
+	return tools
+}
+
+// parseStrategy normalizes the Dify agent strategy name
+func (p *AgentNodeParser) parseStrategy(strategyName string) string {
+	if strings.EqualFold(strategyName, models.AgentStrategyReAct) {
+		return models.AgentStrategyReAct
+	}
+	return models.AgentStrategyFunctionCalling
+}
+
+// parameterValue returns the value of an agent parameter ({type: constant, value: ...})
+func (p *AgentNodeParser) parameterValue(parameters map[string]interface{}, name string) interface{} {
+	parameter, ok := parameters[name].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return parameter["value"]
+}
+
+// getStringParameter returns a string agent parameter
+func (p *AgentNodeParser) getStringParameter(parameters map[string]interface{}, name string) string {
+	value, _ := p.parameterValue(parameters, name).(string)
+	return value
+}
+
+// getIntParameter returns an integer agent parameter
+func (p *AgentNodeParser) getIntParameter(parameters map[string]interface{}, name string) int {
+	switch value := p.parameterValue(parameters, name).(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	}
+	return 0
+}
+
+// ValidateNode validates Dify agent node.
+func (p *AgentNodeParser) ValidateNode(difyNode DifyNode) error {
+	if err := p.BaseNodeParser.ValidateNode(difyNode); err != nil {
+		return err
+	}
+
+	if difyNode.Data.Type != "agent" {
+		return fmt.Errorf("node type must be 'agent', got '%s'", difyNode.Data.Type)
+	}
+
+	return nil
+}
@@ -240,22 +240,12 @@ func (p *BaseNodeParser) convertOutputType(outputInfo map[interface{}]interface{
 	return models.DataTypeString
 }
 
-// convertDataType converts data types.
+// convertDataType converts data types by the central type mapping.
 func (p *BaseNodeParser) convertDataType(difyType string) models.UnifiedDataType {
-	switch difyType {
-	case "text-input", "string":
-		return models.DataTypeString
-	case "number":
-		return models.DataTypeNumber
-	case "boolean":
-		return models.DataTypeBoolean
-	case "array[string]":
-		return models.DataTypeArrayString
-	case "array[object]":
-		return models.DataTypeArrayObject
-	case "object":
-		return models.DataTypeObject
-	default:
-		return models.DataTypeString // Default to string type
+	mapping := models.GetDefaultDataTypeMapping()
+	unifiedType := mapping.FromDifyType(difyType)
+	if _, exists := mapping.DifyMapping[unifiedType]; exists {
+		return unifiedType
 	}
+	return models.DataTypeString // Default to string type, also for input controls like text-input
 }
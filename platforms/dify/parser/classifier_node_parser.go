@@ -2,7 +2,9 @@ package parser
 
 import (
 	"fmt"
+
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 )
 
 // ClassifierNodeParser parses Dify classifier nodes.
@@ -66,6 +68,7 @@ func (p *ClassifierNodeParser) parseClassifierConfig(data DifyNodeData) (models.
 			return config, fmt.Errorf("failed to parse model configuration: %w", err)
 		}
 		config.Model = modelConfig
+		config.Parameters = parseModelParameters(data.Model)
 	}
 
 	// Parse classification classes, filter out "other classification"
@@ -75,12 +78,15 @@ func (p *ClassifierNodeParser) parseClassifierConfig(data DifyNodeData) (models.
 			continue
 		}
 
+		// The topic text is what the model matches; labelled classes name it separately
 		class := models.ClassifierClass{
-			ID:   difyClass.ID,
-			Name: difyClass.Name,
-			// Dify Class structure doesn't have description field, use name as description
+			ID:          difyClass.ID,
+			Name:        difyClass.Name,
 			Description: difyClass.Name,
 		}
+		if difyClass.Label != "" {
+			class.Name = difyClass.Label
+		}
 		config.Classes = append(config.Classes, class)
 	}
 
@@ -97,6 +103,8 @@ func (p *ClassifierNodeParser) parseClassifierConfig(data DifyNodeData) (models.
 	} else if data.Instructions != "" {
 		config.Instructions = data.Instructions
 	}
+	config.Instructions, config.Examples = common.SplitClassifierExamples(config.Instructions, config.Classes)
+	config.Memory = parseMemoryConfig(data.Memory)
 
 	return config, nil
 }
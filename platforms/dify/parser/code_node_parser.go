@@ -135,37 +135,13 @@ func (p *CodeNodeParser) parseOutputEntry(outputName string, value interface{})
 
 // convertVariableType converts variable type.
 func (p *CodeNodeParser) convertVariableType(varType string) models.UnifiedDataType {
-	switch varType {
-	case "string", "text-input":
-		return models.DataTypeString
-	case "number":
-		return models.DataTypeNumber
-	case "boolean":
-		return models.DataTypeBoolean
-	default:
-		return models.DataTypeString
-	}
+	return p.convertDataType(varType)
 }
 
 // convertOutputType converts output type.
 func (p *CodeNodeParser) convertOutputType(outputInfo map[string]interface{}) models.UnifiedDataType {
 	if typeStr, ok := outputInfo["type"].(string); ok {
-		switch typeStr {
-		case "string":
-			return models.DataTypeString
-		case "number":
-			return models.DataTypeNumber
-		case "boolean":
-			return models.DataTypeBoolean
-		case "array[string]":
-			return models.DataTypeArrayString
-		case "array[object]":
-			return models.DataTypeArrayObject
-		case "object":
-			return models.DataTypeObject
-		default:
-			return models.DataTypeString
-		}
+		return p.convertDataType(typeStr)
 	}
 	return models.DataTypeString
 }
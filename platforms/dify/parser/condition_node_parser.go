@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 )
 
 // ConditionNodeParser parses Dify conditional branch nodes.
@@ -51,7 +52,7 @@ func (p *ConditionNodeParser) parseCases(cases []DifyCase) []models.ConditionCas
 	for _, difyCase := range cases {
 		conditionCase := models.ConditionCase{
 			CaseID:          difyCase.CaseID,
-			LogicalOperator: p.mapLogicalOperator(difyCase.LogicalOperator),
+			LogicalOperator: models.NormalizeLogicalOperator(difyCase.LogicalOperator),
 			Conditions:      p.parseConditions(difyCase.Conditions),
 		}
 
@@ -123,70 +124,12 @@ func (p *ConditionNodeParser) generateInputName(nodeID, outputName string) strin
 	return fmt.Sprintf("condition_input_%s_%s", nodeID, outputName)
 }
 
-// mapLogicalOperator maps logical operators.
-func (p *ConditionNodeParser) mapLogicalOperator(operator string) string {
-	switch operator {
-	case "and", "AND":
-		return "and"
-	case "or", "OR":
-		return "or"
-	default:
-		return "and" // Default to and
-	}
-}
-
-// mapComparisonOperator maps comparison operators.
+// mapComparisonOperator maps Dify comparison operators to unified operators.
 func (p *ConditionNodeParser) mapComparisonOperator(operator string) string {
-	return p.getOperatorMapping(operator)
-}
-
-// getOperatorMapping returns the mapped operator using lookup table
-func (p *ConditionNodeParser) getOperatorMapping(operator string) string {
-	mapping := p.getOperatorMappingTable()
-	if mapped, exists := mapping[operator]; exists {
-		return mapped
-	}
-	return operator // Keep as is if not found
-}
-
-// getOperatorMappingTable returns the operator mapping table
-func (p *ConditionNodeParser) getOperatorMappingTable() map[string]string {
-	return map[string]string{
-		"contains":              "contains",
-		"not contains":          "not_contains",
-		"start with":            "starts_with",
-		"end with":              "ends_with",
-		"is":                    "equals",
-		"is not":                "not_equals",
-		"empty":                 "is_empty",
-		"not empty":             "is_not_empty",
-		"greater than":          "gt",
-		"gt":                    "gt",
-		"greater than or equal": "gte",
-		"gte":                   "gte",
-		"less than":             "lt",
-		"lt":                    "lt",
-		"less than or equal":    "lte",
-		"lte":                   "lte",
-	}
+	return common.NormalizeConditionOperator(operator)
 }
 
 // mapVarType maps variable types.
 func (p *ConditionNodeParser) mapVarType(varType string) models.UnifiedDataType {
-	switch varType {
-	case "string":
-		return models.DataTypeString
-	case "number":
-		return models.DataTypeNumber
-	case "boolean":
-		return models.DataTypeBoolean
-	case "array", "array[string]":
-		return models.DataTypeArrayString
-	case "array[object]":
-		return models.DataTypeArrayObject
-	case "object":
-		return models.DataTypeObject
-	default:
-		return models.DataTypeString // Default to string
-	}
+	return p.convertDataType(varType)
 }
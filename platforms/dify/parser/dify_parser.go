@@ -1,18 +1,19 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 	"github.com/iflytek/agentbridge/core/interfaces"
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
-	"strings"
 	"time"
-
-	"gopkg.in/yaml.v3"
 )
 
-// Compile-time interface check
-var _ interfaces.DSLParser = (*DifyParser)(nil)
+// Compile-time interface checks
+var (
+	_ interfaces.DSLParser     = (*DifyParser)(nil)
+	_ interfaces.ContextParser = (*DifyParser)(nil)
+)
 
 // DifyParser parses Dify DSL to unified format.
 type DifyParser struct {
@@ -34,6 +35,13 @@ func NewDifyParser() *DifyParser {
 
 // Parse parses Dify DSL to unified format.
 func (p *DifyParser) Parse(data []byte) (*models.UnifiedDSL, error) {
+	return p.ParseWithContext(context.Background(), data)
+}
+
+// ParseWithContext parses Dify DSL to unified format, returning the context error if ctx is done first.
+func (p *DifyParser) ParseWithContext(ctx context.Context, data []byte) (*models.UnifiedDSL, error) {
+	p.ResetIssues()
+
 	// Validate input data
 	if err := p.Validate(data); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -41,9 +49,12 @@ func (p *DifyParser) Parse(data []byte) (*models.UnifiedDSL, error) {
 
 	// Parse YAML
 	var difyDSL DifyDSL
-	if err := yaml.Unmarshal(data, &difyDSL); err != nil {
+	if err := unmarshalDifyDSL(data, &difyDSL); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Build unified DSL
 	unifiedDSL := &models.UnifiedDSL{
@@ -53,6 +64,9 @@ func (p *DifyParser) Parse(data []byte) (*models.UnifiedDSL, error) {
 			Description: difyDSL.App.Description,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
+			Tags:        difyDSL.App.Tags,
+			Author:      difyDSL.App.Author,
+			Version:     difyDSL.App.Version,
 		},
 		PlatformMetadata: models.PlatformMetadata{
 			Dify: &models.DifyMetadata{
@@ -75,9 +89,12 @@ func (p *DifyParser) Parse(data []byte) (*models.UnifiedDSL, error) {
 	if err := p.parseUIConfig(&difyDSL, unifiedDSL); err != nil {
 		return nil, fmt.Errorf("failed to parse UI config: %w", err)
 	}
+	if err := p.parseFeatures(data, &difyDSL, unifiedDSL); err != nil {
+		return nil, fmt.Errorf("failed to parse features: %w", err)
+	}
 
 	// Parse nodes
-	if err := p.parseNodes(difyDSL.Workflow.Graph.Nodes, unifiedDSL); err != nil {
+	if err := p.parseNodes(ctx, difyDSL.Workflow.Graph.Nodes, unifiedDSL); err != nil {
 		return nil, fmt.Errorf("failed to parse nodes: %w", err)
 	}
 
@@ -94,13 +111,16 @@ func (p *DifyParser) Parse(data []byte) (*models.UnifiedDSL, error) {
 	// Print conversion summary after parsing is complete
 	p.printConversionSummary(unifiedDSL)
 
+	if err := p.FinishParse(unifiedDSL); err != nil {
+		return nil, err
+	}
 	return unifiedDSL, nil
 }
 
 // Validate validates Dify DSL format.
 func (p *DifyParser) Validate(data []byte) error {
 	var difyDSL DifyDSL
-	if err := yaml.Unmarshal(data, &difyDSL); err != nil {
+	if err := unmarshalDifyDSL(data, &difyDSL); err != nil {
 		return fmt.Errorf("invalid YAML format: %w", err)
 	}
 
@@ -116,6 +136,12 @@ func (p *DifyParser) Validate(data []byte) error {
 	return nil
 }
 
+// unmarshalDifyDSL decodes the Dify workflow document of data, which may be one of several
+// concatenated YAML documents and may use anchors and aliases.
+func unmarshalDifyDSL(data []byte, difyDSL *DifyDSL) error {
+	return common.UnmarshalYAMLDocument(data, difyDSL, "Dify", "app", "workflow")
+}
+
 // parseUIConfig parses UI configuration.
 func (p *DifyParser) parseUIConfig(difyDSL *DifyDSL, unifiedDSL *models.UnifiedDSL) error {
 	features := difyDSL.Workflow.Features
@@ -127,10 +153,8 @@ func (p *DifyParser) parseUIConfig(difyDSL *DifyDSL, unifiedDSL *models.UnifiedD
 		uiConfig.OpeningStatement = features.OpeningStatement
 	}
 
-	// Parse suggested questions
-	if len(features.SuggestedQuestions) > 0 {
-		uiConfig.SuggestedQuestions = features.SuggestedQuestions
-	}
+	// Parse suggested questions, skipping the blank entries the editor leaves behind
+	uiConfig.SuggestedQuestions = common.NonBlankQuestions(features.SuggestedQuestions, 0)
 
 	// Parse icons
 	uiConfig.Icon = difyDSL.App.Icon
@@ -145,13 +169,70 @@ func (p *DifyParser) parseUIConfig(difyDSL *DifyDSL, unifiedDSL *models.UnifiedD
 	return nil
 }
 
+// parseFeatures keeps the features block as exported in the Dify metadata, so Dify targets get
+// it back, and maps the features other platforms share to the unified workflow features.
+func (p *DifyParser) parseFeatures(data []byte, difyDSL *DifyDSL, unifiedDSL *models.UnifiedDSL) error {
+	var raw struct {
+		Workflow struct {
+			Features map[string]interface{} `yaml:"features"`
+		} `yaml:"workflow"`
+	}
+	if err := common.UnmarshalYAMLDocument(data, &raw, "Dify", "app", "workflow"); err != nil {
+		return err
+	}
+	if len(raw.Workflow.Features) == 0 {
+		return nil
+	}
+	unifiedDSL.PlatformMetadata.Dify.Features = raw.Workflow.Features
+
+	features := difyDSL.Workflow.Features
+	unified := &models.Features{
+		OpeningStatement:   features.OpeningStatement,
+		SuggestedQuestions: common.NonBlankQuestions(features.SuggestedQuestions, 0),
+	}
+	if upload := features.FileUpload; upload != nil {
+		unified.FileUpload = &models.FileUploadConfig{
+			Enabled:               upload.Enabled,
+			AllowedFileTypes:      upload.AllowedFileTypes,
+			AllowedFileExtensions: upload.AllowedFileExtensions,
+			AllowedUploadMethods:  upload.AllowedFileUploadMethods,
+			NumberLimits:          upload.NumberLimits,
+		}
+		if limits := upload.FileUploadConfig; limits != nil {
+			unified.FileUpload.FileSizeLimit = limits.FileSizeLimit
+			unified.FileUpload.ImageFileSizeLimit = limits.ImageFileSizeLimit
+			unified.FileUpload.AudioFileSizeLimit = limits.AudioFileSizeLimit
+			unified.FileUpload.VideoFileSizeLimit = limits.VideoFileSizeLimit
+			unified.FileUpload.WorkflowFileUploadLimit = limits.WorkflowFileUploadLimit
+			unified.FileUpload.BatchCountLimit = limits.BatchCountLimit
+		}
+	}
+	if features.SpeechToText != nil {
+		unified.SpeechToText = &models.SpeechConfig{Enabled: features.SpeechToText.Enabled}
+	}
+	if tts := features.TextToSpeech; tts != nil {
+		unified.TextToSpeech = &models.SpeechConfig{Enabled: tts.Enabled, Language: tts.Language, Voice: tts.Voice}
+	}
+	unifiedDSL.Workflow.Features = unified
+	return nil
+}
+
 // parseNodes parses nodes.
-func (p *DifyParser) parseNodes(difyNodes []DifyNode, unifiedDSL *models.UnifiedDSL) error {
+func (p *DifyParser) parseNodes(ctx context.Context, difyNodes []DifyNode, unifiedDSL *models.UnifiedDSL) error {
 	// Track skipped node IDs for edge filtering
 	skippedNodeIDs := make(map[string]bool)
 	p.skippedNodeIDs = skippedNodeIDs // Ensure the parser instance has access to skipped node IDs
-
+	nodeTypes := make(map[string]string, len(difyNodes))
 	for _, difyNode := range difyNodes {
+		nodeTypes[difyNode.ID] = difyNode.Data.Type
+	}
+
+	for i, difyNode := range difyNodes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		models.ReportNodeProgress(ctx, models.ProgressStageParse, i, len(difyNodes), difyNode.ID, difyNode.Data.Title)
+
 		// Skip nodes with title "other classification" as they are handled through default intent mechanism in iFlytek
 		if difyNode.Data.Title == "其他分类" {
 			skippedNodeIDs[difyNode.ID] = true
@@ -166,7 +247,7 @@ func (p *DifyParser) parseNodes(difyNodes []DifyNode, unifiedDSL *models.Unified
 
 		if !supported {
 			// Convert unsupported nodes to code node placeholders
-			fmt.Printf("⚠️  Converting unsupported node type '%s' (ID: %s) to code node placeholder\n",
+			p.ReportIssue("Converting unsupported node type '%s' (ID: %s) to code node placeholder",
 				difyNode.Data.Type, difyNode.ID)
 
 			node, err = p.convertUnsupportedNodeToCodeNode(difyNode)
@@ -180,12 +261,19 @@ func (p *DifyParser) parseNodes(difyNodes []DifyNode, unifiedDSL *models.Unified
 			// Re-parse with end node parser context
 			endParser := NewEndNodeParser(p.variableRefSystem).(*EndNodeParser)
 			endParser.SetSkippedNodeIDs(skippedNodeIDs)
+			endParser.SetNodeTypes(nodeTypes)
 			node, err = endParser.ParseNode(difyNode)
 			if err != nil {
 				return fmt.Errorf("failed to parse end node %s: %w", difyNode.ID, err)
 			}
 		}
 
+		// Keep keys the parser does not model for Dify round trips
+		if supported {
+			common.StoreUnknownFields(&node.PlatformConfig.Dify, difyNode.Data.Type, difyNode.Unknown, difyNode.Data.Unknown)
+			node.ErrorHandling = parseErrorHandling(difyNode.Data, node)
+		}
+
 		// Check if the node itself has iteration information and mark it
 		p.markNodeIterationFromNodeData(node, difyNode.Data)
 		p.markNodeIterationFromParentID(node, difyNode.ParentID)
@@ -236,6 +324,7 @@ func (p *DifyParser) parseEdges(difyEdges []DifyEdge, unifiedDSL *models.Unified
 				},
 			}
 		}
+		edge.Style = difyEdge.Data.EdgeStyle
 
 		unifiedDSL.Workflow.Edges = append(unifiedDSL.Workflow.Edges, edge)
 	}
@@ -316,6 +405,8 @@ func (p *DifyParser) setNodeIterationConfig(node *models.Node, iterationID strin
 		p.setConditionNodeIteration(node, iterationID)
 	case models.NodeTypeClassifier:
 		p.setClassifierNodeIteration(node, iterationID)
+	case models.NodeTypeAgent:
+		p.setAgentNodeIteration(node, iterationID)
 	}
 }
 
@@ -389,6 +480,15 @@ func (p *DifyParser) setClassifierNodeIteration(node *models.Node, iterationID s
 	}
 }
 
+// setAgentNodeIteration sets iteration config for agent node
+func (p *DifyParser) setAgentNodeIteration(node *models.Node, iterationID string) {
+	if agentConfig, ok := node.Config.(models.AgentConfig); ok {
+		agentConfig.IsInIteration = true
+		agentConfig.IterationID = iterationID
+		node.Config = agentConfig
+	}
+}
+
 // convertUnsupportedNodeToCodeNode converts unsupported nodes to code node placeholders
 func (p *DifyParser) convertUnsupportedNodeToCodeNode(difyNode DifyNode) (*models.Node, error) {
 	// Get node title for type description
@@ -401,13 +501,13 @@ func (p *DifyParser) convertUnsupportedNodeToCodeNode(difyNode DifyNode) (*model
 	modifiedNode := difyNode
 	modifiedNode.Data.Type = "code"
 
-	modifiedNode.Data.Title = fmt.Sprintf("暂不兼容的节点-%s（请根据需求手动实现）", nodeTitle)
+	modifiedNode.Data.Title = common.FormatUnsupportedNodeTitle(nodeTitle)
 
 	// Set default code configuration
 	modifiedNode.Data.Code = fmt.Sprintf(`# 抱歉！当前兼容性工具不支持转换此类节点: %s
 
-# 请根据业务需求手动补充实现逻辑
-`, difyNode.Data.Type)
+# %s
+`, difyNode.Data.Type, common.UnsupportedNodeAction)
 	modifiedNode.Data.CodeLanguage = "python3"
 
 	// Create default output if none exist to maintain connections
@@ -421,7 +521,17 @@ func (p *DifyParser) convertUnsupportedNodeToCodeNode(difyNode DifyNode) (*model
 	}
 
 	// Parse using code node parser
-	return codeParser.ParseNode(modifiedNode)
+	node, err := codeParser.ParseNode(modifiedNode)
+	if err != nil {
+		return nil, err
+	}
+	common.MarkPlaceholder(node, models.PlaceholderMarker{
+		SourcePlatform: models.PlatformDify,
+		OriginalType:   difyNode.Data.Type,
+		OriginalID:     difyNode.ID,
+		Action:         common.UnsupportedNodeAction,
+	})
+	return node, nil
 }
 
 // extractNodeTitle extracts node title
@@ -444,13 +554,16 @@ func (p *DifyParser) nodeExistsInDSL(nodeID string, unifiedDSL *models.UnifiedDS
 
 // printConversionSummary prints detailed conversion statistics
 func (p *DifyParser) printConversionSummary(unifiedDSL *models.UnifiedDSL) {
+	if p.Quiet() {
+		return
+	}
 	totalNodes := len(unifiedDSL.Workflow.Nodes)
 	fmt.Printf("✅ Conversion Summary: All %d nodes processed successfully\n", totalNodes)
 
-	// Count nodes converted to code placeholders by checking titles
+	// Count nodes converted to code placeholders by their marker
 	convertedCount := 0
 	for _, node := range unifiedDSL.Workflow.Nodes {
-		if strings.Contains(node.Title, "暂不兼容的节点-") {
+		if common.IsPlaceholderNode(node) {
 			convertedCount++
 		}
 	}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// DocumentExtractorNodeParser parses Dify document extractor nodes.
+type DocumentExtractorNodeParser struct {
+	*BaseNodeParser
+}
+
+func NewDocumentExtractorNodeParser(vrs *models.VariableReferenceSystem) NodeParser {
+	return &DocumentExtractorNodeParser{
+		BaseNodeParser: NewBaseNodeParser("document-extractor", vrs),
+	}
+}
+
+// GetSupportedType returns supported node type.
+func (p *DocumentExtractorNodeParser) GetSupportedType() string {
+	return "document-extractor"
+}
+
+// ParseNode parses Dify document extractor node. The file selector becomes the "file" input
+// and the extracted text the "text" output, a list of texts for file lists.
+func (p *DocumentExtractorNodeParser) ParseNode(difyNode DifyNode) (*models.Node, error) {
+	if err := p.ValidateNode(difyNode); err != nil {
+		return nil, err
+	}
+
+	data := difyNode.Data
+	node := p.parseBasicNodeInfo(difyNode)
+	node.Type = models.NodeTypeDocumentExtractor
+	node.Config = models.DocumentExtractorConfig{
+		IsArrayFile:   data.IsArrayFile,
+		IsInIteration: data.IsInIteration,
+		IterationID:   data.IterationID,
+	}
+
+	fileType, textType := models.DataTypeString, models.DataTypeString
+	if data.IsArrayFile {
+		fileType, textType = models.DataTypeArrayString, models.DataTypeArrayString
+	}
+
+	if len(data.VariableSelector) >= 2 {
+		node.Inputs = append(node.Inputs, models.Input{
+			Name:     "file",
+			Type:     fileType,
+			Required: true,
+			Reference: &models.VariableReference{
+				Type:       models.ReferenceTypeNodeOutput,
+				NodeID:     data.VariableSelector[0],
+				OutputName: data.VariableSelector[1],
+				DataType:   fileType,
+			},
+		})
+	}
+	node.Outputs = append(node.Outputs, models.Output{Name: "text", Type: textType})
+
+	return node, nil
+}
+
+// ValidateNode validates Dify document extractor node.
+func (p *DocumentExtractorNodeParser) ValidateNode(difyNode DifyNode) error {
+	if err := p.BaseNodeParser.ValidateNode(difyNode); err != nil {
+		return err
+	}
+
+	if difyNode.Data.Type != "document-extractor" {
+		return fmt.Errorf("node type must be 'document-extractor', got '%s'", difyNode.Data.Type)
+	}
+
+	return nil
+}
@@ -8,7 +8,8 @@ import (
 // EndNodeParser parses Dify end nodes.
 type EndNodeParser struct {
 	*BaseNodeParser
-	skippedNodeIDs map[string]bool // Track skipped node IDs
+	skippedNodeIDs map[string]bool   // Track skipped node IDs
+	nodeTypes      map[string]string // Dify node types by ID
 }
 
 func NewEndNodeParser(vrs *models.VariableReferenceSystem) NodeParser {
@@ -23,6 +24,12 @@ func (p *EndNodeParser) SetSkippedNodeIDs(skippedNodeIDs map[string]bool) {
 	p.skippedNodeIDs = skippedNodeIDs
 }
 
+// SetNodeTypes sets the Dify types of the workflow nodes by ID, used to map the output names
+// the end node references.
+func (p *EndNodeParser) SetNodeTypes(nodeTypes map[string]string) {
+	p.nodeTypes = nodeTypes
+}
+
 // GetSupportedType returns supported node type.
 func (p *EndNodeParser) GetSupportedType() string {
 	return "end"
@@ -41,8 +48,8 @@ func (p *EndNodeParser) ParseNode(difyNode DifyNode) (*models.Node, error) {
 
 	// Parse end node specific configuration
 	config := models.EndConfig{
-		OutputMode:   "variables", // Dify defaults to variables mode
-		StreamOutput: false,       // Default to non-streaming output
+		OutputMode:   models.EndOutputModeVariables, // Dify end nodes always return variables
+		StreamOutput: false,                         // Default to non-streaming output
 	}
 
 	// Parse inputs (end node outputs are actually inputs)
@@ -67,7 +74,7 @@ func (p *EndNodeParser) ParseNode(difyNode DifyNode) (*models.Node, error) {
 				input.Reference = &models.VariableReference{
 					Type:       models.ReferenceTypeNodeOutput,
 					NodeID:     sourceNodeID,
-					OutputName: p.mapOutputName(output.ValueSelector[1]),
+					OutputName: p.mapOutputName(sourceNodeID, output.ValueSelector[1]),
 					DataType:   p.convertDataType(output.ValueType),
 				}
 			}
@@ -76,9 +83,6 @@ func (p *EndNodeParser) ParseNode(difyNode DifyNode) (*models.Node, error) {
 		}
 	}
 
-	// Check if there is template output mode configuration
-	// Dify end nodes may configure templates through other means, use default values for now
-
 	node.Config = config
 
 	return node, nil
@@ -208,11 +212,15 @@ func (p *EndNodeParser) setOutputValueSelector(output *DifyOutput, outputMap map
 }
 
 // mapOutputName maps output names, handling platform differences.
-func (p *EndNodeParser) mapOutputName(outputName string) string {
+func (p *EndNodeParser) mapOutputName(sourceNodeID, outputName string) string {
 	// Dify -> Unified DSL output name mapping
 	switch outputName {
 	case "text":
-		// Dify LLM node output is usually called "text", but in iFlytek SparkAgent it's called "output"
+		// Dify LLM node output is usually called "text", but in iFlytek SparkAgent it's called "output".
+		// Document extractors and other nodes keep their "text" output.
+		if nodeType, known := p.nodeTypes[sourceNodeID]; known && nodeType != "llm" && nodeType != "agent" {
+			return outputName
+		}
 		return "output"
 	default:
 		return outputName
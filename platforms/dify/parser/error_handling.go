@@ -0,0 +1,32 @@
+package parser
+
+import "github.com/iflytek/agentbridge/internal/models"
+
+// parseErrorHandling reads the retries and error strategy of a node, nil when it has neither.
+func parseErrorHandling(data DifyNodeData, node *models.Node) *models.ErrorHandling {
+	handling := &models.ErrorHandling{Strategy: models.ErrorStrategyFail}
+	switch data.ErrorStrategy {
+	case models.ErrorStrategyDefaultValue:
+		handling.Strategy = models.ErrorStrategyDefaultValue
+		handling.DefaultValues = make(map[string]interface{}, len(data.DefaultValue))
+		for _, value := range data.DefaultValue {
+			key := value.Key
+			// The Dify "text" output of LLM nodes is the unified "output"
+			if node.Type == models.NodeTypeLLM && key == "text" && len(node.Outputs) > 0 {
+				key = node.Outputs[0].Name
+			}
+			handling.DefaultValues[key] = value.Value
+		}
+	case models.ErrorStrategyFailBranch:
+		handling.Strategy = models.ErrorStrategyFailBranch
+	}
+	if retry := data.RetryConfig; retry != nil && retry.Enabled && retry.MaxRetries > 0 {
+		handling.MaxRetries = retry.MaxRetries
+		handling.RetryInterval = retry.RetryInterval
+	}
+
+	if handling.Strategy == models.ErrorStrategyFail && handling.MaxRetries == 0 {
+		return nil
+	}
+	return handling
+}
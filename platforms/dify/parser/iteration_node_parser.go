@@ -86,7 +86,7 @@ func (p *IterationNodeParser) parseIterationConfig(data DifyNodeData) (models.It
 	// Parse execution configuration
 	config.Execution.IsParallel = data.IsParallel
 	config.Execution.ParallelNums = data.ParallelNums
-	config.Execution.ErrorHandleMode = data.ErrorHandleMode
+	config.Execution.ErrorHandleMode = models.NormalizeErrorHandleMode(data.ErrorHandleMode)
 
 	// Parse start node ID
 	config.SubWorkflow.StartNodeID = data.StartNodeID
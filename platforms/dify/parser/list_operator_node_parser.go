@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// ListOperatorNodeParser parses Dify list operator nodes.
+type ListOperatorNodeParser struct {
+	*BaseNodeParser
+}
+
+func NewListOperatorNodeParser(vrs *models.VariableReferenceSystem) NodeParser {
+	return &ListOperatorNodeParser{
+		BaseNodeParser: NewBaseNodeParser("list-operator", vrs),
+	}
+}
+
+// GetSupportedType returns supported node type.
+func (p *ListOperatorNodeParser) GetSupportedType() string {
+	return "list-operator"
+}
+
+// ParseNode parses Dify list operator node. The list selector becomes the "variable" input;
+// disabled operations are dropped.
+func (p *ListOperatorNodeParser) ParseNode(difyNode DifyNode) (*models.Node, error) {
+	if err := p.ValidateNode(difyNode); err != nil {
+		return nil, err
+	}
+
+	data := difyNode.Data
+	itemType := p.convertItemType(data.ItemVarType)
+	listType := p.convertListType(data.VarType, itemType)
+
+	node := p.parseBasicNodeInfo(difyNode)
+	node.Type = models.NodeTypeListOperation
+	node.Config = p.parseListOperationConfig(data, itemType)
+
+	if len(data.Variable) >= 2 {
+		node.Inputs = append(node.Inputs, models.Input{
+			Name:     "variable",
+			Type:     listType,
+			Required: true,
+			Reference: &models.VariableReference{
+				Type:       models.ReferenceTypeNodeOutput,
+				NodeID:     data.Variable[0],
+				OutputName: data.Variable[1],
+				DataType:   listType,
+			},
+		})
+	}
+	node.Outputs = []models.Output{
+		{Name: "result", Type: listType},
+		{Name: "first_record", Type: itemType},
+		{Name: "last_record", Type: itemType},
+	}
+
+	return node, nil
+}
+
+// parseListOperationConfig maps the enabled filter, extract, order and limit settings
+func (p *ListOperatorNodeParser) parseListOperationConfig(data DifyNodeData, itemType models.UnifiedDataType) models.ListOperationConfig {
+	config := models.ListOperationConfig{
+		ItemType:      itemType,
+		IsInIteration: data.IsInIteration,
+		IterationID:   data.IterationID,
+	}
+
+	if data.FilterBy != nil && data.FilterBy.Enabled {
+		for _, condition := range data.FilterBy.Conditions {
+			config.Filters = append(config.Filters, models.ListFilterCondition{
+				Key:      condition.Key,
+				Operator: p.convertOperator(condition.ComparisonOperator),
+				Value:    condition.Value,
+			})
+		}
+	}
+
+	// Serials referencing variables ({{#node.var#}}) cannot be kept and extract nothing
+	if data.ExtractBy != nil && data.ExtractBy.Enabled {
+		if serial, err := strconv.Atoi(strings.TrimSpace(data.ExtractBy.Serial)); err == nil && serial > 0 {
+			config.ExtractIndex = serial
+		}
+	}
+
+	if data.OrderBy != nil && data.OrderBy.Enabled {
+		config.SortBy = &models.ListSort{
+			Key:       data.OrderBy.Key,
+			Ascending: data.OrderBy.Value != "desc",
+		}
+	}
+
+	if data.Limit != nil && data.Limit.Enabled {
+		config.Limit = data.Limit.Size
+	}
+
+	return config
+}
+
+// convertOperator maps Dify list filter operators to the condition node vocabulary
+func (p *ListOperatorNodeParser) convertOperator(operator string) string {
+	mapping := map[string]string{
+		"contains":     "contains",
+		"not contains": "not_contains",
+		"start with":   "starts_with",
+		"end with":     "ends_with",
+		"is":           "equals",
+		"is not":       "not_equals",
+		"empty":        "is_empty",
+		"not empty":    "is_not_empty",
+		"in":           "in",
+		"not in":       "not_in",
+		"=":            "equals",
+		"≠":            "not_equals",
+		">":            "gt",
+		"<":            "lt",
+		"≥":            "gte",
+		"≤":            "lte",
+	}
+	if mapped, exists := mapping[operator]; exists {
+		return mapped
+	}
+	return operator
+}
+
+// convertItemType maps the Dify list item type
+func (p *ListOperatorNodeParser) convertItemType(itemType string) models.UnifiedDataType {
+	return p.convertDataType(itemType)
+}
+
+// convertListType maps the Dify list type, falling back to a list of the item type
+func (p *ListOperatorNodeParser) convertListType(listType string, itemType models.UnifiedDataType) models.UnifiedDataType {
+	if unifiedType := p.convertDataType(listType); models.IsArrayType(unifiedType) {
+		return unifiedType
+	}
+	return models.ArrayTypeOf(itemType)
+}
+
+// ValidateNode validates Dify list operator node.
+func (p *ListOperatorNodeParser) ValidateNode(difyNode DifyNode) error {
+	if err := p.BaseNodeParser.ValidateNode(difyNode); err != nil {
+		return err
+	}
+
+	if difyNode.Data.Type != "list-operator" {
+		return fmt.Errorf("node type must be 'list-operator', got '%s'", difyNode.Data.Type)
+	}
+
+	return nil
+}
@@ -1,9 +1,10 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
-	"regexp"
+	"github.com/iflytek/agentbridge/platforms/common"
 )
 
 // LLMNodeParser parses Dify LLM nodes.
@@ -46,12 +47,14 @@ func (p *LLMNodeParser) buildLLMConfig(difyNode DifyNode) models.LLMConfig {
 
 	if difyNode.Data.Model != nil {
 		config.Model = p.parseModelConfig(difyNode.Data.Model)
-		config.Parameters = p.parseModelParameters(difyNode.Data.Model)
+		config.Parameters = parseModelParameters(difyNode.Data.Model)
 	}
 
 	config.Prompt = p.parsePromptConfig(difyNode.Data.PromptTemplate)
 	config.Context = p.parseContextConfig(difyNode.Data.Context)
 	config.Vision = p.parseVisionConfig(difyNode.Data.Vision)
+	config.Memory = parseMemoryConfig(difyNode.Data.Memory)
+	config.Parameters.ResponseFormat, config.OutputSchema = p.parseResponseFormat(difyNode.Data)
 
 	return config
 }
@@ -65,17 +68,22 @@ func (p *LLMNodeParser) parseModelConfig(model *DifyModel) models.ModelConfig {
 	}
 }
 
-// parseModelParameters parses model parameters
-func (p *LLMNodeParser) parseModelParameters(model *DifyModel) models.ModelParameters {
+// parseModelParameters parses the completion params of LLM and classifier nodes
+func parseModelParameters(model *DifyModel) models.ModelParameters {
 	if model.CompletionParams == nil {
 		return models.ModelParameters{}
 	}
 
 	return models.ModelParameters{
-		Temperature: p.getFloatFromParams(model.CompletionParams, "temperature", 0.7),
-		MaxTokens:   p.getIntFromParams(model.CompletionParams, "max_tokens", 8192),
-		TopK:        p.getIntFromParams(model.CompletionParams, "top_k", 4),
-		TopP:        p.getFloatFromParams(model.CompletionParams, "top_p", 0.7),
+		Temperature: getFloatFromParams(model.CompletionParams, "temperature", 0.7),
+		MaxTokens:   getIntFromParams(model.CompletionParams, "max_tokens", 8192),
+		TopK:        getIntFromParams(model.CompletionParams, "top_k", 4),
+		TopP:        getFloatFromParams(model.CompletionParams, "top_p", 0.7),
+
+		PresencePenalty:  getFloatFromParams(model.CompletionParams, "presence_penalty", 0),
+		FrequencyPenalty: getFloatFromParams(model.CompletionParams, "frequency_penalty", 0),
+		Stop:             getStringsFromParams(model.CompletionParams, "stop"),
+		Seed:             getIntFromParams(model.CompletionParams, "seed", 0),
 	}
 }
 
@@ -139,9 +147,43 @@ func (p *LLMNodeParser) parseVisionConfig(vision *DifyVision) *models.VisionConf
 		return nil
 	}
 
-	return &models.VisionConfig{
+	config := &models.VisionConfig{
 		Enabled: vision.Enabled,
 	}
+	if vision.Configs != nil {
+		config.Detail = vision.Configs.Detail
+		config.VariableSelector = vision.Configs.VariableSelector
+	}
+	return config
+}
+
+// parseResponseFormat reads JSON answers from the structured output schema, or else from the
+// response_format model parameter with its optional json_schema.
+func (p *LLMNodeParser) parseResponseFormat(data DifyNodeData) (int, map[string]interface{}) {
+	if data.StructuredOutputEnabled && data.StructuredOutput != nil && len(data.StructuredOutput.Schema) > 0 {
+		return models.ResponseFormatJSON, data.StructuredOutput.Schema
+	}
+	if data.Model == nil {
+		return models.ResponseFormatText, nil
+	}
+
+	switch data.Model.CompletionParams["response_format"] {
+	case "json_object":
+		return models.ResponseFormatJSON, nil
+	case "json_schema":
+		var schema map[string]interface{}
+		if text, ok := data.Model.CompletionParams["json_schema"].(string); ok {
+			if err := json.Unmarshal([]byte(text), &schema); err != nil {
+				schema = nil
+			}
+		}
+		// OpenAI wraps the schema with its name: {"name": ..., "schema": {...}}
+		if inner, ok := schema["schema"].(map[string]interface{}); ok {
+			schema = inner
+		}
+		return models.ResponseFormatJSON, schema
+	}
+	return models.ResponseFormatText, nil
 }
 
 // createLLMOutputs creates default LLM outputs
@@ -208,15 +250,11 @@ func (p *LLMNodeParser) extractInputsFromTemplate(templates []DifyPrompt, contex
 		return []models.Input{p.buildInput(nodeID, field)}
 	}
 
-	// 2) Fallback: find the first template reference {{#nodeId.field#}}
-	re := regexp.MustCompile(`\{\{#([^#.}]+)\.([^#}]+)#\}\}`)
+	// 2) Fallback: use the first template reference {{#nodeId.field#}}
 	for _, t := range templates {
-		if matches := re.FindAllStringSubmatch(t.Text, -1); len(matches) > 0 {
-			// Use the first captured pair as the primary input
-			if len(matches[0]) >= 3 {
-				nodeID := matches[0][1]
-				field := matches[0][2]
-				return []models.Input{p.buildInput(nodeID, field)}
+		for _, placeholder := range common.ParsePromptPlaceholders(t.Text) {
+			if placeholder.IsReference() && placeholder.Output != "" {
+				return []models.Input{p.buildInput(placeholder.NodeID, placeholder.Output)}
 			}
 		}
 	}
@@ -240,7 +278,7 @@ func (p *LLMNodeParser) buildInput(nodeID, field string) models.Input {
 }
 
 // getFloatFromParams gets float value from parameter map.
-func (p *LLMNodeParser) getFloatFromParams(params map[string]interface{}, key string, defaultValue float64) float64 {
+func getFloatFromParams(params map[string]interface{}, key string, defaultValue float64) float64 {
 	if value, exists := params[key]; exists {
 		if floatVal, ok := value.(float64); ok {
 			return floatVal
@@ -253,7 +291,7 @@ func (p *LLMNodeParser) getFloatFromParams(params map[string]interface{}, key st
 }
 
 // getIntFromParams gets integer value from parameter map.
-func (p *LLMNodeParser) getIntFromParams(params map[string]interface{}, key string, defaultValue int) int {
+func getIntFromParams(params map[string]interface{}, key string, defaultValue int) int {
 	if value, exists := params[key]; exists {
 		if intVal, ok := value.(int); ok {
 			return intVal
@@ -264,3 +302,15 @@ func (p *LLMNodeParser) getIntFromParams(params map[string]interface{}, key stri
 	}
 	return defaultValue
 }
+
+// getStringsFromParams gets a string list from parameter map, skipping empty entries.
+func getStringsFromParams(params map[string]interface{}, key string) []string {
+	values, _ := params[key].([]interface{})
+	var stringValues []string
+	for _, value := range values {
+		if stringValue, ok := value.(string); ok && stringValue != "" {
+			stringValues = append(stringValues, stringValue)
+		}
+	}
+	return stringValues
+}
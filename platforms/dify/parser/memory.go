@@ -0,0 +1,23 @@
+package parser
+
+import "github.com/iflytek/agentbridge/internal/models"
+
+// parseMemoryConfig maps the memory of chatflow LLM and classifier nodes. A memory block means
+// the model sees the history; the window bounds it only when enabled.
+func parseMemoryConfig(memory *DifyMemory) *models.MemoryConfig {
+	if memory == nil {
+		return nil
+	}
+
+	config := &models.MemoryConfig{
+		Enabled:     true,
+		QueryPrompt: memory.QueryPromptTemplate,
+	}
+	if memory.Window.Enabled {
+		config.Window = memory.Window.Size
+	}
+	if prefix := memory.RolePrefix; prefix != nil && (prefix.User != "" || prefix.Assistant != "") {
+		config.RolePrefix = &models.MemoryRolePrefix{User: prefix.User, Assistant: prefix.Assistant}
+	}
+	return config
+}
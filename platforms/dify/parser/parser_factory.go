@@ -39,6 +39,15 @@ func NewParserFactory() *ParserFactory {
 	factory.Register("iteration", func(vrs *models.VariableReferenceSystem) NodeParser {
 		return NewIterationNodeParser(vrs)
 	})
+	factory.Register("agent", func(vrs *models.VariableReferenceSystem) NodeParser {
+		return NewAgentNodeParser(vrs)
+	})
+	factory.Register("document-extractor", func(vrs *models.VariableReferenceSystem) NodeParser {
+		return NewDocumentExtractorNodeParser(vrs)
+	})
+	factory.Register("list-operator", func(vrs *models.VariableReferenceSystem) NodeParser {
+		return NewListOperatorNodeParser(vrs)
+	})
 
 	// Register iteration-related node parsers
 	factory.Register("iteration-start", func(vrs *models.VariableReferenceSystem) NodeParser {
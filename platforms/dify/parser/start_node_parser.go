@@ -4,6 +4,14 @@ import (
 	"github.com/iflytek/agentbridge/internal/models"
 )
 
+// difyInputControls maps Dify start variable types to unified input controls
+var difyInputControls = map[string]string{
+	"text-input": models.VariableControlText,
+	"paragraph":  models.VariableControlParagraph,
+	"select":     models.VariableControlSelect,
+	"number":     models.VariableControlNumber,
+}
+
 // StartNodeParser parses start nodes.
 type StartNodeParser struct {
 	*BaseNodeParser
@@ -59,18 +67,48 @@ func (p *StartNodeParser) parseStartConfiguration(difyVariables []DifyVariable)
 // convertDifyVariableToStartVariable converts a Dify variable to start variable
 func (p *StartNodeParser) convertDifyVariableToStartVariable(difyVar DifyVariable) models.Variable {
 	startVar := models.Variable{
-		Name:     difyVar.Variable,
-		Label:    difyVar.Label,
-		Type:     string(p.convertDataType(difyVar.Type)),
-		Required: difyVar.Required,
+		Name:        difyVar.Variable,
+		Label:       difyVar.Label,
+		Type:        string(p.convertDataType(difyVar.Type)),
+		Required:    difyVar.Required,
+		Description: difyVar.Hint,
+		Placeholder: difyVar.Placeholder,
+	}
+	// Exports write an empty default for inputs without one
+	if difyVar.Default != nil && difyVar.Default != "" {
+		startVar.Default = difyVar.Default
 	}
 
+	if difyVar.Type == "file" || difyVar.Type == "file-list" {
+		p.addFileInput(&startVar, difyVar)
+		return startVar
+	}
+
+	startVar.Control = difyInputControls[difyVar.Type]
+
 	// Add constraints if needed
 	p.addVariableConstraints(&startVar, difyVar)
 
 	return startVar
 }
 
+// addFileInput records the accepted files of a file or file-list variable.
+// For file lists max_length is the number of files, not a text length.
+func (p *StartNodeParser) addFileInput(startVar *models.Variable, difyVar DifyVariable) {
+	startVar.Type = string(models.DataTypeString)
+	startVar.File = &models.FileInput{
+		AllowedTypes:      difyVar.AllowedFileTypes,
+		AllowedExtensions: difyVar.AllowedFileExtensions,
+		UploadMethods:     difyVar.AllowedFileUploadMethods,
+	}
+
+	if difyVar.Type == "file-list" {
+		startVar.Type = string(models.DataTypeArrayString)
+		startVar.File.Multiple = true
+		startVar.File.MaxCount = difyVar.MaxLength
+	}
+}
+
 // addVariableConstraints adds constraints to a variable
 func (p *StartNodeParser) addVariableConstraints(startVar *models.Variable, difyVar DifyVariable) {
 	// Parse constraint conditions
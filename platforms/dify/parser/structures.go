@@ -1,5 +1,7 @@
 package parser
 
+import "github.com/iflytek/agentbridge/internal/models"
+
 // DifyDSL represents the root structure of Dify DSL.
 type DifyDSL struct {
 	App      DifyApp      `yaml:"app" json:"app"`
@@ -16,6 +18,11 @@ type DifyApp struct {
 	IconBackground      string `yaml:"icon_background" json:"icon_background"`
 	Mode                string `yaml:"mode" json:"mode"`
 	UseIconAsAnswerIcon bool   `yaml:"use_icon_as_answer_icon" json:"use_icon_as_answer_icon"`
+
+	// Catalog metadata of the app; the top-level version is the DSL version
+	Tags    []string `yaml:"tags" json:"tags"`
+	Author  string   `yaml:"author" json:"author"`
+	Version string   `yaml:"version" json:"version"`
 }
 
 // DifyWorkflow defines workflow structure.
@@ -117,6 +124,9 @@ type DifyEdgeData struct {
 	IterationID   string `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
 	SourceType    string `yaml:"sourceType,omitempty" json:"sourceType,omitempty"`
 	TargetType    string `yaml:"targetType,omitempty" json:"targetType,omitempty"`
+
+	// EdgeStyle is the look of an edge converted from iFlytek; Dify ignores it when drawing
+	EdgeStyle *models.EdgeStyle `yaml:"edgeStyle,omitempty" json:"edgeStyle,omitempty"`
 }
 
 // DifyNode represents a node.
@@ -136,6 +146,8 @@ type DifyNode struct {
 	ParentID         string        `yaml:"parentId,omitempty" json:"parentId,omitempty"`
 	Extent           string        `yaml:"extent,omitempty" json:"extent,omitempty"`
 	Data             DifyNodeData  `yaml:"data" json:"data"`
+
+	Unknown map[string]interface{} `yaml:",inline" json:"-"` // Keys not modeled above, kept for round trips
 }
 
 // DifyPosition contains position coordinates.
@@ -158,6 +170,11 @@ type DifyNodeData struct {
 	PromptTemplate []DifyPrompt `yaml:"prompt_template,omitempty" json:"prompt_template,omitempty"`
 	Context        *DifyContext `yaml:"context,omitempty" json:"context,omitempty"`
 	Vision         *DifyVision  `yaml:"vision,omitempty" json:"vision,omitempty"`
+	Memory         *DifyMemory  `yaml:"memory,omitempty" json:"memory,omitempty"` // Also used by classifier nodes
+
+	// Structured output of LLM nodes
+	StructuredOutputEnabled bool                  `yaml:"structured_output_enabled,omitempty" json:"structured_output_enabled,omitempty"`
+	StructuredOutput        *DifyStructuredOutput `yaml:"structured_output,omitempty" json:"structured_output,omitempty"`
 
 	// Code node specific fields
 	Code         string `yaml:"code,omitempty" json:"code,omitempty"`
@@ -173,6 +190,29 @@ type DifyNodeData struct {
 	QueryVariableSelector []string    `yaml:"query_variable_selector,omitempty" json:"query_variable_selector,omitempty"`
 	Topics                []string    `yaml:"topics,omitempty" json:"topics,omitempty"`
 
+	// Agent node specific fields
+	AgentStrategyProviderName string                 `yaml:"agent_strategy_provider_name,omitempty" json:"agent_strategy_provider_name,omitempty"`
+	AgentStrategyName         string                 `yaml:"agent_strategy_name,omitempty" json:"agent_strategy_name,omitempty"`
+	AgentParameters           map[string]interface{} `yaml:"agent_parameters,omitempty" json:"agent_parameters,omitempty"`
+
+	// Document extractor node specific fields
+	VariableSelector []string `yaml:"variable_selector,omitempty" json:"variable_selector,omitempty"`
+	IsArrayFile      bool     `yaml:"is_array_file,omitempty" json:"is_array_file,omitempty"`
+
+	// List operator node specific fields
+	Variable    []string         `yaml:"variable,omitempty" json:"variable,omitempty"`
+	VarType     string           `yaml:"var_type,omitempty" json:"var_type,omitempty"`
+	ItemVarType string           `yaml:"item_var_type,omitempty" json:"item_var_type,omitempty"`
+	FilterBy    *DifyListFilter  `yaml:"filter_by,omitempty" json:"filter_by,omitempty"`
+	ExtractBy   *DifyListExtract `yaml:"extract_by,omitempty" json:"extract_by,omitempty"`
+	OrderBy     *DifyListOrder   `yaml:"order_by,omitempty" json:"order_by,omitempty"`
+	Limit       *DifyListLimit   `yaml:"limit,omitempty" json:"limit,omitempty"`
+
+	// Error handling of LLM and code nodes
+	ErrorStrategy string             `yaml:"error_strategy,omitempty" json:"error_strategy,omitempty"`
+	DefaultValue  []DifyDefaultValue `yaml:"default_value,omitempty" json:"default_value,omitempty"`
+	RetryConfig   *DifyRetryConfig   `yaml:"retry_config,omitempty" json:"retry_config,omitempty"`
+
 	// Iteration node specific fields
 	ErrorHandleMode   string   `yaml:"error_handle_mode,omitempty" json:"error_handle_mode,omitempty"`
 	IsParallel        bool     `yaml:"is_parallel,omitempty" json:"is_parallel,omitempty"`
@@ -187,6 +227,8 @@ type DifyNodeData struct {
 	IsInIteration bool   `yaml:"isInIteration,omitempty" json:"isInIteration,omitempty"`
 	IsInLoop      bool   `yaml:"isInLoop,omitempty" json:"isInLoop,omitempty"`
 	IterationID   string `yaml:"iteration_id,omitempty" json:"iteration_id,omitempty"`
+
+	Unknown map[string]interface{} `yaml:",inline" json:"-"` // Keys not modeled above, kept for round trips
 }
 
 // DifyVariable defines variable structure.
@@ -199,6 +241,16 @@ type DifyVariable struct {
 	Required      bool     `yaml:"required" json:"required"`
 	MaxLength     int      `yaml:"max_length,omitempty" json:"max_length,omitempty"`
 	Options       []string `yaml:"options,omitempty" json:"options,omitempty"`
+
+	// Input presentation of start variables
+	Default     interface{} `yaml:"default,omitempty" json:"default,omitempty"`
+	Placeholder string      `yaml:"placeholder,omitempty" json:"placeholder,omitempty"`
+	Hint        string      `yaml:"hint,omitempty" json:"hint,omitempty"`
+
+	// File input specific fields
+	AllowedFileTypes         []string `yaml:"allowed_file_types,omitempty" json:"allowed_file_types,omitempty"`
+	AllowedFileExtensions    []string `yaml:"allowed_file_extensions,omitempty" json:"allowed_file_extensions,omitempty"`
+	AllowedFileUploadMethods []string `yaml:"allowed_file_upload_methods,omitempty" json:"allowed_file_upload_methods,omitempty"`
 }
 
 // DifyOutput defines output structure.
@@ -231,7 +283,38 @@ type DifyContext struct {
 
 // DifyVision contains vision configuration.
 type DifyVision struct {
+	Enabled bool               `yaml:"enabled" json:"enabled"`
+	Configs *DifyVisionConfigs `yaml:"configs,omitempty" json:"configs,omitempty"`
+}
+
+// DifyVisionConfigs selects the images of a vision model and their resolution.
+type DifyVisionConfigs struct {
+	Detail           string   `yaml:"detail,omitempty" json:"detail,omitempty"`
+	VariableSelector []string `yaml:"variable_selector,omitempty" json:"variable_selector,omitempty"`
+}
+
+// DifyStructuredOutput contains the JSON schema LLM answers follow.
+type DifyStructuredOutput struct {
+	Schema map[string]interface{} `yaml:"schema,omitempty" json:"schema,omitempty"`
+}
+
+// DifyMemory contains the conversation history settings of chatflow LLM and classifier nodes.
+type DifyMemory struct {
+	QueryPromptTemplate string           `yaml:"query_prompt_template,omitempty" json:"query_prompt_template,omitempty"`
+	RolePrefix          *DifyRolePrefix  `yaml:"role_prefix,omitempty" json:"role_prefix,omitempty"`
+	Window              DifyMemoryWindow `yaml:"window" json:"window"`
+}
+
+// DifyRolePrefix contains the turn prefixes of completion models.
+type DifyRolePrefix struct {
+	User      string `yaml:"user" json:"user"`
+	Assistant string `yaml:"assistant" json:"assistant"`
+}
+
+// DifyMemoryWindow limits the history to the last size rounds when enabled.
+type DifyMemoryWindow struct {
 	Enabled bool `yaml:"enabled" json:"enabled"`
+	Size    int  `yaml:"size,omitempty" json:"size,omitempty"`
 }
 
 // DifyCase represents a conditional branch.
@@ -251,8 +334,55 @@ type DifyCondition struct {
 	VarType            string   `yaml:"varType" json:"varType"`
 }
 
+// DifyListFilter represents list operator filter conditions.
+type DifyListFilter struct {
+	Enabled    bool                  `yaml:"enabled" json:"enabled"`
+	Conditions []DifyListFilterEntry `yaml:"conditions" json:"conditions"`
+}
+
+// DifyListFilterEntry represents one list operator filter condition.
+type DifyListFilterEntry struct {
+	Key                string      `yaml:"key" json:"key"`
+	ComparisonOperator string      `yaml:"comparison_operator" json:"comparison_operator"`
+	Value              interface{} `yaml:"value" json:"value"`
+}
+
+// DifyListExtract represents list operator single item extraction.
+type DifyListExtract struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Serial  string `yaml:"serial" json:"serial"`
+}
+
+// DifyListOrder represents list operator ordering.
+type DifyListOrder struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Key     string `yaml:"key" json:"key"`
+	Value   string `yaml:"value" json:"value"` // asc or desc
+}
+
+// DifyListLimit represents list operator top N slicing.
+type DifyListLimit struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	Size    int  `yaml:"size" json:"size"`
+}
+
 // DifyClass represents a classification category.
 type DifyClass struct {
-	ID   string `yaml:"id" json:"id"`
-	Name string `yaml:"name" json:"name"`
+	ID    string `yaml:"id" json:"id"`
+	Name  string `yaml:"name" json:"name"`                       // Topic text the model reads
+	Label string `yaml:"label,omitempty" json:"label,omitempty"` // Short class name shown on the canvas
+}
+
+// DifyDefaultValue is an output of a node using the default-value error strategy.
+type DifyDefaultValue struct {
+	Key   string      `yaml:"key" json:"key"`
+	Type  string      `yaml:"type" json:"type"`
+	Value interface{} `yaml:"value" json:"value"`
+}
+
+// DifyRetryConfig defines the retries of a failing node.
+type DifyRetryConfig struct {
+	Enabled       bool `yaml:"enabled" json:"enabled"`
+	MaxRetries    int  `yaml:"max_retries" json:"max_retries"`
+	RetryInterval int  `yaml:"retry_interval" json:"retry_interval"` // Milliseconds
 }
@@ -0,0 +1,179 @@
+// Package dslversion negotiates iFlytek SparkAgent DSL versions.
+// Node parameter differences between versions are expressed as adapters registered
+// per node type, so generators and parsers keep emitting and reading a single v1 shape.
+package dslversion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Supported iFlytek SparkAgent DSL versions
+const (
+	V1 = "v1"
+	V2 = "v2"
+
+	// Default is used when neither the user nor the source DSL requests a version
+	Default = V1
+)
+
+// NodeParamAdapter rewrites a node's nodeParam in place.
+type NodeParamAdapter func(nodeParam map[string]interface{})
+
+// Profile describes how a DSL version differs from the v1 baseline.
+type Profile struct {
+	Version string
+
+	// upgraders convert v1 nodeParam to this version, keyed by iFlytek node type
+	upgraders map[string][]NodeParamAdapter
+	// normalizers convert this version's nodeParam back to v1, keyed by iFlytek node type
+	normalizers map[string][]NodeParamAdapter
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]*Profile{}
+)
+
+func init() {
+	Register(newProfile(V1))
+
+	v2 := newProfile(V2)
+	for _, nodeType := range []string{"大模型", "决策"} {
+		v2.RegisterNodeParamAdapter(nodeType, renameChatHistory("chatHistory", "enableChatHistoryV2"),
+			renameChatHistory("enableChatHistoryV2", "chatHistory"))
+	}
+	Register(v2)
+}
+
+func newProfile(version string) *Profile {
+	return &Profile{
+		Version:     version,
+		upgraders:   make(map[string][]NodeParamAdapter),
+		normalizers: make(map[string][]NodeParamAdapter),
+	}
+}
+
+// NewProfile creates an empty profile for a new DSL version.
+func NewProfile(version string) *Profile {
+	return newProfile(normalizeVersion(version))
+}
+
+// Register adds or replaces a version profile.
+func Register(profile *Profile) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles[profile.Version] = profile
+}
+
+// RegisterNodeParamAdapter registers the upgrade (v1 → this version) and normalize
+// (this version → v1) adapters for an iFlytek node type. Either adapter may be nil.
+func (p *Profile) RegisterNodeParamAdapter(nodeType string, upgrade, normalize NodeParamAdapter) {
+	if upgrade != nil {
+		p.upgraders[nodeType] = append(p.upgraders[nodeType], upgrade)
+	}
+	if normalize != nil {
+		p.normalizers[nodeType] = append(p.normalizers[nodeType], normalize)
+	}
+}
+
+// UpgradeNodeParam converts a generated v1 nodeParam to this version.
+func (p *Profile) UpgradeNodeParam(nodeType string, nodeParam map[string]interface{}) {
+	if nodeParam == nil {
+		return
+	}
+	for _, adapter := range p.upgraders[nodeType] {
+		adapter(nodeParam)
+	}
+}
+
+// NormalizeNodeParam converts a parsed nodeParam of this version back to v1.
+func (p *Profile) NormalizeNodeParam(nodeType string, nodeParam map[string]interface{}) {
+	if nodeParam == nil {
+		return
+	}
+	for _, adapter := range p.normalizers[nodeType] {
+		adapter(nodeParam)
+	}
+}
+
+// Lookup returns the profile of a supported version.
+func Lookup(version string) (*Profile, error) {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	if profile, ok := profiles[normalizeVersion(version)]; ok {
+		return profile, nil
+	}
+	return nil, fmt.Errorf("unsupported iFlytek DSL version %q (supported: %s)",
+		version, strings.Join(supportedLocked(), ", "))
+}
+
+// IsSupported reports whether a version has a registered profile.
+func IsSupported(version string) bool {
+	_, err := Lookup(version)
+	return err == nil
+}
+
+// Supported returns all supported versions in ascending order.
+func Supported() []string {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	return supportedLocked()
+}
+
+func supportedLocked() []string {
+	versions := make([]string, 0, len(profiles))
+	for version := range profiles {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Negotiate picks the DSL version to emit. An explicitly requested version must be
+// supported; otherwise the source DSL version is kept when supported, else Default is used.
+func Negotiate(requested, source string) (*Profile, error) {
+	if strings.TrimSpace(requested) != "" {
+		return Lookup(requested)
+	}
+	if profile, err := Lookup(source); err == nil {
+		return profile, nil
+	}
+	return Lookup(Default)
+}
+
+// ForParsing returns the profile used to read a DSL declaring the given version.
+// Unknown or empty versions are read as Default.
+func ForParsing(version string) *Profile {
+	if profile, err := Lookup(version); err == nil {
+		return profile
+	}
+	profile, _ := Lookup(Default)
+	return profile
+}
+
+// normalizeVersion accepts "2", "v2" and "V2" as the same version.
+func normalizeVersion(version string) string {
+	version = strings.ToLower(strings.TrimSpace(version))
+	if version != "" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return version
+}
+
+// renameChatHistory moves the chat history setting between its v1 and v2 keys.
+func renameChatHistory(from, to string) NodeParamAdapter {
+	return func(nodeParam map[string]interface{}) {
+		value, ok := nodeParam[from]
+		if !ok {
+			return
+		}
+		delete(nodeParam, from)
+		if _, exists := nodeParam[to]; !exists {
+			nodeParam[to] = value
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// iFlytek speech synthesis audio encodings (aue) of unified formats
+var ttsFormatEncodings = map[string]string{
+	"mp3": "lame",
+	"pcm": "raw",
+}
+
+// AudioNodeGenerator handles speech synthesis and speech recognition node generation.
+// Inputs and references are generated like code node inputs.
+type AudioNodeGenerator struct {
+	*CodeNodeGenerator
+	nodeType models.NodeType
+}
+
+// NewTextToSpeechNodeGenerator creates a speech synthesis node generator.
+func NewTextToSpeechNodeGenerator() *AudioNodeGenerator {
+	return newAudioNodeGenerator(models.NodeTypeTextToSpeech)
+}
+
+// NewSpeechToTextNodeGenerator creates a speech recognition node generator.
+func NewSpeechToTextNodeGenerator() *AudioNodeGenerator {
+	return newAudioNodeGenerator(models.NodeTypeSpeechToText)
+}
+
+func newAudioNodeGenerator(nodeType models.NodeType) *AudioNodeGenerator {
+	codeGenerator := NewCodeNodeGenerator()
+	codeGenerator.BaseNodeGenerator = NewBaseNodeGenerator(nodeType)
+	return &AudioNodeGenerator{
+		CodeNodeGenerator: codeGenerator,
+		nodeType:          nodeType,
+	}
+}
+
+// GenerateNode generates speech synthesis or speech recognition node
+func (g *AudioNodeGenerator) GenerateNode(node models.Node) (IFlytekNode, error) {
+	if err := g.ValidateNode(node); err != nil {
+		return IFlytekNode{}, err
+	}
+
+	iflytekNode := g.generateBasicNodeInfo(node)
+	iflytekNode.Data.Icon = g.getNodeIcon(g.nodeType)
+	iflytekNode.Data.AllowInputReference = true
+	iflytekNode.Data.AllowOutputReference = true
+
+	iflytekNode.Data.Inputs = g.generateInputsWithMapping(node.Inputs)
+	iflytekNode.Data.Outputs = g.generateOutputs(node.Outputs)
+	iflytekNode.Data.References = g.generateReferences(node.Inputs)
+
+	if config, ok := common.AsTextToSpeechConfig(node.Config); ok && config != nil {
+		iflytekNode.Data.Description = "将文本合成为语音，输出音频地址"
+		iflytekNode.Data.NodeParam = g.generateTextToSpeechParam(*config)
+	} else if config, ok := common.AsSpeechToTextConfig(node.Config); ok && config != nil {
+		iflytekNode.Data.Description = "将语音识别为文本"
+		iflytekNode.Data.NodeParam = g.generateSpeechToTextParam(*config)
+	}
+
+	return iflytekNode, nil
+}
+
+// ValidateNode validates unified DSL audio node
+func (g *AudioNodeGenerator) ValidateNode(node models.Node) error {
+	if node.Type != g.nodeType {
+		return fmt.Errorf("node type must be '%s', got '%s'", g.nodeType, node.Type)
+	}
+
+	switch g.nodeType {
+	case models.NodeTypeTextToSpeech:
+		if cfg, ok := common.AsTextToSpeechConfig(node.Config); !ok || cfg == nil {
+			return fmt.Errorf("text-to-speech node must have TextToSpeechConfig")
+		}
+	case models.NodeTypeSpeechToText:
+		if cfg, ok := common.AsSpeechToTextConfig(node.Config); !ok || cfg == nil {
+			return fmt.Errorf("speech-to-text node must have SpeechToTextConfig")
+		}
+	}
+
+	return nil
+}
+
+// generateTextToSpeechParam generates speech synthesis node parameters
+func (g *AudioNodeGenerator) generateTextToSpeechParam(config models.TextToSpeechConfig) map[string]interface{} {
+	voice := config.Voice
+	if voice == "" {
+		voice = "x4_yezi"
+	}
+	encoding := "lame"
+	if config.Format != "" {
+		encoding = config.Format
+		if mapped, exists := ttsFormatEncodings[config.Format]; exists {
+			encoding = mapped
+		}
+	}
+
+	return map[string]interface{}{
+		"uid":    g.credentials.UID,
+		"appId":  g.credentials.AppID,
+		"vcn":    voice,
+		"speed":  defaultAudioLevel(config.Speed),
+		"volume": defaultAudioLevel(config.Volume),
+		"pitch":  defaultAudioLevel(config.Pitch),
+		"aue":    encoding,
+	}
+}
+
+// generateSpeechToTextParam generates speech recognition node parameters
+func (g *AudioNodeGenerator) generateSpeechToTextParam(config models.SpeechToTextConfig) map[string]interface{} {
+	language := config.Language
+	if language == "" {
+		language = "zh_cn"
+	}
+	accent := config.Accent
+	if accent == "" && language == "zh_cn" {
+		accent = "mandarin"
+	}
+	format := config.Format
+	if format == "" {
+		format = "mp3"
+	}
+
+	return map[string]interface{}{
+		"uid":      g.credentials.UID,
+		"appId":    g.credentials.AppID,
+		"language": language,
+		"accent":   accent,
+		"format":   format,
+	}
+}
+
+// defaultAudioLevel returns the speed/volume/pitch level, 50 (normal) when unset
+func defaultAudioLevel(level int) int {
+	if level <= 0 {
+		return 50
+	}
+	return level
+}
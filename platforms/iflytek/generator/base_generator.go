@@ -8,15 +8,22 @@ import (
 
 // BaseNodeGenerator provides base node generation functionality for iFlytek SparkAgent
 type BaseNodeGenerator struct {
-	nodeType models.NodeType
+	nodeType    models.NodeType
+	credentials SparkCredentials // Spark appId/uid written into node parameters
 }
 
 func NewBaseNodeGenerator(nodeType models.NodeType) *BaseNodeGenerator {
 	return &BaseNodeGenerator{
-		nodeType: nodeType,
+		nodeType:    nodeType,
+		credentials: SparkCredentials{AppID: DefaultSparkAppID, UID: DefaultSparkUID},
 	}
 }
 
+// SetCredentials sets the Spark appId/uid written into generated node parameters
+func (g *BaseNodeGenerator) SetCredentials(credentials SparkCredentials) {
+	g.credentials = credentials
+}
+
 // GetSupportedType returns the supported node type
 func (g *BaseNodeGenerator) GetSupportedType() models.NodeType {
 	return g.nodeType
@@ -129,35 +136,9 @@ func (g *BaseNodeGenerator) getLabelFromTitleMapping(nodeID string, nodeTitleMap
 	return ""
 }
 
-// getLabelFromNodeType gets label based on node type prefix
+// getLabelFromNodeType gets the default label of the node kind
 func (g *BaseNodeGenerator) getLabelFromNodeType(nodeID string) string {
-	prefixMappings := g.getNodeTypePrefixMappings()
-
-	for prefix, label := range prefixMappings {
-		if g.hasPrefix(nodeID, prefix) {
-			return label
-		}
-	}
-
-	return ""
-}
-
-// getNodeTypePrefixMappings returns node type prefix to label mappings
-func (g *BaseNodeGenerator) getNodeTypePrefixMappings() map[string]string {
-	return map[string]string{
-		"node-start::":      "开始",
-		"node-end::":        "结束",
-		"spark-llm::":       "大模型",
-		"ifly-code::":       "代码",
-		"if-else::":         "分支器",
-		"decision-making::": "决策",
-		"iteration::":       "迭代",
-	}
-}
-
-// hasPrefix checks if nodeID has the specified prefix
-func (g *BaseNodeGenerator) hasPrefix(nodeID, prefix string) bool {
-	return len(nodeID) >= len(prefix) && nodeID[:len(prefix)] == prefix
+	return NodeKindOf(nodeID).label()
 }
 
 // generateOutputs generates node outputs
@@ -198,6 +179,12 @@ func (g *BaseNodeGenerator) convertNodeType(nodeType models.NodeType) string {
 		return "决策"
 	case models.NodeTypeIteration:
 		return "迭代"
+	case models.NodeTypeHumanInput:
+		return "问答"
+	case models.NodeTypeTextToSpeech:
+		return "语音合成"
+	case models.NodeTypeSpeechToText:
+		return "语音识别"
 	default:
 		return string(nodeType)
 	}
@@ -220,6 +207,12 @@ func (g *BaseNodeGenerator) getNodeTypeDisplayName(nodeType models.NodeType) str
 		return "决策"
 	case models.NodeTypeIteration:
 		return "迭代"
+	case models.NodeTypeHumanInput:
+		return "问答"
+	case models.NodeTypeTextToSpeech:
+		return "语音合成"
+	case models.NodeTypeSpeechToText:
+		return "语音识别"
 	default:
 		return string(nodeType)
 	}
@@ -232,35 +225,19 @@ func (g *BaseNodeGenerator) getNodeCategory(nodeType models.NodeType) string {
 		return "基础节点"
 	case models.NodeTypeCondition, models.NodeTypeClassifier:
 		return "分支器"
-	case models.NodeTypeCode:
+	case models.NodeTypeCode, models.NodeTypeTextToSpeech, models.NodeTypeSpeechToText:
 		return "工具"
 	default:
 		return "基础节点"
 	}
 }
 
-// convertDataType converts data type
+// convertDataType converts data type by the central type mapping, unknown types to string
 func (g *BaseNodeGenerator) convertDataType(dataType models.UnifiedDataType) string {
-	switch dataType {
-	case models.DataTypeString:
-		return "string"
-	case models.DataTypeInteger:
-		return "integer"
-	case models.DataTypeFloat:
-		return "number"
-	case models.DataTypeNumber:
-		return "number"
-	case models.DataTypeBoolean:
-		return "boolean"
-	case models.DataTypeArrayString:
-		return "array-string"
-	case models.DataTypeArrayObject:
-		return "array-object"
-	case models.DataTypeObject:
-		return "object"
-	default:
-		return "string"
+	if iflytekType, exists := models.GetDefaultDataTypeMapping().IFlytekMapping[dataType]; exists {
+		return iflytekType
 	}
+	return "string"
 }
 
 // generateInputID generates input ID
@@ -280,32 +257,12 @@ func (g *BaseNodeGenerator) generateRefID() string {
 
 // generateIFlytekNodeID generates iFlytek SparkAgent compliant node ID
 func (g *BaseNodeGenerator) generateIFlytekNodeID(nodeType models.NodeType) string {
-	uuid := generateRealUUID()
-
-	switch nodeType {
-	case models.NodeTypeStart:
-		return "node-start::" + uuid
-	case models.NodeTypeEnd:
-		return "node-end::" + uuid
-	case models.NodeTypeLLM:
-		return "spark-llm::" + uuid
-	case models.NodeTypeCode:
-		return "ifly-code::" + uuid
-	case models.NodeTypeCondition:
-		return "if-else::" + uuid
-	case models.NodeTypeClassifier:
-		return "decision-making::" + uuid
-	case models.NodeTypeIteration:
-		return "iteration::" + uuid
-	default:
-		return "node-unknown::" + uuid
-	}
+	return NodeKindFor(nodeType).NodeID(generateRealUUID())
 }
 
 // generateSpecialNodeID generates special node ID for iteration child nodes
-func (g *BaseNodeGenerator) generateSpecialNodeID(nodePrefix string) string {
-	uuid := generateRealUUID()
-	return nodePrefix + "::" + uuid
+func (g *BaseNodeGenerator) generateSpecialNodeID(kind NodeKind) string {
+	return kind.NodeID(generateRealUUID())
 }
 
 // generateRealUUID generates cryptographically secure UUID
@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
-	"regexp"
-	"strings"
 )
 
 // ClassifierNodeGenerator handles iFlytek SparkAgent classifier node generation
@@ -53,6 +51,7 @@ func (g *ClassifierNodeGenerator) GenerateNode(node models.Node) (IFlytekNode, e
 	if err != nil {
 		return IFlytekNode{}, fmt.Errorf("failed to generate node parameters: %w", err)
 	}
+	keepSourceModel(nodeParam, node, classifierConfig.Model.Name)
 	iflytekNode.Data.NodeParam = nodeParam
 
 	// set inputs/outputs (using mapped IDs)
@@ -71,12 +70,13 @@ func (g *ClassifierNodeGenerator) GenerateNode(node models.Node) (IFlytekNode, e
 
 // generateNodeParam generates node parameters
 func (g *ClassifierNodeGenerator) generateNodeParam(config models.ClassifierConfig, inputs []models.Input) (map[string]interface{}, error) {
+	chatHistoryEnabled, chatHistoryRounds := common.ChatHistory(config.Memory, 1)
 	nodeParam := map[string]interface{}{
-		"topK":    4,
+		"topK":    positiveOr(config.Parameters.TopK, 4),
 		"modelId": 141,
 		"chatHistory": map[string]interface{}{
-			"isEnabled": false,
-			"rounds":    1,
+			"isEnabled": chatHistoryEnabled,
+			"rounds":    chatHistoryRounds,
 		},
 		"reasonMode":      1,
 		"auditing":        "default",
@@ -84,14 +84,14 @@ func (g *ClassifierNodeGenerator) generateNodeParam(config models.ClassifierConf
 		"promptPrefix":    g.generatePromptPrefix(config, inputs),
 		"url":             "wss://maas-api.cn-huabei-1.xf-yun.com/v1.1/chat",
 		"multiMode":       false,
-		"uid":             "20718349453", // Default value obtained from examples
+		"uid":             g.credentials.UID,
 		"patchId":         "0",
 		"isThink":         false,
 		"searchDisable":   true,
 		"domain":          "xdeepseekv3",
-		"appId":           "12a0a7e2", // Default value obtained from examples
-		"maxTokens":       8192,
-		"temperature":     0.5,
+		"appId":           g.credentials.AppID,
+		"maxTokens":       positiveOr(config.Parameters.MaxTokens, 8192),
+		"temperature":     config.Parameters.Temperature,
 		"model":           "spark",
 		"useFunctionCall": true,
 		"serviceId":       "xdeepseekv3",
@@ -102,8 +102,13 @@ func (g *ClassifierNodeGenerator) generateNodeParam(config models.ClassifierConf
 	intentChains := make([]map[string]interface{}, 0)
 	var defaultIntentID string
 
-	// create normal intents for all actual classifications
+	// create normal intents for all actual classifications; a default class becomes the default intent
+	var defaultClass *models.ClassifierClass
 	for i, class := range config.Classes {
+		if class.IsDefault {
+			defaultClass = &config.Classes[i]
+			continue
+		}
 		intentID := fmt.Sprintf("intent-one-of::%s", generateUUID())
 
 		// normal classification intent
@@ -135,6 +140,11 @@ func (g *ClassifierNodeGenerator) generateNodeParam(config models.ClassifierConf
 		"nameErrMsg":        "",
 		"descriptionErrMsg": "",
 	}
+	if defaultClass != nil {
+		defaultIntentChain["name"] = g.cleanVariableReferences(defaultClass.Name)
+		defaultIntentChain["description"] = g.cleanVariableReferences(defaultClass.Description)
+		g.classIDToIntentID[defaultClass.ID] = defaultIntentID
+	}
 	intentChains = append(intentChains, defaultIntentChain)
 
 	// save special mapping if default intent exists
@@ -147,60 +157,43 @@ func (g *ClassifierNodeGenerator) generateNodeParam(config models.ClassifierConf
 	return nodeParam, nil
 }
 
-// generatePromptPrefix generates prompt prefix from Dify instructions
+// generatePromptPrefix generates prompt prefix from the instructions and examples
 func (g *ClassifierNodeGenerator) generatePromptPrefix(config models.ClassifierConfig, inputs []models.Input) string {
-	if config.Instructions == "" {
+	instructions := common.ClassifierInstructions(&config)
+	if instructions == "" {
 		return ""
 	}
 
-	return g.unifyVariableReferencesToQuery(config.Instructions)
-}
-
-// processInstructionReferences processes variable references in instructions
-func (g *ClassifierNodeGenerator) processInstructionReferences(instructions string, inputs []models.Input) string {
-	processed := instructions
-
-	// Convert Dify variable references to iFlytek format
-	// Pattern: {{#nodeID.variableName#}} -> {{variableName}}
-	for _, input := range inputs {
-		if input.Reference != nil {
-			difyPattern := fmt.Sprintf("{{#%s.%s#}}", input.Reference.NodeID, input.Reference.OutputName)
-			iflytekPattern := fmt.Sprintf("{{%s}}", input.Name)
-			processed = replaceVariableReference(processed, difyPattern, iflytekPattern)
-		}
-	}
-
-	return processed
+	return g.unifyVariableReferencesToQuery(instructions)
 }
 
-// replaceVariableReference safely replaces variable references
-func replaceVariableReference(text, pattern, replacement string) string {
-	if text == "" || pattern == "" {
-		return text
+// positiveOr returns value, or fallback when the source left it unset
+func positiveOr(value, fallback int) int {
+	if value > 0 {
+		return value
 	}
-	return strings.ReplaceAll(text, pattern, replacement)
+	return fallback
 }
 
-// cleanVariableReferences removes all variable references from description text
+// cleanVariableReferences removes all node references from description text
 func (g *ClassifierNodeGenerator) cleanVariableReferences(text string) string {
-	if text == "" {
-		return ""
-	}
-
-	// Use regex to remove all Dify variable references: {{#...#}}
-	re := regexp.MustCompile(`\{\{#[^}]*#\}\}`)
-	return re.ReplaceAllString(text, "")
+	return common.RewritePromptTemplate(text, func(p common.PromptPlaceholder) string {
+		if p.IsReference() {
+			return ""
+		}
+		return p.Text
+	})
 }
 
-// unifyVariableReferencesToQuery converts all variable references to {{Query}}
+// unifyVariableReferencesToQuery converts all node references to {{Query}}, the single input of
+// iFlytek decision nodes
 func (g *ClassifierNodeGenerator) unifyVariableReferencesToQuery(instructions string) string {
-	if instructions == "" {
-		return ""
-	}
-
-	// Replace all Dify variable references with {{Query}}
-	re := regexp.MustCompile(`\{\{#[^}]*#\}\}`)
-	return re.ReplaceAllString(instructions, "{{Query}}")
+	return common.RewritePromptTemplate(instructions, func(p common.PromptPlaceholder) string {
+		if p.IsReference() {
+			return "{{Query}}"
+		}
+		return p.Text
+	})
 }
 
 // generateReferences generates reference information
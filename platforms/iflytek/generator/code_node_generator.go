@@ -3,7 +3,6 @@ package generator
 import (
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
-	"strings"
 )
 
 // CodeNodeGenerator handles code node generation
@@ -73,8 +72,8 @@ func (g *CodeNodeGenerator) GenerateNode(node models.Node) (IFlytekNode, error)
 // generateNodeParam generates node parameters
 func (g *CodeNodeGenerator) generateNodeParam(node models.Node) map[string]interface{} {
 	nodeParam := map[string]interface{}{
-		"uid":        "20718349453", // default uid
-		"appId":      "12a0a7e2",    // default appId
+		"uid":        g.credentials.UID,
+		"appId":      g.credentials.AppID,
 		"codeErrMsg": "",
 	}
 
@@ -159,6 +158,8 @@ func (g *CodeNodeGenerator) generateOutputs(outputs []models.Output) []IFlytekOu
 // generateReferences generates variable reference information
 func (g *CodeNodeGenerator) generateReferences(inputs []models.Input) []IFlytekReference {
 	nodeGroups := make(map[string][]models.Input)
+	// Referenced nodes in order of first use, so references come out in input order
+	var nodeOrder []string
 
 	// group inputs by source node
 	for _, input := range inputs {
@@ -174,11 +175,15 @@ func (g *CodeNodeGenerator) generateReferences(inputs []models.Input) []IFlytekR
 			}
 		}
 
+		if _, seen := nodeGroups[mappedNodeID]; !seen {
+			nodeOrder = append(nodeOrder, mappedNodeID)
+		}
 		nodeGroups[mappedNodeID] = append(nodeGroups[mappedNodeID], input)
 	}
 
 	var references []IFlytekReference
-	for nodeID, nodeInputs := range nodeGroups {
+	for _, nodeID := range nodeOrder {
+		nodeInputs := nodeGroups[nodeID]
 		// create parent reference for each source node
 		var refDetails []IFlytekRefDetail
 		for _, input := range nodeInputs {
@@ -216,8 +221,7 @@ func (g *CodeNodeGenerator) generateReferences(inputs []models.Input) []IFlytekR
 
 // mapOutputNameForPlatform maps output names for platform compatibility
 func (g *CodeNodeGenerator) mapOutputNameForPlatform(outputName, nodeID string) string {
-	// Check if it's an LLM node (spark-llm::)
-	if strings.HasPrefix(nodeID, "spark-llm::") {
+	if NodeKindLLM.Is(nodeID) {
 		// In Dify, LLM nodes output 'text', but in iFlytek they output 'output'
 		if outputName == "text" {
 			return "output"
@@ -42,6 +42,10 @@ func (g *ConditionNodeGenerator) SetUnifiedDSL(dsl *models.UnifiedDSL) {
 
 // GenerateNode generates condition branch node
 func (g *ConditionNodeGenerator) GenerateNode(node models.Node) (IFlytekNode, error) {
+	if _, err := common.ConditionOperatorIssues(node, models.PlatformIFlytek); err != nil {
+		return IFlytekNode{}, err
+	}
+
 	// generate basic node information
 	iflytekNode := g.generateBasicNodeInfo(node)
 	iflytekNode.Type = "分支器"
@@ -92,6 +96,10 @@ func (g *ConditionNodeGenerator) generateConditionInputs(node models.Node) ([]IF
 		return inputs, inputIDMap
 	}
 
+	if sourceInputs, sourceIDMap, ok := g.sourceConditionInputs(node, condConfig.Cases); ok {
+		return sourceInputs, sourceIDMap
+	}
+
 	for _, caseItem := range condConfig.Cases {
 		inputs, inputIDMap, inputCounter = g.processCaseConditions(caseItem.Conditions, inputs, inputIDMap, inputCounter)
 	}
@@ -99,6 +107,82 @@ func (g *ConditionNodeGenerator) generateConditionInputs(node models.Node) ([]IF
 	return inputs, inputIDMap
 }
 
+// sourceConditionInputs keeps the inputs of a node parsed from iFlytek, where every condition
+// compares a variable input with a literal input of its own, so identity conversions keep the
+// input names. The input IDs are mapped per condition, by case ID and position. It fails when a
+// condition has no matching inputs, as for nodes of other platforms.
+func (g *ConditionNodeGenerator) sourceConditionInputs(node models.Node, cases []models.ConditionCase) ([]IFlytekInput, map[string]string, bool) {
+	inputIDs := make([]string, len(node.Inputs))
+	used := make([]bool, len(node.Inputs))
+	inputIDMap := make(map[string]string)
+
+	// findInput returns the first unused input that matches, else the first used one
+	findInput := func(matches func(models.VariableReference) bool) (int, bool) {
+		found := -1
+		for i, input := range node.Inputs {
+			if input.Reference == nil || !matches(*input.Reference) {
+				continue
+			}
+			if !used[i] {
+				return i, true
+			}
+			if found < 0 {
+				found = i
+			}
+		}
+		return found, found >= 0
+	}
+	inputID := func(index int) string {
+		used[index] = true
+		if inputIDs[index] == "" {
+			inputIDs[index] = g.generateInputID()
+		}
+		return inputIDs[index]
+	}
+
+	for _, caseItem := range cases {
+		for j, condition := range caseItem.Conditions {
+			if !g.isValidConditionSelector(condition.VariableSelector) {
+				continue
+			}
+			sourceNodeID, sourceOutput := g.extractVariableSelector(condition.VariableSelector)
+			left, ok := findInput(func(reference models.VariableReference) bool {
+				return reference.Type == models.ReferenceTypeNodeOutput && reference.NodeID == sourceNodeID && reference.OutputName == sourceOutput
+			})
+			if !ok {
+				return nil, nil, false
+			}
+			inputIDMap[conditionInputKey(caseItem.CaseID, j, "left")] = inputID(left)
+
+			right, ok := findInput(func(reference models.VariableReference) bool {
+				return reference.Type == models.ReferenceTypeLiteral && fmt.Sprint(reference.Value) == fmt.Sprint(condition.Value)
+			})
+			if !ok {
+				return nil, nil, false
+			}
+			inputIDMap[conditionInputKey(caseItem.CaseID, j, "right")] = inputID(right)
+		}
+	}
+
+	var inputs []IFlytekInput
+	for i, input := range node.Inputs {
+		if !used[i] {
+			continue
+		}
+		if input.Reference.Type == models.ReferenceTypeLiteral {
+			inputs = append(inputs, g.createLiteralInput(inputIDs[i], input.Name, input.Reference.Value))
+			continue
+		}
+		inputs = append(inputs, g.createVariableReferenceInput(inputIDs[i], input.Name, input.Reference.OutputName, g.getMappedNodeID(input.Reference.NodeID)))
+	}
+	return inputs, inputIDMap, true
+}
+
+// conditionInputKey is the input ID map key of one side of a condition kept from the source
+func conditionInputKey(caseID string, position int, side string) string {
+	return fmt.Sprintf("case_%s_%d_%s", caseID, position, side)
+}
+
 // processCaseConditions processes all conditions in a case
 func (g *ConditionNodeGenerator) processCaseConditions(conditions []models.Condition, inputs []IFlytekInput, inputIDMap map[string]string, inputCounter int) ([]IFlytekInput, map[string]string, int) {
 	for _, condition := range conditions {
@@ -248,28 +332,40 @@ func (g *ConditionNodeGenerator) createLiteralInput(inputID, inputName string, v
 // generateNodeParamWithInputIDs generates node parameters using input ID mapping
 func (g *ConditionNodeGenerator) generateNodeParamWithInputIDs(node models.Node, inputIDMap map[string]string) map[string]interface{} {
 	nodeParam := map[string]interface{}{
-		"uid":   "20718349453",
-		"appId": "12a0a7e2",
+		"uid":   g.credentials.UID,
+		"appId": g.credentials.AppID,
 	}
 
 	// Extract condition branch information from configuration
 	if condConfig, ok := common.AsConditionConfig(node.Config); ok && condConfig != nil {
-		cases := g.generateCasesWithInputIDs(condConfig.Cases, inputIDMap)
+		cases := g.generateCasesWithInputIDs(condConfig.Cases, condConfig.DefaultCase, inputIDMap)
 		nodeParam["cases"] = cases
 	}
 
 	return nodeParam
 }
 
-// generateCasesWithInputIDs generates condition branch cases using input ID mapping
-func (g *ConditionNodeGenerator) generateCasesWithInputIDs(cases []models.ConditionCase, inputIDMap map[string]string) []map[string]interface{} {
+// generateCasesWithInputIDs generates condition branch cases using input ID mapping. A source
+// case without conditions that is the default case, as iFlytek sources list it, becomes the
+// default branch.
+func (g *ConditionNodeGenerator) generateCasesWithInputIDs(cases []models.ConditionCase, defaultCase string, inputIDMap map[string]string) []map[string]interface{} {
 	var iflytekCases []map[string]interface{}
 
+	actualCases := make([]models.ConditionCase, 0, len(cases))
+	for _, caseItem := range cases {
+		if defaultCase == "" || caseItem.CaseID != defaultCase || len(caseItem.Conditions) > 0 {
+			actualCases = append(actualCases, caseItem)
+		}
+	}
+
 	// Generate actual condition branches
-	iflytekCases = g.generateActualConditionBranches(cases, inputIDMap, iflytekCases)
+	iflytekCases = g.generateActualConditionBranches(actualCases, inputIDMap, iflytekCases)
 
 	// Add default branch
 	iflytekCases = g.addDefaultBranch(iflytekCases)
+	if defaultCase != "" {
+		g.branchIDMapping[defaultCase] = g.branchIDMapping["__default__"]
+	}
 
 	return iflytekCases
 }
@@ -290,12 +386,12 @@ func (g *ConditionNodeGenerator) createConditionCase(caseItem models.ConditionCa
 
 	iflytekCase := map[string]interface{}{
 		"level":           level,
-		"logicalOperator": g.mapLogicalOperator(caseItem.LogicalOperator),
+		"logicalOperator": models.NormalizeLogicalOperator(caseItem.LogicalOperator),
 		"id":              branchID,
 	}
 
 	// Generate condition list
-	conditions := g.generateConditionsWithInputIDs(caseItem.Conditions, inputIDMap)
+	conditions := g.generateConditionsWithInputIDs(caseItem, inputIDMap)
 	iflytekCase["conditions"] = conditions
 
 	return iflytekCase
@@ -347,11 +443,12 @@ func (g *ConditionNodeGenerator) createDefaultCase(defaultBranchID string) map[s
 }
 
 // generateConditionsWithInputIDs generates condition list using input ID mapping
-func (g *ConditionNodeGenerator) generateConditionsWithInputIDs(conditions []models.Condition, inputIDMap map[string]string) []map[string]interface{} {
+func (g *ConditionNodeGenerator) generateConditionsWithInputIDs(caseItem models.ConditionCase, inputIDMap map[string]string) []map[string]interface{} {
 	var iflytekConditions []map[string]interface{}
 
-	for _, condition := range conditions {
-		iflytekCondition := g.processConditionWithInputIDs(condition, inputIDMap)
+	for j, condition := range caseItem.Conditions {
+		iflytekCondition := g.processConditionWithInputIDs(condition, inputIDMap[conditionInputKey(caseItem.CaseID, j, "left")],
+			inputIDMap[conditionInputKey(caseItem.CaseID, j, "right")], inputIDMap)
 		if iflytekCondition != nil {
 			iflytekConditions = append(iflytekConditions, iflytekCondition)
 		}
@@ -360,14 +457,17 @@ func (g *ConditionNodeGenerator) generateConditionsWithInputIDs(conditions []mod
 	return iflytekConditions
 }
 
-// processConditionWithInputIDs processes a single condition with input ID mapping
-func (g *ConditionNodeGenerator) processConditionWithInputIDs(condition models.Condition, inputIDMap map[string]string) map[string]interface{} {
+// processConditionWithInputIDs processes a single condition with input ID mapping; the input IDs
+// kept from the source, when given, take precedence
+func (g *ConditionNodeGenerator) processConditionWithInputIDs(condition models.Condition, leftVarIndex, rightVarIndex string, inputIDMap map[string]string) map[string]interface{} {
 	if !g.isValidConditionSelector(condition.VariableSelector) {
 		return nil
 	}
 
-	sourceNodeID, sourceOutput := g.extractVariableSelector(condition.VariableSelector)
-	leftVarIndex, rightVarIndex := g.getInputIndices(sourceNodeID, sourceOutput, condition.Value, inputIDMap)
+	if leftVarIndex == "" || rightVarIndex == "" {
+		sourceNodeID, sourceOutput := g.extractVariableSelector(condition.VariableSelector)
+		leftVarIndex, rightVarIndex = g.getInputIndices(sourceNodeID, sourceOutput, condition.Value, inputIDMap)
+	}
 
 	if leftVarIndex == "" || rightVarIndex == "" {
 		return nil
@@ -410,87 +510,11 @@ func (g *ConditionNodeGenerator) createConditionStructure(leftVarIndex, rightVar
 	}
 }
 
-// mapLogicalOperator maps logical operators
-func (g *ConditionNodeGenerator) mapLogicalOperator(op string) string {
-	switch op {
-	case "and":
-		return "and"
-	case "or":
-		return "or"
-	default:
-		return "and"
-	}
-}
-
-// mapComparisonOperator maps comparison operators
+// mapComparisonOperator maps unified comparison operators to iFlytek operators. Unsupported
+// operators are rejected by GenerateNode.
 func (g *ConditionNodeGenerator) mapComparisonOperator(op string) string {
-	// Try direct mapping first
-	if mapped := g.getDirectOperatorMapping(op); mapped != "" {
-		return mapped
-	}
-
-	// Check if it's already a valid operator
-	if g.isValidOperator(op) {
-		return op
-	}
-
-	// Return default safe fallback
-	return "is"
-}
-
-// getDirectOperatorMapping returns direct operator mappings
-func (g *ConditionNodeGenerator) getDirectOperatorMapping(op string) string {
-	mappings := g.getOperatorMappings()
-	return mappings[op]
-}
-
-// getOperatorMappings returns the operator mapping table
-func (g *ConditionNodeGenerator) getOperatorMappings() map[string]string {
-	return map[string]string{
-		"contains":     "contains",
-		"not contains": "not_contains",
-		"equals":       "is",
-		"not equals":   "is_not",
-		"gt":           "gt",
-		"gte":          "ge",
-		"ge":           "ge",
-		"lt":           "lt",
-		"lte":          "le",
-		"le":           "le",
-		"starts_with":  "start_with",
-		"start with":   "start_with",
-		"ends_with":    "end_with",
-		"end with":     "end_with",
-		"is_empty":     "empty",
-		"is_not_empty": "not_empty",
-		"not empty":    "not_empty",
-		"is_null":      "null",
-		"null":         "null",
-		"is_not_null":  "not_null",
-		"not null":     "not_null",
-		"eq":           "eq",
-		"ne":           "ne",
-	}
-}
-
-// isValidOperator checks if operator is already valid
-func (g *ConditionNodeGenerator) isValidOperator(op string) bool {
-	validOperators := g.getValidOperators()
-	for _, validOp := range validOperators {
-		if op == validOp {
-			return true
-		}
-	}
-	return false
-}
-
-// getValidOperators returns list of valid operators
-func (g *ConditionNodeGenerator) getValidOperators() []string {
-	return []string{
-		"contains", "not_contains", "empty", "not_empty",
-		"is", "is_not", "start_with", "end_with",
-		"eq", "ne", "gt", "ge", "lt", "le", "null", "not_null",
-	}
+	translation, _ := common.ConditionOperatorFor(op, "", models.PlatformIFlytek)
+	return translation.Operator
 }
 
 // generateBranchID generates branch ID
@@ -506,15 +530,16 @@ func (g *ConditionNodeGenerator) generateBranchID(caseID string) string {
 // generateReferences generates variable reference information
 func (g *ConditionNodeGenerator) generateReferences(inputs []models.Input) []IFlytekReference {
 	// Group inputs by source node
-	nodeGroups := g.groupInputsBySourceNode(inputs)
+	nodeGroups, nodeOrder := g.groupInputsBySourceNode(inputs)
 
 	// Generate references for each node group
-	return g.generateReferencesFromNodeGroups(nodeGroups)
+	return g.generateReferencesFromNodeGroups(nodeGroups, nodeOrder)
 }
 
-// groupInputsBySourceNode groups inputs by their source node ID
-func (g *ConditionNodeGenerator) groupInputsBySourceNode(inputs []models.Input) map[string][]models.Input {
+// groupInputsBySourceNode groups inputs by their source node ID and returns the node IDs in order of first appearance
+func (g *ConditionNodeGenerator) groupInputsBySourceNode(inputs []models.Input) (map[string][]models.Input, []string) {
 	nodeGroups := make(map[string][]models.Input)
+	var nodeOrder []string
 
 	for _, input := range inputs {
 		if !g.isValidInputReference(input) {
@@ -522,10 +547,13 @@ func (g *ConditionNodeGenerator) groupInputsBySourceNode(inputs []models.Input)
 		}
 
 		mappedNodeID := g.getMappedNodeID(input.Reference.NodeID)
+		if _, seen := nodeGroups[mappedNodeID]; !seen {
+			nodeOrder = append(nodeOrder, mappedNodeID)
+		}
 		nodeGroups[mappedNodeID] = append(nodeGroups[mappedNodeID], input)
 	}
 
-	return nodeGroups
+	return nodeGroups, nodeOrder
 }
 
 // isValidInputReference checks if input has valid reference information
@@ -534,11 +562,11 @@ func (g *ConditionNodeGenerator) isValidInputReference(input models.Input) bool
 }
 
 // generateReferencesFromNodeGroups generates references from grouped inputs
-func (g *ConditionNodeGenerator) generateReferencesFromNodeGroups(nodeGroups map[string][]models.Input) []IFlytekReference {
+func (g *ConditionNodeGenerator) generateReferencesFromNodeGroups(nodeGroups map[string][]models.Input, nodeOrder []string) []IFlytekReference {
 	var references []IFlytekReference
 
-	for nodeID, nodeInputs := range nodeGroups {
-		reference := g.createReferenceForNodeGroup(nodeID, nodeInputs)
+	for _, nodeID := range nodeOrder {
+		reference := g.createReferenceForNodeGroup(nodeID, nodeGroups[nodeID])
 		references = append(references, reference)
 	}
 
@@ -683,15 +711,17 @@ func (g *ConditionNodeGenerator) generateConditionReferences(node models.Node) [
 	}
 
 	// Collect all variable references from conditions
-	nodeGroups := g.collectVariableReferencesFromConditions(condConfig.Cases)
+	nodeGroups, nodeOrder := g.collectVariableReferencesFromConditions(condConfig.Cases)
 
 	// Generate references for each node group
-	return g.generateReferencesFromConditionGroups(nodeGroups)
+	return g.generateReferencesFromConditionGroups(nodeGroups, nodeOrder)
 }
 
-// collectVariableReferencesFromConditions collects variable references from condition cases
-func (g *ConditionNodeGenerator) collectVariableReferencesFromConditions(cases []models.ConditionCase) map[string][]string {
+// collectVariableReferencesFromConditions collects variable references from condition cases, with the
+// node IDs in order of first appearance
+func (g *ConditionNodeGenerator) collectVariableReferencesFromConditions(cases []models.ConditionCase) (map[string][]string, []string) {
 	nodeGroups := make(map[string][]string)
+	var nodeOrder []string
 
 	for _, caseItem := range cases {
 		for _, condition := range caseItem.Conditions {
@@ -702,6 +732,9 @@ func (g *ConditionNodeGenerator) collectVariableReferencesFromConditions(cases [
 				// Map the node ID
 				mappedNodeID := g.getMappedNodeID(sourceNodeID)
 
+				if _, seen := nodeGroups[mappedNodeID]; !seen {
+					nodeOrder = append(nodeOrder, mappedNodeID)
+				}
 				// Add to node groups, avoid duplicates
 				if !g.contains(nodeGroups[mappedNodeID], sourceOutput) {
 					nodeGroups[mappedNodeID] = append(nodeGroups[mappedNodeID], sourceOutput)
@@ -710,7 +743,7 @@ func (g *ConditionNodeGenerator) collectVariableReferencesFromConditions(cases [
 		}
 	}
 
-	return nodeGroups
+	return nodeGroups, nodeOrder
 }
 
 // contains checks if a string slice contains a specific string
@@ -724,11 +757,11 @@ func (g *ConditionNodeGenerator) contains(slice []string, item string) bool {
 }
 
 // generateReferencesFromConditionGroups generates references from condition variable groups
-func (g *ConditionNodeGenerator) generateReferencesFromConditionGroups(nodeGroups map[string][]string) []IFlytekReference {
+func (g *ConditionNodeGenerator) generateReferencesFromConditionGroups(nodeGroups map[string][]string, nodeOrder []string) []IFlytekReference {
 	var references []IFlytekReference
 
-	for nodeID, outputNames := range nodeGroups {
-		reference := g.createReferenceForConditionGroup(nodeID, outputNames)
+	for _, nodeID := range nodeOrder {
+		reference := g.createReferenceForConditionGroup(nodeID, nodeGroups[nodeID])
 		references = append(references, reference)
 	}
 
@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"os"
+	"strings"
+)
+
+// Default Spark application identity taken from platform examples
+const (
+	DefaultSparkAppID = "12a0a7e2"
+	DefaultSparkUID   = "20718349453"
+)
+
+// Environment variables overriding the default Spark application identity
+const (
+	EnvSparkAppID = "AGENTBRIDGE_IFLYTEK_APP_ID"
+	EnvSparkUID   = "AGENTBRIDGE_IFLYTEK_UID"
+)
+
+// SparkCredentials identifies the Spark application that generated nodes run under.
+type SparkCredentials struct {
+	AppID string
+	UID   string
+}
+
+// ResolveSparkCredentials resolves the Spark identity with precedence: explicit value, environment, default.
+func ResolveSparkCredentials(appID, uid string) SparkCredentials {
+	return SparkCredentials{
+		AppID: firstNonEmpty(appID, os.Getenv(EnvSparkAppID), DefaultSparkAppID),
+		UID:   firstNonEmpty(uid, os.Getenv(EnvSparkUID), DefaultSparkUID),
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
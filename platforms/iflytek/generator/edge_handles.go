@@ -0,0 +1,121 @@
+package generator
+
+import "fmt"
+
+// branchHandles lists the source handles of a node with branches: the IDs of its branches and the
+// one taken when nothing else matches
+type branchHandles struct {
+	ids        map[string]bool
+	defaultID  string
+	branchName string
+}
+
+// defaultHandleAliases are the handles the source platforms give the fallback branch of a node
+var defaultHandleAliases = map[string]bool{
+	"false":             true,
+	DefaultSourceHandle: true,
+	DefaultIntentKey:    true,
+}
+
+// checkEdgeHandles verifies that every edge leaves an existing node through a handle that node has,
+// so Spark does not import edges hanging off nothing. Handles the mapping of branch IDs missed are
+// repaired where the intent is clear: fallback handles go to the default branch, and handles of
+// other platforms on nodes without branches, such as the "loop-output" of Coze loops, are dropped
+// like in the edges Spark writes. Edges that still point nowhere are an error.
+func (g *iflytekGeneration) checkEdgeHandles(iflytekDSL *IFlytekDSL) error {
+	nodes := make(map[string]*IFlytekNode, len(iflytekDSL.FlowData.Nodes))
+	for i := range iflytekDSL.FlowData.Nodes {
+		nodes[iflytekDSL.FlowData.Nodes[i].ID] = &iflytekDSL.FlowData.Nodes[i]
+	}
+
+	for i := range iflytekDSL.FlowData.Edges {
+		edge := &iflytekDSL.FlowData.Edges[i]
+		source, exists := nodes[edge.Source]
+		if !exists {
+			return fmt.Errorf("edge %s starts at node %s, which was not generated", edge.ID, edge.Source)
+		}
+		if _, exists := nodes[edge.Target]; !exists {
+			return fmt.Errorf("edge %s ends at node %s, which was not generated", edge.ID, edge.Target)
+		}
+
+		handle, err := validSourceHandle(source, edge.SourceHandle)
+		if err != nil {
+			return fmt.Errorf("edge %s: %w", edge.ID, err)
+		}
+		if handle != edge.SourceHandle {
+			edge.SourceHandle = handle
+			edge.ID = g.generateEdgeIDWithHandle(edge.Source, handle, edge.Target)
+		}
+	}
+	return nil
+}
+
+// validSourceHandle returns handle, or the handle of the node it stands for
+func validSourceHandle(node *IFlytekNode, handle string) (string, error) {
+	branches := nodeBranchHandles(node)
+	if branches == nil {
+		if handle == "source" {
+			return handle, nil
+		}
+		return "", nil
+	}
+
+	if branches.ids[handle] {
+		return handle, nil
+	}
+	if defaultHandleAliases[handle] && branches.defaultID != "" {
+		return branches.defaultID, nil
+	}
+	return "", fmt.Errorf("node %q has no %s %q", node.Data.Label, branches.branchName, handle)
+}
+
+// nodeBranchHandles returns the branches of condition, classifier and option question nodes, nil
+// for nodes with a single output
+func nodeBranchHandles(node *IFlytekNode) *branchHandles {
+	switch node.Kind() {
+	case NodeKindCondition:
+		return collectBranchHandles(node.Data.NodeParam["cases"], "branch", func(entry map[string]interface{}) bool {
+			return fmt.Sprint(entry["level"]) == "999"
+		})
+	case NodeKindClassifier:
+		return collectBranchHandles(node.Data.NodeParam["intentChains"], "intent", func(entry map[string]interface{}) bool {
+			return fmt.Sprint(entry["intentType"]) == "1"
+		})
+	case NodeKindQuestionAnswer:
+		if node.Data.NodeParam["answerType"] != questionAnswerTypeOption {
+			return nil
+		}
+		return collectBranchHandles(node.Data.NodeParam["optionAnswer"], "option", func(entry map[string]interface{}) bool {
+			return fmt.Sprint(entry["type"]) == fmt.Sprint(questionOptionTypeDefault)
+		})
+	}
+	return nil
+}
+
+// collectBranchHandles reads the IDs of the branch entries of a node parameter
+func collectBranchHandles(param interface{}, branchName string, isDefault func(map[string]interface{}) bool) *branchHandles {
+	var entries []map[string]interface{}
+	switch list := param.(type) {
+	case []map[string]interface{}:
+		entries = list
+	case []interface{}:
+		for _, item := range list {
+			if entry, ok := item.(map[string]interface{}); ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	branches := &branchHandles{ids: make(map[string]bool, len(entries)), branchName: branchName}
+	for _, entry := range entries {
+		id, _ := entry["id"].(string)
+		if id == "" {
+			continue
+		}
+		branches.ids[id] = true
+		if isDefault(entry) {
+			branches.defaultID = id
+		}
+	}
+	return branches
+}
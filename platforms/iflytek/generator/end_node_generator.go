@@ -40,7 +40,9 @@ func (g *EndNodeGenerator) GetSupportedNodeType() models.NodeType {
 func (g *EndNodeGenerator) GenerateNode(node models.Node) (IFlytekNode, error) {
 	iflytekNode := g.generateBasicNodeInfo(node)
 	iflytekNode.Type = "结束节点"
-	iflytekNode.Data.Label = "结束"
+	if node.Title == "" {
+		iflytekNode.Data.Label = "结束"
+	}
 	iflytekNode.Data.Description = "工作流的结束节点，用于输出工作流运行后的最终结果。"
 	iflytekNode.Data.NodeMeta.AliasName = "结束节点"
 	iflytekNode.Data.NodeMeta.NodeType = "基础节点"
@@ -105,9 +107,9 @@ func (g *EndNodeGenerator) ValidateNode(node models.Node) error {
 // convertOutputMode converts output mode
 func (g *EndNodeGenerator) convertOutputMode(mode string) int {
 	switch mode {
-	case "template":
+	case models.EndOutputModeTemplate:
 		return 1 // template mode
-	case "variables":
+	case models.EndOutputModeVariables:
 		return 0 // variable mode
 	default:
 		return 0 // default variable mode
@@ -135,6 +137,8 @@ func (g *EndNodeGenerator) generateDefaultTemplate(inputs []models.Input) string
 func (g *EndNodeGenerator) generateReferences(inputs []models.Input) []IFlytekReference {
 	// group inputs by node ID
 	nodeGroups := make(map[string][]models.Input)
+	// nodeOrder fixes the reference order, map iteration would shuffle it
+	var nodeOrder []string
 
 	for _, input := range inputs {
 		if input.Reference == nil || input.Reference.NodeID == "" {
@@ -149,13 +153,17 @@ func (g *EndNodeGenerator) generateReferences(inputs []models.Input) []IFlytekRe
 			}
 		}
 
+		if _, seen := nodeGroups[mappedNodeID]; !seen {
+			nodeOrder = append(nodeOrder, mappedNodeID)
+		}
 		nodeGroups[mappedNodeID] = append(nodeGroups[mappedNodeID], input)
 	}
 
 	// create parent reference for each node
 	references := make([]IFlytekReference, 0, len(nodeGroups))
 
-	for nodeID, nodeInputs := range nodeGroups {
+	for _, nodeID := range nodeOrder {
+		nodeInputs := nodeGroups[nodeID]
 		// create all output references for this node
 		refDetails := make([]IFlytekRefDetail, 0, len(nodeInputs))
 
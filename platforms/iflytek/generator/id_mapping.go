@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// buildIDMapping returns the node, output, branch and intent ID mappings of the generation
+func (g *iflytekGeneration) buildIDMapping() *models.IDMapping {
+	mapping := models.NewIDMapping()
+	mapping.Nodes = common.CopyIDMapping(g.idMapping)
+
+	for nodeID, outputs := range g.outputIDMapping {
+		for name, outputID := range outputs {
+			mapping.AddOutput(nodeID, name, outputID)
+		}
+	}
+
+	if g.currentDSL != nil {
+		g.collectHandleIDs(g.currentDSL.Workflow.Nodes, mapping)
+	}
+
+	common.ApplyReusedIDs(mapping, g.reusedIDs)
+	return mapping
+}
+
+// reusePreviousIDs rewrites generated IDs to the IDs a previous conversion assigned to the same
+// source nodes, outputs, branches and intents. iFlytek IDs are UUID based, so a textual rewrite
+// of the serialized DSL updates every reference consistently.
+func (g *iflytekGeneration) reusePreviousIDs(data []byte) []byte {
+	g.reusedIDs = nil
+	if g.previousMapping == nil {
+		return data
+	}
+
+	replacements := common.CollectReusableIDs(g.previousMapping, g.buildIDMapping())
+	g.reusedIDs = replacements
+	return common.ReplaceIDs(data, replacements)
+}
+
+// collectHandleIDs maps source case and class IDs to the generated branch and intent IDs
+func (g *iflytekGeneration) collectHandleIDs(nodes []models.Node, mapping *models.IDMapping) {
+	for _, node := range nodes {
+		iflytekID := g.idMapping[node.ID]
+
+		switch node.Type {
+		case models.NodeTypeCondition:
+			g.collectBranchIDs(node, iflytekID, mapping)
+		case models.NodeTypeClassifier:
+			g.collectIntentIDs(node, mapping)
+		case models.NodeTypeIteration:
+			if iterConfig, ok := common.AsIterationConfig(node.Config); ok && iterConfig != nil {
+				g.collectHandleIDs(iterConfig.SubWorkflow.Nodes, mapping)
+			}
+		}
+	}
+}
+
+// collectBranchIDs maps the source case IDs of a condition node to its branch IDs
+func (g *iflytekGeneration) collectBranchIDs(node models.Node, iflytekID string, mapping *models.IDMapping) {
+	branchMapping, exists := g.conditionBranchMapping[iflytekID]
+	conditionConfig, ok := common.AsConditionConfig(node.Config)
+	if !exists || !ok || conditionConfig == nil {
+		return
+	}
+
+	caseIDs := make([]string, 0, len(conditionConfig.Cases)+1)
+	for _, caseItem := range conditionConfig.Cases {
+		caseIDs = append(caseIDs, caseItem.CaseID)
+	}
+	if conditionConfig.DefaultCase != "" {
+		caseIDs = append(caseIDs, conditionConfig.DefaultCase)
+	}
+
+	for _, caseID := range caseIDs {
+		if branchID, exists := branchMapping.BranchIDs[caseID]; exists {
+			mapping.AddBranch(node.ID, caseID, branchID)
+		}
+	}
+}
+
+// collectIntentIDs pairs source classes with the intent chains of the generated node, which keep the class order
+func (g *iflytekGeneration) collectIntentIDs(node models.Node, mapping *models.IDMapping) {
+	intents, exists := g.intentIDMapping[node.ID]
+	classifierConfig, ok := common.AsClassifierConfig(node.Config)
+	if !exists || !ok || classifierConfig == nil {
+		return
+	}
+
+	intentIndex := 0
+	hasDefaultClass := false
+	for _, class := range classifierConfig.Classes {
+		if class.IsDefault {
+			mapping.AddIntent(node.ID, class.ID, intents.DefaultIntentID)
+			hasDefaultClass = true
+			continue
+		}
+		if intentIndex < len(intents.IntentIDs) {
+			mapping.AddIntent(node.ID, class.ID, intents.IntentIDs[intentIndex])
+		}
+		intentIndex++
+	}
+
+	// Sources without an explicit default class (Dify) reach the generated default intent through "__default__"
+	if !hasDefaultClass {
+		mapping.AddIntent(node.ID, "__default__", intents.DefaultIntentID)
+	}
+}
+
+// recordGeneratedIDs captures the output and intent IDs of every mapped node from the final DSL
+func (g *iflytekGeneration) recordGeneratedIDs(iflytekDSL *IFlytekDSL) {
+	g.outputIDMapping = make(map[string]map[string]string)
+	g.intentIDMapping = make(map[string]*ClassifierMapping)
+	sourceIDs := reverseIDMapping(g.idMapping)
+
+	for _, node := range iflytekDSL.FlowData.Nodes {
+		sourceID, ok := sourceIDs[node.ID]
+		if !ok {
+			continue
+		}
+
+		if len(node.Data.Outputs) > 0 {
+			outputs := make(map[string]string, len(node.Data.Outputs))
+			for _, output := range node.Data.Outputs {
+				outputs[output.Name] = output.ID
+			}
+			g.outputIDMapping[sourceID] = outputs
+		}
+
+		if intentChains := g.getIntentChainsFromNode(node); intentChains != nil {
+			intentIDs, defaultIntentID := g.extractIntentIDsFromChains(intentChains)
+			g.intentIDMapping[sourceID] = &ClassifierMapping{
+				IntentIDs:       intentIDs,
+				DefaultIntentID: defaultIntentID,
+			}
+		}
+	}
+}
+
+// reverseIDMapping builds the iFlytek node ID -> source node ID lookup
+func reverseIDMapping(idMapping map[string]string) map[string]string {
+	reversed := make(map[string]string, len(idMapping))
+	for sourceID, iflytekID := range idMapping {
+		reversed[iflytekID] = sourceID
+	}
+	return reversed
+}
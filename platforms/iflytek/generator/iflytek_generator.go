@@ -1,12 +1,16 @@
 package generator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/iflytek/agentbridge/core/interfaces"
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
+	"github.com/iflytek/agentbridge/platforms/iflytek/dslversion"
+	"strconv"
 	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -26,6 +30,10 @@ const (
 
 // compile-time interface verification
 var _ interfaces.DSLGenerator = (*IFlytekGenerator)(nil)
+var _ interfaces.ConfigurableGenerator = (*IFlytekGenerator)(nil)
+var _ interfaces.MappingProvider = (*IFlytekGenerator)(nil)
+var _ interfaces.MappingGenerator = (*IFlytekGenerator)(nil)
+var _ interfaces.ContextGenerator = (*IFlytekGenerator)(nil)
 
 // BranchMapping contains branch mapping information
 type BranchMapping struct {
@@ -42,9 +50,29 @@ type ClassifierMapping struct {
 	ClassIDToIntentID map[string]string // Complete mapping from class ID/number handle to intent ID
 }
 
-// IFlytekGenerator iFlytek Agent DSL generator
+// IFlytekGenerator iFlytek Agent DSL generator. It holds the configuration only; every generation
+// runs on its own iflytekGeneration, so once configured an instance can be reused and shared by
+// goroutines. Configure and the setters must not be called while generations are running.
 type IFlytekGenerator struct {
 	*common.BaseGenerator
+	settings iflytekSettings
+
+	mu          sync.Mutex
+	lastMapping *models.IDMapping // ID mapping of the last finished generation
+}
+
+// iflytekSettings holds the configuration copied into every generation
+type iflytekSettings struct {
+	previousMapping *models.IDMapping // ID mapping of an earlier conversion whose IDs are reused
+	targetVersion   string            // Requested DSL version, negotiated on generation
+	credentials     SparkCredentials  // Spark appId/uid written into node parameters
+	defaultIntent   string            // Target of the default intents added to Dify classifiers
+}
+
+// iflytekGeneration carries the state of a single conversion
+type iflytekGeneration struct {
+	iflytekSettings
+	ctx                     context.Context // Checked between generation rounds and nodes
 	factory                 *NodeGeneratorFactory
 	idMapping               map[string]string                   // Dify ID -> iFlytek SparkAgent ID mapping
 	nodeTitleMapping        map[string]string                   // iFlytek SparkAgent ID -> node title mapping
@@ -52,14 +80,32 @@ type IFlytekGenerator struct {
 	classifierIntentMapping map[string]*ClassifierMapping       // Classifier node ID -> intent mapping
 	classifierGenerators    map[string]*ClassifierNodeGenerator // Classifier generator cache
 	iterationSubNodeMapping map[string]map[string]string        // Iteration main node ID -> sub-node type -> sub-node ID mapping
+	outputIDMapping         map[string]map[string]string        // Source node ID -> output name -> output ID mapping
+	iterationBodyEdges      []models.Edge                       // Edges of iteration bodies kept in the sub-workflow only
+	intentIDMapping         map[string]*ClassifierMapping       // Source node ID -> generated intent IDs
+	reusedIDs               map[string]string                   // Generated ID -> reused previous ID
 	currentDSL              *models.UnifiedDSL                  // Current DSL being processed
 	sourcePlatform          models.PlatformType                 // Source platform identification
+	versionProfile          *dslversion.Profile                 // Negotiated DSL version profile
 }
 
 func NewIFlytekGenerator() *IFlytekGenerator {
 	return &IFlytekGenerator{
-		BaseGenerator:           common.NewBaseGenerator(models.PlatformIFlytek),
-		factory:                 NewNodeGeneratorFactory(),
+		BaseGenerator: common.NewBaseGenerator(models.PlatformIFlytek),
+		settings: iflytekSettings{
+			credentials: ResolveSparkCredentials("", ""),
+		},
+		lastMapping: models.NewIDMapping(),
+	}
+}
+
+func newIFlytekGeneration(ctx context.Context, settings iflytekSettings) *iflytekGeneration {
+	factory := NewNodeGeneratorFactory()
+	factory.SetCredentials(settings.credentials)
+	return &iflytekGeneration{
+		iflytekSettings:         settings,
+		ctx:                     ctx,
+		factory:                 factory,
 		idMapping:               make(map[string]string),
 		nodeTitleMapping:        make(map[string]string),
 		conditionBranchMapping:  make(map[string]*BranchMapping),
@@ -69,18 +115,95 @@ func NewIFlytekGenerator() *IFlytekGenerator {
 	}
 }
 
+// Configure applies conversion options to the generator
+func (g *IFlytekGenerator) Configure(options *models.ConversionOptions) error {
+	if options == nil {
+		return nil
+	}
+	if options.OutputFormat == models.OutputFormatJSON {
+		return fmt.Errorf("iFlytek Spark imports YAML DSL only, use output format %s", models.OutputFormatYAML)
+	}
+	g.SetCredentials(ResolveSparkCredentials(options.IFlytekAppID, options.IFlytekUID))
+	g.settings.previousMapping = options.PreviousMapping
+	g.settings.defaultIntent = options.DefaultIntent
+	return g.SetTargetVersion(options.TargetVersion)
+}
+
+// GetNodeIDMapping returns the source node ID -> iFlytek SparkAgent node ID mapping of the last generation
+func (g *IFlytekGenerator) GetNodeIDMapping() map[string]string {
+	return g.GetIDMapping().Nodes
+}
+
+// GetIDMapping returns the node, output, branch and intent ID mappings of the last generation.
+// Concurrent callers should use GenerateWithMapping instead.
+func (g *IFlytekGenerator) GetIDMapping() *models.IDMapping {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lastMapping.Clone()
+}
+
+// SetCredentials sets the Spark appId/uid written into generated node parameters
+func (g *IFlytekGenerator) SetCredentials(credentials SparkCredentials) {
+	g.settings.credentials = credentials
+}
+
+// SetTargetVersion requests a specific iFlytek SparkAgent DSL version; empty lets negotiation decide
+func (g *IFlytekGenerator) SetTargetVersion(version string) error {
+	if version != "" {
+		if _, err := dslversion.Lookup(version); err != nil {
+			return err
+		}
+	}
+	g.settings.targetVersion = version
+	return nil
+}
+
 // Generate generates iFlytek SparkAgent DSL from unified format
 func (g *IFlytekGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, error) {
+	data, _, err := g.GenerateWithMapping(unifiedDSL)
+	return data, err
+}
+
+// GenerateWithMapping generates iFlytek SparkAgent DSL and returns the ID mapping of this generation
+func (g *IFlytekGenerator) GenerateWithMapping(unifiedDSL *models.UnifiedDSL) ([]byte, *models.IDMapping, error) {
+	return g.GenerateWithContext(context.Background(), unifiedDSL)
+}
+
+// GenerateWithContext generates like GenerateWithMapping and aborts with the context error once ctx is done
+func (g *IFlytekGenerator) GenerateWithContext(ctx context.Context, unifiedDSL *models.UnifiedDSL) ([]byte, *models.IDMapping, error) {
+	// Validate input
+	if err := g.Validate(unifiedDSL); err != nil {
+		return nil, nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	generation := newIFlytekGeneration(ctx, g.settings)
+	data, err := generation.generate(unifiedDSL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mapping := generation.buildIDMapping()
+	g.mu.Lock()
+	g.lastMapping = mapping.Clone()
+	g.mu.Unlock()
+
+	return data, mapping, nil
+}
+
+// generate builds the iFlytek SparkAgent DSL of a validated unified DSL
+func (g *iflytekGeneration) generate(unifiedDSL *models.UnifiedDSL) ([]byte, error) {
 	// Store DSL for use in generators
 	g.currentDSL = unifiedDSL
 
 	// Identify source platform
 	g.sourcePlatform = g.identifySourcePlatform(unifiedDSL)
 
-	// Validate input
-	if err := g.Validate(unifiedDSL); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+	// Negotiate output DSL version
+	profile, err := g.negotiateVersion(unifiedDSL)
+	if err != nil {
+		return nil, err
 	}
+	g.versionProfile = profile
 
 	// Build iFlytek SparkAgent DSL
 	iflytekDSL := IFlytekDSL{
@@ -98,6 +221,13 @@ func (g *IFlytekGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, erro
 		return nil, fmt.Errorf("failed to generate nodes: %w", err)
 	}
 
+	if err := g.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Keep iteration sub-nodes apart and grow their containers to hold them
+	g.layoutIterationBodies(&iflytekDSL)
+
 	// Before generating edges, first analyze classifier target node mapping
 	g.analyzeClassifierTargets(unifiedDSL.Workflow.Edges)
 
@@ -105,24 +235,66 @@ func (g *IFlytekGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, erro
 	if err := g.generateEdges(unifiedDSL.Workflow.Edges, &iflytekDSL); err != nil {
 		return nil, fmt.Errorf("failed to generate edges: %w", err)
 	}
+	if err := g.generateEdges(g.missingIterationBodyEdges(&iflytekDSL), &iflytekDSL); err != nil {
+		return nil, fmt.Errorf("failed to generate iteration edges: %w", err)
+	}
 
-	// Add default intent connections ONLY for Dify to Spark conversion (let default intent connect to first intent's target node)
-	// Skip this for Coze platform as it already has proper default intent mapping
+	// Connect the default intents added to Dify classifiers, which have none; Coze classifiers
+	// already have a default branch
 	if g.sourcePlatform == models.PlatformDify {
-		g.generateDefaultIntentEdges(unifiedDSL.Workflow.Edges, &iflytekDSL)
+		g.generateDefaultIntentEdges(&unifiedDSL.Workflow, &iflytekDSL)
+	}
+
+	// Catch edges whose branch handle mapping failed before Spark imports them
+	if err := g.checkEdgeHandles(&iflytekDSL); err != nil {
+		return nil, fmt.Errorf("inconsistent edge handles: %w", err)
+	}
+
+	if err := g.ctx.Err(); err != nil {
+		return nil, err
 	}
 
+	// Adapt node parameters to the negotiated DSL version
+	g.applyVersionProfile(&iflytekDSL)
+
+	// Keep generated output and intent IDs for the ID mapping export
+	g.recordGeneratedIDs(&iflytekDSL)
+
+	// Emit source keys the parser did not model
+	g.restoreUnknownFields(unifiedDSL, &iflytekDSL)
+
 	// Serialize to YAML
 	data, err := yaml.Marshal(iflytekDSL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
 	}
 
+	// Keep target IDs stable across repeated conversions
+	data = g.reusePreviousIDs(data)
+
 	return data, nil
 }
 
+// negotiateVersion picks the output DSL version from the request and the source metadata
+func (g *iflytekGeneration) negotiateVersion(unifiedDSL *models.UnifiedDSL) (*dslversion.Profile, error) {
+	sourceVersion := ""
+	if unifiedDSL.PlatformMetadata.IFlytek != nil {
+		sourceVersion = unifiedDSL.PlatformMetadata.IFlytek.DSLVersion
+	}
+	return dslversion.Negotiate(g.targetVersion, sourceVersion)
+}
+
+// applyVersionProfile stamps the negotiated version and upgrades node parameters to it
+func (g *iflytekGeneration) applyVersionProfile(iflytekDSL *IFlytekDSL) {
+	iflytekDSL.FlowMeta.DSLVersion = g.versionProfile.Version
+	for i := range iflytekDSL.FlowData.Nodes {
+		node := &iflytekDSL.FlowData.Nodes[i]
+		g.versionProfile.UpgradeNodeParam(node.Type, node.Data.NodeParam)
+	}
+}
+
 // identifySourcePlatform identifies the source platform from unified DSL
-func (g *IFlytekGenerator) identifySourcePlatform(unifiedDSL *models.UnifiedDSL) models.PlatformType {
+func (g *iflytekGeneration) identifySourcePlatform(unifiedDSL *models.UnifiedDSL) models.PlatformType {
 	// Check platform metadata for source platform identification
 	if unifiedDSL.PlatformMetadata.Coze != nil {
 		return models.PlatformCoze
@@ -143,7 +315,7 @@ func (g *IFlytekGenerator) identifySourcePlatform(unifiedDSL *models.UnifiedDSL)
 }
 
 // identifyFromNodePatterns identifies platform from node structure patterns
-func (g *IFlytekGenerator) identifyFromNodePatterns(unifiedDSL *models.UnifiedDSL) models.PlatformType {
+func (g *iflytekGeneration) identifyFromNodePatterns(unifiedDSL *models.UnifiedDSL) models.PlatformType {
 	// Look for platform-specific patterns in node configurations
 	for _, node := range unifiedDSL.Workflow.Nodes {
 		// Check for Coze-specific patterns
@@ -162,7 +334,7 @@ func (g *IFlytekGenerator) identifyFromNodePatterns(unifiedDSL *models.UnifiedDS
 }
 
 // hasCozePatterns checks for Coze-specific patterns in node configuration
-func (g *IFlytekGenerator) hasCozePatterns(node models.Node) bool {
+func (g *iflytekGeneration) hasCozePatterns(node models.Node) bool {
 	// Check for Coze-specific platform config
 	if node.PlatformConfig.Coze != nil && len(node.PlatformConfig.Coze) > 0 {
 		return true
@@ -183,7 +355,7 @@ func (g *IFlytekGenerator) hasCozePatterns(node models.Node) bool {
 }
 
 // hasDifyPatterns checks for Dify-specific patterns in node configuration
-func (g *IFlytekGenerator) hasDifyPatterns(node models.Node) bool {
+func (g *iflytekGeneration) hasDifyPatterns(node models.Node) bool {
 	// Check for Dify-specific platform config
 	if node.PlatformConfig.Dify != nil && len(node.PlatformConfig.Dify) > 0 {
 		return true
@@ -220,11 +392,14 @@ func (g *IFlytekGenerator) Validate(unifiedDSL *models.UnifiedDSL) error {
 }
 
 // generateFlowMeta generates flow metadata
-func (g *IFlytekGenerator) generateFlowMeta(unifiedDSL *models.UnifiedDSL) IFlytekFlowMeta {
+func (g *iflytekGeneration) generateFlowMeta(unifiedDSL *models.UnifiedDSL) IFlytekFlowMeta {
 	meta := IFlytekFlowMeta{
 		Name:        unifiedDSL.Metadata.Name,
 		Description: unifiedDSL.Metadata.Description,
-		DSLVersion:  "v1",
+		DSLVersion:  dslversion.Default,
+		Tags:        unifiedDSL.Metadata.Tags,
+		Author:      unifiedDSL.Metadata.Author,
+		Version:     unifiedDSL.Metadata.Version,
 	}
 
 	// Restore iFlytek Platform specific configurations
@@ -236,23 +411,23 @@ func (g *IFlytekGenerator) generateFlowMeta(unifiedDSL *models.UnifiedDSL) IFlyt
 		meta.DSLVersion = iflytekMeta.DSLVersion
 	} else {
 		// If no iFlytek specific configuration, generate from UI configuration
-		meta.AvatarColor = "#FFEAD5" // Default color
 		meta.AdvancedConfig = g.generateAdvancedConfig(unifiedDSL.Metadata.UIConfig)
 
-		// If there is Dify configuration, try to convert icon
-		if unifiedDSL.PlatformMetadata.Dify != nil && unifiedDSL.PlatformMetadata.Dify.Icon != "" {
-			meta.AvatarIcon = unifiedDSL.PlatformMetadata.Dify.Icon
-		} else {
-			// Use default hardcoded avatar icon for iFlytek platform
-			meta.AvatarIcon = "https://oss-beijing-m8.openstorage.cn/SparkBotProd/icon/common/emojiitem_00_10@2x.png"
+		// Carry the source icon over when iFlytek can show it
+		var icon, background string
+		if uiConfig := unifiedDSL.Metadata.UIConfig; uiConfig != nil {
+			icon, background = uiConfig.Icon, uiConfig.IconBackground
 		}
+		avatar := common.IconTranslatorFor(nil).Translate(icon, background, models.PlatformIFlytek)
+		meta.AvatarIcon = avatar.Icon
+		meta.AvatarColor = avatar.Background
 	}
 
 	return meta
 }
 
 // generateAdvancedConfig generates advanced configuration
-func (g *IFlytekGenerator) generateAdvancedConfig(uiConfig *models.UIConfig) string {
+func (g *iflytekGeneration) generateAdvancedConfig(uiConfig *models.UIConfig) string {
 	if uiConfig == nil {
 		return `{"prologue":{"enabled":true,"inputExample":["","",""]},"needGuide":false}`
 	}
@@ -265,7 +440,8 @@ func (g *IFlytekGenerator) generateAdvancedConfig(uiConfig *models.UIConfig) str
 	}
 
 	// If there is an opening statement or suggested questions, enable prologue
-	if uiConfig.OpeningStatement != "" || len(uiConfig.SuggestedQuestions) > 0 {
+	questions := common.NonBlankQuestions(uiConfig.SuggestedQuestions, common.SuggestedQuestionLimit(models.PlatformIFlytek))
+	if uiConfig.OpeningStatement != "" || len(questions) > 0 {
 		prologue := map[string]interface{}{
 			"enabled": true,
 		}
@@ -274,22 +450,11 @@ func (g *IFlytekGenerator) generateAdvancedConfig(uiConfig *models.UIConfig) str
 			prologue["statement"] = uiConfig.OpeningStatement
 		}
 
-		if len(uiConfig.SuggestedQuestions) > 0 {
-			// Max 3 suggested questions
-			questions := uiConfig.SuggestedQuestions
-			if len(questions) > 3 {
-				questions = questions[:3]
-			}
-
-			// Ensure there are 3 elements (fill with empty strings)
-			for len(questions) < 3 {
-				questions = append(questions, "")
-			}
-
-			prologue["inputExample"] = questions
-		} else {
-			prologue["inputExample"] = []string{"", "", ""}
+		// The prologue always shows three input examples; unused ones stay empty
+		for len(questions) < 3 {
+			questions = append(questions, "")
 		}
+		prologue["inputExample"] = questions
 
 		config["prologue"] = prologue
 	}
@@ -304,7 +469,7 @@ func (g *IFlytekGenerator) generateAdvancedConfig(uiConfig *models.UIConfig) str
 }
 
 // isIterationSubNode checks if a node is a sub-node within an iteration
-func (g *IFlytekGenerator) isIterationSubNode(node models.Node) bool {
+func (g *iflytekGeneration) isIterationSubNode(node models.Node) bool {
 	checkers := g.getIterationCheckFunctions()
 
 	if checker, exists := checkers[node.Type]; exists {
@@ -315,7 +480,7 @@ func (g *IFlytekGenerator) isIterationSubNode(node models.Node) bool {
 }
 
 // getIterationCheckFunctions returns node type to iteration check function mapping
-func (g *IFlytekGenerator) getIterationCheckFunctions() map[models.NodeType]func(models.Node) bool {
+func (g *iflytekGeneration) getIterationCheckFunctions() map[models.NodeType]func(models.Node) bool {
 	return map[models.NodeType]func(models.Node) bool{
 		models.NodeTypeStart:      g.checkStartNodeIteration,
 		models.NodeTypeCode:       g.checkCodeNodeIteration,
@@ -326,7 +491,7 @@ func (g *IFlytekGenerator) getIterationCheckFunctions() map[models.NodeType]func
 }
 
 // checkStartNodeIteration checks if start node is in iteration
-func (g *IFlytekGenerator) checkStartNodeIteration(node models.Node) bool {
+func (g *iflytekGeneration) checkStartNodeIteration(node models.Node) bool {
 	if startConfig, ok := common.AsStartConfig(node.Config); ok && startConfig != nil {
 		return startConfig.IsInIteration
 	}
@@ -334,7 +499,7 @@ func (g *IFlytekGenerator) checkStartNodeIteration(node models.Node) bool {
 }
 
 // checkCodeNodeIteration checks if code node is in iteration
-func (g *IFlytekGenerator) checkCodeNodeIteration(node models.Node) bool {
+func (g *iflytekGeneration) checkCodeNodeIteration(node models.Node) bool {
 	if codeConfig, ok := common.AsCodeConfig(node.Config); ok && codeConfig != nil {
 		return codeConfig.IsInIteration
 	}
@@ -342,7 +507,7 @@ func (g *IFlytekGenerator) checkCodeNodeIteration(node models.Node) bool {
 }
 
 // checkLLMNodeIteration checks if LLM node is in iteration
-func (g *IFlytekGenerator) checkLLMNodeIteration(node models.Node) bool {
+func (g *iflytekGeneration) checkLLMNodeIteration(node models.Node) bool {
 	if llmConfig, ok := common.AsLLMConfig(node.Config); ok && llmConfig != nil {
 		return llmConfig.IsInIteration
 	}
@@ -350,7 +515,7 @@ func (g *IFlytekGenerator) checkLLMNodeIteration(node models.Node) bool {
 }
 
 // checkConditionNodeIteration checks if condition node is in iteration
-func (g *IFlytekGenerator) checkConditionNodeIteration(node models.Node) bool {
+func (g *iflytekGeneration) checkConditionNodeIteration(node models.Node) bool {
 	if conditionConfig, ok := common.AsConditionConfig(node.Config); ok && conditionConfig != nil {
 		return conditionConfig.IsInIteration
 	}
@@ -358,7 +523,7 @@ func (g *IFlytekGenerator) checkConditionNodeIteration(node models.Node) bool {
 }
 
 // checkClassifierNodeIteration checks if classifier node is in iteration
-func (g *IFlytekGenerator) checkClassifierNodeIteration(node models.Node) bool {
+func (g *iflytekGeneration) checkClassifierNodeIteration(node models.Node) bool {
 	if classifierConfig, ok := common.AsClassifierConfig(node.Config); ok && classifierConfig != nil {
 		return classifierConfig.IsInIteration
 	}
@@ -366,70 +531,67 @@ func (g *IFlytekGenerator) checkClassifierNodeIteration(node models.Node) bool {
 }
 
 // establishIterationSubNodeMappings establishes ID mappings for iteration sub-nodes
-func (g *IFlytekGenerator) establishIterationSubNodeMappings(iterationNode models.Node, generatedSubNodes []IFlytekNode, originalSubNodes []models.Node) {
-	// Establish mapping for the original iteration start node
+func (g *iflytekGeneration) establishIterationSubNodeMappings(iterationNode models.Node, generatedSubNodes []IFlytekNode, originalSubNodes []models.Node) {
+	// Establish mappings for the original iteration start and end nodes
 	for _, originalNode := range originalSubNodes {
-		if originalNode.Type == models.NodeTypeStart {
-			// Find the generated iteration start node (the first one, and its ID starts with iteration-node-start::)
-			for _, generatedNode := range generatedSubNodes {
-				if strings.HasPrefix(generatedNode.ID, "iteration-node-start::") {
-					g.idMapping[originalNode.ID] = generatedNode.ID
-					g.nodeTitleMapping[generatedNode.ID] = originalNode.Title
-					break
-				}
+		kind := NodeKindIterationStart
+		if originalNode.Type == models.NodeTypeEnd {
+			kind = NodeKindIterationEnd
+		} else if originalNode.Type != models.NodeTypeStart {
+			continue
+		}
+		// Find the generated node of the same kind by its ID prefix
+		for _, generatedNode := range generatedSubNodes {
+			if generatedNode.Kind() == kind {
+				g.idMapping[originalNode.ID] = generatedNode.ID
+				g.nodeTitleMapping[generatedNode.ID] = originalNode.Title
+				break
 			}
 		}
 	}
 
 	// Establish mappings for other iteration sub-nodes (code nodes, LLM nodes, etc.)
-	for _, originalNode := range originalSubNodes {
-		if originalNode.Type != models.NodeTypeStart {
-			// Match generated nodes based on node type and title
-			for _, generatedNode := range generatedSubNodes {
-				if g.isMatchingIterationSubNode(originalNode, generatedNode) {
-					g.idMapping[originalNode.ID] = generatedNode.ID
-					g.nodeTitleMapping[generatedNode.ID] = originalNode.Title
-					break
-				}
-			}
+	for _, generatedNode := range generatedSubNodes {
+		if originalNode := findIterationSubNodeSource(originalSubNodes, generatedNode); originalNode != nil {
+			g.idMapping[originalNode.ID] = generatedNode.ID
+			g.nodeTitleMapping[generatedNode.ID] = originalNode.Title
 		}
 	}
 }
 
-// isMatchingIterationSubNode checks if an original node and a generated node match
-func (g *IFlytekGenerator) isMatchingIterationSubNode(originalNode models.Node, generatedNode IFlytekNode) bool {
-	// Match based on node type
-	switch originalNode.Type {
-	case models.NodeTypeCode:
-		return strings.HasPrefix(generatedNode.ID, "ifly-code::") &&
-			generatedNode.Data.Label == originalNode.Title
-	case models.NodeTypeLLM:
-		return strings.HasPrefix(generatedNode.ID, "spark-llm::") &&
-			generatedNode.Data.Label == originalNode.Title
-	case models.NodeTypeCondition:
-		return strings.HasPrefix(generatedNode.ID, "if-else::") &&
-			generatedNode.Data.Label == originalNode.Title
-	case models.NodeTypeClassifier:
-		return strings.HasPrefix(generatedNode.ID, "decision-making::") &&
-			generatedNode.Data.Label == originalNode.Title
-	default:
-		return false
+// findIterationSubNodeSource returns the original node a generated iteration sub-node was generated from.
+// Matching on IDs keeps sub-nodes with the same title apart.
+func findIterationSubNodeSource(originalSubNodes []models.Node, generatedNode IFlytekNode) *models.Node {
+	if generatedNode.sourceID == "" {
+		return nil
+	}
+	for i := range originalSubNodes {
+		if originalSubNodes[i].ID == generatedNode.sourceID {
+			return &originalSubNodes[i]
+		}
 	}
+	return nil
 }
 
 // generateNodes generates nodes
-func (g *IFlytekGenerator) generateNodes(nodes []models.Node, iflytekDSL *IFlytekDSL) error {
+func (g *iflytekGeneration) generateNodes(nodes []models.Node, iflytekDSL *IFlytekDSL) error {
 	// First round: generate all nodes and establish ID mappings
 	if err := g.performFirstRoundNodeGeneration(nodes, iflytekDSL); err != nil {
 		return err
 	}
 
 	// Second round: regenerate nodes with references
+	if err := g.ctx.Err(); err != nil {
+		return err
+	}
 	if err := g.performSecondRoundRegeneration(nodes, iflytekDSL); err != nil {
 		return err
 	}
 
 	// Third round: final refinement of node references
+	if err := g.ctx.Err(); err != nil {
+		return err
+	}
 	if err := g.performThirdRoundRefinement(nodes, iflytekDSL); err != nil {
 		return err
 	}
@@ -438,11 +600,15 @@ func (g *IFlytekGenerator) generateNodes(nodes []models.Node, iflytekDSL *IFlyte
 }
 
 // performFirstRoundNodeGeneration handles the first round of node generation
-func (g *IFlytekGenerator) performFirstRoundNodeGeneration(nodes []models.Node, iflytekDSL *IFlytekDSL) error {
-	for _, node := range nodes {
+func (g *iflytekGeneration) performFirstRoundNodeGeneration(nodes []models.Node, iflytekDSL *IFlytekDSL) error {
+	for i, node := range nodes {
 		if g.isIterationSubNode(node) {
 			continue
 		}
+		if err := g.ctx.Err(); err != nil {
+			return err
+		}
+		models.ReportNodeProgress(g.ctx, models.ProgressStageGenerate, i, len(nodes), node.ID, node.Title)
 
 		if err := g.generateAndProcessSingleNode(node, nodes, iflytekDSL); err != nil {
 			return err
@@ -452,7 +618,7 @@ func (g *IFlytekGenerator) performFirstRoundNodeGeneration(nodes []models.Node,
 }
 
 // generateAndProcessSingleNode generates a single node and handles its special processing
-func (g *IFlytekGenerator) generateAndProcessSingleNode(node models.Node, allNodes []models.Node, iflytekDSL *IFlytekDSL) error {
+func (g *iflytekGeneration) generateAndProcessSingleNode(node models.Node, allNodes []models.Node, iflytekDSL *IFlytekDSL) error {
 	// Generate basic node
 	generator, err := g.factory.GetGenerator(node.Type)
 	if err != nil {
@@ -485,13 +651,13 @@ func (g *IFlytekGenerator) generateAndProcessSingleNode(node models.Node, allNod
 }
 
 // establishNodeMappings establishes basic node mappings
-func (g *IFlytekGenerator) establishNodeMappings(node models.Node, iflytekNode IFlytekNode) {
+func (g *iflytekGeneration) establishNodeMappings(node models.Node, iflytekNode IFlytekNode) {
 	g.idMapping[node.ID] = iflytekNode.ID
 	g.nodeTitleMapping[iflytekNode.ID] = node.Title
 }
 
 // handleNodeTypeSpecificProcessing handles processing specific to different node types
-func (g *IFlytekGenerator) handleNodeTypeSpecificProcessing(node models.Node, iflytekNode IFlytekNode, allNodes []models.Node, generator NodeGenerator, iflytekDSL *IFlytekDSL) error {
+func (g *iflytekGeneration) handleNodeTypeSpecificProcessing(node models.Node, iflytekNode IFlytekNode, allNodes []models.Node, generator NodeGenerator, iflytekDSL *IFlytekDSL) error {
 	switch node.Type {
 	case models.NodeTypeCondition:
 		g.handleConditionNodeSpecialProcessing(generator, iflytekNode)
@@ -504,7 +670,7 @@ func (g *IFlytekGenerator) handleNodeTypeSpecificProcessing(node models.Node, if
 }
 
 // handleConditionNodeSpecialProcessing handles condition node special processing
-func (g *IFlytekGenerator) handleConditionNodeSpecialProcessing(generator NodeGenerator, iflytekNode IFlytekNode) {
+func (g *iflytekGeneration) handleConditionNodeSpecialProcessing(generator NodeGenerator, iflytekNode IFlytekNode) {
 	if conditionGen, ok := generator.(*ConditionNodeGenerator); ok {
 		// Get branch ID mapping from the condition generator
 		branchIDMapping := conditionGen.GetBranchIDMapping()
@@ -528,7 +694,7 @@ func (g *IFlytekGenerator) handleConditionNodeSpecialProcessing(generator NodeGe
 }
 
 // setCompatibilityMappings sets backward compatibility mappings for true/false handles
-func (g *IFlytekGenerator) setCompatibilityMappings(mapping *BranchMapping, branchIDMapping map[string]string) {
+func (g *iflytekGeneration) setCompatibilityMappings(mapping *BranchMapping, branchIDMapping map[string]string) {
 	// Set TrueBranchID and FalseBranchID for backward compatibility
 	if branchID, exists := branchIDMapping["1"]; exists {
 		mapping.TrueBranchID = branchID
@@ -542,7 +708,7 @@ func (g *IFlytekGenerator) setCompatibilityMappings(mapping *BranchMapping, bran
 }
 
 // handleClassifierNodeSpecialProcessing handles classifier node special processing
-func (g *IFlytekGenerator) handleClassifierNodeSpecialProcessing(generator NodeGenerator, iflytekNode IFlytekNode) {
+func (g *iflytekGeneration) handleClassifierNodeSpecialProcessing(generator NodeGenerator, iflytekNode IFlytekNode) {
 	if classifierGen, ok := generator.(*ClassifierNodeGenerator); ok {
 		g.classifierGenerators[iflytekNode.ID] = classifierGen
 	}
@@ -550,14 +716,20 @@ func (g *IFlytekGenerator) handleClassifierNodeSpecialProcessing(generator NodeG
 }
 
 // handleIterationNodeSpecialProcessing handles iteration node special processing
-func (g *IFlytekGenerator) handleIterationNodeSpecialProcessing(node models.Node, iflytekNode IFlytekNode, allNodes []models.Node, generator NodeGenerator, iflytekDSL *IFlytekDSL) error {
+func (g *iflytekGeneration) handleIterationNodeSpecialProcessing(node models.Node, iflytekNode IFlytekNode, allNodes []models.Node, generator NodeGenerator, iflytekDSL *IFlytekDSL) error {
 	iterationGen, ok := generator.(*IterationNodeGenerator)
 	if !ok {
 		return nil
 	}
 
-	// Find iteration sub-nodes
+	// Find iteration sub-nodes; iFlytek sources keep them in the sub-workflow only
 	iterationSubNodes := g.findIterationSubNodes(allNodes, node.ID)
+	if len(iterationSubNodes) == 0 {
+		if iterConfig, ok := common.AsIterationConfig(node.Config); ok && iterConfig != nil {
+			iterationSubNodes = iterConfig.SubWorkflow.Nodes
+			g.iterationBodyEdges = append(g.iterationBodyEdges, iterConfig.SubWorkflow.Edges...)
+		}
+	}
 
 	// Extract iteration start node ID
 	iterationStartNodeID := g.extractIterationStartNodeID(iflytekNode)
@@ -577,7 +749,7 @@ func (g *IFlytekGenerator) handleIterationNodeSpecialProcessing(node models.Node
 }
 
 // extractIterationStartNodeID extracts iteration start node ID from node parameters
-func (g *IFlytekGenerator) extractIterationStartNodeID(iflytekNode IFlytekNode) string {
+func (g *iflytekGeneration) extractIterationStartNodeID(iflytekNode IFlytekNode) string {
 	if nodeParam, ok := iflytekNode.Data.NodeParam["IterationStartNodeId"].(string); ok {
 		return nodeParam
 	}
@@ -585,19 +757,19 @@ func (g *IFlytekGenerator) extractIterationStartNodeID(iflytekNode IFlytekNode)
 }
 
 // addIterationComponentsToDSL adds iteration sub-nodes and edges to DSL
-func (g *IFlytekGenerator) addIterationComponentsToDSL(iflytekDSL *IFlytekDSL, subNodes []IFlytekNode, iterationEdges []IFlytekEdge) {
+func (g *iflytekGeneration) addIterationComponentsToDSL(iflytekDSL *IFlytekDSL, subNodes []IFlytekNode, iterationEdges []IFlytekEdge) {
 	iflytekDSL.FlowData.Nodes = append(iflytekDSL.FlowData.Nodes, subNodes...)
 	iflytekDSL.FlowData.Edges = append(iflytekDSL.FlowData.Edges, iterationEdges...)
 }
 
 // registerIterationGeneratorsForSubNodes registers generators for iteration sub-nodes
-func (g *IFlytekGenerator) registerIterationGeneratorsForSubNodes(iterationSubNodes []models.Node, subNodes []IFlytekNode) {
+func (g *iflytekGeneration) registerIterationGeneratorsForSubNodes(iterationSubNodes []models.Node, subNodes []IFlytekNode) {
 	g.registerIterationSubNodeClassifiers(iterationSubNodes, subNodes)
 	g.registerIterationSubNodeConditions(iterationSubNodes, subNodes)
 }
 
 // performSecondRoundRegeneration handles the second round of node regeneration
-func (g *IFlytekGenerator) performSecondRoundRegeneration(nodes []models.Node, iflytekDSL *IFlytekDSL) error {
+func (g *iflytekGeneration) performSecondRoundRegeneration(nodes []models.Node, iflytekDSL *IFlytekDSL) error {
 	// Set up mappings in factory
 	g.factory.SetIDMapping(g.idMapping)
 	g.factory.SetNodeTitleMapping(g.nodeTitleMapping)
@@ -619,12 +791,12 @@ func (g *IFlytekGenerator) performSecondRoundRegeneration(nodes []models.Node, i
 }
 
 // shouldSkipNodeInSecondRound determines if a node should be skipped in second round
-func (g *IFlytekGenerator) shouldSkipNodeInSecondRound(node models.Node) bool {
+func (g *iflytekGeneration) shouldSkipNodeInSecondRound(node models.Node) bool {
 	return g.isIterationSubNode(node)
 }
 
 // regenerateNodeWithReferences regenerates a node with updated references
-func (g *IFlytekGenerator) regenerateNodeWithReferences(node models.Node, iflytekDSL *IFlytekDSL) error {
+func (g *iflytekGeneration) regenerateNodeWithReferences(node models.Node, iflytekDSL *IFlytekDSL) error {
 	generator, err := g.factory.GetGenerator(node.Type)
 	if err != nil {
 		return fmt.Errorf("failed to get generator for node %s: %w", node.ID, err)
@@ -645,7 +817,7 @@ func (g *IFlytekGenerator) regenerateNodeWithReferences(node models.Node, iflyte
 }
 
 // handleRegenerationSpecialProcessing handles special processing during regeneration
-func (g *IFlytekGenerator) handleRegenerationSpecialProcessing(node models.Node, iflytekNode IFlytekNode, generator NodeGenerator) {
+func (g *iflytekGeneration) handleRegenerationSpecialProcessing(node models.Node, iflytekNode IFlytekNode, generator NodeGenerator) {
 	switch node.Type {
 	case models.NodeTypeCondition:
 		g.handleConditionNodeSpecialProcessing(generator, iflytekNode)
@@ -658,7 +830,7 @@ func (g *IFlytekGenerator) handleRegenerationSpecialProcessing(node models.Node,
 }
 
 // replaceNodeInDSL replaces a node in the DSL by ID lookup
-func (g *IFlytekGenerator) replaceNodeInDSL(iflytekDSL *IFlytekDSL, iflytekNode IFlytekNode) {
+func (g *iflytekGeneration) replaceNodeInDSL(iflytekDSL *IFlytekDSL, iflytekNode IFlytekNode) {
 	for j, existingNode := range iflytekDSL.FlowData.Nodes {
 		if existingNode.ID == iflytekNode.ID {
 			iflytekDSL.FlowData.Nodes[j] = iflytekNode
@@ -668,10 +840,11 @@ func (g *IFlytekGenerator) replaceNodeInDSL(iflytekDSL *IFlytekDSL, iflytekNode
 }
 
 // performThirdRoundRefinement handles the third round of node refinement
-func (g *IFlytekGenerator) performThirdRoundRefinement(nodes []models.Node, iflytekDSL *IFlytekDSL) error {
+func (g *iflytekGeneration) performThirdRoundRefinement(nodes []models.Node, iflytekDSL *IFlytekDSL) error {
 	nodeTypesToRefine := []models.NodeType{
 		models.NodeTypeEnd, models.NodeTypeLLM, models.NodeTypeCondition,
-		models.NodeTypeCode, models.NodeTypeIteration,
+		models.NodeTypeCode, models.NodeTypeIteration, models.NodeTypeHumanInput,
+		models.NodeTypeTextToSpeech, models.NodeTypeSpeechToText,
 	}
 
 	for _, node := range nodes {
@@ -689,7 +862,7 @@ func (g *IFlytekGenerator) performThirdRoundRefinement(nodes []models.Node, ifly
 }
 
 // shouldSkipNodeInThirdRound determines if a node should be skipped in third round
-func (g *IFlytekGenerator) shouldSkipNodeInThirdRound(node models.Node, nodeTypesToRefine []models.NodeType) bool {
+func (g *iflytekGeneration) shouldSkipNodeInThirdRound(node models.Node, nodeTypesToRefine []models.NodeType) bool {
 	if g.isIterationSubNode(node) {
 		return true
 	}
@@ -703,7 +876,7 @@ func (g *IFlytekGenerator) shouldSkipNodeInThirdRound(node models.Node, nodeType
 }
 
 // refineNodeWithFinalReferences performs final refinement of node references
-func (g *IFlytekGenerator) refineNodeWithFinalReferences(node models.Node, iflytekDSL *IFlytekDSL) error {
+func (g *iflytekGeneration) refineNodeWithFinalReferences(node models.Node, iflytekDSL *IFlytekDSL) error {
 	generator, err := g.factory.GetGenerator(node.Type)
 	if err != nil {
 		return err
@@ -724,7 +897,7 @@ func (g *IFlytekGenerator) refineNodeWithFinalReferences(node models.Node, iflyt
 }
 
 // handleFinalRefinementMappings handles mappings during final refinement
-func (g *IFlytekGenerator) handleFinalRefinementMappings(node models.Node, iflytekNode IFlytekNode, generator NodeGenerator) {
+func (g *iflytekGeneration) handleFinalRefinementMappings(node models.Node, iflytekNode IFlytekNode, generator NodeGenerator) {
 	switch node.Type {
 	case models.NodeTypeCondition:
 		g.handleConditionNodeSpecialProcessing(generator, iflytekNode)
@@ -734,7 +907,7 @@ func (g *IFlytekGenerator) handleFinalRefinementMappings(node models.Node, iflyt
 }
 
 // registerIterationSubNodeClassifiers registers classifier generators for iteration sub-nodes
-func (g *IFlytekGenerator) registerIterationSubNodeClassifiers(originalSubNodes []models.Node, generatedSubNodes []IFlytekNode) {
+func (g *iflytekGeneration) registerIterationSubNodeClassifiers(originalSubNodes []models.Node, generatedSubNodes []IFlytekNode) {
 	for _, generatedNode := range generatedSubNodes {
 		if !g.isClassifierNode(generatedNode) {
 			continue
@@ -757,26 +930,23 @@ func (g *IFlytekGenerator) registerIterationSubNodeClassifiers(originalSubNodes
 	}
 }
 
-func (g *IFlytekGenerator) isClassifierNode(generatedNode IFlytekNode) bool {
-	return strings.HasPrefix(generatedNode.ID, "decision-making::")
+func (g *iflytekGeneration) isClassifierNode(generatedNode IFlytekNode) bool {
+	return generatedNode.Kind() == NodeKindClassifier
 }
 
-func (g *IFlytekGenerator) findMatchingOriginalClassifierNode(originalSubNodes []models.Node, generatedNode IFlytekNode) *models.Node {
-	for i := range originalSubNodes {
-		if originalSubNodes[i].Type == models.NodeTypeClassifier &&
-			originalSubNodes[i].Title == generatedNode.Data.Label {
-			return &originalSubNodes[i]
-		}
+func (g *iflytekGeneration) findMatchingOriginalClassifierNode(originalSubNodes []models.Node, generatedNode IFlytekNode) *models.Node {
+	if matchedNode := findIterationSubNodeSource(originalSubNodes, generatedNode); matchedNode != nil && matchedNode.Type == models.NodeTypeClassifier {
+		return matchedNode
 	}
 	return nil
 }
 
-func (g *IFlytekGenerator) establishClassifierNodeMapping(matchedNode *models.Node, generatedNode IFlytekNode) {
+func (g *iflytekGeneration) establishClassifierNodeMapping(matchedNode *models.Node, generatedNode IFlytekNode) {
 	g.idMapping[matchedNode.ID] = generatedNode.ID
 	g.nodeTitleMapping[generatedNode.ID] = matchedNode.Title
 }
 
-func (g *IFlytekGenerator) createClassifierGenerator() interface{} {
+func (g *iflytekGeneration) createClassifierGenerator() interface{} {
 	generator, err := g.factory.GetGenerator(models.NodeTypeClassifier)
 	if err != nil {
 		return nil
@@ -784,7 +954,7 @@ func (g *IFlytekGenerator) createClassifierGenerator() interface{} {
 	return generator
 }
 
-func (g *IFlytekGenerator) configureGeneratorMappings(generator interface{}) {
+func (g *iflytekGeneration) configureGeneratorMappings(generator interface{}) {
 	if mappingSetter, ok := generator.(interface{ SetIDMapping(map[string]string) }); ok {
 		mappingSetter.SetIDMapping(g.idMapping)
 	}
@@ -793,7 +963,7 @@ func (g *IFlytekGenerator) configureGeneratorMappings(generator interface{}) {
 	}
 }
 
-func (g *IFlytekGenerator) processClassifierNodeGeneration(generator interface{}, generatedNode IFlytekNode) {
+func (g *iflytekGeneration) processClassifierNodeGeneration(generator interface{}, generatedNode IFlytekNode) {
 	classifierGen, ok := generator.(*ClassifierNodeGenerator)
 	if !ok {
 		return
@@ -811,7 +981,7 @@ func (g *IFlytekGenerator) processClassifierNodeGeneration(generator interface{}
 	g.updateClassifierIntentMapping(generatedNode.ID, intentChains, classIDToIntentID)
 }
 
-func (g *IFlytekGenerator) extractIntentChainsFromNode(generatedNode IFlytekNode) []map[string]interface{} {
+func (g *iflytekGeneration) extractIntentChainsFromNode(generatedNode IFlytekNode) []map[string]interface{} {
 	nodeParam, ok := generatedNode.Data.NodeParam["intentChains"]
 	if !ok {
 		return nil
@@ -825,7 +995,7 @@ func (g *IFlytekGenerator) extractIntentChainsFromNode(generatedNode IFlytekNode
 	return intentChains
 }
 
-func (g *IFlytekGenerator) buildClassIDToIntentIDMapping(intentChains []map[string]interface{}) map[string]string {
+func (g *iflytekGeneration) buildClassIDToIntentIDMapping(intentChains []map[string]interface{}) map[string]string {
 	classIDToIntentID := make(map[string]string)
 
 	for i, intentChain := range intentChains {
@@ -848,7 +1018,7 @@ func (g *IFlytekGenerator) buildClassIDToIntentIDMapping(intentChains []map[stri
 	return classIDToIntentID
 }
 
-func (g *IFlytekGenerator) updateClassifierIntentMapping(nodeID string, intentChains []map[string]interface{}, classIDToIntentID map[string]string) {
+func (g *iflytekGeneration) updateClassifierIntentMapping(nodeID string, intentChains []map[string]interface{}, classIDToIntentID map[string]string) {
 	if g.classifierIntentMapping == nil {
 		g.classifierIntentMapping = make(map[string]*ClassifierMapping)
 	}
@@ -863,7 +1033,7 @@ func (g *IFlytekGenerator) updateClassifierIntentMapping(nodeID string, intentCh
 	g.classifierIntentMapping[nodeID] = newMapping
 }
 
-func (g *IFlytekGenerator) extractIntentIDsFromChains(intentChains []map[string]interface{}) ([]string, string) {
+func (g *iflytekGeneration) extractIntentIDsFromChains(intentChains []map[string]interface{}) ([]string, string) {
 	var intentIDs []string
 	var defaultIntentID string
 
@@ -887,7 +1057,7 @@ func (g *IFlytekGenerator) extractIntentIDsFromChains(intentChains []map[string]
 }
 
 // registerIterationSubNodeConditions registers condition generators for iteration sub-nodes
-func (g *IFlytekGenerator) registerIterationSubNodeConditions(originalSubNodes []models.Node, generatedSubNodes []IFlytekNode) {
+func (g *iflytekGeneration) registerIterationSubNodeConditions(originalSubNodes []models.Node, generatedSubNodes []IFlytekNode) {
 	for _, generatedNode := range generatedSubNodes {
 		if !g.isConditionNode(generatedNode) {
 			continue
@@ -910,26 +1080,23 @@ func (g *IFlytekGenerator) registerIterationSubNodeConditions(originalSubNodes [
 	}
 }
 
-func (g *IFlytekGenerator) isConditionNode(generatedNode IFlytekNode) bool {
-	return strings.HasPrefix(generatedNode.ID, "if-else::")
+func (g *iflytekGeneration) isConditionNode(generatedNode IFlytekNode) bool {
+	return generatedNode.Kind() == NodeKindCondition
 }
 
-func (g *IFlytekGenerator) findMatchingOriginalConditionNode(originalSubNodes []models.Node, generatedNode IFlytekNode) *models.Node {
-	for i := range originalSubNodes {
-		if originalSubNodes[i].Type == models.NodeTypeCondition &&
-			originalSubNodes[i].Title == generatedNode.Data.Label {
-			return &originalSubNodes[i]
-		}
+func (g *iflytekGeneration) findMatchingOriginalConditionNode(originalSubNodes []models.Node, generatedNode IFlytekNode) *models.Node {
+	if matchedNode := findIterationSubNodeSource(originalSubNodes, generatedNode); matchedNode != nil && matchedNode.Type == models.NodeTypeCondition {
+		return matchedNode
 	}
 	return nil
 }
 
-func (g *IFlytekGenerator) establishConditionNodeMapping(matchedNode *models.Node, generatedNode IFlytekNode) {
+func (g *iflytekGeneration) establishConditionNodeMapping(matchedNode *models.Node, generatedNode IFlytekNode) {
 	g.idMapping[matchedNode.ID] = generatedNode.ID
 	g.nodeTitleMapping[generatedNode.ID] = matchedNode.Title
 }
 
-func (g *IFlytekGenerator) createConditionGenerator() interface{} {
+func (g *iflytekGeneration) createConditionGenerator() interface{} {
 	generator, err := g.factory.GetGenerator(models.NodeTypeCondition)
 	if err != nil {
 		return nil
@@ -937,7 +1104,7 @@ func (g *IFlytekGenerator) createConditionGenerator() interface{} {
 	return generator
 }
 
-func (g *IFlytekGenerator) processConditionNodeGeneration(generator interface{}, generatedNode IFlytekNode, matchedNode *models.Node) {
+func (g *iflytekGeneration) processConditionNodeGeneration(generator interface{}, generatedNode IFlytekNode, matchedNode *models.Node) {
 	conditionGen, ok := generator.(*ConditionNodeGenerator)
 	if !ok {
 		return
@@ -953,7 +1120,7 @@ func (g *IFlytekGenerator) processConditionNodeGeneration(generator interface{},
 	g.extractBranchMappingWithCaseIDs(generatedNode, branchIDMapping)
 }
 
-func (g *IFlytekGenerator) extractCasesFromNode(generatedNode IFlytekNode) []map[string]interface{} {
+func (g *iflytekGeneration) extractCasesFromNode(generatedNode IFlytekNode) []map[string]interface{} {
 	nodeParam, ok := generatedNode.Data.NodeParam["cases"]
 	if !ok {
 		return nil
@@ -967,7 +1134,7 @@ func (g *IFlytekGenerator) extractCasesFromNode(generatedNode IFlytekNode) []map
 	return cases
 }
 
-func (g *IFlytekGenerator) buildBranchIDMapping(cases []map[string]interface{}, matchedNode *models.Node) map[string]string {
+func (g *iflytekGeneration) buildBranchIDMapping(cases []map[string]interface{}, matchedNode *models.Node) map[string]string {
 	branchIDMapping := make(map[string]string)
 
 	condConfig, ok := common.AsConditionConfig(matchedNode.Config)
@@ -981,7 +1148,7 @@ func (g *IFlytekGenerator) buildBranchIDMapping(cases []map[string]interface{},
 	return branchIDMapping
 }
 
-func (g *IFlytekGenerator) buildOriginalCaseIDToIndexMapping(condConfig models.ConditionConfig) map[string]int {
+func (g *iflytekGeneration) buildOriginalCaseIDToIndexMapping(condConfig models.ConditionConfig) map[string]int {
 	originalCaseIDToIndex := make(map[string]int)
 	for i, caseItem := range condConfig.Cases {
 		originalCaseIDToIndex[caseItem.CaseID] = i
@@ -989,7 +1156,7 @@ func (g *IFlytekGenerator) buildOriginalCaseIDToIndexMapping(condConfig models.C
 	return originalCaseIDToIndex
 }
 
-func (g *IFlytekGenerator) extractBranchIDsFromCases(cases []map[string]interface{}, branchIDMapping map[string]string, condConfig models.ConditionConfig) {
+func (g *iflytekGeneration) extractBranchIDsFromCases(cases []map[string]interface{}, branchIDMapping map[string]string, condConfig models.ConditionConfig) {
 	for _, caseItem := range cases {
 		branchID, hasID := caseItem["id"].(string)
 		level, hasLevel := caseItem["level"].(int)
@@ -1006,12 +1173,12 @@ func (g *IFlytekGenerator) extractBranchIDsFromCases(cases []map[string]interfac
 	}
 }
 
-func (g *IFlytekGenerator) handleDefaultBranch(branchIDMapping map[string]string, branchID string) {
+func (g *iflytekGeneration) handleDefaultBranch(branchIDMapping map[string]string, branchID string) {
 	branchIDMapping["__default__"] = branchID
 	branchIDMapping["false"] = branchID
 }
 
-func (g *IFlytekGenerator) handleNormalBranch(branchIDMapping map[string]string, branchID string, level int, condConfig models.ConditionConfig) {
+func (g *iflytekGeneration) handleNormalBranch(branchIDMapping map[string]string, branchID string, level int, condConfig models.ConditionConfig) {
 	levelKey := fmt.Sprintf("%d", level)
 	branchIDMapping[levelKey] = branchID
 
@@ -1026,7 +1193,7 @@ func (g *IFlytekGenerator) handleNormalBranch(branchIDMapping map[string]string,
 }
 
 // generateEdges generates connection relationships
-func (g *IFlytekGenerator) generateEdges(edges []models.Edge, iflytekDSL *IFlytekDSL) error {
+func (g *iflytekGeneration) generateEdges(edges []models.Edge, iflytekDSL *IFlytekDSL) error {
 	for _, edge := range edges {
 		// Use mapped node IDs
 		sourceID := g.idMapping[edge.Source]
@@ -1045,11 +1212,8 @@ func (g *IFlytekGenerator) generateEdges(edges []models.Edge, iflytekDSL *IFlyte
 			if isIterationStartEdge, ok := edge.PlatformConfig.IFlytek["isIterationStartEdge"].(bool); ok && isIterationStartEdge {
 				// This edge should connect from iteration start node to target node
 				// sourceID at this point is already the mapped iFlytek iteration node ID
-				if sourceID != "" && strings.HasPrefix(sourceID, "iteration::") {
-					// Extract UUID and construct iteration start node ID
-					uuid := sourceID[len("iteration::"):]
-					iterationStartNodeID := "iteration-node-start::" + uuid
-					sourceID = iterationStartNodeID
+				if uuid, ok := nodeIDUUID(sourceID); ok && NodeKindIteration.Is(sourceID) {
+					sourceID = NodeKindIterationStart.NodeID(uuid)
 				}
 			}
 		}
@@ -1069,36 +1233,10 @@ func (g *IFlytekGenerator) generateEdges(edges []models.Edge, iflytekDSL *IFlyte
 			ID:           g.generateEdgeIDWithHandle(sourceID, sourceHandle, finalTargetID),
 		}
 
-		// Generate default arrow marker
-		iflytekEdge.MarkerEnd = &IFlytekMarkerEnd{
-			Color: "#275EFF",
-			Type:  "arrow",
-		}
-
-		// Generate edge data
-		iflytekEdge.Data = &IFlytekEdgeData{
-			EdgeType: "curve",
-		}
-
-		// Restore platform specific configurations
-		if edge.PlatformConfig.IFlytek != nil {
-			config := edge.PlatformConfig.IFlytek
-			// Handle MarkerEnd configuration
-			if markerEndData, ok := config["markerEnd"]; ok {
-				if markerEndMap, ok := markerEndData.(map[string]interface{}); ok {
-					color, _ := markerEndMap["color"].(string)
-					markerType, _ := markerEndMap["type"].(string)
-					iflytekEdge.MarkerEnd = &IFlytekMarkerEnd{
-						Color: color,
-						Type:  markerType,
-					}
-				}
-			}
-			// Handle EdgeType configuration
-			if edgeType, ok := config["edgeType"].(string); ok && edgeType != "" {
-				iflytekEdge.Data.EdgeType = edgeType
-			}
-		}
+		// Generate default arrow marker and curve, then apply the source look
+		iflytekEdge.MarkerEnd = g.createArrowMarkerEnd()
+		iflytekEdge.Data = g.createCurveEdgeData()
+		g.applyEdgeStyle(&iflytekEdge, edge.Style)
 
 		iflytekDSL.FlowData.Edges = append(iflytekDSL.FlowData.Edges, iflytekEdge)
 	}
@@ -1106,10 +1244,44 @@ func (g *IFlytekGenerator) generateEdges(edges []models.Edge, iflytekDSL *IFlyte
 	return nil
 }
 
+// missingIterationBodyEdges returns the sub-workflow edges of iteration bodies that the iteration
+// generator did not connect already
+func (g *iflytekGeneration) missingIterationBodyEdges(iflytekDSL *IFlytekDSL) []models.Edge {
+	connected := make(map[[2]string]bool, len(iflytekDSL.FlowData.Edges))
+	for _, edge := range iflytekDSL.FlowData.Edges {
+		connected[[2]string{edge.Source, edge.Target}] = true
+	}
+
+	var missing []models.Edge
+	for _, edge := range g.iterationBodyEdges {
+		if !connected[[2]string{g.idMapping[edge.Source], g.idMapping[edge.Target]}] {
+			missing = append(missing, edge)
+		}
+	}
+	return missing
+}
+
+// applyEdgeStyle overrides the default edge look with the set fields of style
+func (g *iflytekGeneration) applyEdgeStyle(iflytekEdge *IFlytekEdge, style *models.EdgeStyle) {
+	if style == nil {
+		return
+	}
+	if style.Path != "" {
+		iflytekEdge.Data.EdgeType = style.Path
+	}
+	if style.Color != "" {
+		iflytekEdge.MarkerEnd.Color = style.Color
+	}
+	if style.Marker != "" {
+		iflytekEdge.MarkerEnd.Type = style.Marker
+	}
+	iflytekEdge.Label = style.Label
+}
+
 // generateEdgeID generates iFlytek SparkAgent edge ID
 
 // generateEdgeIDWithHandle generates iFlytek SparkAgent edge ID with source handle
-func (g *IFlytekGenerator) generateEdgeIDWithHandle(sourceID, sourceHandle, targetID string) string {
+func (g *iflytekGeneration) generateEdgeIDWithHandle(sourceID, sourceHandle, targetID string) string {
 	if sourceHandle != "" && sourceHandle != "source" {
 		return fmt.Sprintf("reactflow__edge-%s%s-%s", sourceID, sourceHandle, targetID)
 	}
@@ -1117,7 +1289,7 @@ func (g *IFlytekGenerator) generateEdgeIDWithHandle(sourceID, sourceHandle, targ
 }
 
 // convertEdgeType converts connection type
-func (g *IFlytekGenerator) convertEdgeType(edgeType models.EdgeType) string {
+func (g *iflytekGeneration) convertEdgeType(edgeType models.EdgeType) string {
 	switch edgeType {
 	case models.EdgeTypeDefault:
 		return "customEdge"
@@ -1129,7 +1301,12 @@ func (g *IFlytekGenerator) convertEdgeType(edgeType models.EdgeType) string {
 }
 
 // convertSourceHandle converts source handle, handles special cases for branch nodes and classifier nodes
-func (g *IFlytekGenerator) convertSourceHandle(sourceHandle, sourceNodeID string) string {
+func (g *iflytekGeneration) convertSourceHandle(sourceHandle, sourceNodeID string) string {
+	// iFlytek nodes have no fail branch, its edges become plain successors
+	if sourceHandle == models.ErrorBranchHandle {
+		return ""
+	}
+
 	mappedSourceID := g.getMappedSourceNodeID(sourceNodeID)
 
 	if convertedHandle := g.handleStartNodeSource(sourceHandle); convertedHandle != "" {
@@ -1160,7 +1337,7 @@ func (g *IFlytekGenerator) convertSourceHandle(sourceHandle, sourceNodeID string
 }
 
 // getMappedSourceNodeID gets the mapped source node ID or returns original if not found
-func (g *IFlytekGenerator) getMappedSourceNodeID(sourceNodeID string) string {
+func (g *iflytekGeneration) getMappedSourceNodeID(sourceNodeID string) string {
 	mappedSourceID := g.idMapping[sourceNodeID]
 	if mappedSourceID == "" {
 		return sourceNodeID
@@ -1169,7 +1346,7 @@ func (g *IFlytekGenerator) getMappedSourceNodeID(sourceNodeID string) string {
 }
 
 // handleStartNodeSource handles start node source conversion
-func (g *IFlytekGenerator) handleStartNodeSource(sourceHandle string) string {
+func (g *iflytekGeneration) handleStartNodeSource(sourceHandle string) string {
 	if sourceHandle == "start" {
 		// For start node, return "source" as the handle, which is standard for iFlytek SparkAgent
 		return "source"
@@ -1178,7 +1355,7 @@ func (g *IFlytekGenerator) handleStartNodeSource(sourceHandle string) string {
 }
 
 // handleConditionBranchSource handles condition branch node source conversion
-func (g *IFlytekGenerator) handleConditionBranchSource(sourceHandle, mappedSourceID string) string {
+func (g *iflytekGeneration) handleConditionBranchSource(sourceHandle, mappedSourceID string) string {
 	if sourceHandle != "true" && sourceHandle != "false" {
 		return ""
 	}
@@ -1204,7 +1381,7 @@ func (g *IFlytekGenerator) handleConditionBranchSource(sourceHandle, mappedSourc
 }
 
 // tryDirectBranchMapping tries direct true/false branch mapping
-func (g *IFlytekGenerator) tryDirectBranchMapping(sourceHandle string, branchMapping *BranchMapping) string {
+func (g *iflytekGeneration) tryDirectBranchMapping(sourceHandle string, branchMapping *BranchMapping) string {
 	if sourceHandle == "true" && branchMapping.TrueBranchID != "" {
 		return branchMapping.TrueBranchID
 	}
@@ -1215,7 +1392,7 @@ func (g *IFlytekGenerator) tryDirectBranchMapping(sourceHandle string, branchMap
 }
 
 // handleMultiLevelConditionSource handles multi-level condition branch node source conversion
-func (g *IFlytekGenerator) handleMultiLevelConditionSource(sourceHandle, mappedSourceID string) string {
+func (g *iflytekGeneration) handleMultiLevelConditionSource(sourceHandle, mappedSourceID string) string {
 	if g.conditionBranchMapping == nil {
 		return ""
 	}
@@ -1239,7 +1416,7 @@ func (g *IFlytekGenerator) handleMultiLevelConditionSource(sourceHandle, mappedS
 }
 
 // isNumericHandle checks if the source handle is numeric (like "1", "2", "3", etc.)
-func (g *IFlytekGenerator) isNumericHandle(sourceHandle string) bool {
+func (g *iflytekGeneration) isNumericHandle(sourceHandle string) bool {
 	if len(sourceHandle) == 0 {
 		return false
 	}
@@ -1253,7 +1430,7 @@ func (g *IFlytekGenerator) isNumericHandle(sourceHandle string) bool {
 }
 
 // lookupNumericHandle looks up branch ID by numeric handle
-func (g *IFlytekGenerator) lookupNumericHandle(sourceHandle string, branchMapping *BranchMapping) string {
+func (g *iflytekGeneration) lookupNumericHandle(sourceHandle string, branchMapping *BranchMapping) string {
 	if branchID, exists := branchMapping.BranchIDs[sourceHandle]; exists {
 		return branchID
 	}
@@ -1261,7 +1438,7 @@ func (g *IFlytekGenerator) lookupNumericHandle(sourceHandle string, branchMappin
 }
 
 // handleClassifierNumberSource handles classifier number handles conversion
-func (g *IFlytekGenerator) handleClassifierNumberSource(sourceHandle, mappedSourceID string) string {
+func (g *iflytekGeneration) handleClassifierNumberSource(sourceHandle, mappedSourceID string) string {
 	classifierGen, exists := g.classifierGenerators[mappedSourceID]
 	if !exists {
 		return ""
@@ -1276,7 +1453,7 @@ func (g *IFlytekGenerator) handleClassifierNumberSource(sourceHandle, mappedSour
 }
 
 // lookupClassifierIntentID performs unified classifier intent ID lookup with default handling
-func (g *IFlytekGenerator) lookupClassifierIntentID(sourceHandle string, classIDToIntentID map[string]string) string {
+func (g *iflytekGeneration) lookupClassifierIntentID(sourceHandle string, classIDToIntentID map[string]string) string {
 	// Handle special case: "default" should map to "__default__" key
 	if sourceHandle == DefaultSourceHandle {
 		if intentID, found := classIDToIntentID[DefaultIntentKey]; found {
@@ -1293,7 +1470,7 @@ func (g *IFlytekGenerator) lookupClassifierIntentID(sourceHandle string, classID
 }
 
 // handleClassifierIntentMappingSource handles classifier intent mapping source conversion
-func (g *IFlytekGenerator) handleClassifierIntentMappingSource(sourceHandle, mappedSourceID string) string {
+func (g *iflytekGeneration) handleClassifierIntentMappingSource(sourceHandle, mappedSourceID string) string {
 	// First try to use classifierGenerators mapping (same as handleClassifierNumberSource)
 	if classifierGen, exists := g.classifierGenerators[mappedSourceID]; exists {
 		classIDToIntentID := classifierGen.GetClassIDToIntentIDMapping()
@@ -1320,7 +1497,7 @@ func (g *IFlytekGenerator) handleClassifierIntentMappingSource(sourceHandle, map
 }
 
 // tryClassIDToIntentIDMapping tries ClassIDToIntentID mapping
-func (g *IFlytekGenerator) tryClassIDToIntentIDMapping(sourceHandle string, classifierMapping *ClassifierMapping) string {
+func (g *iflytekGeneration) tryClassIDToIntentIDMapping(sourceHandle string, classifierMapping *ClassifierMapping) string {
 	if classifierMapping.ClassIDToIntentID == nil {
 		return ""
 	}
@@ -1328,31 +1505,17 @@ func (g *IFlytekGenerator) tryClassIDToIntentIDMapping(sourceHandle string, clas
 	return g.lookupClassifierIntentID(sourceHandle, classifierMapping.ClassIDToIntentID)
 }
 
-// tryPositionalIntentMapping tries positional intent mapping for numbered handles
-func (g *IFlytekGenerator) tryPositionalIntentMapping(sourceHandle string, classifierMapping *ClassifierMapping) string {
-	switch sourceHandle {
-	case "1":
-		if len(classifierMapping.IntentIDs) > 0 {
-			return classifierMapping.IntentIDs[0]
-		}
-	case "2":
-		if len(classifierMapping.IntentIDs) > 1 {
-			return classifierMapping.IntentIDs[1]
-		}
-	case "3":
-		if len(classifierMapping.IntentIDs) > 2 {
-			return classifierMapping.IntentIDs[2]
-		}
-	case "4":
-		if len(classifierMapping.IntentIDs) > 3 {
-			return classifierMapping.IntentIDs[3]
-		}
+// tryPositionalIntentMapping tries positional intent mapping for numbered handles ("1" is the first intent)
+func (g *iflytekGeneration) tryPositionalIntentMapping(sourceHandle string, classifierMapping *ClassifierMapping) string {
+	position, err := strconv.Atoi(sourceHandle)
+	if err != nil || position < 1 || position > len(classifierMapping.IntentIDs) {
+		return ""
 	}
-	return ""
+	return classifierMapping.IntentIDs[position-1]
 }
 
 // handleClassifierIntentSource handles classifier intent source conversion
-func (g *IFlytekGenerator) handleClassifierIntentSource(sourceHandle, mappedSourceID string) string {
+func (g *iflytekGeneration) handleClassifierIntentSource(sourceHandle, mappedSourceID string) string {
 	if g.classifierIntentMapping == nil {
 		return ""
 	}
@@ -1371,12 +1534,12 @@ func (g *IFlytekGenerator) handleClassifierIntentSource(sourceHandle, mappedSour
 }
 
 // ExtractBranchMapping implements BranchMappingExtractor interface
-func (g *IFlytekGenerator) ExtractBranchMapping(iflytekNode IFlytekNode) {
+func (g *iflytekGeneration) ExtractBranchMapping(iflytekNode IFlytekNode) {
 	g.extractBranchMapping(iflytekNode)
 }
 
 // extractBranchMapping extracts branch mapping from generated condition nodes
-func (g *IFlytekGenerator) extractBranchMapping(iflytekNode IFlytekNode) {
+func (g *iflytekGeneration) extractBranchMapping(iflytekNode IFlytekNode) {
 	// Extract cases from node parameter
 	cases := g.extractCasesFromNodeParam(iflytekNode.Data.NodeParam)
 	if cases == nil {
@@ -1391,7 +1554,7 @@ func (g *IFlytekGenerator) extractBranchMapping(iflytekNode IFlytekNode) {
 }
 
 // extractCasesFromNodeParam extracts cases from node parameters
-func (g *IFlytekGenerator) extractCasesFromNodeParam(nodeParam map[string]interface{}) []interface{} {
+func (g *iflytekGeneration) extractCasesFromNodeParam(nodeParam map[string]interface{}) []interface{} {
 	if nodeParam == nil {
 		return nil
 	}
@@ -1405,7 +1568,7 @@ func (g *IFlytekGenerator) extractCasesFromNodeParam(nodeParam map[string]interf
 }
 
 // convertCasesToInterfaceSlice converts cases to []interface{} format
-func (g *IFlytekGenerator) convertCasesToInterfaceSlice(casesInterface interface{}) []interface{} {
+func (g *iflytekGeneration) convertCasesToInterfaceSlice(casesInterface interface{}) []interface{} {
 	// Try to convert to []interface{} type
 	if casesList, ok := casesInterface.([]interface{}); ok {
 		return casesList
@@ -1424,7 +1587,7 @@ func (g *IFlytekGenerator) convertCasesToInterfaceSlice(casesInterface interface
 }
 
 // createBranchMappingFromCases creates branch mapping from cases
-func (g *IFlytekGenerator) createBranchMappingFromCases(cases []interface{}) *BranchMapping {
+func (g *iflytekGeneration) createBranchMappingFromCases(cases []interface{}) *BranchMapping {
 	mapping := &BranchMapping{
 		BranchIDs: make(map[string]string),
 	}
@@ -1437,7 +1600,7 @@ func (g *IFlytekGenerator) createBranchMappingFromCases(cases []interface{}) *Br
 }
 
 // processCaseForBranchMapping processes a single case for branch mapping
-func (g *IFlytekGenerator) processCaseForBranchMapping(caseInterface interface{}, mapping *BranchMapping) {
+func (g *iflytekGeneration) processCaseForBranchMapping(caseInterface interface{}, mapping *BranchMapping) {
 	caseMap, ok := caseInterface.(map[string]interface{})
 	if !ok {
 		return
@@ -1452,7 +1615,7 @@ func (g *IFlytekGenerator) processCaseForBranchMapping(caseInterface interface{}
 }
 
 // extractLevelAndBranchID extracts level and branch ID from case map
-func (g *IFlytekGenerator) extractLevelAndBranchID(caseMap map[string]interface{}) (int, string) {
+func (g *iflytekGeneration) extractLevelAndBranchID(caseMap map[string]interface{}) (int, string) {
 	levelInterface, exists := caseMap["level"]
 	if !exists {
 		return 0, ""
@@ -1477,7 +1640,7 @@ func (g *IFlytekGenerator) extractLevelAndBranchID(caseMap map[string]interface{
 }
 
 // storeBranchIDByLevel stores branch ID by level with backward compatibility
-func (g *IFlytekGenerator) storeBranchIDByLevel(mapping *BranchMapping, level int, branchID string) {
+func (g *iflytekGeneration) storeBranchIDByLevel(mapping *BranchMapping, level int, branchID string) {
 	switch level {
 	case 1:
 		g.storeLevel1BranchID(mapping, branchID)
@@ -1489,42 +1652,26 @@ func (g *IFlytekGenerator) storeBranchIDByLevel(mapping *BranchMapping, level in
 }
 
 // storeLevel1BranchID stores level 1 (true) branch ID
-func (g *IFlytekGenerator) storeLevel1BranchID(mapping *BranchMapping, branchID string) {
+func (g *iflytekGeneration) storeLevel1BranchID(mapping *BranchMapping, branchID string) {
 	mapping.TrueBranchID = branchID // backward compatibility
 	mapping.BranchIDs["true"] = branchID
 	mapping.BranchIDs["1"] = branchID // also map to string "1"
 }
 
 // storeDefaultBranchID stores default (false) branch ID
-func (g *IFlytekGenerator) storeDefaultBranchID(mapping *BranchMapping, branchID string) {
+func (g *iflytekGeneration) storeDefaultBranchID(mapping *BranchMapping, branchID string) {
 	mapping.FalseBranchID = branchID // backward compatibility
 	mapping.BranchIDs["false"] = branchID
 	mapping.BranchIDs["__default__"] = branchID
 }
 
-// storeMultiLevelBranchID stores multi-level branch ID
-func (g *IFlytekGenerator) storeMultiLevelBranchID(mapping *BranchMapping, level int, branchID string) {
-	levelKey := fmt.Sprintf("%d", level)
-	mapping.BranchIDs[levelKey] = branchID
-
-	// Store specific levels for backward compatibility
-	g.storeSpecificLevelMapping(mapping, level, branchID)
-}
-
-// storeSpecificLevelMapping stores specific level mappings for backward compatibility
-func (g *IFlytekGenerator) storeSpecificLevelMapping(mapping *BranchMapping, level int, branchID string) {
-	switch level {
-	case 2:
-		mapping.BranchIDs["2"] = branchID
-	case 3:
-		mapping.BranchIDs["3"] = branchID
-	case 4:
-		mapping.BranchIDs["4"] = branchID
-	}
+// storeMultiLevelBranchID stores the branch ID of any other level under the level number
+func (g *iflytekGeneration) storeMultiLevelBranchID(mapping *BranchMapping, level int, branchID string) {
+	mapping.BranchIDs[strconv.Itoa(level)] = branchID
 }
 
 // extractBranchMappingWithCaseIDs extracts branch mapping from generated condition nodes and preserves case ID mappings
-func (g *IFlytekGenerator) extractBranchMappingWithCaseIDs(iflytekNode IFlytekNode, additionalMappings map[string]string) {
+func (g *iflytekGeneration) extractBranchMappingWithCaseIDs(iflytekNode IFlytekNode, additionalMappings map[string]string) {
 	cases := g.extractBranchCasesFromNode(iflytekNode)
 	if cases == nil {
 		return
@@ -1535,7 +1682,7 @@ func (g *IFlytekGenerator) extractBranchMappingWithCaseIDs(iflytekNode IFlytekNo
 	g.conditionBranchMapping[iflytekNode.ID] = mapping
 }
 
-func (g *IFlytekGenerator) extractBranchCasesFromNode(iflytekNode IFlytekNode) []interface{} {
+func (g *iflytekGeneration) extractBranchCasesFromNode(iflytekNode IFlytekNode) []interface{} {
 	nodeParam := iflytekNode.Data.NodeParam
 	if nodeParam == nil {
 		return nil
@@ -1549,7 +1696,7 @@ func (g *IFlytekGenerator) extractBranchCasesFromNode(iflytekNode IFlytekNode) [
 	return g.convertToCasesInterface(casesInterface)
 }
 
-func (g *IFlytekGenerator) convertToCasesInterface(casesInterface interface{}) []interface{} {
+func (g *iflytekGeneration) convertToCasesInterface(casesInterface interface{}) []interface{} {
 	if casesList, ok := casesInterface.([]interface{}); ok {
 		return casesList
 	}
@@ -1565,7 +1712,7 @@ func (g *IFlytekGenerator) convertToCasesInterface(casesInterface interface{}) [
 	return nil
 }
 
-func (g *IFlytekGenerator) createBranchMapping(additionalMappings map[string]string) *BranchMapping {
+func (g *iflytekGeneration) createBranchMapping(additionalMappings map[string]string) *BranchMapping {
 	mapping := &BranchMapping{
 		BranchIDs: make(map[string]string),
 	}
@@ -1579,7 +1726,7 @@ func (g *IFlytekGenerator) createBranchMapping(additionalMappings map[string]str
 	return mapping
 }
 
-func (g *IFlytekGenerator) processCasesForMapping(cases []interface{}, mapping *BranchMapping) {
+func (g *iflytekGeneration) processCasesForMapping(cases []interface{}, mapping *BranchMapping) {
 	for _, caseInterface := range cases {
 		caseData := g.extractCaseData(caseInterface)
 		if caseData == nil {
@@ -1595,7 +1742,7 @@ type CaseData struct {
 	BranchID string
 }
 
-func (g *IFlytekGenerator) extractCaseData(caseInterface interface{}) *CaseData {
+func (g *iflytekGeneration) extractCaseData(caseInterface interface{}) *CaseData {
 	caseMap, ok := caseInterface.(map[string]interface{})
 	if !ok {
 		return nil
@@ -1624,7 +1771,7 @@ func (g *IFlytekGenerator) extractCaseData(caseInterface interface{}) *CaseData
 	return &CaseData{Level: level, BranchID: branchID}
 }
 
-func (g *IFlytekGenerator) storeBranchMapping(level int, branchID string, mapping *BranchMapping) {
+func (g *iflytekGeneration) storeBranchMapping(level int, branchID string, mapping *BranchMapping) {
 	switch level {
 	case 1:
 		g.storeLevelOneBranch(branchID, mapping)
@@ -1635,19 +1782,19 @@ func (g *IFlytekGenerator) storeBranchMapping(level int, branchID string, mappin
 	}
 }
 
-func (g *IFlytekGenerator) storeLevelOneBranch(branchID string, mapping *BranchMapping) {
+func (g *iflytekGeneration) storeLevelOneBranch(branchID string, mapping *BranchMapping) {
 	mapping.TrueBranchID = branchID
 	mapping.BranchIDs["true"] = branchID
 	mapping.BranchIDs["1"] = branchID
 }
 
-func (g *IFlytekGenerator) storeDefaultBranch(branchID string, mapping *BranchMapping) {
+func (g *iflytekGeneration) storeDefaultBranch(branchID string, mapping *BranchMapping) {
 	mapping.FalseBranchID = branchID
 	mapping.BranchIDs["false"] = branchID
 	mapping.BranchIDs["__default__"] = branchID
 }
 
-func (g *IFlytekGenerator) storeMultiLevelBranch(level int, branchID string, mapping *BranchMapping) {
+func (g *iflytekGeneration) storeMultiLevelBranch(level int, branchID string, mapping *BranchMapping) {
 	levelKey := fmt.Sprintf("%d", level)
 	mapping.BranchIDs[levelKey] = branchID
 
@@ -1658,13 +1805,13 @@ func (g *IFlytekGenerator) storeMultiLevelBranch(level int, branchID string, map
 }
 
 // analyzeClassifierTargets analyzes classifier target node mapping
-func (g *IFlytekGenerator) analyzeClassifierTargets(edges []models.Edge) {
+func (g *iflytekGeneration) analyzeClassifierTargets(edges []models.Edge) {
 	classifierEdges := g.groupEdgesByClassifier(edges)
 	g.updateClassifierMappings(classifierEdges)
 }
 
 // groupEdgesByClassifier groups edges by classifier node
-func (g *IFlytekGenerator) groupEdgesByClassifier(edges []models.Edge) map[string][]models.Edge {
+func (g *iflytekGeneration) groupEdgesByClassifier(edges []models.Edge) map[string][]models.Edge {
 	classifierEdges := make(map[string][]models.Edge)
 
 	for _, edge := range edges {
@@ -1677,7 +1824,7 @@ func (g *IFlytekGenerator) groupEdgesByClassifier(edges []models.Edge) map[strin
 }
 
 // findClassifierID finds classifier ID from edge source
-func (g *IFlytekGenerator) findClassifierID(edgeSource string) string {
+func (g *iflytekGeneration) findClassifierID(edgeSource string) string {
 	for originalID, mappedID := range g.idMapping {
 		if edgeSource == originalID && g.isClassifierNodeID(mappedID) {
 			return mappedID
@@ -1687,14 +1834,12 @@ func (g *IFlytekGenerator) findClassifierID(edgeSource string) string {
 }
 
 // isClassifierNodeID checks if the ID belongs to a classifier node
-func (g *IFlytekGenerator) isClassifierNodeID(mappedID string) bool {
-	const classifierPrefix = "decision-making::"
-	return mappedID != "" && len(mappedID) > len(classifierPrefix) &&
-		mappedID[:len(classifierPrefix)] == classifierPrefix
+func (g *iflytekGeneration) isClassifierNodeID(mappedID string) bool {
+	return NodeKindClassifier.Is(mappedID)
 }
 
 // updateClassifierMappings updates classifier mappings
-func (g *IFlytekGenerator) updateClassifierMappings(classifierEdges map[string][]models.Edge) {
+func (g *iflytekGeneration) updateClassifierMappings(classifierEdges map[string][]models.Edge) {
 	for classifierID, edges := range classifierEdges {
 		if len(edges) >= 2 { // At least 2 edges needed
 			mapping := g.getOrCreateClassifierMapping(classifierID)
@@ -1704,7 +1849,7 @@ func (g *IFlytekGenerator) updateClassifierMappings(classifierEdges map[string][
 }
 
 // getOrCreateClassifierMapping gets existing classifier mapping or creates one
-func (g *IFlytekGenerator) getOrCreateClassifierMapping(classifierID string) *ClassifierMapping {
+func (g *iflytekGeneration) getOrCreateClassifierMapping(classifierID string) *ClassifierMapping {
 	if existing, exists := g.classifierIntentMapping[classifierID]; exists {
 		return existing
 	}
@@ -1717,7 +1862,7 @@ func (g *IFlytekGenerator) getOrCreateClassifierMapping(classifierID string) *Cl
 }
 
 // setFirstIntentTarget sets the first intent target for mapping
-func (g *IFlytekGenerator) setFirstIntentTarget(mapping *ClassifierMapping, edges []models.Edge) {
+func (g *iflytekGeneration) setFirstIntentTarget(mapping *ClassifierMapping, edges []models.Edge) {
 	if len(edges) == 0 {
 		return
 	}
@@ -1731,7 +1876,7 @@ func (g *IFlytekGenerator) setFirstIntentTarget(mapping *ClassifierMapping, edge
 }
 
 // extractClassifierMapping extracts intent mapping from generated classifier nodes
-func (g *IFlytekGenerator) extractClassifierMapping(iflytekNode IFlytekNode) {
+func (g *iflytekGeneration) extractClassifierMapping(iflytekNode IFlytekNode) {
 	intentChains := g.getIntentChainsFromNode(iflytekNode)
 	if intentChains == nil {
 		return
@@ -1746,7 +1891,7 @@ func (g *IFlytekGenerator) extractClassifierMapping(iflytekNode IFlytekNode) {
 }
 
 // getIntentChainsFromNode extracts intent chains from node parameters
-func (g *IFlytekGenerator) getIntentChainsFromNode(iflytekNode IFlytekNode) []map[string]interface{} {
+func (g *iflytekGeneration) getIntentChainsFromNode(iflytekNode IFlytekNode) []map[string]interface{} {
 	if iflytekNode.Data.NodeParam == nil {
 		return nil
 	}
@@ -1765,19 +1910,19 @@ func (g *IFlytekGenerator) getIntentChainsFromNode(iflytekNode IFlytekNode) []ma
 }
 
 // isMappingComplete checks if mapping is already complete
-func (g *IFlytekGenerator) isMappingComplete(mapping *ClassifierMapping) bool {
+func (g *iflytekGeneration) isMappingComplete(mapping *ClassifierMapping) bool {
 	return mapping.DefaultIntentID != "" && len(mapping.IntentIDs) > 0
 }
 
 // processIntentChains processes intent chains and updates mapping
-func (g *IFlytekGenerator) processIntentChains(mapping *ClassifierMapping, intentChains []map[string]interface{}) {
+func (g *iflytekGeneration) processIntentChains(mapping *ClassifierMapping, intentChains []map[string]interface{}) {
 	for _, intentChain := range intentChains {
 		g.processIntentChain(mapping, intentChain)
 	}
 }
 
 // processIntentChain processes a single intent chain
-func (g *IFlytekGenerator) processIntentChain(mapping *ClassifierMapping, intentChain map[string]interface{}) {
+func (g *iflytekGeneration) processIntentChain(mapping *ClassifierMapping, intentChain map[string]interface{}) {
 	intentType := g.extractIntentType(intentChain)
 	intentID := g.extractIntentID(intentChain)
 
@@ -1789,7 +1934,7 @@ func (g *IFlytekGenerator) processIntentChain(mapping *ClassifierMapping, intent
 }
 
 // extractIntentType extracts intent type from intent chain
-func (g *IFlytekGenerator) extractIntentType(intentChain map[string]interface{}) int {
+func (g *iflytekGeneration) extractIntentType(intentChain map[string]interface{}) int {
 	intentType, exists := intentChain["intentType"]
 	if !exists {
 		return 0
@@ -1804,7 +1949,7 @@ func (g *IFlytekGenerator) extractIntentType(intentChain map[string]interface{})
 }
 
 // extractIntentID extracts intent ID from intent chain
-func (g *IFlytekGenerator) extractIntentID(intentChain map[string]interface{}) string {
+func (g *iflytekGeneration) extractIntentID(intentChain map[string]interface{}) string {
 	intentID, exists := intentChain["id"].(string)
 	if !exists {
 		return ""
@@ -1813,7 +1958,7 @@ func (g *IFlytekGenerator) extractIntentID(intentChain map[string]interface{}) s
 }
 
 // updateMappingWithIntent updates mapping based on intent type
-func (g *IFlytekGenerator) updateMappingWithIntent(mapping *ClassifierMapping, intentType int, intentID string) {
+func (g *iflytekGeneration) updateMappingWithIntent(mapping *ClassifierMapping, intentType int, intentID string) {
 	switch intentType {
 	case 2: // Normal classification intent
 		mapping.IntentIDs = append(mapping.IntentIDs, intentID)
@@ -1823,9 +1968,9 @@ func (g *IFlytekGenerator) updateMappingWithIntent(mapping *ClassifierMapping, i
 }
 
 // isDefaultIntentEdge checks if it's a default intent edge
-func (g *IFlytekGenerator) isDefaultIntentEdge(sourceID, sourceHandle string) bool {
+func (g *iflytekGeneration) isDefaultIntentEdge(sourceID, sourceHandle string) bool {
 	// Check if the source node is a classifier
-	if !strings.HasPrefix(sourceID, "decision-making::") {
+	if !NodeKindClassifier.Is(sourceID) {
 		return false
 	}
 
@@ -1838,48 +1983,47 @@ func (g *IFlytekGenerator) isDefaultIntentEdge(sourceID, sourceHandle string) bo
 }
 
 // getFirstIntentTarget gets the target node of the first intent of a classifier
-func (g *IFlytekGenerator) getFirstIntentTarget(classifierID string) string {
+func (g *iflytekGeneration) getFirstIntentTarget(classifierID string) string {
 	if mapping, exists := g.classifierIntentMapping[classifierID]; exists {
 		return mapping.FirstIntentTarget
 	}
 	return ""
 }
 
-// generateDefaultIntentEdges generates edges for default intents of classifiers, connecting to the target node of the last intent
-func (g *IFlytekGenerator) generateDefaultIntentEdges(edges []models.Edge, iflytekDSL *IFlytekDSL) {
-	// Generate connection edges for default intents of each classifier node
-	for classifierID, classifierGen := range g.classifierGenerators {
-		classIDToIntentID := classifierGen.GetClassIDToIntentIDMapping()
-
-		// Get default intent ID
-		defaultIntentID, hasDefault := classIDToIntentID["__default__"]
-		if !hasDefault {
+// generateDefaultIntentEdges connects the default intents of classifiers to the node the default intent strategy picks
+func (g *iflytekGeneration) generateDefaultIntentEdges(workflow *models.Workflow, iflytekDSL *IFlytekDSL) {
+	for i := range workflow.Nodes {
+		classifier := &workflow.Nodes[i]
+		classifierID := g.idMapping[classifier.ID]
+		classifierGen, isClassifier := g.classifierGenerators[classifierID]
+		if classifier.Type != models.NodeTypeClassifier || !isClassifier {
 			continue
 		}
-
-		// Find the target node of the last classification connection
-		var lastClassTargetNode string
-
-		for _, edge := range edges {
-			if g.idMapping[edge.Source] == classifierID {
-				// Keep updating to the last matching edge's target
-				if mapped := g.idMapping[edge.Target]; mapped != "" {
-					lastClassTargetNode = mapped
-				} else {
-					lastClassTargetNode = edge.Target
-				}
-			}
+		defaultIntentID, hasDefault := classifierGen.GetClassIDToIntentIDMapping()["__default__"]
+		if !hasDefault {
+			continue
 		}
 
-		if lastClassTargetNode == "" {
+		target := common.DefaultIntentTarget(workflow, classifier, g.defaultIntent)
+		if target == "" {
 			continue
 		}
+		targetID := g.idMapping[target]
+		if targetID == "" {
+			targetID = target
+		}
+		// The end of an iteration body is the end node generated for the iteration
+		if config, ok := common.AsClassifierConfig(classifier.Config); ok && config.IsInIteration && config.IterationID == target {
+			targetID = g.iterationSubNodeMapping[targetID]["end"]
+			if targetID == "" {
+				continue
+			}
+		}
 
-		// Create edge for default intent
-		defaultEdge := IFlytekEdge{
-			ID:           g.generateEdgeIDWithHandle(classifierID, defaultIntentID, lastClassTargetNode),
+		iflytekDSL.FlowData.Edges = append(iflytekDSL.FlowData.Edges, IFlytekEdge{
+			ID:           g.generateEdgeIDWithHandle(classifierID, defaultIntentID, targetID),
 			Source:       classifierID,
-			Target:       lastClassTargetNode,
+			Target:       targetID,
 			SourceHandle: defaultIntentID,
 			TargetHandle: "",
 			Type:         "customEdge",
@@ -1890,14 +2034,12 @@ func (g *IFlytekGenerator) generateDefaultIntentEdges(edges []models.Edge, iflyt
 			Data: &IFlytekEdgeData{
 				EdgeType: "curve",
 			},
-		}
-
-		iflytekDSL.FlowData.Edges = append(iflytekDSL.FlowData.Edges, defaultEdge)
+		})
 	}
 }
 
 // findIterationSubNodes finds sub-nodes belonging to a specified iteration node
-func (g *IFlytekGenerator) findIterationSubNodes(allNodes []models.Node, iterationID string) []models.Node {
+func (g *iflytekGeneration) findIterationSubNodes(allNodes []models.Node, iterationID string) []models.Node {
 	var subNodes []models.Node
 
 	for _, node := range allNodes {
@@ -1910,7 +2052,7 @@ func (g *IFlytekGenerator) findIterationSubNodes(allNodes []models.Node, iterati
 }
 
 // isNodeInIteration checks if a node belongs to a specific iteration
-func (g *IFlytekGenerator) isNodeInIteration(node models.Node, iterationID string) bool {
+func (g *iflytekGeneration) isNodeInIteration(node models.Node, iterationID string) bool {
 	if node.Config == nil {
 		return false
 	}
@@ -1919,7 +2061,7 @@ func (g *IFlytekGenerator) isNodeInIteration(node models.Node, iterationID strin
 }
 
 // checkIterationMembership checks iteration membership based on config type
-func (g *IFlytekGenerator) checkIterationMembership(config interface{}, iterationID string) bool {
+func (g *iflytekGeneration) checkIterationMembership(config interface{}, iterationID string) bool {
 	switch cfg := config.(type) {
 	case models.StartConfig:
 		return g.isStartNodeInIteration(cfg, iterationID)
@@ -1936,17 +2078,17 @@ func (g *IFlytekGenerator) checkIterationMembership(config interface{}, iteratio
 }
 
 // isStartNodeInIteration checks if start node belongs to iteration
-func (g *IFlytekGenerator) isStartNodeInIteration(config models.StartConfig, iterationID string) bool {
+func (g *iflytekGeneration) isStartNodeInIteration(config models.StartConfig, iterationID string) bool {
 	return config.IsInIteration && config.ParentID == iterationID
 }
 
 // isConfigInIteration checks if config indicates iteration membership
-func (g *IFlytekGenerator) isConfigInIteration(isInIteration bool, configIterationID, targetIterationID string) bool {
+func (g *iflytekGeneration) isConfigInIteration(isInIteration bool, configIterationID, targetIterationID string) bool {
 	return isInIteration && configIterationID == targetIterationID
 }
 
 // processIterationSubNodes processes iteration sub-nodes, sets the correct parentId
-func (g *IFlytekGenerator) processIterationSubNodes(nodes []models.Node, iflytekDSL *IFlytekDSL) error {
+func (g *iflytekGeneration) processIterationSubNodes(nodes []models.Node, iflytekDSL *IFlytekDSL) error {
 	iterationMap := g.buildIterationMap()
 	processedIterations := make(map[string]bool)
 
@@ -1960,8 +2102,57 @@ func (g *IFlytekGenerator) processIterationSubNodes(nodes []models.Node, iflytek
 	return nil
 }
 
+// Layout of iteration bodies: sub-nodes stacked below one another keep iterationNodeGap apart,
+// and the iteration keeps iterationPadding to the right and below them, but is no smaller than
+// the iterations of the Spark editor
+const (
+	iterationNodeGap   = 40.0
+	iterationPadding   = 60.0
+	iterationMinWidth  = 635.0
+	iterationMinHeight = 763.0
+)
+
+// layoutIterationBodies moves iteration sub-nodes that overlap ones before them down, and sizes
+// each iteration to the area its sub-nodes cover. The start node of a body keeps its place.
+func (g *iflytekGeneration) layoutIterationBodies(iflytekDSL *IFlytekDSL) {
+	layouts := make(map[string]*common.SubNodeLayout)
+	for i := range iflytekDSL.FlowData.Nodes {
+		node := &iflytekDSL.FlowData.Nodes[i]
+		if node.ParentID == nil || *node.ParentID == "" {
+			continue
+		}
+		layout, exists := layouts[*node.ParentID]
+		if !exists {
+			layout = common.NewSubNodeLayout(iterationNodeGap)
+			layouts[*node.ParentID] = layout
+		}
+
+		position := models.Position{X: node.Position.X, Y: node.Position.Y}
+		if node.Type == "开始节点" {
+			layout.Reserve(position, node.Width, node.Height)
+			continue
+		}
+		shift := layout.Place(position, node.Width, node.Height).Y - position.Y
+		if shift == 0 {
+			continue
+		}
+		node.Position.Y += shift
+		node.PositionAbsolute.Y += shift
+		if node.Data.OriginPosition != nil {
+			node.Data.OriginPosition = &IFlytekPosition{X: node.Data.OriginPosition.X, Y: node.Data.OriginPosition.Y + shift}
+		}
+	}
+
+	for i := range iflytekDSL.FlowData.Nodes {
+		node := &iflytekDSL.FlowData.Nodes[i]
+		if layout, exists := layouts[node.ID]; exists {
+			node.Width, node.Height = layout.ContainerSize(iterationPadding, iterationMinWidth, iterationMinHeight)
+		}
+	}
+}
+
 // buildIterationMap builds a map of Dify ID to iFlytek ID for iteration nodes
-func (g *IFlytekGenerator) buildIterationMap() map[string]string {
+func (g *iflytekGeneration) buildIterationMap() map[string]string {
 	iterationMap := make(map[string]string)
 
 	for difyID, iflytekID := range g.idMapping {
@@ -1974,14 +2165,17 @@ func (g *IFlytekGenerator) buildIterationMap() map[string]string {
 }
 
 // isIterationNodeID checks if the ID belongs to an iteration node
-func (g *IFlytekGenerator) isIterationNodeID(iflytekID string) bool {
-	return len(iflytekID) > len("iteration::") && iflytekID[:len("iteration::")] == "iteration::"
+func (g *iflytekGeneration) isIterationNodeID(iflytekID string) bool {
+	return NodeKindIteration.Is(iflytekID)
 }
 
 // generateIterationSubNodesForEach generates sub-nodes for each iteration
-func (g *IFlytekGenerator) generateIterationSubNodesForEach(nodes []models.Node, iflytekDSL *IFlytekDSL, iterationMap map[string]string, processedIterations map[string]bool) error {
-	for difyID, iflytekID := range iterationMap {
-		if processedIterations[iflytekID] {
+func (g *iflytekGeneration) generateIterationSubNodesForEach(nodes []models.Node, iflytekDSL *IFlytekDSL, iterationMap map[string]string, processedIterations map[string]bool) error {
+	// Walk the workflow nodes rather than the map so sub-nodes are appended in a stable order
+	for _, node := range nodes {
+		difyID := node.ID
+		iflytekID, isIteration := iterationMap[difyID]
+		if !isIteration || processedIterations[iflytekID] {
 			continue
 		}
 
@@ -1999,7 +2193,7 @@ func (g *IFlytekGenerator) generateIterationSubNodesForEach(nodes []models.Node,
 }
 
 // hasExistingSubNodes checks if iteration already has sub-nodes
-func (g *IFlytekGenerator) hasExistingSubNodes(iflytekDSL *IFlytekDSL, iflytekID string) bool {
+func (g *iflytekGeneration) hasExistingSubNodes(iflytekDSL *IFlytekDSL, iflytekID string) bool {
 	for _, existingNode := range iflytekDSL.FlowData.Nodes {
 		if existingNode.ParentID != nil && *existingNode.ParentID == iflytekID {
 			return true
@@ -2009,7 +2203,7 @@ func (g *IFlytekGenerator) hasExistingSubNodes(iflytekDSL *IFlytekDSL, iflytekID
 }
 
 // processSignleIterationNode processes a single iteration node
-func (g *IFlytekGenerator) processSignleIterationNode(nodes []models.Node, iflytekDSL *IFlytekDSL, difyID, iflytekID string, processedIterations map[string]bool) error {
+func (g *iflytekGeneration) processSignleIterationNode(nodes []models.Node, iflytekDSL *IFlytekDSL, difyID, iflytekID string, processedIterations map[string]bool) error {
 	originalIterationNode := g.findOriginalIterationNode(nodes, difyID)
 	if originalIterationNode.ID == "" {
 		return nil
@@ -2029,7 +2223,7 @@ func (g *IFlytekGenerator) processSignleIterationNode(nodes []models.Node, iflyt
 }
 
 // findOriginalIterationNode finds the original iteration node
-func (g *IFlytekGenerator) findOriginalIterationNode(nodes []models.Node, difyID string) models.Node {
+func (g *iflytekGeneration) findOriginalIterationNode(nodes []models.Node, difyID string) models.Node {
 	for _, node := range nodes {
 		if node.ID == difyID && node.Type == models.NodeTypeIteration {
 			return node
@@ -2039,7 +2233,7 @@ func (g *IFlytekGenerator) findOriginalIterationNode(nodes []models.Node, difyID
 }
 
 // setupIterationGenerator sets up iteration generator with required mappings
-func (g *IFlytekGenerator) setupIterationGenerator() *IterationNodeGenerator {
+func (g *iflytekGeneration) setupIterationGenerator() *IterationNodeGenerator {
 	iterationGenerator, err := g.factory.GetGenerator(models.NodeTypeIteration)
 	if err != nil {
 		return nil
@@ -2058,7 +2252,7 @@ func (g *IFlytekGenerator) setupIterationGenerator() *IterationNodeGenerator {
 }
 
 // generateAndAddSubNodes generates and adds sub-nodes to DSL
-func (g *IFlytekGenerator) generateAndAddSubNodes(originalIterationNode models.Node, iflytekID string, nodes []models.Node, difyID string, iflytekDSL *IFlytekDSL, iterationGen *IterationNodeGenerator) error {
+func (g *iflytekGeneration) generateAndAddSubNodes(originalIterationNode models.Node, iflytekID string, nodes []models.Node, difyID string, iflytekDSL *IFlytekDSL, iterationGen *IterationNodeGenerator) error {
 	nodeIDs := g.generateIterationNodeIDs(iflytekID)
 	iterationSubNodes := g.findIterationSubNodes(nodes, difyID)
 
@@ -2085,7 +2279,7 @@ type iterationNodeIDs struct {
 }
 
 // generateIterationNodeIDs generates all required node IDs for iteration
-func (g *IFlytekGenerator) generateIterationNodeIDs(iflytekID string) iterationNodeIDs {
+func (g *iflytekGeneration) generateIterationNodeIDs(iflytekID string) iterationNodeIDs {
 	return iterationNodeIDs{
 		startID: g.generateDeterministicStartNodeID(iflytekID),
 		endID:   g.generateDeterministicEndNodeID(iflytekID),
@@ -2094,7 +2288,7 @@ func (g *IFlytekGenerator) generateIterationNodeIDs(iflytekID string) iterationN
 }
 
 // addGeneratedNodesToDSL adds generated nodes and edges to DSL
-func (g *IFlytekGenerator) addGeneratedNodesToDSL(iflytekDSL *IFlytekDSL, generatedSubNodes []IFlytekNode, generatedIterationEdges []IFlytekEdge) {
+func (g *iflytekGeneration) addGeneratedNodesToDSL(iflytekDSL *IFlytekDSL, generatedSubNodes []IFlytekNode, generatedIterationEdges []IFlytekEdge) {
 	iflytekDSL.FlowData.Nodes = append(iflytekDSL.FlowData.Nodes, generatedSubNodes...)
 
 	for _, iterationEdge := range generatedIterationEdges {
@@ -2103,7 +2297,7 @@ func (g *IFlytekGenerator) addGeneratedNodesToDSL(iflytekDSL *IFlytekDSL, genera
 }
 
 // setParentIDsForSubNodes sets parent IDs for iteration sub-nodes
-func (g *IFlytekGenerator) setParentIDsForSubNodes(nodes []models.Node, iflytekDSL *IFlytekDSL, iterationMap map[string]string) {
+func (g *iflytekGeneration) setParentIDsForSubNodes(nodes []models.Node, iflytekDSL *IFlytekDSL, iterationMap map[string]string) {
 	for i, node := range iflytekDSL.FlowData.Nodes {
 		if node.ParentID != nil {
 			continue
@@ -2117,7 +2311,7 @@ func (g *IFlytekGenerator) setParentIDsForSubNodes(nodes []models.Node, iflytekD
 }
 
 // findParentIterationForNode finds the parent iteration ID for a node
-func (g *IFlytekGenerator) findParentIterationForNode(node IFlytekNode, nodes []models.Node, iterationMap map[string]string) string {
+func (g *iflytekGeneration) findParentIterationForNode(node IFlytekNode, nodes []models.Node, iterationMap map[string]string) string {
 	for _, originalNode := range nodes {
 		if g.idMapping[originalNode.ID] == node.ID && g.isIterationSubNode(originalNode) {
 			return g.getParentIterationID(originalNode, iterationMap)
@@ -2127,7 +2321,7 @@ func (g *IFlytekGenerator) findParentIterationForNode(node IFlytekNode, nodes []
 }
 
 // updateNodeWithParentID updates node with parent ID and related properties
-func (g *IFlytekGenerator) updateNodeWithParentID(node *IFlytekNode, parentIterationID string) {
+func (g *iflytekGeneration) updateNodeWithParentID(node *IFlytekNode, parentIterationID string) {
 	node.ParentID = &parentIterationID
 	node.Extent = "parent"
 	node.ZIndex = 1
@@ -2139,7 +2333,7 @@ func (g *IFlytekGenerator) updateNodeWithParentID(node *IFlytekNode, parentItera
 }
 
 // removeDuplicateIterationSubNodes removes duplicate iteration sub-nodes
-func (g *IFlytekGenerator) removeDuplicateIterationSubNodes(iflytekDSL *IFlytekDSL) {
+func (g *iflytekGeneration) removeDuplicateIterationSubNodes(iflytekDSL *IFlytekDSL) {
 	// Record seen node types and parent node combinations to avoid duplicates
 	seenSubNodeTypes := make(map[string]map[string]bool) // parentID -> nodeType -> bool
 	var uniqueNodes []IFlytekNode
@@ -2191,7 +2385,7 @@ func (g *IFlytekGenerator) removeDuplicateIterationSubNodes(iflytekDSL *IFlytekD
 }
 
 // isTrulyDuplicateIterationSubNode checks if it's a truly duplicate iteration sub-node
-func (g *IFlytekGenerator) isTrulyDuplicateIterationSubNode(node IFlytekNode, allNodes []IFlytekNode) bool {
+func (g *iflytekGeneration) isTrulyDuplicateIterationSubNode(node IFlytekNode, allNodes []IFlytekNode) bool {
 	if node.ParentID == nil {
 		return false
 	}
@@ -2220,7 +2414,7 @@ func (g *IFlytekGenerator) isTrulyDuplicateIterationSubNode(node IFlytekNode, al
 }
 
 // generateIterationInternalEdges generates internal edges for iteration
-func (g *IFlytekGenerator) generateIterationInternalEdges(subNodes []IFlytekNode, iterationID string, originalIterationNode models.Node) []IFlytekEdge {
+func (g *iflytekGeneration) generateIterationInternalEdges(subNodes []IFlytekNode, iterationID string, originalIterationNode models.Node) []IFlytekEdge {
 	iterationConfig, ok := g.parseIterationConfig(originalIterationNode)
 	if !ok {
 		return []IFlytekEdge{}
@@ -2241,13 +2435,13 @@ func (g *IFlytekGenerator) generateIterationInternalEdges(subNodes []IFlytekNode
 }
 
 // parseIterationConfig parses iteration configuration
-func (g *IFlytekGenerator) parseIterationConfig(originalIterationNode models.Node) (models.IterationConfig, bool) {
+func (g *iflytekGeneration) parseIterationConfig(originalIterationNode models.Node) (models.IterationConfig, bool) {
 	iterationConfig, ok := common.AsIterationConfig(originalIterationNode.Config)
 	return *iterationConfig, ok && iterationConfig != nil
 }
 
 // findIterationNodes finds start, end, and source nodes in iteration
-func (g *IFlytekGenerator) findIterationNodes(subNodes []IFlytekNode, outputSourceNodeID string) (*IFlytekNode, *IFlytekNode, *IFlytekNode) {
+func (g *iflytekGeneration) findIterationNodes(subNodes []IFlytekNode, outputSourceNodeID string) (*IFlytekNode, *IFlytekNode, *IFlytekNode) {
 	var startNode, endNode, sourceNode *IFlytekNode
 
 	// First pass: find start and end nodes, and try to find source node by mapping
@@ -2270,17 +2464,17 @@ func (g *IFlytekGenerator) findIterationNodes(subNodes []IFlytekNode, outputSour
 }
 
 // isIterationStartNodeByID checks if node is iteration start node by ID
-func (g *IFlytekGenerator) isIterationStartNodeByID(node *IFlytekNode) bool {
-	return strings.HasPrefix(node.ID, "iteration-node-start::")
+func (g *iflytekGeneration) isIterationStartNodeByID(node *IFlytekNode) bool {
+	return node.Kind() == NodeKindIterationStart
 }
 
 // isIterationEndNode checks if node is iteration end node
-func (g *IFlytekGenerator) isIterationEndNode(node *IFlytekNode) bool {
-	return strings.HasPrefix(node.ID, "iteration-node-end::")
+func (g *iflytekGeneration) isIterationEndNode(node *IFlytekNode) bool {
+	return node.Kind() == NodeKindIterationEnd
 }
 
 // tryFindSourceNode tries to find source node by output selector mapping
-func (g *IFlytekGenerator) tryFindSourceNode(node *IFlytekNode, outputSourceNodeID string) *IFlytekNode {
+func (g *iflytekGeneration) tryFindSourceNode(node *IFlytekNode, outputSourceNodeID string) *IFlytekNode {
 	if outputSourceNodeID != "" && g.idMapping != nil {
 		for originalNodeID, mappedNodeID := range g.idMapping {
 			if originalNodeID == outputSourceNodeID && mappedNodeID == node.ID {
@@ -2290,7 +2484,7 @@ func (g *IFlytekGenerator) tryFindSourceNode(node *IFlytekNode, outputSourceNode
 	}
 
 	// Fallback to code node for backward compatibility
-	if strings.HasPrefix(node.ID, "ifly-code::") {
+	if node.Kind() == NodeKindCode {
 		return node
 	}
 
@@ -2298,7 +2492,7 @@ func (g *IFlytekGenerator) tryFindSourceNode(node *IFlytekNode, outputSourceNode
 }
 
 // findSourceNodeFallback finds source node using fallback strategies
-func (g *IFlytekGenerator) findSourceNodeFallback(subNodes []IFlytekNode, outputSourceNodeID string) *IFlytekNode {
+func (g *iflytekGeneration) findSourceNodeFallback(subNodes []IFlytekNode, outputSourceNodeID string) *IFlytekNode {
 	if outputSourceNodeID == "" {
 		return nil
 	}
@@ -2313,7 +2507,7 @@ func (g *IFlytekGenerator) findSourceNodeFallback(subNodes []IFlytekNode, output
 }
 
 // createStartToSourceEdge creates edge from start node to source node
-func (g *IFlytekGenerator) createStartToSourceEdge(startNode, sourceNode *IFlytekNode, iterationID string) *IFlytekEdge {
+func (g *iflytekGeneration) createStartToSourceEdge(startNode, sourceNode *IFlytekNode, iterationID string) *IFlytekEdge {
 	if startNode == nil || sourceNode == nil {
 		return nil
 	}
@@ -2334,7 +2528,7 @@ func (g *IFlytekGenerator) createStartToSourceEdge(startNode, sourceNode *IFlyte
 }
 
 // createSourceToEndEdge creates edge from source node to end node
-func (g *IFlytekGenerator) createSourceToEndEdge(sourceNode, endNode *IFlytekNode) *IFlytekEdge {
+func (g *iflytekGeneration) createSourceToEndEdge(sourceNode, endNode *IFlytekNode) *IFlytekEdge {
 	if sourceNode == nil || endNode == nil {
 		return nil
 	}
@@ -2353,8 +2547,8 @@ func (g *IFlytekGenerator) createSourceToEndEdge(sourceNode, endNode *IFlytekNod
 }
 
 // fixStartNodeSourceID fixes abnormal start node source ID
-func (g *IFlytekGenerator) fixStartNodeSourceID(sourceID, iterationID string) string {
-	if strings.Contains(sourceID, "start") && !strings.HasPrefix(sourceID, "iteration-node-start::") {
+func (g *iflytekGeneration) fixStartNodeSourceID(sourceID, iterationID string) string {
+	if strings.Contains(sourceID, "start") && !NodeKindIterationStart.Is(sourceID) {
 		if g.iterationSubNodeMapping[iterationID] != nil {
 			if correctStartID, exists := g.iterationSubNodeMapping[iterationID]["start"]; exists {
 				return correctStartID
@@ -2365,7 +2559,7 @@ func (g *IFlytekGenerator) fixStartNodeSourceID(sourceID, iterationID string) st
 }
 
 // createArrowMarkerEnd creates arrow marker end for edges
-func (g *IFlytekGenerator) createArrowMarkerEnd() *IFlytekMarkerEnd {
+func (g *iflytekGeneration) createArrowMarkerEnd() *IFlytekMarkerEnd {
 	return &IFlytekMarkerEnd{
 		Color: "#275EFF",
 		Type:  "arrow",
@@ -2373,25 +2567,22 @@ func (g *IFlytekGenerator) createArrowMarkerEnd() *IFlytekMarkerEnd {
 }
 
 // createCurveEdgeData creates curve edge data
-func (g *IFlytekGenerator) createCurveEdgeData() *IFlytekEdgeData {
+func (g *iflytekGeneration) createCurveEdgeData() *IFlytekEdgeData {
 	return &IFlytekEdgeData{
 		EdgeType: "curve",
 	}
 }
 
 // generateDeterministicStartNodeID generates a deterministic start node ID based on iteration node ID
-func (g *IFlytekGenerator) generateDeterministicStartNodeID(iterationID string) string {
+func (g *iflytekGeneration) generateDeterministicStartNodeID(iterationID string) string {
 	// Generate a deterministic start node ID based on iteration node ID, consistent with IterationNodeGenerator
 	// Extract UUID part from iteration node ID
-	var startNodeID string
-	if len(iterationID) > len("iteration::") {
-		uuid := iterationID[len("iteration::"):]
-		startNodeID = "iteration-node-start::" + uuid
-	} else {
+	uuid, ok := nodeIDUUID(iterationID)
+	if !ok {
 		// If format is incorrect, fallback to random generation
-		newUUID := generateRandomUUID()
-		startNodeID = "iteration-node-start::" + newUUID
+		uuid = generateRandomUUID()
 	}
+	startNodeID := NodeKindIterationStart.NodeID(uuid)
 
 	// Add the newly generated ID to the mapping to ensure subsequent references can find the correct ID
 	// Here we create an inverse mapping from iteration start node ID to iteration main node ID
@@ -2408,17 +2599,15 @@ func (g *IFlytekGenerator) generateDeterministicStartNodeID(iterationID string)
 }
 
 // generateDeterministicEndNodeID generates a deterministic end node ID based on iteration node ID
-func (g *IFlytekGenerator) generateDeterministicEndNodeID(iterationID string) string {
+func (g *iflytekGeneration) generateDeterministicEndNodeID(iterationID string) string {
 	// Generate a deterministic end node ID based on iteration node ID, consistent with IterationNodeGenerator
 	// Extract UUID part from iteration node ID
-	var endNodeID string
-	if len(iterationID) > len("iteration::") {
-		uuid := iterationID[len("iteration::"):]
-		endNodeID = "iteration-node-end::" + uuid
-	} else {
+	uuid, ok := nodeIDUUID(iterationID)
+	if !ok {
 		// If not parsable, generate a UUID
-		endNodeID = "iteration-node-end::" + generateRandomUUID()
+		uuid = generateRandomUUID()
 	}
+	endNodeID := NodeKindIterationEnd.NodeID(uuid)
 
 	// Add the newly generated ID to the mapping
 	if g.iterationSubNodeMapping[iterationID] == nil {
@@ -2430,10 +2619,10 @@ func (g *IFlytekGenerator) generateDeterministicEndNodeID(iterationID string) st
 }
 
 // generateDeterministicCodeNodeID generates a unique ID for iteration code nodes
-func (g *IFlytekGenerator) generateDeterministicCodeNodeID(iterationID string) string {
+func (g *iflytekGeneration) generateDeterministicCodeNodeID(iterationID string) string {
 	// Generate a UUID for iteration code nodes, ensuring it is different from other node IDs
 	newUUID := generateRandomUUID()
-	codeNodeID := "ifly-code::" + newUUID
+	codeNodeID := NodeKindCode.NodeID(newUUID)
 
 	// Add the newly generated ID to the mapping
 	if g.iterationSubNodeMapping[iterationID] == nil {
@@ -2445,13 +2634,13 @@ func (g *IFlytekGenerator) generateDeterministicCodeNodeID(iterationID string) s
 }
 
 // updateIterationNodeParam updates IterationStartNodeId in iterationNode.nodeParam
-func (g *IFlytekGenerator) updateIterationNodeParam(iflytekDSL *IFlytekDSL, iterationID string, startNodeID string) {
+func (g *iflytekGeneration) updateIterationNodeParam(iflytekDSL *IFlytekDSL, iterationID string, startNodeID string) {
 	actualStartNodeID := g.resolveActualStartNodeID(iflytekDSL, iterationID, startNodeID)
 	g.setIterationStartNodeParam(iflytekDSL, iterationID, actualStartNodeID)
 }
 
 // resolveActualStartNodeID resolves the actual start node ID using multiple strategies
-func (g *IFlytekGenerator) resolveActualStartNodeID(iflytekDSL *IFlytekDSL, iterationID string, startNodeID string) string {
+func (g *iflytekGeneration) resolveActualStartNodeID(iflytekDSL *IFlytekDSL, iterationID string, startNodeID string) string {
 	if startNodeID != "" {
 		return startNodeID
 	}
@@ -2466,7 +2655,7 @@ func (g *IFlytekGenerator) resolveActualStartNodeID(iflytekDSL *IFlytekDSL, iter
 }
 
 // findStartNodeFromDSL finds iteration start node from DSL nodes
-func (g *IFlytekGenerator) findStartNodeFromDSL(iflytekDSL *IFlytekDSL, iterationID string) string {
+func (g *iflytekGeneration) findStartNodeFromDSL(iflytekDSL *IFlytekDSL, iterationID string) string {
 	for _, node := range iflytekDSL.FlowData.Nodes {
 		if g.isIterationStartNode(node, iterationID) {
 			return node.ID
@@ -2476,14 +2665,14 @@ func (g *IFlytekGenerator) findStartNodeFromDSL(iflytekDSL *IFlytekDSL, iteratio
 }
 
 // isIterationStartNode checks if a node is iteration start node for given iteration
-func (g *IFlytekGenerator) isIterationStartNode(node IFlytekNode, iterationID string) bool {
+func (g *iflytekGeneration) isIterationStartNode(node IFlytekNode, iterationID string) bool {
 	return node.ParentID != nil &&
 		*node.ParentID == iterationID &&
 		node.Type == "开始节点"
 }
 
 // getStartNodeFromMapping gets start node ID from iteration sub-node mapping
-func (g *IFlytekGenerator) getStartNodeFromMapping(iterationID string) string {
+func (g *iflytekGeneration) getStartNodeFromMapping(iterationID string) string {
 	if g.iterationSubNodeMapping == nil || g.iterationSubNodeMapping[iterationID] == nil {
 		return ""
 	}
@@ -2496,7 +2685,7 @@ func (g *IFlytekGenerator) getStartNodeFromMapping(iterationID string) string {
 }
 
 // setIterationStartNodeParam sets IterationStartNodeId in iteration node param
-func (g *IFlytekGenerator) setIterationStartNodeParam(iflytekDSL *IFlytekDSL, iterationID string, startNodeID string) {
+func (g *iflytekGeneration) setIterationStartNodeParam(iflytekDSL *IFlytekDSL, iterationID string, startNodeID string) {
 	for _, node := range iflytekDSL.FlowData.Nodes {
 		if node.ID == iterationID && node.Data.NodeParam != nil {
 			node.Data.NodeParam["IterationStartNodeId"] = startNodeID
@@ -2506,7 +2695,7 @@ func (g *IFlytekGenerator) setIterationStartNodeParam(iflytekDSL *IFlytekDSL, it
 }
 
 // getParentIterationID gets the iFlytek SparkAgent ID of the parent iteration node
-func (g *IFlytekGenerator) getParentIterationID(node models.Node, iterationMap map[string]string) string {
+func (g *iflytekGeneration) getParentIterationID(node models.Node, iterationMap map[string]string) string {
 	switch config := node.Config.(type) {
 	case models.StartConfig:
 		if config.IsInIteration && config.ParentID != "" {
@@ -2525,7 +2714,7 @@ func (g *IFlytekGenerator) getParentIterationID(node models.Node, iterationMap m
 }
 
 // needsRegeneration checks if a node needs to be regenerated (nodes that reference other nodes)
-func (g *IFlytekGenerator) needsRegeneration(node models.Node) bool {
+func (g *iflytekGeneration) needsRegeneration(node models.Node) bool {
 	// Only nodes that reference other nodes need to be regenerated
 	switch node.Type {
 	case models.NodeTypeEnd, models.NodeTypeLLM, models.NodeTypeCondition,
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/iflytek/agentbridge/internal/models"
@@ -80,7 +81,7 @@ func (g *IterationNodeGenerator) ExtractConditionBranchMappings(nodes []IFlytekN
 	}
 
 	for _, node := range nodes {
-		if strings.HasPrefix(node.ID, "if-else::") {
+		if NodeKindCondition.Is(node.ID) {
 			g.branchExtractor.ExtractBranchMapping(node)
 		}
 	}
@@ -128,8 +129,8 @@ func (g *IterationNodeGenerator) GenerateNode(node models.Node) (IFlytekNode, er
 		ID:               iterationID,
 		Dragging:         false,
 		Selected:         false,
-		Width:            635, // Set according to examples
-		Height:           763, // Set according to examples
+		Width:            iterationMinWidth, // Sized to the body by layoutIterationBodies
+		Height:           iterationMinHeight,
 		Position:         g.convertPosition(node.Position),
 		PositionAbsolute: g.convertPosition(node.Position),
 		Type:             "迭代",
@@ -217,7 +218,7 @@ func (g *IterationNodeGenerator) createIterationStartNode(iterationID, iteration
 
 // determineStartNodeID determines the start node ID to use
 func (g *IterationNodeGenerator) determineStartNodeID(iterationID, iterationStartNodeID string) string {
-	if iterationStartNodeID != "" && strings.HasPrefix(iterationStartNodeID, "iteration-node-start::") {
+	if NodeKindIterationStart.Is(iterationStartNodeID) {
 		return iterationStartNodeID
 	}
 	return g.generateDeterministicStartNodeID(iterationID)
@@ -260,7 +261,8 @@ func (g *IterationNodeGenerator) generateIterationChildNodes(subNodes []models.N
 	var childNodes []IFlytekNode
 
 	for _, subNode := range subNodes {
-		if subNode.Type == models.NodeTypeStart {
+		// The iteration start and end nodes are generated separately
+		if subNode.Type == models.NodeTypeStart || subNode.Type == models.NodeTypeEnd {
 			continue
 		}
 
@@ -269,6 +271,7 @@ func (g *IterationNodeGenerator) generateIterationChildNodes(subNodes []models.N
 			return nil, fmt.Errorf("生成迭代子节点失败 %s: %w", subNode.ID, err)
 		}
 
+		childNode.sourceID = subNode.ID
 		childNodes = append(childNodes, childNode)
 	}
 
@@ -312,7 +315,7 @@ func (g *IterationNodeGenerator) determineEndNodeID(iterationEndNodeID string) s
 	if iterationEndNodeID != "" {
 		return iterationEndNodeID
 	}
-	return g.generateSpecialNodeID("iteration-node-end")
+	return g.generateSpecialNodeID(NodeKindIterationEnd)
 }
 
 // findIterationSourceNodes finds source and start nodes in iteration
@@ -336,7 +339,7 @@ func (g *IterationNodeGenerator) findNodesByOutputSelector(outputSourceNodeID st
 	var sourceNode, startNode *IFlytekNode
 
 	for i := range subNodes {
-		if strings.HasPrefix(subNodes[i].ID, "iteration-node-start::") {
+		if subNodes[i].Kind() == NodeKindIterationStart {
 			startNode = &subNodes[i]
 		}
 
@@ -365,7 +368,7 @@ func (g *IterationNodeGenerator) isOutputSelectorMatch(outputSourceNodeID, nodeI
 // findLastCodeNode finds the last code node for fallback
 func (g *IterationNodeGenerator) findLastCodeNode(subNodes []IFlytekNode) *IFlytekNode {
 	for i := len(subNodes) - 1; i >= 0; i-- {
-		if strings.HasPrefix(subNodes[i].ID, "ifly-code::") {
+		if subNodes[i].Kind() == NodeKindCode {
 			return &subNodes[i]
 		}
 	}
@@ -556,21 +559,25 @@ func (g *IterationNodeGenerator) getChildNodeGenerator(nodeType models.NodeType)
 	switch nodeType {
 	case models.NodeTypeCode:
 		codeGen := NewCodeNodeGenerator()
+		codeGen.SetCredentials(g.credentials)
 		return codeGen, nil
 	case models.NodeTypeLLM:
 		llmGen := NewLLMNodeGenerator()
+		llmGen.SetCredentials(g.credentials)
 		return llmGen, nil
 	case models.NodeTypeCondition:
 		condGen := NewConditionNodeGenerator()
 		// Set ID mappings for the condition generator
 		condGen.SetIDMapping(g.idMapping)
 		condGen.SetNodeTitleMapping(g.nodeTitleMapping)
+		condGen.SetCredentials(g.credentials)
 		return condGen, nil
 	case models.NodeTypeClassifier:
 		classifierGen := NewClassifierNodeGenerator()
 		// Set ID mappings for the classifier generator
 		classifierGen.SetIDMapping(g.idMapping)
 		classifierGen.SetNodeTitleMapping(g.nodeTitleMapping)
+		classifierGen.SetCredentials(g.credentials)
 		return classifierGen, nil
 	default:
 		return nil, fmt.Errorf("不支持的迭代子节点类型: %s", nodeType)
@@ -723,12 +730,18 @@ func (g *IterationNodeGenerator) resolveIterationStartNodeID(iterationStartNodeI
 		return iterationStartNodeID
 	}
 
+	// Sorted, so that workflows with several iterations resolve the same ID on every run
+	var candidates []string
 	for mappedID := range g.idMapping {
-		if strings.HasPrefix(mappedID, "iteration-node-start::") {
-			return mappedID
+		if NodeKindIterationStart.Is(mappedID) {
+			candidates = append(candidates, mappedID)
 		}
 	}
-	return ""
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Strings(candidates)
+	return candidates[0]
 }
 
 // processSubNodeInputReferences processes all input references of a sub-node
@@ -1261,8 +1274,8 @@ func (g *IterationNodeGenerator) generateDeterministicIterationOutputID(iteratio
 // generateIterationNodeParam generates iteration node parameters
 func (g *IterationNodeGenerator) generateIterationNodeParam(config models.IterationConfig, iterationStartNodeID string) map[string]interface{} {
 	nodeParam := map[string]interface{}{
-		"uid":                  "20718349453", // Default UID, can be configured in actual use
-		"appId":                "12a0a7e2",    // Default AppID, can be configured in actual use
+		"uid":                  g.credentials.UID,
+		"appId":                g.credentials.AppID,
 		"IterationStartNodeId": iterationStartNodeID,
 	}
 
@@ -1272,36 +1285,11 @@ func (g *IterationNodeGenerator) generateIterationNodeParam(config models.Iterat
 // generateDeterministicStartNodeID generates deterministic start node ID based on iteration node ID
 func (g *IterationNodeGenerator) generateDeterministicStartNodeID(iterationID string) string {
 	// Extract UUID part from iteration node ID
-	if len(iterationID) > len("iteration::") {
-		uuid := iterationID[len("iteration::"):]
-		return "iteration-node-start::" + uuid
+	if uuid, ok := nodeIDUUID(iterationID); ok {
+		return NodeKindIterationStart.NodeID(uuid)
 	}
 	// If format is incorrect, fallback to random generation
-	return g.generateSpecialNodeID("iteration-node-start")
-}
-
-// convertDataType converts data types
-func (g *IterationNodeGenerator) convertDataType(dataType models.UnifiedDataType) string {
-	switch dataType {
-	case models.DataTypeArrayString:
-		return "array-string"
-	case models.DataTypeArrayObject:
-		return "array-object"
-	case models.DataTypeString:
-		return "string"
-	case models.DataTypeInteger:
-		return "integer"
-	case models.DataTypeFloat:
-		return "number"
-	case models.DataTypeNumber:
-		return "integer"
-	case models.DataTypeBoolean:
-		return "boolean"
-	case models.DataTypeObject:
-		return "object"
-	default:
-		return string(dataType)
-	}
+	return g.generateSpecialNodeID(NodeKindIterationStart)
 }
 
 // convertPosition converts positions
@@ -1357,7 +1345,7 @@ func (g *IterationNodeGenerator) fixIterationChildOutputIDs(childNode IFlytekNod
 
 // shouldFixChildOutputIDs checks if child output IDs need fixing
 func (g *IterationNodeGenerator) shouldFixChildOutputIDs(childNode IFlytekNode) bool {
-	return strings.HasPrefix(childNode.ID, "spark-llm::") && len(childNode.Data.Outputs) > 0
+	return childNode.Kind() == NodeKindLLM && len(childNode.Data.Outputs) > 0
 }
 
 // fixChildNodeOutputIDs fixes output IDs for child node
@@ -1389,12 +1377,17 @@ func (g *IterationNodeGenerator) applyIterationOutputMapping(fixedNode *IFlytekN
 	}
 }
 
-// getFirstIterationOutputID gets first available iteration output ID
+// getFirstIterationOutputID gets the ID of the iteration output that sorts first by name
 func (g *IterationNodeGenerator) getFirstIterationOutputID(iterationOutputs map[string]string) string {
-	for _, outputID := range iterationOutputs {
-		return outputID
+	names := make([]string, 0, len(iterationOutputs))
+	for name := range iterationOutputs {
+		names = append(names, name)
 	}
-	return ""
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return iterationOutputs[names[0]]
 }
 
 // generateFallbackOutputIDs generates fallback deterministic IDs
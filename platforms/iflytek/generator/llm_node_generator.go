@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
-	"regexp"
 	"strings"
 )
 
@@ -58,21 +57,33 @@ func (g *LLMNodeGenerator) GenerateNode(node models.Node) (IFlytekNode, error) {
 	iflytekNode.Data.AllowOutputReference = true
 	iflytekNode.Data.Icon = g.getNodeIcon(models.NodeTypeLLM)
 
-	// Parse LLM configuration
+	// Parse LLM configuration; prompt references become named placeholders bound to inputs
+	// JSON answers also output the fields of their schema
+	inputs := node.Inputs
+	outputs := common.StructuredOutputs(node)
+	imageInput := ""
 	if llmConfig, ok := common.AsLLMConfig(node.Config); ok && llmConfig != nil {
-		// Generate nodeParam configuration
-		nodeParam := g.generateNodeParam(*llmConfig)
-		iflytekNode.Data.NodeParam = nodeParam
+		config := *llmConfig
+		config.Parameters.ResponseFormat = common.ResponseFormat(llmConfig, outputs)
+		inputs = common.NamePromptTemplates(&config, node.Inputs)
+		inputs, imageInput = g.withImageInput(inputs, config.Vision)
+		iflytekNode.Data.NodeParam = g.generateNodeParam(config)
+		keepSourceModel(iflytekNode.Data.NodeParam, node, config.Model.Name)
 	}
 
 	// Generate inputs (LLM node receives variable references through inputs)
-	iflytekNode.Data.Inputs = g.generateInputsWithMapping(node.Inputs)
+	iflytekNode.Data.Inputs = g.generateInputsWithMapping(inputs)
+	for i := range iflytekNode.Data.Inputs {
+		if iflytekNode.Data.Inputs[i].Name == imageInput {
+			iflytekNode.Data.Inputs[i].FileType = "image"
+		}
+	}
 
 	// Generate outputs (LLM node has default output)
-	iflytekNode.Data.Outputs = g.generateOutputs(node.Outputs)
+	iflytekNode.Data.Outputs = g.generateOutputs(outputs)
 
 	// Generate variable reference information
-	iflytekNode.Data.References = g.generateReferences(node.Inputs)
+	iflytekNode.Data.References = g.generateReferences(inputs)
 
 	return iflytekNode, nil
 }
@@ -100,7 +111,7 @@ func (g *LLMNodeGenerator) generateNodeParam(config models.LLMConfig) map[string
 
 	// User template (prompt) configuration
 	if config.Prompt.UserTemplate != "" {
-		nodeParam["template"] = g.convertTemplateFormat(config.Prompt.UserTemplate)
+		nodeParam["template"] = config.Prompt.UserTemplate
 	} else {
 		nodeParam["template"] = "无" // Default fallback
 	}
@@ -120,10 +131,10 @@ func (g *LLMNodeGenerator) generateNodeParam(config models.LLMConfig) map[string
 	nodeParam["llmIdErrMsg"] = ""
 	nodeParam["url"] = g.getModelUrl(config.Model.Name)
 	nodeParam["auditing"] = "default"
-	nodeParam["multiMode"] = false
-	nodeParam["uid"] = "20718349453" // Example value, should be obtained from configuration
+	nodeParam["multiMode"] = config.Vision != nil && config.Vision.Enabled
+	nodeParam["uid"] = g.credentials.UID
 	nodeParam["patchId"] = "0"
-	nodeParam["appId"] = "12a0a7e2" // Example value, should be obtained from configuration
+	nodeParam["appId"] = g.credentials.AppID
 	nodeParam["isThink"] = false
 	nodeParam["searchDisable"] = true
 
@@ -131,14 +142,15 @@ func (g *LLMNodeGenerator) generateNodeParam(config models.LLMConfig) map[string
 	nodeParam["respFormat"] = g.convertResponseFormat(config.Parameters.ResponseFormat)
 
 	// Chat history configuration
+	chatHistoryEnabled, chatHistoryRounds := common.ChatHistory(config.Memory, 1)
 	nodeParam["chatHistory"] = map[string]interface{}{
-		"isEnabled": false,
-		"rounds":    1,
+		"isEnabled": chatHistoryEnabled,
+		"rounds":    chatHistoryRounds,
 	}
 
 	// System template
 	if config.Prompt.SystemTemplate != "" {
-		nodeParam["systemTemplate"] = g.convertTemplateFormat(config.Prompt.SystemTemplate)
+		nodeParam["systemTemplate"] = config.Prompt.SystemTemplate
 	} else {
 		nodeParam["systemTemplate"] = "无" // Default fallback for iFlytek requirement
 	}
@@ -146,6 +158,38 @@ func (g *LLMNodeGenerator) generateNodeParam(config models.LLMConfig) map[string
 	return nodeParam
 }
 
+// withImageInput returns the inputs with the image input multimodal models read, adding it when
+// no input references the vision images, and the name of that input. Chat uploads (sys.files)
+// have no iFlytek reference.
+func (g *LLMNodeGenerator) withImageInput(inputs []models.Input, vision *models.VisionConfig) ([]models.Input, string) {
+	if vision == nil || !vision.Enabled || len(vision.VariableSelector) < 2 || vision.VariableSelector[0] == "sys" {
+		return inputs, ""
+	}
+	nodeID, outputName := vision.VariableSelector[0], vision.VariableSelector[1]
+
+	name := outputName
+	for _, input := range inputs {
+		if input.Reference != nil && input.Reference.NodeID == nodeID && input.Reference.OutputName == outputName {
+			return inputs, input.Name
+		}
+		if input.Name == name {
+			name = outputName + "_image"
+		}
+	}
+
+	imageInput := models.Input{
+		Name: name,
+		Type: models.DataTypeString,
+		Reference: &models.VariableReference{
+			Type:       models.ReferenceTypeNodeOutput,
+			NodeID:     nodeID,
+			OutputName: outputName,
+			DataType:   models.DataTypeString,
+		},
+	}
+	return append(append([]models.Input(nil), inputs...), imageInput), name
+}
+
 // convertModelProvider converts model provider
 func (g *LLMNodeGenerator) convertModelProvider(provider string) string {
 	switch provider {
@@ -186,25 +230,6 @@ func (g *LLMNodeGenerator) getModelUrl(modelName string) string {
 	return "wss://maas-api.cn-huabei-1.xf-yun.com/v1.1/chat"
 }
 
-// convertTemplateFormat converts template format
-func (g *LLMNodeGenerator) convertTemplateFormat(template string) string {
-	// Convert Dify format to iFlytek SparkAgent format
-	result := template
-
-	// Replace {{#nodeId.outputName#}} with {{outputName}}
-	result = strings.ReplaceAll(result, "{{#", "{{")
-	result = strings.ReplaceAll(result, "#}}", "}}")
-
-	// Use regex to remove nodeId prefix, only keep variable name
-	// For example: {{1754269219469.input_01}} -> {{input_01}}
-
-	// Match {{nodeId.variableName}} pattern
-	re := regexp.MustCompile(`\{\{[^.]+\.([^}]+)\}\}`)
-	result = re.ReplaceAllString(result, "{{$1}}")
-
-	return result
-}
-
 // convertResponseFormat converts response format from Coze to iFlytek
 func (g *LLMNodeGenerator) convertResponseFormat(cozeFormat int) int {
 	switch cozeFormat {
@@ -266,6 +291,7 @@ func (g *LLMNodeGenerator) generateInputsWithMapping(inputs []models.Input) []IF
 func (g *LLMNodeGenerator) generateReferences(inputs []models.Input) []IFlytekReference {
 	// Group inputs by node ID
 	nodeGroups := make(map[string][]models.Input)
+	var nodeOrder []string
 
 	for _, input := range inputs {
 		if input.Reference == nil || input.Reference.NodeID == "" {
@@ -280,13 +306,17 @@ func (g *LLMNodeGenerator) generateReferences(inputs []models.Input) []IFlytekRe
 			}
 		}
 
+		if _, seen := nodeGroups[mappedNodeID]; !seen {
+			nodeOrder = append(nodeOrder, mappedNodeID)
+		}
 		nodeGroups[mappedNodeID] = append(nodeGroups[mappedNodeID], input)
 	}
 
 	// Create a parent reference for each node
 	references := make([]IFlytekReference, 0, len(nodeGroups))
 
-	for nodeID, nodeInputs := range nodeGroups {
+	for _, nodeID := range nodeOrder {
+		nodeInputs := nodeGroups[nodeID]
 		// Create all output references for this node
 		refDetails := make([]IFlytekRefDetail, 0, len(nodeInputs))
 
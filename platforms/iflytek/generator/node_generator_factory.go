@@ -7,14 +7,16 @@ import (
 
 // NodeGeneratorFactory creates iFlytek SparkAgent node generators
 type NodeGeneratorFactory struct {
-	generators map[models.NodeType]NodeGenerator
-	idMapping  map[string]string
+	generators  map[models.NodeType]NodeGenerator
+	idMapping   map[string]string
+	credentials SparkCredentials
 }
 
 func NewNodeGeneratorFactory() *NodeGeneratorFactory {
 	factory := &NodeGeneratorFactory{
-		generators: make(map[models.NodeType]NodeGenerator),
-		idMapping:  make(map[string]string),
+		generators:  make(map[models.NodeType]NodeGenerator),
+		idMapping:   make(map[string]string),
+		credentials: SparkCredentials{AppID: DefaultSparkAppID, UID: DefaultSparkUID},
 	}
 
 	// register all node generators
@@ -46,6 +48,24 @@ func (f *NodeGeneratorFactory) SetIDMapping(idMapping map[string]string) {
 	if iterationGen, ok := f.generators[models.NodeTypeIteration].(*IterationNodeGenerator); ok {
 		iterationGen.SetIDMapping(idMapping)
 	}
+	if questionGen, ok := f.generators[models.NodeTypeHumanInput].(*QuestionAnswerNodeGenerator); ok {
+		questionGen.SetIDMapping(idMapping)
+	}
+	for _, audioType := range []models.NodeType{models.NodeTypeTextToSpeech, models.NodeTypeSpeechToText} {
+		if audioGen, ok := f.generators[audioType].(*AudioNodeGenerator); ok {
+			audioGen.SetIDMapping(idMapping)
+		}
+	}
+}
+
+// SetCredentials sets the Spark appId/uid every generator writes into node parameters
+func (f *NodeGeneratorFactory) SetCredentials(credentials SparkCredentials) {
+	f.credentials = credentials
+	for _, generator := range f.generators {
+		if setter, ok := generator.(interface{ SetCredentials(SparkCredentials) }); ok {
+			setter.SetCredentials(credentials)
+		}
+	}
 }
 
 // SetNodeTitleMapping sets node title mapping
@@ -69,6 +89,14 @@ func (f *NodeGeneratorFactory) SetNodeTitleMapping(nodeTitleMapping map[string]s
 	if iterationGen, ok := f.generators[models.NodeTypeIteration].(*IterationNodeGenerator); ok {
 		iterationGen.SetNodeTitleMapping(nodeTitleMapping)
 	}
+	if questionGen, ok := f.generators[models.NodeTypeHumanInput].(*QuestionAnswerNodeGenerator); ok {
+		questionGen.SetNodeTitleMapping(nodeTitleMapping)
+	}
+	for _, audioType := range []models.NodeType{models.NodeTypeTextToSpeech, models.NodeTypeSpeechToText} {
+		if audioGen, ok := f.generators[audioType].(*AudioNodeGenerator); ok {
+			audioGen.SetNodeTitleMapping(nodeTitleMapping)
+		}
+	}
 }
 
 // registerGenerators registers all node generators
@@ -80,6 +108,9 @@ func (f *NodeGeneratorFactory) registerGenerators() {
 	f.generators[models.NodeTypeCode] = NewCodeNodeGenerator()
 	f.generators[models.NodeTypeClassifier] = NewClassifierNodeGenerator()
 	f.generators[models.NodeTypeIteration] = NewIterationNodeGenerator()
+	f.generators[models.NodeTypeHumanInput] = NewQuestionAnswerNodeGenerator()
+	f.generators[models.NodeTypeTextToSpeech] = NewTextToSpeechNodeGenerator()
+	f.generators[models.NodeTypeSpeechToText] = NewSpeechToTextNodeGenerator()
 }
 
 // GetGenerator returns generator for specified node type
@@ -88,6 +119,7 @@ func (f *NodeGeneratorFactory) GetGenerator(nodeType models.NodeType) (NodeGener
 	if nodeType == models.NodeTypeClassifier {
 		classifierGen := NewClassifierNodeGenerator()
 		classifierGen.SetIDMapping(f.idMapping)
+		classifierGen.SetCredentials(f.credentials)
 		return classifierGen, nil
 	}
 
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// NodeKind is the type of an iFlytek node, encoded as the prefix of its ID ("<kind>::<uuid>").
+type NodeKind string
+
+const (
+	NodeKindStart          NodeKind = "node-start"
+	NodeKindEnd            NodeKind = "node-end"
+	NodeKindLLM            NodeKind = "spark-llm"
+	NodeKindCode           NodeKind = "ifly-code"
+	NodeKindCondition      NodeKind = "if-else"
+	NodeKindClassifier     NodeKind = "decision-making"
+	NodeKindIteration      NodeKind = "iteration"
+	NodeKindIterationStart NodeKind = "iteration-node-start"
+	NodeKindIterationEnd   NodeKind = "iteration-node-end"
+	NodeKindQuestionAnswer NodeKind = "question-answer"
+	NodeKindTextToSpeech   NodeKind = "text-to-speech"
+	NodeKindSpeechToText   NodeKind = "speech-to-text"
+	NodeKindUnknown        NodeKind = "node-unknown"
+)
+
+// nodeIDSeparator separates the kind from the UUID in node IDs
+const nodeIDSeparator = "::"
+
+// nodeKindInfo describes a node kind
+type nodeKindInfo struct {
+	nodeType models.NodeType // Unified node type generated with this kind, empty for iteration internals
+	label    string          // Default node label
+}
+
+// nodeKinds lists the known node kinds. Adding an iFlytek node type only takes a constant and an entry here.
+var nodeKinds = map[NodeKind]nodeKindInfo{
+	NodeKindStart:          {nodeType: models.NodeTypeStart, label: "开始"},
+	NodeKindEnd:            {nodeType: models.NodeTypeEnd, label: "结束"},
+	NodeKindLLM:            {nodeType: models.NodeTypeLLM, label: "大模型"},
+	NodeKindCode:           {nodeType: models.NodeTypeCode, label: "代码"},
+	NodeKindCondition:      {nodeType: models.NodeTypeCondition, label: "分支器"},
+	NodeKindClassifier:     {nodeType: models.NodeTypeClassifier, label: "决策"},
+	NodeKindIteration:      {nodeType: models.NodeTypeIteration, label: "迭代"},
+	NodeKindIterationStart: {},
+	NodeKindIterationEnd:   {},
+	NodeKindQuestionAnswer: {nodeType: models.NodeTypeHumanInput, label: "问答"},
+	NodeKindTextToSpeech:   {nodeType: models.NodeTypeTextToSpeech, label: "语音合成"},
+	NodeKindSpeechToText:   {nodeType: models.NodeTypeSpeechToText, label: "语音识别"},
+}
+
+// NodeKindOf returns the kind encoded in an iFlytek node ID, NodeKindUnknown for other IDs.
+func NodeKindOf(nodeID string) NodeKind {
+	prefix, _, found := strings.Cut(nodeID, nodeIDSeparator)
+	if !found {
+		return NodeKindUnknown
+	}
+	if _, known := nodeKinds[NodeKind(prefix)]; !known {
+		return NodeKindUnknown
+	}
+	return NodeKind(prefix)
+}
+
+// NodeKindFor returns the kind generated for a unified node type.
+func NodeKindFor(nodeType models.NodeType) NodeKind {
+	for kind, info := range nodeKinds {
+		if info.nodeType != "" && info.nodeType == nodeType {
+			return kind
+		}
+	}
+	return NodeKindUnknown
+}
+
+// Kind returns the kind of the node.
+func (n IFlytekNode) Kind() NodeKind {
+	return NodeKindOf(n.ID)
+}
+
+// Is reports whether nodeID is of this kind.
+func (k NodeKind) Is(nodeID string) bool {
+	return NodeKindOf(nodeID) == k
+}
+
+// NodeID builds a node ID of this kind.
+func (k NodeKind) NodeID(uuid string) string {
+	return string(k) + nodeIDSeparator + uuid
+}
+
+// label returns the default label of the kind, empty for unknown kinds.
+func (k NodeKind) label() string {
+	return nodeKinds[k].label
+}
+
+// nodeIDUUID returns the UUID part of an iFlytek node ID.
+func nodeIDUUID(nodeID string) (string, bool) {
+	_, uuid, found := strings.Cut(nodeID, nodeIDSeparator)
+	return uuid, found && uuid != ""
+}
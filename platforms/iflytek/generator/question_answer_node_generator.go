@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// iFlytek question answer node answer types
+const (
+	questionAnswerTypeDirect = "direct"
+	questionAnswerTypeOption = "option"
+)
+
+// iFlytek question answer option kinds
+const (
+	questionOptionTypeDefault = 1
+	questionOptionTypeNormal  = 2
+)
+
+// QuestionAnswerNodeGenerator handles question answer (human input) node generation.
+// Inputs and references are generated like code node inputs.
+type QuestionAnswerNodeGenerator struct {
+	*CodeNodeGenerator
+}
+
+func NewQuestionAnswerNodeGenerator() *QuestionAnswerNodeGenerator {
+	codeGenerator := NewCodeNodeGenerator()
+	codeGenerator.BaseNodeGenerator = NewBaseNodeGenerator(models.NodeTypeHumanInput)
+	return &QuestionAnswerNodeGenerator{
+		CodeNodeGenerator: codeGenerator,
+	}
+}
+
+// GenerateNode generates question answer node
+func (g *QuestionAnswerNodeGenerator) GenerateNode(node models.Node) (IFlytekNode, error) {
+	if err := g.ValidateNode(node); err != nil {
+		return IFlytekNode{}, err
+	}
+	config, _ := common.AsHumanInputConfig(node.Config)
+
+	iflytekNode := g.generateBasicNodeInfo(node)
+	iflytekNode.Data.Icon = g.getNodeIcon(models.NodeTypeHumanInput)
+	iflytekNode.Data.Description = "向用户提问并等待回复，支持直接回答与选项回答"
+	iflytekNode.Data.AllowInputReference = true
+	iflytekNode.Data.AllowOutputReference = true
+
+	iflytekNode.Data.Inputs = g.generateInputsWithMapping(node.Inputs)
+	iflytekNode.Data.Outputs = g.generateOutputs(node.Outputs)
+	iflytekNode.Data.References = g.generateReferences(node.Inputs)
+	iflytekNode.Data.NodeParam = g.generateNodeParam(*config)
+
+	return iflytekNode, nil
+}
+
+// ValidateNode validates unified DSL human input node
+func (g *QuestionAnswerNodeGenerator) ValidateNode(node models.Node) error {
+	if node.Type != models.NodeTypeHumanInput {
+		return fmt.Errorf("node type must be 'human_input', got '%s'", node.Type)
+	}
+
+	if cfg, ok := common.AsHumanInputConfig(node.Config); !ok || cfg == nil {
+		return fmt.Errorf("human input node must have HumanInputConfig")
+	}
+
+	return nil
+}
+
+// generateNodeParam generates question answer node parameters
+func (g *QuestionAnswerNodeGenerator) generateNodeParam(config models.HumanInputConfig) map[string]interface{} {
+	maxRetries := config.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 2
+	}
+
+	nodeParam := map[string]interface{}{
+		"uid":        g.credentials.UID,
+		"appId":      g.credentials.AppID,
+		"question":   config.Question,
+		"answerType": questionAnswerTypeDirect,
+		"timeout":    3,
+		"needReply":  false,
+		"directAnswer": map[string]interface{}{
+			"handleResponse": false,
+			"maxRetryCounts": maxRetries,
+		},
+		"optionAnswer": []interface{}{},
+	}
+
+	if config.AnswerType == models.HumanInputAnswerOption {
+		nodeParam["answerType"] = questionAnswerTypeOption
+		nodeParam["optionAnswer"] = g.generateOptionAnswers(config)
+	}
+
+	return nodeParam
+}
+
+// generateOptionAnswers generates the answer options. Option IDs are the source handles of the
+// option branches, so the unified option IDs are kept and edges need no handle mapping.
+func (g *QuestionAnswerNodeGenerator) generateOptionAnswers(config models.HumanInputConfig) []interface{} {
+	options := make([]interface{}, 0, len(config.Options)+1)
+	for i, option := range config.Options {
+		optionID := option.ID
+		if optionID == "" {
+			optionID = "option-" + generateRealUUID()
+		}
+		options = append(options, map[string]interface{}{
+			"id":           optionID,
+			"name":         string(rune('A' + i%26)),
+			"type":         questionOptionTypeNormal,
+			"content":      option.Name,
+			"content_type": "string",
+		})
+	}
+
+	defaultID := config.DefaultOption
+	if defaultID == "" {
+		defaultID = DefaultSourceHandle
+	}
+	options = append(options, map[string]interface{}{
+		"id":           defaultID,
+		"name":         DefaultSourceHandle,
+		"type":         questionOptionTypeDefault,
+		"content":      "",
+		"content_type": "string",
+	})
+
+	return options
+}
@@ -0,0 +1,23 @@
+package generator
+
+import "github.com/iflytek/agentbridge/internal/models"
+
+// sparkModelKeys are the nodeParam keys that select the Spark model of LLM and classifier nodes
+var sparkModelKeys = []string{"model", "domain", "serviceId", "modelId", "llmId", "url", "patchId"}
+
+// keepSourceModel replaces the model keys of a generated nodeParam with those of the iFlytek
+// source node when it calls the model the unified node names, so iFlytek round trips keep models
+// the generator has no defaults for. Model keys the source node does not set are removed.
+func keepSourceModel(nodeParam map[string]interface{}, source models.Node, modelName string) {
+	sourceParam, ok := source.PlatformConfig.IFlytek["nodeParam"].(map[string]interface{})
+	if !ok || modelName == "" || sourceParam["domain"] != modelName {
+		return
+	}
+	for _, key := range sparkModelKeys {
+		if value, exists := sourceParam[key]; exists {
+			nodeParam[key] = value
+		} else {
+			delete(nodeParam, key)
+		}
+	}
+}
@@ -5,6 +5,15 @@ import (
 	"github.com/iflytek/agentbridge/platforms/common"
 )
 
+// iFlytek file input types of the unified file categories
+var iflytekFileTypes = map[string]string{
+	models.FileTypeDocument: "file",
+	models.FileTypeImage:    "image",
+	models.FileTypeAudio:    "audio",
+	models.FileTypeVideo:    "video",
+	models.FileTypeCustom:   "file",
+}
+
 // StartNodeGenerator handles start node generation
 type StartNodeGenerator struct {
 	*BaseNodeGenerator
@@ -73,19 +82,75 @@ func (g *StartNodeGenerator) createOutputFromVariable(variable models.Variable)
 		Schema: IFlytekSchema{
 			Type:       g.convertDataType(models.UnifiedDataType(variable.Type)),
 			Properties: []interface{}{},
-			Default:    variable.Default,
 		},
-		Required: variable.Required,
+		Required:       variable.Required,
+		DeleteDisabled: variable.DeleteDisabled,
 	}
 
-	// Handle custom parameter type for non-string types
-	if models.UnifiedDataType(variable.Type) != models.DataTypeString {
+	if variable.File != nil {
+		g.applyFileInput(&output, *variable.File)
+		return output
+	}
+
+	g.applyInputRules(&output, variable)
+
+	// Handle custom parameter type for non-string types; numbers stay plain inputs so they
+	// do not read back as uploads
+	dataType := models.UnifiedDataType(variable.Type)
+	if variable.CustomParameterType != "" {
+		output.CustomParameterType = variable.CustomParameterType
+	} else if dataType != models.DataTypeString && !models.IsNumericType(dataType) {
 		output.CustomParameterType = "xfyun-file"
 	}
 
 	return output
 }
 
+// applyInputRules carries the input control of a variable into the schema: the placeholder,
+// description or label becomes the input hint iFlytek keeps in the default, and length and choices
+// become validation rules. Default values have no place and are reported by CheckStartDefaults.
+func (g *StartNodeGenerator) applyInputRules(output *IFlytekOutput, variable models.Variable) {
+	switch {
+	case variable.Placeholder != "":
+		output.Schema.Default = variable.Placeholder
+	case variable.Description != "":
+		output.Schema.Default = variable.Description
+	case variable.Label != "" && variable.Label != variable.Name:
+		output.Schema.Default = variable.Label
+	}
+
+	if variable.Constraints == nil {
+		return
+	}
+	if variable.Control != models.VariableControlNumber && variable.Control != models.VariableControlSelect {
+		output.Schema.MaxLength = variable.Constraints.MaxLength
+	}
+	if len(variable.Constraints.Options) > 0 {
+		output.Schema.Enum = variable.Constraints.Options
+	}
+}
+
+// applyFileInput marks an output as file upload; file lists become array-string uploads
+func (g *StartNodeGenerator) applyFileInput(output *IFlytekOutput, file models.FileInput) {
+	output.CustomParameterType = "xfyun-file"
+	output.Schema.Type = "string"
+	if file.Multiple {
+		output.Schema.Type = "array-string"
+	}
+
+	seen := make(map[string]bool)
+	for _, fileType := range file.AllowedTypes {
+		if iflytekType, exists := iflytekFileTypes[fileType]; exists && !seen[iflytekType] {
+			seen[iflytekType] = true
+			output.AllowedFileType = append(output.AllowedFileType, iflytekType)
+		}
+	}
+	if len(output.AllowedFileType) == 0 {
+		output.AllowedFileType = []string{iflytekFileTypes[models.FileTypeDocument]}
+	}
+	output.FileType = output.AllowedFileType[0]
+}
+
 // ensureDefaultUserInputOutput ensures AGENT_USER_INPUT output exists
 func (g *StartNodeGenerator) ensureDefaultUserInputOutput(iflytekNode *IFlytekNode) {
 	if g.hasAgentUserInputOutput(iflytekNode.Data.Outputs) {
@@ -14,6 +14,11 @@ type IFlytekFlowMeta struct {
 	AvatarColor    string `yaml:"avatarColor" json:"avatarColor"`
 	AdvancedConfig string `yaml:"advancedConfig" json:"advancedConfig"`
 	DSLVersion     string `yaml:"dslVersion" json:"dslVersion"`
+
+	// Workflow catalog metadata, written only when set
+	Tags    []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Author  string   `yaml:"author,omitempty" json:"author,omitempty"`
+	Version string   `yaml:"version,omitempty" json:"version,omitempty"`
 }
 
 // IFlytekFlowData contains flow data.
@@ -37,6 +42,11 @@ type IFlytekNode struct {
 	ZIndex           int             `yaml:"zIndex,omitempty" json:"zIndex,omitempty"`
 	Draggable        *bool           `yaml:"draggable,omitempty" json:"draggable,omitempty"`
 	Data             IFlytekNodeData `yaml:"data" json:"data"`
+
+	// Source keys no field above models, emitted again on iFlytek round trips
+	Unknown map[string]interface{} `yaml:",inline" json:"-"`
+
+	sourceID string // Unified node the node was generated from, not serialized
 }
 
 // IFlytekPosition contains position information.
@@ -64,6 +74,9 @@ type IFlytekNodeData struct {
 	// Iteration node specific fields
 	ParentID       *string          `yaml:"parentId,omitempty" json:"parentId,omitempty"`
 	OriginPosition *IFlytekPosition `yaml:"originPosition,omitempty" json:"originPosition,omitempty"`
+
+	// Source keys no field above models, emitted again on iFlytek round trips
+	Unknown map[string]interface{} `yaml:",inline" json:"-"`
 }
 
 // IFlytekNodeMeta contains node metadata.
@@ -90,6 +103,8 @@ type IFlytekOutput struct {
 	Required            bool          `yaml:"required,omitempty" json:"required,omitempty"`
 	DeleteDisabled      bool          `yaml:"deleteDisabled,omitempty" json:"deleteDisabled,omitempty"`
 	CustomParameterType string        `yaml:"customParameterType,omitempty" json:"customParameterType,omitempty"`
+	FileType            string        `yaml:"fileType,omitempty" json:"fileType,omitempty"`               // xfyun-file inputs only
+	AllowedFileType     []string      `yaml:"allowedFileType,omitempty" json:"allowedFileType,omitempty"` // xfyun-file inputs only
 }
 
 // IFlytekSchema contains data schema.
@@ -98,6 +113,8 @@ type IFlytekSchema struct {
 	Properties []interface{}       `yaml:"properties,omitempty" json:"properties,omitempty"`
 	Default    interface{}         `yaml:"default,omitempty" json:"default,omitempty"`
 	Value      *IFlytekSchemaValue `yaml:"value,omitempty" json:"value,omitempty"`
+	MaxLength  int                 `yaml:"maxLength,omitempty" json:"maxLength,omitempty"` // Start inputs only
+	Enum       []interface{}       `yaml:"enum,omitempty" json:"enum,omitempty"`           // Start inputs only, allowed values
 }
 
 // IFlytekSchemaValue contains data value.
@@ -145,6 +162,7 @@ type IFlytekEdge struct {
 	MarkerEnd    *IFlytekMarkerEnd `yaml:"markerEnd,omitempty" json:"markerEnd,omitempty"`
 	Data         *IFlytekEdgeData  `yaml:"data,omitempty" json:"data,omitempty"`
 	ZIndex       int               `yaml:"zIndex,omitempty" json:"zIndex,omitempty"`
+	Label        string            `yaml:"label,omitempty" json:"label,omitempty"`
 }
 
 // IFlytekMarkerEnd contains arrow marker.
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+)
+
+// restoreUnknownFields emits the node keys the iFlytek parser kept without modeling them on the
+// nodes generated from those source nodes, so iFlytek round trips keep them.
+func (g *iflytekGeneration) restoreUnknownFields(unifiedDSL *models.UnifiedDSL, iflytekDSL *IFlytekDSL) {
+	sourceNodes := common.NodesByTargetID(unifiedDSL.Workflow.Nodes, g.idMapping)
+
+	nodes := iflytekDSL.FlowData.Nodes
+	for i := range nodes {
+		sourceNode := sourceNodes[nodes[i].ID]
+		if sourceNode == nil {
+			continue
+		}
+		nodes[i].Unknown, nodes[i].Data.Unknown = common.RestoreUnknownFields(
+			sourceNode.PlatformConfig.IFlytek, string(nodes[i].Kind()), IFlytekNode{}, IFlytekNodeData{})
+		nodes[i].Data.NodeParam = restoreNodeParam(sourceNode, nodes[i], g.credentials)
+	}
+}
+
+// nodeParamAliases are generated nodeParam keys that editors also write under another name into
+// v1 files; the name of the source node is kept
+var nodeParamAliases = map[string]string{"chatHistory": "enableChatHistoryV2"}
+
+// nodeParamDefaults are the values the generator fills in when the unified node has none; they
+// are left out for source nodes without the key
+var nodeParamDefaults = map[string]interface{}{"template": "无", "systemTemplate": "无", "searchDisable": true}
+
+// restoreNodeParam adds the nodeParam keys of an iFlytek source node of the same kind the
+// generator did not set; generated keys are kept, under the name the source used, except for
+// defaults the source did not have. The source Spark identity is kept unless one was configured.
+func restoreNodeParam(sourceNode *models.Node, node IFlytekNode, credentials SparkCredentials) map[string]interface{} {
+	nodeParam := node.Data.NodeParam
+	sourceParam, ok := sourceNode.PlatformConfig.IFlytek["nodeParam"].(map[string]interface{})
+	if !ok {
+		return nodeParam
+	}
+	if kind, _, _ := strings.Cut(sourceNode.ID, "::"); kind != string(node.Kind()) {
+		return nodeParam
+	}
+
+	for key, alias := range nodeParamAliases {
+		value, generated := nodeParam[key]
+		_, sourceHasKey := sourceParam[key]
+		_, sourceHasAlias := sourceParam[alias]
+		if generated && !sourceHasKey && sourceHasAlias {
+			delete(nodeParam, key)
+			nodeParam[alias] = value
+		}
+	}
+	for key, value := range nodeParamDefaults {
+		if _, exists := sourceParam[key]; !exists && nodeParam[key] == value {
+			delete(nodeParam, key)
+		}
+	}
+	for key, isDefault := range map[string]bool{"appId": credentials.AppID == DefaultSparkAppID, "uid": credentials.UID == DefaultSparkUID} {
+		if _, generated := nodeParam[key]; generated && isDefault && sourceParam[key] != nil {
+			nodeParam[key] = sourceParam[key]
+		}
+	}
+
+	for key, value := range sourceParam {
+		if _, exists := nodeParam[key]; exists {
+			continue
+		}
+		if nodeParam == nil {
+			nodeParam = make(map[string]interface{}, len(sourceParam))
+		}
+		nodeParam[key] = value
+	}
+	return nodeParam
+}
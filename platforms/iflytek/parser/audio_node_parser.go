@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// iFlytek speech synthesis audio encodings (aue) and their unified formats
+var ttsEncodingFormats = map[string]string{
+	"lame": "mp3",
+	"raw":  "pcm",
+}
+
+// AudioNodeParser parses speech synthesis and speech recognition nodes.
+type AudioNodeParser struct {
+	*CodeNodeParser
+	iflytekType string
+	nodeType    models.NodeType
+}
+
+// NewTextToSpeechNodeParser creates a speech synthesis node parser.
+func NewTextToSpeechNodeParser(variableRefSystem *models.VariableReferenceSystem) *AudioNodeParser {
+	return &AudioNodeParser{
+		CodeNodeParser: NewCodeNodeParser(variableRefSystem),
+		iflytekType:    IFlytekNodeTypeTextToSpeech,
+		nodeType:       models.NodeTypeTextToSpeech,
+	}
+}
+
+// NewSpeechToTextNodeParser creates a speech recognition node parser.
+func NewSpeechToTextNodeParser(variableRefSystem *models.VariableReferenceSystem) *AudioNodeParser {
+	return &AudioNodeParser{
+		CodeNodeParser: NewCodeNodeParser(variableRefSystem),
+		iflytekType:    IFlytekNodeTypeSpeechToText,
+		nodeType:       models.NodeTypeSpeechToText,
+	}
+}
+
+// GetSupportedType returns the supported node type.
+func (p *AudioNodeParser) GetSupportedType() string {
+	return p.iflytekType
+}
+
+// ValidateNode validates node data.
+func (p *AudioNodeParser) ValidateNode(iflytekNode IFlytekNode) error {
+	if iflytekNode.ID == "" {
+		return fmt.Errorf("node ID is empty")
+	}
+
+	if iflytekNode.Type != p.GetSupportedType() {
+		return fmt.Errorf("invalid node type: expected %s, got %s", p.GetSupportedType(), iflytekNode.Type)
+	}
+
+	return nil
+}
+
+// ParseNode parses a node.
+func (p *AudioNodeParser) ParseNode(iflytekNode IFlytekNode) (*models.Node, error) {
+	if err := p.ValidateNode(iflytekNode); err != nil {
+		return nil, err
+	}
+
+	node := p.ParseBasicNodeInfo(iflytekNode, p.nodeType)
+
+	if err := p.parseInputsOutputs(node, iflytekNode.Data); err != nil {
+		return nil, err
+	}
+
+	nodeParam, _ := iflytekNode.Data["nodeParam"].(map[string]interface{})
+	if p.nodeType == models.NodeTypeTextToSpeech {
+		node.Config = p.parseTextToSpeechConfig(nodeParam)
+	} else {
+		node.Config = p.parseSpeechToTextConfig(nodeParam)
+	}
+
+	p.SavePlatformConfig(node, iflytekNode)
+
+	return node, nil
+}
+
+// parseTextToSpeechConfig parses the speaker and voice settings from nodeParam
+func (p *AudioNodeParser) parseTextToSpeechConfig(nodeParam map[string]interface{}) models.TextToSpeechConfig {
+	config := models.TextToSpeechConfig{
+		Speed:  p.intParam(nodeParam, "speed"),
+		Volume: p.intParam(nodeParam, "volume"),
+		Pitch:  p.intParam(nodeParam, "pitch"),
+	}
+	config.Voice, _ = nodeParam["vcn"].(string)
+
+	encoding, _ := nodeParam["aue"].(string)
+	config.Format = encoding
+	if format, exists := ttsEncodingFormats[encoding]; exists {
+		config.Format = format
+	}
+
+	return config
+}
+
+// parseSpeechToTextConfig parses the recognition language settings from nodeParam
+func (p *AudioNodeParser) parseSpeechToTextConfig(nodeParam map[string]interface{}) models.SpeechToTextConfig {
+	config := models.SpeechToTextConfig{}
+	config.Language, _ = nodeParam["language"].(string)
+	config.Accent, _ = nodeParam["accent"].(string)
+	config.Format, _ = nodeParam["format"].(string)
+	return config
+}
+
+// intParam reads an integer parameter that may be decoded as int or float64
+func (p *AudioNodeParser) intParam(nodeParam map[string]interface{}, name string) int {
+	switch value := nodeParam[name].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	}
+	return 0
+}
@@ -183,6 +183,8 @@ type IFlytekNode struct {
 	Extent           string                 `yaml:"extent,omitempty"`
 	ZIndex           int                    `yaml:"zIndex,omitempty"`
 	Draggable        bool                   `yaml:"draggable,omitempty"`
+
+	Unknown map[string]interface{} `yaml:",inline"` // Keys not modeled above, kept for round trips
 }
 
 // IFlytekPosition contains position information.
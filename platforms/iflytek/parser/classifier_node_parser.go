@@ -2,7 +2,9 @@ package parser
 
 import (
 	"fmt"
+
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 )
 
 // ClassifierNodeParser parses classification decision nodes.
@@ -107,6 +109,8 @@ func (p *ClassifierNodeParser) parseClassifierConfig(data map[string]interface{}
 
 	p.parseClassifierQueryVariable(data, config)
 	p.parseClassifierInstructions(nodeParam, config)
+	config.Instructions, config.Examples = common.SplitClassifierExamples(config.Instructions, config.Classes)
+	config.Memory = parseChatHistory(nodeParam)
 
 	return config, nil
 }
@@ -181,8 +185,10 @@ func (p *ClassifierNodeParser) parseModelConfig(nodeParam map[string]interface{}
 		modelConfig.Provider = domain
 	}
 
-	// Parse model name
-	if model, ok := nodeParam["model"].(string); ok {
+	// Parse model name, the domain like LLM nodes, else the model family
+	if domain, ok := nodeParam["domain"].(string); ok && domain != "" {
+		modelConfig.Name = domain
+	} else if model, ok := nodeParam["model"].(string); ok {
 		modelConfig.Name = model
 	}
 
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 )
 
 // ConditionNodeParser parses conditional branch nodes.
@@ -150,7 +151,7 @@ func (p *ConditionNodeParser) parseConditionCase(caseData map[string]interface{}
 
 	// Parse logical operator
 	if logicalOperator, ok := caseData["logicalOperator"].(string); ok {
-		conditionCase.LogicalOperator = logicalOperator
+		conditionCase.LogicalOperator = models.NormalizeLogicalOperator(logicalOperator)
 	}
 
 	// Parse condition list
@@ -347,41 +348,7 @@ func (p *ConditionNodeParser) getOutputTypeFromContext(sourceNodeID, outputName
 	return models.DataTypeString
 }
 
-// mapComparisonOperator maps comparison operators.
+// mapComparisonOperator maps iFlytek comparison operators to unified operators.
 func (p *ConditionNodeParser) mapComparisonOperator(operator string) string {
-	// Use operator mapping table for better maintainability
-	operatorMap := p.getOperatorMappingTable()
-	if mappedOp, exists := operatorMap[operator]; exists {
-		return mappedOp
-	}
-	// Keep original operator if no mapping found
-	return operator
-}
-
-// getOperatorMappingTable returns the operator mapping table
-func (p *ConditionNodeParser) getOperatorMappingTable() map[string]string {
-	return map[string]string{
-		"contains":      "contains",
-		"not_contains":  "not_contains",
-		"equals":        "equals",
-		"==":            "equals",
-		"not_equals":    "not_equals",
-		"!=":            "not_equals",
-		"start_with":    "start_with",
-		"starts_with":   "start_with",
-		"end_with":      "end_with",
-		"ends_with":     "end_with",
-		"empty":         "is_empty",
-		"is_empty":      "is_empty",
-		"not_empty":     "is_not_empty",
-		"is_not_empty":  "is_not_empty",
-		"greater_than":  "greater_than",
-		">":             "greater_than",
-		"less_than":     "less_than",
-		"<":             "less_than",
-		"greater_equal": "greater_equal",
-		">=":            "greater_equal",
-		"less_equal":    "less_equal",
-		"<=":            "less_equal",
-	}
+	return common.NormalizeConditionOperator(operator)
 }
@@ -17,8 +17,16 @@ type IFlytekEdge struct {
 	MarkerEnd    map[string]interface{} `yaml:"markerEnd,omitempty"`
 	Data         map[string]interface{} `yaml:"data,omitempty"`
 	ZIndex       int                    `yaml:"zIndex,omitempty"`
+	Label        string                 `yaml:"label,omitempty"`
 }
 
+// Look of the edges the iFlytek editor draws by default
+const (
+	defaultEdgePath   = models.EdgePathCurve
+	defaultEdgeColor  = "#275EFF"
+	defaultEdgeMarker = "arrow"
+)
+
 // EdgeParser parses edges.
 type EdgeParser struct {
 	variableRefSystem *models.VariableReferenceSystem
@@ -104,6 +112,10 @@ func (p *EdgeParser) parseIFlytekEdge(edgeMap map[string]interface{}) (*IFlytekE
 		edge.ZIndex = int(zIndex)
 	}
 
+	if label, ok := edgeMap["label"].(string); ok {
+		edge.Label = label
+	}
+
 	return edge, nil
 }
 
@@ -126,6 +138,8 @@ func (p *EdgeParser) ParseEdge(iflytekEdge IFlytekEdge) (*models.Edge, error) {
 		unifiedEdge.Condition = condition
 	}
 
+	unifiedEdge.Style = p.parseEdgeStyle(iflytekEdge)
+
 	// Save platform-specific configuration
 	unifiedEdge.PlatformConfig = models.PlatformConfig{
 		IFlytek: p.preserveIFlytekPlatformFields(iflytekEdge),
@@ -135,6 +149,25 @@ func (p *EdgeParser) ParseEdge(iflytekEdge IFlytekEdge) (*models.Edge, error) {
 	return unifiedEdge, nil
 }
 
+// parseEdgeStyle keeps the parts of the edge look that differ from the editor default.
+func (p *EdgeParser) parseEdgeStyle(iflytekEdge IFlytekEdge) *models.EdgeStyle {
+	style := models.EdgeStyle{Label: iflytekEdge.Label}
+	if edgeType, _ := iflytekEdge.Data["edgeType"].(string); edgeType != "" && edgeType != defaultEdgePath {
+		style.Path = edgeType
+	}
+	if color, _ := iflytekEdge.MarkerEnd["color"].(string); color != "" && !strings.EqualFold(color, defaultEdgeColor) {
+		style.Color = color
+	}
+	if marker, _ := iflytekEdge.MarkerEnd["type"].(string); marker != "" && marker != defaultEdgeMarker {
+		style.Marker = marker
+	}
+
+	if style == (models.EdgeStyle{}) {
+		return nil
+	}
+	return &style
+}
+
 // generateUnifiedEdgeID generates unified edge ID.
 func (p *EdgeParser) generateUnifiedEdgeID(iflytekEdge IFlytekEdge) string {
 	if iflytekEdge.ID != "" {
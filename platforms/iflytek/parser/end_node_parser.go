@@ -60,7 +60,7 @@ func (p *EndNodeParser) ParseNode(iflytekNode IFlytekNode) (*models.Node, error)
 	} else {
 		// Create default configuration if no inputs
 		node.Config = models.EndConfig{
-			OutputMode:   "template",
+			OutputMode:   models.EndOutputModeTemplate,
 			StreamOutput: true,
 			Outputs:      make([]models.EndOutput, 0),
 		}
@@ -75,7 +75,7 @@ func (p *EndNodeParser) ParseNode(iflytekNode IFlytekNode) (*models.Node, error)
 // parseEndConfig parses end node configuration.
 func (p *EndNodeParser) parseEndConfig(data map[string]interface{}, inputs []models.Input) (models.EndConfig, error) {
 	config := models.EndConfig{
-		OutputMode:   "template",
+		OutputMode:   models.EndOutputModeTemplate,
 		StreamOutput: true,
 		Outputs:      make([]models.EndOutput, 0),
 	}
@@ -90,9 +90,9 @@ func (p *EndNodeParser) parseEndConfig(data map[string]interface{}, inputs []mod
 		}
 		if outputMode, ok := nodeParam["outputMode"].(float64); ok {
 			if outputMode == 1 {
-				config.OutputMode = "template"
+				config.OutputMode = models.EndOutputModeTemplate
 			} else {
-				config.OutputMode = "variables"
+				config.OutputMode = models.EndOutputModeVariables
 			}
 		}
 	}
@@ -1,19 +1,22 @@
 package parser
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/iflytek/agentbridge/core/interfaces"
 	"github.com/iflytek/agentbridge/internal/models"
 	"github.com/iflytek/agentbridge/platforms/common"
+	"github.com/iflytek/agentbridge/platforms/iflytek/dslversion"
 	"os"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
-// Compile-time interface check
-var _ interfaces.DSLParser = (*IFlytekParser)(nil)
+// Compile-time interface checks
+var (
+	_ interfaces.DSLParser     = (*IFlytekParser)(nil)
+	_ interfaces.ContextParser = (*IFlytekParser)(nil)
+)
 
 // IFlytekParser provides DSL parsing for iFlytek Agent platform
 type IFlytekParser struct {
@@ -40,20 +43,33 @@ func NewIFlytekParser() *IFlytekParser {
 
 // Parse parses DSL data into unified format
 func (p *IFlytekParser) Parse(data []byte) (*models.UnifiedDSL, error) {
+	return p.ParseWithContext(context.Background(), data)
+}
+
+// ParseWithContext parses DSL data into unified format and gives up once ctx is done
+func (p *IFlytekParser) ParseWithContext(ctx context.Context, data []byte) (*models.UnifiedDSL, error) {
+	p.ResetIssues()
+
 	var root IFlytekRootStructure
-	if err := yaml.Unmarshal(data, &root); err != nil {
+	if err := unmarshalIFlytekDSL(data, &root); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	unifiedDSL := models.NewUnifiedDSL()
 
+	// Normalize version-specific node parameters to the baseline format
+	p.normalizeDSLVersion(&root)
+
 	// Parse metadata
 	if err := p.parseMetadata(root.FlowMeta, unifiedDSL); err != nil {
 		return nil, fmt.Errorf("failed to parse metadata: %w", err)
 	}
 
 	// Parse nodes
-	if err := p.parseNodes(root.FlowData.Nodes, unifiedDSL); err != nil {
+	if err := p.parseNodes(ctx, root.FlowData.Nodes, unifiedDSL); err != nil {
 		return nil, fmt.Errorf("failed to parse nodes: %w", err)
 	}
 
@@ -70,9 +86,27 @@ func (p *IFlytekParser) Parse(data []byte) (*models.UnifiedDSL, error) {
 	// Print conversion summary after parsing is complete
 	p.printConversionSummary(unifiedDSL)
 
+	if err := p.FinishParse(unifiedDSL); err != nil {
+		return nil, err
+	}
 	return unifiedDSL, nil
 }
 
+// normalizeDSLVersion converts node parameters of newer DSL versions to the v1 shape read by node parsers
+func (p *IFlytekParser) normalizeDSLVersion(root *IFlytekRootStructure) {
+	version := root.FlowMeta.DSLVersion
+	if version != "" && !dslversion.IsSupported(version) {
+		p.ReportIssue("Unknown iFlytek DSL version '%s', parsing as %s", version, dslversion.Default)
+	}
+
+	profile := dslversion.ForParsing(version)
+	for _, node := range root.FlowData.Nodes {
+		if nodeParam, ok := node.Data["nodeParam"].(map[string]interface{}); ok {
+			profile.NormalizeNodeParam(node.Type, nodeParam)
+		}
+	}
+}
+
 // ParseFile parses DSL from file
 func (p *IFlytekParser) ParseFile(filename string) (*models.UnifiedDSL, error) {
 	data, err := os.ReadFile(filename)
@@ -85,13 +119,19 @@ func (p *IFlytekParser) ParseFile(filename string) (*models.UnifiedDSL, error) {
 // Validate validates input data
 func (p *IFlytekParser) Validate(data []byte) error {
 	var root IFlytekRootStructure
-	if err := yaml.Unmarshal(data, &root); err != nil {
+	if err := unmarshalIFlytekDSL(data, &root); err != nil {
 		return fmt.Errorf("invalid YAML format: %w", err)
 	}
 
 	return p.validateStructure(root)
 }
 
+// unmarshalIFlytekDSL decodes the iFlytek workflow document of data, which may be one of
+// several concatenated YAML documents and may use anchors and aliases.
+func unmarshalIFlytekDSL(data []byte, root *IFlytekRootStructure) error {
+	return common.UnmarshalYAMLDocument(data, root, "iFlytek", "flowMeta", "flowData")
+}
+
 // parseMetadata parses flow metadata
 func (p *IFlytekParser) parseMetadata(flowMeta IFlytekFlowMeta, unifiedDSL *models.UnifiedDSL) error {
 	// Validate required fields
@@ -102,6 +142,9 @@ func (p *IFlytekParser) parseMetadata(flowMeta IFlytekFlowMeta, unifiedDSL *mode
 	// Set basic metadata
 	unifiedDSL.Metadata.Name = flowMeta.Name
 	unifiedDSL.Metadata.Description = flowMeta.Description
+	unifiedDSL.Metadata.Tags = flowMeta.Tags
+	unifiedDSL.Metadata.Author = flowMeta.Author
+	unifiedDSL.Metadata.Version = flowMeta.Version
 
 	// Parse UI configuration
 	uiConfig, err := p.parseUIConfig(flowMeta)
@@ -162,21 +205,20 @@ func (p *IFlytekParser) parsePrologueConfig(advancedConfig map[string]interface{
 		return
 	}
 
+	// A disabled prologue shows neither the statement nor the input examples
 	prologue, ok := prologueInterface.(map[string]interface{})
 	if !ok {
 		return
 	}
+	if enabled, ok := prologue["enabled"].(bool); !ok || !enabled {
+		return
+	}
 
 	p.parseOpeningStatement(prologue, uiConfig)
 	p.parseSuggestedQuestions(prologue, uiConfig)
 }
 
 func (p *IFlytekParser) parseOpeningStatement(prologue map[string]interface{}, uiConfig *models.UIConfig) {
-	enabled, ok := prologue["enabled"].(bool)
-	if !ok || !enabled {
-		return
-	}
-
 	statement, ok := prologue["statement"].(string)
 	if ok && statement != "" {
 		uiConfig.OpeningStatement = statement
@@ -227,9 +269,9 @@ func (p *IFlytekParser) isUIConfigEmpty(uiConfig *models.UIConfig) bool {
 }
 
 // parseNodes parses nodes.
-func (p *IFlytekParser) parseNodes(nodes []IFlytekNode, unifiedDSL *models.UnifiedDSL) error {
+func (p *IFlytekParser) parseNodes(ctx context.Context, nodes []IFlytekNode, unifiedDSL *models.UnifiedDSL) error {
 	// Step 1: Parse all nodes
-	allNodes, nodeParentMap, err := p.parseAllNodes(nodes)
+	allNodes, nodeParentMap, err := p.parseAllNodes(ctx, nodes)
 	if err != nil {
 		return err
 	}
@@ -243,11 +285,16 @@ func (p *IFlytekParser) parseNodes(nodes []IFlytekNode, unifiedDSL *models.Unifi
 }
 
 // parseAllNodes parses all iFlytek nodes to unified nodes
-func (p *IFlytekParser) parseAllNodes(nodes []IFlytekNode) ([]*models.Node, map[string]string, error) {
+func (p *IFlytekParser) parseAllNodes(ctx context.Context, nodes []IFlytekNode) ([]*models.Node, map[string]string, error) {
 	allNodes := make([]*models.Node, 0, len(nodes))
 	nodeParentMap := make(map[string]string) // nodeID -> parentID
 
-	for _, iflytekNode := range nodes {
+	for i, iflytekNode := range nodes {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		label, _ := iflytekNode.Data["label"].(string)
+		models.ReportNodeProgress(ctx, models.ProgressStageParse, i, len(nodes), iflytekNode.ID, label)
 		node, err := p.parseIndividualNode(iflytekNode)
 		if err != nil {
 			return nil, nil, err
@@ -278,7 +325,7 @@ func (p *IFlytekParser) parseIndividualNode(iflytekNode IFlytekNode) (*models.No
 
 	if !supported {
 		// Convert unsupported nodes to code node placeholders
-		fmt.Printf("⚠️  Converting unsupported node type '%s' (ID: %s) to code node placeholder\n",
+		p.ReportIssue("Converting unsupported node type '%s' (ID: %s) to code node placeholder",
 			iflytekNode.Type, iflytekNode.ID)
 
 		return p.convertUnsupportedNodeToCodeNode(iflytekNode)
@@ -287,9 +334,47 @@ func (p *IFlytekParser) parseIndividualNode(iflytekNode IFlytekNode) (*models.No
 	// Update node output type mapping table
 	p.updateNodeOutputTypeMapping(node)
 
+	// Keep keys the parser does not model for iFlytek round trips
+	kind, _, _ := strings.Cut(iflytekNode.ID, "::")
+	common.StoreUnknownFields(&node.PlatformConfig.IFlytek, kind, iflytekNode.Unknown, unmodeledNodeData(iflytekNode.Data))
+
 	return node, nil
 }
 
+// modeledNodeDataKeys are the node data keys the parser and generator model
+var modeledNodeDataKeys = map[string]bool{
+	"allowInputReference":  true,
+	"allowOutputReference": true,
+	"label":                true,
+	"labelEdit":            true,
+	"status":               true,
+	"nodeMeta":             true,
+	"inputs":               true,
+	"outputs":              true,
+	"references":           true,
+	"nodeParam":            true,
+	"icon":                 true,
+	"description":          true,
+	"updatable":            true,
+	"parentId":             true,
+	"originPosition":       true,
+}
+
+// unmodeledNodeData returns the node data keys outside modeledNodeDataKeys.
+func unmodeledNodeData(data map[string]interface{}) map[string]interface{} {
+	var unknown map[string]interface{}
+	for key, value := range data {
+		if modeledNodeDataKeys[key] {
+			continue
+		}
+		if unknown == nil {
+			unknown = make(map[string]interface{})
+		}
+		unknown[key] = value
+	}
+	return unknown
+}
+
 // convertUnsupportedNodeToCodeNode converts unsupported nodes to code node placeholders
 func (p *IFlytekParser) convertUnsupportedNodeToCodeNode(iflytekNode IFlytekNode) (*models.Node, error) {
 	// Get node label for type description
@@ -306,17 +391,18 @@ func (p *IFlytekParser) convertUnsupportedNodeToCodeNode(iflytekNode IFlytekNode
 	if modifiedNode.Data == nil {
 		modifiedNode.Data = make(map[string]interface{})
 	}
-	modifiedNode.Data["label"] = fmt.Sprintf("暂不兼容的节点-%s（请根据需求手动实现）", nodeLabel)
+	modifiedNode.Data["label"] = common.FormatUnsupportedNodeTitle(nodeLabel)
 
 	// Set default code configuration
-	if modifiedNode.Data["nodeParam"] == nil {
-		modifiedNode.Data["nodeParam"] = make(map[string]interface{})
+	nodeParam, ok := modifiedNode.Data["nodeParam"].(map[string]interface{})
+	if !ok {
+		nodeParam = make(map[string]interface{})
+		modifiedNode.Data["nodeParam"] = nodeParam
 	}
-	nodeParam := modifiedNode.Data["nodeParam"].(map[string]interface{})
 	nodeParam["code"] = fmt.Sprintf(`# 抱歉！当前兼容性工具不支持转换此类节点: %s
 
-# 请根据业务需求手动补充实现逻辑
-`, iflytekNode.Type)
+# %s
+`, iflytekNode.Type, common.UnsupportedNodeAction)
 
 	// Create default output if none exist to maintain connections
 	if _, hasOutputs := modifiedNode.Data["outputs"]; !hasOutputs {
@@ -333,7 +419,17 @@ func (p *IFlytekParser) convertUnsupportedNodeToCodeNode(iflytekNode IFlytekNode
 	}
 
 	// Parse using code node parser
-	return codeParser.ParseNode(modifiedNode)
+	node, err := codeParser.ParseNode(modifiedNode)
+	if err != nil {
+		return nil, err
+	}
+	common.MarkPlaceholder(node, models.PlaceholderMarker{
+		SourcePlatform: models.PlatformIFlytek,
+		OriginalType:   iflytekNode.Type,
+		OriginalID:     iflytekNode.ID,
+		Action:         common.UnsupportedNodeAction,
+	})
+	return node, nil
 }
 
 // extractNodeLabel extracts node label
@@ -555,7 +651,7 @@ func (p *IFlytekParser) parseEdges(edges []IFlytekEdge, unifiedDSL *models.Unifi
 	for _, edge := range edges {
 		// Skip edges that reference non-existent (unsupported) nodes
 		if !existingNodeIDs[edge.Source] || !existingNodeIDs[edge.Target] {
-			fmt.Printf("⚠️  Skipping edge with unsupported nodes: %s -> %s\n", edge.Source, edge.Target)
+			p.ReportIssue("Skipping edge with unsupported nodes: %s -> %s", edge.Source, edge.Target)
 			continue
 		}
 
@@ -568,6 +664,7 @@ func (p *IFlytekParser) parseEdges(edges []IFlytekEdge, unifiedDSL *models.Unifi
 			"type":         edge.Type,
 			"markerEnd":    edge.MarkerEnd,
 			"data":         edge.Data,
+			"label":        edge.Label,
 		})
 	}
 
@@ -673,13 +770,16 @@ func convertNodesToPointers(nodes []models.Node) []*models.Node {
 
 // printConversionSummary prints detailed conversion statistics
 func (p *IFlytekParser) printConversionSummary(unifiedDSL *models.UnifiedDSL) {
+	if p.Quiet() {
+		return
+	}
 	totalNodes := len(unifiedDSL.Workflow.Nodes)
 	fmt.Printf("✅ Conversion Summary: All %d nodes processed successfully\n", totalNodes)
 
-	// Count nodes converted to code placeholders by checking titles
+	// Count nodes converted to code placeholders by their marker
 	convertedCount := 0
 	for _, node := range unifiedDSL.Workflow.Nodes {
-		if strings.Contains(node.Title, "暂不兼容的节点-") {
+		if common.IsPlaceholderNode(node) {
 			convertedCount++
 		}
 	}
@@ -704,6 +804,11 @@ type IFlytekFlowMeta struct {
 	AvatarColor    string `yaml:"avatarColor"`
 	AdvancedConfig string `yaml:"advancedConfig"`
 	DSLVersion     string `yaml:"dslVersion"`
+
+	// Workflow catalog metadata, not part of Spark exports
+	Tags    []string `yaml:"tags"`
+	Author  string   `yaml:"author"`
+	Version string   `yaml:"version"`
 }
 
 // IFlytekFlowData represents iFlytek SparkAgent flowData structure.
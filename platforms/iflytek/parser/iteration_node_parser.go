@@ -97,11 +97,11 @@ func (p *IterationNodeParser) parseIterationConfig(data map[string]interface{})
 		}
 		config.Iterator = *iteratorConfig
 
-		// Parse execution configuration (use default values)
+		// iFlytek runs items one at a time and stops at the first failure
 		config.Execution = models.ExecutionConfig{
-			IsParallel:      false,      // Default to serial execution
-			ParallelNums:    1,          // Default parallel number is 1
-			ErrorHandleMode: "continue", // Default error handling mode is continue
+			IsParallel:      false,
+			ParallelNums:    1,
+			ErrorHandleMode: models.ErrorHandleModeTerminated,
 		}
 
 		// Parse sub-workflow configuration
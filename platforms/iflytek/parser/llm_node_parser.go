@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 )
 
 // LLMNodeParser parses LLM nodes.
@@ -66,6 +67,12 @@ func (p *LLMNodeParser) ParseNode(iflytekNode IFlytekNode) (*models.Node, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse LLM config: %w", err)
 	}
+	if config.Parameters.ResponseFormat == models.ResponseFormatJSON {
+		config.OutputSchema = common.SchemaFromOutputs(node.Outputs)
+	}
+	if config.Vision != nil && config.Vision.Enabled {
+		config.Vision.VariableSelector = p.imageInputSelector(iflytekNode.Data, node.Inputs)
+	}
 	node.Config = config
 
 	// Save platform-specific configuration
@@ -95,6 +102,7 @@ func (p *LLMNodeParser) parseLLMConfig(data map[string]interface{}) (models.LLMC
 		p.parsePromptConfig(&config, nodeParam)
 		p.parseContextConfig(&config, nodeParam)
 		p.parseVisionConfig(&config, nodeParam)
+		config.Memory = parseChatHistory(nodeParam)
 	}
 
 	return config, nil
@@ -112,9 +120,11 @@ func (p *LLMNodeParser) parseModelConfig(config *models.LLMConfig, nodeParam map
 
 // parseModelParameters parses model parameters.
 func (p *LLMNodeParser) parseModelParameters(config *models.LLMConfig, nodeParam map[string]interface{}) {
-	// Temperature parameter - supports float64 type
+	// Temperature parameter - supports float64 and int types; whole numbers are written as integers
 	if temperature, ok := nodeParam["temperature"].(float64); ok {
 		config.Parameters.Temperature = temperature
+	} else if temperature, ok := nodeParam["temperature"].(int); ok {
+		config.Parameters.Temperature = float64(temperature)
 	}
 
 	// MaxTokens parameter - supports int and float64 types
@@ -124,6 +134,13 @@ func (p *LLMNodeParser) parseModelParameters(config *models.LLMConfig, nodeParam
 		config.Parameters.MaxTokens = int(maxTokens)
 	}
 
+	// Response format parameter - text, markdown or JSON
+	if respFormat, ok := nodeParam["respFormat"].(int); ok {
+		config.Parameters.ResponseFormat = respFormat
+	} else if respFormat, ok := nodeParam["respFormat"].(float64); ok {
+		config.Parameters.ResponseFormat = int(respFormat)
+	}
+
 	// TopK parameter - supports int and float64 types
 	if topK, ok := nodeParam["topK"].(int); ok {
 		config.Parameters.TopK = topK
@@ -151,6 +168,23 @@ func (p *LLMNodeParser) parseContextConfig(config *models.LLMConfig, nodeParam m
 	}
 }
 
+// imageInputSelector returns the reference of the image input multimodal models read.
+func (p *LLMNodeParser) imageInputSelector(data map[string]interface{}, inputs []models.Input) []string {
+	rawInputs, _ := data["inputs"].([]interface{})
+	for _, rawInput := range rawInputs {
+		inputMap, ok := rawInput.(map[string]interface{})
+		if !ok || inputMap["fileType"] != "image" {
+			continue
+		}
+		for _, input := range inputs {
+			if input.Name == inputMap["name"] && input.Reference != nil && input.Reference.Type == models.ReferenceTypeNodeOutput {
+				return []string{input.Reference.NodeID, input.Reference.OutputName}
+			}
+		}
+	}
+	return nil
+}
+
 // parseVisionConfig parses vision configuration.
 func (p *LLMNodeParser) parseVisionConfig(config *models.LLMConfig, nodeParam map[string]interface{}) {
 	if multiMode, ok := nodeParam["multiMode"].(bool); ok {
@@ -159,3 +193,23 @@ func (p *LLMNodeParser) parseVisionConfig(config *models.LLMConfig, nodeParam ma
 		}
 	}
 }
+
+// parseChatHistory maps the chat history of LLM and classifier nodes; nil when it is off.
+func parseChatHistory(nodeParam map[string]interface{}) *models.MemoryConfig {
+	chatHistory, ok := nodeParam["chatHistory"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if enabled, _ := chatHistory["isEnabled"].(bool); !enabled {
+		return nil
+	}
+
+	memory := &models.MemoryConfig{Enabled: true}
+	switch rounds := chatHistory["rounds"].(type) {
+	case int:
+		memory.Window = rounds
+	case float64:
+		memory.Window = int(rounds)
+	}
+	return memory
+}
@@ -14,6 +14,10 @@ const (
 	IFlytekNodeTypeCondition  = "分支器"
 	IFlytekNodeTypeClassifier = "决策"
 	IFlytekNodeTypeIteration  = "迭代"
+
+	IFlytekNodeTypeQuestionAnswer = "问答"
+	IFlytekNodeTypeTextToSpeech   = "语音合成"
+	IFlytekNodeTypeSpeechToText   = "语音识别"
 )
 
 // TypeProvider provides node output type querying interface
@@ -55,6 +59,15 @@ func NewParserFactory() *ParserFactory {
 	factory.Register(IFlytekNodeTypeIteration, func(vrs *models.VariableReferenceSystem, tp TypeProvider) NodeParser {
 		return NewIterationNodeParser(vrs)
 	})
+	factory.Register(IFlytekNodeTypeQuestionAnswer, func(vrs *models.VariableReferenceSystem, tp TypeProvider) NodeParser {
+		return NewQuestionAnswerNodeParser(vrs)
+	})
+	factory.Register(IFlytekNodeTypeTextToSpeech, func(vrs *models.VariableReferenceSystem, tp TypeProvider) NodeParser {
+		return NewTextToSpeechNodeParser(vrs)
+	})
+	factory.Register(IFlytekNodeTypeSpeechToText, func(vrs *models.VariableReferenceSystem, tp TypeProvider) NodeParser {
+		return NewSpeechToTextNodeParser(vrs)
+	})
 
 	return factory
 }
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// iFlytek question answer option kinds
+const questionOptionTypeDefault = 1
+
+// QuestionAnswerNodeParser parses question answer (human input) nodes.
+type QuestionAnswerNodeParser struct {
+	*CodeNodeParser
+}
+
+func NewQuestionAnswerNodeParser(variableRefSystem *models.VariableReferenceSystem) *QuestionAnswerNodeParser {
+	return &QuestionAnswerNodeParser{
+		CodeNodeParser: NewCodeNodeParser(variableRefSystem),
+	}
+}
+
+// GetSupportedType returns the supported node type.
+func (p *QuestionAnswerNodeParser) GetSupportedType() string {
+	return IFlytekNodeTypeQuestionAnswer
+}
+
+// ValidateNode validates node data.
+func (p *QuestionAnswerNodeParser) ValidateNode(iflytekNode IFlytekNode) error {
+	if iflytekNode.ID == "" {
+		return fmt.Errorf("node ID is empty")
+	}
+
+	if iflytekNode.Type != p.GetSupportedType() {
+		return fmt.Errorf("invalid node type: expected %s, got %s", p.GetSupportedType(), iflytekNode.Type)
+	}
+
+	return nil
+}
+
+// ParseNode parses a node.
+func (p *QuestionAnswerNodeParser) ParseNode(iflytekNode IFlytekNode) (*models.Node, error) {
+	if err := p.ValidateNode(iflytekNode); err != nil {
+		return nil, err
+	}
+
+	node := p.ParseBasicNodeInfo(iflytekNode, models.NodeTypeHumanInput)
+
+	if err := p.parseInputsOutputs(node, iflytekNode.Data); err != nil {
+		return nil, err
+	}
+
+	nodeParam, _ := iflytekNode.Data["nodeParam"].(map[string]interface{})
+	node.Config = p.parseQuestionAnswerConfig(nodeParam)
+
+	p.SavePlatformConfig(node, iflytekNode)
+
+	return node, nil
+}
+
+// parseQuestionAnswerConfig parses the question, answer type and options from nodeParam
+func (p *QuestionAnswerNodeParser) parseQuestionAnswerConfig(nodeParam map[string]interface{}) models.HumanInputConfig {
+	config := models.HumanInputConfig{AnswerType: models.HumanInputAnswerText}
+	config.Question, _ = nodeParam["question"].(string)
+
+	if directAnswer, ok := nodeParam["directAnswer"].(map[string]interface{}); ok {
+		if maxRetries, ok := directAnswer["maxRetryCounts"].(int); ok {
+			config.MaxRetries = maxRetries
+		} else if maxRetries, ok := directAnswer["maxRetryCounts"].(float64); ok {
+			config.MaxRetries = int(maxRetries)
+		}
+	}
+
+	if answerType, _ := nodeParam["answerType"].(string); answerType != "option" {
+		return config
+	}
+
+	config.AnswerType = models.HumanInputAnswerOption
+	options, _ := nodeParam["optionAnswer"].([]interface{})
+	for _, option := range options {
+		optionMap, ok := option.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		optionID, _ := optionMap["id"].(string)
+
+		if p.isDefaultOption(optionMap) {
+			config.DefaultOption = optionID
+			continue
+		}
+		content, _ := optionMap["content"].(string)
+		config.Options = append(config.Options, models.HumanInputOption{ID: optionID, Name: content})
+	}
+
+	return config
+}
+
+// isDefaultOption reports whether an option is the fallback taken when no other option matches
+func (p *QuestionAnswerNodeParser) isDefaultOption(option map[string]interface{}) bool {
+	switch optionType := option["type"].(type) {
+	case int:
+		return optionType == questionOptionTypeDefault
+	case float64:
+		return int(optionType) == questionOptionTypeDefault
+	}
+	return false
+}
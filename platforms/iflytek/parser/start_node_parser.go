@@ -5,6 +5,16 @@ import (
 	"github.com/iflytek/agentbridge/internal/models"
 )
 
+// Unified file categories of iFlytek file input types
+var iflytekFileTypes = map[string]string{
+	"file":  models.FileTypeDocument,
+	"pdf":   models.FileTypeDocument,
+	"doc":   models.FileTypeDocument,
+	"image": models.FileTypeImage,
+	"audio": models.FileTypeAudio,
+	"video": models.FileTypeVideo,
+}
+
 // StartNodeParser parses start nodes.
 type StartNodeParser struct {
 	*BaseNodeParser
@@ -127,6 +137,7 @@ func (p *StartNodeParser) parseVariableSchema(variable *models.Variable, outputD
 	p.parseVariableType(variable, schema)
 	p.parseVariableDefault(variable, schema)
 	p.parseVariableProperties(variable, schema)
+	p.parseVariableRules(variable, schema)
 }
 
 // parseVariableType parses data type from schema
@@ -141,17 +152,10 @@ func (p *StartNodeParser) parseVariableType(variable *models.Variable, schema ma
 	variable.Type = string(unifiedType)
 }
 
-// parseVariableDefault parses default value and updates label if meaningful
+// parseVariableDefault parses the schema default, which iFlytek start inputs use for their
+// description rather than a value, into the label
 func (p *StartNodeParser) parseVariableDefault(variable *models.Variable, schema map[string]interface{}) {
-	defaultValue, ok := schema["default"]
-	if !ok {
-		return
-	}
-
-	variable.Default = defaultValue
-
-	// If default is a non-empty string, use it as a more meaningful label
-	if defaultStr, ok := defaultValue.(string); ok && defaultStr != "" && defaultStr != variable.Name {
+	if defaultStr, ok := schema["default"].(string); ok && defaultStr != "" && defaultStr != variable.Name {
 		variable.Label = defaultStr
 	}
 }
@@ -163,6 +167,26 @@ func (p *StartNodeParser) parseVariableProperties(variable *models.Variable, sch
 	}
 }
 
+// parseVariableRules parses the maxLength and enum validation rules; an enum makes a select input
+func (p *StartNodeParser) parseVariableRules(variable *models.Variable, schema map[string]interface{}) {
+	maxLength := 0
+	switch value := schema["maxLength"].(type) {
+	case int:
+		maxLength = value
+	case float64:
+		maxLength = int(value)
+	}
+	options, _ := schema["enum"].([]interface{})
+	if maxLength <= 0 && len(options) == 0 {
+		return
+	}
+
+	variable.Constraints = &models.Constraints{MaxLength: maxLength, Options: options}
+	if len(options) > 0 {
+		variable.Control = models.VariableControlSelect
+	}
+}
+
 // parseVariableCustomType parses custom parameter type and adjusts data type accordingly
 func (p *StartNodeParser) parseVariableCustomType(variable *models.Variable, outputData map[string]interface{}) {
 	customType, ok := outputData["customParameterType"].(string)
@@ -172,10 +196,43 @@ func (p *StartNodeParser) parseVariableCustomType(variable *models.Variable, out
 
 	variable.CustomParameterType = customType
 
-	// Adjust data type based on custom type
-	if customType == "xfyun-file" {
-		variable.Type = string(models.DataTypeString)
+	// Adjust data type based on custom type. Exports also mark plain inputs xfyun-file; only those
+	// with a file type take uploads.
+	if customType == "xfyun-file" && (outputData["fileType"] != nil || outputData["allowedFileType"] != nil) {
+		p.parseFileInput(variable, outputData)
+	}
+}
+
+// parseFileInput records the accepted files of an upload input; array inputs take several files
+func (p *StartNodeParser) parseFileInput(variable *models.Variable, outputData map[string]interface{}) {
+	file := &models.FileInput{Multiple: variable.Type == string(models.DataTypeArrayString)}
+
+	fileTypes, _ := outputData["allowedFileType"].([]interface{})
+	if len(fileTypes) == 0 {
+		fileTypes = []interface{}{outputData["fileType"]}
+	}
+	for _, fileType := range fileTypes {
+		name, _ := fileType.(string)
+		if unifiedType, exists := iflytekFileTypes[name]; exists && !p.containsFileType(file.AllowedTypes, unifiedType) {
+			file.AllowedTypes = append(file.AllowedTypes, unifiedType)
+		}
+	}
+
+	variable.File = file
+	variable.Type = string(models.DataTypeString)
+	if file.Multiple {
+		variable.Type = string(models.DataTypeArrayString)
+	}
+}
+
+// containsFileType reports whether fileTypes already lists fileType
+func (p *StartNodeParser) containsFileType(fileTypes []string, fileType string) bool {
+	for _, existing := range fileTypes {
+		if existing == fileType {
+			return true
+		}
 	}
+	return false
 }
 
 // parseVariableConstraints parses constraint conditions
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/iflytek/agentbridge/core/interfaces"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	"gopkg.in/yaml.v3"
+)
+
+// Compile-time interface checks
+var (
+	_ interfaces.DSLGenerator          = (*UnifiedGenerator)(nil)
+	_ interfaces.ConfigurableGenerator = (*UnifiedGenerator)(nil)
+)
+
+// UnifiedGenerator writes the unified DSL itself as YAML, for inspection, editing and later
+// generation with --from unified.
+type UnifiedGenerator struct {
+	*common.BaseGenerator
+	outputFormat string // models.OutputFormatYAML or models.OutputFormatJSON
+}
+
+func NewUnifiedGenerator() *UnifiedGenerator {
+	return &UnifiedGenerator{
+		BaseGenerator: common.NewBaseGenerator(models.PlatformUnified),
+	}
+}
+
+// Configure applies conversion options to the generator
+func (g *UnifiedGenerator) Configure(options *models.ConversionOptions) error {
+	if options != nil {
+		g.outputFormat = options.OutputFormat
+	}
+	return nil
+}
+
+// Generate serializes the unified DSL.
+func (g *UnifiedGenerator) Generate(unifiedDSL *models.UnifiedDSL) ([]byte, error) {
+	if err := g.Validate(unifiedDSL); err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(unifiedDSL); err != nil {
+		return nil, fmt.Errorf("failed to marshal unified DSL: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal unified DSL: %w", err)
+	}
+	return common.EncodeOutput(buffer.Bytes(), g.outputFormat)
+}
+
+// Validate accepts any non-nil unified DSL, every node type has a representation.
+func (g *UnifiedGenerator) Validate(unifiedDSL *models.UnifiedDSL) error {
+	if unifiedDSL == nil {
+		return fmt.Errorf("unified DSL cannot be nil")
+	}
+	return nil
+}
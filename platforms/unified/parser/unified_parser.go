@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/core/interfaces"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	"github.com/iflytek/agentbridge/platforms/unified/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// Compile-time interface check
+var _ interfaces.DSLParser = (*UnifiedParser)(nil)
+
+// UnifiedParser reads a unified DSL exported with --to unified, as YAML or JSON.
+type UnifiedParser struct {
+	*common.BaseParser
+}
+
+func NewUnifiedParser() *UnifiedParser {
+	return &UnifiedParser{
+		BaseParser: common.NewBaseParser(models.PlatformUnified),
+	}
+}
+
+// Parse validates the document against the unified DSL schema and decodes it.
+func (p *UnifiedParser) Parse(data []byte) (*models.UnifiedDSL, error) {
+	p.ResetIssues()
+
+	document, err := p.decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	unifiedDSL := &models.UnifiedDSL{}
+	if err := document.Decode(unifiedDSL); err != nil {
+		return nil, fmt.Errorf("failed to decode unified DSL: %w", err)
+	}
+	restorePlatformConfigs(unifiedDSL.Workflow.Nodes, unifiedDSL.Workflow.Edges)
+
+	if err := p.FinishParse(unifiedDSL); err != nil {
+		return nil, err
+	}
+	return unifiedDSL, nil
+}
+
+// Validate checks that the data is a unified DSL matching the schema.
+func (p *UnifiedParser) Validate(data []byte) error {
+	_, err := p.decode(data)
+	return err
+}
+
+// decode reads the workflow document and validates it against the schema.
+func (p *UnifiedParser) decode(data []byte) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := common.UnmarshalYAMLDocument(data, &document, "unified DSL", "version", "workflow"); err != nil {
+		return nil, fmt.Errorf("invalid YAML format: %w", err)
+	}
+	if err := schema.Validate(&document); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	return &document, nil
+}
+
+// restorePlatformConfigs recreates the empty platform config maps that exports omit. Parsers
+// create them with every node and edge (see models.NewNode), and generators record state in them.
+func restorePlatformConfigs(nodes []models.Node, edges []models.Edge) {
+	for i := range nodes {
+		restorePlatformConfig(&nodes[i].PlatformConfig)
+		if iterConfig, ok := common.AsIterationConfig(nodes[i].Config); ok && iterConfig != nil {
+			restorePlatformConfigs(iterConfig.SubWorkflow.Nodes, iterConfig.SubWorkflow.Edges)
+		}
+	}
+	for i := range edges {
+		restorePlatformConfig(&edges[i].PlatformConfig)
+	}
+}
+
+func restorePlatformConfig(config *models.PlatformConfig) {
+	if config.IFlytek == nil {
+		config.IFlytek = make(map[string]interface{})
+	}
+	if config.Dify == nil {
+		config.Dify = make(map[string]interface{})
+	}
+}
@@ -0,0 +1,196 @@
+// Package schema publishes the JSON Schema of the unified DSL and validates documents against it.
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Draft is the JSON Schema dialect of the published schema
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// requiredFields lists the keys a hand-written document must set. Other keys default to their
+// zero value, so exports stay valid when fields are added.
+var requiredFields = map[reflect.Type][]string{
+	reflect.TypeOf(models.UnifiedDSL{}):         {"version", "workflow"},
+	reflect.TypeOf(models.Workflow{}):           {"nodes"},
+	reflect.TypeOf(models.Node{}):               {"id", "type"},
+	reflect.TypeOf(models.Edge{}):               {"source", "target"},
+	reflect.TypeOf(models.Input{}):              {"name"},
+	reflect.TypeOf(models.Output{}):             {"name"},
+	reflect.TypeOf(models.Variable{}):           {"name"},
+	reflect.TypeOf(models.VariableReference{}):  {"type"},
+	reflect.TypeOf(models.SubWorkflowConfig{}):  {"nodes"},
+	reflect.TypeOf(models.ConditionCase{}):      {"case_id"},
+	reflect.TypeOf(models.ClassifierClass{}):    {"id"},
+	reflect.TypeOf(models.HumanInputOption{}):   {"id"},
+	reflect.TypeOf(models.DataStoreField{}):     {"name"},
+	reflect.TypeOf(models.DataStoreCondition{}): {"field"},
+}
+
+// enumValues lists the values of the string types generators switch on
+var enumValues = map[reflect.Type][]interface{}{
+	reflect.TypeOf(models.NodeType("")):      nodeTypeValues(),
+	reflect.TypeOf(models.ReferenceType("")): {string(models.ReferenceTypeNodeOutput), string(models.ReferenceTypeLiteral), string(models.ReferenceTypeTemplate)},
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	nodeType       = reflect.TypeOf(models.Node{})
+	nodeConfigType = reflect.TypeOf((*models.NodeConfig)(nil)).Elem()
+)
+
+// Schema returns the JSON Schema of the unified DSL. It is derived from the model types of this
+// build, so it always describes what the parser reads and the generator writes.
+func Schema() map[string]interface{} {
+	builder := &schemaBuilder{defs: make(map[string]interface{})}
+	root := builder.typeSchema(reflect.TypeOf(models.UnifiedDSL{}))
+	for _, nodeType := range models.NodeTypes() {
+		if configType, ok := models.NodeConfigType(nodeType); ok {
+			builder.typeSchema(configType)
+		}
+	}
+
+	return map[string]interface{}{
+		"$schema":     Draft,
+		"title":       "AgentBridge unified DSL",
+		"description": "Intermediate workflow representation exported by --to unified and read by --from unified",
+		"$ref":        root["$ref"],
+		"$defs":       builder.defs,
+	}
+}
+
+// JSON returns the schema as indented JSON.
+func JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(Schema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaBuilder collects the definitions of the struct types met while building a schema.
+type schemaBuilder struct {
+	defs map[string]interface{}
+}
+
+func (b *schemaBuilder) typeSchema(t reflect.Type) map[string]interface{} {
+	if values, ok := enumValues[t]; ok {
+		return map[string]interface{}{"type": "string", "enum": values}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return map[string]interface{}{"anyOf": []interface{}{
+			map[string]interface{}{"type": "null"},
+			b.typeSchema(t.Elem()),
+		}}
+	case reflect.Slice:
+		return map[string]interface{}{"type": []interface{}{"array", "null"}, "items": b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": []interface{}{"object", "null"}, "additionalProperties": b.typeSchema(t.Elem())}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		b.defineStruct(t)
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// defineStruct adds the definition of a struct type, keyed by its name.
+func (b *schemaBuilder) defineStruct(t reflect.Type) {
+	if _, ok := b.defs[t.Name()]; ok {
+		return
+	}
+	// Reserve the name first, types such as Node refer to themselves through sub-workflows
+	b.defs[t.Name()] = nil
+
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if field.Type == nodeConfigType {
+			// Refined per node type below
+			properties[name] = map[string]interface{}{"type": []interface{}{"object", "null"}}
+			continue
+		}
+		properties[name] = b.typeSchema(field.Type)
+	}
+
+	definition := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if required, ok := requiredFields[t]; ok {
+		definition["required"] = toInterfaces(required)
+	}
+	if t == nodeType {
+		definition["allOf"] = b.nodeConfigRules()
+	}
+	b.defs[t.Name()] = definition
+}
+
+// nodeConfigRules ties the config of a node to the config type of its node type.
+func (b *schemaBuilder) nodeConfigRules() []interface{} {
+	var rules []interface{}
+	for _, nodeType := range models.NodeTypes() {
+		configType, ok := models.NodeConfigType(nodeType)
+		if !ok {
+			continue
+		}
+		rules = append(rules, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"type": map[string]interface{}{"const": string(nodeType)}},
+				"required":   []interface{}{"type"},
+			},
+			"then": map[string]interface{}{
+				"properties": map[string]interface{}{"config": map[string]interface{}{"anyOf": []interface{}{
+					map[string]interface{}{"type": "null"},
+					b.typeSchema(configType),
+				}}},
+			},
+		})
+	}
+	return rules
+}
+
+func nodeTypeValues() []interface{} {
+	var values []interface{}
+	for _, nodeType := range models.NodeTypes() {
+		values = append(values, string(nodeType))
+	}
+	return values
+}
+
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
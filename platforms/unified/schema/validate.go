@@ -0,0 +1,282 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxReportedViolations bounds the violations listed in a validation error
+const maxReportedViolations = 10
+
+// Violation is a place where a document does not match the schema.
+type Violation struct {
+	Path    string // e.g. workflow.nodes[2].config.model
+	Line    int
+	Message string
+}
+
+func (v Violation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "document"
+	}
+	return fmt.Sprintf("line %d: %s: %s", v.Line, path, v.Message)
+}
+
+// ValidationError lists the violations of a document.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, maxReportedViolations)
+	for i, violation := range e.Violations {
+		if i == maxReportedViolations {
+			messages = append(messages, fmt.Sprintf("and %d more", len(e.Violations)-i))
+			break
+		}
+		messages = append(messages, violation.String())
+	}
+	return fmt.Sprintf("document does not match the unified DSL schema: %s", strings.Join(messages, "; "))
+}
+
+// Validate checks a decoded YAML or JSON document against the unified DSL schema. Aliases must
+// have been expanded. It returns a *ValidationError listing every violation.
+func Validate(document *yaml.Node) error {
+	root := Schema()
+	validator := &validator{defs: root["$defs"].(map[string]interface{})}
+	if document.Kind == yaml.DocumentNode && len(document.Content) > 0 {
+		document = document.Content[0]
+	}
+	validator.validate(document, root, "")
+	if len(validator.violations) > 0 {
+		return &ValidationError{Violations: validator.violations}
+	}
+	return nil
+}
+
+// validator implements the subset of JSON Schema the published schema uses.
+type validator struct {
+	defs       map[string]interface{}
+	violations []Violation
+}
+
+func (v *validator) report(node *yaml.Node, path, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Path: path, Line: node.Line, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether node is valid against schema without recording violations.
+func (v *validator) matches(node *yaml.Node, schema map[string]interface{}, path string) bool {
+	probe := &validator{defs: v.defs}
+	probe.validate(node, schema, path)
+	return len(probe.violations) == 0
+}
+
+func (v *validator) validate(node *yaml.Node, schema map[string]interface{}, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		definition, ok := v.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		if !ok {
+			v.report(node, path, "schema reference %s not found", ref)
+			return
+		}
+		v.validate(node, definition, path)
+	}
+
+	if types, ok := schema["type"]; ok && !hasType(node, types) {
+		v.report(node, path, "expected %s, found %s", describeTypes(types), nodeTypeName(node))
+		return
+	}
+	if constant, ok := schema["const"]; ok && !scalarEquals(node, constant) {
+		v.report(node, path, "expected %v", constant)
+	}
+	if values, ok := schema["enum"].([]interface{}); ok && !inEnum(node, values) {
+		v.report(node, path, "%q is not one of %s", node.Value, describeValues(values))
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		v.validateAnyOf(node, anyOf, path)
+	}
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, item := range allOf {
+			v.validate(node, item.(map[string]interface{}), path)
+		}
+	}
+	if condition, ok := schema["if"].(map[string]interface{}); ok && v.matches(node, condition, path) {
+		if then, ok := schema["then"].(map[string]interface{}); ok {
+			v.validate(node, then, path)
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		v.validateMapping(node, schema, path)
+	case yaml.SequenceNode:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range node.Content {
+				v.validate(item, items, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+// validateAnyOf reports the violations of the closest alternative when none matches. A null
+// alternative only describes optional values, so the other alternative explains the failure.
+func (v *validator) validateAnyOf(node *yaml.Node, anyOf []interface{}, path string) {
+	var candidates []map[string]interface{}
+	for _, item := range anyOf {
+		alternative := item.(map[string]interface{})
+		if v.matches(node, alternative, path) {
+			return
+		}
+		if alternative["type"] != "null" {
+			candidates = append(candidates, alternative)
+		}
+	}
+	if len(candidates) == 1 {
+		v.validate(node, candidates[0], path)
+		return
+	}
+	v.report(node, path, "does not match any allowed form")
+}
+
+func (v *validator) validateMapping(node *yaml.Node, schema map[string]interface{}, path string) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	pairs := mappingPairs(node)
+
+	present := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		key, value := pair[0], pair[1]
+		present[key.Value] = true
+		if property, ok := properties[key.Value].(map[string]interface{}); ok {
+			v.validate(value, property, joinPath(path, key.Value))
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.report(key, path, "unknown key %q", key.Value)
+			}
+		case map[string]interface{}:
+			v.validate(value, additional, joinPath(path, key.Value))
+		}
+	}
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if !present[name.(string)] {
+				v.report(node, path, "missing required key %q", name)
+			}
+		}
+	}
+}
+
+// mappingPairs returns the key/value pairs of a mapping, with merge keys resolved.
+func mappingPairs(node *yaml.Node) [][2]*yaml.Node {
+	var pairs [][2]*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Tag == "!!merge" {
+			merged := []*yaml.Node{value}
+			if value.Kind == yaml.SequenceNode {
+				merged = value.Content
+			}
+			for _, mapping := range merged {
+				if mapping.Kind == yaml.MappingNode {
+					pairs = append(pairs, mappingPairs(mapping)...)
+				}
+			}
+			continue
+		}
+		pairs = append(pairs, [2]*yaml.Node{key, value})
+	}
+	return pairs
+}
+
+func hasType(node *yaml.Node, types interface{}) bool {
+	if list, ok := types.([]interface{}); ok {
+		for _, item := range list {
+			if hasType(node, item) {
+				return true
+			}
+		}
+		return false
+	}
+
+	name := nodeTypeName(node)
+	switch types {
+	case "number":
+		return name == "number" || name == "integer"
+	case "string":
+		// Timestamps are plain strings in JSON
+		return name == "string" || node.Tag == "!!timestamp"
+	default:
+		return name == types
+	}
+}
+
+// nodeTypeName returns the JSON type of a YAML node.
+func nodeTypeName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.ShortTag() {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!timestamp":
+		return "timestamp"
+	default:
+		return "string"
+	}
+}
+
+func scalarEquals(node *yaml.Node, value interface{}) bool {
+	return node.Kind == yaml.ScalarNode && node.Value == fmt.Sprint(value)
+}
+
+func inEnum(node *yaml.Node, values []interface{}) bool {
+	for _, value := range values {
+		if scalarEquals(node, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeTypes(types interface{}) string {
+	if list, ok := types.([]interface{}); ok {
+		names := make([]string, len(list))
+		for i, item := range list {
+			names[i] = fmt.Sprint(item)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+func describeValues(values []interface{}) string {
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = fmt.Sprint(value)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,42 @@
+package strategies
+
+import (
+	"github.com/iflytek/agentbridge/core/interfaces"
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	unifiedGenerator "github.com/iflytek/agentbridge/platforms/unified/generator"
+	unifiedParser "github.com/iflytek/agentbridge/platforms/unified/parser"
+)
+
+// UnifiedStrategy reads and writes the unified DSL, the intermediate format of every conversion.
+type UnifiedStrategy struct {
+	validator *common.UnifiedDSLValidator
+}
+
+// NewUnifiedStrategy creates the unified DSL strategy.
+func NewUnifiedStrategy() services.PlatformStrategy {
+	return &UnifiedStrategy{
+		validator: common.NewUnifiedDSLValidator(),
+	}
+}
+
+// GetPlatformType returns the unified pseudo-platform.
+func (s *UnifiedStrategy) GetPlatformType() models.PlatformType {
+	return models.PlatformUnified
+}
+
+// CreateParser creates a unified DSL parser instance.
+func (s *UnifiedStrategy) CreateParser() (interfaces.DSLParser, error) {
+	return unifiedParser.NewUnifiedParser(), nil
+}
+
+// CreateGenerator creates a unified DSL generator instance.
+func (s *UnifiedStrategy) CreateGenerator() (interfaces.DSLGenerator, error) {
+	return unifiedGenerator.NewUnifiedGenerator(), nil
+}
+
+// GetValidator returns the DSL validator instance.
+func (s *UnifiedStrategy) GetValidator() *common.UnifiedDSLValidator {
+	return s.validator
+}
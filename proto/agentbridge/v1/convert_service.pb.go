@@ -0,0 +1,874 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: agentbridge/v1/convert_service.proto
+
+package agentbridgev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ConvertRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The DSL file: YAML, JSON or a Coze ZIP export
+	Source []byte `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// Source platform (iflytek|dify|coze|unified), detected when empty
+	From string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	// Target platform (iflytek|dify|coze|unified)
+	To      string             `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Options *ConversionOptions `protobuf:"bytes,4,opt,name=options,proto3" json:"options,omitempty"`
+}
+
+func (x *ConvertRequest) Reset() {
+	*x = ConvertRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentbridge_v1_convert_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertRequest) ProtoMessage() {}
+
+func (x *ConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentbridge_v1_convert_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertRequest.ProtoReflect.Descriptor instead.
+func (*ConvertRequest) Descriptor() ([]byte, []int) {
+	return file_agentbridge_v1_convert_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ConvertRequest) GetSource() []byte {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetOptions() *ConversionOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+// ConversionOptions override the defaults the server was started with.
+type ConversionOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TargetVersion       string `protobuf:"bytes,1,opt,name=target_version,json=targetVersion,proto3" json:"target_version,omitempty"`
+	KeepTitles          *bool  `protobuf:"varint,2,opt,name=keep_titles,json=keepTitles,proto3,oneof" json:"keep_titles,omitempty"`
+	TitlePrefix         string `protobuf:"bytes,3,opt,name=title_prefix,json=titlePrefix,proto3" json:"title_prefix,omitempty"`
+	TitleSuffix         string `protobuf:"bytes,4,opt,name=title_suffix,json=titleSuffix,proto3" json:"title_suffix,omitempty"`
+	PlaceholderStrategy string `protobuf:"bytes,5,opt,name=placeholder_strategy,json=placeholderStrategy,proto3" json:"placeholder_strategy,omitempty"`
+	AudioStrategy       string `protobuf:"bytes,6,opt,name=audio_strategy,json=audioStrategy,proto3" json:"audio_strategy,omitempty"`
+	DefaultIntent       string `protobuf:"bytes,7,opt,name=default_intent,json=defaultIntent,proto3" json:"default_intent,omitempty"`
+	Anonymize           *bool  `protobuf:"varint,8,opt,name=anonymize,proto3,oneof" json:"anonymize,omitempty"`
+	Minify              *bool  `protobuf:"varint,9,opt,name=minify,proto3,oneof" json:"minify,omitempty"`
+	// yaml or json
+	OutputFormat string `protobuf:"bytes,10,opt,name=output_format,json=outputFormat,proto3" json:"output_format,omitempty"`
+	// Increment the workflow version of the export: major, minor or patch
+	BumpVersion string `protobuf:"bytes,11,opt,name=bump_version,json=bumpVersion,proto3" json:"bump_version,omitempty"`
+	// Set the updated time of the export to the conversion time
+	Touch *bool `protobuf:"varint,12,opt,name=touch,proto3,oneof" json:"touch,omitempty"`
+	// Schedule and event trigger handling on iFlytek and Dify: comment or placeholder
+	TriggerStrategy string `protobuf:"bytes,13,opt,name=trigger_strategy,json=triggerStrategy,proto3" json:"trigger_strategy,omitempty"`
+}
+
+func (x *ConversionOptions) Reset() {
+	*x = ConversionOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentbridge_v1_convert_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConversionOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversionOptions) ProtoMessage() {}
+
+func (x *ConversionOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_agentbridge_v1_convert_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversionOptions.ProtoReflect.Descriptor instead.
+func (*ConversionOptions) Descriptor() ([]byte, []int) {
+	return file_agentbridge_v1_convert_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ConversionOptions) GetTargetVersion() string {
+	if x != nil {
+		return x.TargetVersion
+	}
+	return ""
+}
+
+func (x *ConversionOptions) GetKeepTitles() bool {
+	if x != nil && x.KeepTitles != nil {
+		return *x.KeepTitles
+	}
+	return false
+}
+
+func (x *ConversionOptions) GetTitlePrefix() string {
+	if x != nil {
+		return x.TitlePrefix
+	}
+	return ""
+}
+
+func (x *ConversionOptions) GetTitleSuffix() string {
+	if x != nil {
+		return x.TitleSuffix
+	}
+	return ""
+}
+
+func (x *ConversionOptions) GetPlaceholderStrategy() string {
+	if x != nil {
+		return x.PlaceholderStrategy
+	}
+	return ""
+}
+
+func (x *ConversionOptions) GetAudioStrategy() string {
+	if x != nil {
+		return x.AudioStrategy
+	}
+	return ""
+}
+
+func (x *ConversionOptions) GetDefaultIntent() string {
+	if x != nil {
+		return x.DefaultIntent
+	}
+	return ""
+}
+
+func (x *ConversionOptions) GetAnonymize() bool {
+	if x != nil && x.Anonymize != nil {
+		return *x.Anonymize
+	}
+	return false
+}
+
+func (x *ConversionOptions) GetMinify() bool {
+	if x != nil && x.Minify != nil {
+		return *x.Minify
+	}
+	return false
+}
+
+func (x *ConversionOptions) GetOutputFormat() string {
+	if x != nil {
+		return x.OutputFormat
+	}
+	return ""
+}
+
+func (x *ConversionOptions) GetBumpVersion() string {
+	if x != nil {
+		return x.BumpVersion
+	}
+	return ""
+}
+
+func (x *ConversionOptions) GetTouch() bool {
+	if x != nil && x.Touch != nil {
+		return *x.Touch
+	}
+	return false
+}
+
+func (x *ConversionOptions) GetTriggerStrategy() string {
+	if x != nil {
+		return x.TriggerStrategy
+	}
+	return ""
+}
+
+type ConvertResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Output       []byte   `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	Source       string   `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Target       string   `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	Warnings     []string `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Placeholders int32    `protobuf:"varint,5,opt,name=placeholders,proto3" json:"placeholders,omitempty"`
+	// Source node ID -> target node ID
+	NodeMapping map[string]string `protobuf:"bytes,6,rep,name=node_mapping,json=nodeMapping,proto3" json:"node_mapping,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *ConvertResponse) Reset() {
+	*x = ConvertResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentbridge_v1_convert_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertResponse) ProtoMessage() {}
+
+func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentbridge_v1_convert_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertResponse.ProtoReflect.Descriptor instead.
+func (*ConvertResponse) Descriptor() ([]byte, []int) {
+	return file_agentbridge_v1_convert_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConvertResponse) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ConvertResponse) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *ConvertResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetPlaceholders() int32 {
+	if x != nil {
+		return x.Placeholders
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetNodeMapping() map[string]string {
+	if x != nil {
+		return x.NodeMapping
+	}
+	return nil
+}
+
+type ConvertEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*ConvertEvent_Progress
+	//	*ConvertEvent_Result
+	Event isConvertEvent_Event `protobuf_oneof:"event"`
+}
+
+func (x *ConvertEvent) Reset() {
+	*x = ConvertEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentbridge_v1_convert_service_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConvertEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertEvent) ProtoMessage() {}
+
+func (x *ConvertEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_agentbridge_v1_convert_service_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertEvent.ProtoReflect.Descriptor instead.
+func (*ConvertEvent) Descriptor() ([]byte, []int) {
+	return file_agentbridge_v1_convert_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (m *ConvertEvent) GetEvent() isConvertEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *ConvertEvent) GetProgress() *Progress {
+	if x, ok := x.GetEvent().(*ConvertEvent_Progress); ok {
+		return x.Progress
+	}
+	return nil
+}
+
+func (x *ConvertEvent) GetResult() *ConvertResponse {
+	if x, ok := x.GetEvent().(*ConvertEvent_Result); ok {
+		return x.Result
+	}
+	return nil
+}
+
+type isConvertEvent_Event interface {
+	isConvertEvent_Event()
+}
+
+type ConvertEvent_Progress struct {
+	Progress *Progress `protobuf:"bytes,1,opt,name=progress,proto3,oneof"`
+}
+
+type ConvertEvent_Result struct {
+	Result *ConvertResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*ConvertEvent_Progress) isConvertEvent_Event() {}
+
+func (*ConvertEvent_Result) isConvertEvent_Event() {}
+
+// Progress mirrors models.ProgressEvent.
+type Progress struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// parse, transform, generate or done
+	Stage     string `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	Percent   int32  `protobuf:"varint,2,opt,name=percent,proto3" json:"percent,omitempty"`
+	NodeId    string `protobuf:"bytes,3,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	NodeTitle string `protobuf:"bytes,4,opt,name=node_title,json=nodeTitle,proto3" json:"node_title,omitempty"`
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentbridge_v1_convert_service_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_agentbridge_v1_convert_service_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_agentbridge_v1_convert_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Progress) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *Progress) GetPercent() int32 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *Progress) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *Progress) GetNodeTitle() string {
+	if x != nil {
+		return x.NodeTitle
+	}
+	return ""
+}
+
+type ParseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Source []byte `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// Source platform, detected when empty
+	From string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+}
+
+func (x *ParseRequest) Reset() {
+	*x = ParseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentbridge_v1_convert_service_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseRequest) ProtoMessage() {}
+
+func (x *ParseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentbridge_v1_convert_service_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseRequest.ProtoReflect.Descriptor instead.
+func (*ParseRequest) Descriptor() ([]byte, []int) {
+	return file_agentbridge_v1_convert_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ParseRequest) GetSource() []byte {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *ParseRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+type ParseResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Platform string      `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	Dsl      *UnifiedDSL `protobuf:"bytes,2,opt,name=dsl,proto3" json:"dsl,omitempty"`
+}
+
+func (x *ParseResponse) Reset() {
+	*x = ParseResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentbridge_v1_convert_service_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseResponse) ProtoMessage() {}
+
+func (x *ParseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentbridge_v1_convert_service_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseResponse.ProtoReflect.Descriptor instead.
+func (*ParseResponse) Descriptor() ([]byte, []int) {
+	return file_agentbridge_v1_convert_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ParseResponse) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *ParseResponse) GetDsl() *UnifiedDSL {
+	if x != nil {
+		return x.Dsl
+	}
+	return nil
+}
+
+var File_agentbridge_v1_convert_service_proto protoreflect.FileDescriptor
+
+var file_agentbridge_v1_convert_service_proto_rawDesc = []byte{
+	0x0a, 0x24, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2f, 0x76, 0x31,
+	0x2f, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72, 0x69,
+	0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x1a, 0x20, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72, 0x69,
+	0x64, 0x67, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x75, 0x6e, 0x69, 0x66, 0x69, 0x65, 0x64, 0x5f, 0x64,
+	0x73, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x89, 0x01, 0x0a, 0x0e, 0x43, 0x6f, 0x6e,
+	0x76, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x3b, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
+	0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x22, 0xa8, 0x04, 0x0a, 0x11, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x24, 0x0a, 0x0b, 0x6b, 0x65, 0x65, 0x70, 0x5f, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x0a, 0x6b, 0x65, 0x65, 0x70, 0x54, 0x69,
+	0x74, 0x6c, 0x65, 0x73, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x69, 0x74, 0x6c, 0x65,
+	0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x74,
+	0x69, 0x74, 0x6c, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x69,
+	0x74, 0x6c, 0x65, 0x5f, 0x73, 0x75, 0x66, 0x66, 0x69, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x53, 0x75, 0x66, 0x66, 0x69, 0x78, 0x12, 0x31, 0x0a,
+	0x14, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x5f, 0x73, 0x74, 0x72,
+	0x61, 0x74, 0x65, 0x67, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x70, 0x6c, 0x61,
+	0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79,
+	0x12, 0x25, 0x0a, 0x0e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x5f, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65,
+	0x67, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x53,
+	0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x65, 0x66, 0x61, 0x75,
+	0x6c, 0x74, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0d, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x21,
+	0x0a, 0x09, 0x61, 0x6e, 0x6f, 0x6e, 0x79, 0x6d, 0x69, 0x7a, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x08, 0x48, 0x01, 0x52, 0x09, 0x61, 0x6e, 0x6f, 0x6e, 0x79, 0x6d, 0x69, 0x7a, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x1b, 0x0a, 0x06, 0x6d, 0x69, 0x6e, 0x69, 0x66, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x08, 0x48, 0x02, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x69, 0x66, 0x79, 0x88, 0x01, 0x01, 0x12, 0x23,
+	0x0a, 0x0d, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x46, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x62, 0x75, 0x6d, 0x70, 0x5f, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x62, 0x75, 0x6d, 0x70, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x05, 0x74, 0x6f, 0x75, 0x63, 0x68, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x08, 0x48, 0x03, 0x52, 0x05, 0x74, 0x6f, 0x75, 0x63, 0x68, 0x88, 0x01,
+	0x01, 0x12, 0x29, 0x0a, 0x10, 0x74, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x5f, 0x73, 0x74, 0x72,
+	0x61, 0x74, 0x65, 0x67, 0x79, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x74, 0x72, 0x69,
+	0x67, 0x67, 0x65, 0x72, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x42, 0x0e, 0x0a, 0x0c,
+	0x5f, 0x6b, 0x65, 0x65, 0x70, 0x5f, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x73, 0x42, 0x0c, 0x0a, 0x0a,
+	0x5f, 0x61, 0x6e, 0x6f, 0x6e, 0x79, 0x6d, 0x69, 0x7a, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6d,
+	0x69, 0x6e, 0x69, 0x66, 0x79, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x74, 0x6f, 0x75, 0x63, 0x68, 0x22,
+	0xae, 0x02, 0x0a, 0x0f, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x77,
+	0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x77,
+	0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x6c, 0x61, 0x63, 0x65,
+	0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x70,
+	0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x73, 0x12, 0x53, 0x0a, 0x0c, 0x6e,
+	0x6f, 0x64, 0x65, 0x5f, 0x6d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x30, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x0b, 0x6e, 0x6f, 0x64, 0x65, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67,
+	0x1a, 0x3e, 0x0a, 0x10, 0x4e, 0x6f, 0x64, 0x65, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0x8a, 0x01, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x12, 0x36, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72, 0x69, 0x64, 0x67,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x48, 0x00, 0x52,
+	0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x39, 0x0a, 0x06, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x67, 0x65, 0x6e,
+	0x74, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65,
+	0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x72, 0x0a,
+	0x08, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61,
+	0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x67, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6e, 0x6f, 0x64,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6e, 0x6f, 0x64, 0x65,
+	0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x74, 0x69, 0x74, 0x6c, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x6f, 0x64, 0x65, 0x54, 0x69, 0x74, 0x6c,
+	0x65, 0x22, 0x3a, 0x0a, 0x0c, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f,
+	0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x22, 0x59, 0x0a,
+	0x0d, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x2c, 0x0a, 0x03, 0x64, 0x73,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62,
+	0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x6e, 0x69, 0x66, 0x69, 0x65, 0x64,
+	0x44, 0x53, 0x4c, 0x52, 0x03, 0x64, 0x73, 0x6c, 0x32, 0xf3, 0x01, 0x0a, 0x0e, 0x43, 0x6f, 0x6e,
+	0x76, 0x65, 0x72, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4a, 0x0a, 0x07, 0x43,
+	0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x12, 0x1e, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72,
+	0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72,
+	0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x76, 0x65,
+	0x72, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x1e, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
+	0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
+	0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72,
+	0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x12, 0x44, 0x0a, 0x05, 0x50, 0x61, 0x72, 0x73,
+	0x65, 0x12, 0x1c, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1d, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x43,
+	0x5a, 0x41, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x69, 0x66, 0x6c,
+	0x79, 0x74, 0x65, 0x6b, 0x2f, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72, 0x69, 0x64,
+	0x67, 0x65, 0x2f, 0x76, 0x31, 0x3b, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x62, 0x72, 0x69, 0x64, 0x67,
+	0x65, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_agentbridge_v1_convert_service_proto_rawDescOnce sync.Once
+	file_agentbridge_v1_convert_service_proto_rawDescData = file_agentbridge_v1_convert_service_proto_rawDesc
+)
+
+func file_agentbridge_v1_convert_service_proto_rawDescGZIP() []byte {
+	file_agentbridge_v1_convert_service_proto_rawDescOnce.Do(func() {
+		file_agentbridge_v1_convert_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_agentbridge_v1_convert_service_proto_rawDescData)
+	})
+	return file_agentbridge_v1_convert_service_proto_rawDescData
+}
+
+var file_agentbridge_v1_convert_service_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_agentbridge_v1_convert_service_proto_goTypes = []any{
+	(*ConvertRequest)(nil),    // 0: agentbridge.v1.ConvertRequest
+	(*ConversionOptions)(nil), // 1: agentbridge.v1.ConversionOptions
+	(*ConvertResponse)(nil),   // 2: agentbridge.v1.ConvertResponse
+	(*ConvertEvent)(nil),      // 3: agentbridge.v1.ConvertEvent
+	(*Progress)(nil),          // 4: agentbridge.v1.Progress
+	(*ParseRequest)(nil),      // 5: agentbridge.v1.ParseRequest
+	(*ParseResponse)(nil),     // 6: agentbridge.v1.ParseResponse
+	nil,                       // 7: agentbridge.v1.ConvertResponse.NodeMappingEntry
+	(*UnifiedDSL)(nil),        // 8: agentbridge.v1.UnifiedDSL
+}
+var file_agentbridge_v1_convert_service_proto_depIdxs = []int32{
+	1, // 0: agentbridge.v1.ConvertRequest.options:type_name -> agentbridge.v1.ConversionOptions
+	7, // 1: agentbridge.v1.ConvertResponse.node_mapping:type_name -> agentbridge.v1.ConvertResponse.NodeMappingEntry
+	4, // 2: agentbridge.v1.ConvertEvent.progress:type_name -> agentbridge.v1.Progress
+	2, // 3: agentbridge.v1.ConvertEvent.result:type_name -> agentbridge.v1.ConvertResponse
+	8, // 4: agentbridge.v1.ParseResponse.dsl:type_name -> agentbridge.v1.UnifiedDSL
+	0, // 5: agentbridge.v1.ConvertService.Convert:input_type -> agentbridge.v1.ConvertRequest
+	0, // 6: agentbridge.v1.ConvertService.ConvertStream:input_type -> agentbridge.v1.ConvertRequest
+	5, // 7: agentbridge.v1.ConvertService.Parse:input_type -> agentbridge.v1.ParseRequest
+	2, // 8: agentbridge.v1.ConvertService.Convert:output_type -> agentbridge.v1.ConvertResponse
+	3, // 9: agentbridge.v1.ConvertService.ConvertStream:output_type -> agentbridge.v1.ConvertEvent
+	6, // 10: agentbridge.v1.ConvertService.Parse:output_type -> agentbridge.v1.ParseResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_agentbridge_v1_convert_service_proto_init() }
+func file_agentbridge_v1_convert_service_proto_init() {
+	if File_agentbridge_v1_convert_service_proto != nil {
+		return
+	}
+	file_agentbridge_v1_unified_dsl_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_agentbridge_v1_convert_service_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ConvertRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_agentbridge_v1_convert_service_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ConversionOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_agentbridge_v1_convert_service_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ConvertResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_agentbridge_v1_convert_service_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*ConvertEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_agentbridge_v1_convert_service_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*Progress); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_agentbridge_v1_convert_service_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ParseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_agentbridge_v1_convert_service_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*ParseResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_agentbridge_v1_convert_service_proto_msgTypes[1].OneofWrappers = []any{}
+	file_agentbridge_v1_convert_service_proto_msgTypes[3].OneofWrappers = []any{
+		(*ConvertEvent_Progress)(nil),
+		(*ConvertEvent_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_agentbridge_v1_convert_service_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_agentbridge_v1_convert_service_proto_goTypes,
+		DependencyIndexes: file_agentbridge_v1_convert_service_proto_depIdxs,
+		MessageInfos:      file_agentbridge_v1_convert_service_proto_msgTypes,
+	}.Build()
+	File_agentbridge_v1_convert_service_proto = out.File
+	file_agentbridge_v1_convert_service_proto_rawDesc = nil
+	file_agentbridge_v1_convert_service_proto_goTypes = nil
+	file_agentbridge_v1_convert_service_proto_depIdxs = nil
+}
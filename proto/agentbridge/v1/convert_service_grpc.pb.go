@@ -0,0 +1,224 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: agentbridge/v1/convert_service.proto
+
+package agentbridgev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	ConvertService_Convert_FullMethodName       = "/agentbridge.v1.ConvertService/Convert"
+	ConvertService_ConvertStream_FullMethodName = "/agentbridge.v1.ConvertService/ConvertStream"
+	ConvertService_Parse_FullMethodName         = "/agentbridge.v1.ConvertService/Parse"
+)
+
+// ConvertServiceClient is the client API for ConvertService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ConvertService converts workflow DSL files between platforms, like the convert command.
+type ConvertServiceClient interface {
+	// Convert converts a DSL file and returns the target file.
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
+	// ConvertStream converts a DSL file, streaming its progress before the result.
+	ConvertStream(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (ConvertService_ConvertStreamClient, error)
+	// Parse returns the unified DSL of a DSL file.
+	Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error)
+}
+
+type convertServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewConvertServiceClient(cc grpc.ClientConnInterface) ConvertServiceClient {
+	return &convertServiceClient{cc}
+}
+
+func (c *convertServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConvertResponse)
+	err := c.cc.Invoke(ctx, ConvertService_Convert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *convertServiceClient) ConvertStream(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (ConvertService_ConvertStreamClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ConvertService_ServiceDesc.Streams[0], ConvertService_ConvertStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &convertServiceConvertStreamClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ConvertService_ConvertStreamClient interface {
+	Recv() (*ConvertEvent, error)
+	grpc.ClientStream
+}
+
+type convertServiceConvertStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *convertServiceConvertStreamClient) Recv() (*ConvertEvent, error) {
+	m := new(ConvertEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *convertServiceClient) Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParseResponse)
+	err := c.cc.Invoke(ctx, ConvertService_Parse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ConvertServiceServer is the server API for ConvertService service.
+// All implementations must embed UnimplementedConvertServiceServer
+// for forward compatibility
+//
+// ConvertService converts workflow DSL files between platforms, like the convert command.
+type ConvertServiceServer interface {
+	// Convert converts a DSL file and returns the target file.
+	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
+	// ConvertStream converts a DSL file, streaming its progress before the result.
+	ConvertStream(*ConvertRequest, ConvertService_ConvertStreamServer) error
+	// Parse returns the unified DSL of a DSL file.
+	Parse(context.Context, *ParseRequest) (*ParseResponse, error)
+	mustEmbedUnimplementedConvertServiceServer()
+}
+
+// UnimplementedConvertServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedConvertServiceServer struct {
+}
+
+func (UnimplementedConvertServiceServer) Convert(context.Context, *ConvertRequest) (*ConvertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedConvertServiceServer) ConvertStream(*ConvertRequest, ConvertService_ConvertStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ConvertStream not implemented")
+}
+func (UnimplementedConvertServiceServer) Parse(context.Context, *ParseRequest) (*ParseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Parse not implemented")
+}
+func (UnimplementedConvertServiceServer) mustEmbedUnimplementedConvertServiceServer() {}
+
+// UnsafeConvertServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ConvertServiceServer will
+// result in compilation errors.
+type UnsafeConvertServiceServer interface {
+	mustEmbedUnimplementedConvertServiceServer()
+}
+
+func RegisterConvertServiceServer(s grpc.ServiceRegistrar, srv ConvertServiceServer) {
+	s.RegisterService(&ConvertService_ServiceDesc, srv)
+}
+
+func _ConvertService_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConvertServiceServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConvertService_Convert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConvertServiceServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConvertService_ConvertStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ConvertRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConvertServiceServer).ConvertStream(m, &convertServiceConvertStreamServer{ServerStream: stream})
+}
+
+type ConvertService_ConvertStreamServer interface {
+	Send(*ConvertEvent) error
+	grpc.ServerStream
+}
+
+type convertServiceConvertStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *convertServiceConvertStreamServer) Send(m *ConvertEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ConvertService_Parse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConvertServiceServer).Parse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConvertService_Parse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConvertServiceServer).Parse(ctx, req.(*ParseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ConvertService_ServiceDesc is the grpc.ServiceDesc for ConvertService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ConvertService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "agentbridge.v1.ConvertService",
+	HandlerType: (*ConvertServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Convert",
+			Handler:    _ConvertService_Convert_Handler,
+		},
+		{
+			MethodName: "Parse",
+			Handler:    _ConvertService_Parse_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ConvertStream",
+			Handler:       _ConvertService_ConvertStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "agentbridge/v1/convert_service.proto",
+}
@@ -0,0 +1,4 @@
+// Package agentbridgev1 holds the protobuf form of the unified DSL and the gRPC ConvertService.
+package agentbridgev1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative agentbridge/v1/unified_dsl.proto agentbridge/v1/convert_service.proto
@@ -0,0 +1,102 @@
+package benchmark
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// budgetEnv enables the performance budget check, which takes about ten seconds
+const budgetEnv = "AGENTBRIDGE_PERF_BUDGET"
+
+// performanceBudgets caps one generation or parse per platform and workflow size. Each budget is
+// three times the measured duration, rounded up, with a floor of 50ms against timer noise on small
+// workflows. Every operation scales about linearly with the workflow size; a budget that no longer
+// fits points to a new hotspot rather than a slow runner. Measure again when an operation speeds up.
+var performanceBudgets = map[string]time.Duration{
+	"generate/iflytek/10":   50 * time.Millisecond,
+	"generate/iflytek/100":  150 * time.Millisecond,
+	"generate/iflytek/1000": 2 * time.Second,
+	"generate/dify/10":      50 * time.Millisecond,
+	"generate/dify/100":     150 * time.Millisecond,
+	"generate/dify/1000":    1500 * time.Millisecond,
+	"generate/coze/10":      50 * time.Millisecond,
+	"generate/coze/100":     300 * time.Millisecond,
+	"generate/coze/1000":    3500 * time.Millisecond,
+	"parse/iflytek/10":      50 * time.Millisecond,
+	"parse/iflytek/100":     150 * time.Millisecond,
+	"parse/iflytek/1000":    1 * time.Second,
+	"parse/dify/10":         50 * time.Millisecond,
+	"parse/dify/100":        150 * time.Millisecond,
+	"parse/dify/1000":       1500 * time.Millisecond,
+	"parse/coze/10":         50 * time.Millisecond,
+	"parse/coze/100":        250 * time.Millisecond,
+	"parse/coze/1000":       2500 * time.Millisecond,
+}
+
+// TestPerformanceBudget fails when an operation exceeds its budget. Small workflows take the best
+// of three runs to smooth out noise.
+func TestPerformanceBudget(t *testing.T) {
+	if os.Getenv(budgetEnv) == "" {
+		t.Skipf("set %s=1 to run the performance budget check", budgetEnv)
+	}
+
+	for _, platform := range platformCases {
+		for _, size := range workflowSizes {
+			unifiedDSL := BuildChainWorkflow(size)
+			generator, err := platform.strategy.CreateGenerator()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkBudget(t, fmt.Sprintf("generate/%s/%d", platform.name, size), size, func() error {
+				_, err := generator.Generate(unifiedDSL)
+				return err
+			})
+
+			data, err := platform.sourceData(size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parser, err := platform.strategy.CreateParser()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkBudget(t, fmt.Sprintf("parse/%s/%d", platform.name, size), size, func() error {
+				_, err := parser.Parse(data)
+				return err
+			})
+		}
+	}
+}
+
+func checkBudget(t *testing.T, name string, size int, operation func() error) {
+	t.Helper()
+
+	budget, exists := performanceBudgets[name]
+	if !exists {
+		t.Fatalf("no performance budget for %s", name)
+	}
+
+	runs := 3
+	if size >= 1000 {
+		runs = 1
+	}
+
+	var best time.Duration
+	for i := 0; i < runs; i++ {
+		start := time.Now()
+		if err := operation(); err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		if elapsed := time.Since(start); i == 0 || elapsed < best {
+			best = elapsed
+		}
+	}
+
+	if best > budget {
+		t.Errorf("%s took %v, over its budget of %v", name, best, budget)
+		return
+	}
+	t.Logf("%s: %v (budget %v)", name, best, budget)
+}
@@ -0,0 +1,79 @@
+package benchmark
+
+import (
+	"fmt"
+	"testing"
+
+	difyGenerator "github.com/iflytek/agentbridge/platforms/dify/generator"
+)
+
+// BenchmarkGenerate measures target DSL generation from unified workflows of each size.
+func BenchmarkGenerate(b *testing.B) {
+	for _, platform := range platformCases {
+		for _, size := range workflowSizes {
+			b.Run(fmt.Sprintf("%s/%d", platform.name, size), func(b *testing.B) {
+				unifiedDSL := BuildChainWorkflow(size)
+				generator, err := platform.strategy.CreateGenerator()
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := generator.Generate(unifiedDSL); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkParse measures parsing platform DSL of each size into the unified format.
+func BenchmarkParse(b *testing.B) {
+	for _, platform := range platformCases {
+		for _, size := range workflowSizes {
+			b.Run(fmt.Sprintf("%s/%d", platform.name, size), func(b *testing.B) {
+				data, err := platform.sourceData(size)
+				if err != nil {
+					b.Fatal(err)
+				}
+				parser, err := platform.strategy.CreateParser()
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				b.ReportAllocs()
+				b.SetBytes(int64(len(data)))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := parser.Parse(data); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkGenerateIterationNodes measures Dify generation of iteration sub-workflows, whose
+// internal node templates are rewritten to reference the current iteration item.
+func BenchmarkGenerateIterationNodes(b *testing.B) {
+	for _, size := range workflowSizes {
+		b.Run(fmt.Sprintf("dify/%d", size), func(b *testing.B) {
+			unifiedDSL := BuildIterationWorkflow(size)
+			iterationNode := unifiedDSL.Workflow.Nodes[2]
+			generator := difyGenerator.NewIterationNodeGenerator()
+			generator.SetNodeMapping(unifiedDSL.Workflow.Nodes)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := generator.GenerateIterationNodes(iterationNode); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
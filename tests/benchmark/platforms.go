@@ -0,0 +1,45 @@
+package benchmark
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/core/interfaces"
+	"github.com/iflytek/agentbridge/internal/models"
+	cozeStrategies "github.com/iflytek/agentbridge/platforms/coze/strategies"
+	difyStrategies "github.com/iflytek/agentbridge/platforms/dify/strategies"
+	iflytekStrategies "github.com/iflytek/agentbridge/platforms/iflytek/strategies"
+)
+
+// platformStrategy creates the parser and generator of a platform
+type platformStrategy interface {
+	CreateParser() (interfaces.DSLParser, error)
+	CreateGenerator() (interfaces.DSLGenerator, error)
+}
+
+// platformCase describes a platform covered by the benchmarks
+type platformCase struct {
+	name     string
+	strategy platformStrategy
+	// Node types of the workflows parsed back; Coze LLM nodes written by the generator
+	// lack the llmParam block the parser requires, so Coze parsing uses code nodes only
+	parseChainTypes []models.NodeType
+}
+
+var platformCases = []platformCase{
+	{name: "iflytek", strategy: iflytekStrategies.NewIFlytekStrategy()},
+	{name: "dify", strategy: difyStrategies.NewDifyStrategy()},
+	{name: "coze", strategy: cozeStrategies.NewCozeStrategy(), parseChainTypes: []models.NodeType{models.NodeTypeCode}},
+}
+
+// sourceData generates platform DSL of a chain workflow with nodeCount nodes to parse back
+func (c platformCase) sourceData(nodeCount int) ([]byte, error) {
+	generator, err := c.strategy.CreateGenerator()
+	if err != nil {
+		return nil, err
+	}
+	data, err := generator.Generate(BuildChainWorkflow(nodeCount, c.parseChainTypes...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s source data: %w", c.name, err)
+	}
+	return data, nil
+}
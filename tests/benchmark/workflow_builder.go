@@ -0,0 +1,211 @@
+package benchmark
+
+import (
+	"fmt"
+
+	"github.com/iflytek/agentbridge/internal/models"
+)
+
+// Workflow sizes covered by the benchmarks and the performance budget
+var workflowSizes = []int{10, 100, 1000}
+
+// BuildChainWorkflow builds a unified workflow of nodeCount nodes: a start node, a chain of nodes
+// cycling through chainTypes (code and LLM nodes) each reading the output of its predecessor, and an end node.
+func BuildChainWorkflow(nodeCount int, chainTypes ...models.NodeType) *models.UnifiedDSL {
+	if len(chainTypes) == 0 {
+		chainTypes = []models.NodeType{models.NodeTypeCode, models.NodeTypeLLM}
+	}
+	if nodeCount < 2 {
+		nodeCount = 2
+	}
+
+	startID := nodeID(0)
+	nodes := []models.Node{{
+		ID:       startID,
+		Type:     models.NodeTypeStart,
+		Title:    "开始",
+		Position: models.Position{X: 0, Y: 0},
+		Outputs:  []models.Output{{Name: "query", Type: models.DataTypeString}},
+		Config: models.StartConfig{
+			Variables: []models.Variable{{Name: "query", Label: "query", Type: "string", Required: true}},
+		},
+	}}
+	edges := make([]models.Edge, 0, nodeCount-1)
+
+	previousID, previousOutput := startID, "query"
+	for i := 1; i < nodeCount-1; i++ {
+		node := codeNode(i, previousID, previousOutput)
+		if chainTypes[(i-1)%len(chainTypes)] == models.NodeTypeLLM {
+			node = llmNode(i, previousID, previousOutput)
+		}
+		nodes = append(nodes, node)
+		edges = append(edges, chainEdge(previousID, node.ID))
+		previousID, previousOutput = node.ID, node.Outputs[0].Name
+	}
+
+	endID := nodeID(nodeCount - 1)
+	nodes = append(nodes, models.Node{
+		ID:       endID,
+		Type:     models.NodeTypeEnd,
+		Title:    "结束",
+		Position: models.Position{X: float64(nodeCount-1) * 300, Y: 0},
+		Inputs:   []models.Input{nodeInput("result", previousID, previousOutput)},
+		Config:   models.EndConfig{OutputMode: "variables"},
+	})
+	edges = append(edges, chainEdge(previousID, endID))
+
+	return &models.UnifiedDSL{
+		Version:  "1.0",
+		Metadata: models.Metadata{Name: fmt.Sprintf("chain_%d", nodeCount), Description: "benchmark workflow"},
+		Workflow: models.Workflow{Nodes: nodes, Edges: edges},
+	}
+}
+
+func nodeID(index int) string {
+	return fmt.Sprintf("%d", 1760000000000+index)
+}
+
+func codeNode(index int, previousID, previousOutput string) models.Node {
+	return models.Node{
+		ID:       nodeID(index),
+		Type:     models.NodeTypeCode,
+		Title:    fmt.Sprintf("代码%d", index),
+		Position: models.Position{X: float64(index) * 300, Y: 0},
+		Inputs:   []models.Input{nodeInput("text", previousID, previousOutput)},
+		Outputs:  []models.Output{{Name: "result", Type: models.DataTypeString}},
+		Config: models.CodeConfig{
+			Language: "python3",
+			Code:     "def main(text: str) -> dict:\n    return {\n        \"result\": text.strip()\n    }",
+		},
+	}
+}
+
+func llmNode(index int, previousID, previousOutput string) models.Node {
+	return models.Node{
+		ID:       nodeID(index),
+		Type:     models.NodeTypeLLM,
+		Title:    fmt.Sprintf("模型%d", index),
+		Position: models.Position{X: float64(index) * 300, Y: 0},
+		Inputs:   []models.Input{nodeInput("input", previousID, previousOutput)},
+		Outputs:  []models.Output{{Name: "text", Type: models.DataTypeString}},
+		Config: models.LLMConfig{
+			Model:      models.ModelConfig{Provider: "openai", Name: "gpt-4o", Mode: "chat"},
+			Parameters: models.ModelParameters{Temperature: 0.7, MaxTokens: 2048},
+			Prompt: models.PromptConfig{
+				SystemTemplate: "你是一个文本助手",
+				Messages: []models.Message{
+					{Role: "system", Content: "你是一个文本助手"},
+					{Role: "user", Content: fmt.Sprintf("请改写：{{#%s.%s#}}", previousID, previousOutput)},
+				},
+			},
+		},
+	}
+}
+
+func nodeInput(name, sourceID, sourceOutput string) models.Input {
+	return models.Input{
+		Name: name,
+		Type: models.DataTypeString,
+		Reference: &models.VariableReference{
+			Type:       models.ReferenceTypeNodeOutput,
+			NodeID:     sourceID,
+			OutputName: sourceOutput,
+			DataType:   models.DataTypeString,
+		},
+	}
+}
+
+func chainEdge(sourceID, targetID string) models.Edge {
+	return models.Edge{
+		ID:           sourceID + "-source-" + targetID + "-target",
+		Source:       sourceID,
+		Target:       targetID,
+		SourceHandle: "source",
+		TargetHandle: "target",
+		Type:         models.EdgeTypeDefault,
+	}
+}
+
+// BuildIterationWorkflow builds a unified workflow whose iteration node runs a sub-workflow of
+// subNodeCount LLM nodes, each prompting with the current item and the output of its predecessor.
+func BuildIterationWorkflow(subNodeCount int) *models.UnifiedDSL {
+	dsl := BuildChainWorkflow(3, models.NodeTypeCode)
+	listNode := &dsl.Workflow.Nodes[1]
+	listNode.Outputs[0].Type = models.DataTypeArrayString
+	listNode.Config = models.CodeConfig{
+		Language: "python3",
+		Code:     "def main(text: str) -> dict:\n    return {\n        \"result\": text.split()\n    }",
+	}
+
+	iterationID := nodeID(100000)
+	iterationStartID := iterationID + "start"
+	subNodes := []models.Node{{
+		ID:      iterationStartID,
+		Type:    models.NodeTypeStart,
+		Title:   "迭代开始",
+		Outputs: []models.Output{{Name: "input", Type: models.DataTypeString}},
+		Config:  models.StartConfig{},
+	}}
+	subEdges := make([]models.Edge, 0, subNodeCount+1)
+
+	previousID, previousOutput := iterationStartID, "input"
+	for i := 1; i <= subNodeCount; i++ {
+		node := llmNode(100000+i, previousID, previousOutput)
+		node.Inputs = append(node.Inputs, nodeInput("item", iterationStartID, "input"))
+		prompt := fmt.Sprintf("结合{{#%s.input#}}改写：{{#%s.%s#}}", iterationStartID, previousID, previousOutput)
+		llmConfig := node.Config.(models.LLMConfig)
+		llmConfig.Prompt.Messages[1].Content = prompt
+		node.Config = llmConfig
+		// The Dify generator takes the prompt of LLM nodes without iFlytek configuration from the description
+		node.Description = prompt
+		subNodes = append(subNodes, node)
+		subEdges = append(subEdges, chainEdge(previousID, node.ID))
+		previousID, previousOutput = node.ID, node.Outputs[0].Name
+	}
+
+	iterationEndID := nodeID(100000 + subNodeCount + 1)
+	subNodes = append(subNodes, models.Node{
+		ID:     iterationEndID,
+		Type:   models.NodeTypeEnd,
+		Title:  "迭代结束",
+		Inputs: []models.Input{nodeInput("output", previousID, previousOutput)},
+		Config: models.EndConfig{OutputMode: "variables"},
+	})
+	subEdges = append(subEdges, chainEdge(previousID, iterationEndID))
+
+	iterationNode := models.Node{
+		ID:       iterationID,
+		Type:     models.NodeTypeIteration,
+		Title:    "迭代",
+		Position: models.Position{X: 600, Y: 0},
+		Inputs:   []models.Input{nodeInput("input", listNode.ID, "result")},
+		Outputs:  []models.Output{{Name: "output", Type: models.DataTypeArrayString}},
+		Config: &models.IterationConfig{
+			Iterator:  models.IteratorConfig{InputType: "array", SourceNode: listNode.ID, SourceOutput: "result"},
+			Execution: models.ExecutionConfig{ParallelNums: 1, ErrorHandleMode: "terminated"},
+			SubWorkflow: models.SubWorkflowConfig{
+				Nodes:       subNodes,
+				Edges:       subEdges,
+				StartNodeID: iterationStartID,
+				EndNodeID:   iterationEndID,
+			},
+			OutputSelector: models.OutputSelectorConfig{NodeID: previousID, OutputName: previousOutput},
+			OutputType:     string(models.DataTypeArrayString),
+		},
+	}
+	iterationNode.Inputs[0].Type = models.DataTypeArrayString
+
+	endNode := &dsl.Workflow.Nodes[2]
+	endNode.Position.X = 900
+	endNode.Inputs = []models.Input{nodeInput("result", iterationID, "output")}
+	endNode.Inputs[0].Type = models.DataTypeArrayString
+
+	dsl.Workflow.Nodes = []models.Node{dsl.Workflow.Nodes[0], *listNode, iterationNode, *endNode}
+	dsl.Workflow.Edges = []models.Edge{
+		dsl.Workflow.Edges[0],
+		chainEdge(listNode.ID, iterationID),
+		chainEdge(iterationID, endNode.ID),
+	}
+	dsl.Metadata.Name = fmt.Sprintf("iteration_%d", subNodeCount)
+	return dsl
+}
@@ -0,0 +1,183 @@
+package regression
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	"github.com/stretchr/testify/require"
+)
+
+// update rewrites the expected files from the current output:
+//
+//	go test ./tests/regression/ -update
+var update = flag.Bool("update", false, "rewrite golden files from the current conversion output")
+
+// Layout of a case directory below testdata
+const (
+	sourceName  = "source" // source.yml, source.json or source.zip
+	expectedDir = "expected"
+	reportName  = "report.txt"
+)
+
+// goldenTargets are the platforms every source is converted to, the source platform excepted
+var goldenTargets = []models.PlatformType{models.PlatformIFlytek, models.PlatformDify, models.PlatformCoze, models.PlatformUnified}
+
+// volatileValues are generated IDs and timestamps that differ between runs
+var volatileValues = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}` +
+	`|\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})` +
+	`|\b\d{10,}\b`)
+
+// TestGoldenConversions converts the source of every testdata case to each other platform and
+// compares the outputs and the conversion report with the expected files of the case.
+func TestGoldenConversions(t *testing.T) {
+	entries, err := os.ReadDir("testdata")
+	require.NoError(t, err, "testdata should list the golden cases")
+
+	service, err := core.InitializeArchitecture()
+	require.NoError(t, err, "architecture initialization failed")
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join("testdata", entry.Name())
+		t.Run(entry.Name(), func(t *testing.T) {
+			sourcePath := findSource(t, dir)
+			source, err := os.ReadFile(sourcePath)
+			require.NoError(t, err, "source read failed")
+			detection, err := common.DetectPlatform(source)
+			require.NoError(t, err, "source platform detection failed")
+
+			var report strings.Builder
+			for _, target := range goldenTargets {
+				if target == detection.Platform {
+					continue
+				}
+				result, err := service.ConvertWithResult(context.Background(), source, detection.Platform, target, models.NewConversionOptions())
+				writeReport(&report, detection.Platform, target, result, err)
+				outputPath := filepath.Join(dir, expectedDir, string(target)+".yml")
+				if err == nil {
+					checkGolden(t, outputPath, normalize(string(result.Output)))
+				} else if *update {
+					// Failed conversions are only in the report
+					os.Remove(outputPath)
+				}
+			}
+			checkGolden(t, filepath.Join(dir, expectedDir, reportName), normalize(report.String()))
+		})
+	}
+}
+
+// deterministicRuns is how often TestDeterministicConversions repeats each conversion; map
+// iteration order changes from run to run, so a few repetitions expose order dependent output
+const deterministicRuns = 5
+
+// TestDeterministicConversions converts the source of every testdata case repeatedly and expects
+// the same output each time, apart from volatile values.
+func TestDeterministicConversions(t *testing.T) {
+	entries, err := os.ReadDir("testdata")
+	require.NoError(t, err, "testdata should list the golden cases")
+
+	service, err := core.InitializeArchitecture()
+	require.NoError(t, err, "architecture initialization failed")
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join("testdata", entry.Name())
+		t.Run(entry.Name(), func(t *testing.T) {
+			source, err := os.ReadFile(findSource(t, dir))
+			require.NoError(t, err, "source read failed")
+			detection, err := common.DetectPlatform(source)
+			require.NoError(t, err, "source platform detection failed")
+
+			for _, target := range goldenTargets {
+				if target == detection.Platform {
+					continue
+				}
+				var first string
+				for run := 0; run < deterministicRuns; run++ {
+					result, err := service.ConvertWithResult(context.Background(), source, detection.Platform, target, models.NewConversionOptions())
+					if err != nil {
+						break
+					}
+					output := normalize(string(result.Output))
+					if run == 0 {
+						first = output
+						continue
+					}
+					require.Equal(t, first, output, "%s -> %s output changed between runs", detection.Platform, target)
+				}
+			}
+		})
+	}
+}
+
+// findSource returns the source DSL of a case directory
+func findSource(t *testing.T, dir string) string {
+	matches, err := filepath.Glob(filepath.Join(dir, sourceName+".*"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1, "%s should contain exactly one %s file", dir, sourceName)
+	return matches[0]
+}
+
+// writeReport appends the outcome of one conversion: the error, or the node counts, placeholders
+// and warnings
+func writeReport(report *strings.Builder, source, target models.PlatformType, result *services.ConversionResult, err error) {
+	fmt.Fprintf(report, "%s -> %s\n", source, target)
+	if err != nil {
+		fmt.Fprintf(report, "  error: %v\n", err)
+		return
+	}
+
+	types := make([]string, 0, len(result.NodeTypes))
+	for nodeType, count := range result.NodeTypes {
+		types = append(types, fmt.Sprintf("%s=%d", nodeType, count))
+	}
+	sort.Strings(types)
+	fmt.Fprintf(report, "  nodes: %s\n", strings.Join(types, " "))
+	fmt.Fprintf(report, "  placeholders: %d\n", result.Placeholders)
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(report, "  warning: %s\n", warning)
+	}
+}
+
+// normalize numbers every distinct volatile value by first appearance, so that outputs compare
+// equal across runs while references between IDs stay visible
+func normalize(content string) string {
+	seen := make(map[string]string)
+	return volatileValues.ReplaceAllStringFunc(content, func(value string) string {
+		if placeholder, ok := seen[value]; ok {
+			return placeholder
+		}
+		placeholder := fmt.Sprintf("<volatile-%d>", len(seen)+1)
+		seen[value] = placeholder
+		return placeholder
+	})
+}
+
+// checkGolden compares content with the golden file at path, or rewrites it with -update
+func checkGolden(t *testing.T, path, content string) {
+	t.Helper()
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "golden file missing, run go test ./tests/regression/ -update")
+	require.Equal(t, string(expected), content, "%s differs from the current output; run with -update if the change is intended", path)
+}
@@ -0,0 +1,75 @@
+package regression
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+// identityPlatforms are the platforms whose fixtures are converted to themselves
+var identityPlatforms = []models.PlatformType{models.PlatformCoze, models.PlatformDify, models.PlatformIFlytek}
+
+// TestIdentityConversions converts every fixture to its own platform. The conversion fails listing
+// the differences when the output parses into another workflow than the source.
+func TestIdentityConversions(t *testing.T) {
+	service, err := core.InitializeArchitecture()
+	require.NoError(t, err, "architecture initialization failed")
+
+	for _, platform := range identityPlatforms {
+		fixtures, err := filepath.Glob(filepath.Join("..", "fixtures", string(platform), "*"))
+		require.NoError(t, err)
+		for _, fixture := range fixtures {
+			name := filepath.Base(fixture)
+			t.Run(string(platform)+"/"+name, func(t *testing.T) {
+				source, err := os.ReadFile(fixture)
+				require.NoError(t, err, "fixture read failed")
+				_, err = service.ConvertWithResult(context.Background(), source, platform, platform, models.NewConversionOptions())
+				require.NoError(t, err, "identity conversion failed")
+			})
+		}
+	}
+}
+
+// TestIdentityConversionsWithEdits converts the LLM fixture of each platform to its own platform
+// with options that edit the workflow on purpose, which the identity check must let through
+func TestIdentityConversionsWithEdits(t *testing.T) {
+	service, err := core.InitializeArchitecture()
+	require.NoError(t, err, "architecture initialization failed")
+
+	for _, platform := range identityPlatforms {
+		fixture := filepath.Join("..", "fixtures", string(platform), string(platform)+"_start_llm_end.yml")
+		source, err := os.ReadFile(fixture)
+		require.NoError(t, err, "fixture read failed")
+		unifiedDSL, err := service.Parse(source, platform)
+		require.NoError(t, err, "fixture parsing failed")
+		var llmID string
+		for _, node := range unifiedDSL.Workflow.Nodes {
+			if node.Type == models.NodeTypeLLM {
+				llmID = node.ID
+			}
+		}
+		require.NotEmpty(t, llmID, "the fixture should have an LLM node")
+
+		cases := map[string]func(*models.ConversionOptions){
+			"anonymize":      func(o *models.ConversionOptions) { o.Anonymize = true },
+			"title prefix":   func(o *models.ConversionOptions) { o.TitlePrefix = "X " },
+			"title suffix":   func(o *models.ConversionOptions) { o.TitleSuffix = " (copy)" },
+			"title template": func(o *models.ConversionOptions) { o.TitleTemplate = "{{type}}: {{title}}" },
+			"include nodes":  func(o *models.ConversionOptions) { o.IncludeNodes = []string{llmID} },
+			"subgraph from":  func(o *models.ConversionOptions) { o.SubgraphFrom = llmID },
+		}
+		for name, edit := range cases {
+			t.Run(string(platform)+"/"+name, func(t *testing.T) {
+				options := models.NewConversionOptions()
+				edit(options)
+				_, err := service.ConvertWithResult(context.Background(), source, platform, platform, options)
+				require.NoError(t, err, "edited identity conversion failed")
+			})
+		}
+	}
+}
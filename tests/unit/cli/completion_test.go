@@ -0,0 +1,17 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPlatformCompletion checks that --to completes the source platform too, for identity conversions
+func TestPlatformCompletion(t *testing.T) {
+	out := run(t, t.TempDir(), nil, "__complete", "convert", "--from", "coze", "--to", "")
+	require.Equal(t, 0, out.exitCode, out.stderr)
+	completions := strings.Split(strings.TrimSpace(out.stdout), "\n")
+	require.Contains(t, completions, "coze", "the source platform should be completed")
+	require.Contains(t, completions, "dify")
+}
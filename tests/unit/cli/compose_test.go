@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestComposeDebugDir checks that each composed workflow keeps its debug artifacts apart
+func TestComposeDebugDir(t *testing.T) {
+	dir := t.TempDir()
+	debugDir := filepath.Join(dir, "debug")
+	out := run(t, dir, nil, "compose",
+		"--input", fixture(t, "dify/dify_start_llm_end.yml"),
+		"--input", fixture(t, "dify/dify_start_code_end.yml"),
+		"--to", "dify", "--output", filepath.Join(dir, "composed.yml"), "--debug-dir", debugDir)
+	require.Equal(t, 0, out.exitCode, out.stderr+out.stdout)
+
+	for _, artifact := range []string{
+		"1-dify_start_llm_end/unified_parsed.yml",
+		"2-dify_start_code_end/unified_parsed.yml",
+		"unified_final.yml",
+	} {
+		_, err := os.Stat(filepath.Join(debugDir, artifact))
+		require.NoError(t, err, "%s should be saved", artifact)
+	}
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigProfileFlags validates that config profile values fill only flags not set on the command line
+func TestConfigProfileFlags(t *testing.T) {
+	dir := t.TempDir()
+	configPath := writeFile(t, dir, "config.yaml", `default_profile: team
+defaults:
+  title_prefix: "[defaults] "
+profiles:
+  team:
+    title_prefix: "[team] "
+  keep:
+    keep_titles: true
+`)
+	input := fixture(t, "iflytek/iflytek_basic_start_end.yml")
+
+	cases := []struct {
+		name     string
+		args     []string
+		contains string // in the output, or in stderr when the conversion fails
+		fails    bool
+	}{
+		{"default profile", nil, "[team] ", false},
+		{"flag overrides profile", []string{"--title-prefix", "[flag] "}, "[flag] ", false},
+		{"profile bool with flag prefix", []string{"--profile", "keep", "--title-prefix", "[flag] "}, "INVALID_OPTIONS", true},
+		{"changed bool flag overrides profile", []string{"--profile", "keep", "--keep-titles=false", "--title-prefix", "[flag] "}, "[flag] ", false},
+		{"unknown profile", []string{"--profile", "missing"}, `config profile "missing" not found (available: keep, team)`, true},
+	}
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := filepath.Join(dir, "out"+string(rune('a'+i))+".yml")
+			args := append([]string{"convert", "--config", configPath, "--from", "iflytek", "--to", "dify", "--input", input, "--output", output}, tc.args...)
+			res := run(t, dir, nil, args...)
+			if tc.fails {
+				require.NotZero(t, res.exitCode, res.stdout)
+				require.Contains(t, res.stderr, tc.contains)
+				return
+			}
+			require.Zero(t, res.exitCode, res.stderr)
+			data, err := os.ReadFile(output)
+			require.NoError(t, err)
+			require.Contains(t, string(data), tc.contains)
+		})
+	}
+
+	t.Run("missing explicit config", func(t *testing.T) {
+		res := run(t, dir, nil, "convert", "--config", filepath.Join(dir, "absent.yaml"), "--from", "iflytek", "--to", "dify",
+			"--input", input, "--output", filepath.Join(dir, "absent.yml"))
+		require.NotZero(t, res.exitCode)
+		require.Contains(t, res.stderr, "failed to read config file")
+	})
+}
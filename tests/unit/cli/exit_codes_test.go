@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iflytek/agentbridge/cmd"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExitCodeFor validates the exit code of each error type and conversion error code
+func TestExitCodeFor(t *testing.T) {
+	conversionError := func(code string) error {
+		return fmt.Errorf("conversion failed: %w", &models.ConversionError{Code: code})
+	}
+
+	cases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"success", nil, cmd.ExitOK},
+		{"plain error", errors.New("boom"), cmd.ExitFailure},
+		{"path error", fmt.Errorf("failed to read file: %w", &fs.PathError{Op: "open", Path: "agent.yml", Err: fs.ErrNotExist}), cmd.ExitIO},
+		{"missing file", fmt.Errorf("input: %w", os.ErrNotExist), cmd.ExitIO},
+		{"permission denied", fmt.Errorf("output: %w", fs.ErrPermission), cmd.ExitIO},
+		{"parse error", fmt.Errorf("node: %w", &models.ParseError{Code: "PARSE_FAILED"}), cmd.ExitParse},
+		{"validation error", &models.ValidationError{Message: "missing start node"}, cmd.ExitValidation},
+		{"PARSE_FAILED", conversionError("PARSE_FAILED"), cmd.ExitParse},
+		{"PARSER_NOT_FOUND", conversionError("PARSER_NOT_FOUND"), cmd.ExitParse},
+		{"POLICY_VIOLATION", conversionError("POLICY_VIOLATION"), cmd.ExitValidation},
+		{"GENERATION_FAILED", conversionError("GENERATION_FAILED"), cmd.ExitGeneration},
+		{"GENERATOR_NOT_FOUND", conversionError("GENERATOR_NOT_FOUND"), cmd.ExitGeneration},
+		{"MINIFY_FAILED", conversionError("MINIFY_FAILED"), cmd.ExitGeneration},
+		{"COMPOSITION_FAILED", conversionError("COMPOSITION_FAILED"), cmd.ExitGeneration},
+		{"UNSUPPORTED_NODES", conversionError("UNSUPPORTED_NODES"), cmd.ExitGeneration},
+		{"UNSUPPORTED_CONDITION", conversionError("UNSUPPORTED_CONDITION"), cmd.ExitGeneration},
+		{"UNSUPPORTED_OPERATOR", conversionError("UNSUPPORTED_OPERATOR"), cmd.ExitGeneration},
+		{"IDENTITY_CHANGED", conversionError("IDENTITY_CHANGED"), cmd.ExitGeneration},
+		{"other conversion error", conversionError("NODE_NOT_FOUND"), cmd.ExitFailure},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.code, cmd.ExitCodeFor(tc.err))
+		})
+	}
+}
+
+// jsonSummary is the summary line commands print on stderr
+type jsonSummary struct {
+	Command      string   `json:"command"`
+	Success      bool     `json:"success"`
+	ExitCode     int      `json:"exit_code"`
+	Category     string   `json:"category"`
+	Error        string   `json:"error"`
+	Converted    int      `json:"files_converted"`
+	Failed       int      `json:"files_failed"`
+	Nodes        int      `json:"nodes"`
+	Placeholders int      `json:"placeholders"`
+	Fidelity     *float64 `json:"fidelity"`
+}
+
+// TestExitCodes validates the exit codes and the JSON summary line of failing commands, batches
+// included
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	broken := writeFile(t, dir, "broken.yml", "app: [\n")
+	unnamed := writeFile(t, dir, "unnamed.yml", "foo: bar\n")
+	database := fixture(t, "coze/coze_start_database_end.yml")
+	output := filepath.Join(dir, "out.yml")
+
+	summaryOf := func(t *testing.T, res result) jsonSummary {
+		t.Helper()
+		lines := strings.Split(strings.TrimSpace(res.stderr), "\n")
+		var summary jsonSummary
+		require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &summary), res.stderr)
+		require.Equal(t, res.exitCode, summary.ExitCode)
+		return summary
+	}
+
+	cases := []struct {
+		name     string
+		args     []string
+		code     int
+		category string
+	}{
+		{"success", []string{"convert", "--from", "iflytek", "--to", "dify", "--input", fixture(t, "iflytek/iflytek_basic_start_end.yml"), "--output", output}, cmd.ExitOK, "ok"},
+		{"invalid yaml", []string{"convert", "--from", "dify", "--to", "iflytek", "--input", broken, "--output", output}, cmd.ExitParse, "parse"},
+		{"not a workflow", []string{"convert", "--from", "dify", "--to", "iflytek", "--input", unnamed, "--output", output}, cmd.ExitParse, "parse"},
+		{"validation", []string{"validate", "--from", "dify", "--input", unnamed}, cmd.ExitValidation, "validation"},
+		{"rejected placeholder", []string{"convert", "--from", "coze", "--to", "iflytek", "--input", database, "--output", output, "--placeholder-strategy", "fail"}, cmd.ExitGeneration, "generation"},
+		{"missing input", []string{"convert", "--from", "coze", "--to", "iflytek", "--input", filepath.Join(dir, "absent.yml"), "--output", output}, cmd.ExitIO, "io"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := run(t, dir, nil, append(tc.args, "--json-summary")...)
+			require.Equal(t, tc.code, res.exitCode, res.stderr)
+			summary := summaryOf(t, res)
+			require.Equal(t, tc.args[0], summary.Command)
+			require.Equal(t, tc.category, summary.Category)
+			require.Equal(t, tc.code == cmd.ExitOK, summary.Success)
+			require.Equal(t, tc.code != cmd.ExitOK, summary.Error != "")
+		})
+	}
+
+	t.Run("summary from the environment", func(t *testing.T) {
+		res := run(t, dir, []string{cmd.EnvJSONSummary + "=1"}, "convert", "--from", "coze", "--to", "iflytek", "--input", database, "--output", output)
+		require.Zero(t, res.exitCode, res.stderr)
+		summary := summaryOf(t, res)
+		require.Equal(t, 1, summary.Converted)
+		require.Equal(t, 3, summary.Nodes)
+		require.Equal(t, 1, summary.Placeholders)
+		require.NotNil(t, summary.Fidelity)
+		require.InDelta(t, 2.0/3, *summary.Fidelity, 0.001)
+
+		res = run(t, dir, nil, "convert", "--from", "coze", "--to", "iflytek", "--input", database, "--output", output)
+		require.NotContains(t, res.stderr, `"exit_code"`, "the summary is opt-in")
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		uniform := t.TempDir()
+		writeFile(t, uniform, "a.yml", "app: [\n")
+		writeFile(t, uniform, "b.yml", "app: [\n")
+		res := run(t, dir, nil, "batch", "--from", "coze", "--to", "iflytek", "--input-dir", uniform, "--output-dir", t.TempDir(), "--json-summary")
+		require.Equal(t, cmd.ExitParse, res.exitCode, "failures of one category give its exit code")
+		summary := summaryOf(t, res)
+		require.Equal(t, 2, summary.Failed)
+		require.Equal(t, "parse", summary.Category)
+
+		mixed := t.TempDir()
+		writeFile(t, mixed, "a.yml", "app: [\n")
+		data, err := os.ReadFile(database)
+		require.NoError(t, err)
+		writeFile(t, mixed, "database.yml", string(data))
+		res = run(t, dir, nil, "batch", "--from", "coze", "--to", "iflytek", "--input-dir", mixed, "--output-dir", t.TempDir(),
+			"--placeholder-strategy", "fail", "--json-summary")
+		require.Equal(t, cmd.ExitFailure, res.exitCode, "failures of several categories give the generic exit code")
+		require.Equal(t, 2, summaryOf(t, res).Failed)
+	})
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestProfiling validates that the profiling flags write their files and that paths that cannot
+// be created fail the command
+func TestProfiling(t *testing.T) {
+	dir := t.TempDir()
+	input := fixture(t, "iflytek/iflytek_basic_start_end.yml")
+	convert := func(flags ...string) result {
+		args := append([]string{"convert", "--from", "iflytek", "--to", "dify", "--input", input, "--output", filepath.Join(dir, "out.yml")}, flags...)
+		return run(t, dir, nil, args...)
+	}
+	readFile := func(path string) []byte {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("profiles written", func(t *testing.T) {
+		cpuProfile := filepath.Join(dir, "cpu.pprof")
+		heapProfile := filepath.Join(dir, "heap.pprof")
+		traceFile := filepath.Join(dir, "run.trace")
+		res := convert("--cpuprofile", cpuProfile, "--memprofile", heapProfile, "--trace", traceFile)
+		require.Zero(t, res.exitCode, res.stderr)
+
+		// pprof profiles are gzip compressed protocol buffers
+		require.True(t, bytes.HasPrefix(readFile(cpuProfile), []byte{0x1f, 0x8b}), "CPU profile")
+		require.True(t, bytes.HasPrefix(readFile(heapProfile), []byte{0x1f, 0x8b}), "heap profile")
+		require.True(t, bytes.HasPrefix(readFile(traceFile), []byte("go 1.")), "execution trace")
+	})
+
+	t.Run("profiles written when the command fails", func(t *testing.T) {
+		cpuProfile := filepath.Join(dir, "failed.pprof")
+		res := run(t, dir, nil, "convert", "--from", "iflytek", "--to", "dify", "--input", filepath.Join(dir, "absent.yml"),
+			"--output", filepath.Join(dir, "out.yml"), "--cpuprofile", cpuProfile)
+		require.NotZero(t, res.exitCode)
+		require.True(t, bytes.HasPrefix(readFile(cpuProfile), []byte{0x1f, 0x8b}))
+	})
+
+	t.Run("doctor", func(t *testing.T) {
+		// doctor skips the config file but not profiling
+		cpuProfile := filepath.Join(dir, "doctor.pprof")
+		writeFile(t, dir, "broken-config.yaml", "profiles: [\n")
+		res := run(t, dir, nil, "doctor", "--config", filepath.Join(dir, "broken-config.yaml"), "--cpuprofile", cpuProfile)
+		require.True(t, bytes.HasPrefix(readFile(cpuProfile), []byte{0x1f, 0x8b}), res.stderr)
+	})
+
+	t.Run("bad paths", func(t *testing.T) {
+		missingDir := filepath.Join(dir, "missing", "profile")
+
+		res := convert("--cpuprofile", missingDir)
+		require.NotZero(t, res.exitCode)
+		require.Contains(t, res.stderr, "failed to create CPU profile")
+
+		// The CPU profile started before the trace failed is finished
+		cpuProfile := filepath.Join(dir, "before-trace.pprof")
+		res = convert("--cpuprofile", cpuProfile, "--trace", missingDir)
+		require.NotZero(t, res.exitCode)
+		require.Contains(t, res.stderr, "failed to create trace")
+		require.True(t, bytes.HasPrefix(readFile(cpuProfile), []byte{0x1f, 0x8b}))
+
+		// The heap profile is written after the command, so it only warns
+		res = convert("--memprofile", missingDir)
+		require.Zero(t, res.exitCode, res.stderr)
+		require.Contains(t, res.stderr, "failed to create heap profile")
+	})
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestConvertProvenance validates embedded and sidecar provenance and that embedding rejects JSON output
+func TestConvertProvenance(t *testing.T) {
+	dir := t.TempDir()
+	input := fixture(t, "iflytek/iflytek_basic_start_end.yml")
+	source, err := os.ReadFile(input)
+	require.NoError(t, err)
+	sum := sha256.Sum256(source)
+
+	checkProvenance := func(t *testing.T, provenance models.Provenance) {
+		require.Equal(t, models.ProvenanceToolName, provenance.Tool)
+		require.Equal(t, models.PlatformIFlytek, provenance.SourcePlatform)
+		require.Equal(t, models.PlatformDify, provenance.TargetPlatform)
+		require.Equal(t, "iflytek_basic_start_end.yml", provenance.SourceFile)
+		require.Equal(t, hex.EncodeToString(sum[:]), provenance.SourceSHA256)
+		require.NotEmpty(t, provenance.NodeMapping)
+		require.False(t, provenance.ConvertedAt.IsZero())
+	}
+
+	t.Run("embed", func(t *testing.T) {
+		output := filepath.Join(dir, "embed.yml")
+		res := run(t, dir, nil, "convert", "--from", "iflytek", "--to", "dify", "--input", input, "--output", output, "--provenance", "embed")
+		require.Zero(t, res.exitCode, res.stderr)
+
+		data, err := os.ReadFile(output)
+		require.NoError(t, err)
+		header, body, found := strings.Cut(string(data), "\n")
+		require.True(t, found)
+		require.True(t, strings.HasPrefix(header, "# agentbridge-provenance: "), header)
+
+		var provenance models.Provenance
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(header, "# agentbridge-provenance: ")), &provenance))
+		checkProvenance(t, provenance)
+
+		var document map[string]interface{}
+		require.NoError(t, yaml.Unmarshal([]byte(body), &document), "the output must stay valid YAML")
+		require.Contains(t, document, "workflow")
+		require.NoFileExists(t, output+".provenance.json")
+	})
+
+	t.Run("sidecar", func(t *testing.T) {
+		output := filepath.Join(dir, "sidecar.yml")
+		res := run(t, dir, nil, "convert", "--from", "iflytek", "--to", "dify", "--input", input, "--output", output, "--provenance", "sidecar")
+		require.Zero(t, res.exitCode, res.stderr)
+
+		data, err := os.ReadFile(output)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), "agentbridge-provenance")
+
+		sidecar, err := os.ReadFile(output + ".provenance.json")
+		require.NoError(t, err)
+		var provenance models.Provenance
+		require.NoError(t, json.Unmarshal(sidecar, &provenance))
+		checkProvenance(t, provenance)
+	})
+
+	t.Run("sidecar with json output", func(t *testing.T) {
+		output := filepath.Join(dir, "sidecar.json")
+		res := run(t, dir, nil, "convert", "--from", "iflytek", "--to", "dify", "--input", input, "--output", output,
+			"--provenance", "sidecar", "--output-format", "json")
+		require.Zero(t, res.exitCode, res.stderr)
+		require.FileExists(t, output+".provenance.json")
+	})
+
+	t.Run("embed with json output", func(t *testing.T) {
+		output := filepath.Join(dir, "embed.json")
+		res := run(t, dir, nil, "convert", "--from", "iflytek", "--to", "dify", "--input", input, "--output", output,
+			"--provenance", "embed", "--output-format", "json")
+		require.NotZero(t, res.exitCode)
+		require.Contains(t, res.stderr, "use --provenance sidecar with --output-format json")
+		require.NoFileExists(t, output)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		res := run(t, dir, nil, "convert", "--from", "iflytek", "--to", "dify", "--input", input, "--output", filepath.Join(dir, "invalid.yml"), "--provenance", "inline")
+		require.NotZero(t, res.exitCode)
+		require.Contains(t, res.stderr, "invalid provenance mode 'inline'")
+	})
+}
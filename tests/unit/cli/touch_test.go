@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestBatchTouchBypassesCache checks that --touch converts again instead of returning cached
+// outputs stamped with the time of an earlier run
+func TestBatchTouchBypassesCache(t *testing.T) {
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "in")
+	require.NoError(t, os.Mkdir(inputDir, 0o755))
+	source, err := os.ReadFile(fixture(t, "dify/dify_start_llm_end.yml"))
+	require.NoError(t, err)
+	writeFile(t, inputDir, "agent.yml", string(source))
+	cacheDir := filepath.Join(dir, "cache")
+
+	batch := func(extra ...string) result {
+		args := append([]string{"batch", "--from", "dify", "--to", "iflytek", "--input-dir", inputDir,
+			"--output-dir", t.TempDir(), "--cache-dir", cacheDir}, extra...)
+		out := run(t, dir, nil, args...)
+		require.Equal(t, 0, out.exitCode, out.stderr+out.stdout)
+		return out
+	}
+	batch()
+	require.Contains(t, batch().stdout, "Cached: 1", "an unchanged file should come from the cache")
+	batch("--touch")
+	require.NotContains(t, batch("--touch").stdout, "Cached:", "--touch should convert again")
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/iflytek/agentbridge/cmd"
+	"github.com/iflytek/agentbridge/internal/update"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpdateRequiresKey validates that a build without a release key refuses to update before
+// looking up the release, while --check still reports it
+func TestUpdateRequiresKey(t *testing.T) {
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		json.NewEncoder(w).Encode(update.Release{TagName: "v99.0.0", HTMLURL: "https://example.com/v99.0.0"})
+	}))
+	defer server.Close()
+	env := []string{cmd.EnvUpdateAPI + "=" + server.URL}
+	dir := t.TempDir()
+
+	res := run(t, dir, env, "update")
+	require.Equal(t, cmd.ExitValidation, res.exitCode, res.stderr)
+	require.Contains(t, res.stderr, "--allow-unsigned")
+	require.Zero(t, lookups.Load(), "nothing is looked up without a key")
+
+	res = run(t, dir, env, "update", "--check")
+	require.Zero(t, res.exitCode, res.stderr)
+	require.Contains(t, res.stdout, "v99.0.0 is available")
+	require.Equal(t, int32(1), lookups.Load())
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerify validates checking the checksum and signature convert writes with --checksum and --sign
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	writeKeys := func(name string) (privatePath, publicPath string) {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+		require.NoError(t, err)
+		publicDER, err := x509.MarshalPKIXPublicKey(public)
+		require.NoError(t, err)
+		privatePath = writeFile(t, dir, name+".pem", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})))
+		publicPath = writeFile(t, dir, name+".pub.pem", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})))
+		return privatePath, publicPath
+	}
+	privateKey, publicKey := writeKeys("signer")
+	_, otherKey := writeKeys("other")
+
+	output := filepath.Join(dir, "agent.yml")
+	res := run(t, dir, nil, "convert", "--from", "iflytek", "--to", "dify",
+		"--input", fixture(t, "iflytek/iflytek_basic_start_end.yml"), "--output", output, "--checksum", "--sign", privateKey)
+	require.Zero(t, res.exitCode, res.stderr)
+	require.FileExists(t, output+".sha256")
+	require.FileExists(t, output+".sig")
+
+	t.Run("checksum and signature", func(t *testing.T) {
+		res := run(t, dir, nil, "verify", "--input", output, "--key", publicKey)
+		require.Zero(t, res.exitCode, res.stderr)
+		require.Contains(t, res.stdout, "Checksum: OK")
+		require.Contains(t, res.stdout, "Signature: valid")
+	})
+
+	t.Run("signing key verifies", func(t *testing.T) {
+		res := run(t, dir, nil, "verify", "--input", output, "--key", privateKey)
+		require.Zero(t, res.exitCode, res.stderr)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		res := run(t, dir, nil, "verify", "--input", output, "--key", otherKey)
+		require.NotZero(t, res.exitCode)
+		require.Contains(t, res.stderr, "invalid signature")
+	})
+
+	t.Run("tampered file", func(t *testing.T) {
+		data, err := os.ReadFile(output)
+		require.NoError(t, err)
+		tampered := writeFile(t, dir, "tampered.yml", string(data)+"\n# edited\n")
+		for _, suffix := range []string{".sha256", ".sig"} {
+			sidecar, err := os.ReadFile(output + suffix)
+			require.NoError(t, err)
+			writeFile(t, dir, "tampered.yml"+suffix, string(sidecar))
+		}
+
+		res := run(t, dir, nil, "verify", "--input", tampered)
+		require.NotZero(t, res.exitCode)
+		require.Contains(t, res.stderr, "checksum mismatch")
+
+		require.NoError(t, os.Remove(tampered+".sha256"))
+		res = run(t, dir, nil, "verify", "--input", tampered, "--key", publicKey)
+		require.NotZero(t, res.exitCode)
+		require.Contains(t, res.stderr, "invalid signature")
+	})
+
+	t.Run("nothing to verify", func(t *testing.T) {
+		unsigned := writeFile(t, dir, "unsigned.yml", "app: {}\n")
+		res := run(t, dir, nil, "verify", "--input", unsigned)
+		require.NotZero(t, res.exitCode)
+		require.Contains(t, res.stderr, "nothing to verify")
+
+		res = run(t, dir, nil, "verify", "--input", unsigned, "--signature", output+".sig")
+		require.NotZero(t, res.exitCode)
+		require.Contains(t, res.stderr, "--signature needs --key")
+	})
+}
@@ -1,13 +1,27 @@
 package generators
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	cozeGenerator "github.com/iflytek/agentbridge/platforms/coze/generator"
+	cozeParser "github.com/iflytek/agentbridge/platforms/coze/parser"
 	"github.com/iflytek/agentbridge/platforms/coze/strategies"
+	difyGenerator "github.com/iflytek/agentbridge/platforms/dify/generator"
+	difyParser "github.com/iflytek/agentbridge/platforms/dify/parser"
+	iflytekParser "github.com/iflytek/agentbridge/platforms/iflytek/parser"
 	golden "github.com/iflytek/agentbridge/tests/unit/golden/basic_start_end"
 	codeGolden "github.com/iflytek/agentbridge/tests/unit/golden/code_workflow"
 
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 // TestCozeGenerator_BasicStartEnd_FromIFlytek tests Coze DSL generation from iFlytek parsed basic start-end workflow.
@@ -61,3 +75,552 @@ func TestCozeGenerator_CodeWorkflow_FromIFlytek(t *testing.T) {
 		t.Logf("Generated DSL length: %d bytes", len(cozeDSL))
 	}
 }
+
+// TestCozeGenerator_SelectorBranchesRoundTrip tests that every condition case and the default
+// branch get their own selector port and survive parsing the generated Coze DSL again.
+func TestCozeGenerator_SelectorBranchesRoundTrip(t *testing.T) {
+	const caseCount = 5
+
+	testCases := []struct {
+		name   string
+		parser interface {
+			Parse([]byte) (*models.UnifiedDSL, error)
+		}
+		fixture string
+	}{
+		{name: "dify", parser: difyParser.NewDifyParser(), fixture: filepath.Join("dify", "dify_start_condition_end.yml")},
+		{name: "iflytek", parser: iflytekParser.NewIFlytekParser(), fixture: filepath.Join("iflytek", "iflytek_start_condition_end.yml")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", tc.fixture))
+			require.NoError(t, err, "failed to read fixture")
+			unifiedDSL, err := tc.parser.Parse(data)
+			require.NoError(t, err, "source parsing failed")
+
+			conditionID, sourceHandles := connectSelectorBranchesToEnd(t, unifiedDSL, caseCount)
+
+			output, mapping, err := cozeGenerator.NewCozeGenerator().GenerateWithMapping(unifiedDSL)
+			require.NoError(t, err, "Coze DSL generation failed")
+
+			ports := make(map[string]bool)
+			for _, handle := range sourceHandles {
+				port := mapping.Branches[conditionID][handle]
+				require.NotEmpty(t, port, "branch %s should have a selector port", handle)
+				require.False(t, ports[port], "port %s used by two branches", port)
+				ports[port] = true
+			}
+			require.True(t, ports["false"], "the default branch should use the else port")
+			require.True(t, ports[fmt.Sprintf("true_%d", caseCount-1)], "the last case should use port true_%d", caseCount-1)
+
+			roundTrip, err := cozeParser.NewCozeParser().Parse(output)
+			require.NoError(t, err, "generated Coze DSL should parse")
+
+			var parsedHandles []string
+			for _, edge := range roundTrip.Workflow.Edges {
+				if edge.Source == mapping.Nodes[conditionID] {
+					parsedHandles = append(parsedHandles, edge.SourceHandle)
+				}
+			}
+			expectedHandles := []string{"__default__"}
+			for i := 0; i < caseCount; i++ {
+				expectedHandles = append(expectedHandles, fmt.Sprintf("case_%d", i))
+			}
+			require.ElementsMatch(t, expectedHandles, parsedHandles, "every branch should come back as its own case")
+		})
+	}
+}
+
+// connectSelectorBranchesToEnd extends the condition node of a parsed fixture to caseCount cases and
+// connects every branch directly to the end node, dropping the nodes in between. It returns the
+// condition node ID and the source handles of its edges.
+func connectSelectorBranchesToEnd(t *testing.T, unifiedDSL *models.UnifiedDSL, caseCount int) (string, []string) {
+	var condition, end *models.Node
+	var kept []models.Node
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		switch node.Type {
+		case models.NodeTypeStart, models.NodeTypeEnd, models.NodeTypeCondition:
+			kept = append(kept, node)
+		}
+	}
+	unifiedDSL.Workflow.Nodes = kept
+	for i := range unifiedDSL.Workflow.Nodes {
+		switch unifiedDSL.Workflow.Nodes[i].Type {
+		case models.NodeTypeCondition:
+			condition = &unifiedDSL.Workflow.Nodes[i]
+		case models.NodeTypeEnd:
+			end = &unifiedDSL.Workflow.Nodes[i]
+			end.Inputs = nil
+		}
+	}
+	require.NotNil(t, condition, "fixture should have a condition node")
+	require.NotNil(t, end, "fixture should have an end node")
+
+	config, ok := common.AsConditionConfig(condition.Config)
+	require.True(t, ok, "condition node should have a condition config")
+	var template models.ConditionCase
+	for _, caseItem := range config.Cases {
+		if len(caseItem.Conditions) > 0 {
+			template = caseItem
+		}
+	}
+	for i := 0; countConditionCases(config) < caseCount; i++ {
+		extra := template
+		extra.CaseID = fmt.Sprintf("extra-case-%d", i)
+		extra.Level = template.Level + i + 1
+		config.Cases = append(config.Cases, extra)
+	}
+	condition.Config = config
+
+	var edges []models.Edge
+	for _, edge := range unifiedDSL.Workflow.Edges {
+		if edge.Target == condition.ID {
+			edges = append(edges, edge)
+		}
+	}
+	handles := []string{config.DefaultCase}
+	for _, caseItem := range config.Cases {
+		if caseItem.CaseID != config.DefaultCase {
+			handles = append(handles, caseItem.CaseID)
+		}
+	}
+	for _, handle := range handles {
+		edges = append(edges, models.Edge{
+			ID:           condition.ID + "-" + handle,
+			Source:       condition.ID,
+			Target:       end.ID,
+			SourceHandle: handle,
+			Type:         models.EdgeTypeDefault,
+		})
+	}
+	unifiedDSL.Workflow.Edges = edges
+
+	return condition.ID, handles
+}
+
+// countConditionCases returns the number of cases with conditions
+func countConditionCases(config *models.ConditionConfig) int {
+	count := 0
+	for _, caseItem := range config.Cases {
+		if len(caseItem.Conditions) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCozeGenerator_ClassifierDescriptions(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_classifier_end.yml"))
+	require.NoError(t, err, "file read failed")
+	input := strings.Replace(string(data), "        - id: '1'\n          name: 知识学习类\n",
+		"        - id: '1'\n          label: 学习\n          name: 知识学习类\n", 1)
+	require.NotEqual(t, string(data), input, "fixture should contain the classifier classes")
+
+	unifiedDSL, err := difyParser.NewDifyParser().Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+	for i, node := range unifiedDSL.Workflow.Nodes {
+		if config, ok := common.AsClassifierConfig(node.Config); ok && config != nil {
+			require.Equal(t, models.ClassifierClass{ID: "1", Name: "学习", Description: "知识学习类"}, config.Classes[0])
+			config.Mode = models.ClassifierModeFast
+			unifiedDSL.Workflow.Nodes[i].Config = config
+		}
+	}
+	output, err := cozeGenerator.NewCozeGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Coze DSL generation failed")
+	require.Contains(t, string(output), "description: 知识学习类")
+	require.Contains(t, string(output), "mode: top_speed")
+
+	data, err = os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "coze_start_classifier_end.yml"))
+	require.NoError(t, err, "file read failed")
+	input = strings.ReplaceAll(string(data), "- name: 知识学习类\n", "- name: 学习\n                      description: 知识学习类\n")
+	input = strings.ReplaceAll(input, "mode: all\n", "mode: top_speed\n")
+	parsed, err := cozeParser.NewCozeParser().Parse([]byte(input))
+	require.NoError(t, err, "Coze parsing failed")
+	var classifier *models.ClassifierConfig
+	for _, node := range parsed.Workflow.Nodes {
+		if config, ok := common.AsClassifierConfig(node.Config); ok && config != nil {
+			classifier = config
+		}
+	}
+	require.NotNil(t, classifier, "the workflow should have a classifier node")
+	require.Equal(t, models.ClassifierModeFast, classifier.Mode)
+	require.Equal(t, "学习", classifier.Classes[0].Name)
+	require.Equal(t, "知识学习类", classifier.Classes[0].Description)
+	require.Equal(t, "技能实践类", classifier.Classes[1].Description)
+
+	difyOutput, err := difyGenerator.NewDifyGenerator().Generate(parsed)
+	require.NoError(t, err, "Dify DSL generation failed")
+	require.Contains(t, string(difyOutput), "label: 学习")
+}
+
+// TestCozeGenerator_ManyClassifierIntents tests that 8-way classifiers of every source platform keep
+// one intent port per class and come back with one handle per intent.
+func TestCozeGenerator_ManyClassifierIntents(t *testing.T) {
+	const intentCount = 8
+
+	testCases := []struct {
+		name   string
+		parser interface {
+			Parse([]byte) (*models.UnifiedDSL, error)
+		}
+		fixture  string
+		newClass func(position int) (models.ClassifierClass, string) // Returns the class at a 1-based position and its edge handle
+	}{
+		{
+			name: "dify", parser: difyParser.NewDifyParser(), fixture: filepath.Join("dify", "dify_start_classifier_end.yml"),
+			newClass: func(position int) (models.ClassifierClass, string) {
+				id := strconv.Itoa(position)
+				return models.ClassifierClass{ID: id, Name: fmt.Sprintf("类别%d", position)}, id
+			},
+		},
+		{
+			name: "iflytek", parser: iflytekParser.NewIFlytekParser(), fixture: filepath.Join("iflytek", "iflytek_start_classifier_end.yml"),
+			newClass: func(position int) (models.ClassifierClass, string) {
+				id := fmt.Sprintf("intent-one-of::extra-%d", position)
+				return models.ClassifierClass{ID: id, Name: fmt.Sprintf("类别%d", position)}, id
+			},
+		},
+		{
+			name: "coze", parser: cozeParser.NewCozeParser(), fixture: filepath.Join("coze", "coze_start_classifier_end.yml"),
+			newClass: func(position int) (models.ClassifierClass, string) {
+				return models.ClassifierClass{ID: fmt.Sprintf("class_%d", position-1), Name: fmt.Sprintf("类别%d", position)}, strconv.Itoa(position)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", tc.fixture))
+			require.NoError(t, err, "failed to read fixture")
+			unifiedDSL, err := tc.parser.Parse(data)
+			require.NoError(t, err, "source parsing failed")
+
+			// Keep the classifier between start and end; Coze LLM nodes do not parse back
+			var kept []models.Node
+			for _, node := range unifiedDSL.Workflow.Nodes {
+				switch node.Type {
+				case models.NodeTypeStart, models.NodeTypeEnd, models.NodeTypeClassifier:
+					kept = append(kept, node)
+				}
+			}
+			unifiedDSL.Workflow.Nodes = kept
+			var classifier, end *models.Node
+			for i := range unifiedDSL.Workflow.Nodes {
+				switch unifiedDSL.Workflow.Nodes[i].Type {
+				case models.NodeTypeClassifier:
+					classifier = &unifiedDSL.Workflow.Nodes[i]
+				case models.NodeTypeEnd:
+					end = &unifiedDSL.Workflow.Nodes[i]
+					end.Inputs = nil
+				}
+			}
+			require.NotNil(t, classifier, "fixture should have a classifier node")
+			require.NotNil(t, end, "fixture should have an end node")
+			config, ok := common.AsClassifierConfig(classifier.Config)
+			require.True(t, ok)
+
+			// The fixture classes keep their handles, read from the fixture edges
+			fixtureHandles := make(map[string]bool)
+			var edges []models.Edge
+			for _, edge := range unifiedDSL.Workflow.Edges {
+				if edge.Source == classifier.ID {
+					fixtureHandles[edge.SourceHandle] = true
+				} else if edge.Target == classifier.ID {
+					edges = append(edges, edge)
+				}
+			}
+			var handles []string
+			for i, class := range config.Classes {
+				if class.IsDefault {
+					continue
+				}
+				if fixtureHandles[class.ID] {
+					handles = append(handles, class.ID)
+				} else {
+					handles = append(handles, strconv.Itoa(i+1))
+				}
+			}
+			for position := len(handles) + 1; position <= intentCount; position++ {
+				class, handle := tc.newClass(position)
+				config.Classes = append(config.Classes, class)
+				handles = append(handles, handle)
+			}
+			classifier.Config = config
+			require.Len(t, handles, intentCount)
+			for _, handle := range handles {
+				edges = append(edges, models.Edge{
+					ID: classifier.ID + "-" + handle, Source: classifier.ID, Target: end.ID,
+					SourceHandle: handle, Type: models.EdgeTypeDefault,
+				})
+			}
+			unifiedDSL.Workflow.Edges = edges
+
+			output, mapping, err := cozeGenerator.NewCozeGenerator().GenerateWithMapping(unifiedDSL)
+			require.NoError(t, err, "Coze DSL generation failed")
+			for i, handle := range handles {
+				require.Equal(t, fmt.Sprintf("branch_%d", i), mapping.Intents[classifier.ID][handle], "intent %d", i+1)
+			}
+
+			roundTrip, err := cozeParser.NewCozeParser().Parse(output)
+			require.NoError(t, err, "generated Coze DSL should parse")
+			parsedHandles := make(map[string]bool)
+			for _, edge := range roundTrip.Workflow.Edges {
+				if edge.Source == mapping.Nodes[classifier.ID] {
+					parsedHandles[edge.SourceHandle] = true
+				}
+			}
+			for position := 1; position <= intentCount; position++ {
+				require.True(t, parsedHandles[strconv.Itoa(position)], "intent %d should keep its edge", position)
+			}
+
+			// Coze positions become the Dify classes at the same position
+			difyOutput, err := difyGenerator.NewDifyGenerator().Generate(roundTrip)
+			require.NoError(t, err, "Dify DSL generation failed")
+			difyDSL, err := difyParser.NewDifyParser().Parse(difyOutput)
+			require.NoError(t, err, "generated Dify DSL should parse")
+			var difyClassifierID string
+			for _, node := range difyDSL.Workflow.Nodes {
+				if node.Type == models.NodeTypeClassifier {
+					difyClassifierID = node.ID
+				}
+			}
+			difyHandles := make(map[string]bool)
+			for _, edge := range difyDSL.Workflow.Edges {
+				if edge.Source == difyClassifierID {
+					difyHandles[edge.SourceHandle] = true
+				}
+			}
+			require.Len(t, difyHandles, intentCount, "every intent should keep its own Dify handle")
+		})
+	}
+}
+
+// TestCozeGenerator_Timestamps validates that Coze exports keep the source timestamps
+func TestCozeGenerator_Timestamps(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "coze_basic_start_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	unifiedDSL, err := cozeParser.NewCozeParser().Parse(data)
+	require.NoError(t, err, "source parsing failed")
+	require.Equal(t, int64(1758002272), unifiedDSL.Metadata.CreatedAt.Unix())
+	require.Equal(t, int64(1758002579), unifiedDSL.Metadata.UpdatedAt.Unix())
+
+	output, err := cozeGenerator.NewCozeGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Coze generation failed")
+	require.Contains(t, string(output), "createtime: 1758002272\nupdatetime: 1758002579\n")
+
+	// Sources without timestamps are stamped with the conversion time, not 1970
+	unifiedDSL.Metadata.CreatedAt = time.Time{}
+	output, err = cozeGenerator.NewCozeGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Coze generation failed")
+	require.NotContains(t, string(output), "createtime: 0\n")
+	require.Contains(t, string(output), "updatetime: 1758002579\n")
+}
+
+// TestOutputNameTemplate validates batch output naming: slugified fields and rejected names
+func TestOutputNameTemplate(t *testing.T) {
+	nameTemplate, err := common.ParseOutputNameTemplate("{{.Name}}-{{.TargetPlatform}}{{.Ext}}")
+	require.NoError(t, err)
+	name, err := nameTemplate.Render(common.OutputNameFields{
+		Name: "Customer Support / FAQ (v2)", TargetPlatform: models.PlatformCoze, Ext: ".yml",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "customer-support-faq-v2-coze.yml", name)
+	require.Equal(t, "智能学习助手", common.Slugify(" 智能学习助手 "))
+	require.Equal(t, "1.4.0", common.Slugify("1.4.0"))
+
+	_, err = common.ParseOutputNameTemplate("{{.Title}}.yml")
+	require.Error(t, err, "unknown fields fail when the template is parsed")
+	nameTemplate, err = common.ParseOutputNameTemplate("{{.SourcePlatform}}/{{.Stem}}")
+	require.NoError(t, err)
+	_, err = nameTemplate.Render(common.OutputNameFields{Stem: "a", SourcePlatform: models.PlatformDify})
+	require.Error(t, err, "names may not leave the output directory")
+}
+
+// TestCozeGenerator_ParallelIterationBatchNode checks that parallel iterations become Coze batch
+// nodes whose concurrency survives parsing the generated DSL again.
+func TestCozeGenerator_ParallelIterationBatchNode(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_iteration_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	input := strings.NewReplacer(
+		"is_parallel: false", "is_parallel: true",
+		"parallel_nums: 10", "parallel_nums: 4",
+	).Replace(string(data))
+	unifiedDSL, err := difyParser.NewDifyParser().Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+
+	output, err := cozeGenerator.NewCozeGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Coze DSL generation failed")
+	require.Contains(t, string(output), "batch-function-inline-output", "the body should hang off the batch ports")
+
+	roundTrip, err := cozeParser.NewCozeParser().Parse(output)
+	require.NoError(t, err, "generated Coze DSL should parse")
+
+	iterations := 0
+	for _, node := range roundTrip.Workflow.Nodes {
+		iterConfig, ok := common.AsIterationConfig(node.Config)
+		if node.Type != models.NodeTypeIteration || !ok || iterConfig == nil {
+			continue
+		}
+		iterations++
+		require.True(t, iterConfig.Execution.IsParallel, "the batch node should come back parallel")
+		require.Equal(t, 4, iterConfig.Execution.ParallelNums)
+		require.NotEmpty(t, iterConfig.SubWorkflow.Nodes, "the batch body should survive")
+	}
+	require.Equal(t, 1, iterations, "the workflow should have one iteration")
+}
+
+// TestCozeGenerator_BatchModeNode checks that a Coze node run in batch mode, which the parser wraps
+// in a parallel iteration, is generated as that node in batch mode again.
+func TestCozeGenerator_BatchModeNode(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "Workflow-X70_Vshuangrenxinlixue_video_1-draft-2241.zip"))
+	require.NoError(t, err, "file read failed")
+	unifiedDSL, err := cozeParser.NewCozeParser().Parse(data)
+	require.NoError(t, err, "Coze parsing failed")
+
+	output, mapping, err := cozeGenerator.NewCozeGenerator().GenerateWithMapping(unifiedDSL)
+	require.NoError(t, err, "Coze DSL generation failed")
+
+	var root struct {
+		Nodes []struct {
+			ID   string `yaml:"id"`
+			Type string `yaml:"type"`
+			Data struct {
+				Inputs  map[string]interface{}   `yaml:"inputs"`
+				Outputs []map[string]interface{} `yaml:"outputs"`
+			} `yaml:"data"`
+		} `yaml:"nodes"`
+		Edges []struct {
+			FromNode string `yaml:"from_node"`
+			FromPort string `yaml:"from_port"`
+		} `yaml:"edges"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &root))
+
+	batchNodeID := mapping.Nodes["139227"]
+	found := false
+	for _, node := range root.Nodes {
+		if node.ID != batchNodeID {
+			continue
+		}
+		found = true
+		require.Equal(t, "3", node.Type, "the LLM node should not become a batch node")
+		batch, _ := node.Data.Inputs["batch"].(map[string]interface{})
+		require.Equal(t, true, batch["batchEnable"], "the node should run in batch mode")
+		require.Len(t, node.Data.Outputs, 1, "the node should collect its outputs in one list")
+		require.Equal(t, "list", node.Data.Outputs[0]["type"])
+	}
+	require.True(t, found, "the batch-mode node should be generated")
+	for _, edge := range root.Edges {
+		if edge.FromNode == batchNodeID {
+			require.Empty(t, edge.FromPort, "edges leaving the node should have no port")
+		}
+	}
+}
+
+func TestCozeGenerator_ZIPLoopBody(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "Workflow-X72_Vminjiangushi_video_1-draft-2269.zip"))
+	require.NoError(t, err, "file read failed")
+	unifiedDSL, err := cozeParser.NewCozeParser().Parse(data)
+	require.NoError(t, err, "Coze parsing failed")
+
+	output, mapping, err := cozeGenerator.NewCozeGenerator().GenerateWithMapping(unifiedDSL)
+	require.NoError(t, err, "Coze DSL generation failed")
+
+	var root struct {
+		Nodes []struct {
+			ID     string `yaml:"id"`
+			Blocks []struct {
+				ID string `yaml:"id"`
+			} `yaml:"blocks"`
+			Data struct {
+				Outputs []struct {
+					Name  string `yaml:"name"`
+					Input struct {
+						Value struct {
+							Content struct {
+								BlockID string `yaml:"blockID"`
+							} `yaml:"content"`
+						} `yaml:"value"`
+					} `yaml:"input"`
+				} `yaml:"outputs"`
+			} `yaml:"data"`
+		} `yaml:"nodes"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &root))
+
+	loopID := mapping.Nodes["140775"]
+	found := false
+	for _, node := range root.Nodes {
+		if node.ID != loopID {
+			continue
+		}
+		found = true
+		require.NotEmpty(t, node.Blocks, "the loop body should be kept")
+		blockIDs := make(map[string]bool)
+		for _, block := range node.Blocks {
+			blockIDs[block.ID] = true
+		}
+		require.Len(t, node.Data.Outputs, 2, "the loop should keep every output")
+		for _, loopOutput := range node.Data.Outputs {
+			require.True(t, blockIDs[loopOutput.Input.Value.Content.BlockID],
+				"loop output %q should collect a body node", loopOutput.Name)
+		}
+	}
+	require.True(t, found, "the loop should be generated")
+}
+
+func TestCozeGenerator_LLMListTypes(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "Workflow-X72_Vminjiangushi_video_1-draft-2269.zip"))
+	require.NoError(t, err, "file read failed")
+	unifiedDSL, err := cozeParser.NewCozeParser().Parse(data)
+	require.NoError(t, err, "Coze parsing failed")
+
+	output, mapping, err := cozeGenerator.NewCozeGenerator().GenerateWithMapping(unifiedDSL)
+	require.NoError(t, err, "Coze DSL generation failed")
+
+	type listType struct {
+		Type   string `yaml:"type"`
+		Schema struct {
+			Type string `yaml:"type"`
+		} `yaml:"schema"`
+	}
+	var root struct {
+		Nodes []struct {
+			ID   string `yaml:"id"`
+			Data struct {
+				Inputs struct {
+					InputParameters []struct {
+						Name  string   `yaml:"name"`
+						Input listType `yaml:"input"`
+					} `yaml:"inputParameters"`
+				} `yaml:"inputs"`
+				Outputs []struct {
+					Name     string `yaml:"name"`
+					listType `yaml:",inline"`
+				} `yaml:"outputs"`
+			} `yaml:"data"`
+		} `yaml:"nodes"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &root))
+
+	llmID := mapping.Nodes["130809"]
+	found := false
+	for _, node := range root.Nodes {
+		if node.ID != llmID {
+			continue
+		}
+		found = true
+		for _, param := range node.Data.Inputs.InputParameters {
+			if param.Name == "wenanTimeline" {
+				require.Equal(t, "list", param.Input.Type, "list inputs should keep their type")
+				require.Equal(t, "string", param.Input.Schema.Type)
+			}
+		}
+		require.NotEmpty(t, node.Data.Outputs)
+		require.Equal(t, "list", node.Data.Outputs[0].Type, "list outputs should keep their type")
+		require.Equal(t, "string", node.Data.Outputs[0].Schema.Type)
+	}
+	require.True(t, found, "the LLM node should be generated")
+}
@@ -1,12 +1,24 @@
 package generators
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	cozeGenerator "github.com/iflytek/agentbridge/platforms/coze/generator"
+	difyGenerator "github.com/iflytek/agentbridge/platforms/dify/generator"
 	"github.com/iflytek/agentbridge/platforms/dify/strategies"
+	iflytekGenerator "github.com/iflytek/agentbridge/platforms/iflytek/generator"
+	iflytekParser "github.com/iflytek/agentbridge/platforms/iflytek/parser"
 	golden "github.com/iflytek/agentbridge/tests/unit/golden/basic_start_end"
 	codeGolden "github.com/iflytek/agentbridge/tests/unit/golden/code_workflow"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 // TestDifyGenerator_BasicStartEnd_FromIFlytek tests Dify DSL generation from iFlytek parsed basic start-end workflow.
@@ -20,6 +32,15 @@ func TestDifyGenerator_BasicStartEnd_FromIFlytek(t *testing.T) {
 	unifiedDSL := golden.GetIFlytekToUnified_BasicStartEnd()
 	require.NotNil(t, unifiedDSL, "unified DSL should not be nil")
 
+	// Return the end node variables directly; answer templates are covered by TestDifyGenerator_EndAnswerTemplate
+	for i, node := range unifiedDSL.Workflow.Nodes {
+		if config, ok := common.AsEndConfig(node.Config); ok && config != nil {
+			endConfig := *config
+			endConfig.OutputMode = models.EndOutputModeVariables
+			unifiedDSL.Workflow.Nodes[i].Config = endConfig
+		}
+	}
+
 	// Generate Dify DSL from unified DSL
 	difyDSL, err := generator.Generate(unifiedDSL)
 	require.NoError(t, err, "Dify DSL generation failed")
@@ -60,3 +81,724 @@ func TestDifyGenerator_CodeWorkflow_FromIFlytek(t *testing.T) {
 		t.Logf("Generated DSL length: %d bytes", len(difyDSL))
 	}
 }
+
+// TestDifyGenerator_TargetVersion tests version-specific Dify DSL adjustments.
+func TestDifyGenerator_TargetVersion(t *testing.T) {
+	unifiedDSL := golden.GetIFlytekToUnified_BasicStartEnd()
+	require.NotNil(t, unifiedDSL, "unified DSL should not be nil")
+
+	cases := []struct {
+		targetVersion   string
+		dslVersion      string
+		hasDependencies bool
+	}{
+		{"", "0.3.1", true},
+		{"1.x", "0.3.1", true},
+		{"0.15.3", "0.1.5", false},
+		{"0.6.x", "0.1.0", false},
+	}
+
+	for _, tc := range cases {
+		generator := difyGenerator.NewDifyGenerator()
+		require.NoError(t, generator.SetTargetVersion(tc.targetVersion))
+
+		output, err := generator.Generate(unifiedDSL)
+		require.NoError(t, err, "Dify DSL generation failed for %q", tc.targetVersion)
+
+		var root map[string]interface{}
+		require.NoError(t, yaml.Unmarshal(output, &root))
+		require.Equal(t, tc.dslVersion, root["version"], "unexpected DSL version for %q", tc.targetVersion)
+		_, hasDependencies := root["dependencies"]
+		require.Equal(t, tc.hasDependencies, hasDependencies, "unexpected dependencies for %q", tc.targetVersion)
+	}
+
+	require.Error(t, difyGenerator.NewDifyGenerator().SetTargetVersion("0.3"), "versions before 0.6 should be rejected")
+}
+
+// TestDifyGenerator_JSONOutput tests that JSON output holds the same document as the YAML output.
+func TestDifyGenerator_JSONOutput(t *testing.T) {
+	unifiedDSL := golden.GetIFlytekToUnified_BasicStartEnd()
+	require.NotNil(t, unifiedDSL, "unified DSL should not be nil")
+
+	yamlOutput, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err)
+
+	generator := difyGenerator.NewDifyGenerator()
+	require.NoError(t, generator.Configure(&models.ConversionOptions{OutputFormat: models.OutputFormatJSON}))
+	jsonOutput, err := generator.Generate(unifiedDSL)
+	require.NoError(t, err)
+
+	var fromYAML, fromJSON map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(yamlOutput, &fromYAML))
+	require.NoError(t, json.Unmarshal(jsonOutput, &fromJSON))
+	require.Equal(t, fromYAML["version"], fromJSON["version"])
+	require.Equal(t, fromYAML["kind"], fromJSON["kind"])
+
+	yamlGraph := fromYAML["workflow"].(map[string]interface{})["graph"].(map[string]interface{})
+	jsonGraph := fromJSON["workflow"].(map[string]interface{})["graph"].(map[string]interface{})
+	require.Len(t, jsonGraph["nodes"], len(yamlGraph["nodes"].([]interface{})))
+	require.Len(t, jsonGraph["edges"], len(yamlGraph["edges"].([]interface{})))
+}
+
+// TestDifyGenerator_ConditionOperators tests that condition operators are translated to Dify
+// operators and that operators Dify cannot express fail the generation.
+func TestDifyGenerator_ConditionOperators(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_start_condition_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+
+	withOperator := func(operator string, varType models.UnifiedDataType) *models.UnifiedDSL {
+		unifiedDSL, err := iflytekParser.NewIFlytekParser().Parse(data)
+		require.NoError(t, err, "iFlytek parsing failed")
+		for i := range unifiedDSL.Workflow.Nodes {
+			config, ok := common.AsConditionConfig(unifiedDSL.Workflow.Nodes[i].Config)
+			if !ok || config == nil {
+				continue
+			}
+			for c := range config.Cases {
+				for k := range config.Cases[c].Conditions {
+					config.Cases[c].Conditions[k].ComparisonOperator = operator
+					config.Cases[c].Conditions[k].VarType = varType
+				}
+			}
+			unifiedDSL.Workflow.Nodes[i].Config = config
+		}
+		return unifiedDSL
+	}
+
+	output, err := difyGenerator.NewDifyGenerator().Generate(withOperator("ge", models.DataTypeInteger))
+	require.NoError(t, err, "Dify DSL generation failed")
+	require.Contains(t, string(output), "comparison_operator: ≥", "numeric operators should use Dify symbols")
+	require.NotContains(t, string(output), ">=")
+
+	output, err = difyGenerator.NewDifyGenerator().Generate(withOperator("not equals", models.DataTypeString))
+	require.NoError(t, err, "Dify DSL generation failed")
+	require.Contains(t, string(output), "comparison_operator: is not")
+
+	_, err = difyGenerator.NewDifyGenerator().Generate(withOperator(common.OperatorLengthGT, models.DataTypeString))
+	require.ErrorContains(t, err, "not supported on dify", "length comparisons have no Dify equivalent")
+}
+
+// TestDifyGenerator_EndAnswerTemplate tests that the answer template of a template mode end node
+// is rendered by a code node whose result the Dify end node returns.
+func TestDifyGenerator_EndAnswerTemplate(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_basic_start_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	unifiedDSL, err := iflytekParser.NewIFlytekParser().Parse(data)
+	require.NoError(t, err, "iFlytek parsing failed")
+
+	output, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+
+	var root struct {
+		Workflow struct {
+			Graph struct {
+				Nodes []struct {
+					ID   string                 `yaml:"id"`
+					Data map[string]interface{} `yaml:"data"`
+				} `yaml:"nodes"`
+			} `yaml:"graph"`
+		} `yaml:"workflow"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &root))
+
+	var renderID string
+	var endOutputs []interface{}
+	for _, node := range root.Workflow.Graph.Nodes {
+		switch node.Data["type"] {
+		case "code":
+			require.Contains(t, node.Data["code"], "{{result2}}", "the render node should carry the answer template")
+			renderID = node.ID
+		case "end":
+			endOutputs, _ = node.Data["outputs"].([]interface{})
+		}
+	}
+	require.NotEmpty(t, renderID, "a code node should render the answer template")
+	require.Len(t, endOutputs, 1, "the end node should return the rendered answer only")
+	selector := endOutputs[0].(map[string]interface{})["value_selector"].([]interface{})
+	require.Equal(t, []interface{}{renderID, "output"}, selector)
+
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if config, ok := common.AsEndConfig(node.Config); ok && config != nil {
+			require.Equal(t, models.EndOutputModeTemplate, config.OutputMode, "generation should not modify the unified DSL")
+		}
+	}
+}
+
+// TestDifyGenerator_UnknownFieldsRoundTrip tests that node keys the Dify parser does not model survive a Dify round trip.
+func TestDifyGenerator_UnknownFieldsRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	// A data key and a node key newer Dify releases might add
+	input := strings.Replace(string(data), "        type: llm\n        vision:\n          enabled: false\n",
+		"        type: llm\n        vision:\n          enabled: false\n        reasoning_format:\n          mode: separated\n          max_depth: 3\n      x_review_state: approved\n", 1)
+	require.NotEqual(t, string(data), input, "fixture should contain the LLM node")
+
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+
+	output, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+
+	var root struct {
+		Workflow struct {
+			Graph struct {
+				Nodes []map[string]interface{} `yaml:"nodes"`
+			} `yaml:"graph"`
+		} `yaml:"workflow"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &root))
+
+	llmNodes := 0
+	for _, node := range root.Workflow.Graph.Nodes {
+		data := node["data"].(map[string]interface{})
+		if data["type"] != "llm" {
+			require.NotContains(t, data, "reasoning_format", "unknown keys belong to their source node only")
+			continue
+		}
+		if _, ok := data["reasoning_format"]; !ok {
+			continue
+		}
+		llmNodes++
+		require.Equal(t, map[string]interface{}{"mode": "separated", "max_depth": 3}, data["reasoning_format"])
+		require.Equal(t, "approved", node["x_review_state"])
+	}
+	require.Equal(t, 1, llmNodes, "the LLM node should keep its unknown keys")
+}
+
+// TestDifyGenerator_IterationExecutionRoundTrip checks that parallelism and error handling of
+// iterations survive a Dify round trip and are reported for sequential targets.
+func TestDifyGenerator_IterationExecutionRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_iteration_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	input := strings.NewReplacer(
+		"error_handle_mode: terminated", "error_handle_mode: continue-on-error",
+		"is_parallel: false", "is_parallel: true",
+		"parallel_nums: 10", "parallel_nums: 4",
+	).Replace(string(data))
+
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+
+	output, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+
+	var root struct {
+		Workflow struct {
+			Graph struct {
+				Nodes []struct {
+					Data map[string]interface{} `yaml:"data"`
+				} `yaml:"nodes"`
+			} `yaml:"graph"`
+		} `yaml:"workflow"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &root))
+
+	iterations := 0
+	for _, node := range root.Workflow.Graph.Nodes {
+		if node.Data["type"] != "iteration" {
+			continue
+		}
+		iterations++
+		require.Equal(t, true, node.Data["is_parallel"])
+		require.Equal(t, 4, node.Data["parallel_nums"])
+		require.Equal(t, models.ErrorHandleModeContinue, node.Data["error_handle_mode"])
+	}
+	require.Equal(t, 1, iterations, "the workflow should have one iteration")
+
+	require.Empty(t, common.CheckIterationExecution(unifiedDSL, models.PlatformDify))
+	require.Len(t, common.CheckIterationExecution(unifiedDSL, models.PlatformIFlytek), 2,
+		"iFlytek should report both the parallelism and the error handling")
+	require.Len(t, common.CheckIterationExecution(unifiedDSL, models.PlatformCoze), 1,
+		"Coze batch nodes keep the parallelism but not the error handling")
+}
+
+// TestDifyGenerator_IterationSubNodeLayout checks that iteration sub-nodes of one type, which the
+// generator puts at the same spot, are stacked apart and that the iteration is sized to hold them.
+func TestDifyGenerator_IterationSubNodeLayout(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_iteration_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+
+	var duplicates []models.Node
+	for i := range unifiedDSL.Workflow.Nodes {
+		body, _ := common.IterationBody(unifiedDSL, &unifiedDSL.Workflow.Nodes[i])
+		for _, subNode := range body {
+			if subNode.Type == models.NodeTypeStart || subNode.Type == models.NodeTypeEnd {
+				continue
+			}
+			for copyIndex := 1; copyIndex <= 2; copyIndex++ {
+				duplicate := *subNode
+				duplicate.ID = fmt.Sprintf("%s%d", subNode.ID, copyIndex)
+				duplicates = append(duplicates, duplicate)
+			}
+			break
+		}
+	}
+	unifiedDSL.Workflow.Nodes = append(unifiedDSL.Workflow.Nodes, duplicates...)
+	duplicated := len(duplicates)
+	require.Equal(t, 2, duplicated, "the iteration body should have a node to duplicate")
+
+	output, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+
+	var root struct {
+		Workflow struct {
+			Graph struct {
+				Nodes []struct {
+					ParentID string                 `yaml:"parentId"`
+					Width    float64                `yaml:"width"`
+					Height   float64                `yaml:"height"`
+					Position map[string]float64     `yaml:"position"`
+					Data     map[string]interface{} `yaml:"data"`
+				} `yaml:"nodes"`
+			} `yaml:"graph"`
+		} `yaml:"workflow"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &root))
+
+	var iterationWidth, iterationHeight float64
+	for _, node := range root.Workflow.Graph.Nodes {
+		if node.Data["type"] == "iteration" {
+			iterationWidth, iterationHeight = node.Width, node.Height
+		}
+	}
+	require.Greater(t, iterationHeight, 250.0, "the iteration should grow for the stacked nodes")
+
+	positions := make(map[string]bool)
+	children := 0
+	for _, node := range root.Workflow.Graph.Nodes {
+		if node.ParentID == "" {
+			continue
+		}
+		children++
+		key := fmt.Sprintf("%v,%v", node.Position["x"], node.Position["y"])
+		require.False(t, positions[key], "two iteration sub-nodes share position %s", key)
+		positions[key] = true
+		require.LessOrEqual(t, node.Position["x"]+node.Width, iterationWidth, "sub-node %s sticks out to the right", key)
+		require.LessOrEqual(t, node.Position["y"]+node.Height, iterationHeight, "sub-node %s sticks out below", key)
+	}
+	require.GreaterOrEqual(t, children, 4, "start node, sub-node and its copies expected")
+}
+
+// TestDifyGenerator_ErrorHandling checks that retries and the default-value strategy of a Dify
+// LLM node survive a Dify round trip and become Coze error settings.
+func TestDifyGenerator_ErrorHandling(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	input := strings.Replace(string(data), "        type: llm\n        vision:\n          enabled: false\n",
+		"        type: llm\n        vision:\n          enabled: false\n        error_strategy: default-value\n        default_value:\n        - key: text\n          type: string\n          value: 暂无建议\n        retry_config:\n          enabled: true\n          max_retries: 2\n          retry_interval: 500\n", 1)
+	require.NotEqual(t, string(data), input, "fixture should contain the LLM node")
+
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+
+	expected := &models.ErrorHandling{
+		Strategy:      models.ErrorStrategyDefaultValue,
+		DefaultValues: map[string]interface{}{"output": "暂无建议"},
+		MaxRetries:    2,
+		RetryInterval: 500,
+	}
+	var llmNode *models.Node
+	for i := range unifiedDSL.Workflow.Nodes {
+		if unifiedDSL.Workflow.Nodes[i].Type == models.NodeTypeLLM {
+			llmNode = &unifiedDSL.Workflow.Nodes[i]
+		}
+	}
+	require.NotNil(t, llmNode, "the workflow should have an LLM node")
+	require.Equal(t, expected, llmNode.ErrorHandling)
+
+	output, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+
+	var root struct {
+		Workflow struct {
+			Graph struct {
+				Nodes []struct {
+					Data map[string]interface{} `yaml:"data"`
+				} `yaml:"nodes"`
+			} `yaml:"graph"`
+		} `yaml:"workflow"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &root))
+	for _, node := range root.Workflow.Graph.Nodes {
+		if node.Data["type"] != "llm" {
+			require.NotContains(t, node.Data, "error_strategy")
+			continue
+		}
+		require.Equal(t, models.ErrorStrategyDefaultValue, node.Data["error_strategy"])
+		require.Equal(t, map[string]interface{}{"enabled": true, "max_retries": 2, "retry_interval": 500}, node.Data["retry_config"])
+		require.Equal(t, []interface{}{map[string]interface{}{"key": "text", "type": "string", "value": "暂无建议"}}, node.Data["default_value"])
+	}
+
+	cozeOutput, err := cozeGenerator.NewCozeGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Coze DSL generation failed")
+	require.Contains(t, string(cozeOutput), "processType: 2", "Coze should output the default values on errors")
+	require.Contains(t, string(cozeOutput), "暂无建议")
+	require.Empty(t, common.CheckErrorHandling(unifiedDSL, models.PlatformCoze))
+	require.Len(t, common.CheckErrorHandling(unifiedDSL, models.PlatformIFlytek), 1, "iFlytek drops the error handling")
+}
+
+// TestDifyGenerator_MemoryRoundTrip tests that chatflow LLM memory survives Dify and iFlytek round trips.
+func TestDifyGenerator_MemoryRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	input := strings.Replace(string(data), "  mode: workflow\n", "  mode: advanced-chat\n", 1)
+	input = strings.Replace(input, "        type: llm\n        vision:\n          enabled: false\n",
+		"        type: llm\n        vision:\n          enabled: false\n        memory:\n          query_prompt_template: '{{#sys.query#}}'\n          window:\n            enabled: true\n            size: 5\n", 1)
+	require.NotEqual(t, string(data), input, "fixture should contain the LLM node")
+
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+
+	expected := &models.MemoryConfig{Enabled: true, Window: 5, QueryPrompt: "{{#sys.query#}}"}
+	var memory *models.MemoryConfig
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if node.Type == models.NodeTypeLLM {
+			memory = common.MemoryOf(node)
+		}
+	}
+	require.Equal(t, expected, memory)
+	require.Empty(t, common.CheckMemory(unifiedDSL, models.PlatformDify), "chatflows keep the memory")
+
+	output, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+	require.Contains(t, string(output), "query_prompt_template: '{{#sys.query#}}'")
+	require.Contains(t, string(output), "size: 5")
+
+	iflytekOutput, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	parsed, err := iflytekParser.NewIFlytekParser().Parse(iflytekOutput)
+	require.NoError(t, err, "iFlytek parsing failed")
+	memory = nil
+	for _, node := range parsed.Workflow.Nodes {
+		if node.Type == models.NodeTypeLLM {
+			memory = common.MemoryOf(node)
+		}
+	}
+	require.Equal(t, &models.MemoryConfig{Enabled: true, Window: 5}, memory)
+
+	unifiedDSL.PlatformMetadata.Dify.Mode = "workflow"
+	require.Len(t, common.CheckMemory(unifiedDSL, models.PlatformDify), 1, "workflow apps drop the memory")
+}
+
+// TestDifyGenerator_StructuredOutput tests that LLM JSON schemas become outputs on iFlytek and Coze and come back.
+func TestDifyGenerator_StructuredOutput(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	input := strings.Replace(string(data), "        type: llm\n        vision:\n          enabled: false\n",
+		"        type: llm\n        vision:\n          enabled: false\n        structured_output_enabled: true\n        structured_output:\n          schema:\n            type: object\n            properties:\n              plan:\n                type: string\n              days:\n                type: integer\n            required:\n            - plan\n", 1)
+	require.NotEqual(t, string(data), input, "fixture should contain the LLM node")
+
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+
+	var llmNode models.Node
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if node.Type == models.NodeTypeLLM {
+			llmNode = node
+		}
+	}
+	llmConfig, ok := common.AsLLMConfig(llmNode.Config)
+	require.True(t, ok, "the workflow should have an LLM node")
+	require.Equal(t, models.ResponseFormatJSON, llmConfig.Parameters.ResponseFormat)
+	outputs := common.StructuredOutputs(llmNode)
+	require.Len(t, outputs, 3, "schema fields should follow the text output")
+	require.Equal(t, models.Output{Name: "days", Type: models.DataTypeInteger}, outputs[1])
+
+	output, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+	require.Contains(t, string(output), "structured_output_enabled: true")
+	require.Contains(t, string(output), "days:")
+
+	cozeOutput, err := cozeGenerator.NewCozeGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Coze DSL generation failed")
+	require.Contains(t, string(cozeOutput), "name: days")
+
+	iflytekOutput, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	parsed, err := iflytekParser.NewIFlytekParser().Parse(iflytekOutput)
+	require.NoError(t, err, "iFlytek parsing failed")
+	for _, node := range parsed.Workflow.Nodes {
+		if parsedConfig, ok := common.AsLLMConfig(node.Config); ok && parsedConfig != nil {
+			require.Equal(t, models.ResponseFormatJSON, parsedConfig.Parameters.ResponseFormat)
+			require.Contains(t, parsedConfig.OutputSchema["properties"], "plan")
+		}
+	}
+}
+
+// TestDifyGenerator_StartDefaults tests that start input defaults and placeholders survive Dify and Coze, and are reported for iFlytek.
+func TestDifyGenerator_StartDefaults(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_code_end.yml"))
+	require.NoError(t, err, "file read failed")
+	input := strings.Replace(string(data), "          variable: name\n",
+		"          variable: name\n          default: Alice\n          placeholder: Your name\n          hint: Used in the greeting\n", 1)
+	require.NotEqual(t, string(data), input, "fixture should contain the start variable")
+
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+
+	var variable models.Variable
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if startConfig, ok := common.AsStartConfig(node.Config); ok && startConfig != nil {
+			variable = startConfig.Variables[0]
+		}
+	}
+	require.Equal(t, "Alice", variable.Default)
+	require.Equal(t, "Your name", variable.Placeholder)
+	require.Equal(t, "Used in the greeting", variable.Description)
+
+	output, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+	for _, field := range []string{"default: Alice", "placeholder: Your name", "hint: Used in the greeting"} {
+		require.Contains(t, string(output), field)
+	}
+
+	cozeOutput, err := cozeGenerator.NewCozeGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Coze DSL generation failed")
+	require.Contains(t, string(cozeOutput), "defaultValue: Alice")
+
+	require.Empty(t, common.CheckStartDefaults(unifiedDSL, models.PlatformCoze), "Coze keeps defaults")
+	warnings := common.CheckStartDefaults(unifiedDSL, models.PlatformIFlytek)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], `"name" defaults to Alice`)
+}
+
+// TestDifyGenerator_ClassifierSettingsRoundTrip tests that classifier instructions, few-shot examples and
+// model parameters survive iFlytek -> Dify -> iFlytek.
+func TestDifyGenerator_ClassifierSettingsRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_start_classifier_end.yml"))
+	require.NoError(t, err, "file read failed")
+	unifiedDSL, err := iflytekParser.NewIFlytekParser().Parse(data)
+	require.NoError(t, err, "iFlytek parsing failed")
+
+	classifier := func(dsl *models.UnifiedDSL) *models.ClassifierConfig {
+		for _, node := range dsl.Workflow.Nodes {
+			if config, ok := common.AsClassifierConfig(node.Config); ok && config != nil {
+				return config
+			}
+		}
+		t.Fatal("no classifier node")
+		return nil
+	}
+	examples := func(config *models.ClassifierConfig) map[string]string {
+		names := make(map[string]string)
+		for _, class := range config.Classes {
+			names[class.ID] = class.Name
+		}
+		byQuery := make(map[string]string)
+		for _, example := range config.Examples {
+			byQuery[example.Query] = names[example.Class]
+		}
+		return byQuery
+	}
+
+	for i := range unifiedDSL.Workflow.Nodes {
+		node := &unifiedDSL.Workflow.Nodes[i]
+		config, ok := common.AsClassifierConfig(node.Config)
+		if !ok || config == nil {
+			continue
+		}
+		require.Equal(t, "xdeepseekv3", config.Model.Name, "the domain names the model")
+		config.Instructions = "Classify the learning goal in {{Query}}"
+		config.Examples = []models.ClassifierExample{
+			{Query: "What is a closure?", Class: config.Classes[0].ID},
+			{Query: "Build a \"todo\" app -> deploy it", Class: config.Classes[1].ID},
+		}
+		config.Parameters.Temperature = 0.3
+		config.Parameters.MaxTokens = 2048
+		node.Config = *config
+	}
+	want := examples(classifier(unifiedDSL))
+
+	difyOutput, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+	require.Contains(t, string(difyOutput), "Examples:\n")
+	difyParser, err := strategies.NewDifyStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	fromDify, err := difyParser.Parse(difyOutput)
+	require.NoError(t, err, "Dify parsing failed")
+
+	config := classifier(fromDify)
+	require.True(t, strings.HasPrefix(config.Instructions, "Classify the learning goal in {{#"), config.Instructions)
+	require.Equal(t, want, examples(config))
+	require.Equal(t, 0.3, config.Parameters.Temperature)
+	require.Equal(t, 2048, config.Parameters.MaxTokens)
+
+	iflytekOutput, err := iflytekGenerator.NewIFlytekGenerator().Generate(fromDify)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	backToIFlytek, err := iflytekParser.NewIFlytekParser().Parse(iflytekOutput)
+	require.NoError(t, err, "iFlytek parsing failed")
+
+	config = classifier(backToIFlytek)
+	require.Equal(t, "Classify the learning goal in {{Query}}", config.Instructions)
+	require.Equal(t, want, examples(config))
+	require.Equal(t, 0.3, config.Parameters.Temperature)
+	require.Equal(t, 2048, config.Parameters.MaxTokens)
+}
+
+// TestDifyGenerator_FeaturesRoundTrip tests that the features block of a Dify source survives a
+// Dify round trip and maps to the unified features and UI config.
+func TestDifyGenerator_FeaturesRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	input := strings.Replace(string(data), "    sensitive_word_avoidance:\n      enabled: false\n    speech_to_text:\n      enabled: false\n",
+		"    sensitive_word_avoidance:\n      enabled: true\n    speech_to_text:\n      enabled: true\n", 1)
+	require.NotEqual(t, string(data), input, "fixture should contain the features block")
+
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+
+	features := unifiedDSL.Workflow.Features
+	require.NotNil(t, features)
+	require.True(t, features.SpeechToText.Enabled)
+	require.Equal(t, unifiedDSL.Metadata.UIConfig.OpeningStatement, features.OpeningStatement)
+	require.Contains(t, unifiedDSL.PlatformMetadata.Dify.Features, "sensitive_word_avoidance")
+
+	// The UI config wins over the kept block, so edits such as anonymization reach the output
+	unifiedDSL.Metadata.UIConfig.OpeningStatement = "你好"
+	output, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+
+	var generated struct {
+		Workflow struct {
+			Features map[string]interface{} `yaml:"features"`
+		} `yaml:"workflow"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &generated))
+	require.Equal(t, map[string]interface{}{"enabled": true}, generated.Workflow.Features["sensitive_word_avoidance"])
+	require.Equal(t, map[string]interface{}{"enabled": true}, generated.Workflow.Features["speech_to_text"])
+	require.Equal(t, "你好", generated.Workflow.Features["opening_statement"])
+}
+
+// TestDifyGenerator_AgentNode tests that agents stay agent nodes on Dify, with their tools, strategy
+// and iteration limit, and are lowered to LLM nodes on the other targets.
+func TestDifyGenerator_AgentNode(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_agent_end.yml"))
+	require.NoError(t, err, "file read failed")
+	parser, err := strategies.NewDifyStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	for _, target := range []models.PlatformType{models.PlatformIFlytek, models.PlatformCoze} {
+		unifiedDSL, err := parser.Parse(data)
+		require.NoError(t, err, "Dify parsing failed")
+		common.LowerNodes(unifiedDSL, target, nil)
+		require.Equal(t, models.NodeTypeLLM, unifiedDSL.GetNodeByID("1754290000001").Type, target)
+		require.Equal(t, common.SupportDegraded, common.NodeCapabilityFor(models.Node{Type: models.NodeTypeAgent}, target, nil).Level)
+	}
+
+	unifiedDSL, err := parser.Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+	common.LowerNodes(unifiedDSL, models.PlatformDify, nil)
+	require.Equal(t, models.NodeTypeAgent, unifiedDSL.GetNodeByID("1754290000001").Type)
+	require.Equal(t, common.SupportNative, common.NodeCapabilityFor(models.Node{Type: models.NodeTypeAgent}, models.PlatformDify, nil).Level)
+
+	output, err := difyGenerator.NewDifyGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "Dify DSL generation failed")
+
+	var generated struct {
+		Workflow struct {
+			Graph struct {
+				Nodes []struct {
+					ID   string `yaml:"id"`
+					Data struct {
+						Type                      string                 `yaml:"type"`
+						AgentStrategyName         string                 `yaml:"agent_strategy_name"`
+						AgentStrategyProviderName string                 `yaml:"agent_strategy_provider_name"`
+						AgentParameters           map[string]interface{} `yaml:"agent_parameters"`
+					} `yaml:"data"`
+				} `yaml:"nodes"`
+			} `yaml:"graph"`
+		} `yaml:"workflow"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &generated))
+	var parameters map[string]interface{}
+	var startID string
+	for _, node := range generated.Workflow.Graph.Nodes {
+		if node.Data.Type == "start" {
+			startID = node.ID
+		}
+		if node.Data.Type == "agent" {
+			require.Equal(t, models.AgentStrategyFunctionCalling, node.Data.AgentStrategyName)
+			require.Equal(t, "langgenius/agent/agent", node.Data.AgentStrategyProviderName)
+			parameters = node.Data.AgentParameters
+		}
+	}
+	require.NotNil(t, parameters, "agent node not generated")
+
+	value := func(name string) interface{} {
+		return parameters[name].(map[string]interface{})["value"]
+	}
+	require.Equal(t, 5, value("maximum_iterations"))
+	require.Equal(t, "学习内容：{{#"+startID+".input_01#}} 学习目标：{{#"+startID+".input_text_01#}}", value("query"))
+	require.Equal(t, "你是学习顾问，可以调用搜索工具查找学习资料。", value("instruction"))
+	model := value("model").(map[string]interface{})
+	require.Equal(t, "xdeepseekv32", model["model"])
+	require.Equal(t, "langgenius/openai_api_compatible/openai_api_compatible", model["provider"])
+	require.Equal(t, map[string]interface{}{"temperature": 0.7}, model["completion_params"], "unset params stay unset")
+
+	tools := value("tools").([]interface{})
+	require.Len(t, tools, 2, "disabled tools are kept")
+	require.Equal(t, "ddgo_search", tools[0].(map[string]interface{})["tool_name"])
+	require.Equal(t, true, tools[0].(map[string]interface{})["enabled"])
+	require.Equal(t, false, tools[1].(map[string]interface{})["enabled"])
+}
+
+// TestReplaceTemplateNodeReferences tests rewriting node IDs in {{#id.field#}} references
+func TestReplaceTemplateNodeReferences(t *testing.T) {
+	mapping := map[string]string{
+		"a":            "1001",
+		"a.b":          "1002",
+		"spark-llm::x": "1003",
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"no reference", "plain text", "plain text"},
+		{"single", "{{#a.output#}}", "{{#1001.output#}}"},
+		{"several", "x {{#a.out#}} y {{#spark-llm::x.text#}}", "x {{#1001.out#}} y {{#1003.text#}}"},
+		{"longest id first", "{{#a.b.output#}}", "{{#1002.output#}}"},
+		{"unmapped id", "{{#c.output#}} {{#a.output#}}", "{{#c.output#}} {{#1001.output#}}"},
+		{"id prefix", "{{#ab.output#}}", "{{#ab.output#}}"},
+		{"unterminated", "{{#a.output", "{{#1001.output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, common.ReplaceTemplateNodeReferences(tt.text, mapping))
+		})
+	}
+}
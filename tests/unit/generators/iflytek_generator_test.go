@@ -1,13 +1,27 @@
 package generators
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/internal/simulator"
+	"github.com/iflytek/agentbridge/platforms/common"
+	difyParser "github.com/iflytek/agentbridge/platforms/dify/parser"
+	iflytekGenerator "github.com/iflytek/agentbridge/platforms/iflytek/generator"
+	iflytekParser "github.com/iflytek/agentbridge/platforms/iflytek/parser"
 	"github.com/iflytek/agentbridge/platforms/iflytek/strategies"
 	golden "github.com/iflytek/agentbridge/tests/unit/golden/basic_start_end"
 	codeGolden "github.com/iflytek/agentbridge/tests/unit/golden/code_workflow"
 
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 // TestIFlytekGenerator_BasicStartEnd_FromCoze tests iFlytek DSL generation from Coze parsed basic start-end workflow.
@@ -113,3 +127,1144 @@ func TestIFlytekGenerator_CodeWorkflow_FromDify(t *testing.T) {
 		t.Logf("Generated DSL length: %d bytes", len(iflytekDSL))
 	}
 }
+
+// TestIFlytekGenerator_DSLVersionNegotiation tests iFlytek DSL version selection.
+func TestIFlytekGenerator_DSLVersionNegotiation(t *testing.T) {
+	unifiedDSL := golden.GetDifyToUnified_Basic_start_end()
+	require.NotNil(t, unifiedDSL, "unified DSL should not be nil")
+
+	cases := []struct {
+		targetVersion string
+		dslVersion    string
+	}{
+		{"", "v1"},
+		{"v2", "v2"},
+		{"1", "v1"},
+	}
+
+	for _, tc := range cases {
+		generator := iflytekGenerator.NewIFlytekGenerator()
+		require.NoError(t, generator.SetTargetVersion(tc.targetVersion))
+
+		output, err := generator.Generate(unifiedDSL)
+		require.NoError(t, err, "iFlytek DSL generation failed for %q", tc.targetVersion)
+
+		var root struct {
+			FlowMeta struct {
+				DSLVersion string `yaml:"dslVersion"`
+			} `yaml:"flowMeta"`
+		}
+		require.NoError(t, yaml.Unmarshal(output, &root))
+		require.Equal(t, tc.dslVersion, root.FlowMeta.DSLVersion, "unexpected DSL version for %q", tc.targetVersion)
+	}
+
+	require.Error(t, iflytekGenerator.NewIFlytekGenerator().SetTargetVersion("v9"), "unknown versions should be rejected")
+}
+
+func TestIFlytekGenerator_IDMapping(t *testing.T) {
+	unifiedDSL := golden.GetDifyToUnified_Basic_start_end()
+	require.NotNil(t, unifiedDSL, "unified DSL should not be nil")
+
+	generator := iflytekGenerator.NewIFlytekGenerator()
+	output, err := generator.Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+
+	mapping := generator.GetIDMapping()
+	require.NotNil(t, mapping)
+
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		targetID, exists := mapping.Nodes[node.ID]
+		require.True(t, exists, "node %s should be mapped", node.ID)
+		require.Contains(t, string(output), targetID, "mapped node ID should appear in output")
+	}
+
+	for sourceID, outputs := range mapping.Outputs {
+		require.Contains(t, mapping.Nodes, sourceID, "outputs should be keyed by source node ID")
+		for _, outputID := range outputs {
+			require.Contains(t, string(output), outputID, "mapped output ID should appear in output")
+		}
+	}
+}
+
+func TestIFlytekGenerator_PreviousMappingReuse(t *testing.T) {
+	unifiedDSL := golden.GetDifyToUnified_Basic_start_end()
+	require.NotNil(t, unifiedDSL, "unified DSL should not be nil")
+
+	first := iflytekGenerator.NewIFlytekGenerator()
+	_, err := first.Generate(unifiedDSL)
+	require.NoError(t, err, "first generation failed")
+	previous := first.GetIDMapping()
+
+	second := iflytekGenerator.NewIFlytekGenerator()
+	require.NoError(t, second.Configure(&models.ConversionOptions{PreviousMapping: previous}))
+	output, err := second.Generate(unifiedDSL)
+	require.NoError(t, err, "second generation failed")
+
+	current := second.GetIDMapping()
+	require.Equal(t, previous.Nodes, current.Nodes, "node IDs should be reused")
+	require.Equal(t, previous.Outputs, current.Outputs, "output IDs should be reused")
+	for _, targetID := range previous.Nodes {
+		require.Contains(t, string(output), targetID, "reused node ID should appear in output")
+	}
+}
+
+func TestIFlytekGenerator_ConcurrentGenerate(t *testing.T) {
+	unifiedDSL := codeGolden.GetDifyToUnified_Code_workflow()
+	require.NotNil(t, unifiedDSL, "unified DSL should not be nil")
+
+	generator := iflytekGenerator.NewIFlytekGenerator()
+	const workers = 8
+	outputs := make([][]byte, workers)
+	mappings := make([]*models.IDMapping, workers)
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			outputs[i], mappings[i], errs[i] = generator.GenerateWithMapping(unifiedDSL)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		require.NoError(t, errs[i], "generation %d failed", i)
+		require.Len(t, mappings[i].Nodes, len(unifiedDSL.Workflow.Nodes), "generation %d should map every node", i)
+		for _, targetID := range mappings[i].Nodes {
+			require.Contains(t, string(outputs[i]), targetID, "generation %d should only map its own node IDs", i)
+		}
+	}
+}
+
+// TestIFlytekNodeKind tests node kind detection from iFlytek node IDs.
+func TestIFlytekNodeKind(t *testing.T) {
+	cases := map[string]iflytekGenerator.NodeKind{
+		"spark-llm::6f1b6a1e-0d0c-4f51-9a33-5c1f4b7e2d10":            iflytekGenerator.NodeKindLLM,
+		"iteration::7edacd7a-facc-475c-bac2-5ea63d63a135":            iflytekGenerator.NodeKindIteration,
+		"iteration-node-start::d4274d51-b9aa-4508-ba31-f49703ab6d61": iflytekGenerator.NodeKindIterationStart,
+		"node-start::d61b0f71-87ee-475e-93ba-f1607f0ce783":           iflytekGenerator.NodeKindStart,
+		"1760000000000": iflytekGenerator.NodeKindUnknown,
+		"plugin::4a5c":  iflytekGenerator.NodeKindUnknown,
+	}
+	for nodeID, expected := range cases {
+		require.Equal(t, expected, iflytekGenerator.NodeKindOf(nodeID), nodeID)
+	}
+
+	for _, nodeType := range []models.NodeType{models.NodeTypeStart, models.NodeTypeLLM, models.NodeTypeClassifier, models.NodeTypeHumanInput} {
+		kind := iflytekGenerator.NodeKindFor(nodeType)
+		require.True(t, kind.Is(kind.NodeID("0918514b-72a8-4646-8dd9-ff4a8fc26d44")), nodeType)
+	}
+	require.Equal(t, iflytekGenerator.NodeKindUnknown, iflytekGenerator.NodeKindFor(models.NodeTypeAgent))
+}
+
+// TestIFlytekGenerator_IterationSubNodesWithSameTitle tests that iteration sub-nodes sharing a title are mapped apart.
+func TestIFlytekGenerator_IterationSubNodesWithSameTitle(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_iteration_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+
+	var duplicate models.Node
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if codeConfig, ok := common.AsCodeConfig(node.Config); ok && codeConfig.IsInIteration {
+			duplicate = node
+			duplicate.ID = node.ID + "1"
+		}
+	}
+	require.NotEmpty(t, duplicate.ID, "fixture should have a code node inside the iteration")
+	unifiedDSL.Workflow.Nodes = append(unifiedDSL.Workflow.Nodes, duplicate)
+
+	_, mapping, err := iflytekGenerator.NewIFlytekGenerator().GenerateWithMapping(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+
+	originalID := strings.TrimSuffix(duplicate.ID, "1")
+	require.Contains(t, mapping.Nodes, originalID)
+	require.Contains(t, mapping.Nodes, duplicate.ID)
+	require.NotEqual(t, mapping.Nodes[originalID], mapping.Nodes[duplicate.ID], "sub-nodes with the same title should map to their own nodes")
+}
+
+// TestIFlytekGenerator_ManyBranches tests that condition and classifier nodes with more than four
+// branches keep one edge handle per branch.
+func TestIFlytekGenerator_ManyBranches(t *testing.T) {
+	const branchCount = 12
+
+	testCases := []struct {
+		fixture  string
+		nodeType models.NodeType
+		addCases func(node *models.Node) []string // Adds branches and returns their source handles
+	}{
+		{
+			fixture:  "dify_start_condition_end.yml",
+			nodeType: models.NodeTypeCondition,
+			addCases: func(node *models.Node) []string {
+				config, ok := common.AsConditionConfig(node.Config)
+				require.True(t, ok)
+				var handles []string
+				for i := len(config.Cases) + 1; i <= branchCount; i++ {
+					branch := config.Cases[0]
+					branch.CaseID = fmt.Sprintf("case-%d", i)
+					config.Cases = append(config.Cases, branch)
+					handles = append(handles, branch.CaseID)
+				}
+				node.Config = config
+				return handles
+			},
+		},
+		{
+			fixture:  "dify_start_classifier_end.yml",
+			nodeType: models.NodeTypeClassifier,
+			addCases: func(node *models.Node) []string {
+				config, ok := common.AsClassifierConfig(node.Config)
+				require.True(t, ok)
+				var handles []string
+				for i := len(config.Classes) + 1; i <= branchCount; i++ {
+					class := models.ClassifierClass{ID: strconv.Itoa(i), Name: fmt.Sprintf("class %d", i)}
+					config.Classes = append(config.Classes, class)
+					handles = append(handles, class.ID)
+				}
+				node.Config = config
+				return handles
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.nodeType), func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", tc.fixture))
+			require.NoError(t, err, "failed to read fixture")
+			unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+			require.NoError(t, err, "Dify parsing failed")
+
+			var branchNode *models.Node
+			for i := range unifiedDSL.Workflow.Nodes {
+				if unifiedDSL.Workflow.Nodes[i].Type == tc.nodeType {
+					branchNode = &unifiedDSL.Workflow.Nodes[i]
+				}
+			}
+			require.NotNil(t, branchNode, "fixture should have a %s node", tc.nodeType)
+
+			var target string
+			for _, edge := range unifiedDSL.Workflow.Edges {
+				if edge.Source == branchNode.ID {
+					target = edge.Target
+				}
+			}
+			for _, handle := range tc.addCases(branchNode) {
+				unifiedDSL.Workflow.Edges = append(unifiedDSL.Workflow.Edges, models.Edge{
+					ID:           branchNode.ID + "-" + handle,
+					Source:       branchNode.ID,
+					Target:       target,
+					SourceHandle: handle,
+					TargetHandle: "target",
+					Type:         models.EdgeTypeDefault,
+				})
+			}
+			var sourceEdges int
+			for _, edge := range unifiedDSL.Workflow.Edges {
+				if edge.Source == branchNode.ID {
+					sourceEdges++
+				}
+			}
+
+			output, mapping, err := iflytekGenerator.NewIFlytekGenerator().GenerateWithMapping(unifiedDSL)
+			require.NoError(t, err, "iFlytek DSL generation failed")
+
+			var dsl iflytekGenerator.IFlytekDSL
+			require.NoError(t, yaml.Unmarshal(output, &dsl), "generated DSL should be valid YAML")
+
+			handles := make(map[string]bool)
+			for _, edge := range dsl.FlowData.Edges {
+				if edge.Source == mapping.Nodes[branchNode.ID] {
+					require.NotEmpty(t, edge.SourceHandle)
+					require.False(t, handles[edge.SourceHandle], "branch handle %s used twice", edge.SourceHandle)
+					handles[edge.SourceHandle] = true
+				}
+			}
+			// iFlytek classifiers add an edge for their default intent
+			require.GreaterOrEqual(t, len(handles), sourceEdges, "every branch should keep its own edge")
+		})
+	}
+}
+
+// TestIFlytekGenerator_PromptReferences tests that every node reference of a Dify prompt becomes a
+// named placeholder bound to an LLM node input.
+func TestIFlytekGenerator_PromptReferences(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+
+	var dsl iflytekGenerator.IFlytekDSL
+	require.NoError(t, yaml.Unmarshal(output, &dsl), "generated DSL should be valid YAML")
+
+	var llmNodes int
+	for _, node := range dsl.FlowData.Nodes {
+		if node.Kind() != iflytekGenerator.NodeKindLLM {
+			continue
+		}
+		llmNodes++
+
+		inputs := make(map[string]bool)
+		for _, input := range node.Data.Inputs {
+			inputs[input.Name] = true
+		}
+		userTemplate, _ := node.Data.NodeParam["template"].(string)
+		systemTemplate, _ := node.Data.NodeParam["systemTemplate"].(string)
+		template := userTemplate + systemTemplate
+		require.NotContains(t, template, "{{#", "Dify references should be rewritten")
+
+		placeholders := common.ParsePromptPlaceholders(template)
+		require.Len(t, placeholders, 2, "prompts %q should keep both references", template)
+		for _, placeholder := range placeholders {
+			require.True(t, inputs[placeholder.Name], "placeholder %s should be bound to an input", placeholder.Text)
+		}
+	}
+	require.Positive(t, llmNodes, "fixture should have an LLM node")
+}
+
+// TestIFlytekGenerator_VisionRoundTrip tests that Dify vision settings become an image input of
+// an iFlytek multimodal LLM node and come back.
+func TestIFlytekGenerator_VisionRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	data = []byte(strings.Replace(string(data), "        vision:\n          enabled: false\n",
+		"        vision:\n          enabled: true\n          configs:\n            detail: low\n            variable_selector:\n            - '1754269219469'\n            - input_01\n", 1))
+	unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+
+	var vision *models.VisionConfig
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if llmConfig, ok := common.AsLLMConfig(node.Config); ok && llmConfig != nil {
+			vision = llmConfig.Vision
+		}
+	}
+	require.NotNil(t, vision)
+	require.Equal(t, models.VisionDetailLow, vision.Detail)
+	require.Equal(t, []string{"1754269219469", "input_01"}, vision.VariableSelector)
+	require.NotEmpty(t, common.CheckVision(unifiedDSL, models.PlatformIFlytek), "text models should be warned about")
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	require.Contains(t, string(output), "multiMode: true")
+	require.Contains(t, string(output), "fileType: image")
+
+	parsed, err := iflytekParser.NewIFlytekParser().Parse(output)
+	require.NoError(t, err, "iFlytek parsing failed")
+	vision = nil
+	for _, node := range parsed.Workflow.Nodes {
+		if llmConfig, ok := common.AsLLMConfig(node.Config); ok && llmConfig != nil {
+			vision = llmConfig.Vision
+		}
+	}
+	require.NotNil(t, vision)
+	require.True(t, vision.Enabled)
+	require.Len(t, vision.VariableSelector, 2, "the image input should be found again")
+}
+
+// TestIFlytekGenerator_HookScript tests that hook script rules edit the matching nodes before generation.
+func TestIFlytekGenerator_HookScript(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+
+	script, err := common.ParseHookScript([]byte(`
+before:
+  - match: {type: llm}
+    set: {system_prompt_prefix: "[trace] "}
+after:
+  - match: {type: llm, target: dify}
+    set: {model: never-applied}
+  - match: {model: "xdeepseek*"}
+    set: {model: xdeepseekv3}
+`))
+	require.NoError(t, err, "hook script should parse")
+	_, err = common.ParseHookScript([]byte("before: [{match: {kind: llm}}]"))
+	require.Error(t, err, "unknown keys should be rejected")
+
+	hooks := []models.NodeHook{script}
+	ctx := models.NodeHookContext{SourcePlatform: models.PlatformDify, TargetPlatform: models.PlatformIFlytek}
+	require.NoError(t, common.ApplyNodeHooks(unifiedDSL, hooks, common.HookPhaseBefore, ctx))
+	require.NoError(t, common.ApplyNodeHooks(unifiedDSL, hooks, common.HookPhaseAfter, ctx))
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+
+	var dsl iflytekGenerator.IFlytekDSL
+	require.NoError(t, yaml.Unmarshal(output, &dsl), "generated DSL should be valid YAML")
+	var llmNodes int
+	for _, node := range dsl.FlowData.Nodes {
+		if node.Kind() != iflytekGenerator.NodeKindLLM {
+			continue
+		}
+		llmNodes++
+		systemTemplate, _ := node.Data.NodeParam["systemTemplate"].(string)
+		require.True(t, strings.HasPrefix(systemTemplate, "[trace] "), "the system prompt should get the prefix")
+		require.Equal(t, "xdeepseekv3", node.Data.NodeParam["domain"], "the model should be renamed")
+	}
+	require.Equal(t, 1, llmNodes)
+}
+
+// TestIFlytekGenerator_Policy tests that policy rules rewrite, warn about and block nodes.
+func TestIFlytekGenerator_Policy(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+
+	policy, err := models.ParsePolicy([]byte(`
+rules:
+  - name: temperature-cap
+    max_temperature: 0.5
+    action: rewrite
+  - name: approved-providers
+    allowed_providers: [iflytek]
+  - name: no-network
+    forbid_imports: [os, requests]
+    action: block
+`))
+	require.NoError(t, err, "policy should parse")
+	_, err = models.ParsePolicy([]byte("rules: [{name: r, forbid_imports: [os], action: rewrite}]"))
+	require.Error(t, err, "forbidden imports cannot be rewritten")
+
+	warnings, err := common.EnforcePolicy(unifiedDSL, policy)
+	require.NoError(t, err, "the LLM workflow breaks no block rule")
+	require.Len(t, warnings, 2)
+	require.Contains(t, warnings[0], "(rewritten)")
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if config, ok := common.AsLLMConfig(node.Config); ok && config != nil {
+			require.Equal(t, 0.5, config.Parameters.Temperature, "the temperature should be capped")
+		}
+	}
+
+	unifiedDSL.Workflow.Nodes = append(unifiedDSL.Workflow.Nodes, models.Node{
+		ID:     "network",
+		Type:   models.NodeTypeCode,
+		Title:  "fetch",
+		Config: models.CodeConfig{Language: "python3", Code: "import json, requests.adapters as a\n\ndef main():\n    return {}"},
+	})
+	_, err = common.EnforcePolicy(unifiedDSL, policy)
+	require.ErrorContains(t, err, "code imports requests.adapters")
+}
+
+// TestIFlytekGenerator_ValidationRules tests that rules files and Go validators run in their
+// stages, with warnings reported and errors naming the offending nodes.
+func TestIFlytekGenerator_ValidationRules(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+
+	rules, err := models.ParseValidationRules([]byte(`
+rules:
+  - name: system-prompt
+    require_system_prompt: true
+  - name: answer-output
+    require_end_output: answer
+    severity: warning
+  - name: llm-titles
+    stage: platform
+    node_type: llm
+    title_pattern: "^LLM "
+`))
+	require.NoError(t, err, "rules should parse")
+	_, err = models.ParseValidationRules([]byte("rules: [{name: r, require_description: true, max_nodes: 3}]"))
+	require.Error(t, err, "a rule sets one constraint")
+
+	var stages []models.ValidationStage
+	validators := append(common.RuleValidators(rules), models.ValidatorFunc{
+		RuleName:  "stage-recorder",
+		RuleStage: models.ValidationStageStructure,
+		Check: func(ctx models.ValidationContext, unifiedDSL *models.UnifiedDSL) []models.ValidationIssue {
+			stages = append(stages, ctx.Stage)
+			return nil
+		},
+	})
+
+	semantic := models.ValidationContext{Stage: models.ValidationStageSemantic, SourcePlatform: models.PlatformDify, TargetPlatform: models.PlatformIFlytek}
+	warnings, err := common.RunValidators(unifiedDSL, validators, semantic)
+	require.NoError(t, err, "every LLM node has a system prompt")
+	require.Equal(t, []string{`rule "answer-output": no end node returns "answer"`}, warnings)
+	require.Empty(t, stages, "structure validators do not run in the semantic stage")
+
+	platform := semantic
+	platform.Stage = models.ValidationStagePlatform
+	_, err = common.RunValidators(unifiedDSL, validators, platform)
+	require.ErrorContains(t, err, `rule "llm-titles": node 通用学习建议`)
+}
+
+// TestIFlytekGenerator_Anonymize tests that anonymized workflows keep their structure and
+// placeholders but lose titles and prompt text, the same way on every run.
+func TestIFlytekGenerator_Anonymize(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+
+	generate := func() (*models.UnifiedDSL, string) {
+		unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+		require.NoError(t, err, "Dify parsing failed")
+		common.AnonymizeDSL(unifiedDSL)
+		output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+		require.NoError(t, err, "iFlytek DSL generation failed")
+		return unifiedDSL, string(output)
+	}
+
+	unifiedDSL, output := generate()
+	require.NotContains(t, output, "通用学习建议", "titles should be replaced")
+	require.NotContains(t, output, "学习内容", "prompt text should be replaced")
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		require.True(t, strings.HasPrefix(node.Title, string(node.Type)+"_"), "title %q should be a pseudonym", node.Title)
+		if config, ok := common.AsLLMConfig(node.Config); ok && config != nil {
+			require.Len(t, common.ParsePromptPlaceholders(config.Prompt.SystemTemplate+config.Prompt.UserTemplate), 2,
+				"placeholders should survive anonymization")
+		}
+	}
+
+	again, _ := generate()
+	for i, node := range again.Workflow.Nodes {
+		require.Equal(t, unifiedDSL.Workflow.Nodes[i].Title, node.Title, "pseudonyms should be deterministic")
+	}
+}
+
+// TestIFlytekGenerator_Minify tests that minified output drops editor defaults, interns repeated
+// strings and still parses to the same workflow.
+func TestIFlytekGenerator_Minify(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_condition_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+
+	minified, report, err := common.MinifyOutput(output, models.PlatformIFlytek, models.OutputFormatYAML)
+	require.NoError(t, err, "minify failed")
+	require.Equal(t, len(output), report.OriginalBytes)
+	require.Equal(t, len(minified), report.MinifiedBytes)
+	require.Less(t, report.MinifiedBytes, report.OriginalBytes)
+	require.Positive(t, report.InternedStrings)
+	require.NotContains(t, string(minified), "dragging: false")
+	require.NotContains(t, string(minified), "nameErrMsg")
+
+	original, err := iflytekParser.NewIFlytekParser().Parse(output)
+	require.NoError(t, err)
+	reparsed, err := iflytekParser.NewIFlytekParser().Parse(minified)
+	require.NoError(t, err, "minified output should parse")
+	require.Len(t, reparsed.Workflow.Nodes, len(original.Workflow.Nodes))
+	require.Len(t, reparsed.Workflow.Edges, len(original.Workflow.Edges))
+
+	_, _, err = common.MinifyOutput(output, models.PlatformType("n8n"), models.OutputFormatYAML)
+	require.Error(t, err, "platforms without a minify profile should be rejected")
+}
+
+// TestIFlytekGenerator_UnknownFieldsRoundTrip tests that node keys the iFlytek parser does not model survive an iFlytek round trip.
+func TestIFlytekGenerator_UnknownFieldsRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_start_llm_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+
+	// A data key and a node key newer iFlytek releases might add, on the last (LLM) node
+	input := strings.Replace(string(data), "        updatable: false\n  edges:",
+		"        updatable: false\n        retryConfig:\n          maxRetries: 2\n      reviewState: approved\n  edges:", 1)
+	require.NotEqual(t, string(data), input, "fixture should end with the LLM node")
+
+	strategy := strategies.NewIFlytekStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "iFlytek parsing failed")
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+
+	var dsl struct {
+		FlowData struct {
+			Nodes []map[string]interface{} `yaml:"nodes"`
+		} `yaml:"flowData"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &dsl))
+
+	llmNodes := 0
+	for _, node := range dsl.FlowData.Nodes {
+		nodeData := node["data"].(map[string]interface{})
+		if iflytekGenerator.NodeKindOf(node["id"].(string)) != iflytekGenerator.NodeKindLLM {
+			require.NotContains(t, nodeData, "retryConfig", "unknown keys belong to their source node only")
+			continue
+		}
+		llmNodes++
+		require.Equal(t, map[string]interface{}{"maxRetries": 2}, nodeData["retryConfig"])
+		require.Equal(t, "approved", node["reviewState"])
+	}
+	require.Equal(t, 1, llmNodes)
+}
+
+// TestIFlytekGenerator_NodeParamRoundTrip tests that nodeParam keys the generator does not set survive an
+// iFlytek round trip, while the keys it generates follow the unified DSL.
+func TestIFlytekGenerator_NodeParamRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_basic_start_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+
+	strategy := strategies.NewIFlytekStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse(data)
+	require.NoError(t, err, "iFlytek parsing failed")
+
+	for i := range unifiedDSL.Workflow.Nodes {
+		if endConfig, ok := common.AsEndConfig(unifiedDSL.Workflow.Nodes[i].Config); ok && endConfig != nil {
+			endConfig.Template = "edited {{output}}"
+			unifiedDSL.Workflow.Nodes[i].Config = *endConfig
+		}
+	}
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+
+	var dsl struct {
+		FlowData struct {
+			Nodes []struct {
+				ID   string `yaml:"id"`
+				Data struct {
+					NodeParam map[string]interface{} `yaml:"nodeParam"`
+				} `yaml:"data"`
+			} `yaml:"nodes"`
+		} `yaml:"flowData"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &dsl))
+
+	params := make(map[iflytekGenerator.NodeKind]map[string]interface{})
+	for _, node := range dsl.FlowData.Nodes {
+		params[iflytekGenerator.NodeKindOf(node.ID)] = node.Data.NodeParam
+	}
+	require.Equal(t, "输出中变量名校验不通过,自动生成JSON失败", params[iflytekGenerator.NodeKindStart]["setAnswerContentErrMsg"])
+	require.Equal(t, "无", params[iflytekGenerator.NodeKindEnd]["reasoningTemplate"])
+	require.Equal(t, "edited {{output}}", params[iflytekGenerator.NodeKindEnd]["template"], "generated keys take precedence")
+	require.NotContains(t, params[iflytekGenerator.NodeKindStart], "reasoningTemplate", "source keys belong to their node only")
+}
+
+// TestIFlytekGenerator_StartInputRules verifies select options and text lengths of Dify start inputs
+// survive an iFlytek round trip, and number inputs are not turned into uploads.
+func TestIFlytekGenerator_StartInputRules(t *testing.T) {
+	unifiedDSL := golden.GetDifyToUnified_Basic_start_end()
+	for i, node := range unifiedDSL.Workflow.Nodes {
+		startConfig, ok := node.Config.(models.StartConfig)
+		if !ok {
+			continue
+		}
+		startConfig.Variables = append(startConfig.Variables,
+			models.Variable{Name: "level", Label: "Level", Type: "string", Control: models.VariableControlSelect,
+				Constraints: &models.Constraints{Options: []interface{}{"basic", "advanced"}}},
+			models.Variable{Name: "detail", Label: "Detail", Type: "string", Control: models.VariableControlParagraph,
+				Constraints: &models.Constraints{MaxLength: 2000}},
+			models.Variable{Name: "count", Label: "Count", Type: "number", Control: models.VariableControlNumber})
+		unifiedDSL.Workflow.Nodes[i].Config = startConfig
+	}
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	parsed, err := iflytekParser.NewIFlytekParser().Parse(output)
+	require.NoError(t, err, "iFlytek parsing failed")
+
+	variables := make(map[string]models.Variable)
+	for _, node := range parsed.Workflow.Nodes {
+		if startConfig, ok := node.Config.(models.StartConfig); ok {
+			for _, variable := range startConfig.Variables {
+				variables[variable.Name] = variable
+			}
+		}
+	}
+
+	require.Equal(t, models.VariableControlSelect, variables["level"].Control)
+	require.Equal(t, []interface{}{"basic", "advanced"}, variables["level"].Constraints.Options)
+	require.Equal(t, 2000, variables["detail"].Constraints.MaxLength)
+	require.Equal(t, "Detail", variables["detail"].Label, "the label is the input hint")
+	require.Nil(t, variables["detail"].Default, "the hint is no default value")
+	require.Empty(t, variables["count"].CustomParameterType, "number inputs must not become uploads")
+}
+
+func TestIconTranslator(t *testing.T) {
+	translator := common.NewIconTranslator(&models.IconSet{
+		Emoji: map[string]string{"📚": "https://example.com/book.png"},
+	})
+
+	cases := []struct {
+		icon, background string
+		target           models.PlatformType
+		want             common.TranslatedIcon
+	}{
+		{"📚", "#E8F5E8", models.PlatformIFlytek, common.TranslatedIcon{Icon: "https://example.com/book.png", Background: "#E8F5E8"}},
+		{"https://example.com/book.png", "#FFEAD5", models.PlatformDify, common.TranslatedIcon{Icon: "📚", Background: "#FFEAD5"}},
+		{"🎯", "", models.PlatformIFlytek, common.TranslatedIcon{Icon: common.DefaultIFlytekIcon, Background: common.DefaultIFlytekColor, Fallback: true}},
+		{"data:image/png;base64,iVBORw0KGgo=", "red", models.PlatformDify, common.TranslatedIcon{Icon: common.DefaultDifyIcon, Background: common.DefaultDifyBackground, Fallback: true}},
+		{common.DefaultIFlytekIcon, "", models.PlatformDify, common.TranslatedIcon{Icon: common.DefaultDifyIcon, Background: common.DefaultDifyBackground}},
+		{"📚", "", models.PlatformCoze, common.TranslatedIcon{}},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.want, translator.Translate(tc.icon, tc.background, tc.target), "%s → %s", tc.icon, tc.target)
+	}
+}
+
+func TestIFlytekGenerator_EdgeStyleRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_basic_start_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	input := strings.NewReplacer("color: '#275EFF'", "color: '#FF5500'", "edgeType: curve", "edgeType: polyline").Replace(string(data))
+
+	parsed, err := iflytekParser.NewIFlytekParser().Parse([]byte(input))
+	require.NoError(t, err, "iFlytek parsing failed")
+	require.Len(t, parsed.Workflow.Edges, 1)
+	want := &models.EdgeStyle{Path: models.EdgePathPolyline, Color: "#FF5500"}
+	require.Equal(t, want, parsed.Workflow.Edges[0].Style)
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(parsed)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	regenerated, err := iflytekParser.NewIFlytekParser().Parse(output)
+	require.NoError(t, err, "iFlytek parsing failed")
+	require.Equal(t, want, regenerated.Workflow.Edges[0].Style)
+
+	// Default edges carry no style
+	parsed, err = iflytekParser.NewIFlytekParser().Parse(data)
+	require.NoError(t, err, "iFlytek parsing failed")
+	require.Nil(t, parsed.Workflow.Edges[0].Style)
+}
+
+// TestIFlytekGenerator_SamplingLimits tests that sampling parameters are clamped to Spark ranges and
+// that the ones Spark lacks are reported.
+func TestIFlytekGenerator_SamplingLimits(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	input := strings.Replace(string(data), "          completion_params:\n            temperature: 0.7\n",
+		"          completion_params:\n            temperature: 1.5\n            top_k: 10\n            presence_penalty: 0.5\n            stop:\n            - END\n            seed: 42\n", 1)
+	require.NotEqual(t, string(data), input, "fixture should contain the LLM model parameters")
+
+	unifiedDSL, err := difyParser.NewDifyParser().Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+	require.Empty(t, common.NormalizeSampling(unifiedDSL, models.PlatformDify), "Dify accepts every parameter")
+
+	warnings := common.NormalizeSampling(unifiedDSL, models.PlatformIFlytek)
+	require.Len(t, warnings, 1)
+	for _, change := range []string{"temperature 1.5 lowered to 1", "top_k 10 lowered to 6", "penalties dropped", `stop sequences ["END"] dropped`, "seed 42 dropped"} {
+		require.Contains(t, warnings[0], change)
+	}
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	parsed, err := iflytekParser.NewIFlytekParser().Parse(output)
+	require.NoError(t, err, "iFlytek parsing failed")
+	for _, node := range parsed.Workflow.Nodes {
+		if llmConfig, ok := common.AsLLMConfig(node.Config); ok && llmConfig != nil {
+			require.Equal(t, 1.0, llmConfig.Parameters.Temperature)
+			require.Equal(t, 6, llmConfig.Parameters.TopK)
+		}
+	}
+}
+
+func TestIFlytekGenerator_DataTypes(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_code_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	input := strings.Replace(string(data), "            type: array[string]\n",
+		"            type: array[string]\n"+
+			"          total:\n            children: null\n            type: number\n"+
+			"          scores:\n            children: null\n            type: array[number]\n"+
+			"          flags:\n            children: null\n            type: array[boolean]\n"+
+			"          attachments:\n            children: null\n            type: array[file]\n", 1)
+	require.NotEqual(t, string(data), input, "fixture should contain the code outputs")
+
+	unifiedDSL, err := difyParser.NewDifyParser().Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+	require.Empty(t, common.CheckDataTypes(unifiedDSL, models.PlatformDify), "Dify has every type")
+	warnings := common.CheckDataTypes(unifiedDSL, models.PlatformIFlytek)
+	require.Len(t, warnings, 1, "only the file list changes its values")
+	require.Contains(t, warnings[0], `"attachments"`)
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	parsed, err := iflytekParser.NewIFlytekParser().Parse(output)
+	require.NoError(t, err, "iFlytek parsing failed")
+
+	expected := map[string]models.UnifiedDataType{
+		"total":       models.DataTypeFloat,
+		"scores":      models.DataTypeArrayFloat,
+		"flags":       models.DataTypeArrayBoolean,
+		"attachments": models.DataTypeArrayString,
+	}
+	found := 0
+	for _, node := range parsed.Workflow.Nodes {
+		for _, output := range node.Outputs {
+			if expectedType, ok := expected[output.Name]; ok {
+				require.Equal(t, expectedType, output.Type, "output %s", output.Name)
+				found++
+			}
+		}
+	}
+	require.Equal(t, len(expected), found, "every code output should be generated")
+}
+
+// TestIFlytekGenerator_Subgraph tests that a subgraph gets start and end nodes, with references
+// to removed nodes read from the start node.
+func TestIFlytekGenerator_Subgraph(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_condition_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+
+	_, err = common.ExtractSubgraph(unifiedDSL, []string{"missing"}, "")
+	require.ErrorContains(t, err, "unknown nodes missing")
+
+	warnings, err := common.ExtractSubgraph(unifiedDSL, []string{"1758004955189", "1758004961777"}, "")
+	require.NoError(t, err, "subgraph extraction failed")
+	require.NotEmpty(t, warnings, "the LLM nodes read the removed start node")
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	parsed, err := iflytekParser.NewIFlytekParser().Parse(output)
+	require.NoError(t, err, "iFlytek parsing failed")
+
+	types := make(map[models.NodeType]int)
+	var startID string
+	for _, node := range parsed.Workflow.Nodes {
+		types[node.Type]++
+		if node.Type == models.NodeTypeStart {
+			startID = node.ID
+		}
+	}
+	require.Equal(t, map[models.NodeType]int{models.NodeTypeStart: 1, models.NodeTypeLLM: 2, models.NodeTypeEnd: 1}, types)
+	require.Len(t, parsed.Workflow.Edges, 4, "both LLM nodes should run between start and end")
+	for _, node := range parsed.Workflow.Nodes {
+		for _, input := range node.Inputs {
+			if node.Type == models.NodeTypeLLM && input.Reference != nil && input.Reference.NodeID != "" {
+				require.Equal(t, startID, input.Reference.NodeID, "input %s of %s", input.Name, node.Title)
+			}
+		}
+	}
+}
+
+// TestIFlytekGenerator_AppendWorkflow tests that a workflow appended to itself gets new IDs and
+// reads its start variable from the end output of the first copy.
+func TestIFlytekGenerator_AppendWorkflow(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	first, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+	second, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+
+	_, err = common.AppendWorkflow(first, second, map[string]string{"input_01": "missing"})
+	require.ErrorContains(t, err, `no end output "missing"`)
+
+	second, err = difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+	warnings, err := common.AppendWorkflow(first, second, map[string]string{"input_01": "result1"})
+	require.NoError(t, err, "append failed")
+	require.Empty(t, warnings, "the other start variables are shared")
+
+	ids := make(map[string]bool)
+	types := make(map[models.NodeType]int)
+	var appendedLLM *models.Node
+	for i, node := range first.Workflow.Nodes {
+		require.False(t, ids[node.ID], "node ID %s is used twice", node.ID)
+		ids[node.ID] = true
+		types[node.Type]++
+		if node.Type == models.NodeTypeLLM && node.ID != "1754290000001" {
+			appendedLLM = &first.Workflow.Nodes[i]
+		}
+	}
+	require.Equal(t, map[models.NodeType]int{models.NodeTypeStart: 1, models.NodeTypeLLM: 2, models.NodeTypeEnd: 1}, types)
+	require.Len(t, first.Workflow.Edges, 3, "start, both LLM nodes and end form a chain")
+	require.NotNil(t, appendedLLM)
+
+	config, ok := common.AsLLMConfig(appendedLLM.Config)
+	require.True(t, ok)
+	prompts := fmt.Sprint(config.Prompt)
+	require.Contains(t, prompts, "1754290000001", "the appended LLM should read the first LLM")
+	require.NotContains(t, prompts, "1754269219469.input_01", "the mapped start variable should not be read")
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(first)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	_, err = iflytekParser.NewIFlytekParser().Parse(output)
+	require.NoError(t, err, "iFlytek parsing failed")
+}
+
+// TestIFlytekGenerator_Simulate checks that a converted workflow takes the same branch and produces the same outputs.
+func TestIFlytekGenerator_Simulate(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_condition_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	source, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(source)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+	converted, err := iflytekParser.NewIFlytekParser().Parse(output)
+	require.NoError(t, err, "iFlytek parsing failed")
+
+	options := simulator.Options{Inputs: map[string]interface{}{"gender": "woman", "birth_month": 5}}
+	run := func(unifiedDSL *models.UnifiedDSL) ([]string, *simulator.Trace) {
+		trace, err := simulator.Run(context.Background(), unifiedDSL, options)
+		require.NoError(t, err, "simulation failed")
+		var path []string
+		for _, step := range trace.Steps {
+			if step.Branch != "" || step.Mode == simulator.ModeEcho {
+				path = append(path, step.NodeTitle)
+			}
+		}
+		return path, trace
+	}
+	sourcePath, sourceTrace := run(source)
+	convertedPath, convertedTrace := run(converted)
+
+	require.NotEmpty(t, sourcePath)
+	require.Equal(t, sourcePath, convertedPath, "the converted workflow should run the same branch and LLM nodes")
+	require.Equal(t, sourceTrace.Outputs, convertedTrace.Outputs)
+	require.Len(t, convertedTrace.Skipped, len(sourceTrace.Skipped))
+}
+
+// TestIFlytekGenerator_DefaultIntent tests that the default intent added to Dify classifiers goes
+// where the default intent strategy says, and that the report names the target.
+func TestIFlytekGenerator_DefaultIntent(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_classifier_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err, "Dify parsing failed")
+
+	var classifier, end *models.Node
+	for i := range unifiedDSL.Workflow.Nodes {
+		switch node := &unifiedDSL.Workflow.Nodes[i]; node.Type {
+		case models.NodeTypeClassifier:
+			classifier = node
+		case models.NodeTypeEnd:
+			end = node
+		}
+	}
+	require.NotNil(t, classifier, "fixture should have a classifier")
+	require.NotNil(t, end, "fixture should have an end node")
+	var lastClassTarget string
+	for _, edge := range unifiedDSL.Workflow.Edges {
+		if edge.Source == classifier.ID {
+			lastClassTarget = edge.Target
+		}
+	}
+
+	testCases := []struct {
+		strategy string
+		target   string // Source ID of the node the default intent connects to, empty for none
+	}{
+		{"", lastClassTarget},
+		{models.DefaultIntentEnd, end.ID},
+		{models.DefaultIntentNone, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			generator := iflytekGenerator.NewIFlytekGenerator()
+			require.NoError(t, generator.Configure(&models.ConversionOptions{DefaultIntent: tc.strategy}))
+			output, mapping, err := generator.GenerateWithMapping(unifiedDSL)
+			require.NoError(t, err, "iFlytek DSL generation failed")
+
+			var dsl iflytekGenerator.IFlytekDSL
+			require.NoError(t, yaml.Unmarshal(output, &dsl), "generated DSL should be valid YAML")
+			defaultIntentID := mapping.Intents[classifier.ID]["__default__"]
+			require.NotEmpty(t, defaultIntentID, "the classifier should get a default intent")
+
+			var targets []string
+			for _, edge := range dsl.FlowData.Edges {
+				if edge.SourceHandle == defaultIntentID {
+					targets = append(targets, edge.Target)
+				}
+			}
+			warnings := common.CheckDefaultIntents(unifiedDSL, models.PlatformIFlytek, tc.strategy)
+			require.Len(t, warnings, 1, "the report should record the default intent")
+			if tc.target == "" {
+				require.Empty(t, targets, "the default intent should stay unconnected")
+				require.Contains(t, warnings[0], "unconnected")
+				return
+			}
+			require.Equal(t, []string{mapping.Nodes[tc.target]}, targets)
+			for _, node := range unifiedDSL.Workflow.Nodes {
+				if node.ID == tc.target {
+					require.Contains(t, warnings[0], strconv.Quote(node.Title))
+				}
+			}
+		})
+	}
+}
+
+// TestIFlytekGenerator_EdgeHandles tests that edges only leave branch nodes through branches they
+// have: fallback handles reach the default branch and unknown handles fail the generation.
+func TestIFlytekGenerator_EdgeHandles(t *testing.T) {
+	parse := func(t *testing.T) (*models.UnifiedDSL, *models.Node) {
+		data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_condition_end.yml"))
+		require.NoError(t, err, "failed to read fixture")
+		unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+		require.NoError(t, err, "Dify parsing failed")
+		for i := range unifiedDSL.Workflow.Nodes {
+			if unifiedDSL.Workflow.Nodes[i].Type == models.NodeTypeCondition {
+				return unifiedDSL, &unifiedDSL.Workflow.Nodes[i]
+			}
+		}
+		t.Fatal("fixture should have a condition node")
+		return nil, nil
+	}
+	addEdge := func(unifiedDSL *models.UnifiedDSL, condition *models.Node, handle string) {
+		for _, edge := range unifiedDSL.Workflow.Edges {
+			if edge.Source == condition.ID {
+				edge.ID += "-" + handle
+				edge.SourceHandle = handle
+				unifiedDSL.Workflow.Edges = append(unifiedDSL.Workflow.Edges, edge)
+				return
+			}
+		}
+	}
+
+	t.Run("fallback handle", func(t *testing.T) {
+		unifiedDSL, condition := parse(t)
+		addEdge(unifiedDSL, condition, "default")
+
+		output, mapping, err := iflytekGenerator.NewIFlytekGenerator().GenerateWithMapping(unifiedDSL)
+		require.NoError(t, err, "iFlytek DSL generation failed")
+		var dsl iflytekGenerator.IFlytekDSL
+		require.NoError(t, yaml.Unmarshal(output, &dsl), "generated DSL should be valid YAML")
+
+		branches := make(map[string]bool)
+		for _, node := range dsl.FlowData.Nodes {
+			if node.ID != mapping.Nodes[condition.ID] {
+				continue
+			}
+			cases, _ := node.Data.NodeParam["cases"].([]interface{})
+			for _, item := range cases {
+				branch, _ := item.(map[string]interface{})
+				branches[fmt.Sprint(branch["id"])] = true
+			}
+		}
+		require.NotEmpty(t, branches)
+		for _, edge := range dsl.FlowData.Edges {
+			if edge.Source == mapping.Nodes[condition.ID] {
+				require.True(t, branches[edge.SourceHandle], "edge handle %q should be a branch of the node", edge.SourceHandle)
+			}
+		}
+	})
+
+	t.Run("unknown handle", func(t *testing.T) {
+		unifiedDSL, condition := parse(t)
+		addEdge(unifiedDSL, condition, "no-such-case")
+
+		_, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `no branch "no-such-case"`)
+	})
+}
+
+// TestIFlytekGenerator_CrossIterationReferences tests that a node reading into an iteration body
+// reads the iteration output instead, and fails when the iteration output carries another value.
+func TestIFlytekGenerator_CrossIterationReferences(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_iteration_end.yml"))
+	require.NoError(t, err, "failed to read fixture")
+	// The end node reads the code node of the iteration body instead of the iteration
+	input := strings.Replace(string(data), "          - '5134172206038022'\n          - output\n",
+		"          - '5134172206022235'\n          - result\n", 1)
+	require.NotEqual(t, string(data), input, "fixture should contain the end reference")
+
+	endReference := func(unifiedDSL *models.UnifiedDSL) *models.VariableReference {
+		for _, node := range unifiedDSL.Workflow.Nodes {
+			if node.Type == models.NodeTypeEnd && len(node.Inputs) > 0 {
+				return node.Inputs[0].Reference
+			}
+		}
+		return nil
+	}
+
+	unifiedDSL, err := difyParser.NewDifyParser().Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+	warnings, err := common.RewriteCrossIterationReferences(unifiedDSL, models.PlatformIFlytek)
+	require.NoError(t, err, "the iteration output carries the value read")
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "5134172206022235.result")
+	reference := endReference(unifiedDSL)
+	require.NotNil(t, reference, "end node should read a node")
+	require.Equal(t, "5134172206038022", reference.NodeID)
+	require.Equal(t, "output", reference.OutputName)
+	_, err = iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err, "iFlytek DSL generation failed")
+
+	unifiedDSL, err = difyParser.NewDifyParser().Parse([]byte(input))
+	require.NoError(t, err, "Dify parsing failed")
+	for i := range unifiedDSL.Workflow.Nodes {
+		if iterConfig, ok := common.AsIterationConfig(unifiedDSL.Workflow.Nodes[i].Config); ok && iterConfig != nil {
+			iterConfig.OutputSelector = models.OutputSelectorConfig{}
+			unifiedDSL.Workflow.Nodes[i].Config = iterConfig
+		}
+	}
+	_, err = common.RewriteCrossIterationReferences(unifiedDSL, models.PlatformIFlytek)
+	require.ErrorIs(t, err, common.ErrCrossIterationReference)
+	require.ErrorContains(t, err, "5134172206022235.result only exists inside iteration")
+	require.Equal(t, "5134172206022235", endReference(unifiedDSL).NodeID, "failed references are left as they are")
+
+	warnings, err = common.RewriteCrossIterationReferences(unifiedDSL, models.PlatformUnified)
+	require.NoError(t, err, "unified exports keep the workflow as parsed")
+	require.Empty(t, warnings)
+}
+
+// TestResolveSparkCredentials tests the explicit value > environment > default precedence.
+func TestResolveSparkCredentials(t *testing.T) {
+	cases := []struct {
+		name     string
+		appID    string
+		uid      string
+		envAppID string
+		envUID   string
+		expected iflytekGenerator.SparkCredentials
+	}{
+		{"default", "", "", "", "", iflytekGenerator.SparkCredentials{AppID: iflytekGenerator.DefaultSparkAppID, UID: iflytekGenerator.DefaultSparkUID}},
+		{"environment", "", "", "env-app", "env-uid", iflytekGenerator.SparkCredentials{AppID: "env-app", UID: "env-uid"}},
+		{"flag over environment", "flag-app", "flag-uid", "env-app", "env-uid", iflytekGenerator.SparkCredentials{AppID: "flag-app", UID: "flag-uid"}},
+		{"mixed", "flag-app", "", "", "env-uid", iflytekGenerator.SparkCredentials{AppID: "flag-app", UID: "env-uid"}},
+		{"blank values", "  ", "", " ", "", iflytekGenerator.SparkCredentials{AppID: iflytekGenerator.DefaultSparkAppID, UID: iflytekGenerator.DefaultSparkUID}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(iflytekGenerator.EnvSparkAppID, tc.envAppID)
+			t.Setenv(iflytekGenerator.EnvSparkUID, tc.envUID)
+			require.Equal(t, tc.expected, iflytekGenerator.ResolveSparkCredentials(tc.appID, tc.uid))
+		})
+	}
+}
+
+// TestIFlytekGenerator_Credentials tests that every generated node, iteration sub-nodes included,
+// carries the configured Spark identity.
+func TestIFlytekGenerator_Credentials(t *testing.T) {
+	t.Setenv(iflytekGenerator.EnvSparkAppID, "env-app")
+	t.Setenv(iflytekGenerator.EnvSparkUID, "env-uid")
+
+	for _, fixture := range []string{"dify_start_iteration_end.yml", "dify_start_condition_end.yml", "dify_start_classifier_end.yml", "dify_start_llm_end.yml"} {
+		data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", fixture))
+		require.NoError(t, err, "failed to read fixture")
+		unifiedDSL, err := difyParser.NewDifyParser().Parse(data)
+		require.NoError(t, err, "Dify parsing failed")
+
+		generator := iflytekGenerator.NewIFlytekGenerator()
+		options := models.NewConversionOptions()
+		options.IFlytekAppID = "flag-app"
+		require.NoError(t, generator.Configure(options))
+		output, err := generator.Generate(unifiedDSL)
+		require.NoError(t, err, "iFlytek DSL generation failed for %s", fixture)
+
+		var root struct {
+			FlowData struct {
+				Nodes []struct {
+					ID   string `yaml:"id"`
+					Data struct {
+						NodeParam map[string]interface{} `yaml:"nodeParam"`
+					} `yaml:"data"`
+				} `yaml:"nodes"`
+			} `yaml:"flowData"`
+		}
+		require.NoError(t, yaml.Unmarshal(output, &root))
+		checked := 0
+		for _, node := range root.FlowData.Nodes {
+			if appID, ok := node.Data.NodeParam["appId"]; ok {
+				require.Equal(t, "flag-app", appID, "%s: node %s", fixture, node.ID)
+				require.Equal(t, "env-uid", node.Data.NodeParam["uid"], "%s: node %s", fixture, node.ID)
+				checked++
+			}
+		}
+		require.NotZero(t, checked, "%s should generate nodes with a Spark identity", fixture)
+		require.NotContains(t, string(output), iflytekGenerator.DefaultSparkAppID, fixture)
+	}
+}
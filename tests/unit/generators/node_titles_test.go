@@ -0,0 +1,107 @@
+package generators
+
+import (
+	"testing"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	iflytekGenerator "github.com/iflytek/agentbridge/platforms/iflytek/generator"
+	golden "github.com/iflytek/agentbridge/tests/unit/golden/basic_start_end"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestRenderNodeTitle tests the node naming options on single nodes
+func TestRenderNodeTitle(t *testing.T) {
+	node := models.Node{ID: "llm_1", Type: models.NodeTypeLLM, Title: "摘要"}
+	placeholder := models.Node{ID: "db_1", Type: models.NodeTypeCode, Title: common.FormatUnsupportedNodeTitle("查询")}
+
+	tests := []struct {
+		name     string
+		node     models.Node
+		options  *models.ConversionOptions
+		expected string
+	}{
+		{"no options", node, nil, "摘要"},
+		{"prefix", node, &models.ConversionOptions{TitlePrefix: "[迁移] "}, "[迁移] 摘要"},
+		{"suffix", node, &models.ConversionOptions{TitleSuffix: " (m)"}, "摘要 (m)"},
+		{"prefix and suffix", node, &models.ConversionOptions{TitlePrefix: "A-", TitleSuffix: "-Z"}, "A-摘要-Z"},
+		{"template", node, &models.ConversionOptions{TitleTemplate: "{{type}}: {{title}} ({{id}})"}, "llm: 摘要 (llm_1)"},
+		{"template with prefix", node, &models.ConversionOptions{TitlePrefix: "> ", TitleTemplate: "{{title}}!"}, "> 摘要!"},
+		{"template without title", node, &models.ConversionOptions{TitleTemplate: "node {{id}}"}, "node llm_1"},
+		{"keep titles", placeholder, &models.ConversionOptions{KeepTitles: true}, "查询"},
+		{"keep titles of a regular node", node, &models.ConversionOptions{KeepTitles: true}, "摘要"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, common.RenderNodeTitle(tt.node, tt.options))
+		})
+	}
+}
+
+// TestApplyTitleOptions tests that naming reaches iteration bodies and names derived nodes alike
+func TestApplyTitleOptions(t *testing.T) {
+	body := models.Node{ID: "body_1", Type: models.NodeTypeCode, Title: "处理"}
+	end := models.Node{
+		ID:     "end_1",
+		Type:   models.NodeTypeEnd,
+		Title:  "结束",
+		Inputs: []models.Input{{Name: "a", Reference: &models.VariableReference{Type: models.ReferenceTypeNodeOutput, NodeID: "llm_1", OutputName: "output"}}},
+		Config: models.EndConfig{OutputMode: models.EndOutputModeTemplate, Template: "答案：{{a}}"},
+	}
+	unifiedDSL := &models.UnifiedDSL{Workflow: models.Workflow{Nodes: []models.Node{
+		{ID: "iter_1", Type: models.NodeTypeIteration, Title: "循环", Config: &models.IterationConfig{
+			SubWorkflow: models.SubWorkflowConfig{Nodes: []models.Node{body}},
+		}},
+		end,
+	}}}
+
+	options := &models.ConversionOptions{TitleSuffix: " (m)"}
+	require.NoError(t, common.ApplyTitleOptions(unifiedDSL, options))
+	require.Equal(t, "循环 (m)", unifiedDSL.Workflow.Nodes[0].Title)
+	iterConfig, ok := common.AsIterationConfig(unifiedDSL.Workflow.Nodes[0].Config)
+	require.True(t, ok)
+	require.Equal(t, "处理 (m)", iterConfig.SubWorkflow.Nodes[0].Title)
+
+	renamedEnd := unifiedDSL.Workflow.Nodes[1]
+	require.Equal(t, "结束 (m)", renamedEnd.Title)
+	render, ok := common.EndTemplateRenderNode(renamedEnd)
+	require.True(t, ok)
+	require.Equal(t, "结束回复模板 (m)", render.Title, "derived titles start from the title before renaming")
+	require.Equal(t, "结束回复模板", common.DerivedNodeTitle(end, "end_1_answer", models.NodeTypeCode, "回复模板"))
+
+	options = &models.ConversionOptions{KeepTitles: true, TitlePrefix: "x"}
+	require.Error(t, common.ApplyTitleOptions(unifiedDSL, options))
+}
+
+// TestIFlytekGenerator_EndNodeTitle tests that iFlytek end nodes carry their title, renamed or not
+func TestIFlytekGenerator_EndNodeTitle(t *testing.T) {
+	unifiedDSL := golden.GetIFlytekToUnified_BasicStartEnd()
+	require.NoError(t, common.ApplyTitleOptions(unifiedDSL, &models.ConversionOptions{TitlePrefix: "[m] "}))
+	var endTitle string
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if node.Type == models.NodeTypeEnd {
+			endTitle = node.Title
+		}
+	}
+	require.Contains(t, endTitle, "[m] ")
+
+	output, err := iflytekGenerator.NewIFlytekGenerator().Generate(unifiedDSL)
+	require.NoError(t, err)
+	var generated struct {
+		FlowData struct {
+			Nodes []struct {
+				Type string `yaml:"type"`
+				Data struct {
+					Label string `yaml:"label"`
+				} `yaml:"data"`
+			} `yaml:"nodes"`
+		} `yaml:"flowData"`
+	}
+	require.NoError(t, yaml.Unmarshal(output, &generated))
+	labels := make(map[string]string)
+	for _, node := range generated.FlowData.Nodes {
+		labels[node.Type] = node.Data.Label
+	}
+	require.Equal(t, endTitle, labels["结束节点"])
+}
@@ -0,0 +1,47 @@
+package integrations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/cache"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConversionCache validates that a cached result round-trips and that any change of the key
+// inputs misses
+func TestConversionCache(t *testing.T) {
+	source, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_basic_start_end.yml"))
+	require.NoError(t, err)
+	conversionService, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+	result, err := conversionService.ConvertWithResult(context.Background(), source, models.PlatformIFlytek, models.PlatformDify, models.NewConversionOptions())
+	require.NoError(t, err)
+
+	conversionCache := cache.New(t.TempDir())
+	key := cache.Key(source, models.PlatformIFlytek, models.PlatformDify, "1.0.0", []byte(`{"minify":true}`))
+	_, ok := conversionCache.Get(key)
+	require.False(t, ok)
+	require.NoError(t, conversionCache.Put(key, result))
+
+	cached, ok := conversionCache.Get(key)
+	require.True(t, ok)
+	require.Equal(t, string(result.Output), string(cached.Output))
+	require.Equal(t, result.IDMapping.Nodes, cached.IDMapping.Nodes)
+	require.Equal(t, result.NodeTypes, cached.NodeTypes)
+
+	for _, other := range []string{
+		cache.Key(append(source, '\n'), models.PlatformIFlytek, models.PlatformDify, "1.0.0", []byte(`{"minify":true}`)),
+		cache.Key(source, models.PlatformIFlytek, models.PlatformCoze, "1.0.0", []byte(`{"minify":true}`)),
+		cache.Key(source, models.PlatformIFlytek, models.PlatformDify, "1.0.1", []byte(`{"minify":true}`)),
+		cache.Key(source, models.PlatformIFlytek, models.PlatformDify, "1.0.0", []byte(`{}`)),
+	} {
+		require.NotEqual(t, key, other)
+		_, ok := conversionCache.Get(other)
+		require.False(t, ok)
+	}
+}
@@ -0,0 +1,129 @@
+package integrations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNodeCapabilityFor validates the verdicts of the capability matrix and their refinements
+func TestNodeCapabilityFor(t *testing.T) {
+	input := []models.Input{{Name: "items", Reference: &models.VariableReference{NodeID: "start", OutputName: "items"}}}
+	failOptions := models.NewConversionOptions()
+	failOptions.PlaceholderStrategy = models.PlaceholderStrategyFail
+	httpOptions := models.NewConversionOptions()
+	httpOptions.AudioStrategy = models.AudioStrategyHTTP
+
+	cases := []struct {
+		name        string
+		node        models.Node
+		target      models.PlatformType
+		options     *models.ConversionOptions
+		level       common.SupportLevel
+		placeholder bool
+	}{
+		{"llm", models.Node{Type: models.NodeTypeLLM}, models.PlatformCoze, nil, common.SupportNative, false},
+		{"agent on dify", models.Node{Type: models.NodeTypeAgent}, models.PlatformDify, nil, common.SupportNative, false},
+		{"agent on iflytek", models.Node{Type: models.NodeTypeAgent}, models.PlatformIFlytek, nil, common.SupportDegraded, false},
+		{"data store", models.Node{Type: models.NodeTypeDataStore}, models.PlatformIFlytek, nil, common.SupportDegraded, true},
+		{"data store with fail strategy", models.Node{Type: models.NodeTypeDataStore}, models.PlatformIFlytek, failOptions, common.SupportUnsupported, true},
+		{"speech on iflytek", models.Node{Type: models.NodeTypeTextToSpeech}, models.PlatformIFlytek, nil, common.SupportNative, false},
+		{"speech on dify", models.Node{Type: models.NodeTypeTextToSpeech}, models.PlatformDify, nil, common.SupportDegraded, true},
+		{"speech over http", models.Node{Type: models.NodeTypeSpeechToText}, models.PlatformDify, httpOptions, common.SupportDegraded, false},
+		{"speech over http with fail strategy", models.Node{Type: models.NodeTypeSpeechToText}, models.PlatformCoze, failOptions, common.SupportUnsupported, true},
+		{"list operation on dify", models.Node{Type: models.NodeTypeListOperation, Inputs: input, Config: models.ListOperationConfig{}}, models.PlatformDify, nil, common.SupportNative, false},
+		{"list field mapping on dify", models.Node{Type: models.NodeTypeListOperation, Inputs: input, Config: models.ListOperationConfig{MapField: "name"}}, models.PlatformDify, nil, common.SupportDegraded, false},
+		{"unconnected list operation", models.Node{Type: models.NodeTypeListOperation, Config: models.ListOperationConfig{}}, models.PlatformCoze, nil, common.SupportDegraded, true},
+		{"json process", models.Node{Type: models.NodeTypeJSONProcess, Inputs: input}, models.PlatformIFlytek, nil, common.SupportDegraded, false},
+		{"unconnected json process", models.Node{Type: models.NodeTypeJSONProcess}, models.PlatformIFlytek, failOptions, common.SupportUnsupported, true},
+		{"source placeholder", models.Node{Type: models.NodeTypeCode, PlatformConfig: models.PlatformConfig{Placeholder: &models.PlaceholderMarker{}}}, models.PlatformDify, nil, common.SupportDegraded, true},
+		{"unified target", models.Node{Type: models.NodeTypeDataStore}, models.PlatformUnified, failOptions, common.SupportNative, false},
+		{"unknown node type", models.Node{Type: models.NodeType("loop")}, models.PlatformDify, nil, common.SupportUnsupported, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			capability := common.NodeCapabilityFor(tc.node, tc.target, tc.options)
+			require.Equal(t, tc.level, capability.Level, capability.Note)
+			require.Equal(t, tc.placeholder, capability.Placeholder, capability.Note)
+			if tc.level != common.SupportNative {
+				require.NotEmpty(t, capability.Note)
+			}
+		})
+	}
+}
+
+// TestConversionCheck validates the pre-flight check of whole workflows
+func TestConversionCheck(t *testing.T) {
+	conversionService, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", name))
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("native workflow", func(t *testing.T) {
+		report, err := conversionService.Check(fixture("dify/dify_start_agent_end.yml"), models.PlatformDify, models.PlatformDify, nil)
+		require.NoError(t, err)
+		require.True(t, report.Passed())
+		require.Equal(t, len(report.Nodes), report.Count(common.SupportNative))
+	})
+
+	t.Run("degraded agent", func(t *testing.T) {
+		report, err := conversionService.Check(fixture("dify/dify_start_agent_end.yml"), models.PlatformDify, models.PlatformIFlytek, nil)
+		require.NoError(t, err)
+		require.True(t, report.Passed())
+		require.Equal(t, 1, report.Count(common.SupportDegraded))
+		require.Zero(t, report.Placeholders())
+	})
+
+	t.Run("iteration descent", func(t *testing.T) {
+		for fixtureName, platform := range map[string]models.PlatformType{
+			"dify/dify_start_iteration_end.yml":       models.PlatformDify,
+			"iflytek/iflytek_start_iteration_end.yml": models.PlatformIFlytek,
+			"coze/coze_start_iteration_end.yml":       models.PlatformCoze,
+		} {
+			report, err := conversionService.Check(fixture(fixtureName), platform, models.PlatformDify, nil)
+			require.NoError(t, err, fixtureName)
+
+			iterations := make(map[string]bool)
+			for _, node := range report.Nodes {
+				if node.Type == models.NodeTypeIteration {
+					iterations[node.ID] = true
+				}
+			}
+			require.NotEmpty(t, iterations, fixtureName)
+			subNodes := 0
+			for _, node := range report.Nodes {
+				if node.IterationID != "" {
+					require.True(t, iterations[node.IterationID], "%s: node %s", fixtureName, node.ID)
+					subNodes++
+				}
+			}
+			require.NotZero(t, subNodes, "%s: iteration sub-nodes should be checked", fixtureName)
+		}
+	})
+
+	t.Run("placeholders", func(t *testing.T) {
+		report, err := conversionService.Check(fixture("coze/coze_start_database_end.yml"), models.PlatformCoze, models.PlatformIFlytek, nil)
+		require.NoError(t, err)
+		require.True(t, report.Passed(), "%v", report.DryRunError)
+		require.NotZero(t, report.Placeholders())
+		require.Zero(t, report.Count(common.SupportUnsupported))
+	})
+
+	t.Run("fail placeholder strategy", func(t *testing.T) {
+		options := models.NewConversionOptions()
+		options.PlaceholderStrategy = models.PlaceholderStrategyFail
+		report, err := conversionService.Check(fixture("coze/coze_start_database_end.yml"), models.PlatformCoze, models.PlatformIFlytek, options)
+		require.NoError(t, err)
+		require.False(t, report.Passed())
+		require.Equal(t, report.Placeholders(), report.Count(common.SupportUnsupported))
+		require.Error(t, report.DryRunError, "the dry run should fail like the conversion")
+	})
+}
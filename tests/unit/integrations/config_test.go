@@ -0,0 +1,116 @@
+package integrations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iflytek/agentbridge/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigLoad validates reading config files, missing ones included
+func TestConfigLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	cases := []struct {
+		name     string
+		path     string
+		explicit bool
+		errorMsg string
+	}{
+		{"missing default file", filepath.Join(dir, "absent.yaml"), false, ""},
+		{"missing explicit file", filepath.Join(dir, "absent.yaml"), true, "failed to read config file"},
+		{"valid file", write("valid.yaml", "defaults:\n  workers: 2\nprofiles:\n  prod:\n    parse_mode: strict\n"), true, ""},
+		{"invalid yaml", write("broken.yaml", "defaults: [\n"), true, "invalid config file"},
+		{"invalid enum", write("enum.yaml", "profiles:\n  prod:\n    placeholder_strategy: drop\n"), true, `config profile "prod": invalid placeholder_strategy "drop"`},
+		{"negative workers", write("workers.yaml", "defaults:\n  workers: -1\n"), false, `config profile "defaults": workers must not be negative`},
+		{"unknown account platform", write("account.yaml", "defaults:\n  accounts:\n    n8n: {endpoint: https://n8n.example.com}\n"), true, `unknown account platform "n8n"`},
+		{"icon without url", write("icons.yaml", "defaults:\n  icons:\n    iflytek_default: book.png\n"), true, "must be an image URL"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := config.Load(tc.path, tc.explicit)
+			if tc.errorMsg != "" {
+				require.ErrorContains(t, err, tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, file)
+		})
+	}
+}
+
+// TestConfigResolve validates profile selection and how profiles overlay the defaults
+func TestConfigResolve(t *testing.T) {
+	enabled, disabled := true, false
+	file := &config.File{
+		DefaultProfile: "dev",
+		Defaults: config.Profile{
+			Verbose:     &enabled,
+			Workers:     4,
+			TitlePrefix: "[base] ",
+			ModelMap:    map[string]string{"gpt-4o": "xdeepseekv3", "gpt-4o-mini": "spark-lite"},
+			IFlytek:     config.IFlytekProfile{AppID: "base-app", UID: "base-uid"},
+			Accounts:    map[string]config.AccountProfile{"dify": {Endpoint: "https://dify.example.com", APIKey: "base-key"}},
+		},
+		Profiles: map[string]config.Profile{
+			"dev": {TitleSuffix: " (dev)"},
+			"prod": {
+				Verbose:  &disabled,
+				ModelMap: map[string]string{"gpt-4o": "4.0Ultra"},
+				IFlytek:  config.IFlytekProfile{AppID: "prod-app"},
+				Accounts: map[string]config.AccountProfile{"dify": {APIKey: "prod-key"}, "coze": {Space: "7"}},
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		profile  string
+		errorMsg string
+		check    func(t *testing.T, profile *config.Profile)
+	}{
+		{"default profile", "", "", func(t *testing.T, profile *config.Profile) {
+			require.Equal(t, " (dev)", profile.TitleSuffix)
+			require.Equal(t, "[base] ", profile.TitlePrefix)
+		}},
+		{"named profile", "prod", "", func(t *testing.T, profile *config.Profile) {
+			require.Empty(t, profile.TitleSuffix)
+			require.False(t, *profile.Verbose, "set booleans override, false included")
+			require.Equal(t, 4, profile.Workers, "unset values keep the defaults")
+			require.Equal(t, map[string]string{"gpt-4o": "4.0Ultra", "gpt-4o-mini": "spark-lite"}, profile.ModelMap)
+			require.Equal(t, config.IFlytekProfile{AppID: "prod-app", UID: "base-uid"}, profile.IFlytek)
+			require.Equal(t, config.AccountProfile{Endpoint: "https://dify.example.com", APIKey: "prod-key"}, profile.Accounts["dify"])
+			require.Equal(t, "7", profile.Accounts["coze"].Space)
+		}},
+		{"unknown profile", "staging", `config profile "staging" not found (available: dev, prod)`, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			profile, err := file.Resolve(tc.profile)
+			if tc.errorMsg != "" {
+				require.EqualError(t, err, tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, profile)
+		})
+	}
+
+	// Resolving must not change the defaults shared by all profiles
+	require.Equal(t, map[string]string{"gpt-4o": "xdeepseekv3", "gpt-4o-mini": "spark-lite"}, file.Defaults.ModelMap)
+	require.Equal(t, "base-key", file.Defaults.Accounts["dify"].APIKey)
+	require.True(t, *file.Defaults.Verbose)
+
+	t.Run("no default profile", func(t *testing.T) {
+		profile, err := (&config.File{Defaults: config.Profile{Workers: 2}}).Resolve("")
+		require.NoError(t, err)
+		require.Equal(t, 2, profile.Workers)
+	})
+}
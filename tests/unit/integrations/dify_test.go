@@ -0,0 +1,83 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iflytek/agentbridge/integrations"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDifyAdapter validates export, import with version confirmation and workspace switching
+// against a stub of the Dify console API
+func TestDifyAdapter(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		switch r.URL.Path {
+		case "/console/api/apps/app-1/export":
+			json.NewEncoder(w).Encode(map[string]string{"data": "app:\n  mode: workflow\n"})
+		case "/console/api/workspaces/switch":
+			w.Write([]byte(`{"result":"success"}`))
+		case "/console/api/apps/imports":
+			var request map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			require.Equal(t, "yaml-content", request["mode"])
+			json.NewEncoder(w).Encode(map[string]string{
+				"id": "import-1", "status": "pending", "imported_dsl_version": "0.1.5", "current_dsl_version": "0.3.0",
+			})
+		case "/console/api/apps/imports/import-1/confirm":
+			json.NewEncoder(w).Encode(map[string]string{"id": "import-1", "status": "completed", "app_id": "app-2"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter, err := integrations.NewAdapter(integrations.Account{
+		Platform: models.PlatformDify,
+		Endpoint: server.URL,
+		APIKey:   "token",
+		Space:    "tenant-1",
+	})
+	require.NoError(t, err)
+
+	data, err := adapter.Pull(context.Background(), "app-1", true)
+	require.NoError(t, err)
+	require.Equal(t, "app:\n  mode: workflow\n", string(data))
+
+	result, err := adapter.Push(context.Background(), data, integrations.PushOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "app-2", result.AppID)
+	require.False(t, result.Updated)
+	require.Contains(t, result.Warning, "0.1.5")
+
+	require.Equal(t, []string{
+		"GET /console/api/apps/app-1/export?include_secret=true",
+		"POST /console/api/workspaces/switch",
+		"POST /console/api/apps/imports",
+		"POST /console/api/apps/imports/import-1/confirm",
+	}, calls)
+}
+
+// TestUnsupportedAdapters validates that platforms without DSL APIs report the manual step
+func TestUnsupportedAdapters(t *testing.T) {
+	for _, platform := range []models.PlatformType{models.PlatformCoze, models.PlatformIFlytek} {
+		adapter, err := integrations.NewAdapter(integrations.Account{Platform: platform})
+		require.NoError(t, err)
+
+		_, err = adapter.Pull(context.Background(), "7412345678901234567", false)
+		require.True(t, errors.Is(err, integrations.ErrUnsupported), platform)
+		_, err = adapter.Push(context.Background(), []byte("schema: {}"), integrations.PushOptions{})
+		require.True(t, errors.Is(err, integrations.ErrUnsupported), platform)
+	}
+
+	_, err := integrations.NewAdapter(integrations.Account{Platform: models.PlatformDify})
+	require.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package integrations
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/server"
+	agentbridgev1 "github.com/iflytek/agentbridge/proto/agentbridge/v1"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestGRPCConvertService validates Convert, ConvertStream and Parse over an in-memory connection
+func TestGRPCConvertService(t *testing.T) {
+	conversionService, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := server.NewGRPC(conversionService, server.Config{})
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := agentbridgev1.NewConvertServiceClient(conn)
+	ctx := context.Background()
+
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", name))
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("convert", func(t *testing.T) {
+		response, err := client.Convert(ctx, &agentbridgev1.ConvertRequest{
+			Source:  fixture("iflytek/iflytek_start_llm_end.yml"),
+			To:      "dify",
+			Options: &agentbridgev1.ConversionOptions{Minify: proto.Bool(true)},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "iflytek", response.Source)
+		require.Contains(t, string(response.Output), "workflow:")
+		require.NotEmpty(t, response.NodeMapping)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		stream, err := client.ConvertStream(ctx, &agentbridgev1.ConvertRequest{Source: fixture("dify/dify_start_classifier_end.yml"), To: "iflytek"})
+		require.NoError(t, err)
+		var stages []string
+		var result *agentbridgev1.ConvertResponse
+		for {
+			event, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			if progress := event.GetProgress(); progress != nil {
+				stages = append(stages, progress.Stage)
+			}
+			if event.GetResult() != nil {
+				result = event.GetResult()
+			}
+		}
+		require.Contains(t, stages, "generate")
+		require.NotNil(t, result, "the stream should end with the result")
+		require.NotEmpty(t, result.Output)
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		response, err := client.Parse(ctx, &agentbridgev1.ParseRequest{Source: fixture("dify/dify_start_llm_end.yml")})
+		require.NoError(t, err)
+		require.Equal(t, "dify", response.Platform)
+		var llm *agentbridgev1.Node
+		for _, node := range response.Dsl.Workflow.Nodes {
+			if node.Type == "llm" {
+				llm = node
+			}
+		}
+		require.NotNil(t, llm)
+		require.NotEmpty(t, llm.Config.Fields["model"].GetStructValue().GetFields()["name"].GetStringValue())
+		require.NotEmpty(t, response.Dsl.Workflow.Edges)
+	})
+
+	t.Run("bad target", func(t *testing.T) {
+		_, err := client.Convert(ctx, &agentbridgev1.ConvertRequest{Source: fixture("dify/dify_start_llm_end.yml"), To: "langflow"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
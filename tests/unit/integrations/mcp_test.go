@@ -0,0 +1,99 @@
+package integrations
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMCPServer validates the handshake, the tool list and the tool calls of the MCP server
+func TestMCPServer(t *testing.T) {
+	conversionService, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+	server := mcp.New(conversionService, mcp.Config{Version: "test"})
+
+	call := func(id int, name string, arguments map[string]interface{}) string {
+		request, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0", "id": id, "method": "tools/call",
+			"params": map[string]interface{}{"name": name, "arguments": arguments},
+		})
+		require.NoError(t, err)
+		return string(request)
+	}
+	fixture := func(name string) string {
+		return filepath.Join("..", "..", "fixtures", name)
+	}
+	requests := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		call(3, "convert_workflow", map[string]interface{}{"path": fixture("iflytek/iflytek_start_llm_end.yml"), "to": "dify"}),
+		call(4, "validate_workflow", map[string]interface{}{"path": fixture("dify/dify_start_classifier_end.yml"), "to": "iflytek"}),
+		call(5, "inspect_workflow", map[string]interface{}{"path": fixture("dify/dify_start_iteration_end.yml")}),
+		call(6, "convert_workflow", map[string]interface{}{"path": fixture("dify/dify_start_llm_end.yml"), "to": "coze"}),
+		`{"jsonrpc":"2.0","id":7,"method":"resources/list"}`,
+	}
+
+	var output bytes.Buffer
+	require.NoError(t, server.Serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")), &output))
+
+	type result struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	responses := map[int]struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}{}
+	scanner := bufio.NewScanner(&output)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var response struct {
+			ID int `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &response))
+		entry := responses[response.ID]
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		responses[response.ID] = entry
+	}
+	require.Len(t, responses, 7, "the notification gets no response")
+
+	require.Contains(t, string(responses[1].Result), `"protocolVersion":"2024-11-05"`)
+	for _, name := range []string{"convert_workflow", "validate_workflow", "inspect_workflow"} {
+		require.Contains(t, string(responses[2].Result), `"name":"`+name+`"`)
+	}
+
+	var converted result
+	require.NoError(t, json.Unmarshal(responses[3].Result, &converted))
+	require.False(t, converted.IsError)
+	require.Len(t, converted.Content, 2)
+	require.Contains(t, converted.Content[0].Text, "workflow:")
+	require.Contains(t, converted.Content[1].Text, `"node_mapping"`)
+
+	var validated result
+	require.NoError(t, json.Unmarshal(responses[4].Result, &validated))
+	require.Contains(t, validated.Content[0].Text, `"valid": true`)
+
+	var inspected result
+	require.NoError(t, json.Unmarshal(responses[5].Result, &inspected))
+	require.Contains(t, inspected.Content[0].Text, `"iteration":`)
+
+	var rejected result
+	require.NoError(t, json.Unmarshal(responses[6].Result, &rejected))
+	require.True(t, rejected.IsError, "dify to coze goes through iflytek")
+
+	require.NotNil(t, responses[7].Error)
+	require.Equal(t, -32601, responses[7].Error.Code)
+}
@@ -0,0 +1,83 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestConvertNode validates converting single nodes pasted from exports, without their workflow
+func TestConvertNode(t *testing.T) {
+	conversionService, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// fixtureNode returns the node of a fixture whose type, found at typePath, is nodeType
+	fixtureNode := func(name string, nodesPath, typePath []string, nodeType string) []byte {
+		data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", name))
+		require.NoError(t, err)
+		var current interface{}
+		require.NoError(t, yaml.Unmarshal(data, &current))
+		for _, key := range nodesPath {
+			current = current.(map[string]interface{})[key]
+		}
+		for _, node := range current.([]interface{}) {
+			value := node
+			for _, key := range typePath {
+				value = value.(map[string]interface{})[key]
+			}
+			if value == nodeType {
+				encoded, err := yaml.Marshal(node)
+				require.NoError(t, err)
+				return encoded
+			}
+		}
+		t.Fatalf("%s has no %s node", name, nodeType)
+		return nil
+	}
+
+	t.Run("dify llm to iflytek", func(t *testing.T) {
+		node := fixtureNode("dify/dify_start_llm_end.yml", []string{"workflow", "graph", "nodes"}, []string{"data", "type"}, "llm")
+		result, err := conversionService.ConvertNode(ctx, node, models.PlatformDify, models.PlatformIFlytek, nil)
+		require.NoError(t, err)
+		require.Equal(t, "1754290000001", result.SourceNodeID)
+		require.True(t, strings.HasPrefix(result.TargetNodeID, "spark-llm::"), result.TargetNodeID)
+
+		var converted map[string]interface{}
+		require.NoError(t, yaml.Unmarshal(result.Output, &converted))
+		require.Equal(t, result.TargetNodeID, converted["id"])
+		require.Equal(t, "大模型", converted["type"])
+		require.NotContains(t, string(result.Output), "1754269219469", "the start node read by the node is not part of the conversion")
+		require.Contains(t, strings.Join(result.Warnings, "\n"), "start variable input_01 replaces it")
+	})
+
+	t.Run("iflytek llm to dify as json", func(t *testing.T) {
+		node := fixtureNode("iflytek/iflytek_start_llm_end.yml", []string{"flowData", "nodes"}, []string{"type"}, "大模型")
+		options := models.NewConversionOptions()
+		options.OutputFormat = models.OutputFormatJSON
+		result, err := conversionService.ConvertNode(ctx, node, models.PlatformIFlytek, models.PlatformDify, options)
+		require.NoError(t, err)
+
+		var converted struct {
+			ID   string                 `json:"id"`
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(result.Output, &converted))
+		require.Equal(t, result.TargetNodeID, converted.ID)
+		require.Equal(t, "llm", converted.Data["type"])
+	})
+
+	t.Run("node without id", func(t *testing.T) {
+		_, err := conversionService.ConvertNode(ctx, []byte("type: llm\n"), models.PlatformDify, models.PlatformIFlytek, nil)
+		var parseErr *models.ParseError
+		require.ErrorAs(t, err, &parseErr)
+	})
+}
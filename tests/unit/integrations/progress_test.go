@@ -0,0 +1,57 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConversionProgress validates that a conversion reports every stage and node in order, and
+// that the SSE reporter streams the same events
+func TestConversionProgress(t *testing.T) {
+	source, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_classifier_end.yml"))
+	require.NoError(t, err)
+	conversionService, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+
+	var events []models.ProgressEvent
+	var stream bytes.Buffer
+	sse := services.NewSSEProgressReporter(&stream)
+	options := models.NewConversionOptions()
+	options.Progress = models.ProgressFunc(func(event models.ProgressEvent) {
+		events = append(events, event)
+		sse.ReportProgress(event)
+	})
+	_, err = conversionService.ConvertWithResult(context.Background(), source, models.PlatformDify, models.PlatformIFlytek, options)
+	require.NoError(t, err)
+	require.NoError(t, sse.Err())
+
+	var stages []models.ProgressStage
+	generatedNodes := 0
+	for i, event := range events {
+		if i > 0 {
+			require.GreaterOrEqual(t, event.Percent, events[i-1].Percent, "progress should not go back")
+		}
+		if event.NodeID == "" {
+			stages = append(stages, event.Stage)
+		} else if event.Stage == models.ProgressStageGenerate {
+			generatedNodes++
+		}
+	}
+	require.Equal(t, []models.ProgressStage{
+		models.ProgressStageParse, models.ProgressStageTransform, models.ProgressStageGenerate, models.ProgressStageDone,
+	}, stages)
+	require.Equal(t, 5, generatedNodes)
+	require.Equal(t, 100, events[len(events)-1].Percent)
+
+	require.Equal(t, len(events), strings.Count(stream.String(), "event: progress\ndata: {"))
+	require.Contains(t, stream.String(), `data: {"stage":"done","percent":100}`)
+}
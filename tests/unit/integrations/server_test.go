@@ -0,0 +1,113 @@
+package integrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/server"
+	"github.com/iflytek/agentbridge/web"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadForm returns a multipart form with the fixture as "file" and the fields
+func uploadForm(t *testing.T, fixture string, fields map[string]string) (*bytes.Buffer, string) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", fixture))
+	require.NoError(t, err)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(fixture))
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	require.NoError(t, writer.Close())
+	return &body, writer.FormDataContentType()
+}
+
+// TestServer validates the playground API: graph preview, plain and streamed conversion, and the
+// embedded UI
+func TestServer(t *testing.T) {
+	conversionService, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+	ts := httptest.NewServer(server.New(conversionService, server.Config{UI: web.UI()}))
+	defer ts.Close()
+
+	t.Run("parse", func(t *testing.T) {
+		body, contentType := uploadForm(t, "dify/dify_start_iteration_end.yml", nil)
+		response, err := http.Post(ts.URL+"/api/parse", contentType, body)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		var graph server.ParseResponse
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&graph))
+		require.Equal(t, "dify", string(graph.Platform))
+		require.NotEmpty(t, graph.Edges)
+		var bodyNodes int
+		for _, node := range graph.Nodes {
+			if node.Parent != "" {
+				bodyNodes++
+			}
+		}
+		require.Positive(t, bodyNodes, "iteration body nodes should name their iteration")
+	})
+
+	t.Run("convert", func(t *testing.T) {
+		body, contentType := uploadForm(t, "iflytek/iflytek_start_llm_end.yml", map[string]string{"to": "dify", "minify": "true"})
+		response, err := http.Post(ts.URL+"/api/convert", contentType, body)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		var result server.ConvertResponse
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+		require.Equal(t, "iflytek_start_llm_end.dify.yml", result.Filename)
+		require.Contains(t, result.Output, "workflow:")
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		body, contentType := uploadForm(t, "dify/dify_start_classifier_end.yml", map[string]string{"to": "iflytek"})
+		request, err := http.NewRequest(http.MethodPost, ts.URL+"/api/convert", body)
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", contentType)
+		request.Header.Set("Accept", "text/event-stream")
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		stream, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+		require.Contains(t, string(stream), `data: {"stage":"generate","percent":50}`)
+		require.True(t, strings.Contains(string(stream), "\nevent: result\n"), "the stream should end with the result")
+	})
+
+	t.Run("bad target", func(t *testing.T) {
+		body, contentType := uploadForm(t, "dify/dify_start_llm_end.yml", map[string]string{"to": "langflow"})
+		response, err := http.Post(ts.URL+"/api/convert", contentType, body)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+
+	t.Run("ui", func(t *testing.T) {
+		response, err := http.Get(ts.URL + "/")
+		require.NoError(t, err)
+		defer response.Body.Close()
+		page, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(page), "AgentBridge Playground")
+	})
+}
@@ -0,0 +1,164 @@
+package integrations
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iflytek/agentbridge/internal/signing"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPEM writes a PEM block to name in dir and returns its path
+func writeKeyPEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+// TestSigningRoundTrip validates signing and verifying with RSA, ECDSA and Ed25519 keys in the
+// formats OpenSSL writes, and that a tampered file or a wrong key fails verification
+func TestSigningRoundTrip(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sec1, err := x509.MarshalECPrivateKey(ecdsaKey)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		key       crypto.Signer
+		blockType string // Private key block; empty writes PKCS#8
+		der       []byte
+	}{
+		{"rsa pkcs8", rsaKey, "", nil},
+		{"rsa pkcs1", rsaKey, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)},
+		{"ecdsa pkcs8", ecdsaKey, "", nil},
+		{"ecdsa sec1", ecdsaKey, "EC PRIVATE KEY", sec1},
+		{"ed25519", ed25519Key, "", nil},
+	}
+
+	data := []byte("app:\n  name: signed\n")
+	tampered := []byte("app:\n  name: tampered\n")
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			publicDER, err := x509.MarshalPKIXPublicKey(tc.key.Public())
+			require.NoError(t, err)
+			blockType, der := tc.blockType, tc.der
+			if blockType == "" {
+				blockType = "PRIVATE KEY"
+				der, err = x509.MarshalPKCS8PrivateKey(tc.key)
+				require.NoError(t, err)
+			}
+			privatePath := writeKeyPEM(t, dir, "key.pem", blockType, der)
+			publicPath := writeKeyPEM(t, dir, "public.pem", "PUBLIC KEY", publicDER)
+
+			signer, err := signing.LoadPrivateKey(privatePath)
+			require.NoError(t, err)
+			signature, err := signing.Sign(signer, data)
+			require.NoError(t, err)
+
+			publicKey, err := signing.LoadPublicKey(publicPath)
+			require.NoError(t, err)
+			require.NoError(t, signing.Verify(publicKey, data, signature))
+
+			// The signing key verifies with its public half
+			privateAsPublic, err := signing.LoadPublicKey(privatePath)
+			require.NoError(t, err)
+			require.NoError(t, signing.Verify(privateAsPublic, data, signature))
+
+			require.ErrorIs(t, signing.Verify(publicKey, tampered, signature), signing.ErrInvalidSignature)
+		})
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		signature, err := signing.Sign(rsaKey, data)
+		require.NoError(t, err)
+		otherRSA, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		require.ErrorIs(t, signing.Verify(otherRSA.Public(), data, signature), signing.ErrInvalidSignature)
+		require.ErrorIs(t, signing.Verify(ed25519Key.Public(), data, signature), signing.ErrInvalidSignature)
+
+		signature, err = signing.Sign(ecdsaKey, data)
+		require.NoError(t, err)
+		otherECDSA, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		require.ErrorIs(t, signing.Verify(otherECDSA.Public(), data, signature), signing.ErrInvalidSignature)
+	})
+
+	t.Run("invalid key files", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := signing.LoadPrivateKey(filepath.Join(dir, "absent.pem"))
+		require.ErrorContains(t, err, "failed to read key file")
+
+		notPEM := filepath.Join(dir, "key.txt")
+		require.NoError(t, os.WriteFile(notPEM, []byte("not a key"), 0o600))
+		_, err = signing.LoadPrivateKey(notPEM)
+		require.ErrorContains(t, err, "no PEM data found")
+
+		encrypted := writeKeyPEM(t, dir, "encrypted.pem", "ENCRYPTED PRIVATE KEY", []byte{0})
+		_, err = signing.LoadPrivateKey(encrypted)
+		require.ErrorContains(t, err, "encrypted keys are not supported")
+
+		publicDER, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+		require.NoError(t, err)
+		_, err = signing.LoadPrivateKey(writeKeyPEM(t, dir, "public.pem", "PUBLIC KEY", publicDER))
+		require.ErrorContains(t, err, "expected a private key")
+	})
+}
+
+// TestChecksumFile validates checksum files in the sha256sum format
+func TestChecksumFile(t *testing.T) {
+	data := []byte("workflow: {}\n")
+	sum := signing.Checksum(data)
+	otherSum := signing.Checksum([]byte("other"))
+
+	formatted := signing.FormatChecksumFile(data, "agent.yml")
+	require.Equal(t, sum+"  agent.yml\n", string(formatted))
+	parsed, err := signing.ParseChecksumFile(formatted, "agent.yml")
+	require.NoError(t, err)
+	require.Equal(t, sum, parsed)
+
+	cases := []struct {
+		name     string
+		content  string
+		fileName string
+		expected string
+		errorMsg string
+	}{
+		{"single entry with another name", sum + "  renamed.yml\n", "agent.yml", sum, ""},
+		{"multiple entries", otherSum + "  other.yml\n\n" + sum + "  agent.yml\n", "agent.yml", sum, ""},
+		{"binary mode marker", otherSum + "  other.yml\n" + sum + " *agent.yml\n", "agent.yml", sum, ""},
+		{"upper case digest", otherSum + "  other.yml\n" + strings.ToUpper(sum) + "  agent.yml\n", "agent.yml", sum, ""},
+		{"multiple entries without the file", otherSum + "  other.yml\n" + sum + "  third.yml\n", "agent.yml", "", "no checksum recorded for agent.yml"},
+		{"malformed line", "abc  agent.yml\n", "agent.yml", "", "malformed checksum line"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := signing.ParseChecksumFile([]byte(tc.content), tc.fileName)
+			if tc.errorMsg != "" {
+				require.ErrorContains(t, err, tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+
+	require.NoError(t, signing.VerifyChecksum(data, strings.ToUpper(sum)))
+	require.ErrorIs(t, signing.VerifyChecksum([]byte("workflow: {}\n\n"), sum), signing.ErrChecksumMismatch)
+}
@@ -0,0 +1,210 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/internal/smoketest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSmokeTestDify validates importing, running and deleting a test app against a stub of the
+// Dify console and app APIs
+func TestSmokeTestDify(t *testing.T) {
+	// difyServer stubs an instance whose workflow runs end with status and chatflows answer "pong"
+	difyServer := func(t *testing.T, status string, calls *[]string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls = append(*calls, r.Method+" "+r.URL.Path)
+			switch r.URL.Path {
+			case "/console/api/apps/imports":
+				require.Equal(t, "Bearer console-token", r.Header.Get("Authorization"))
+				json.NewEncoder(w).Encode(map[string]string{"id": "import-1", "status": "completed", "app_id": "app-1"})
+			case "/console/api/apps/app-1/api-keys":
+				require.Equal(t, "Bearer console-token", r.Header.Get("Authorization"))
+				json.NewEncoder(w).Encode(map[string]string{"token": "app-token"})
+			case "/console/api/apps/app-1":
+				require.Equal(t, http.MethodDelete, r.Method)
+				w.WriteHeader(http.StatusNoContent)
+			case "/v1/workflows/run":
+				require.Equal(t, "Bearer app-token", r.Header.Get("Authorization"))
+				var request map[string]interface{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+				require.Equal(t, map[string]interface{}{"topic": "hello"}, request["inputs"])
+				require.Equal(t, "blocking", request["response_mode"])
+				require.Equal(t, smoketest.UserID, request["user"])
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"workflow_run_id": "run-1",
+					"data":            map[string]interface{}{"status": status, "outputs": map[string]string{"result": "done"}, "error": "node failed"},
+				})
+			case "/v1/chat-messages":
+				require.Equal(t, "Bearer app-token", r.Header.Get("Authorization"))
+				var request map[string]interface{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+				require.Equal(t, "ping", request["query"])
+				json.NewEncoder(w).Encode(map[string]string{"answer": "pong"})
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	inputs := map[string]interface{}{"topic": "hello"}
+
+	t.Run("workflow", func(t *testing.T) {
+		var calls []string
+		server := difyServer(t, "succeeded", &calls)
+		target := smoketest.Target{Platform: models.PlatformDify, Endpoint: server.URL, APIKey: "console-token"}
+		result, err := smoketest.Run(context.Background(), target, []byte("app:\n  mode: workflow\n"), inputs)
+		require.NoError(t, err)
+		require.Equal(t, "app-1", result.AppID)
+		require.True(t, result.Imported)
+		require.Equal(t, map[string]interface{}{"result": "done"}, result.Outputs)
+		require.Equal(t, []string{
+			"POST /console/api/apps/imports",
+			"POST /console/api/apps/app-1/api-keys",
+			"POST /v1/workflows/run",
+			"DELETE /console/api/apps/app-1",
+		}, calls)
+	})
+
+	t.Run("chatflow kept", func(t *testing.T) {
+		var calls []string
+		server := difyServer(t, "succeeded", &calls)
+		target := smoketest.Target{Platform: models.PlatformDify, Endpoint: server.URL, APIKey: "console-token", Query: "ping", Keep: true}
+		result, err := smoketest.Run(context.Background(), target, []byte("app:\n  mode: advanced-chat\n"), inputs)
+		require.NoError(t, err)
+		require.Equal(t, "pong", result.Answer)
+		require.NotContains(t, calls, "DELETE /console/api/apps/app-1")
+	})
+
+	t.Run("failed run", func(t *testing.T) {
+		var calls []string
+		server := difyServer(t, "failed", &calls)
+		target := smoketest.Target{Platform: models.PlatformDify, Endpoint: server.URL, APIKey: "console-token"}
+		_, err := smoketest.Run(context.Background(), target, []byte("app:\n  mode: workflow\n"), inputs)
+		require.ErrorIs(t, err, smoketest.ErrRun)
+		require.ErrorContains(t, err, "workflow run run-1 failed: node failed")
+		require.Contains(t, calls, "DELETE /console/api/apps/app-1", "the test app is deleted after a failed run")
+	})
+
+	t.Run("rejected import", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]string{"id": "import-1", "status": "failed", "error": "unsupported version"})
+		}))
+		defer server.Close()
+		target := smoketest.Target{Platform: models.PlatformDify, Endpoint: server.URL, APIKey: "console-token"}
+		_, err := smoketest.Run(context.Background(), target, []byte("app:\n  mode: workflow\n"), inputs)
+		require.ErrorIs(t, err, smoketest.ErrImport)
+		require.ErrorContains(t, err, "unsupported version")
+
+		_, err = smoketest.Run(context.Background(), target, []byte("app: ["), inputs)
+		require.ErrorIs(t, err, smoketest.ErrImport)
+	})
+}
+
+// TestSmokeTestCoze validates running a published Coze workflow against a stub of the OpenAPI
+func TestSmokeTestCoze(t *testing.T) {
+	var response map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/workflow/run", r.URL.Path)
+		require.Equal(t, "Bearer pat", r.Header.Get("Authorization"))
+		var request map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		require.Equal(t, "7412345678901234567", request["workflow_id"])
+		require.Equal(t, map[string]interface{}{"topic": "hello"}, request["parameters"])
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	target := smoketest.Target{Platform: models.PlatformCoze, Endpoint: server.URL, APIKey: "pat", WorkflowID: "7412345678901234567"}
+	run := func() (*smoketest.Result, error) {
+		return smoketest.Run(context.Background(), target, nil, map[string]interface{}{"topic": "hello"})
+	}
+
+	response = map[string]interface{}{"code": 0, "data": `{"output":"done"}`}
+	result, err := run()
+	require.NoError(t, err)
+	require.Equal(t, "7412345678901234567", result.AppID)
+	require.False(t, result.Imported)
+	require.Equal(t, map[string]interface{}{"output": "done"}, result.Outputs)
+
+	response = map[string]interface{}{"code": 0, "data": "plain answer"}
+	result, err = run()
+	require.NoError(t, err)
+	require.Equal(t, "plain answer", result.Answer)
+
+	response = map[string]interface{}{"code": 4200, "msg": "workflow not published", "debug_url": "https://www.coze.cn/work_flow?execute_id=1"}
+	_, err = run()
+	require.ErrorIs(t, err, smoketest.ErrRun)
+	require.ErrorContains(t, err, "code 4200: workflow not published (trace: https://www.coze.cn/work_flow?execute_id=1)")
+}
+
+// TestSmokeTestIFlytek validates running a published iFlytek Spark workflow against a stub of the
+// workflow API
+func TestSmokeTestIFlytek(t *testing.T) {
+	status, response := http.StatusOK, map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/workflow/v1/chat/completions", r.URL.Path)
+		require.Equal(t, "Bearer key:secret", r.Header.Get("Authorization"))
+		var request map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		require.Equal(t, "7350000000000000000", request["flow_id"])
+		require.Equal(t, smoketest.UserID, request["uid"])
+		require.Equal(t, false, request["stream"])
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	target := smoketest.Target{Platform: models.PlatformIFlytek, Endpoint: server.URL, APIKey: "key:secret", WorkflowID: "7350000000000000000"}
+	run := func() (*smoketest.Result, error) {
+		return smoketest.Run(context.Background(), target, nil, map[string]interface{}{"AGENT_USER_INPUT": "hello"})
+	}
+
+	response = map[string]interface{}{"code": 0, "choices": []interface{}{map[string]interface{}{"delta": map[string]string{"content": "done"}}}}
+	result, err := run()
+	require.NoError(t, err)
+	require.Equal(t, "7350000000000000000", result.AppID)
+	require.Equal(t, "done", result.Answer)
+
+	response = map[string]interface{}{"code": 20204, "message": "flow not published"}
+	_, err = run()
+	require.ErrorIs(t, err, smoketest.ErrRun)
+	require.ErrorContains(t, err, "code 20204: flow not published")
+
+	status, response = http.StatusUnauthorized, map[string]interface{}{"message": "invalid key"}
+	_, err = run()
+	require.ErrorIs(t, err, smoketest.ErrRun)
+	require.ErrorContains(t, err, "401")
+}
+
+// TestSmokeTestTargetValidate validates the settings each platform needs before anything is sent
+func TestSmokeTestTargetValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		target   smoketest.Target
+		errorMsg string
+	}{
+		{"dify", smoketest.Target{Platform: models.PlatformDify, Endpoint: "http://dify", APIKey: "key"}, ""},
+		{"dify without endpoint", smoketest.Target{Platform: models.PlatformDify, APIKey: "key"}, "endpoint"},
+		{"coze without workflow", smoketest.Target{Platform: models.PlatformCoze, APIKey: "key"}, "Coze has no workflow import API"},
+		{"iflytek without flow", smoketest.Target{Platform: models.PlatformIFlytek, APIKey: "key"}, "iFlytek Spark has no workflow import API"},
+		{"missing key", smoketest.Target{Platform: models.PlatformCoze, WorkflowID: "1"}, "an API key is required"},
+		{"unsupported platform", smoketest.Target{Platform: "unified", APIKey: "key"}, "not supported"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.target.Validate()
+			if tc.errorMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.errorMsg)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package integrations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iflytek/agentbridge/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStatsAppendLoad validates appending records, concurrently as batch workers do, and reading them back
+func TestStatsAppendLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "stats.jsonl")
+
+	records, err := stats.Load(path)
+	require.NoError(t, err, "a missing file has no records")
+	require.Empty(t, records)
+
+	first := stats.Record{
+		Time:         time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+		Command:      "convert",
+		Source:       "iflytek",
+		Target:       "dify",
+		Success:      true,
+		Nodes:        map[string]int{"start": 1, "llm": 2, "end": 1},
+		Placeholders: 1,
+		Warnings:     3,
+		DurationMS:   42,
+	}
+	require.NoError(t, stats.Append(path, first), "the directory is created when needed")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			require.NoError(t, stats.Append(path, stats.Record{Command: "batch", Source: "coze", Target: "iflytek", DurationMS: int64(i)}))
+		}(i)
+	}
+	wg.Wait()
+
+	records, err = stats.Load(path)
+	require.NoError(t, err)
+	require.Len(t, records, 9)
+	require.Equal(t, first, records[0])
+	require.Equal(t, 4, records[0].NodeCount())
+	for _, record := range records[1:] {
+		require.Equal(t, "batch", record.Command)
+		require.Zero(t, record.NodeCount())
+	}
+
+	t.Run("blank lines", func(t *testing.T) {
+		path := filepath.Join(dir, "blank.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte("\n{\"command\":\"convert\"}\n\n"), 0o644))
+		records, err := stats.Load(path)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+	})
+
+	t.Run("invalid record", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte("{\"command\":\"convert\"}\nnot json\n"), 0o644))
+		_, err := stats.Load(path)
+		require.ErrorContains(t, err, fmt.Sprintf("%s:2: invalid statistics record", path))
+	})
+
+	t.Run("unwritable path", func(t *testing.T) {
+		blocker := filepath.Join(dir, "file")
+		require.NoError(t, os.WriteFile(blocker, nil, 0o644))
+		require.ErrorContains(t, stats.Append(filepath.Join(blocker, "stats.jsonl"), first), "failed to create statistics directory")
+	})
+}
+
+// TestStatsSummarize validates the aggregation of records
+func TestStatsSummarize(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC) }
+	records := []stats.Record{
+		{Time: day(3), Source: "iflytek", Target: "dify", Success: true, Nodes: map[string]int{"start": 1, "llm": 2, "end": 1}, Placeholders: 1},
+		{Time: day(1), Source: "coze", Target: "iflytek", Success: true, Nodes: map[string]int{"start": 1, "end": 1}},
+		{Time: day(5), Source: "iflytek", Target: "dify", Success: false, ErrorCode: "PARSE_FAILED", Nodes: map[string]int{"llm": 9}},
+		{Time: day(4), Source: "dify", Target: "coze", Success: false},
+		{Time: day(2), Source: "iflytek", Target: "dify", Success: true, Nodes: map[string]int{"code": 4}, Placeholders: 2},
+	}
+
+	summary := stats.Summarize(records, time.Time{})
+	require.Equal(t, day(1), *summary.First)
+	require.Equal(t, day(5), *summary.Last)
+	require.Equal(t, 5, summary.Conversions)
+	require.Equal(t, 3, summary.Succeeded)
+	require.Equal(t, 2, summary.Failed)
+	require.Equal(t, []stats.Count{
+		{Name: "iflytek → dify", Count: 3},
+		{Name: "coze → iflytek", Count: 1},
+		{Name: "dify → coze", Count: 1},
+	}, summary.Paths)
+	require.Equal(t, []stats.Count{{Name: "PARSE_FAILED", Count: 1}, {Name: "unknown", Count: 1}}, summary.Errors)
+	require.Equal(t, []stats.Count{
+		{Name: "code", Count: 4},
+		{Name: "end", Count: 2},
+		{Name: "llm", Count: 2},
+		{Name: "start", Count: 2},
+	}, summary.NodeTypes, "nodes of failed conversions are not counted")
+	require.Equal(t, 10, summary.Nodes)
+	require.Equal(t, 3, summary.Placeholders)
+	require.Equal(t, 2, summary.WithPlaceholder)
+	require.InDelta(t, 30, summary.PlaceholderRate(), 0.001)
+	require.InDelta(t, 60, summary.SuccessRate(), 0.001)
+
+	since := stats.Summarize(records, day(3))
+	require.Equal(t, 3, since.Conversions, "records before since are left out")
+	require.Equal(t, day(3), *since.First)
+	require.Equal(t, 1, since.Succeeded)
+	require.Equal(t, 4, since.Nodes)
+
+	empty := stats.Summarize(nil, time.Time{})
+	require.Nil(t, empty.First)
+	require.Zero(t, empty.Conversions)
+	require.Empty(t, empty.Paths)
+	require.Zero(t, empty.PlaceholderRate())
+	require.Zero(t, empty.SuccessRate())
+}
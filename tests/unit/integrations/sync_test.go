@@ -0,0 +1,94 @@
+package integrations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/integrations"
+	"github.com/iflytek/agentbridge/internal/gitops"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAdapter is a platform account keeping pushed apps in memory
+type memoryAdapter struct {
+	apps   map[string][]byte
+	pushes int
+}
+
+func (a *memoryAdapter) Pull(ctx context.Context, appID string, includeSecrets bool) ([]byte, error) {
+	return a.apps[appID], nil
+}
+
+func (a *memoryAdapter) Push(ctx context.Context, dsl []byte, options integrations.PushOptions) (*integrations.PushResult, error) {
+	a.pushes++
+	appID := options.AppID
+	if appID == "" {
+		appID = "app-1"
+	}
+	a.apps[appID] = dsl
+	return &integrations.PushResult{AppID: appID, Updated: options.AppID != ""}, nil
+}
+
+// TestSyncIdempotentWithDrift validates that a sync pushes new workflows once, leaves synced ones
+// alone and overwrites apps changed on the platform
+func TestSyncIdempotentWithDrift(t *testing.T) {
+	dir := t.TempDir()
+	source, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_basic_start_end.yml"))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "workflows"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "workflows", "agent.yml"), source, 0644))
+	configPath := filepath.Join(dir, "sync.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("source_dir: workflows\nfrom: iflytek\ntargets:\n  - platform: dify\n"), 0644))
+
+	config, err := gitops.LoadConfig(configPath)
+	require.NoError(t, err)
+	conversionService, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+	adapter := &memoryAdapter{apps: make(map[string][]byte)}
+	syncer := &gitops.Syncer{
+		Config: config,
+		Convert: func(ctx context.Context, source []byte, from, to models.PlatformType, previous *models.IDMapping) (*services.ConversionResult, error) {
+			options := models.NewConversionOptions()
+			options.PreviousMapping = previous
+			return conversionService.ConvertWithResult(ctx, source, from, to, options)
+		},
+		NewAdapter: func(account integrations.Account) (integrations.Adapter, error) { return adapter, nil },
+		Version:    "test",
+	}
+
+	report, err := syncer.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report.Items, 1)
+	require.Equal(t, gitops.StatusCreated, report.Items[0].Status, report.Items[0].Error)
+	require.Equal(t, "app-1", report.Items[0].AppID)
+
+	state, err := os.ReadFile(config.StatePath())
+	require.NoError(t, err)
+	report, err = syncer.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, gitops.StatusInSync, report.Items[0].Status)
+	require.Equal(t, 1, adapter.pushes)
+	unchanged, err := os.ReadFile(config.StatePath())
+	require.NoError(t, err)
+	require.Equal(t, string(state), string(unchanged))
+
+	adapter.apps["app-1"] = []byte("edited on the platform")
+	syncer.DryRun = true
+	report, err = syncer.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, gitops.StatusPending, report.Items[0].Status)
+	require.Equal(t, 1, report.Drifted())
+	require.Equal(t, 1, adapter.pushes)
+
+	syncer.DryRun = false
+	report, err = syncer.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, gitops.StatusUpdated, report.Items[0].Status)
+	require.True(t, report.Items[0].Drift)
+	require.Equal(t, 2, adapter.pushes)
+}
@@ -0,0 +1,116 @@
+package integrations
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/iflytek/agentbridge/internal/signing"
+	"github.com/iflytek/agentbridge/internal/update"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpdater validates release lookup, checksum and signature checks and the binary swap
+// against a stub of the GitHub releases API
+func TestUpdater(t *testing.T) {
+	binaryName := update.AssetName(runtime.GOOS, runtime.GOARCH)
+	binary := []byte("#!/bin/sh\necho agentbridge v1.5.0\n")
+	checksums := signing.FormatChecksumFile(binary, binaryName)
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signature, err := signing.Sign(privateKey, checksums)
+	require.NoError(t, err)
+
+	assets := map[string][]byte{binaryName: binary, update.ChecksumsAsset: checksums, update.SignatureAsset: signature}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/iflytek/agentbridge/releases/latest" {
+			release := update.Release{TagName: "v1.5.0"}
+			for name, data := range assets {
+				release.Assets = append(release.Assets, update.Asset{Name: name, URL: server.URL + "/download/" + name, Size: int64(len(data))})
+			}
+			json.NewEncoder(w).Encode(release)
+			return
+		}
+		data, ok := assets[filepath.Base(r.URL.Path)]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	updater := &update.Updater{API: server.URL, PublicKey: publicKey}
+	release, err := updater.Latest(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "v1.5.0", release.TagName)
+	require.True(t, update.Newer(release.TagName, "v1.4.2"))
+	require.True(t, update.Newer(release.TagName, "dev"))
+	require.False(t, update.Newer(release.TagName, "v1.5.0"))
+
+	downloaded, err := updater.Download(context.Background(), release)
+	require.NoError(t, err)
+	require.Equal(t, binary, downloaded)
+
+	executable := filepath.Join(t.TempDir(), "agentbridge")
+	require.NoError(t, os.WriteFile(executable, []byte("old"), 0o755))
+	require.NoError(t, update.Replace(executable, downloaded))
+	installed, err := os.ReadFile(executable)
+	require.NoError(t, err)
+	require.Equal(t, binary, installed)
+
+	// A tampered binary or a foreign signature is never installed
+	assets[binaryName] = []byte("#!/bin/sh\necho tampered\n")
+	_, err = updater.Download(context.Background(), release)
+	require.ErrorIs(t, err, signing.ErrChecksumMismatch)
+
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = (&update.Updater{API: server.URL, PublicKey: otherKey}).Download(context.Background(), release)
+	require.ErrorIs(t, err, signing.ErrInvalidSignature)
+
+	// Without a key a release is only installed when unsigned ones are allowed
+	assets[binaryName] = binary
+	_, err = (&update.Updater{API: server.URL}).Download(context.Background(), release)
+	require.ErrorIs(t, err, update.ErrUnsigned)
+	downloaded, err = (&update.Updater{API: server.URL, AllowUnsigned: true}).Download(context.Background(), release)
+	require.NoError(t, err)
+	require.Equal(t, binary, downloaded)
+}
+
+// TestEmbeddedKey validates decoding the release public key set at build time
+func TestEmbeddedKey(t *testing.T) {
+	defer func(key string) { update.ReleaseKey = key }(update.ReleaseKey)
+
+	update.ReleaseKey = ""
+	key, err := update.EmbeddedKey()
+	require.NoError(t, err)
+	require.Nil(t, key, "development builds embed no key")
+
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	update.ReleaseKey = base64.StdEncoding.EncodeToString(der)
+	key, err = update.EmbeddedKey()
+	require.NoError(t, err)
+	require.Equal(t, publicKey, key)
+
+	update.ReleaseKey = "not base64!"
+	_, err = update.EmbeddedKey()
+	require.Error(t, err)
+
+	update.ReleaseKey = base64.StdEncoding.EncodeToString([]byte("not a key"))
+	_, err = update.EmbeddedKey()
+	require.Error(t, err)
+}
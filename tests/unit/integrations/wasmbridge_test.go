@@ -0,0 +1,65 @@
+package integrations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/wasmbridge"
+	"github.com/stretchr/testify/require"
+)
+
+// requireJSValue fails unless syscall/js can convert value: nil, booleans, numbers, strings,
+// []interface{} and map[string]interface{} of these
+func requireJSValue(t *testing.T, path string, value interface{}) {
+	t.Helper()
+	switch value := value.(type) {
+	case nil, bool, int, float64, string:
+	case []interface{}:
+		for _, item := range value {
+			requireJSValue(t, path+"[]", item)
+		}
+	case map[string]interface{}:
+		for key, item := range value {
+			requireJSValue(t, path+"."+key, item)
+		}
+	default:
+		t.Fatalf("%s has type %T, which syscall/js cannot convert", path, value)
+	}
+}
+
+// TestWasmBridgeConvert validates the responses of the js/wasm convert function
+func TestWasmBridgeConvert(t *testing.T) {
+	conversionService, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+	source, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err)
+
+	response, err := wasmbridge.Convert(conversionService, source, "", "iflytek", `{"title_prefix":"Web "}`)
+	require.NoError(t, err)
+	requireJSValue(t, "response", response)
+	require.Equal(t, "dify", response["source"], "the source platform should be detected")
+	require.Equal(t, "iflytek", response["target"])
+	require.Contains(t, response["output"], "Web ", "the options should apply")
+	require.IsType(t, map[string]interface{}{}, response["mapping"])
+
+	_, err = wasmbridge.Convert(conversionService, source, "dify", "iflytek", `{"title_prefix":`)
+	require.ErrorContains(t, err, "invalid options")
+	_, err = wasmbridge.Convert(conversionService, []byte("not a workflow"), "", "dify", "")
+	require.Error(t, err)
+}
+
+// TestWasmBridgeDetect validates the responses of the js/wasm detect function
+func TestWasmBridgeDetect(t *testing.T) {
+	source, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "Workflow-X72_Vminjiangushi_video_1-draft-2269.zip"))
+	require.NoError(t, err)
+
+	response, err := wasmbridge.Detect(source)
+	require.NoError(t, err)
+	requireJSValue(t, "response", response)
+	require.Equal(t, "coze", response["platform"], "ZIP exports should be detected as Coze")
+	signals := response["signals"].([]interface{})
+	require.NotEmpty(t, signals)
+	require.NotEmpty(t, signals[0], "signals should explain the detection")
+}
@@ -1,10 +1,20 @@
 package parsers
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/iflytek/agentbridge/core/interfaces"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	cozeParser "github.com/iflytek/agentbridge/platforms/coze/parser"
 	"github.com/iflytek/agentbridge/platforms/coze/strategies"
 	"github.com/stretchr/testify/require"
 )
@@ -152,3 +162,432 @@ func TestCozeParser_LLMWorkflow(t *testing.T) {
 
 	t.Logf("✅ Coze LLMWorkflow parser validation passed")
 }
+
+// TestCozeParser_RawWorkflowJSON validates Coze parser with the raw canvas JSON some tools export
+// instead of a ZIP package
+func TestCozeParser_RawWorkflowJSON(t *testing.T) {
+	strategy := strategies.NewCozeStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	inputFile := filepath.Join("..", "..", "fixtures", "coze", "coze_start_llm_end.json")
+	inputData, err := os.ReadFile(inputFile)
+	require.NoError(t, err, "file read failed")
+	require.True(t, common.IsCozeWorkflowJSON(inputData), "raw workflow JSON not recognized")
+
+	require.NoError(t, parser.Validate(inputData), "validation failed")
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	err = ValidateParserResult_LLMWorkflow(unifiedDSL)
+	require.NoError(t, err, "result validation failed")
+
+	// A unified DSL in JSON has no top-level canvas
+	require.False(t, common.IsCozeWorkflowJSON([]byte(`{"version":"1.0","workflow":{"nodes":[],"edges":[]}}`)))
+}
+
+// TestCozeParser_DatabaseWorkflow validates Coze parser with database query workflow
+func TestCozeParser_DatabaseWorkflow(t *testing.T) {
+	// Create parser instance
+	strategy := strategies.NewCozeStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	// Load test fixture data
+	inputFile := filepath.Join("..", "..", "fixtures", "coze", "coze_start_database_end.yml")
+	inputData, err := os.ReadFile(inputFile)
+	require.NoError(t, err, "file read failed")
+
+	// Parse Coze DSL to unified format
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	node := unifiedDSL.GetNodeByID("131546")
+	require.NotNil(t, node, "database node not found")
+	require.Equal(t, models.NodeTypeDataStore, node.Type)
+
+	config, ok := common.AsDataStoreConfig(node.Config)
+	require.True(t, ok, "unexpected config type")
+	require.Equal(t, models.DataStoreDatabase, config.Store)
+	require.Equal(t, models.DataStoreOperationQuery, config.Operation)
+	require.Equal(t, "7551234567890123456", config.Table)
+	require.Equal(t, 10, config.Limit)
+	require.Len(t, config.Fields, 2)
+	require.Len(t, config.Conditions, 1)
+	require.Equal(t, "user_name", config.Conditions[0].Field)
+	require.Equal(t, "equal", config.Conditions[0].Operator)
+
+	// Referenced condition values become node inputs
+	require.Len(t, node.Inputs, 1)
+	require.Equal(t, "100001", node.Inputs[0].Reference.NodeID)
+	require.Equal(t, "name", node.Inputs[0].Reference.OutputName)
+
+	t.Logf("✅ Coze DatabaseWorkflow parser validation passed")
+}
+
+func TestCozeParser_QuestionWorkflow(t *testing.T) {
+	// Create parser instance
+	strategy := strategies.NewCozeStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	// Load test fixture data
+	inputFile := filepath.Join("..", "..", "fixtures", "coze", "coze_start_question_end.yml")
+	inputData, err := os.ReadFile(inputFile)
+	require.NoError(t, err, "file read failed")
+
+	// Parse Coze DSL to unified format
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	node := unifiedDSL.GetNodeByID("150001")
+	require.NotNil(t, node, "question node not found")
+	require.Equal(t, models.NodeTypeHumanInput, node.Type)
+
+	config, ok := common.AsHumanInputConfig(node.Config)
+	require.True(t, ok, "unexpected config type")
+	require.Equal(t, models.HumanInputAnswerOption, config.AnswerType)
+	require.Equal(t, 3, config.MaxRetries)
+	require.Equal(t, "default", config.DefaultOption)
+	require.Len(t, config.Options, 2)
+	require.Equal(t, "branch_0", config.Options[0].ID)
+	require.Equal(t, "课程咨询", config.Options[0].Name)
+
+	// Option branches leave from the option ports
+	handles := make(map[string]bool)
+	for _, edge := range unifiedDSL.Workflow.Edges {
+		if edge.Source == node.ID {
+			handles[edge.SourceHandle] = true
+		}
+	}
+	require.Equal(t, map[string]bool{"branch_0": true, "branch_1": true, "default": true}, handles)
+
+	t.Logf("✅ Coze QuestionWorkflow parser validation passed")
+}
+
+// TestCozeParser_JSONWorkflow validates Coze parser with JSON serialization and deserialization nodes
+func TestCozeParser_JSONWorkflow(t *testing.T) {
+	// Create parser instance
+	strategy := strategies.NewCozeStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	// Load test fixture data
+	inputFile := filepath.Join("..", "..", "fixtures", "coze", "coze_start_json_end.yml")
+	inputData, err := os.ReadFile(inputFile)
+	require.NoError(t, err, "file read failed")
+
+	// Parse Coze DSL to unified format
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	deserializeNode := unifiedDSL.GetNodeByID("160001")
+	require.NotNil(t, deserializeNode, "deserialization node not found")
+	require.Equal(t, models.NodeTypeJSONProcess, deserializeNode.Type)
+	config, ok := common.AsJSONProcessConfig(deserializeNode.Config)
+	require.True(t, ok, "unexpected config type")
+	require.Equal(t, models.JSONOperationDeserialize, config.Operation)
+	require.Equal(t, models.DataTypeObject, deserializeNode.Outputs[0].Type)
+
+	serializeNode := unifiedDSL.GetNodeByID("160002")
+	require.NotNil(t, serializeNode, "serialization node not found")
+	config, ok = common.AsJSONProcessConfig(serializeNode.Config)
+	require.True(t, ok, "unexpected config type")
+	require.Equal(t, models.JSONOperationSerialize, config.Operation)
+	require.Equal(t, "160001", serializeNode.Inputs[0].Reference.NodeID)
+
+	// JSON nodes become equivalent code nodes on every target
+	common.LowerNodes(unifiedDSL, models.PlatformDify, nil)
+	serializeNode = unifiedDSL.GetNodeByID("160002")
+	require.Equal(t, models.NodeTypeCode, serializeNode.Type)
+	codeConfig, ok := common.AsCodeConfig(serializeNode.Config)
+	require.True(t, ok)
+	require.Contains(t, codeConfig.Code, "json.dumps(input, ensure_ascii=False)")
+
+	t.Logf("✅ Coze JSONWorkflow parser validation passed")
+}
+
+// TestCozeParser_ManyClassifierIntents validates that every intent branch of a 12-intent classifier keeps its edge
+func TestCozeParser_ManyClassifierIntents(t *testing.T) {
+	inputFile := filepath.Join("..", "..", "fixtures", "coze", "coze_start_classifier_end.yml")
+	inputData, err := os.ReadFile(inputFile)
+	require.NoError(t, err, "file read failed")
+
+	// Add intents 3-12 routed to the second branch target
+	const intentCount = 12
+	var intents, schemaEdges, edges strings.Builder
+	for i := 3; i <= intentCount; i++ {
+		fmt.Fprintf(&intents, "%s- name: 意图%d\n", strings.Repeat(" ", 20), i)
+		fmt.Fprintf(&schemaEdges, "        - sourceNodeID: \"197165\"\n          sourcePortID: branch_%d\n          targetNodeID: \"197167\"\n", i-1)
+		fmt.Fprintf(&edges, "    - from_node: \"197165\"\n      from_port: branch_%d\n      to_node: \"197167\"\n      to_port: \"\"\n", i-1)
+	}
+	dsl := string(inputData)
+	dsl = strings.ReplaceAll(dsl, "                    - name: 技能实践类\n", "                    - name: 技能实践类\n"+intents.String())
+	dsl = strings.Replace(dsl, "          sourcePortID: branch_1\n          targetNodeID: \"197167\"\n",
+		"          sourcePortID: branch_1\n          targetNodeID: \"197167\"\n"+schemaEdges.String(), 1)
+	dsl = strings.Replace(dsl, "      from_port: branch_1\n      to_node: \"197167\"\n      to_port: \"\"\n",
+		"      from_port: branch_1\n      to_node: \"197167\"\n      to_port: \"\"\n"+edges.String(), 1)
+
+	parser, err := strategies.NewCozeStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse([]byte(dsl))
+	require.NoError(t, err, "DSL parsing failed")
+
+	var classifierID string
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		if node.Type == models.NodeTypeClassifier {
+			classifierID = node.ID
+			config, ok := common.AsClassifierConfig(node.Config)
+			require.True(t, ok, "classifier config expected")
+			require.GreaterOrEqual(t, len(config.Classes), intentCount, "every intent should become a class")
+		}
+	}
+	require.NotEmpty(t, classifierID, "classifier node not found")
+
+	handles := map[string]bool{}
+	for _, edge := range unifiedDSL.Workflow.Edges {
+		if edge.Source == classifierID {
+			handles[edge.SourceHandle] = true
+		}
+	}
+	for i := 1; i <= intentCount; i++ {
+		require.True(t, handles[strconv.Itoa(i)], "edge of intent %d should be kept", i)
+	}
+}
+
+// TestCozeParser_CancelledZip checks that ZIP extraction stops once the context is cancelled
+func TestCozeParser_CancelledZip(t *testing.T) {
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "Workflow-X70_Vshuangrenxinlixue_video_1-draft-2241.zip"))
+	require.NoError(t, err, "file read failed")
+
+	parser, err := strategies.NewCozeStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	contextParser, ok := parser.(interfaces.ContextParser)
+	require.True(t, ok, "Coze parser should accept a context")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = contextParser.ParseWithContext(ctx, inputData)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Contains(t, err.Error(), "ZIP", "the ZIP conversion should be the cancelled step")
+}
+
+// buildZip returns a ZIP archive of the named entries
+func buildZip(t *testing.T, entries map[string][]byte) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range entries {
+		entry, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = entry.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+// TestCozeParser_ArchiveLimits checks that ZIP bombs are rejected before they are decompressed in full
+func TestCozeParser_ArchiveLimits(t *testing.T) {
+	workflow := []byte(`{"nodes":[],"edges":[]}`)
+	limits := models.ArchiveLimits{MaxDecompressedBytes: 1 << 20, MaxEntries: 3, MaxNestingDepth: 1}
+
+	tests := []struct {
+		name    string
+		archive []byte
+		message string
+	}{
+		{
+			name:    "decompressed size",
+			archive: buildZip(t, map[string][]byte{"Workflow-bomb.zip": make([]byte, 4<<20)}),
+			message: "decompresses beyond 1048576 bytes",
+		},
+		{
+			name: "entry count",
+			archive: buildZip(t, map[string][]byte{
+				"a.txt": nil, "b.txt": nil, "c.txt": nil, "Workflow-x.zip": workflow,
+			}),
+			message: "4 entries, at most 3 allowed",
+		},
+		{
+			name: "nesting",
+			archive: buildZip(t, map[string][]byte{
+				"Workflow-x.zip": buildZip(t, map[string][]byte{
+					"inner.zip": buildZip(t, map[string][]byte{"workflow.json": workflow}),
+				}),
+			}),
+			message: "nested more than 1 levels deep",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := cozeParser.NewCozeParser()
+			parser.SetArchiveLimits(limits)
+			_, err := parser.Parse(tt.archive)
+			require.ErrorIs(t, err, cozeParser.ErrArchiveLimit)
+			require.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+// TestCozeParser_DebugArtifacts checks that intermediate results of a ZIP export are saved only
+// into the debug directory
+func TestCozeParser_DebugArtifacts(t *testing.T) {
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "Workflow-X70_Vshuangrenxinlixue_video_1-draft-2241.zip"))
+	require.NoError(t, err, "file read failed")
+
+	debugDir := filepath.Join(t.TempDir(), "debug")
+	parser := cozeParser.NewCozeParser()
+	parser.SetDebugDir(debugDir)
+	_, err = parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	for _, name := range []string{common.ArtifactWorkflowJSON, common.ArtifactManifest, common.ArtifactCozeDSL} {
+		info, err := os.Stat(filepath.Join(debugDir, name))
+		require.NoError(t, err, "%s should be saved", name)
+		require.NotZero(t, info.Size(), "%s should not be empty", name)
+	}
+}
+
+// TestCozeParser_CodeAnalysis validates the static analysis of parsed and hand-written code nodes
+func TestCozeParser_CodeAnalysis(t *testing.T) {
+	parser, err := strategies.NewCozeStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "coze_start_code_end.yml"))
+	require.NoError(t, err, "file read failed")
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	var findings []common.CodeFinding
+	for i := range unifiedDSL.Workflow.Nodes {
+		findings = append(findings, common.AnalyzeCode(&unifiedDSL.Workflow.Nodes[i], models.PlatformIFlytek)...)
+	}
+	require.Len(t, findings, 1, "the rewritten main still reads the Coze params")
+	require.Equal(t, common.CodeFindingUndeclared, findings[0].Kind)
+	require.Contains(t, findings[0].Message, "params")
+
+	node := &models.Node{
+		Type:   models.NodeTypeCode,
+		Inputs: []models.Input{{Name: "query"}},
+		Config: models.CodeConfig{Language: "python3", Code: "import json\nimport requests\n\ndef main(query, limit):\n    with open('cache.txt') as f:\n        cached = json.load(f)\n    return {'result': requests.get(url, params=cached).text}\n"},
+	}
+	kinds := make(map[string]int)
+	for _, finding := range common.AnalyzeCode(node, models.PlatformIFlytek) {
+		kinds[finding.Kind]++
+	}
+	require.Equal(t, map[string]int{
+		common.CodeFindingImport:     1, // json
+		common.CodeFindingNetwork:    1, // requests
+		common.CodeFindingFile:       1, // open
+		common.CodeFindingUndeclared: 2, // the limit parameter and url
+	}, kinds)
+
+	node.Config = models.CodeConfig{Language: "javascript", Code: "async function main({ params }) {\n  const res = await fetch(params.endpoint);\n  return { result: params.query };\n}\n"}
+	findings = common.AnalyzeCode(node, models.PlatformCoze)
+	require.Len(t, findings, 2)
+	require.Equal(t, common.CodeFindingNetwork, findings[0].Kind)
+	require.Contains(t, findings[1].Message, "endpoint")
+}
+
+// TestCozeParser_BatchModeNode checks that a node running in batch mode becomes a parallel iteration
+func TestCozeParser_BatchModeNode(t *testing.T) {
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "Workflow-X70_Vshuangrenxinlixue_video_1-draft-2241.zip"))
+	require.NoError(t, err, "file read failed")
+	parser, err := strategies.NewCozeStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	nodes := make(map[string]models.Node)
+	for _, node := range unifiedDSL.Workflow.Nodes {
+		nodes[node.ID] = node
+	}
+
+	// The LLM node 139227 runs once per item of 547995.output, ten at a time
+	batchNode := nodes["139227"]
+	require.Equal(t, models.NodeTypeIteration, batchNode.Type)
+	config, ok := common.AsIterationConfig(batchNode.Config)
+	require.True(t, ok)
+	require.Equal(t, "547995", config.Iterator.SourceNode)
+	require.True(t, config.Execution.IsParallel)
+	require.Equal(t, 10, config.Execution.ParallelNums)
+	require.Len(t, config.SubWorkflow.Nodes, 1)
+
+	inner := config.SubWorkflow.Nodes[0]
+	require.Equal(t, models.NodeTypeLLM, inner.Type)
+	require.Equal(t, inner.ID, config.OutputSelector.NodeID)
+	require.Equal(t, "output", config.OutputSelector.OutputName, "the fields of outputList are the outputs per item")
+	require.Equal(t, "139227", inner.Inputs[0].Reference.NodeID, "the item is read from the iteration")
+	require.Contains(t, nodes, inner.ID, "body nodes are listed in the workflow like Coze loop bodies")
+
+	// Downstream nodes read the collected list as the iteration output
+	reader := nodes["194925"]
+	require.Equal(t, "139227", reader.Inputs[0].Reference.NodeID)
+	require.Equal(t, "output", reader.Inputs[0].Reference.OutputName)
+}
+
+// TestCozeParser_LenientRepair checks that lenient parse mode salvages damaged ZIP exports that
+// the other modes reject, and reports what it repaired
+func TestCozeParser_LenientRepair(t *testing.T) {
+	export, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "Workflow-X72_Vminjiangushi_video_1-draft-2269.zip"))
+	require.NoError(t, err, "file read failed")
+	workflow, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "coze_start_llm_end.json"))
+	require.NoError(t, err, "file read failed")
+	trailingComma := append(bytes.TrimSuffix(bytes.TrimSpace(workflow), []byte("}")), []byte(",}")...)
+
+	tests := []struct {
+		name   string
+		export []byte
+		issue  string
+	}{
+		{name: "truncated archive", export: export[:len(export)*6/10], issue: "salvaged"},
+		{name: "wrapper bytes", export: append([]byte("--boundary\r\n\r\n"), export...), issue: "wrapper bytes"},
+		{name: "trailing comma", export: buildZip(t, map[string][]byte{"Workflow-x.zip": trailingComma}), issue: "trailing commas"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := cozeParser.NewCozeParser()
+			_, err := parser.Parse(tt.export)
+			require.Error(t, err, "permissive mode should reject the damaged export")
+
+			parser.SetParseMode(models.ParseModeLenient)
+			unifiedDSL, err := parser.Parse(tt.export)
+			require.NoError(t, err, "lenient mode should salvage the export")
+			require.NotEmpty(t, unifiedDSL.Workflow.Nodes)
+			require.NoError(t, common.NewUnifiedDSLValidator().ValidateWorkflow(&unifiedDSL.Workflow), "the salvaged workflow should be valid")
+			require.Contains(t, strings.Join(parser.Issues(), "\n"), tt.issue)
+		})
+	}
+}
+
+func TestCozeParser_Triggers(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "coze_start_trigger_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	for _, tt := range []struct {
+		strategy string
+		nodes    int
+	}{
+		{strategy: models.TriggerStrategyComment, nodes: 2},
+		{strategy: models.TriggerStrategyPlaceholder, nodes: 4},
+	} {
+		t.Run(tt.strategy, func(t *testing.T) {
+			unifiedDSL, err := cozeParser.NewCozeParser().Parse(data)
+			require.NoError(t, err, "parse should succeed")
+			require.Len(t, unifiedDSL.Workflow.Nodes, 2, "trigger nodes should not become workflow nodes")
+			require.Len(t, unifiedDSL.Workflow.Triggers, 2)
+			require.Equal(t, models.TriggerTypeSchedule, unifiedDSL.Workflow.Triggers[0].Type)
+			require.Equal(t, "0 9 * * 1-5", unifiedDSL.Workflow.Triggers[0].Cron)
+			require.Equal(t, "早报", unifiedDSL.Workflow.Triggers[0].Inputs["input_01"])
+			require.Equal(t, "order.created", unifiedDSL.Workflow.Triggers[1].Event)
+
+			warnings := common.LowerTriggers(unifiedDSL, models.PlatformIFlytek, &models.ConversionOptions{TriggerStrategy: tt.strategy})
+			require.Len(t, warnings, 2)
+			require.Empty(t, unifiedDSL.Workflow.Triggers)
+			require.Len(t, unifiedDSL.Workflow.Nodes, tt.nodes)
+			require.NoError(t, common.NewUnifiedDSLValidator().ValidateWorkflow(&unifiedDSL.Workflow))
+			if tt.strategy == models.TriggerStrategyComment {
+				require.Contains(t, unifiedDSL.Workflow.Nodes[0].Description, "0 9 * * 1-5")
+			}
+		})
+	}
+}
@@ -1,10 +1,17 @@
 package parsers
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/iflytek/agentbridge/core/interfaces"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
 	"github.com/iflytek/agentbridge/platforms/dify/strategies"
 	"github.com/stretchr/testify/require"
 )
@@ -152,3 +159,336 @@ func TestDifyParser_LLMWorkflow(t *testing.T) {
 
 	t.Logf("✅ Dify LLMWorkflow parser validation passed")
 }
+
+// TestDifyParser_AgentWorkflow validates Dify parser with agent workflow
+func TestDifyParser_AgentWorkflow(t *testing.T) {
+	// Create parser instance
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	// Load test fixture data
+	inputFile := filepath.Join("..", "..", "fixtures", "dify", "dify_start_agent_end.yml")
+	inputData, err := os.ReadFile(inputFile)
+	require.NoError(t, err, "file read failed")
+
+	// Parse Dify DSL to unified format
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	node := unifiedDSL.GetNodeByID("1754290000001")
+	require.NotNil(t, node, "agent node not found")
+	require.Equal(t, models.NodeTypeAgent, node.Type)
+
+	config, ok := common.AsAgentConfig(node.Config)
+	require.True(t, ok, "unexpected config type")
+	require.Equal(t, "xdeepseekv32", config.Model.Name)
+	require.Equal(t, models.AgentStrategyFunctionCalling, config.Strategy)
+	require.Equal(t, 5, config.MaxIterations)
+	require.Len(t, config.Tools, 1, "disabled tools are skipped")
+	require.Equal(t, "ddgo_search", config.Tools[0].Name)
+
+	// Agents degrade to LLM nodes that keep their outputs
+	common.LowerNodes(unifiedDSL, models.PlatformIFlytek, nil)
+	node = unifiedDSL.GetNodeByID("1754290000001")
+	require.Equal(t, models.NodeTypeLLM, node.Type)
+	require.Len(t, node.Outputs, 1)
+	require.Equal(t, "output", node.Outputs[0].Name)
+
+	t.Logf("✅ Dify AgentWorkflow parser validation passed")
+}
+
+// TestDifyParser_DocumentWorkflow validates Dify parser with file inputs and document extractors
+func TestDifyParser_DocumentWorkflow(t *testing.T) {
+	// Create parser instance
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	// Load test fixture data
+	inputFile := filepath.Join("..", "..", "fixtures", "dify", "dify_start_document_end.yml")
+	inputData, err := os.ReadFile(inputFile)
+	require.NoError(t, err, "file read failed")
+
+	// Parse Dify DSL to unified format
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	startConfig, ok := common.AsStartConfig(unifiedDSL.GetNodeByID("1754300000001").Config)
+	require.True(t, ok, "unexpected start config type")
+	require.Len(t, startConfig.Variables, 2)
+	contract, attachments := startConfig.Variables[0], startConfig.Variables[1]
+	require.NotNil(t, contract.File)
+	require.False(t, contract.File.Multiple)
+	require.Equal(t, string(models.DataTypeString), contract.Type)
+	require.NotNil(t, attachments.File)
+	require.True(t, attachments.File.Multiple)
+	require.Equal(t, 3, attachments.File.MaxCount)
+	require.Equal(t, []string{models.FileTypeDocument, models.FileTypeImage}, attachments.File.AllowedTypes)
+
+	node := unifiedDSL.GetNodeByID("1754300000003")
+	require.NotNil(t, node, "document extractor node not found")
+	require.Equal(t, models.NodeTypeDocumentExtractor, node.Type)
+	config, ok := common.AsDocumentExtractorConfig(node.Config)
+	require.True(t, ok, "unexpected config type")
+	require.True(t, config.IsArrayFile)
+	require.Len(t, node.Inputs, 1)
+	require.Equal(t, "attachments", node.Inputs[0].Reference.OutputName)
+	require.Equal(t, models.DataTypeArrayString, node.Outputs[0].Type)
+
+	// Document extractors degrade to code stubs on iFlytek that keep their outputs
+	common.LowerNodes(unifiedDSL, models.PlatformIFlytek, nil)
+	node = unifiedDSL.GetNodeByID("1754300000003")
+	require.Equal(t, models.NodeTypeCode, node.Type)
+	require.Equal(t, "text", node.Outputs[0].Name)
+
+	t.Logf("✅ Dify DocumentWorkflow parser validation passed")
+}
+
+// TestDifyParser_ListWorkflow validates Dify parser with list operator workflow
+func TestDifyParser_ListWorkflow(t *testing.T) {
+	// Create parser instance
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	// Load test fixture data
+	inputFile := filepath.Join("..", "..", "fixtures", "dify", "dify_start_list_end.yml")
+	inputData, err := os.ReadFile(inputFile)
+	require.NoError(t, err, "file read failed")
+
+	// Parse Dify DSL to unified format
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	node := unifiedDSL.GetNodeByID("1754310000003")
+	require.NotNil(t, node, "list operator node not found")
+	require.Equal(t, models.NodeTypeListOperation, node.Type)
+
+	config, ok := common.AsListOperationConfig(node.Config)
+	require.True(t, ok, "unexpected config type")
+	require.Equal(t, models.DataTypeObject, config.ItemType)
+	require.Len(t, config.Filters, 1)
+	require.Equal(t, "gte", config.Filters[0].Operator)
+	require.NotNil(t, config.SortBy)
+	require.False(t, config.SortBy.Ascending)
+	require.Equal(t, 2, config.Limit)
+	require.Zero(t, config.ExtractIndex, "disabled extraction is dropped")
+	require.Len(t, node.Outputs, 3)
+
+	// List operations become equivalent code nodes on iFlytek
+	common.LowerNodes(unifiedDSL, models.PlatformIFlytek, nil)
+	node = unifiedDSL.GetNodeByID("1754310000003")
+	require.Equal(t, models.NodeTypeCode, node.Type)
+	codeConfig, ok := common.AsCodeConfig(node.Config)
+	require.True(t, ok)
+	require.Contains(t, codeConfig.Code, `"first_record": result[0] if result else {}`)
+
+	t.Logf("✅ Dify ListWorkflow parser validation passed")
+}
+
+// TestDifyParser_MultiDocumentYAML validates that the Dify workflow is picked out of concatenated YAML documents
+func TestDifyParser_MultiDocumentYAML(t *testing.T) {
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_basic_start_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	// CI metadata before the workflow, an empty document after it
+	stream := "# generated by ci\nci_run: 42\n---\n" + string(inputData) + "\n---\n"
+	unifiedDSL, err := parser.Parse([]byte(stream))
+	require.NoError(t, err, "multi-document parsing failed")
+	require.NoError(t, ValidateParserResult_BasicStartEnd(unifiedDSL), "result validation failed")
+
+	// Two workflows in one stream are ambiguous
+	_, err = parser.Parse([]byte(string(inputData) + "\n---\n" + string(inputData)))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "found 2 Dify workflows in YAML documents 1, 2")
+
+	_, err = parser.Parse([]byte("ci_run: 42\n---\nstatus: ok\n"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "none of the 2 YAML documents is a Dify workflow")
+}
+
+// TestDifyParser_YAMLAnchors validates anchors, merge keys and alias-heavy documents beyond yaml.v3's alias ratio limit
+func TestDifyParser_YAMLAnchors(t *testing.T) {
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_basic_start_end.yml"))
+	require.NoError(t, err, "file read failed")
+	input := string(inputData)
+
+	// Share the app block through an anchor and a merge key
+	input = strings.Replace(input, "app:\n", "x-app: &app\n  mode: workflow\n  name: 智能学习助手\napp:\n  <<: *app\n", 1)
+	input = strings.Replace(input, "  mode: workflow\n  name: 智能学习助手\n  use_icon", "  use_icon", 1)
+
+	// Nest aliases of aliases, far beyond the alias ratio yaml.v3 accepts
+	block := make([]string, 10)
+	for i := range block {
+		block[i] = fmt.Sprintf("item%d", i)
+	}
+	rows := strings.TrimSuffix(strings.Repeat("*shared, ", 10), ", ")
+	grid := strings.TrimSuffix(strings.Repeat("*rows, ", 10), ", ")
+	aliases := strings.TrimSuffix(strings.Repeat("*grid, ", 20), ", ")
+	input = "x-shared: &shared [" + strings.Join(block, ", ") + "]\nx-rows: &rows [" + rows + "]\nx-grid: &grid [" + grid + "]\n" + input
+	input = strings.Replace(input, "  conversation_variables: []", "  conversation_variables: ["+aliases+"]", 1)
+
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "anchored DSL parsing failed")
+	require.Equal(t, "智能学习助手", unifiedDSL.Metadata.Name)
+	require.NoError(t, ValidateParserResult_BasicStartEnd(unifiedDSL), "result validation failed")
+}
+
+// TestDifyParser_YAMLAliasBomb validates that aliases expanding exponentially are rejected before
+// anything is copied
+func TestDifyParser_YAMLAliasBomb(t *testing.T) {
+	parser, err := strategies.NewDifyStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	// Nine levels of nine aliases of the level below: 9^9 strings from a few hundred bytes
+	var input strings.Builder
+	input.WriteString("a0: &a0 [lol, lol, lol, lol, lol, lol, lol, lol, lol]\n")
+	for level := 1; level <= 9; level++ {
+		alias := fmt.Sprintf("*a%d, ", level-1)
+		fmt.Fprintf(&input, "a%d: &a%d [%s]\n", level, level, strings.TrimSuffix(strings.Repeat(alias, 9), ", "))
+	}
+	input.WriteString("app:\n  mode: workflow\n  name: bomb\nkind: app\nworkflow:\n  graph:\n    nodes: *a9\n")
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	started := time.Now()
+	_, err = parser.Parse([]byte(input.String()))
+	elapsed := time.Since(started)
+	runtime.ReadMemStats(&after)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "YAML aliases expand to more than")
+	require.Less(t, elapsed, 2*time.Second, "the document should be rejected quickly")
+	require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(32<<20), "the document should be rejected before expansion")
+}
+
+// TestDifyParser_ParseModes validates that strict parsing fails on the source problems permissive parsing reports
+func TestDifyParser_ParseModes(t *testing.T) {
+	strategy := strategies.NewDifyStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	configurable, ok := parser.(interfaces.ConfigurableParser)
+	require.True(t, ok, "Dify parser should support parse modes")
+
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	// An unknown node type and an end output referencing a missing node
+	input := strings.Replace(string(inputData), "type: llm\n", "type: mystery-node\n", 1)
+	input = strings.Replace(input, "- '1754290000001'\n          - text", "- '9999'\n          - text", 1)
+
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "permissive parsing should work around source problems")
+	require.NotNil(t, unifiedDSL)
+	issues := configurable.Issues()
+	require.Len(t, issues, 2, "issues: %v", issues)
+	require.Contains(t, issues[0], "unsupported node type 'mystery-node'")
+	require.Contains(t, issues[1], "references unknown node 9999")
+
+	configurable.SetParseMode(models.ParseModeStrict)
+	_, err = parser.Parse([]byte(input))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "strict parse mode")
+	require.Contains(t, err.Error(), "mystery-node")
+	require.Contains(t, err.Error(), "9999")
+
+	_, err = parser.Parse(inputData)
+	require.NoError(t, err, "a clean source should parse in strict mode")
+	require.Empty(t, configurable.Issues())
+}
+
+func TestDifyParser_PlaceholderMarker(t *testing.T) {
+	parser, err := strategies.NewDifyStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_code_end.yml"))
+	require.NoError(t, err, "file read failed")
+	input := strings.Replace(string(inputData), "type: code\n", "type: knowledge-retrieval\n", 1)
+
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "DSL parsing failed")
+	node := unifiedDSL.GetNodeByID(common.FindPlaceholders(unifiedDSL)[0].ID)
+	require.NotNil(t, node.PlatformConfig.Placeholder, "the platform config should mark the placeholder")
+
+	// Placeholders stay recognizable without the title prefix
+	options := models.NewConversionOptions()
+	options.KeepTitles = true
+	require.NoError(t, common.ApplyTitleOptions(unifiedDSL, options))
+	require.False(t, common.IsUnsupportedNodeTitle(unifiedDSL.GetNodeByID(node.ID).Title))
+	require.Len(t, common.CollectUnsupportedNodes(unifiedDSL), 1)
+
+	// The code marker alone identifies placeholders read back from platform files
+	node = unifiedDSL.GetNodeByID(node.ID)
+	node.PlatformConfig.Placeholder = nil
+	require.True(t, common.IsPlaceholderNode(*node))
+
+	placeholders := common.FindPlaceholders(unifiedDSL)
+	require.Len(t, placeholders, 1)
+	require.False(t, placeholders[0].TitleOnly)
+	require.Equal(t, models.PlatformDify, placeholders[0].Marker.SourcePlatform)
+	require.Equal(t, "knowledge-retrieval", placeholders[0].Marker.OriginalType)
+	require.Equal(t, placeholders[0].ID, placeholders[0].Marker.OriginalID)
+	require.Equal(t, common.UnsupportedNodeAction, placeholders[0].Marker.Action)
+}
+
+// TestDifyParser_WorkflowStats validates the graph statistics of a parsed condition workflow
+func TestDifyParser_WorkflowStats(t *testing.T) {
+	parser, err := strategies.NewDifyStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_condition_end.yml"))
+	require.NoError(t, err, "file read failed")
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	stats := common.ComputeWorkflowStats(unifiedDSL)
+	require.Equal(t, 5, stats.Nodes)
+	require.Equal(t, 2, stats.NodeTypes[models.NodeTypeLLM])
+	require.Equal(t, 4, stats.MaxDepth)
+	require.Equal(t, 0, stats.Iterations)
+	require.Equal(t, 1, stats.BranchNodes)
+	require.Equal(t, 3, stats.Branches, "two cases and the default branch")
+	require.Equal(t, 3, stats.MaxFanOut.Count, "start node read by the condition and both LLM nodes")
+	require.Equal(t, "1758004290203", stats.MaxFanOut.NodeID)
+}
+
+// TestDifyParser_BranchPreview validates the branches a parsed condition workflow takes for sample values
+func TestDifyParser_BranchPreview(t *testing.T) {
+	parser, err := strategies.NewDifyStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_condition_end.yml"))
+	require.NoError(t, err, "file read failed")
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	cases := []struct {
+		gender interface{}
+		branch string
+		next   string
+	}{
+		{"男", "true", "1758004955189"},
+		{"a woman", "b28378e0-e0c1-4e55-b87e-53dedb759aeb", "1758004961777"},
+		{"other", "false", "1758004961777"},
+	}
+	for _, c := range cases {
+		preview, err := common.PreviewBranches(unifiedDSL, map[string]interface{}{"gender": c.gender})
+		require.NoError(t, err)
+		require.Len(t, preview.Decisions, 1)
+		require.Equal(t, c.branch, preview.Decisions[0].Branch, "gender %v", c.gender)
+		require.Equal(t, []string{c.next}, preview.Decisions[0].Next)
+		require.Contains(t, preview.Reached, "1758005101437", "the end node is reached")
+	}
+
+	preview, err := common.PreviewBranches(unifiedDSL, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"1758004290203.gender"}, preview.Missing)
+}
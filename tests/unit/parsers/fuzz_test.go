@@ -0,0 +1,68 @@
+package parsers
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cozeParser "github.com/iflytek/agentbridge/platforms/coze/parser"
+	difyParser "github.com/iflytek/agentbridge/platforms/dify/parser"
+	iflytekParser "github.com/iflytek/agentbridge/platforms/iflytek/parser"
+)
+
+// The fuzz targets feed corrupted DSL files to the parsers, which must return errors rather than
+// panic. Run one with, for example:
+//
+//	go test ./tests/unit/parsers/ -run '^$' -fuzz FuzzCozeParser -fuzztime 1m -fuzzminimizetime 5s
+//
+// Fixtures are large, so the default minimization of new inputs can stall a run for minutes.
+// Crashers are saved below testdata/fuzz and replayed by go test.
+
+// addFixtureSeeds seeds f with every fixture file of a platform
+func addFixtureSeeds(f *testing.F, platform string) [][]byte {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "fixtures", platform, "*"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	var seeds [][]byte
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+		seeds = append(seeds, data)
+	}
+	return seeds
+}
+
+func FuzzCozeParser(f *testing.F) {
+	for _, seed := range addFixtureSeeds(f, "coze") {
+		// Base64 encoded ZIP exports take their own branch
+		if len(seed) > 1 && seed[0] == 'P' && seed[1] == 'K' {
+			f.Add([]byte(base64.StdEncoding.EncodeToString(seed)))
+		}
+	}
+	f.Add([]byte(`{"nodes":[{"id":"1","type":"1"}],"edges":[]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cozeParser.NewCozeParser().Parse(data)
+	})
+}
+
+func FuzzDifyParser(f *testing.F) {
+	addFixtureSeeds(f, "dify")
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		difyParser.NewDifyParser().Parse(data)
+	})
+}
+
+func FuzzIFlytekParser(f *testing.F) {
+	addFixtureSeeds(f, "iflytek")
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		iflytekParser.NewIFlytekParser().Parse(data)
+	})
+}
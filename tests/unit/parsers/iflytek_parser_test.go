@@ -1,10 +1,16 @@
 package parsers
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	difyStrategies "github.com/iflytek/agentbridge/platforms/dify/strategies"
 	"github.com/iflytek/agentbridge/platforms/iflytek/strategies"
 	"github.com/stretchr/testify/require"
 )
@@ -152,3 +158,179 @@ func TestIFlytekParser_LLMWorkflow(t *testing.T) {
 
 	t.Logf("✅ iFlytek LLMWorkflow parser validation passed")
 }
+
+func TestIFlytekParser_AudioWorkflow(t *testing.T) {
+	// Create parser instance
+	strategy := strategies.NewIFlytekStrategy()
+	parser, err := strategy.CreateParser()
+	require.NoError(t, err, "parser creation failed")
+
+	// Load test fixture data
+	inputFile := filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_start_audio_end.yml")
+	inputData, err := os.ReadFile(inputFile)
+	require.NoError(t, err, "file read failed")
+
+	// Parse iFlytek DSL to unified format
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	asrNode := unifiedDSL.GetNodeByID("speech-to-text::2d4f6a8c-1b3d-4e5f-8a9b-0c1d2e3f4a52")
+	require.NotNil(t, asrNode, "speech recognition node not found")
+	require.Equal(t, models.NodeTypeSpeechToText, asrNode.Type)
+	asrConfig, ok := common.AsSpeechToTextConfig(asrNode.Config)
+	require.True(t, ok, "unexpected config type")
+	require.Equal(t, "zh_cn", asrConfig.Language)
+
+	ttsNode := unifiedDSL.GetNodeByID("text-to-speech::3e5a7b9d-2c4e-4f6a-9b0c-1d2e3f4a5b53")
+	require.NotNil(t, ttsNode, "speech synthesis node not found")
+	require.Equal(t, models.NodeTypeTextToSpeech, ttsNode.Type)
+	ttsConfig, ok := common.AsTextToSpeechConfig(ttsNode.Config)
+	require.True(t, ok, "unexpected config type")
+	require.Equal(t, "x4_xiaoyan", ttsConfig.Voice)
+	require.Equal(t, 60, ttsConfig.Speed)
+	require.Equal(t, "mp3", ttsConfig.Format)
+
+	// The HTTP degrade strategy calls a speech service instead of stubbing the node out; node
+	// strategies override it for single nodes
+	options := &models.ConversionOptions{
+		AudioStrategy:  models.AudioStrategyHTTP,
+		NodeStrategies: map[string]string{asrNode.ID: models.PlaceholderStrategyFail},
+	}
+	require.NoError(t, options.Validate())
+	common.LowerNodes(unifiedDSL, models.PlatformDify, options)
+	ttsNode = unifiedDSL.GetNodeByID(ttsNode.ID)
+	require.Equal(t, models.NodeTypeCode, ttsNode.Type)
+	require.Equal(t, "合成语音", ttsNode.Title)
+	codeConfig, ok := common.AsCodeConfig(ttsNode.Config)
+	require.True(t, ok, "unexpected config type")
+	require.True(t, strings.Contains(codeConfig.Code, "urllib.request.urlopen"))
+
+	asrNode = unifiedDSL.GetNodeByID(asrNode.ID)
+	require.True(t, common.IsUnsupportedNodeTitle(asrNode.Title), "the ASR node should be a placeholder")
+	require.Equal(t, []string{asrNode.Title}, common.CollectRejectedPlaceholders(unifiedDSL, options))
+
+	t.Logf("✅ iFlytek AudioWorkflow parser validation passed")
+}
+
+func TestIFlytekParser_Prologue(t *testing.T) {
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_start_llm_end.yml"))
+	require.NoError(t, err, "file read failed")
+
+	prologue := func(config string) *models.UIConfig {
+		input := strings.Replace(string(inputData),
+			`advancedConfig: '{"prologue":{"enabled":true,"inputExample":["","",""]},"needGuide":false}'`,
+			"advancedConfig: '"+config+"'", 1)
+		require.NotEqual(t, string(inputData), input, "fixture advancedConfig changed")
+
+		parser, err := strategies.NewIFlytekStrategy().CreateParser()
+		require.NoError(t, err, "parser creation failed")
+		unifiedDSL, err := parser.Parse([]byte(input))
+		require.NoError(t, err, "DSL parsing failed")
+		return unifiedDSL.Metadata.UIConfig
+	}
+
+	uiConfig := prologue(`{"prologue":{"enabled":true,"statement":"Hello","inputExample":["Q1","","Q2","Q3","Q4"]},"needGuide":false}`)
+	require.NotNil(t, uiConfig)
+	require.Equal(t, "Hello", uiConfig.OpeningStatement)
+	require.Equal(t, []string{"Q1", "Q2", "Q3", "Q4"}, uiConfig.SuggestedQuestions)
+
+	// A disabled prologue is not shown, so nothing is carried over
+	uiConfig = prologue(`{"prologue":{"enabled":false,"statement":"Hello","inputExample":["Q1"]},"needGuide":false}`)
+	if uiConfig != nil {
+		require.Empty(t, uiConfig.OpeningStatement)
+		require.Empty(t, uiConfig.SuggestedQuestions)
+	}
+}
+
+// TestIFlytekParser_Lint validates the lint rules on a parsed condition workflow
+func TestIFlytekParser_Lint(t *testing.T) {
+	parser, err := strategies.NewIFlytekStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_start_condition_end.yml"))
+	require.NoError(t, err, "file read failed")
+	unifiedDSL, err := parser.Parse(inputData)
+	require.NoError(t, err, "DSL parsing failed")
+
+	findings, err := common.Lint(unifiedDSL, models.LintConfig{})
+	require.NoError(t, err)
+	require.Len(t, findings, 1, "only birth_year is unread; AGENT_USER_INPUT is never reported")
+	require.Equal(t, "unused-start-variable", findings[0].Rule)
+	require.Contains(t, findings[0].Message, "birth_year")
+
+	// Dropping the edges of one branch leaves it without a target
+	var conditionID, handle string
+	edges := unifiedDSL.Workflow.Edges[:0]
+	for _, edge := range unifiedDSL.Workflow.Edges {
+		if handle == "" && strings.HasPrefix(edge.SourceHandle, "branch_one_of::") {
+			conditionID, handle = edge.Source, edge.SourceHandle
+		}
+		if edge.SourceHandle != handle || handle == "" {
+			edges = append(edges, edge)
+		}
+	}
+	unifiedDSL.Workflow.Edges = edges
+	require.NotEmpty(t, handle)
+
+	findings, err = common.Lint(unifiedDSL, models.LintConfig{Disable: []string{"unused-start-variable"}})
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, "branch-without-target", findings[0].Rule)
+	require.Equal(t, models.SeverityError, findings[0].Severity)
+	require.Equal(t, conditionID, findings[0].NodeID)
+	require.Contains(t, findings[0].Message, handle)
+
+	findings, err = common.Lint(unifiedDSL, models.LintConfig{Severity: map[string]models.ErrorSeverity{
+		"branch-without-target": models.LintSeverityOff, "unused-start-variable": models.SeverityError,
+	}})
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, models.SeverityError, findings[0].Severity)
+
+	_, err = common.Lint(unifiedDSL, models.LintConfig{Disable: []string{"no-such-rule"}})
+	require.Error(t, err)
+}
+
+// TestIFlytekParser_WorkflowMetadata validates that tags, author and version reach Dify, bumped on request
+func TestIFlytekParser_WorkflowMetadata(t *testing.T) {
+	inputData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_start_llm_end.yml"))
+	require.NoError(t, err, "file read failed")
+	input := strings.Replace(string(inputData), "  dslVersion: v1\n",
+		"  dslVersion: v1\n  tags: [support, faq]\n  author: Jane Doe\n  version: v1.2.3-rc.1\n", 1)
+
+	parser, err := strategies.NewIFlytekStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	unifiedDSL, err := parser.Parse([]byte(input))
+	require.NoError(t, err, "DSL parsing failed")
+	require.Equal(t, []string{"support", "faq"}, unifiedDSL.Metadata.Tags)
+	require.Equal(t, "Jane Doe", unifiedDSL.Metadata.Author)
+	require.Equal(t, "v1.2.3-rc.1", unifiedDSL.Metadata.Version)
+
+	service, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+	options := models.NewConversionOptions()
+	options.BumpVersion = models.VersionBumpMinor
+	result, err := service.ConvertWithResult(context.Background(), []byte(input), models.PlatformIFlytek, models.PlatformDify, options)
+	require.NoError(t, err, "conversion failed")
+
+	difyParser, err := difyStrategies.NewDifyStrategy().CreateParser()
+	require.NoError(t, err, "parser creation failed")
+	converted, err := difyParser.Parse(result.Output)
+	require.NoError(t, err, "Dify output parsing failed")
+	require.Equal(t, []string{"support", "faq"}, converted.Metadata.Tags)
+	require.Equal(t, "Jane Doe", converted.Metadata.Author)
+	require.Equal(t, "v1.3.0", converted.Metadata.Version)
+
+	for version, bumped := range map[string][3]string{
+		"":       {"1.0.0", "0.1.0", "0.0.1"},
+		"2":      {"3.0.0", "2.1.0", "2.0.1"},
+		"v0.9.9": {"v1.0.0", "v0.10.0", "v0.9.10"},
+	} {
+		for i, part := range []string{models.VersionBumpMajor, models.VersionBumpMinor, models.VersionBumpPatch} {
+			next, err := common.BumpVersion(version, part)
+			require.NoError(t, err)
+			require.Equal(t, bumped[i], next, "%s bump of %q", part, version)
+		}
+	}
+	_, err = common.BumpVersion("release-2", models.VersionBumpPatch)
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDetectPlatform validates source platform detection on every fixture and its refusal to guess
+func TestDetectPlatform(t *testing.T) {
+	for _, platform := range []models.PlatformType{models.PlatformDify, models.PlatformIFlytek, models.PlatformCoze} {
+		files, err := filepath.Glob(filepath.Join("..", "..", "fixtures", string(platform), "*"))
+		require.NoError(t, err)
+		require.NotEmpty(t, files)
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			require.NoError(t, err, "file read failed")
+
+			detection, err := common.DetectPlatform(data)
+			require.NoError(t, err, file)
+			require.Equal(t, platform, detection.Platform, file)
+			require.GreaterOrEqual(t, detection.Confidence, 0.5, file)
+		}
+	}
+
+	_, err := common.DetectPlatform([]byte("name: notes\nitems: [a, b]\n"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no Dify, iFlytek or Coze workflow signature found")
+
+	// A Dify document and an iFlytek document match equally well
+	dify, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_basic_start_end.yml"))
+	require.NoError(t, err)
+	iflytek, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_basic_start_end.yml"))
+	require.NoError(t, err)
+	_, err = common.DetectPlatform([]byte(string(dify) + "\n---\n" + string(iflytek)))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "matches both")
+}
+
+// TestDiagnoseInput validates the doctor checks on damaged variants of fixtures
+func TestDiagnoseInput(t *testing.T) {
+	yamlData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_llm_end.yml"))
+	require.NoError(t, err, "file read failed")
+	zipData, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "coze", "Workflow-X74_Wcaisehuochairen_video_1-draft-2293.zip"))
+	require.NoError(t, err, "file read failed")
+
+	// check returns the diagnosis of one check
+	check := func(diagnoses []common.Diagnosis, name string) common.Diagnosis {
+		for _, diagnosis := range diagnoses {
+			if diagnosis.Check == name {
+				return diagnosis
+			}
+		}
+		t.Fatalf("no %s diagnosis in %+v", name, diagnoses)
+		return common.Diagnosis{}
+	}
+
+	diagnoses := common.DiagnoseInput(yamlData)
+	for _, diagnosis := range diagnoses {
+		require.True(t, diagnosis.Passed(), "%+v", diagnosis)
+	}
+	require.Contains(t, check(diagnoses, "platform").Message, "dify")
+
+	withBOM := append([]byte("\xEF\xBB\xBF"), bytes.ReplaceAll(yamlData, []byte("\n"), []byte("\r\n"))...)
+	diagnoses = common.DiagnoseInput(withBOM)
+	require.Equal(t, models.SeverityInfo, check(diagnoses, "encoding").Severity)
+	require.NotEmpty(t, check(diagnoses, "encoding").Fix, "the BOM is reported")
+	require.Equal(t, "CRLF (Windows)", check(diagnoses, "line endings").Message)
+
+	tabbed := bytes.Replace(yamlData, []byte("\n  "), []byte("\n\t"), 1)
+	diagnoses = common.DiagnoseInput(tabbed)
+	require.Equal(t, models.SeverityError, check(diagnoses, "indentation").Severity)
+	require.Equal(t, models.SeverityError, check(diagnoses, "yaml").Severity)
+
+	diagnoses = common.DiagnoseInput(yamlData[:len(yamlData)/2])
+	require.Equal(t, models.SeverityError, check(diagnoses, "yaml").Severity)
+
+	diagnoses = common.DiagnoseInput(zipData)
+	require.True(t, check(diagnoses, "zip").Passed())
+	require.Contains(t, check(diagnoses, "platform").Message, "coze")
+
+	diagnoses = common.DiagnoseInput(zipData[:len(zipData)/2])
+	require.Equal(t, models.SeverityError, check(diagnoses, "zip").Severity)
+
+	diagnoses = common.DiagnoseInput([]byte(base64.StdEncoding.EncodeToString(zipData)))
+	require.True(t, check(diagnoses, "base64").Passed())
+	require.True(t, check(diagnoses, "zip").Passed())
+	require.Contains(t, check(diagnoses, "platform").Fix, "--from coze")
+}
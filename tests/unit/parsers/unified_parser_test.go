@@ -0,0 +1,140 @@
+package parsers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/core/interfaces"
+	"github.com/iflytek/agentbridge/internal/models"
+	"github.com/iflytek/agentbridge/platforms/common"
+	difyParser "github.com/iflytek/agentbridge/platforms/dify/parser"
+	iflytekParser "github.com/iflytek/agentbridge/platforms/iflytek/parser"
+	unifiedGenerator "github.com/iflytek/agentbridge/platforms/unified/generator"
+	unifiedParser "github.com/iflytek/agentbridge/platforms/unified/parser"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnifiedParser_RoundTrip validates that an exported unified DSL reads back unchanged and is schema checked
+func TestUnifiedParser_RoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "iflytek", "iflytek_start_iteration_end.yml"))
+	require.NoError(t, err)
+	source, err := iflytekParser.NewIFlytekParser().Parse(data)
+	require.NoError(t, err)
+
+	generator := unifiedGenerator.NewUnifiedGenerator()
+	exported, err := generator.Generate(source)
+	require.NoError(t, err)
+
+	parser := unifiedParser.NewUnifiedParser()
+	imported, err := parser.Parse(exported)
+	require.NoError(t, err)
+	reexported, err := generator.Generate(imported)
+	require.NoError(t, err)
+	require.Equal(t, string(exported), string(reexported))
+
+	// Configs come back with their node types, iterations by pointer as the generators expect
+	iteration := findNodeByType(imported.Workflow.Nodes, models.NodeTypeIteration)
+	require.NotNil(t, iteration)
+	iterConfig, ok := iteration.Config.(*models.IterationConfig)
+	require.True(t, ok)
+	require.NotEmpty(t, iterConfig.SubWorkflow.Nodes)
+	_, ok = common.AsCodeConfig(findNodeByType(iterConfig.SubWorkflow.Nodes, models.NodeTypeCode).Config)
+	require.True(t, ok)
+
+	invalid := strings.Replace(string(exported), "type: code", "type: script", 1)
+	_, err = parser.Parse([]byte(invalid))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"script" is not one of`)
+}
+
+func findNodeByType(nodes []models.Node, nodeType models.NodeType) *models.Node {
+	for i := range nodes {
+		if nodes[i].Type == nodeType {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
+// TestUnifiedParser_NestedConditions tests that a case nesting and/or groups takes the same
+// branches after conversion to every platform, which only have flat cases.
+func TestUnifiedParser_NestedConditions(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "dify", "dify_start_condition_end.yml"))
+	require.NoError(t, err)
+	source, err := difyParser.NewDifyParser().Parse(data)
+	require.NoError(t, err)
+
+	condition := findNodeByType(source.Workflow.Nodes, models.NodeTypeCondition)
+	require.NotNil(t, condition)
+	config, ok := common.AsConditionConfig(condition.Config)
+	require.True(t, ok)
+	selector := config.Cases[0].Conditions[0].VariableSelector
+	leaf := func(output, operator string, value interface{}, varType models.UnifiedDataType) models.ConditionExpression {
+		return models.ConditionExpression{Condition: &models.Condition{
+			VariableSelector:   []string{selector[0], output},
+			ComparisonOperator: operator,
+			Value:              value,
+			VarType:            varType,
+		}}
+	}
+	// (gender is 男 or man) and (birth_month < 4 or birth_day > 20)
+	config.Cases[0].Expression = &models.ConditionExpression{
+		LogicalOperator: models.LogicalAnd,
+		Operands: []models.ConditionExpression{
+			{LogicalOperator: models.LogicalOr, Operands: []models.ConditionExpression{
+				leaf("gender", common.OperatorEquals, "男", models.DataTypeString),
+				leaf("gender", common.OperatorEquals, "man", models.DataTypeString),
+			}},
+			{LogicalOperator: "OR", Operands: []models.ConditionExpression{
+				leaf("birth_month", common.OperatorLess, 4, models.DataTypeNumber),
+				leaf("birth_day", common.OperatorGreater, 20, models.DataTypeNumber),
+			}},
+		},
+	}
+	condition.Config = config
+	exported, err := unifiedGenerator.NewUnifiedGenerator().Generate(source)
+	require.NoError(t, err)
+	source, err = unifiedParser.NewUnifiedParser().Parse(exported)
+	require.NoError(t, err, "nested expressions should pass the unified schema")
+
+	samples := []map[string]interface{}{
+		{"gender": "man", "birth_month": 2, "birth_day": 1},
+		{"gender": "男", "birth_month": 8, "birth_day": 25},
+		{"gender": "man", "birth_month": 8, "birth_day": 1},
+		{"gender": "woman", "birth_month": 2, "birth_day": 25},
+	}
+	route := func(unifiedDSL *models.UnifiedDSL, values map[string]interface{}) []string {
+		preview, err := common.PreviewBranches(unifiedDSL, values)
+		require.NoError(t, err)
+		titles := make(map[string]string)
+		for _, node := range unifiedDSL.Workflow.Nodes {
+			titles[node.ID] = node.Title
+		}
+		var reached []string
+		for _, id := range preview.Reached {
+			reached = append(reached, titles[id])
+		}
+		return reached
+	}
+
+	service, err := core.InitializeArchitecture()
+	require.NoError(t, err)
+	targets := map[models.PlatformType]interfaces.DSLParser{
+		models.PlatformDify:    difyParser.NewDifyParser(),
+		models.PlatformIFlytek: iflytekParser.NewIFlytekParser(),
+	}
+	for target, parser := range targets {
+		result, err := service.ConvertWithResult(context.Background(), exported, models.PlatformUnified, target, nil)
+		require.NoError(t, err, "conversion to %s failed", target)
+		require.Contains(t, strings.Join(result.Warnings, "\n"), "split into 4 branches")
+		converted, err := parser.Parse(result.Output)
+		require.NoError(t, err, "%s parsing failed", target)
+		for _, values := range samples {
+			require.Equal(t, route(source, values), route(converted, values), "%s route for %v", target, values)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// AgentBridge in the browser: loads agentbridge.wasm and converts workflows client-side.
+//
+// Load wasm_exec.js of the Go release that built the module first
+// (cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .), then:
+//
+//   const agentbridge = await loadAgentBridge("agentbridge.wasm");
+//   const result = await agentbridge.convert(fileText, { to: "dify", minify: true });
+//   console.log(result.output, result.warnings);
+//
+// Inputs are strings, or Uint8Arrays for Coze ZIP exports. Nothing leaves the page.
+(function (root) {
+  "use strict";
+
+  let loading = null;
+
+  async function instantiate(source, importObject) {
+    if (typeof source === "string" || source instanceof URL) {
+      source = fetch(source);
+    }
+    if (typeof source.then === "function" || (typeof Response !== "undefined" && source instanceof Response)) {
+      const response = await source;
+      if (typeof WebAssembly.instantiateStreaming === "function") {
+        return WebAssembly.instantiateStreaming(response, importObject);
+      }
+      source = await response.arrayBuffer();
+    }
+    return WebAssembly.instantiate(source, importObject);
+  }
+
+  // loadAgentBridge starts the module once and resolves to the conversion API. source is the URL
+  // of agentbridge.wasm, a fetch Response or the module bytes.
+  function loadAgentBridge(source = "agentbridge.wasm") {
+    if (loading) {
+      return loading;
+    }
+    if (typeof root.Go !== "function") {
+      return Promise.reject(new Error("wasm_exec.js must be loaded before agentbridge.js"));
+    }
+    loading = (async () => {
+      const go = new root.Go();
+      const { instance } = await instantiate(source, go.importObject);
+      // main registers the API and keeps running to serve it
+      go.run(instance);
+      const api = root.agentbridge;
+      if (!api) {
+        throw new Error("agentbridge.wasm did not register its API");
+      }
+      return {
+        version: api.version,
+
+        // convert resolves to {output, source, target, warnings, placeholders, mapping}.
+        // options takes from (detected when empty), to and the JSON fields of
+        // ConversionOptions, such as target_version, minify or model_map.
+        convert(input, options = {}) {
+          const { from = "", to, ...conversionOptions } = options;
+          if (!to) {
+            return Promise.reject(new Error("options.to names the target platform (iflytek|dify|coze|unified)"));
+          }
+          return api.convert(input, from, to, conversionOptions);
+        },
+
+        // detect resolves to {platform, confidence, signals}
+        detect(input) {
+          return api.detect(input);
+        },
+      };
+    })();
+    return loading;
+  }
+
+  root.loadAgentBridge = loadAgentBridge;
+  if (typeof module === "object" && module.exports) {
+    module.exports = { loadAgentBridge };
+  }
+})(typeof globalThis !== "undefined" ? globalThis : this);
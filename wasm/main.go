@@ -0,0 +1,111 @@
+//go:build js && wasm
+
+// Command wasm builds the conversion core for browsers, so pages can convert workflows without
+// uploading them anywhere. It registers a global "agentbridge" object; wasm/agentbridge.js loads
+// the module and wraps the object in promises.
+//
+//	GOOS=js GOARCH=wasm go build -o agentbridge.wasm ./wasm
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/iflytek/agentbridge/core"
+	"github.com/iflytek/agentbridge/core/services"
+	"github.com/iflytek/agentbridge/internal/wasmbridge"
+)
+
+// version is set at build time via -ldflags
+var version = "dev"
+
+func main() {
+	service, err := core.InitializeArchitecture()
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialize architecture: %v", err))
+	}
+
+	js.Global().Set("agentbridge", js.ValueOf(map[string]interface{}{
+		"version": version,
+		"convert": promiseFunc(func(args []js.Value) (interface{}, error) {
+			return convert(service, args)
+		}),
+		"detect": promiseFunc(detect),
+	}))
+
+	// Keep the exported functions alive
+	select {}
+}
+
+// convert(input, from, to, options) converts input, a string or Uint8Array, from the source
+// platform ("" detects it) to the target. options is an object with the JSON fields of
+// models.ConversionOptions. It resolves to {output, source, target, warnings, placeholders, mapping}.
+func convert(service *services.ConversionService, args []js.Value) (interface{}, error) {
+	if len(args) < 3 {
+		return nil, fmt.Errorf("convert needs input, from and to")
+	}
+	data, err := inputBytes(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var from string
+	if !args[1].IsNull() && !args[1].IsUndefined() {
+		from = args[1].String()
+	}
+	var options string
+	if len(args) > 3 && args[3].Type() == js.TypeObject {
+		options = js.Global().Get("JSON").Call("stringify", args[3]).String()
+	}
+	return wasmbridge.Convert(service, data, from, args[2].String(), options)
+}
+
+// detect(input) resolves to {platform, confidence, signals} for a string or Uint8Array.
+func detect(args []js.Value) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("detect needs input")
+	}
+	data, err := inputBytes(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return wasmbridge.Detect(data)
+}
+
+// inputBytes reads a string or a Uint8Array, such as the contents of a ZIP export
+func inputBytes(value js.Value) ([]byte, error) {
+	switch {
+	case value.Type() == js.TypeString:
+		return []byte(value.String()), nil
+	case value.InstanceOf(js.Global().Get("Uint8Array")):
+		data := make([]byte, value.Get("length").Int())
+		js.CopyBytesToGo(data, value)
+		return data, nil
+	}
+	return nil, fmt.Errorf("input must be a string or a Uint8Array, got %s", value.Type())
+}
+
+// promiseFunc exports fn as a JavaScript function returning a promise. Conversions run on their
+// own goroutine, so the page stays responsive between the callbacks of the Go scheduler.
+func promiseFunc(fn func(args []js.Value) (interface{}, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		executor := js.FuncOf(func(this js.Value, handlers []js.Value) interface{} {
+			resolve, reject := handlers[0], handlers[1]
+			go func() {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						reject.Invoke(js.Global().Get("Error").New(fmt.Sprint(recovered)))
+					}
+				}()
+				value, err := fn(args)
+				if err != nil {
+					reject.Invoke(js.Global().Get("Error").New(err.Error()))
+					return
+				}
+				resolve.Invoke(value)
+			}()
+			return nil
+		})
+		defer executor.Release()
+		return js.Global().Get("Promise").New(executor)
+	})
+}
@@ -0,0 +1,287 @@
+// AgentBridge playground: parses a dropped DSL file for preview and converts it through the
+// /api endpoints of "agentbridge serve".
+(function () {
+  "use strict";
+
+  const NODE_WIDTH = 170;
+  const NODE_HEIGHT = 44;
+  const SCALE = 0.6;
+  const SVG_NS = "http://www.w3.org/2000/svg";
+
+  let currentFile = null;
+  let downloadURL = null;
+
+  const $ = (id) => document.getElementById(id);
+
+  function showError(message) {
+    $("error").textContent = message;
+    $("error").hidden = !message;
+  }
+
+  // API errors are JSON objects with an "error" field
+  async function readError(response) {
+    try {
+      const body = await response.json();
+      return body.error || response.statusText;
+    } catch (e) {
+      return response.statusText;
+    }
+  }
+
+  function formFor(file) {
+    const form = new FormData();
+    form.append("file", file);
+    return form;
+  }
+
+  async function loadFile(file) {
+    currentFile = file;
+    showError("");
+    $("result").hidden = true;
+    $("file-info").textContent = `${file.name} (${file.size} bytes)`;
+
+    const response = await fetch("api/parse", { method: "POST", body: formFor(file) });
+    if (!response.ok) {
+      $("preview").hidden = true;
+      $("options").hidden = true;
+      showError(await readError(response));
+      return;
+    }
+    const graph = await response.json();
+    $("workflow-name").textContent = graph.name ? `“${graph.name}”` : "";
+    $("graph-info").textContent = `${graph.platform} · ${graph.nodes.length} nodes · ${graph.edges.length} edges`;
+    renderGraph(graph);
+    $("preview").hidden = false;
+    $("options").hidden = false;
+
+    // Offer the other platforms first
+    const target = $("convert-form").elements.to;
+    if (target.value === graph.platform) {
+      target.value = graph.platform === "iflytek" ? "dify" : "iflytek";
+    }
+  }
+
+  // absolutePositions resolves node positions relative to their iteration
+  function absolutePositions(nodes) {
+    const byID = new Map(nodes.map((node) => [node.id, node]));
+    const positions = new Map();
+    const resolve = (node) => {
+      if (positions.has(node.id)) {
+        return positions.get(node.id);
+      }
+      let x = node.x;
+      let y = node.y;
+      const parent = node.parent && byID.get(node.parent);
+      if (parent) {
+        const origin = resolve(parent);
+        x += origin.x;
+        y += origin.y + NODE_HEIGHT / SCALE;
+      }
+      positions.set(node.id, { x, y });
+      return positions.get(node.id);
+    };
+    nodes.forEach(resolve);
+
+    // Workflows without layout get a grid
+    const placed = [...positions.values()].some((p) => p.x !== 0 || p.y !== 0);
+    if (!placed) {
+      nodes.forEach((node, i) => positions.set(node.id, { x: (i % 4) * 320, y: Math.floor(i / 4) * 140 }));
+    }
+    return positions;
+  }
+
+  function svgElement(name, attributes, parent) {
+    const element = document.createElementNS(SVG_NS, name);
+    Object.entries(attributes).forEach(([key, value]) => element.setAttribute(key, value));
+    if (parent) {
+      parent.appendChild(element);
+    }
+    return element;
+  }
+
+  function renderGraph(graph) {
+    const svg = $("graph");
+    svg.innerHTML = "";
+    if (graph.nodes.length === 0) {
+      return;
+    }
+
+    const positions = absolutePositions(graph.nodes);
+    const xs = [...positions.values()].map((p) => p.x * SCALE);
+    const ys = [...positions.values()].map((p) => p.y * SCALE);
+    const minX = Math.min(...xs) - 20;
+    const minY = Math.min(...ys) - 20;
+    const at = (id) => {
+      const p = positions.get(id);
+      return p && { x: p.x * SCALE - minX, y: p.y * SCALE - minY };
+    };
+    svg.setAttribute("width", Math.max(...xs) - minX + NODE_WIDTH + 20);
+    svg.setAttribute("height", Math.max(...ys) - minY + NODE_HEIGHT + 20);
+
+    // Iterations are drawn around their bodies, behind everything else
+    graph.nodes.filter((node) => node.type === "iteration").forEach((iteration) => {
+      const body = graph.nodes.filter((node) => node.parent === iteration.id).map((node) => at(node.id));
+      const self = at(iteration.id);
+      const all = body.concat([self]);
+      const x = Math.min(...all.map((p) => p.x)) - 10;
+      const y = Math.min(...all.map((p) => p.y)) - 10;
+      const group = svgElement("g", { class: "node iteration" }, svg);
+      svgElement("rect", {
+        x, y,
+        width: Math.max(...all.map((p) => p.x)) - x + NODE_WIDTH + 10,
+        height: Math.max(...all.map((p) => p.y)) - y + NODE_HEIGHT + 10,
+      }, group);
+    });
+
+    graph.edges.forEach((edge) => {
+      const from = at(edge.source);
+      const to = at(edge.target);
+      if (!from || !to) {
+        return;
+      }
+      const x1 = from.x + NODE_WIDTH;
+      const y1 = from.y + NODE_HEIGHT / 2;
+      const x2 = to.x;
+      const y2 = to.y + NODE_HEIGHT / 2;
+      const bend = Math.max(40, Math.abs(x2 - x1) / 2);
+      const path = svgElement("path", {
+        class: "edge",
+        d: `M${x1},${y1} C${x1 + bend},${y1} ${x2 - bend},${y2} ${x2},${y2}`,
+      }, svg);
+      if (edge.handle) {
+        svgElement("title", {}, path).textContent = edge.handle;
+      }
+    });
+
+    graph.nodes.forEach((node) => {
+      const p = at(node.id);
+      const group = svgElement("g", { class: "node", transform: `translate(${p.x},${p.y})` }, svg);
+      svgElement("rect", { width: NODE_WIDTH, height: NODE_HEIGHT }, group);
+      const title = svgElement("text", { x: 10, y: 18 }, group);
+      title.textContent = node.title.length > 22 ? node.title.slice(0, 21) + "…" : node.title;
+      const type = svgElement("text", { class: "type", x: 10, y: 34 }, group);
+      type.textContent = node.type;
+      svgElement("title", {}, group).textContent = `${node.title} (${node.id})`;
+    });
+  }
+
+  // readEvents calls onEvent for each server-sent event of a streamed response
+  async function readEvents(response, onEvent) {
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = "";
+    for (;;) {
+      const { value, done } = await reader.read();
+      if (done) {
+        return;
+      }
+      buffer += decoder.decode(value, { stream: true });
+      let end;
+      while ((end = buffer.indexOf("\n\n")) >= 0) {
+        const block = buffer.slice(0, end);
+        buffer = buffer.slice(end + 2);
+        let name = "message";
+        let data = "";
+        block.split("\n").forEach((line) => {
+          if (line.startsWith("event: ")) {
+            name = line.slice(7);
+          } else if (line.startsWith("data: ")) {
+            data += line.slice(6);
+          }
+        });
+        onEvent(name, JSON.parse(data));
+      }
+    }
+  }
+
+  function showResult(result) {
+    if (downloadURL) {
+      URL.revokeObjectURL(downloadURL);
+    }
+    downloadURL = URL.createObjectURL(new Blob([result.output], { type: "text/plain" }));
+    const link = $("download");
+    link.href = downloadURL;
+    link.download = result.filename;
+    $("result-info").textContent = `${result.filename} · ${result.output.length} bytes` +
+      (result.placeholders ? ` · ${result.placeholders} placeholder nodes` : "");
+
+    const warnings = $("warnings");
+    warnings.innerHTML = "";
+    result.warnings.forEach((warning) => {
+      const item = document.createElement("li");
+      item.textContent = warning;
+      warnings.appendChild(item);
+    });
+    $("output").textContent = result.output;
+    $("result").hidden = false;
+  }
+
+  async function convert(event) {
+    event.preventDefault();
+    if (!currentFile) {
+      return;
+    }
+    showError("");
+    $("result").hidden = true;
+
+    const form = formFor(currentFile);
+    const fields = new FormData(event.target);
+    fields.forEach((value, key) => {
+      if (value !== "") {
+        form.append(key, value);
+      }
+    });
+
+    const progress = $("progress");
+    progress.hidden = false;
+    progress.value = 0;
+    const response = await fetch("api/convert", {
+      method: "POST",
+      body: form,
+      headers: { Accept: "text/event-stream" },
+    });
+    if (!response.ok) {
+      progress.hidden = true;
+      showError(await readError(response));
+      return;
+    }
+    await readEvents(response, (name, data) => {
+      switch (name) {
+        case "progress":
+          progress.value = data.percent;
+          $("progress-info").textContent = data.stage + (data.node_title ? `: ${data.node_title}` : "");
+          break;
+        case "result":
+          showResult(data);
+          break;
+        case "error":
+          showError(data.error);
+          break;
+      }
+    });
+    progress.hidden = true;
+    $("progress-info").textContent = "";
+  }
+
+  const drop = $("drop");
+  drop.addEventListener("dragover", (event) => {
+    event.preventDefault();
+    drop.classList.add("over");
+  });
+  drop.addEventListener("dragleave", () => drop.classList.remove("over"));
+  drop.addEventListener("drop", (event) => {
+    event.preventDefault();
+    drop.classList.remove("over");
+    if (event.dataTransfer.files.length > 0) {
+      loadFile(event.dataTransfer.files[0]).catch((error) => showError(error.message));
+    }
+  });
+  $("file").addEventListener("change", (event) => {
+    if (event.target.files.length > 0) {
+      loadFile(event.target.files[0]).catch((error) => showError(error.message));
+    }
+  });
+  $("convert-form").addEventListener("submit", (event) => {
+    convert(event).catch((error) => showError(error.message));
+  });
+})();
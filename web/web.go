@@ -0,0 +1,19 @@
+// Package web holds the static files of the browser playground served by "agentbridge serve --ui".
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed static
+var static embed.FS
+
+// UI returns the playground files, rooted at index.html.
+func UI() fs.FS {
+	ui, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // The directory is embedded at build time
+	}
+	return ui
+}